@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sso/internal/storage/sqlite"
+	"time"
+)
+
+// backup - согласованная копия БД, пока сервер продолжает работать (в отличие от копирования файла)
+func backup(args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	storagePath := fs.String("storage-path", "", "path to the SQLite database")
+	out := fs.String("out", "", "backup file to create (must not exist)")
+	_ = fs.Parse(args)
+
+	if *storagePath == "" || *out == "" {
+		return errors.New("-storage-path and -out are required")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	s, err := sqlite.New(*storagePath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	start := time.Now()
+	reported := false
+	err = s.Backup(ctx, *out, func(copied, total int) {
+		fmt.Fprintf(os.Stderr, "\rcopied %d/%d pages", copied, total)
+		reported = true
+	})
+	if reported {
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(*out)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("backup %s: %d bytes in %s, integrity check ok\n", *out, info.Size(), time.Since(start).Round(time.Millisecond))
+
+	return nil
+}
+
+// verifyBackup - проверяет копию перед восстановлением (PRAGMA integrity_check)
+func verifyBackup(args []string) error {
+	fs := flag.NewFlagSet("verify-backup", flag.ExitOnError)
+	file := fs.String("file", "", "backup file to check")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	if err := sqlite.CheckIntegrity(context.Background(), *file); err != nil {
+		return err
+	}
+
+	fmt.Printf("%s: integrity check ok\n", *file)
+
+	return nil
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// importRow - строка файла импорта (CSV с заголовком или JSONL)
+type importRow struct {
+	Email        string `json:"email"`
+	PasswordHash string `json:"password_hash"`
+	Password     string `json:"password"` // открытый пароль; принимается только с --hash-plaintext
+	IsAdmin      bool   `json:"is_admin"`
+}
+
+// importUsers - загружает пользователей из CSV/JSONL через Admin.ImportUsers
+func importUsers(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:44044", "SSO gRPC address")
+	token := fs.String("token", os.Getenv("SSO_TOKEN"), "admin bearer token (default $SSO_TOKEN)")
+	file := fs.String("file", "", "CSV (with header) or JSONL file with users")
+	hashPlaintext := fs.Bool("hash-plaintext", false, "bcrypt plaintext `password` values instead of refusing them")
+	_ = fs.Parse(args)
+
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	rows, err := readImportFile(*file)
+	if err != nil {
+		return err
+	}
+
+	cc, err := grpc.NewClient(*addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+*token)
+
+	stream, err := ssov1.NewAdminClient(cc).ImportUsers(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i, r := range rows {
+		hash := r.PasswordHash
+		if hash == "" && r.Password != "" {
+			if !*hashPlaintext {
+				return fmt.Errorf("row %d (%s): plaintext password given, use --hash-plaintext to hash it", i+1, r.Email)
+			}
+
+			b, err := bcrypt.GenerateFromPassword([]byte(r.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return fmt.Errorf("row %d (%s): %w", i+1, r.Email, err)
+			}
+			hash = string(b)
+		}
+
+		if err := stream.Send(&ssov1.ImportUsersRequest{
+			Email:        r.Email,
+			PasswordHash: hash,
+			IsAdmin:      r.IsAdmin,
+		}); err != nil {
+			break // настоящая ошибка придёт из CloseAndRecv
+		}
+	}
+
+	summary, err := stream.CloseAndRecv()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created: %d, skipped: %d, failed: %d\n", summary.GetCreated(), summary.GetSkipped(), summary.GetFailed())
+	for _, e := range summary.GetErrors() {
+		fmt.Printf("  row %d (%s): %s\n", e.GetRow(), e.GetEmail(), e.GetReason())
+	}
+
+	return nil
+}
+
+// readImportFile - читает строки импорта; формат определяется по расширению (.jsonl/.ndjson, иначе CSV)
+func readImportFile(path string) ([]importRow, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson":
+		return readJSONL(f)
+	default:
+		return readCSV(f)
+	}
+}
+
+func readJSONL(r io.Reader) ([]importRow, error) {
+	var rows []importRow
+
+	sc := bufio.NewScanner(r)
+	for line := 1; sc.Scan(); line++ {
+		if strings.TrimSpace(sc.Text()) == "" {
+			continue
+		}
+
+		var row importRow
+		if err := json.Unmarshal(sc.Bytes(), &row); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, sc.Err()
+}
+
+func readCSV(r io.Reader) ([]importRow, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	// Колонки ищем по заголовку, порядок не важен
+	col := map[string]int{}
+	for i, name := range records[0] {
+		col[strings.TrimSpace(name)] = i
+	}
+	if _, ok := col["email"]; !ok {
+		return nil, errors.New("csv: email column is required")
+	}
+
+	get := func(rec []string, name string) string {
+		if i, ok := col[name]; ok && i < len(rec) {
+			return strings.TrimSpace(rec[i])
+		}
+		return ""
+	}
+
+	rows := make([]importRow, 0, len(records)-1)
+	for i, rec := range records[1:] {
+		isAdmin := false
+		if v := get(rec, "is_admin"); v != "" {
+			if isAdmin, err = strconv.ParseBool(v); err != nil {
+				return nil, fmt.Errorf("line %d: is_admin: %w", i+2, err)
+			}
+		}
+
+		rows = append(rows, importRow{
+			Email:        get(rec, "email"),
+			PasswordHash: get(rec, "password_hash"),
+			Password:     get(rec, "password"),
+			IsAdmin:      isAdmin,
+		})
+	}
+
+	return rows, nil
+}
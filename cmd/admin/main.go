@@ -0,0 +1,98 @@
+// admin - служебные команды для операторов SSO.
+//
+//	admin keygen -alg ES256|EdDSA [-app-id N]
+//	admin import -file users.csv [-addr host:port] [-token T] [--hash-plaintext]
+//	admin backup -storage-path sso.db -out backup.db
+//	admin verify-backup -file backup.db
+//	admin grant-superadmin -storage-path sso.db -user-id N [-revoke]
+//	admin encrypt-pii -storage-path sso.db [-key-file pii.key] [-batch 500]
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sso/internal/buildinfo"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "keygen":
+		err = keygen(args)
+	case "import":
+		err = importUsers(args)
+	case "backup":
+		err = backup(args)
+	case "verify-backup":
+		err = verifyBackup(args)
+	case "grant-superadmin":
+		err = grantSuperadmin(args)
+	case "encrypt-pii":
+		err = encryptPII(args)
+	case "version", "--version", "-version":
+		fmt.Println(buildinfo.Get())
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: admin <command> [flags]
+
+commands:
+  keygen         generate a token signing keypair and print the public JWK
+  import         bulk import users with bcrypt hashes from CSV or JSONL
+  backup         consistent copy of the SQLite database while the server is running
+  verify-backup  check a backup file before restoring it (PRAGMA integrity_check)
+  grant-superadmin
+                 grant (or -revoke) rights to manage all organizations
+  encrypt-pii    encrypt emails and phone numbers before enabling pii_key (resumable)
+  version        print version and exit`)
+}
+
+// keygen - создаёт ключевую пару для подписи токенов приложения.
+// Закрытый ключ (PEM) кладётся в apps.signing_key, открытый JWK отдаётся партнёрам
+func keygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	alg := fs.String("alg", jwt.AlgES256, "signing algorithm: ES256 or EdDSA")
+	appID := fs.Int("app-id", 0, "app id, used as the key id (kid)")
+	_ = fs.Parse(args)
+
+	key, err := jwt.GenerateKey(*alg)
+	if err != nil {
+		return err
+	}
+
+	jwk, err := jwt.PublicJWK(models.App{ID: *appID, Algorithm: *alg, SigningKey: string(key)})
+	if err != nil {
+		return err
+	}
+
+	pub, err := json.MarshalIndent(jwk, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("# private key: store in apps.signing_key and set apps.algorithm = '%s'\n", *alg)
+	fmt.Print(string(key))
+	fmt.Println("# public JWK:")
+	fmt.Println(string(pub))
+
+	return nil
+}
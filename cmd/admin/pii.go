@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"sso/internal/lib/pii"
+	"sso/internal/storage/sqlite"
+	"syscall"
+)
+
+// encryptPII - шифрует email и телефоны в существующей БД ключом pii_key. Запускается перед тем,
+// как включить pii_key в конфигурации (сервис с ключом не стартует на незашифрованной БД).
+// Шифрование идёт пачками; прерванное (в том числе Ctrl+C) продолжается повторным запуском с тем же ключом
+func encryptPII(args []string) error {
+	fs := flag.NewFlagSet("encrypt-pii", flag.ExitOnError)
+	storagePath := fs.String("storage-path", "", "path to the SQLite database")
+	keyFile := fs.String("key-file", "", "file with the base64 pii_key (default: PII_KEY environment variable)")
+	batch := fs.Int("batch", 500, "rows encrypted per transaction")
+	_ = fs.Parse(args)
+
+	if *storagePath == "" {
+		return errors.New("-storage-path is required")
+	}
+
+	key := os.Getenv("PII_KEY")
+	if *keyFile != "" {
+		b, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return err
+		}
+		key = string(b)
+	}
+	if key == "" {
+		return errors.New("-key-file or PII_KEY is required")
+	}
+
+	raw, err := pii.ParseKey(key)
+	if err != nil {
+		return err
+	}
+	cipher, err := pii.New(raw)
+	if err != nil {
+		return err
+	}
+
+	s, err := sqlite.New(*storagePath, sqlite.WithPII(cipher))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err = s.EncryptPII(ctx, *batch, func(p sqlite.PIIProgress) {
+		fmt.Printf("%s: %d/%d\n", p.Table, p.Done, p.Total)
+	})
+	if err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("interrupted, run the command again to continue: %w", err)
+		}
+
+		return err
+	}
+
+	fmt.Printf("database is encrypted with key %s; set pii_key and start the service\n", cipher.KeyID())
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"sso/internal/storage/sqlite"
+)
+
+// grantSuperadmin - выдаёт (или с -revoke отзывает) права администратора всех организаций.
+// Через API этого сделать нельзя: первого суперадминистратора назначает оператор с доступом к БД
+func grantSuperadmin(args []string) error {
+	fs := flag.NewFlagSet("grant-superadmin", flag.ExitOnError)
+	storagePath := fs.String("storage-path", "", "path to the SQLite database")
+	userID := fs.Int64("user-id", 0, "user to grant superadmin rights to")
+	revoke := fs.Bool("revoke", false, "revoke superadmin rights instead")
+	_ = fs.Parse(args)
+
+	if *storagePath == "" || *userID == 0 {
+		return errors.New("-storage-path and -user-id are required")
+	}
+
+	s, err := sqlite.New(*storagePath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	if err := s.SetSuperadmin(context.Background(), *userID, !*revoke); err != nil {
+		return err
+	}
+
+	if *revoke {
+		fmt.Printf("user %d is no longer a superadmin\n", *userID)
+	} else {
+		fmt.Printf("user %d is now a superadmin\n", *userID)
+	}
+
+	return nil
+}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"sso/internal/buildinfo"
+	"sso/internal/storage/sqlite"
+	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
@@ -14,11 +19,17 @@ func main() {
 	var storagePath, migrationsPath, migrationsTable string
 
 	// Чтение флагов командной строки:
-	flag.StringVar(&storagePath, "storage-path", "", "path to storage") // путь к файлу БД (например, SQLite)
-	flag.StringVar(&migrationsPath, "migrations-path", "", "path to migrations") // путь к папке с миграциями
+	flag.StringVar(&storagePath, "storage-path", "", "path to storage")                            // путь к файлу БД (например, SQLite)
+	flag.StringVar(&migrationsPath, "migrations-path", "", "path to migrations")                   // путь к папке с миграциями
 	flag.StringVar(&migrationsTable, "migrations-table", "migrations", "name of migrations table") // таблица, где будут храниться сведения о выполненных миграциях
-	flag.Parse()
 
+	// --version печатает сведения о сборке и завершает программу
+	flag.BoolFunc("version", "print version and exit", func(string) error {
+		fmt.Println(buildinfo.Get())
+		os.Exit(0)
+		return nil
+	})
+	flag.Parse()
 
 	// Проверка обязательных параметров
 	if storagePath == "" {
@@ -27,27 +38,47 @@ func main() {
 	if migrationsPath == "" {
 		panic("migrations-path is required")
 	}
-	
 
 	// Создаём экземпляр мигратора
 	m, err := migrate.New(
 		"file://"+migrationsPath, // откуда брать миграции
-		fmt.Sprintf("sqlite3://%s?x-migrations-table=%s", 
-		storagePath, migrationsTable), // к какой БД подключаться
+		fmt.Sprintf("sqlite3://%s?x-migrations-table=%s",
+			storagePath, migrationsTable), // к какой БД подключаться
 	)
 	if err != nil {
 		panic(err)
 	}
 
-
 	// Применяем миграции (по порядку)
 	if err := m.Up(); err != nil {
 		if errors.Is(err, migrate.ErrNoChange) {
 			fmt.Println("no migrations to apply") // если нет новых миграций — просто выходим
 			return
 		}
+		reportEmailCaseConflicts(storagePath)
 		panic(err) // если ошибка — падаем
 	}
 
 	fmt.Println("migrations applied") // успешное завершение
 }
+
+// reportEmailCaseConflicts - выводит email, различающиеся только регистром: из-за них не применяется
+// миграция уникального индекса по lower(email). Конфликты нужно разрешить вручную и повторить миграцию
+func reportEmailCaseConflicts(storagePath string) {
+	s, err := sqlite.New(storagePath)
+	if err != nil {
+		return
+	}
+	defer s.Close()
+
+	conflicts, err := s.EmailCaseConflicts(context.Background())
+	if err != nil || len(conflicts) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "found %d emails that differ only by case:\n", len(conflicts))
+	for _, emails := range conflicts {
+		fmt.Fprintf(os.Stderr, "  %s\n", strings.Join(emails, ", "))
+	}
+	fmt.Fprintln(os.Stderr, "rename or merge these users, reset the dirty version with `migrate force 9` and run migrator again")
+}
@@ -1,71 +1,141 @@
 package main
 
 import (
-	"log/slog"             // Новый логгер из стандартной библиотеки Go (Go 1.21+)
-	"os"                   // Работа с операционной системой (файлы, переменные окружения и сигналы)
-	"os/signal"            // Обработчик системных сигналов (например, завершение программы)
-	app "sso/internal/app" // Импортируем пакет с логикой gRPC-сервера
-	"sso/internal/config"  // Импортируем конфигурационный пакет
-	"syscall"              // Используется для перехвата системных сигналов (SIGTERM, SIGINT)
-)
-
-// Константы, определяющие окружение
-const (
-	envLocal = "local" // Локальная среда (например, разработка)
-	envDev   = "dev"   // Среда разработки
-	envProd  = "prod"  // Продакшен-среда
+	"context"                   // Контекст для координации остановки
+	"flag"                      // Разбор флагов командной строки
+	"fmt"                       // Вывод версии
+	"log/slog"                  // Новый логгер из стандартной библиотеки Go (Go 1.21+)
+	"os"                        // Работа с операционной системой (файлы, переменные окружения и сигналы)
+	"os/signal"                 // Обработчик системных сигналов (например, завершение программы)
+	app "sso/internal/app"      // Импортируем пакет с логикой gRPC-сервера
+	"sso/internal/buildinfo"    // Версия, коммит и дата сборки
+	"sso/internal/config"       // Импортируем конфигурационный пакет
+	"sso/internal/lib/listenfd" // Сокеты, унаследованные при перезапуске без простоя
+	"sso/internal/lib/logger"   // Настройка логгера по конфигурации
+	"strconv"                   // Номера дескрипторов из флагов
+	"syscall"                   // Используется для перехвата системных сигналов (SIGTERM, SIGINT)
 )
 
 func main() {
-	// Загружаем конфигурацию из config.yaml
-	cfg := config.MustLoad()
+	// Загружаем конфигурацию из файла, указанного флагом `-config` или переменной CONFIG_PATH
+	fs := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	fs.BoolFunc("version", "print version and exit", printVersion)
+	var inheritFDs []int
+	fs.Func("inherit-listener", "listen on an inherited socket with this file descriptor number (repeatable)", func(v string) error {
+		fd, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid file descriptor %q", v)
+		}
+		inheritFDs = append(inheritFDs, fd)
+
+		return nil
+	})
+	cfg := config.MustLoadWithFlags(fs, os.Args[1:])
+
+	// Лог пишется в stdout или в файл с ротацией (log.output)
+	logOut, err := logger.Output(cfg.Log)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "failed to open log output:", err)
+		os.Exit(1)
+	}
+	defer logOut.Close()
 
 	// Настраиваем логгер в зависимости от окружения
-	log := setupLogger(cfg.Env)
+	log, logLevel := logger.Setup(logOut, cfg.Log, cfg.Env)
 
 	// Логируем запуск приложения с загруженными настройками
-	log.Info("starting application", slog.Any("config", cfg))
+	log.Info("starting application", slog.Any("build", buildinfo.Get()), slog.Any("config", cfg))
 
-	// Создаем новый экземпляр gRPC-приложения
-	application := app.New(log, cfg.GRPC.Port, cfg.StoragePath, cfg.TokenTTL)
+	// Сокеты от systemd (LISTEN_FDS) или от предыдущего процесса (--inherit-listener): порт не освобождается
+	// на время перезапуска. Без них адреса открываются как обычно
+	listeners, err := inheritedListeners(inheritFDs)
+	if err != nil {
+		log.Error("failed to inherit listeners", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
-	// Запускаем gRPC-сервер в отдельной горутине
-	go application.GRPCSrv.MustRun()
+	// Создаем новый экземпляр приложения
+	application, err := app.New(log, logLevel, cfg)
+	if err != nil {
+		log.Error("failed to init application", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	if n := listeners.Len(); n > 0 {
+		log.Info("using inherited listeners", slog.Int("count", n))
+		application.InheritListeners(listeners)
+	}
 
-	// Создаем канал для обработки системных сигналов (SIGINT, SIGTERM)
-	stop := make(chan os.Signal, 1)
-	signal.Notify(stop, syscall.SIGTERM, syscall.SIGINT)
+	// Контекст отменяется по SIGINT/SIGTERM - это сигнал приложению остановиться
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
 
-	// Блокируем выполнение и ждем получения сигнала на остановку
-	sign := <-stop
+	// SIGHUP - перечитать конфигурацию без рестарта
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
 
-	// Логируем, что приложение завершает работу
-	log.Info("stopping application", slog.String("signal", sign.String()))
+	// SIGUSR1 - включить или выключить режим обслуживания
+	usr1 := make(chan os.Signal, 1)
+	signal.Notify(usr1, syscall.SIGUSR1)
 
-	// Останавливаем gRPC-сервер
-	application.GRPCSrv.Stop()
+	// SIGUSR2 - открыть файлы логов заново после logrotate
+	usr2 := make(chan os.Signal, 1)
+	signal.Notify(usr2, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+				reloadConfig(log, cfg, application)
+			case <-usr1:
+				application.ToggleMaintenance()
+			case <-usr2:
+				if err := logger.ReopenAll(); err != nil {
+					log.Error("failed to reopen log files", slog.String("error", err.Error()))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Запускаем серверы и блокируемся до сигнала остановки или фатальной ошибки
+	// (например, порт уже занят). Run сам останавливает серверы и дописывает журнал безопасности
+	if err := application.Run(ctx); err != nil {
+		log.Error("application stopped with error", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
 	// Логируем завершение работы
 	log.Info("application stopped")
 }
 
-// setupLogger - настраивает логгер в зависимости от окружения
-func setupLogger(env string) *slog.Logger {
-	var log *slog.Logger
-
-	switch env {
-	case envLocal:
-		// Локальная среда: текстовый лог с DEBUG уровнем
-		log = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
-	case envDev:
-		// Среда разработки: JSON-лог с DEBUG уровнем
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug}))
-	case envProd:
-		// Продакшен: JSON-лог с INFO уровнем (не логируем DEBUG)
-		log = slog.New(
-			slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelInfo}))
+// reloadConfig - перечитывает файл конфигурации и применяет безопасные для изменения настройки.
+// При ошибке (например, битый YAML) продолжает работать со старой конфигурацией
+func reloadConfig(log *slog.Logger, cfg *config.Config, application *app.App) {
+	log.Info("reloading config", slog.String("path", cfg.Path()))
+
+	newCfg, err := config.Load(cfg.Path())
+	if err != nil {
+		log.Error("failed to reload config, keeping the current one", slog.String("error", err.Error()))
+		return
 	}
 
-	return log
+	application.Reload(newCfg)
+}
+
+// inheritedListeners - сокеты с номерами из --inherit-listener, а без флага - переданные systemd
+func inheritedListeners(fds []int) (*listenfd.Set, error) {
+	if len(fds) > 0 {
+		return listenfd.FromFDs(fds)
+	}
+
+	return listenfd.FromEnv()
+}
+
+// printVersion - печатает сведения о сборке и завершает программу (флаг --version)
+func printVersion(string) error {
+	fmt.Println(buildinfo.Get())
+	os.Exit(0)
+
+	return nil
 }
@@ -0,0 +1,399 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+)
+
+// admin - команды сервиса Admin (нужен токен администратора)
+func admin(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: admin <set-admin|set-app-admin|list-users|backup|export-audit|verify-audit> [flags]", errUsage)
+	}
+
+	switch cmd, args := args[0], args[1:]; cmd {
+	case "set-admin":
+		return setAdmin(args)
+	case "set-app-admin":
+		return setAppAdmin(args)
+	case "list-users":
+		return listUsers(args)
+	case "backup":
+		return backup(args)
+	case "export-audit":
+		return exportAudit(args)
+	case "verify-audit":
+		return verifyAudit(args)
+	default:
+		return fmt.Errorf("%w: unknown admin command %q", errUsage, cmd)
+	}
+}
+
+// setAdmin - выдаёт или (с -revoke) отзывает права администратора
+func setAdmin(args []string) error {
+	fs := flag.NewFlagSet("admin set-admin", flag.ContinueOnError)
+	c := commonFlags(fs)
+	userID := fs.Int64("user-id", 0, "user to change")
+	revoke := fs.Bool("revoke", false, "revoke admin rights instead of granting them")
+	expectedVersion := fs.Int64("expected-version", 0, "fail if the user has changed since this version (0 - no check)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *userID == 0 {
+		return fmt.Errorf("%w: -user-id is required", errUsage)
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel, err := c.authContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	resp, err := ssov1.NewAdminClient(cc).SetAdmin(ctx, &ssov1.SetAdminRequest{
+		UserId:          *userID,
+		IsAdmin:         !*revoke,
+		ExpectedVersion: *expectedVersion,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.print(resp, field{"user_id", *userID}, field{"is_admin", !*revoke}, field{"version", resp.GetVersion()})
+}
+
+// setAppAdmin - назначает администратора приложения или (с -revoke) снимает его (нужен суперадминистратор)
+func setAppAdmin(args []string) error {
+	fs := flag.NewFlagSet("admin set-app-admin", flag.ContinueOnError)
+	c := commonFlags(fs)
+	appID := fs.Int("app-id", 0, "app to change")
+	userID := fs.Int64("user-id", 0, "user to change")
+	revoke := fs.Bool("revoke", false, "remove the app administrator instead of adding one")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *appID == 0 || *userID == 0 {
+		return fmt.Errorf("%w: -app-id and -user-id are required", errUsage)
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel, err := c.authContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	resp, err := ssov1.NewAdminClient(cc).SetAppAdmin(ctx, &ssov1.SetAppAdminRequest{
+		AppId:  int32(*appID),
+		UserId: *userID,
+		Admin:  !*revoke,
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.print(resp, field{"app_id", *appID}, field{"user_id", *userID}, field{"admin", !*revoke})
+}
+
+// listUsers - одна страница пользователей или (с -all) все страницы подряд
+func listUsers(args []string) error {
+	fs := flag.NewFlagSet("admin list-users", flag.ContinueOnError)
+	c := commonFlags(fs)
+	pageSize := fs.Int("page-size", 100, "users per page (max 1000)")
+	pageToken := fs.String("page-token", "", "next_page_token from a previous call")
+	emailPrefix := fs.String("email-prefix", "", "only users whose email starts with this prefix (case-insensitive)")
+	appID := fs.Int("app-id", 0, "only users of this app (requires user_apps on the server)")
+	all := fs.Bool("all", false, "fetch all pages")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	client := ssov1.NewAdminClient(cc)
+	result := &ssov1.ListUsersResponse{}
+
+	for token := *pageToken; ; {
+		ctx, cancel, err := c.authContext()
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.ListUsers(ctx, &ssov1.ListUsersRequest{
+			PageSize:    int32(*pageSize),
+			PageToken:   token,
+			EmailPrefix: *emailPrefix,
+			AppId:       int32(*appID),
+		})
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		result.Users = append(result.Users, resp.GetUsers()...)
+		result.NextPageToken = resp.GetNextPageToken()
+
+		token = resp.GetNextPageToken()
+		if !*all || token == "" {
+			break
+		}
+	}
+
+	if c.json {
+		return printJSON(result)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tEMAIL\tADMIN\tVERSION")
+	for _, u := range result.GetUsers() {
+		fmt.Fprintf(w, "%d\t%s\t%t\t%d\n", u.GetId(), u.GetEmail(), u.GetIsAdmin(), u.GetVersion())
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if result.GetNextPageToken() != "" {
+		fmt.Fprintf(os.Stderr, "more users: -page-token %s\n", result.GetNextPageToken())
+	}
+
+	return nil
+}
+
+// backup - копия БД в backup_dir сервера; прогресс выводится в stderr
+func backup(args []string) error {
+	fs := flag.NewFlagSet("admin backup", flag.ContinueOnError)
+	c := commonFlags(fs)
+	name := fs.String("name", "", "file name inside the server backup_dir (default sso-<UTC time>.db)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel, err := c.authContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	stream, err := ssov1.NewAdminClient(cc).Backup(ctx, &ssov1.BackupRequest{Name: *name})
+	if err != nil {
+		return err
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return errors.New("backup stream ended without a result")
+		}
+		if err != nil {
+			return err
+		}
+
+		if p := resp.GetProgress(); p != nil && !c.json {
+			fmt.Fprintf(os.Stderr, "\rcopied %d/%d pages", p.GetPagesCopied(), p.GetPagesTotal())
+			continue
+		}
+
+		if result := resp.GetResult(); result != nil {
+			if !c.json {
+				fmt.Fprintln(os.Stderr)
+			}
+
+			return c.print(result,
+				field{"name", result.GetName()},
+				field{"size_bytes", result.GetSizeBytes()},
+				field{"duration", (time.Duration(result.GetDurationMs()) * time.Millisecond).String()},
+			)
+		}
+	}
+}
+
+// exportAudit - выгрузка журнала безопасности в файл. Файл проверяется по trailer сервера;
+// если поток оборвался или сумма не сошлась, недописанный файл удаляется
+func exportAudit(args []string) (err error) {
+	fs := flag.NewFlagSet("admin export-audit", flag.ContinueOnError)
+	c := commonFlags(fs)
+	from := fs.String("from", "", "start of the range, RFC 3339 or YYYY-MM-DD (inclusive)")
+	to := fs.String("to", "", "end of the range, RFC 3339 or YYYY-MM-DD (exclusive)")
+	format := fs.String("format", "csv", "csv or jsonl")
+	out := fs.String("out", "", "output file")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *out == "" {
+		return fmt.Errorf("%w: -out is required", errUsage)
+	}
+	fromTime, err := parseTime(*from)
+	if err != nil {
+		return fmt.Errorf("%w: -from: %w", errUsage, err)
+	}
+	toTime, err := parseTime(*to)
+	if err != nil {
+		return fmt.Errorf("%w: -to: %w", errUsage, err)
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel, err := c.authContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	stream, err := ssov1.NewAdminClient(cc).ExportAuditEvents(ctx, &ssov1.ExportAuditEventsRequest{
+		From:   fromTime.Unix(),
+		To:     toTime.Unix(),
+		Format: *format,
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(*out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			_ = os.Remove(*out)
+		}
+	}()
+
+	sum := sha256.New()
+	w := io.MultiWriter(f, sum)
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return errors.New("export stream ended without a trailer, the file is incomplete")
+		}
+		if err != nil {
+			return err
+		}
+
+		if chunk := resp.GetChunk(); chunk != nil {
+			if _, err := w.Write(chunk); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if trailer := resp.GetTrailer(); trailer != nil {
+			if got := hex.EncodeToString(sum.Sum(nil)); got != trailer.GetSha256() {
+				return fmt.Errorf("checksum mismatch: got %s, server sent %s", got, trailer.GetSha256())
+			}
+
+			return c.print(trailer,
+				field{"file", *out},
+				field{"rows", trailer.GetRows()},
+				field{"sha256", trailer.GetSha256()},
+			)
+		}
+	}
+}
+
+// verifyAudit - проверка цепочки хешей журнала безопасности. Разрыв цепочки - ошибка (код выхода 1)
+// с ID первого события, на котором она рвётся
+func verifyAudit(args []string) error {
+	fs := flag.NewFlagSet("admin verify-audit", flag.ContinueOnError)
+	c := commonFlags(fs)
+	from := fs.String("from", "", "start of the range, RFC 3339 or YYYY-MM-DD (inclusive)")
+	to := fs.String("to", "", "end of the range, RFC 3339 or YYYY-MM-DD (exclusive)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	fromTime, err := parseTime(*from)
+	if err != nil {
+		return fmt.Errorf("%w: -from: %w", errUsage, err)
+	}
+	toTime, err := parseTime(*to)
+	if err != nil {
+		return fmt.Errorf("%w: -to: %w", errUsage, err)
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel, err := c.authContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	resp, err := ssov1.NewAdminClient(cc).VerifyAuditChain(ctx, &ssov1.VerifyAuditChainRequest{
+		From: fromTime.Unix(),
+		To:   toTime.Unix(),
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := c.print(resp,
+		field{"ok", resp.GetOk()},
+		field{"rows checked", resp.GetRowsChecked()},
+		field{"unchained", resp.GetUnchained()},
+		field{"head verified", resp.GetHeadVerified()},
+	); err != nil {
+		return err
+	}
+
+	if !resp.GetOk() {
+		return fmt.Errorf("audit chain is broken at event %d: %s", resp.GetFirstBrokenId(), resp.GetReason())
+	}
+
+	return nil
+}
+
+// parseTime - время в RFC 3339 или дата YYYY-MM-DD (полночь UTC)
+func parseTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("required")
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+
+	return time.Parse(time.RFC3339, s)
+}
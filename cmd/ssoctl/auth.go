@@ -0,0 +1,206 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+)
+
+// register - регистрирует пользователя; пароль запрашивается дважды
+func register(args []string) error {
+	fs := flag.NewFlagSet("register", flag.ContinueOnError)
+	c := commonFlags(fs)
+	email := fs.String("email", "", "user email")
+	org := fs.String("org", "", "organization slug (empty - no organization)")
+	tos := fs.String("accept-tos", "", "accepted terms of service version")
+	appID := fs.Int("app-id", 0, "app the user signs up through (0 - none)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *email == "" {
+		return fmt.Errorf("%w: -email is required", errUsage)
+	}
+
+	password, err := readNewPassword()
+	if err != nil {
+		return err
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	resp, err := ssov1.NewAuthClient(cc).Register(ctx, &ssov1.RegisterRequest{
+		Email:              *email,
+		Password:           password,
+		Org:                *org,
+		AcceptedTosVersion: *tos,
+		AppId:              int32(*appID),
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.print(resp, field{"user_id", resp.GetUserId()})
+}
+
+// login - входит и сохраняет токен для следующих команд
+func login(args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	c := commonFlags(fs)
+	email := fs.String("email", "", "user email")
+	appID := fs.Int("app-id", 1, "app to log in to")
+	org := fs.String("org", "", "organization slug (empty - no organization)")
+	tos := fs.String("accept-tos", "", "accept this terms of service version while logging in")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *email == "" {
+		return fmt.Errorf("%w: -email is required", errUsage)
+	}
+
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return err
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	resp, err := ssov1.NewAuthClient(cc).Login(ctx, &ssov1.LoginRequest{
+		Email:    *email,
+		Password: password,
+		AppId:    int32(*appID),
+		Org:      *org,
+
+		AcceptedTosVersion: *tos,
+	})
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(time.Duration(resp.GetExpiresIn()) * time.Second).Truncate(time.Second)
+
+	if err := saveToken(storedToken{
+		Token:     resp.GetToken(),
+		Addr:      c.addr,
+		Email:     *email,
+		AppID:     int32(*appID),
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return fmt.Errorf("save token: %w", err)
+	}
+
+	path, _ := tokenPath()
+
+	// Токен печатается только в JSON: в обычном выводе он попал бы в историю терминала
+	return c.print(resp,
+		field{"email", *email},
+		field{"app_id", *appID},
+		field{"expires_at", expiresAt.Format(time.RFC3339)},
+		field{"scopes", resp.GetScopes()},
+		field{"tos_reacceptance_required", resp.GetTosReacceptanceRequired()},
+		field{"token saved to", path},
+	)
+}
+
+// validate - проверяет токен из аргумента, -token/$SSO_TOKEN или сохранённый
+func validate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	c := commonFlags(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	token := fs.Arg(0)
+	if token == "" {
+		var err error
+		if token, err = c.bearer(); err != nil {
+			return err
+		}
+	}
+
+	return showPrincipal(c, token)
+}
+
+// whoami - владелец токена, с которым работают остальные команды
+func whoami(args []string) error {
+	fs := flag.NewFlagSet("whoami", flag.ContinueOnError)
+	c := commonFlags(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: whoami takes no arguments", errUsage)
+	}
+
+	token, err := c.bearer()
+	if err != nil {
+		return err
+	}
+
+	return showPrincipal(c, token)
+}
+
+// showPrincipal - проверяет token через ValidateToken и печатает владельца
+func showPrincipal(c *common, token string) error {
+	if token == "" {
+		return errors.New("empty token")
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel := c.context()
+	defer cancel()
+
+	resp, err := ssov1.NewAuthClient(cc).ValidateToken(ctx, &ssov1.ValidateTokenRequest{Token: token})
+	if err != nil {
+		return err
+	}
+
+	expiresAt := "never"
+	if resp.GetExpiresAt() != 0 {
+		expiresAt = time.Unix(resp.GetExpiresAt(), 0).Format(time.RFC3339)
+	}
+
+	if err := c.print(resp,
+		field{"user_id", resp.GetUserId()},
+		field{"email", resp.GetEmail()},
+		field{"app_id", resp.GetAppId()},
+		field{"is_admin", resp.GetIsAdmin()},
+		field{"org_id", resp.GetOrgId()},
+		field{"org_role", resp.GetOrgRole()},
+		field{"expires_at", expiresAt},
+	); err != nil {
+		return err
+	}
+
+	// Предупреждение - в stderr, чтобы не ломать -json
+	if resp.GetExpiresAt() != 0 && time.Until(time.Unix(resp.GetExpiresAt(), 0)) < time.Minute {
+		fmt.Fprintln(os.Stderr, "warning: token expires in less than a minute")
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+// ssoctl - клиент SSO для ручной проверки и эксплуатации.
+//
+//	ssoctl register -email E [-app-id N]
+//	ssoctl login -email E -app-id N
+//	ssoctl validate [-token T | TOKEN]
+//	ssoctl whoami
+//	ssoctl admin set-admin -user-id N [-revoke]
+//	ssoctl admin set-app-admin -app-id N -user-id N [-revoke]
+//	ssoctl admin list-users [-page-size N] [-page-token T] [-email-prefix P] [-app-id N] [-all]
+//	ssoctl admin backup [-name sso.db] [-timeout 10m]
+//	ssoctl admin export-audit -from 2025-01-01 -to 2025-02-01 [-format csv|jsonl] -out audit.csv [-timeout 1h]
+//	ssoctl admin verify-audit -from 2025-01-01 -to 2025-02-01 [-timeout 10m]
+//	ssoctl selftest [-timeout 1m]
+//	ssoctl gen-rules -config config/prod.yaml [-windows 5m,1h,6h] [-out rules.yml]
+//
+// Пароль всегда запрашивается с терминала без эха (или читается строкой из stdin, если это не терминал).
+// Токен после login сохраняется в каталоге настроек пользователя и используется следующими командами.
+// Код выхода - код gRPC-статуса ошибки (5 NotFound, 7 PermissionDenied, 16 Unauthenticated...),
+// 1 - локальная ошибка, 2 - неверные аргументы
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// errUsage - неверные аргументы команды (код выхода 2)
+var errUsage = errors.New("usage error")
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "register":
+		err = register(args)
+	case "login":
+		err = login(args)
+	case "validate":
+		err = validate(args)
+	case "whoami":
+		err = whoami(args)
+	case "admin":
+		err = admin(args)
+	case "selftest":
+		err = selfTest(args)
+	case "gen-rules":
+		err = genRules(args)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(exitCode(err))
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: ssoctl <command> [flags]
+
+commands:
+  register    register a new user
+  login       log in and store the token for subsequent commands
+  validate    validate a token
+  whoami      show the owner of the stored token
+  admin       set-admin, set-app-admin, list-users, backup, export-audit, verify-audit (requires an admin token)
+  selftest    run the end-to-end self-test on the server (requires an admin token)
+  gen-rules   print Prometheus recording rules for the SLO counters in a server config
+
+common flags (also from env):
+  -addr     SSO gRPC address ($SSO_ADDR, default localhost:44044)
+  -tls      use TLS ($SSO_TLS)
+  -ca-file  PEM file with CA certificates for TLS ($SSO_CA_FILE)
+  -token    bearer token ($SSO_TOKEN, default the stored one)
+  -json     print JSON instead of human-readable output
+
+exit status: 0 on success, the gRPC status code on RPC errors,
+1 on local errors, 2 on invalid arguments`)
+}
+
+// exitCode - код gRPC для ошибок сервера, 2 для неверных аргументов, 1 для остальных
+func exitCode(err error) int {
+	if errors.Is(err, errUsage) {
+		return 2
+	}
+
+	if st, ok := status.FromError(err); ok {
+		return int(st.Code())
+	}
+
+	return 1
+}
+
+// common - флаги, общие для всех команд
+type common struct {
+	addr    string
+	useTLS  bool
+	caFile  string
+	token   string
+	json    bool
+	timeout time.Duration
+}
+
+// commonFlags - регистрирует общие флаги в fs; значения по умолчанию берутся из окружения
+func commonFlags(fs *flag.FlagSet) *common {
+	c := &common{}
+
+	useTLS, _ := strconv.ParseBool(os.Getenv("SSO_TLS"))
+
+	fs.StringVar(&c.addr, "addr", envOr("SSO_ADDR", "localhost:44044"), "SSO gRPC address")
+	fs.BoolVar(&c.useTLS, "tls", useTLS, "use TLS")
+	fs.StringVar(&c.caFile, "ca-file", os.Getenv("SSO_CA_FILE"), "PEM file with CA certificates (default system roots)")
+	fs.StringVar(&c.token, "token", os.Getenv("SSO_TOKEN"), "bearer token (default the one stored by login)")
+	fs.BoolVar(&c.json, "json", false, "print JSON")
+	fs.DurationVar(&c.timeout, "timeout", 10*time.Second, "request timeout")
+
+	return c
+}
+
+// dial - подключается к серверу с учётом -tls и -ca-file
+func (c *common) dial() (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+
+	if c.useTLS || c.caFile != "" {
+		cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+		if c.caFile != "" {
+			pem, err := os.ReadFile(c.caFile)
+			if err != nil {
+				return nil, err
+			}
+
+			cfg.RootCAs = x509.NewCertPool()
+			if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("%s: no certificates found", c.caFile)
+			}
+		}
+
+		creds = credentials.NewTLS(cfg)
+	}
+
+	return grpc.NewClient(c.addr, grpc.WithTransportCredentials(creds))
+}
+
+// context - контекст запроса с таймаутом
+func (c *common) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+// authContext - контекст запроса с bearer-токеном из -token/$SSO_TOKEN или сохранённым после login
+func (c *common) authContext() (context.Context, context.CancelFunc, error) {
+	token, err := c.bearer()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := c.context()
+
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token), cancel, nil
+}
+
+// bearer - токен из флага или сохранённый
+func (c *common) bearer() (string, error) {
+	if c.token != "" {
+		return c.token, nil
+	}
+
+	stored, err := loadToken()
+	if err != nil {
+		return "", err
+	}
+
+	return stored.Token, nil
+}
+
+// parse - разбирает флаги команды; ошибки разбора - errUsage
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+
+	return nil
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return def
+}
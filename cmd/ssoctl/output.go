@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// field - строка человекочитаемого вывода
+type field struct {
+	name  string
+	value any
+}
+
+// print - выводит ответ: как JSON (-json) или парами "имя: значение"
+func (c *common) print(msg proto.Message, fields ...field) error {
+	if c.json {
+		return printJSON(msg)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 1, ' ', 0)
+	for _, f := range fields {
+		fmt.Fprintf(w, "%s:\t%v\n", f.name, f.value)
+	}
+
+	return w.Flush()
+}
+
+// printJSON - ответ сервера в JSON с именами полей как в proto
+func printJSON(msg proto.Message) error {
+	data, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true, Multiline: true}.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintln(os.Stdout, string(data))
+
+	return err
+}
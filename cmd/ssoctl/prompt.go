@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// stdin - общий буфер stdin: несколько запросов подряд не должны терять прочитанное
+var stdin = bufio.NewReader(os.Stdin)
+
+// readPassword - запрашивает пароль. С терминала - без эха, иначе читает строку из stdin
+// (для скриптов: `printf '%s\n' "$PASS" | ssoctl login ...`). Пароль никогда не берётся из argv
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", err
+		}
+
+		return string(b), nil
+	}
+
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("read password: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// readNewPassword - пароль с подтверждением (при вводе с терминала)
+func readNewPassword() (string, error) {
+	password, err := readPassword("Password: ")
+	if err != nil {
+		return "", err
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return password, nil
+	}
+
+	confirm, err := readPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", errors.New("passwords do not match")
+	}
+
+	return password, nil
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sso/internal/config"
+	"sso/internal/grpc/interceptors"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// genRules - правила записи Prometheus для счётчиков SLO из раздела grpc.slo конфигурации сервера.
+// Методы проверяются так же, как при запуске сервера, поэтому правила не расходятся с кодом
+func genRules(args []string) error {
+	fs := flag.NewFlagSet("gen-rules", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv("CONFIG_PATH"), "server config file ($CONFIG_PATH)")
+	windows := fs.String("windows", "", "comma-separated rule windows (default 5m,30m,1h,6h)")
+	out := fs.String("out", "", "output file (default stdout)")
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	if *configPath == "" {
+		return fmt.Errorf("%w: -config is required", errUsage)
+	}
+
+	var durations []time.Duration
+	if *windows != "" {
+		for _, w := range strings.Split(*windows, ",") {
+			d, err := time.ParseDuration(strings.TrimSpace(w))
+			if err != nil || d < time.Second {
+				return fmt.Errorf("%w: invalid window %q", errUsage, w)
+			}
+			durations = append(durations, d)
+		}
+	}
+
+	// Читаем только grpc.slo: остальной конфиг (секреты, storage_path) для правил не нужен
+	data, err := os.ReadFile(*configPath)
+	if err != nil {
+		return err
+	}
+	var cfg struct {
+		GRPC struct {
+			SLO config.SLOConfig `yaml:"slo"`
+		} `yaml:"grpc"`
+	}
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("%s: %w", *configPath, err)
+	}
+
+	if _, err := interceptors.NewSLO(cfg.GRPC.SLO); err != nil {
+		return err
+	}
+
+	rules, err := interceptors.RecordingRules(cfg.GRPC.SLO, durations)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(rules)
+		return err
+	}
+
+	return os.WriteFile(*out, rules, 0o644)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+)
+
+// selfTest - самопроверка развёртывания (Admin.RunSelfTest). Упавший или пропущенный шаг - ошибка команды
+// (код выхода 1), чтобы её можно было вызывать из скрипта выкладки
+func selfTest(args []string) error {
+	fs := flag.NewFlagSet("selftest", flag.ContinueOnError)
+	c := commonFlags(fs)
+	if err := parse(fs, args); err != nil {
+		return err
+	}
+
+	cc, err := c.dial()
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, cancel, err := c.authContext()
+	if err != nil {
+		return err
+	}
+	defer cancel()
+
+	resp, err := ssov1.NewAdminClient(cc).RunSelfTest(ctx, &ssov1.RunSelfTestRequest{})
+	if err != nil {
+		return err
+	}
+
+	if c.json {
+		err = printJSON(resp)
+	} else {
+		err = printSelfTest(resp)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, step := range resp.GetSteps() {
+		if step.GetStatus() == "failed" {
+			return fmt.Errorf("self-test failed at step %s: %s: %s", step.GetName(), step.GetReason(), step.GetMessage())
+		}
+	}
+	for _, step := range resp.GetSteps() {
+		if step.GetStatus() == "skipped" {
+			return fmt.Errorf("self-test did not pass: step %s skipped: %s", step.GetName(), step.GetMessage())
+		}
+	}
+
+	return nil
+}
+
+// printSelfTest - таблица шагов самопроверки
+func printSelfTest(resp *ssov1.RunSelfTestResponse) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STEP\tSTATUS\tTIME\tREASON\tMESSAGE")
+	for _, s := range resp.GetSteps() {
+		fmt.Fprintf(w, "%s\t%s\t%dms\t%s\t%s\n", s.GetName(), s.GetStatus(), s.GetDurationMs(), s.GetReason(), s.GetMessage())
+	}
+
+	return w.Flush()
+}
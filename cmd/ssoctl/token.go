@@ -0,0 +1,80 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// storedToken - токен, сохранённый командой login
+type storedToken struct {
+	Token     string    `json:"token"`
+	Addr      string    `json:"addr"` // сервер, выдавший токен
+	Email     string    `json:"email"`
+	AppID     int32     `json:"app_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// tokenPath - файл токена в каталоге настроек пользователя
+// (~/.config/ssoctl на Linux, ~/Library/Application Support/ssoctl на macOS, %AppData%\ssoctl на Windows)
+func tokenPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "ssoctl", "token.json"), nil
+}
+
+// saveToken - сохраняет токен; файл доступен только владельцу
+func saveToken(t storedToken) error {
+	path, err := tokenPath()
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// Пишем во временный файл и переименовываем, чтобы не оставить обрезанный токен
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// loadToken - читает сохранённый токен
+func loadToken() (storedToken, error) {
+	path, err := tokenPath()
+	if err != nil {
+		return storedToken{}, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return storedToken{}, fmt.Errorf("%w: no token, run `ssoctl login` or pass -token", errUsage)
+		}
+
+		return storedToken{}, err
+	}
+
+	var t storedToken
+	if err := json.Unmarshal(data, &t); err != nil {
+		return storedToken{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,285 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"golang.org/x/sync/errgroup"
+	"google.golang.org/grpc"
+)
+
+// Операции нагрузки
+const (
+	opRegister = "register"
+	opLogin    = "login"
+	opValidate = "validate"
+)
+
+// ops - операции в порядке вывода
+var ops = []string{opRegister, opLogin, opValidate}
+
+// maxTokens - сколько токенов держится для операций validate
+const maxTokens = 256
+
+// mix - веса операций (flag.Value для -mix)
+type mix map[string]int
+
+func (m mix) String() string {
+	parts := make([]string, 0, len(ops))
+	for _, op := range ops {
+		parts = append(parts, fmt.Sprintf("%s=%d", op, m[op]))
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// Set - разбирает "register=1,login=8,validate=1"; не перечисленные операции получают вес 0
+func (m mix) Set(s string) error {
+	clear(m)
+	for _, part := range strings.Split(s, ",") {
+		op, weight, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || !slices.Contains(ops, op) {
+			return fmt.Errorf("want op=weight with op one of %q, got %q", ops, part)
+		}
+		w, err := strconv.Atoi(weight)
+		if err != nil || w < 0 {
+			return fmt.Errorf("%s: weight must be a non-negative integer, got %q", op, weight)
+		}
+		m[op] = w
+	}
+
+	return nil
+}
+
+func (m mix) total() int {
+	var n int
+	for _, op := range ops {
+		n += m[op]
+	}
+
+	return n
+}
+
+// pick - случайная операция с учётом весов
+func (m mix) pick(r *rand.Rand) string {
+	n := r.IntN(m.total())
+	for _, op := range ops {
+		if n < m[op] {
+			return op
+		}
+		n -= m[op]
+	}
+
+	return ops[len(ops)-1]
+}
+
+// loadTest - состояние одного запуска
+type loadTest struct {
+	client ssov1.AuthClient
+	opts   options
+	runID  string        // часть email, уникальная для запуска
+	next   atomic.Uint64 // номер следующего сгенерированного email
+
+	mu     sync.Mutex
+	emails []string // зарегистрированные пользователи для login
+	tokens []string // токены для validate
+}
+
+func newLoadTest(cc grpc.ClientConnInterface, opts options) *loadTest {
+	return &loadTest{client: ssov1.NewAuthClient(cc), opts: opts, runID: fmt.Sprintf("%08x", rand.Uint32())}
+}
+
+// newEmail - уникальный email для регистрации
+func (lt *loadTest) newEmail() string {
+	return fmt.Sprintf("lt-%s-%d@%s", lt.runID, lt.next.Add(1), lt.opts.emailDomain)
+}
+
+// seed - регистрирует opts.seedUsers пользователей и входит частью из них, чтобы было что проверять
+func (lt *loadTest) seed(ctx context.Context) error {
+	emails := make(chan string)
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		defer close(emails)
+		for range lt.opts.seedUsers {
+			select {
+			case emails <- lt.newEmail():
+			case <-gctx.Done():
+				return gctx.Err()
+			}
+		}
+
+		return nil
+	})
+
+	for range lt.opts.concurrency {
+		g.Go(func() error {
+			for email := range emails {
+				if err := lt.register(gctx, email); err != nil {
+					return fmt.Errorf("register %s: %w", email, err)
+				}
+				if lt.tokenCount() < maxTokens {
+					if err := lt.login(gctx, email); err != nil {
+						return fmt.Errorf("login %s: %w", email, err)
+					}
+				}
+			}
+
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// measure - измеряемая фаза: воркеры выполняют операции до конца opts.duration или остановки по ошибкам
+func (lt *loadTest) measure(ctx context.Context) *report {
+	ctx, cancel := context.WithTimeout(ctx, lt.opts.duration)
+	defer cancel()
+
+	rec := newRecorder()
+	var aborted atomic.Bool
+
+	// доля ошибок проверяется не на каждом запросе, а раз в полсекунды
+	go func() {
+		ticker := time.NewTicker(500 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if total, failed := rec.counts(); total >= int64(lt.opts.minRequests) &&
+					float64(failed)/float64(total) > lt.opts.maxErrorRate {
+					aborted.Store(true)
+					cancel()
+
+					return
+				}
+			}
+		}
+	}()
+
+	started := time.Now()
+	var wg sync.WaitGroup
+	for range lt.opts.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			r := rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64()))
+			for ctx.Err() == nil {
+				op := lt.opts.mix.pick(r)
+
+				begin := time.Now()
+				err := lt.do(ctx, r, op)
+				// запрос, прерванный окончанием теста, не считается ни успехом, ни ошибкой
+				if ctx.Err() != nil && err != nil {
+					return
+				}
+				rec.observe(op, time.Since(begin), err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return rec.report(lt.opts, time.Since(started), aborted.Load())
+}
+
+// do - выполняет одну операцию
+func (lt *loadTest) do(ctx context.Context, r *rand.Rand, op string) error {
+	switch op {
+	case opRegister:
+		return lt.register(ctx, lt.newEmail())
+	case opLogin:
+		email, ok := lt.random(r, &lt.emails)
+		if !ok {
+			return errors.New("no users to log in")
+		}
+
+		return lt.login(ctx, email)
+	default:
+		token, ok := lt.random(r, &lt.tokens)
+		if !ok {
+			return errors.New("no tokens to validate")
+		}
+
+		return lt.validate(ctx, token)
+	}
+}
+
+func (lt *loadTest) register(ctx context.Context, email string) error {
+	ctx, cancel := context.WithTimeout(ctx, lt.opts.timeout)
+	defer cancel()
+
+	if _, err := lt.client.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: lt.opts.password}); err != nil {
+		return err
+	}
+
+	lt.mu.Lock()
+	lt.emails = append(lt.emails, email)
+	lt.mu.Unlock()
+
+	return nil
+}
+
+func (lt *loadTest) login(ctx context.Context, email string) error {
+	ctx, cancel := context.WithTimeout(ctx, lt.opts.timeout)
+	defer cancel()
+
+	resp, err := lt.client.Login(ctx, &ssov1.LoginRequest{
+		Email:    email,
+		Password: lt.opts.password,
+		AppId:    int32(lt.opts.appID),
+	})
+	if err != nil {
+		return err
+	}
+
+	// свежие токены вытесняют случайные старые: истёкшие не копятся
+	lt.mu.Lock()
+	if len(lt.tokens) < maxTokens {
+		lt.tokens = append(lt.tokens, resp.GetToken())
+	} else {
+		lt.tokens[rand.IntN(len(lt.tokens))] = resp.GetToken()
+	}
+	lt.mu.Unlock()
+
+	return nil
+}
+
+func (lt *loadTest) validate(ctx context.Context, token string) error {
+	ctx, cancel := context.WithTimeout(ctx, lt.opts.timeout)
+	defer cancel()
+
+	_, err := lt.client.ValidateToken(ctx, &ssov1.ValidateTokenRequest{Token: token})
+
+	return err
+}
+
+// random - случайный элемент списка пользователей или токенов (false - список пуст)
+func (lt *loadTest) random(r *rand.Rand, list *[]string) (string, bool) {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	if len(*list) == 0 {
+		return "", false
+	}
+
+	return (*list)[r.IntN(len(*list))], true
+}
+
+func (lt *loadTest) tokenCount() int {
+	lt.mu.Lock()
+	defer lt.mu.Unlock()
+
+	return len(lt.tokens)
+}
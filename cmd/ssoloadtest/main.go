@@ -0,0 +1,175 @@
+// ssoloadtest - нагрузочный тест SSO через настоящий gRPC API.
+//
+//	ssoloadtest [-addr host:port] [-tls] [-ca-file ca.pem] [-concurrency 16] [-duration 30s]
+//	            [-mix register=1,login=8,validate=1] [-seed-users 100] [-app-id 1]
+//	            [-max-error-rate 0.2] [-report report.json]
+//
+// Перед измеряемой фазой регистрирует -seed-users пользователей (их входы и проверки токенов и нагружаются),
+// затем -concurrency воркеров выполняют операции в пропорциях -mix в течение -duration. Регистрации
+// получают уникальные email вида lt-<запуск>-<номер>@<домен>, поэтому тест можно повторять на той же БД.
+// В конце печатаются RPS, перцентили задержки и ошибки по кодам gRPC по каждой операции.
+//
+// Если доля ошибок превысила -max-error-rate (после первых -min-requests запросов), тест останавливается
+// с кодом выхода 1, чтобы не нагружать неисправное окружение. Лимиты запросов и CAPTCHA на тестовом
+// стенде стоит выключить: иначе измеряются они, а не сервер
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// errAborted - тест остановлен из-за доли ошибок
+var errAborted = errors.New("aborted: error rate exceeded -max-error-rate")
+
+// options - параметры запуска
+type options struct {
+	addr         string
+	useTLS       bool
+	caFile       string
+	serverName   string
+	concurrency  int
+	duration     time.Duration
+	timeout      time.Duration
+	mix          mix
+	seedUsers    int
+	appID        int
+	password     string
+	emailDomain  string
+	maxErrorRate float64
+	minRequests  int
+	reportFile   string
+}
+
+func main() {
+	opts, err := parseFlags(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(2)
+	}
+
+	if err := run(opts); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+// parseFlags - параметры из аргументов командной строки
+func parseFlags(args []string) (options, error) {
+	fs := flag.NewFlagSet("ssoloadtest", flag.ContinueOnError)
+
+	opts := options{mix: mix{opRegister: 1, opLogin: 8, opValidate: 1}}
+	fs.StringVar(&opts.addr, "addr", envOr("SSO_ADDR", "localhost:44044"), "SSO gRPC address")
+	fs.BoolVar(&opts.useTLS, "tls", false, "use TLS")
+	fs.StringVar(&opts.caFile, "ca-file", "", "PEM file with CA certificates (default system roots)")
+	fs.StringVar(&opts.serverName, "server-name", "", "TLS server name if it differs from the -addr host")
+	fs.IntVar(&opts.concurrency, "concurrency", 16, "parallel workers")
+	fs.DurationVar(&opts.duration, "duration", 30*time.Second, "length of the measured phase")
+	fs.DurationVar(&opts.timeout, "timeout", 10*time.Second, "timeout of a single request")
+	fs.Var(&opts.mix, "mix", "operation weights: register=N,login=N,validate=N")
+	fs.IntVar(&opts.seedUsers, "seed-users", 100, "users registered before the measured phase")
+	fs.IntVar(&opts.appID, "app-id", 1, "app to log in to")
+	fs.StringVar(&opts.password, "password", "Loadtest-Secret-7421", "password of generated users")
+	fs.StringVar(&opts.emailDomain, "email-domain", "loadtest.example.com", "domain of generated emails")
+	fs.Float64Var(&opts.maxErrorRate, "max-error-rate", 0.2, "abort when this fraction of requests fails (0-1)")
+	fs.IntVar(&opts.minRequests, "min-requests", 100, "requests before -max-error-rate is checked")
+	fs.StringVar(&opts.reportFile, "report", "", "write the report as JSON to this file")
+	if err := fs.Parse(args); err != nil {
+		return options{}, err
+	}
+
+	switch {
+	case opts.concurrency <= 0:
+		return options{}, errors.New("-concurrency must be positive")
+	case opts.duration <= 0:
+		return options{}, errors.New("-duration must be positive")
+	case opts.seedUsers <= 0 && (opts.mix[opLogin] > 0 || opts.mix[opValidate] > 0):
+		return options{}, errors.New("-seed-users must be positive when the mix has login or validate")
+	case opts.maxErrorRate <= 0 || opts.maxErrorRate > 1:
+		return options{}, errors.New("-max-error-rate must be in range (0, 1]")
+	case opts.mix.total() == 0:
+		return options{}, errors.New("-mix must have at least one positive weight")
+	}
+
+	return opts, nil
+}
+
+// run - подготовка, измеряемая фаза и отчёт
+func run(opts options) error {
+	cc, err := dial(opts)
+	if err != nil {
+		return err
+	}
+	defer cc.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	lt := newLoadTest(cc, opts)
+
+	fmt.Fprintf(os.Stderr, "seeding %d users...\n", opts.seedUsers)
+	if err := lt.seed(ctx); err != nil {
+		return fmt.Errorf("seed: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "running %s with %d workers, mix %s\n", opts.duration, opts.concurrency, opts.mix.String())
+	rep := lt.measure(ctx)
+
+	rep.print(os.Stdout)
+	if opts.reportFile != "" {
+		if err := rep.writeJSON(opts.reportFile); err != nil {
+			return err
+		}
+	}
+
+	if rep.Aborted {
+		return errAborted
+	}
+
+	return nil
+}
+
+// dial - подключение к серверу; с -tls или -ca-file - по TLS
+func dial(opts options) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+
+	if opts.useTLS || opts.caFile != "" {
+		cfg := &tls.Config{MinVersion: tls.VersionTLS12, ServerName: opts.serverName}
+
+		if opts.caFile != "" {
+			pem, err := os.ReadFile(opts.caFile)
+			if err != nil {
+				return nil, err
+			}
+
+			cfg.RootCAs = x509.NewCertPool()
+			if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("%s: no certificates found", opts.caFile)
+			}
+		}
+
+		creds = credentials.NewTLS(cfg)
+	}
+
+	return grpc.NewClient(opts.addr, grpc.WithTransportCredentials(creds))
+}
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return def
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"google.golang.org/grpc/status"
+)
+
+// recorder - задержки и ошибки измеряемой фазы по операциям
+type recorder struct {
+	total, failed atomic.Int64
+
+	mu        sync.Mutex
+	latencies map[string][]time.Duration // задержки успешных запросов
+	errors    map[string]map[string]int  // операция -> код gRPC -> количество
+}
+
+func newRecorder() *recorder {
+	return &recorder{latencies: map[string][]time.Duration{}, errors: map[string]map[string]int{}}
+}
+
+// observe - учитывает выполненный запрос
+func (r *recorder) observe(op string, elapsed time.Duration, err error) {
+	r.total.Add(1)
+	if err != nil {
+		r.failed.Add(1)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil {
+		r.latencies[op] = append(r.latencies[op], elapsed)
+		return
+	}
+	if r.errors[op] == nil {
+		r.errors[op] = map[string]int{}
+	}
+	r.errors[op][status.Code(err).String()]++
+}
+
+// counts - всего запросов и из них ошибок
+func (r *recorder) counts() (total, failed int64) {
+	return r.total.Load(), r.failed.Load()
+}
+
+// report - итог измеряемой фазы
+type report struct {
+	Target      string         `json:"target"`
+	Concurrency int            `json:"concurrency"`
+	Mix         string         `json:"mix"`
+	Duration    float64        `json:"duration_seconds"`
+	Requests    int64          `json:"requests"`
+	Errors      int64          `json:"errors"`
+	RPS         float64        `json:"rps"`
+	Aborted     bool           `json:"aborted"` // остановлен по -max-error-rate
+	Operations  []operationRep `json:"operations"`
+}
+
+// operationRep - итог по одной операции; задержки в миллисекундах и только по успешным запросам
+type operationRep struct {
+	Name     string         `json:"name"`
+	Requests int            `json:"requests"`
+	RPS      float64        `json:"rps"`
+	P50      float64        `json:"p50_ms"`
+	P90      float64        `json:"p90_ms"`
+	P95      float64        `json:"p95_ms"`
+	P99      float64        `json:"p99_ms"`
+	Max      float64        `json:"max_ms"`
+	Errors   map[string]int `json:"errors,omitempty"` // по кодам gRPC
+}
+
+func (r *recorder) report(opts options, elapsed time.Duration, aborted bool) *report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rep := &report{
+		Target:      opts.addr,
+		Concurrency: opts.concurrency,
+		Mix:         opts.mix.String(),
+		Duration:    elapsed.Seconds(),
+		Aborted:     aborted,
+	}
+	rep.Requests, rep.Errors = r.counts()
+	rep.RPS = float64(rep.Requests) / elapsed.Seconds()
+
+	for _, op := range ops {
+		lat := r.latencies[op]
+		if len(lat) == 0 && len(r.errors[op]) == 0 {
+			continue
+		}
+		slices.Sort(lat)
+
+		o := operationRep{
+			Name:   op,
+			P50:    percentile(lat, 50),
+			P90:    percentile(lat, 90),
+			P95:    percentile(lat, 95),
+			P99:    percentile(lat, 99),
+			Errors: r.errors[op],
+		}
+		if len(lat) > 0 {
+			o.Max = ms(lat[len(lat)-1])
+		}
+		o.Requests = len(lat)
+		for _, n := range o.Errors {
+			o.Requests += n
+		}
+		o.RPS = float64(o.Requests) / elapsed.Seconds()
+		rep.Operations = append(rep.Operations, o)
+	}
+
+	return rep
+}
+
+// percentile - перцентиль p отсортированных задержек в миллисекундах (метод ближайшего ранга)
+func percentile(sorted []time.Duration, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+
+	return ms(sorted[max(rank, 1)-1])
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// print - отчёт в виде таблицы
+func (rep *report) print(w io.Writer) {
+	fmt.Fprintf(w, "target %s, %d workers, %.1fs: %d requests, %d errors, %.1f rps\n",
+		rep.Target, rep.Concurrency, rep.Duration, rep.Requests, rep.Errors, rep.RPS)
+	if rep.Aborted {
+		fmt.Fprintln(w, "ABORTED: error rate exceeded -max-error-rate")
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "op\trequests\trps\tp50 ms\tp90 ms\tp95 ms\tp99 ms\tmax ms\t")
+	for _, o := range rep.Operations {
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t%.1f\t\n",
+			o.Name, o.Requests, o.RPS, o.P50, o.P90, o.P95, o.P99, o.Max)
+	}
+	_ = tw.Flush()
+
+	for _, o := range rep.Operations {
+		codes := make([]string, 0, len(o.Errors))
+		for code := range o.Errors {
+			codes = append(codes, code)
+		}
+		sort.Strings(codes)
+		for _, code := range codes {
+			fmt.Fprintf(w, "%s errors: %s %d\n", o.Name, code, o.Errors[code])
+		}
+	}
+}
+
+// writeJSON - отчёт в файл JSON (-report)
+func (rep *report) writeJSON(path string) error {
+	data, err := json.MarshalIndent(rep, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
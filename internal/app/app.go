@@ -1,33 +1,719 @@
 package app
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
 	"log/slog"
 	grpcapp "sso/internal/app/grpc"
+	httpapp "sso/internal/app/http"
+	"sso/internal/config"
+	admingrpc "sso/internal/grpc/admin"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/grpc/interceptors"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/auditchain"
+	"sso/internal/lib/backlog"
+	"sso/internal/lib/captcha"
+	"sso/internal/lib/errreport"
+	"sso/internal/lib/events"
+	"sso/internal/lib/features"
+	"sso/internal/lib/geoip"
+	"sso/internal/lib/hashpool"
+	"sso/internal/lib/httpx"
+	"sso/internal/lib/janitor"
+	"sso/internal/lib/listenfd"
+	"sso/internal/lib/mail"
+	"sso/internal/lib/maintenance"
+	"sso/internal/lib/pii"
+	"sso/internal/lib/pwned"
+	"sso/internal/lib/ratelimit"
+	"sso/internal/lib/revocations"
+	"sso/internal/lib/schemacheck"
+	"sso/internal/lib/sms"
 	"sso/internal/services/auth"
+	"sso/internal/storage/instrumented"
 	"sso/internal/storage/sqlite"
+	"sso/internal/storage/usercache"
+	"sso/migrations"
+	"sync"
 	"time"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"golang.org/x/sync/errgroup"
 )
 
+// server - сервер, которым управляет App (gRPC, HTTP)
+type server interface {
+	Run() error // блокируется до остановки сервера
+	Stop()      // перестаёт принимать соединения и дожидается активных запросов
+}
+
 // основная структура приложения
 type App struct {
 	GRPCSrv *grpcapp.App
+	HTTPSrv *httpapp.App // nil, если http.port не задан
+
+	log         *slog.Logger
+	logLevel    *slog.LevelVar     // уровень логирования, меняется при перезагрузке конфигурации
+	authService *auth.AuthService  // нужен для применения новых TTL токенов
+	maintenance *maintenance.Mode  // режим обслуживания; перезагрузка конфигурации его не сбрасывает
+	limiter     *ratelimit.Limiter // лимиты запросов gRPC, меняются при перезагрузке конфигурации
+	features    *features.Set      // флаги поведения, меняются при перезагрузке конфигурации
+	cfg         *config.Config     // действующая конфигурация
+
+	storage  *sqlite.Storage
+	servers  []server       // серверы в порядке запуска
+	hooks    []shutdownHook // фоновые компоненты в порядке запуска, закрываются после серверов
+	stopOnce sync.Once      // остановка выполняется ровно один раз
+}
+
+// shutdownHook - функция освобождения ресурса, вызываемая при остановке
+type shutdownHook struct {
+	name  string
+	close func() error
+}
+
+// OnShutdown - регистрирует компонент, который нужно закрыть при остановке приложения.
+// Хуки вызываются после остановки серверов в порядке, обратном регистрации
+func (a *App) OnShutdown(name string, close func() error) {
+	a.hooks = append(a.hooks, shutdownHook{name: name, close: close})
 }
 
 // конструктор
-func New(log *slog.Logger, grpcPort int, storagePath string, tokenTTL time.Duration) *App {
-	// инициализация хранилище (подключаемся)
-	storage, err := sqlite.New(storagePath)
+func New(log *slog.Logger, logLevel *slog.LevelVar, cfg *config.Config) (*App, error) {
+	const op = "app.New"
+
+	// переводы сообщений об ошибках; в local и dev неполный каталог - ошибка запуска,
+	// чтобы новые причины не уходили в прод без перевода
+	messages, err := errinfo.LoadMessages(cfg.GRPC.Locale)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if missing := messages.Missing(); len(missing) > 0 && (cfg.Env == config.EnvLocal || cfg.Env == config.EnvDev) {
+		return nil, fmt.Errorf("%s: error messages without translation: %v", op, missing)
+	}
+
+	// общий пул исходящих HTTP-соединений; таймауты у клиента каждого сервиса свои
+	outbound, err := httpx.NewFactory(cfg.Outbound)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// сертификат слушателей gRPC с TLS; читаем до открытия остальных ресурсов
+	var grpcTLS *tls.Config
+	if cfg.GRPC.TLS.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.GRPC.TLS.CertFile, cfg.GRPC.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		grpcTLS = &tls.Config{Certificates: []tls.Certificate{cert}, MinVersion: tls.VersionTLS12}
+	}
+
+	// параметры сайта для passkey; проверяем до открытия остальных ресурсов
+	var relyingParty *webauthn.WebAuthn
+	if cfg.Passkey.RPID != "" {
+		relyingParty, err = webauthn.New(&webauthn.Config{
+			RPID:          cfg.Passkey.RPID,
+			RPDisplayName: cfg.Passkey.RPName,
+			RPOrigins:     cfg.Passkey.Origins,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// отправка SMS с кодами входа (второй фактор)
+	var smsProvider sms.Provider
+	if cfg.SMS.Driver != config.SMSDriverNone {
+		smsProvider, err = sms.New(cfg.SMS, outbound.Client("sms", cfg.SMS.Twilio.Timeout), log)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// проверка CAPTCHA при регистрации и после неудачных входов
+	var captchaVerifier *captcha.Verifier
+	if cfg.Captcha.Enabled() {
+		captchaVerifier, err = captcha.New(cfg.Captcha, outbound.Client("captcha", cfg.Captcha.Timeout))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	// журнал событий безопасности пишется отдельно от лога приложения
+	auditSink := audit.NewSink(cfg.Audit, log)
+
+	// отправка писем; шаблоны проверяем при старте, а не при первой отправке
+	mailer, err := mail.New(cfg.Mail, log)
+	if err != nil {
+		_ = auditSink.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	templates, err := mail.LoadTemplates(cfg.Mail.TemplatesDir)
+	if err != nil {
+		_ = auditSink.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// инициализация хранилище (подключаемся); с pii_key email и телефоны хранятся зашифрованными
+	var storageOpts []sqlite.Option
+	if cfg.PIIKey != "" {
+		cipher, err := newPIICipher(cfg.PIIKey.Reveal())
+		if err != nil {
+			_ = auditSink.Close()
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		storageOpts = append(storageOpts, sqlite.WithPII(cipher))
+	}
+	// с audit.chain события журнала безопасности связываются цепочкой хешей
+	var chainKey []byte
+	if cfg.Audit.ChainKey != "" {
+		chainKey = []byte(cfg.Audit.ChainKey.Reveal())
+	}
+	if cfg.Audit.Chain {
+		storageOpts = append(storageOpts, sqlite.WithAuditChain(chainKey))
+	}
+	// с replicas горячие чтения идут на реплики
+	if r := cfg.Replicas; len(r.DSNs) > 0 {
+		storageOpts = append(storageOpts, sqlite.WithReplicas(r.DSNs, r.HealthInterval, r.HealthTimeout))
+	}
+
+	// Пустая БД или том только для чтения обнаруживаются до первого запроса
+	migrated, err := sqlite.Bootstrap(context.Background(), cfg.StoragePath, cfg.Migrations.AutoApply)
+	if err != nil {
+		_ = auditSink.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if migrated {
+		log.Info("database schema created", slog.Int64("version", migrations.Latest()))
+	}
+
+	storage, err := sqlite.New(cfg.StoragePath, storageOpts...)
 	if err != nil {
-		panic(err)
+		_ = auditSink.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// БД должна быть зашифрована тем же ключом, что задан в конфигурации (или не зашифрована, если его нет)
+	if err := storage.CheckPII(context.Background()); err != nil {
+		_ = storage.Close()
+		_ = auditSink.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
+	// пулы соединений (основной и реплик) и их здоровье - в /debug/vars
+	sqlite.PublishPoolStats(storage)
+
+	// все обращения к хранилищу идут через метрики (длительность и ошибки по методам)
+	store := instrumented.New(storage, sqlite.IsBusy)
+
+	// поток отзывов для сервисов, кеширующих результат ValidateToken
+	feed := revocations.New(log, store, cfg.Revocations)
+
+	// события безопасности дополнительно сохраняются в БД для выгрузки через Admin.ExportAuditEvents
+	auditLog := audit.New(auditSink)
+	var auditStore *audit.StoreSink
+	if cfg.Audit.Store {
+		auditStore = audit.NewStoreSink(store, cfg.Audit.BufferSize, log)
+		auditLog = auditLog.WithRecorder(auditStore)
+	}
+
+	flags := features.New(cfg.Features)
+
+	authOpts := []auth.Option{
+		auth.WithAudit(auditLog),
+		auth.WithFeatures(flags),
+		auth.WithClaimsEnricher(auth.ChainEnrichers(
+			auth.NewMetadataEnricher(store),
+			auth.NewAppMetadataEnricher(store, cfg.Metadata.TokenClaims),
+			auth.NewGroupsEnricher(store),
+		)),
+		auth.WithMaxTokenSize(cfg.JWT.MaxTokenSize),
+		auth.WithTokenLeeway(cfg.JWT.Leeway),
+		auth.WithPasswordHistory(cfg.Password.HistorySize),
+		auth.WithPasswordMaxAge(cfg.Password.MaxAge),
+		auth.WithHashPool(hashpool.New(cfg.Password.Hashing.Workers, cfg.Password.Hashing.QueueDepth)),
+		auth.WithDomainPolicy(auth.NewDomainPolicy(cfg.Registration.AllowedDomains, cfg.Registration.BlockedDomains)),
+		auth.WithOrganizations(store, cfg.Organizations.EmailUniqueness == config.EmailUniqueOrg),
+		auth.WithInvitations(store, cfg.Invitations.TTL, cfg.Invitations.AcceptURL),
+		auth.WithConsents(store),
+		auth.WithRevocationLog(store, feed.Notify),
+		auth.WithTokenDenylist(store),
+		auth.WithAppMetadata(store),
+		auth.WithTOS(store, auth.TOSPolicy{Version: cfg.TOS.Version, EnforceOnLogin: cfg.TOS.EnforceOnLogin}),
+	}
+	if !cfg.Sessions.Disabled {
+		authOpts = append(authOpts, auth.WithSessions(store))
+	}
+
+	// страна и сеть клиента для истории входов; без баз история ведётся без них
+	var (
+		geoDB *geoip.DB
+		geo   auth.GeoLocator
+	)
+	if cfg.Geo.Enabled() {
+		geoDB, err = geoip.Open(log, cfg.Geo)
+		if err != nil {
+			if auditStore != nil {
+				_ = auditStore.Close()
+			}
+			_ = storage.Close()
+			_ = auditSink.Close()
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		geo = geoDB
+	}
+	authOpts = append(authOpts, auth.WithLoginHistory(store, auth.LoginHistorySettings{
+		Geo:           geo,
+		HistoryDepth:  cfg.Geo.HistoryDepth,
+		RequireStepUp: cfg.Geo.RequireStepUp,
+	}))
+	if cfg.LoginAlerts.Enabled {
+		authOpts = append(authOpts, auth.WithLoginAlerts(auth.LoginAlertSettings{
+			SessionsURL: cfg.LoginAlerts.SessionsURL,
+			Interval:    cfg.LoginAlerts.Interval,
+		}))
+	}
+
+	// janitor удаляет устаревшие записи тех подсистем, что включены
+	retention := cfg.Revocations.Retention
+	loginRetention := cfg.Geo.Retention
+	cleanup := []janitor.Task{{
+		Name: "expired sessions",
+		Run: func(ctx context.Context, limit int) (int, error) {
+			return store.DeleteExpiredSessions(ctx, time.Now(), limit)
+		},
+	}, {
+		Name: "expired offline tokens",
+		Run: func(ctx context.Context, limit int) (int, error) {
+			return store.DeleteExpiredOfflineTokens(ctx, time.Now(), limit)
+		},
+	}, {
+		Name: "old revocations",
+		Run: func(ctx context.Context, limit int) (int, error) {
+			return store.DeleteRevocationsBefore(ctx, time.Now().Add(-retention), limit)
+		},
+	}, {
+		Name: "expired denied tokens",
+		Run: func(ctx context.Context, limit int) (int, error) {
+			return store.DeleteExpiredDeniedTokens(ctx, time.Now(), limit)
+		},
+	}, {
+		Name: "old login history",
+		Run: func(ctx context.Context, limit int) (int, error) {
+			return store.DeleteLoginsBefore(ctx, time.Now().Add(-loginRetention), limit)
+		},
+	}, {
+		Name: "old login failures",
+		Run: func(ctx context.Context, limit int) (int, error) {
+			return store.DeleteLoginFailuresBefore(ctx, time.Now().Add(-loginRetention), limit)
+		},
+	}}
+	if cfg.Audit.Store {
+		auditRetention := cfg.Audit.Retention
+		cleanup = append(cleanup, janitor.Task{
+			Name: "old audit events",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteAuditEventsBefore(ctx, time.Now().Add(-auditRetention), limit)
+			},
+		})
+	}
+	if d := cfg.AccountDeletion; d.Enabled {
+		authOpts = append(authOpts, auth.WithAccountDeletion(store, d.GracePeriod, d.CancelURL))
+	}
+	if o := cfg.OfflineTokens; o.Enabled {
+		authOpts = append(authOpts, auth.WithOfflineTokens(store, auth.OfflineTokenSettings{
+			TTL:       o.TTL,
+			Scopes:    o.Scopes,
+			MFAMaxAge: o.MFAMaxAge,
+		}))
+	}
+	if r := cfg.Refresh; r.Enabled {
+		authOpts = append(authOpts, auth.WithRefreshTokens(store, auth.RefreshSettings{
+			SlidingWindow: r.SlidingWindow,
+			MaxLifetime:   r.MaxLifetime,
+		}))
+		cleanup = append(cleanup, janitor.Task{
+			Name: "expired refresh tokens",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteExpiredRefreshFamilies(ctx, time.Now(), limit)
+			},
+		})
+	}
+	if cfg.Provisioning.Enabled {
+		authOpts = append(authOpts, auth.WithProvisioning(store))
+	}
+	if cfg.UserApps.Enabled {
+		authOpts = append(authOpts, auth.WithUserApps(store, cfg.UserApps.RequireAppOnRegister))
+	}
+	if cfg.SelfTest.Enabled {
+		authOpts = append(authOpts, auth.WithSelfTest(store))
+
+		staleAfter := cfg.SelfTest.StaleAfter
+		cleanup = append(cleanup, janitor.Task{
+			Name: "stale self-test data",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteStaleSelfTestData(ctx, time.Now().Add(-staleAfter), limit)
+			},
+		})
+	}
+
+	// кеш пользователей на пути проверки токенов; изменения прав через Admin и UpgradeGuest сбрасывают его сразу
+	var users *usercache.Cache
+	if cfg.JWT.UserCacheTTL > 0 {
+		users = usercache.New(store, cfg.JWT.UserCacheTTL)
+	}
+
+	if cfg.Guests.Enabled {
+		var guests auth.GuestStore = store
+		if users != nil {
+			guests = invalidatingGuests{GuestStore: store, users: users}
+		}
+		authOpts = append(authOpts, auth.WithGuests(guests, cfg.Guests.TokenTTL))
+
+		inactiveAfter := cfg.Guests.InactiveAfter
+		cleanup = append(cleanup, janitor.Task{
+			Name: "inactive guests",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteInactiveGuests(ctx, time.Now().Add(-inactiveAfter), limit)
+			},
+		})
+	}
+	// токены одного действия подписываются ключом сервиса; без него выдавать их нельзя
+	if key := cfg.JWT.SigningKey.Reveal(); key != "" {
+		authOpts = append(authOpts, auth.WithActionTokens(store, []byte(key)))
+		cleanup = append(cleanup, janitor.Task{
+			Name: "expired action tokens",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteExpiredActionTokens(ctx, time.Now(), limit)
+			},
+		})
+	}
+
+	if cfg.Device.VerificationURI != "" {
+		authOpts = append(authOpts, auth.WithDeviceAuthorization(store, auth.DeviceSettings{
+			VerificationURI: cfg.Device.VerificationURI,
+			CodeTTL:         cfg.Device.CodeTTL,
+			PollInterval:    cfg.Device.PollInterval,
+		}))
+		cleanup = append(cleanup, janitor.Task{
+			Name: "expired device authorizations",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteExpiredDeviceAuthorizations(ctx, time.Now(), limit)
+			},
+		})
+	}
+
+	if relyingParty != nil {
+		authOpts = append(authOpts, auth.WithPasskeys(store, relyingParty, auth.PasskeySettings{
+			ChallengeTTL: cfg.Passkey.ChallengeTTL,
+			MaxPerUser:   cfg.Passkey.MaxPerUser,
+		}))
+		cleanup = append(cleanup, janitor.Task{
+			Name: "expired webauthn sessions",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteExpiredWebAuthnSessions(ctx, time.Now(), limit)
+			},
+		})
+	}
+
+	if smsProvider != nil {
+		authOpts = append(authOpts, auth.WithSMS(store, smsProvider, auth.SMSSettings{
+			ChallengeTTL:     cfg.SMS.ChallengeTTL,
+			CodeTTL:          cfg.SMS.CodeTTL,
+			MaxAttempts:      cfg.SMS.MaxAttempts,
+			ResendInterval:   cfg.SMS.ResendInterval,
+			SendWindow:       cfg.SMS.SendWindow,
+			MaxSendsPerUser:  cfg.SMS.MaxSendsPerUser,
+			MaxSendsPerPhone: cfg.SMS.MaxSendsPerPhone,
+		}))
+		cleanup = append(cleanup, janitor.Task{
+			Name: "expired sms challenges",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteExpiredSMSChallenges(ctx, time.Now(), limit)
+			},
+		}, janitor.Task{
+			Name: "old sms sends",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteSMSSendsBefore(ctx, time.Now().Add(-cfg.SMS.SendWindow), limit)
+			},
+		})
+	}
+
+	if captchaVerifier != nil {
+		authOpts = append(authOpts, auth.WithCaptcha(captchaVerifier, auth.CaptchaSettings{
+			LoginFailures: cfg.Captcha.LoginFailures,
+			FailureWindow: cfg.Captcha.FailureWindow,
+			FailOpen:      cfg.Captcha.FailOpen,
+		}))
+	}
+
+	if cfg.HTTP.OIDC.Enabled {
+		authOpts = append(authOpts, auth.WithOIDC(store, auth.OIDCSettings{
+			Issuer:  cfg.HTTP.OIDC.Issuer,
+			CodeTTL: cfg.HTTP.OIDC.CodeTTL,
+		}))
+		cleanup = append(cleanup, janitor.Task{
+			Name: "expired authorization codes",
+			Run: func(ctx context.Context, limit int) (int, error) {
+				return store.DeleteExpiredAuthCodes(ctx, time.Now(), limit)
+			},
+		})
+	}
+
+	var grpcStorage grpcapp.Storage = store
+	if users != nil {
+		authOpts = append(authOpts, auth.WithValidationCache(users))
+		grpcStorage = invalidatingStorage{Storage: store, users: users}
+	}
+
+	if cfg.Password.BreachCheck.Enabled {
+		client := outbound.Client("pwned", cfg.Password.BreachCheck.Timeout)
+		authOpts = append(authOpts, auth.WithBreachChecker(pwned.New(cfg.Password.BreachCheck, client, log)))
+	}
+
+	var publisher *events.NATS
+	if cfg.Events.Driver == config.EventsDriverNATS {
+		publisher, err = events.NewNATS(cfg.Events.NATS)
+		if err != nil {
+			if auditStore != nil {
+				_ = auditStore.Close()
+			}
+			_ = storage.Close()
+			_ = auditSink.Close()
+			if geoDB != nil {
+				_ = geoDB.Close()
+			}
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	var relay *events.Relay
+	switch {
+	case publisher != nil && cfg.Events.Outbox.Enabled:
+		authOpts = append(authOpts, auth.WithOutbox(events.NewOutboxWriter(store), store))
+		relay = events.NewRelay(log, store, publisher, cfg.Events.Outbox.PollInterval, cfg.Events.Outbox.BatchSize)
+	case publisher != nil:
+		authOpts = append(authOpts, auth.WithEventPublisher(publisher))
+	}
+
+	// письма отправляются в фоне с повторами, ошибки доставки не доходят до пользователя
+	mailQueue := mail.NewQueue(mailer, log, cfg.Mail.QueueSize, cfg.Mail.MaxAttempts, cfg.Mail.RetryBackoff)
+	authOpts = append(authOpts, auth.WithMailer(mail.NewSender(mailQueue, templates)))
+
 	// инициализация сервиса авторизации
-	authService := auth.New(log, storage, storage, storage, tokenTTL)
+	authService := auth.New(log, store, store, store, cfg.TokenTTL, authOpts...)
 
-	// инициализация grpc сервиса 
-	grpcApp := grpcapp.New(log, authService, grpcPort)
+	// аккаунты, удаление которых запросили сами пользователи, обезличиваются по истечении срока отмены
+	if cfg.AccountDeletion.Enabled {
+		cleanup = append(cleanup, janitor.Task{
+			Name: "due account deletions",
+			Run:  authService.ExecuteDueDeletions,
+		})
+	}
 
-	return &App{
-		GRPCSrv: grpcApp,
+	// инициализация grpc сервиса
+	// режим обслуживания включается во время работы (Admin.SetMaintenance, SIGUSR1)
+	mode := maintenance.New(cfg.Maintenance)
+	limiter := ratelimit.New(cfg.RateLimit)
+	// пороги SLO ссылаются на методы по имени: опечатка - ошибка запуска, а не метод без счётчиков
+	slo, err := interceptors.NewSLO(cfg.GRPC.SLO)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// паники и ответы INTERNAL уходят в трекер ошибок, если задан его DSN
+	reporter := errreport.Nop()
+	var sentry *errreport.Sentry
+	if cfg.ErrorReporting.DSN != "" {
+		sentry, err = errreport.NewSentry(cfg.ErrorReporting, cfg.Env, outbound.Client("sentry", cfg.ErrorReporting.Timeout), log)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		reporter = sentry
+	}
+
+	var auditChain admingrpc.AuditChainVerifier // nil - Admin.VerifyAuditChain отвечает FAILED_PRECONDITION
+	if cfg.Audit.Chain {
+		auditChain = auditchain.New(store, chainKey)
+	}
+
+	var appScope admingrpc.AppScope // nil - пользователи приложениям не принадлежат
+	if cfg.UserApps.Enabled {
+		appScope = store
+	}
+
+	grpcApp := grpcapp.New(log, authService, authService, grpcStorage, feed, mode, limiter, slo, reporter, messages, cfg.GRPC, grpcTLS, cfg.Backup.Dir, auditChain, appScope, flags)
+
+	// версия схемы БД сверяется с последней встроенной миграцией: на старой схеме health сообщает NOT_SERVING
+	schema := schemacheck.New(store, migrations.Latest(), cfg.Schema, log, grpcApp.SetServing)
+	schema.Check(context.Background())
+
+	// поток отзывов останавливается раньше gRPC-сервера, иначе тот ждал бы завершения бесконечных потоков
+	a := &App{
+		GRPCSrv:     grpcApp,
+		log:         log,
+		logLevel:    logLevel,
+		authService: authService,
+		cfg:         cfg,
+		storage:     storage,
+		maintenance: mode,
+		limiter:     limiter,
+		features:    flags,
+		servers:     []server{grpcApp, feed, schema},
+	}
+
+	// relay останавливается после серверов; неотправленные события остаются в outbox до следующего запуска
+	if relay != nil {
+		a.servers = append([]server{relay}, a.servers...)
+	}
+
+	// показатели отставания в /debug/vars: только для подсистем, которые запущены
+	var backlogOpts []backlog.Option
+	if relay != nil {
+		backlogOpts = append(backlogOpts, backlog.WithOutbox(store))
+	}
+	if len(cleanup) > 0 {
+		cleaner := janitor.New(log, cfg.Janitor.Interval, cfg.Janitor.BatchSize, cleanup...)
+		a.servers = append(a.servers, cleaner)
+		backlogOpts = append(backlogOpts, backlog.WithJanitor(cleaner))
+	}
+	backlog.Publish(backlog.New(store, backlog.Settings{
+		CacheTTL:     cfg.Metrics.CacheTTL,
+		QueryTimeout: cfg.Metrics.QueryTimeout,
+		OutboxMaxAge: cfg.Metrics.OutboxMaxAge,
+	}, backlogOpts...))
+
+	// Журнал безопасности закрывается последним, чтобы в него попали события всех остальных компонентов
+	a.OnShutdown("audit sink", auditSink.Close)
+	a.OnShutdown("storage", storage.Close)
+	if auditStore != nil {
+		a.OnShutdown("audit store", auditStore.Close)
+	}
+	a.OnShutdown("mail queue", mailQueue.Close)
+	if geoDB != nil {
+		a.OnShutdown("geoip", geoDB.Close)
 	}
+	if publisher != nil {
+		a.OnShutdown("event publisher", publisher.Close)
+	}
+	// отчёты об ошибках остановки серверов тоже должны уйти, но не дольше error_reporting.flush_timeout
+	if sentry != nil {
+		a.OnShutdown("error reporter", sentry.Close)
+	}
+
+	// служебный HTTP-сервер (pprof, отладка, JSON-шлюз)
+	if cfg.HTTP.Port != 0 {
+		a.HTTPSrv = httpapp.New(log, cfg.HTTP, store, authService, store, authService, mode, schema)
+		a.servers = append(a.servers, a.HTTPSrv)
+	}
+
+	return a, nil
+}
+
+// ToggleMaintenance - переключает режим обслуживания (SIGUSR1)
+func (a *App) ToggleMaintenance() {
+	state := a.maintenance.Toggle("SIGUSR1")
+
+	a.log.Warn("maintenance mode toggled by signal", slog.Bool("enabled", state.Enabled))
+}
+
+// InheritListeners - gRPC-серверы слушают сокеты, унаследованные от предыдущего процесса или systemd,
+// вместо открытия своих адресов; вызывается до Run
+func (a *App) InheritListeners(set *listenfd.Set) {
+	a.GRPCSrv.Inherit(set)
+}
+
+// Run - запускает все серверы и блокируется, пока не будет отменён ctx или один из серверов
+// не завершится с ошибкой (например, не удалось занять порт). В обоих случаях остальные серверы
+// останавливаются, а ресурсы освобождаются. Возвращает первую фатальную ошибку
+func (a *App) Run(ctx context.Context) error {
+	g, gctx := errgroup.WithContext(ctx)
+
+	for _, srv := range a.servers {
+		g.Go(srv.Run)
+	}
+
+	// Как только ctx отменён или какой-то сервер упал - останавливаем всё
+	g.Go(func() error {
+		<-gctx.Done()
+		a.log.Info("stopping application")
+		a.Stop()
+
+		return nil
+	})
+
+	return g.Wait()
+}
+
+// Stop - останавливает приложение в строгом порядке: серверы перестают принимать соединения
+// и дожидаются активных запросов (в порядке, обратном запуску), затем в обратном порядке
+// вызываются хуки остановки (хранилище, затем журнал безопасности).
+// Повторные вызовы ничего не делают
+func (a *App) Stop() {
+	a.stopOnce.Do(func() {
+		for i := len(a.servers) - 1; i >= 0; i-- {
+			a.servers[i].Stop()
+		}
+
+		for i := len(a.hooks) - 1; i >= 0; i-- {
+			h := a.hooks[i]
+			if err := h.close(); err != nil {
+				a.log.Error("failed to close "+h.name, slog.String("error", err.Error()))
+			}
+		}
+	})
+}
+
+// invalidatingStorage - хранилище для обработчиков gRPC, сбрасывающее кеш проверки токенов
+// после изменения прав пользователя
+type invalidatingStorage struct {
+	grpcapp.Storage
+	users *usercache.Cache
+}
+
+func (s invalidatingStorage) SetAdmin(ctx context.Context, userID int64, isAdmin bool, expectedVersion int64) (int64, error) {
+	version, err := s.Storage.SetAdmin(ctx, userID, isAdmin, expectedVersion)
+	if err != nil {
+		return 0, err
+	}
+	s.users.Invalidate(userID)
+
+	return version, nil
+}
+
+// invalidatingGuests - гостевые пользователи, сбрасывающие кеш проверки токенов после UpgradeGuest:
+// иначе кеш ещё TTL отдавал бы флаг гостя уже обычному пользователю
+type invalidatingGuests struct {
+	auth.GuestStore
+	users *usercache.Cache
+}
+
+func (g invalidatingGuests) UpgradeGuest(ctx context.Context, userID int64, email string, passHash []byte) error {
+	if err := g.GuestStore.UpgradeGuest(ctx, userID, email, passHash); err != nil {
+		return err
+	}
+	g.users.Invalidate(userID)
+
+	return nil
+}
+
+// newPIICipher - шифр email и телефонов из pii_key (base64 от pii.KeySize байт)
+func newPIICipher(key string) (*pii.Cipher, error) {
+	raw, err := pii.ParseKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("pii_key: %w", err)
+	}
+
+	return pii.New(raw)
 }
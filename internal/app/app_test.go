@@ -0,0 +1,214 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"sso/internal/config"
+	"sso/internal/lib/listenfd"
+	"sso/internal/storage/sqlite"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// fakeServer - сервер, который работает до Stop или сразу возвращает runErr
+type fakeServer struct {
+	name   string
+	runErr error
+	calls  *callLog
+
+	stopped chan struct{}
+	once    sync.Once
+}
+
+func newFakeServer(name string, calls *callLog) *fakeServer {
+	return &fakeServer{name: name, calls: calls, stopped: make(chan struct{})}
+}
+
+func (s *fakeServer) Run() error {
+	if s.runErr != nil {
+		return s.runErr
+	}
+	<-s.stopped
+
+	return nil
+}
+
+func (s *fakeServer) Stop() {
+	s.calls.add("stop " + s.name)
+	s.once.Do(func() { close(s.stopped) })
+}
+
+// callLog - потокобезопасный журнал вызовов
+type callLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *callLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.calls = append(l.calls, call)
+}
+
+func (l *callLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.calls...)
+}
+
+func newTestApp(calls *callLog, servers ...server) *App {
+	a := &App{
+		log:     slog.New(slog.NewTextHandler(io.Discard, nil)),
+		servers: servers,
+	}
+
+	a.OnShutdown("audit sink", func() error {
+		calls.add("flush")
+		return nil
+	})
+	a.OnShutdown("storage", func() error {
+		calls.add("close storage")
+		return errors.New("close failed") // ошибка хука не мешает остальным
+	})
+
+	return a
+}
+
+func TestRun_StopsOnContextCancel(t *testing.T) {
+	calls := &callLog{}
+	a := newTestApp(calls, newFakeServer("grpc", calls), newFakeServer("http", calls))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx) }()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after context cancel")
+	}
+
+	// Серверы останавливаются в обратном порядке, затем хранилище, логи сбрасываются последними
+	assert.Equal(t, []string{"stop http", "stop grpc", "close storage", "flush"}, calls.get())
+}
+
+func TestRun_ReturnsServerError(t *testing.T) {
+	calls := &callLog{}
+	failing := newFakeServer("http", calls)
+	failing.runErr = errors.New("bind failed")
+	a := newTestApp(calls, newFakeServer("grpc", calls), failing)
+
+	done := make(chan error, 1)
+	go func() { done <- a.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, failing.runErr)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run hung after a server failed")
+	}
+
+	assert.Equal(t, []string{"stop http", "stop grpc", "close storage", "flush"}, calls.get())
+
+	// Повторная остановка ничего не делает: хранилище закрывается ровно один раз
+	a.Stop()
+	assert.Len(t, calls.get(), 4)
+}
+
+// runConfig - минимальная конфигурация для запуска App с gRPC на port
+func runConfig(t *testing.T, port int) *config.Config {
+	t.Helper()
+
+	return &config.Config{
+		Env:         config.EnvLocal,
+		StoragePath: filepath.Join(t.TempDir(), "sso.db"),
+		TokenTTL:    time.Hour,
+		GRPC:        config.GRPCConfig{Port: port, Locale: "en"},
+		Audit:       config.AuditConfig{Output: config.AuditOutputStdout, BufferSize: 10},
+		Janitor:     config.JanitorConfig{Interval: time.Hour, BatchSize: 500},
+		Revocations: config.RevocationsConfig{
+			PollInterval: time.Second, KeepaliveInterval: 30 * time.Second, BufferSize: 256, Retention: time.Hour,
+		},
+		Maintenance: config.MaintenanceConfig{RetryAfter: 30 * time.Second},
+		Geo:         config.GeoConfig{CheckInterval: time.Minute, HistoryDepth: 20, Retention: time.Hour},
+		Schema:      config.SchemaConfig{CheckInterval: 30 * time.Second},
+		Migrations:  config.MigrationsConfig{AutoApply: true},
+	}
+}
+
+func TestNew_SchemaMissing(t *testing.T) {
+	cfg := runConfig(t, 0)
+	cfg.Migrations.AutoApply = false
+
+	_, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), new(slog.LevelVar), cfg)
+	require.ErrorIs(t, err, sqlite.ErrSchemaMissing)
+	assert.ErrorContains(t, err, "migrator --storage-path=")
+}
+
+func TestRun_PortInUse(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	cfg := runConfig(t, l.Addr().(*net.TCPAddr).Port)
+
+	a, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), new(slog.LevelVar), cfg)
+	require.NoError(t, err)
+
+	done := make(chan error, 1)
+	go func() { done <- a.Run(context.Background()) }()
+
+	select {
+	case err := <-done:
+		require.ErrorContains(t, err, fmt.Sprintf(":%d", cfg.GRPC.Port))
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run hung although the gRPC port is taken")
+	}
+}
+
+// Унаследованный сокет занятого порта используется вместо нового: порт не освобождался на время перезапуска
+func TestRun_InheritedListener(t *testing.T) {
+	l, err := net.Listen("tcp", ":0")
+	require.NoError(t, err)
+
+	cfg := runConfig(t, l.Addr().(*net.TCPAddr).Port)
+
+	a, err := New(slog.New(slog.NewTextHandler(io.Discard, nil)), new(slog.LevelVar), cfg)
+	require.NoError(t, err)
+	a.InheritListeners(listenfd.New(l))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- a.Run(ctx) }()
+
+	conn, err := grpc.NewClient(l.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Ответ health (статус зависит от схемы БД) означает, что сервер принимает соединения на сокете
+	_, err = healthgrpc.NewHealthClient(conn).Check(ctx, &healthgrpc.HealthCheckRequest{}, grpc.WaitForReady(true))
+	require.NoError(t, err)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not stop")
+	}
+}
@@ -1,35 +1,314 @@
 package grpcapp
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
+	"net/netip"
+	"slices"
+	"sso/internal/config"
+	admingrpc "sso/internal/grpc/admin"
 	authgrpc "sso/internal/grpc/auth"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/grpc/interceptors"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/errreport"
+	"sso/internal/lib/listenfd"
+	"sso/internal/lib/maintenance"
+	"sso/internal/lib/proxyproto"
+	"sso/internal/lib/ratelimit"
+	"sync"
 
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+// Пределы размера запроса. Самые крупные сообщения - credential_json passkey и метаданные
+// пользователя (несколько КБ); ответы (выгрузки, Backup) не ограничиваются
+const (
+	maxRecvMsgSize    = 256 << 10 // сообщение целиком (по умолчанию в gRPC - 4 МБ)
+	maxHeaderListSize = 16 << 10  // метаданные запроса: bearer-токен, User-Agent, X-Forwarded-For
 )
 
 // App - структура, представляющая приложение
 type App struct {
-	log        *slog.Logger  // Логгер для записи событий
-	gRPCServer *grpc.Server  // Экземпляр gRPC-сервера
-	port       int           // Порт, на котором работает gRPC-сервер
+	log     *slog.Logger   // Логгер для записи событий
+	servers []*server      // gRPC-серверы; слушатели с одинаковыми сервисами и TLS делят один сервер
+	trusted []netip.Prefix // балансировщики, которым верим заголовок PROXY protocol
+
+	inherited *listenfd.Set // сокеты от родительского процесса (systemd, --inherit-listener)
+}
+
+// server - gRPC-сервер и адреса, на которых он слушает
+type server struct {
+	grpc     *grpc.Server
+	health   *health.Server // nil - сервис health на этих слушателях не зарегистрирован
+	addrs    []string
+	proxied  map[string]bool // адреса, на которых принимается заголовок PROXY protocol
+	services []string
+	tls      bool
+}
+
+// Service - сервисный слой, который вызывают обработчики Auth и приглашения в Admin
+type Service interface {
+	authgrpc.Auth
+	admingrpc.Inviter
+	admingrpc.TOSReporter
+	admingrpc.TokenAdmin
+	admingrpc.SelfTester
+}
+
+// Storage - то, что обработчики берут напрямую из хранилища, минуя сервисный слой
+type Storage interface {
+	authgrpc.SchemaProvider
+	admingrpc.UserAdmin
+	admingrpc.AppAdmin
+	admingrpc.OrgAdmin
+	admingrpc.GroupAdmin
+	admingrpc.Backuper
+	admingrpc.StatsProvider
+	admingrpc.AuditExporter
+}
+
+// accessPolicy - какие методы требуют токена или прав администратора (остальные публичны).
+// Все методы сервиса Admin по умолчанию требуют прав администратора, кроме методов организаций:
+// их права (суперадминистратор или администратор организации) проверяет обработчик. Так же ListUsers, GetUser
+// и GetStats доступны администратору приложения без глобальных прав: приложение запроса проверяет обработчик.
+// Гостям недоступны методы администрирования, согласий, условий использования, входа на устройстве, passkey,
+// offline-токенов и телефона (AccessRegistered). Методы провижининга токена не требуют: приложение
+// с ролью provisioner подтверждает себя секретом, его проверяет сервис
+var accessPolicy = interceptors.Policy{
+	Methods: map[string]interceptors.Access{
+		ssov1.Auth_GetUsersBatch_FullMethodName:             interceptors.AccessAdmin,
+		ssov1.Auth_WatchRevocations_FullMethodName:          interceptors.AccessAdmin,         // для сервисов-потребителей
+		ssov1.Auth_ExportUserData_FullMethodName:            interceptors.AccessAuthenticated, // свои данные или администратор
+		ssov1.Auth_EraseUser_FullMethodName:                 interceptors.AccessAdmin,
+		ssov1.Auth_GrantConsent_FullMethodName:              interceptors.AccessRegistered, // свои согласия или администратор
+		ssov1.Auth_ListConsents_FullMethodName:              interceptors.AccessRegistered,
+		ssov1.Auth_RevokeConsent_FullMethodName:             interceptors.AccessRegistered,
+		ssov1.Auth_AcceptTOS_FullMethodName:                 interceptors.AccessRegistered,    // свои условия или администратор
+		ssov1.Auth_RequestAccountDeletion_FullMethodName:    interceptors.AccessRegistered,    // свой аккаунт или администратор
+		ssov1.Auth_GetUserMetadata_FullMethodName:           interceptors.AccessAuthenticated, // метаданные приложения токена
+		ssov1.Auth_UpdateUserMetadata_FullMethodName:        interceptors.AccessAuthenticated,
+		ssov1.Auth_ApproveDevice_FullMethodName:             interceptors.AccessRegistered, // решение принимает вошедший пользователь
+		ssov1.Auth_DenyDevice_FullMethodName:                interceptors.AccessRegistered,
+		ssov1.Auth_BeginPasskeyRegistration_FullMethodName:  interceptors.AccessRegistered, // passkey вошедшего пользователя
+		ssov1.Auth_FinishPasskeyRegistration_FullMethodName: interceptors.AccessRegistered,
+		ssov1.Auth_ListPasskeys_FullMethodName:              interceptors.AccessRegistered,
+		ssov1.Auth_DeletePasskey_FullMethodName:             interceptors.AccessRegistered,
+		ssov1.Auth_IssueOfflineToken_FullMethodName:         interceptors.AccessRegistered, // свои offline-токены или администратор
+		ssov1.Auth_ListOfflineTokens_FullMethodName:         interceptors.AccessRegistered,
+		ssov1.Auth_RevokeOfflineToken_FullMethodName:        interceptors.AccessRegistered,
+		ssov1.Auth_StartPhoneVerification_FullMethodName:    interceptors.AccessRegistered, // номер вошедшего пользователя
+		ssov1.Auth_ConfirmPhone_FullMethodName:              interceptors.AccessRegistered,
+		ssov1.Auth_RemovePhone_FullMethodName:               interceptors.AccessRegistered,
+
+		ssov1.Admin_CreateOrganization_FullMethodName: interceptors.AccessRegistered,
+		ssov1.Admin_AddMember_FullMethodName:          interceptors.AccessRegistered,
+		ssov1.Admin_RemoveMember_FullMethodName:       interceptors.AccessRegistered,
+		ssov1.Admin_ListMembers_FullMethodName:        interceptors.AccessRegistered,
+
+		ssov1.Admin_ListUsers_FullMethodName: interceptors.AccessAppAdmin,
+		ssov1.Admin_GetUser_FullMethodName:   interceptors.AccessAppAdmin,
+		ssov1.Admin_GetStats_FullMethodName:  interceptors.AccessAppAdmin,
+	},
+	Services: map[string]interceptors.Access{
+		ssov1.Admin_ServiceDesc.ServiceName: interceptors.AccessAdmin,
+	},
+}
+
+// appScopedPolicy - accessPolicy при user_apps.enabled. Администратор приложения ограничен своими приложениями,
+// поэтому всё, что затрагивает пользователей любых приложений или весь сервис (остальные методы Admin,
+// GetUsersBatch, EraseUser, WatchRevocations), доступно только суперадминистратору
+func appScopedPolicy() interceptors.Policy {
+	p := interceptors.Policy{Methods: maps.Clone(accessPolicy.Methods), Services: maps.Clone(accessPolicy.Services)}
+	p.Services[ssov1.Admin_ServiceDesc.ServiceName] = interceptors.AccessSuperadmin
+	for _, method := range []string{
+		ssov1.Auth_GetUsersBatch_FullMethodName,
+		ssov1.Auth_EraseUser_FullMethodName,
+		ssov1.Auth_WatchRevocations_FullMethodName,
+	} {
+		p.Methods[method] = interceptors.AccessSuperadmin
+	}
+
+	return p
+}
+
+// readOnly - методы, которые работают в режиме обслуживания: проверка токенов, чтение
+// и выключение самого режима. Вход (Login, StepUp, Refresh, вход по passkey и коду из SMS) работает, если не запрещён конфигурацией
+var readOnly = interceptors.ReadOnly{
+	Methods: map[string]bool{
+		ssov1.Auth_IsAdmin_FullMethodName:           true,
+		ssov1.Auth_IsUserExists_FullMethodName:      true,
+		ssov1.Auth_GetServerInfo_FullMethodName:     true,
+		ssov1.Auth_GetUsersBatch_FullMethodName:     true,
+		ssov1.Auth_ExportUserData_FullMethodName:    true,
+		ssov1.Auth_ValidateToken_FullMethodName:     true,
+		ssov1.Auth_ListConsents_FullMethodName:      true,
+		ssov1.Auth_GetUserMetadata_FullMethodName:   true,
+		ssov1.Auth_WatchRevocations_FullMethodName:  true,
+		ssov1.Auth_ListPasskeys_FullMethodName:      true,
+		ssov1.Auth_ListOfflineTokens_FullMethodName: true,
+
+		ssov1.Auth_ListProvisionedUsers_FullMethodName: true,
+
+		healthgrpc.Health_Check_FullMethodName:                                 true,
+		healthgrpc.Health_Watch_FullMethodName:                                 true,
+		reflectionv1.ServerReflection_ServerReflectionInfo_FullMethodName:      true,
+		reflectionv1alpha.ServerReflection_ServerReflectionInfo_FullMethodName: true,
+
+		ssov1.Admin_ListUsers_FullMethodName:            true,
+		ssov1.Admin_GetUser_FullMethodName:              true,
+		ssov1.Admin_Backup_FullMethodName:               true,
+		ssov1.Admin_ListMembers_FullMethodName:          true,
+		ssov1.Admin_ListInvitations_FullMethodName:      true,
+		ssov1.Admin_ListGroupMembers_FullMethodName:     true,
+		ssov1.Admin_ListPendingTOS_FullMethodName:       true,
+		ssov1.Admin_ListAppLoginPolicies_FullMethodName: true,
+		ssov1.Admin_GetAppOrigins_FullMethodName:        true,
+		ssov1.Admin_SetMaintenance_FullMethodName:       true,
+		ssov1.Admin_GetStats_FullMethodName:             true,
+		ssov1.Admin_ExportAuditEvents_FullMethodName:    true,
+		ssov1.Admin_VerifyAuditChain_FullMethodName:     true,
+		ssov1.Admin_InspectToken_FullMethodName:         true,
+	},
+	Login: map[string]bool{
+		ssov1.Auth_Login_FullMethodName:   true,
+		ssov1.Auth_StepUp_FullMethodName:  true,
+		ssov1.Auth_Refresh_FullMethodName: true,
+
+		ssov1.Auth_BeginPasskeyLogin_FullMethodName:  true,
+		ssov1.Auth_FinishPasskeyLogin_FullMethodName: true,
+
+		ssov1.Auth_SendSMSCode_FullMethodName:   true,
+		ssov1.Auth_VerifySMSCode_FullMethodName: true,
+	},
 }
 
 // New - функция-конструктор для создания нового экземпляра App
-func New(log *slog.Logger, authService authgrpc.Auth, port int) *App {
-	// Создаем новый gRPC-сервер
-	gRPCServer := grpc.NewServer()
-
-	// Регистрируем сервис аутентификации в gRPC-сервере
-	authgrpc.RegisterAuthServer(gRPCServer, authService)
-
-	// Возвращаем экземпляр App со всеми необходимыми полями
-	return &App{
-		log:        log,
-		gRPCServer: gRPCServer,
-		port:       port,
+func New(
+	log *slog.Logger,
+	authService Service,
+	authn interceptors.Authenticator,
+	storage Storage,
+	feed authgrpc.RevocationFeed,
+	mode *maintenance.Mode,
+	limiter *ratelimit.Limiter,
+	slo *interceptors.SLO,
+	reporter errreport.Reporter,
+	messages *errinfo.Messages,
+	cfg config.GRPCConfig,
+	tlsConfig *tls.Config,
+	backupDir string,
+	auditChain admingrpc.AuditChainVerifier,
+	appScope admingrpc.AppScope,
+	flags authgrpc.FeatureFlags,
+) *App {
+	// Конфиг уже проверен (config.Validate), поэтому ошибок разбора здесь не бывает
+	trusted, _ := clientip.ParsePrefixes(cfg.TrustedProxies)
+	resolver := clientip.NewResolver(trusted)
+
+	// Порядок интерцепторов - из grpc.interceptors (по умолчанию config.DefaultGRPCInterceptors)
+	policy := accessPolicy
+	if appScope != nil {
+		policy = appScopedPolicy()
+	}
+
+	unary, stream, applied := interceptorChain(interceptorRegistry, cfg.EnabledInterceptors(), interceptorDeps{
+		log:      log,
+		authn:    authn,
+		policy:   policy,
+		mode:     mode,
+		limiter:  limiter,
+		slo:      slo,
+		reporter: reporter,
+		messages: messages,
+		resolver: resolver,
+		cfg:      cfg,
+	})
+	log.Info("grpc interceptors", slog.Any("order", applied))
+
+	a := &App{log: log, trusted: trusted}
+
+	// Слушатели с одинаковыми сервисами и TLS обслуживает один сервер
+	for _, l := range cfg.AllListeners() {
+		if srv := a.serverFor(l); srv != nil {
+			srv.addrs = append(srv.addrs, l.Addr)
+			srv.proxied[l.Addr] = l.ProxyProtocol
+			continue
+		}
+
+		opts := []grpc.ServerOption{
+			grpc.MaxRecvMsgSize(maxRecvMsgSize),
+			grpc.MaxHeaderListSize(maxHeaderListSize),
+			// Область закрепления за основной БД - вне настраиваемой цепочки: она нужна каждому запросу
+			grpc.ChainUnaryInterceptor(append([]grpc.UnaryServerInterceptor{interceptors.PinScope()}, unary...)...),
+			grpc.ChainStreamInterceptor(stream...),
+		}
+		if l.TLS {
+			opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		}
+
+		srv := &server{
+			grpc:     grpc.NewServer(opts...),
+			addrs:    []string{l.Addr},
+			proxied:  map[string]bool{l.Addr: l.ProxyProtocol},
+			services: l.Services,
+			tls:      l.TLS,
+		}
+
+		if slices.Contains(l.Services, config.GRPCServiceAuth) {
+			authgrpc.RegisterAuthServer(srv.grpc, authService, storage, feed, mode, flags)
+		}
+		if slices.Contains(l.Services, config.GRPCServiceAdmin) {
+			// У сервиса администрирования своя политика доступа (accessPolicy.Services)
+			admingrpc.RegisterAdminServer(srv.grpc, storage, storage, storage, storage, authService, authService, authService, storage, backupDir, mode, storage, storage, auditChain, authService, appScope)
+		}
+		if slices.Contains(l.Services, config.GRPCServiceReflection) {
+			reflection.Register(srv.grpc)
+		}
+		// health - последним: он сообщает статус всех сервисов, уже зарегистрированных на сервере
+		if slices.Contains(l.Services, config.GRPCServiceHealth) {
+			srv.health = health.NewServer()
+			healthgrpc.RegisterHealthServer(srv.grpc, srv.health)
+			for name := range srv.grpc.GetServiceInfo() {
+				srv.health.SetServingStatus(name, healthgrpc.HealthCheckResponse_SERVING)
+			}
+		}
+
+		a.servers = append(a.servers, srv)
+	}
+
+	return a
+}
+
+// serverFor - сервер с теми же сервисами и TLS, что у слушателя l (nil - такого ещё нет)
+func (a *App) serverFor(l config.GRPCListener) *server {
+	for _, srv := range a.servers {
+		if srv.tls == l.TLS && sameServices(srv.services, l.Services) {
+			return srv
+		}
 	}
+
+	return nil
+}
+
+// sameServices - одинаковые наборы сервисов без учёта порядка
+func sameServices(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+
+	return slices.Equal(a, b)
 }
 
 // MustRun - запускает gRPC-сервер и в случае ошибки завершает программу
@@ -39,38 +318,121 @@ func (a *App) MustRun() {
 	}
 }
 
-// Run - запускает gRPC-сервер и слушает входящие соединения
+// Run - открывает все слушатели и обслуживает их, пока серверы не остановят. Если какой-то адрес
+// не открылся, не запускается ни один слушатель. Если сервер упал во время работы, останавливаются
+// и остальные: Run возвращает все ошибки
 func (a *App) Run() error {
 	const op = "grpcapp.Run" // Название операции для логирования
 
-	// Создаем логгер с контекстной информацией (название операции + порт)
-	log := a.log.With(slog.String("op", op), slog.Int("port", a.port))
+	type bound struct {
+		srv       *server
+		l         net.Listener
+		proxy     bool
+		inherited bool
+	}
+
+	// Сначала открываем все адреса, чтобы не работать на части портов.
+	// Унаследованный сокет адреса используется вместо нового: он не закрывался на время перезапуска
+	var bounds []bound
+	for _, srv := range a.servers {
+		for _, addr := range srv.addrs {
+			l, inherited := a.inherited.Take(addr)
+			if !inherited {
+				var err error
+				if l, err = net.Listen("tcp", addr); err != nil {
+					for _, b := range bounds {
+						_ = b.l.Close()
+					}
+
+					return fmt.Errorf("%s: %w", op, err)
+				}
+			}
+			// Адрес клиента из заголовка PROXY попадает в peer, а оттуда - в clientip
+			if srv.proxied[addr] {
+				l = proxyproto.NewListener(l, a.trusted)
+			}
+			bounds = append(bounds, bound{srv: srv, l: l, proxy: srv.proxied[addr], inherited: inherited})
+		}
+	}
+
+	if n := a.inherited.Len(); n > 0 {
+		a.log.Warn("closing inherited listeners that match no gRPC address", slog.String("op", op), slog.Int("count", n))
+		_ = a.inherited.Close()
+	}
+
+	errs := make([]error, len(bounds))
+
+	var wg sync.WaitGroup
+	for i, b := range bounds {
+		a.log.Info("gRPC server is running",
+			slog.String("op", op),
+			slog.String("addr", b.l.Addr().String()),
+			slog.Any("services", b.srv.services),
+			slog.Bool("tls", b.srv.tls),
+			slog.Bool("proxy_protocol", b.proxy),
+			slog.Bool("inherited", b.inherited),
+		)
 
-	// Открываем TCP-соединение и слушаем входящие gRPC-запросы на указанном порту
-	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
-	if err != nil {
-		return fmt.Errorf("%s: %w", op, err) // Возвращаем ошибку, если порт не удалось открыть
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// ErrServerStopped означает, что Stop вызвали раньше Serve - это штатная остановка
+			if err := b.srv.grpc.Serve(b.l); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+				errs[i] = fmt.Errorf("%s: %s: %w", op, b.l.Addr(), err)
+				a.Stop()
+			}
+		}()
 	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
 
-	// Логируем успешный запуск gRPC-сервера
-	log.Info("gRPC server is running", slog.String("addr", l.Addr().String()))
+// Inherit - слушать унаследованные сокеты вместо открытия адресов с тем же адресом; вызывается до Run.
+// Адреса без унаследованного сокета открываются как обычно, лишние сокеты закрываются в Run
+func (a *App) Inherit(set *listenfd.Set) {
+	a.inherited = set
+}
 
-	// Запускаем gRPC-сервер и начинаем обработку запросов
-	if err := a.gRPCServer.Serve(l); err != nil {
-		return fmt.Errorf("%s: %w", op, err) // Возвращаем ошибку, если сервер не смог запуститься
+// SetServing - статус всех сервисов в health: NOT_SERVING, пока схема БД несовместима со сборкой
+// (schemacheck). После Stop статус не меняется
+func (a *App) SetServing(serving bool) {
+	status := healthgrpc.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthgrpc.HealthCheckResponse_SERVING
 	}
 
-	return nil // Если сервер запустился без ошибок, возвращаем `nil`
+	for _, srv := range a.servers {
+		if srv.health == nil {
+			continue
+		}
+		for name := range srv.grpc.GetServiceInfo() {
+			srv.health.SetServingStatus(name, status)
+		}
+		// Пустое имя - статус сервера целиком
+		srv.health.SetServingStatus("", status)
+	}
 }
 
-// Stop - останавливает gRPC-сервер
+// Stop - останавливает все gRPC-серверы: health сообщает NOT_SERVING, затем серверы
+// дожидаются активных запросов (Graceful Shutdown)
 func (a *App) Stop() {
 	const op = "grpcapp.Stop" // Название операции для логирования
 
-	// Логируем остановку сервера
-	a.log.With(slog.String("op", op)).
-		Info("stopping gRPC server", slog.Int("port", a.port))
+	var wg sync.WaitGroup
+	for _, srv := range a.servers {
+		a.log.Info("stopping gRPC server", slog.String("op", op), slog.Any("addrs", srv.addrs))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
 
-	// Выполняем Graceful Shutdown (завершаем все активные соединения перед остановкой)
-	a.gRPCServer.GracefulStop()
+			if srv.health != nil {
+				srv.health.Shutdown()
+			}
+			srv.grpc.GracefulStop()
+		}()
+	}
+	wg.Wait()
 }
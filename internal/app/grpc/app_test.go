@@ -0,0 +1,48 @@
+package grpcapp
+
+import (
+	"sso/internal/grpc/interceptors"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessPolicy_AdminServiceRequiresAdmin(t *testing.T) {
+	// Права на организацию проверяет сам обработчик; гостям методы организаций недоступны
+	orgMethods := map[string]bool{
+		ssov1.Admin_CreateOrganization_FullMethodName: true,
+		ssov1.Admin_AddMember_FullMethodName:          true,
+		ssov1.Admin_RemoveMember_FullMethodName:       true,
+		ssov1.Admin_ListMembers_FullMethodName:        true,
+	}
+	// Приложение запроса проверяет обработчик: администратору приложения глобальные права не нужны
+	appMethods := map[string]bool{
+		ssov1.Admin_ListUsers_FullMethodName: true,
+		ssov1.Admin_GetUser_FullMethodName:   true,
+		ssov1.Admin_GetStats_FullMethodName:  true,
+	}
+
+	for _, m := range ssov1.Admin_ServiceDesc.Methods {
+		method := "/" + ssov1.Admin_ServiceDesc.ServiceName + "/" + m.MethodName
+		switch {
+		case orgMethods[method]:
+			assert.Equal(t, interceptors.AccessRegistered, accessPolicy.For(method), method)
+		case appMethods[method]:
+			assert.Equal(t, interceptors.AccessAppAdmin, accessPolicy.For(method), method)
+		default:
+			assert.Equal(t, interceptors.AccessAdmin, accessPolicy.For(method), method)
+		}
+	}
+	for _, s := range ssov1.Admin_ServiceDesc.Streams {
+		method := "/" + ssov1.Admin_ServiceDesc.ServiceName + "/" + s.StreamName
+		assert.Equal(t, interceptors.AccessAdmin, accessPolicy.For(method), method)
+	}
+
+	assert.Equal(t, interceptors.AccessPublic, accessPolicy.For(ssov1.Auth_Login_FullMethodName))
+	assert.Equal(t, interceptors.AccessPublic, accessPolicy.For(ssov1.Auth_Register_FullMethodName))
+	assert.Equal(t, interceptors.AccessPublic, accessPolicy.For(ssov1.Auth_CreateGuest_FullMethodName))
+	assert.Equal(t, interceptors.AccessRegistered, accessPolicy.For(ssov1.Auth_GrantConsent_FullMethodName))
+	assert.Equal(t, interceptors.AccessPublic, accessPolicy.For(ssov1.Auth_PollDeviceToken_FullMethodName))
+	assert.Equal(t, interceptors.AccessRegistered, accessPolicy.For(ssov1.Auth_ApproveDevice_FullMethodName))
+}
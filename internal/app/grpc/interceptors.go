@@ -0,0 +1,165 @@
+package grpcapp
+
+import (
+	"log/slog"
+	"sso/internal/config"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/grpc/interceptors"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/errreport"
+	"sso/internal/lib/maintenance"
+	"sso/internal/lib/ratelimit"
+	"sso/internal/lib/redact"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc"
+)
+
+// interceptorDeps - всё, из чего собираются интерцепторы
+type interceptorDeps struct {
+	log      *slog.Logger
+	authn    interceptors.Authenticator
+	policy   interceptors.Policy
+	mode     *maintenance.Mode
+	limiter  *ratelimit.Limiter
+	slo      *interceptors.SLO
+	reporter errreport.Reporter
+	messages *errinfo.Messages
+	resolver *clientip.Resolver
+	cfg      config.GRPCConfig
+}
+
+// interceptor - unary- и stream-вариант интерцептора; nil - для этого вида вызовов его нет
+// или он выключен настройкой (например, payloads без grpc.debug_payloads)
+type interceptor struct {
+	unary  grpc.UnaryServerInterceptor
+	stream grpc.StreamServerInterceptor
+}
+
+// interceptorRegistry - конструкторы интерцепторов по именам из grpc.interceptors.
+// Порядок задаёт конфигурация (config.DefaultGRPCInterceptors), допустимость порядка проверяет config.Validate
+var interceptorRegistry = map[string]func(d interceptorDeps) interceptor{
+	// Метрики - снаружи всех интерцепторов: длительность с их работой и итоговый статус с причиной
+	config.InterceptorMetrics: func(d interceptorDeps) interceptor {
+		return interceptor{unary: interceptors.Metrics(d.slo)}
+	},
+	// ErrorInfo с причиной и перевод получают и ошибки остальных интерцепторов
+	config.InterceptorErrorReasons: func(d interceptorDeps) interceptor {
+		return interceptor{
+			unary:  interceptors.ErrorReasons(d.messages),
+			stream: interceptors.ErrorReasonsStream(d.messages),
+		}
+	},
+	// Адрес клиента (а не прокси) нужен логам, аудиту и списку разрешённых сетей
+	config.InterceptorClientIP: func(d interceptorDeps) interceptor {
+		return interceptor{
+			unary:  interceptors.ClientIP(d.resolver, d.cfg.ForwardedHeader),
+			stream: interceptors.ClientIPStream(d.resolver, d.cfg.ForwardedHeader),
+		}
+	},
+	// User-Agent - для поиска входов с новых устройств
+	config.InterceptorUserAgent: func(interceptorDeps) interceptor {
+		return interceptor{unary: interceptors.UserAgent()}
+	},
+	// Логгер запроса в контексте нужен всем последующим обработчикам
+	config.InterceptorLogging: func(d interceptorDeps) interceptor {
+		return interceptor{unary: interceptors.Logging(d.log)}
+	},
+	// Паника не роняет сервер; она и ответы INTERNAL уходят в трекер ошибок с x-request-id из Logging
+	config.InterceptorRecovery: func(d interceptorDeps) interceptor {
+		return interceptor{
+			unary:  interceptors.Recovery(d.log, d.reporter),
+			stream: interceptors.RecoveryStream(d.log, d.reporter),
+		}
+	},
+	// Длина и символы строковых полей - до любой дорогой работы (токены, bcrypt, база)
+	config.InterceptorInputLimits: func(interceptorDeps) interceptor {
+		return interceptor{
+			unary:  interceptors.InputLimits(),
+			stream: interceptors.InputLimitsStream(),
+		}
+	},
+	// Запросы и ответы целиком - только при отладке вне prod (config.Validate не пропускает флаг в prod)
+	config.InterceptorPayloads: func(d interceptorDeps) interceptor {
+		if !d.cfg.DebugPayloads {
+			return interceptor{}
+		}
+		d.log.Warn("logging request and response payloads (grpc.debug_payloads)")
+
+		return interceptor{
+			unary:  interceptors.Payloads(d.log, redact.Default),
+			stream: interceptors.PayloadsStream(d.log, redact.Default),
+		}
+	},
+	// Администрирование - только из разрешённых сетей, ещё до проверки токена
+	config.InterceptorAdminAllowlist: func(d interceptorDeps) interceptor {
+		if len(d.cfg.AdminAllowlist) == 0 {
+			return interceptor{}
+		}
+		allowed, _ := clientip.ParsePrefixes(d.cfg.AdminAllowlist)
+		adminServices := []string{ssov1.Admin_ServiceDesc.ServiceName}
+
+		return interceptor{
+			unary:  interceptors.AllowNetworks(adminServices, allowed),
+			stream: interceptors.AllowNetworksStream(adminServices, allowed),
+		}
+	},
+	// В режиме обслуживания изменяющие методы отклоняются до проверки токена
+	config.InterceptorMaintenance: func(d interceptorDeps) interceptor {
+		return interceptor{
+			unary:  interceptors.Maintenance(d.mode, readOnly),
+			stream: interceptors.MaintenanceStream(d.mode, readOnly),
+		}
+	},
+	// Лимиты запросов - до проверки токена, чтобы поток запросов не нагружал её. Интерцептор ставится
+	// и без правил: ограничение можно включить перезагрузкой конфигурации
+	config.InterceptorRateLimit: func(d interceptorDeps) interceptor {
+		return interceptor{
+			unary:  interceptors.RateLimit(d.limiter),
+			stream: interceptors.RateLimitStream(d.limiter),
+		}
+	},
+	// Проверка bearer-токена и прав доступа к методу
+	config.InterceptorAuth: func(d interceptorDeps) interceptor {
+		return interceptor{
+			unary:  interceptors.Auth(d.authn, d.policy),
+			stream: interceptors.AuthStream(d.authn, d.policy),
+		}
+	},
+	// Принудительное сжатие крупных ответов (если клиент поддерживает gzip)
+	config.InterceptorCompression: func(d interceptorDeps) interceptor {
+		if !d.cfg.Compression.Force {
+			return interceptor{}
+		}
+
+		return interceptor{unary: interceptors.Compression(d.cfg.Compression.MinSize)}
+	},
+}
+
+// interceptorChain - интерцепторы из registry в порядке names (от внешнего к внутреннему)
+// и имена тех, что действительно поставлены. Имена уже проверены config.Validate, неизвестные пропускаются
+func interceptorChain(
+	registry map[string]func(d interceptorDeps) interceptor,
+	names []string,
+	d interceptorDeps,
+) (unary []grpc.UnaryServerInterceptor, stream []grpc.StreamServerInterceptor, applied []string) {
+	for _, name := range names {
+		newInterceptor, ok := registry[name]
+		if !ok {
+			continue
+		}
+
+		i := newInterceptor(d)
+		if i.unary != nil {
+			unary = append(unary, i.unary)
+		}
+		if i.stream != nil {
+			stream = append(stream, i.stream)
+		}
+		if i.unary != nil || i.stream != nil {
+			applied = append(applied, name)
+		}
+	}
+
+	return unary, stream, applied
+}
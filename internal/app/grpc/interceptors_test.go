@@ -0,0 +1,113 @@
+package grpcapp
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"net/netip"
+	"sso/internal/config"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/requestid"
+	"sso/internal/lib/useragent"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// invoke - вызов handler через цепочку unary-интерцепторов, как это делает grpc.ChainUnaryInterceptor
+func invoke(ctx context.Context, chain []grpc.UnaryServerInterceptor, handler grpc.UnaryHandler) (any, error) {
+	if len(chain) == 0 {
+		return handler(ctx, nil)
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/auth.Auth/Login"}
+
+	return chain[0](ctx, nil, info, func(ctx context.Context, _ any) (any, error) {
+		return invoke(ctx, chain[1:], handler)
+	})
+}
+
+type seenKey struct{}
+
+func TestInterceptorChain_OrderFromConfig(t *testing.T) {
+	// каждый интерцептор дописывает своё имя к значению в контексте, оставленному предыдущими
+	record := func(name string) func(interceptorDeps) interceptor {
+		return func(interceptorDeps) interceptor {
+			return interceptor{unary: func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+				seen, _ := ctx.Value(seenKey{}).([]string)
+				return handler(context.WithValue(ctx, seenKey{}, append(seen[:len(seen):len(seen)], name)), req)
+			}}
+		}
+	}
+	registry := map[string]func(interceptorDeps) interceptor{
+		"a": record("a"),
+		"b": record("b"),
+		"c": record("c"),
+		"off": func(interceptorDeps) interceptor {
+			return interceptor{}
+		},
+	}
+
+	for _, names := range [][]string{{"a", "b", "c"}, {"c", "off", "a"}, {"b"}} {
+		unary, stream, applied := interceptorChain(registry, names, interceptorDeps{})
+		assert.Empty(t, stream)
+
+		var got []string
+		_, err := invoke(context.Background(), unary, func(ctx context.Context, _ any) (any, error) {
+			got, _ = ctx.Value(seenKey{}).([]string)
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		want := make([]string, 0, len(names))
+		for _, n := range names {
+			if n != "off" {
+				want = append(want, n)
+			}
+		}
+		assert.Equal(t, want, got, "handler sees every value in config order")
+		assert.Equal(t, want, applied, "disabled interceptors are not reported as applied")
+	}
+}
+
+func TestInterceptorChain_RequestScopedValues(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cfg := config.GRPCConfig{
+		Interceptors:    []string{config.InterceptorClientIP, config.InterceptorUserAgent, config.InterceptorLogging, config.InterceptorRecovery},
+		ForwardedHeader: "x-forwarded-for",
+	}
+	unary, _, applied := interceptorChain(interceptorRegistry, cfg.EnabledInterceptors(), interceptorDeps{
+		log:      log,
+		resolver: clientip.NewResolver(nil),
+		cfg:      cfg,
+	})
+	require.Equal(t, cfg.Interceptors, applied)
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("192.0.2.7"), Port: 5000}})
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("user-agent", "test-agent", requestid.Header, "req-1"))
+
+	_, err := invoke(ctx, unary, func(ctx context.Context, _ any) (any, error) {
+		addr, ok := clientip.From(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, netip.MustParseAddr("192.0.2.7"), addr)
+		assert.Equal(t, "test-agent", useragent.From(ctx))
+		assert.Equal(t, "req-1", requestid.From(ctx))
+		assert.NotNil(t, logctx.From(ctx))
+
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestInterceptorRegistry_CoversConfig(t *testing.T) {
+	for _, name := range config.DefaultGRPCInterceptors {
+		assert.Contains(t, interceptorRegistry, name)
+	}
+	assert.Len(t, interceptorRegistry, len(config.DefaultGRPCInterceptors))
+}
@@ -0,0 +1,147 @@
+package httpapp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sso/internal/config"
+	"time"
+)
+
+// App - служебный HTTP-сервер (отладка, метрики, JSON-шлюз)
+type App struct {
+	log             *slog.Logger  // Логгер для записи событий
+	httpServer      *http.Server  // Экземпляр HTTP-сервера
+	port            int           // Порт, на котором работает HTTP-сервер
+	shutdownTimeout time.Duration // Сколько ждать завершения активных запросов при остановке
+}
+
+// Maintenance - режим обслуживания (maintenance.Mode)
+type Maintenance interface {
+	Enabled() bool
+	AllowLogin() bool
+	RetryAfter() time.Duration
+}
+
+// Readiness - готовность обслуживать запросы (schemacheck.Checker)
+type Readiness interface {
+	Ready() bool
+}
+
+// New - функция-конструктор для создания служебного HTTP-сервера
+func New(
+	log *slog.Logger,
+	cfg config.HTTPConfig,
+	schema SchemaProvider,
+	authenticator Authenticator,
+	origins AppOrigins,
+	oidc OIDCProvider,
+	mode Maintenance,
+	readiness Readiness,
+) *App {
+	mux := http.NewServeMux()
+
+	// Проверка живости для балансировщика: режим обслуживания не делает сервис нездоровым,
+	// токены по-прежнему проверяются, поэтому он только отражается в ответе
+	mux.Handle("GET /healthz", healthHandler(mode))
+	// Проверка готовности: 503, пока схема БД несовместима со сборкой - балансировщик не шлёт запросы,
+	// которые упали бы на отсутствующей колонке
+	mux.Handle("GET /readyz", readyHandler(readiness))
+
+	// Отладочные обработчики (pprof, expvar) включаются только явно
+	if cfg.Debug.Enabled {
+		registerDebug(mux, cfg.Debug.Token.Reveal(), schema)
+	}
+
+	if cfg.Gateway.Enabled {
+		registerGateway(mux, log, authenticator, origins, mode, cfg.Gateway)
+	}
+
+	if cfg.OIDC.Enabled {
+		registerOIDC(mux, log, oidc, mode, cfg.OIDC)
+	}
+
+	return &App{
+		log: log,
+		httpServer: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		},
+		port:            cfg.Port,
+		shutdownTimeout: cfg.ShutdownTimeout,
+	}
+}
+
+// MustRun - запускает HTTP-сервер и в случае ошибки завершает программу
+func (a *App) MustRun() {
+	if err := a.Run(); err != nil {
+		panic(err)
+	}
+}
+
+// Run - запускает HTTP-сервер и слушает входящие соединения
+func (a *App) Run() error {
+	const op = "httpapp.Run"
+
+	log := a.log.With(slog.String("op", op), slog.Int("port", a.port))
+
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.port))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("HTTP server is running", slog.String("addr", l.Addr().String()))
+
+	if err := a.httpServer.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Stop - останавливает HTTP-сервер, дожидаясь активных запросов не дольше shutdownTimeout
+func (a *App) Stop() {
+	const op = "httpapp.Stop"
+
+	log := a.log.With(slog.String("op", op))
+	log.Info("stopping HTTP server", slog.Int("port", a.port))
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.shutdownTimeout)
+	defer cancel()
+
+	if err := a.httpServer.Shutdown(ctx); err != nil {
+		log.Error("HTTP server shutdown timed out", slog.String("error", err.Error()))
+		_ = a.httpServer.Close()
+	}
+}
+
+// healthHandler - {"status":"ok","maintenance":false}
+func healthHandler(mode Maintenance) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			Status      string `json:"status"`
+			Maintenance bool   `json:"maintenance"`
+		}{Status: "ok", Maintenance: mode.Enabled()})
+	})
+}
+
+// readyHandler - {"status":"ok"} или 503 {"status":"not ready"}
+func readyHandler(readiness Readiness) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		status, code := "ok", http.StatusOK
+		if !readiness.Ready() {
+			status, code = "not ready", http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(struct {
+			Status string `json:"status"`
+		}{Status: status})
+	})
+}
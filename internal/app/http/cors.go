@@ -0,0 +1,128 @@
+package httpapp
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sso/internal/config"
+	"sso/internal/lib/origin"
+	"strconv"
+	"strings"
+)
+
+// AppOrigins - источники браузерных клиентов приложений (sqlite.Storage.AppOrigins)
+type AppOrigins interface {
+	AppOrigins(ctx context.Context, appID int) ([]string, error)
+}
+
+// corsMethods - методы, которые шлюз разрешает в ответе на preflight
+const corsMethods = "GET, POST"
+
+// cors - заголовки CORS для запросов с источников, зарегистрированных у приложения запроса.
+// Origin незарегистрированного источника (или запроса без приложения) не получает ни одного заголовка CORS
+type cors struct {
+	log         *slog.Logger
+	origins     AppOrigins
+	appHeader   string
+	headers     string // Access-Control-Allow-Headers
+	maxAge      string // Access-Control-Max-Age (пусто - не отправлять)
+	credentials bool   // режим cookie: браузеру разрешено отправлять cookie сессии
+}
+
+func newCORS(log *slog.Logger, origins AppOrigins, cfg config.CORSConfig, credentials bool) *cors {
+	// Заголовок с приложением сам требует preflight, поэтому разрешён всегда
+	headers := slices.Clone(cfg.AllowedHeaders)
+	if !slices.ContainsFunc(headers, func(h string) bool { return strings.EqualFold(h, cfg.AppHeader) }) {
+		headers = append(headers, cfg.AppHeader)
+	}
+
+	c := &cors{
+		log:         log,
+		origins:     origins,
+		appHeader:   cfg.AppHeader,
+		headers:     strings.Join(headers, ", "),
+		credentials: credentials,
+	}
+	if cfg.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	}
+
+	return c
+}
+
+// wrap - добавляет заголовки CORS к ответу next и отвечает на preflight, не вызывая next
+func (c *cors) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestOrigin := r.Header.Get("Origin")
+		if requestOrigin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Ответ зависит от источника и приложения: кеши не должны отдавать его другим
+		h := w.Header()
+		h.Add("Vary", "Origin")
+		h.Add("Vary", c.appHeader)
+
+		allowed := c.allowed(r, requestOrigin)
+		if allowed {
+			h.Set("Access-Control-Allow-Origin", requestOrigin)
+			if c.credentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if allowed {
+			h.Set("Access-Control-Allow-Methods", corsMethods)
+			h.Set("Access-Control-Allow-Headers", c.headers)
+			if c.maxAge != "" {
+				h.Set("Access-Control-Max-Age", c.maxAge)
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// preflight - обработчик OPTIONS пути шлюза: отвечает только на preflight
+func (c *cors) preflight() http.Handler {
+	return c.wrap(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	}))
+}
+
+// allowed - зарегистрирован ли requestOrigin у приложения запроса. Ошибка хранилища - отказ
+func (c *cors) allowed(r *http.Request, requestOrigin string) bool {
+	appID := c.appID(r)
+	if appID == 0 {
+		return false
+	}
+
+	patterns, err := c.origins.AppOrigins(r.Context(), appID)
+	if err != nil {
+		c.log.Error("failed to load app origins", slog.Int("app_id", appID), slog.String("error", err.Error()))
+		return false
+	}
+
+	return slices.ContainsFunc(patterns, func(p string) bool { return origin.Match(p, requestOrigin) })
+}
+
+// appID - приложение запроса: параметр app_id в адресе, иначе заголовок appHeader (0 - не указано)
+func (c *cors) appID(r *http.Request) int {
+	raw := r.URL.Query().Get("app_id")
+	if raw == "" {
+		raw = r.Header.Get(c.appHeader)
+	}
+
+	id, err := strconv.Atoi(raw)
+	if err != nil || id <= 0 {
+		return 0
+	}
+
+	return id
+}
@@ -0,0 +1,126 @@
+package httpapp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sso/internal/config"
+	"sso/internal/lib/maintenance"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOrigins - источники приложений; приложение 3 недоступно (ошибка хранилища)
+type fakeOrigins map[int][]string
+
+func (f fakeOrigins) AppOrigins(_ context.Context, appID int) ([]string, error) {
+	if appID == 3 {
+		return nil, errors.New("database is locked")
+	}
+
+	return f[appID], nil
+}
+
+func newCORSGateway(t *testing.T, cookie config.CookieConfig) *http.ServeMux {
+	t.Helper()
+
+	origins := fakeOrigins{
+		1: {"https://*.example.com", "http://localhost:3000"},
+		2: {"https://billing.example.net"},
+	}
+	mux := http.NewServeMux()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registerGateway(mux, log, &fakeAuth{ended: map[string]bool{}}, origins, maintenance.New(config.MaintenanceConfig{}),
+		config.GatewayConfig{Enabled: true, Cookie: cookie, CORS: config.CORSConfig{
+			Enabled: true, AppHeader: "X-App-ID", AllowedHeaders: []string{"Content-Type"}, MaxAge: 10 * time.Minute,
+		}})
+
+	return mux
+}
+
+func preflight(path, origin string) *http.Request {
+	req := httptest.NewRequest(http.MethodOptions, path, nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+	req.Header.Set("Access-Control-Request-Headers", "content-type")
+
+	return req
+}
+
+func TestGateway_CORSPreflight(t *testing.T) {
+	mux := newCORSGateway(t, config.CookieConfig{})
+
+	tests := []struct {
+		name, path, origin string
+		allowed            bool
+	}{
+		{"wildcard subdomain", "/v1/login?app_id=1", "https://app.example.com", true},
+		{"nested subdomain", "/v1/login?app_id=1", "https://eu.app.example.com", true},
+		{"exact origin", "/v1/me?app_id=1", "http://localhost:3000", true},
+		{"apex is not a subdomain", "/v1/login?app_id=1", "https://example.com", false},
+		{"suffix without a dot", "/v1/login?app_id=1", "https://evilexample.com", false},
+		{"other scheme", "/v1/login?app_id=1", "http://app.example.com", false},
+		{"origin of another app", "/v1/login?app_id=2", "https://app.example.com", false},
+		{"no app", "/v1/login", "https://app.example.com", false},
+		{"unknown app", "/v1/login?app_id=9", "https://app.example.com", false},
+		{"storage error", "/v1/login?app_id=3", "https://app.example.com", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(mux, preflight(tt.path, tt.origin))
+			require.Equal(t, http.StatusNoContent, rec.Code)
+
+			h := rec.Header()
+			if !tt.allowed {
+				for name := range h {
+					assert.False(t, strings.HasPrefix(name, "Access-Control-"), "unexpected %s", name)
+				}
+				return
+			}
+			assert.Equal(t, tt.origin, h.Get("Access-Control-Allow-Origin"))
+			assert.Equal(t, "GET, POST", h.Get("Access-Control-Allow-Methods"))
+			assert.Equal(t, "Content-Type, X-App-ID", h.Get("Access-Control-Allow-Headers"))
+			assert.Equal(t, "600", h.Get("Access-Control-Max-Age"))
+			assert.Empty(t, h.Get("Access-Control-Allow-Credentials"))
+			assert.Equal(t, []string{"Origin", "X-App-ID"}, h.Values("Vary"))
+		})
+	}
+
+	// OPTIONS без Access-Control-Request-Method - не preflight
+	req := httptest.NewRequest(http.MethodOptions, "/v1/login?app_id=1", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(mux, req).Code)
+}
+
+func TestGateway_CORSRequest(t *testing.T) {
+	mux := newCORSGateway(t, config.CookieConfig{Enabled: true, Name: "sso_session", MaxAge: time.Hour})
+
+	// Приложение из заголовка; заголовки CORS есть и у ответа с ошибкой
+	req := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("X-App-ID", "1")
+	rec := serve(mux, req)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Methods"), "only preflight lists methods")
+
+	// Незарегистрированный источник: запрос обработан, но без заголовков CORS
+	req = httptest.NewRequest(http.MethodPost, "/v1/login?app_id=2", strings.NewReader(loginBody))
+	req.Header.Set("Origin", "https://app.example.com")
+	rec = serve(mux, req)
+	assert.Equal(t, http.StatusForbidden, rec.Code, "CSRF check still applies")
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// Запрос без Origin (не из браузера) не меняется
+	rec = serve(mux, httptest.NewRequest(http.MethodGet, "/v1/csrf", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Values("Vary"))
+}
@@ -0,0 +1,76 @@
+package httpapp
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"sso/internal/buildinfo"
+	"sso/migrations"
+	"strings"
+)
+
+// SchemaProvider - источник версии схемы БД для /debug/build
+type SchemaProvider interface {
+	SchemaVersion(ctx context.Context) (version int64, dirty bool, err error)
+}
+
+// registerDebug - регистрирует pprof, /debug/vars и /debug/build.
+// Если token не пустой, обработчики требуют заголовок `Authorization: Bearer <token>`
+func registerDebug(mux *http.ServeMux, token string, schema SchemaProvider) {
+	handle := func(pattern string, h http.Handler) {
+		mux.Handle(pattern, requireBearer(token, h))
+	}
+
+	handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
+	handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
+	handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
+	handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
+	handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	handle("/debug/vars", expvar.Handler())
+	handle("/debug/build", buildHandler(schema))
+}
+
+// requireBearer - пропускает запрос только с правильным статическим токеном
+func requireBearer(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// buildHandler - отдаёт информацию о сборке и версии схемы БД (то же, что GetServerInfo)
+func buildHandler(schema SchemaProvider) http.Handler {
+	type response struct {
+		buildinfo.Info
+		SchemaVersion         int64 `json:"schema_version"`
+		SchemaDirty           bool  `json:"schema_dirty"`
+		ExpectedSchemaVersion int64 `json:"expected_schema_version"`
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res := response{Info: buildinfo.Get(), ExpectedSchemaVersion: migrations.Latest()}
+
+		if schema != nil {
+			if version, dirty, err := schema.SchemaVersion(r.Context()); err == nil {
+				res.SchemaVersion = version
+				res.SchemaDirty = dirty
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(res)
+	})
+}
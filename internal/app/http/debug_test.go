@@ -0,0 +1,74 @@
+package httpapp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sso/migrations"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebug_RequiresToken(t *testing.T) {
+	mux := http.NewServeMux()
+	registerDebug(mux, "s3cret", nil)
+
+	for _, path := range []string{"/debug/pprof/", "/debug/vars", "/debug/build"} {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer wrong")
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, path)
+
+		req = httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec = httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+	}
+}
+
+func TestDebug_Build(t *testing.T) {
+	mux := http.NewServeMux()
+	registerDebug(mux, "", fakeSchema{version: 2})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/build", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, runtime.Version(), body["go_version"])
+	assert.Equal(t, "dev", body["version"])
+	assert.EqualValues(t, 2, body["schema_version"])
+	assert.Equal(t, false, body["schema_dirty"])
+	assert.EqualValues(t, migrations.Latest(), body["expected_schema_version"])
+}
+
+type readiness bool
+
+func (r readiness) Ready() bool { return bool(r) }
+
+func TestReady(t *testing.T) {
+	for ready, code := range map[readiness]int{true: http.StatusOK, false: http.StatusServiceUnavailable} {
+		rec := httptest.NewRecorder()
+		readyHandler(ready).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		assert.Equal(t, code, rec.Code)
+	}
+}
+
+type fakeSchema struct {
+	version int64
+}
+
+func (f fakeSchema) SchemaVersion(context.Context) (int64, bool, error) {
+	return f.version, false, nil
+}
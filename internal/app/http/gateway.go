@@ -0,0 +1,371 @@
+package httpapp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sso/internal/config"
+	"sso/internal/domain/models"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/useragent"
+	"sso/internal/services/auth"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	csrfCookie = "sso_csrf"     // cookie с CSRF-токеном (читается скриптом страницы)
+	csrfHeader = "X-CSRF-Token" // заголовок, в котором страница повторяет CSRF-токен
+)
+
+// Authenticator - сервис авторизации за шлюзом
+type Authenticator interface {
+	Login(ctx context.Context, email, password string, appID int, orgSlug, acceptedTOS, captchaToken string) (models.Token, error)
+	Authenticate(ctx context.Context, token string) (authctx.Principal, error)
+	AuthenticateSession(ctx context.Context, sid string) (authctx.Principal, error)
+	EndSession(ctx context.Context, sid string) error
+}
+
+// gateway - HTTP/JSON-шлюз к сервису авторизации. В режиме cookie браузер не видит JWT:
+// cookie содержит id сессии, а вызывающего по нему находит сервис авторизации через хранилище сессий
+type gateway struct {
+	log    *slog.Logger
+	auth   Authenticator
+	cookie config.CookieConfig
+
+	maintenance Maintenance
+}
+
+// registerGateway - регистрирует /v1/login, /v1/logout, /v1/me и, в режиме cookie, /v1/csrf.
+// С http.gateway.cors каждый путь отвечает и на preflight, а заголовки CORS добавляются и к ответам с ошибкой,
+// чтобы скрипт страницы мог их прочитать
+func registerGateway(
+	mux *http.ServeMux,
+	log *slog.Logger,
+	a Authenticator,
+	origins AppOrigins,
+	mode Maintenance,
+	cfg config.GatewayConfig,
+) {
+	g := &gateway{log: log, auth: a, cookie: cfg.Cookie, maintenance: mode}
+
+	var c *cors
+	if cfg.CORS.Enabled {
+		c = newCORS(log, origins, cfg.CORS, cfg.Cookie.Enabled)
+	}
+	handle := func(method, path string, h http.Handler) {
+		if c != nil {
+			h = c.wrap(h)
+			mux.Handle(http.MethodOptions+" "+path, c.preflight())
+		}
+		mux.Handle(method+" "+path, h)
+	}
+
+	handle(http.MethodPost, "/v1/login", g.csrfProtected(g.unlessMaintenance(true, http.HandlerFunc(g.login))))
+	handle(http.MethodPost, "/v1/logout", g.csrfProtected(g.unlessMaintenance(false, http.HandlerFunc(g.logout))))
+	handle(http.MethodGet, "/v1/me", http.HandlerFunc(g.me))
+	if cfg.Cookie.Enabled {
+		handle(http.MethodGet, "/v1/csrf", http.HandlerFunc(g.csrf))
+	}
+}
+
+type loginRequest struct {
+	Email        string `json:"email"`
+	Password     string `json:"password"`
+	AppID        int    `json:"app_id"`
+	Org          string `json:"org"`
+	AcceptedTOS  string `json:"accepted_tos"`
+	CaptchaToken string `json:"captcha_token"`
+}
+
+type loginResponse struct {
+	AccessToken             string `json:"access_token,omitempty"` // только без режима cookie
+	TokenType               string `json:"token_type,omitempty"`
+	ExpiresAt               int64  `json:"expires_at"`
+	TOSReacceptanceRequired bool   `json:"tos_reacceptance_required,omitempty"`
+	StepUpRequired          bool   `json:"step_up_required,omitempty"`
+}
+
+func (g *gateway) login(w http.ResponseWriter, r *http.Request) {
+	var req loginRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<16)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	if req.Email == "" || req.Password == "" || req.AppID == 0 {
+		writeError(w, http.StatusBadRequest, "email, password and app_id are required")
+		return
+	}
+
+	ctx := useragent.Into(r.Context(), r.UserAgent())
+	token, err := g.auth.Login(ctx, req.Email, req.Password, req.AppID, req.Org, req.AcceptedTOS, req.CaptchaToken)
+	if err != nil {
+		g.writeAuthError(w, err)
+		return
+	}
+
+	resp := loginResponse{
+		ExpiresAt:               token.ExpiresAt.Unix(),
+		TOSReacceptanceRequired: token.TOSReacceptanceRequired,
+		StepUpRequired:          token.StepUpRequired,
+	}
+
+	if !g.cookie.Enabled {
+		resp.AccessToken = token.AccessToken
+		resp.TokenType = "Bearer"
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	if token.SessionID == "" {
+		g.log.Error("gateway login without a session, cookie mode requires sessions")
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	http.SetCookie(w, g.sessionCookie(token.SessionID, token.ExpiresAt))
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (g *gateway) logout(w http.ResponseWriter, r *http.Request) {
+	var sid string
+	if g.cookie.Enabled {
+		c, err := r.Cookie(g.cookie.Name)
+		if err != nil || c.Value == "" {
+			writeError(w, http.StatusUnauthorized, "not logged in")
+			return
+		}
+		sid = c.Value
+	} else {
+		// Без режима cookie сессию называет сам токен
+		token, ok := bearerToken(r)
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "not logged in")
+			return
+		}
+
+		p, err := g.auth.Authenticate(r.Context(), token)
+		if err != nil {
+			g.writeAuthError(w, err)
+			return
+		}
+		sid = p.SessionID
+	}
+
+	// Уже завершённая сессия - не ошибка: cookie всё равно нужно стереть
+	if err := g.auth.EndSession(r.Context(), sid); err != nil && !errors.Is(err, auth.ErrSessionNotFound) {
+		g.log.Error("failed to end session", slog.String("error", err.Error()))
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	if g.cookie.Enabled {
+		http.SetCookie(w, g.expiredSessionCookie())
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+type meResponse struct {
+	UserID    int64    `json:"user_id"`
+	Email     string   `json:"email,omitempty"`
+	AppID     int      `json:"app_id"`
+	IsAdmin   bool     `json:"is_admin"`
+	AMR       []string `json:"amr,omitempty"`
+	ACR       string   `json:"acr,omitempty"`
+	ExpiresAt int64    `json:"expires_at,omitempty"`
+}
+
+func (g *gateway) me(w http.ResponseWriter, r *http.Request) {
+	p, ok, err := g.authenticate(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "not logged in")
+		return
+	}
+	if err != nil {
+		g.writeAuthError(w, err)
+		return
+	}
+
+	resp := meResponse{
+		UserID:  p.UserID,
+		Email:   p.Email,
+		AppID:   p.AppID,
+		IsAdmin: p.IsAdmin,
+		AMR:     p.AMR,
+		ACR:     p.ACR,
+	}
+	if !p.ExpiresAt.IsZero() {
+		resp.ExpiresAt = p.ExpiresAt.Unix()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// csrf - выдаёт CSRF-токен: страница кладёт его в заголовок X-CSRF-Token изменяющих запросов.
+// Уже выданный токен переиспользуется, чтобы параллельные вкладки не перетирали друг друга
+func (g *gateway) csrf(w http.ResponseWriter, r *http.Request) {
+	token := ""
+	if c, err := r.Cookie(csrfCookie); err == nil && c.Value != "" {
+		token = c.Value
+	} else {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		token = base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	cookie := g.baseCookie(csrfCookie, token)
+	cookie.HttpOnly = false // значение читает скрипт страницы
+	cookie.MaxAge = int(g.cookie.MaxAge.Seconds())
+	http.SetCookie(w, cookie)
+
+	writeJSON(w, http.StatusOK, map[string]string{"csrf_token": token})
+}
+
+// unlessMaintenance - в режиме обслуживания отвечает 503 с Retry-After.
+// Вход (login) пропускается, если он не запрещён конфигурацией; выход завершает сессию в хранилище и не пропускается
+func (g *gateway) unlessMaintenance(login bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if g.maintenance.Enabled() && !(login && g.maintenance.AllowLogin()) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(g.maintenance.RetryAfter().Seconds())))
+			writeError(w, http.StatusServiceUnavailable, "service is in maintenance mode, retry later")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// csrfProtected - в режиме cookie пропускает запрос, только если заголовок X-CSRF-Token совпадает
+// с cookie sso_csrf (double-submit): чужой сайт может отправить cookie, но не прочитать её значение.
+// Запросы с bearer-токеном браузер сам не подставляет, поэтому без режима cookie проверка не нужна
+func (g *gateway) csrfProtected(next http.Handler) http.Handler {
+	if !g.cookie.Enabled {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie(csrfCookie)
+		header := r.Header.Get(csrfHeader)
+		if err != nil || c.Value == "" || subtle.ConstantTimeCompare([]byte(c.Value), []byte(header)) != 1 {
+			writeError(w, http.StatusForbidden, "missing or invalid CSRF token")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate - вызывающий запроса: в режиме cookie - по id сессии из cookie, иначе - по токену
+// из заголовка Authorization. ok = false, если запрос не несёт ни того, ни другого
+func (g *gateway) authenticate(r *http.Request) (p authctx.Principal, ok bool, err error) {
+	if !g.cookie.Enabled {
+		token, ok := bearerToken(r)
+		if !ok {
+			return authctx.Principal{}, false, nil
+		}
+		p, err = g.auth.Authenticate(r.Context(), token)
+
+		return p, true, err
+	}
+
+	c, err := r.Cookie(g.cookie.Name)
+	if err != nil || c.Value == "" {
+		return authctx.Principal{}, false, nil
+	}
+	p, err = g.auth.AuthenticateSession(r.Context(), c.Value)
+
+	return p, true, err
+}
+
+// bearerToken - токен из заголовка Authorization
+func bearerToken(r *http.Request) (string, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	return token, ok && token != ""
+}
+
+// sessionCookie - cookie сессии; живёт не дольше токена
+func (g *gateway) sessionCookie(sid string, expiresAt time.Time) *http.Cookie {
+	maxAge := min(g.cookie.MaxAge, time.Until(expiresAt))
+
+	cookie := g.baseCookie(g.cookie.Name, sid)
+	cookie.MaxAge = max(int(maxAge.Seconds()), 1)
+
+	return cookie
+}
+
+// expiredSessionCookie - cookie, которая удаляет cookie сессии из браузера
+func (g *gateway) expiredSessionCookie() *http.Cookie {
+	cookie := g.baseCookie(g.cookie.Name, "")
+	cookie.MaxAge = -1
+
+	return cookie
+}
+
+// baseCookie - общие атрибуты cookie шлюза
+func (g *gateway) baseCookie(name, value string) *http.Cookie {
+	sameSite := http.SameSiteLaxMode
+	switch g.cookie.SameSite {
+	case "strict":
+		sameSite = http.SameSiteStrictMode
+	case "none":
+		sameSite = http.SameSiteNoneMode
+	}
+
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Domain:   g.cookie.Domain,
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: sameSite,
+	}
+}
+
+// writeAuthError - HTTP-статус ошибки сервиса авторизации
+func (g *gateway) writeAuthError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		writeError(w, http.StatusUnauthorized, "invalid email or password")
+	case errors.Is(err, auth.ErrInvalidToken):
+		writeError(w, http.StatusUnauthorized, "session expired or revoked")
+	case errors.Is(err, auth.ErrInvalidAppID):
+		writeError(w, http.StatusBadRequest, "invalid app_id")
+	case errors.Is(err, auth.ErrOrgNotFound):
+		writeError(w, http.StatusBadRequest, "organization not found")
+	case errors.Is(err, auth.ErrPasswordExpired),
+		errors.Is(err, auth.ErrTOSReacceptanceRequired),
+		errors.Is(err, auth.ErrConsentRequired),
+		errors.Is(err, auth.ErrTooManySessions):
+		writeError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, auth.ErrCaptchaRequired):
+		writeError(w, http.StatusForbidden, "captcha required")
+	case errors.Is(err, auth.ErrCaptchaInvalid):
+		writeError(w, http.StatusForbidden, "captcha verification failed")
+	case errors.Is(err, auth.ErrCaptchaUnavailable):
+		writeError(w, http.StatusServiceUnavailable, "captcha verification is unavailable")
+	default:
+		g.log.Error("gateway request failed", slog.String("error", err.Error()))
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
@@ -0,0 +1,219 @@
+package httpapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sso/internal/config"
+	"sso/internal/domain/models"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/maintenance"
+	"sso/internal/services/auth"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuth - сервис авторизации с одной учётной записью a@b.c / secret
+type fakeAuth struct {
+	ended map[string]bool
+}
+
+func (f *fakeAuth) Login(_ context.Context, email, password string, appID int, _, _, _ string) (models.Token, error) {
+	if email != "a@b.c" || password != "secret" {
+		return models.Token{}, fmt.Errorf("Auth.Login: %w", auth.ErrInvalidCredentials)
+	}
+
+	return models.Token{AccessToken: "jwt-sid-1", ExpiresAt: time.Now().Add(time.Hour), SessionID: "sid-1"}, nil
+}
+
+func (f *fakeAuth) Authenticate(_ context.Context, token string) (authctx.Principal, error) {
+	if token != "jwt-sid-1" || f.ended["sid-1"] {
+		return authctx.Principal{}, fmt.Errorf("Auth.Authenticate: %w", auth.ErrInvalidToken)
+	}
+
+	return authctx.Principal{UserID: 7, Email: "a@b.c", AppID: 1, SessionID: "sid-1"}, nil
+}
+
+func (f *fakeAuth) AuthenticateSession(_ context.Context, sid string) (authctx.Principal, error) {
+	if sid != "sid-1" || f.ended[sid] {
+		return authctx.Principal{}, fmt.Errorf("Auth.AuthenticateSession: %w", auth.ErrInvalidToken)
+	}
+
+	return authctx.Principal{UserID: 7, Email: "a@b.c", AppID: 1, SessionID: sid}, nil
+}
+
+func (f *fakeAuth) EndSession(_ context.Context, sid string) error {
+	if f.ended[sid] {
+		return fmt.Errorf("Auth.EndSession: %w", auth.ErrSessionNotFound)
+	}
+	f.ended[sid] = true
+
+	return nil
+}
+
+func newGateway(t *testing.T, cookie config.CookieConfig) (*http.ServeMux, *fakeAuth) {
+	t.Helper()
+
+	mux, fake, _ := newGatewayWithMaintenance(t, cookie, config.MaintenanceConfig{RetryAfter: 30 * time.Second})
+
+	return mux, fake
+}
+
+func newGatewayWithMaintenance(
+	t *testing.T,
+	cookie config.CookieConfig,
+	cfg config.MaintenanceConfig,
+) (*http.ServeMux, *fakeAuth, *maintenance.Mode) {
+	t.Helper()
+
+	fake := &fakeAuth{ended: map[string]bool{}}
+	mode := maintenance.New(cfg)
+	mux := http.NewServeMux()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registerGateway(mux, log, fake, nil, mode, config.GatewayConfig{Enabled: true, Cookie: cookie})
+
+	return mux, fake, mode
+}
+
+func serve(mux *http.ServeMux, req *http.Request, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func cookieNamed(rec *httptest.ResponseRecorder, name string) *http.Cookie {
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == name {
+			return c
+		}
+	}
+
+	return nil
+}
+
+const loginBody = `{"email":"a@b.c","password":"secret","app_id":1}`
+
+func TestGateway_CookieMode(t *testing.T) {
+	mux, fake := newGateway(t, config.CookieConfig{
+		Enabled: true, Name: "sso_session", Domain: "example.com", MaxAge: 30 * time.Minute, SameSite: "strict",
+	})
+
+	// Без CSRF-токена вход отклоняется
+	rec := serve(mux, httptest.NewRequest(http.MethodPost, "/v1/login", strings.NewReader(loginBody)))
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = serve(mux, httptest.NewRequest(http.MethodGet, "/v1/csrf", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	csrf := cookieNamed(rec, csrfCookie)
+	require.NotNil(t, csrf)
+	assert.False(t, csrf.HttpOnly)
+
+	login := httptest.NewRequest(http.MethodPost, "/v1/login", strings.NewReader(loginBody))
+	login.Header.Set(csrfHeader, csrf.Value)
+	rec = serve(mux, login, csrf)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "jwt-sid-1", "the token stays on the server")
+
+	session := cookieNamed(rec, "sso_session")
+	require.NotNil(t, session)
+	assert.Equal(t, "sid-1", session.Value)
+	assert.True(t, session.HttpOnly)
+	assert.True(t, session.Secure)
+	assert.Equal(t, http.SameSiteStrictMode, session.SameSite)
+	assert.Equal(t, "example.com", session.Domain)
+	assert.Equal(t, 1800, session.MaxAge)
+
+	rec = serve(mux, httptest.NewRequest(http.MethodGet, "/v1/me", nil), session)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var me map[string]any
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &me))
+	assert.EqualValues(t, 7, me["user_id"])
+
+	// Сессию находит сервис авторизации, а не память шлюза: cookie действует после перезапуска
+	restarted := http.NewServeMux()
+	registerGateway(restarted, slog.New(slog.NewTextHandler(io.Discard, nil)), fake, nil,
+		maintenance.New(config.MaintenanceConfig{}), config.GatewayConfig{Enabled: true, Cookie: config.CookieConfig{
+			Enabled: true, Name: "sso_session", MaxAge: 30 * time.Minute,
+		}})
+	rec = serve(restarted, httptest.NewRequest(http.MethodGet, "/v1/me", nil), session)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	// Чужая страница не знает значения CSRF-cookie
+	logout := httptest.NewRequest(http.MethodPost, "/v1/logout", nil)
+	logout.Header.Set(csrfHeader, "forged")
+	rec = serve(mux, logout, session, csrf)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	logout = httptest.NewRequest(http.MethodPost, "/v1/logout", nil)
+	logout.Header.Set(csrfHeader, csrf.Value)
+	rec = serve(mux, logout, session, csrf)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.True(t, fake.ended["sid-1"])
+	cleared := cookieNamed(rec, "sso_session")
+	require.NotNil(t, cleared)
+	assert.Negative(t, cleared.MaxAge)
+
+	rec = serve(mux, httptest.NewRequest(http.MethodGet, "/v1/me", nil), session)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestGateway_BearerMode(t *testing.T) {
+	mux, fake := newGateway(t, config.CookieConfig{})
+
+	rec := serve(mux, httptest.NewRequest(http.MethodPost, "/v1/login",
+		strings.NewReader(`{"email":"a@b.c","password":"wrong","app_id":1}`)))
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	rec = serve(mux, httptest.NewRequest(http.MethodPost, "/v1/login", strings.NewReader(loginBody)))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"access_token":"jwt-sid-1"`)
+	assert.Nil(t, cookieNamed(rec, "sso_session"))
+
+	// /v1/csrf есть только в режиме cookie
+	rec = serve(mux, httptest.NewRequest(http.MethodGet, "/v1/csrf", nil))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	logout := httptest.NewRequest(http.MethodPost, "/v1/logout", nil)
+	logout.Header.Set("Authorization", "Bearer jwt-sid-1")
+	rec = serve(mux, logout)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	assert.True(t, fake.ended["sid-1"])
+}
+
+func TestGateway_Maintenance(t *testing.T) {
+	mux, fake, mode := newGatewayWithMaintenance(t, config.CookieConfig{},
+		config.MaintenanceConfig{BlockLogin: true, RetryAfter: 45 * time.Second})
+	mode.Set(true, "migration")
+
+	rec := serve(mux, httptest.NewRequest(http.MethodPost, "/v1/login", strings.NewReader(loginBody)))
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "45", rec.Header().Get("Retry-After"))
+
+	logout := httptest.NewRequest(http.MethodPost, "/v1/logout", nil)
+	logout.Header.Set("Authorization", "Bearer jwt-sid-1")
+	rec = serve(mux, logout)
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.False(t, fake.ended["sid-1"])
+
+	// Проверка токена работает
+	me := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+	me.Header.Set("Authorization", "Bearer jwt-sid-1")
+	rec = serve(mux, me)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	mode.Configure(config.MaintenanceConfig{RetryAfter: 45 * time.Second})
+	rec = serve(mux, httptest.NewRequest(http.MethodPost, "/v1/login", strings.NewReader(loginBody)))
+	assert.Equal(t, http.StatusOK, rec.Code, "login is allowed unless block_login is set")
+}
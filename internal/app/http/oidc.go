@@ -0,0 +1,482 @@
+package httpapp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"embed"
+	"encoding/base64"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sso/internal/config"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/useragent"
+	"sso/internal/services/auth"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// oidcCSRFCookie - cookie с CSRF-токеном страницы входа (double-submit со скрытым полем формы)
+const oidcCSRFCookie = "sso_oidc_csrf"
+
+// authorizeParams - параметры запроса авторизации, которые страница передаёт обратно в форме
+var authorizeParams = []string{
+	"client_id", "redirect_uri", "response_type", "scope", "state", "nonce", "code_challenge", "code_challenge_method",
+}
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var (
+	authorizePage = template.Must(template.ParseFS(templateFS, "templates/authorize.html"))
+	errorPage     = template.Must(template.ParseFS(templateFS, "templates/error.html"))
+)
+
+// OIDCProvider - сервис авторизации за эндпоинтами OpenID Connect
+type OIDCProvider interface {
+	CheckAuthorizationRequest(ctx context.Context, r models.AuthorizationRequest) (models.App, error)
+	AuthorizeWithPassword(
+		ctx context.Context,
+		r models.AuthorizationRequest,
+		email, password, captchaToken string,
+		grantConsent bool,
+	) (string, error)
+	ExchangeAuthCode(
+		ctx context.Context,
+		clientID int,
+		clientSecret, code, redirectURI, codeVerifier string,
+	) (models.Token, string, error)
+	PublicKeys(ctx context.Context) (jwt.JWKS, error)
+}
+
+// oidcProvider - эндпоинты OpenID Connect: discovery, JWKS, страница входа и token endpoint.
+// Поддерживается только authorization code flow с PKCE для приложений с секретом
+type oidcProvider struct {
+	log         *slog.Logger
+	auth        OIDCProvider
+	issuer      string
+	maintenance Maintenance
+}
+
+// registerOIDC - регистрирует /.well-known/openid-configuration, /.well-known/jwks.json,
+// /oauth2/authorize и /oauth2/token
+func registerOIDC(mux *http.ServeMux, log *slog.Logger, p OIDCProvider, mode Maintenance, cfg config.OIDCConfig) {
+	o := &oidcProvider{log: log, auth: p, issuer: cfg.Issuer, maintenance: mode}
+
+	mux.HandleFunc("GET /.well-known/openid-configuration", o.discovery)
+	mux.HandleFunc("GET /.well-known/jwks.json", o.jwks)
+	mux.HandleFunc("GET /oauth2/authorize", o.authorizePage)
+	mux.HandleFunc("POST /oauth2/authorize", o.authorize)
+	mux.HandleFunc("POST /oauth2/token", o.token)
+}
+
+type discoveryDocument struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	JWKSURI                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IDTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+}
+
+func (o *oidcProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, discoveryDocument{
+		Issuer:                            o.issuer,
+		AuthorizationEndpoint:             o.issuer + "/oauth2/authorize",
+		TokenEndpoint:                     o.issuer + "/oauth2/token",
+		JWKSURI:                           o.issuer + "/.well-known/jwks.json",
+		ResponseTypesSupported:            []string{"code"},
+		GrantTypesSupported:               []string{"authorization_code"},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{jwt.AlgHS256, jwt.AlgES256, jwt.AlgEdDSA},
+		CodeChallengeMethodsSupported:     []string{"S256"},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post"},
+		ScopesSupported:                   []string{auth.ScopeOpenID, "email"},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce", "email", "amr", "acr", "sid",
+		},
+	})
+}
+
+func (o *oidcProvider) jwks(w http.ResponseWriter, r *http.Request) {
+	set, err := o.auth.PublicKeys(r.Context())
+	if err != nil {
+		o.log.Error("failed to load public keys", slog.String("error", err.Error()))
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, set)
+}
+
+// authorizeView - данные страницы входа
+type authorizeView struct {
+	AppName         string
+	ThirdParty      bool
+	Scopes          []string
+	Params          map[string]string
+	CSRFToken       string
+	Email           string
+	Error           string
+	CaptchaRequired bool
+}
+
+// authorizePage - проверяет запрос авторизации и показывает страницу входа
+func (o *oidcProvider) authorizePage(w http.ResponseWriter, r *http.Request) {
+	req, app, ok := o.checkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	token := ""
+	if c, err := r.Cookie(oidcCSRFCookie); err == nil && c.Value != "" {
+		token = c.Value
+	} else {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			o.errorPage(w, http.StatusInternalServerError, "Internal error.")
+			return
+		}
+		token = base64.RawURLEncoding.EncodeToString(b)
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcCSRFCookie,
+		Value:    token,
+		Path:     "/oauth2/authorize",
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	o.renderAuthorize(w, http.StatusOK, r, req, app, token, "", false)
+}
+
+// authorize - вход с формы страницы: при успехе возвращает браузер на redirect_uri с кодом
+func (o *oidcProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<16)
+	if err := r.ParseForm(); err != nil {
+		o.errorPage(w, http.StatusBadRequest, "Invalid form.")
+		return
+	}
+
+	c, err := r.Cookie(oidcCSRFCookie)
+	if err != nil || c.Value == "" ||
+		subtle.ConstantTimeCompare([]byte(c.Value), []byte(r.PostForm.Get("csrf_token"))) != 1 {
+		o.errorPage(w, http.StatusForbidden, "The sign-in form has expired. Reload the page and try again.")
+		return
+	}
+
+	req, app, ok := o.checkRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if r.PostForm.Get("action") == "deny" {
+		redirectError(w, r, req, "access_denied", "the user cancelled the sign-in")
+		return
+	}
+
+	if o.maintenance.Enabled() && !o.maintenance.AllowLogin() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(o.maintenance.RetryAfter().Seconds())))
+		o.renderAuthorize(w, http.StatusServiceUnavailable, r, req, app, c.Value,
+			"The service is in maintenance mode, try again later.", false)
+		return
+	}
+
+	ctx := useragent.Into(r.Context(), r.UserAgent())
+	code, err := o.auth.AuthorizeWithPassword(ctx, req,
+		r.PostForm.Get("email"), r.PostForm.Get("password"), r.PostForm.Get("captcha_token"),
+		r.PostForm.Get("consent") == "yes")
+	if err != nil {
+		o.authorizeError(w, r, req, app, c.Value, err)
+		return
+	}
+
+	redirect(w, r, req.RedirectURI, url.Values{"code": {code}, "state": {req.State}})
+}
+
+// authorizeError - ошибки входа, которые может исправить пользователь, показываются на странице,
+// остальные возвращаются приложению
+func (o *oidcProvider) authorizeError(
+	w http.ResponseWriter,
+	r *http.Request,
+	req models.AuthorizationRequest,
+	app models.App,
+	csrfToken string,
+	err error,
+) {
+	switch {
+	case errors.Is(err, auth.ErrInvalidCredentials):
+		o.renderAuthorize(w, http.StatusUnauthorized, r, req, app, csrfToken, "Invalid email or password.", false)
+	case errors.Is(err, auth.ErrConsentRequired):
+		o.renderAuthorize(w, http.StatusForbidden, r, req, app, csrfToken,
+			"Allow access to continue signing in.", false)
+	case errors.Is(err, auth.ErrCaptchaRequired), errors.Is(err, auth.ErrCaptchaInvalid):
+		o.renderAuthorize(w, http.StatusForbidden, r, req, app, csrfToken, "Complete the CAPTCHA to continue.", true)
+	case errors.Is(err, auth.ErrCaptchaUnavailable):
+		o.renderAuthorize(w, http.StatusServiceUnavailable, r, req, app, csrfToken,
+			"CAPTCHA verification is temporarily unavailable, try again later.", true)
+	case errors.Is(err, auth.ErrMFARequired):
+		o.renderAuthorize(w, http.StatusForbidden, r, req, app, csrfToken,
+			"This account uses a second factor, which this page does not support yet.", false)
+	case errors.Is(err, auth.ErrInvalidAuthRequest):
+		redirectError(w, r, req, "invalid_request", errorDescription(err, auth.ErrInvalidAuthRequest))
+	case errors.Is(err, auth.ErrPasswordExpired):
+		redirectError(w, r, req, "access_denied", auth.ErrPasswordExpired.Error())
+	case errors.Is(err, auth.ErrTOSReacceptanceRequired):
+		redirectError(w, r, req, "access_denied", auth.ErrTOSReacceptanceRequired.Error())
+	case errors.Is(err, auth.ErrTooManySessions):
+		redirectError(w, r, req, "access_denied", auth.ErrTooManySessions.Error())
+	default:
+		o.log.Error("authorization failed", slog.String("error", err.Error()))
+		redirectError(w, r, req, "server_error", "internal error")
+	}
+}
+
+// checkRequest - проверяет запрос авторизации. Пока client_id и redirect_uri не подтверждены, ошибка
+// показывается пользователю: перенаправление на неизвестный адрес сделало бы сервис открытым редиректом
+func (o *oidcProvider) checkRequest(w http.ResponseWriter, r *http.Request) (models.AuthorizationRequest, models.App, bool) {
+	params := formValues(r)
+	req := models.AuthorizationRequest{
+		RedirectURI:   params.Get("redirect_uri"),
+		Scope:         params.Get("scope"),
+		State:         params.Get("state"),
+		Nonce:         params.Get("nonce"),
+		CodeChallenge: params.Get("code_challenge"),
+	}
+
+	clientID, err := strconv.Atoi(params.Get("client_id"))
+	if err != nil {
+		o.errorPage(w, http.StatusBadRequest, "The application did not identify itself (client_id).")
+		return req, models.App{}, false
+	}
+	req.ClientID = clientID
+
+	app, err := o.auth.CheckAuthorizationRequest(r.Context(), req)
+	switch {
+	case err == nil:
+	case errors.Is(err, auth.ErrInvalidAppID):
+		o.errorPage(w, http.StatusBadRequest, "Unknown application.")
+		return req, models.App{}, false
+	case errors.Is(err, auth.ErrInvalidRedirectURI):
+		o.errorPage(w, http.StatusBadRequest, "The application's return address is not registered.")
+		return req, models.App{}, false
+	case errors.Is(err, auth.ErrInvalidAuthRequest):
+		redirectError(w, r, req, "invalid_request", errorDescription(err, auth.ErrInvalidAuthRequest))
+		return req, models.App{}, false
+	default:
+		o.log.Error("failed to check authorization request", slog.String("error", err.Error()))
+		o.errorPage(w, http.StatusInternalServerError, "Internal error.")
+		return req, models.App{}, false
+	}
+
+	if params.Get("response_type") != "code" {
+		redirectError(w, r, req, "unsupported_response_type", "only response_type=code is supported")
+		return req, models.App{}, false
+	}
+	if params.Get("code_challenge_method") != "S256" {
+		redirectError(w, r, req, "invalid_request", "code_challenge_method must be S256")
+		return req, models.App{}, false
+	}
+
+	return req, app, true
+}
+
+func (o *oidcProvider) renderAuthorize(
+	w http.ResponseWriter,
+	code int,
+	r *http.Request,
+	req models.AuthorizationRequest,
+	app models.App,
+	csrfToken, message string,
+	captcha bool,
+) {
+	params := formValues(r)
+	view := authorizeView{
+		AppName:         app.Name,
+		ThirdParty:      app.ThirdParty,
+		Scopes:          strings.Fields(req.Scope),
+		Params:          make(map[string]string, len(authorizeParams)),
+		CSRFToken:       csrfToken,
+		Email:           params.Get("email"),
+		Error:           message,
+		CaptchaRequired: captcha || params.Get("captcha_token") != "",
+	}
+	for _, name := range authorizeParams {
+		view.Params[name] = params.Get(name)
+	}
+
+	pageHeaders(w)
+	w.WriteHeader(code)
+	if err := authorizePage.Execute(w, view); err != nil {
+		o.log.Error("failed to render authorization page", slog.String("error", err.Error()))
+	}
+}
+
+func (o *oidcProvider) errorPage(w http.ResponseWriter, code int, message string) {
+	pageHeaders(w)
+	w.WriteHeader(code)
+	if err := errorPage.Execute(w, message); err != nil {
+		o.log.Error("failed to render error page", slog.String("error", err.Error()))
+	}
+}
+
+// pageHeaders - страница входа не кешируется и не встраивается в чужие фреймы (clickjacking)
+func pageHeaders(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; form-action 'self'; frame-ancestors 'none'")
+}
+
+// formValues - параметры запроса: для GET - из адреса, для POST - из формы
+func formValues(r *http.Request) url.Values {
+	if r.Method == http.MethodPost {
+		return r.PostForm
+	}
+
+	return r.URL.Query()
+}
+
+// errorDescription - текст ошибки для приложения, начиная с target: имена операций из цепочки
+// оборачивания наружу не отдаются
+func errorDescription(err, target error) string {
+	msg := err.Error()
+	if i := strings.Index(msg, target.Error()); i >= 0 {
+		return msg[i:]
+	}
+
+	return target.Error()
+}
+
+// redirectError - ошибка запроса авторизации для приложения (RFC 6749, 4.1.2.1)
+func redirectError(w http.ResponseWriter, r *http.Request, req models.AuthorizationRequest, code, description string) {
+	params := url.Values{"error": {code}, "error_description": {description}}
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	redirect(w, r, req.RedirectURI, params)
+}
+
+// redirect - возвращает браузер на подтверждённый redirect_uri, сохраняя его собственные параметры
+func redirect(w http.ResponseWriter, r *http.Request, redirectURI string, params url.Values) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		// Адрес уже совпал с зарегистрированным, а их проверяет Admin.SetAppRedirectURIs
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	q := u.Query()
+	for name, values := range params {
+		if values[0] != "" {
+			q.Set(name, values[0])
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, u.String(), http.StatusSeeOther)
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope,omitempty"`
+}
+
+// token - обмен кода авторизации на токены (RFC 6749, 4.1.3). Приложение подтверждает себя секретом
+// в заголовке Authorization (client_secret_basic) или в теле запроса (client_secret_post)
+func (o *oidcProvider) token(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<16)
+	if err := r.ParseForm(); err != nil {
+		writeTokenError(w, http.StatusBadRequest, "invalid_request", "invalid form body")
+		return
+	}
+	form := r.PostForm
+
+	if o.maintenance.Enabled() && !o.maintenance.AllowLogin() {
+		w.Header().Set("Retry-After", strconv.Itoa(int(o.maintenance.RetryAfter().Seconds())))
+		writeTokenError(w, http.StatusServiceUnavailable, "temporarily_unavailable", "service is in maintenance mode")
+		return
+	}
+
+	clientID, secret, basic := r.BasicAuth()
+	if basic {
+		if form.Has("client_secret") {
+			writeTokenError(w, http.StatusBadRequest, "invalid_request", "use only one client authentication method")
+			return
+		}
+		// Учётные данные в Basic кодируются как application/x-www-form-urlencoded (RFC 6749, 2.3.1)
+		clientID, _ = url.QueryUnescape(clientID)
+		secret, _ = url.QueryUnescape(secret)
+	} else {
+		clientID, secret = form.Get("client_id"), form.Get("client_secret")
+	}
+
+	if grant := form.Get("grant_type"); grant != "authorization_code" {
+		writeTokenError(w, http.StatusBadRequest, "unsupported_grant_type", "only authorization_code is supported")
+		return
+	}
+	if form.Get("code") == "" || form.Get("redirect_uri") == "" || form.Get("code_verifier") == "" {
+		writeTokenError(w, http.StatusBadRequest, "invalid_request", "code, redirect_uri and code_verifier are required")
+		return
+	}
+
+	appID, err := strconv.Atoi(clientID)
+	if err != nil || secret == "" {
+		o.invalidClient(w, basic)
+		return
+	}
+
+	token, idToken, err := o.auth.ExchangeAuthCode(r.Context(), appID, secret,
+		form.Get("code"), form.Get("redirect_uri"), form.Get("code_verifier"))
+	switch {
+	case err == nil:
+	case errors.Is(err, auth.ErrInvalidClient):
+		o.invalidClient(w, basic)
+		return
+	case errors.Is(err, auth.ErrInvalidGrant):
+		writeTokenError(w, http.StatusBadRequest, "invalid_grant", "invalid, expired or already used authorization code")
+		return
+	default:
+		o.log.Error("code exchange failed", slog.String("error", err.Error()))
+		writeTokenError(w, http.StatusInternalServerError, "server_error", "internal error")
+		return
+	}
+
+	w.Header().Set("Pragma", "no-cache")
+	writeJSON(w, http.StatusOK, tokenResponse{
+		AccessToken: token.AccessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   max(int64(time.Until(token.ExpiresAt).Seconds()), 0),
+		IDToken:     idToken,
+		Scope:       strings.Join(token.Scopes, " "),
+	})
+}
+
+func (o *oidcProvider) invalidClient(w http.ResponseWriter, basic bool) {
+	if basic {
+		w.Header().Set("WWW-Authenticate", `Basic realm="sso"`)
+	}
+	writeTokenError(w, http.StatusUnauthorized, "invalid_client", "invalid client credentials")
+}
+
+// writeTokenError - ошибка token endpoint (RFC 6749, 5.2)
+func writeTokenError(w http.ResponseWriter, code int, errCode, description string) {
+	writeJSON(w, code, map[string]string{"error": errCode, "error_description": description})
+}
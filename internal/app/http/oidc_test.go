@@ -0,0 +1,260 @@
+package httpapp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sso/internal/config"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/maintenance"
+	"sso/internal/services/auth"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOIDC - приложение 1 с адресом возврата https://app.example/cb и учётной записью a@b.c / secret
+type fakeOIDC struct {
+	codes map[string]models.AuthorizationRequest
+}
+
+func (f *fakeOIDC) CheckAuthorizationRequest(_ context.Context, r models.AuthorizationRequest) (models.App, error) {
+	switch {
+	case r.ClientID != 1:
+		return models.App{}, fmt.Errorf("Auth.CheckAuthorizationRequest: %w", auth.ErrInvalidAppID)
+	case r.RedirectURI != "https://app.example/cb":
+		return models.App{}, fmt.Errorf("Auth.CheckAuthorizationRequest: %w", auth.ErrInvalidRedirectURI)
+	case !strings.Contains(r.Scope, "openid"):
+		return models.App{}, fmt.Errorf("Auth.CheckAuthorizationRequest: %w: scope must include openid", auth.ErrInvalidAuthRequest)
+	}
+
+	return models.App{ID: 1, Name: "Grafana"}, nil
+}
+
+func (f *fakeOIDC) AuthorizeWithPassword(
+	ctx context.Context,
+	r models.AuthorizationRequest,
+	email, password, _ string,
+	_ bool,
+) (string, error) {
+	if _, err := f.CheckAuthorizationRequest(ctx, r); err != nil {
+		return "", err
+	}
+	if email != "a@b.c" || password != "secret" {
+		return "", fmt.Errorf("Auth.AuthorizeWithPassword: %w", auth.ErrInvalidCredentials)
+	}
+
+	code := fmt.Sprintf("code-%d", len(f.codes)+1)
+	f.codes[code] = r
+
+	return code, nil
+}
+
+func (f *fakeOIDC) ExchangeAuthCode(
+	_ context.Context,
+	clientID int,
+	clientSecret, code, redirectURI, codeVerifier string,
+) (models.Token, string, error) {
+	if clientID != 1 || clientSecret != "s3cret&" {
+		return models.Token{}, "", fmt.Errorf("Auth.ExchangeAuthCode: %w", auth.ErrInvalidClient)
+	}
+	r, ok := f.codes[code]
+	delete(f.codes, code)
+	if !ok || r.RedirectURI != redirectURI || codeVerifier == "" {
+		return models.Token{}, "", fmt.Errorf("Auth.ExchangeAuthCode: %w", auth.ErrInvalidGrant)
+	}
+
+	return models.Token{AccessToken: "access", ExpiresAt: time.Now().Add(time.Hour), Scopes: []string{"openid"}}, "id", nil
+}
+
+func (f *fakeOIDC) PublicKeys(context.Context) (jwt.JWKS, error) {
+	return jwt.JWKS{Keys: []jwt.JWK{{Kty: "OKP", Crv: "Ed25519", X: "x", Alg: jwt.AlgEdDSA, Use: "sig", Kid: "1"}}}, nil
+}
+
+func newOIDC(t *testing.T) *http.ServeMux {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	registerOIDC(mux, log, &fakeOIDC{codes: map[string]models.AuthorizationRequest{}},
+		maintenance.New(config.MaintenanceConfig{}), config.OIDCConfig{Enabled: true, Issuer: "https://sso.example"})
+
+	return mux
+}
+
+var authorizeQuery = url.Values{
+	"client_id":             {"1"},
+	"redirect_uri":          {"https://app.example/cb"},
+	"response_type":         {"code"},
+	"scope":                 {"openid email"},
+	"state":                 {"xyz"},
+	"code_challenge":        {"E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"},
+	"code_challenge_method": {"S256"},
+}
+
+func authorizeURL(modify func(q url.Values)) string {
+	q := url.Values{}
+	for k, v := range authorizeQuery {
+		q[k] = v
+	}
+	if modify != nil {
+		modify(q)
+	}
+
+	return "/oauth2/authorize?" + q.Encode()
+}
+
+func TestOIDC_Discovery(t *testing.T) {
+	mux := newOIDC(t)
+
+	rec := serve(mux, httptest.NewRequest(http.MethodGet, "/.well-known/openid-configuration", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var doc map[string]any
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&doc))
+	assert.Equal(t, "https://sso.example", doc["issuer"])
+	assert.Equal(t, "https://sso.example/oauth2/token", doc["token_endpoint"])
+	assert.Equal(t, "https://sso.example/.well-known/jwks.json", doc["jwks_uri"])
+	assert.Equal(t, []any{"code"}, doc["response_types_supported"])
+	assert.Equal(t, []any{"S256"}, doc["code_challenge_methods_supported"])
+
+	rec = serve(mux, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"kid":"1"`)
+}
+
+// Неподтверждённый адрес возврата - страница с ошибкой, а не перенаправление
+func TestOIDC_AuthorizeRejectsUnknownRedirect(t *testing.T) {
+	mux := newOIDC(t)
+
+	rec := serve(mux, httptest.NewRequest(http.MethodGet, authorizeURL(func(q url.Values) {
+		q.Set("redirect_uri", "https://evil.example/cb")
+	}), nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, rec.Header().Get("Location"))
+
+	// После проверки адреса ошибки возвращаются приложению
+	rec = serve(mux, httptest.NewRequest(http.MethodGet, authorizeURL(func(q url.Values) {
+		q.Set("response_type", "token")
+	}), nil))
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "unsupported_response_type", loc.Query().Get("error"))
+	assert.Equal(t, "xyz", loc.Query().Get("state"))
+
+	rec = serve(mux, httptest.NewRequest(http.MethodGet, authorizeURL(func(q url.Values) { q.Set("scope", "email") }), nil))
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	loc, err = url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "invalid_request", loc.Query().Get("error"))
+	assert.Equal(t, "invalid authorization request: scope must include openid", loc.Query().Get("error_description"))
+}
+
+func TestOIDC_CodeFlow(t *testing.T) {
+	mux := newOIDC(t)
+
+	rec := serve(mux, httptest.NewRequest(http.MethodGet, authorizeURL(nil), nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "DENY", rec.Header().Get("X-Frame-Options"))
+	csrf := cookieNamed(rec, oidcCSRFCookie)
+	require.NotNil(t, csrf)
+	assert.Contains(t, rec.Body.String(), `value="`+csrf.Value+`"`)
+
+	form := func(csrfToken, password string) *http.Request {
+		f := url.Values{"csrf_token": {csrfToken}, "email": {"a@b.c"}, "password": {password}, "action": {"allow"}}
+		for k, v := range authorizeQuery {
+			f[k] = v
+		}
+		req := httptest.NewRequest(http.MethodPost, "/oauth2/authorize", strings.NewReader(f.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		return req
+	}
+
+	// Форма без CSRF-токена из cookie отклоняется
+	rec = serve(mux, form("forged", "secret"), csrf)
+	require.Equal(t, http.StatusForbidden, rec.Code)
+
+	// Неверный пароль показывается на странице
+	rec = serve(mux, form(csrf.Value, "wrong"), csrf)
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Invalid email or password")
+
+	rec = serve(mux, form(csrf.Value, "secret"), csrf)
+	require.Equal(t, http.StatusSeeOther, rec.Code)
+	loc, err := url.Parse(rec.Header().Get("Location"))
+	require.NoError(t, err)
+	assert.Equal(t, "app.example", loc.Host)
+	assert.Equal(t, "xyz", loc.Query().Get("state"))
+	code := loc.Query().Get("code")
+	require.NotEmpty(t, code)
+
+	exchange := func() *httptest.ResponseRecorder {
+		body := url.Values{
+			"grant_type": {"authorization_code"}, "code": {code},
+			"redirect_uri": {"https://app.example/cb"}, "code_verifier": {"verifier"},
+		}
+		req := httptest.NewRequest(http.MethodPost, "/oauth2/token", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("1", url.QueryEscape("s3cret&"))
+
+		return serve(mux, req)
+	}
+
+	rec = exchange()
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	var resp tokenResponse
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal(t, "access", resp.AccessToken)
+	assert.Equal(t, "id", resp.IDToken)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Positive(t, resp.ExpiresIn)
+
+	rec = exchange()
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"error":"invalid_grant"`)
+}
+
+func TestOIDC_TokenErrors(t *testing.T) {
+	mux := newOIDC(t)
+
+	post := func(body url.Values) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/oauth2/token", strings.NewReader(body.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		return serve(mux, req)
+	}
+	errorOf := func(rec *httptest.ResponseRecorder) string {
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&body))
+
+		return body["error"]
+	}
+
+	rec := post(url.Values{"grant_type": {"password"}})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "unsupported_grant_type", errorOf(rec))
+
+	rec = post(url.Values{"grant_type": {"authorization_code"}, "code": {"c"}})
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "invalid_request", errorOf(rec))
+
+	rec = post(url.Values{
+		"grant_type": {"authorization_code"}, "code": {"c"}, "redirect_uri": {"https://app.example/cb"},
+		"code_verifier": {"v"}, "client_id": {"1"}, "client_secret": {"wrong"},
+	})
+	require.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "invalid_client", errorOf(rec))
+	assert.Empty(t, rec.Header().Get("WWW-Authenticate"))
+}
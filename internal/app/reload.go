@@ -0,0 +1,128 @@
+package app
+
+import (
+	"log/slog"
+	"reflect"
+	"sso/internal/config"
+	"sso/internal/services/auth"
+)
+
+// Reload - применяет новую конфигурацию на лету.
+// Применяются только безопасные настройки (уровень логирования, TTL токенов, ограничения регистрации,
+// версия условий использования);
+// изменения, требующие рестарта (порт, хранилище, секреты), игнорируются с предупреждением.
+// Возвращает списки применённых и проигнорированных полей
+func (a *App) Reload(cfg *config.Config) (applied, ignored []string) {
+	const op = "app.Reload"
+
+	log := a.log.With(slog.String("op", op))
+
+	if cfg.Log.Level != a.cfg.Log.Level {
+		level, err := config.ParseLevel(cfg.Log.Level, cfg.Env)
+		if err == nil {
+			a.logLevel.Set(level)
+			a.cfg.Log.Level = cfg.Log.Level
+			applied = append(applied, "log.level")
+		}
+	}
+
+	if cfg.TokenTTL != a.cfg.TokenTTL {
+		a.authService.SetTokenTTL(cfg.TokenTTL)
+		a.cfg.TokenTTL = cfg.TokenTTL
+		applied = append(applied, "token_ttl")
+	}
+
+	if !reflect.DeepEqual(cfg.Registration, a.cfg.Registration) {
+		a.authService.SetDomainPolicy(auth.NewDomainPolicy(cfg.Registration.AllowedDomains, cfg.Registration.BlockedDomains))
+		a.cfg.Registration = cfg.Registration
+		applied = append(applied, "registration")
+	}
+
+	if cfg.TOS != a.cfg.TOS {
+		a.authService.SetTOSPolicy(auth.TOSPolicy{Version: cfg.TOS.Version, EnforceOnLogin: cfg.TOS.EnforceOnLogin})
+		a.cfg.TOS = cfg.TOS
+		applied = append(applied, "tos")
+	}
+
+	// Сам режим обслуживания при этом не меняется: он включается только во время работы
+	if cfg.Maintenance != a.cfg.Maintenance {
+		a.maintenance.Configure(cfg.Maintenance)
+		a.cfg.Maintenance = cfg.Maintenance
+		applied = append(applied, "maintenance")
+	}
+
+	if !reflect.DeepEqual(cfg.RateLimit, a.cfg.RateLimit) {
+		a.limiter.Configure(cfg.RateLimit)
+		a.cfg.RateLimit = cfg.RateLimit
+		applied = append(applied, "rate_limit")
+	}
+
+	if !reflect.DeepEqual(cfg.Features, a.cfg.Features) {
+		a.features.Configure(cfg.Features)
+		a.cfg.Features = cfg.Features
+		applied = append(applied, "features")
+	}
+
+	// Эти настройки читаются только при старте
+	restartOnly := []struct {
+		name     string
+		old, new any
+	}{
+		{name: "env", old: a.cfg.Env, new: cfg.Env},
+		{name: "log.format", old: a.cfg.Log.Format, new: cfg.Log.Format},
+		{name: "log.output", old: logOutput(a.cfg.Log), new: logOutput(cfg.Log)},
+		{name: "storage_path", old: a.cfg.StoragePath, new: cfg.StoragePath},
+		{name: "replicas", old: a.cfg.Replicas, new: cfg.Replicas},
+		{name: "migrations", old: a.cfg.Migrations, new: cfg.Migrations},
+		{name: "user_apps", old: a.cfg.UserApps, new: cfg.UserApps},
+		{name: "grpc", old: a.cfg.GRPC, new: cfg.GRPC},
+		{name: "http", old: a.cfg.HTTP, new: cfg.HTTP},
+		{name: "jwt", old: a.cfg.JWT, new: cfg.JWT},
+		{name: "password", old: a.cfg.Password, new: cfg.Password},
+		{name: "events", old: a.cfg.Events, new: cfg.Events},
+		{name: "mail", old: a.cfg.Mail, new: cfg.Mail},
+		{name: "pepper", old: a.cfg.Pepper, new: cfg.Pepper},
+		{name: "pii_key", old: a.cfg.PIIKey, new: cfg.PIIKey},
+		{name: "secrets_dir", old: a.cfg.SecretsDir, new: cfg.SecretsDir},
+		{name: "metadata", old: a.cfg.Metadata, new: cfg.Metadata},
+		{name: "account_deletion", old: a.cfg.AccountDeletion, new: cfg.AccountDeletion},
+		{name: "sessions", old: a.cfg.Sessions, new: cfg.Sessions},
+		{name: "offline_tokens", old: a.cfg.OfflineTokens, new: cfg.OfflineTokens},
+		{name: "refresh", old: a.cfg.Refresh, new: cfg.Refresh},
+		{name: "provisioning", old: a.cfg.Provisioning, new: cfg.Provisioning},
+		{name: "guests", old: a.cfg.Guests, new: cfg.Guests},
+		{name: "device", old: a.cfg.Device, new: cfg.Device},
+		{name: "passkey", old: a.cfg.Passkey, new: cfg.Passkey},
+		{name: "sms", old: a.cfg.SMS, new: cfg.SMS},
+		{name: "captcha", old: a.cfg.Captcha, new: cfg.Captcha},
+		{name: "janitor", old: a.cfg.Janitor, new: cfg.Janitor},
+		{name: "metrics", old: a.cfg.Metrics, new: cfg.Metrics},
+		{name: "selftest", old: a.cfg.SelfTest, new: cfg.SelfTest},
+		{name: "revocations", old: a.cfg.Revocations, new: cfg.Revocations},
+		{name: "geo", old: a.cfg.Geo, new: cfg.Geo},
+		{name: "outbound", old: a.cfg.Outbound, new: cfg.Outbound},
+		{name: "login_alerts", old: a.cfg.LoginAlerts, new: cfg.LoginAlerts},
+		{name: "audit", old: a.cfg.Audit, new: cfg.Audit},
+		{name: "error_reporting", old: a.cfg.ErrorReporting, new: cfg.ErrorReporting},
+		{name: "schema", old: a.cfg.Schema, new: cfg.Schema},
+	}
+	for _, f := range restartOnly {
+		if !reflect.DeepEqual(f.old, f.new) {
+			ignored = append(ignored, f.name)
+		}
+	}
+
+	log.Info("config reloaded",
+		slog.Any("applied", applied),
+		slog.Any("ignored_require_restart", ignored),
+	)
+
+	return applied, ignored
+}
+
+// logOutput - настройки файла лога без уровня и формата
+func logOutput(l config.LogConfig) config.LogConfig {
+	l.Level, l.Format = "", ""
+
+	return l
+}
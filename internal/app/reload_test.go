@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sso/internal/config"
+	"sso/internal/lib/features"
+	"sso/internal/lib/maintenance"
+	"sso/internal/lib/ratelimit"
+	"sso/internal/services/auth"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReload(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	level := new(slog.LevelVar)
+
+	current := &config.Config{
+		Env:         config.EnvProd,
+		StoragePath: "./storage/sso.db",
+		TokenTTL:    time.Hour,
+		GRPC:        config.GRPCConfig{Port: 44044},
+	}
+
+	a := &App{
+		log:         log,
+		logLevel:    level,
+		authService: auth.New(log, nil, nil, nil, current.TokenTTL),
+		cfg:         current,
+	}
+
+	next := *current
+	next.TokenTTL = 2 * time.Hour
+	next.Log.Level = "warn"
+	next.GRPC.Port = 55055
+	next.StoragePath = "./other.db"
+	next.Registration.AllowedDomains = []string{"ourcompany.com"}
+
+	applied, ignored := a.Reload(&next)
+
+	assert.ElementsMatch(t, []string{"log.level", "token_ttl", "registration"}, applied)
+	assert.ElementsMatch(t, []string{"grpc", "storage_path"}, ignored)
+	assert.Equal(t, slog.LevelWarn, level.Level())
+	assert.Equal(t, 2*time.Hour, current.TokenTTL)
+	assert.Equal(t, 44044, current.GRPC.Port, "port must not change without restart")
+
+	_, err := a.authService.RegisterNewUser(context.Background(), "a@gmail.com", "password", "", "", "", 0)
+	require.ErrorIs(t, err, auth.ErrDomainNotAllowed)
+}
+
+func TestReload_KeepsMaintenanceMode(t *testing.T) {
+	current := &config.Config{Maintenance: config.MaintenanceConfig{RetryAfter: 30 * time.Second}}
+	mode := maintenance.New(current.Maintenance)
+	a := &App{log: slog.New(slog.NewTextHandler(io.Discard, nil)), cfg: current, maintenance: mode}
+
+	require.True(t, mode.Set(true, "migration"))
+
+	next := *current
+	next.Maintenance = config.MaintenanceConfig{BlockLogin: true, RetryAfter: time.Minute}
+
+	applied, _ := a.Reload(&next)
+	assert.Contains(t, applied, "maintenance")
+	assert.True(t, mode.Enabled(), "reload must not turn maintenance mode off")
+	assert.False(t, mode.AllowLogin())
+	assert.Equal(t, time.Minute, mode.RetryAfter())
+}
+
+func TestReload_RateLimit(t *testing.T) {
+	current := &config.Config{}
+	limiter := ratelimit.New(current.RateLimit)
+	a := &App{log: slog.New(slog.NewTextHandler(io.Discard, nil)), cfg: current, limiter: limiter}
+
+	next := *current
+	next.RateLimit = config.RateLimitConfig{
+		Enabled: true,
+		Rules:   []config.RateLimitRule{{Method: "/auth.Auth/Register", Rate: 1, Burst: 1}},
+	}
+
+	applied, ignored := a.Reload(&next)
+	assert.Contains(t, applied, "rate_limit")
+	assert.Empty(t, ignored)
+
+	_, ok := limiter.Allow("/auth.Auth/Register", 0)
+	require.True(t, ok)
+	_, ok = limiter.Allow("/auth.Auth/Register", 0)
+	assert.False(t, ok, "new limits apply without restart")
+}
+
+func TestReload_Features(t *testing.T) {
+	current := &config.Config{}
+	flags := features.New(current.Features)
+	a := &App{log: slog.New(slog.NewTextHandler(io.Discard, nil)), cfg: current, features: flags}
+
+	next := *current
+	next.Features = map[string]features.Rule{string(features.StrictScopes): {AppIDs: []int{3}}}
+
+	applied, ignored := a.Reload(&next)
+	assert.Contains(t, applied, "features")
+	assert.Empty(t, ignored)
+
+	assert.True(t, flags.Enabled(features.StrictScopes, 3, 1), "new rules apply without restart")
+	assert.False(t, flags.Enabled(features.StrictScopes, 4, 1))
+}
@@ -0,0 +1,80 @@
+// Package buildinfo - сведения о сборке (версия, коммит, дата).
+//
+// Значения задаются при сборке через ldflags:
+//
+//	go build -ldflags "-X sso/internal/buildinfo.Version=v1.2.3 \
+//		-X sso/internal/buildinfo.Commit=$(git rev-parse HEAD) \
+//		-X sso/internal/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)" ./cmd/sso
+//
+// Если ldflags не заданы, коммит и дата берутся из VCS-информации, которую Go встраивает сам.
+package buildinfo
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime"
+	"runtime/debug"
+)
+
+// Задаются через -ldflags "-X ..."
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info - сведения о запущенной сборке
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get - возвращает сведения о сборке
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	// Без ldflags (go run, go build без Taskfile) пробуем VCS-информацию из бинаря
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch {
+			case s.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = s.Value
+			case s.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = s.Value
+			}
+		}
+	}
+
+	return info
+}
+
+// String - строка для вывода по флагу --version
+func (i Info) String() string {
+	return fmt.Sprintf("version %s (commit %s, built %s, %s)",
+		i.Version, orUnknown(i.Commit), orUnknown(i.BuildDate), i.GoVersion)
+}
+
+// LogValue - реализует slog.LogValuer
+func (i Info) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("version", i.Version),
+		slog.String("commit", i.Commit),
+		slog.String("build_date", i.BuildDate),
+		slog.String("go_version", i.GoVersion),
+	)
+}
+
+func orUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+
+	return s
+}
@@ -2,76 +2,868 @@ package config
 
 import (
 	"flag"
+	"fmt"
 	"os"
+	"sso/internal/lib/features"
 	"time"
 
 	"github.com/ilyakaznacheev/cleanenv" // Библиотека для загрузки конфигурации из YAML/ENV
 )
 
+// configPathEnv - переменная окружения с путём к файлу конфигурации
+const configPathEnv = "CONFIG_PATH"
+
 // Config - структура, содержащая настройки приложения
 type Config struct {
-	Env           string        `yaml:"env" env-default:"local"`  // Окружение (local, dev, prod)
-	StoragePath   string        `yaml:"storage_path" env-required:"true"` // Путь к файлу хранения (например, SQLite)
-	TokenTTL      time.Duration `yaml:"token_ttl" env-required:"true"` // Время жизни токена
-	GRPC          GRPCConfig    `yaml:"grpc"` // Вложенная структура с настройками gRPC
+	Env         string        `yaml:"env" env-default:"local"`                           // Окружение (local, dev, prod)
+	StoragePath string        `yaml:"storage_path" env-required:"true" sensitive:"true"` // Путь к файлу хранения (например, SQLite); может содержать DSN с паролем
+	TokenTTL    time.Duration `yaml:"token_ttl" env-required:"true"`                     // Время жизни токена
+	GRPC        GRPCConfig    `yaml:"grpc"`                                              // Вложенная структура с настройками gRPC
+	HTTP        HTTPConfig    `yaml:"http"`                                              // Служебный HTTP-сервер (отладка, метрики)
+	JWT         JWTConfig     `yaml:"jwt"`                                               // Настройки подписи токенов
+
+	Pepper     Secret `yaml:"pepper" env:"PEPPER"`             // Pepper для хеширования паролей (лучше задавать через pepper_file)
+	PepperFile string `yaml:"pepper_file" env:"PEPPER_FILE"`   // Путь к файлу с pepper
+	PIIKey     Secret `yaml:"pii_key" env:"PII_KEY"`           // Ключ шифрования email и телефонов в БД (base64 от 32 байт; пусто - не шифровать)
+	PIIKeyFile string `yaml:"pii_key_file" env:"PII_KEY_FILE"` // Путь к файлу с pii_key
+	SecretsDir string `yaml:"secrets_dir" env:"SECRETS_DIR"`   // Каталог с секретами (например, смонтированный Kubernetes Secret)
+
+	Password     PasswordConfig     `yaml:"password"`     // Парольная политика
+	Registration RegistrationConfig `yaml:"registration"` // Ограничения регистрации (применяются при перезагрузке конфигурации)
+
+	Events EventsConfig `yaml:"events"` // Публикация доменных событий в шину сообщений
+	Mail   MailConfig   `yaml:"mail"`   // Отправка писем
+
+	Log   LogConfig   `yaml:"log"`   // Настройки логирования
+	Audit AuditConfig `yaml:"audit"` // Журнал событий безопасности
+
+	Backup   BackupConfig   `yaml:"backup"`   // Резервные копии БД через Admin.Backup
+	Replicas ReplicasConfig `yaml:"replicas"` // Реплики БД только для чтения для горячих чтений
+
+	Organizations OrganizationsConfig `yaml:"organizations"` // Организации (tenants)
+	UserApps      UserAppsConfig      `yaml:"user_apps"`     // Пользователи приложений и администраторы приложений
+	Invitations   InvitationsConfig   `yaml:"invitations"`   // Приглашения пользователей по email
+
+	AccountDeletion AccountDeletionConfig `yaml:"account_deletion"` // Удаление аккаунта самим пользователем
+
+	Sessions      SessionsConfig      `yaml:"sessions"`       // Сессии входа (клейм sid, выход, лимиты сессий приложений)
+	OfflineTokens OfflineTokensConfig `yaml:"offline_tokens"` // Долгоживущие токены фоновых агентов (Auth.IssueOfflineToken)
+	Refresh       RefreshConfig       `yaml:"refresh"`        // Refresh-токены входа (Auth.Refresh)
+
+	Provisioning ProvisioningConfig `yaml:"provisioning"` // Создание и отключение пользователей внешней системой (HR)
+
+	TOS TOSConfig `yaml:"tos"` // Условия использования (применяются при перезагрузке конфигурации)
+
+	Metadata MetadataConfig `yaml:"metadata"` // Метаданные пользователей, которые хранят приложения
+
+	Guests  GuestsConfig  `yaml:"guests"`  // Гостевые пользователи (Auth.CreateGuest)
+	Device  DeviceConfig  `yaml:"device"`  // Вход на устройствах без браузера (Auth.StartDeviceAuthorization)
+	Passkey PasskeyConfig `yaml:"passkey"` // Вход по passkey (WebAuthn)
+	SMS     SMSConfig     `yaml:"sms"`     // Коды входа в SMS (второй фактор) и подтверждение телефона
+	Captcha CaptchaConfig `yaml:"captcha"` // CAPTCHA при регистрации и после неудачных входов
+	Janitor JanitorConfig `yaml:"janitor"` // Фоновое удаление устаревших записей
+	Metrics MetricsConfig `yaml:"metrics"` // Показатели отставания фоновых подсистем в /debug/vars (sso_*)
+
+	SelfTest SelfTestConfig `yaml:"selftest"` // Самопроверка развёртывания (Admin.RunSelfTest, ssoctl selftest)
+
+	Revocations RevocationsConfig `yaml:"revocations"` // Поток отзывов токенов (Auth.WatchRevocations)
+
+	Geo         GeoConfig         `yaml:"geo"`          // История входов, страна и сеть клиента, подозрительные входы
+	LoginAlerts LoginAlertsConfig `yaml:"login_alerts"` // Письма о входе с нового устройства или из новой страны
+
+	Maintenance MaintenanceConfig `yaml:"maintenance"` // Режим обслуживания (применяется при перезагрузке конфигурации)
+
+	RateLimit RateLimitConfig `yaml:"rate_limit"` // Ограничение частоты запросов к gRPC API (применяется при перезагрузке конфигурации)
+
+	Features map[string]features.Rule `yaml:"features"` // Флаги постепенного включения поведения (применяются при перезагрузке конфигурации)
+
+	Outbound OutboundConfig `yaml:"outbound"` // Исходящие HTTP-запросы к внешним сервисам (пул соединений, прокси, повторы)
+
+	ErrorReporting ErrorReportingConfig `yaml:"error_reporting"` // Отправка неожиданных ошибок (паник и INTERNAL) в Sentry
+
+	Schema     SchemaConfig     `yaml:"schema"`     // Проверка совместимости версии схемы БД со сборкой
+	Migrations MigrationsConfig `yaml:"migrations"` // Создание схемы при старте на пустой БД
+
+	path string // Путь к файлу конфигурации
+}
+
+// PasswordConfig - парольная политика
+type PasswordConfig struct {
+	HistorySize int           `yaml:"history_size" env-default:"5"` // Сколько предыдущих паролей нельзя повторять (0 - проверка отключена)
+	MaxAge      time.Duration `yaml:"max_age"`                      // Срок действия пароля, после которого вход требует его смены (0 - без ограничения)
+
+	BreachCheck BreachCheckConfig `yaml:"breach_check"` // Проверка по базе утечек Pwned Passwords
+	Hashing     HashingConfig     `yaml:"hashing"`      // Ограничение одновременных bcrypt-операций
+}
+
+// HashingConfig - пул bcrypt-операций (вход, регистрация, смена пароля).
+// Одновременно выполняется не больше workers операций, ещё queue_depth ждут своей очереди,
+// остальные запросы сразу получают ResourceExhausted
+type HashingConfig struct {
+	Workers    int `yaml:"workers"`                       // Сколько bcrypt-операций выполнять одновременно (0 - по числу CPU)
+	QueueDepth int `yaml:"queue_depth" env-default:"256"` // Сколько операций может ждать свободного слота (0 - без ограничения)
+}
+
+// RegistrationConfig - ограничения регистрации по домену email.
+// "example.com" совпадает только с самим доменом, "*.example.com" - только с его поддоменами
+type RegistrationConfig struct {
+	AllowedDomains []string `yaml:"allowed_domains"` // Если не пусто - регистрироваться можно только с этими доменами
+	BlockedDomains []string `yaml:"blocked_domains"` // Запрещённые домены (например, одноразовая почта); важнее allowed_domains
+}
+
+// BreachCheckConfig - проверка новых паролей по базе утечек (k-anonymity API Pwned Passwords).
+// Наружу уходят только первые 5 символов SHA-1 пароля
+type BreachCheckConfig struct {
+	Enabled   bool          `yaml:"enabled"`                                                      // Включить проверку (по умолчанию выключена)
+	Endpoint  string        `yaml:"endpoint" env-default:"https://api.pwnedpasswords.com/range/"` // Адрес range API
+	Threshold int           `yaml:"threshold"`                                                    // Отклонять пароль, если он встречался в утечках больше threshold раз
+	Timeout   time.Duration `yaml:"timeout" env-default:"2s"`                                     // Таймаут запроса к API
+	FailOpen  bool          `yaml:"fail_open" env-default:"true"`                                 // Пропускать пароль, если API недоступен
+	CacheTTL  time.Duration `yaml:"cache_ttl" env-default:"5m"`                                   // Сколько хранить ответ для префикса (0 - без кеша)
+}
+
+// Куда публиковать доменные события
+const (
+	EventsDriverNone = ""
+	EventsDriverNATS = "nats"
+)
+
+// EventsConfig - публикация доменных событий (user.registered, user.logged_in и т. д.).
+// Ошибки публикации логируются и не влияют на запросы пользователей
+type EventsConfig struct {
+	Driver string       `yaml:"driver"` // "" (не публиковать) или nats
+	NATS   NATSConfig   `yaml:"nats"`   // Настройки NATS JetStream (для driver: nats)
+	Outbox OutboxConfig `yaml:"outbox"` // Надёжная доставка через таблицу outbox
+}
+
+// OutboxConfig - доставка событий через outbox: событие пишется в БД в одной транзакции
+// с изменением состояния, а фоновый relay публикует его в шину (at-least-once)
+type OutboxConfig struct {
+	Enabled      bool          `yaml:"enabled"`                        // Публиковать через outbox, а не напрямую
+	PollInterval time.Duration `yaml:"poll_interval" env-default:"1s"` // Как часто relay проверяет outbox
+	BatchSize    int           `yaml:"batch_size" env-default:"100"`   // Сколько событий relay читает за раз
+}
+
+// NATSConfig - параметры подключения к NATS JetStream
+type NATSConfig struct {
+	URL           string        `yaml:"url" env:"NATS_URL" env-default:"nats://127.0.0.1:4222"` // Адрес сервера (можно несколько через запятую)
+	SubjectPrefix string        `yaml:"subject_prefix" env-default:"sso.events"`                // Префикс subject; полный subject - "<prefix>.<type>"
+	CredsFile     string        `yaml:"creds_file" env:"NATS_CREDS_FILE"`                       // Файл .creds (JWT + NKey)
+	Token         Secret        `yaml:"token" env:"NATS_TOKEN"`                                 // Токен авторизации (лучше задавать через token_file)
+	TokenFile     string        `yaml:"token_file" env:"NATS_TOKEN_FILE"`                       // Путь к файлу с токеном
+	Timeout       time.Duration `yaml:"timeout" env-default:"2s"`                               // Таймаут подключения и ожидания подтверждения публикации
+}
+
+// Способ отправки писем
+const (
+	MailDriverLog  = "log"  // писать письма в лог (локальное окружение)
+	MailDriverSMTP = "smtp" // отправлять через SMTP
+)
+
+// Шифрование SMTP-соединения
+const (
+	SMTPTLSStartTLS = "starttls" // STARTTLS после подключения (обычно порт 587)
+	SMTPTLSImplicit = "implicit" // TLS сразу при подключении (обычно порт 465)
+	SMTPTLSNone     = "none"     // без шифрования (только для локального релея)
+)
+
+// MailConfig - отправка писем (подтверждение email, сброс пароля, коды входа).
+// Письма отправляются асинхронно с повторами: ошибки отправки не возвращаются пользователю
+type MailConfig struct {
+	Driver       string        `yaml:"driver" env-default:"log"`              // log или smtp
+	From         string        `yaml:"from" env-default:"no-reply@localhost"` // Адрес отправителя
+	TemplatesDir string        `yaml:"templates_dir"`                         // Каталог с переопределёнными шаблонами писем
+	QueueSize    int           `yaml:"queue_size" env-default:"1000"`         // Сколько писем может ждать отправки
+	MaxAttempts  int           `yaml:"max_attempts" env-default:"5"`          // Сколько раз пытаться отправить письмо
+	RetryBackoff time.Duration `yaml:"retry_backoff" env-default:"2s"`        // Пауза перед первым повтором (дальше удваивается)
+	SMTP         SMTPConfig    `yaml:"smtp"`                                  // Настройки SMTP (для driver: smtp)
+}
+
+// SMTPConfig - параметры SMTP-сервера
+type SMTPConfig struct {
+	Host         string        `yaml:"host" env:"SMTP_HOST"`                   // Адрес сервера
+	Port         int           `yaml:"port" env:"SMTP_PORT" env-default:"587"` // Порт
+	TLS          string        `yaml:"tls" env-default:"starttls"`             // starttls, implicit или none
+	Username     string        `yaml:"username" env:"SMTP_USERNAME"`           // Логин (пусто - без авторизации)
+	Password     Secret        `yaml:"password" env:"SMTP_PASSWORD"`           // Пароль (лучше задавать через password_file)
+	PasswordFile string        `yaml:"password_file" env:"SMTP_PASSWORD_FILE"` // Путь к файлу с паролем
+	Timeout      time.Duration `yaml:"timeout" env-default:"10s"`              // Таймаут одной SMTP-сессии
+}
+
+// LogConfig - параметры логирования
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL"`   // Уровень логирования (debug, info, warn, error); по умолчанию зависит от env
+	Format string `yaml:"format" env:"LOG_FORMAT"` // Формат логов (text, json); по умолчанию зависит от env
+
+	// Файл вместо stdout - для ВМ без сборщика логов. Ротация по размеру и возрасту; по SIGUSR2
+	// файл открывается заново (для logrotate)
+	Output     string        `yaml:"output" env:"LOG_OUTPUT" env-default:"stdout"` // stdout или file
+	Path       string        `yaml:"path" env:"LOG_PATH"`                          // Путь к файлу (для output: file)
+	MaxSizeMB  int           `yaml:"max_size_mb" env-default:"100"`                // Размер файла, после которого выполняется ротация (0 - без ограничения)
+	MaxAge     time.Duration `yaml:"max_age"`                                      // Возраст файла, после которого выполняется ротация (0 - без ограничения)
+	MaxBackups int           `yaml:"max_backups" env-default:"10"`                 // Сколько старых файлов хранить
+}
+
+// Куда пишется лог приложения
+const (
+	LogOutputStdout = "stdout"
+	LogOutputFile   = "file"
+)
+
+// JWTConfig - параметры подписи токенов
+type JWTConfig struct {
+	SigningKey     Secret `yaml:"signing_key" env:"JWT_SIGNING_KEY"`           // Ключ подписи токенов одного действия (пусто - выключены; лучше задавать через signing_key_file)
+	SigningKeyFile string `yaml:"signing_key_file" env:"JWT_SIGNING_KEY_FILE"` // Путь к файлу с ключом подписи
+	MaxTokenSize   int    `yaml:"max_token_size" env-default:"8192"`           // Максимальный размер токена в байтах (0 - без ограничения)
+
+	Leeway       time.Duration `yaml:"leeway" env-default:"30s"` // Допустимое расхождение часов при проверке сроков токена
+	UserCacheTTL time.Duration `yaml:"user_cache_ttl"`           // Сколько проверка токена доверяет закешированному пользователю (0 - без кеша)
+}
+
+// MaxUserCacheTTL - верхняя граница jwt.user_cache_ttl: отключение пользователя на другой реплике
+// должно вступать в силу не позже чем через это время
+const MaxUserCacheTTL = 5 * time.Second
+
+// Куда писать журнал событий безопасности
+const (
+	AuditOutputStdout = "stdout"
+	AuditOutputFile   = "file"
+	AuditOutputSyslog = "syslog"
+)
+
+// AuditConfig - параметры журнала событий безопасности (неудачные входы, изменения админами и т. п.).
+// Журнал отделён от обычного лога, чтобы его было удобно забирать в SIEM
+type AuditConfig struct {
+	Output     string        `yaml:"output" env-default:"stdout"`     // stdout, file или syslog
+	Path       string        `yaml:"path"`                            // Путь к файлу (для output: file)
+	MaxSizeMB  int           `yaml:"max_size_mb" env-default:"100"`   // Размер файла, после которого выполняется ротация
+	MaxBackups int           `yaml:"max_backups" env-default:"10"`    // Сколько старых файлов хранить
+	MaxAge     time.Duration `yaml:"max_age"`                         // Возраст файла, после которого выполняется ротация (0 - без ограничения)
+	SyslogAddr string        `yaml:"syslog_addr"`                     // Адрес syslog по UDP (пусто - локальный syslog)
+	BufferSize int           `yaml:"buffer_size" env-default:"10000"` // Сколько записей держать в памяти, пока приёмник недоступен
+
+	// Store - дополнительно хранить события в БД для выгрузки через Admin.ExportAuditEvents.
+	// Записи старше Retention удаляет janitor
+	Store     bool          `yaml:"store"`
+	Retention time.Duration `yaml:"retention" env-default:"2160h"`
+
+	// Chain - связывать события в БД цепочкой хешей (Admin.VerifyAuditChain находит изменённые и удалённые
+	// записи). Хеш считается в транзакции вставки: запись события дороже примерно на четверть.
+	// ChainKey - ключ HMAC последнего звена: без него удаление событий с конца журнала не обнаружить
+	Chain        bool   `yaml:"chain"`
+	ChainKey     Secret `yaml:"chain_key" env:"AUDIT_CHAIN_KEY"`
+	ChainKeyFile string `yaml:"chain_key_file" env:"AUDIT_CHAIN_KEY_FILE"`
+}
+
+// BackupConfig - онлайн-копии БД через Admin.Backup. Копии пишутся только в dir
+type BackupConfig struct {
+	Dir string `yaml:"dir" env:"BACKUP_DIR"` // Каталог для копий (пусто - Admin.Backup выключен)
+}
+
+// ReplicasConfig - реплики БД только для чтения (например, копии LiteFS). Чтения пользователя, приложения,
+// прав и групп идут на здоровую реплику, записи и транзакции - на storage_path. Пока все реплики недоступны,
+// чтения идут на storage_path
+type ReplicasConfig struct {
+	DSNs           []string      `yaml:"dsns" sensitive:"true"`            // Пути (DSN) реплик (пусто - реплик нет)
+	HealthInterval time.Duration `yaml:"health_interval" env-default:"5s"` // Как часто проверять здоровье реплик
+	HealthTimeout  time.Duration `yaml:"health_timeout" env-default:"1s"`  // Таймаут одной проверки
+}
+
+// UserAppsConfig - связи пользователей с приложениями: пользователь связывается с приложением при регистрации
+// через него и при первом входе. Администратор без прав суперадминистратора видит в Admin (ListUsers, GetUser,
+// GetStats) только пользователей приложений, которыми управляет (Admin.SetAppAdmin)
+type UserAppsConfig struct {
+	Enabled              bool `yaml:"enabled"`                 // Записывать связи и ограничивать ими администраторов
+	RequireAppOnRegister bool `yaml:"require_app_on_register"` // Регистрация только с app_id
+}
+
+// Где email пользователя должен быть уникален
+const (
+	EmailUniqueGlobal = "global"       // во всём сервисе: один аккаунт на email для всех организаций
+	EmailUniqueOrg    = "organization" // внутри организации: в разных организациях могут быть разные аккаунты с одним email
+)
+
+// OrganizationsConfig - параметры организаций
+type OrganizationsConfig struct {
+	// Режим уникальности email. Переключение с organization обратно на global не поддерживается,
+	// если уже есть пользователи, зарегистрированные в организациях
+	EmailUniqueness string `yaml:"email_uniqueness" env:"ORG_EMAIL_UNIQUENESS" env-default:"global"`
+}
+
+// InvitationsConfig - приглашения пользователей (Admin.InviteUser)
+type InvitationsConfig struct {
+	TTL       time.Duration `yaml:"ttl" env-default:"72h"` // Срок действия приглашения
+	AcceptURL string        `yaml:"accept_url"`            // Страница принятия; к ней добавляется ?token=... (пусто - в письме только код)
+}
+
+// AccountDeletionConfig - удаление аккаунта по запросу пользователя (Auth.RequestAccountDeletion).
+// Аккаунт обезличивает janitor по истечении grace_period; до этого удаление можно отменить по ссылке из письма
+type AccountDeletionConfig struct {
+	Enabled     bool          `yaml:"enabled"`                         // Разрешить пользователям удалять свой аккаунт (по умолчанию выключено)
+	GracePeriod time.Duration `yaml:"grace_period" env-default:"720h"` // Срок, в течение которого удаление можно отменить
+	CancelURL   string        `yaml:"cancel_url"`                      // Страница отмены; к ней добавляется ?token=... (пусто - в письме только код)
+}
+
+// SessionsConfig - сессии входа: каждый вход создаёт сессию, id которой лежит в клейме sid токена.
+// Без них токены не привязаны к сессии: выход отзывает только сам токен, лимиты сессий приложений не действуют,
+// а режим cookie шлюза недоступен
+type SessionsConfig struct {
+	Disabled bool `yaml:"disabled"` // Выключить сессии (по умолчанию включены)
+}
+
+// OfflineTokensConfig - offline-токены фоновых агентов, которые синхронизируют данные, пока пользователя нет.
+// Токен выдаётся только после свежего входа со вторым фактором и принимается только приложениями,
+// которые на это согласились (Admin.SetAppOfflineTokens). Истёкшие токены удаляет janitor
+type OfflineTokensConfig struct {
+	Enabled   bool          `yaml:"enabled"`                           // Разрешить выдачу offline-токенов (по умолчанию выключено)
+	TTL       time.Duration `yaml:"ttl" env-default:"2160h"`           // Срок действия токена
+	Scopes    []string      `yaml:"scopes" env-default:"offline_sync"` // Права токена (клейм scope); уже, чем у токена входа
+	MFAMaxAge time.Duration `yaml:"mfa_max_age" env-default:"5m"`      // Насколько давно может быть вход со вторым фактором
+}
+
+// RefreshConfig - refresh-токены: вход выдаёт вместе с токеном доступа refresh-токен, который Auth.Refresh
+// меняет на новую пару. Каждый обмен продлевает его на sliding_window, но не дальше max_lifetime от входа;
+// приложение может задать свои окна (Admin.SetAppRefreshWindows). Истёкшие цепочки удаляет janitor
+type RefreshConfig struct {
+	Enabled       bool          `yaml:"enabled"`                           // Выдавать refresh-токены (по умолчанию выключено)
+	SlidingWindow time.Duration `yaml:"sliding_window" env-default:"168h"` // Без обмена за это время refresh-токен истекает
+	MaxLifetime   time.Duration `yaml:"max_lifetime" env-default:"720h"`   // Предел от входа, после которого нужен новый вход
+}
+
+// ProvisioningConfig - провижининг пользователей внешней системой (HR): Auth.ProvisionUser, Auth.DeprovisionUser
+// и Auth.ListProvisionedUsers. Вызывать их может приложение с ролью provisioner (Admin.SetAppProvisioner)
+type ProvisioningConfig struct {
+	Enabled bool `yaml:"enabled"` // Разрешить провижининг (по умолчанию выключен)
+}
+
+// TOSConfig - условия использования (terms of service).
+// Пустая версия отключает проверку принятия условий
+type TOSConfig struct {
+	Version string `yaml:"version" env:"TOS_VERSION"` // Текущая версия; регистрация требует её принять
+	// Не выдавать токен при входе, пока пользователь не примет текущую версию.
+	// false - токен выдаётся, а в ответе на Login стоит флаг tos_reacceptance_required
+	EnforceOnLogin bool `yaml:"enforce_on_login" env:"TOS_ENFORCE_ON_LOGIN"`
+}
+
+// MetadataConfig - метаданные пользователей по приложениям (Auth.UpdateUserMetadata)
+type MetadataConfig struct {
+	// Ключи метаданных, которые попадают в токены приложения (например, locale, display_name).
+	// Остальные ключи доступны только через Auth.GetUserMetadata
+	TokenClaims []string `yaml:"token_claims"`
+}
+
+// GuestsConfig - гостевые пользователи без email и пароля (Auth.CreateGuest, Auth.UpgradeGuest).
+// Гостей, не проявлявших активности inactive_after, удаляет janitor вместе с их данными
+type GuestsConfig struct {
+	Enabled       bool          `yaml:"enabled"`                           // Разрешить создание гостей (по умолчанию выключено)
+	TokenTTL      time.Duration `yaml:"token_ttl" env-default:"1h"`        // Время жизни токена гостя (обычно короче token_ttl)
+	InactiveAfter time.Duration `yaml:"inactive_after" env-default:"720h"` // Через сколько без активности гость удаляется
+}
+
+// DeviceConfig - вход на устройствах без браузера (OAuth 2.0 Device Authorization Grant, RFC 8628).
+// Включается, если задан verification_uri
+type DeviceConfig struct {
+	VerificationURI string        `yaml:"verification_uri"`               // Страница, на которой пользователь вводит код с устройства
+	CodeTTL         time.Duration `yaml:"code_ttl" env-default:"10m"`     // Срок действия кодов устройства
+	PollInterval    time.Duration `yaml:"poll_interval" env-default:"5s"` // Минимальный интервал опроса Auth.PollDeviceToken
+}
+
+// PasskeyConfig - вход по passkey (WebAuthn). Включается заданием rp_id
+type PasskeyConfig struct {
+	RPID         string        `yaml:"rp_id"`                          // Домен сервиса (Relying Party ID), например example.com; пусто - passkey выключены
+	RPName       string        `yaml:"rp_name" env-default:"SSO"`      // Название сервиса, которое браузер показывает пользователю
+	Origins      []string      `yaml:"origins"`                        // Origin страниц, с которых разрешены церемонии (https://example.com)
+	ChallengeTTL time.Duration `yaml:"challenge_ttl" env-default:"5m"` // Сколько действует вызов Begin* (между Begin и Finish)
+	MaxPerUser   int           `yaml:"max_per_user" env-default:"10"`  // Сколько passkey может зарегистрировать один пользователь
+}
+
+// Куда отправлять SMS
+const (
+	SMSDriverNone   = ""
+	SMSDriverLog    = "log"    // писать SMS в лог (локальное окружение)
+	SMSDriverTwilio = "twilio" // отправлять через HTTP API в стиле Twilio
+)
+
+// SMSConfig - коды входа в SMS (второй фактор). Включается заданием driver.
+// SMS платные, поэтому отправки ограничены и по пользователю, и по номеру
+type SMSConfig struct {
+	Driver           string        `yaml:"driver"`                              // log или twilio; пусто - SMS выключены
+	ChallengeTTL     time.Duration `yaml:"challenge_ttl" env-default:"10m"`     // Сколько действует вызов после входа по паролю
+	CodeTTL          time.Duration `yaml:"code_ttl" env-default:"5m"`           // Сколько действует отправленный код
+	MaxAttempts      int           `yaml:"max_attempts" env-default:"5"`        // Сколько раз можно ввести код одного вызова
+	ResendInterval   time.Duration `yaml:"resend_interval" env-default:"1m"`    // Минимальная пауза между отправками кода одного вызова
+	SendWindow       time.Duration `yaml:"send_window" env-default:"1h"`        // Окно, в котором считаются отправки
+	MaxSendsPerUser  int           `yaml:"max_sends_per_user" env-default:"5"`  // Сколько SMS можно отправить одному пользователю за окно
+	MaxSendsPerPhone int           `yaml:"max_sends_per_phone" env-default:"5"` // Сколько SMS можно отправить на один номер за окно
+	Twilio           TwilioConfig  `yaml:"twilio"`                              // Настройки провайдера (для driver: twilio)
+}
+
+// TwilioConfig - HTTP API отправки SMS (Twilio или совместимый)
+type TwilioConfig struct {
+	Endpoint      string        `yaml:"endpoint" env-default:"https://api.twilio.com"` // Адрес API
+	AccountSID    string        `yaml:"account_sid" env:"TWILIO_ACCOUNT_SID"`          // Идентификатор аккаунта
+	AuthToken     Secret        `yaml:"auth_token" env:"TWILIO_AUTH_TOKEN"`            // Токен аккаунта (лучше задавать через auth_token_file)
+	AuthTokenFile string        `yaml:"auth_token_file" env:"TWILIO_AUTH_TOKEN_FILE"`  // Путь к файлу с токеном
+	From          string        `yaml:"from"`                                          // Номер отправителя в E.164 или Messaging Service SID (MG...)
+	Timeout       time.Duration `yaml:"timeout" env-default:"10s"`                     // Таймаут запроса к API
+}
+
+// Провайдеры CAPTCHA
+const (
+	CaptchaProviderDisabled  = "disabled"  // CAPTCHA не проверяется
+	CaptchaProviderReCAPTCHA = "recaptcha" // Google reCAPTCHA (v2 или v3)
+	CaptchaProviderHCaptcha  = "hcaptcha"  // hCaptcha
+	CaptchaProviderTurnstile = "turnstile" // Cloudflare Turnstile
+)
+
+// CaptchaConfig - проверка CAPTCHA: при регистрации всегда, при входе - после нескольких
+// неудачных попыток для email или адреса клиента
+type CaptchaConfig struct {
+	Provider      string        `yaml:"provider" env:"CAPTCHA_PROVIDER" env-default:"disabled"` // disabled, recaptcha, hcaptcha или turnstile
+	Endpoint      string        `yaml:"endpoint"`                                               // Адрес проверки токена (пусто - адрес провайдера)
+	Secret        Secret        `yaml:"secret" env:"CAPTCHA_SECRET"`                            // Секретный ключ сайта (лучше задавать через secret_file)
+	SecretFile    string        `yaml:"secret_file" env:"CAPTCHA_SECRET_FILE"`                  // Путь к файлу с секретным ключом
+	MinScore      float64       `yaml:"min_score"`                                              // Минимальная оценка reCAPTCHA v3 (0 - не проверяется)
+	Timeout       time.Duration `yaml:"timeout" env-default:"3s"`                               // Таймаут запроса к провайдеру
+	FailOpen      bool          `yaml:"fail_open"`                                              // Пропускать запрос, если провайдер недоступен (по умолчанию - отказ)
+	LoginFailures int           `yaml:"login_failures" env-default:"3"`                         // После скольких неудачных входов вход требует CAPTCHA
+	FailureWindow time.Duration `yaml:"failure_window" env-default:"15m"`                       // Окно, в котором считаются неудачные входы
+}
+
+// Enabled - CAPTCHA проверяется (пустой provider - то же, что disabled)
+func (c CaptchaConfig) Enabled() bool {
+	return c.Provider != CaptchaProviderDisabled && c.Provider != ""
+}
+
+// RevocationsConfig - поток отзывов токенов для сервисов, кеширующих результат ValidateToken
+type RevocationsConfig struct {
+	PollInterval      time.Duration `yaml:"poll_interval" env-default:"1s"`       // Как часто проверять журнал (запись отзыва будит сразу)
+	KeepaliveInterval time.Duration `yaml:"keepalive_interval" env-default:"30s"` // Пинг в потоке без отзывов, чтобы прокси не рвали соединение
+	BufferSize        int           `yaml:"buffer_size" env-default:"256"`        // Сколько отзывов ждут отправки подписчику, прежде чем его отключат
+	Retention         time.Duration `yaml:"retention" env-default:"168h"`         // Сколько хранить журнал (окно для переподключения)
+}
+
+// GeoConfig - история входов и поиск входов из новой страны.
+// История входов ведётся всегда; страна и ASN определяются, только если заданы пути к базам MaxMind
+type GeoConfig struct {
+	CountryDB     string        `yaml:"country_db"`                      // База GeoIP2/GeoLite2 Country или City (.mmdb); пусто - страна не определяется
+	ASNDB         string        `yaml:"asn_db"`                          // База GeoLite2 ASN (.mmdb); пусто - ASN не определяется
+	CheckInterval time.Duration `yaml:"check_interval" env-default:"1m"` // Как часто проверять, не заменили ли файлы баз
+	HistoryDepth  int           `yaml:"history_depth" env-default:"20"`  // Со сколькими последними входами сравнивать страну нового
+	RequireStepUp bool          `yaml:"require_step_up"`                 // Вход из новой страны требует StepUp, если у пользователя есть второй фактор
+	Retention     time.Duration `yaml:"retention" env-default:"2160h"`   // Сколько хранить историю входов
+}
+
+// Enabled - задана хотя бы одна база
+func (c GeoConfig) Enabled() bool {
+	return c.CountryDB != "" || c.ASNDB != ""
+}
+
+// LoginAlertsConfig - письма пользователю о входе с нового устройства (по User-Agent) или из новой страны
+// (нужна GeoIP-база). Пользователь отказывается от писем флагом "login_alerts": false в метаданных
+type LoginAlertsConfig struct {
+	Enabled     bool          `yaml:"enabled"`                   // Отправлять письма
+	SessionsURL string        `yaml:"sessions_url"`              // Страница, где пользователь завершает чужие сеансы (ссылка в письме)
+	Interval    time.Duration `yaml:"interval" env-default:"1h"` // Не чаще одного письма пользователю за этот интервал
+}
+
+// MaintenanceConfig - поведение в режиме обслуживания. Сам режим включается во время работы
+// (Admin.SetMaintenance или SIGUSR1), а не конфигурацией
+type MaintenanceConfig struct {
+	BlockLogin bool          `yaml:"block_login"`                   // Отклонять и вход (по умолчанию он работает, хотя создаёт сессии и пишет историю входов)
+	RetryAfter time.Duration `yaml:"retry_after" env-default:"30s"` // Подсказка клиентам, через сколько повторить отклонённый запрос
+}
+
+// RateLimitConfig - ограничение частоты запросов к gRPC API корзинами токенов.
+// Методы без правила ограничиваются лимитами Default, каждый своей корзиной
+type RateLimitConfig struct {
+	Enabled bool            `yaml:"enabled"` // Включить ограничение
+	Default RateLimit       `yaml:"default"` // Лимиты методов без правила (rate 0 - без ограничения)
+	Rules   []RateLimitRule `yaml:"rules"`   // Лимиты отдельных методов и приложений
+}
+
+// RateLimit - скорость пополнения и вместимость корзины
+type RateLimit struct {
+	Rate  float64 `yaml:"rate"`  // Запросов в секунду (0 - без ограничения)
+	Burst int     `yaml:"burst"` // Сколько запросов можно сделать разом
+}
+
+// RateLimitRule - лимиты метода или метода для одного приложения (app_id берётся из запроса)
+type RateLimitRule struct {
+	Method string  `yaml:"method"`  // Полное имя метода, например /auth.Auth/Register
+	AppID  int     `yaml:"app_id"`  // Только для этого приложения; 0 - для всех
+	PerApp bool    `yaml:"per_app"` // Отдельная корзина на каждый app_id (иначе одна на метод)
+	Rate   float64 `yaml:"rate"`    // Запросов в секунду (0 - без ограничения)
+	Burst  int     `yaml:"burst"`   // Сколько запросов можно сделать разом
+}
+
+// Limit - лимиты правила
+func (r RateLimitRule) Limit() RateLimit {
+	return RateLimit{Rate: r.Rate, Burst: r.Burst}
+}
+
+// OutboundConfig - общий HTTP-клиент для запросов к внешним сервисам (Pwned Passwords и т. п.).
+// Таймауты задаются в настройках каждого сервиса (например, password.breach_check.timeout)
+type OutboundConfig struct {
+	Proxy               string        `yaml:"proxy" sensitive:"true"`                  // http(s)://[user:pass@]host:port; пусто - HTTPS_PROXY/HTTP_PROXY/NO_PROXY из окружения
+	MaxIdleConnsPerHost int           `yaml:"max_idle_conns_per_host" env-default:"8"` // Сколько простаивающих соединений держать к одному хосту
+	MaxConnsPerHost     int           `yaml:"max_conns_per_host" env-default:"32"`     // Сколько соединений можно открыть к одному хосту (0 - без ограничения)
+	IdleConnTimeout     time.Duration `yaml:"idle_conn_timeout" env-default:"90s"`     // Когда закрывать простаивающее соединение
+	Retries             int           `yaml:"retries" env-default:"2"`                 // Повторы GET при сетевой ошибке или ответе 5xx/429
+	NoRetries           bool          `yaml:"no_retries"`                              // Не повторять запросы (retries: 0 заменяется значением по умолчанию)
+	RetryBackoff        time.Duration `yaml:"retry_backoff" env-default:"100ms"`       // Пауза перед первым повтором; дальше удваивается, со случайным разбросом
+}
+
+// SchemaConfig - сравнение версии схемы БД с последней миграцией, встроенной в сборку. На старой или
+// прерванной (dirty) схеме health и /readyz сообщают NOT_SERVING: первый же запрос к новой колонке упал бы
+type SchemaConfig struct {
+	RefuseNewer   bool          `yaml:"refuse_newer"`                     // Не обслуживать и на схеме новее сборки (по умолчанию - только предупреждение)
+	CheckInterval time.Duration `yaml:"check_interval" env-default:"30s"` // Как часто перепроверять версию (после миграции сервис станет готов сам)
+}
+
+// MigrationsConfig - что делать при старте, если файла БД нет или миграции к нему не применялись.
+// Без auto_apply сервис не запускается и подсказывает команду мигратора
+type MigrationsConfig struct {
+	AutoApply bool `yaml:"auto_apply"` // Создать каталог и файл БД и применить встроенные миграции
+}
+
+// ErrorReportingConfig - отправка паник и ответов INTERNAL в Sentry (или совместимый трекер) в фоне.
+// Без DSN ничего не отправляется
+type ErrorReportingConfig struct {
+	DSN          Secret        `yaml:"dsn" env:"SENTRY_DSN"`           // DSN проекта, например https://key@o1.ingest.sentry.io/42 (лучше задавать через dsn_file)
+	DSNFile      string        `yaml:"dsn_file" env:"SENTRY_DSN_FILE"` // Путь к файлу с DSN
+	Environment  string        `yaml:"environment"`                    // Окружение в отчётах (пусто - env)
+	QueueSize    int           `yaml:"queue_size" env-default:"100"`   // Сколько отчётов ждут отправки; лишние отбрасываются
+	Timeout      time.Duration `yaml:"timeout" env-default:"5s"`       // Таймаут отправки одного отчёта
+	FlushTimeout time.Duration `yaml:"flush_timeout" env-default:"2s"` // Сколько ждать отправки очереди при остановке
+}
+
+// JanitorConfig - фоновое удаление устаревших записей (неактивных гостей, использованных токенов и т. п.).
+// Janitor запускается, только если ему есть что удалять
+type JanitorConfig struct {
+	Interval  time.Duration `yaml:"interval" env-default:"1h"`    // Как часто запускать уборку
+	BatchSize int           `yaml:"batch_size" env-default:"500"` // Сколько записей удалять за один запрос
+}
+
+// MetricsConfig - показатели отставания фоновых подсистем в /debug/vars: очередь outbox, приглашения,
+// отключённые пользователи, сессии, проходы janitor и sso_degraded. Считаются запросами к БД при чтении
+type MetricsConfig struct {
+	CacheTTL     time.Duration `yaml:"cache_ttl" env-default:"30s"`     // Сколько отдавать посчитанные показатели без новых запросов
+	QueryTimeout time.Duration `yaml:"query_timeout" env-default:"2s"`  // Таймаут запросов одного подсчёта
+	OutboxMaxAge time.Duration `yaml:"outbox_max_age" env-default:"5m"` // Событие в outbox старше - sso_degraded = 1 (0 - не учитывать)
+}
+
+// SelfTestConfig - самопроверка развёртывания Admin.RunSelfTest: сценарий пользователя на временных
+// приложении и пользователе, которые удаляются в конце проверки
+type SelfTestConfig struct {
+	Enabled bool `yaml:"enabled"` // Разрешить самопроверку (по умолчанию выключена)
+	// Данные самопроверки, прерванной до уборки (например, рестартом), janitor удаляет после этого срока
+	StaleAfter time.Duration `yaml:"stale_after" env-default:"1h"`
+}
+
+// HTTPConfig - параметры служебного HTTP-сервера
+type HTTPConfig struct {
+	Port            int           `yaml:"port"`                              // Порт HTTP-сервера (0 - сервер не запускается)
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env-default:"5s"` // Сколько ждать активные запросы при остановке
+	Debug           DebugConfig   `yaml:"debug"`                             // Отладочные обработчики
+	Gateway         GatewayConfig `yaml:"gateway"`                           // JSON-шлюз /v1 к сервису авторизации
+	OIDC            OIDCConfig    `yaml:"oidc"`                              // Вход в приложения по OpenID Connect
+}
+
+// OIDCConfig - провайдер OpenID Connect: discovery, JWKS, страница входа и token endpoint
+// (только authorization code flow с PKCE для приложений с секретом)
+type OIDCConfig struct {
+	Enabled bool          `yaml:"enabled"`                   // Включить эндпоинты OpenID Connect
+	Issuer  string        `yaml:"issuer"`                    // Внешний адрес сервиса (iss), например https://sso.example.com
+	CodeTTL time.Duration `yaml:"code_ttl" env-default:"1m"` // Срок действия кода авторизации
+}
+
+// GatewayConfig - HTTP/JSON-шлюз к сервису авторизации (/v1/login, /v1/logout, /v1/me).
+// gRPC-API от него не зависит
+type GatewayConfig struct {
+	Enabled bool         `yaml:"enabled"` // Включить шлюз
+	Cookie  CookieConfig `yaml:"cookie"`  // Режим cookie для браузерных приложений
+	CORS    CORSConfig   `yaml:"cors"`    // Запросы браузерных клиентов с других источников
+}
+
+// CORSConfig - ответы шлюза на запросы с других источников. Приложение запроса берётся из заголовка app_header
+// или параметра app_id в адресе (preflight не несёт значений заголовков, поэтому браузерным клиентам нужен
+// параметр), а Origin сравнивается с источниками приложения (Admin.SetAppOrigins). Запрос с незарегистрированного
+// источника обрабатывается как обычно, но без заголовков CORS - ответ браузер скрипту не покажет
+type CORSConfig struct {
+	Enabled        bool          `yaml:"enabled"`                                                               // Включить CORS
+	AppHeader      string        `yaml:"app_header" env-default:"X-App-ID"`                                     // Заголовок с id приложения
+	AllowedHeaders []string      `yaml:"allowed_headers" env-default:"Content-Type,Authorization,X-CSRF-Token"` // Access-Control-Allow-Headers
+	MaxAge         time.Duration `yaml:"max_age" env-default:"10m"`                                             // Сколько браузер кеширует ответ на preflight
+}
+
+// CookieConfig - режим cookie: /v1/login не отдаёт JWT, а ставит HttpOnly-cookie с id сессии; токен остаётся
+// на сервере. Изменяющие запросы защищены от CSRF по схеме double-submit (cookie и заголовок X-CSRF-Token)
+type CookieConfig struct {
+	Enabled  bool          `yaml:"enabled"`                        // Включить режим cookie
+	Name     string        `yaml:"name" env-default:"sso_session"` // Имя cookie сессии
+	Domain   string        `yaml:"domain"`                         // Атрибут Domain (пусто - только текущий хост)
+	MaxAge   time.Duration `yaml:"max_age" env-default:"24h"`      // Атрибут Max-Age (не дольше срока жизни токена)
+	SameSite string        `yaml:"same_site" env-default:"lax"`    // Атрибут SameSite: lax, strict или none
+}
+
+// DebugConfig - параметры отладочных обработчиков (pprof, /debug/vars, /debug/build)
+type DebugConfig struct {
+	Enabled   bool   `yaml:"enabled"`                           // Включить отладочные обработчики
+	Token     Secret `yaml:"token" env:"DEBUG_TOKEN"`           // Статический bearer-токен (пусто - без авторизации)
+	TokenFile string `yaml:"token_file" env:"DEBUG_TOKEN_FILE"` // Путь к файлу с токеном
 }
 
 // GRPCConfig - структура с параметрами gRPC
 type GRPCConfig struct {
-	Port    int           `yaml:"port"`    // Порт gRPC-сервера
-	Timeout time.Duration `yaml:"timeout"` // Таймаут gRPC-запросов
+	Port        int               `yaml:"port"`        // Порт gRPC-сервера: краткая запись одного слушателя со всеми сервисами
+	Timeout     time.Duration     `yaml:"timeout"`     // Таймаут gRPC-запросов
+	Compression CompressionConfig `yaml:"compression"` // Настройки сжатия ответов
+
+	// AdminAllowlist - сети (CIDR или адрес), из которых доступны методы сервиса Admin.
+	// Пусто - ограничения нет; остальным клиентам Admin отвечает PermissionDenied
+	AdminAllowlist []string `yaml:"admin_allowlist"`
+	// TrustedProxies - сети прокси, которым верим заголовок ForwardedHeader с адресом клиента.
+	// Пусто - заголовок игнорируется, адрес клиента - адрес соединения
+	TrustedProxies []string `yaml:"trusted_proxies"`
+	// ForwardedHeader - ключ метаданных, в который прокси дописывает адрес клиента
+	ForwardedHeader string `yaml:"forwarded_header" env-default:"x-forwarded-for"`
+	// ProxyProtocol - то же, что proxy_protocol слушателя, для краткой записи через Port
+	ProxyProtocol bool `yaml:"proxy_protocol"`
+	// Locale - язык переводов сообщений об ошибках (LocalizedMessage) для клиентов без
+	// accept-language или с языком, для которого нет каталога
+	Locale string `yaml:"locale" env-default:"en"`
+	// DebugPayloads - записывать в лог запросы и ответы целиком (JSON, секретные поля замаскированы).
+	// Для отладки интеграций в local/dev; в prod не допускается
+	DebugPayloads bool `yaml:"debug_payloads" env:"GRPC_DEBUG_PAYLOADS"`
+
+	// Interceptors - включённые интерцепторы в порядке от внешнего к внутреннему; пусто - DefaultGRPCInterceptors.
+	// recovery и auth обязательны, снаружи recovery допускаются только GRPCContextInterceptors
+	Interceptors []string `yaml:"interceptors"`
+
+	// Listeners - адреса, на которых слушает сервер, со своим набором сервисов и TLS.
+	// Задаются вместо Port, например, чтобы обслуживать старый и новый порт на время переезда
+	// или открыть Admin только на localhost
+	Listeners []GRPCListener `yaml:"listeners"`
+	TLS       GRPCTLSConfig  `yaml:"tls"` // Сертификат для слушателей с tls: true
+
+	SLO SLOConfig `yaml:"slo"` // Счётчики для целей уровня обслуживания (SLO)
+}
+
+// SLOConfig - счётчики SLO по методам в /debug/vars (grpc_slo_*). Запрос "хороший" по задержке, если уложился
+// в порог метода; в отказы доступности попадают только ошибки сервера, а не клиента (INVALID_ARGUMENT и т. п.).
+// Правила записи для Prometheus генерирует ssoctl gen-rules из этого же раздела
+type SLOConfig struct {
+	DefaultLatency time.Duration `yaml:"default_latency"` // Порог методов без своего (0 - задержка таких методов не учитывается)
+	Latency        []SLOLatency  `yaml:"latency"`         // Пороги задержки отдельных методов
+}
+
+// SLOLatency - порог задержки метода
+type SLOLatency struct {
+	Method    string        `yaml:"method"`    // Полное имя метода, например /auth.Auth/Login
+	Threshold time.Duration `yaml:"threshold"` // Запрос не дольше порога - хороший
+}
+
+// Сервисы, которые регистрируются на слушателе gRPC
+const (
+	GRPCServiceAuth       = "auth"
+	GRPCServiceAdmin      = "admin"
+	GRPCServiceHealth     = "health"     // grpc.health.v1.Health
+	GRPCServiceReflection = "reflection" // серверная рефлексия (grpcurl и т. п.)
+)
+
+// DefaultGRPCServices - сервисы слушателя, у которого они не перечислены. Рефлексия
+// по умолчанию выключена: она раскрывает схему API
+var DefaultGRPCServices = []string{GRPCServiceAuth, GRPCServiceAdmin, GRPCServiceHealth}
+
+// Интерцепторы gRPC (grpc.interceptors)
+const (
+	InterceptorMetrics        = "metrics"         // длительность и статусы запросов, счётчики SLO
+	InterceptorErrorReasons   = "error_reasons"   // ErrorInfo с причиной и перевод сообщения об ошибке
+	InterceptorClientIP       = "client_ip"       // адрес клиента с учётом grpc.trusted_proxies
+	InterceptorUserAgent      = "user_agent"      // User-Agent для истории входов
+	InterceptorLogging        = "logging"         // логгер запроса с x-request-id
+	InterceptorRecovery       = "recovery"        // паника - INTERNAL, отчёт в трекер ошибок
+	InterceptorInputLimits    = "input_limits"    // длина и символы строковых полей
+	InterceptorPayloads       = "payloads"        // запросы и ответы в лог (только с grpc.debug_payloads)
+	InterceptorAdminAllowlist = "admin_allowlist" // сети для Admin (только с grpc.admin_allowlist)
+	InterceptorMaintenance    = "maintenance"     // режим обслуживания
+	InterceptorRateLimit      = "rate_limit"      // лимиты запросов
+	InterceptorAuth           = "auth"            // bearer-токен и права доступа к методу
+	InterceptorCompression    = "compression"     // сжатие ответов (только с grpc.compression.force)
+)
+
+// DefaultGRPCInterceptors - порядок интерцепторов, если grpc.interceptors не задан
+var DefaultGRPCInterceptors = []string{
+	InterceptorMetrics,
+	InterceptorErrorReasons,
+	InterceptorClientIP,
+	InterceptorUserAgent,
+	InterceptorLogging,
+	InterceptorRecovery,
+	InterceptorInputLimits,
+	InterceptorPayloads,
+	InterceptorAdminAllowlist,
+	InterceptorMaintenance,
+	InterceptorRateLimit,
+	InterceptorAuth,
+	InterceptorCompression,
+}
+
+// GRPCContextInterceptors - интерцепторы, которые могут стоять снаружи recovery: они только наблюдают
+// за запросом и кладут в контекст адрес клиента и логгер, которые нужны отчёту recovery о панике
+var GRPCContextInterceptors = []string{
+	InterceptorMetrics,
+	InterceptorErrorReasons,
+	InterceptorClientIP,
+	InterceptorUserAgent,
+	InterceptorLogging,
+}
+
+// EnabledInterceptors - интерцепторы в порядке от внешнего к внутреннему с учётом значения по умолчанию
+func (c GRPCConfig) EnabledInterceptors() []string {
+	if len(c.Interceptors) == 0 {
+		return DefaultGRPCInterceptors
+	}
+
+	return c.Interceptors
+}
+
+// GRPCListener - один адрес gRPC-сервера
+type GRPCListener struct {
+	Addr     string   `yaml:"addr"`     // host:port; пустой host - все интерфейсы
+	Services []string `yaml:"services"` // auth, admin, health, reflection; пусто - DefaultGRPCServices
+	TLS      bool     `yaml:"tls"`      // Принимать только TLS-соединения (сертификат из grpc.tls)
+	// ProxyProtocol - принимать заголовок PROXY protocol v1/v2 от балансировщика L4: адрес клиента
+	// берётся из него, если соединение пришло из grpc.trusted_proxies. Соединения без заголовка тоже принимаются
+	ProxyProtocol bool `yaml:"proxy_protocol"`
 }
 
-// MustLoad - загружает конфигурацию из файла, указанного в аргументе `-config` или переменной окружения `CONFIG_PATH`
+// GRPCTLSConfig - сертификат сервера для слушателей с TLS
+type GRPCTLSConfig struct {
+	CertFile string `yaml:"cert_file"` // PEM с сертификатом (и цепочкой)
+	KeyFile  string `yaml:"key_file"`  // PEM с закрытым ключом
+}
+
+// AllListeners - слушатели с учётом краткой записи: без Listeners - один слушатель ":Port"
+// со всеми сервисами по умолчанию. Пустые Services заменяются на DefaultGRPCServices
+func (c GRPCConfig) AllListeners() []GRPCListener {
+	if len(c.Listeners) == 0 {
+		return []GRPCListener{{Addr: fmt.Sprintf(":%d", c.Port), Services: DefaultGRPCServices, ProxyProtocol: c.ProxyProtocol}}
+	}
+
+	listeners := make([]GRPCListener, len(c.Listeners))
+	for i, l := range c.Listeners {
+		if len(l.Services) == 0 {
+			l.Services = DefaultGRPCServices
+		}
+		listeners[i] = l
+	}
+
+	return listeners
+}
+
+// CompressionConfig - параметры сжатия gRPC-сообщений.
+//
+// Кодек gzip регистрируется на сервере всегда: сервер объявляет его в grpc-accept-encoding,
+// принимает сжатые запросы и отвечает сжатием, если клиент сам прислал сжатый запрос.
+// Клиенты, не поддерживающие gzip, никогда не получают сжатых ответов.
+//
+// Ограничения на размер сообщений (MaxRecvMsgSize/MaxSendMsgSize) в gRPC применяются
+// к размеру сообщения ПОСЛЕ распаковки, поэтому сжатие не позволяет обойти лимит
+// и не защищает от "zip-бомб" сильнее, чем сам лимит.
+type CompressionConfig struct {
+	// Force - сжимать ответы gzip, даже если запрос пришёл несжатым,
+	// при условии, что клиент объявил поддержку gzip.
+	Force bool `yaml:"force"`
+	// MinSize - минимальный размер ответа в байтах для принудительного сжатия.
+	// Маленькие ответы (Login, IsAdmin) сжимать невыгодно — заголовки gzip больше выигрыша.
+	MinSize int `yaml:"min_size" env-default:"1024"`
+}
+
+// MustLoad - загружает конфигурацию из файла, указанного в переменной окружения `CONFIG_PATH`.
+// Глобальные флаги не трогает, поэтому безопасен для тестов и бинарей со своими флагами
 func MustLoad() *Config {
-	path := fetchConfigPath() // Получаем путь к конфигурационному файлу
+	path := os.Getenv(configPathEnv) // Получаем путь к конфигурационному файлу
 	if path == "" {
 		panic("config path is empty") // Если путь пуст, завершаем выполнение с ошибкой
 	}
 
-	// Проверяем, существует ли файл конфигурации
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		panic("config file does not exist: " + path) // Если нет файла — ошибка
-	}
-
-	var cfg Config
+	return MustLoadPath(path)
+}
 
-	// Читаем конфигурацию из YAML-файла
-	if err := cleanenv.ReadConfig(path, &cfg); err != nil {
-		panic("failed to read config: " + err.Error()) // Если ошибка — завершаем выполнение
+// MustLoadWithFlags - регистрирует флаг `-config` в переданном FlagSet, разбирает args
+// и загружает конфигурацию. Если флаг не указан, используется переменная окружения `CONFIG_PATH`.
+// Остальные флаги бинарь регистрирует в том же FlagSet до вызова
+func MustLoadWithFlags(fs *flag.FlagSet, args []string) *Config {
+	path := fetchConfigPath(fs, args) // Получаем путь к конфигурационному файлу
+	if path == "" {
+		panic("config path is empty") // Если путь пуст, завершаем выполнение с ошибкой
 	}
 
-	return &cfg // Возвращаем загруженную конфигурацию
+	return MustLoadPath(path)
 }
 
 // MustLoadPath - загружает конфигурацию из указанного пути
 func MustLoadPath(configPath string) *Config {
+	cfg, err := Load(configPath)
+	if err != nil {
+		panic(err.Error()) // Если ошибка — завершаем выполнение
+	}
+
+	return cfg // Возвращаем загруженную конфигурацию
+}
+
+// Load - загружает и проверяет конфигурацию из указанного пути, возвращая ошибку вместо паники.
+// Используется при перезагрузке конфигурации на лету, где падать нельзя
+func Load(configPath string) (*Config, error) {
 	// Проверяем, существует ли файл конфигурации
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		panic("config file does not exist: " + configPath) // Если файла нет, завершаем выполнение
+		return nil, fmt.Errorf("config file does not exist: %s", configPath)
 	}
 
 	var cfg Config
 
 	// Читаем конфигурацию из YAML-файла
 	if err := cleanenv.ReadConfig(configPath, &cfg); err != nil {
-		panic("cannot read config: " + err.Error()) // Если ошибка — завершаем выполнение
+		return nil, fmt.Errorf("cannot read config: %w", err)
+	}
+
+	warnUnknownKeys(configPath)
+
+	// Подгружаем секреты из файлов, чтобы они не лежали в config.yaml
+	if err := cfg.loadSecrets(); err != nil {
+		return nil, fmt.Errorf("cannot load secrets: %w", err)
 	}
 
-	return &cfg // Возвращаем загруженную конфигурацию
+	// Проверяем значения сразу, а не при открытии порта или БД
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config:\n%w", err)
+	}
+
+	cfg.path = configPath
+
+	return &cfg, nil
+}
+
+// Path - путь к файлу, из которого загружена конфигурация (нужен для перезагрузки)
+func (c *Config) Path() string {
+	return c.path
 }
 
 // fetchConfigPath - определяет путь к файлу конфигурации
-func fetchConfigPath() string {
+func fetchConfigPath(fs *flag.FlagSet, args []string) string {
 	var res string
 
-	// Читаем путь к конфигу из флага `-config`
-	flag.StringVar(&res, "config", "", "path to config file")
-	flag.Parse() // Разбираем аргументы командной строки
+	// Читаем путь к конфигу из флага `-config` на отдельном FlagSet, а не на flag.CommandLine
+	fs.StringVar(&res, "config", "", "path to config file")
+	if err := fs.Parse(args); err != nil {
+		panic("failed to parse flags: " + err.Error()) // Сработает только для flag.ContinueOnError
+	}
 
 	// Если путь не указан через флаг, пробуем прочитать переменную окружения `CONFIG_PATH`
 	if res == "" {
-		res = os.Getenv("CONFIG_PATH")
+		res = os.Getenv(configPathEnv)
 	}
 
 	return res // Возвращаем путь к конфигурации
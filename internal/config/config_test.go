@@ -0,0 +1,59 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfig = `
+env: local
+storage_path: ./storage/sso.db
+token_ttl: 1h
+grpc:
+  port: 44044
+  timeout: 10h
+`
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(body), 0o600))
+
+	return path
+}
+
+func TestMustLoadWithFlags_Flag(t *testing.T) {
+	path := writeConfig(t, testConfig)
+
+	fs := flag.NewFlagSet("sso", flag.ContinueOnError)
+	verbose := fs.Bool("verbose", false, "binary's own flag")
+
+	cfg := MustLoadWithFlags(fs, []string{"-verbose", "-config", path})
+
+	assert.True(t, *verbose)
+	assert.Equal(t, 44044, cfg.GRPC.Port)
+}
+
+func TestMustLoadWithFlags_EnvFallback(t *testing.T) {
+	t.Setenv(configPathEnv, writeConfig(t, testConfig))
+
+	cfg := MustLoadWithFlags(flag.NewFlagSet("sso", flag.ContinueOnError), nil)
+
+	assert.Equal(t, EnvLocal, cfg.Env)
+}
+
+func TestMustLoad_DoesNotTouchGlobalFlags(t *testing.T) {
+	t.Setenv(configPathEnv, writeConfig(t, testConfig))
+
+	// Повторная загрузка не должна паниковать с "flag redefined"
+	MustLoad()
+	MustLoad()
+
+	assert.Nil(t, flag.Lookup("config"))
+}
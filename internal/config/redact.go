@@ -0,0 +1,64 @@
+package config
+
+import (
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Поля конфигурации, помеченные тегом `sensitive:"true"`, и поля типа Secret
+// никогда не попадают в логи в открытом виде. Тот же механизм нужно использовать
+// для любой выдачи конфигурации наружу (например, admin RPC "dump config").
+
+// LogValue - реализует slog.LogValuer: чувствительные поля заменяются на "[REDACTED]"
+func (c Config) LogValue() slog.Value { return redactedValue(reflect.ValueOf(c)) }
+
+// LogValue - реализует slog.LogValuer
+func (c GRPCConfig) LogValue() slog.Value { return redactedValue(reflect.ValueOf(c)) }
+
+// LogValue - реализует slog.LogValuer
+func (c HTTPConfig) LogValue() slog.Value { return redactedValue(reflect.ValueOf(c)) }
+
+// LogValue - реализует slog.LogValuer
+func (c JWTConfig) LogValue() slog.Value { return redactedValue(reflect.ValueOf(c)) }
+
+// LogValue - реализует slog.LogValuer
+func (c LogConfig) LogValue() slog.Value { return redactedValue(reflect.ValueOf(c)) }
+
+// redactedValue - строит группу slog по полям структуры (ключи - yaml-теги),
+// скрывая чувствительные значения
+func redactedValue(v reflect.Value) slog.Value {
+	t := v.Type()
+
+	attrs := make([]slog.Attr, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fv := v.Field(i)
+
+		switch {
+		case f.Tag.Get("sensitive") == "true":
+			value := ""
+			if !fv.IsZero() {
+				value = redacted
+			}
+			attrs = append(attrs, slog.String(name, value))
+		case fv.Kind() == reflect.Struct && !fv.Type().Implements(logValuerType):
+			attrs = append(attrs, slog.Attr{Key: name, Value: redactedValue(fv)})
+		default:
+			attrs = append(attrs, slog.Any(name, fv.Interface()))
+		}
+	}
+
+	return slog.GroupValue(attrs...)
+}
+
+var logValuerType = reflect.TypeOf((*slog.LogValuer)(nil)).Elem()
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogValue_Redacts(t *testing.T) {
+	cfg := &Config{
+		Env:         EnvProd,
+		StoragePath: "postgres://sso:db-password@db/sso",
+		TokenTTL:    time.Hour,
+		GRPC:        GRPCConfig{Port: 44044},
+		JWT:         JWTConfig{SigningKey: "signing-key-value"},
+		Pepper:      "pepper-value",
+	}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("starting application", slog.Any("config", cfg))
+
+	out := buf.String()
+	for _, secret := range []string{"db-password", "signing-key-value", "pepper-value"} {
+		assert.NotContains(t, out, secret)
+	}
+
+	var record struct {
+		Config map[string]any `json:"config"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+
+	assert.Equal(t, EnvProd, record.Config["env"])
+	assert.Equal(t, redacted, record.Config["storage_path"])
+	assert.Equal(t, float64(time.Hour), record.Config["token_ttl"])
+	assert.Equal(t, float64(44044), record.Config["grpc"].(map[string]any)["port"])
+	assert.Equal(t, redacted, record.Config["jwt"].(map[string]any)["signing_key"])
+	assert.Equal(t, "", record.Config["secrets_dir"])
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// redacted - то, что выводится вместо значения секрета в логах и при печати
+const redacted = "[REDACTED]"
+
+// Secret - строка с секретным значением (ключ подписи, pepper, client secret).
+// При печати через fmt, slog и encoding/json значение заменяется на "[REDACTED]",
+// исходное значение доступно только через Reveal
+type Secret string
+
+// Reveal - возвращает настоящее значение секрета
+func (s Secret) Reveal() string { return string(s) }
+
+// String - реализует fmt.Stringer
+func (s Secret) String() string { return redactedOrEmpty(s) }
+
+// GoString - реализует fmt.GoStringer (для %#v)
+func (s Secret) GoString() string { return redactedOrEmpty(s) }
+
+// LogValue - реализует slog.LogValuer
+func (s Secret) LogValue() slog.Value { return slog.StringValue(redactedOrEmpty(s)) }
+
+// MarshalJSON - реализует json.Marshaler
+func (s Secret) MarshalJSON() ([]byte, error) { return json.Marshal(redactedOrEmpty(s)) }
+
+// redactedOrEmpty - пустой секрет показываем как пустой, чтобы было видно, что он не задан
+func redactedOrEmpty(s Secret) string {
+	if s == "" {
+		return ""
+	}
+
+	return redacted
+}
+
+// secretRef - описание одного секрета: имя файла в каталоге секретов,
+// путь из `*_file` и поле, куда нужно положить значение
+type secretRef struct {
+	name  string
+	file  string
+	value *Secret
+}
+
+// secretRefs - список всех секретов конфигурации
+func (c *Config) secretRefs() []secretRef {
+	return []secretRef{
+		{name: "jwt_signing_key", file: c.JWT.SigningKeyFile, value: &c.JWT.SigningKey},
+		{name: "pepper", file: c.PepperFile, value: &c.Pepper},
+		{name: "pii_key", file: c.PIIKeyFile, value: &c.PIIKey},
+		{name: "audit_chain_key", file: c.Audit.ChainKeyFile, value: &c.Audit.ChainKey},
+		{name: "debug_token", file: c.HTTP.Debug.TokenFile, value: &c.HTTP.Debug.Token},
+		{name: "nats_token", file: c.Events.NATS.TokenFile, value: &c.Events.NATS.Token},
+		{name: "smtp_password", file: c.Mail.SMTP.PasswordFile, value: &c.Mail.SMTP.Password},
+		{name: "twilio_auth_token", file: c.SMS.Twilio.AuthTokenFile, value: &c.SMS.Twilio.AuthToken},
+		{name: "captcha_secret", file: c.Captcha.SecretFile, value: &c.Captcha.Secret},
+		{name: "sentry_dsn", file: c.ErrorReporting.DSNFile, value: &c.ErrorReporting.DSN},
+	}
+}
+
+// loadSecrets - подставляет значения секретов из файлов.
+// Приоритет: `*_file` > значение из YAML/ENV > файл с именем секрета в secrets_dir
+func (c *Config) loadSecrets() error {
+	if c.SecretsDir != "" {
+		info, err := os.Stat(c.SecretsDir)
+		if err != nil {
+			return fmt.Errorf("secrets_dir %s: %w", c.SecretsDir, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("secrets_dir %s: not a directory", c.SecretsDir)
+		}
+	}
+
+	var errs []error
+
+	for _, ref := range c.secretRefs() {
+		switch {
+		case ref.file != "":
+			v, err := readSecretFile(ref.file)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ref.name, err))
+				continue
+			}
+			*ref.value = v
+		case *ref.value == "" && c.SecretsDir != "":
+			v, err := readSecretFile(filepath.Join(c.SecretsDir, ref.name))
+			if errors.Is(err, os.ErrNotExist) {
+				continue // Секрет в каталоге необязателен
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", ref.name, err))
+				continue
+			}
+			*ref.value = v
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// readSecretFile - читает секрет из файла, отрезая завершающий перевод строки
+// (echo и kubectl create secret обычно его добавляют)
+func readSecretFile(path string) (Secret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read secret file %s: %w", path, err)
+	}
+
+	return Secret(strings.TrimRight(string(data), "\r\n")), nil
+}
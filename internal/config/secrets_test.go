@@ -0,0 +1,57 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecrets_File(t *testing.T) {
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "signing.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("key-from-file\n"), 0o600))
+
+	cfg := Config{JWT: JWTConfig{SigningKey: "inline", SigningKeyFile: keyFile}}
+
+	require.NoError(t, cfg.loadSecrets())
+	assert.Equal(t, "key-from-file", cfg.JWT.SigningKey.Reveal())
+}
+
+func TestLoadSecrets_Dir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "pepper"), []byte("pepper-from-dir\r\n"), 0o600))
+
+	cfg := Config{SecretsDir: dir, JWT: JWTConfig{SigningKey: "inline"}}
+
+	require.NoError(t, cfg.loadSecrets())
+	assert.Equal(t, "pepper-from-dir", cfg.Pepper.Reveal())
+	assert.Equal(t, "inline", cfg.JWT.SigningKey.Reveal(), "inline value wins over secrets dir")
+}
+
+func TestLoadSecrets_MissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "nope")
+
+	cfg := Config{PepperFile: missing}
+
+	err := cfg.loadSecrets()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, missing)
+}
+
+func TestSecret_Redacted(t *testing.T) {
+	cfg := Config{Pepper: "super-secret-pepper", JWT: JWTConfig{SigningKey: "super-secret-key"}}
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, nil)).Info("config", slog.Any("config", cfg))
+	slog.New(slog.NewTextHandler(&buf, nil)).Info("config", slog.Any("config", cfg))
+	fmt.Fprintf(&buf, "%v %+v %#v", cfg, cfg, cfg)
+
+	assert.NotContains(t, buf.String(), "super-secret")
+	assert.Contains(t, buf.String(), redacted)
+}
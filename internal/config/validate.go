@@ -0,0 +1,965 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"sso/internal/lib/features"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+// Допустимые окружения
+const (
+	EnvLocal = "local"
+	EnvDev   = "dev"
+	EnvProd  = "prod"
+)
+
+// minChainKeyLen - минимальная длина audit.chain_key (ключ HMAC-SHA256 короче размера хеша ослабляет подпись)
+const minChainKeyLen = 32
+
+// Validate - проверяет конфигурацию и возвращает все найденные нарушения одной ошибкой,
+// а не только первое, чтобы опечатки можно было исправить за один заход
+func (c *Config) Validate() error {
+	var errs []error
+
+	if !slices.Contains([]string{EnvLocal, EnvDev, EnvProd}, c.Env) {
+		errs = append(errs, fmt.Errorf("env: must be one of %q, %q, %q, got %q", EnvLocal, EnvDev, EnvProd, c.Env))
+	}
+
+	if strings.TrimSpace(c.StoragePath) == "" {
+		errs = append(errs, errors.New("storage_path: must not be empty"))
+	}
+
+	if c.TokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("token_ttl: must be positive, got %s", c.TokenTTL))
+	}
+
+	if c.JWT.MaxTokenSize < 0 {
+		errs = append(errs, fmt.Errorf("jwt.max_token_size: must not be negative, got %d", c.JWT.MaxTokenSize))
+	}
+
+	if c.JWT.Leeway < 0 {
+		errs = append(errs, fmt.Errorf("jwt.leeway: must not be negative, got %s", c.JWT.Leeway))
+	}
+
+	if c.JWT.UserCacheTTL < 0 || c.JWT.UserCacheTTL > MaxUserCacheTTL {
+		errs = append(errs, fmt.Errorf("jwt.user_cache_ttl: must be between 0 and %s, got %s", MaxUserCacheTTL, c.JWT.UserCacheTTL))
+	}
+
+	if c.Password.HistorySize < 0 {
+		errs = append(errs, fmt.Errorf("password.history_size: must not be negative, got %d", c.Password.HistorySize))
+	}
+
+	if c.Password.MaxAge < 0 {
+		errs = append(errs, fmt.Errorf("password.max_age: must not be negative, got %s", c.Password.MaxAge))
+	}
+
+	if c.Password.Hashing.Workers < 0 {
+		errs = append(errs, fmt.Errorf("password.hashing.workers: must not be negative, got %d", c.Password.Hashing.Workers))
+	}
+
+	if c.Password.Hashing.QueueDepth < 0 {
+		errs = append(errs, fmt.Errorf("password.hashing.queue_depth: must not be negative, got %d", c.Password.Hashing.QueueDepth))
+	}
+
+	if bc := c.Password.BreachCheck; bc.Enabled {
+		if bc.Endpoint == "" {
+			errs = append(errs, errors.New("password.breach_check.endpoint: must not be empty"))
+		}
+
+		if bc.Threshold < 0 {
+			errs = append(errs, fmt.Errorf("password.breach_check.threshold: must not be negative, got %d", bc.Threshold))
+		}
+
+		if bc.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("password.breach_check.timeout: must be positive, got %s", bc.Timeout))
+		}
+
+		if bc.CacheTTL < 0 {
+			errs = append(errs, fmt.Errorf("password.breach_check.cache_ttl: must not be negative, got %s", bc.CacheTTL))
+		}
+	}
+
+	for _, d := range c.Registration.AllowedDomains {
+		if err := validateDomainPattern(d); err != nil {
+			errs = append(errs, fmt.Errorf("registration.allowed_domains: %q: %w", d, err))
+		}
+	}
+
+	for _, d := range c.Registration.BlockedDomains {
+		if err := validateDomainPattern(d); err != nil {
+			errs = append(errs, fmt.Errorf("registration.blocked_domains: %q: %w", d, err))
+		}
+	}
+
+	switch c.Events.Driver {
+	case EventsDriverNone:
+	case EventsDriverNATS:
+		if strings.TrimSpace(c.Events.NATS.URL) == "" {
+			errs = append(errs, errors.New("events.nats.url: must not be empty"))
+		}
+
+		if c.Events.NATS.SubjectPrefix == "" {
+			errs = append(errs, errors.New("events.nats.subject_prefix: must not be empty"))
+		}
+
+		if c.Events.NATS.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("events.nats.timeout: must be positive, got %s", c.Events.NATS.Timeout))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("events.driver: must be empty or %q, got %q", EventsDriverNATS, c.Events.Driver))
+	}
+
+	if ob := c.Events.Outbox; ob.Enabled {
+		if c.Events.Driver == EventsDriverNone {
+			errs = append(errs, errors.New("events.outbox.enabled: requires events.driver"))
+		}
+
+		if ob.PollInterval <= 0 {
+			errs = append(errs, fmt.Errorf("events.outbox.poll_interval: must be positive, got %s", ob.PollInterval))
+		}
+
+		if ob.BatchSize <= 0 {
+			errs = append(errs, fmt.Errorf("events.outbox.batch_size: must be positive, got %d", ob.BatchSize))
+		}
+	}
+
+	switch c.Mail.Driver {
+	case "", MailDriverLog:
+	case MailDriverSMTP:
+		if strings.TrimSpace(c.Mail.SMTP.Host) == "" {
+			errs = append(errs, errors.New("mail.smtp.host: must not be empty"))
+		}
+
+		if c.Mail.SMTP.Port < 1 || c.Mail.SMTP.Port > 65535 {
+			errs = append(errs, fmt.Errorf("mail.smtp.port: must be in range 1-65535, got %d", c.Mail.SMTP.Port))
+		}
+
+		if !slices.Contains([]string{SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone}, c.Mail.SMTP.TLS) {
+			errs = append(errs, fmt.Errorf("mail.smtp.tls: must be one of %q, %q, %q, got %q",
+				SMTPTLSStartTLS, SMTPTLSImplicit, SMTPTLSNone, c.Mail.SMTP.TLS))
+		}
+
+		if c.Mail.SMTP.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("mail.smtp.timeout: must be positive, got %s", c.Mail.SMTP.Timeout))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("mail.driver: must be %q or %q, got %q", MailDriverLog, MailDriverSMTP, c.Mail.Driver))
+	}
+
+	if c.Mail.From != "" {
+		if _, err := mail.ParseAddress(c.Mail.From); err != nil {
+			errs = append(errs, fmt.Errorf("mail.from: %w", err))
+		}
+	}
+
+	if c.Mail.QueueSize < 0 {
+		errs = append(errs, fmt.Errorf("mail.queue_size: must not be negative, got %d", c.Mail.QueueSize))
+	}
+
+	if c.Mail.MaxAttempts < 0 {
+		errs = append(errs, fmt.Errorf("mail.max_attempts: must not be negative, got %d", c.Mail.MaxAttempts))
+	}
+
+	if len(c.GRPC.Listeners) == 0 {
+		if c.GRPC.Port < 1 || c.GRPC.Port > 65535 {
+			errs = append(errs, fmt.Errorf("grpc.port: must be in range 1-65535, got %d", c.GRPC.Port))
+		}
+	} else {
+		if c.GRPC.Port != 0 {
+			errs = append(errs, errors.New("grpc.port: must not be set with grpc.listeners, list the port there"))
+		}
+		errs = append(errs, validateListeners(c.GRPC)...)
+		if c.GRPC.ProxyProtocol {
+			errs = append(errs, errors.New("grpc.proxy_protocol: must not be set with grpc.listeners, set it per listener"))
+		}
+	}
+
+	// Без доверенных сетей адрес из заголовка PROXY всегда игнорировался бы
+	if len(c.GRPC.TrustedProxies) == 0 {
+		if c.GRPC.ProxyProtocol {
+			errs = append(errs, errors.New("grpc.proxy_protocol: requires grpc.trusted_proxies"))
+		}
+		for i, l := range c.GRPC.Listeners {
+			if l.ProxyProtocol {
+				errs = append(errs, fmt.Errorf("grpc.listeners[%d].proxy_protocol: requires grpc.trusted_proxies", i))
+			}
+		}
+	}
+
+	if c.HTTP.Port < 0 || c.HTTP.Port > 65535 {
+		errs = append(errs, fmt.Errorf("http.port: must be in range 0-65535, got %d", c.HTTP.Port))
+	}
+
+	if c.HTTP.Port != 0 {
+		for _, l := range c.GRPC.AllListeners() {
+			if _, port, err := net.SplitHostPort(l.Addr); err == nil && port == strconv.Itoa(c.HTTP.Port) {
+				errs = append(errs, fmt.Errorf("http.port: must differ from gRPC listener %q", l.Addr))
+			}
+		}
+	}
+
+	if g := c.HTTP.Gateway; g.Enabled && c.HTTP.Port == 0 {
+		errs = append(errs, errors.New("http.gateway.enabled: requires http.port"))
+	}
+	if ck := c.HTTP.Gateway.Cookie; ck.Enabled {
+		if !c.HTTP.Gateway.Enabled {
+			errs = append(errs, errors.New("http.gateway.cookie.enabled: requires http.gateway.enabled"))
+		}
+		// Cookie содержит id сессии: без сессий вход через шлюз падал бы только во время работы
+		if c.Sessions.Disabled {
+			errs = append(errs, errors.New("http.gateway.cookie.enabled: requires sessions, but sessions.disabled is set"))
+		}
+		if ck.Name == "" || strings.ContainsAny(ck.Name, " ;,=\"") {
+			errs = append(errs, fmt.Errorf("http.gateway.cookie.name: must be a non-empty cookie name, got %q", ck.Name))
+		}
+		if ck.MaxAge <= 0 {
+			errs = append(errs, fmt.Errorf("http.gateway.cookie.max_age: must be positive, got %s", ck.MaxAge))
+		}
+		if !slices.Contains([]string{"lax", "strict", "none"}, ck.SameSite) {
+			errs = append(errs, fmt.Errorf("http.gateway.cookie.same_site: must be lax, strict or none, got %q", ck.SameSite))
+		}
+	}
+	if cors := c.HTTP.Gateway.CORS; cors.Enabled {
+		if !c.HTTP.Gateway.Enabled {
+			errs = append(errs, errors.New("http.gateway.cors.enabled: requires http.gateway.enabled"))
+		}
+		if !isHeaderName(cors.AppHeader) {
+			errs = append(errs, fmt.Errorf("http.gateway.cors.app_header: must be a header name, got %q", cors.AppHeader))
+		}
+		for _, h := range cors.AllowedHeaders {
+			if !isHeaderName(h) {
+				errs = append(errs, fmt.Errorf("http.gateway.cors.allowed_headers: must be header names, got %q", h))
+			}
+		}
+		if cors.MaxAge < 0 {
+			errs = append(errs, fmt.Errorf("http.gateway.cors.max_age: must not be negative, got %s", cors.MaxAge))
+		}
+	}
+
+	if o := c.HTTP.OIDC; o.Enabled {
+		if c.HTTP.Port == 0 {
+			errs = append(errs, errors.New("http.oidc.enabled: requires http.port"))
+		}
+		if u, err := url.Parse(o.Issuer); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" ||
+			u.RawQuery != "" || u.Fragment != "" || strings.HasSuffix(o.Issuer, "/") {
+			errs = append(errs, fmt.Errorf("http.oidc.issuer: must be an http(s) URL without query, fragment or trailing slash, got %q", o.Issuer))
+		}
+		if o.CodeTTL <= 0 || o.CodeTTL > 10*time.Minute {
+			errs = append(errs, fmt.Errorf("http.oidc.code_ttl: must be in range (0, 10m], got %s", o.CodeTTL))
+		}
+	}
+
+	errs = append(errs, validateInterceptors(c.GRPC)...)
+
+	if c.GRPC.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("grpc.timeout: must not be negative, got %s", c.GRPC.Timeout))
+	}
+
+	if c.GRPC.DebugPayloads && c.Env == EnvProd {
+		errs = append(errs, fmt.Errorf("grpc.debug_payloads: not allowed in env %q", EnvProd))
+	}
+	if c.GRPC.Compression.MinSize < 0 {
+		errs = append(errs, fmt.Errorf("grpc.compression.min_size: must not be negative, got %d", c.GRPC.Compression.MinSize))
+	}
+
+	if c.Maintenance.RetryAfter <= 0 {
+		errs = append(errs, fmt.Errorf("maintenance.retry_after: must be positive, got %s", c.Maintenance.RetryAfter))
+	}
+
+	errs = append(errs, validateRateLimit(c.RateLimit)...)
+	errs = append(errs, validateFeatures(c.Features)...)
+	errs = append(errs, validateSLO(c.GRPC.SLO)...)
+
+	if o := c.Outbound; o.Proxy != "" {
+		if u, err := url.Parse(o.Proxy); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			// Значение не выводим: в адресе прокси может быть пароль
+			errs = append(errs, errors.New("outbound.proxy: must be an http(s) URL like http://host:port"))
+		}
+	}
+	if c.Outbound.MaxIdleConnsPerHost < 0 || c.Outbound.MaxConnsPerHost < 0 {
+		errs = append(errs, errors.New("outbound.max_idle_conns_per_host, outbound.max_conns_per_host: must not be negative"))
+	}
+	if c.Outbound.Retries < 0 {
+		errs = append(errs, fmt.Errorf("outbound.retries: must not be negative, got %d", c.Outbound.Retries))
+	}
+	if c.Outbound.Retries > 0 && !c.Outbound.NoRetries && c.Outbound.RetryBackoff <= 0 {
+		errs = append(errs, fmt.Errorf("outbound.retry_backoff: must be positive with retries, got %s", c.Outbound.RetryBackoff))
+	}
+
+	if c.Schema.CheckInterval <= 0 {
+		errs = append(errs, fmt.Errorf("schema.check_interval: must be positive, got %s", c.Schema.CheckInterval))
+	}
+
+	if er := c.ErrorReporting; er.DSN != "" {
+		// Сам DSN в ошибку не попадает: в нём ключ проекта
+		if u, err := url.Parse(er.DSN.Reveal()); err != nil || (u.Scheme != "https" && u.Scheme != "http") ||
+			u.Host == "" || u.User.Username() == "" || strings.Trim(u.Path, "/") == "" {
+			errs = append(errs, errors.New("error_reporting.dsn: must be like https://key@host/project"))
+		}
+		if er.QueueSize < 1 {
+			errs = append(errs, fmt.Errorf("error_reporting.queue_size: must be at least 1, got %d", er.QueueSize))
+		}
+		if er.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("error_reporting.timeout: must be positive, got %s", er.Timeout))
+		}
+		if er.FlushTimeout <= 0 {
+			errs = append(errs, fmt.Errorf("error_reporting.flush_timeout: must be positive, got %s", er.FlushTimeout))
+		}
+	}
+
+	if c.Geo.CheckInterval <= 0 {
+		errs = append(errs, fmt.Errorf("geo.check_interval: must be positive, got %s", c.Geo.CheckInterval))
+	}
+	if c.Geo.HistoryDepth < 1 {
+		errs = append(errs, fmt.Errorf("geo.history_depth: must be at least 1, got %d", c.Geo.HistoryDepth))
+	}
+	if c.Geo.Retention <= 0 {
+		errs = append(errs, fmt.Errorf("geo.retention: must be positive, got %s", c.Geo.Retention))
+	}
+	if c.Geo.RequireStepUp && c.Geo.CountryDB == "" {
+		errs = append(errs, errors.New("geo.require_step_up: requires geo.country_db"))
+	}
+
+	errs = append(errs, validateNetworks("grpc.admin_allowlist", c.GRPC.AdminAllowlist)...)
+	errs = append(errs, validateNetworks("grpc.trusted_proxies", c.GRPC.TrustedProxies)...)
+
+	if len(c.GRPC.TrustedProxies) > 0 && strings.TrimSpace(c.GRPC.ForwardedHeader) == "" {
+		errs = append(errs, errors.New("grpc.forwarded_header: required with grpc.trusted_proxies"))
+	}
+
+	if _, err := language.Parse(c.GRPC.Locale); err != nil {
+		errs = append(errs, fmt.Errorf("grpc.locale: must be a language tag, got %q", c.GRPC.Locale))
+	}
+
+	if _, err := ParseLevel(c.Log.Level, c.Env); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+
+	if !slices.Contains([]string{"", "text", "json"}, c.Log.Format) {
+		errs = append(errs, fmt.Errorf("log.format: must be text or json, got %q", c.Log.Format))
+	}
+
+	switch c.Log.Output {
+	case "", LogOutputStdout:
+	case LogOutputFile:
+		if c.Log.Path == "" {
+			errs = append(errs, errors.New("log.path: required when log.output is file"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("log.output: must be stdout or file, got %q", c.Log.Output))
+	}
+	errs = append(errs, validateRotation("log", c.Log.MaxSizeMB, c.Log.MaxBackups, c.Log.MaxAge)...)
+
+	switch c.Audit.Output {
+	case "", AuditOutputStdout, AuditOutputSyslog:
+	case AuditOutputFile:
+		if c.Audit.Path == "" {
+			errs = append(errs, errors.New("audit.path: required when audit.output is file"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("audit.output: must be stdout, file or syslog, got %q", c.Audit.Output))
+	}
+	errs = append(errs, validateRotation("audit", c.Audit.MaxSizeMB, c.Audit.MaxBackups, c.Audit.MaxAge)...)
+	if c.Log.Output == LogOutputFile && c.Audit.Output == AuditOutputFile && c.Log.Path == c.Audit.Path {
+		errs = append(errs, errors.New("log.path: must differ from audit.path"))
+	}
+	if c.Audit.Store && c.Audit.Retention <= 0 {
+		errs = append(errs, errors.New("audit.retention: must be positive when audit.store is enabled"))
+	}
+	if c.Audit.Chain && !c.Audit.Store {
+		errs = append(errs, errors.New("audit.chain: requires audit.store"))
+	}
+	if c.Audit.ChainKey != "" && !c.Audit.Chain {
+		errs = append(errs, errors.New("audit.chain_key: requires audit.chain"))
+	}
+	if key := c.Audit.ChainKey; key != "" && len(key) < minChainKeyLen {
+		errs = append(errs, fmt.Errorf("audit.chain_key: must be at least %d bytes", minChainKeyLen))
+	}
+
+	if dir := c.Backup.Dir; dir != "" {
+		if info, err := os.Stat(dir); err != nil {
+			errs = append(errs, fmt.Errorf("backup.dir: %w", err))
+		} else if !info.IsDir() {
+			errs = append(errs, fmt.Errorf("backup.dir: %q is not a directory", dir))
+		}
+	}
+
+	if len(c.Replicas.DSNs) > 0 {
+		for i, dsn := range c.Replicas.DSNs {
+			switch {
+			case strings.TrimSpace(dsn) == "":
+				errs = append(errs, fmt.Errorf("replicas.dsns[%d]: must not be empty", i))
+			case dsn == c.StoragePath:
+				errs = append(errs, fmt.Errorf("replicas.dsns[%d]: must differ from storage_path", i))
+			}
+		}
+		if c.Replicas.HealthInterval <= 0 {
+			errs = append(errs, fmt.Errorf("replicas.health_interval: must be positive, got %s", c.Replicas.HealthInterval))
+		}
+		if c.Replicas.HealthTimeout <= 0 {
+			errs = append(errs, fmt.Errorf("replicas.health_timeout: must be positive, got %s", c.Replicas.HealthTimeout))
+		}
+	}
+
+	if c.UserApps.RequireAppOnRegister && !c.UserApps.Enabled {
+		errs = append(errs, errors.New("user_apps.require_app_on_register: requires user_apps.enabled"))
+	}
+
+	switch c.Organizations.EmailUniqueness {
+	case "", EmailUniqueGlobal, EmailUniqueOrg:
+	default:
+		errs = append(errs, fmt.Errorf("organizations.email_uniqueness: must be %s or %s, got %q",
+			EmailUniqueGlobal, EmailUniqueOrg, c.Organizations.EmailUniqueness))
+	}
+
+	if c.Invitations.TTL < 0 {
+		errs = append(errs, fmt.Errorf("invitations.ttl: must not be negative, got %s", c.Invitations.TTL))
+	}
+	if raw := c.Invitations.AcceptURL; raw != "" {
+		if u, err := url.Parse(raw); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("invitations.accept_url: must be an absolute http(s) URL, got %q", raw))
+		}
+	}
+
+	if d := c.AccountDeletion; d.Enabled {
+		if d.GracePeriod <= 0 {
+			errs = append(errs, fmt.Errorf("account_deletion.grace_period: must be positive, got %s", d.GracePeriod))
+		}
+		if raw := d.CancelURL; raw != "" {
+			if u, err := url.Parse(raw); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("account_deletion.cancel_url: must be an absolute http(s) URL, got %q", raw))
+			}
+		}
+	}
+
+	if o := c.OfflineTokens; o.Enabled {
+		if o.TTL <= 0 {
+			errs = append(errs, fmt.Errorf("offline_tokens.ttl: must be positive, got %s", o.TTL))
+		}
+		if o.MFAMaxAge <= 0 {
+			errs = append(errs, fmt.Errorf("offline_tokens.mfa_max_age: must be positive, got %s", o.MFAMaxAge))
+		}
+		if len(o.Scopes) == 0 {
+			errs = append(errs, errors.New("offline_tokens.scopes: at least one scope is required"))
+		}
+		for _, scope := range o.Scopes {
+			if scope == "" || strings.ContainsAny(scope, " \t\n") {
+				errs = append(errs, fmt.Errorf("offline_tokens.scopes: %q must be a single word", scope))
+			}
+		}
+	}
+
+	if r := c.Refresh; r.Enabled {
+		if r.SlidingWindow <= 0 {
+			errs = append(errs, fmt.Errorf("refresh.sliding_window: must be positive, got %s", r.SlidingWindow))
+		}
+		if r.MaxLifetime <= 0 {
+			errs = append(errs, fmt.Errorf("refresh.max_lifetime: must be positive, got %s", r.MaxLifetime))
+		}
+		if r.SlidingWindow > 0 && r.MaxLifetime > 0 && r.SlidingWindow > r.MaxLifetime {
+			errs = append(errs, fmt.Errorf("refresh.sliding_window: must not exceed refresh.max_lifetime (%s), got %s",
+				r.MaxLifetime, r.SlidingWindow))
+		}
+	}
+
+	if g := c.Guests; g.Enabled {
+		if g.TokenTTL <= 0 {
+			errs = append(errs, fmt.Errorf("guests.token_ttl: must be positive, got %s", g.TokenTTL))
+		}
+
+		if g.InactiveAfter <= 0 {
+			errs = append(errs, fmt.Errorf("guests.inactive_after: must be positive, got %s", g.InactiveAfter))
+		}
+	}
+
+	if la := c.LoginAlerts; la.Enabled {
+		if la.SessionsURL != "" {
+			if u, err := url.Parse(la.SessionsURL); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("login_alerts.sessions_url: must be an absolute http(s) URL, got %q", la.SessionsURL))
+			}
+		}
+
+		if la.Interval <= 0 {
+			errs = append(errs, fmt.Errorf("login_alerts.interval: must be positive, got %s", la.Interval))
+		}
+	}
+
+	if d := c.Device; d.VerificationURI != "" {
+		if u, err := url.Parse(d.VerificationURI); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("device.verification_uri: must be an absolute http(s) URL, got %q", d.VerificationURI))
+		}
+
+		if d.CodeTTL <= 0 {
+			errs = append(errs, fmt.Errorf("device.code_ttl: must be positive, got %s", d.CodeTTL))
+		}
+
+		if d.PollInterval < time.Second {
+			errs = append(errs, fmt.Errorf("device.poll_interval: must be at least 1s, got %s", d.PollInterval))
+		}
+	}
+
+	if pk := c.Passkey; pk.RPID != "" {
+		if strings.Contains(pk.RPID, "/") || strings.Contains(pk.RPID, ":") {
+			errs = append(errs, fmt.Errorf("passkey.rp_id: must be a domain without scheme and port, got %q", pk.RPID))
+		}
+
+		if len(pk.Origins) == 0 {
+			errs = append(errs, errors.New("passkey.origins: at least one origin is required"))
+		}
+		for _, origin := range pk.Origins {
+			u, err := url.Parse(origin)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || (u.Path != "" && u.Path != "/") {
+				errs = append(errs, fmt.Errorf("passkey.origins: must be an origin like https://example.com, got %q", origin))
+			}
+		}
+
+		if pk.ChallengeTTL <= 0 {
+			errs = append(errs, fmt.Errorf("passkey.challenge_ttl: must be positive, got %s", pk.ChallengeTTL))
+		}
+		if pk.MaxPerUser < 1 {
+			errs = append(errs, fmt.Errorf("passkey.max_per_user: must be at least 1, got %d", pk.MaxPerUser))
+		}
+	}
+
+	if s := c.SMS; s.Driver != SMSDriverNone {
+		switch s.Driver {
+		case SMSDriverLog:
+		case SMSDriverTwilio:
+			u, err := url.Parse(s.Twilio.Endpoint)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("sms.twilio.endpoint: must be an http(s) URL, got %q", s.Twilio.Endpoint))
+			}
+			if s.Twilio.AccountSID == "" {
+				errs = append(errs, errors.New("sms.twilio.account_sid: required for driver twilio"))
+			}
+			if s.Twilio.AuthToken == "" {
+				errs = append(errs, errors.New("sms.twilio.auth_token: required for driver twilio"))
+			}
+			if s.Twilio.From == "" {
+				errs = append(errs, errors.New("sms.twilio.from: required for driver twilio"))
+			}
+			if s.Twilio.Timeout <= 0 {
+				errs = append(errs, fmt.Errorf("sms.twilio.timeout: must be positive, got %s", s.Twilio.Timeout))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("sms.driver: must be %q or %q, got %q", SMSDriverLog, SMSDriverTwilio, s.Driver))
+		}
+
+		if s.ChallengeTTL <= 0 {
+			errs = append(errs, fmt.Errorf("sms.challenge_ttl: must be positive, got %s", s.ChallengeTTL))
+		}
+		if s.CodeTTL <= 0 || s.CodeTTL > s.ChallengeTTL {
+			errs = append(errs, fmt.Errorf("sms.code_ttl: must be positive and not longer than sms.challenge_ttl, got %s", s.CodeTTL))
+		}
+		if s.MaxAttempts < 1 {
+			errs = append(errs, fmt.Errorf("sms.max_attempts: must be at least 1, got %d", s.MaxAttempts))
+		}
+		if s.ResendInterval < 0 {
+			errs = append(errs, fmt.Errorf("sms.resend_interval: must not be negative, got %s", s.ResendInterval))
+		}
+		if s.SendWindow <= 0 {
+			errs = append(errs, fmt.Errorf("sms.send_window: must be positive, got %s", s.SendWindow))
+		}
+		if s.MaxSendsPerUser < 1 {
+			errs = append(errs, fmt.Errorf("sms.max_sends_per_user: must be at least 1, got %d", s.MaxSendsPerUser))
+		}
+		if s.MaxSendsPerPhone < 1 {
+			errs = append(errs, fmt.Errorf("sms.max_sends_per_phone: must be at least 1, got %d", s.MaxSendsPerPhone))
+		}
+	}
+
+	if cp := c.Captcha; cp.Enabled() {
+		switch cp.Provider {
+		case CaptchaProviderReCAPTCHA, CaptchaProviderHCaptcha, CaptchaProviderTurnstile:
+		default:
+			errs = append(errs, fmt.Errorf("captcha.provider: must be %q, %q, %q or %q, got %q",
+				CaptchaProviderDisabled, CaptchaProviderReCAPTCHA, CaptchaProviderHCaptcha, CaptchaProviderTurnstile, cp.Provider))
+		}
+
+		if cp.Endpoint != "" {
+			u, err := url.Parse(cp.Endpoint)
+			if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("captcha.endpoint: must be an http(s) URL, got %q", cp.Endpoint))
+			}
+		}
+		if cp.Secret == "" {
+			errs = append(errs, errors.New("captcha.secret: required when captcha is enabled"))
+		}
+		if cp.MinScore < 0 || cp.MinScore > 1 {
+			errs = append(errs, fmt.Errorf("captcha.min_score: must be between 0 and 1, got %v", cp.MinScore))
+		}
+		if cp.Timeout <= 0 {
+			errs = append(errs, fmt.Errorf("captcha.timeout: must be positive, got %s", cp.Timeout))
+		}
+		if cp.LoginFailures < 0 {
+			errs = append(errs, fmt.Errorf("captcha.login_failures: must not be negative, got %d", cp.LoginFailures))
+		}
+		if cp.FailureWindow <= 0 {
+			errs = append(errs, fmt.Errorf("captcha.failure_window: must be positive, got %s", cp.FailureWindow))
+		}
+	}
+
+	if c.Revocations.PollInterval <= 0 {
+		errs = append(errs, fmt.Errorf("revocations.poll_interval: must be positive, got %s", c.Revocations.PollInterval))
+	}
+	if c.Revocations.KeepaliveInterval <= 0 {
+		errs = append(errs, fmt.Errorf("revocations.keepalive_interval: must be positive, got %s", c.Revocations.KeepaliveInterval))
+	}
+	if c.Revocations.Retention <= 0 {
+		errs = append(errs, fmt.Errorf("revocations.retention: must be positive, got %s", c.Revocations.Retention))
+	}
+	if c.Revocations.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("revocations.buffer_size: must be positive, got %d", c.Revocations.BufferSize))
+	}
+
+	if c.Janitor.Interval <= 0 {
+		errs = append(errs, fmt.Errorf("janitor.interval: must be positive, got %s", c.Janitor.Interval))
+	}
+
+	if c.Janitor.BatchSize <= 0 {
+		errs = append(errs, fmt.Errorf("janitor.batch_size: must be positive, got %d", c.Janitor.BatchSize))
+	}
+
+	if c.Metrics.CacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("metrics.cache_ttl: must not be negative, got %s", c.Metrics.CacheTTL))
+	}
+
+	if c.Metrics.OutboxMaxAge < 0 {
+		errs = append(errs, fmt.Errorf("metrics.outbox_max_age: must not be negative, got %s", c.Metrics.OutboxMaxAge))
+	}
+
+	if c.SelfTest.Enabled && c.SelfTest.StaleAfter <= 0 {
+		errs = append(errs, fmt.Errorf("selftest.stale_after: must be positive, got %s", c.SelfTest.StaleAfter))
+	}
+
+	return errors.Join(errs...)
+}
+
+// warnUnknownKeys - предупреждает о ключах YAML, которых нет в Config (например, опечатка `token_tll`).
+// Логгер приложения ещё не создан, поэтому пишем через slog по умолчанию (stderr).
+func warnUnknownKeys(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return // Ошибку чтения сообщит cleanenv
+	}
+
+	var raw map[string]any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return
+	}
+
+	for _, key := range unknownKeys(raw, reflect.TypeOf(Config{}), "") {
+		slog.Warn("unknown config key", slog.String("key", key), slog.String("path", path))
+	}
+}
+
+// unknownKeys - рекурсивно сравнивает ключи YAML с yaml-тегами структуры и возвращает лишние
+func unknownKeys(raw map[string]any, t reflect.Type, prefix string) []string {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name, _, _ := strings.Cut(f.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fields[name] = f.Type
+	}
+
+	var res []string
+	for key, value := range raw {
+		ft, ok := fields[key]
+		if !ok {
+			res = append(res, prefix+key)
+			continue
+		}
+
+		nested, ok := value.(map[string]any)
+		if ok && ft.Kind() == reflect.Struct {
+			res = append(res, unknownKeys(nested, ft, prefix+key+".")...)
+		}
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// ParseLevel - возвращает уровень логирования: явно заданный level (debug, info, warn, error)
+// или значение по умолчанию для env (debug для local/dev, info для остальных)
+func ParseLevel(level, env string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "":
+		if env == EnvLocal || env == EnvDev {
+			return slog.LevelDebug, nil
+		}
+
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+
+	return 0, fmt.Errorf("unknown log level %q", level)
+}
+
+// validateNetworks - каждый элемент - сеть CIDR или одиночный адрес
+func validateNetworks(field string, networks []string) []error {
+	var errs []error
+	for i, n := range networks {
+		n = strings.TrimSpace(n)
+		if _, err := netip.ParsePrefix(n); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(n); err != nil {
+			errs = append(errs, fmt.Errorf("%s[%d]: must be a CIDR or an IP address, got %q", field, i, n))
+		}
+	}
+
+	return errs
+}
+
+// validateListeners - адреса корректны и не повторяются, сервисы известны, для TLS задан сертификат
+func validateListeners(c GRPCConfig) []error {
+	var errs []error
+
+	known := []string{GRPCServiceAuth, GRPCServiceAdmin, GRPCServiceHealth, GRPCServiceReflection}
+	addrs := make(map[string]bool, len(c.Listeners))
+	withTLS := false
+
+	for i, l := range c.Listeners {
+		field := fmt.Sprintf("grpc.listeners[%d]", i)
+
+		_, portStr, err := net.SplitHostPort(l.Addr)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s.addr: must be host:port, got %q", field, l.Addr))
+		} else if port, err := strconv.Atoi(portStr); err != nil || port < 1 || port > 65535 {
+			errs = append(errs, fmt.Errorf("%s.addr: port must be in range 1-65535, got %q", field, portStr))
+		}
+		if addrs[l.Addr] {
+			errs = append(errs, fmt.Errorf("%s.addr: duplicate address %q", field, l.Addr))
+		}
+		addrs[l.Addr] = true
+
+		seen := make(map[string]bool, len(l.Services))
+		for _, s := range l.Services {
+			if !slices.Contains(known, s) {
+				errs = append(errs, fmt.Errorf("%s.services: must be one of %q, got %q", field, known, s))
+			}
+			if seen[s] {
+				errs = append(errs, fmt.Errorf("%s.services: duplicate service %q", field, s))
+			}
+			seen[s] = true
+		}
+
+		withTLS = withTLS || l.TLS
+	}
+
+	if withTLS && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("grpc.tls: cert_file and key_file are required for listeners with tls"))
+	}
+
+	return errs
+}
+
+// validateInterceptors - имена известны и не повторяются, recovery и auth включены, снаружи recovery
+// только GRPCContextInterceptors, а при заданном admin_allowlist его интерцептор включён и стоит
+// после client_ip (иначе адрес клиента неизвестен)
+func validateInterceptors(c GRPCConfig) []error {
+	var errs []error
+
+	names := c.EnabledInterceptors()
+	seen := make(map[string]int, len(names))
+	for i, name := range names {
+		if !slices.Contains(DefaultGRPCInterceptors, name) {
+			errs = append(errs, fmt.Errorf("grpc.interceptors[%d]: must be one of %q, got %q", i, DefaultGRPCInterceptors, name))
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			errs = append(errs, fmt.Errorf("grpc.interceptors[%d]: duplicate interceptor %q", i, name))
+			continue
+		}
+		seen[name] = i
+	}
+
+	for _, required := range []string{InterceptorRecovery, InterceptorAuth} {
+		if _, ok := seen[required]; !ok {
+			errs = append(errs, fmt.Errorf("grpc.interceptors: %q is required", required))
+		}
+	}
+
+	if recovery, ok := seen[InterceptorRecovery]; ok {
+		for _, name := range names[:recovery] {
+			if !slices.Contains(GRPCContextInterceptors, name) {
+				errs = append(errs, fmt.Errorf("grpc.interceptors: %q must come after %q (only %q may wrap it)",
+					name, InterceptorRecovery, GRPCContextInterceptors))
+			}
+		}
+	}
+
+	// Выключенный интерцептор молча снял бы ограничение сетей для Admin
+	if len(c.AdminAllowlist) > 0 {
+		allowlist, ok := seen[InterceptorAdminAllowlist]
+		if !ok {
+			errs = append(errs, fmt.Errorf("grpc.admin_allowlist: requires interceptor %q", InterceptorAdminAllowlist))
+		} else if ip, ok := seen[InterceptorClientIP]; !ok || ip > allowlist {
+			errs = append(errs, fmt.Errorf("grpc.interceptors: %q requires %q before it", InterceptorAdminAllowlist, InterceptorClientIP))
+		}
+	}
+
+	return errs
+}
+
+// validateFeatures - флаги известны сборке, доля в процентах, app_id положительные
+func validateFeatures(flags map[string]features.Rule) []error {
+	names := make([]string, 0, len(flags))
+	for name := range flags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		r := flags[name]
+		field := "features." + name
+
+		if !features.Known(name) {
+			errs = append(errs, fmt.Errorf("%s: unknown feature flag", field))
+		}
+		if r.Percent < 0 || r.Percent > 100 {
+			errs = append(errs, fmt.Errorf("%s.percent: must be between 0 and 100, got %d", field, r.Percent))
+		}
+		for i, id := range r.AppIDs {
+			if id <= 0 {
+				errs = append(errs, fmt.Errorf("%s.app_ids[%d]: must be positive, got %d", field, i, id))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateRateLimit - лимиты корректны, у каждого правила есть метод, и правила не повторяются
+func validateRateLimit(c RateLimitConfig) []error {
+	var errs []error
+
+	validLimit := func(field string, l RateLimit) {
+		if l.Rate < 0 {
+			errs = append(errs, fmt.Errorf("%s.rate: must not be negative, got %v", field, l.Rate))
+		}
+		if l.Rate > 0 && l.Burst < 1 {
+			errs = append(errs, fmt.Errorf("%s.burst: must be at least 1 when rate is set, got %d", field, l.Burst))
+		}
+	}
+
+	validLimit("rate_limit.default", c.Default)
+
+	seen := make(map[string]bool, len(c.Rules))
+	for i, r := range c.Rules {
+		field := fmt.Sprintf("rate_limit.rules[%d]", i)
+
+		if !strings.HasPrefix(r.Method, "/") {
+			errs = append(errs, fmt.Errorf("%s.method: must be a full method name like /auth.Auth/Login, got %q", field, r.Method))
+		}
+		if r.AppID < 0 {
+			errs = append(errs, fmt.Errorf("%s.app_id: must not be negative, got %d", field, r.AppID))
+		}
+		if r.AppID != 0 && r.PerApp {
+			errs = append(errs, fmt.Errorf("%s: per_app has no effect on a rule for a single app_id", field))
+		}
+		validLimit(field, r.Limit())
+
+		key := fmt.Sprintf("%s app=%d", r.Method, r.AppID)
+		if seen[key] {
+			errs = append(errs, fmt.Errorf("%s: duplicate rule for %s", field, key))
+		}
+		seen[key] = true
+	}
+
+	return errs
+}
+
+// validateSLO - пороги положительные, у каждого есть метод, и методы не повторяются.
+// Существование методов проверяется при запуске gRPC-сервера (interceptors.NewSLO)
+func validateSLO(c SLOConfig) []error {
+	var errs []error
+
+	if c.DefaultLatency < 0 {
+		errs = append(errs, fmt.Errorf("grpc.slo.default_latency: must not be negative, got %s", c.DefaultLatency))
+	}
+
+	seen := make(map[string]bool, len(c.Latency))
+	for i, l := range c.Latency {
+		field := fmt.Sprintf("grpc.slo.latency[%d]", i)
+
+		if !strings.HasPrefix(l.Method, "/") {
+			errs = append(errs, fmt.Errorf("%s.method: must be a full method name like /auth.Auth/Login, got %q", field, l.Method))
+		}
+		if l.Threshold <= 0 {
+			errs = append(errs, fmt.Errorf("%s.threshold: must be positive, got %s", field, l.Threshold))
+		}
+		if seen[l.Method] {
+			errs = append(errs, fmt.Errorf("%s: duplicate threshold for %s", field, l.Method))
+		}
+		seen[l.Method] = true
+	}
+
+	return errs
+}
+
+// validateRotation - параметры ротации файла лога (section - log или audit) не отрицательные
+func validateRotation(section string, maxSizeMB, maxBackups int, maxAge time.Duration) []error {
+	var errs []error
+
+	if maxSizeMB < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_size_mb: must not be negative, got %d", section, maxSizeMB))
+	}
+	if maxBackups < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_backups: must not be negative, got %d", section, maxBackups))
+	}
+	if maxAge < 0 {
+		errs = append(errs, fmt.Errorf("%s.max_age: must not be negative, got %s", section, maxAge))
+	}
+
+	return errs
+}
+
+// validateDomainPattern - домен или "*.домен"; "*" допустим только в начале
+func validateDomainPattern(pattern string) error {
+	domain := strings.TrimPrefix(strings.TrimSpace(pattern), "*.")
+
+	switch {
+	case domain == "":
+		return errors.New("must not be empty")
+	case strings.ContainsAny(domain, "*@ "):
+		return errors.New(`must be a domain or "*.domain"`)
+	}
+
+	return nil
+}
+
+// isHeaderName - непустое имя HTTP-заголовка (token из RFC 9110: без пробелов, двоеточий и разделителей)
+func isHeaderName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, " \t:,;\"()/<>=?@[\\]{}")
+}
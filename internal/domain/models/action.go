@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// ActionToken - неиспользованный токен одного действия
+type ActionToken struct {
+	JTI       string
+	UserID    int64
+	Action    string // для какого действия выдан (клейм purpose)
+	Payload   []byte // данные действия (например, id выгрузки); в токене лежит только их хеш
+	ExpiresAt time.Time
+}
@@ -1,7 +1,33 @@
 package models
 
+import "time"
+
 type App struct {
 	ID     int
 	Name   string
 	Secret string // секрет нужен, чтобы подписывать токены и валидировать их в последующем
+
+	Algorithm  string // алгоритм подписи токенов (HS256, ES256, EdDSA); пусто - HS256
+	SigningKey string // закрытый ключ в PEM для ES256/EdDSA
+
+	GroupsClaim bool // добавлять ли в токены клейм groups
+	ThirdParty  bool // стороннее приложение: вход требует согласия пользователя (Consent)
+
+	MaxSessions        int    // сколько одновременных сессий пользователя допускается (0 - без ограничения)
+	SessionLimitPolicy string // что делать при превышении MaxSessions (SessionLimitReject или SessionLimitEvictOldest)
+
+	SessionIdleTimeout time.Duration // сессия без активности дольше этого истекает (0 - без ограничения)
+	SessionMaxLifetime time.Duration // сессия истекает через это время после входа, даже если активна (0 - без ограничения)
+
+	RefreshSlidingWindow time.Duration // refresh-токен истекает, если его не обновили за это время (0 - по умолчанию сервиса)
+	RefreshMaxLifetime   time.Duration // после этого срока от входа обновление невозможно (0 - по умолчанию сервиса)
+
+	MinACR string // минимальный уровень аутентификации (ACRMultiFactor - нужен второй фактор; пусто - любой)
+
+	AcceptOfflineTokens bool // приложение принимает offline-токены фоновых агентов (OfflineToken)
+
+	Provisioner bool // роль provisioner: приложение может создавать и отключать пользователей (ProvisionedUser)
+
+	LoginPolicies        []LoginPolicy // ограничения входа по сети и времени (все должны выполняться)
+	RecheckNetworkPolicy bool          // проверять сеть политик и у выданных токенов, а не только при входе
 }
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// AuditRecord - событие журнала безопасности, сохранённое в БД (audit.store)
+type AuditRecord struct {
+	ID         int64
+	OccurredAt time.Time
+	Event      string // Имя события (audit.EventLoginSucceeded и т. д.)
+	Outcome    string
+	UserID     int64
+	AppID      int
+	Email      string
+	Reason     string
+	ActorID    int64
+	RequestID  string
+	IP         string // Адрес клиента или, если он неизвестен, адрес соединения
+	PrevHash   string // Хеш предыдущего звена цепочки (audit.chain; пусто - событие вне цепочки)
+	Hash       string // Хеш события (auditchain.Hash)
+}
+
+// AuditChainHead - последнее звено цепочки журнала безопасности
+type AuditChainHead struct {
+	EventID int64
+	Hash    string
+	MAC     string // HMAC звена ключом audit.chain_key (пусто - ключ не задан)
+}
+
+// AuditChainReport - результат проверки цепочки журнала безопасности
+type AuditChainReport struct {
+	RowsChecked   int
+	Unchained     int   // События в начале диапазона, записанные до включения цепочки
+	FirstBrokenID int64 // ID первого события, на котором цепочка рвётся (0 - цепочка цела)
+	Reason        string
+	HeadVerified  bool // Диапазон дошёл до конца журнала и последнее звено совпало с audit_chain_head
+}
+
+// OK - цепочка в проверенном диапазоне цела
+func (r AuditChainReport) OK() bool {
+	return r.FirstBrokenID == 0
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// Consent - согласие пользователя передавать данные стороннему приложению
+type Consent struct {
+	ID        int64
+	UserID    int64
+	AppID     int
+	Scopes    []string // что пользователь разрешил передавать (email, profile)
+	GrantedAt time.Time
+	RevokedAt time.Time // нулевое значение - согласие действует
+}
+
+// Active - действует ли согласие
+func (c Consent) Active() bool {
+	return c.RevokedAt.IsZero()
+}
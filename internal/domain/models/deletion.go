@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// AccountDeletion - запрошенное пользователем удаление аккаунта, ожидающее срока
+type AccountDeletion struct {
+	UserID      int64
+	TokenHash   string // SHA-256 токена отмены из письма (hex); сам токен есть только в письме
+	RequestedAt time.Time
+	ExecuteAt   time.Time // когда аккаунт будет обезличен
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Состояния входа на устройстве
+const (
+	DevicePending  = "pending"  // ждёт решения пользователя
+	DeviceApproved = "approved" // пользователь разрешил вход, устройство ещё не забрало токен
+	DeviceDenied   = "denied"   // пользователь отказал
+	DeviceConsumed = "consumed" // устройство получило токен
+)
+
+// DeviceAuthorization - вход на устройстве без браузера (RFC 8628)
+type DeviceAuthorization struct {
+	ID             int64
+	DeviceCodeHash string // SHA-256 device_code (hex); сам код есть только у устройства
+	UserCode       string // короткий код, который пользователь вводит на другом устройстве (без дефиса)
+	AppID          int
+	Status         string        // DevicePending, DeviceApproved, DeviceDenied или DeviceConsumed
+	UserID         int64         // кто принял решение (0 - пока никто)
+	Interval       time.Duration // как часто устройству можно опрашивать PollDeviceToken
+	LastPolledAt   time.Time     // нулевое значение - ещё не опрашивало
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+// DeviceCodes - коды, которые получает устройство в начале входа
+type DeviceCodes struct {
+	DeviceCode              string // секрет устройства для PollDeviceToken
+	UserCode                string // код для пользователя в виде XXXX-XXXX
+	VerificationURI         string // где пользователь вводит код
+	VerificationURIComplete string // та же страница с уже подставленным кодом (для QR-кода)
+	ExpiresAt               time.Time
+	Interval                time.Duration
+}
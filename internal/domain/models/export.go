@@ -0,0 +1,23 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// UserDataExport - всё, что сервис хранит о пользователе (ответ на запрос субъекта данных)
+type UserDataExport struct {
+	ExportedAt  time.Time       `json:"exported_at"`
+	Profile     UserProfile     `json:"profile"`
+	Metadata    json.RawMessage `json:"metadata"`
+	AppMetadata json.RawMessage `json:"app_metadata,omitempty"` // {"<app_id>": {...}}
+	Apps        []int           `json:"apps,omitempty"`         // Приложения пользователя (user_apps)
+}
+
+// UserProfile - профиль пользователя без секретов
+type UserProfile struct {
+	ID       int64  `json:"id"`
+	Email    string `json:"email"`
+	IsAdmin  bool   `json:"is_admin"`
+	IsActive bool   `json:"is_active"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Group - группа пользователей ("engineering", "finance"); приложения авторизуют по клейму groups
+type Group struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+// GroupMember - участник группы
+type GroupMember struct {
+	UserID   int64
+	Email    string
+	JoinedAt time.Time
+}
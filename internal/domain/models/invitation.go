@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Роли, которые назначает приглашение
+const (
+	RoleUser  = "user"  // обычный пользователь
+	RoleAdmin = "admin" // администратор сервиса (users.is_admin)
+)
+
+// Состояния приглашения
+const (
+	InvitationPending  = "pending"  // ждёт ответа
+	InvitationAccepted = "accepted" // принято, пользователь создан
+	InvitationRevoked  = "revoked"  // отозвано администратором
+	InvitationExpired  = "expired"  // не принято вовремя
+)
+
+// Invitation - приглашение зарегистрироваться по ссылке из письма
+type Invitation struct {
+	ID        int64
+	Email     string
+	TokenHash string // SHA-256 одноразового токена (hex); сам токен есть только в письме
+	AppID     int    // приложение, для которого выдаётся токен после принятия
+	Role      string // RoleUser или RoleAdmin
+	InvitedBy int64  // кто пригласил (0 - неизвестно)
+	CreatedAt time.Time
+	ExpiresAt time.Time
+
+	AcceptedAt time.Time // нулевое значение - ещё не принято
+	RevokedAt  time.Time // нулевое значение - не отозвано
+	UserID     int64     // созданный пользователь (после принятия)
+}
+
+// Pending - ждёт ли приглашение ответа (не принято и не отозвано; срок действия не учитывается)
+func (i Invitation) Pending() bool {
+	return i.AcceptedAt.IsZero() && i.RevokedAt.IsZero()
+}
+
+// Status - состояние приглашения на момент now
+func (i Invitation) Status(now time.Time) string {
+	switch {
+	case !i.AcceptedAt.IsZero():
+		return InvitationAccepted
+	case !i.RevokedAt.IsZero():
+		return InvitationRevoked
+	case now.After(i.ExpiresAt):
+		return InvitationExpired
+	default:
+		return InvitationPending
+	}
+}
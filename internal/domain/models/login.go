@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// GeoLocation - страна и сеть, определённые по адресу клиента
+type GeoLocation struct {
+	Country string // ISO 3166-1 alpha-2 ("DE"); пусто, если не определена
+	ASN     uint   // номер автономной системы; 0, если не определён
+}
+
+// LoginRecord - успешный вход в истории входов пользователя
+type LoginRecord struct {
+	ID         int64
+	UserID     int64
+	AppID      int
+	Country    string // пусто, если GeoIP не настроен или страна не определена
+	ASN        uint
+	Device     string // отпечаток устройства (useragent.Fingerprint); пусто, если клиент не прислал User-Agent
+	Suspicious bool   // вход из страны, которой нет в недавней истории
+	Offline    bool   // выдан offline-токен фоновому агенту, а не интерактивный вход
+	LoggedInAt time.Time
+}
@@ -0,0 +1,73 @@
+package models
+
+import (
+	"net/netip"
+	"slices"
+	"time"
+)
+
+// Условия политики входа: какое из них нарушено (LoginPolicy.Violation)
+const (
+	PolicyRuleNetwork = "network"
+	PolicyRuleWeekday = "weekday"
+	PolicyRuleTime    = "time"
+)
+
+// LoginPolicy - ограничение входа в приложение по сети, дню недели и времени суток.
+// Пустое условие не ограничивает; вход разрешён, только если выполнены все политики приложения
+type LoginPolicy struct {
+	Name        string
+	Networks    []netip.Prefix // сети, из которых разрешён вход
+	Weekdays    []time.Weekday // дни недели в часовом поясе Location
+	StartMinute int            // начало окна в минутах от полуночи (включительно)
+	EndMinute   int            // конец окна (не включительно); равен началу - весь день, меньше - окно через полночь
+	Location    *time.Location // часовой пояс дней и окна (nil - UTC)
+}
+
+// Violation - какое условие политики нарушает вход с адреса addr в момент now ("" - никакое).
+// Неизвестный адрес не входит ни в одну сеть
+func (p LoginPolicy) Violation(addr netip.Addr, now time.Time) string {
+	if !p.NetworkAllowed(addr) {
+		return PolicyRuleNetwork
+	}
+
+	loc := p.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+
+	if len(p.Weekdays) > 0 && !slices.Contains(p.Weekdays, now.Weekday()) {
+		return PolicyRuleWeekday
+	}
+
+	minute := now.Hour()*60 + now.Minute()
+	switch {
+	case p.StartMinute == p.EndMinute:
+	case p.StartMinute < p.EndMinute && (minute < p.StartMinute || minute >= p.EndMinute):
+		return PolicyRuleTime
+	case p.StartMinute > p.EndMinute && minute < p.StartMinute && minute >= p.EndMinute:
+		return PolicyRuleTime
+	}
+
+	return ""
+}
+
+// NetworkAllowed - выполнено ли сетевое условие политики для адреса addr
+func (p LoginPolicy) NetworkAllowed(addr netip.Addr) bool {
+	if len(p.Networks) == 0 {
+		return true
+	}
+	if !addr.IsValid() {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, n := range p.Networks {
+		if n.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// OfflineToken - долгоживущий токен фонового агента (синхронизация от имени пользователя, пока его нет).
+// Хранится отдельно от сессий входа; ID совпадает с jti токена
+type OfflineToken struct {
+	ID        string
+	UserID    int64
+	AppID     int
+	Label     string   // подпись, которую видит пользователь ("Ноутбук, синхронизация")
+	Scopes    []string // права токена (уже, чем у токена входа)
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	RevokedAt time.Time // нулевое значение - токен не отозван
+}
+
+// Active - токен не отозван и не истёк к моменту now
+func (t OfflineToken) Active(now time.Time) bool {
+	return t.RevokedAt.IsZero() && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// AuthorizationRequest - запрос авторизации OpenID Connect (authorization code flow с PKCE)
+type AuthorizationRequest struct {
+	ClientID      int    // id приложения
+	RedirectURI   string // куда вернуть код; должен точно совпадать с одним из адресов приложения
+	Scope         string // права через пробел, среди них обязательно openid
+	State         string // значение клиента, возвращается вместе с кодом
+	Nonce         string // значение клиента, попадает в ID-токен
+	CodeChallenge string // PKCE: base64url(SHA-256(code_verifier))
+}
+
+// AuthCode - выданный код авторизации
+type AuthCode struct {
+	ID            string    // SHA-256 кода (hex); сам код есть только у клиента
+	AppID         int       // приложение, которому выдан код
+	RedirectURI   string    // redirect_uri запроса авторизации (обмен должен передать тот же)
+	CodeChallenge string    // PKCE S256
+	Nonce         string    // nonce запроса авторизации
+	SealedToken   []byte    // токен доступа, зашифрованный ключом из кода
+	ExpiresAt     time.Time // срок действия кода
+}
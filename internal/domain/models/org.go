@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Роли участника организации
+const (
+	OrgRoleOwner  = "owner"  // владелец: всё, что может admin, и назначение владельцев
+	OrgRoleAdmin  = "admin"  // управляет участниками своей организации
+	OrgRoleMember = "member" // обычный участник
+)
+
+// Organization - организация (tenant), к которой относятся пользователи
+type Organization struct {
+	ID              int64
+	Slug            string // короткое имя для входа и регистрации (acme, acme-eu)
+	Name            string
+	AllowSelfSignup bool // можно зарегистрироваться в организации без приглашения
+	CreatedAt       time.Time
+}
+
+// OrgMember - участник организации
+type OrgMember struct {
+	UserID   int64
+	Email    string
+	Role     string
+	JoinedAt time.Time
+}
@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// OutboxEvent - доменное событие, ожидающее отправки в шину сообщений
+type OutboxEvent struct {
+	Seq       int64     // Монотонно растущий номер; по нему потребители отбрасывают дубликаты
+	Payload   []byte    // Событие в JSON
+	CreatedAt time.Time // Когда событие записано в outbox
+}
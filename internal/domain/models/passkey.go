@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Церемонии WebAuthn
+const (
+	CeremonyRegistration = "registration" // регистрация новой passkey
+	CeremonyLogin        = "login"        // вход по passkey
+)
+
+// Passkey - учётные данные WebAuthn пользователя (хранится только открытый ключ)
+type Passkey struct {
+	ID              int64
+	UserID          int64
+	CredentialID    []byte // id учётных данных, который присылает аутентификатор
+	PublicKey       []byte // открытый ключ в формате COSE
+	AttestationType string
+	AAGUID          []byte   // модель аутентификатора
+	SignCount       uint32   // последний счётчик подписей (0 - аутентификатор его не ведёт)
+	Transports      []string // как клиент может связаться с аутентификатором: usb, nfc, ble, internal, hybrid
+	BackupEligible  bool     // ключ может синхронизироваться между устройствами (облачная passkey)
+	BackupState     bool     // ключ сейчас синхронизирован
+	Name            string   // имя, которое дал пользователь ("Рабочий ноутбук")
+	CreatedAt       time.Time
+	LastUsedAt      time.Time // нулевое значение - ещё не использовалась для входа
+}
+
+// WebAuthnSession - состояние начатой церемонии WebAuthn между Begin* и Finish*
+type WebAuthnSession struct {
+	ID        string // случайный nonce, который клиент возвращает в Finish*
+	UserID    int64  // для регистрации - кто регистрирует; для входа по email - чей вход (0 - вход без email)
+	Ceremony  string // CeremonyRegistration или CeremonyLogin
+	Data      []byte // состояние библиотеки WebAuthn (JSON): вызов, допустимые учётные данные и т. п.
+	ExpiresAt time.Time
+}
+
+// PasskeyOptions - параметры церемонии для navigator.credentials.create/get и nonce для Finish*
+type PasskeyOptions struct {
+	SessionID   string
+	OptionsJSON []byte // PublicKeyCredentialCreationOptions или PublicKeyCredentialRequestOptions (JSON)
+}
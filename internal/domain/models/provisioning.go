@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// ProvisionedUser - пользователь, которого создала внешняя система (HR) через провижининг.
+// ExternalID - его id во внешней системе; по нему ProvisionUser обновляет, а не создаёт пользователя
+type ProvisionedUser struct {
+	UserID        int64
+	ExternalID    string
+	Email         string
+	Attributes    map[string]string // атрибуты из последнего ProvisionUser (отдел, должность и т. п.)
+	IsActive      bool              // false - пользователь отключён DeprovisionUser
+	ProvisionedAt time.Time         // когда провижининг последний раз менял пользователя
+}
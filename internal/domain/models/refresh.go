@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// RefreshFamily - цепочка refresh-токенов одного входа. Каждое обновление заменяет секрет (старый токен
+// перестаёт действовать) и сдвигает ExpiresAt на скользящее окно, но не дальше AbsoluteExpiresAt:
+// после него нужен новый вход. Клеймы входа (права, организация, способ аутентификации) переносятся
+// в каждый выданный по цепочке токен доступа
+type RefreshFamily struct {
+	ID         string
+	UserID     int64
+	AppID      int
+	SessionID  string // сессия входа (пусто - токены без sid)
+	SecretHash string // SHA-256 секрета текущего токена (hex); сам секрет есть только у клиента
+
+	Scopes   []string
+	OrgID    int64 // организация входа; роль в ней читается заново при каждом обновлении
+	AMR      []string
+	ACR      string
+	AuthTime time.Time
+
+	CreatedAt         time.Time
+	ExpiresAt         time.Time // конец скользящего окна: без обновления до этого момента цепочка истекает
+	AbsoluteExpiresAt time.Time // предел, дальше которого окно не сдвигается
+	RevokedAt         time.Time // нулевое значение - цепочка не отозвана
+}
+
+// Active - цепочка не отозвана и не истекла к моменту now
+func (f RefreshFamily) Active(now time.Time) bool {
+	return f.RevokedAt.IsZero() && now.Before(f.ExpiresAt) && now.Before(f.AbsoluteExpiresAt)
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Виды отзыва (Revocation.Kind)
+const (
+	RevocationSession = "session" // отозвана одна сессия: токены с sid = SessionID
+	RevocationUser    = "user"    // отозваны все токены пользователя (только в приложении AppID, если оно задано)
+	RevocationToken   = "token"   // отозван один токен: jti = TokenID
+)
+
+// Revocation - запись журнала отзывов токенов
+type Revocation struct {
+	Seq       int64 // Номер в журнале (монотонно растёт)
+	Kind      string
+	UserID    int64
+	AppID     int    // 0 - все приложения
+	SessionID string // Только для RevocationSession
+	TokenID   string // Только для RevocationToken
+	RevokedAt time.Time
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+// Шаги самопроверки (SelfTestStep.Name) в порядке выполнения
+const (
+	SelfTestCreateApp      = "create_app"
+	SelfTestRegister       = "register"
+	SelfTestVerifyEmail    = "verify_email"
+	SelfTestLogin          = "login"
+	SelfTestValidate       = "validate"
+	SelfTestRefresh        = "refresh"
+	SelfTestChangePassword = "change_password"
+	SelfTestLogout         = "logout"
+	SelfTestDelete         = "delete"
+	SelfTestCleanup        = "cleanup"
+)
+
+// Результат шага самопроверки (SelfTestStep.Status)
+const (
+	SelfTestPassed  = "passed"
+	SelfTestFailed  = "failed"
+	SelfTestSkipped = "skipped" // шаг не выполнялся: не поддерживается или упал предыдущий шаг
+)
+
+// SelfTestStep - результат одного шага самопроверки
+type SelfTestStep struct {
+	Name     string
+	Status   string
+	Duration time.Duration
+	Err      error // почему шаг упал или пропущен (nil у пройденных)
+}
+
+// SelfTestReport - результат самопроверки
+type SelfTestReport struct {
+	StartedAt time.Time
+	Steps     []SelfTestStep
+}
+
+// Passed - все шаги пройдены. Пропущенный шаг - тоже не успех: эта часть сервиса не проверена,
+// даже если пропуск вызван конфигурацией, а не упавшим шагом
+func (r SelfTestReport) Passed() bool {
+	for _, s := range r.Steps {
+		if s.Status != SelfTestPassed {
+			return false
+		}
+	}
+
+	return true
+}
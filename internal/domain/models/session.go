@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Политики превышения лимита сессий приложения (App.SessionLimitPolicy)
+const (
+	SessionLimitReject      = "reject"       // отказать в новом входе
+	SessionLimitEvictOldest = "evict_oldest" // отозвать самую старую сессию
+)
+
+// Session - сессия входа пользователя в приложение; её id лежит в клейме sid токена.
+// Клеймы входа (права, организация, способ аутентификации) хранятся вместе с ней: по ним вызывающий,
+// найденный по id сессии без токена, получает те же права, что и по токену входа
+type Session struct {
+	ID     string
+	UserID int64
+	AppID  int
+
+	Scopes   []string
+	OrgID    int64 // организация входа; роль в ней читается заново при каждой проверке
+	AMR      []string
+	ACR      string
+	AuthTime time.Time
+
+	CreatedAt  time.Time
+	LastSeenAt time.Time // последняя проверка токена сессии (обновляется не чаще раза в минуту)
+	ExpiresAt  time.Time
+	RevokedAt  time.Time // нулевое значение - сессия не отозвана
+}
+
+// Active - сессия не отозвана и не истекла к моменту now
+func (s Session) Active(now time.Time) bool {
+	return s.RevokedAt.IsZero() && now.Before(s.ExpiresAt)
+}
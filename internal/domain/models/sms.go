@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Назначения вызовов с кодом из SMS
+const (
+	SMSPurposeLogin = "login" // завершение входа по паролю вторым фактором
+	SMSPurposePhone = "phone" // подтверждение нового номера телефона
+)
+
+// SMSChallenge - вызов, который завершается вводом кода из SMS
+type SMSChallenge struct {
+	ID          string // SHA-256 токена вызова (hex); сам токен есть только у клиента
+	Purpose     string // SMSPurposeLogin или SMSPurposePhone
+	UserID      int64
+	Phone       string // номер в E.164, на который уходит код
+	AppID       int    // приложение, для которого выдаётся токен (только для входа)
+	OrgID       int64  // организация входа (0 - без организации)
+	AcceptedTOS string // версия условий использования, принятая на странице входа
+
+	CodeHash      []byte    // хеш отправленного кода (nil - код ещё не отправлен)
+	CodeExpiresAt time.Time // до какого момента действует отправленный код
+	SentAt        time.Time // последняя отправка (нулевое значение - ещё не отправлялся)
+	Attempts      int       // сколько раз проверяли код
+	ExpiresAt     time.Time // срок действия вызова
+}
+
+// MFAChallenge - незавершённый вход или подтверждение телефона, ожидающие кода из SMS
+type MFAChallenge struct {
+	Token     string // секрет вызова для SendSMSCode и VerifySMSCode
+	PhoneHint string // замаскированный номер, на который придёт код (+7********89)
+	ExpiresAt time.Time
+}
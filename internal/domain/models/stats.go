@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Stats - сводка для панели администратора. Счётчики пользователей общие для всего сервиса,
+// остальные считаются по приложению, если оно указано
+type Stats struct {
+	TotalUsers           int64 // Пользователи без удалённых и гостей
+	RegisteredLast24h    int64 // Зарегистрированные за последние сутки (без гостей)
+	RegisteredLast7d     int64
+	RegisteredLast30d    int64
+	LockedUsers          int64 // Деактивированные администратором (is_active = FALSE), без удалённых
+	ActiveSessions       int64 // Не отозванные и не истёкшие сессии
+	LoginsLastHour       int64
+	FailedLoginsLastHour int64
+	GeneratedAt          time.Time // Когда посчитана сводка (ответ может браться из кеша)
+}
+
+// Backlog - состояние очередей и накопившихся записей для метрик (считается при чтении /debug/vars)
+type Backlog struct {
+	PendingInvitations int64 // Не принятые, не отозванные и не истёкшие приглашения
+	LockedUsers        int64 // Деактивированные пользователи (is_active = FALSE), без удалённых
+	ActiveSessions     int64 // Не отозванные и не истёкшие сессии всех приложений
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+// Token - выданный токен доступа и его метаданные (берутся из тех же клеймов, что подписаны в токене)
+type Token struct {
+	AccessToken string
+	ExpiresAt   time.Time
+	Scopes      []string
+	SessionID   string // Сессия входа (клейм sid); пусто, если токен не привязан к сессии
+
+	RefreshToken     string    // Токен для auth.RefreshToken; пусто, если refresh-токены выключены
+	RefreshExpiresAt time.Time // До какого момента RefreshToken нужно обменять (конец скользящего окна)
+
+	TOSReacceptanceRequired bool // Пользователь не принял текущую версию условий использования
+	StepUpRequired          bool // Уровень входа ниже App.MinACR: приложению нужно подтверждение вторым фактором
+
+	DeletionScheduledAt time.Time // Когда будет удалён аккаунт по запросу пользователя (нулевое - удаление не запрошено)
+
+	MFAChallenge *MFAChallenge // Вход не завершён: токена нет, нужен код из SMS (auth.SendSMSCode, auth.VerifySMSCode)
+}
+
+// Состояние срока действия токена (TokenInfo.Validity)
+const (
+	TokenValid       = "valid"
+	TokenExpired     = "expired"
+	TokenNotValidYet = "not_yet_valid"
+)
+
+// Причины отзыва токена (TokenInfo.RevokedReason)
+const (
+	RevokedByToken   = "token"   // отозван сам токен
+	RevokedBySession = "session" // завершена сессия токена
+)
+
+// TokenInfo - разбор токена для администратора: клеймы подписанного токена и его состояние
+type TokenInfo struct {
+	TokenID   string // jti
+	UserID    int64
+	Email     string
+	AppID     int
+	SessionID string
+	Scopes    []string
+	OrgID     int64
+	Guest     bool
+	Offline   bool // offline-токен фонового агента
+	AMR       []string
+	ACR       string
+	Purpose   string // не пусто - токен одного действия
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	AuthTime  time.Time
+
+	Validity      string   // TokenValid, TokenExpired или TokenNotValidYet
+	Revoked       bool     // токен отозван (по jti или вместе с сессией)
+	RevokedReason string   // почему токен отозван
+	Session       *Session // сессия токена (nil - токен без сессии или сессии нет)
+}
+
+// DeniedToken - отдельно отозванный токен
+type DeniedToken struct {
+	TokenID   string // jti
+	UserID    int64
+	AppID     int
+	ExpiresAt time.Time // после этого момента токен недействителен и без записи
+	RevokedAt time.Time
+}
+
+// Уровни аутентификации (клейм acr, уровни AAL из NIST SP 800-63B)
+const (
+	ACRSingleFactor = "aal1" // один фактор (пароль)
+	ACRMultiFactor  = "aal2" // пароль и второй фактор
+)
+
+// Способы аутентификации (клейм amr, RFC 8176)
+const (
+	AMRPassword    = "pwd" // пароль
+	AMRMultiFactor = "mfa" // использовано несколько факторов
+	AMRSMS         = "sms" // код из SMS
+	AMRHardwareKey = "hwk" // ключ, привязанный к устройству (passkey без синхронизации)
+	AMRSoftwareKey = "swk" // ключ, который может покинуть устройство (синхронизируемая passkey)
+)
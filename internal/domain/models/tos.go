@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// TOSAcceptance - принятие пользователем версии условий использования
+type TOSAcceptance struct {
+	UserID     int64
+	Version    string
+	AcceptedAt time.Time
+}
+
+// PendingTOS - пользователь, который не принял текущую версию условий использования
+type PendingTOS struct {
+	UserID     int64
+	Email      string
+	Version    string    // последняя принятая версия (пусто - не принимал никакую)
+	AcceptedAt time.Time // когда принята Version (нулевое значение, если не принимал)
+}
@@ -1,7 +1,22 @@
 package models
 
+import "time"
+
 type User struct {
 	ID       int64
 	Email    string
 	PassHash []byte
+	IsAdmin  bool
+	IsActive bool
+
+	IsSuperadmin  bool // Администратор всех организаций
+	EmailVerified bool // Адрес подтверждён (например, пользователь пришёл по приглашению)
+	IsGuest       bool // Гость без email и пароля (auth.CreateGuest), пока не выполнен UpgradeGuest
+
+	Phone string // Подтверждённый номер в E.164 (пусто - не задан); с ним вход по паролю требует кода из SMS
+
+	PasswordChangedAt   time.Time // Когда пароль меняли последний раз (нулевое значение - неизвестно)
+	ForcePasswordChange bool      // Администратор потребовал сменить пароль при следующем входе
+
+	Version int64 // Растёт при каждом изменении; изменения от администраторов проверяют ожидаемую версию
 }
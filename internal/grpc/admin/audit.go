@@ -0,0 +1,188 @@
+package admin
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"hash"
+	"sso/internal/domain/models"
+	"strconv"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Форматы выгрузки журнала безопасности
+const (
+	AuditFormatCSV   = "csv"
+	AuditFormatJSONL = "jsonl"
+)
+
+const (
+	// auditPageSize - сколько событий читается из БД за раз: память не зависит от размера диапазона
+	auditPageSize = 1000
+	// auditChunkSize - примерный размер куска выгрузки в одном сообщении
+	auditChunkSize = 64 << 10
+)
+
+// auditColumns - столбцы CSV и ключи JSON Lines
+var auditColumns = []string{
+	"id", "occurred_at", "event", "outcome", "user_id", "app_id", "email", "reason", "actor_id", "request_id", "ip",
+}
+
+// AuditExporter - чтение сохранённого журнала безопасности (sqlite.Storage.AuditEvents)
+type AuditExporter interface {
+	AuditEvents(ctx context.Context, afterTime time.Time, afterID int64, to time.Time, limit int) ([]models.AuditRecord, error)
+}
+
+// auditRow - событие в JSON Lines
+type auditRow struct {
+	ID         int64  `json:"id"`
+	OccurredAt string `json:"occurred_at"`
+	Event      string `json:"event"`
+	Outcome    string `json:"outcome"`
+	UserID     int64  `json:"user_id"`
+	AppID      int    `json:"app_id"`
+	Email      string `json:"email"`
+	Reason     string `json:"reason"`
+	ActorID    int64  `json:"actor_id"`
+	RequestID  string `json:"request_id"`
+	IP         string `json:"ip"`
+}
+
+// auditEncoder - пишет события в buf в выбранном формате
+type auditEncoder interface {
+	encode(r models.AuditRecord) error
+}
+
+type csvAuditEncoder struct{ w *csv.Writer }
+
+func (e csvAuditEncoder) encode(r models.AuditRecord) error {
+	err := e.w.Write([]string{
+		strconv.FormatInt(r.ID, 10), formatAuditTime(r.OccurredAt), r.Event, r.Outcome,
+		strconv.FormatInt(r.UserID, 10), strconv.Itoa(r.AppID), r.Email, r.Reason,
+		strconv.FormatInt(r.ActorID, 10), r.RequestID, r.IP,
+	})
+	if err != nil {
+		return err
+	}
+	// Строка сразу попадает в буфер: по его размеру решается, пора ли отправлять кусок
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+type jsonlAuditEncoder struct{ enc *json.Encoder }
+
+func (e jsonlAuditEncoder) encode(r models.AuditRecord) error {
+	return e.enc.Encode(auditRow{
+		ID: r.ID, OccurredAt: formatAuditTime(r.OccurredAt), Event: r.Event, Outcome: r.Outcome,
+		UserID: r.UserID, AppID: r.AppID, Email: r.Email, Reason: r.Reason,
+		ActorID: r.ActorID, RequestID: r.RequestID, IP: r.IP,
+	})
+}
+
+func formatAuditTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// auditStream - куски выгрузки с подсчётом строк и контрольной суммы
+type auditStream struct {
+	stream grpc.ServerStreamingServer[ssov1.ExportAuditEventsResponse]
+	buf    bytes.Buffer
+	sum    hash.Hash
+	rows   int64
+}
+
+// flush - отправляет накопленное одним куском
+func (s *auditStream) flush() error {
+	if s.buf.Len() == 0 {
+		return nil
+	}
+
+	chunk := bytes.Clone(s.buf.Bytes())
+	s.buf.Reset()
+	s.sum.Write(chunk)
+
+	return s.stream.Send(&ssov1.ExportAuditEventsResponse{Event: &ssov1.ExportAuditEventsResponse_Chunk{Chunk: chunk}})
+}
+
+// ExportAuditEvents - выгружает сохранённый журнал безопасности за [from, to), читая БД страницами.
+// Отмена клиентом прерывает выгрузку между страницами и внутри запроса к БД
+func (s *serverAPI) ExportAuditEvents(
+	req *ssov1.ExportAuditEventsRequest,
+	stream grpc.ServerStreamingServer[ssov1.ExportAuditEventsResponse],
+) error {
+	from, to := time.Unix(req.GetFrom(), 0), time.Unix(req.GetTo(), 0)
+	if req.GetTo() <= req.GetFrom() {
+		return status.Error(codes.InvalidArgument, "to must be after from")
+	}
+
+	out := &auditStream{stream: stream, sum: sha256.New()}
+
+	var enc auditEncoder
+	switch req.GetFormat() {
+	case AuditFormatCSV:
+		w := csv.NewWriter(&out.buf)
+		if err := w.Write(auditColumns); err != nil {
+			return status.Error(codes.Internal, "internal error")
+		}
+		w.Flush()
+		enc = csvAuditEncoder{w: w}
+	case AuditFormatJSONL:
+		enc = jsonlAuditEncoder{enc: json.NewEncoder(&out.buf)}
+	default:
+		return status.Errorf(codes.InvalidArgument, "format must be %s or %s", AuditFormatCSV, AuditFormatJSONL)
+	}
+
+	ctx := stream.Context()
+	afterTime, afterID := from, int64(0)
+	for {
+		page, err := s.audit.AuditEvents(ctx, afterTime, afterID, to, auditPageSize)
+		if err != nil {
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return status.FromContextError(err).Err()
+			}
+
+			return status.Error(codes.Internal, "internal error")
+		}
+
+		for _, r := range page {
+			if err := enc.encode(r); err != nil {
+				return status.Error(codes.Internal, "internal error")
+			}
+			out.rows++
+
+			if out.buf.Len() >= auditChunkSize {
+				if err := out.flush(); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(page) < auditPageSize {
+			break
+		}
+		last := page[len(page)-1]
+		afterTime, afterID = last.OccurredAt, last.ID
+
+		if err := ctx.Err(); err != nil {
+			return status.FromContextError(err).Err()
+		}
+	}
+
+	if err := out.flush(); err != nil {
+		return err
+	}
+
+	return stream.Send(&ssov1.ExportAuditEventsResponse{Event: &ssov1.ExportAuditEventsResponse_Trailer{
+		Trailer: &ssov1.ExportAuditEventsTrailer{Rows: out.rows, Sha256: hex.EncodeToString(out.sum.Sum(nil))},
+	}})
+}
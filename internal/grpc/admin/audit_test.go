@@ -0,0 +1,196 @@
+package admin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sso/internal/domain/models"
+	"strings"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memAudit - события по секунде на каждое, начиная с base
+type memAudit struct {
+	records []models.AuditRecord
+	pages   int
+}
+
+func newMemAudit(n int, base time.Time) *memAudit {
+	m := &memAudit{}
+	for i := range n {
+		m.records = append(m.records, models.AuditRecord{
+			ID: int64(i + 1), OccurredAt: base.Add(time.Duration(i) * time.Second),
+			Event: "login.failed", Outcome: "failure", Email: fmt.Sprintf("u%d@b.c", i), Reason: "invalid, password",
+		})
+	}
+
+	return m
+}
+
+func (m *memAudit) AuditEvents(
+	_ context.Context,
+	afterTime time.Time,
+	afterID int64,
+	to time.Time,
+	limit int,
+) ([]models.AuditRecord, error) {
+	m.pages++
+
+	var res []models.AuditRecord
+	for _, r := range m.records {
+		after := r.OccurredAt.After(afterTime) || (r.OccurredAt.Equal(afterTime) && r.ID > afterID)
+		if after && r.OccurredAt.Before(to) && len(res) < limit {
+			res = append(res, r)
+		}
+	}
+
+	return res, nil
+}
+
+// auditExportStream - серверный поток, запоминающий отправленные сообщения
+type auditExportStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent []*ssov1.ExportAuditEventsResponse
+}
+
+func (s *auditExportStream) Context() context.Context { return s.ctx }
+
+func (s *auditExportStream) Send(resp *ssov1.ExportAuditEventsResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+// collect - склеенные куски и trailer
+func (s *auditExportStream) collect(t *testing.T) (string, *ssov1.ExportAuditEventsTrailer) {
+	t.Helper()
+
+	require.NotEmpty(t, s.sent)
+	var b strings.Builder
+	for _, m := range s.sent[:len(s.sent)-1] {
+		require.NotNil(t, m.GetChunk())
+		b.Write(m.GetChunk())
+	}
+	trailer := s.sent[len(s.sent)-1].GetTrailer()
+	require.NotNil(t, trailer)
+
+	return b.String(), trailer
+}
+
+func TestExportAuditEvents_CSV(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	// Больше одной страницы, чтобы проверить курсор
+	events := newMemAudit(2*auditPageSize+10, base)
+	s := &serverAPI{audit: events}
+	stream := &auditExportStream{ctx: context.Background()}
+
+	err := s.ExportAuditEvents(&ssov1.ExportAuditEventsRequest{
+		From: base.Unix(), To: base.Add(time.Hour).Unix(), Format: AuditFormatCSV,
+	}, stream)
+	require.NoError(t, err)
+	assert.Equal(t, 3, events.pages)
+
+	body, trailer := stream.collect(t)
+	assert.EqualValues(t, 2*auditPageSize+10, trailer.GetRows())
+	sum := sha256.Sum256([]byte(body))
+	assert.Equal(t, hex.EncodeToString(sum[:]), trailer.GetSha256())
+
+	rows, err := csv.NewReader(strings.NewReader(body)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 2*auditPageSize+11)
+	assert.Equal(t, auditColumns, rows[0])
+	assert.Equal(t, []string{"1", "2023-11-14T22:13:20Z", "login.failed", "failure", "0", "0", "u0@b.c",
+		"invalid, password", "0", "", ""}, rows[1])
+	assert.Equal(t, fmt.Sprint(2*auditPageSize+10), rows[len(rows)-1][0], "no row is lost or repeated")
+}
+
+func TestExportAuditEvents_JSONLRange(t *testing.T) {
+	base := time.Unix(1_700_000_000, 0)
+	s := &serverAPI{audit: newMemAudit(10, base)}
+	stream := &auditExportStream{ctx: context.Background()}
+
+	err := s.ExportAuditEvents(&ssov1.ExportAuditEventsRequest{
+		From: base.Add(2 * time.Second).Unix(), To: base.Add(5 * time.Second).Unix(), Format: AuditFormatJSONL,
+	}, stream)
+	require.NoError(t, err)
+
+	body, trailer := stream.collect(t)
+	assert.EqualValues(t, 3, trailer.GetRows())
+
+	lines := strings.Split(strings.TrimSpace(body), "\n")
+	require.Len(t, lines, 3)
+	var first map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	assert.EqualValues(t, 3, first["id"])
+	assert.Equal(t, "u2@b.c", first["email"])
+}
+
+func TestExportAuditEvents_Errors(t *testing.T) {
+	s := &serverAPI{audit: newMemAudit(1, time.Unix(0, 0))}
+
+	for name, req := range map[string]*ssov1.ExportAuditEventsRequest{
+		"empty range":    {From: 10, To: 10, Format: AuditFormatCSV},
+		"unknown format": {From: 0, To: 10, Format: "xml"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := s.ExportAuditEvents(req, &auditExportStream{ctx: context.Background()})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	big := &serverAPI{audit: newMemAudit(2*auditPageSize, time.Unix(0, 0))}
+	stream := &auditExportStream{ctx: ctx}
+	err := big.ExportAuditEvents(&ssov1.ExportAuditEventsRequest{From: 0, To: 1 << 30, Format: AuditFormatJSONL}, stream)
+	assert.Equal(t, codes.Canceled, status.Code(err))
+	for _, m := range stream.sent {
+		assert.Nil(t, m.GetTrailer(), "cancelled export has no trailer")
+	}
+}
+
+// stubChain - заранее заданный результат проверки цепочки
+type stubChain struct {
+	report   models.AuditChainReport
+	from, to time.Time
+}
+
+func (s *stubChain) VerifyAuditChain(_ context.Context, from, to time.Time) (models.AuditChainReport, error) {
+	s.from, s.to = from, to
+
+	return s.report, nil
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := (&serverAPI{}).VerifyAuditChain(ctx, &ssov1.VerifyAuditChainRequest{From: 0, To: 10})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	chain := &stubChain{report: models.AuditChainReport{RowsChecked: 7, FirstBrokenID: 5, Reason: "hash_mismatch"}}
+	s := &serverAPI{auditChain: chain}
+
+	_, err = s.VerifyAuditChain(ctx, &ssov1.VerifyAuditChainRequest{From: 10, To: 10})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Разрыв цепочки - не ошибка RPC
+	resp, err := s.VerifyAuditChain(ctx, &ssov1.VerifyAuditChainRequest{From: 10, To: 20})
+	require.NoError(t, err)
+	assert.False(t, resp.GetOk())
+	assert.EqualValues(t, 7, resp.GetRowsChecked())
+	assert.EqualValues(t, 5, resp.GetFirstBrokenId())
+	assert.Equal(t, "hash_mismatch", resp.GetReason())
+	assert.Equal(t, int64(10), chain.from.Unix())
+	assert.Equal(t, int64(20), chain.to.Unix())
+}
@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AuditChainVerifier - проверка цепочки хешей журнала безопасности (auditchain.Verifier)
+type AuditChainVerifier interface {
+	VerifyAuditChain(ctx context.Context, from, to time.Time) (models.AuditChainReport, error)
+}
+
+// VerifyAuditChain - проверяет цепочку хешей событий за [from, to). Разрыв - не ошибка, а ok = false
+func (s *serverAPI) VerifyAuditChain(
+	ctx context.Context,
+	req *ssov1.VerifyAuditChainRequest,
+) (*ssov1.VerifyAuditChainResponse, error) {
+	if s.auditChain == nil {
+		return nil, status.Error(codes.FailedPrecondition, "audit chain is disabled: audit.chain is not configured")
+	}
+	if req.GetTo() <= req.GetFrom() {
+		return nil, status.Error(codes.InvalidArgument, "to must be after from")
+	}
+
+	report, err := s.auditChain.VerifyAuditChain(ctx, time.Unix(req.GetFrom(), 0), time.Unix(req.GetTo(), 0))
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.FromContextError(err).Err()
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.VerifyAuditChainResponse{
+		Ok:            report.OK(),
+		RowsChecked:   int64(report.RowsChecked),
+		Unchained:     int64(report.Unchained),
+		FirstBrokenId: report.FirstBrokenID,
+		Reason:        report.Reason,
+		HeadVerified:  report.HeadVerified,
+	}, nil
+}
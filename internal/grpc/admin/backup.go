@@ -0,0 +1,91 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/storage"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Backuper - онлайн-копия хранилища
+type Backuper interface {
+	// Backup - согласованная проверенная копия в файл path (os.ErrExist, если он уже есть);
+	// progress вызывается по ходу копирования
+	Backup(ctx context.Context, path string, progress func(copied, total int)) error
+}
+
+// backupNameLayout - имя копии по умолчанию
+const backupNameLayout = "sso-20060102T150405Z.db"
+
+// Backup - копирует БД в backupDir, сообщая прогресс. Имя файла не может указывать за пределы каталога
+func (s *serverAPI) Backup(req *ssov1.BackupRequest, stream grpc.ServerStreamingServer[ssov1.BackupResponse]) error {
+	if s.backupDir == "" {
+		return status.Error(codes.FailedPrecondition, "backups are disabled: backup_dir is not configured")
+	}
+
+	name := req.GetName()
+	if name == "" {
+		name = time.Now().UTC().Format(backupNameLayout)
+	}
+	if !validBackupName(name) {
+		return status.Error(codes.InvalidArgument, "name must be a plain file name inside the backup directory")
+	}
+
+	start := time.Now()
+
+	// Клиент, который не принимает прогресс, отменяет копирование
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	var sendErr error
+	err := s.backups.Backup(ctx, filepath.Join(s.backupDir, name), func(copied, total int) {
+		if sendErr != nil {
+			return
+		}
+		sendErr = stream.Send(&ssov1.BackupResponse{Event: &ssov1.BackupResponse_Progress{
+			Progress: &ssov1.BackupProgress{PagesCopied: int64(copied), PagesTotal: int64(total)},
+		}})
+		if sendErr != nil {
+			cancel()
+		}
+	})
+	if sendErr != nil {
+		return sendErr
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, os.ErrExist):
+			return status.Error(codes.AlreadyExists, "backup with this name already exists")
+		case errors.Is(err, storage.ErrCorrupt):
+			return errinfo.FromService(err, codes.DataLoss, "backup failed integrity check")
+		case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+			return status.FromContextError(err).Err()
+		default:
+			return status.Error(codes.Internal, "backup failed")
+		}
+	}
+
+	info, err := os.Stat(filepath.Join(s.backupDir, name))
+	if err != nil {
+		return status.Error(codes.Internal, "backup failed")
+	}
+
+	return stream.Send(&ssov1.BackupResponse{Event: &ssov1.BackupResponse_Result{Result: &ssov1.BackupResult{
+		Name:       name,
+		SizeBytes:  info.Size(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}}})
+}
+
+// validBackupName - имя файла без каталогов, "..", абсолютных путей и скрытых файлов
+func validBackupName(name string) bool {
+	return filepath.IsLocal(name) && filepath.Base(name) == name && name[0] != '.'
+}
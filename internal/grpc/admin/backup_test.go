@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeBackuper - пишет в path фиксированное содержимое в три шага
+type fakeBackuper struct {
+	paths []string
+}
+
+func (f *fakeBackuper) Backup(_ context.Context, path string, progress func(copied, total int)) error {
+	f.paths = append(f.paths, path)
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("storage.fake.Backup: %w", os.ErrExist)
+	}
+	for i := 1; i <= 3; i++ {
+		progress(i, 3)
+	}
+
+	return os.WriteFile(path, []byte("backup"), 0o600)
+}
+
+// backupStream - серверный поток, запоминающий отправленные сообщения
+type backupStream struct {
+	grpc.ServerStream
+	sent []*ssov1.BackupResponse
+}
+
+func (s *backupStream) Context() context.Context { return context.Background() }
+
+func (s *backupStream) Send(resp *ssov1.BackupResponse) error {
+	s.sent = append(s.sent, resp)
+	return nil
+}
+
+func TestBackup(t *testing.T) {
+	dir := t.TempDir()
+	backups := &fakeBackuper{}
+	s := &serverAPI{backups: backups, backupDir: dir}
+
+	stream := &backupStream{}
+	require.NoError(t, s.Backup(&ssov1.BackupRequest{Name: "nightly.db"}, stream))
+
+	require.Len(t, stream.sent, 4)
+	for i, resp := range stream.sent[:3] {
+		assert.Equal(t, int64(i+1), resp.GetProgress().GetPagesCopied())
+		assert.Equal(t, int64(3), resp.GetProgress().GetPagesTotal())
+	}
+	result := stream.sent[3].GetResult()
+	require.NotNil(t, result)
+	assert.Equal(t, "nightly.db", result.GetName())
+	assert.Equal(t, int64(len("backup")), result.GetSizeBytes())
+	assert.Equal(t, []string{filepath.Join(dir, "nightly.db")}, backups.paths)
+
+	// Повтор с тем же именем не перезаписывает копию
+	err := s.Backup(&ssov1.BackupRequest{Name: "nightly.db"}, &backupStream{})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	// Имя по умолчанию
+	stream = &backupStream{}
+	require.NoError(t, s.Backup(&ssov1.BackupRequest{}, stream))
+	assert.Regexp(t, `^sso-\d{8}T\d{6}Z\.db$`, stream.sent[len(stream.sent)-1].GetResult().GetName())
+}
+
+func TestBackup_RefusesPathsOutsideDir(t *testing.T) {
+	backups := &fakeBackuper{}
+	s := &serverAPI{backups: backups, backupDir: t.TempDir()}
+
+	for _, name := range []string{"../sso.db", "/etc/passwd", "sub/sso.db", "..", ".", ".hidden"} {
+		err := s.Backup(&ssov1.BackupRequest{Name: name}, &backupStream{})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), name)
+	}
+	assert.Empty(t, backups.paths)
+}
+
+func TestBackup_Disabled(t *testing.T) {
+	s := &serverAPI{backups: &fakeBackuper{}}
+
+	err := s.Backup(&ssov1.BackupRequest{Name: "sso.db"}, &backupStream{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
@@ -0,0 +1,145 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/storage"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// GroupAdmin - управление группами пользователей
+type GroupAdmin interface {
+	// CreateGroup - создаёт группу (storage.ErrGroupExists, если имя занято)
+	CreateGroup(ctx context.Context, name string) (int64, error)
+
+	// DeleteGroup - удаляет группу вместе с членством (storage.ErrGroupNotFound, если её нет)
+	DeleteGroup(ctx context.Context, groupID int64) error
+
+	// AddToGroup - добавляет пользователя в группу
+	AddToGroup(ctx context.Context, groupID, userID int64) error
+
+	// RemoveFromGroup - исключает пользователя из группы (storage.ErrNotInGroup, если он не участник)
+	RemoveFromGroup(ctx context.Context, groupID, userID int64) error
+
+	// ListGroupMembers - участники группы (storage.ErrGroupNotFound, если её нет)
+	ListGroupMembers(ctx context.Context, groupID int64) ([]models.GroupMember, error)
+}
+
+// groupNameRe - допустимое имя группы: оно попадает в токены как есть
+var groupNameRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+func (s *serverAPI) CreateGroup(ctx context.Context, req *ssov1.CreateGroupRequest) (*ssov1.CreateGroupResponse, error) {
+	if !groupNameRe.MatchString(req.GetName()) {
+		return nil, status.Error(codes.InvalidArgument, "name must be 1-64 characters of a-z, 0-9, '.', '_' and '-'")
+	}
+
+	id, err := s.groups.CreateGroup(ctx, req.GetName())
+	if err != nil {
+		if errors.Is(err, storage.ErrGroupExists) {
+			return nil, errinfo.FromService(err, codes.AlreadyExists, "group already exists")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.CreateGroupResponse{GroupId: id}, nil
+}
+
+func (s *serverAPI) DeleteGroup(ctx context.Context, req *ssov1.DeleteGroupRequest) (*ssov1.DeleteGroupResponse, error) {
+	if req.GetGroupId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+
+	if err := s.groups.DeleteGroup(ctx, req.GetGroupId()); err != nil {
+		return nil, groupError(err)
+	}
+
+	return &ssov1.DeleteGroupResponse{}, nil
+}
+
+func (s *serverAPI) AddToGroup(ctx context.Context, req *ssov1.AddToGroupRequest) (*ssov1.AddToGroupResponse, error) {
+	if req.GetGroupId() == 0 || req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "group_id and user_id are required")
+	}
+
+	if err := s.groups.AddToGroup(ctx, req.GetGroupId(), req.GetUserId()); err != nil {
+		return nil, groupError(err)
+	}
+
+	return &ssov1.AddToGroupResponse{}, nil
+}
+
+func (s *serverAPI) RemoveFromGroup(
+	ctx context.Context,
+	req *ssov1.RemoveFromGroupRequest,
+) (*ssov1.RemoveFromGroupResponse, error) {
+	if req.GetGroupId() == 0 || req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "group_id and user_id are required")
+	}
+
+	if err := s.groups.RemoveFromGroup(ctx, req.GetGroupId(), req.GetUserId()); err != nil {
+		return nil, groupError(err)
+	}
+
+	return &ssov1.RemoveFromGroupResponse{}, nil
+}
+
+func (s *serverAPI) ListGroupMembers(
+	ctx context.Context,
+	req *ssov1.ListGroupMembersRequest,
+) (*ssov1.ListGroupMembersResponse, error) {
+	if req.GetGroupId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "group_id is required")
+	}
+
+	members, err := s.groups.ListGroupMembers(ctx, req.GetGroupId())
+	if err != nil {
+		return nil, groupError(err)
+	}
+
+	resp := &ssov1.ListGroupMembersResponse{Members: make([]*ssov1.GroupMember, 0, len(members))}
+	for _, m := range members {
+		resp.Members = append(resp.Members, &ssov1.GroupMember{UserId: m.UserID, Email: m.Email})
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) SetAppGroupsClaim(
+	ctx context.Context,
+	req *ssov1.SetAppGroupsClaimRequest,
+) (*ssov1.SetAppGroupsClaimResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	if err := s.apps.SetAppGroupsClaim(ctx, int(req.GetAppId()), req.GetEnabled()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppGroupsClaimResponse{}, nil
+}
+
+// groupError - статус ошибки изменения группы
+func groupError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrGroupNotFound):
+		return errinfo.FromService(err, codes.NotFound, "group not found")
+	case errors.Is(err, storage.ErrUserNotFound):
+		return errinfo.FromService(err, codes.NotFound, "user not found")
+	case errors.Is(err, storage.ErrNotInGroup):
+		return errinfo.FromService(err, codes.NotFound, "user is not a member of this group")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,46 @@
+package admin
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// stubGroups - группы, в которых никто не состоит
+type stubGroups struct {
+	GroupAdmin
+}
+
+func (stubGroups) CreateGroup(context.Context, string) (int64, error) {
+	return 7, nil
+}
+
+func (stubGroups) RemoveFromGroup(context.Context, int64, int64) error {
+	return storage.ErrNotInGroup
+}
+
+func TestCreateGroup_Validation(t *testing.T) {
+	s := &serverAPI{groups: stubGroups{}}
+
+	for _, name := range []string{"", "Engineering", "-eng", "eng team", "eng/ops"} {
+		_, err := s.CreateGroup(context.Background(), &ssov1.CreateGroupRequest{Name: name})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "name %q", name)
+	}
+
+	resp, err := s.CreateGroup(context.Background(), &ssov1.CreateGroupRequest{Name: "eng.backend_ops-1"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), resp.GetGroupId())
+}
+
+func TestRemoveFromGroup_NotMember(t *testing.T) {
+	s := &serverAPI{groups: stubGroups{}}
+
+	_, err := s.RemoveFromGroup(context.Background(), &ssov1.RemoveFromGroupRequest{GroupId: 1, UserId: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
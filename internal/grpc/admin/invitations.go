@@ -0,0 +1,139 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+	"strconv"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Inviter - приглашения пользователей (сервисный слой: нужны письма и токены)
+type Inviter interface {
+	// InviteUser - создаёт или продлевает приглашение и отправляет письмо
+	InviteUser(ctx context.Context, email string, appID int, role string) (models.Invitation, error)
+
+	// ListInvitations - до limit приглашений с id больше afterID по возрастанию id
+	ListInvitations(ctx context.Context, afterID int64, limit int, pendingOnly bool) ([]models.Invitation, error)
+
+	// RevokeInvitation - отзывает ожидающее приглашение
+	RevokeInvitation(ctx context.Context, invitationID int64) error
+}
+
+func (s *serverAPI) InviteUser(ctx context.Context, req *ssov1.InviteUserRequest) (*ssov1.InviteUserResponse, error) {
+	if req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	role := req.GetRole()
+	if role == "" {
+		role = models.RoleUser
+	}
+
+	inv, err := s.invites.InviteUser(ctx, req.GetEmail(), int(req.GetAppId()), role)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidRole):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "role must be user or admin")
+		case errors.Is(err, auth.ErrInvalidAppID):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+		case errors.Is(err, auth.ErrUserExists):
+			return nil, errinfo.FromService(err, codes.AlreadyExists, "user already exists")
+		}
+
+		return nil, invitationError(err)
+	}
+
+	return &ssov1.InviteUserResponse{Invitation: toInvitation(inv, time.Now())}, nil
+}
+
+func (s *serverAPI) ListInvitations(
+	ctx context.Context,
+	req *ssov1.ListInvitationsRequest,
+) (*ssov1.ListInvitationsResponse, error) {
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be in range 0-%d", maxPageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+
+	var afterID int64
+	if token := req.GetPageToken(); token != "" {
+		id, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || id < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		afterID = id
+	}
+
+	invitations, err := s.invites.ListInvitations(ctx, afterID, pageSize, req.GetPendingOnly())
+	if err != nil {
+		return nil, invitationError(err)
+	}
+
+	now := time.Now()
+	resp := &ssov1.ListInvitationsResponse{Invitations: make([]*ssov1.Invitation, 0, len(invitations))}
+	for _, inv := range invitations {
+		resp.Invitations = append(resp.Invitations, toInvitation(inv, now))
+	}
+
+	if len(invitations) == pageSize {
+		resp.NextPageToken = strconv.FormatInt(invitations[len(invitations)-1].ID, 10)
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) RevokeInvitation(
+	ctx context.Context,
+	req *ssov1.RevokeInvitationRequest,
+) (*ssov1.RevokeInvitationResponse, error) {
+	if req.GetInvitationId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "invitation_id is required")
+	}
+
+	if err := s.invites.RevokeInvitation(ctx, req.GetInvitationId()); err != nil {
+		return nil, invitationError(err)
+	}
+
+	return &ssov1.RevokeInvitationResponse{}, nil
+}
+
+// invitationError - статус общих ошибок приглашений
+func invitationError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvitationNotFound):
+		return errinfo.FromService(err, codes.NotFound, "invitation not found")
+	case errors.Is(err, auth.ErrInvitationUsed):
+		return errinfo.FromService(err, codes.FailedPrecondition, "invitation is already accepted or revoked")
+	case errors.Is(err, auth.ErrInvitationsDisabled):
+		return errinfo.FromService(err, codes.FailedPrecondition, "invitations are disabled")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func toInvitation(inv models.Invitation, now time.Time) *ssov1.Invitation {
+	return &ssov1.Invitation{
+		Id:        inv.ID,
+		Email:     inv.Email,
+		AppId:     int32(inv.AppID),
+		Role:      inv.Role,
+		InvitedBy: inv.InvitedBy,
+		CreatedAt: inv.CreatedAt.Unix(),
+		ExpiresAt: inv.ExpiresAt.Unix(),
+		Status:    inv.Status(now),
+		UserId:    inv.UserID,
+	}
+}
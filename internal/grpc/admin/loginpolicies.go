@@ -0,0 +1,183 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/clientip"
+	"sso/internal/storage"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxPolicyNetworks - сколько сетей можно задать одной политике входа
+const maxPolicyNetworks = 100
+
+// policyTimeLayout - формат начала и конца окна политики входа
+const policyTimeLayout = "15:04"
+
+func (s *serverAPI) SetAppLoginPolicy(
+	ctx context.Context,
+	req *ssov1.SetAppLoginPolicyRequest,
+) (*ssov1.SetAppLoginPolicyResponse, error) {
+	if req.GetAppId() == 0 || req.GetPolicy().GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "app_id and policy.name are required")
+	}
+
+	p, err := loginPolicyFromProto(req.GetPolicy())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.apps.SetAppLoginPolicy(ctx, int(req.GetAppId()), p); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppLoginPolicyResponse{}, nil
+}
+
+func (s *serverAPI) DeleteAppLoginPolicy(
+	ctx context.Context,
+	req *ssov1.DeleteAppLoginPolicyRequest,
+) (*ssov1.DeleteAppLoginPolicyResponse, error) {
+	if req.GetAppId() == 0 || req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "app_id and name are required")
+	}
+
+	if err := s.apps.DeleteAppLoginPolicy(ctx, int(req.GetAppId()), req.GetName()); err != nil {
+		if errors.Is(err, storage.ErrLoginPolicyNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "login policy not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.DeleteAppLoginPolicyResponse{}, nil
+}
+
+func (s *serverAPI) ListAppLoginPolicies(
+	ctx context.Context,
+	req *ssov1.ListAppLoginPoliciesRequest,
+) (*ssov1.ListAppLoginPoliciesResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	app, err := s.apps.App(ctx, int(req.GetAppId()))
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &ssov1.ListAppLoginPoliciesResponse{RecheckNetwork: app.RecheckNetworkPolicy}
+	for _, p := range app.LoginPolicies {
+		resp.Policies = append(resp.Policies, loginPolicyToProto(p))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) SetAppNetworkPolicyRecheck(
+	ctx context.Context,
+	req *ssov1.SetAppNetworkPolicyRecheckRequest,
+) (*ssov1.SetAppNetworkPolicyRecheckResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	if err := s.apps.SetAppRecheckNetworkPolicy(ctx, int(req.GetAppId()), req.GetRecheck()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppNetworkPolicyRecheckResponse{}, nil
+}
+
+// loginPolicyFromProto - политика входа из запроса; ошибка описывает неверное поле для клиента
+func loginPolicyFromProto(in *ssov1.LoginPolicy) (models.LoginPolicy, error) {
+	p := models.LoginPolicy{Name: in.GetName()}
+
+	if len(in.GetNetworks()) > maxPolicyNetworks {
+		return models.LoginPolicy{}, fmt.Errorf("at most %d networks are allowed", maxPolicyNetworks)
+	}
+	networks, err := clientip.ParsePrefixes(in.GetNetworks())
+	if err != nil {
+		return models.LoginPolicy{}, fmt.Errorf("networks must be CIDR prefixes: %v", err)
+	}
+	if len(networks) > 0 {
+		p.Networks = networks
+	}
+
+	for _, d := range in.GetWeekdays() {
+		if d < int32(time.Sunday) || d > int32(time.Saturday) {
+			return models.LoginPolicy{}, fmt.Errorf("weekdays must be between 0 (Sunday) and 6 (Saturday), got %d", d)
+		}
+		p.Weekdays = append(p.Weekdays, time.Weekday(d))
+	}
+
+	// Окно задаётся двумя границами или не задаётся вовсе
+	if (in.GetStartTime() == "") != (in.GetEndTime() == "") {
+		return models.LoginPolicy{}, errors.New("start_time and end_time must be set together")
+	}
+	if in.GetStartTime() != "" {
+		if p.StartMinute, err = parsePolicyTime(in.GetStartTime()); err != nil {
+			return models.LoginPolicy{}, fmt.Errorf("start_time: %v", err)
+		}
+		if p.EndMinute, err = parsePolicyTime(in.GetEndTime()); err != nil {
+			return models.LoginPolicy{}, fmt.Errorf("end_time: %v", err)
+		}
+	}
+
+	p.Location = time.UTC
+	if in.GetTimezone() != "" {
+		if p.Location, err = time.LoadLocation(in.GetTimezone()); err != nil {
+			return models.LoginPolicy{}, fmt.Errorf("unknown timezone %q", in.GetTimezone())
+		}
+	}
+
+	return p, nil
+}
+
+// parsePolicyTime - минуты от полуночи для "ЧЧ:ММ"
+func parsePolicyTime(s string) (int, error) {
+	t, err := time.Parse(policyTimeLayout, s)
+	if err != nil {
+		return 0, fmt.Errorf("must be HH:MM, got %q", s)
+	}
+
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func loginPolicyToProto(p models.LoginPolicy) *ssov1.LoginPolicy {
+	out := &ssov1.LoginPolicy{Name: p.Name, Timezone: time.UTC.String()}
+	for _, n := range p.Networks {
+		out.Networks = append(out.Networks, n.String())
+	}
+	for _, d := range p.Weekdays {
+		out.Weekdays = append(out.Weekdays, int32(d))
+	}
+	if p.StartMinute != p.EndMinute {
+		out.StartTime = fmt.Sprintf("%02d:%02d", p.StartMinute/60, p.StartMinute%60)
+		out.EndTime = fmt.Sprintf("%02d:%02d", p.EndMinute/60, p.EndMinute%60)
+	}
+	if p.Location != nil {
+		out.Timezone = p.Location.String()
+	}
+
+	return out
+}
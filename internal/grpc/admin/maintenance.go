@@ -0,0 +1,36 @@
+package admin
+
+import (
+	"context"
+	"log/slog"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/maintenance"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+)
+
+// MaintenanceSwitch - режим обслуживания (maintenance.Mode)
+type MaintenanceSwitch interface {
+	// Set - включает или выключает режим; false - режим уже был в этом состоянии
+	Set(enabled bool, reason string) bool
+	State() maintenance.State
+}
+
+func (s *serverAPI) SetMaintenance(ctx context.Context, req *ssov1.SetMaintenanceRequest) (*ssov1.SetMaintenanceResponse, error) {
+	if s.maintenance.Set(req.GetEnabled(), req.GetReason()) {
+		principal, _ := authctx.From(ctx)
+		logctx.FromOr(ctx, slog.Default()).Warn("maintenance mode changed",
+			slog.Bool("enabled", req.GetEnabled()),
+			slog.String("reason", req.GetReason()),
+			slog.Int64("actor_uid", principal.UserID))
+	}
+
+	state := s.maintenance.State()
+	resp := &ssov1.SetMaintenanceResponse{Enabled: state.Enabled, Reason: state.Reason}
+	if state.Enabled {
+		resp.Since = state.Since.Unix()
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,190 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/storage"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// OrgAdmin - управление организациями и их участниками
+type OrgAdmin interface {
+	// CreateOrganization - создаёт организацию (storage.ErrOrgExists, если slug занят)
+	CreateOrganization(ctx context.Context, slug, name string, allowSelfSignup bool) (int64, error)
+
+	// MemberRole - роль пользователя в организации (storage.ErrNotMember, если он не участник)
+	MemberRole(ctx context.Context, orgID, userID int64) (string, error)
+
+	// AddMember - добавляет участника или меняет его роль
+	AddMember(ctx context.Context, orgID, userID int64, role string) error
+
+	// RemoveMember - исключает участника (storage.ErrNotMember, если он не участник)
+	RemoveMember(ctx context.Context, orgID, userID int64) error
+
+	// ListMembers - участники организации (storage.ErrOrgNotFound, если её нет)
+	ListMembers(ctx context.Context, orgID int64) ([]models.OrgMember, error)
+}
+
+// slugRe - допустимый slug организации
+var slugRe = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,62}$`)
+
+// Методы организаций доступны любому аутентифицированному вызывающему (см. accessPolicy),
+// поэтому права проверяются здесь: суперадминистратор управляет всеми организациями,
+// owner и admin - только той, в которую выполнен вход
+
+func (s *serverAPI) CreateOrganization(
+	ctx context.Context,
+	req *ssov1.CreateOrganizationRequest,
+) (*ssov1.CreateOrganizationResponse, error) {
+	if p, _ := authctx.From(ctx); !p.IsSuperadmin {
+		return nil, status.Error(codes.PermissionDenied, "only a superadmin can create organizations")
+	}
+
+	if !slugRe.MatchString(req.GetSlug()) {
+		return nil, status.Error(codes.InvalidArgument, "slug must be 2-63 characters of a-z, 0-9 and '-'")
+	}
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	id, err := s.orgs.CreateOrganization(ctx, req.GetSlug(), req.GetName(), req.GetAllowSelfSignup())
+	if err != nil {
+		if errors.Is(err, storage.ErrOrgExists) {
+			return nil, errinfo.FromService(err, codes.AlreadyExists, "organization already exists")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.CreateOrganizationResponse{OrgId: id}, nil
+}
+
+func (s *serverAPI) AddMember(ctx context.Context, req *ssov1.AddMemberRequest) (*ssov1.AddMemberResponse, error) {
+	if req.GetOrgId() == 0 || req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "org_id and user_id are required")
+	}
+
+	role := req.GetRole()
+	switch role {
+	case models.OrgRoleOwner, models.OrgRoleAdmin, models.OrgRoleMember:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "role must be owner, admin or member")
+	}
+
+	if err := s.checkOrgChange(ctx, req.GetOrgId(), req.GetUserId(), role); err != nil {
+		return nil, err
+	}
+
+	if err := s.orgs.AddMember(ctx, req.GetOrgId(), req.GetUserId(), role); err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.AddMemberResponse{}, nil
+}
+
+func (s *serverAPI) RemoveMember(ctx context.Context, req *ssov1.RemoveMemberRequest) (*ssov1.RemoveMemberResponse, error) {
+	if req.GetOrgId() == 0 || req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "org_id and user_id are required")
+	}
+
+	if err := s.checkOrgChange(ctx, req.GetOrgId(), req.GetUserId(), ""); err != nil {
+		return nil, err
+	}
+
+	if err := s.orgs.RemoveMember(ctx, req.GetOrgId(), req.GetUserId()); err != nil {
+		return nil, orgError(err)
+	}
+
+	return &ssov1.RemoveMemberResponse{}, nil
+}
+
+func (s *serverAPI) ListMembers(ctx context.Context, req *ssov1.ListMembersRequest) (*ssov1.ListMembersResponse, error) {
+	if req.GetOrgId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "org_id is required")
+	}
+
+	if _, ok := canManageOrg(ctx, req.GetOrgId()); !ok {
+		return nil, status.Error(codes.PermissionDenied, "not an administrator of this organization")
+	}
+
+	members, err := s.orgs.ListMembers(ctx, req.GetOrgId())
+	if err != nil {
+		return nil, orgError(err)
+	}
+
+	resp := &ssov1.ListMembersResponse{Members: make([]*ssov1.OrgMember, 0, len(members))}
+	for _, m := range members {
+		resp.Members = append(resp.Members, &ssov1.OrgMember{UserId: m.UserID, Email: m.Email, Role: m.Role})
+	}
+
+	return resp, nil
+}
+
+// canManageOrg - может ли вызывающий управлять участниками orgID; owner - является ли он владельцем
+// (суперадминистратор приравнивается к владельцу)
+func canManageOrg(ctx context.Context, orgID int64) (owner bool, ok bool) {
+	p, _ := authctx.From(ctx)
+	if p.IsSuperadmin {
+		return true, true
+	}
+
+	if p.OrgID != orgID {
+		return false, false
+	}
+
+	switch p.OrgRole {
+	case models.OrgRoleOwner:
+		return true, true
+	case models.OrgRoleAdmin:
+		return false, true
+	default:
+		return false, false
+	}
+}
+
+// checkOrgChange - может ли вызывающий назначить userID роль newRole (пусто - исключить его).
+// Назначать, снимать и исключать владельцев может только владелец
+func (s *serverAPI) checkOrgChange(ctx context.Context, orgID, userID int64, newRole string) error {
+	owner, ok := canManageOrg(ctx, orgID)
+	if !ok {
+		return status.Error(codes.PermissionDenied, "not an administrator of this organization")
+	}
+	if owner {
+		return nil
+	}
+
+	if newRole == models.OrgRoleOwner {
+		return status.Error(codes.PermissionDenied, "only an owner can assign the owner role")
+	}
+
+	current, err := s.orgs.MemberRole(ctx, orgID, userID)
+	if err != nil && !errors.Is(err, storage.ErrNotMember) {
+		return errinfo.FromService(err, codes.Internal, "internal error")
+	}
+	if current == models.OrgRoleOwner {
+		return status.Error(codes.PermissionDenied, "only an owner can change another owner")
+	}
+
+	return nil
+}
+
+// orgError - статус ошибки изменения организации
+func orgError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrOrgNotFound):
+		return errinfo.FromService(err, codes.NotFound, "organization not found")
+	case errors.Is(err, storage.ErrUserNotFound):
+		return errinfo.FromService(err, codes.NotFound, "user not found")
+	case errors.Is(err, storage.ErrNotMember):
+		return errinfo.FromService(err, codes.NotFound, "user is not a member of this organization")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
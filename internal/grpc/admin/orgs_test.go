@@ -0,0 +1,164 @@
+package admin
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/authctx"
+	"sso/internal/storage"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memOrgs - участники организаций в памяти: orgID -> userID -> роль
+type memOrgs struct {
+	OrgAdmin
+	members map[int64]map[int64]string
+}
+
+func (m *memOrgs) MemberRole(_ context.Context, orgID, userID int64) (string, error) {
+	role, ok := m.members[orgID][userID]
+	if !ok {
+		return "", storage.ErrNotMember
+	}
+
+	return role, nil
+}
+
+func (m *memOrgs) AddMember(_ context.Context, orgID, userID int64, role string) error {
+	if m.members[orgID] == nil {
+		return storage.ErrOrgNotFound
+	}
+	m.members[orgID][userID] = role
+
+	return nil
+}
+
+func (m *memOrgs) RemoveMember(_ context.Context, orgID, userID int64) error {
+	if _, ok := m.members[orgID][userID]; !ok {
+		return storage.ErrNotMember
+	}
+	delete(m.members[orgID], userID)
+
+	return nil
+}
+
+func (m *memOrgs) CreateOrganization(context.Context, string, string, bool) (int64, error) {
+	return 99, nil
+}
+
+func TestOrgs_Authorization(t *testing.T) {
+	const (
+		org   = 1
+		other = 2
+		owner = 10 // владелец org
+		admin = 11 // администратор org
+		user  = 12 // обычный участник org
+	)
+
+	callers := map[string]authctx.Principal{
+		"superadmin":      {UserID: 1, IsSuperadmin: true},
+		"owner":           {UserID: owner, OrgID: org, OrgRole: models.OrgRoleOwner},
+		"admin":           {UserID: admin, OrgID: org, OrgRole: models.OrgRoleAdmin},
+		"member":          {UserID: user, OrgID: org, OrgRole: models.OrgRoleMember},
+		"admin elsewhere": {UserID: 20, OrgID: other, OrgRole: models.OrgRoleAdmin},
+		"global admin":    {UserID: 30, IsAdmin: true},
+	}
+
+	tests := []struct {
+		name    string
+		call    func(ctx context.Context, s *serverAPI) error
+		allowed []string // кому разрешено; остальные получают PermissionDenied
+	}{
+		{
+			name: "create organization",
+			call: func(ctx context.Context, s *serverAPI) error {
+				_, err := s.CreateOrganization(ctx, &ssov1.CreateOrganizationRequest{Slug: "acme", Name: "Acme"})
+				return err
+			},
+			allowed: []string{"superadmin"},
+		},
+		{
+			name: "add member",
+			call: func(ctx context.Context, s *serverAPI) error {
+				_, err := s.AddMember(ctx, &ssov1.AddMemberRequest{OrgId: org, UserId: 40, Role: models.OrgRoleMember})
+				return err
+			},
+			allowed: []string{"superadmin", "owner", "admin"},
+		},
+		{
+			name: "assign owner",
+			call: func(ctx context.Context, s *serverAPI) error {
+				_, err := s.AddMember(ctx, &ssov1.AddMemberRequest{OrgId: org, UserId: user, Role: models.OrgRoleOwner})
+				return err
+			},
+			allowed: []string{"superadmin", "owner"},
+		},
+		{
+			name: "demote owner",
+			call: func(ctx context.Context, s *serverAPI) error {
+				_, err := s.AddMember(ctx, &ssov1.AddMemberRequest{OrgId: org, UserId: owner, Role: models.OrgRoleMember})
+				return err
+			},
+			allowed: []string{"superadmin", "owner"},
+		},
+		{
+			name: "remove member",
+			call: func(ctx context.Context, s *serverAPI) error {
+				_, err := s.RemoveMember(ctx, &ssov1.RemoveMemberRequest{OrgId: org, UserId: user})
+				return err
+			},
+			allowed: []string{"superadmin", "owner", "admin"},
+		},
+		{
+			name: "remove owner",
+			call: func(ctx context.Context, s *serverAPI) error {
+				_, err := s.RemoveMember(ctx, &ssov1.RemoveMemberRequest{OrgId: org, UserId: owner})
+				return err
+			},
+			allowed: []string{"superadmin", "owner"},
+		},
+	}
+
+	for _, tt := range tests {
+		for name, p := range callers {
+			t.Run(tt.name+"/"+name, func(t *testing.T) {
+				orgs := &memOrgs{members: map[int64]map[int64]string{
+					org:   {owner: models.OrgRoleOwner, admin: models.OrgRoleAdmin, user: models.OrgRoleMember},
+					other: {},
+				}}
+				s := &serverAPI{orgs: orgs}
+
+				err := tt.call(authctx.Into(context.Background(), p), s)
+
+				allowed := false
+				for _, a := range tt.allowed {
+					allowed = allowed || a == name
+				}
+				if allowed {
+					require.NoError(t, err)
+				} else {
+					assert.Equal(t, codes.PermissionDenied, status.Code(err), err)
+				}
+			})
+		}
+	}
+}
+
+func TestCreateOrganization_Validation(t *testing.T) {
+	s := &serverAPI{orgs: &memOrgs{}}
+	ctx := authctx.Into(context.Background(), authctx.Principal{UserID: 1, IsSuperadmin: true})
+
+	for _, slug := range []string{"", "a", "Acme", "-acme", "ac me", "acme_eu"} {
+		_, err := s.CreateOrganization(ctx, &ssov1.CreateOrganizationRequest{Slug: slug, Name: "Acme"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), "slug %q", slug)
+	}
+
+	resp, err := s.CreateOrganization(ctx, &ssov1.CreateOrganizationRequest{Slug: "acme-eu", Name: "Acme EU"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(99), resp.GetOrgId())
+}
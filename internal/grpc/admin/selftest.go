@@ -0,0 +1,69 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// SelfTester - самопроверка развёртывания
+type SelfTester interface {
+	// RunSelfTest - проходит сценарий пользователя и возвращает результат шагов (auth.ErrSelfTestDisabled)
+	RunSelfTest(ctx context.Context) (models.SelfTestReport, error)
+}
+
+func (s *serverAPI) RunSelfTest(ctx context.Context, _ *ssov1.RunSelfTestRequest) (*ssov1.RunSelfTestResponse, error) {
+	report, err := s.selfTest.RunSelfTest(ctx)
+	if err != nil {
+		if errors.Is(err, auth.ErrSelfTestDisabled) {
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "self-test is disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &ssov1.RunSelfTestResponse{
+		Passed:    report.Passed(),
+		Steps:     make([]*ssov1.SelfTestStep, 0, len(report.Steps)),
+		StartedAt: report.StartedAt.Unix(),
+	}
+	for _, step := range report.Steps {
+		resp.Steps = append(resp.Steps, toSelfTestStep(step))
+	}
+
+	return resp, nil
+}
+
+// toSelfTestStep - шаг для ответа. Текст ошибки отдаётся как есть: метод только для администраторов,
+// а дежурному он нужен, чтобы разобраться без логов
+func toSelfTestStep(step models.SelfTestStep) *ssov1.SelfTestStep {
+	res := &ssov1.SelfTestStep{
+		Name:       step.Name,
+		Status:     step.Status,
+		DurationMs: step.Duration.Milliseconds(),
+	}
+	if step.Err == nil {
+		return res
+	}
+
+	res.Message = step.Err.Error()
+	if step.Status == models.SelfTestFailed {
+		switch res.Reason = errinfo.Of(step.Err); {
+		case res.Reason != "":
+		case errors.Is(step.Err, context.DeadlineExceeded):
+			res.Reason = errinfo.ForCode(codes.DeadlineExceeded)
+		case errors.Is(step.Err, context.Canceled):
+			res.Reason = errinfo.ForCode(codes.Canceled)
+		default:
+			res.Reason = errinfo.ForCode(codes.Internal)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,665 @@
+package admin
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/url"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/origin"
+	"sso/internal/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Размер страницы ListUsers
+const (
+	defaultPageSize = 100
+	maxPageSize     = 1000
+)
+
+// importBatchSize - сколько пользователей ImportUsers пишет в одной транзакции
+const importBatchSize = 500
+
+// UserAdmin - управление пользователями
+type UserAdmin interface {
+	// ListUsers - до limit пользователей с id больше afterID по возрастанию id;
+	// непустой emailPrefix оставляет только email с этим префиксом (без учёта регистра);
+	// гости попадают в список, только если includeGuests; appID не 0 - только пользователи приложения
+	ListUsers(ctx context.Context, afterID int64, limit int, emailPrefix string, includeGuests bool, appID int) ([]models.User, error)
+
+	// UserByID - пользователь по id (storage.ErrUserNotFound, если его нет)
+	UserByID(ctx context.Context, userID int64) (models.User, error)
+	// User - пользователь по email из общего пространства (storage.ErrUserNotFound, если его нет)
+	User(ctx context.Context, email string) (models.User, error)
+
+	// SetAdmin - выдаёт или отзывает права администратора и возвращает новую версию пользователя.
+	// expectedVersion 0 - без проверки, иначе при несовпадении версии storage.ErrVersionConflict
+	SetAdmin(ctx context.Context, userID int64, isAdmin bool, expectedVersion int64) (int64, error)
+
+	// SetForcePasswordChange - требует смены пароля при следующем входе; expectedVersion - как в SetAdmin
+	SetForcePasswordChange(ctx context.Context, userID int64, force bool, expectedVersion int64) (int64, error)
+
+	// SaveUsers - сохраняет пачку пользователей; результат по каждой строке (nil, storage.ErrUserExists или ошибка)
+	SaveUsers(ctx context.Context, users []models.User) ([]error, error)
+}
+
+// AppAdmin - управление приложениями
+type AppAdmin interface {
+	// SaveApp - регистрирует приложение и возвращает его id
+	SaveApp(ctx context.Context, name string, secret string) (int, error)
+
+	// SetAppGroupsClaim - включает или выключает клейм groups в токенах приложения (storage.ErrAppNotFound)
+	SetAppGroupsClaim(ctx context.Context, appID int, enabled bool) error
+
+	// SetAppThirdParty - помечает приложение как стороннее или внутреннее (storage.ErrAppNotFound)
+	SetAppThirdParty(ctx context.Context, appID int, thirdParty bool) error
+
+	// SetAppSessionLimit - лимит одновременных сессий пользователя и политика его превышения (storage.ErrAppNotFound)
+	SetAppSessionLimit(ctx context.Context, appID int, maxSessions int, policy string) error
+
+	// SetAppSessionTimeouts - тайм-аут бездействия и время жизни сессий приложения (storage.ErrAppNotFound)
+	SetAppSessionTimeouts(ctx context.Context, appID int, idle, maxLifetime time.Duration) error
+	// SetAppRefreshWindows - скользящее окно и предел refresh-токенов приложения (storage.ErrAppNotFound)
+	SetAppRefreshWindows(ctx context.Context, appID int, sliding, maxLifetime time.Duration) error
+
+	// SetAppMinACR - минимальный уровень аутентификации приложения (storage.ErrAppNotFound)
+	SetAppMinACR(ctx context.Context, appID int, minACR string) error
+	// SetAppAcceptOfflineTokens - принимает ли приложение offline-токены (storage.ErrAppNotFound)
+	SetAppAcceptOfflineTokens(ctx context.Context, appID int, accept bool) error
+
+	// SetAppProvisioner - выдаёт или забирает роль provisioner (storage.ErrAppNotFound)
+	SetAppProvisioner(ctx context.Context, appID int, provisioner bool) error
+
+	// App - приложение вместе с политиками входа (storage.ErrAppNotFound)
+	App(ctx context.Context, appID int) (models.App, error)
+	// SetAppLoginPolicy - создаёт или заменяет политику входа приложения (storage.ErrAppNotFound)
+	SetAppLoginPolicy(ctx context.Context, appID int, p models.LoginPolicy) error
+	// DeleteAppLoginPolicy - удаляет политику входа приложения (storage.ErrLoginPolicyNotFound)
+	DeleteAppLoginPolicy(ctx context.Context, appID int, name string) error
+	// SetAppRecheckNetworkPolicy - проверять ли сеть политик у выданных токенов (storage.ErrAppNotFound)
+	SetAppRecheckNetworkPolicy(ctx context.Context, appID int, recheck bool) error
+
+	// SetAppRedirectURIs - адреса возврата приложения для OpenID Connect (storage.ErrAppNotFound)
+	SetAppRedirectURIs(ctx context.Context, appID int, uris []string) error
+
+	// AppOrigins - источники браузерных клиентов приложения для CORS шлюза
+	AppOrigins(ctx context.Context, appID int) ([]string, error)
+	// SetAppOrigins - заменяет источники браузерных клиентов приложения (storage.ErrAppNotFound)
+	SetAppOrigins(ctx context.Context, appID int, origins []string) error
+}
+
+// serverAPI - обработчик сервиса Admin. Права администратора проверяет интерцептор авторизации,
+// права на организацию - сами методы организаций
+type serverAPI struct {
+	ssov1.UnimplementedAdminServer
+	users     UserAdmin
+	apps      AppAdmin
+	orgs      OrgAdmin
+	groups    GroupAdmin
+	invites   Inviter
+	tos       TOSReporter
+	tokens    TokenAdmin
+	backups   Backuper
+	backupDir string // пусто - резервное копирование через API выключено
+
+	maintenance MaintenanceSwitch
+	stats       *statsCache
+	audit       AuditExporter
+	auditChain  AuditChainVerifier // nil - цепочка хешей журнала выключена
+	selfTest    SelfTester
+	appScope    AppScope // nil - user_apps выключен, пользователи приложениям не принадлежат
+}
+
+// RegisterAdminServer - регистрирует сервис Admin. Backup пишет копии только в backupDir
+func RegisterAdminServer(
+	gRPC *grpc.Server,
+	users UserAdmin,
+	apps AppAdmin,
+	orgs OrgAdmin,
+	groups GroupAdmin,
+	invites Inviter,
+	tos TOSReporter,
+	tokens TokenAdmin,
+	backups Backuper,
+	backupDir string,
+	maintenance MaintenanceSwitch,
+	stats StatsProvider,
+	audit AuditExporter,
+	auditChain AuditChainVerifier,
+	selfTest SelfTester,
+	appScope AppScope,
+) {
+	ssov1.RegisterAdminServer(gRPC, &serverAPI{
+		users:     users,
+		apps:      apps,
+		orgs:      orgs,
+		groups:    groups,
+		invites:   invites,
+		tos:       tos,
+		tokens:    tokens,
+		backups:   backups,
+		backupDir: backupDir,
+
+		maintenance: maintenance,
+		stats:       newStatsCache(stats, statsTTL, appScope != nil),
+		audit:       audit,
+		auditChain:  auditChain,
+		selfTest:    selfTest,
+		appScope:    appScope,
+	})
+}
+
+func (s *serverAPI) ListUsers(ctx context.Context, req *ssov1.ListUsersRequest) (*ssov1.ListUsersResponse, error) {
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be in range 0-%d", maxPageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+
+	var afterID int64
+	if token := req.GetPageToken(); token != "" {
+		id, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || id < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		afterID = id
+	}
+
+	if err := s.checkAppScope(ctx, int(req.GetAppId())); err != nil {
+		return nil, err
+	}
+	if req.GetAppId() != 0 && s.appScope == nil {
+		return nil, status.Error(codes.FailedPrecondition, "user_apps is disabled")
+	}
+
+	users, err := s.users.ListUsers(ctx, afterID, pageSize, req.GetEmailPrefix(), req.GetIncludeGuests(), int(req.GetAppId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &ssov1.ListUsersResponse{Users: make([]*ssov1.User, 0, len(users))}
+	for _, u := range users {
+		resp.Users = append(resp.Users, toUser(u))
+	}
+
+	// Полная страница - возможно, есть ещё
+	if len(users) == pageSize {
+		resp.NextPageToken = strconv.FormatInt(users[len(users)-1].ID, 10)
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) GetUser(ctx context.Context, req *ssov1.GetUserRequest) (*ssov1.GetUserResponse, error) {
+	if req.GetUserId() == 0 && req.GetEmail() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id or email is required")
+	}
+	appID := int(req.GetAppId())
+	if err := s.checkAppScope(ctx, appID); err != nil {
+		return nil, err
+	}
+	if appID != 0 && s.appScope == nil {
+		return nil, status.Error(codes.FailedPrecondition, "user_apps is disabled")
+	}
+
+	userID := req.GetUserId()
+	if userID == 0 {
+		byEmail, err := s.users.User(ctx, req.GetEmail())
+		if err != nil {
+			return nil, updateError(err)
+		}
+		userID = byEmail.ID
+	}
+
+	// Пользователь чужого приложения неотличим от несуществующего
+	if appID != 0 {
+		ok, err := s.appScope.IsUserInApp(ctx, userID, appID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "internal error")
+		}
+		if !ok {
+			return nil, status.Error(codes.NotFound, "user not found")
+		}
+	}
+
+	user, err := s.users.UserByID(ctx, userID)
+	if err != nil {
+		return nil, updateError(err)
+	}
+
+	return &ssov1.GetUserResponse{User: toUser(user)}, nil
+}
+
+func (s *serverAPI) SetAdmin(ctx context.Context, req *ssov1.SetAdminRequest) (*ssov1.SetAdminResponse, error) {
+	if req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	version, err := s.users.SetAdmin(ctx, req.GetUserId(), req.GetIsAdmin(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, updateError(err)
+	}
+
+	return &ssov1.SetAdminResponse{Version: version}, nil
+}
+
+func (s *serverAPI) SetForcePasswordChange(
+	ctx context.Context,
+	req *ssov1.SetForcePasswordChangeRequest,
+) (*ssov1.SetForcePasswordChangeResponse, error) {
+	if req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	version, err := s.users.SetForcePasswordChange(ctx, req.GetUserId(), req.GetForce(), req.GetExpectedVersion())
+	if err != nil {
+		return nil, updateError(err)
+	}
+
+	return &ssov1.SetForcePasswordChangeResponse{Version: version}, nil
+}
+
+// updateError - статус ошибки чтения или изменения пользователя
+func updateError(err error) error {
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound):
+		return errinfo.FromService(err, codes.NotFound, "user not found")
+	case errors.Is(err, storage.ErrVersionConflict):
+		return errinfo.FromService(err, codes.Aborted, "user was modified concurrently, re-read it and retry")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func toUser(u models.User) *ssov1.User {
+	return &ssov1.User{
+		Id:       u.ID,
+		Email:    u.Email,
+		IsAdmin:  u.IsAdmin,
+		IsActive: u.IsActive,
+		Version:  u.Version,
+		IsGuest:  u.IsGuest,
+	}
+}
+
+func (s *serverAPI) CreateApp(ctx context.Context, req *ssov1.CreateAppRequest) (*ssov1.CreateAppResponse, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	id, err := s.apps.SaveApp(ctx, req.GetName(), secret)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppExists) {
+			return nil, errinfo.FromService(err, codes.AlreadyExists, "app already exists")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.CreateAppResponse{AppId: int32(id), Secret: secret}, nil
+}
+
+func (s *serverAPI) SetAppThirdParty(
+	ctx context.Context,
+	req *ssov1.SetAppThirdPartyRequest,
+) (*ssov1.SetAppThirdPartyResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	if err := s.apps.SetAppThirdParty(ctx, int(req.GetAppId()), req.GetThirdParty()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppThirdPartyResponse{}, nil
+}
+
+func (s *serverAPI) SetAppSessionLimit(
+	ctx context.Context,
+	req *ssov1.SetAppSessionLimitRequest,
+) (*ssov1.SetAppSessionLimitResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+	if req.GetMaxSessions() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "max_sessions must not be negative")
+	}
+
+	policy := req.GetPolicy()
+	switch policy {
+	case "":
+		policy = models.SessionLimitReject
+	case models.SessionLimitReject, models.SessionLimitEvictOldest:
+	default:
+		return nil, status.Error(codes.InvalidArgument, "policy must be reject or evict_oldest")
+	}
+
+	if err := s.apps.SetAppSessionLimit(ctx, int(req.GetAppId()), int(req.GetMaxSessions()), policy); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppSessionLimitResponse{}, nil
+}
+
+func (s *serverAPI) SetAppSessionTimeouts(
+	ctx context.Context,
+	req *ssov1.SetAppSessionTimeoutsRequest,
+) (*ssov1.SetAppSessionTimeoutsResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+	if req.GetIdleTimeoutSeconds() < 0 || req.GetMaxLifetimeSeconds() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "timeouts must not be negative")
+	}
+
+	idle := time.Duration(req.GetIdleTimeoutSeconds()) * time.Second
+	maxLifetime := time.Duration(req.GetMaxLifetimeSeconds()) * time.Second
+	if err := s.apps.SetAppSessionTimeouts(ctx, int(req.GetAppId()), idle, maxLifetime); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppSessionTimeoutsResponse{}, nil
+}
+
+func (s *serverAPI) SetAppRefreshWindows(
+	ctx context.Context,
+	req *ssov1.SetAppRefreshWindowsRequest,
+) (*ssov1.SetAppRefreshWindowsResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+	if req.GetSlidingWindowSeconds() < 0 || req.GetMaxLifetimeSeconds() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "windows must not be negative")
+	}
+	// Окно шире предела не продлило бы токен ни разу: после входа он сразу упирается в предел
+	if req.GetMaxLifetimeSeconds() > 0 && req.GetSlidingWindowSeconds() > req.GetMaxLifetimeSeconds() {
+		return nil, status.Error(codes.InvalidArgument, "sliding window must not exceed max lifetime")
+	}
+
+	sliding := time.Duration(req.GetSlidingWindowSeconds()) * time.Second
+	maxLifetime := time.Duration(req.GetMaxLifetimeSeconds()) * time.Second
+	if err := s.apps.SetAppRefreshWindows(ctx, int(req.GetAppId()), sliding, maxLifetime); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppRefreshWindowsResponse{}, nil
+}
+
+func (s *serverAPI) SetAppMinACR(ctx context.Context, req *ssov1.SetAppMinACRRequest) (*ssov1.SetAppMinACRResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	switch req.GetMinAcr() {
+	case "", models.ACRSingleFactor, models.ACRMultiFactor:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "min_acr must be empty, %s or %s", models.ACRSingleFactor, models.ACRMultiFactor)
+	}
+
+	if err := s.apps.SetAppMinACR(ctx, int(req.GetAppId()), req.GetMinAcr()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppMinACRResponse{}, nil
+}
+
+func (s *serverAPI) SetAppOfflineTokens(
+	ctx context.Context,
+	req *ssov1.SetAppOfflineTokensRequest,
+) (*ssov1.SetAppOfflineTokensResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	if err := s.apps.SetAppAcceptOfflineTokens(ctx, int(req.GetAppId()), req.GetAccept()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppOfflineTokensResponse{}, nil
+}
+
+func (s *serverAPI) SetAppProvisioner(
+	ctx context.Context,
+	req *ssov1.SetAppProvisionerRequest,
+) (*ssov1.SetAppProvisionerResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	if err := s.apps.SetAppProvisioner(ctx, int(req.GetAppId()), req.GetProvisioner()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppProvisionerResponse{}, nil
+}
+
+// maxRedirectURIs - сколько адресов возврата можно задать приложению
+const maxRedirectURIs = 20
+
+func (s *serverAPI) SetAppRedirectURIs(
+	ctx context.Context,
+	req *ssov1.SetAppRedirectURIsRequest,
+) (*ssov1.SetAppRedirectURIsResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+	if len(req.GetRedirectUris()) > maxRedirectURIs {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d redirect_uris are allowed", maxRedirectURIs)
+	}
+	for _, raw := range req.GetRedirectUris() {
+		// Фрагмент запрещён RFC 6749 (3.1.2), относительный адрес не с чем сравнивать
+		u, err := url.Parse(raw)
+		if err != nil || !u.IsAbs() || u.Host == "" || strings.Contains(raw, "#") {
+			return nil, status.Errorf(codes.InvalidArgument, "redirect_uri %q must be an absolute URL without a fragment", raw)
+		}
+	}
+
+	if err := s.apps.SetAppRedirectURIs(ctx, int(req.GetAppId()), req.GetRedirectUris()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppRedirectURIsResponse{}, nil
+}
+
+// maxAppOrigins - сколько источников можно задать приложению
+const maxAppOrigins = 20
+
+func (s *serverAPI) SetAppOrigins(ctx context.Context, req *ssov1.SetAppOriginsRequest) (*ssov1.SetAppOriginsResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+	if len(req.GetOrigins()) > maxAppOrigins {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d origins are allowed", maxAppOrigins)
+	}
+	for _, o := range req.GetOrigins() {
+		if err := origin.Validate(o); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	if err := s.apps.SetAppOrigins(ctx, int(req.GetAppId()), req.GetOrigins()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.SetAppOriginsResponse{}, nil
+}
+
+func (s *serverAPI) GetAppOrigins(ctx context.Context, req *ssov1.GetAppOriginsRequest) (*ssov1.GetAppOriginsResponse, error) {
+	if req.GetAppId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	if _, err := s.apps.App(ctx, int(req.GetAppId())); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	origins, err := s.apps.AppOrigins(ctx, int(req.GetAppId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.GetAppOriginsResponse{Origins: origins}, nil
+}
+
+// ImportUsers - принимает поток пользователей с готовыми bcrypt-хешами и пишет их пачками по importBatchSize.
+// Дубликаты и невалидные строки не прерывают импорт, а попадают в сводку
+func (s *serverAPI) ImportUsers(stream grpc.ClientStreamingServer[ssov1.ImportUsersRequest, ssov1.ImportUsersResponse]) error {
+	ctx := stream.Context()
+	summary := &ssov1.ImportUsersResponse{}
+
+	var (
+		batch []models.User
+		rows  []int64 // номера строк пачки
+		row   int64
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+
+		results, err := s.users.SaveUsers(ctx, batch)
+		if err != nil {
+			return status.Error(codes.Internal, "failed to save batch")
+		}
+
+		for i, err := range results {
+			switch {
+			case err == nil:
+				summary.Created++
+			case errors.Is(err, storage.ErrUserExists):
+				summary.Skipped++
+				summary.Errors = append(summary.Errors, rowError(rows[i], batch[i].Email, "user already exists"))
+			default:
+				summary.Failed++
+				summary.Errors = append(summary.Errors, rowError(rows[i], batch[i].Email, "failed to save user"))
+			}
+		}
+
+		batch, rows = batch[:0], rows[:0]
+
+		return nil
+	}
+
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		row++
+
+		if reason := validateImportRow(req); reason != "" {
+			summary.Failed++
+			summary.Errors = append(summary.Errors, rowError(row, req.GetEmail(), reason))
+			continue
+		}
+
+		batch = append(batch, models.User{
+			Email:    req.GetEmail(),
+			PassHash: []byte(req.GetPasswordHash()),
+			IsAdmin:  req.GetIsAdmin(),
+		})
+		rows = append(rows, row)
+
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return stream.SendAndClose(summary)
+}
+
+// validateImportRow - возвращает причину отказа или пустую строку
+func validateImportRow(req *ssov1.ImportUsersRequest) string {
+	if req.GetEmail() == "" {
+		return "email is required"
+	}
+
+	// bcrypt.Cost разбирает заголовок хеша ($2a$10$...), открытый пароль его не пройдёт
+	if _, err := bcrypt.Cost([]byte(req.GetPasswordHash())); err != nil {
+		return "password_hash is not a bcrypt hash"
+	}
+
+	return ""
+}
+
+func rowError(row int64, email, reason string) *ssov1.ImportRowError {
+	return &ssov1.ImportRowError{Row: row, Email: email, Reason: reason}
+}
+
+// newSecret - случайный секрет приложения (256 бит)
+func newSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
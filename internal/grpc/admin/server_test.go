@@ -0,0 +1,252 @@
+package admin
+
+import (
+	"context"
+	"io"
+	"sso/internal/domain/models"
+	"sso/internal/lib/authctx"
+	"sso/internal/storage"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// asAdmin - контекст вызова администратора (без user_apps он видит всех пользователей)
+func asAdmin() context.Context {
+	return authctx.Into(context.Background(), authctx.Principal{UserID: 100, IsAdmin: true})
+}
+
+// fakeUsers - пользователи с id 1..n
+type fakeUsers struct {
+	UserAdmin
+	n int64
+}
+
+func (f fakeUsers) ListUsers(_ context.Context, afterID int64, limit int, _ string, _ bool, _ int) ([]models.User, error) {
+	var users []models.User
+	for id := afterID + 1; id <= f.n && len(users) < limit; id++ {
+		users = append(users, models.User{ID: id})
+	}
+
+	return users, nil
+}
+
+// memUsers - хранилище импорта в памяти
+type memUsers struct {
+	UserAdmin
+	emails map[string]bool
+}
+
+func (m *memUsers) SaveUsers(_ context.Context, users []models.User) ([]error, error) {
+	res := make([]error, len(users))
+	for i, u := range users {
+		if m.emails[u.Email] {
+			res[i] = storage.ErrUserExists
+			continue
+		}
+		m.emails[u.Email] = true
+	}
+
+	return res, nil
+}
+
+// versionedUser - один пользователь с версией
+type versionedUser struct {
+	UserAdmin
+	user models.User
+}
+
+func (v *versionedUser) UserByID(_ context.Context, userID int64) (models.User, error) {
+	if userID != v.user.ID {
+		return models.User{}, storage.ErrUserNotFound
+	}
+
+	return v.user, nil
+}
+
+func (v *versionedUser) SetAdmin(_ context.Context, userID int64, isAdmin bool, expectedVersion int64) (int64, error) {
+	if userID != v.user.ID {
+		return 0, storage.ErrUserNotFound
+	}
+	if expectedVersion != 0 && expectedVersion != v.user.Version {
+		return 0, storage.ErrVersionConflict
+	}
+	v.user.IsAdmin = isAdmin
+	v.user.Version++
+
+	return v.user.Version, nil
+}
+
+// importStream - клиентский поток из заранее заданных сообщений
+type importStream struct {
+	grpc.ServerStream
+	reqs []*ssov1.ImportUsersRequest
+	resp *ssov1.ImportUsersResponse
+}
+
+func (s *importStream) Context() context.Context { return context.Background() }
+
+func (s *importStream) Recv() (*ssov1.ImportUsersRequest, error) {
+	if len(s.reqs) == 0 {
+		return nil, io.EOF
+	}
+	req := s.reqs[0]
+	s.reqs = s.reqs[1:]
+
+	return req, nil
+}
+
+func (s *importStream) SendAndClose(resp *ssov1.ImportUsersResponse) error {
+	s.resp = resp
+	return nil
+}
+
+func TestImportUsers(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	users := &memUsers{emails: map[string]bool{"old@example.com": true}}
+	s := &serverAPI{users: users}
+
+	stream := &importStream{reqs: []*ssov1.ImportUsersRequest{
+		{Email: "new@example.com", PasswordHash: string(hash)},
+		{Email: "old@example.com", PasswordHash: string(hash)},
+		{Email: "plain@example.com", PasswordHash: "hunter2"},
+		{Email: "", PasswordHash: string(hash)},
+		{Email: "admin@example.com", PasswordHash: string(hash), IsAdmin: true},
+	}}
+
+	require.NoError(t, s.ImportUsers(stream))
+
+	assert.Equal(t, int64(2), stream.resp.GetCreated())
+	assert.Equal(t, int64(1), stream.resp.GetSkipped())
+	assert.Equal(t, int64(2), stream.resp.GetFailed())
+
+	rows := map[int64]string{}
+	for _, e := range stream.resp.GetErrors() {
+		rows[e.GetRow()] = e.GetReason()
+	}
+	assert.Equal(t, map[int64]string{
+		2: "user already exists",
+		3: "password_hash is not a bcrypt hash",
+		4: "email is required",
+	}, rows)
+}
+
+func TestListUsers_Pagination(t *testing.T) {
+	s := &serverAPI{users: fakeUsers{n: 5}}
+
+	var (
+		ids   []int64
+		token string
+	)
+	for range 10 {
+		resp, err := s.ListUsers(asAdmin(), &ssov1.ListUsersRequest{PageSize: 2, PageToken: token})
+		require.NoError(t, err)
+
+		for _, u := range resp.GetUsers() {
+			ids = append(ids, u.GetId())
+		}
+
+		token = resp.GetNextPageToken()
+		if token == "" {
+			break
+		}
+	}
+
+	assert.Equal(t, []int64{1, 2, 3, 4, 5}, ids)
+}
+
+func TestListUsers_InvalidArguments(t *testing.T) {
+	s := &serverAPI{users: fakeUsers{n: 5}}
+
+	_, err := s.ListUsers(context.Background(), &ssov1.ListUsersRequest{PageSize: maxPageSize + 1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.ListUsers(context.Background(), &ssov1.ListUsersRequest{PageToken: "abc"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// Два администратора прочитали одну версию: второе изменение отклоняется с ABORTED
+func TestSetAdmin_VersionConflict(t *testing.T) {
+	s := &serverAPI{users: &versionedUser{user: models.User{ID: 1, Version: 3}}}
+	ctx := asAdmin()
+
+	got, err := s.GetUser(ctx, &ssov1.GetUserRequest{UserId: 1})
+	require.NoError(t, err)
+	read := got.GetUser().GetVersion()
+	require.Equal(t, int64(3), read)
+
+	resp, err := s.SetAdmin(ctx, &ssov1.SetAdminRequest{UserId: 1, IsAdmin: true, ExpectedVersion: read})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), resp.GetVersion())
+
+	_, err = s.SetAdmin(ctx, &ssov1.SetAdminRequest{UserId: 1, IsAdmin: false, ExpectedVersion: read})
+	assert.Equal(t, codes.Aborted, status.Code(err))
+
+	// Без expected_version изменение проходит всегда
+	resp, err = s.SetAdmin(ctx, &ssov1.SetAdminRequest{UserId: 1, IsAdmin: false})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), resp.GetVersion())
+
+	_, err = s.GetUser(ctx, &ssov1.GetUserRequest{UserId: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+// fakeOriginApps - источники приложений; приложение 1 существует
+type fakeOriginApps struct {
+	AppAdmin
+	origins map[int][]string
+}
+
+func (f *fakeOriginApps) SetAppOrigins(_ context.Context, appID int, origins []string) error {
+	if appID != 1 {
+		return storage.ErrAppNotFound
+	}
+	f.origins[appID] = origins
+
+	return nil
+}
+
+func (f *fakeOriginApps) App(_ context.Context, appID int) (models.App, error) {
+	if appID != 1 {
+		return models.App{}, storage.ErrAppNotFound
+	}
+
+	return models.App{ID: appID}, nil
+}
+
+func (f *fakeOriginApps) AppOrigins(_ context.Context, appID int) ([]string, error) {
+	return f.origins[appID], nil
+}
+
+func TestSetAppOrigins(t *testing.T) {
+	ctx := context.Background()
+	apps := &fakeOriginApps{origins: map[int][]string{}}
+	s := &serverAPI{apps: apps}
+
+	origins := []string{"https://*.example.com", "http://localhost:3000"}
+	_, err := s.SetAppOrigins(ctx, &ssov1.SetAppOriginsRequest{AppId: 1, Origins: origins})
+	require.NoError(t, err)
+
+	resp, err := s.GetAppOrigins(ctx, &ssov1.GetAppOriginsRequest{AppId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, origins, resp.GetOrigins())
+
+	for _, bad := range []string{"https://example.com/", "https://a.*.example.com", "https://*.com", "example.com"} {
+		_, err = s.SetAppOrigins(ctx, &ssov1.SetAppOriginsRequest{AppId: 1, Origins: []string{bad}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), bad)
+	}
+	assert.Equal(t, origins, apps.origins[1], "invalid list is not saved")
+
+	_, err = s.SetAppOrigins(ctx, &ssov1.SetAppOriginsRequest{AppId: 2, Origins: origins})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = s.GetAppOrigins(ctx, &ssov1.GetAppOriginsRequest{AppId: 2})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,94 @@
+package admin
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sync"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statsTTL - сколько живёт посчитанная сводка: панели опрашивают GetStats хоть каждую секунду,
+// а хранилище считает агрегаты не чаще раза в statsTTL на каждое приложение
+const statsTTL = 30 * time.Second
+
+// StatsProvider - сводка для панели администратора (sqlite.Storage.Stats)
+type StatsProvider interface {
+	// Stats - сводка на момент now; appUsers - считать по appID и пользователей (user_apps)
+	Stats(ctx context.Context, appID int, appUsers bool, now time.Time) (models.Stats, error)
+}
+
+// statsCache - сводки по app_id, посчитанные не раньше ttl назад. Запросы одного app_id, пришедшие
+// во время подсчёта, ждут его результата, а не считают заново
+type statsCache struct {
+	provider StatsProvider
+	ttl      time.Duration
+	appUsers bool // пользователи принадлежат приложениям (user_apps.enabled)
+	now      func() time.Time
+
+	mu      sync.Mutex
+	entries map[int]*statsEntry
+}
+
+type statsEntry struct {
+	mu    sync.Mutex
+	stats models.Stats
+	ok    bool
+}
+
+func newStatsCache(provider StatsProvider, ttl time.Duration, appUsers bool) *statsCache {
+	return &statsCache{provider: provider, ttl: ttl, appUsers: appUsers, now: time.Now, entries: make(map[int]*statsEntry)}
+}
+
+// get - сводка по appID из кеша или свежая из хранилища. Ошибки не кешируются
+func (c *statsCache) get(ctx context.Context, appID int) (models.Stats, error) {
+	c.mu.Lock()
+	e, ok := c.entries[appID]
+	if !ok {
+		e = &statsEntry{}
+		c.entries[appID] = e
+	}
+	c.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := c.now()
+	if e.ok && now.Sub(e.stats.GeneratedAt) < c.ttl {
+		return e.stats, nil
+	}
+
+	st, err := c.provider.Stats(ctx, appID, c.appUsers, now)
+	if err != nil {
+		return models.Stats{}, err
+	}
+	e.stats, e.ok = st, true
+
+	return st, nil
+}
+
+func (s *serverAPI) GetStats(ctx context.Context, req *ssov1.GetStatsRequest) (*ssov1.GetStatsResponse, error) {
+	if err := s.checkAppScope(ctx, int(req.GetAppId())); err != nil {
+		return nil, err
+	}
+
+	st, err := s.stats.get(ctx, int(req.GetAppId()))
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.GetStatsResponse{
+		TotalUsers:           st.TotalUsers,
+		RegisteredLast_24H:   st.RegisteredLast24h,
+		RegisteredLast_7D:    st.RegisteredLast7d,
+		RegisteredLast_30D:   st.RegisteredLast30d,
+		LockedUsers:          st.LockedUsers,
+		ActiveSessions:       st.ActiveSessions,
+		LoginsLastHour:       st.LoginsLastHour,
+		FailedLoginsLastHour: st.FailedLoginsLastHour,
+		GeneratedAt:          st.GeneratedAt.Unix(),
+	}, nil
+}
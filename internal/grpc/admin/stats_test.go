@@ -0,0 +1,74 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// countingStats - сводка, в которой число пользователей равно номеру подсчёта
+type countingStats struct {
+	calls map[int]int
+	fail  bool
+}
+
+func (c *countingStats) Stats(_ context.Context, appID int, _ bool, now time.Time) (models.Stats, error) {
+	if c.fail {
+		return models.Stats{}, errors.New("database is locked")
+	}
+	c.calls[appID]++
+
+	return models.Stats{TotalUsers: int64(c.calls[appID]), GeneratedAt: now}, nil
+}
+
+func TestGetStats_Cache(t *testing.T) {
+	provider := &countingStats{calls: map[int]int{}}
+	cache := newStatsCache(provider, statsTTL, false)
+	now := time.Unix(1_700_000_000, 0)
+	cache.now = func() time.Time { return now }
+	s := &serverAPI{stats: cache}
+	ctx := asAdmin()
+
+	resp, err := s.GetStats(ctx, &ssov1.GetStatsRequest{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, resp.GetTotalUsers())
+	assert.Equal(t, now.Unix(), resp.GetGeneratedAt())
+
+	now = now.Add(statsTTL - time.Second)
+	resp, err = s.GetStats(ctx, &ssov1.GetStatsRequest{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, resp.GetTotalUsers(), "served from cache")
+
+	// У каждого приложения своя запись
+	resp, err = s.GetStats(ctx, &ssov1.GetStatsRequest{AppId: 3})
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, resp.GetTotalUsers())
+	assert.Equal(t, 1, provider.calls[3])
+
+	now = now.Add(time.Second)
+	resp, err = s.GetStats(ctx, &ssov1.GetStatsRequest{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, resp.GetTotalUsers(), "recomputed after ttl")
+
+	// Ошибка не кешируется и не затирает прошлую сводку
+	now = now.Add(statsTTL)
+	provider.fail = true
+	_, err = s.GetStats(ctx, &ssov1.GetStatsRequest{})
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	provider.fail = false
+	resp, err = s.GetStats(ctx, &ssov1.GetStatsRequest{})
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, resp.GetTotalUsers())
+
+	_, err = s.GetStats(ctx, &ssov1.GetStatsRequest{AppId: -1})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
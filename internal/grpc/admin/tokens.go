@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TokenAdmin - разбор и отзыв отдельных токенов (сервисный слой: там ключи приложений и сессии)
+type TokenAdmin interface {
+	// InspectToken - клеймы и состояние токена, в том числе истёкшего (auth.ErrInvalidAppID, auth.ErrInvalidToken)
+	InspectToken(ctx context.Context, token string) (models.TokenInfo, error)
+
+	// RevokeToken - отзывает токен и возвращает его состояние после отзыва
+	RevokeToken(ctx context.Context, token string) (models.TokenInfo, error)
+}
+
+func (s *serverAPI) InspectToken(ctx context.Context, req *ssov1.InspectTokenRequest) (*ssov1.InspectTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	info, err := s.tokens.InspectToken(ctx, req.GetToken())
+	if err != nil {
+		return nil, tokenError(err)
+	}
+
+	return tokenInfoToProto(info), nil
+}
+
+func (s *serverAPI) RevokeToken(ctx context.Context, req *ssov1.RevokeTokenRequest) (*ssov1.RevokeTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	info, err := s.tokens.RevokeToken(ctx, req.GetToken())
+	if err != nil {
+		return nil, tokenError(err)
+	}
+
+	resp := &ssov1.RevokeTokenResponse{TokenId: info.TokenID}
+	if info.RevokedReason == models.RevokedBySession {
+		resp.SessionId = info.SessionID
+	}
+
+	return resp, nil
+}
+
+// tokenError - токен чужого для сервиса приложения не найден, а не сломан
+func tokenError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidAppID):
+		return errinfo.FromService(err, codes.NotFound, "token app not found")
+	case errors.Is(err, auth.ErrInvalidToken):
+		return errinfo.FromService(err, codes.InvalidArgument, "invalid token")
+	case errors.Is(err, auth.ErrTokenRevocationDisabled):
+		return errinfo.FromService(err, codes.FailedPrecondition, "token has no session and revoking single tokens is disabled")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func tokenInfoToProto(info models.TokenInfo) *ssov1.InspectTokenResponse {
+	resp := &ssov1.InspectTokenResponse{
+		UserId:        info.UserID,
+		Email:         info.Email,
+		AppId:         int32(info.AppID),
+		TokenId:       info.TokenID,
+		SessionId:     info.SessionID,
+		Scopes:        info.Scopes,
+		OrgId:         info.OrgID,
+		Guest:         info.Guest,
+		Offline:       info.Offline,
+		Amr:           info.AMR,
+		Acr:           info.ACR,
+		Purpose:       info.Purpose,
+		ExpiresAt:     info.ExpiresAt.Unix(),
+		Validity:      info.Validity,
+		Revoked:       info.Revoked,
+		RevokedReason: info.RevokedReason,
+	}
+	if !info.IssuedAt.IsZero() {
+		resp.IssuedAt = info.IssuedAt.Unix()
+	}
+	if !info.AuthTime.IsZero() {
+		resp.AuthTime = info.AuthTime.Unix()
+	}
+
+	if sess := info.Session; sess != nil {
+		resp.Session = &ssov1.TokenSession{
+			Id:        sess.ID,
+			CreatedAt: sess.CreatedAt.Unix(),
+			ExpiresAt: sess.ExpiresAt.Unix(),
+		}
+		if !sess.LastSeenAt.IsZero() {
+			resp.Session.LastSeenAt = sess.LastSeenAt.Unix()
+		}
+		if !sess.RevokedAt.IsZero() {
+			resp.Session.RevokedAt = sess.RevokedAt.Unix()
+		}
+	}
+
+	return resp
+}
@@ -0,0 +1,87 @@
+package admin
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/services/auth"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeTokens - токены "expired" (истёкший токен сессии sid-1), "foreign" (чужое приложение) и "garbage"
+type fakeTokens struct {
+	revoked []string
+}
+
+func (f *fakeTokens) InspectToken(_ context.Context, token string) (models.TokenInfo, error) {
+	switch token {
+	case "expired":
+		return models.TokenInfo{
+			TokenID: "jti-1", UserID: 7, AppID: 1, SessionID: "sid-1",
+			ExpiresAt: time.Unix(1_700_000_000, 0), Validity: models.TokenExpired,
+			Session: &models.Session{ID: "sid-1", CreatedAt: time.Unix(1_699_990_000, 0)},
+		}, nil
+	case "foreign":
+		return models.TokenInfo{}, fmt.Errorf("Auth.InspectToken: %w", auth.ErrInvalidAppID)
+	default:
+		return models.TokenInfo{}, fmt.Errorf("Auth.InspectToken: %w: token is malformed", auth.ErrInvalidToken)
+	}
+}
+
+func (f *fakeTokens) RevokeToken(ctx context.Context, token string) (models.TokenInfo, error) {
+	info, err := f.InspectToken(ctx, token)
+	if err != nil {
+		return models.TokenInfo{}, err
+	}
+	f.revoked = append(f.revoked, info.TokenID)
+	info.Revoked, info.RevokedReason = true, models.RevokedBySession
+
+	return info, nil
+}
+
+func TestInspectToken(t *testing.T) {
+	s := &serverAPI{tokens: &fakeTokens{}}
+	ctx := context.Background()
+
+	resp, err := s.InspectToken(ctx, &ssov1.InspectTokenRequest{Token: "expired"})
+	require.NoError(t, err)
+	assert.Equal(t, "expired", resp.GetValidity())
+	assert.Equal(t, int64(1_700_000_000), resp.GetExpiresAt())
+	assert.Equal(t, "sid-1", resp.GetSession().GetId())
+	assert.Zero(t, resp.GetSession().GetRevokedAt())
+
+	tests := []struct {
+		token string
+		want  codes.Code
+	}{
+		{"", codes.InvalidArgument},
+		{"foreign", codes.NotFound},
+		{"garbage", codes.InvalidArgument},
+	}
+	for _, tt := range tests {
+		_, err := s.InspectToken(ctx, &ssov1.InspectTokenRequest{Token: tt.token})
+		assert.Equal(t, tt.want, status.Code(err), tt.token)
+	}
+}
+
+func TestRevokeToken(t *testing.T) {
+	tokens := &fakeTokens{}
+	s := &serverAPI{tokens: tokens}
+	ctx := context.Background()
+
+	resp, err := s.RevokeToken(ctx, &ssov1.RevokeTokenRequest{Token: "expired"})
+	require.NoError(t, err)
+	assert.Equal(t, "jti-1", resp.GetTokenId())
+	assert.Equal(t, "sid-1", resp.GetSessionId())
+	assert.Equal(t, []string{"jti-1"}, tokens.revoked)
+
+	_, err = s.RevokeToken(ctx, &ssov1.RevokeTokenRequest{Token: "foreign"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
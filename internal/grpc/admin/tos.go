@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+	"strconv"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TOSReporter - отчёт по принятию условий использования (сервисный слой: там текущая версия)
+type TOSReporter interface {
+	// TOSVersion - текущая версия условий (пусто - не учитываются)
+	TOSVersion() string
+
+	// UsersPendingTOS - до limit пользователей с id больше afterID, не принявших текущую версию
+	UsersPendingTOS(ctx context.Context, afterID int64, limit int) ([]models.PendingTOS, error)
+}
+
+func (s *serverAPI) ListPendingTOS(ctx context.Context, req *ssov1.ListPendingTOSRequest) (*ssov1.ListPendingTOSResponse, error) {
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0 || pageSize > maxPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be in range 0-%d", maxPageSize)
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	}
+
+	var afterID int64
+	if token := req.GetPageToken(); token != "" {
+		id, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || id < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		afterID = id
+	}
+
+	// Версию читаем до выборки: если её поменяют между страницами, отчёт покажет, по какой он построен
+	version := s.tos.TOSVersion()
+
+	pending, err := s.tos.UsersPendingTOS(ctx, afterID, pageSize)
+	if err != nil {
+		if errors.Is(err, auth.ErrTOSDisabled) {
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "terms of service are not tracked")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &ssov1.ListPendingTOSResponse{
+		Users:          make([]*ssov1.PendingTOS, 0, len(pending)),
+		CurrentVersion: version,
+	}
+	for _, p := range pending {
+		user := &ssov1.PendingTOS{UserId: p.UserID, Email: p.Email, AcceptedVersion: p.Version}
+		if !p.AcceptedAt.IsZero() {
+			user.AcceptedAt = p.AcceptedAt.Unix()
+		}
+		resp.Users = append(resp.Users, user)
+	}
+
+	// Полная страница - возможно, есть ещё
+	if len(pending) == pageSize {
+		resp.NextPageToken = strconv.FormatInt(pending[len(pending)-1].UserID, 10)
+	}
+
+	return resp, nil
+}
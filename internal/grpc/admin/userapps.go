@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/storage"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AppScope - пользователи и администраторы приложений (user_apps.enabled)
+type AppScope interface {
+	// IsUserInApp - связан ли пользователь с приложением
+	IsUserInApp(ctx context.Context, userID int64, appID int) (bool, error)
+	// IsAppAdmin - является ли пользователь администратором приложения
+	IsAppAdmin(ctx context.Context, userID int64, appID int) (bool, error)
+	// SetAppAdmin - назначает или снимает администратора приложения (storage.ErrAppNotFound, storage.ErrUserNotFound)
+	SetAppAdmin(ctx context.Context, userID int64, appID int, admin bool) error
+}
+
+// checkAppScope - может ли вызывающий смотреть пользователей приложения appID (0 - всех пользователей).
+// Методы с этой проверкой не требуют глобальных прав администратора (interceptors.AccessAppAdmin).
+// Без user_apps.enabled администраторов приложений нет: нужен администратор, и он видит всех.
+// С ним суперадминистратор видит всех, а остальные - только приложения, которыми управляют
+func (s *serverAPI) checkAppScope(ctx context.Context, appID int) error {
+	if appID < 0 {
+		return status.Error(codes.InvalidArgument, "app_id must not be negative")
+	}
+
+	p, _ := authctx.From(ctx)
+	if s.appScope == nil {
+		if !p.IsAdmin {
+			return status.Error(codes.PermissionDenied, "admin access required")
+		}
+
+		return nil
+	}
+	if p.IsSuperadmin {
+		return nil
+	}
+	if appID == 0 {
+		return status.Error(codes.PermissionDenied, "app_id is required: only a superadmin can query all apps")
+	}
+
+	ok, err := s.appScope.IsAppAdmin(ctx, p.UserID, appID)
+	if err != nil {
+		return status.Error(codes.Internal, "internal error")
+	}
+	if !ok {
+		return status.Error(codes.PermissionDenied, "not an administrator of this app")
+	}
+
+	return nil
+}
+
+func (s *serverAPI) SetAppAdmin(ctx context.Context, req *ssov1.SetAppAdminRequest) (*ssov1.SetAppAdminResponse, error) {
+	if s.appScope == nil {
+		return nil, status.Error(codes.FailedPrecondition, "user_apps is disabled")
+	}
+	if p, _ := authctx.From(ctx); !p.IsSuperadmin {
+		return nil, status.Error(codes.PermissionDenied, "only a superadmin can assign app administrators")
+	}
+	if req.GetAppId() <= 0 || req.GetUserId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "app_id and user_id are required")
+	}
+
+	if err := s.appScope.SetAppAdmin(ctx, req.GetUserId(), int(req.GetAppId()), req.GetAdmin()); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "app not found")
+		}
+
+		return nil, updateError(err)
+	}
+
+	return &ssov1.SetAppAdminResponse{}, nil
+}
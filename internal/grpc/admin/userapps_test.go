@@ -0,0 +1,136 @@
+package admin
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/authctx"
+	"sso/internal/storage"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAppScope - пользователь 1 администрирует приложение 1; пользователи 5 и 6 - в приложении 1, 6 - и во 2
+type fakeAppScope struct {
+	set []bool
+}
+
+func (f *fakeAppScope) IsUserInApp(_ context.Context, userID int64, appID int) (bool, error) {
+	return appID == 1 && (userID == 5 || userID == 6) || appID == 2 && userID == 6, nil
+}
+
+func (f *fakeAppScope) IsAppAdmin(_ context.Context, userID int64, appID int) (bool, error) {
+	return userID == 1 && appID == 1, nil
+}
+
+func (f *fakeAppScope) SetAppAdmin(_ context.Context, _ int64, appID int, admin bool) error {
+	if appID != 1 {
+		return storage.ErrAppNotFound
+	}
+	f.set = append(f.set, admin)
+
+	return nil
+}
+
+// emailUsers - пользователи 5 и 6, находятся и по email
+type emailUsers struct {
+	fakeUsers
+}
+
+func (emailUsers) User(_ context.Context, email string) (models.User, error) {
+	if email == "six@example.com" {
+		return models.User{ID: 6, Email: email}, nil
+	}
+
+	return models.User{}, storage.ErrUserNotFound
+}
+
+func (emailUsers) UserByID(_ context.Context, userID int64) (models.User, error) {
+	return models.User{ID: userID}, nil
+}
+
+func TestAppScope(t *testing.T) {
+	s := &serverAPI{users: emailUsers{fakeUsers{n: 3}}, appScope: &fakeAppScope{}, stats: newStatsCache(&countingStats{calls: map[int]int{}}, statsTTL, true)}
+
+	// Администратору приложения глобальные права не нужны (interceptors.AccessAppAdmin)
+	appAdmin := authctx.Into(context.Background(), authctx.Principal{UserID: 1})
+	superadmin := authctx.Into(context.Background(), authctx.Principal{UserID: 2, IsAdmin: true, IsSuperadmin: true})
+
+	_, err := s.ListUsers(appAdmin, &ssov1.ListUsersRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.ListUsers(appAdmin, &ssov1.ListUsersRequest{AppId: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.ListUsers(appAdmin, &ssov1.ListUsersRequest{AppId: 1})
+	require.NoError(t, err)
+	_, err = s.ListUsers(superadmin, &ssov1.ListUsersRequest{})
+	require.NoError(t, err)
+
+	_, err = s.GetStats(appAdmin, &ssov1.GetStatsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.GetStats(appAdmin, &ssov1.GetStatsRequest{AppId: 1})
+	require.NoError(t, err)
+	_, err = s.GetStats(appAdmin, &ssov1.GetStatsRequest{AppId: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	resp, err := s.GetUser(appAdmin, &ssov1.GetUserRequest{Email: "six@example.com", AppId: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(6), resp.GetUser().GetId())
+
+	// Пользователь другого приложения неотличим от несуществующего
+	_, err = s.GetUser(appAdmin, &ssov1.GetUserRequest{UserId: 3, AppId: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = s.GetUser(appAdmin, &ssov1.GetUserRequest{UserId: 6})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.GetUser(appAdmin, &ssov1.GetUserRequest{UserId: 6, AppId: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Пользователь, который ничем не управляет, не видит ни одного приложения
+	nobody := authctx.Into(context.Background(), authctx.Principal{UserID: 9})
+	_, err = s.ListUsers(nobody, &ssov1.ListUsersRequest{AppId: 1})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
+
+func TestAppScope_Disabled(t *testing.T) {
+	s := &serverAPI{users: fakeUsers{n: 3}, stats: newStatsCache(&countingStats{calls: map[int]int{}}, statsTTL, false)}
+	ctx := authctx.Into(context.Background(), authctx.Principal{UserID: 1, IsAdmin: true})
+
+	_, err := s.ListUsers(ctx, &ssov1.ListUsersRequest{})
+	require.NoError(t, err)
+
+	// Без user_apps администраторов приложений нет: методы пользователей только для администратора
+	user := authctx.Into(context.Background(), authctx.Principal{UserID: 1})
+	_, err = s.ListUsers(user, &ssov1.ListUsersRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.GetUser(user, &ssov1.GetUserRequest{UserId: 2})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	_, err = s.GetStats(user, &ssov1.GetStatsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = s.ListUsers(ctx, &ssov1.ListUsersRequest{AppId: 1})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = s.SetAppAdmin(ctx, &ssov1.SetAppAdminRequest{AppId: 1, UserId: 5, Admin: true})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestSetAppAdmin(t *testing.T) {
+	scope := &fakeAppScope{}
+	s := &serverAPI{appScope: scope}
+
+	appAdmin := authctx.Into(context.Background(), authctx.Principal{UserID: 1, IsAdmin: true})
+	superadmin := authctx.Into(context.Background(), authctx.Principal{UserID: 2, IsAdmin: true, IsSuperadmin: true})
+
+	_, err := s.SetAppAdmin(appAdmin, &ssov1.SetAppAdminRequest{AppId: 1, UserId: 5, Admin: true})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = s.SetAppAdmin(superadmin, &ssov1.SetAppAdminRequest{AppId: 1, UserId: 5, Admin: true})
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true}, scope.set)
+
+	_, err = s.SetAppAdmin(superadmin, &ssov1.SetAppAdminRequest{AppId: 2, UserId: 5, Admin: true})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,120 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/services/auth"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Методы согласий требуют токена (см. accessPolicy). Согласиями пользователя управляет он сам
+// или администратор, и только с токеном внутреннего приложения: иначе стороннее приложение
+// могло бы само выдать себе согласие
+
+func (s *serverAPI) GrantConsent(ctx context.Context, req *ssov1.GrantConsentRequest) (*ssov1.GrantConsentResponse, error) {
+	if req.GetUserId() == emptyValue || req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "user_id and app_id are required")
+	}
+
+	if err := checkConsentCaller(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	consent, err := s.auth.GrantConsent(ctx, req.GetUserId(), int(req.GetAppId()), req.GetScopes())
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidScopes):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "scopes must be non-empty names like email or profile")
+		case errors.Is(err, auth.ErrInvalidAppID):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+		case errors.Is(err, auth.ErrConsentsDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "consents are disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.GrantConsentResponse{Consent: toConsent(consent)}, nil
+}
+
+func (s *serverAPI) ListConsents(ctx context.Context, req *ssov1.ListConsentsRequest) (*ssov1.ListConsentsResponse, error) {
+	if req.GetUserId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	if err := checkConsentCaller(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	consents, err := s.auth.ListConsents(ctx, req.GetUserId())
+	if err != nil {
+		if errors.Is(err, auth.ErrConsentsDisabled) {
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "consents are disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &ssov1.ListConsentsResponse{Consents: make([]*ssov1.Consent, 0, len(consents))}
+	for _, c := range consents {
+		resp.Consents = append(resp.Consents, toConsent(c))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) RevokeConsent(ctx context.Context, req *ssov1.RevokeConsentRequest) (*ssov1.RevokeConsentResponse, error) {
+	if req.GetUserId() == emptyValue || req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "user_id and app_id are required")
+	}
+
+	if err := checkConsentCaller(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RevokeConsent(ctx, req.GetUserId(), int(req.GetAppId())); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrConsentNotFound):
+			return nil, errinfo.FromService(err, codes.NotFound, "no active consent for this app")
+		case errors.Is(err, auth.ErrConsentsDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "consents are disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.RevokeConsentResponse{}, nil
+}
+
+// checkConsentCaller - может ли вызывающий управлять согласиями userID
+func checkConsentCaller(ctx context.Context, userID int64) error {
+	principal, ok := authctx.From(ctx)
+	if !ok || (!principal.IsAdmin && principal.UserID != userID) {
+		return status.Error(codes.PermissionDenied, "can only manage own consents")
+	}
+
+	if principal.ThirdParty {
+		return status.Error(codes.PermissionDenied, "consents can only be managed from a first-party app")
+	}
+
+	return nil
+}
+
+func toConsent(c models.Consent) *ssov1.Consent {
+	consent := &ssov1.Consent{
+		AppId:     int32(c.AppID),
+		Scopes:    c.Scopes,
+		GrantedAt: c.GrantedAt.Unix(),
+	}
+	if !c.Active() {
+		consent.RevokedAt = c.RevokedAt.Unix()
+	}
+
+	return consent
+}
@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/services/auth"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// RequestAccountDeletion требует токена (см. accessPolicy): удаление запрашивает сам пользователь или администратор
+func (s *serverAPI) RequestAccountDeletion(
+	ctx context.Context,
+	req *ssov1.RequestAccountDeletionRequest,
+) (*ssov1.RequestAccountDeletionResponse, error) {
+	if req.GetUserId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	principal, ok := authctx.From(ctx)
+	if !ok || (!principal.IsAdmin && principal.UserID != req.GetUserId()) {
+		return nil, status.Error(codes.PermissionDenied, "can only request deletion of your own account")
+	}
+
+	d, err := s.auth.RequestAccountDeletion(ctx, req.GetUserId())
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrUserNotFound):
+			return nil, errinfo.FromService(err, codes.NotFound, "user not found")
+		case errors.Is(err, auth.ErrGuestDeletion):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "guest accounts cannot be deleted")
+		case errors.Is(err, auth.ErrAccountDeletionDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "account deletion is disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.RequestAccountDeletionResponse{ExecuteAt: d.ExecuteAt.Unix()}, nil
+}
+
+// CancelAccountDeletion публичный: токен из письма сам подтверждает право на отмену,
+// а без токена отменить удаление может только его владелец или администратор
+func (s *serverAPI) CancelAccountDeletion(
+	ctx context.Context,
+	req *ssov1.CancelAccountDeletionRequest,
+) (*ssov1.CancelAccountDeletionResponse, error) {
+	if req.GetToken() == "" && req.GetUserId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "token or user_id is required")
+	}
+
+	if req.GetToken() == "" {
+		principal, ok := authctx.From(ctx)
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+		if principal.IsGuest || (!principal.IsAdmin && principal.UserID != req.GetUserId()) {
+			return nil, status.Error(codes.PermissionDenied, "can only cancel deletion of your own account")
+		}
+	}
+
+	userID, err := s.auth.CancelAccountDeletion(ctx, req.GetToken(), req.GetUserId())
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrNoPendingDeletion):
+			return nil, errinfo.FromService(err, codes.NotFound, "no pending account deletion")
+		case errors.Is(err, auth.ErrAccountDeletionDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "account deletion is disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.CancelAccountDeletionResponse{UserId: userID}, nil
+}
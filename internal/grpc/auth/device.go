@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/services/auth"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StartDeviceAuthorization и PollDeviceToken публичные: у устройства ещё нет токена.
+// ApproveDevice и DenyDevice вызывает вошедший пользователь (см. accessPolicy)
+
+func (s *serverAPI) StartDeviceAuthorization(
+	ctx context.Context,
+	req *ssov1.StartDeviceAuthorizationRequest,
+) (*ssov1.StartDeviceAuthorizationResponse, error) {
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	dc, err := s.auth.StartDeviceAuthorization(ctx, int(req.GetAppId()))
+	if err != nil {
+		return nil, deviceError(err)
+	}
+
+	return &ssov1.StartDeviceAuthorizationResponse{
+		DeviceCode:              dc.DeviceCode,
+		UserCode:                dc.UserCode,
+		VerificationUri:         dc.VerificationURI,
+		VerificationUriComplete: dc.VerificationURIComplete,
+		ExpiresIn:               dc.ExpiresAt.Unix() - time.Now().Unix(),
+		Interval:                int64(dc.Interval / time.Second),
+	}, nil
+}
+
+func (s *serverAPI) ApproveDevice(ctx context.Context, req *ssov1.ApproveDeviceRequest) (*ssov1.ApproveDeviceResponse, error) {
+	if req.GetUserCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_code is required")
+	}
+
+	principal, err := deviceCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.ApproveDevice(ctx, principal.UserID, req.GetUserCode()); err != nil {
+		return nil, deviceError(err)
+	}
+
+	return &ssov1.ApproveDeviceResponse{}, nil
+}
+
+func (s *serverAPI) DenyDevice(ctx context.Context, req *ssov1.DenyDeviceRequest) (*ssov1.DenyDeviceResponse, error) {
+	if req.GetUserCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_code is required")
+	}
+
+	principal, err := deviceCaller(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.DenyDevice(ctx, principal.UserID, req.GetUserCode()); err != nil {
+		return nil, deviceError(err)
+	}
+
+	return &ssov1.DenyDeviceResponse{}, nil
+}
+
+func (s *serverAPI) PollDeviceToken(
+	ctx context.Context,
+	req *ssov1.PollDeviceTokenRequest,
+) (*ssov1.PollDeviceTokenResponse, error) {
+	if req.GetDeviceCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "device_code is required")
+	}
+
+	token, err := s.auth.PollDeviceToken(ctx, req.GetDeviceCode())
+	if err != nil {
+		return nil, deviceError(err)
+	}
+
+	return &ssov1.PollDeviceTokenResponse{
+		Token:     token.AccessToken,
+		ExpiresIn: token.ExpiresAt.Unix() - time.Now().Unix(),
+		TokenType: tokenTypeBearer,
+	}, nil
+}
+
+// deviceCaller - пользователь, принимающий решение. Токен стороннего приложения не подходит:
+// иначе приложение могло бы само разрешить вход на устройстве от имени пользователя
+func deviceCaller(ctx context.Context) (authctx.Principal, error) {
+	principal, ok := authctx.From(ctx)
+	if !ok {
+		return authctx.Principal{}, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	if principal.ThirdParty {
+		return authctx.Principal{}, status.Error(codes.PermissionDenied, "devices can only be approved from a first-party app")
+	}
+
+	return principal, nil
+}
+
+// deviceError - статус ошибки входа на устройстве
+func deviceError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrAuthorizationPending):
+		return failedPrecondition("user has not approved the device yet", ReasonAuthorizationPending)
+	case errors.Is(err, auth.ErrSlowDown):
+		return failedPrecondition("polling too frequently, increase the interval by 5 seconds", ReasonSlowDown)
+	case errors.Is(err, auth.ErrDeviceDenied):
+		return failedPrecondition("user denied the device authorization", ReasonAccessDenied)
+	case errors.Is(err, auth.ErrDeviceCodeExpired):
+		return failedPrecondition("device code expired, start again", ReasonDeviceCodeExpired)
+	case errors.Is(err, auth.ErrDeviceCodeNotFound):
+		return errinfo.FromService(err, codes.NotFound, "device code not found or already used")
+	case errors.Is(err, auth.ErrUserCodeNotFound):
+		return errinfo.FromService(err, codes.NotFound, "user code not found")
+	case errors.Is(err, auth.ErrDeviceAlreadyDecided):
+		return errinfo.FromService(err, codes.FailedPrecondition, "device authorization is already decided")
+	case errors.Is(err, auth.ErrConsentRequired):
+		return failedPrecondition("user has not consented to share data with this app", ReasonConsentRequired)
+	case errors.Is(err, auth.ErrInvalidAppID):
+		return errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+	case errors.Is(err, auth.ErrDeviceFlowDisabled):
+		return errinfo.FromService(err, codes.FailedPrecondition, "device authorization is disabled")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
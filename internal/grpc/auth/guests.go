@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *serverAPI) CreateGuest(ctx context.Context, req *ssov1.CreateGuestRequest) (*ssov1.CreateGuestResponse, error) {
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	userID, token, err := s.auth.CreateGuest(ctx, int(req.GetAppId()))
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidAppID):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+		case errors.Is(err, auth.ErrGuestAppNotAllowed):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "guest accounts are not available for third-party apps")
+		case errors.Is(err, auth.ErrGuestsDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "guest accounts are disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.CreateGuestResponse{
+		UserId:    userID,
+		Token:     token.AccessToken,
+		ExpiresIn: token.ExpiresAt.Unix() - time.Now().Unix(),
+		TokenType: tokenTypeBearer,
+	}, nil
+}
+
+func (s *serverAPI) UpgradeGuest(ctx context.Context, req *ssov1.UpgradeGuestRequest) (*ssov1.UpgradeGuestResponse, error) {
+	if req.GetGuestToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "guest_token is required")
+	}
+	if err := validateRegister(&ssov1.RegisterRequest{Email: req.GetEmail(), Password: req.GetPassword()}); err != nil {
+		return nil, err
+	}
+
+	userID, token, err := s.auth.UpgradeGuest(
+		ctx, req.GetGuestToken(), req.GetEmail(), req.GetPassword(), req.GetAcceptedTosVersion())
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidToken):
+			return nil, errinfo.FromService(err, codes.Unauthenticated, "invalid guest token")
+		case errors.Is(err, auth.ErrNotGuest):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "user is not a guest")
+		case errors.Is(err, auth.ErrUserExists):
+			return nil, errinfo.FromService(err, codes.AlreadyExists, "user already exists")
+		case errors.Is(err, auth.ErrWeakPassword):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "password has appeared in a data breach, choose another one")
+		case errors.Is(err, auth.ErrPasswordTooLong):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "password must not be longer than 72 bytes")
+		case errors.Is(err, auth.ErrDomainNotAllowed):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "registration with this email domain is not allowed")
+		case errors.Is(err, auth.ErrTOSNotAccepted):
+			return nil, s.tosStatus("the current terms of service must be accepted", ReasonTOSAcceptanceRequired)
+		case errors.Is(err, auth.ErrGuestsDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "guest accounts are disabled")
+		case errors.Is(err, auth.ErrOverloaded):
+			return nil, overloadedStatus()
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.UpgradeGuestResponse{
+		UserId:    userID,
+		Token:     token.AccessToken,
+		ExpiresIn: token.ExpiresAt.Unix() - time.Now().Unix(),
+		TokenType: tokenTypeBearer,
+	}, nil
+}
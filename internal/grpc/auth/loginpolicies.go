@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// policyDeniedStatus - PermissionDenied для входа, запрещённого политикой приложения: в сообщении
+// и в metadata ErrorInfo - имя политики и нарушенное условие (network, weekday, time).
+// nil - err не отказ политики
+func policyDeniedStatus(err error) error {
+	var denied *auth.PolicyDeniedError
+	if !errors.As(err, &denied) {
+		return nil
+	}
+
+	st := status.New(codes.PermissionDenied,
+		fmt.Sprintf("login is not allowed by app policy %q: %s rule", denied.Policy, denied.Rule))
+
+	withDetails, detailsErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   errinfo.ReasonPolicyDenied,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{"policy": denied.Policy, "rule": denied.Rule},
+	})
+	if detailsErr != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
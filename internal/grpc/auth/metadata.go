@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/services/auth"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Методы метаданных требуют токена (см. accessPolicy). Приложение видит только свои метаданные:
+// без прав администратора app_id должен совпадать с приложением токена, иначе одно приложение
+// могло бы переписать настройки другого
+
+func (s *serverAPI) GetUserMetadata(
+	ctx context.Context,
+	req *ssov1.GetUserMetadataRequest,
+) (*ssov1.GetUserMetadataResponse, error) {
+	appID, err := metadataScope(ctx, req.GetUserId(), req.GetAppId())
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.auth.AppMetadata(ctx, req.GetUserId(), appID)
+	if err != nil {
+		return nil, metadataError(err)
+	}
+
+	return &ssov1.GetUserMetadataResponse{Metadata: metadata}, nil
+}
+
+func (s *serverAPI) UpdateUserMetadata(
+	ctx context.Context,
+	req *ssov1.UpdateUserMetadataRequest,
+) (*ssov1.UpdateUserMetadataResponse, error) {
+	if len(req.GetPatch()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "patch is required")
+	}
+
+	appID, err := metadataScope(ctx, req.GetUserId(), req.GetAppId())
+	if err != nil {
+		return nil, err
+	}
+
+	metadata, err := s.auth.UpdateAppMetadata(ctx, req.GetUserId(), appID, req.GetPatch())
+	if err != nil {
+		return nil, metadataError(err)
+	}
+
+	return &ssov1.UpdateUserMetadataResponse{Metadata: metadata}, nil
+}
+
+// metadataScope - приложение, с метаданными которого работает вызывающий
+func metadataScope(ctx context.Context, userID int64, appID int32) (int, error) {
+	if userID == emptyValue {
+		return 0, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	principal, ok := authctx.From(ctx)
+	if !ok || (!principal.IsAdmin && principal.UserID != userID) {
+		return 0, status.Error(codes.PermissionDenied, "can only access own metadata")
+	}
+
+	if appID == emptyValue {
+		return principal.AppID, nil
+	}
+
+	if !principal.IsAdmin && int(appID) != principal.AppID {
+		return 0, status.Error(codes.PermissionDenied, "can only access metadata of the token's app")
+	}
+
+	return int(appID), nil
+}
+
+func metadataError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrUserNotFound):
+		return errinfo.FromService(err, codes.NotFound, "user not found")
+	case errors.Is(err, auth.ErrInvalidAppID):
+		return errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+	case errors.Is(err, auth.ErrInvalidMetadata):
+		return errinfo.FromService(err, codes.InvalidArgument, "patch must be a JSON merge patch producing an object")
+	case errors.Is(err, auth.ErrMetadataTooLarge):
+		return errinfo.Newf(codes.InvalidArgument, errinfo.ReasonMetadataTooLarge, "metadata must not exceed %d bytes", auth.MaxAppMetadataSize)
+	case errors.Is(err, auth.ErrMetadataConflict):
+		return errinfo.FromService(err, codes.Aborted, "metadata changed concurrently, retry")
+	case errors.Is(err, auth.ErrMetadataDisabled):
+		return errinfo.FromService(err, codes.FailedPrecondition, "app metadata is not available")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/services/auth"
+	"strings"
+	"unicode/utf8"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxOfflineLabelLen - максимальная длина подписи offline-токена в символах
+const maxOfflineLabelLen = 64
+
+// IssueOfflineToken, ListOfflineTokens и RevokeOfflineToken требуют токена (см. accessPolicy).
+// Выдать offline-токен может только сам пользователь: нужен его свежий вход со вторым фактором
+
+func (s *serverAPI) IssueOfflineToken(
+	ctx context.Context,
+	req *ssov1.IssueOfflineTokenRequest,
+) (*ssov1.IssueOfflineTokenResponse, error) {
+	if req.GetUserId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+	label := strings.TrimSpace(req.GetLabel())
+	if label == "" {
+		return nil, status.Error(codes.InvalidArgument, "label is required")
+	}
+	if utf8.RuneCountInString(label) > maxOfflineLabelLen {
+		return nil, status.Errorf(codes.InvalidArgument, "label must not be longer than %d characters", maxOfflineLabelLen)
+	}
+
+	principal, err := firstPartyPrincipal(ctx, "offline tokens can only be issued from a first-party app")
+	if err != nil {
+		return nil, err
+	}
+	if principal.UserID != req.GetUserId() {
+		return nil, status.Error(codes.PermissionDenied, "can only issue offline tokens for your own account")
+	}
+
+	t, token, err := s.auth.IssueOfflineToken(ctx, principal, req.GetUserId(), int(req.GetAppId()), label)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidAppID):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+		case errors.Is(err, auth.ErrUserNotFound):
+			return nil, errinfo.FromService(err, codes.NotFound, "user not found")
+		}
+
+		return nil, offlineTokenError(err)
+	}
+
+	return &ssov1.IssueOfflineTokenResponse{
+		Token:        token.AccessToken,
+		OfflineToken: offlineTokenToProto(t),
+	}, nil
+}
+
+func (s *serverAPI) ListOfflineTokens(
+	ctx context.Context,
+	req *ssov1.ListOfflineTokensRequest,
+) (*ssov1.ListOfflineTokensResponse, error) {
+	if err := offlineTokensOwner(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	tokens, err := s.auth.ListOfflineTokens(ctx, req.GetUserId())
+	if err != nil {
+		return nil, offlineTokenError(err)
+	}
+
+	resp := &ssov1.ListOfflineTokensResponse{OfflineTokens: make([]*ssov1.OfflineToken, 0, len(tokens))}
+	for _, t := range tokens {
+		resp.OfflineTokens = append(resp.OfflineTokens, offlineTokenToProto(t))
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) RevokeOfflineToken(
+	ctx context.Context,
+	req *ssov1.RevokeOfflineTokenRequest,
+) (*ssov1.RevokeOfflineTokenResponse, error) {
+	if req.GetId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := offlineTokensOwner(ctx, req.GetUserId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RevokeOfflineToken(ctx, req.GetUserId(), req.GetId()); err != nil {
+		return nil, offlineTokenError(err)
+	}
+
+	return &ssov1.RevokeOfflineTokenResponse{}, nil
+}
+
+// offlineTokensOwner - offline-токенами пользователя userID управляет он сам или администратор
+func offlineTokensOwner(ctx context.Context, userID int64) error {
+	if userID == emptyValue {
+		return status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	principal, ok := authctx.From(ctx)
+	if !ok || (!principal.IsAdmin && principal.UserID != userID) {
+		return status.Error(codes.PermissionDenied, "can only manage offline tokens of your own account")
+	}
+
+	return nil
+}
+
+// offlineTokenError - статус ошибки offline-токенов
+func offlineTokenError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrOfflineTokenNotFound):
+		return errinfo.FromService(err, codes.NotFound, "offline token not found")
+	case errors.Is(err, auth.ErrFreshMFARequired):
+		return errinfo.FromService(err, codes.FailedPrecondition, "a recent login with a second factor is required")
+	case errors.Is(err, auth.ErrOfflineTokensNotAccepted):
+		return errinfo.FromService(err, codes.FailedPrecondition, "app does not accept offline tokens")
+	case errors.Is(err, auth.ErrOfflineTokensDisabled):
+		return errinfo.FromService(err, codes.FailedPrecondition, "offline tokens are disabled")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func offlineTokenToProto(t models.OfflineToken) *ssov1.OfflineToken {
+	return &ssov1.OfflineToken{
+		Id:        t.ID,
+		AppId:     int32(t.AppID),
+		Label:     t.Label,
+		Scopes:    t.Scopes,
+		CreatedAt: t.CreatedAt.Unix(),
+		ExpiresAt: t.ExpiresAt.Unix(),
+	}
+}
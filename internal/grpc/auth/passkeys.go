@@ -0,0 +1,225 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/services/auth"
+	"time"
+	"unicode/utf8"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// maxPasskeyNameLen - максимальная длина имени passkey в символах
+const maxPasskeyNameLen = 64
+
+// BeginPasskeyLogin и FinishPasskeyLogin публичные: у пользователя ещё нет токена.
+// Регистрацию, список и удаление passkey вызывает вошедший пользователь (см. accessPolicy)
+
+func (s *serverAPI) BeginPasskeyRegistration(
+	ctx context.Context,
+	_ *ssov1.BeginPasskeyRegistrationRequest,
+) (*ssov1.BeginPasskeyRegistrationResponse, error) {
+	principal, err := passkeyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := s.auth.BeginPasskeyRegistration(ctx, principal.UserID)
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.BeginPasskeyRegistrationResponse{
+		SessionId:   opts.SessionID,
+		OptionsJson: opts.OptionsJSON,
+	}, nil
+}
+
+func (s *serverAPI) FinishPasskeyRegistration(
+	ctx context.Context,
+	req *ssov1.FinishPasskeyRegistrationRequest,
+) (*ssov1.FinishPasskeyRegistrationResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if len(req.GetCredentialJson()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "credential_json is required")
+	}
+	if utf8.RuneCountInString(req.GetName()) > maxPasskeyNameLen {
+		return nil, status.Errorf(codes.InvalidArgument, "name must not be longer than %d characters", maxPasskeyNameLen)
+	}
+
+	principal, err := passkeyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.auth.FinishPasskeyRegistration(ctx, principal.UserID, req.GetSessionId(), req.GetName(), req.GetCredentialJson())
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.FinishPasskeyRegistrationResponse{PasskeyId: id}, nil
+}
+
+func (s *serverAPI) BeginPasskeyLogin(
+	ctx context.Context,
+	req *ssov1.BeginPasskeyLoginRequest,
+) (*ssov1.BeginPasskeyLoginResponse, error) {
+	opts, err := s.auth.BeginPasskeyLogin(ctx, req.GetEmail())
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.BeginPasskeyLoginResponse{
+		SessionId:   opts.SessionID,
+		OptionsJson: opts.OptionsJSON,
+	}, nil
+}
+
+func (s *serverAPI) FinishPasskeyLogin(
+	ctx context.Context,
+	req *ssov1.FinishPasskeyLoginRequest,
+) (*ssov1.FinishPasskeyLoginResponse, error) {
+	if req.GetSessionId() == "" {
+		return nil, status.Error(codes.InvalidArgument, "session_id is required")
+	}
+	if len(req.GetCredentialJson()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "credential_json is required")
+	}
+	if req.GetAppId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "app_id is required")
+	}
+
+	token, err := s.auth.FinishPasskeyLogin(
+		ctx, req.GetSessionId(), req.GetCredentialJson(), int(req.GetAppId()), req.GetAcceptedTosVersion())
+	if err != nil {
+		if errors.Is(err, auth.ErrConsentRequired) {
+			return nil, failedPrecondition("user has not consented to share data with this app", ReasonConsentRequired)
+		}
+
+		if errors.Is(err, auth.ErrTOSReacceptanceRequired) {
+			return nil, s.tosStatus("terms of service have changed, accept the current version", ReasonTOSReacceptanceRequired)
+		}
+
+		if st := policyDeniedStatus(err); st != nil {
+			return nil, st
+		}
+
+		if errors.Is(err, auth.ErrTooManySessions) {
+			return nil, failedPrecondition("too many concurrent sessions for this app, sign out elsewhere first", ReasonSessionLimitReached)
+		}
+
+		if errors.Is(err, auth.ErrInvalidAppID) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+		}
+
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.FinishPasskeyLoginResponse{
+		Token:     token.AccessToken,
+		ExpiresIn: token.ExpiresAt.Unix() - time.Now().Unix(),
+		TokenType: tokenTypeBearer,
+		Scopes:    token.Scopes,
+
+		TosReacceptanceRequired: token.TOSReacceptanceRequired,
+		StepUpRequired:          token.StepUpRequired,
+		RefreshToken:            token.RefreshToken,
+		RefreshExpiresIn:        refreshExpiresIn(token),
+	}, nil
+}
+
+func (s *serverAPI) ListPasskeys(ctx context.Context, _ *ssov1.ListPasskeysRequest) (*ssov1.ListPasskeysResponse, error) {
+	principal, err := passkeyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	passkeys, err := s.auth.ListPasskeys(ctx, principal.UserID)
+	if err != nil {
+		return nil, passkeyError(err)
+	}
+
+	resp := &ssov1.ListPasskeysResponse{Passkeys: make([]*ssov1.Passkey, 0, len(passkeys))}
+	for _, p := range passkeys {
+		pk := &ssov1.Passkey{
+			Id:         p.ID,
+			Name:       p.Name,
+			Transports: p.Transports,
+			Synced:     p.BackupState,
+			CreatedAt:  p.CreatedAt.Unix(),
+		}
+		if !p.LastUsedAt.IsZero() {
+			pk.LastUsedAt = p.LastUsedAt.Unix()
+		}
+		resp.Passkeys = append(resp.Passkeys, pk)
+	}
+
+	return resp, nil
+}
+
+func (s *serverAPI) DeletePasskey(ctx context.Context, req *ssov1.DeletePasskeyRequest) (*ssov1.DeletePasskeyResponse, error) {
+	if req.GetPasskeyId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "passkey_id is required")
+	}
+
+	principal, err := passkeyOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.DeletePasskey(ctx, principal.UserID, req.GetPasskeyId()); err != nil {
+		return nil, passkeyError(err)
+	}
+
+	return &ssov1.DeletePasskeyResponse{}, nil
+}
+
+// passkeyOwner - владелец passkey. Токен стороннего приложения не подходит:
+// иначе приложение могло бы добавить пользователю свой ключ входа
+func passkeyOwner(ctx context.Context) (authctx.Principal, error) {
+	return firstPartyPrincipal(ctx, "passkeys can only be managed from a first-party app")
+}
+
+// firstPartyPrincipal - вызывающий с токеном собственного приложения сервиса (способы входа
+// пользователя сторонним приложениям недоступны); msg - сообщение отказа для стороннего
+func firstPartyPrincipal(ctx context.Context, msg string) (authctx.Principal, error) {
+	principal, ok := authctx.From(ctx)
+	if !ok {
+		return authctx.Principal{}, status.Error(codes.Unauthenticated, "authentication required")
+	}
+
+	if principal.ThirdParty {
+		return authctx.Principal{}, status.Error(codes.PermissionDenied, msg)
+	}
+
+	return principal, nil
+}
+
+// passkeyError - статус ошибки passkey
+func passkeyError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrPasskeyChallengeNotFound):
+		return errinfo.FromService(err, codes.NotFound, "passkey challenge not found or expired, start again")
+	case errors.Is(err, auth.ErrInvalidPasskey):
+		return errinfo.FromService(err, codes.InvalidArgument, "passkey verification failed")
+	case errors.Is(err, auth.ErrPasskeyNotFound):
+		return errinfo.FromService(err, codes.NotFound, "passkey not found")
+	case errors.Is(err, auth.ErrPasskeyExists):
+		return errinfo.FromService(err, codes.AlreadyExists, "passkey is already registered")
+	case errors.Is(err, auth.ErrTooManyPasskeys):
+		return errinfo.FromService(err, codes.FailedPrecondition, "passkey limit reached, delete one first")
+	case errors.Is(err, auth.ErrUserNotFound):
+		return errinfo.FromService(err, codes.NotFound, "user not found")
+	case errors.Is(err, auth.ErrPasskeysDisabled):
+		return errinfo.FromService(err, codes.FailedPrecondition, "passkeys are disabled")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
@@ -0,0 +1,183 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Ограничения запросов провижининга
+const (
+	maxExternalIDLen         = 256  // символов в external_id
+	maxProvisionedAttributes = 64   // атрибутов у пользователя
+	maxAttributeNameLen      = 64   // символов в имени атрибута
+	maxAttributeValueLen     = 1024 // символов в значении атрибута
+
+	defaultProvisionedPageSize = 100
+	maxProvisionedPageSize     = 1000
+)
+
+// ProvisionUser, DeprovisionUser и ListProvisionedUsers публичны для интерцептора авторизации:
+// вызывающего проверяет сервис по client_id и client_secret приложения с ролью provisioner
+
+func (s *serverAPI) ProvisionUser(
+	ctx context.Context,
+	req *ssov1.ProvisionUserRequest,
+) (*ssov1.ProvisionUserResponse, error) {
+	if err := validateProvisioner(req.GetClientId(), req.GetClientSecret()); err != nil {
+		return nil, err
+	}
+	if err := validateExternalID(req.GetExternalId()); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.GetEmail()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "email is required")
+	}
+	if len(req.GetAttributes()) > maxProvisionedAttributes {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d attributes are allowed", maxProvisionedAttributes)
+	}
+	for name, value := range req.GetAttributes() {
+		if name == "" || utf8.RuneCountInString(name) > maxAttributeNameLen {
+			return nil, status.Errorf(codes.InvalidArgument, "attribute names must be 1-%d characters long", maxAttributeNameLen)
+		}
+		if utf8.RuneCountInString(value) > maxAttributeValueLen {
+			return nil, status.Errorf(codes.InvalidArgument, "attribute %q must not be longer than %d characters",
+				name, maxAttributeValueLen)
+		}
+	}
+
+	user, created, err := s.auth.ProvisionUser(ctx, int(req.GetClientId()), req.GetClientSecret(),
+		req.GetExternalId(), req.GetEmail(), req.GetAttributes())
+	if err != nil {
+		return nil, provisioningError(err)
+	}
+
+	return &ssov1.ProvisionUserResponse{User: provisionedUserToProto(user), Created: created}, nil
+}
+
+func (s *serverAPI) DeprovisionUser(
+	ctx context.Context,
+	req *ssov1.DeprovisionUserRequest,
+) (*ssov1.DeprovisionUserResponse, error) {
+	if err := validateProvisioner(req.GetClientId(), req.GetClientSecret()); err != nil {
+		return nil, err
+	}
+	if err := validateExternalID(req.GetExternalId()); err != nil {
+		return nil, err
+	}
+
+	userID, err := s.auth.DeprovisionUser(ctx, int(req.GetClientId()), req.GetClientSecret(), req.GetExternalId())
+	if err != nil {
+		return nil, provisioningError(err)
+	}
+
+	return &ssov1.DeprovisionUserResponse{UserId: userID}, nil
+}
+
+func (s *serverAPI) ListProvisionedUsers(
+	ctx context.Context,
+	req *ssov1.ListProvisionedUsersRequest,
+) (*ssov1.ListProvisionedUsersResponse, error) {
+	if err := validateProvisioner(req.GetClientId(), req.GetClientSecret()); err != nil {
+		return nil, err
+	}
+	if req.GetSince() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "since must not be negative")
+	}
+
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize < 0 || pageSize > maxProvisionedPageSize:
+		return nil, status.Errorf(codes.InvalidArgument, "page_size must be in range 0-%d", maxProvisionedPageSize)
+	case pageSize == 0:
+		pageSize = defaultProvisionedPageSize
+	}
+
+	var afterID int64
+	if token := req.GetPageToken(); token != "" {
+		id, err := strconv.ParseInt(token, 10, 64)
+		if err != nil || id < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+		afterID = id
+	}
+
+	users, err := s.auth.ListProvisionedUsers(ctx, int(req.GetClientId()), req.GetClientSecret(),
+		time.Unix(req.GetSince(), 0), afterID, pageSize)
+	if err != nil {
+		return nil, provisioningError(err)
+	}
+
+	resp := &ssov1.ListProvisionedUsersResponse{Users: make([]*ssov1.ProvisionedUser, 0, len(users))}
+	for _, u := range users {
+		resp.Users = append(resp.Users, provisionedUserToProto(u))
+	}
+
+	// Полная страница - возможно, есть ещё
+	if len(users) == pageSize {
+		resp.NextPageToken = strconv.FormatInt(users[len(users)-1].UserID, 10)
+	}
+
+	return resp, nil
+}
+
+func validateProvisioner(clientID int32, clientSecret string) error {
+	if clientID == emptyValue {
+		return status.Error(codes.InvalidArgument, "client_id is required")
+	}
+	if clientSecret == "" {
+		return status.Error(codes.InvalidArgument, "client_secret is required")
+	}
+
+	return nil
+}
+
+func validateExternalID(externalID string) error {
+	if strings.TrimSpace(externalID) == "" {
+		return status.Error(codes.InvalidArgument, "external_id is required")
+	}
+	if utf8.RuneCountInString(externalID) > maxExternalIDLen {
+		return status.Errorf(codes.InvalidArgument, "external_id must not be longer than %d characters", maxExternalIDLen)
+	}
+
+	return nil
+}
+
+// provisioningError - статус ошибки провижининга
+func provisioningError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidClient):
+		return errinfo.FromService(err, codes.Unauthenticated, "invalid client credentials")
+	case errors.Is(err, auth.ErrNotProvisioner):
+		return errinfo.FromService(err, codes.PermissionDenied, "app does not have the provisioner role")
+	case errors.Is(err, auth.ErrProvisioningConflict):
+		return errinfo.FromService(err, codes.AlreadyExists, "email is already used by another account")
+	case errors.Is(err, auth.ErrProvisionedUserNotFound):
+		return errinfo.FromService(err, codes.NotFound, "no user is provisioned with this external_id")
+	case errors.Is(err, auth.ErrProvisioningDisabled):
+		return errinfo.FromService(err, codes.FailedPrecondition, "user provisioning is disabled")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+func provisionedUserToProto(u models.ProvisionedUser) *ssov1.ProvisionedUser {
+	return &ssov1.ProvisionedUser{
+		UserId:        u.UserID,
+		ExternalId:    u.ExternalID,
+		Email:         u.Email,
+		Attributes:    u.Attributes,
+		Active:        u.IsActive,
+		ProvisionedAt: u.ProvisionedAt.Unix(),
+	}
+}
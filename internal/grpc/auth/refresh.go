@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Refresh - обмен refresh-токена на новую пару токенов. Публичный метод, как и Login:
+// доказательство - сам refresh-токен в запросе
+func (s *serverAPI) Refresh(ctx context.Context, req *ssov1.RefreshRequest) (*ssov1.RefreshResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "refresh_token is required")
+	}
+
+	token, err := s.auth.RefreshToken(ctx, req.GetRefreshToken())
+	if err != nil {
+		if st := policyDeniedStatus(err); st != nil {
+			return nil, st
+		}
+
+		switch {
+		case errors.Is(err, auth.ErrReloginRequired), errors.Is(err, auth.ErrRefreshTokenExpired):
+			return nil, errinfo.FromService(err, codes.Unauthenticated, "sign-in expired, sign in again")
+		case errors.Is(err, auth.ErrInvalidRefreshToken):
+			return nil, errinfo.FromService(err, codes.Unauthenticated, "invalid refresh token")
+		case errors.Is(err, auth.ErrRefreshDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "refresh tokens are disabled")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.RefreshResponse{
+		Token:            token.AccessToken,
+		ExpiresIn:        token.ExpiresAt.Unix() - time.Now().Unix(),
+		TokenType:        tokenTypeBearer,
+		Scopes:           token.Scopes,
+		StepUpRequired:   token.StepUpRequired,
+		RefreshToken:     token.RefreshToken,
+		RefreshExpiresIn: refreshExpiresIn(token),
+	}, nil
+}
+
+// refreshExpiresIn - через сколько секунд refresh-токен нужно обменять; срок тот же, что записан в цепочке
+// (0 - refresh-токен не выдан)
+func refreshExpiresIn(token models.Token) int64 {
+	if token.RefreshToken == "" {
+		return 0
+	}
+
+	return token.RefreshExpiresAt.Unix() - time.Now().Unix()
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/lib/revocations"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replayBatch - сколько отзывов дочитывается из журнала за один запрос
+const replayBatch = 500
+
+// RevocationFeed - журнал отзывов и подписка на новые (revocations.Feed)
+type RevocationFeed interface {
+	// RevocationsSince - до limit отзывов с sequence больше afterSeq по возрастанию
+	RevocationsSince(ctx context.Context, afterSeq int64, limit int) ([]models.Revocation, error)
+	// Subscribe - подписка на отзывы, которые будут записаны дальше
+	Subscribe(ctx context.Context) (*revocations.Subscription, error)
+	// KeepaliveInterval - как часто слать пинг в потоке без отзывов
+	KeepaliveInterval() time.Duration
+}
+
+// WatchRevocations - отдаёт отзывы из журнала после since_sequence и затем новые по мере записи
+func (s *serverAPI) WatchRevocations(
+	req *ssov1.WatchRevocationsRequest,
+	stream grpc.ServerStreamingServer[ssov1.WatchRevocationsResponse],
+) error {
+	if s.revocations == nil {
+		return status.Error(codes.Unimplemented, "revocation feed is not available")
+	}
+	if req.GetSinceSequence() < 0 {
+		return status.Error(codes.InvalidArgument, "since_sequence must not be negative")
+	}
+
+	ctx := stream.Context()
+
+	// Подписка раньше дочитывания: отзывы, записанные во время него, придут по подписке
+	// (повторы отбрасываются по sequence)
+	sub, err := s.revocations.Subscribe(ctx)
+	if err != nil {
+		return status.Error(codes.Internal, "internal error")
+	}
+	defer sub.Close()
+
+	last, err := s.replayRevocations(ctx, stream, req.GetSinceSequence())
+	if err != nil {
+		return err
+	}
+
+	keepalive := time.NewTicker(s.revocations.KeepaliveInterval())
+	defer keepalive.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-sub.Closed():
+			if errors.Is(sub.Err(), revocations.ErrSlowConsumer) {
+				return withReason(codes.ResourceExhausted,
+					"consumer is too slow, reconnect with the last received sequence", ReasonSlowConsumer)
+			}
+
+			return status.Error(codes.Unavailable, "server is shutting down, reconnect with the last received sequence")
+		case r := <-sub.C():
+			if r.Seq <= last {
+				continue
+			}
+			if err := stream.Send(revocationEvent(r)); err != nil {
+				return err
+			}
+			last = r.Seq
+			keepalive.Reset(s.revocations.KeepaliveInterval())
+		case <-keepalive.C:
+			if err := stream.Send(&ssov1.WatchRevocationsResponse{Event: &ssov1.WatchRevocationsResponse_Keepalive{
+				Keepalive: &ssov1.RevocationKeepalive{Sequence: last},
+			}}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// replayRevocations - отправляет отзывы журнала после since и возвращает последний отправленный sequence.
+// Если первая запись журнала дальше since + 1, часть истории уже удалена и кеш потребителя не восстановить
+func (s *serverAPI) replayRevocations(
+	ctx context.Context,
+	stream grpc.ServerStreamingServer[ssov1.WatchRevocationsResponse],
+	since int64,
+) (int64, error) {
+	last := since
+	for {
+		batch, err := s.revocations.RevocationsSince(ctx, last, replayBatch)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, status.FromContextError(ctx.Err()).Err()
+			}
+
+			return 0, status.Error(codes.Internal, "internal error")
+		}
+
+		if last == since && since > 0 && len(batch) > 0 && batch[0].Seq > since+1 {
+			return 0, status.Error(codes.OutOfRange, "since_sequence is older than the retained history, drop the cache")
+		}
+
+		for _, r := range batch {
+			if err := stream.Send(revocationEvent(r)); err != nil {
+				return 0, err
+			}
+			last = r.Seq
+		}
+
+		if len(batch) < replayBatch {
+			return last, nil
+		}
+	}
+}
+
+func revocationEvent(r models.Revocation) *ssov1.WatchRevocationsResponse {
+	return &ssov1.WatchRevocationsResponse{Event: &ssov1.WatchRevocationsResponse_Revocation{
+		Revocation: &ssov1.Revocation{
+			Sequence:  r.Seq,
+			Kind:      r.Kind,
+			UserId:    r.UserID,
+			AppId:     int32(r.AppID),
+			SessionId: r.SessionID,
+			RevokedAt: r.RevokedAt.Unix(),
+			TokenId:   r.TokenID,
+		},
+	}}
+}
@@ -0,0 +1,155 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sso/internal/config"
+	"sso/internal/domain/models"
+	"sso/internal/lib/revocations"
+	"sync"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// revocationLog - журнал отзывов в памяти (seq задаёт тест, чтобы имитировать удалённую историю)
+type revocationLog struct {
+	mu   sync.Mutex
+	rows []models.Revocation
+}
+
+func (l *revocationLog) add(seq int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.rows = append(l.rows, models.Revocation{Seq: seq, Kind: models.RevocationSession, UserID: 7, SessionID: "sid"})
+}
+
+func (l *revocationLog) RevocationsSince(_ context.Context, afterSeq int64, limit int) ([]models.Revocation, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var res []models.Revocation
+	for _, r := range l.rows {
+		if r.Seq > afterSeq && len(res) < limit {
+			res = append(res, r)
+		}
+	}
+
+	return res, nil
+}
+
+func (l *revocationLog) LastRevocationSeq(context.Context) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.rows) == 0 {
+		return 0, nil
+	}
+
+	return l.rows[len(l.rows)-1].Seq, nil
+}
+
+// revocationStream - серверный поток, передающий отправленные сообщения в канал
+type revocationStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *ssov1.WatchRevocationsResponse
+}
+
+func (s *revocationStream) Context() context.Context { return s.ctx }
+
+func (s *revocationStream) Send(resp *ssov1.WatchRevocationsResponse) error {
+	s.sent <- resp
+	return nil
+}
+
+func watch(t *testing.T, log *revocationLog, since int64) (*revocations.Feed, *revocationStream, chan error) {
+	t.Helper()
+
+	feed := revocations.New(slog.New(slog.NewTextHandler(io.Discard, nil)), log, config.RevocationsConfig{
+		PollInterval: time.Hour, KeepaliveInterval: 50 * time.Millisecond, BufferSize: 16,
+	})
+	go func() { _ = feed.Run() }()
+	t.Cleanup(feed.Stop)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	stream := &revocationStream{ctx: ctx, sent: make(chan *ssov1.WatchRevocationsResponse, 16)}
+	done := make(chan error, 1)
+	s := &serverAPI{revocations: feed}
+	go func() { done <- s.WatchRevocations(&ssov1.WatchRevocationsRequest{SinceSequence: since}, stream) }()
+
+	return feed, stream, done
+}
+
+func nextRevocation(t *testing.T, stream *revocationStream) int64 {
+	t.Helper()
+
+	for {
+		select {
+		case resp := <-stream.sent:
+			if r := resp.GetRevocation(); r != nil {
+				return r.GetSequence()
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("no revocation received")
+		}
+	}
+}
+
+func TestWatchRevocations_ReplayThenTail(t *testing.T) {
+	log := &revocationLog{}
+	for seq := int64(1); seq <= 3; seq++ {
+		log.add(seq)
+	}
+
+	feed, stream, done := watch(t, log, 1)
+
+	assert.Equal(t, int64(2), nextRevocation(t, stream))
+	assert.Equal(t, int64(3), nextRevocation(t, stream))
+
+	log.add(4)
+	feed.Notify()
+	assert.Equal(t, int64(4), nextRevocation(t, stream))
+
+	// В тишине поток шлёт keepalive с последним отправленным sequence
+	select {
+	case resp := <-stream.sent:
+		require.NotNil(t, resp.GetKeepalive())
+		assert.Equal(t, int64(4), resp.GetKeepalive().GetSequence())
+	case <-time.After(5 * time.Second):
+		t.Fatal("no keepalive")
+	}
+
+	// Остановка сервера закрывает поток, клиент переподключается
+	feed.Stop()
+	select {
+	case err := <-done:
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream was not closed on shutdown")
+	}
+}
+
+func TestWatchRevocations_HistoryGone(t *testing.T) {
+	log := &revocationLog{}
+	log.add(5) // записи 1-4 уже удалены
+
+	_, _, done := watch(t, log, 2)
+
+	select {
+	case err := <-done:
+		assert.Equal(t, codes.OutOfRange, status.Code(err))
+	case <-time.After(5 * time.Second):
+		t.Fatal("stream was not rejected")
+	}
+}
@@ -3,41 +3,267 @@ package auth
 import (
 	"context"
 	"errors"
+	"sso/internal/buildinfo"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/features"
 	"sso/internal/services/auth"
+	"sso/migrations"
+	"time"
 
 	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 // Auth - интерфейс, который определяет методы аутентификации
 type Auth interface {
-	// Login - выполняет вход пользователя по email и паролю. Возвращает токен, если вход успешный, или ошибку, если нет
-	Login(ctx context.Context, email string, password string, appID int) (token string, err error)
-
-	// RegisterNewUser - регистрирует нового пользователя. Возвращает ID нового пользователя или ошибку
-	RegisterNewUser(ctx context.Context, email string, password string) (userID int64, err error)
+	// Login - выполняет вход пользователя по email и паролю (в организацию orgSlug, если он не пуст);
+	// непустой acceptedTOS - версия условий использования, принятая при входе; captchaToken - токен CAPTCHA.
+	// Возвращает токен, если вход успешный, или ошибку, если нет
+	Login(
+		ctx context.Context,
+		email string,
+		password string,
+		appID int,
+		orgSlug string,
+		acceptedTOS string,
+		captchaToken string,
+	) (token models.Token, err error)
+
+	// RegisterNewUser - регистрирует нового пользователя (в организации orgSlug, если он не пуст),
+	// принявшего версию условий использования acceptedTOS; captchaToken - токен CAPTCHA;
+	// appID - приложение, через которое он регистрируется (0 - без приложения).
+	// Возвращает ID нового пользователя или ошибку
+	RegisterNewUser(
+		ctx context.Context,
+		email string,
+		password string,
+		orgSlug string,
+		acceptedTOS string,
+		captchaToken string,
+		appID int,
+	) (userID int64, err error)
 
 	// IsAdmin - проверяет, является ли пользователь администратором. Возвращает true, если да, и false, если нет
 	IsAdmin(ctx context.Context, userID int64) (bool, error)
 
 	IsUserExists(ctx context.Context, userID int64) (bool, error)
+
+	// UsersBatch - получает пользователей по списку id одним запросом. Отсутствующих id в результате нет
+	UsersBatch(ctx context.Context, userIDs []int64) (map[int64]models.User, error)
+
+	// ExportUserData - выгружает всё, что хранится о пользователе, в JSON
+	ExportUserData(ctx context.Context, userID int64) ([]byte, error)
+
+	// EraseUser - обезличивает пользователя
+	EraseUser(ctx context.Context, userID int64) error
+
+	// RequestAccountDeletion - назначает удаление аккаунта после срока отмены
+	RequestAccountDeletion(ctx context.Context, userID int64) (models.AccountDeletion, error)
+
+	// CancelAccountDeletion - отменяет назначенное удаление по токену из письма или по userID
+	CancelAccountDeletion(ctx context.Context, token string, userID int64) (int64, error)
+
+	// ChangePassword - меняет пароль пользователя после проверки текущего
+	ChangePassword(ctx context.Context, email, oldPassword, newPassword string) error
+
+	// Authenticate - проверяет токен и возвращает его владельца
+	Authenticate(ctx context.Context, token string) (authctx.Principal, error)
+
+	// AcceptInvitation - создаёт пользователя по приглашению и выдаёт ему токен
+	AcceptInvitation(ctx context.Context, token, password string) (userID int64, t models.Token, err error)
+
+	// GrantConsent - записывает согласие пользователя для стороннего приложения
+	GrantConsent(ctx context.Context, userID int64, appID int, scopes []string) (models.Consent, error)
+
+	// ListConsents - согласия пользователя, включая отозванные
+	ListConsents(ctx context.Context, userID int64) ([]models.Consent, error)
+
+	// RevokeConsent - отзывает согласие и вместе с ним токены приложения
+	RevokeConsent(ctx context.Context, userID int64, appID int) error
+
+	// AcceptTOS - записывает принятие текущей версии условий использования
+	AcceptTOS(ctx context.Context, userID int64, version string) error
+
+	// TOSVersion - текущая версия условий использования (пусто - не учитываются)
+	TOSVersion() string
+
+	// AppMetadata - метаданные пользователя в пространстве приложения
+	AppMetadata(ctx context.Context, userID int64, appID int) ([]byte, error)
+
+	// UpdateAppMetadata - применяет JSON Merge Patch к метаданным приложения
+	UpdateAppMetadata(ctx context.Context, userID int64, appID int, patch []byte) ([]byte, error)
+
+	// CreateGuest - создаёт гостя и выдаёт ему токен приложения
+	CreateGuest(ctx context.Context, appID int) (userID int64, t models.Token, err error)
+
+	// UpgradeGuest - задаёт гостю email и пароль и выдаёт обычный токен
+	UpgradeGuest(
+		ctx context.Context,
+		guestToken string,
+		email string,
+		password string,
+		acceptedTOS string,
+	) (userID int64, t models.Token, err error)
+
+	// StartDeviceAuthorization - начинает вход на устройстве и возвращает его коды
+	StartDeviceAuthorization(ctx context.Context, appID int) (models.DeviceCodes, error)
+
+	// ApproveDevice - пользователь разрешает вход на устройстве с кодом userCode
+	ApproveDevice(ctx context.Context, userID int64, userCode string) error
+
+	// DenyDevice - пользователь отказывает во входе на устройстве с кодом userCode
+	DenyDevice(ctx context.Context, userID int64, userCode string) error
+
+	// PollDeviceToken - токен устройства, если пользователь разрешил вход
+	PollDeviceToken(ctx context.Context, deviceCode string) (models.Token, error)
+
+	// StepUp - подтверждает вход вторым фактором и выдаёт замену токена
+	StepUp(ctx context.Context, currentToken string, code string) (models.Token, error)
+
+	// RefreshToken - меняет refresh-токен на новый токен доступа и новый refresh-токен
+	RefreshToken(ctx context.Context, refreshToken string) (models.Token, error)
+
+	// BeginPasskeyRegistration - начинает регистрацию passkey пользователя
+	BeginPasskeyRegistration(ctx context.Context, userID int64) (models.PasskeyOptions, error)
+
+	// FinishPasskeyRegistration - проверяет ответ аутентификатора и сохраняет passkey
+	FinishPasskeyRegistration(ctx context.Context, userID int64, sessionID, name string, credentialJSON []byte) (int64, error)
+
+	// BeginPasskeyLogin - начинает вход по passkey (пустой email - любая passkey сайта)
+	BeginPasskeyLogin(ctx context.Context, email string) (models.PasskeyOptions, error)
+
+	// FinishPasskeyLogin - проверяет подпись аутентификатора и выдаёт токен приложения
+	FinishPasskeyLogin(
+		ctx context.Context,
+		sessionID string,
+		credentialJSON []byte,
+		appID int,
+		acceptedTOS string,
+	) (models.Token, error)
+
+	// ListPasskeys - passkey пользователя
+	ListPasskeys(ctx context.Context, userID int64) ([]models.Passkey, error)
+
+	// DeletePasskey - удаляет passkey пользователя
+	DeletePasskey(ctx context.Context, userID, id int64) error
+
+	// IssueOfflineToken - выдаёт offline-токен фонового агента после свежего входа со вторым фактором
+	IssueOfflineToken(
+		ctx context.Context,
+		caller authctx.Principal,
+		userID int64,
+		appID int,
+		label string,
+	) (models.OfflineToken, models.Token, error)
+
+	// ListOfflineTokens - действующие offline-токены пользователя
+	ListOfflineTokens(ctx context.Context, userID int64) ([]models.OfflineToken, error)
+
+	// RevokeOfflineToken - отзывает один offline-токен пользователя
+	RevokeOfflineToken(ctx context.Context, userID int64, id string) error
+
+	// SendSMSCode - отправляет код вызова и возвращает срок его действия
+	SendSMSCode(ctx context.Context, challengeToken string) (time.Time, error)
+
+	// VerifySMSCode - завершает вход кодом из SMS
+	VerifySMSCode(ctx context.Context, challengeToken, code string) (models.Token, error)
+
+	// StartPhoneVerification - отправляет код на новый номер пользователя
+	StartPhoneVerification(ctx context.Context, userID int64, phone string) (models.MFAChallenge, error)
+
+	// ConfirmPhone - сохраняет номер, подтверждённый кодом
+	ConfirmPhone(ctx context.Context, userID int64, challengeToken, code string) (string, error)
+
+	// RemovePhone - удаляет номер пользователя (acr - уровень входа токена вызывающего)
+	RemovePhone(ctx context.Context, userID int64, acr string) error
+
+	// ProvisionUser - создаёт или обновляет пользователя внешней системы (created - создан)
+	ProvisionUser(
+		ctx context.Context,
+		clientID int,
+		clientSecret, externalID, email string,
+		attributes map[string]string,
+	) (user models.ProvisionedUser, created bool, err error)
+
+	// DeprovisionUser - отключает пользователя внешней системы, отзывает его сессии и токены и возвращает его id
+	DeprovisionUser(ctx context.Context, clientID int, clientSecret, externalID string) (int64, error)
+
+	// ListProvisionedUsers - пользователи внешней системы, изменённые не раньше since, по возрастанию id после afterID
+	ListProvisionedUsers(
+		ctx context.Context,
+		clientID int,
+		clientSecret string,
+		since time.Time,
+		afterID int64,
+		limit int,
+	) ([]models.ProvisionedUser, error)
+}
+
+// SchemaProvider - источник версии схемы БД для GetServerInfo
+type SchemaProvider interface {
+	SchemaVersion(ctx context.Context) (version int64, dirty bool, err error)
 }
 
 // serverAPI - структура, которая обрабатывает все входящие gRPC-запросы
 type serverAPI struct {
-	ssov1.UnimplementedAuthServer // Встраиваемая структура с заглушками для gRPC-методов
-	auth Auth                     // Поле для использования интерфейса аутентификации
+	ssov1.UnimplementedAuthServer                // Встраиваемая структура с заглушками для gRPC-методов
+	auth                          Auth           // Поле для использования интерфейса аутентификации
+	schema                        SchemaProvider // Версия схемы БД (может быть nil)
+	revocations                   RevocationFeed // Поток отзывов (nil - WatchRevocations недоступен)
+	maintenance                   Maintenance    // Режим обслуживания для GetServerInfo (может быть nil)
+	features                      FeatureFlags   // Флаги для GetServerInfo (может быть nil)
+}
+
+// FeatureFlags - где сейчас включены флаги постепенного включения поведения (features.Set)
+type FeatureFlags interface {
+	States() []features.State
+}
+
+// Maintenance - включён ли режим обслуживания (maintenance.Mode)
+type Maintenance interface {
+	Enabled() bool
 }
 
 const (
 	emptyValue = 0
+
+	tokenTypeBearer = "Bearer" // тип выдаваемых токенов (RFC 6750)
+
+	overloadedRetryDelay = time.Second // через сколько клиенту советуют повторить запрос при перегрузке
+)
+
+// Детали ошибок (google.rpc.ErrorInfo): по ним клиент отличает истёкший пароль от неверного
+// и переходит к ChangePassword, а истёкшее приглашение - от уже использованного.
+// Полный каталог причин - в пакете errinfo, здесь - имена, которыми уже пользуются
+const (
+	ErrorDomain               = errinfo.Domain
+	ReasonPasswordExpired     = errinfo.ReasonPasswordExpired
+	ReasonInvitationUsed      = errinfo.ReasonInvitationUsed
+	ReasonInvitationExpired   = errinfo.ReasonInvitationExpired
+	ReasonConsentRequired     = errinfo.ReasonConsentRequired
+	ReasonSessionLimitReached = errinfo.ReasonSessionLimitReached
+	ReasonSessionExpired      = errinfo.ReasonSessionExpired
+	ReasonReloginRequired     = errinfo.ReasonReloginRequired
+	ReasonSlowConsumer        = errinfo.ReasonSlowConsumer
+
+	ReasonTOSAcceptanceRequired   = errinfo.ReasonTOSAcceptanceRequired
+	ReasonTOSReacceptanceRequired = errinfo.ReasonTOSReacceptanceRequired
+
+	ReasonAuthorizationPending = errinfo.ReasonAuthorizationPending
+	ReasonSlowDown             = errinfo.ReasonSlowDown
+	ReasonAccessDenied         = errinfo.ReasonAccessDenied
+	ReasonDeviceCodeExpired    = errinfo.ReasonDeviceCodeExpired
 )
 
 // метод для регистрации сервера авторизации, регистрирует обработчик
-func RegisterAuthServer(gRPC *grpc.Server, auth Auth) {
-	ssov1.RegisterAuthServer(gRPC, &serverAPI{auth: auth})
+func RegisterAuthServer(gRPC *grpc.Server, auth Auth, schema SchemaProvider, feed RevocationFeed, maintenance Maintenance, flags FeatureFlags) {
+	ssov1.RegisterAuthServer(gRPC, &serverAPI{auth: auth, schema: schema, revocations: feed, maintenance: maintenance, features: flags})
 }
 
 func (s *serverAPI) Login(ctx context.Context, req *ssov1.LoginRequest) (*ssov1.LoginResponse, error) {
@@ -45,16 +271,76 @@ func (s *serverAPI) Login(ctx context.Context, req *ssov1.LoginRequest) (*ssov1.
 		return nil, err
 	}
 
-	token, err := s.auth.Login(ctx, req.GetEmail(), req.GetPassword(), int(req.GetAppId()))
+	token, err := s.auth.Login(
+		ctx, req.GetEmail(), req.GetPassword(), int(req.GetAppId()), req.GetOrg(), req.GetAcceptedTosVersion(), req.GetCaptchaToken())
 	if err != nil {
 		if errors.Is(err, auth.ErrInvalidCredentials) {
-			return nil, status.Error(codes.InvalidArgument, "invalid email or password")
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid email or password")
+		}
+
+		if st := captchaStatus(err); st != nil {
+			return nil, st
+		}
+
+		if errors.Is(err, auth.ErrOrgNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "organization not found")
+		}
+
+		if errors.Is(err, auth.ErrPasswordExpired) {
+			return nil, passwordExpiredStatus()
+		}
+
+		if errors.Is(err, auth.ErrConsentRequired) {
+			return nil, failedPrecondition("user has not consented to share data with this app", ReasonConsentRequired)
+		}
+
+		if errors.Is(err, auth.ErrTOSReacceptanceRequired) {
+			return nil, s.tosStatus("terms of service have changed, accept the current version", ReasonTOSReacceptanceRequired)
+		}
+
+		if st := policyDeniedStatus(err); st != nil {
+			return nil, st
+		}
+
+		if errors.Is(err, auth.ErrTooManySessions) {
+			return nil, failedPrecondition("too many concurrent sessions for this app, sign out elsewhere first", ReasonSessionLimitReached)
+		}
+
+		if errors.Is(err, auth.ErrInvalidAppID) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+		}
+
+		if errors.Is(err, auth.ErrOverloaded) {
+			return nil, overloadedStatus()
 		}
 
 		return nil, status.Error(codes.Internal, "failed to login")
 	}
 
-	return &ssov1.LoginResponse{Token: token}, nil
+	if c := token.MFAChallenge; c != nil {
+		return &ssov1.LoginResponse{
+			MfaRequired:       true,
+			MfaChallengeToken: c.Token,
+			PhoneHint:         c.PhoneHint,
+		}, nil
+	}
+
+	resp := &ssov1.LoginResponse{
+		Token:     token.AccessToken,
+		ExpiresIn: token.ExpiresAt.Unix() - time.Now().Unix(), // exp в токене хранится с точностью до секунды
+		TokenType: tokenTypeBearer,
+		Scopes:    token.Scopes,
+
+		TosReacceptanceRequired: token.TOSReacceptanceRequired,
+		StepUpRequired:          token.StepUpRequired,
+		RefreshToken:            token.RefreshToken,
+		RefreshExpiresIn:        refreshExpiresIn(token),
+	}
+	if !token.DeletionScheduledAt.IsZero() {
+		resp.DeletionScheduledAt = token.DeletionScheduledAt.Unix()
+	}
+
+	return resp, nil
 }
 
 func (s *serverAPI) Register(ctx context.Context, req *ssov1.RegisterRequest) (*ssov1.RegisterResponse, error) {
@@ -62,10 +348,52 @@ func (s *serverAPI) Register(ctx context.Context, req *ssov1.RegisterRequest) (*
 		return nil, err
 	}
 
-	userID, err := s.auth.RegisterNewUser(ctx, req.GetEmail(), req.GetPassword())
+	userID, err := s.auth.RegisterNewUser(
+		ctx, req.GetEmail(), req.GetPassword(), req.GetOrg(), req.GetAcceptedTosVersion(), req.GetCaptchaToken(),
+		int(req.GetAppId()))
 	if err != nil {
+		if st := captchaStatus(err); st != nil {
+			return nil, st
+		}
+
 		if errors.Is(err, auth.ErrUserExists) {
-			return nil, status.Error(codes.AlreadyExists, "user already exists")
+			return nil, errinfo.FromService(err, codes.AlreadyExists, "user already exists")
+		}
+
+		if errors.Is(err, auth.ErrOrgNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "organization not found")
+		}
+
+		if errors.Is(err, auth.ErrSignupClosed) {
+			return nil, errinfo.FromService(err, codes.PermissionDenied, "organization is invite-only")
+		}
+
+		if errors.Is(err, auth.ErrAppRequired) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "app_id is required")
+		}
+
+		if errors.Is(err, auth.ErrInvalidAppID) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+		}
+
+		if errors.Is(err, auth.ErrWeakPassword) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "password has appeared in a data breach, choose another one")
+		}
+
+		if errors.Is(err, auth.ErrPasswordTooLong) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "password must not be longer than 72 bytes")
+		}
+
+		if errors.Is(err, auth.ErrDomainNotAllowed) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "registration with this email domain is not allowed")
+		}
+
+		if errors.Is(err, auth.ErrTOSNotAccepted) {
+			return nil, s.tosStatus("the current terms of service must be accepted", ReasonTOSAcceptanceRequired)
+		}
+
+		if errors.Is(err, auth.ErrOverloaded) {
+			return nil, overloadedStatus()
 		}
 
 		return nil, status.Error(codes.Internal, "internal error")
@@ -84,7 +412,7 @@ func (s *serverAPI) IsAdmin(ctx context.Context, req *ssov1.IsAdminRequest) (*ss
 	isAdmin, err := s.auth.IsAdmin(ctx, req.GetUserId())
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, errinfo.FromService(err, codes.NotFound, "user not found")
 		}
 
 		return nil, status.Error(codes.Internal, "internal error")
@@ -98,17 +426,280 @@ func (s *serverAPI) IsUserExists(ctx context.Context, req *ssov1.IsUserExistsReq
 		return nil, status.Error(codes.InvalidArgument, "user_id is required")
 	}
 
+	// Отсутствующий пользователь - exists: false, а не NotFound
 	isUserExists, err := s.auth.IsUserExists(ctx, req.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.IsUserExistsResponse{Exists: isUserExists}, nil
+}
+
+// GetUsersBatch - пакетная версия IsUserExists: отвечает по каждому запрошенному id,
+// отсутствующие пользователи явно помечаются exists = false
+func (s *serverAPI) GetUsersBatch(ctx context.Context, req *ssov1.GetUsersBatchRequest) (*ssov1.GetUsersBatchResponse, error) {
+	ids := req.GetUserIds()
+	if len(ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "user_ids is required")
+	}
+
+	found, err := s.auth.UsersBatch(ctx, ids)
+	if err != nil {
+		if errors.Is(err, auth.ErrTooManyIDs) {
+			return nil, errinfo.Newf(codes.InvalidArgument, errinfo.ReasonTooManyIDs, "at most %d user_ids per call", auth.MaxBatchSize)
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	users := make(map[int64]*ssov1.UserInfo, len(ids))
+	for _, id := range ids {
+		u, ok := found[id]
+		if !ok {
+			users[id] = &ssov1.UserInfo{Exists: false}
+			continue
+		}
+
+		users[id] = &ssov1.UserInfo{
+			Exists:   true,
+			Email:    u.Email,
+			IsAdmin:  u.IsAdmin,
+			IsActive: u.IsActive,
+		}
+	}
+
+	return &ssov1.GetUsersBatchResponse{Users: users}, nil
+}
+
+// ExportUserData - выгрузка данных пользователя. Доступна администратору или самому пользователю
+func (s *serverAPI) ExportUserData(ctx context.Context, req *ssov1.ExportUserDataRequest) (*ssov1.ExportUserDataResponse, error) {
+	if req.GetUserId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	// Интерцептор уже проверил токен; здесь проверяем, чьи данные запрошены
+	principal, ok := authctx.From(ctx)
+	if !ok || (!principal.IsAdmin && principal.UserID != req.GetUserId()) {
+		return nil, status.Error(codes.PermissionDenied, "can only export own data")
+	}
+
+	data, err := s.auth.ExportUserData(ctx, req.GetUserId())
 	if err != nil {
 		if errors.Is(err, auth.ErrUserNotFound) {
-			return nil, status.Error(codes.NotFound, "user not found")
+			return nil, errinfo.FromService(err, codes.NotFound, "user not found")
 		}
 
 		return nil, status.Error(codes.Internal, "internal error")
 	}
 
+	return &ssov1.ExportUserDataResponse{Data: data}, nil
+}
 
-	return &ssov1.IsUserExistsResponse{Exists: isUserExists}, nil
+// EraseUser - обезличивание пользователя (только администратор, проверяет интерцептор)
+func (s *serverAPI) EraseUser(ctx context.Context, req *ssov1.EraseUserRequest) (*ssov1.EraseUserResponse, error) {
+	if req.GetUserId() == emptyValue {
+		return nil, status.Error(codes.InvalidArgument, "user_id is required")
+	}
+
+	if err := s.auth.EraseUser(ctx, req.GetUserId()); err != nil {
+		if errors.Is(err, auth.ErrUserNotFound) {
+			return nil, errinfo.FromService(err, codes.NotFound, "user not found")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.EraseUserResponse{}, nil
+}
+
+func (s *serverAPI) ChangePassword(ctx context.Context, req *ssov1.ChangePasswordRequest) (*ssov1.ChangePasswordResponse, error) {
+	if err := validateChangePassword(req); err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.ChangePassword(ctx, req.GetEmail(), req.GetOldPassword(), req.GetNewPassword()); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid email or password")
+		case errors.Is(err, auth.ErrPasswordReused):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "new password must not match a recently used one")
+		case errors.Is(err, auth.ErrWeakPassword):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "password has appeared in a data breach, choose another one")
+		case errors.Is(err, auth.ErrPasswordTooLong):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "password must not be longer than 72 bytes")
+		case errors.Is(err, auth.ErrOverloaded):
+			return nil, overloadedStatus()
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.ChangePasswordResponse{}, nil
+}
+
+// ValidateToken - проверка чужого токена сервисом-потребителем. Публичный метод:
+// сам токен и есть доказательство, поэтому bearer-токен вызывающего не нужен
+func (s *serverAPI) ValidateToken(ctx context.Context, req *ssov1.ValidateTokenRequest) (*ssov1.ValidateTokenResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+
+	principal, err := s.auth.Authenticate(ctx, req.GetToken())
+	if err != nil {
+		if errors.Is(err, auth.ErrSessionExpired) {
+			return nil, withReason(codes.Unauthenticated, "session expired, sign in again", ReasonSessionExpired)
+		}
+
+		if errors.Is(err, auth.ErrInvalidToken) {
+			return nil, errinfo.FromService(err, codes.Unauthenticated, "invalid token")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	resp := &ssov1.ValidateTokenResponse{
+		UserId:  principal.UserID,
+		Email:   principal.Email,
+		AppId:   int32(principal.AppID),
+		IsAdmin: principal.IsAdmin,
+		OrgId:   principal.OrgID,
+		OrgRole: principal.OrgRole,
+		Amr:     principal.AMR,
+		Acr:     principal.ACR,
+	}
+	if !principal.ExpiresAt.IsZero() {
+		resp.ExpiresAt = principal.ExpiresAt.Unix()
+	}
+	if !principal.AuthTime.IsZero() {
+		resp.AuthTime = principal.AuthTime.Unix()
+	}
+
+	return resp, nil
+}
+
+// AcceptInvitation - принятие приглашения. Публичный метод: доказательство - токен из письма
+func (s *serverAPI) AcceptInvitation(
+	ctx context.Context,
+	req *ssov1.AcceptInvitationRequest,
+) (*ssov1.AcceptInvitationResponse, error) {
+	if req.GetToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token is required")
+	}
+	if req.GetPassword() == "" {
+		return nil, status.Error(codes.InvalidArgument, "password is required")
+	}
+
+	userID, token, err := s.auth.AcceptInvitation(ctx, req.GetToken(), req.GetPassword())
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvitationNotFound):
+			return nil, errinfo.FromService(err, codes.NotFound, "invitation not found")
+		case errors.Is(err, auth.ErrInvitationExpired):
+			return nil, failedPrecondition("invitation expired, ask for a new one", ReasonInvitationExpired)
+		case errors.Is(err, auth.ErrInvitationUsed):
+			return nil, failedPrecondition("invitation is already accepted or revoked", ReasonInvitationUsed)
+		case errors.Is(err, auth.ErrUserExists):
+			return nil, errinfo.FromService(err, codes.AlreadyExists, "user already exists")
+		case errors.Is(err, auth.ErrWeakPassword):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "password has appeared in a data breach, choose another one")
+		case errors.Is(err, auth.ErrPasswordTooLong):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "password must not be longer than 72 bytes")
+		case errors.Is(err, auth.ErrInvitationsDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "invitations are disabled")
+		case errors.Is(err, auth.ErrOverloaded):
+			return nil, overloadedStatus()
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.AcceptInvitationResponse{
+		UserId:    userID,
+		Token:     token.AccessToken,
+		ExpiresIn: token.ExpiresAt.Unix() - time.Now().Unix(),
+	}, nil
+}
+
+// GetServerInfo - отдаёт версию сборки, версию схемы БД и ожидаемую сборкой версию, признак режима обслуживания
+// и где включены флаги поведения. Не требует авторизации, поэтому сюда нельзя добавлять другие значения
+// конфигурации (и причину режима обслуживания): флаги - исключение, по ним клиенты сверяют, какое поведение их ждёт
+func (s *serverAPI) GetServerInfo(ctx context.Context, _ *ssov1.GetServerInfoRequest) (*ssov1.GetServerInfoResponse, error) {
+	info := buildinfo.Get()
+
+	resp := &ssov1.GetServerInfoResponse{
+		Version:               info.Version,
+		Commit:                info.Commit,
+		BuildDate:             info.BuildDate,
+		GoVersion:             info.GoVersion,
+		ExpectedSchemaVersion: migrations.Latest(),
+	}
+	if s.maintenance != nil {
+		resp.Maintenance = s.maintenance.Enabled()
+	}
+	if s.features != nil {
+		for _, f := range s.features.States() {
+			flag := &ssov1.FeatureFlag{Name: string(f.Name), Enabled: f.Enabled, Percent: int32(f.Percent)}
+			for _, id := range f.AppIDs {
+				flag.AppIds = append(flag.AppIds, int32(id))
+			}
+			resp.Features = append(resp.Features, flag)
+		}
+	}
+
+	// Неизвестная версия схемы (например, БД не мигрирована) - не повод отказывать в ответе
+	if s.schema != nil {
+		if version, dirty, err := s.schema.SchemaVersion(ctx); err == nil {
+			resp.SchemaVersion = version
+			resp.SchemaDirty = dirty
+		}
+	}
+
+	return resp, nil
+}
+
+// passwordExpiredStatus - FailedPrecondition с ErrorInfo{Reason: PASSWORD_EXPIRED}
+func passwordExpiredStatus() error {
+	return failedPrecondition("password expired, change it with ChangePassword", ReasonPasswordExpired)
+}
+
+// captchaStatus - статус ошибки CAPTCHA (nil, если err не про неё). По причине клиент понимает,
+// что нужно показать виджет: AUTH_CAPTCHA_REQUIRED - токена не было, AUTH_CAPTCHA_INVALID - он не принят
+func captchaStatus(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrCaptchaRequired):
+		return errinfo.FromService(err, codes.FailedPrecondition, "captcha required, complete the challenge and retry")
+	case errors.Is(err, auth.ErrCaptchaInvalid):
+		return errinfo.FromService(err, codes.FailedPrecondition, "captcha verification failed, complete the challenge again")
+	case errors.Is(err, auth.ErrCaptchaUnavailable):
+		return errinfo.FromService(err, codes.Unavailable, "captcha verification is unavailable, retry later")
+	default:
+		return nil
+	}
+}
+
+// failedPrecondition - FailedPrecondition с ErrorInfo{Reason: reason}
+func failedPrecondition(msg, reason string) error {
+	return withReason(codes.FailedPrecondition, msg, reason)
+}
+
+// withReason - статус code с ErrorInfo{Reason: reason}
+func withReason(code codes.Code, msg, reason string) error {
+	return errinfo.New(code, msg, reason)
+}
+
+// overloadedStatus - ResourceExhausted с RetryInfo: проверка пароля отклонена из-за перегрузки, запрос можно повторить
+func overloadedStatus() error {
+	st := status.New(codes.ResourceExhausted, "server is overloaded, retry later")
+
+	withDetails, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: errinfo.ReasonOverloaded, Domain: ErrorDomain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(overloadedRetryDelay)},
+	)
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
 }
 
 func validateLogin(req *ssov1.LoginRequest) error {
@@ -136,6 +727,26 @@ func validateRegister(req *ssov1.RegisterRequest) error {
 		return status.Error(codes.InvalidArgument, "password is required")
 	}
 
+	if req.GetAppId() < 0 {
+		return status.Error(codes.InvalidArgument, "app_id must not be negative")
+	}
+
+	return nil
+}
+
+func validateChangePassword(req *ssov1.ChangePasswordRequest) error {
+	if req.GetEmail() == "" {
+		return status.Error(codes.InvalidArgument, "email is required")
+	}
+
+	if req.GetOldPassword() == "" {
+		return status.Error(codes.InvalidArgument, "old_password is required")
+	}
+
+	if req.GetNewPassword() == "" {
+		return status.Error(codes.InvalidArgument, "new_password is required")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,468 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sso/internal/domain/models"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/features"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth"
+	"sso/migrations"
+	"strconv"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeAuth - сервис авторизации, выдающий настоящие токены без хранилища
+type fakeAuth struct {
+	Auth
+	app        models.App
+	ttl        time.Duration
+	refreshTTL time.Duration // не ноль - вместе с токеном выдаётся refresh-токен на этот срок
+}
+
+func (f fakeAuth) Login(_ context.Context, email string, _ string, _ int, _ string, _ string, _ string) (models.Token, error) {
+	return f.issue(email)
+}
+
+func (f fakeAuth) RefreshToken(context.Context, string) (models.Token, error) {
+	return f.issue("a@b.c")
+}
+
+func (f fakeAuth) issue(email string) (models.Token, error) {
+	token, claims, err := jwt.Issue(models.User{ID: 1, Email: email}, f.app, f.ttl)
+	if err != nil {
+		return models.Token{}, err
+	}
+
+	t := models.Token{AccessToken: token, ExpiresAt: claims.ExpiresAt.Time}
+	if f.refreshTTL > 0 {
+		t.RefreshToken, t.RefreshExpiresAt = "family.secret", claims.IssuedAt.Add(f.refreshTTL)
+	}
+
+	return t, nil
+}
+
+func TestLogin_ExpiresInMatchesToken(t *testing.T) {
+	app := models.App{ID: 1, Secret: "test-secret"}
+	s := &serverAPI{auth: fakeAuth{app: app, ttl: 90 * time.Minute}}
+
+	resp, err := s.Login(context.Background(), &ssov1.LoginRequest{Email: "a@b.c", Password: "p", AppId: 1})
+	require.NoError(t, err)
+	now := time.Now()
+
+	assert.Equal(t, "Bearer", resp.GetTokenType())
+	assert.Equal(t, int64(90*60), resp.GetExpiresIn())
+
+	claims, err := jwt.Parse(resp.GetToken(), []byte(app.Secret))
+	require.NoError(t, err)
+	assert.InDelta(t, claims.ExpiresAt.Unix(), now.Add(time.Duration(resp.GetExpiresIn())*time.Second).Unix(), 1)
+	assert.Empty(t, resp.GetRefreshToken())
+	assert.Zero(t, resp.GetRefreshExpiresIn(), "no refresh token, no refresh expiry")
+}
+
+func TestLoginAndRefresh_RefreshExpiresIn(t *testing.T) {
+	app := models.App{ID: 1, Secret: "test-secret"}
+	s := &serverAPI{auth: fakeAuth{app: app, ttl: 15 * time.Minute, refreshTTL: 7 * 24 * time.Hour}}
+
+	login, err := s.Login(context.Background(), &ssov1.LoginRequest{Email: "a@b.c", Password: "p", AppId: 1})
+	require.NoError(t, err)
+	refreshed, err := s.Refresh(context.Background(), &ssov1.RefreshRequest{RefreshToken: login.GetRefreshToken()})
+	require.NoError(t, err)
+
+	// Оба ответа считают сроки от тех же клеймов, что подписаны в токене: exp и refresh-окно не расходятся
+	for name, r := range map[string]interface {
+		GetToken() string
+		GetExpiresIn() int64
+		GetRefreshToken() string
+		GetRefreshExpiresIn() int64
+	}{"login": login, "refresh": refreshed} {
+		assert.Equal(t, "family.secret", r.GetRefreshToken(), name)
+		assert.Equal(t, int64(15*60), r.GetExpiresIn(), name)
+		assert.Equal(t, int64(7*24*60*60), r.GetRefreshExpiresIn(), name)
+
+		claims, err := jwt.Parse(r.GetToken(), []byte(app.Secret))
+		require.NoError(t, err, name)
+		assert.Equal(t, claims.IssuedAt.Add(7*24*time.Hour).Unix()-claims.ExpiresAt.Unix(),
+			r.GetRefreshExpiresIn()-r.GetExpiresIn(), name)
+	}
+}
+
+func (f fakeAuth) UsersBatch(_ context.Context, ids []int64) (map[int64]models.User, error) {
+	if len(ids) > auth.MaxBatchSize {
+		return nil, auth.ErrTooManyIDs
+	}
+
+	return map[int64]models.User{1: {ID: 1, Email: "a@b.c", IsActive: true}}, nil
+}
+
+// expiredAuth - сервис, у которого пароль любого пользователя истёк
+type expiredAuth struct{ Auth }
+
+func (expiredAuth) Login(context.Context, string, string, int, string, string, string) (models.Token, error) {
+	return models.Token{}, fmt.Errorf("Auth.Login: %w", auth.ErrPasswordExpired)
+}
+
+func TestLogin_PasswordExpiredDetail(t *testing.T) {
+	s := &serverAPI{auth: expiredAuth{}}
+
+	_, err := s.Login(context.Background(), &ssov1.LoginRequest{Email: "a@b.c", Password: "p", AppId: 1})
+	st := status.Convert(err)
+	require.Equal(t, codes.FailedPrecondition, st.Code())
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, ReasonPasswordExpired, info.GetReason())
+	assert.Equal(t, ErrorDomain, info.GetDomain())
+}
+
+// overloadedAuth - сервис, у которого пул хеширования всегда переполнен
+type overloadedAuth struct{ Auth }
+
+func (overloadedAuth) Login(context.Context, string, string, int, string, string, string) (models.Token, error) {
+	return models.Token{}, fmt.Errorf("Auth.Login: %w", auth.ErrOverloaded)
+}
+
+func TestLogin_Overloaded(t *testing.T) {
+	s := &serverAPI{auth: overloadedAuth{}}
+
+	_, err := s.Login(context.Background(), &ssov1.LoginRequest{Email: "a@b.c", Password: "p", AppId: 1})
+	st := status.Convert(err)
+	require.Equal(t, codes.ResourceExhausted, st.Code())
+
+	require.Len(t, st.Details(), 2)
+	assert.Equal(t, errinfo.ReasonOverloaded, errinfo.Reason(err))
+	info, ok := st.Details()[1].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, overloadedRetryDelay, info.GetRetryDelay().AsDuration())
+}
+
+func TestGetUsersBatch_ReportsMissing(t *testing.T) {
+	s := &serverAPI{auth: fakeAuth{}}
+
+	resp, err := s.GetUsersBatch(context.Background(), &ssov1.GetUsersBatchRequest{UserIds: []int64{1, 2}})
+	require.NoError(t, err)
+	require.Len(t, resp.GetUsers(), 2)
+
+	assert.True(t, resp.GetUsers()[1].GetExists())
+	assert.Equal(t, "a@b.c", resp.GetUsers()[1].GetEmail())
+	assert.False(t, resp.GetUsers()[2].GetExists())
+
+	_, err = s.GetUsersBatch(context.Background(), &ssov1.GetUsersBatchRequest{UserIds: make([]int64, auth.MaxBatchSize+1)})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func (f fakeAuth) ExportUserData(_ context.Context, userID int64) ([]byte, error) {
+	return []byte(`{"profile":{"id":` + strconv.FormatInt(userID, 10) + `}}`), nil
+}
+
+func TestExportUserData_AdminOrSelf(t *testing.T) {
+	s := &serverAPI{auth: fakeAuth{}}
+	req := &ssov1.ExportUserDataRequest{UserId: 5}
+
+	tests := []struct {
+		name      string
+		principal *authctx.Principal
+		want      codes.Code
+	}{
+		{name: "anonymous", want: codes.PermissionDenied},
+		{name: "other user", principal: &authctx.Principal{UserID: 6}, want: codes.PermissionDenied},
+		{name: "self", principal: &authctx.Principal{UserID: 5}, want: codes.OK},
+		{name: "admin", principal: &authctx.Principal{UserID: 1, IsAdmin: true}, want: codes.OK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.principal != nil {
+				ctx = authctx.Into(ctx, *tt.principal)
+			}
+
+			resp, err := s.ExportUserData(ctx, req)
+			require.Equal(t, tt.want, status.Code(err))
+			if err == nil {
+				assert.JSONEq(t, `{"profile":{"id":5}}`, string(resp.GetData()))
+			}
+		})
+	}
+}
+
+type fakeSchema struct {
+	version int64
+	dirty   bool
+	err     error
+}
+
+func (f fakeSchema) SchemaVersion(context.Context) (int64, bool, error) {
+	return f.version, f.dirty, f.err
+}
+
+func TestGetServerInfo(t *testing.T) {
+	s := &serverAPI{schema: fakeSchema{version: 2, dirty: true}}
+
+	resp, err := s.GetServerInfo(context.Background(), &ssov1.GetServerInfoRequest{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "dev", resp.GetVersion())
+	assert.Equal(t, runtime.Version(), resp.GetGoVersion())
+	assert.Equal(t, int64(2), resp.GetSchemaVersion())
+	assert.True(t, resp.GetSchemaDirty())
+	assert.Equal(t, migrations.Latest(), resp.GetExpectedSchemaVersion())
+}
+
+func TestGetServerInfo_Features(t *testing.T) {
+	flags := features.New(map[string]features.Rule{string(features.StrictScopes): {Percent: 10, AppIDs: []int{3}}})
+	s := &serverAPI{features: flags}
+
+	resp, err := s.GetServerInfo(context.Background(), &ssov1.GetServerInfoRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetFeatures(), len(features.All))
+
+	byName := make(map[string]*ssov1.FeatureFlag)
+	for _, f := range resp.GetFeatures() {
+		byName[f.GetName()] = f
+	}
+	strict := byName[string(features.StrictScopes)]
+	assert.Equal(t, int32(10), strict.GetPercent())
+	assert.Equal(t, []int32{3}, strict.GetAppIds())
+	assert.False(t, byName[string(features.RehashOnLogin)].GetEnabled())
+}
+
+func TestGetServerInfo_UnknownSchema(t *testing.T) {
+	s := &serverAPI{schema: fakeSchema{err: errors.New("no such table: migrations")}}
+
+	resp, err := s.GetServerInfo(context.Background(), &ssov1.GetServerInfoRequest{})
+	require.NoError(t, err)
+	assert.Zero(t, resp.GetSchemaVersion())
+}
+
+// consentAuth - сервис, у которого у пользователя нет согласия ни для одного стороннего приложения
+type consentAuth struct{ Auth }
+
+func (consentAuth) Login(context.Context, string, string, int, string, string, string) (models.Token, error) {
+	return models.Token{}, fmt.Errorf("Auth.Login: %w", auth.ErrConsentRequired)
+}
+
+func (consentAuth) GrantConsent(_ context.Context, userID int64, appID int, scopes []string) (models.Consent, error) {
+	return models.Consent{UserID: userID, AppID: appID, Scopes: scopes, GrantedAt: time.Now()}, nil
+}
+
+func TestLogin_ConsentRequiredDetail(t *testing.T) {
+	s := &serverAPI{auth: consentAuth{}}
+
+	_, err := s.Login(context.Background(), &ssov1.LoginRequest{Email: "a@b.c", Password: "p", AppId: 2})
+	st := status.Convert(err)
+	require.Equal(t, codes.FailedPrecondition, st.Code())
+
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, ReasonConsentRequired, info.GetReason())
+}
+
+func TestGrantConsent_Caller(t *testing.T) {
+	s := &serverAPI{auth: consentAuth{}}
+	req := &ssov1.GrantConsentRequest{UserId: 5, AppId: 2, Scopes: []string{"email"}}
+
+	tests := []struct {
+		name      string
+		principal authctx.Principal
+		want      codes.Code
+	}{
+		{name: "self", principal: authctx.Principal{UserID: 5}, want: codes.OK},
+		{name: "admin", principal: authctx.Principal{UserID: 1, IsAdmin: true}, want: codes.OK},
+		{name: "other user", principal: authctx.Principal{UserID: 6}, want: codes.PermissionDenied},
+		{name: "third-party app token", principal: authctx.Principal{UserID: 5, ThirdParty: true}, want: codes.PermissionDenied},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.GrantConsent(authctx.Into(context.Background(), tt.principal), req)
+			require.Equal(t, tt.want, status.Code(err))
+			if err == nil {
+				assert.Equal(t, []string{"email"}, resp.GetConsent().GetScopes())
+				assert.Zero(t, resp.GetConsent().GetRevokedAt())
+			}
+		})
+	}
+}
+
+// tosAuth - сервис, у которого текущая версия условий "2025-01" и её никто не принял
+type tosAuth struct{ Auth }
+
+func (tosAuth) Login(context.Context, string, string, int, string, string, string) (models.Token, error) {
+	return models.Token{}, fmt.Errorf("Auth.Login: %w", auth.ErrTOSReacceptanceRequired)
+}
+
+func (tosAuth) RegisterNewUser(context.Context, string, string, string, string, string, int) (int64, error) {
+	return 0, fmt.Errorf("auth.RegisterNewUser: %w", auth.ErrTOSNotAccepted)
+}
+
+func (tosAuth) TOSVersion() string { return "2025-01" }
+
+func TestTOSRequiredDetail(t *testing.T) {
+	s := &serverAPI{auth: tosAuth{}}
+
+	_, loginErr := s.Login(context.Background(), &ssov1.LoginRequest{Email: "a@b.c", Password: "p", AppId: 1})
+	_, registerErr := s.Register(context.Background(), &ssov1.RegisterRequest{Email: "a@b.c", Password: "p"})
+
+	for reason, err := range map[string]error{
+		ReasonTOSReacceptanceRequired: loginErr,
+		ReasonTOSAcceptanceRequired:   registerErr,
+	} {
+		st := status.Convert(err)
+		require.Equal(t, codes.FailedPrecondition, st.Code())
+
+		require.Len(t, st.Details(), 1)
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, reason, info.GetReason())
+		assert.Equal(t, "2025-01", info.GetMetadata()["current_version"])
+	}
+}
+
+// metadataAuth - сервис, возвращающий, чьи метаданные запрошены
+type metadataAuth struct{ Auth }
+
+func (metadataAuth) AppMetadata(_ context.Context, userID int64, appID int) ([]byte, error) {
+	return []byte(fmt.Sprintf(`{"user":%d,"app":%d}`, userID, appID)), nil
+}
+
+func TestGetUserMetadata_Scope(t *testing.T) {
+	s := &serverAPI{auth: metadataAuth{}}
+
+	tests := []struct {
+		name      string
+		principal authctx.Principal
+		req       *ssov1.GetUserMetadataRequest
+		want      codes.Code
+		wantData  string
+	}{
+		{
+			name:      "own metadata of token app",
+			principal: authctx.Principal{UserID: 5, AppID: 3},
+			req:       &ssov1.GetUserMetadataRequest{UserId: 5},
+			wantData:  `{"user":5,"app":3}`,
+		},
+		{
+			name:      "another app",
+			principal: authctx.Principal{UserID: 5, AppID: 3},
+			req:       &ssov1.GetUserMetadataRequest{UserId: 5, AppId: 4},
+			want:      codes.PermissionDenied,
+		},
+		{
+			name:      "another user",
+			principal: authctx.Principal{UserID: 5, AppID: 3},
+			req:       &ssov1.GetUserMetadataRequest{UserId: 6},
+			want:      codes.PermissionDenied,
+		},
+		{
+			name:      "admin",
+			principal: authctx.Principal{UserID: 1, AppID: 3, IsAdmin: true},
+			req:       &ssov1.GetUserMetadataRequest{UserId: 6, AppId: 4},
+			wantData:  `{"user":6,"app":4}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := s.GetUserMetadata(authctx.Into(context.Background(), tt.principal), tt.req)
+			require.Equal(t, tt.want, status.Code(err))
+			if err == nil {
+				assert.JSONEq(t, tt.wantData, string(resp.GetMetadata()))
+			}
+		})
+	}
+}
+
+// missingUserAuth - сервис, в котором нет ни одного пользователя
+type missingUserAuth struct{ Auth }
+
+func (missingUserAuth) IsAdmin(context.Context, int64) (bool, error) {
+	return false, fmt.Errorf("Auth.IsAdmin: %w", auth.ErrUserNotFound)
+}
+
+func (missingUserAuth) IsUserExists(context.Context, int64) (bool, error) {
+	return false, nil
+}
+
+func TestMissingUser_ErrorMapping(t *testing.T) {
+	s := &serverAPI{auth: missingUserAuth{}}
+
+	_, err := s.IsAdmin(context.Background(), &ssov1.IsAdminRequest{UserId: 404})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	resp, err := s.IsUserExists(context.Background(), &ssov1.IsUserExistsRequest{UserId: 404})
+	require.NoError(t, err)
+	assert.False(t, resp.GetExists())
+}
+
+// mfaAuth - сервис, требующий кода из SMS после пароля
+type mfaAuth struct{ Auth }
+
+func (mfaAuth) Login(context.Context, string, string, int, string, string, string) (models.Token, error) {
+	return models.Token{MFAChallenge: &models.MFAChallenge{Token: "challenge", PhoneHint: "+7********89"}}, nil
+}
+
+func (mfaAuth) SendSMSCode(context.Context, string) (time.Time, error) {
+	return time.Time{}, fmt.Errorf("Auth.SendSMSCode: %w", auth.ErrSMSRateLimited)
+}
+
+func TestLogin_MFARequired(t *testing.T) {
+	s := &serverAPI{auth: mfaAuth{}}
+
+	resp, err := s.Login(context.Background(), &ssov1.LoginRequest{Email: "a@b.c", Password: "p", AppId: 1})
+	require.NoError(t, err)
+	assert.True(t, resp.GetMfaRequired())
+	assert.Equal(t, "challenge", resp.GetMfaChallengeToken())
+	assert.Equal(t, "+7********89", resp.GetPhoneHint())
+	assert.Empty(t, resp.GetToken())
+
+	_, err = s.SendSMSCode(context.Background(), &ssov1.SendSMSCodeRequest{ChallengeToken: "challenge"})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, errinfo.ReasonSMSRateLimited, errinfo.Reason(err))
+}
+
+// refreshAuth - сервис, у которого обмен refresh-токена заканчивается ошибкой err
+type refreshAuth struct {
+	Auth
+	err error
+}
+
+func (f refreshAuth) RefreshToken(context.Context, string) (models.Token, error) {
+	return models.Token{}, fmt.Errorf("Auth.RefreshToken: %w", f.err)
+}
+
+func TestRefresh_ErrorMapping(t *testing.T) {
+	for err, want := range map[error]struct {
+		code   codes.Code
+		reason string
+	}{
+		auth.ErrReloginRequired:     {codes.Unauthenticated, ReasonReloginRequired},
+		auth.ErrRefreshTokenExpired: {codes.Unauthenticated, ReasonReloginRequired},
+		auth.ErrInvalidRefreshToken: {codes.Unauthenticated, errinfo.ReasonInvalidRefreshToken},
+		auth.ErrRefreshDisabled:     {codes.FailedPrecondition, errinfo.ReasonRefreshDisabled},
+	} {
+		s := &serverAPI{auth: refreshAuth{err: err}}
+
+		_, got := s.Refresh(context.Background(), &ssov1.RefreshRequest{RefreshToken: "family.secret"})
+		assert.Equal(t, want.code, status.Code(got), err.Error())
+		assert.Equal(t, want.reason, errinfo.Reason(got), err.Error())
+	}
+
+	s := &serverAPI{auth: refreshAuth{}}
+	_, err := s.Refresh(context.Background(), &ssov1.RefreshRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
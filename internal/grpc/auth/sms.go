@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/services/auth"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Ограничения полей запросов с кодом из SMS
+const (
+	maxSMSCodeLen = 16
+	maxPhoneLen   = 32 // с пробелами, скобками и дефисами
+)
+
+// SendSMSCode и VerifySMSCode публичные: у пользователя ещё нет токена, вызов задаёт mfa_challenge_token из Login.
+// Номер телефона подтверждает и удаляет вошедший пользователь (см. accessPolicy)
+
+func (s *serverAPI) SendSMSCode(ctx context.Context, req *ssov1.SendSMSCodeRequest) (*ssov1.SendSMSCodeResponse, error) {
+	if req.GetChallengeToken() == "" {
+		return nil, status.Error(codes.InvalidArgument, "challenge_token is required")
+	}
+
+	expiresAt, err := s.auth.SendSMSCode(ctx, req.GetChallengeToken())
+	if err != nil {
+		return nil, smsError(err)
+	}
+
+	return &ssov1.SendSMSCodeResponse{ExpiresIn: int64(time.Until(expiresAt).Seconds())}, nil
+}
+
+func (s *serverAPI) VerifySMSCode(ctx context.Context, req *ssov1.VerifySMSCodeRequest) (*ssov1.VerifySMSCodeResponse, error) {
+	if err := validateSMSCode(req.GetChallengeToken(), req.GetCode()); err != nil {
+		return nil, err
+	}
+
+	token, err := s.auth.VerifySMSCode(ctx, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		if errors.Is(err, auth.ErrInvalidCredentials) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid email or password")
+		}
+
+		if errors.Is(err, auth.ErrConsentRequired) {
+			return nil, failedPrecondition("user has not consented to share data with this app", ReasonConsentRequired)
+		}
+
+		if errors.Is(err, auth.ErrTOSReacceptanceRequired) {
+			return nil, s.tosStatus("terms of service have changed, accept the current version", ReasonTOSReacceptanceRequired)
+		}
+
+		if st := policyDeniedStatus(err); st != nil {
+			return nil, st
+		}
+
+		if errors.Is(err, auth.ErrTooManySessions) {
+			return nil, failedPrecondition("too many concurrent sessions for this app, sign out elsewhere first", ReasonSessionLimitReached)
+		}
+
+		if errors.Is(err, auth.ErrInvalidAppID) {
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid app_id")
+		}
+
+		return nil, smsError(err)
+	}
+
+	return &ssov1.VerifySMSCodeResponse{
+		Token:     token.AccessToken,
+		ExpiresIn: token.ExpiresAt.Unix() - time.Now().Unix(),
+		TokenType: tokenTypeBearer,
+		Scopes:    token.Scopes,
+
+		TosReacceptanceRequired: token.TOSReacceptanceRequired,
+		StepUpRequired:          token.StepUpRequired,
+		RefreshToken:            token.RefreshToken,
+		RefreshExpiresIn:        refreshExpiresIn(token),
+	}, nil
+}
+
+func (s *serverAPI) StartPhoneVerification(
+	ctx context.Context,
+	req *ssov1.StartPhoneVerificationRequest,
+) (*ssov1.StartPhoneVerificationResponse, error) {
+	if req.GetPhone() == "" {
+		return nil, status.Error(codes.InvalidArgument, "phone is required")
+	}
+	if len(req.GetPhone()) > maxPhoneLen {
+		return nil, errinfo.FromService(auth.ErrInvalidPhone, codes.InvalidArgument, "invalid phone number")
+	}
+
+	principal, err := phoneOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.auth.StartPhoneVerification(ctx, principal.UserID, req.GetPhone())
+	if err != nil {
+		return nil, smsError(err)
+	}
+
+	return &ssov1.StartPhoneVerificationResponse{
+		ChallengeToken: challenge.Token,
+		PhoneHint:      challenge.PhoneHint,
+		ExpiresIn:      int64(time.Until(challenge.ExpiresAt).Seconds()),
+	}, nil
+}
+
+func (s *serverAPI) ConfirmPhone(ctx context.Context, req *ssov1.ConfirmPhoneRequest) (*ssov1.ConfirmPhoneResponse, error) {
+	if err := validateSMSCode(req.GetChallengeToken(), req.GetCode()); err != nil {
+		return nil, err
+	}
+
+	principal, err := phoneOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	phone, err := s.auth.ConfirmPhone(ctx, principal.UserID, req.GetChallengeToken(), req.GetCode())
+	if err != nil {
+		return nil, smsError(err)
+	}
+
+	return &ssov1.ConfirmPhoneResponse{Phone: phone}, nil
+}
+
+func (s *serverAPI) RemovePhone(ctx context.Context, _ *ssov1.RemovePhoneRequest) (*ssov1.RemovePhoneResponse, error) {
+	principal, err := phoneOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.auth.RemovePhone(ctx, principal.UserID, principal.ACR); err != nil {
+		return nil, smsError(err)
+	}
+
+	return &ssov1.RemovePhoneResponse{}, nil
+}
+
+// phoneOwner - владелец номера. Как и passkey, номер - способ входа, и стороннее приложение его не меняет
+func phoneOwner(ctx context.Context) (authctx.Principal, error) {
+	return firstPartyPrincipal(ctx, "phone number can only be managed from a first-party app")
+}
+
+func validateSMSCode(challengeToken, code string) error {
+	if challengeToken == "" {
+		return status.Error(codes.InvalidArgument, "challenge_token is required")
+	}
+	if code == "" {
+		return status.Error(codes.InvalidArgument, "code is required")
+	}
+	if len(code) > maxSMSCodeLen {
+		return status.Errorf(codes.InvalidArgument, "code must not be longer than %d characters", maxSMSCodeLen)
+	}
+
+	return nil
+}
+
+// smsError - статус ошибки второго фактора по SMS
+func smsError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrInvalidSecondFactor):
+		return errinfo.FromService(err, codes.InvalidArgument, "invalid code")
+	case errors.Is(err, auth.ErrMFAChallengeNotFound):
+		return errinfo.FromService(err, codes.NotFound, "challenge not found or expired, start again")
+	case errors.Is(err, auth.ErrSMSCodeExpired):
+		return errinfo.FromService(err, codes.FailedPrecondition, "code expired or not sent, request a new one")
+	case errors.Is(err, auth.ErrTooManySMSAttempts):
+		return errinfo.FromService(err, codes.FailedPrecondition, "too many invalid codes, start again")
+	case errors.Is(err, auth.ErrSMSRateLimited):
+		return errinfo.FromService(err, codes.ResourceExhausted, "too many codes requested, try again later")
+	case errors.Is(err, auth.ErrInvalidPhone):
+		return errinfo.FromService(err, codes.InvalidArgument, "phone must be in international format, e.g. +79123456789")
+	case errors.Is(err, auth.ErrPhoneNotSet):
+		return errinfo.FromService(err, codes.FailedPrecondition, "phone number is not set")
+	case errors.Is(err, auth.ErrMFARequired):
+		return errinfo.FromService(err, codes.PermissionDenied, "sign in with a second factor first")
+	case errors.Is(err, auth.ErrUserNotFound):
+		return errinfo.FromService(err, codes.NotFound, "user not found")
+	case errors.Is(err, auth.ErrSMSDisabled):
+		return errinfo.FromService(err, codes.FailedPrecondition, "sms verification is disabled")
+	default:
+		return status.Error(codes.Internal, "internal error")
+	}
+}
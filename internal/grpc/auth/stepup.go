@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/services/auth"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// StepUp - подтверждение входа вторым фактором. Публичный метод, как и ValidateToken:
+// доказательство - сам токен в запросе
+func (s *serverAPI) StepUp(ctx context.Context, req *ssov1.StepUpRequest) (*ssov1.StepUpResponse, error) {
+	if req.GetToken() == "" || req.GetCode() == "" {
+		return nil, status.Error(codes.InvalidArgument, "token and code are required")
+	}
+
+	token, err := s.auth.StepUp(ctx, req.GetToken(), req.GetCode())
+	if err != nil {
+		if st := policyDeniedStatus(err); st != nil {
+			return nil, st
+		}
+
+		switch {
+		case errors.Is(err, auth.ErrInvalidSecondFactor):
+			return nil, errinfo.FromService(err, codes.InvalidArgument, "invalid code")
+		case errors.Is(err, auth.ErrSessionExpired):
+			return nil, withReason(codes.Unauthenticated, "session expired, sign in again", ReasonSessionExpired)
+		case errors.Is(err, auth.ErrInvalidToken):
+			return nil, errinfo.FromService(err, codes.Unauthenticated, "invalid token")
+		case errors.Is(err, auth.ErrStepUpUnavailable):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "no second factor is configured")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.StepUpResponse{
+		Token:     token.AccessToken,
+		ExpiresIn: token.ExpiresAt.Unix() - time.Now().Unix(),
+		TokenType: tokenTypeBearer,
+	}, nil
+}
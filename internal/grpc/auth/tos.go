@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/authctx"
+	"sso/internal/services/auth"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AcceptTOS требует токена (см. accessPolicy): условия принимает сам пользователь или администратор
+func (s *serverAPI) AcceptTOS(ctx context.Context, req *ssov1.AcceptTOSRequest) (*ssov1.AcceptTOSResponse, error) {
+	if req.GetUserId() == emptyValue || req.GetVersion() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user_id and version are required")
+	}
+
+	principal, ok := authctx.From(ctx)
+	if !ok || (!principal.IsAdmin && principal.UserID != req.GetUserId()) {
+		return nil, status.Error(codes.PermissionDenied, "can only accept terms of service for yourself")
+	}
+
+	if err := s.auth.AcceptTOS(ctx, req.GetUserId(), req.GetVersion()); err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidTOSVersion):
+			return nil, errinfo.Newf(codes.InvalidArgument, errinfo.ReasonInvalidTOSVersion, "version must be the current one (%s)", s.auth.TOSVersion())
+		case errors.Is(err, auth.ErrTOSDisabled):
+			return nil, errinfo.FromService(err, codes.FailedPrecondition, "terms of service are not tracked")
+		}
+
+		return nil, status.Error(codes.Internal, "internal error")
+	}
+
+	return &ssov1.AcceptTOSResponse{}, nil
+}
+
+// tosStatus - FailedPrecondition с ErrorInfo{Reason: reason}, в metadata - текущая версия условий,
+// чтобы клиент показал пользователю именно её
+func (s *serverAPI) tosStatus(msg, reason string) error {
+	st := status.New(codes.FailedPrecondition, msg)
+
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: map[string]string{"current_version": s.auth.TOSVersion()},
+	})
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
@@ -0,0 +1,309 @@
+package errinfo
+
+import (
+	"errors"
+	"slices"
+	"sso/internal/lib/revocations"
+	"sso/internal/services/auth"
+	"sso/internal/storage"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Каталог причин. Причины без префикса появились раньше каталога и сохранены как есть:
+// клиенты уже ветвятся по ним
+const (
+	ReasonInvalidCredentials  = "AUTH_INVALID_CREDENTIALS"
+	ReasonInvalidApp          = "AUTH_INVALID_APP"
+	ReasonUserExists          = "AUTH_USER_EXISTS"
+	ReasonUserNotFound        = "AUTH_USER_NOT_FOUND"
+	ReasonInvalidToken        = "AUTH_INVALID_TOKEN"
+	ReasonTooManyIDs          = "AUTH_TOO_MANY_IDS"
+	ReasonOverloaded          = "AUTH_OVERLOADED"
+	ReasonEmailDomainDenied   = "AUTH_EMAIL_DOMAIN_NOT_ALLOWED"
+	ReasonOrgNotFound         = "AUTH_ORG_NOT_FOUND"
+	ReasonSignupClosed        = "AUTH_SIGNUP_CLOSED"
+	ReasonAppRequired         = "AUTH_APP_REQUIRED"
+	ReasonPasswordExpired     = "PASSWORD_EXPIRED"
+	ReasonPasswordReused      = "AUTH_PASSWORD_REUSED"
+	ReasonWeakPassword        = "AUTH_WEAK_PASSWORD"
+	ReasonPasswordTooLong     = "AUTH_PASSWORD_TOO_LONG"
+	ReasonMFAUnavailable      = "AUTH_MFA_UNAVAILABLE"
+	ReasonInvalidMFACode      = "AUTH_INVALID_MFA_CODE"
+	ReasonSessionLimitReached = "SESSION_LIMIT_REACHED"
+	ReasonSessionExpired      = "SESSION_EXPIRED"
+	ReasonSessionNotFound     = "AUTH_SESSION_NOT_FOUND"
+	ReasonRevocationDisabled  = "AUTH_TOKEN_REVOCATION_DISABLED"
+
+	ReasonRefreshDisabled     = "AUTH_REFRESH_DISABLED"
+	ReasonInvalidRefreshToken = "AUTH_INVALID_REFRESH_TOKEN"
+	ReasonReloginRequired     = "AUTH_RELOGIN_REQUIRED" // refresh-токен истёк или вышел за предел срока: нужен новый вход
+
+	ReasonConsentRequired  = "CONSENT_REQUIRED"
+	ReasonConsentNotFound  = "AUTH_CONSENT_NOT_FOUND"
+	ReasonConsentsDisabled = "AUTH_CONSENTS_DISABLED"
+	ReasonInvalidScopes    = "AUTH_INVALID_SCOPES"
+
+	ReasonTOSAcceptanceRequired   = "TOS_ACCEPTANCE_REQUIRED"
+	ReasonTOSReacceptanceRequired = "TOS_REACCEPTANCE_REQUIRED"
+	ReasonInvalidTOSVersion       = "AUTH_INVALID_TOS_VERSION"
+	ReasonTOSDisabled             = "AUTH_TOS_DISABLED"
+
+	ReasonAccountDeletionDisabled = "AUTH_ACCOUNT_DELETION_DISABLED"
+	ReasonNoPendingDeletion       = "AUTH_NO_PENDING_DELETION"
+	ReasonGuestDeletion           = "AUTH_GUEST_DELETION"
+
+	ReasonInvitationsDisabled = "AUTH_INVITATIONS_DISABLED"
+	ReasonInvalidRole         = "AUTH_INVALID_ROLE"
+	ReasonInvitationNotFound  = "AUTH_INVITATION_NOT_FOUND"
+	ReasonInvitationExpired   = "INVITATION_EXPIRED"
+	ReasonInvitationUsed      = "INVITATION_USED"
+
+	ReasonGuestsDisabled     = "AUTH_GUESTS_DISABLED"
+	ReasonNotGuest           = "AUTH_NOT_GUEST"
+	ReasonGuestAppNotAllowed = "AUTH_GUEST_APP_NOT_ALLOWED"
+
+	ReasonInvalidMetadata  = "AUTH_INVALID_METADATA"
+	ReasonMetadataTooLarge = "AUTH_METADATA_TOO_LARGE"
+	ReasonMetadataDisabled = "AUTH_METADATA_DISABLED"
+	ReasonMetadataConflict = "AUTH_METADATA_CONFLICT"
+
+	ReasonActionTokensDisabled = "AUTH_ACTION_TOKENS_DISABLED"
+	ReasonInvalidAction        = "AUTH_INVALID_ACTION"
+	ReasonInvalidActionToken   = "AUTH_INVALID_ACTION_TOKEN"
+	ReasonActionTokenUsed      = "AUTH_ACTION_TOKEN_USED"
+
+	ReasonDeviceFlowDisabled   = "AUTH_DEVICE_FLOW_DISABLED"
+	ReasonDeviceCodeNotFound   = "AUTH_DEVICE_CODE_NOT_FOUND"
+	ReasonUserCodeNotFound     = "AUTH_USER_CODE_NOT_FOUND"
+	ReasonDeviceAlreadyDecided = "AUTH_DEVICE_ALREADY_DECIDED"
+	// Причины из RFC 8628, 3.5 - клиенты устройств уже умеют их обрабатывать
+	ReasonAuthorizationPending = "AUTHORIZATION_PENDING"
+	ReasonSlowDown             = "SLOW_DOWN"
+	ReasonAccessDenied         = "ACCESS_DENIED"
+	ReasonDeviceCodeExpired    = "DEVICE_CODE_EXPIRED"
+
+	ReasonPasskeysDisabled         = "AUTH_PASSKEYS_DISABLED"
+	ReasonPasskeyNotFound          = "AUTH_PASSKEY_NOT_FOUND"
+	ReasonPasskeyExists            = "AUTH_PASSKEY_EXISTS"
+	ReasonTooManyPasskeys          = "AUTH_TOO_MANY_PASSKEYS"
+	ReasonPasskeyChallengeNotFound = "AUTH_PASSKEY_CHALLENGE_NOT_FOUND"
+	ReasonInvalidPasskey           = "AUTH_INVALID_PASSKEY"
+
+	ReasonOfflineTokensDisabled    = "AUTH_OFFLINE_TOKENS_DISABLED"
+	ReasonOfflineTokensNotAccepted = "AUTH_OFFLINE_TOKENS_NOT_ACCEPTED"
+	ReasonFreshMFARequired         = "AUTH_FRESH_MFA_REQUIRED"
+	ReasonOfflineTokenNotFound     = "AUTH_OFFLINE_TOKEN_NOT_FOUND"
+
+	ReasonSMSDisabled          = "AUTH_SMS_DISABLED"
+	ReasonInvalidPhone         = "AUTH_INVALID_PHONE"
+	ReasonMFAChallengeNotFound = "AUTH_MFA_CHALLENGE_NOT_FOUND"
+	ReasonSMSCodeExpired       = "AUTH_SMS_CODE_EXPIRED"
+	ReasonTooManySMSAttempts   = "AUTH_TOO_MANY_SMS_ATTEMPTS"
+	ReasonSMSRateLimited       = "AUTH_SMS_RATE_LIMITED"
+	ReasonPhoneNotSet          = "AUTH_PHONE_NOT_SET"
+	ReasonMFARequired          = "AUTH_MFA_REQUIRED"
+
+	ReasonCaptchaRequired    = "AUTH_CAPTCHA_REQUIRED"
+	ReasonCaptchaInvalid     = "AUTH_CAPTCHA_INVALID"
+	ReasonCaptchaUnavailable = "AUTH_CAPTCHA_UNAVAILABLE"
+
+	ReasonOIDCDisabled       = "AUTH_OIDC_DISABLED"
+	ReasonInvalidRedirectURI = "AUTH_INVALID_REDIRECT_URI"
+	ReasonInvalidAuthRequest = "AUTH_INVALID_AUTH_REQUEST"
+	ReasonInvalidClient      = "AUTH_INVALID_CLIENT"
+	ReasonInvalidGrant       = "AUTH_INVALID_GRANT"
+
+	ReasonProvisioningDisabled    = "AUTH_PROVISIONING_DISABLED"
+	ReasonNotProvisioner          = "AUTH_NOT_PROVISIONER"
+	ReasonProvisioningConflict    = "AUTH_PROVISIONING_EMAIL_CONFLICT"
+	ReasonProvisionedUserNotFound = "AUTH_PROVISIONED_USER_NOT_FOUND"
+
+	ReasonPolicyDenied = "AUTH_POLICY_DENIED"
+
+	ReasonSelfTestDisabled    = "AUTH_SELF_TEST_DISABLED"
+	ReasonSelfTestCheckFailed = "AUTH_SELF_TEST_CHECK_FAILED"
+
+	// Ошибки хранилища, которые обработчики Admin отдают напрямую
+	ReasonAppNotFound     = "AUTH_APP_NOT_FOUND"
+	ReasonAppExists       = "AUTH_APP_EXISTS"
+	ReasonOrgExists       = "AUTH_ORG_EXISTS"
+	ReasonNotMember       = "AUTH_NOT_ORG_MEMBER"
+	ReasonGroupNotFound   = "AUTH_GROUP_NOT_FOUND"
+	ReasonGroupExists     = "AUTH_GROUP_EXISTS"
+	ReasonNotInGroup      = "AUTH_NOT_GROUP_MEMBER"
+	ReasonVersionConflict = "AUTH_VERSION_CONFLICT"
+	ReasonBackupCorrupt   = "AUTH_BACKUP_CORRUPT"
+
+	ReasonLoginPolicyNotFound = "AUTH_LOGIN_POLICY_NOT_FOUND"
+
+	ReasonSlowConsumer = "SLOW_CONSUMER" // поток WatchRevocations закрыт: потребитель не успевал читать
+
+	// Отказы интерцепторов - до вызова обработчика
+	ReasonMaintenance = "MAINTENANCE"
+	ReasonRateLimited = "RATE_LIMITED"
+)
+
+// userFacingCodes - коды, общая причина которых (ForCode) показывается пользователю как есть:
+// сообщения таких ошибок не содержат подробностей, поэтому их можно переводить целиком.
+// Общие INVALID_ARGUMENT и NOT_FOUND сюда не входят - их сообщения описывают конкретное поле
+var userFacingCodes = []codes.Code{
+	codes.Unauthenticated,
+	codes.PermissionDenied,
+	codes.DeadlineExceeded,
+	codes.Unavailable,
+	codes.Internal,
+}
+
+// catalog - причина каждой ошибки сервисного слоя. Порядок важен: ошибки сервиса идут раньше ошибок
+// хранилища, которые они могут оборачивать
+var catalog = []struct {
+	err    error
+	reason string
+}{
+	{auth.ErrInvalidCredentials, ReasonInvalidCredentials},
+	{auth.ErrInvalidAppID, ReasonInvalidApp},
+	{auth.ErrUserExists, ReasonUserExists},
+	{auth.ErrUserNotFound, ReasonUserNotFound},
+	{auth.ErrInvalidToken, ReasonInvalidToken},
+	{auth.ErrTooManyIDs, ReasonTooManyIDs},
+	{auth.ErrOverloaded, ReasonOverloaded},
+	{auth.ErrDomainNotAllowed, ReasonEmailDomainDenied},
+	{auth.ErrOrgNotFound, ReasonOrgNotFound},
+	{auth.ErrSignupClosed, ReasonSignupClosed},
+	{auth.ErrAppRequired, ReasonAppRequired},
+	{auth.ErrPasswordExpired, ReasonPasswordExpired},
+	{auth.ErrPasswordReused, ReasonPasswordReused},
+	{auth.ErrWeakPassword, ReasonWeakPassword},
+	{auth.ErrPasswordTooLong, ReasonPasswordTooLong},
+	{auth.ErrStepUpUnavailable, ReasonMFAUnavailable},
+	{auth.ErrInvalidSecondFactor, ReasonInvalidMFACode},
+	{auth.ErrTooManySessions, ReasonSessionLimitReached},
+	{auth.ErrSessionExpired, ReasonSessionExpired},
+	{auth.ErrSessionNotFound, ReasonSessionNotFound},
+	{auth.ErrTokenRevocationDisabled, ReasonRevocationDisabled},
+	{auth.ErrRefreshDisabled, ReasonRefreshDisabled},
+	{auth.ErrInvalidRefreshToken, ReasonInvalidRefreshToken},
+	{auth.ErrRefreshTokenExpired, ReasonReloginRequired},
+	{auth.ErrReloginRequired, ReasonReloginRequired},
+
+	{auth.ErrConsentRequired, ReasonConsentRequired},
+	{auth.ErrConsentNotFound, ReasonConsentNotFound},
+	{auth.ErrConsentsDisabled, ReasonConsentsDisabled},
+	{auth.ErrInvalidScopes, ReasonInvalidScopes},
+
+	{auth.ErrTOSNotAccepted, ReasonTOSAcceptanceRequired},
+	{auth.ErrTOSReacceptanceRequired, ReasonTOSReacceptanceRequired},
+	{auth.ErrInvalidTOSVersion, ReasonInvalidTOSVersion},
+	{auth.ErrTOSDisabled, ReasonTOSDisabled},
+
+	{auth.ErrAccountDeletionDisabled, ReasonAccountDeletionDisabled},
+	{auth.ErrNoPendingDeletion, ReasonNoPendingDeletion},
+	{auth.ErrGuestDeletion, ReasonGuestDeletion},
+
+	{auth.ErrInvitationsDisabled, ReasonInvitationsDisabled},
+	{auth.ErrInvalidRole, ReasonInvalidRole},
+	{auth.ErrInvitationNotFound, ReasonInvitationNotFound},
+	{auth.ErrInvitationExpired, ReasonInvitationExpired},
+	{auth.ErrInvitationUsed, ReasonInvitationUsed},
+
+	{auth.ErrGuestsDisabled, ReasonGuestsDisabled},
+	{auth.ErrNotGuest, ReasonNotGuest},
+	{auth.ErrGuestAppNotAllowed, ReasonGuestAppNotAllowed},
+
+	{auth.ErrInvalidMetadata, ReasonInvalidMetadata},
+	{auth.ErrMetadataTooLarge, ReasonMetadataTooLarge},
+	{auth.ErrMetadataDisabled, ReasonMetadataDisabled},
+	{auth.ErrMetadataConflict, ReasonMetadataConflict},
+
+	{auth.ErrActionTokensDisabled, ReasonActionTokensDisabled},
+	{auth.ErrInvalidAction, ReasonInvalidAction},
+	{auth.ErrInvalidActionToken, ReasonInvalidActionToken},
+	{auth.ErrActionTokenUsed, ReasonActionTokenUsed},
+
+	{auth.ErrDeviceFlowDisabled, ReasonDeviceFlowDisabled},
+	{auth.ErrDeviceCodeNotFound, ReasonDeviceCodeNotFound},
+	{auth.ErrUserCodeNotFound, ReasonUserCodeNotFound},
+	{auth.ErrDeviceCodeExpired, ReasonDeviceCodeExpired},
+	{auth.ErrDeviceAlreadyDecided, ReasonDeviceAlreadyDecided},
+	{auth.ErrAuthorizationPending, ReasonAuthorizationPending},
+	{auth.ErrSlowDown, ReasonSlowDown},
+	{auth.ErrDeviceDenied, ReasonAccessDenied},
+
+	{auth.ErrPasskeysDisabled, ReasonPasskeysDisabled},
+	{auth.ErrPasskeyNotFound, ReasonPasskeyNotFound},
+	{auth.ErrPasskeyExists, ReasonPasskeyExists},
+	{auth.ErrTooManyPasskeys, ReasonTooManyPasskeys},
+	{auth.ErrPasskeyChallengeNotFound, ReasonPasskeyChallengeNotFound},
+	{auth.ErrInvalidPasskey, ReasonInvalidPasskey},
+	{auth.ErrOfflineTokensDisabled, ReasonOfflineTokensDisabled},
+	{auth.ErrOfflineTokensNotAccepted, ReasonOfflineTokensNotAccepted},
+	{auth.ErrFreshMFARequired, ReasonFreshMFARequired},
+	{auth.ErrOfflineTokenNotFound, ReasonOfflineTokenNotFound},
+	{auth.ErrSMSDisabled, ReasonSMSDisabled},
+	{auth.ErrInvalidPhone, ReasonInvalidPhone},
+	{auth.ErrMFAChallengeNotFound, ReasonMFAChallengeNotFound},
+	{auth.ErrSMSCodeExpired, ReasonSMSCodeExpired},
+	{auth.ErrTooManySMSAttempts, ReasonTooManySMSAttempts},
+	{auth.ErrSMSRateLimited, ReasonSMSRateLimited},
+	{auth.ErrPhoneNotSet, ReasonPhoneNotSet},
+	{auth.ErrMFARequired, ReasonMFARequired},
+	{auth.ErrCaptchaRequired, ReasonCaptchaRequired},
+	{auth.ErrCaptchaInvalid, ReasonCaptchaInvalid},
+	{auth.ErrCaptchaUnavailable, ReasonCaptchaUnavailable},
+	{auth.ErrOIDCDisabled, ReasonOIDCDisabled},
+	{auth.ErrInvalidRedirectURI, ReasonInvalidRedirectURI},
+	{auth.ErrInvalidAuthRequest, ReasonInvalidAuthRequest},
+	{auth.ErrInvalidClient, ReasonInvalidClient},
+	{auth.ErrInvalidGrant, ReasonInvalidGrant},
+	{auth.ErrProvisioningDisabled, ReasonProvisioningDisabled},
+	{auth.ErrNotProvisioner, ReasonNotProvisioner},
+	{auth.ErrProvisioningConflict, ReasonProvisioningConflict},
+	{auth.ErrProvisionedUserNotFound, ReasonProvisionedUserNotFound},
+	{auth.ErrLoginPolicyDenied, ReasonPolicyDenied},
+	{auth.ErrSelfTestDisabled, ReasonSelfTestDisabled},
+	{auth.ErrSelfTestCheckFailed, ReasonSelfTestCheckFailed},
+
+	{storage.ErrUserExists, ReasonUserExists},
+	{storage.ErrUserNotFound, ReasonUserNotFound},
+	{storage.ErrAppNotFound, ReasonAppNotFound},
+	{storage.ErrAppExists, ReasonAppExists},
+	{storage.ErrOrgNotFound, ReasonOrgNotFound},
+	{storage.ErrOrgExists, ReasonOrgExists},
+	{storage.ErrNotMember, ReasonNotMember},
+	{storage.ErrGroupNotFound, ReasonGroupNotFound},
+	{storage.ErrGroupExists, ReasonGroupExists},
+	{storage.ErrNotInGroup, ReasonNotInGroup},
+	{storage.ErrVersionConflict, ReasonVersionConflict},
+	{storage.ErrCorrupt, ReasonBackupCorrupt},
+	{storage.ErrLoginPolicyNotFound, ReasonLoginPolicyNotFound},
+
+	{revocations.ErrSlowConsumer, ReasonSlowConsumer},
+}
+
+// Of - причина ошибки сервисного слоя из каталога ("" - ошибки в каталоге нет)
+func Of(err error) string {
+	for _, c := range catalog {
+		if errors.Is(err, c.err) {
+			return c.reason
+		}
+	}
+
+	return ""
+}
+
+// Reasons - все причины, которые сервер отдаёт пользователю: из каталога, интерцепторов
+// и общие по кодам из userFacingCodes. Отсортированы, без повторов
+func Reasons() []string {
+	reasons := []string{ReasonMaintenance, ReasonRateLimited}
+	for _, c := range catalog {
+		reasons = append(reasons, c.reason)
+	}
+	for _, code := range userFacingCodes {
+		reasons = append(reasons, ForCode(code))
+	}
+
+	slices.Sort(reasons)
+
+	return slices.Compact(reasons)
+}
@@ -0,0 +1,103 @@
+// Package errinfo - машиночитаемые причины ошибок gRPC (google.rpc.ErrorInfo).
+//
+// Каждая ошибка сервера несёт ErrorInfo с Domain "sso" и стабильной причиной из каталога:
+// клиенты ветвятся по причине, а не по тексту сообщения, который можно переформулировать
+// и переводить. Причины - контракт с клиентами: переименовывать их нельзя.
+package errinfo
+
+import (
+	"strings"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain - ErrorInfo.Domain всех ошибок сервиса
+const Domain = "sso"
+
+// New - статус code с сообщением msg и ErrorInfo{Reason: reason}
+func New(code codes.Code, msg, reason string) error {
+	return withInfo(status.New(code, msg), reason).Err()
+}
+
+// Newf - то же, что New, с форматированием сообщения
+func Newf(code codes.Code, reason, format string, args ...any) error {
+	return withInfo(status.Newf(code, format, args...), reason).Err()
+}
+
+// FromService - статус для ошибки сервисного слоя err: причина берётся из каталога,
+// а если ошибки в нём нет - общая причина по коду
+func FromService(err error, code codes.Code, msg string) error {
+	reason := Of(err)
+	if reason == "" {
+		reason = ForCode(code)
+	}
+
+	return New(code, msg, reason)
+}
+
+// Reason - причина из ErrorInfo ошибки err ("" - ошибка не gRPC или без ErrorInfo)
+func Reason(err error) string {
+	info := find(status.Convert(err))
+	if info == nil {
+		return ""
+	}
+
+	return info.GetReason()
+}
+
+// Ensure - добавляет к ошибке gRPC общую причину по коду, если у неё ещё нет ErrorInfo.
+// Ошибки, которые не являются статусом gRPC, становятся Unknown
+func Ensure(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	st := status.Convert(err)
+	if st.Code() == codes.OK || find(st) != nil {
+		return err
+	}
+
+	return withInfo(st, ForCode(st.Code())).Err()
+}
+
+// ForCode - общая причина для ошибок без своей причины в каталоге: имя кода gRPC
+// в виде INVALID_ARGUMENT, NOT_FOUND и т. д.
+func ForCode(code codes.Code) string {
+	if code == codes.Canceled {
+		// codes.Canceled.String() - "Canceled", а в google.rpc.Code - CANCELLED
+		return "CANCELLED"
+	}
+
+	name := code.String()
+
+	var b strings.Builder
+	for i, r := range name {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+
+	return strings.ToUpper(b.String())
+}
+
+func withInfo(st *status.Status, reason string) *status.Status {
+	withDetails, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: Domain})
+	if err != nil {
+		return st
+	}
+
+	return withDetails
+}
+
+func find(st *status.Status) *errdetails.ErrorInfo {
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			return info
+		}
+	}
+
+	return nil
+}
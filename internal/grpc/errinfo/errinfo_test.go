@@ -0,0 +1,144 @@
+package errinfo
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"regexp"
+	"sso/internal/services/auth"
+	"sso/internal/storage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// serviceErrors - имена экспортируемых переменных Err* пакета services/auth
+func serviceErrors(t *testing.T) []string {
+	t.Helper()
+
+	pkgs, err := parser.ParseDir(token.NewFileSet(), "../../services/auth", func(fi fs.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range pkgs["auth"].Files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				for _, name := range spec.(*ast.ValueSpec).Names {
+					if strings.HasPrefix(name.Name, "Err") && name.IsExported() {
+						names = append(names, name.Name)
+					}
+				}
+			}
+		}
+	}
+	require.NotEmpty(t, names)
+
+	return names
+}
+
+// catalogErrors - сколько раз каждая auth.Err* встречается в записях каталога (по исходному коду catalog.go)
+func catalogErrors(t *testing.T) map[string]int {
+	t.Helper()
+
+	f, err := parser.ParseFile(token.NewFileSet(), "catalog.go", nil, 0)
+	require.NoError(t, err)
+
+	counts := make(map[string]int)
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok || len(spec.Names) != 1 || spec.Names[0].Name != "catalog" {
+			return true
+		}
+		ast.Inspect(spec, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "auth" {
+					counts[sel.Sel.Name]++
+				}
+			}
+
+			return true
+		})
+
+		return false
+	})
+
+	return counts
+}
+
+// Каждая ошибка сервиса есть в каталоге ровно один раз: новая ошибка без причины не пройдёт тест
+func TestCatalog_CoversServiceErrors(t *testing.T) {
+	counts := catalogErrors(t)
+
+	for _, name := range serviceErrors(t) {
+		assert.Equal(t, 1, counts[name], "auth.%s must map to exactly one reason", name)
+	}
+}
+
+func TestCatalog_Entries(t *testing.T) {
+	upperSnake := regexp.MustCompile(`^[A-Z][A-Z0-9]*(_[A-Z0-9]+)*$`)
+	seen := make(map[error]bool)
+
+	for _, c := range catalog {
+		assert.False(t, seen[c.err], "%v is listed twice", c.err)
+		seen[c.err] = true
+		assert.Regexp(t, upperSnake, c.reason)
+
+		// Ошибка, обёрнутая сервисом, находит свою причину, а не причину более общей записи
+		assert.Equal(t, c.reason, Of(fmt.Errorf("Auth.Op: %w", c.err)), "%v", c.err)
+	}
+
+	assert.Empty(t, Of(errors.New("unexpected")))
+	assert.Equal(t, ReasonUserNotFound, Of(fmt.Errorf("storage.sqlite.User: %w", storage.ErrUserNotFound)))
+}
+
+func TestFromService(t *testing.T) {
+	err := FromService(fmt.Errorf("Auth.Login: %w", auth.ErrInvalidCredentials), codes.InvalidArgument, "invalid email or password")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "invalid email or password", status.Convert(err).Message())
+	assert.Equal(t, ReasonInvalidCredentials, Reason(err))
+
+	err = FromService(errors.New("unexpected"), codes.Internal, "internal error")
+	assert.Equal(t, "INTERNAL", Reason(err))
+}
+
+func TestEnsure(t *testing.T) {
+	assert.NoError(t, Ensure(nil))
+
+	err := Ensure(status.Error(codes.InvalidArgument, "email is required"))
+	assert.Equal(t, "INVALID_ARGUMENT", Reason(err))
+	assert.Equal(t, "email is required", status.Convert(err).Message())
+
+	// Своя причина не заменяется
+	err = Ensure(New(codes.FailedPrecondition, "password expired", ReasonPasswordExpired))
+	assert.Equal(t, ReasonPasswordExpired, Reason(err))
+	assert.Len(t, status.Convert(err).Details(), 1)
+
+	assert.Equal(t, "UNKNOWN", Reason(Ensure(errors.New("plain error"))))
+	assert.Empty(t, Reason(errors.New("plain error")))
+}
+
+func TestForCode(t *testing.T) {
+	for code, want := range map[codes.Code]string{
+		codes.InvalidArgument:    "INVALID_ARGUMENT",
+		codes.NotFound:           "NOT_FOUND",
+		codes.Canceled:           "CANCELLED",
+		codes.DeadlineExceeded:   "DEADLINE_EXCEEDED",
+		codes.Unauthenticated:    "UNAUTHENTICATED",
+		codes.FailedPrecondition: "FAILED_PRECONDITION",
+	} {
+		assert.Equal(t, want, ForCode(code))
+	}
+}
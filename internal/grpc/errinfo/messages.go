@@ -0,0 +1,152 @@
+package errinfo
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/language"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/status"
+)
+
+// FallbackLocale - язык канонических сообщений: основное сообщение статуса всегда на нём,
+// и им же заменяются переводы, которых нет в каталоге языка
+const FallbackLocale = "en"
+
+// Каталоги сообщений: locales/<язык>.json, причина -> сообщение для пользователя
+//
+//go:embed locales/*.json
+var localeFiles embed.FS
+
+// Messages - переводы сообщений об ошибках для пользователей (google.rpc.LocalizedMessage)
+type Messages struct {
+	locales  []string                     // языки каталогов, язык по умолчанию - первый
+	matcher  language.Matcher             // выбор языка по accept-language среди locales
+	catalogs map[string]map[string]string // язык -> причина -> сообщение
+}
+
+// LoadMessages - читает встроенные каталоги сообщений. defaultLocale - язык для клиентов
+// без accept-language или с языком, которого нет среди каталогов
+func LoadMessages(defaultLocale string) (*Messages, error) {
+	const op = "errinfo.LoadMessages"
+
+	files, err := localeFiles.ReadDir("locales")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	m := &Messages{catalogs: make(map[string]map[string]string, len(files))}
+	for _, f := range files {
+		locale := strings.TrimSuffix(f.Name(), ".json")
+
+		data, err := localeFiles.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		var catalog map[string]string
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, f.Name(), err)
+		}
+
+		m.catalogs[locale] = catalog
+		m.locales = append(m.locales, locale)
+	}
+
+	if _, ok := m.catalogs[FallbackLocale]; !ok {
+		return nil, fmt.Errorf("%s: no catalog for fallback locale %q", op, FallbackLocale)
+	}
+
+	i := slices.Index(m.locales, defaultLocale)
+	if i < 0 {
+		return nil, fmt.Errorf("%s: no catalog for default locale %q, have %q", op, defaultLocale, m.locales)
+	}
+	m.locales[0], m.locales[i] = m.locales[i], m.locales[0]
+
+	tags := make([]language.Tag, len(m.locales))
+	for i, locale := range m.locales {
+		tags[i] = language.Make(locale)
+	}
+	m.matcher = language.NewMatcher(tags)
+
+	return m, nil
+}
+
+// Missing - причины из Reasons, для которых в каталоге языка нет сообщения: язык -> причины.
+// Пустой результат - каталоги полные
+func (m *Messages) Missing() map[string][]string {
+	missing := make(map[string][]string)
+	for _, locale := range m.locales {
+		for _, reason := range Reasons() {
+			if _, ok := m.catalogs[locale][reason]; !ok {
+				missing[locale] = append(missing[locale], reason)
+			}
+		}
+	}
+
+	return missing
+}
+
+// Locale - язык каталога для значения accept-language ("" - язык по умолчанию)
+func (m *Messages) Locale(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return m.locales[0]
+	}
+
+	_, i, conf := m.matcher.Match(tags...)
+	if conf == language.No {
+		return m.locales[0]
+	}
+
+	return m.locales[i]
+}
+
+// Message - сообщение для причины reason на языке locale. Если перевода нет - сообщение
+// из английского каталога, а если нет и его - fallback (основное сообщение статуса).
+// Возвращает язык, на котором сообщение на самом деле написано
+func (m *Messages) Message(locale, reason, fallback string) (string, string) {
+	if msg, ok := m.catalogs[locale][reason]; ok {
+		return locale, msg
+	}
+	if msg, ok := m.catalogs[FallbackLocale][reason]; ok {
+		return FallbackLocale, msg
+	}
+
+	return FallbackLocale, fallback
+}
+
+// Localize - добавляет к ошибке gRPC с ErrorInfo перевод сообщения на язык из accept-language.
+// Основное сообщение статуса не меняется: оно остаётся каноническим английским
+func (m *Messages) Localize(err error, acceptLanguage string) error {
+	st, ok := status.FromError(err)
+	if !ok || err == nil {
+		return err
+	}
+
+	reason := ""
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.LocalizedMessage:
+			return err // перевод уже добавлен
+		case *errdetails.ErrorInfo:
+			reason = d.GetReason()
+		}
+	}
+	if reason == "" {
+		return err
+	}
+
+	locale, msg := m.Message(m.Locale(acceptLanguage), reason, st.Message())
+
+	withDetails, detailsErr := st.WithDetails(&errdetails.LocalizedMessage{Locale: locale, Message: msg})
+	if detailsErr != nil {
+		return err
+	}
+
+	return withDetails.Err()
+}
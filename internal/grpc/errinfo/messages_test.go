@@ -0,0 +1,95 @@
+package errinfo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func localized(t *testing.T, err error) *errdetails.LocalizedMessage {
+	t.Helper()
+
+	for _, d := range status.Convert(err).Details() {
+		if msg, ok := d.(*errdetails.LocalizedMessage); ok {
+			return msg
+		}
+	}
+	t.Fatal("no LocalizedMessage in status details")
+
+	return nil
+}
+
+// Каталоги в репозитории должны покрывать все причины: новая причина без перевода ломает этот тест
+func TestMessages_Complete(t *testing.T) {
+	m, err := LoadMessages("en")
+	require.NoError(t, err)
+
+	assert.Empty(t, m.Missing())
+	assert.ElementsMatch(t, []string{"en", "ru"}, m.locales)
+}
+
+func TestLoadMessages_UnknownDefault(t *testing.T) {
+	_, err := LoadMessages("de")
+	assert.ErrorContains(t, err, `"de"`)
+}
+
+func TestMessages_Locale(t *testing.T) {
+	m, err := LoadMessages("ru")
+	require.NoError(t, err)
+
+	for accept, want := range map[string]string{
+		"":                        "ru",
+		"en":                      "en",
+		"en-US,en;q=0.9":          "en",
+		"ru-RU":                   "ru",
+		"de-DE,en;q=0.8,ru;q=0.5": "en",
+		"de":                      "ru",
+		"not a language;;;":       "ru",
+	} {
+		assert.Equal(t, want, m.Locale(accept), "accept-language %q", accept)
+	}
+}
+
+func TestMessages_Fallback(t *testing.T) {
+	m, err := LoadMessages("en")
+	require.NoError(t, err)
+
+	// Причина без перевода на русский - английское сообщение из каталога
+	delete(m.catalogs["ru"], ReasonUserExists)
+	locale, msg := m.Message("ru", ReasonUserExists, "user already exists")
+	assert.Equal(t, "en", locale)
+	assert.Equal(t, m.catalogs["en"][ReasonUserExists], msg)
+	assert.Equal(t, map[string][]string{"ru": {ReasonUserExists}}, m.Missing())
+
+	// Причины нет ни в одном каталоге - основное сообщение статуса
+	locale, msg = m.Message("ru", "INVALID_ARGUMENT", "email is required")
+	assert.Equal(t, "en", locale)
+	assert.Equal(t, "email is required", msg)
+}
+
+func TestMessages_Localize(t *testing.T) {
+	m, err := LoadMessages("en")
+	require.NoError(t, err)
+
+	err = m.Localize(New(codes.InvalidArgument, "invalid email or password", ReasonInvalidCredentials), "ru-RU,ru;q=0.9")
+
+	// Каноническое сообщение и причина не меняются
+	assert.Equal(t, "invalid email or password", status.Convert(err).Message())
+	assert.Equal(t, ReasonInvalidCredentials, Reason(err))
+
+	msg := localized(t, err)
+	assert.Equal(t, "ru", msg.GetLocale())
+	assert.Equal(t, "Неверный email или пароль", msg.GetMessage())
+
+	// Повторный вызов не добавляет второй перевод
+	assert.Len(t, status.Convert(m.Localize(err, "en")).Details(), 2)
+
+	// Без ErrorInfo переводить нечего
+	plain := status.Error(codes.Internal, "boom")
+	assert.Equal(t, plain, m.Localize(plain, "ru"))
+	assert.NoError(t, m.Localize(nil, "ru"))
+}
@@ -0,0 +1,125 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/logctx"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Access - требуемый уровень доступа к методу
+type Access int
+
+const (
+	AccessPublic        Access = iota // Токен не нужен (Login, Register)
+	AccessAuthenticated               // Нужен действительный токен
+	AccessRegistered                  // Нужен токен зарегистрированного (не гостевого) пользователя
+	AccessAppAdmin                    // Как AccessRegistered; права администратора приложения проверяет обработчик
+	AccessAdmin                       // Нужен токен администратора
+	AccessSuperadmin                  // Нужен токен администратора с правами суперадминистратора
+)
+
+// Policy - какие методы какого доступа требуют.
+// Настройка метода важнее настройки сервиса; всё, что не описано, публично
+type Policy struct {
+	Methods  map[string]Access // полное имя метода (/auth.Auth/Login) -> доступ
+	Services map[string]Access // имя сервиса (auth.Admin) -> доступ по умолчанию для всех его методов
+}
+
+// For - уровень доступа для метода
+func (p Policy) For(fullMethod string) Access {
+	if access, ok := p.Methods[fullMethod]; ok {
+		return access
+	}
+
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if access, ok := p.Services[service]; ok {
+		return access
+	}
+
+	return AccessPublic
+}
+
+// Authenticator - проверяет bearer-токен и возвращает вызывающего
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (authctx.Principal, error)
+}
+
+// Auth - интерцептор авторизации: проверяет `authorization: Bearer <token>` и кладёт вызывающего
+// в контекст (authctx). Для публичных методов токен необязателен, а недействительный токен игнорируется
+func Auth(authn Authenticator, policy Policy) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, authn, policy.For(info.FullMethod))
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthStream - то же, что Auth, для потоковых методов
+func AuthStream(authn Authenticator, policy Policy) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), authn, policy.For(info.FullMethod))
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorize - проверяет токен из метаданных согласно требуемому доступу
+func authorize(ctx context.Context, authn Authenticator, access Access) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	token, hasToken := strings.CutPrefix(first(md, "authorization"), "Bearer ")
+
+	if !hasToken || token == "" {
+		if access != AccessPublic {
+			return nil, status.Error(codes.Unauthenticated, "missing bearer token")
+		}
+
+		return ctx, nil
+	}
+
+	principal, err := authn.Authenticate(ctx, token)
+	if err != nil {
+		if access == AccessPublic {
+			return ctx, nil
+		}
+
+		logctx.FromOr(ctx, slog.Default()).Info("authentication failed", slog.String("error", err.Error()))
+
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	if access >= AccessRegistered && principal.IsGuest {
+		return nil, status.Error(codes.PermissionDenied, "guest accounts cannot call this method")
+	}
+
+	if access >= AccessAdmin && !principal.IsAdmin {
+		return nil, status.Error(codes.PermissionDenied, "admin access required")
+	}
+	if access == AccessSuperadmin && !principal.IsSuperadmin {
+		return nil, status.Error(codes.PermissionDenied, "superadmin access required")
+	}
+
+	return authctx.Into(ctx, principal), nil
+}
+
+// contextStream - ServerStream с подменённым контекстом
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
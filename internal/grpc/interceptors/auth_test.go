@@ -0,0 +1,104 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"sso/internal/lib/authctx"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tokenAuthenticator - токены "superadmin", "admin", "user" и "guest" действительны, остальные нет
+type tokenAuthenticator struct{}
+
+func (tokenAuthenticator) Authenticate(_ context.Context, token string) (authctx.Principal, error) {
+	switch token {
+	case "superadmin":
+		return authctx.Principal{UserID: 4, IsAdmin: true, IsSuperadmin: true}, nil
+	case "admin":
+		return authctx.Principal{UserID: 1, IsAdmin: true}, nil
+	case "user":
+		return authctx.Principal{UserID: 2}, nil
+	case "guest":
+		return authctx.Principal{UserID: 3, IsGuest: true}, nil
+	default:
+		return authctx.Principal{}, errors.New("invalid token")
+	}
+}
+
+func TestPolicy_For(t *testing.T) {
+	p := Policy{
+		Methods:  map[string]Access{"/auth.Auth/GetUsersBatch": AccessAdmin, "/auth.Admin/Ping": AccessPublic},
+		Services: map[string]Access{"auth.Admin": AccessAdmin},
+	}
+
+	assert.Equal(t, AccessPublic, p.For("/auth.Auth/Login"))
+	assert.Equal(t, AccessAdmin, p.For("/auth.Auth/GetUsersBatch"))
+	assert.Equal(t, AccessAdmin, p.For("/auth.Admin/ListUsers"))
+	assert.Equal(t, AccessPublic, p.For("/auth.Admin/Ping"))
+}
+
+func TestAuth(t *testing.T) {
+	policy := Policy{Methods: map[string]Access{
+		"/test/Public":   AccessPublic,
+		"/test/User":     AccessAuthenticated,
+		"/test/Member":   AccessRegistered,
+		"/test/AppAdmin": AccessAppAdmin,
+		"/test/Admin":    AccessAdmin,
+		"/test/Super":    AccessSuperadmin,
+	}}
+	interceptor := Auth(tokenAuthenticator{}, policy)
+
+	tests := []struct {
+		method   string
+		token    string
+		wantCode codes.Code
+		wantUID  int64
+	}{
+		{method: "/test/Public", wantCode: codes.OK},
+		{method: "/test/Public", token: "garbage", wantCode: codes.OK},
+		{method: "/test/Public", token: "user", wantCode: codes.OK, wantUID: 2},
+		{method: "/test/User", wantCode: codes.Unauthenticated},
+		{method: "/test/User", token: "garbage", wantCode: codes.Unauthenticated},
+		{method: "/test/User", token: "user", wantCode: codes.OK, wantUID: 2},
+		{method: "/test/User", token: "guest", wantCode: codes.OK, wantUID: 3},
+		{method: "/test/Member", token: "guest", wantCode: codes.PermissionDenied},
+		{method: "/test/Member", token: "user", wantCode: codes.OK, wantUID: 2},
+		{method: "/test/Admin", token: "guest", wantCode: codes.PermissionDenied},
+		{method: "/test/Admin", token: "user", wantCode: codes.PermissionDenied},
+		{method: "/test/Admin", token: "admin", wantCode: codes.OK, wantUID: 1},
+		// Администратора приложения проверяет обработчик: интерцептор не требует глобальных прав
+		{method: "/test/AppAdmin", token: "guest", wantCode: codes.PermissionDenied},
+		{method: "/test/AppAdmin", token: "user", wantCode: codes.OK, wantUID: 2},
+		{method: "/test/Super", token: "user", wantCode: codes.PermissionDenied},
+		{method: "/test/Super", token: "admin", wantCode: codes.PermissionDenied},
+		{method: "/test/Super", token: "superadmin", wantCode: codes.OK, wantUID: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+"/"+tt.token, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.token != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+tt.token))
+			}
+
+			var gotUID int64
+			handler := func(ctx context.Context, _ any) (any, error) {
+				if p, ok := authctx.From(ctx); ok {
+					gotUID = p.UserID
+				}
+				return "ok", nil
+			}
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			require.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantUID, gotUID)
+		})
+	}
+}
@@ -0,0 +1,38 @@
+package interceptors
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding/gzip" // Регистрирует кодек gzip на сервере
+	"google.golang.org/protobuf/proto"
+)
+
+// Compression - интерцептор, принудительно сжимающий крупные ответы gzip.
+// Сжатие включается только если клиент объявил поддержку gzip и ответ не меньше minSize байт,
+// поэтому короткие ответы (например, Login) остаются несжатыми.
+func Compression(minSize int) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+
+		msg, ok := resp.(proto.Message)
+		if !ok || proto.Size(msg) < minSize {
+			return resp, nil
+		}
+
+		// Клиент должен поддерживать gzip, иначе он не сможет прочитать ответ
+		supported, err := grpc.ClientSupportedCompressors(ctx)
+		if err != nil || !slices.Contains(supported, gzip.Name) {
+			return resp, nil
+		}
+
+		// Ошибка означает лишь то, что ответ уйдёт несжатым
+		_ = grpc.SetSendCompressor(ctx, gzip.Name)
+
+		return resp, nil
+	}
+}
@@ -0,0 +1,79 @@
+package interceptors
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// usersPayload - имитирует ответ ListUsers на n пользователей.
+func usersPayload(b *testing.B, n int) []byte {
+	b.Helper()
+
+	users := make([]any, 0, n)
+	for i := 0; i < n; i++ {
+		users = append(users, map[string]any{
+			"user_id":  float64(i + 1),
+			"email":    fmt.Sprintf("user%d@example.com", i),
+			"is_admin": i%50 == 0,
+		})
+	}
+
+	list, err := structpb.NewList(users)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	raw, err := proto.Marshal(list)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return raw
+}
+
+func BenchmarkCompression(b *testing.B) {
+	cases := []struct {
+		name  string
+		users int
+	}{
+		{name: "login-sized", users: 1},
+		{name: "list-users-1000", users: 1000},
+	}
+
+	for _, tc := range cases {
+		raw := usersPayload(b, tc.users)
+
+		b.Run(tc.name+"/identity", func(b *testing.B) {
+			b.ReportMetric(float64(len(raw)), "wire-bytes")
+		})
+
+		b.Run(tc.name+"/gzip", func(b *testing.B) {
+			c := encoding.GetCompressor(gzip.Name)
+
+			var buf bytes.Buffer
+			for i := 0; i < b.N; i++ {
+				buf.Reset()
+
+				w, err := c.Compress(&buf)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if _, err := io.Copy(w, bytes.NewReader(raw)); err != nil {
+					b.Fatal(err)
+				}
+				if err := w.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(buf.Len()), "wire-bytes")
+		})
+	}
+}
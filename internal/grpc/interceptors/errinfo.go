@@ -0,0 +1,46 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/grpc/errinfo"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// AcceptLanguageHeader - ключ метаданных с языками клиента в формате HTTP Accept-Language
+const AcceptLanguageHeader = "accept-language"
+
+// ErrorReasons - добавляет общую причину по коду (INVALID_ARGUMENT, INTERNAL...) к ошибкам,
+// у которых обработчик или интерцептор не указал свою: ErrorInfo есть у каждой ошибки сервера.
+// Затем добавляет LocalizedMessage с переводом по причине на язык из accept-language
+func ErrorReasons(messages *errinfo.Messages) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		return resp, messages.Localize(errinfo.Ensure(err), acceptLanguage(ctx))
+	}
+}
+
+// ErrorReasonsStream - то же, что ErrorReasons, для потоков
+func ErrorReasonsStream(messages *errinfo.Messages) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := handler(srv, ss)
+		if err == nil {
+			return nil
+		}
+
+		return messages.Localize(errinfo.Ensure(err), acceptLanguage(ss.Context()))
+	}
+}
+
+// acceptLanguage - значение accept-language из метаданных запроса (несколько значений склеиваются)
+func acceptLanguage(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return strings.Join(md.Get(AcceptLanguageHeader), ",")
+}
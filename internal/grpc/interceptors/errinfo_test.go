@@ -0,0 +1,44 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/grpc/errinfo"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestErrorReasons_Localized(t *testing.T) {
+	messages, err := errinfo.LoadMessages("en")
+	require.NoError(t, err)
+	interceptor := ErrorReasons(messages)
+
+	call := func(ctx context.Context, handlerErr error) error {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/auth.Auth/Login"},
+			func(context.Context, any) (any, error) { return nil, handlerErr })
+
+		return err
+	}
+
+	ru := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AcceptLanguageHeader, "ru-RU,ru;q=0.9,en;q=0.8"))
+
+	err = call(ru, status.Error(codes.Unauthenticated, "missing bearer token"))
+	st := status.Convert(err)
+	assert.Equal(t, "missing bearer token", st.Message())
+	require.Len(t, st.Details(), 2)
+	assert.Equal(t, "UNAUTHENTICATED", st.Details()[0].(*errdetails.ErrorInfo).GetReason())
+	assert.Equal(t, "ru", st.Details()[1].(*errdetails.LocalizedMessage).GetLocale())
+	assert.Equal(t, "Войдите, чтобы продолжить", st.Details()[1].(*errdetails.LocalizedMessage).GetMessage())
+
+	// Без accept-language - язык по умолчанию
+	msg := status.Convert(call(context.Background(), status.Error(codes.Unauthenticated, "missing bearer token"))).Details()[1]
+	assert.Equal(t, "en", msg.(*errdetails.LocalizedMessage).GetLocale())
+
+	assert.NoError(t, call(ru, nil))
+}
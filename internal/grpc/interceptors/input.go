@@ -0,0 +1,139 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Пределы длины строковых полей запроса в байтах
+const (
+	MaxEmailBytes    = 254  // RFC 5321: длина пути (адреса) не больше 256 с угловыми скобками
+	MaxPasswordBytes = 256  // bcrypt учитывает 72 байта; запас - на нормализацию и старые пароли из импорта
+	MaxStringBytes   = 8192 // остальные строки: самые длинные из них - токены
+)
+
+// InputLimits - отклоняет запросы со строковыми полями длиннее пределов, с невалидным UTF-8
+// и управляющими символами (включая NUL) до остальных интерцепторов и обработчика: bcrypt,
+// разбор токенов и запросы к базе не должны получать такие данные. Проверяются и вложенные
+// сообщения; bytes-поля (JSON, выгрузки) ограничены только размером сообщения
+func InputLimits() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := checkInput(req); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// InputLimitsStream - InputLimits для потоковых методов: проверяется каждое полученное сообщение
+func InputLimitsStream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &checkedStream{ServerStream: ss})
+	}
+}
+
+type checkedStream struct {
+	grpc.ServerStream
+}
+
+func (s *checkedStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	return checkInput(m)
+}
+
+// checkInput - InvalidArgument с путём первого неподходящего поля
+func checkInput(req any) error {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	if err := checkMessage(msg.ProtoReflect(), ""); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return nil
+}
+
+func checkMessage(m protoreflect.Message, prefix string) error {
+	var err error
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		path := prefix + string(fd.Name())
+
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := 0; i < list.Len() && err == nil; i++ {
+				err = checkValue(fd, list.Get(i), fmt.Sprintf("%s[%d]", path, i))
+			}
+		case fd.IsMap():
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				if fd.MapKey().Kind() == protoreflect.StringKind {
+					err = checkString(fd.MapKey(), k.String(), path+" key")
+				}
+				if err == nil {
+					err = checkValue(fd.MapValue(), mv, path+"["+k.String()+"]")
+				}
+
+				return err == nil
+			})
+		default:
+			err = checkValue(fd, v, path)
+		}
+
+		return err == nil
+	})
+
+	return err
+}
+
+func checkValue(fd protoreflect.FieldDescriptor, v protoreflect.Value, path string) error {
+	switch fd.Kind() {
+	case protoreflect.StringKind:
+		return checkString(fd, v.String(), path)
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return checkMessage(v.Message(), path+".")
+	default:
+		return nil
+	}
+}
+
+func checkString(fd protoreflect.FieldDescriptor, s, path string) error {
+	if limit := maxBytes(fd); len(s) > limit {
+		return fmt.Errorf("%s: must be at most %d bytes", path, limit)
+	}
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("%s: must be valid UTF-8", path)
+	}
+	if strings.IndexFunc(s, unicode.IsControl) >= 0 {
+		return fmt.Errorf("%s: must not contain control characters", path)
+	}
+
+	return nil
+}
+
+// maxBytes - предел длины поля по его имени
+func maxBytes(fd protoreflect.FieldDescriptor) int {
+	name := string(fd.Name())
+	switch {
+	case name == "email":
+		return MaxEmailBytes
+	case strings.HasSuffix(name, "password"):
+		return MaxPasswordBytes
+	default:
+		return MaxStringBytes
+	}
+}
@@ -0,0 +1,163 @@
+package interceptors
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// adversarial - строки, которые не должны дойти до обработчика ни в одном поле
+var adversarial = map[string]string{
+	"huge":          strings.Repeat("a", MaxStringBytes+1),
+	"invalid utf-8": "a\xff\xfeb",
+	"nul":           "a\x00b",
+	"newline":       "a\nb",
+	"c1 control":    "a\u0085b",
+}
+
+// stringFields - пути ко всем строковым полям сообщения и вложенных сообщений (для repeated - один элемент)
+func stringFields(md protoreflect.MessageDescriptor, depth int) [][]protoreflect.FieldDescriptor {
+	var paths [][]protoreflect.FieldDescriptor
+	fields := md.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		switch {
+		case fd.IsMap():
+		case fd.Kind() == protoreflect.StringKind:
+			paths = append(paths, []protoreflect.FieldDescriptor{fd})
+		case fd.Kind() == protoreflect.MessageKind && depth < 3:
+			for _, sub := range stringFields(fd.Message(), depth+1) {
+				paths = append(paths, append([]protoreflect.FieldDescriptor{fd}, sub...))
+			}
+		}
+	}
+
+	return paths
+}
+
+// withString - новое сообщение типа md, в котором по пути path записана строка s
+func withString(t *testing.T, md protoreflect.MessageDescriptor, path []protoreflect.FieldDescriptor, s string) proto.Message {
+	t.Helper()
+
+	mt, err := protoregistry.GlobalTypes.FindMessageByName(md.FullName())
+	require.NoError(t, err)
+
+	root := mt.New()
+	m := root
+	for _, fd := range path[:len(path)-1] {
+		if fd.IsList() {
+			m = m.Mutable(fd).List().AppendMutable().Message()
+			continue
+		}
+		m = m.Mutable(fd).Message()
+	}
+
+	last := path[len(path)-1]
+	if last.IsList() {
+		m.Mutable(last).List().Append(protoreflect.ValueOfString(s))
+	} else {
+		m.Set(last, protoreflect.ValueOfString(s))
+	}
+
+	return root.Interface()
+}
+
+// Каждый публичный метод Auth и Admin отклоняет опасные строки в любом строковом поле запроса
+func TestInputLimits_EveryRPC(t *testing.T) {
+	interceptor := InputLimits()
+	handler := func(context.Context, any) (any, error) { return "ok", nil }
+
+	for _, service := range []string{ssov1.Auth_ServiceDesc.ServiceName, ssov1.Admin_ServiceDesc.ServiceName} {
+		desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+		require.NoError(t, err)
+
+		methods := desc.(protoreflect.ServiceDescriptor).Methods()
+		for i := range methods.Len() {
+			method := methods.Get(i)
+			info := &grpc.UnaryServerInfo{FullMethod: "/" + service + "/" + string(method.Name())}
+
+			for _, path := range stringFields(method.Input(), 0) {
+				name := string(method.Name()) + "." + string(path[len(path)-1].Name())
+
+				_, err := interceptor(context.Background(), withString(t, method.Input(), path, "valid value"), info, handler)
+				require.NoError(t, err, name)
+
+				for kind, s := range adversarial {
+					_, err := interceptor(context.Background(), withString(t, method.Input(), path, s), info, handler)
+					assert.Equal(t, codes.InvalidArgument, status.Code(err), "%s: %s", name, kind)
+				}
+			}
+		}
+	}
+}
+
+func TestInputLimits_FieldLimits(t *testing.T) {
+	interceptor := InputLimits()
+	call := func(req any) error {
+		_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: ssov1.Auth_Login_FullMethodName},
+			func(context.Context, any) (any, error) { return nil, nil })
+
+		return err
+	}
+
+	require.NoError(t, call(&ssov1.LoginRequest{Email: strings.Repeat("a", MaxEmailBytes), Password: "пароль с пробелами"}))
+
+	err := call(&ssov1.LoginRequest{Email: strings.Repeat("a", MaxEmailBytes+1)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "email: must be at most 254 bytes")
+
+	// 10 МБ пароля отклоняются до bcrypt
+	err = call(&ssov1.LoginRequest{Email: "a@b.c", Password: strings.Repeat("p", 10<<20)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "password")
+
+	err = call(&ssov1.ChangePasswordRequest{OldPassword: "old", NewPassword: strings.Repeat("p", MaxPasswordBytes+1)})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Запросы без proto-сообщения не проверяются
+	require.NoError(t, call("not a message"))
+}
+
+type recvStream struct {
+	grpc.ServerStream
+	msgs []*ssov1.ImportUsersRequest
+}
+
+func (s *recvStream) Context() context.Context { return context.Background() }
+
+func (s *recvStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), s.msgs[0])
+	s.msgs = s.msgs[1:]
+
+	return nil
+}
+
+func TestInputLimitsStream(t *testing.T) {
+	ss := &recvStream{msgs: []*ssov1.ImportUsersRequest{
+		{Email: "a@b.c", PasswordHash: "$2a$10$hash"},
+		{Email: "b@b.c\x00", PasswordHash: "$2a$10$hash"},
+	}}
+
+	var errs []error
+	err := InputLimitsStream()(nil, ss, &grpc.StreamServerInfo{FullMethod: ssov1.Admin_ImportUsers_FullMethodName},
+		func(_ any, stream grpc.ServerStream) error {
+			for range 2 {
+				errs = append(errs, stream.RecvMsg(&ssov1.ImportUsersRequest{}))
+			}
+
+			return nil
+		})
+	require.NoError(t, err)
+	require.NoError(t, errs[0])
+	assert.Equal(t, codes.InvalidArgument, status.Code(errs[1]))
+}
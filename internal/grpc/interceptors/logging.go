@@ -0,0 +1,73 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/requestid"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// maxRequestIDLen - более длинные x-request-id считаем мусором и генерируем свой
+const maxRequestIDLen = 128
+
+// Logging - интерцептор, который обогащает логгер атрибутами запроса (метод, адрес клиента,
+// x-request-id, trace id), кладёт его в контекст для сервисного слоя и логирует завершение каждого запроса.
+// x-request-id берётся из метаданных (или генерируется) и возвращается клиенту в trailer
+func Logging(log *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		reqID := first(md, requestid.Header)
+		if reqID == "" || len(reqID) > maxRequestIDLen {
+			reqID = uuid.NewString()
+		}
+		ctx = requestid.Into(ctx, reqID)
+
+		// Отдаём идентификатор клиенту даже при ошибке
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(requestid.Header, reqID))
+
+		reqLog := log.With(
+			slog.String("method", info.FullMethod),
+			slog.String("request_id", reqID),
+		)
+		if p, ok := peer.FromContext(ctx); ok {
+			reqLog = reqLog.With(slog.String("peer", p.Addr.String()))
+		}
+		if ip, ok := clientip.From(ctx); ok {
+			reqLog = reqLog.With(slog.String("client_ip", ip.String()))
+		}
+		if traceID, _, ok := requestid.ParseTraceparent(first(md, requestid.TraceparentHeader)); ok {
+			ctx = requestid.TraceIntoContext(ctx, traceID)
+			reqLog = reqLog.With(slog.String("trace_id", traceID))
+		}
+
+		ctx = logctx.Into(ctx, reqLog)
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+
+		reqLog.Info("request finished",
+			slog.String("code", status.Code(err).String()),
+			slog.Duration("duration", time.Since(start)),
+		)
+
+		return resp, err
+	}
+}
+
+// first - первое значение ключа метаданных
+func first(md metadata.MD, key string) string {
+	if v := md.Get(key); len(v) > 0 {
+		return v[0]
+	}
+
+	return ""
+}
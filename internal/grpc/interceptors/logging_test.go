@@ -0,0 +1,84 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/requestid"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestLogging_PutsLoggerIntoContext(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/auth.Auth/Login"}
+	handler := func(ctx context.Context, req any) (any, error) {
+		reqLog := logctx.From(ctx)
+		require.NotNil(t, reqLog)
+		reqLog.Info("from service layer", slog.String("op", "Auth.Login"))
+
+		return "ok", nil
+	}
+
+	_, err := Logging(log)(context.Background(), nil, info, handler)
+	require.NoError(t, err)
+
+	assert.Regexp(t, `msg="from service layer" method=/auth.Auth/Login request_id=[0-9a-f-]{36} op=Auth.Login`, buf.String())
+	assert.Regexp(t, `msg="request finished" method=/auth.Auth/Login request_id=[0-9a-f-]{36} code=OK`, buf.String())
+}
+
+func TestLogging_RequestIDRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, nil))
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(Logging(log)))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	cc, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+
+	client := healthpb.NewHealthClient(cc)
+
+	t.Run("propagated", func(t *testing.T) {
+		ctx := metadata.AppendToOutgoingContext(context.Background(),
+			requestid.Header, "req-from-gateway",
+			requestid.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		)
+
+		var trailer metadata.MD
+		_, err := client.Check(ctx, &healthpb.HealthCheckRequest{}, grpc.Trailer(&trailer))
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"req-from-gateway"}, trailer.Get(requestid.Header))
+		assert.Contains(t, buf.String(), "request_id=req-from-gateway")
+		assert.Contains(t, buf.String(), "trace_id=4bf92f3577b34da6a3ce929d0e0e4736")
+	})
+
+	t.Run("generated", func(t *testing.T) {
+		var trailer metadata.MD
+		_, err := client.Check(context.Background(), &healthpb.HealthCheckRequest{}, grpc.Trailer(&trailer))
+		require.NoError(t, err)
+
+		require.Len(t, trailer.Get(requestid.Header), 1)
+		assert.Len(t, trailer.Get(requestid.Header)[0], 36)
+	})
+}
@@ -0,0 +1,72 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/grpc/errinfo"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ReasonMaintenance - ErrorInfo.Reason отказа в режиме обслуживания (домен "sso", как у обработчиков)
+const ReasonMaintenance = errinfo.ReasonMaintenance
+
+// MaintenanceMode - состояние режима обслуживания (maintenance.Mode)
+type MaintenanceMode interface {
+	Enabled() bool
+	AllowLogin() bool
+	RetryAfter() time.Duration
+}
+
+// ReadOnly - методы, которые работают в режиме обслуживания. Всё, что не перечислено, считается
+// изменяющим хранилище и отклоняется: новый метод не проскочит режим, если его забыли сюда добавить
+type ReadOnly struct {
+	Methods map[string]bool // полное имя метода -> работает всегда
+	Login   map[string]bool // методы входа: работают, если вход не запрещён (maintenance.block_login)
+}
+
+// allows - работает ли метод при текущих настройках режима
+func (r ReadOnly) allows(mode MaintenanceMode, fullMethod string) bool {
+	return r.Methods[fullMethod] || (r.Login[fullMethod] && mode.AllowLogin())
+}
+
+// Maintenance - в режиме обслуживания отклоняет изменяющие методы с Unavailable и RetryInfo
+func Maintenance(mode MaintenanceMode, readOnly ReadOnly) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if mode.Enabled() && !readOnly.allows(mode, info.FullMethod) {
+			return nil, maintenanceStatus(mode.RetryAfter())
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// MaintenanceStream - то же, что Maintenance, для потоковых методов
+func MaintenanceStream(mode MaintenanceMode, readOnly ReadOnly) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if mode.Enabled() && !readOnly.allows(mode, info.FullMethod) {
+			return maintenanceStatus(mode.RetryAfter())
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// maintenanceStatus - Unavailable с ErrorInfo{Reason: MAINTENANCE} и RetryInfo
+func maintenanceStatus(retryAfter time.Duration) error {
+	st := status.New(codes.Unavailable, "service is in maintenance mode, retry later")
+
+	withDetails, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: ReasonMaintenance, Domain: errinfo.Domain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
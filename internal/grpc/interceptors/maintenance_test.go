@@ -0,0 +1,62 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/config"
+	"sso/internal/lib/maintenance"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMaintenance(t *testing.T) {
+	mode := maintenance.New(config.MaintenanceConfig{RetryAfter: 30 * time.Second})
+	interceptor := Maintenance(mode, ReadOnly{
+		Methods: map[string]bool{"/auth.Auth/ValidateToken": true},
+		Login:   map[string]bool{"/auth.Auth/Login": true},
+	})
+
+	call := func(method string) error {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method},
+			func(context.Context, any) (any, error) { return nil, nil })
+
+		return err
+	}
+
+	require.NoError(t, call("/auth.Auth/Register"), "everything works outside maintenance mode")
+
+	mode.Set(true, "migration")
+
+	err := call("/auth.Auth/Register")
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	var retry *errdetails.RetryInfo
+	var info *errdetails.ErrorInfo
+	for _, d := range status.Convert(err).Details() {
+		switch d := d.(type) {
+		case *errdetails.RetryInfo:
+			retry = d
+		case *errdetails.ErrorInfo:
+			info = d
+		}
+	}
+	require.NotNil(t, retry)
+	assert.Equal(t, 30*time.Second, retry.GetRetryDelay().AsDuration())
+	require.NotNil(t, info)
+	assert.Equal(t, ReasonMaintenance, info.GetReason())
+
+	assert.NoError(t, call("/auth.Auth/ValidateToken"))
+	assert.NoError(t, call("/auth.Auth/Login"))
+
+	mode.Configure(config.MaintenanceConfig{BlockLogin: true, RetryAfter: 30 * time.Second})
+	assert.Equal(t, codes.Unavailable, status.Code(call("/auth.Auth/Login")))
+
+	mode.Set(false, "")
+	assert.NoError(t, call("/auth.Auth/Register"))
+}
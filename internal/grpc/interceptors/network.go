@@ -0,0 +1,88 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/logctx"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// ClientIP - интерцептор, который определяет адрес клиента (с учётом доверенных прокси)
+// и кладёт его в контекст (clientip): его записывают логи и события аудита.
+// header - ключ метаданных, в который прокси дописывают адрес клиента
+func ClientIP(resolver *clientip.Resolver, header string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(withClientIP(ctx, resolver, header), req)
+	}
+}
+
+// ClientIPStream - то же, что ClientIP, для потоковых методов
+func ClientIPStream(resolver *clientip.Resolver, header string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: ss, ctx: withClientIP(ss.Context(), resolver, header)})
+	}
+}
+
+func withClientIP(ctx context.Context, resolver *clientip.Resolver, header string) context.Context {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ctx
+	}
+	remote, ok := clientip.AddrOf(p.Addr)
+	if !ok {
+		return ctx
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	return clientip.Into(ctx, resolver.Resolve(remote, md.Get(strings.ToLower(header))))
+}
+
+// AllowNetworks - пускает к методам сервисов services только клиентов из сетей allowed
+// (адрес берётся из контекста, его кладёт ClientIP). Клиент с неизвестным адресом не пускается
+func AllowNetworks(services []string, allowed []netip.Prefix) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := checkNetwork(ctx, services, allowed, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AllowNetworksStream - то же, что AllowNetworks, для потоковых методов
+func AllowNetworksStream(services []string, allowed []netip.Prefix) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := checkNetwork(ss.Context(), services, allowed, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+func checkNetwork(ctx context.Context, services []string, allowed []netip.Prefix, fullMethod string) error {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !slices.Contains(services, service) {
+		return nil
+	}
+
+	addr, _ := clientip.From(ctx)
+	if clientip.Allowed(addr, allowed) {
+		return nil
+	}
+
+	logctx.FromOr(ctx, slog.Default()).Warn("admin call from a network outside the allowlist",
+		slog.String("client_ip", addr.String()))
+
+	return status.Error(codes.PermissionDenied, "method is not available from this network")
+}
@@ -0,0 +1,73 @@
+package interceptors
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sso/internal/lib/clientip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func peerContext(remote string, forwarded ...string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.ParseIP(remote), Port: 40000},
+	})
+	if len(forwarded) > 0 {
+		md := metadata.MD{}
+		for _, f := range forwarded {
+			md.Append("x-forwarded-for", f)
+		}
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+
+	return ctx
+}
+
+func TestClientIP(t *testing.T) {
+	resolver := clientip.NewResolver([]netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+	interceptor := ClientIP(resolver, "X-Forwarded-For")
+
+	resolve := func(ctx context.Context) string {
+		var got string
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/test/M"}, func(ctx context.Context, _ any) (any, error) {
+			ip, _ := clientip.From(ctx)
+			got = ip.String()
+
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		return got
+	}
+
+	assert.Equal(t, "198.51.100.4", resolve(peerContext("10.0.0.1", "198.51.100.4")))
+	assert.Equal(t, "203.0.113.9", resolve(peerContext("203.0.113.9", "198.51.100.4")), "header from an untrusted peer is ignored")
+}
+
+func TestAllowNetworks(t *testing.T) {
+	interceptor := AllowNetworks([]string{"auth.Admin"}, []netip.Prefix{netip.MustParsePrefix("10.20.0.0/16")})
+
+	call := func(ctx context.Context, method string) codes.Code {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+
+		return status.Code(err)
+	}
+
+	office := clientip.Into(context.Background(), netip.MustParseAddr("10.20.1.1"))
+	outside := clientip.Into(context.Background(), netip.MustParseAddr("198.51.100.4"))
+
+	assert.Equal(t, codes.OK, call(office, "/auth.Admin/ListUsers"))
+	assert.Equal(t, codes.PermissionDenied, call(outside, "/auth.Admin/ListUsers"))
+	assert.Equal(t, codes.PermissionDenied, call(context.Background(), "/auth.Admin/ListUsers"), "unknown address is denied")
+	assert.Equal(t, codes.OK, call(outside, "/auth.Auth/Login"), "other services are not restricted")
+}
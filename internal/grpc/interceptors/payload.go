@@ -0,0 +1,69 @@
+package interceptors
+
+import (
+	"context"
+	"log/slog"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/redact"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Payloads - записывает в лог (уровень debug) запрос и ответ каждого вызова в JSON, маскируя секретные
+// поля по registry. Только для отладки интеграций вне prod (grpc.debug_payloads); ставится после Logging,
+// чтобы записи были с x-request-id
+func Payloads(log *slog.Logger, registry *redact.Registry) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		reqLog := logctx.FromOr(ctx, log)
+		reqLog.Debug("request payload", slog.String("payload", payload(registry, req)))
+
+		resp, err := handler(ctx, req)
+		if err == nil {
+			reqLog.Debug("response payload", slog.String("payload", payload(registry, resp)))
+		}
+
+		return resp, err
+	}
+}
+
+// PayloadsStream - то же, что Payloads, для потоков: записывается каждое принятое и отправленное сообщение
+func PayloadsStream(log *slog.Logger, registry *redact.Registry) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &payloadStream{
+			ServerStream: ss,
+			log:          logctx.FromOr(ss.Context(), log).With(slog.String("method", info.FullMethod)),
+			registry:     registry,
+		})
+	}
+}
+
+type payloadStream struct {
+	grpc.ServerStream
+	log      *slog.Logger
+	registry *redact.Registry
+}
+
+func (s *payloadStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.log.Debug("stream message received", slog.String("payload", payload(s.registry, m)))
+
+	return nil
+}
+
+func (s *payloadStream) SendMsg(m any) error {
+	s.log.Debug("stream message sent", slog.String("payload", payload(s.registry, m)))
+
+	return s.ServerStream.SendMsg(m)
+}
+
+func payload(registry *redact.Registry, m any) string {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return "<not a proto message>"
+	}
+
+	return registry.JSON(msg)
+}
@@ -0,0 +1,49 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sso/internal/lib/redact"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestPayloads_LogsRedactedRequestAndResponse(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	info := &grpc.UnaryServerInfo{FullMethod: ssov1.Auth_Login_FullMethodName}
+
+	req := &ssov1.LoginRequest{Email: "a@b.c", Password: "hunter2", AppId: 1}
+	resp, err := Payloads(log, redact.Default)(context.Background(), req, info,
+		func(context.Context, any) (any, error) {
+			return &ssov1.LoginResponse{Token: "jwt-value", ExpiresIn: 60}, nil
+		})
+	require.NoError(t, err)
+	assert.Equal(t, "jwt-value", resp.(*ssov1.LoginResponse).GetToken(), "the response itself is not masked")
+
+	out := buf.String()
+	assert.Contains(t, out, "request payload")
+	assert.Contains(t, out, "a@b.c")
+	assert.Contains(t, out, "response payload")
+	assert.Contains(t, out, `\"expires_in\":\"60\"`)
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "jwt-value")
+}
+
+func TestPayloads_NoResponseOnError(t *testing.T) {
+	var buf bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	info := &grpc.UnaryServerInfo{FullMethod: ssov1.Auth_Login_FullMethodName}
+
+	_, err := Payloads(log, redact.Default)(context.Background(), &ssov1.LoginRequest{}, info,
+		func(context.Context, any) (any, error) { return nil, errors.New("boom") })
+	require.Error(t, err)
+	assert.Contains(t, buf.String(), "request payload")
+	assert.NotContains(t, buf.String(), "response payload")
+}
@@ -0,0 +1,17 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/storage"
+
+	"google.golang.org/grpc"
+)
+
+// PinScope - интерцептор, открывающий область закрепления за основной БД на время запроса:
+// после записи, которую реплика может ещё не получить (например, регистрации), storage.PinPrimary
+// направляет на основную БД все дальнейшие чтения этого запроса
+func PinScope() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return handler(storage.WithPinScope(ctx), req)
+	}
+}
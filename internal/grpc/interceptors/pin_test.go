@@ -0,0 +1,29 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestPinScope(t *testing.T) {
+	var pinned bool
+	handler := func(ctx context.Context, _ any) (any, error) {
+		// Закрепление во вложенном вызове видно всему запросу
+		func(ctx context.Context) { storage.PinPrimary(ctx) }(ctx)
+		pinned = storage.PinnedToPrimary(ctx)
+
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	_, err := PinScope()(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+	require.NoError(t, err)
+
+	assert.True(t, pinned)
+	assert.False(t, storage.PinnedToPrimary(ctx))
+}
@@ -0,0 +1,76 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/grpc/errinfo"
+	"strconv"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ReasonRateLimited - ErrorInfo.Reason отказа из-за превышения лимита запросов
+const ReasonRateLimited = errinfo.ReasonRateLimited
+
+// RateLimiter - лимиты запросов по методу и приложению (ratelimit.Limiter)
+type RateLimiter interface {
+	Allow(method string, appID int) (time.Duration, bool)
+}
+
+// appIDRequest - запрос, в котором есть app_id
+type appIDRequest interface {
+	GetAppId() int32
+}
+
+// RateLimit - отклоняет запросы сверх лимита с ResourceExhausted, RetryInfo и заголовком retry-after.
+// app_id берётся из сообщения запроса, если в нём есть такое поле
+func RateLimit(limiter RateLimiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var appID int
+		if r, ok := req.(appIDRequest); ok {
+			appID = int(r.GetAppId())
+		}
+
+		if retryAfter, ok := limiter.Allow(info.FullMethod, appID); !ok {
+			return nil, rateLimited(ctx, retryAfter)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// RateLimitStream - то же, что RateLimit, для открытия потоков (app_id не известен до первого сообщения)
+func RateLimitStream(limiter RateLimiter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if retryAfter, ok := limiter.Allow(info.FullMethod, 0); !ok {
+			return rateLimited(ss.Context(), retryAfter)
+		}
+
+		return handler(srv, ss)
+	}
+}
+
+// rateLimited - ResourceExhausted с ErrorInfo{Reason: RATE_LIMITED} и RetryInfo.
+// Задержка дублируется в заголовке retry-after (в целых секундах, с округлением вверх)
+// для клиентов, которые не разбирают детали ошибки
+func rateLimited(ctx context.Context, retryAfter time.Duration) error {
+	seconds := int64((retryAfter + time.Second - 1) / time.Second)
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.FormatInt(seconds, 10)))
+
+	st := status.New(codes.ResourceExhausted, "too many requests, retry later")
+
+	withDetails, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: ReasonRateLimited, Domain: errinfo.Domain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+	)
+	if err != nil {
+		return st.Err()
+	}
+
+	return withDetails.Err()
+}
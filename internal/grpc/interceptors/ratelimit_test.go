@@ -0,0 +1,56 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/config"
+	"sso/internal/lib/ratelimit"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRateLimit(t *testing.T) {
+	limiter := ratelimit.New(config.RateLimitConfig{
+		Enabled: true,
+		Rules: []config.RateLimitRule{
+			{Method: ssov1.Auth_Login_FullMethodName, PerApp: true, Rate: 0.5, Burst: 1},
+		},
+	})
+	interceptor := RateLimit(limiter)
+
+	call := func(appID int32) error {
+		_, err := interceptor(context.Background(), &ssov1.LoginRequest{AppId: appID},
+			&grpc.UnaryServerInfo{FullMethod: ssov1.Auth_Login_FullMethodName},
+			func(context.Context, any) (any, error) { return nil, nil })
+
+		return err
+	}
+
+	require.NoError(t, call(1))
+	require.NoError(t, call(2), "app_id is taken from the request")
+
+	err := call(1)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	var retry *errdetails.RetryInfo
+	var info *errdetails.ErrorInfo
+	for _, d := range status.Convert(err).Details() {
+		switch d := d.(type) {
+		case *errdetails.RetryInfo:
+			retry = d
+		case *errdetails.ErrorInfo:
+			info = d
+		}
+	}
+	require.NotNil(t, retry)
+	assert.InDelta(t, 2*time.Second, retry.GetRetryDelay().AsDuration(), float64(100*time.Millisecond))
+	require.NotNil(t, info)
+	assert.Equal(t, ReasonRateLimited, info.GetReason())
+}
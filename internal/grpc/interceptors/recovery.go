@@ -0,0 +1,154 @@
+package interceptors
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
+	"sso/internal/lib/errreport"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/requestid"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// unexpectedCodes - коды, которые означают ошибку сервера, а не отказ по правилам: о них сообщается в трекер
+var unexpectedCodes = []codes.Code{codes.Internal, codes.Unknown, codes.DataLoss}
+
+// secretFieldParts - поля запроса, в имени которых есть одна из этих частей, в отчёт не попадают:
+// пароли, токены, коды подтверждения, ключи и хеши
+var secretFieldParts = []string{"password", "token", "secret", "code", "hash", "credential", "key", "otp", "assertion"}
+
+// maxReportedField - сколько байт строкового поля попадает в отчёт
+const maxReportedField = 256
+
+// Recovery - перехватывает панику обработчика и последующих интерцепторов: записывает её в лог со стеком
+// и отвечает INTERNAL, не роняя сервер. О панике и об ответах INTERNAL (UNKNOWN, DATA_LOSS) сообщает
+// в reporter вместе с методом, x-request-id и полями запроса без секретов. Ставится после Logging,
+// чтобы в отчёт и лог попал идентификатор запроса
+func Recovery(log *slog.Logger, reporter errreport.Reporter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				stack := string(debug.Stack())
+				logctx.FromOr(ctx, log).Error("panic in handler",
+					slog.Any("panic", p),
+					slog.String("stack", stack))
+
+				resp, err = nil, status.Error(codes.Internal, "internal error")
+				report(ctx, reporter, info.FullMethod, req, fmt.Sprintf("panic: %v", p), codes.Internal, stack)
+
+				return
+			}
+
+			if code := status.Code(err); slices.Contains(unexpectedCodes, code) {
+				report(ctx, reporter, info.FullMethod, req, status.Convert(err).Message(), code, "")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStream - то же, что Recovery, для потоков (без полей запроса: сообщения читает обработчик)
+func RecoveryStream(log *slog.Logger, reporter errreport.Reporter) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx := ss.Context()
+
+		defer func() {
+			if p := recover(); p != nil {
+				stack := string(debug.Stack())
+				logctx.FromOr(ctx, log).Error("panic in stream handler",
+					slog.String("method", info.FullMethod),
+					slog.Any("panic", p),
+					slog.String("stack", stack))
+
+				err = status.Error(codes.Internal, "internal error")
+				report(ctx, reporter, info.FullMethod, nil, fmt.Sprintf("panic: %v", p), codes.Internal, stack)
+
+				return
+			}
+
+			if code := status.Code(err); slices.Contains(unexpectedCodes, code) {
+				report(ctx, reporter, info.FullMethod, nil, status.Convert(err).Message(), code, "")
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+// report - отправляет отчёт; паника в reporter не должна стать ответом клиенту или уронить сервер
+func report(ctx context.Context, reporter errreport.Reporter, method string, req any, msg string, code codes.Code, stack string) {
+	defer func() {
+		_ = recover()
+	}()
+
+	reporter.Report(errreport.Report{
+		Message:   msg,
+		Method:    method,
+		Code:      code.String(),
+		RequestID: requestid.From(ctx),
+		TraceID:   requestid.TraceFrom(ctx),
+		Fields:    sanitizedFields(req),
+		Stack:     stack,
+		Time:      time.Now(),
+	})
+}
+
+// sanitizedFields - поля верхнего уровня запроса для отчёта: секретные поля заменяются на "[redacted]",
+// bytes - на размер, вложенные сообщения и списки - на краткое описание
+func sanitizedFields(req any) map[string]string {
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	fields := make(map[string]string)
+	msg.ProtoReflect().Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		name := string(fd.Name())
+
+		switch {
+		case secretField(name):
+			fields[name] = "[redacted]"
+		case fd.IsList():
+			fields[name] = fmt.Sprintf("[%d items]", v.List().Len())
+		case fd.IsMap():
+			fields[name] = fmt.Sprintf("[%d entries]", v.Map().Len())
+		case fd.Kind() == protoreflect.BytesKind:
+			fields[name] = fmt.Sprintf("[%d bytes]", len(v.Bytes()))
+		case fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind:
+			fields[name] = "[" + string(fd.Message().Name()) + "]"
+		case fd.Kind() == protoreflect.StringKind:
+			s := v.String()
+			if len(s) > maxReportedField {
+				s = s[:maxReportedField] + "..."
+			}
+			fields[name] = s
+		default:
+			fields[name] = v.String()
+		}
+
+		return true
+	})
+
+	return fields
+}
+
+func secretField(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretFieldParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,97 @@
+package interceptors
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sso/internal/lib/errreport"
+	"sso/internal/lib/requestid"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type reportRecorder struct {
+	reports []errreport.Report
+}
+
+func (r *reportRecorder) Report(rep errreport.Report) { r.reports = append(r.reports, rep) }
+
+type panickingReporter struct{}
+
+func (panickingReporter) Report(errreport.Report) { panic("reporter is broken") }
+
+func TestRecovery_Panic(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := &reportRecorder{}
+	info := &grpc.UnaryServerInfo{FullMethod: ssov1.Auth_Login_FullMethodName}
+	ctx := requestid.Into(context.Background(), "req-1")
+
+	req := &ssov1.LoginRequest{Email: "a@b.c", Password: "hunter2", AppId: 1, CaptchaToken: "captcha"}
+	resp, err := Recovery(slog.New(slog.NewTextHandler(&buf, nil)), reporter)(ctx, req, info,
+		func(context.Context, any) (any, error) { panic("boom") })
+	require.Nil(t, resp)
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, "internal error", status.Convert(err).Message())
+	assert.Contains(t, buf.String(), "panic in handler")
+
+	require.Len(t, reporter.reports, 1)
+	r := reporter.reports[0]
+	assert.Equal(t, "panic: boom", r.Message)
+	assert.Equal(t, ssov1.Auth_Login_FullMethodName, r.Method)
+	assert.Equal(t, "req-1", r.RequestID)
+	assert.Contains(t, r.Stack, "recovery_test.go")
+	assert.Equal(t, map[string]string{
+		"email": "a@b.c", "password": "[redacted]", "app_id": "1", "captcha_token": "[redacted]",
+	}, r.Fields)
+	assert.NotContains(t, r.Stack+r.Message, "hunter2")
+}
+
+func TestRecovery_ReportsOnlyUnexpectedErrors(t *testing.T) {
+	reporter := &reportRecorder{}
+	interceptor := Recovery(slog.Default(), reporter)
+	info := &grpc.UnaryServerInfo{FullMethod: ssov1.Auth_Login_FullMethodName}
+	call := func(err error) {
+		_, got := interceptor(context.Background(), &ssov1.LoginRequest{}, info,
+			func(context.Context, any) (any, error) { return nil, err })
+		require.Equal(t, err, got)
+	}
+
+	call(nil)
+	call(status.Error(codes.InvalidArgument, "email is required"))
+	call(status.Error(codes.Unauthenticated, "invalid credentials"))
+	assert.Empty(t, reporter.reports)
+
+	call(status.Error(codes.Internal, "internal error"))
+	call(errors.New("raw error"))
+	require.Len(t, reporter.reports, 2)
+	assert.Equal(t, "Internal", reporter.reports[0].Code)
+	assert.Equal(t, "Unknown", reporter.reports[1].Code)
+	assert.Empty(t, reporter.reports[0].Stack)
+}
+
+// Паника в самом reporter не меняет ответ и не роняет сервер
+func TestRecovery_ReporterPanics(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: ssov1.Auth_Login_FullMethodName}
+
+	_, err := Recovery(slog.Default(), panickingReporter{})(context.Background(), nil, info,
+		func(context.Context, any) (any, error) { panic("boom") })
+	assert.Equal(t, codes.Internal, status.Code(err))
+
+	err = RecoveryStream(slog.Default(), panickingReporter{})(nil, &recvStream{}, &grpc.StreamServerInfo{FullMethod: ssov1.Admin_ImportUsers_FullMethodName},
+		func(any, grpc.ServerStream) error { panic("boom") })
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestSanitizedFields(t *testing.T) {
+	fields := sanitizedFields(&ssov1.ChangePasswordRequest{OldPassword: "old", NewPassword: "new"})
+	assert.Equal(t, map[string]string{"old_password": "[redacted]", "new_password": "[redacted]"}, fields)
+
+	assert.Nil(t, sanitizedFields("not a message"))
+}
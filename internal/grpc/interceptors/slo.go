@@ -0,0 +1,169 @@
+package interceptors
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"slices"
+	"sso/internal/config"
+	"sso/internal/grpc/errinfo"
+	"sso/internal/lib/metrics"
+	"strings"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+// Имена переменных в /debug/vars. Их же используют правила записи (RecordingRules): переименовывать
+// только вместе с правилами в Prometheus
+const (
+	MetricRequestDuration = "grpc_request_duration_seconds"      // гистограмма длительности по методам
+	MetricSLORequests     = "grpc_slo_requests_total"            // все запросы по методам
+	MetricSLOFailed       = "grpc_slo_requests_failed"           // запросы, не выполненные по вине сервера
+	MetricSLOLatencyGood  = "grpc_slo_latency_good"              // запросы, уложившиеся в порог задержки
+	MetricSLOThresholds   = "grpc_slo_latency_threshold_seconds" // пороги задержки из конфигурации
+)
+
+var (
+	requestDuration = metrics.NewHistogramVec(metrics.DurationBuckets)
+	sloRequests     = expvar.NewMap(MetricSLORequests)
+	sloFailed       = expvar.NewMap(MetricSLOFailed)
+	sloLatencyGood  = expvar.NewMap(MetricSLOLatencyGood)
+	sloThresholds   = expvar.NewMap(MetricSLOThresholds)
+)
+
+func init() {
+	expvar.Publish(MetricRequestDuration, requestDuration)
+}
+
+// clientCodes - ошибки по вине клиента: запрос выполнен правильно, доступность сервиса они не снижают.
+// ResourceExhausted - отказ по лимиту запросов клиента; перегрузка сервера (AUTH_OVERLOADED) считается отказом
+var clientCodes = []codes.Code{
+	codes.OK,
+	codes.Canceled,
+	codes.InvalidArgument,
+	codes.NotFound,
+	codes.AlreadyExists,
+	codes.PermissionDenied,
+	codes.ResourceExhausted,
+	codes.FailedPrecondition,
+	codes.Aborted,
+	codes.OutOfRange,
+	codes.Unauthenticated,
+}
+
+// SLO - пороги задержки по методам (config.SLOConfig), проверенные по зарегистрированным сервисам
+type SLO struct {
+	thresholds     map[string]time.Duration
+	defaultLatency time.Duration
+}
+
+// NewSLO - пороги из конфигурации. Метод, которого нет ни в одном сервисе, - ошибка запуска:
+// опечатка в имени иначе тихо оставила бы метод без SLO
+func NewSLO(cfg config.SLOConfig) (*SLO, error) {
+	const op = "interceptors.NewSLO"
+
+	s := &SLO{thresholds: make(map[string]time.Duration, len(cfg.Latency)), defaultLatency: cfg.DefaultLatency}
+
+	var errs []error
+	for _, l := range cfg.Latency {
+		if !methodExists(l.Method) {
+			errs = append(errs, fmt.Errorf("grpc.slo: unknown method %q", l.Method))
+			continue
+		}
+		s.thresholds[l.Method] = l.Threshold
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for method, threshold := range s.thresholds {
+		f := new(expvar.Float)
+		f.Set(threshold.Seconds())
+		sloThresholds.Set(method, f)
+	}
+
+	return s, nil
+}
+
+// threshold - порог задержки метода (0 - задержка не учитывается)
+func (s *SLO) threshold(method string) time.Duration {
+	if t, ok := s.thresholds[method]; ok {
+		return t
+	}
+
+	return s.defaultLatency
+}
+
+// Metrics - длительность каждого запроса по методам (MetricRequestDuration) и, если slo не nil, счётчики SLO:
+// всего запросов, отказов по вине сервера и запросов быстрее порога. Ставится первым в цепочке,
+// чтобы учитывать время всех интерцепторов и итоговый статус ответа
+func Metrics(slo *SLO) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		elapsed := time.Since(start)
+
+		requestDuration.With(info.FullMethod).Observe(elapsed.Seconds())
+		if slo != nil {
+			slo.observe(info.FullMethod, elapsed, err)
+		}
+
+		return resp, err
+	}
+}
+
+func (s *SLO) observe(method string, elapsed time.Duration, err error) {
+	sloRequests.Add(method, 1)
+
+	if serverFault(err) {
+		sloFailed.Add(method, 1)
+		return
+	}
+
+	// Быстрый отказ по вине сервера не должен улучшать задержку, поэтому хорошими считаются только
+	// запросы без такого отказа
+	if t := s.threshold(method); t > 0 && elapsed <= t {
+		sloLatencyGood.Add(method, 1)
+	}
+}
+
+// serverFault - запрос не выполнен по вине сервера
+func serverFault(err error) bool {
+	st := status.Convert(err)
+	if !slices.Contains(clientCodes, st.Code()) {
+		return true
+	}
+
+	if st.Code() == codes.ResourceExhausted {
+		for _, d := range st.Details() {
+			if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetReason() == errinfo.ReasonOverloaded {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// methodExists - есть ли метод /пакет.Сервис/Метод среди зарегистрированных proto-сервисов
+func methodExists(fullMethod string) bool {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return false
+	}
+
+	desc, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return false
+	}
+	sd, ok := desc.(protoreflect.ServiceDescriptor)
+
+	return ok && sd.Methods().ByName(protoreflect.Name(method)) != nil
+}
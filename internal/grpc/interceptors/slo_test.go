@@ -0,0 +1,97 @@
+package interceptors
+
+import (
+	"context"
+	"expvar"
+	"sso/internal/config"
+	"sso/internal/grpc/errinfo"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func counter(m *expvar.Map, method string) int64 {
+	if v, ok := m.Get(method).(*expvar.Int); ok {
+		return v.Value()
+	}
+
+	return 0
+}
+
+func TestNewSLO_UnknownMethod(t *testing.T) {
+	_, err := NewSLO(config.SLOConfig{Latency: []config.SLOLatency{
+		{Method: ssov1.Auth_Login_FullMethodName, Threshold: time.Second},
+		{Method: "/auth.Auth/Logn", Threshold: time.Second},
+		{Method: "/auth.Nope/Login", Threshold: time.Second},
+	}})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, `"/auth.Auth/Logn"`)
+	assert.ErrorContains(t, err, `"/auth.Nope/Login"`)
+	assert.NotContains(t, err.Error(), `"/auth.Auth/Login"`)
+}
+
+func TestMetrics_SLO(t *testing.T) {
+	const method = ssov1.Auth_Register_FullMethodName
+
+	slo, err := NewSLO(config.SLOConfig{Latency: []config.SLOLatency{{Method: method, Threshold: 20 * time.Millisecond}}})
+	require.NoError(t, err)
+	interceptor := Metrics(slo)
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+
+	call := func(delay time.Duration, err error) {
+		_, _ = interceptor(context.Background(), nil, info, func(context.Context, any) (any, error) {
+			time.Sleep(delay)
+			return nil, err
+		})
+	}
+
+	total, failed, good := counter(sloRequests, method), counter(sloFailed, method), counter(sloLatencyGood, method)
+
+	call(0, nil)                                                        // хороший
+	call(0, status.Error(codes.InvalidArgument, "bad email"))           // ошибка клиента, быстрый
+	call(50*time.Millisecond, nil)                                      // медленный
+	call(0, status.Error(codes.Internal, "internal error"))             // отказ сервера
+	call(0, status.Error(codes.ResourceExhausted, "too many requests")) // лимит клиента
+	call(0, overloaded(t))                                              // перегрузка сервера
+
+	assert.Equal(t, int64(6), counter(sloRequests, method)-total)
+	assert.Equal(t, int64(2), counter(sloFailed, method)-failed)
+	// Ошибки клиента в пороге задержки - хорошие запросы, отказы сервера - нет
+	assert.Equal(t, int64(3), counter(sloLatencyGood, method)-good)
+}
+
+func overloaded(t *testing.T) error {
+	t.Helper()
+
+	st, err := status.New(codes.ResourceExhausted, "server is overloaded").
+		WithDetails(&errdetails.ErrorInfo{Reason: errinfo.ReasonOverloaded, Domain: errinfo.Domain})
+	require.NoError(t, err)
+
+	return st.Err()
+}
+
+func TestRecordingRules(t *testing.T) {
+	rules, err := RecordingRules(config.SLOConfig{Latency: []config.SLOLatency{
+		{Method: ssov1.Auth_Login_FullMethodName, Threshold: 500 * time.Millisecond},
+	}}, []time.Duration{5 * time.Minute, time.Hour})
+	require.NoError(t, err)
+
+	out := string(rules)
+	assert.Contains(t, out, "record: grpc:slo_availability_errors:ratio_rate5m")
+	assert.Contains(t, out, "record: grpc:slo_latency_errors:ratio_rate1h")
+	assert.Contains(t, out, MetricSLOFailed+"[1h]")
+	assert.Contains(t, out, MetricSLOLatencyGood+`{method=~"/auth\\.Auth/Login"}[5m]`)
+
+	// Без порогов правил задержки нет
+	rules, err = RecordingRules(config.SLOConfig{}, nil)
+	require.NoError(t, err)
+	assert.NotContains(t, string(rules), "latency")
+	assert.Contains(t, string(rules), "ratio_rate6h")
+}
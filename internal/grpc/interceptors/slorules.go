@@ -0,0 +1,94 @@
+package interceptors
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"slices"
+	"sso/internal/config"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultRuleWindows - окна правил записи: короткие и длинные для оповещений о скорости расхода бюджета ошибок
+var DefaultRuleWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+type ruleFile struct {
+	Groups []ruleGroup `yaml:"groups"`
+}
+
+type ruleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []rule `yaml:"rules"`
+}
+
+type rule struct {
+	Record string `yaml:"record"`
+	Expr   string `yaml:"expr"`
+}
+
+// RecordingRules - правила записи Prometheus для счётчиков SLO из cfg: доля отказов по вине сервера
+// и доля запросов медленнее порога по методам для каждого окна. Счётчики берутся по именам из /debug/vars,
+// метка method - ключ в объекте счётчика. Задержка считается только для методов с порогом
+// (всех, если задан default_latency)
+func RecordingRules(cfg config.SLOConfig, windows []time.Duration) ([]byte, error) {
+	const op = "interceptors.RecordingRules"
+
+	if len(windows) == 0 {
+		windows = DefaultRuleWindows
+	}
+
+	latency := cfg.DefaultLatency > 0 || len(cfg.Latency) > 0
+	selector := ""
+	if cfg.DefaultLatency == 0 {
+		methods := make([]string, 0, len(cfg.Latency))
+		for _, l := range cfg.Latency {
+			// Точки в именах методов экранируются; в строке PromQL обратная косая черта удваивается
+			methods = append(methods, strings.ReplaceAll(regexp.QuoteMeta(l.Method), `\`, `\\`))
+		}
+		slices.Sort(methods)
+		selector = fmt.Sprintf(`{method=~"%s"}`, strings.Join(methods, "|"))
+	}
+
+	group := ruleGroup{Name: "sso-slo"}
+	for _, w := range windows {
+		window := promDuration(w)
+
+		group.Rules = append(group.Rules, rule{
+			Record: "grpc:slo_availability_errors:ratio_rate" + window,
+			Expr: fmt.Sprintf("sum by (method) (rate(%s[%s]))\n/\nsum by (method) (rate(%s[%s]))",
+				MetricSLOFailed, window, MetricSLORequests, window),
+		})
+		if !latency {
+			continue
+		}
+		group.Rules = append(group.Rules, rule{
+			Record: "grpc:slo_latency_errors:ratio_rate" + window,
+			Expr: fmt.Sprintf("1 - (\n  sum by (method) (rate(%s%s[%s]))\n  /\n  sum by (method) (rate(%s%s[%s]))\n)",
+				MetricSLOLatencyGood, selector, window, MetricSLORequests, selector, window),
+		})
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(ruleFile{Groups: []ruleGroup{group}}); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// promDuration - длительность в записи Prometheus (5m, 1h, 90s)
+func promDuration(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return fmt.Sprintf("%ds", d/time.Second)
+	}
+}
@@ -0,0 +1,22 @@
+package interceptors
+
+import (
+	"context"
+	"sso/internal/lib/useragent"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// UserAgent - кладёт в контекст (useragent) User-Agent клиента из метаданных запроса.
+// По нему история входов отличает новые устройства
+func UserAgent() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if ua := md.Get("user-agent"); len(ua) > 0 {
+			ctx = useragent.Into(ctx, ua[0])
+		}
+
+		return handler(ctx, req)
+	}
+}
@@ -0,0 +1,186 @@
+package audit
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/requestid"
+	"sso/internal/lib/selftest"
+	"time"
+
+	"google.golang.org/grpc/peer"
+)
+
+// Имена событий безопасности. Это контракт с SIEM: переименовывать нельзя
+const (
+	EventLoginSucceeded = "login.succeeded"
+	EventLoginFailed    = "login.failed"
+	EventUserRegistered = "user.registered"
+	EventRegisterFailed = "user.register_failed"
+	EventUserExported   = "user.data_exported"
+	EventUserErased     = "user.erased"
+
+	EventAccountDeletionRequested = "account.deletion_requested"
+	EventAccountDeletionCancelled = "account.deletion_cancelled"
+	EventAccountDeletionExecuted  = "account.deletion_executed"
+
+	EventPasswordChanged      = "password.changed"
+	EventPasswordChangeFailed = "password.change_failed"
+
+	EventUserInvited        = "user.invited"
+	EventInvitationAccepted = "invitation.accepted"
+	EventInvitationRevoked  = "invitation.revoked"
+
+	EventConsentGranted = "consent.granted"
+	EventConsentRevoked = "consent.revoked"
+
+	EventTOSAccepted = "tos.accepted"
+
+	EventGuestCreated  = "guest.created"
+	EventGuestUpgraded = "guest.upgraded"
+
+	EventDeviceApproved = "device.approved"
+	EventDeviceDenied   = "device.denied"
+
+	EventPasskeyRegistered   = "passkey.registered"
+	EventPasskeyDeleted      = "passkey.deleted"
+	EventPasskeyCloneWarning = "passkey.clone_warning"
+
+	EventSMSCodeSent    = "sms.code_sent"
+	EventSMSSendLimited = "sms.send_limited"
+	EventPhoneVerified  = "phone.verified"
+	EventPhoneRemoved   = "phone.removed"
+
+	EventOIDCCodeIssued    = "oidc.code_issued"
+	EventOIDCCodeExchanged = "oidc.code_exchanged"
+
+	EventOfflineTokenIssued  = "offline_token.issued"
+	EventOfflineTokenRevoked = "offline_token.revoked"
+
+	EventUserProvisioned        = "provisioning.user_provisioned"
+	EventUserDeprovisioned      = "provisioning.user_deprovisioned"
+	EventProvisionedUsersListed = "provisioning.users_listed"
+
+	EventSessionEvicted = "session.evicted"
+	EventSessionEnded   = "session.ended"
+	EventTokenRevoked   = "token.revoked"
+
+	EventTokenRefreshed     = "token.refreshed"
+	EventTokenRefreshFailed = "token.refresh_failed"
+
+	EventStepUpSucceeded = "login.step_up_succeeded"
+	EventStepUpFailed    = "login.step_up_failed"
+	EventLoginSuspicious = "login.suspicious"
+)
+
+// Результат операции
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event - запись журнала безопасности
+type Event struct {
+	Name    string // Имя события (EventLoginSucceeded и т. д.)
+	Outcome string // OutcomeSuccess или OutcomeFailure
+	UserID  int64  // ID пользователя (0, если неизвестен)
+	AppID   int    // ID приложения (0, если неприменимо)
+	Email   string // Email, с которым пришёл запрос
+	Reason  string // Причина отказа
+	ActorID int64  // Кто выполнил действие (администратор); по умолчанию берётся из authctx
+}
+
+// Recorder - дополнительный получатель событий (StoreSink)
+type Recorder interface {
+	Record(r models.AuditRecord)
+}
+
+// Logger - журнал событий безопасности, отдельный от обычного лога приложения
+type Logger struct {
+	log      *slog.Logger
+	recorder Recorder // nil - события только в журнале
+	now      func() time.Time
+}
+
+// New - создаёт журнал, пишущий JSON-записи в w
+func New(w io.Writer) *Logger {
+	return &Logger{log: slog.New(slog.NewJSONHandler(w, nil)), now: time.Now}
+}
+
+// WithRecorder - журнал, который кроме w передаёт каждое событие в r
+func (l *Logger) WithRecorder(r Recorder) *Logger {
+	return &Logger{log: l.log, recorder: r, now: l.now}
+}
+
+// Nop - журнал, который ничего не пишет (для тестов и по умолчанию)
+func Nop() *Logger {
+	return New(io.Discard)
+}
+
+// Emit - записывает событие; адрес клиента берётся из gRPC-контекста
+func (l *Logger) Emit(ctx context.Context, e Event) {
+	attrs := []slog.Attr{
+		slog.String("event", e.Name),
+		slog.String("outcome", e.Outcome),
+	}
+
+	if e.UserID != 0 {
+		attrs = append(attrs, slog.Int64("uid", e.UserID))
+	}
+	if e.AppID != 0 {
+		attrs = append(attrs, slog.Int("app_id", e.AppID))
+	}
+	if e.Email != "" {
+		attrs = append(attrs, slog.String("email", e.Email))
+	}
+	if e.Reason != "" {
+		attrs = append(attrs, slog.String("reason", e.Reason))
+	}
+	if e.ActorID == 0 {
+		if p, ok := authctx.From(ctx); ok {
+			e.ActorID = p.UserID
+		}
+	}
+	if e.ActorID != 0 {
+		attrs = append(attrs, slog.Int64("actor_uid", e.ActorID))
+	}
+	requestID := requestid.From(ctx)
+	if requestID != "" {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	// За прокси адрес соединения - адрес прокси, поэтому записываем адрес клиента, если он известен
+	var addr string
+	if ip, ok := clientip.From(ctx); ok {
+		addr = ip.String()
+		attrs = append(attrs, slog.String("ip", addr))
+	} else if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+		attrs = append(attrs, slog.String("peer", addr))
+	}
+
+	// События самопроверки остаются в журнале с пометкой, но не попадают в хранилище и его экспорт
+	selfTest := selftest.From(ctx)
+	if selfTest {
+		attrs = append(attrs, slog.Bool("selftest", true))
+	}
+
+	l.log.LogAttrs(ctx, slog.LevelInfo, "security event", attrs...)
+
+	if l.recorder != nil && !selfTest {
+		l.recorder.Record(models.AuditRecord{
+			OccurredAt: l.now(),
+			Event:      e.Name,
+			Outcome:    e.Outcome,
+			UserID:     e.UserID,
+			AppID:      e.AppID,
+			Email:      e.Email,
+			Reason:     e.Reason,
+			ActorID:    e.ActorID,
+			RequestID:  requestID,
+			IP:         addr,
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package audit
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"log/syslog"
+	"os"
+	"sso/internal/config"
+	"sso/internal/lib/logger"
+	"sync"
+	"time"
+)
+
+// retryInterval - как часто пытаться переоткрыть недоступный приёмник
+const retryInterval = 5 * time.Second
+
+// Sink - неблокирующий приёмник журнала безопасности.
+// Запись кладётся в буфер в памяти и пишется в фоне; если приёмник недоступен (syslog упал, диск переполнен),
+// записи копятся до bufferSize штук (старые вытесняются), а в лог приложения
+// уходит громкое предупреждение. Логины никогда не ждут приёмник
+type Sink struct {
+	open func() (io.WriteCloser, error) // открывает приёмник заново
+	warn *slog.Logger                   // лог приложения для предупреждений
+
+	wake chan struct{} // сигнал фоновой горутине, что появились записи
+	stop chan struct{}
+	done chan struct{}
+
+	mu         sync.Mutex
+	pending    [][]byte // записи, ожидающие записи в приёмник
+	maxPending int
+	dropped    int // сколько записей потеряно из-за переполнения буфера
+
+	// Используются только фоновой горутиной
+	w        io.WriteCloser
+	failing  bool      // приёмник сейчас недоступен
+	lastWarn time.Time // когда последний раз предупреждали о недоступности
+}
+
+// NewSink - создаёт приёмник по настройкам audit.*
+func NewSink(cfg config.AuditConfig, warn *slog.Logger) *Sink {
+	var open func() (io.WriteCloser, error)
+
+	switch cfg.Output {
+	case config.AuditOutputFile:
+		open = func() (io.WriteCloser, error) {
+			return logger.OpenRotatingFile(cfg.Path, int64(cfg.MaxSizeMB)<<20, cfg.MaxAge, cfg.MaxBackups)
+		}
+	case config.AuditOutputSyslog:
+		open = func() (io.WriteCloser, error) {
+			network := ""
+			if cfg.SyslogAddr != "" {
+				network = "udp"
+			}
+
+			return syslog.Dial(network, cfg.SyslogAddr, syslog.LOG_AUTH|syslog.LOG_INFO, "sso")
+		}
+	default:
+		open = func() (io.WriteCloser, error) { return nopCloser{os.Stdout}, nil }
+	}
+
+	return newSink(open, cfg.BufferSize, warn)
+}
+
+func newSink(open func() (io.WriteCloser, error), bufferSize int, warn *slog.Logger) *Sink {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+
+	s := &Sink{
+		open:       open,
+		warn:       warn,
+		wake:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		maxPending: bufferSize,
+	}
+
+	go s.loop()
+
+	return s
+}
+
+// Write - реализует io.Writer; никогда не ждёт приёмник
+func (s *Sink) Write(p []byte) (int, error) {
+	b := make([]byte, len(p))
+	copy(b, p)
+
+	s.mu.Lock()
+	if len(s.pending) >= s.maxPending {
+		s.pending = s.pending[1:]
+		s.dropped++
+	}
+	s.pending = append(s.pending, b)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return len(p), nil
+}
+
+// Close - пытается дописать буфер и закрывает приёмник
+func (s *Sink) Close() error {
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	pending, dropped := len(s.pending), s.dropped
+	s.mu.Unlock()
+
+	if pending > 0 || dropped > 0 {
+		s.warn.Error("security log sink closed with unwritten records",
+			slog.Int("pending", pending),
+			slog.Int("dropped", dropped),
+		)
+	}
+
+	if s.w == nil {
+		return nil
+	}
+
+	return s.w.Close()
+}
+
+// loop - фоновая запись в приёмник
+func (s *Sink) loop() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.wake:
+			s.flush()
+		case <-ticker.C:
+			s.flush()
+		case <-s.stop:
+			s.flush()
+			return
+		}
+	}
+}
+
+// flush - пишет накопленные записи; при ошибке возвращает недописанные в начало буфера
+func (s *Sink) flush() {
+	s.mu.Lock()
+	batch := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	written, err := s.writeBatch(batch)
+	if err != nil {
+		s.mu.Lock()
+		s.pending = append(batch[written:], s.pending...)
+		if over := len(s.pending) - s.maxPending; over > 0 {
+			s.pending = s.pending[over:]
+			s.dropped += over
+		}
+		buffered, dropped := len(s.pending), s.dropped
+		s.mu.Unlock()
+
+		s.fail(err, buffered, dropped)
+
+		return
+	}
+
+	if s.failing {
+		s.failing = false
+		s.warn.Warn("security log sink is available again")
+	}
+}
+
+// writeBatch - пишет записи по порядку, возвращает количество записанных
+func (s *Sink) writeBatch(batch [][]byte) (int, error) {
+	if s.w == nil {
+		w, err := s.open()
+		if err != nil {
+			return 0, fmt.Errorf("open security log sink: %w", err)
+		}
+		s.w = w
+	}
+
+	for i, b := range batch {
+		if _, err := s.w.Write(b); err != nil {
+			_ = s.w.Close()
+			s.w = nil
+
+			return i, fmt.Errorf("write security log: %w", err)
+		}
+	}
+
+	return len(batch), nil
+}
+
+// fail - громко сообщает, что события безопасности пока копятся в памяти
+// (не чаще раза в retryInterval, чтобы не завалить лог приложения)
+func (s *Sink) fail(err error, buffered, dropped int) {
+	s.failing = true
+
+	if time.Since(s.lastWarn) < retryInterval {
+		return
+	}
+	s.lastWarn = time.Now()
+
+	s.warn.Error("SECURITY LOG SINK UNAVAILABLE, buffering events in memory",
+		slog.String("error", err.Error()),
+		slog.Int("buffered", buffered),
+		slog.Int("buffer_cap", s.maxPending),
+		slog.Int("dropped", dropped),
+	)
+}
+
+// nopCloser - обёртка, чтобы Close не закрывал stdout
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
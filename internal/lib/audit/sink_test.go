@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyWriter - приёмник, который можно "уронить"
+type flakyWriter struct {
+	mu   sync.Mutex
+	down bool
+	buf  bytes.Buffer
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.down {
+		return 0, errors.New("sink is down")
+	}
+
+	return w.buf.Write(p)
+}
+
+func (w *flakyWriter) Close() error { return nil }
+
+func (w *flakyWriter) setDown(down bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.down = down
+}
+
+func TestSink_BuffersWhileUnavailable(t *testing.T) {
+	warnings := &flakyWriter{}
+	warn := slog.New(slog.NewTextHandler(warnings, nil))
+
+	w := &flakyWriter{down: true}
+	s := newSink(func() (io.WriteCloser, error) { return w, nil }, 2, warn)
+
+	_, err := s.Write([]byte("a\n"))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		warnings.mu.Lock()
+		defer warnings.mu.Unlock()
+		return bytes.Contains(warnings.buf.Bytes(), []byte("SECURITY LOG SINK UNAVAILABLE"))
+	}, time.Second, 5*time.Millisecond)
+
+	for _, rec := range []string{"b\n", "c\n"} {
+		n, err := s.Write([]byte(rec))
+		require.NoError(t, err)
+		require.Equal(t, len(rec), n)
+	}
+
+	w.setDown(false)
+	require.NoError(t, s.Close())
+
+	// Буфер на 2 записи: самая старая вытеснена, остальные дописаны по порядку после восстановления
+	assert.Equal(t, "b\nc\n", w.buf.String())
+}
+
+func TestSink_OpenFailureDoesNotBlock(t *testing.T) {
+	warn := slog.New(slog.NewTextHandler(io.Discard, nil))
+	s := newSink(func() (io.WriteCloser, error) { return nil, errors.New("no syslog") }, 10, warn)
+
+	for i := 0; i < 100; i++ {
+		_, err := s.Write([]byte("event\n"))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, s.Close())
+}
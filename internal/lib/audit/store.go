@@ -0,0 +1,102 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// storeTimeout - сколько фоновая запись одного события ждёт хранилище
+const storeTimeout = 5 * time.Second
+
+// Store - хранилище событий (sqlite.Storage)
+type Store interface {
+	AddAuditEvent(ctx context.Context, r models.AuditRecord) error
+}
+
+// StoreSink - неблокирующая запись событий в БД. Как и Sink, события копятся в буфере до bufferSize
+// штук и пишутся в фоне; при переполнении новые события в БД не попадают (в основном журнале они есть),
+// а в лог приложения уходит предупреждение
+type StoreSink struct {
+	store Store
+	warn  *slog.Logger
+
+	records chan models.AuditRecord
+	done    chan struct{}
+
+	mu       sync.Mutex
+	closed   bool
+	dropped  int
+	lastWarn time.Time
+}
+
+// NewStoreSink - создаёт запись событий в store
+func NewStoreSink(store Store, bufferSize int, warn *slog.Logger) *StoreSink {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+
+	s := &StoreSink{
+		store:   store,
+		warn:    warn,
+		records: make(chan models.AuditRecord, bufferSize),
+		done:    make(chan struct{}),
+	}
+
+	go s.loop()
+
+	return s
+}
+
+// Record - ставит событие в очередь записи; никогда не ждёт хранилище
+func (s *StoreSink) Record(r models.AuditRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.records <- r:
+	default:
+		s.dropped++
+		if time.Since(s.lastWarn) >= retryInterval {
+			s.lastWarn = time.Now()
+			s.warn.Error("audit store queue is full, events are not saved to the database",
+				slog.Int("dropped", s.dropped))
+		}
+	}
+}
+
+// Close - дописывает очередь и останавливает запись
+func (s *StoreSink) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.records)
+	}
+	s.mu.Unlock()
+
+	<-s.done
+
+	return nil
+}
+
+func (s *StoreSink) loop() {
+	defer close(s.done)
+
+	for r := range s.records {
+		ctx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		err := s.store.AddAuditEvent(ctx, r)
+		cancel()
+
+		if err != nil {
+			s.warn.Error("failed to save audit event",
+				slog.String("event", r.Event),
+				slog.String("error", err.Error()))
+		}
+	}
+}
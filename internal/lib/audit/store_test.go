@@ -0,0 +1,70 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/requestid"
+	"sso/internal/lib/selftest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memStore - сохранённые события
+type memStore struct {
+	mu      sync.Mutex
+	records []models.AuditRecord
+}
+
+func (m *memStore) AddAuditEvent(_ context.Context, r models.AuditRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, r)
+
+	return nil
+}
+
+func TestLogger_WithRecorder(t *testing.T) {
+	store := &memStore{}
+	sink := NewStoreSink(store, 10, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var buf bytes.Buffer
+	l := New(&buf).WithRecorder(sink)
+
+	ctx := requestid.Into(context.Background(), "req-1")
+	l.Emit(ctx, Event{Name: EventLoginFailed, Outcome: OutcomeFailure, UserID: 7, AppID: 1, Reason: "invalid password"})
+	require.NoError(t, sink.Close())
+
+	assert.Contains(t, buf.String(), `"event":"login.failed"`, "the main log still gets the event")
+	require.Len(t, store.records, 1)
+	r := store.records[0]
+	assert.Equal(t, EventLoginFailed, r.Event)
+	assert.Equal(t, OutcomeFailure, r.Outcome)
+	assert.EqualValues(t, 7, r.UserID)
+	assert.Equal(t, "invalid password", r.Reason)
+	assert.Equal(t, "req-1", r.RequestID)
+	assert.False(t, r.OccurredAt.IsZero())
+
+	// После закрытия события в БД не пишутся, но и не блокируют
+	l.Emit(ctx, Event{Name: EventLoginSucceeded, Outcome: OutcomeSuccess})
+	assert.Len(t, store.records, 1)
+}
+
+func TestLogger_SelfTestNotRecorded(t *testing.T) {
+	store := &memStore{}
+	sink := NewStoreSink(store, 10, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	var buf bytes.Buffer
+	l := New(&buf).WithRecorder(sink)
+
+	l.Emit(selftest.Into(context.Background()), Event{Name: EventUserRegistered, Outcome: OutcomeSuccess, UserID: 7})
+	require.NoError(t, sink.Close())
+
+	assert.Contains(t, buf.String(), `"selftest":true`)
+	assert.Empty(t, store.records)
+}
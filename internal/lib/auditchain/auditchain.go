@@ -0,0 +1,170 @@
+// Package auditchain - цепочка хешей журнала безопасности в БД (audit.chain).
+//
+// Каждое событие хранит prev_hash - хеш предыдущего события - и свой hash: SHA-256 от канонической записи
+// события вместе с prev_hash. Изменение события меняет его хеш, удаление события из середины рвёт связь
+// prev_hash следующего. Последнее звено хранится отдельно (audit_chain_head) и, если задан ключ,
+// подписано HMAC: без ключа нельзя ни отрезать конец журнала, ни пересчитать всю цепочку заново.
+// Начало цепочки удаляет janitor (audit.retention), поэтому prev_hash первого проверяемого события
+// принимается на веру.
+package auditchain
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sso/internal/domain/models"
+	"strconv"
+	"time"
+)
+
+// Причины разрыва цепочки (AuditChainReport.Reason)
+const (
+	ReasonPrevHash  = "prev_hash_mismatch" // prev_hash не совпадает с хешем предыдущего события: событие удалено или вставлено
+	ReasonHash      = "hash_mismatch"      // событие изменено после записи
+	ReasonUnchained = "unchained"          // событие без хеша после событий цепочки (цепочку выключали)
+	ReasonHead      = "head_mismatch"      // последнее событие не совпадает с audit_chain_head
+	ReasonHeadMAC   = "head_mac_invalid"   // подпись audit_chain_head не сходится с audit.chain_key
+	ReasonTruncated = "truncated"          // события с конца журнала удалены
+)
+
+// pageSize - сколько событий читается из БД за раз
+const pageSize = 1000
+
+// Hash - хеш события r, следующего за звеном prev (hex SHA-256). Событие сериализуется JSON-массивом
+// полей в фиксированном порядке, время - в UTC с наносекундами
+func Hash(prev string, r models.AuditRecord) string {
+	// Ошибки быть не может: в массиве только строки и числа
+	data, _ := json.Marshal([]any{
+		r.ID, r.OccurredAt.UTC().Format(time.RFC3339Nano), r.Event, r.Outcome, r.UserID, r.AppID,
+		r.Email, r.Reason, r.ActorID, r.RequestID, r.IP, prev,
+	})
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// MAC - подпись последнего звена (событие id с хешем hash) ключом key (hex HMAC-SHA256)
+func MAC(key []byte, id int64, hash string) string {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(strconv.FormatInt(id, 10) + ":" + hash))
+
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Store - чтение цепочки (sqlite.Storage)
+type Store interface {
+	// AuditChainRange - наименьший и наибольший ID событий за [from, to) (0, 0 - событий нет)
+	AuditChainRange(ctx context.Context, from, to time.Time) (first, last int64, err error)
+	// AuditChainRows - до limit событий с хешами с ID в (afterID, lastID] по возрастанию ID
+	AuditChainRows(ctx context.Context, afterID, lastID int64, limit int) ([]models.AuditRecord, error)
+	// AuditChainHead - последнее звено (нулевое, если цепочка пуста)
+	AuditChainHead(ctx context.Context) (models.AuditChainHead, error)
+}
+
+// Verifier - проверка цепочки (Admin.VerifyAuditChain)
+type Verifier struct {
+	store Store
+	key   []byte
+}
+
+// New - проверка цепочки в store; key - audit.chain_key (nil - подпись последнего звена не проверяется)
+func New(store Store, key []byte) *Verifier {
+	return &Verifier{store: store, key: key}
+}
+
+// VerifyAuditChain - проверяет все события между первым и последним событием за [from, to) по порядку ID.
+// События без хеша в начале диапазона (записанные до включения цепочки) пропускаются. Если диапазон
+// доходит до конца журнала, последнее событие сверяется с audit_chain_head
+func (v *Verifier) VerifyAuditChain(ctx context.Context, from, to time.Time) (models.AuditChainReport, error) {
+	const op = "auditchain.VerifyAuditChain"
+
+	var report models.AuditChainReport
+
+	first, last, err := v.store.AuditChainRange(ctx, from, to)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+	if first == 0 {
+		return report, nil
+	}
+
+	broken := func(id int64, reason string) (models.AuditChainReport, error) {
+		report.FirstBrokenID, report.Reason = id, reason
+		return report, nil
+	}
+
+	var (
+		prev    string
+		chained bool
+		lastID  int64
+	)
+	for afterID := first - 1; ; {
+		page, err := v.store.AuditChainRows(ctx, afterID, last, pageSize)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, r := range page {
+			report.RowsChecked++
+			lastID = r.ID
+
+			switch {
+			case r.Hash == "" && !chained:
+				report.Unchained++
+				continue
+			case r.Hash == "":
+				return broken(r.ID, ReasonUnchained)
+			// Первое событие цепочки записано с пустым prev_hash: непустой после событий без хеша
+			// значит, что хеши предыдущих событий стёрли
+			case chained && r.PrevHash != prev, !chained && report.Unchained > 0 && r.PrevHash != "":
+				return broken(r.ID, ReasonPrevHash)
+			case Hash(r.PrevHash, r) != r.Hash:
+				return broken(r.ID, ReasonHash)
+			}
+			prev, chained = r.Hash, true
+		}
+
+		if len(page) < pageSize {
+			break
+		}
+		afterID = page[len(page)-1].ID
+
+		if err := ctx.Err(); err != nil {
+			return report, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if !chained {
+		return report, nil
+	}
+
+	// Звено читается до проверки хвоста: событие, записанное между запросами, видно как хвост,
+	// а не как отрезанный конец журнала
+	head, err := v.store.AuditChainHead(ctx)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+	tail, err := v.store.AuditChainRows(ctx, lastID, math.MaxInt64, 1)
+	if err != nil {
+		return report, fmt.Errorf("%s: %w", op, err)
+	}
+	if len(tail) > 0 {
+		return report, nil // Диапазон не доходит до конца журнала
+	}
+
+	switch {
+	case head.EventID > lastID:
+		return broken(head.EventID, ReasonTruncated)
+	case head.EventID != lastID || head.Hash != prev:
+		return broken(lastID, ReasonHead)
+	case v.key != nil && !hmac.Equal([]byte(MAC(v.key, head.EventID, head.Hash)), []byte(head.MAC)):
+		return broken(lastID, ReasonHeadMAC)
+	}
+	report.HeadVerified = true
+
+	return report, nil
+}
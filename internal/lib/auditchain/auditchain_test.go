@@ -0,0 +1,134 @@
+package auditchain
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeStore - журнал в памяти; rows упорядочены по ID
+type fakeStore struct {
+	rows []models.AuditRecord
+	head models.AuditChainHead
+}
+
+func (f *fakeStore) AuditChainRange(_ context.Context, from, to time.Time) (int64, int64, error) {
+	var first, last int64
+	for _, r := range f.rows {
+		if !r.OccurredAt.Before(from) && r.OccurredAt.Before(to) {
+			if first == 0 {
+				first = r.ID
+			}
+			last = r.ID
+		}
+	}
+
+	return first, last, nil
+}
+
+func (f *fakeStore) AuditChainRows(_ context.Context, afterID, lastID int64, limit int) ([]models.AuditRecord, error) {
+	var res []models.AuditRecord
+	for _, r := range f.rows {
+		if r.ID > afterID && r.ID <= lastID && len(res) < limit {
+			res = append(res, r)
+		}
+	}
+
+	return res, nil
+}
+
+func (f *fakeStore) AuditChainHead(context.Context) (models.AuditChainHead, error) {
+	return f.head, nil
+}
+
+// add - дописывает событие; chained - звеном цепочки (как sqlite.Storage с audit.chain)
+func (f *fakeStore) add(chained bool) {
+	r := models.AuditRecord{
+		ID: int64(len(f.rows) + 1), OccurredAt: time.Unix(int64(len(f.rows)), 0), Event: "login.failed", Outcome: "failure",
+	}
+	if chained {
+		r.PrevHash = f.head.Hash
+		r.Hash = Hash(r.PrevHash, r)
+		f.head = models.AuditChainHead{EventID: r.ID, Hash: r.Hash}
+	}
+	f.rows = append(f.rows, r)
+}
+
+func TestHash(t *testing.T) {
+	r := models.AuditRecord{ID: 1, OccurredAt: time.Date(2025, 3, 1, 12, 0, 0, 5, time.UTC), Event: "login.failed"}
+
+	h := Hash("", r)
+	assert.Len(t, h, 64)
+	assert.Equal(t, h, Hash("", models.AuditRecord{ID: 1, OccurredAt: r.OccurredAt.In(time.FixedZone("MSK", 3*3600)), Event: "login.failed"}))
+	assert.NotEqual(t, h, Hash("prev", r))
+
+	r.Email = "user@example.com"
+	assert.NotEqual(t, h, Hash("", r))
+}
+
+func TestVerifyAuditChain(t *testing.T) {
+	ctx := context.Background()
+	from, to := time.Unix(0, 0), time.Unix(100, 0)
+
+	t.Run("unchained prefix", func(t *testing.T) {
+		f := &fakeStore{}
+		f.add(false)
+		f.add(false)
+		f.add(true)
+		f.add(true)
+
+		report, err := New(f, nil).VerifyAuditChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, models.AuditChainReport{RowsChecked: 4, Unchained: 2, HeadVerified: true}, report)
+	})
+
+	t.Run("erased hashes", func(t *testing.T) {
+		f := &fakeStore{}
+		for range 4 {
+			f.add(true)
+		}
+		f.rows[0].PrevHash, f.rows[0].Hash = "", ""
+		f.rows[1].PrevHash, f.rows[1].Hash = "", ""
+
+		report, err := New(f, nil).VerifyAuditChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), report.FirstBrokenID)
+		assert.Equal(t, ReasonPrevHash, report.Reason)
+	})
+
+	t.Run("chain switched off", func(t *testing.T) {
+		f := &fakeStore{}
+		f.add(true)
+		f.add(false)
+		f.add(true)
+
+		report, err := New(f, nil).VerifyAuditChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, models.AuditChainReport{RowsChecked: 2, FirstBrokenID: 2, Reason: ReasonUnchained}, report)
+	})
+
+	t.Run("unsigned head with key", func(t *testing.T) {
+		f := &fakeStore{}
+		f.add(true)
+
+		report, err := New(f, []byte("key")).VerifyAuditChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, ReasonHeadMAC, report.Reason)
+
+		f.head.MAC = MAC([]byte("key"), f.head.EventID, f.head.Hash)
+		report, err = New(f, []byte("key")).VerifyAuditChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.True(t, report.OK())
+		assert.True(t, report.HeadVerified)
+	})
+
+	t.Run("empty range", func(t *testing.T) {
+		report, err := New(&fakeStore{}, nil).VerifyAuditChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, models.AuditChainReport{}, report)
+	})
+}
@@ -0,0 +1,48 @@
+package authctx
+
+import (
+	"context"
+	"time"
+)
+
+// Principal - аутентифицированный вызывающий (владелец bearer-токена)
+type Principal struct {
+	UserID  int64
+	Email   string
+	AppID   int  // приложение, для которого выдан токен
+	IsAdmin bool // берётся из БД при проверке токена, а не из клеймов
+
+	ThirdParty bool // токен выдан стороннему приложению (models.App.ThirdParty)
+
+	OrgID        int64  // организация, в которую выполнен вход (0 - без организации)
+	OrgRole      string // роль в OrgID; как и IsAdmin, берётся из БД
+	IsSuperadmin bool   // администратор всех организаций
+
+	IsGuest bool // гость без email и пароля (auth.CreateGuest)
+
+	SessionID string // сессия входа (клейм sid; пусто у токенов без сессии)
+
+	Offline bool     // offline-токен фонового агента (клейм offline)
+	Scopes  []string // права токена (клейм scope; пусто - без ограничений)
+
+	AMR      []string  // способы аутентификации (клейм amr)
+	ACR      string    // уровень аутентификации (клейм acr)
+	AuthTime time.Time // когда пользователь вошёл (клейм auth_time; нулевое значение, если его нет)
+
+	ExpiresAt time.Time // когда истекает токен (нулевое значение, если у токена нет exp)
+}
+
+// ctxKey - приватный тип ключа, чтобы не пересекаться с другими пакетами
+type ctxKey struct{}
+
+// Into - кладёт вызывающего в контекст
+func Into(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, ctxKey{}, p)
+}
+
+// From - достаёт вызывающего из контекста; ok = false для анонимных запросов
+func From(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(ctxKey{}).(Principal)
+
+	return p, ok
+}
@@ -0,0 +1,239 @@
+// Package backlog - показатели отставания фоновых подсистем в /debug/vars: очередь outbox, ожидающие
+// приглашения, отключённые пользователи, активные сессии и последний проход задач janitor.
+//
+// В отличие от счётчиков, которые обновляются по ходу работы, эти показатели считаются запросами к БД
+// при чтении /debug/vars. Результат кешируется на cache_ttl, поэтому частый сбор метрик не нагружает БД.
+// sso_degraded - 1, если отставание выше порогов или показатели не удалось прочитать: на неё настроены алерты
+package backlog
+
+import (
+	"context"
+	"expvar"
+	"sso/internal/domain/models"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Имена переменных в /debug/vars. На них настроены дашборды и алерты: переименовывать нельзя
+const (
+	MetricOutboxUnsent       = "sso_outbox_unsent"
+	MetricOutboxOldestAge    = "sso_outbox_oldest_unsent_age_seconds"
+	MetricInvitationsPending = "sso_invitations_pending"
+	MetricUsersLocked        = "sso_users_locked"
+	MetricSessionsActive     = "sso_sessions_active"
+	MetricJanitorLastRun     = "sso_janitor_last_run_timestamp_seconds" // задача -> unix-время (0 - ещё не проходила)
+	MetricDegraded           = "sso_degraded"
+)
+
+// Store - счётчики накопившихся записей (sqlite.Storage.Backlog)
+type Store interface {
+	Backlog(ctx context.Context, now time.Time) (models.Backlog, error)
+}
+
+// OutboxStore - очередь outbox (events.OutboxStore)
+type OutboxStore interface {
+	OutboxLag(ctx context.Context) (int64, time.Time, error)
+}
+
+// JanitorStatus - последние проходы задач janitor (janitor.Janitor)
+type JanitorStatus interface {
+	LastRuns() map[string]time.Time
+}
+
+// Settings - кеш и пороги показателей
+type Settings struct {
+	CacheTTL     time.Duration // сколько отдавать посчитанные показатели без новых запросов к БД
+	QueryTimeout time.Duration // таймаут запросов одного подсчёта (0 - без таймаута)
+	OutboxMaxAge time.Duration // событие в outbox старше - sso_degraded (0 - не учитывать)
+}
+
+// Option - подсистема, показатели которой публикуются
+type Option func(m *Monitor)
+
+// WithOutbox - показатели очереди outbox и порог её возраста
+func WithOutbox(store OutboxStore) Option {
+	return func(m *Monitor) {
+		m.outbox = store
+	}
+}
+
+// WithJanitor - время последнего прохода задач janitor
+func WithJanitor(j JanitorStatus) Option {
+	return func(m *Monitor) {
+		m.janitor = j
+	}
+}
+
+// Snapshot - показатели на момент подсчёта
+type Snapshot struct {
+	models.Backlog
+	OutboxUnsent   int64
+	OutboxOldest   time.Time            // время записи самого старого неотправленного события (нулевое - очередь пуста)
+	JanitorLastRun map[string]time.Time // nil - janitor не запущен
+	Degraded       bool
+	At             time.Time
+}
+
+// Monitor - показатели отставания, посчитанные не раньше CacheTTL назад
+type Monitor struct {
+	store    Store
+	outbox   OutboxStore
+	janitor  JanitorStatus
+	settings Settings
+	now      func() time.Time
+
+	mu     sync.Mutex // подсчёт идёт под мьютексом: одновременные чтения ждут его результата
+	cached Snapshot
+	ok     bool
+}
+
+// New - создаёт монитор показателей хранилища store и подсистем из opts
+func New(store Store, settings Settings, opts ...Option) *Monitor {
+	m := &Monitor{store: store, settings: settings, now: time.Now}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Snapshot - показатели из кеша или свежие. Ошибки не кешируются
+func (m *Monitor) Snapshot(ctx context.Context) (Snapshot, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	if m.ok && now.Sub(m.cached.At) < m.settings.CacheTTL {
+		return m.cached, nil
+	}
+
+	if m.settings.QueryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.settings.QueryTimeout)
+		defer cancel()
+	}
+
+	s := Snapshot{At: now}
+
+	var err error
+	if s.Backlog, err = m.store.Backlog(ctx, now); err != nil {
+		return Snapshot{}, err
+	}
+	if m.outbox != nil {
+		if s.OutboxUnsent, s.OutboxOldest, err = m.outbox.OutboxLag(ctx); err != nil {
+			return Snapshot{}, err
+		}
+		if m.settings.OutboxMaxAge > 0 && !s.OutboxOldest.IsZero() && now.Sub(s.OutboxOldest) > m.settings.OutboxMaxAge {
+			s.Degraded = true
+		}
+	}
+	if m.janitor != nil {
+		s.JanitorLastRun = m.janitor.LastRuns()
+	}
+
+	m.cached, m.ok = s, true
+
+	return s, nil
+}
+
+// Vars - показатели в виде переменных expvar, только для подсистем, которые включены.
+// Значение считается при чтении; если показатели не удалось прочитать - null, а sso_degraded - 1
+func (m *Monitor) Vars() map[string]expvar.Var {
+	gauge := func(value func(s Snapshot) any) expvar.Var {
+		return expvar.Func(func() any {
+			s, err := m.Snapshot(context.Background())
+			if err != nil {
+				return nil
+			}
+
+			return value(s)
+		})
+	}
+
+	vars := map[string]expvar.Var{
+		MetricInvitationsPending: gauge(func(s Snapshot) any { return s.PendingInvitations }),
+		MetricUsersLocked:        gauge(func(s Snapshot) any { return s.LockedUsers }),
+		MetricSessionsActive:     gauge(func(s Snapshot) any { return s.ActiveSessions }),
+		MetricDegraded: expvar.Func(func() any {
+			s, err := m.Snapshot(context.Background())
+			if err != nil || s.Degraded {
+				return 1
+			}
+
+			return 0
+		}),
+	}
+
+	if m.outbox != nil {
+		vars[MetricOutboxUnsent] = gauge(func(s Snapshot) any { return s.OutboxUnsent })
+		vars[MetricOutboxOldestAge] = gauge(func(s Snapshot) any {
+			if s.OutboxOldest.IsZero() {
+				return 0.0
+			}
+
+			return s.At.Sub(s.OutboxOldest).Seconds()
+		})
+	}
+
+	if m.janitor != nil {
+		vars[MetricJanitorLastRun] = gauge(func(s Snapshot) any {
+			res := make(map[string]int64, len(s.JanitorLastRun))
+			for task, at := range s.JanitorLastRun {
+				res[task] = 0
+				if !at.IsZero() {
+					res[task] = at.Unix()
+				}
+			}
+
+			return res
+		})
+	}
+
+	return vars
+}
+
+// published - переменные, уже зарегистрированные в expvar. expvar не позволяет заменить или удалить
+// переменную, поэтому регистрируется обёртка, а Publish меняет то, на что она указывает
+var (
+	publishMu sync.Mutex
+	published = map[string]*slot{}
+)
+
+// slot - переменная /debug/vars, значение которой задаёт последний Publish (null, если в нём её не было)
+type slot struct {
+	v atomic.Pointer[expvar.Var]
+}
+
+func (s *slot) String() string {
+	v := s.v.Load()
+	if v == nil {
+		return "null"
+	}
+
+	return (*v).String()
+}
+
+// Publish - публикует показатели m в /debug/vars вместо опубликованных раньше. Показатели подсистем,
+// которые в m не включены, становятся null
+func Publish(m *Monitor) {
+	publishMu.Lock()
+	defer publishMu.Unlock()
+
+	vars := m.Vars()
+	for name, v := range vars {
+		s, ok := published[name]
+		if !ok {
+			s = &slot{}
+			published[name] = s
+			expvar.Publish(name, s)
+		}
+		s.v.Store(&v)
+	}
+
+	for name, s := range published {
+		if _, ok := vars[name]; !ok {
+			s.v.Store(nil)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package backlog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"sso/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStore struct {
+	backlog models.Backlog
+	err     error
+	calls   int
+}
+
+func (f *fakeStore) Backlog(context.Context, time.Time) (models.Backlog, error) {
+	f.calls++
+
+	return f.backlog, f.err
+}
+
+type fakeOutbox struct {
+	pending int64
+	oldest  time.Time
+}
+
+func (f *fakeOutbox) OutboxLag(context.Context) (int64, time.Time, error) {
+	return f.pending, f.oldest, nil
+}
+
+type fakeJanitor map[string]time.Time
+
+func (f fakeJanitor) LastRuns() map[string]time.Time {
+	return f
+}
+
+// values - значения переменных так, как их видит /debug/vars
+func values(t *testing.T, vars map[string]expvar.Var) map[string]any {
+	t.Helper()
+
+	res := make(map[string]any, len(vars))
+	for name, v := range vars {
+		var value any
+		require.NoError(t, json.Unmarshal([]byte(v.String()), &value), name)
+		res[name] = value
+	}
+
+	return res
+}
+
+func TestMonitor_Vars(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	store := &fakeStore{backlog: models.Backlog{PendingInvitations: 3, LockedUsers: 2, ActiveSessions: 10}}
+	outbox := &fakeOutbox{pending: 4, oldest: now.Add(-90 * time.Second)}
+	janitor := fakeJanitor{"expired sessions": now.Add(-time.Hour), "due account deletions": {}}
+
+	m := New(store, Settings{CacheTTL: time.Minute, OutboxMaxAge: 5 * time.Minute}, WithOutbox(outbox), WithJanitor(janitor))
+	m.now = func() time.Time { return now }
+
+	assert.Equal(t, map[string]any{
+		MetricInvitationsPending: 3.0,
+		MetricUsersLocked:        2.0,
+		MetricSessionsActive:     10.0,
+		MetricOutboxUnsent:       4.0,
+		MetricOutboxOldestAge:    90.0,
+		MetricJanitorLastRun: map[string]any{
+			"expired sessions":      float64(now.Add(-time.Hour).Unix()),
+			"due account deletions": 0.0,
+		},
+		MetricDegraded: 0.0,
+	}, values(t, m.Vars()))
+	// Все переменные читаются из одного подсчёта
+	assert.Equal(t, 1, store.calls)
+}
+
+func TestMonitor_OnlyEnabledSubsystems(t *testing.T) {
+	m := New(&fakeStore{}, Settings{})
+
+	vars := m.Vars()
+	assert.NotContains(t, vars, MetricOutboxUnsent)
+	assert.NotContains(t, vars, MetricOutboxOldestAge)
+	assert.NotContains(t, vars, MetricJanitorLastRun)
+	assert.Contains(t, vars, MetricSessionsActive)
+	assert.Contains(t, vars, MetricDegraded)
+}
+
+func TestMonitor_Cache(t *testing.T) {
+	now := time.Now()
+	store := &fakeStore{}
+	m := New(store, Settings{CacheTTL: time.Minute})
+	m.now = func() time.Time { return now }
+
+	for range 3 {
+		_, err := m.Snapshot(context.Background())
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 1, store.calls)
+
+	now = now.Add(time.Minute)
+	_, err := m.Snapshot(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, store.calls)
+
+	// Ошибка не кешируется
+	store.err = errors.New("database is locked")
+	now = now.Add(time.Minute)
+	_, err = m.Snapshot(context.Background())
+	require.Error(t, err)
+	store.err = nil
+	_, err = m.Snapshot(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, store.calls)
+}
+
+func TestMonitor_Degraded(t *testing.T) {
+	now := time.Now()
+	store := &fakeStore{}
+	outbox := &fakeOutbox{pending: 1, oldest: now.Add(-10 * time.Minute)}
+	m := New(store, Settings{OutboxMaxAge: 5 * time.Minute}, WithOutbox(outbox))
+	m.now = func() time.Time { return now }
+
+	assert.Equal(t, "1", m.Vars()[MetricDegraded].String())
+
+	// Очередь разобрана
+	outbox.pending, outbox.oldest = 0, time.Time{}
+	assert.Equal(t, "0", m.Vars()[MetricDegraded].String())
+
+	// Показатели не удалось прочитать
+	store.err = errors.New("database is locked")
+	vars := m.Vars()
+	assert.Equal(t, "1", vars[MetricDegraded].String())
+	assert.Equal(t, "null", vars[MetricSessionsActive].String())
+}
+
+func TestPublish_Replaces(t *testing.T) {
+	Publish(New(&fakeStore{backlog: models.Backlog{ActiveSessions: 1}}, Settings{}, WithOutbox(&fakeOutbox{pending: 7})))
+	assert.Equal(t, "1", expvar.Get(MetricSessionsActive).String())
+	assert.Equal(t, "7", expvar.Get(MetricOutboxUnsent).String())
+
+	// Повторная публикация (новый экземпляр приложения) не паникует и заменяет источник
+	Publish(New(&fakeStore{backlog: models.Backlog{ActiveSessions: 2}}, Settings{}))
+	assert.Equal(t, "2", expvar.Get(MetricSessionsActive).String())
+	assert.Equal(t, "null", expvar.Get(MetricOutboxUnsent).String())
+}
@@ -0,0 +1,114 @@
+// Package captcha - проверка токенов CAPTCHA у провайдера.
+//
+// reCAPTCHA, hCaptcha и Turnstile проверяют токен одинаково: POST формы secret, response
+// и remoteip на адрес siteverify, в ответе - {"success": bool, "error-codes": [...]}
+// (reCAPTCHA v3 добавляет оценку score). Различаются только адреса.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+	"strings"
+)
+
+// ErrRejected - провайдер не принял токен (бот, просроченный или повторно использованный токен)
+var ErrRejected = errors.New("captcha rejected")
+
+// maxResponse - сколько байт ответа провайдера читать
+const maxResponse = 16 << 10
+
+// endpoints - адреса проверки токена по провайдерам
+var endpoints = map[string]string{
+	config.CaptchaProviderReCAPTCHA: "https://www.google.com/recaptcha/api/siteverify",
+	config.CaptchaProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+	config.CaptchaProviderTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+}
+
+// Verifier - проверка токенов у одного провайдера
+type Verifier struct {
+	endpoint string
+	secret   string
+	minScore float64
+	client   *httpx.Client
+}
+
+// New - создаёт Verifier по настройкам captcha.*; client - клиент исходящих запросов
+// с таймаутом cfg.Timeout
+func New(cfg config.CaptchaConfig, client *httpx.Client) (*Verifier, error) {
+	const op = "captcha.New"
+
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		var ok bool
+		if endpoint, ok = endpoints[cfg.Provider]; !ok {
+			return nil, fmt.Errorf("%s: unknown provider %q", op, cfg.Provider)
+		}
+	}
+
+	return &Verifier{
+		endpoint: endpoint,
+		secret:   cfg.Secret.Reveal(),
+		minScore: cfg.MinScore,
+		client:   client,
+	}, nil
+}
+
+// Verify - проверяет токен, полученный виджетом на странице; remoteIP - адрес клиента
+// (пусто - неизвестен). ErrRejected - токен не принят; другие ошибки - провайдер недоступен
+func (v *Verifier) Verify(ctx context.Context, token, remoteIP string) error {
+	const op = "captcha.Verify"
+
+	form := url.Values{"secret": {v.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %s", op, resp.Status)
+	}
+
+	var result struct {
+		Success    bool     `json:"success"`
+		Score      *float64 `json:"score"` // только reCAPTCHA v3
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponse)).Decode(&result); err != nil {
+		return fmt.Errorf("%s: decode response: %w", op, err)
+	}
+
+	if !result.Success {
+		// Неверный секрет - ошибка настройки сервиса, а не клиента
+		for _, code := range result.ErrorCodes {
+			if code == "invalid-input-secret" || code == "missing-input-secret" {
+				return fmt.Errorf("%s: provider rejected the secret: %s", op, code)
+			}
+		}
+
+		return fmt.Errorf("%s: %w: %s", op, ErrRejected, strings.Join(result.ErrorCodes, ","))
+	}
+
+	if v.minScore > 0 && result.Score != nil && *result.Score < v.minScore {
+		return fmt.Errorf("%s: %w: score %.2f", op, ErrRejected, *result.Score)
+	}
+
+	return nil
+}
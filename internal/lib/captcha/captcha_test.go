@@ -0,0 +1,77 @@
+package captcha
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newVerifier(t *testing.T, minScore float64, timeout time.Duration, handler http.HandlerFunc) *Verifier {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	outbound, err := httpx.NewFactory(config.OutboundConfig{})
+	require.NoError(t, err)
+
+	v, err := New(config.CaptchaConfig{
+		Provider: config.CaptchaProviderTurnstile, Endpoint: srv.URL, Secret: "secret", MinScore: minScore,
+	}, outbound.Client("captcha", timeout))
+	require.NoError(t, err)
+
+	return v
+}
+
+func TestVerify(t *testing.T) {
+	v := newVerifier(t, 0, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "203.0.113.7", r.PostForm.Get("remoteip"))
+
+		if r.PostForm.Get("response") == "good" {
+			_, _ = w.Write([]byte(`{"success": true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	})
+
+	require.NoError(t, v.Verify(context.Background(), "good", "203.0.113.7"))
+
+	err := v.Verify(context.Background(), "bot", "203.0.113.7")
+	require.ErrorIs(t, err, ErrRejected)
+	assert.ErrorContains(t, err, "invalid-input-response")
+}
+
+func TestVerify_Score(t *testing.T) {
+	v := newVerifier(t, 0.5, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success": true, "score": 0.1}`))
+	})
+
+	require.ErrorIs(t, v.Verify(context.Background(), "token", ""), ErrRejected)
+}
+
+// Недоступный провайдер и неверный секрет - не вина клиента: ошибка не ErrRejected
+func TestVerify_ProviderFailure(t *testing.T) {
+	secret := newVerifier(t, 0, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-secret"]}`))
+	})
+	err := secret.Verify(context.Background(), "token", "")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRejected)
+
+	slow := newVerifier(t, 0, 20*time.Millisecond, func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	})
+	err = slow.Verify(context.Background(), "token", "")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrRejected)
+}
@@ -0,0 +1,126 @@
+// Package clientip - адрес клиента с учётом доверенных прокси.
+//
+// Заголовок с цепочкой адресов (x-forwarded-for) подделать может кто угодно, поэтому ему верим,
+// только если соединение пришло от доверенного прокси. Цепочка читается справа налево: правые адреса
+// дописали наши прокси, и первый адрес не из доверенных сетей - клиент. Всё левее него клиент мог
+// прислать сам и не учитывается.
+package clientip
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+type ctxKey struct{}
+
+// Into - кладёт адрес клиента в контекст
+func Into(ctx context.Context, addr netip.Addr) context.Context {
+	return context.WithValue(ctx, ctxKey{}, addr)
+}
+
+// From - достаёт адрес клиента из контекста (false, если его нет)
+func From(ctx context.Context) (netip.Addr, bool) {
+	addr, ok := ctx.Value(ctxKey{}).(netip.Addr)
+
+	return addr, ok && addr.IsValid()
+}
+
+// Resolver - определяет адрес клиента по адресу соединения и заголовку прокси
+type Resolver struct {
+	trusted []netip.Prefix
+}
+
+// NewResolver - Resolver, доверяющий заголовку только от прокси из сетей trusted.
+// Без доверенных прокси адрес клиента - всегда адрес соединения
+func NewResolver(trusted []netip.Prefix) *Resolver {
+	return &Resolver{trusted: trusted}
+}
+
+// Resolve - адрес клиента. forwarded - значения заголовка прокси (их может быть несколько,
+// каждое - список адресов через запятую). Если цепочка от доверенного прокси испорчена,
+// адрес неизвестен (невалидный netip.Addr): подставить адрес прокси значило бы выдать его за клиента
+func (r *Resolver) Resolve(remote netip.Addr, forwarded []string) netip.Addr {
+	remote = remote.Unmap()
+	if !contains(r.trusted, remote) || len(forwarded) == 0 {
+		return remote
+	}
+
+	var hops []string
+	for _, v := range forwarded {
+		hops = append(hops, strings.Split(v, ",")...)
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil || addr.Zone() != "" {
+			return netip.Addr{}
+		}
+
+		client = addr.Unmap()
+		if !contains(r.trusted, client) {
+			break
+		}
+	}
+
+	return client
+}
+
+// Allowed - входит ли addr хотя бы в одну из сетей (неизвестный адрес не входит никуда)
+func Allowed(addr netip.Addr, networks []netip.Prefix) bool {
+	return addr.IsValid() && contains(networks, addr.Unmap())
+}
+
+func contains(networks []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range networks {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddrOf - IP из адреса соединения (net.TCPAddr или "host:port")
+func AddrOf(addr net.Addr) (netip.Addr, bool) {
+	if tcp, ok := addr.(*net.TCPAddr); ok {
+		ip, ok := netip.AddrFromSlice(tcp.IP)
+
+		return ip.Unmap(), ok
+	}
+
+	ap, err := netip.ParseAddrPort(addr.String())
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return ap.Addr().Unmap(), true
+}
+
+// ParsePrefixes - разбирает список сетей в формате CIDR; одиночный адрес считается сетью из одного адреса
+func ParsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, c := range cidrs {
+		c = strings.TrimSpace(c)
+		if !strings.Contains(c, "/") {
+			addr, err := netip.ParseAddr(c)
+			if err != nil {
+				return nil, err
+			}
+			addr = addr.Unmap()
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+
+			continue
+		}
+
+		p, err := netip.ParsePrefix(c)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, p.Masked())
+	}
+
+	return prefixes, nil
+}
@@ -0,0 +1,76 @@
+package clientip
+
+import (
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	trusted, err := ParsePrefixes([]string{"10.0.0.0/8", "192.168.1.5"})
+	require.NoError(t, err)
+	r := NewResolver(trusted)
+
+	tests := []struct {
+		name      string
+		remote    string
+		forwarded []string
+		want      string
+	}{
+		{name: "direct client", remote: "203.0.113.7", want: "203.0.113.7"},
+		{name: "header from untrusted peer is ignored", remote: "203.0.113.7", forwarded: []string{"10.1.1.1"}, want: "203.0.113.7"},
+		{name: "trusted proxy", remote: "10.0.0.1", forwarded: []string{"198.51.100.4"}, want: "198.51.100.4"},
+		{name: "spoofed left part is skipped", remote: "10.0.0.1", forwarded: []string{"1.2.3.4, 198.51.100.4"}, want: "198.51.100.4"},
+		{name: "proxy chain", remote: "10.0.0.1", forwarded: []string{"198.51.100.4, 192.168.1.5", "10.0.0.2"}, want: "198.51.100.4"},
+		{name: "only proxies", remote: "10.0.0.1", forwarded: []string{"10.0.0.3, 10.0.0.2"}, want: "10.0.0.3"},
+		{name: "malformed hop", remote: "10.0.0.1", forwarded: []string{"198.51.100.4, not-an-ip"}, want: "invalid IP"},
+		{name: "port is not accepted", remote: "10.0.0.1", forwarded: []string{"198.51.100.4:443"}, want: "invalid IP"},
+		{name: "ipv4-mapped peer", remote: "::ffff:10.0.0.1", forwarded: []string{"2001:db8::1"}, want: "2001:db8::1"},
+		{name: "trusted proxy without header", remote: "10.0.0.1", want: "10.0.0.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := r.Resolve(netip.MustParseAddr(tt.remote), tt.forwarded)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestResolve_NoTrustedProxies(t *testing.T) {
+	r := NewResolver(nil)
+
+	got := r.Resolve(netip.MustParseAddr("10.0.0.1"), []string{"198.51.100.4"})
+	assert.Equal(t, "10.0.0.1", got.String())
+}
+
+func TestAllowed(t *testing.T) {
+	networks, err := ParsePrefixes([]string{"10.20.0.0/16", "2001:db8::/32"})
+	require.NoError(t, err)
+
+	assert.True(t, Allowed(netip.MustParseAddr("10.20.3.4"), networks))
+	assert.True(t, Allowed(netip.MustParseAddr("::ffff:10.20.3.4"), networks))
+	assert.True(t, Allowed(netip.MustParseAddr("2001:db8::5"), networks))
+	assert.False(t, Allowed(netip.MustParseAddr("10.21.0.1"), networks))
+	assert.False(t, Allowed(netip.Addr{}, networks))
+}
+
+func TestParsePrefixes_Invalid(t *testing.T) {
+	_, err := ParsePrefixes([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+
+	_, err = ParsePrefixes([]string{"office"})
+	assert.Error(t, err)
+}
+
+func TestAddrOf(t *testing.T) {
+	addr, ok := AddrOf(&net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 5000})
+	require.True(t, ok)
+	assert.Equal(t, "192.0.2.1", addr.String())
+
+	_, ok = AddrOf(&net.UnixAddr{Name: "/tmp/sso.sock", Net: "unix"})
+	assert.False(t, ok)
+}
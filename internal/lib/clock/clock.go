@@ -0,0 +1,59 @@
+// Package clock - источник текущего времени и таймеров.
+//
+// Сервисы берут время из Clock, а не из time.Now: в работе это настоящие часы (Real),
+// а тесты подставляют Fake и переводят его вперёд, чтобы проверить истечение токенов,
+// сессий и кодов без ожидания.
+package clock
+
+import "time"
+
+// Clock - текущее время и таймеры
+type Clock interface {
+	Now() time.Time
+	// After - канал, в который придёт время, когда пройдёт d
+	After(d time.Duration) <-chan time.Time
+	// NewTimer - таймер, срабатывающий через d
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer - таймер Clock (аналог *time.Timer)
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// Real - настоящие часы (time.Now, time.NewTimer)
+func Real() Clock {
+	return realClock{}
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{t: time.NewTimer(d)}
+}
+
+type realTimer struct {
+	t *time.Timer
+}
+
+func (r realTimer) C() <-chan time.Time {
+	return r.t.C
+}
+
+func (r realTimer) Stop() bool {
+	return r.t.Stop()
+}
+
+func (r realTimer) Reset(d time.Duration) bool {
+	return r.t.Reset(d)
+}
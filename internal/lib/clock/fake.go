@@ -0,0 +1,160 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Fake - часы, которые стоят на месте, пока тест не переведёт их Advance или Set.
+// Таймеры срабатывают в момент перевода, если их срок наступил
+type Fake struct {
+	mu      sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed chan struct{} // закрывается и заменяется при каждом изменении списка таймеров
+}
+
+// NewFake - часы, показывающие now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, changed: make(chan struct{})}
+}
+
+// Now - текущее время часов
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// After - канал, в который придёт время, когда часы переведут на d вперёд
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer - таймер, срабатывающий, когда часы переведут на d вперёд
+func (f *Fake) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{clock: f, c: make(chan time.Time, 1)}
+	t.Reset(d)
+
+	return t
+}
+
+// Advance - переводит часы на d вперёд и запускает наступившие таймеры
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	f.set(f.now.Add(d))
+	f.mu.Unlock()
+}
+
+// Set - переводит часы на время now (назад - тоже, таймеры при этом не срабатывают)
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	f.set(now)
+	f.mu.Unlock()
+}
+
+// Waiters - сколько таймеров ждут срабатывания
+func (f *Fake) Waiters() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.timers)
+}
+
+// BlockUntil - ждёт, пока ожидающих таймеров станет n: так тест узнаёт,
+// что фоновая горутина дошла до ожидания, и только потом переводит часы
+func (f *Fake) BlockUntil(n int) {
+	for {
+		f.mu.Lock()
+		if len(f.timers) == n {
+			f.mu.Unlock()
+
+			return
+		}
+		changed := f.changed
+		f.mu.Unlock()
+
+		<-changed
+	}
+}
+
+func (f *Fake) set(now time.Time) {
+	f.now = now
+
+	pending := f.timers[:0]
+	for _, t := range f.timers {
+		if now.Before(t.deadline) {
+			pending = append(pending, t)
+
+			continue
+		}
+		select {
+		case t.c <- now:
+		default:
+		}
+	}
+	clear(f.timers[len(pending):])
+	if len(pending) != len(f.timers) {
+		f.timers = pending
+		f.notify()
+	}
+}
+
+// notify - будит BlockUntil; вызывается под f.mu
+func (f *Fake) notify() {
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+// remove - убирает t из ожидающих; вызывается под f.mu
+func (f *Fake) remove(t *fakeTimer) bool {
+	for i, w := range f.timers {
+		if w == t {
+			f.timers = append(f.timers[:i], f.timers[i+1:]...)
+			f.notify()
+
+			return true
+		}
+	}
+
+	return false
+}
+
+type fakeTimer struct {
+	clock    *Fake
+	c        chan time.Time
+	deadline time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	active := f.remove(t)
+	t.deadline = f.now.Add(d)
+	if d <= 0 {
+		select {
+		case t.c <- f.now:
+		default:
+		}
+
+		return active
+	}
+	f.timers = append(f.timers, t)
+	f.notify()
+
+	return active
+}
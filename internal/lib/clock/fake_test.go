@@ -0,0 +1,84 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func fired(c <-chan time.Time) bool {
+	select {
+	case <-c:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestFake_AdvanceFiresDueTimers(t *testing.T) {
+	f := NewFake(start)
+
+	short := f.After(time.Minute)
+	long := f.NewTimer(time.Hour)
+	assert.Equal(t, 2, f.Waiters())
+
+	f.Advance(59 * time.Second)
+	assert.False(t, fired(short))
+
+	f.Advance(time.Second)
+	assert.True(t, fired(short))
+	assert.False(t, fired(long.C()))
+	assert.Equal(t, start.Add(time.Minute), f.Now())
+	assert.Equal(t, 1, f.Waiters())
+
+	f.Advance(time.Hour)
+	assert.True(t, fired(long.C()))
+	assert.Zero(t, f.Waiters())
+}
+
+func TestFake_StopAndReset(t *testing.T) {
+	f := NewFake(start)
+
+	timer := f.NewTimer(time.Minute)
+	require.True(t, timer.Stop())
+	require.False(t, timer.Stop(), "already stopped")
+
+	f.Advance(time.Hour)
+	assert.False(t, fired(timer.C()))
+
+	assert.False(t, timer.Reset(time.Second))
+	f.Advance(time.Second)
+	assert.True(t, fired(timer.C()))
+}
+
+func TestFake_SetBackwardsDoesNotFire(t *testing.T) {
+	f := NewFake(start)
+
+	c := f.After(time.Minute)
+	f.Set(start.Add(-time.Hour))
+	assert.False(t, fired(c))
+	assert.Equal(t, start.Add(-time.Hour), f.Now())
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	f := NewFake(start)
+
+	done := make(chan struct{})
+	go func() {
+		<-f.After(time.Second)
+		close(done)
+	}()
+
+	f.BlockUntil(1)
+	f.Advance(time.Second)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timer did not fire")
+	}
+}
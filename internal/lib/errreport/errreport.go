@@ -0,0 +1,301 @@
+// Package errreport - отправка неожиданных ошибок (паник и ответов INTERNAL) во внешний трекер.
+//
+// Отчёты отправляются в фоне из ограниченной очереди: недоступный трекер не задерживает запросы,
+// а лишние отчёты при переполнении отбрасываются. Паника при отправке перехватывается - сервис
+// из-за трекера не падает.
+package errreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"sso/internal/buildinfo"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Метрики отправки отчётов (видны в /debug/vars)
+var (
+	reportsSent    = expvar.NewInt("errreport_sent")    // принято трекером
+	reportsFailed  = expvar.NewInt("errreport_failed")  // не отправлено (трекер недоступен или отказал)
+	reportsDropped = expvar.NewInt("errreport_dropped") // отброшено из-за переполненной или остановленной очереди
+)
+
+// Report - одна неожиданная ошибка. Fields уже очищены от секретов: сюда не должны попадать пароли и токены
+type Report struct {
+	Message   string
+	Method    string // полное имя gRPC-метода
+	Code      string // итоговый код ответа
+	RequestID string
+	TraceID   string
+	Fields    map[string]string // поля запроса
+	Stack     string            // стек паники (пусто - ошибка без паники)
+	Time      time.Time
+}
+
+// Reporter - получатель отчётов. Report не блокирует и не паникует
+type Reporter interface {
+	Report(r Report)
+}
+
+type nop struct{}
+
+func (nop) Report(Report) {}
+
+// Nop - Reporter, который ничего не отправляет (трекер не настроен)
+func Nop() Reporter {
+	return nop{}
+}
+
+// Sentry - отправка отчётов в Sentry (store API) или совместимый трекер
+type Sentry struct {
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	serverName  string
+
+	client       *httpx.Client
+	log          *slog.Logger
+	flushTimeout time.Duration
+
+	reports chan Report
+	ctx     context.Context // отменяется, если очередь не успела отправиться за flushTimeout
+	cancel  context.CancelFunc
+	done    chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewSentry - запускает фоновую отправку по настройкам error_reporting.*; client - клиент исходящих
+// запросов с таймаутом cfg.Timeout, env - окружение, если cfg.Environment не задано
+func NewSentry(cfg config.ErrorReportingConfig, env string, client *httpx.Client, log *slog.Logger) (*Sentry, error) {
+	const op = "errreport.NewSentry"
+
+	endpoint, key, err := parseDSN(cfg.DSN.Reveal())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	environment := cfg.Environment
+	if environment == "" {
+		environment = env
+	}
+	host, _ := os.Hostname()
+	release := "sso@" + buildinfo.Get().Version
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Sentry{
+		endpoint:     endpoint,
+		auth:         fmt.Sprintf("Sentry sentry_version=7, sentry_key=%s, sentry_client=%s", key, release),
+		environment:  environment,
+		release:      release,
+		serverName:   host,
+		client:       client,
+		log:          log.With(slog.String("component", "error reporter")),
+		flushTimeout: cfg.FlushTimeout,
+		reports:      make(chan Report, max(cfg.QueueSize, 1)),
+		ctx:          ctx,
+		cancel:       cancel,
+		done:         make(chan struct{}),
+	}
+
+	go s.loop()
+
+	return s, nil
+}
+
+// parseDSN - адрес store API и открытый ключ проекта из DSN вида https://key@host/path/project
+func parseDSN(dsn string) (endpoint, key string, err error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return "", "", errors.New("invalid dsn")
+	}
+
+	key = u.User.Username()
+	path, project, _ := strings.Cut(strings.Trim(u.Path, "/"), "/")
+	if project == "" {
+		path, project = "", path
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" || key == "" || project == "" {
+		return "", "", errors.New("dsn must be like https://key@host/project")
+	}
+	if path != "" {
+		path = "/" + path
+	}
+
+	return fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path, project), key, nil
+}
+
+// Report - ставит отчёт в очередь; при переполненной или остановленной очереди отчёт отбрасывается
+func (s *Sentry) Report(r Report) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		reportsDropped.Add(1)
+		return
+	}
+	if r.Time.IsZero() {
+		r.Time = time.Now()
+	}
+
+	select {
+	case s.reports <- r:
+	default:
+		reportsDropped.Add(1)
+	}
+}
+
+// Close - перестаёт принимать отчёты и отправляет уже поставленные, но не дольше flushTimeout:
+// после этого отправка прерывается, а оставшиеся отчёты теряются
+func (s *Sentry) Close() error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.reports)
+	}
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.flushTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-s.done:
+	case <-timer.C:
+		s.log.Warn("error reports not flushed before deadline", slog.Int("pending", len(s.reports)))
+		s.cancel()
+		<-s.done
+	}
+	s.cancel()
+
+	return nil
+}
+
+func (s *Sentry) loop() {
+	defer close(s.done)
+
+	for r := range s.reports {
+		if s.ctx.Err() != nil {
+			reportsDropped.Add(1)
+			continue
+		}
+		s.deliver(r)
+	}
+}
+
+// deliver - отправляет один отчёт; паника при отправке только записывается в лог
+func (s *Sentry) deliver(r Report) {
+	defer func() {
+		if p := recover(); p != nil {
+			reportsFailed.Add(1)
+			s.log.Error("error report panicked", slog.Any("panic", p))
+		}
+	}()
+
+	if err := s.send(r); err != nil {
+		reportsFailed.Add(1)
+		s.log.Warn("failed to send error report", slog.String("error", err.Error()))
+
+		return
+	}
+	reportsSent.Add(1)
+}
+
+func (s *Sentry) send(r Report) error {
+	const op = "errreport.send"
+
+	body, err := json.Marshal(s.event(r))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	req, err := http.NewRequestWithContext(s.ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	resp, err := s.client.Do(s.ctx, req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4<<10))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", op, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// event - событие в формате store API
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Logger      string            `json:"logger"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Message     string            `json:"message"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+}
+
+func (s *Sentry) event(r Report) event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	level := "error"
+	if r.Stack != "" {
+		level = "fatal"
+	}
+
+	e := event{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   r.Time.UTC().Format(time.RFC3339Nano),
+		Level:       level,
+		Platform:    "go",
+		Logger:      "sso",
+		Environment: s.environment,
+		Release:     s.release,
+		ServerName:  s.serverName,
+		Message:     r.Message,
+		Transaction: r.Method,
+		Tags:        map[string]string{"method": r.Method, "code": r.Code},
+		Extra:       map[string]any{},
+	}
+	if r.RequestID != "" {
+		e.Tags["request_id"] = r.RequestID
+	}
+	if r.TraceID != "" {
+		e.Tags["trace_id"] = r.TraceID
+	}
+	if len(r.Fields) > 0 {
+		e.Extra["request"] = r.Fields
+	}
+	if r.Stack != "" {
+		e.Extra["stack"] = r.Stack
+	}
+
+	return e
+}
@@ -0,0 +1,113 @@
+package errreport
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSentry(t *testing.T, dsn string, queue int, flush time.Duration) *Sentry {
+	t.Helper()
+
+	outbound, err := httpx.NewFactory(config.OutboundConfig{})
+	require.NoError(t, err)
+
+	s, err := NewSentry(config.ErrorReportingConfig{
+		DSN: config.Secret(dsn), QueueSize: queue, FlushTimeout: flush,
+	}, "test", outbound.Client("sentry", time.Second), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestParseDSN(t *testing.T) {
+	endpoint, key, err := parseDSN("https://public@o1.ingest.sentry.io/42")
+	require.NoError(t, err)
+	assert.Equal(t, "https://o1.ingest.sentry.io/api/42/store/", endpoint)
+	assert.Equal(t, "public", key)
+
+	endpoint, _, err = parseDSN("http://public@sentry.local:9000/prefix/7")
+	require.NoError(t, err)
+	assert.Equal(t, "http://sentry.local:9000/prefix/api/7/store/", endpoint)
+
+	for _, dsn := range []string{"", "https://o1.ingest.sentry.io/42", "https://key@host", "ftp://key@host/1"} {
+		_, _, err := parseDSN(dsn)
+		assert.Error(t, err, dsn)
+	}
+}
+
+func TestSentry_Report(t *testing.T) {
+	events := make(chan map[string]any, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/42/store/", r.URL.Path)
+		assert.Contains(t, r.Header.Get("X-Sentry-Auth"), "sentry_key=public")
+
+		var e map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&e))
+		events <- e
+	}))
+	t.Cleanup(srv.Close)
+
+	s := newSentry(t, strings.Replace(srv.URL, "://", "://public@", 1)+"/42", 10, time.Second)
+	s.Report(Report{
+		Message: "boom", Method: "/auth.Auth/Login", Code: "Internal", RequestID: "req-1",
+		Fields: map[string]string{"email": "a@b.c", "password": "[redacted]"}, Stack: "goroutine 1",
+	})
+	require.NoError(t, s.Close())
+
+	e := <-events
+	assert.Equal(t, "boom", e["message"])
+	assert.Equal(t, "fatal", e["level"])
+	assert.Equal(t, "test", e["environment"])
+	assert.Len(t, e["event_id"], 32)
+	assert.Equal(t, map[string]any{"method": "/auth.Auth/Login", "code": "Internal", "request_id": "req-1"}, e["tags"])
+	assert.Equal(t, "[redacted]", e["extra"].(map[string]any)["request"].(map[string]any)["password"])
+
+	// После остановки отчёты отбрасываются
+	dropped := reportsDropped.Value()
+	s.Report(Report{Message: "late"})
+	assert.Equal(t, dropped+1, reportsDropped.Value())
+}
+
+func TestSentry_QueueFullAndFlushDeadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(func() {
+		close(release)
+		srv.Close()
+	})
+
+	s := newSentry(t, strings.Replace(srv.URL, "://", "://public@", 1)+"/42", 1, 50*time.Millisecond)
+
+	// Первый отчёт отправляется и зависает, второй ждёт в очереди, остальные отбрасываются
+	dropped := reportsDropped.Value()
+	for range 5 {
+		s.Report(Report{Message: "boom"})
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.GreaterOrEqual(t, reportsDropped.Value(), dropped+3)
+
+	// Зависший трекер не задерживает остановку дольше flush_timeout
+	start := time.Now()
+	require.NoError(t, s.Close())
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestNop(t *testing.T) {
+	assert.NotPanics(t, func() { Nop().Report(Report{Message: "boom"}) })
+}
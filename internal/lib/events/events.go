@@ -0,0 +1,75 @@
+// Package events - доменные события SSO для внешних потребителей (шина сообщений).
+//
+// Формат события - контракт с потребителями: поля можно только добавлять,
+// а несовместимые изменения требуют увеличения SchemaVersion.
+package events
+
+import (
+	"context"
+	"expvar"
+	"sso/internal/lib/requestid"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SchemaVersion - версия JSON-схемы события
+const SchemaVersion = 1
+
+// Типы событий. Это контракт с потребителями: переименовывать нельзя
+const (
+	TypeUserRegistered  = "user.registered"
+	TypeUserLoggedIn    = "user.logged_in"
+	TypeUserDeleted     = "user.deleted"
+	TypeTokenRevoked    = "token.revoked"
+	TypeLoginSuspicious = "login.suspicious"
+
+	TypeAccountDeletionRequested = "account.deletion_requested"
+	TypeAccountDeletionCancelled = "account.deletion_cancelled"
+	TypeAccountDeletionExecuted  = "account.deletion_executed"
+
+	TypeUserProvisioned   = "user.provisioned"   // создан или обновлён внешней системой (app_id - provisioner)
+	TypeUserDeprovisioned = "user.deprovisioned" // отключён внешней системой
+)
+
+// PublishFailures - сколько событий не удалось опубликовать (виден в /debug/vars)
+var PublishFailures = expvar.NewInt("events_publish_failures")
+
+// Event - доменное событие
+type Event struct {
+	Version    int       `json:"version"`              // Версия схемы (SchemaVersion)
+	ID         string    `json:"id"`                   // Уникальный id события; по нему потребители отбрасывают дубликаты
+	Type       string    `json:"type"`                 // Тип события (TypeUserRegistered и т. д.)
+	OccurredAt time.Time `json:"occurred_at"`          // Когда произошло событие (UTC)
+	RequestID  string    `json:"request_id,omitempty"` // Идентификатор запроса для корреляции с логами
+	UserID     int64     `json:"user_id,omitempty"`    // ID пользователя
+	AppID      int       `json:"app_id,omitempty"`     // ID приложения (0, если неприменимо)
+	Sequence   int64     `json:"sequence,omitempty"`   // Номер в outbox (монотонно растёт; 0 - событие опубликовано напрямую)
+}
+
+// New - создаёт событие типа typ; request id берётся из ctx
+func New(ctx context.Context, typ string, userID int64) Event {
+	return Event{
+		Version:    SchemaVersion,
+		ID:         uuid.NewString(),
+		Type:       typ,
+		OccurredAt: time.Now().UTC(),
+		RequestID:  requestid.From(ctx),
+		UserID:     userID,
+	}
+}
+
+// Publisher - отправляет события во внешнюю систему
+type Publisher interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// nop - публикатор по умолчанию, который никуда не отправляет события
+type nop struct{}
+
+func (nop) Publish(context.Context, Event) error { return nil }
+
+// Nop - публикатор, который ничего не делает
+func Nop() Publisher {
+	return nop{}
+}
@@ -0,0 +1,43 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"sso/internal/lib/requestid"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvent_JSONSchema(t *testing.T) {
+	ctx := requestid.Into(context.Background(), "req-1")
+
+	e := New(ctx, TypeUserLoggedIn, 42)
+	e.AppID = 7
+	e.OccurredAt = time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	e.ID = "0b5c2d6e-2f44-4c39-9a2e-3d2d0c5a8f11"
+
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	// Имена полей - контракт с потребителями
+	assert.JSONEq(t, `{
+		"version": 1,
+		"id": "0b5c2d6e-2f44-4c39-9a2e-3d2d0c5a8f11",
+		"type": "user.logged_in",
+		"occurred_at": "2025-01-02T03:04:05Z",
+		"request_id": "req-1",
+		"user_id": 42,
+		"app_id": 7
+	}`, string(data))
+}
+
+func TestNew_UniqueIDs(t *testing.T) {
+	a := New(context.Background(), TypeUserRegistered, 1)
+	b := New(context.Background(), TypeUserRegistered, 1)
+
+	assert.NotEqual(t, a.ID, b.ID)
+	assert.Empty(t, a.RequestID)
+}
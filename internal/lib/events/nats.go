@@ -0,0 +1,88 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sso/internal/config"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATS - публикатор событий в NATS JetStream.
+// Событие уходит в subject "<subject_prefix>.<type>", id события передаётся
+// в заголовке Nats-Msg-Id, поэтому JetStream сам отбрасывает повторы в окне дедупликации
+type NATS struct {
+	conn    *nats.Conn
+	js      jetstream.JetStream
+	prefix  string
+	timeout time.Duration
+}
+
+// NewNATS - подключается к NATS по настройкам events.nats.*
+func NewNATS(cfg config.NATSConfig) (*NATS, error) {
+	const op = "events.NewNATS"
+
+	opts := []nats.Option{
+		nats.Name("sso"),
+		nats.Timeout(cfg.Timeout),
+		nats.MaxReconnects(-1), // Соединение восстанавливается само, пока сервис работает
+	}
+	if cfg.CredsFile != "" {
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	}
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token.Reveal()))
+	}
+
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return &NATS{conn: conn, js: js, prefix: cfg.SubjectPrefix, timeout: cfg.Timeout}, nil
+}
+
+// Publish - публикует событие и ждёт подтверждения JetStream (не дольше events.nats.timeout)
+func (n *NATS) Publish(ctx context.Context, e Event) error {
+	const op = "events.NATS.Publish"
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, n.timeout)
+	defer cancel()
+
+	if _, err := n.js.Publish(ctx, n.Subject(e), data, jetstream.WithMsgID(e.ID)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Subject - subject, в который публикуется событие
+func (n *NATS) Subject(e Event) string {
+	return n.prefix + "." + e.Type
+}
+
+// Close - отправляет буферизованные сообщения и закрывает соединение
+func (n *NATS) Close() error {
+	const op = "events.NATS.Close"
+
+	if err := n.conn.Drain(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// Метрики outbox (видны в /debug/vars)
+var (
+	outboxPending   = expvar.NewInt("outbox_pending")                     // сколько событий ждут отправки
+	outboxOldestAge = expvar.NewFloat("outbox_oldest_unsent_age_seconds") // возраст самого старого неотправленного события
+)
+
+// OutboxStore - хранилище outbox
+type OutboxStore interface {
+	// AddOutboxEvent - записывает событие (внутри транзакции, если она есть в ctx)
+	AddOutboxEvent(ctx context.Context, eventID, eventType string, payload []byte) error
+	// PendingOutboxEvents - до limit неотправленных событий по возрастанию seq
+	PendingOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error)
+	// MarkOutboxSent - отмечает события отправленными
+	MarkOutboxSent(ctx context.Context, seqs []int64) error
+	// OutboxLag - количество неотправленных событий и время записи самого старого
+	OutboxLag(ctx context.Context) (int64, time.Time, error)
+}
+
+// OutboxWriter - Publisher, который не отправляет событие, а записывает его в outbox.
+// Вызванный внутри транзакции хранилища, пишет событие атомарно с изменением состояния
+type OutboxWriter struct {
+	store OutboxStore
+}
+
+// NewOutboxWriter - создаёт Publisher поверх outbox
+func NewOutboxWriter(store OutboxStore) *OutboxWriter {
+	return &OutboxWriter{store: store}
+}
+
+// Publish - записывает событие в outbox
+func (w *OutboxWriter) Publish(ctx context.Context, e Event) error {
+	const op = "events.OutboxWriter.Publish"
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := w.store.AddOutboxEvent(ctx, e.ID, e.Type, payload); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// Relay - фоновая отправка событий из outbox в Publisher.
+// Гарантия - at-least-once: событие отмечается отправленным только после успешной публикации,
+// поэтому при сбое между публикацией и отметкой оно уйдёт повторно с тем же id и sequence.
+// События публикуются строго по возрастанию sequence; на первой ошибке пачка прерывается
+type Relay struct {
+	store     OutboxStore
+	publisher Publisher
+	log       *slog.Logger
+	interval  time.Duration
+	batchSize int
+
+	mu      sync.Mutex
+	running bool // Run запущен
+	stopped bool // Stop уже вызван
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewRelay - создаёт relay, опрашивающий outbox каждые interval пачками по batchSize
+func NewRelay(log *slog.Logger, store OutboxStore, publisher Publisher, interval time.Duration, batchSize int) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		log:       log.With(slog.String("component", "outbox relay")),
+		interval:  interval,
+		batchSize: batchSize,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Run - опрашивает outbox, пока не вызван Stop
+func (r *Relay) Run() error {
+	r.mu.Lock()
+	if r.stopped {
+		r.mu.Unlock()
+
+		return nil
+	}
+	r.running = true
+	r.mu.Unlock()
+
+	defer close(r.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-r.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		// Полная пачка - скорее всего, есть ещё, поэтому не ждём следующего тика
+		for n := r.batchSize; n == r.batchSize && ctx.Err() == nil; {
+			n = r.relayBatch(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stop - прерывает текущую пачку и дожидается завершения Run (если он был запущен)
+func (r *Relay) Stop() {
+	r.mu.Lock()
+	if !r.stopped {
+		r.stopped = true
+		close(r.stop)
+	}
+	running := r.running
+	r.mu.Unlock()
+
+	if running {
+		<-r.done
+	}
+}
+
+// relayBatch - публикует одну пачку и возвращает количество отправленных событий
+func (r *Relay) relayBatch(ctx context.Context) int {
+	defer r.updateLag(ctx)
+
+	pending, err := r.store.PendingOutboxEvents(ctx, r.batchSize)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.log.Error("failed to read outbox", slog.String("error", err.Error()))
+		}
+
+		return 0
+	}
+
+	sent := make([]int64, 0, len(pending))
+	for _, row := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+
+		var e Event
+		if err := json.Unmarshal(row.Payload, &e); err != nil {
+			// Испорченную запись повторять бессмысленно: пропускаем, чтобы не блокировать очередь
+			r.log.Error("dropping malformed outbox event", slog.Int64("seq", row.Seq), slog.String("error", err.Error()))
+			sent = append(sent, row.Seq)
+
+			continue
+		}
+		e.Sequence = row.Seq
+
+		if err := r.publisher.Publish(ctx, e); err != nil {
+			PublishFailures.Add(1)
+			r.log.Warn("failed to publish outbox event, will retry",
+				slog.Int64("seq", row.Seq),
+				slog.String("event", e.Type),
+				slog.String("error", err.Error()),
+			)
+
+			break
+		}
+		sent = append(sent, row.Seq)
+	}
+
+	// Отметку пишем даже при остановке, иначе уже отправленные события уйдут повторно
+	if err := r.store.MarkOutboxSent(context.WithoutCancel(ctx), sent); err != nil {
+		r.log.Error("failed to mark outbox events sent", slog.String("error", err.Error()))
+
+		return 0
+	}
+
+	return len(sent)
+}
+
+// updateLag - обновляет метрики отставания
+func (r *Relay) updateLag(ctx context.Context) {
+	pending, oldest, err := r.store.OutboxLag(context.WithoutCancel(ctx))
+	if err != nil {
+		r.log.Warn("failed to read outbox lag", slog.String("error", err.Error()))
+
+		return
+	}
+
+	outboxPending.Set(pending)
+	if oldest.IsZero() {
+		outboxOldestAge.Set(0)
+	} else {
+		outboxOldestAge.Set(time.Since(oldest).Seconds())
+	}
+}
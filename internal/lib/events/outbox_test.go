@@ -0,0 +1,164 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memOutbox - outbox в памяти
+type memOutbox struct {
+	mu   sync.Mutex
+	rows []models.OutboxEvent
+	sent map[int64]bool
+}
+
+func (m *memOutbox) AddOutboxEvent(_ context.Context, _, _ string, payload []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.rows = append(m.rows, models.OutboxEvent{Seq: int64(len(m.rows) + 1), Payload: payload, CreatedAt: time.Now()})
+
+	return nil
+}
+
+func (m *memOutbox) PendingOutboxEvents(_ context.Context, limit int) ([]models.OutboxEvent, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []models.OutboxEvent
+	for _, r := range m.rows {
+		if !m.sent[r.Seq] && len(res) < limit {
+			res = append(res, r)
+		}
+	}
+
+	return res, nil
+}
+
+func (m *memOutbox) MarkOutboxSent(_ context.Context, seqs []int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, seq := range seqs {
+		m.sent[seq] = true
+	}
+
+	return nil
+}
+
+func (m *memOutbox) OutboxLag(context.Context) (int64, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var (
+		n      int64
+		oldest time.Time
+	)
+	for _, r := range m.rows {
+		if !m.sent[r.Seq] {
+			if n == 0 {
+				oldest = r.CreatedAt
+			}
+			n++
+		}
+	}
+
+	return n, oldest, nil
+}
+
+// flakyPublisher - падает на первых failures публикациях
+type flakyPublisher struct {
+	mu        sync.Mutex
+	failures  int
+	published []Event
+}
+
+func (p *flakyPublisher) Publish(_ context.Context, e Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, e)
+
+	return nil
+}
+
+func (p *flakyPublisher) Published() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]Event(nil), p.published...)
+}
+
+func TestRelay_DeliversInOrderAfterFailures(t *testing.T) {
+	store := &memOutbox{sent: make(map[int64]bool)}
+	writer := NewOutboxWriter(store)
+
+	for range 5 {
+		require.NoError(t, writer.Publish(context.Background(), New(context.Background(), TypeUserRegistered, 1)))
+	}
+
+	pub := &flakyPublisher{failures: 2}
+	relay := NewRelay(slog.New(slog.NewTextHandler(io.Discard, nil)), store, pub, 10*time.Millisecond, 2)
+
+	go func() { _ = relay.Run() }()
+	t.Cleanup(relay.Stop)
+
+	require.Eventually(t, func() bool { return len(pub.Published()) == 5 }, time.Second, 5*time.Millisecond)
+
+	for i, e := range pub.Published() {
+		assert.Equal(t, int64(i+1), e.Sequence)
+	}
+
+	pending, _, err := store.OutboxLag(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, pending)
+}
+
+func TestRelay_SkipsMalformed(t *testing.T) {
+	store := &memOutbox{sent: make(map[int64]bool)}
+	require.NoError(t, store.AddOutboxEvent(context.Background(), "bad", "x", []byte("not json")))
+
+	good, err := json.Marshal(New(context.Background(), TypeUserDeleted, 2))
+	require.NoError(t, err)
+	require.NoError(t, store.AddOutboxEvent(context.Background(), "good", TypeUserDeleted, good))
+
+	pub := &flakyPublisher{}
+	relay := NewRelay(slog.New(slog.NewTextHandler(io.Discard, nil)), store, pub, time.Hour, 10)
+
+	assert.Equal(t, 2, relay.relayBatch(context.Background()))
+	require.Len(t, pub.Published(), 1)
+	assert.Equal(t, int64(2), pub.Published()[0].Sequence)
+}
+
+func TestRelay_StopWithoutRun(t *testing.T) {
+	relay := NewRelay(slog.New(slog.NewTextHandler(io.Discard, nil)), &memOutbox{}, Nop(), time.Hour, 10)
+
+	done := make(chan struct{})
+	go func() {
+		relay.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked without Run")
+	}
+
+	require.NoError(t, relay.Run(), "Run after Stop returns immediately")
+}
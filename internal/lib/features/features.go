@@ -0,0 +1,133 @@
+// Package features - флаги постепенного включения рискованных изменений поведения.
+//
+// Флаг включается целиком (enabled), для списка приложений (app_ids) или для доли пользователей (percent).
+// Доля считается по хешу пользователя, а не случайно на каждый запрос: один и тот же пользователь
+// всегда попадает в одну и ту же группу, пока процент не изменится.
+package features
+
+import (
+	"hash/fnv"
+	"slices"
+	"sort"
+	"strconv"
+	"sync/atomic"
+)
+
+// Flag - имя флага. Код проверяет флаги только через константы ниже: имя, которого нет в All,
+// роняет тест TestFlags_Exhaustive
+type Flag string
+
+const (
+	// RehashOnLogin - пересчитывать при входе хеш пароля, посчитанный с устаревшей стоимостью bcrypt
+	RehashOnLogin Flag = "rehash_on_login"
+	// StrictScopes - права токена стороннего приложения должны входить в действующее согласие пользователя
+	StrictScopes Flag = "strict_scopes"
+	// ConstantTimeLogin - вход с неизвестным email тратит на проверку пароля столько же, сколько с известным
+	ConstantTimeLogin Flag = "constant_time_login"
+	// NormalizedEmail - email при входе и регистрации сравнивается без учёта регистра и пробелов по краям
+	NormalizedEmail Flag = "normalized_email"
+)
+
+// All - все известные флаги
+var All = []Flag{RehashOnLogin, StrictScopes, ConstantTimeLogin, NormalizedEmail}
+
+// Known - есть ли флаг с таким именем
+func Known(name string) bool {
+	return slices.Contains(All, Flag(name))
+}
+
+// Rule - где включён флаг. Флаг включён, если выполнено хотя бы одно из условий
+type Rule struct {
+	Enabled bool  `yaml:"enabled"` // Для всех
+	Percent int   `yaml:"percent"` // Для доли пользователей, 0-100
+	AppIDs  []int `yaml:"app_ids"` // Для всех пользователей этих приложений
+}
+
+// State - флаг и его правило (для GetServerInfo)
+type State struct {
+	Name Flag
+	Rule
+}
+
+// Set - текущие правила флагов. Нулевой и nil Set считает все флаги выключенными
+type Set struct {
+	rules atomic.Pointer[map[Flag]Rule]
+}
+
+// New - создаёт Set с правилами rules (ключ - имя флага)
+func New(rules map[string]Rule) *Set {
+	s := &Set{}
+	s.Configure(rules)
+
+	return s
+}
+
+// Configure - применяет новые правила (перезагрузка конфигурации). Неизвестные имена
+// отсекает проверка конфигурации; здесь они просто никогда не спрашиваются
+func (s *Set) Configure(rules map[string]Rule) {
+	m := make(map[Flag]Rule, len(rules))
+	for name, r := range rules {
+		m[Flag(name)] = r
+	}
+	s.rules.Store(&m)
+}
+
+// Enabled - включён ли флаг f для пользователя userID в приложении appID
+func (s *Set) Enabled(f Flag, appID int, userID int64) bool {
+	return s.EnabledFor(f, appID, strconv.FormatInt(userID, 10))
+}
+
+// EnabledFor - как Enabled, но доля считается по произвольному ключу. Нужен там, где пользователь
+// ещё не известен (например, вход до поиска по email): тогда ключом служит сам email
+func (s *Set) EnabledFor(f Flag, appID int, key string) bool {
+	if s == nil {
+		return false
+	}
+	rules := s.rules.Load()
+	if rules == nil {
+		return false
+	}
+
+	r, ok := (*rules)[f]
+	switch {
+	case !ok:
+		return false
+	case r.Enabled:
+		return true
+	case appID != 0 && slices.Contains(r.AppIDs, appID):
+		return true
+	case r.Percent > 0:
+		return bucket(f, key) < r.Percent
+	}
+
+	return false
+}
+
+// States - правила всех известных флагов по имени (выключенные - с пустым правилом)
+func (s *Set) States() []State {
+	var rules map[Flag]Rule
+	if s != nil {
+		if p := s.rules.Load(); p != nil {
+			rules = *p
+		}
+	}
+
+	res := make([]State, 0, len(All))
+	for _, f := range All {
+		res = append(res, State{Name: f, Rule: rules[f]})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return res
+}
+
+// bucket - группа 0-99 ключа для флага. Имя флага входит в хеш, чтобы первые проценты
+// разных флагов приходились на разных пользователей
+func bucket(f Flag, key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(f))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(key))
+
+	return int(h.Sum32() % 100)
+}
@@ -0,0 +1,160 @@
+package features
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSet_Enabled(t *testing.T) {
+	s := New(map[string]Rule{
+		string(StrictScopes):    {Enabled: true},
+		string(NormalizedEmail): {AppIDs: []int{3}},
+	})
+
+	assert.True(t, s.Enabled(StrictScopes, 1, 7))
+	assert.True(t, s.Enabled(NormalizedEmail, 3, 7))
+	assert.False(t, s.Enabled(NormalizedEmail, 4, 7))
+	assert.False(t, s.Enabled(RehashOnLogin, 3, 7), "flag without a rule is off")
+
+	var off *Set
+	assert.False(t, off.Enabled(StrictScopes, 1, 7))
+
+	s.Configure(nil)
+	assert.False(t, s.Enabled(StrictScopes, 1, 7), "reload turns the flag off")
+}
+
+func TestSet_PercentIsSticky(t *testing.T) {
+	s := New(map[string]Rule{string(RehashOnLogin): {Percent: 30}})
+
+	on := 0
+	for id := range int64(10000) {
+		first := s.Enabled(RehashOnLogin, 1, id)
+		for range 3 {
+			require.Equal(t, first, s.Enabled(RehashOnLogin, 1, id), "user %d changed group", id)
+		}
+		if first {
+			on++
+		}
+	}
+	assert.InDelta(t, 3000, on, 300)
+
+	// Увеличение доли не выключает флаг тем, у кого он уже был
+	wider := New(map[string]Rule{string(RehashOnLogin): {Percent: 60}})
+	for id := range int64(1000) {
+		if s.Enabled(RehashOnLogin, 1, id) {
+			assert.True(t, wider.Enabled(RehashOnLogin, 1, id))
+		}
+	}
+}
+
+func TestSet_States(t *testing.T) {
+	s := New(map[string]Rule{string(StrictScopes): {Percent: 10, AppIDs: []int{2}}})
+
+	states := s.States()
+	require.Len(t, states, len(All))
+	for _, st := range states {
+		if st.Name == StrictScopes {
+			assert.Equal(t, Rule{Percent: 10, AppIDs: []int{2}}, st.Rule)
+		} else {
+			assert.Zero(t, st.Rule, st.Name)
+		}
+	}
+}
+
+// TestFlags_Exhaustive - каждый объявленный флаг есть в All, а код нигде не спрашивает флаг
+// по имени, которого нет в All (строкой вместо константы)
+func TestFlags_Exhaustive(t *testing.T) {
+	fset := token.NewFileSet()
+
+	self, err := parser.ParseFile(fset, "features.go", nil, 0)
+	require.NoError(t, err)
+	for _, decl := range self.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if typ, ok := vs.Type.(*ast.Ident); !ok || typ.Name != "Flag" {
+				continue
+			}
+			for _, v := range vs.Values {
+				name, err := strconv.Unquote(v.(*ast.BasicLit).Value)
+				require.NoError(t, err)
+				assert.True(t, Known(name), "flag %q is declared but missing from All", name)
+			}
+		}
+	}
+
+	root := filepath.Join("..", "..", "..")
+	err = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if name := d.Name(); name == "vendor" || (strings.HasPrefix(name, ".") && name != "." && name != "..") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, ".pb.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		for _, name := range flagLiterals(file) {
+			assert.True(t, Known(name), "%s: unknown feature flag %q", path, name)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+// flagLiterals - строковые имена флагов в файле: первый аргумент Enabled/EnabledFor и преобразования Flag("...")
+func flagLiterals(file *ast.File) []string {
+	var res []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) == 0 {
+			return true
+		}
+
+		var name string
+		switch fun := call.Fun.(type) {
+		case *ast.SelectorExpr:
+			name = fun.Sel.Name
+		case *ast.Ident:
+			name = fun.Name
+		}
+		if !slices.Contains([]string{"Enabled", "EnabledFor", "Flag"}, name) {
+			return true
+		}
+		// Enabled без аргументов (например, maintenance.Mode) - не флаг
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if !ok || lit.Kind != token.STRING {
+			return true
+		}
+		if s, err := strconv.Unquote(lit.Value); err == nil {
+			res = append(res, s)
+		}
+
+		return true
+	})
+
+	return res
+}
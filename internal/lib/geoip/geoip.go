@@ -0,0 +1,169 @@
+// Package geoip - страна и автономная система (ASN) по IP-адресу из баз MaxMind (.mmdb).
+//
+// Базы обновляются снаружи (geoipupdate, cron), поэтому DB время от времени проверяет файлы
+// и переоткрывает заменённые, не останавливая поиск. Пока новая база не открылась, работает прежняя.
+package geoip
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"sso/internal/config"
+	"sso/internal/domain/models"
+	"sync"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+)
+
+// DB - базы стран и ASN; безопасна для конкурентного использования
+type DB struct {
+	log        *slog.Logger
+	checkEvery time.Duration
+
+	mu        sync.RWMutex
+	country   *database // nil - база стран не настроена
+	asn       *database // nil - база ASN не настроена
+	checkedAt time.Time
+}
+
+// database - открытый файл базы и его версия на момент открытия
+type database struct {
+	path    string
+	reader  *geoip2.Reader
+	modTime time.Time
+	size    int64
+}
+
+// Open - открывает базы из cfg (пустой путь - база не используется)
+func Open(log *slog.Logger, cfg config.GeoConfig) (*DB, error) {
+	const op = "geoip.Open"
+
+	db := &DB{
+		log:        log.With(slog.String("component", "geoip")),
+		checkEvery: cfg.CheckInterval,
+		checkedAt:  time.Now(),
+	}
+
+	var err error
+	if cfg.CountryDB != "" {
+		if db.country, err = openDatabase(cfg.CountryDB); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+	if cfg.ASNDB != "" {
+		if db.asn, err = openDatabase(cfg.ASNDB); err != nil {
+			_ = db.Close()
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return db, nil
+}
+
+func openDatabase(path string) (*database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := geoip2.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+
+	return &database{path: path, reader: reader, modTime: info.ModTime(), size: info.Size()}, nil
+}
+
+// Locate - страна и ASN адреса. false - адрес не найден ни в одной базе (например, частная сеть)
+func (d *DB) Locate(addr netip.Addr) (models.GeoLocation, bool) {
+	d.reloadIfChanged()
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	ip := addr.Unmap().AsSlice()
+
+	var loc models.GeoLocation
+	if d.country != nil {
+		if rec, err := d.country.reader.Country(ip); err == nil {
+			loc.Country = rec.Country.IsoCode
+		}
+	}
+	if d.asn != nil {
+		if rec, err := d.asn.reader.ASN(ip); err == nil {
+			loc.ASN = rec.AutonomousSystemNumber
+		}
+	}
+
+	return loc, loc.Country != "" || loc.ASN != 0
+}
+
+// reloadIfChanged - не чаще checkEvery проверяет файлы и переоткрывает изменившиеся
+func (d *DB) reloadIfChanged() {
+	d.mu.RLock()
+	due := time.Since(d.checkedAt) >= d.checkEvery
+	d.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if time.Since(d.checkedAt) < d.checkEvery {
+		return
+	}
+	d.checkedAt = time.Now()
+
+	for _, db := range []**database{&d.country, &d.asn} {
+		if *db == nil {
+			continue
+		}
+
+		fresh, err := (*db).reopenIfChanged()
+		if err != nil {
+			d.log.Warn("failed to reload geoip database, keeping the previous one",
+				slog.String("path", (*db).path), slog.String("error", err.Error()))
+
+			continue
+		}
+		if fresh != nil {
+			_ = (*db).reader.Close()
+			*db = fresh
+			d.log.Info("geoip database reloaded", slog.String("path", fresh.path))
+		}
+	}
+}
+
+// reopenIfChanged - новая версия базы, если файл изменился с момента открытия (nil - не изменился)
+func (db *database) reopenIfChanged() (*database, error) {
+	info, err := os.Stat(db.path)
+	if err != nil {
+		return nil, err
+	}
+	if info.ModTime().Equal(db.modTime) && info.Size() == db.size {
+		return nil, nil
+	}
+
+	return openDatabase(db.path)
+}
+
+// Close - закрывает базы
+func (d *DB) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var errs []error
+	for _, db := range []*database{d.country, d.asn} {
+		if db != nil {
+			errs = append(errs, db.reader.Close())
+		}
+	}
+	d.country, d.asn = nil, nil
+
+	return errors.Join(errs...)
+}
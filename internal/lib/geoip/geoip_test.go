@@ -0,0 +1,43 @@
+package geoip
+
+import (
+	"io"
+	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sso/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestOpen_MissingFile(t *testing.T) {
+	_, err := Open(testLogger(), config.GeoConfig{
+		CountryDB: filepath.Join(t.TempDir(), "GeoLite2-Country.mmdb"), CheckInterval: time.Minute,
+	})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestOpen_NotAnMMDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "GeoLite2-ASN.mmdb")
+	require.NoError(t, os.WriteFile(path, []byte("not a maxmind database"), 0o600))
+
+	_, err := Open(testLogger(), config.GeoConfig{ASNDB: path, CheckInterval: time.Minute})
+	assert.Error(t, err)
+}
+
+func TestLocate_NoDatabases(t *testing.T) {
+	db, err := Open(testLogger(), config.GeoConfig{CheckInterval: time.Minute})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	_, ok := db.Locate(netip.MustParseAddr("198.51.100.1"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,107 @@
+// Package hashpool - ограничение числа одновременных bcrypt-операций.
+//
+// bcrypt намеренно дорогой: при всплеске входов каждая горутина запроса считает свой хеш,
+// и несколько сотен параллельных логинов занимают все ядра, так что не отвечают даже health-проверки.
+// Pool пропускает не больше workers операций одновременно, остальные ждут в очереди ограниченной длины,
+// а сверх неё сразу получают ErrOverloaded - лишняя нагрузка отбрасывается, а не копится
+package hashpool
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"runtime"
+	"sso/internal/lib/metrics"
+	"sync/atomic"
+	"time"
+)
+
+// Метрики пула (видны в /debug/vars)
+var (
+	waitSeconds = metrics.NewHistogram(metrics.DurationBuckets) // время ожидания свободного слота
+	queued      = expvar.NewInt("bcrypt_queued")                // сколько операций сейчас ждут слот
+	rejected    = expvar.NewInt("bcrypt_rejected")              // отклонено из-за переполненной очереди
+)
+
+func init() {
+	expvar.Publish("bcrypt_wait_seconds", waitSeconds)
+}
+
+// ErrOverloaded - очередь заполнена, операция отклонена без ожидания
+var ErrOverloaded = errors.New("too many concurrent password operations")
+
+// Pool - семафор на bcrypt-операции. Нулевой указатель допустим: операции выполняются без ограничений
+type Pool struct {
+	slots    chan struct{}
+	waiting  atomic.Int64
+	maxQueue int64
+}
+
+// New - пул на workers одновременных операций (0 - по числу CPU) с очередью до queueDepth ожидающих
+// (0 - очередь не ограничена)
+func New(workers, queueDepth int) *Pool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	return &Pool{
+		slots:    make(chan struct{}, workers),
+		maxQueue: int64(queueDepth),
+	}
+}
+
+// Do - выполняет fn, когда освободится слот. Возвращает ErrOverloaded, если очередь заполнена,
+// и ошибку контекста, если он отменён во время ожидания (место в очереди при этом сразу освобождается)
+func (p *Pool) Do(ctx context.Context, fn func()) error {
+	if p == nil {
+		fn()
+
+		return nil
+	}
+
+	if err := p.acquire(ctx); err != nil {
+		return err
+	}
+	defer func() { <-p.slots }()
+
+	fn()
+
+	return nil
+}
+
+func (p *Pool) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	// Свободный слот есть - очередь не нужна
+	select {
+	case p.slots <- struct{}{}:
+		waitSeconds.Observe(0)
+
+		return nil
+	default:
+	}
+
+	n := p.waiting.Add(1)
+	defer p.waiting.Add(-1)
+
+	if p.maxQueue > 0 && n > p.maxQueue {
+		rejected.Add(1)
+
+		return ErrOverloaded
+	}
+
+	queued.Add(1)
+	defer queued.Add(-1)
+
+	start := time.Now()
+	select {
+	case p.slots <- struct{}{}:
+		waitSeconds.Observe(time.Since(start).Seconds())
+
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
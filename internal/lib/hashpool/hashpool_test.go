@@ -0,0 +1,131 @@
+package hashpool
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// occupy - занимает все слоты пула, пока не будет вызвана возвращённая функция
+func occupy(t *testing.T, p *Pool, workers int) func() {
+	t.Helper()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = p.Do(context.Background(), func() {
+				started <- struct{}{}
+				<-release
+			})
+		}()
+	}
+	for range workers {
+		<-started
+	}
+
+	return func() {
+		close(release)
+		wg.Wait()
+	}
+}
+
+// waitQueued - ждёт, пока в очереди пула окажется n операций
+func waitQueued(t *testing.T, p *Pool, n int64) {
+	t.Helper()
+
+	require.Eventually(t, func() bool { return p.waiting.Load() == n }, time.Second, time.Millisecond)
+}
+
+func TestPool_LimitsConcurrency(t *testing.T) {
+	const workers = 2
+
+	p := New(workers, 0)
+
+	var (
+		mu      sync.Mutex
+		running int
+		peak    int
+		wg      sync.WaitGroup
+	)
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, p.Do(context.Background(), func() {
+				mu.Lock()
+				running++
+				peak = max(peak, running)
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+			}))
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, peak, workers)
+}
+
+func TestPool_RejectsBeyondQueueDepth(t *testing.T) {
+	p := New(1, 1)
+	release := occupy(t, p, 1)
+
+	// Первый ожидающий помещается в очередь
+	queuedDone := make(chan error, 1)
+	go func() { queuedDone <- p.Do(context.Background(), func() {}) }()
+	waitQueued(t, p, 1)
+
+	// Второму места нет - отказ без ожидания
+	err := p.Do(context.Background(), func() { t.Error("rejected operation must not run") })
+	require.ErrorIs(t, err, ErrOverloaded)
+
+	release()
+	require.NoError(t, <-queuedDone)
+}
+
+func TestPool_CancelReleasesQueueSlot(t *testing.T) {
+	p := New(1, 1)
+	release := occupy(t, p, 1)
+	defer release()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- p.Do(ctx, func() { t.Error("canceled operation must not run") }) }()
+	waitQueued(t, p, 1)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("canceled operation is still waiting")
+	}
+
+	// Место в очереди освободилось: следующий запрос снова может ждать
+	assert.Zero(t, p.waiting.Load())
+
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, p.Do(ctx, func() {}), context.DeadlineExceeded)
+}
+
+func TestPool_Nil(t *testing.T) {
+	var p *Pool
+
+	ran := false
+	require.NoError(t, p.Do(context.Background(), func() { ran = true }))
+	assert.True(t, ran)
+}
@@ -0,0 +1,211 @@
+// Package httpx - HTTP-клиент для запросов к внешним сервисам.
+//
+// Все исходящие запросы идут через один пул соединений (Factory) с ограничениями
+// и прокси из outbound.*. Клиент каждого сервиса (Client) получает свой таймаут: он действует
+// на каждую попытку, даже если у контекста вызывающего нет дедлайна (фоновые задачи).
+// GET и HEAD повторяются при сетевой ошибке и ответах 5xx/429, остальные методы - никогда.
+// Метрики видны в /debug/vars:
+//
+//	outbound_request_duration_seconds - гистограмма длительности попыток по сервисам
+//	outbound_responses                - {"<сервис>": {"200": n, "503": n, "error": n}}
+package httpx
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sso/internal/config"
+	"sso/internal/lib/metrics"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// maxDrain - сколько байт ответа дочитывать перед повтором, чтобы соединение вернулось в пул
+const maxDrain = 64 << 10
+
+// Метрики исходящих запросов (видны в /debug/vars)
+var (
+	requestDuration = metrics.NewHistogramVec(metrics.DurationBuckets)
+	responses       = expvar.NewMap("outbound_responses")
+
+	responsesMu sync.Mutex // создание вложенной карты сервиса в responses
+)
+
+func init() {
+	expvar.Publish("outbound_request_duration_seconds", requestDuration)
+}
+
+// Factory - общий пул соединений, из которого создаются клиенты сервисов
+type Factory struct {
+	transport *http.Transport
+	retries   int
+	backoff   time.Duration
+}
+
+// NewFactory - пул соединений по настройкам outbound.*
+func NewFactory(cfg config.OutboundConfig) (*Factory, error) {
+	const op = "httpx.NewFactory"
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	transport.IdleConnTimeout = cfg.IdleConnTimeout
+
+	if cfg.Proxy != "" {
+		proxy, err := url.Parse(cfg.Proxy)
+		if err != nil {
+			// Сам адрес в ошибку не попадает: в нём может быть пароль
+			return nil, fmt.Errorf("%s: invalid proxy URL", op)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	retries := cfg.Retries
+	if cfg.NoRetries {
+		retries = 0
+	}
+
+	return &Factory{transport: transport, retries: retries, backoff: cfg.RetryBackoff}, nil
+}
+
+// Client - клиент сервиса target (метка в метриках) с таймаутом одной попытки timeout (0 - без таймаута)
+func (f *Factory) Client(target string, timeout time.Duration) *Client {
+	return &Client{
+		http:    &http.Client{Transport: f.transport},
+		target:  target,
+		timeout: timeout,
+		retries: f.retries,
+		backoff: f.backoff,
+	}
+}
+
+// Client - HTTP-клиент одного внешнего сервиса
+type Client struct {
+	http    *http.Client
+	target  string
+	timeout time.Duration
+	retries int
+	backoff time.Duration
+}
+
+// Do - выполняет запрос в контексте ctx (контекст запроса req не используется).
+// Таймаут попытки действует, пока не закрыто тело ответа, поэтому тело нужно закрывать всегда
+func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.attempt(ctx, req)
+		if !idempotent || attempt >= c.retries || !retryable(resp, err) || ctx.Err() != nil {
+			return resp, err
+		}
+
+		if resp != nil {
+			_, _ = io.CopyN(io.Discard, resp.Body, maxDrain)
+			_ = resp.Body.Close()
+		}
+
+		if err := sleep(ctx, c.delay(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Get - GET url с заголовками header
+func (c *Client) Get(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	return c.Do(ctx, req)
+}
+
+// attempt - одна попытка со своим таймаутом; таймаут отменяется при закрытии тела ответа
+func (c *Client) attempt(ctx context.Context, req *http.Request) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if c.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(req.WithContext(ctx))
+	requestDuration.With(c.target).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		cancel()
+		countResponse(c.target, "error")
+
+		return nil, err
+	}
+	countResponse(c.target, strconv.Itoa(resp.StatusCode))
+
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// delay - пауза перед повтором номер attempt+1: backoff * 2^attempt со случайным разбросом ±50%
+func (c *Client) delay(attempt int) time.Duration {
+	d := c.backoff << attempt
+	if d <= 0 {
+		return 0
+	}
+
+	return d/2 + rand.N(d)
+}
+
+// retryable - попытку стоит повторить: сетевая ошибка, перегрузка или ошибка сервера
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func countResponse(target, status string) {
+	m, ok := responses.Get(target).(*expvar.Map)
+	if !ok {
+		responsesMu.Lock()
+		if m, ok = responses.Get(target).(*expvar.Map); !ok {
+			m = new(expvar.Map)
+			responses.Set(target, m)
+		}
+		responsesMu.Unlock()
+	}
+
+	m.Add(status, 1)
+}
+
+// cancelOnClose - тело ответа, закрытие которого отменяет контекст попытки
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+
+	return err
+}
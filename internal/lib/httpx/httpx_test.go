@@ -0,0 +1,172 @@
+package httpx
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sso/internal/config"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, cfg config.OutboundConfig, target string, timeout time.Duration) *Client {
+	t.Helper()
+
+	f, err := NewFactory(cfg)
+	require.NoError(t, err)
+
+	return f.Client(target, timeout)
+}
+
+// statuses - сервер, отвечающий по очереди кодами из codes (дальше - 200 "ok")
+func statuses(t *testing.T, calls *atomic.Int32, codes ...int) *httptest.Server {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n := int(calls.Add(1))
+		if n <= len(codes) {
+			w.WriteHeader(codes[n-1])
+			return
+		}
+		_, _ = io.WriteString(w, "ok")
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestClient_RetriesIdempotentGET(t *testing.T) {
+	var calls atomic.Int32
+	srv := statuses(t, &calls, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+	c := newClient(t, config.OutboundConfig{Retries: 2, RetryBackoff: time.Millisecond}, "test-retry", time.Second)
+
+	resp, err := c.Get(context.Background(), srv.URL, nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(body))
+	assert.EqualValues(t, 3, calls.Load())
+
+	m := responses.Get("test-retry").(*expvar.Map)
+	assert.Equal(t, "1", m.Get("503").String())
+	assert.Equal(t, "1", m.Get("429").String())
+	assert.Equal(t, "1", m.Get("200").String())
+	assert.EqualValues(t, 3, requestDuration.With("test-retry").Count())
+}
+
+func TestClient_RetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	srv := statuses(t, &calls, 500, 500, 500, 500)
+	c := newClient(t, config.OutboundConfig{Retries: 2, RetryBackoff: time.Millisecond}, "test", time.Second)
+
+	resp, err := c.Get(context.Background(), srv.URL, nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Последний ответ отдаётся вызывающему как есть
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.EqualValues(t, 3, calls.Load())
+}
+
+func TestClient_NoRetries(t *testing.T) {
+	for name, tc := range map[string]struct {
+		cfg    config.OutboundConfig
+		method string
+	}{
+		"POST":       {cfg: config.OutboundConfig{Retries: 2, RetryBackoff: time.Millisecond}, method: http.MethodPost},
+		"no_retries": {cfg: config.OutboundConfig{Retries: 2, RetryBackoff: time.Millisecond, NoRetries: true}, method: http.MethodGet},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := statuses(t, &calls, http.StatusServiceUnavailable)
+			c := newClient(t, tc.cfg, "test", time.Second)
+
+			req, err := http.NewRequest(tc.method, srv.URL, strings.NewReader("payload"))
+			require.NoError(t, err)
+
+			resp, err := c.Do(context.Background(), req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+			assert.EqualValues(t, 1, calls.Load())
+		})
+	}
+}
+
+// Таймаут действует и для контекста без дедлайна, причём на каждую попытку
+func TestClient_Timeout(t *testing.T) {
+	var calls atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	c := newClient(t, config.OutboundConfig{Retries: 1, RetryBackoff: time.Millisecond}, "test", 50*time.Millisecond)
+
+	start := time.Now()
+	_, err := c.Get(context.Background(), srv.URL, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 2*time.Second)
+	assert.EqualValues(t, 2, calls.Load())
+}
+
+// Отмена контекста вызывающего прекращает повторы
+func TestClient_CanceledContext(t *testing.T) {
+	var calls atomic.Int32
+	srv := statuses(t, &calls, 503, 503, 503)
+	c := newClient(t, config.OutboundConfig{Retries: 2, RetryBackoff: time.Hour}, "test", time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := c.Get(ctx, srv.URL, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.EqualValues(t, 1, calls.Load())
+}
+
+func TestClient_Proxy(t *testing.T) {
+	var proxied atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied.Store(r.URL.String())
+		_, _ = io.WriteString(w, "via proxy")
+	}))
+	defer proxy.Close()
+
+	c := newClient(t, config.OutboundConfig{Proxy: proxy.URL}, "test", time.Second)
+
+	resp, err := c.Get(context.Background(), "http://api.example.com/range/ABCDE", http.Header{"Add-Padding": {"true"}})
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	assert.Equal(t, "via proxy", string(body))
+	assert.Equal(t, "http://api.example.com/range/ABCDE", proxied.Load())
+}
+
+func TestClient_Delay(t *testing.T) {
+	c := &Client{backoff: 100 * time.Millisecond}
+
+	for attempt, base := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond} {
+		for range 20 {
+			d := c.delay(attempt)
+			assert.GreaterOrEqual(t, d, base/2)
+			assert.Less(t, d, base*3/2)
+		}
+	}
+}
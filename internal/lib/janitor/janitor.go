@@ -0,0 +1,150 @@
+package janitor
+
+import (
+	"context"
+	"log/slog"
+	"sso/internal/lib/clock"
+	"sync"
+	"time"
+)
+
+// Task - удаление одного вида устаревших записей. Run удаляет не больше limit записей
+// и возвращает, сколько удалил: полная пачка означает, что, скорее всего, есть ещё
+type Task struct {
+	Name string
+	Run  func(ctx context.Context, limit int) (int, error)
+}
+
+// Janitor - фоновое удаление устаревших записей: каждые interval выполняет все задачи по очереди.
+// Ошибка задачи логируется и не мешает остальным; задача повторится на следующем тике
+type Janitor struct {
+	log       *slog.Logger
+	clock     clock.Clock
+	interval  time.Duration
+	batchSize int
+	tasks     []Task
+
+	mu      sync.Mutex
+	running bool                 // Run запущен
+	stopped bool                 // Stop уже вызван
+	lastRun map[string]time.Time // имя задачи -> когда она последний раз прошла без ошибки
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New - создаёт janitor, выполняющий tasks каждые interval пачками по batchSize
+func New(log *slog.Logger, interval time.Duration, batchSize int, tasks ...Task) *Janitor {
+	return &Janitor{
+		log:       log.With(slog.String("component", "janitor")),
+		clock:     clock.Real(),
+		interval:  interval,
+		batchSize: batchSize,
+		tasks:     tasks,
+		lastRun:   make(map[string]time.Time, len(tasks)),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// SetClock - источник времени для тиков и LastRuns (по умолчанию настоящие часы). Вызывается до Run
+func (j *Janitor) SetClock(c clock.Clock) {
+	j.clock = c
+}
+
+// Run - выполняет задачи, пока не вызван Stop
+func (j *Janitor) Run() error {
+	j.mu.Lock()
+	if j.stopped {
+		j.mu.Unlock()
+
+		return nil
+	}
+	j.running = true
+	j.mu.Unlock()
+
+	defer close(j.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-j.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for {
+		for _, task := range j.tasks {
+			j.runTask(ctx, task)
+		}
+
+		// Таймер заводится после прохода: следующий начнётся через interval после конца текущего
+		timer := j.clock.NewTimer(j.interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil
+		case <-timer.C():
+		}
+	}
+}
+
+// Stop - прерывает текущую задачу и дожидается завершения Run (если он был запущен)
+func (j *Janitor) Stop() {
+	j.mu.Lock()
+	if !j.stopped {
+		j.stopped = true
+		close(j.stop)
+	}
+	running := j.running
+	j.mu.Unlock()
+
+	if running {
+		<-j.done
+	}
+}
+
+// LastRuns - когда каждая задача последний раз прошла до конца без ошибки. Задачи, которые ещё
+// ни разу не прошли, в ответе есть с нулевым временем: по ним видно, что уборка застряла
+func (j *Janitor) LastRuns() map[string]time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	res := make(map[string]time.Time, len(j.tasks))
+	for _, task := range j.tasks {
+		res[task.Name] = j.lastRun[task.Name]
+	}
+
+	return res
+}
+
+// runTask - выполняет задачу пачками, пока пачки полные
+func (j *Janitor) runTask(ctx context.Context, task Task) {
+	total := 0
+	for ctx.Err() == nil {
+		n, err := task.Run(ctx, j.batchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				j.log.Error("cleanup failed", slog.String("task", task.Name), slog.String("error", err.Error()))
+			}
+
+			break
+		}
+		total += n
+
+		if n < j.batchSize {
+			j.mu.Lock()
+			j.lastRun[task.Name] = j.clock.Now()
+			j.mu.Unlock()
+
+			break
+		}
+	}
+
+	if total > 0 {
+		j.log.Info("cleanup finished", slog.String("task", task.Name), slog.Int("deleted", total))
+	}
+}
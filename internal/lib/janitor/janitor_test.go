@@ -0,0 +1,128 @@
+package janitor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sso/internal/lib/clock"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func TestJanitor_RepeatsFullBatches(t *testing.T) {
+	// 7 записей пачками по 3: 3 + 3 + 1, после неполной пачки задача ждёт следующего тика
+	var left, calls atomic.Int64
+	left.Store(7)
+	task := Task{Name: "rows", Run: func(_ context.Context, limit int) (int, error) {
+		calls.Add(1)
+		n := min(int(left.Load()), limit)
+		left.Add(-int64(n))
+
+		return n, nil
+	}}
+
+	j := New(discard(), time.Hour, 3, task)
+	go func() { _ = j.Run() }()
+	t.Cleanup(j.Stop)
+
+	require.Eventually(t, func() bool { return calls.Load() == 3 }, time.Second, 5*time.Millisecond)
+	assert.Zero(t, left.Load())
+}
+
+func TestJanitor_FailingTaskDoesNotBlockOthers(t *testing.T) {
+	var ran atomic.Bool
+	failing := Task{Name: "failing", Run: func(context.Context, int) (int, error) {
+		return 0, errors.New("boom")
+	}}
+	ok := Task{Name: "ok", Run: func(context.Context, int) (int, error) {
+		ran.Store(true)
+
+		return 0, nil
+	}}
+
+	j := New(discard(), time.Hour, 10, failing, ok)
+	go func() { _ = j.Run() }()
+	t.Cleanup(j.Stop)
+
+	require.Eventually(t, ran.Load, time.Second, 5*time.Millisecond)
+}
+
+func TestJanitor_StopWithoutRun(t *testing.T) {
+	j := New(discard(), time.Hour, 10)
+
+	done := make(chan struct{})
+	go func() {
+		j.Stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Stop blocked without Run")
+	}
+
+	require.NoError(t, j.Run(), "Run after Stop returns immediately")
+}
+
+func TestJanitor_LastRuns(t *testing.T) {
+	var ran atomic.Bool
+	failing := Task{Name: "failing", Run: func(context.Context, int) (int, error) {
+		return 0, errors.New("boom")
+	}}
+	ok := Task{Name: "ok", Run: func(context.Context, int) (int, error) {
+		ran.Store(true)
+
+		return 0, nil
+	}}
+
+	j := New(discard(), time.Hour, 10, failing, ok)
+	assert.Equal(t, map[string]time.Time{"failing": {}, "ok": {}}, j.LastRuns())
+
+	start := time.Now()
+	go func() { _ = j.Run() }()
+	t.Cleanup(j.Stop)
+
+	require.Eventually(t, func() bool { return !j.LastRuns()["ok"].IsZero() }, time.Second, 5*time.Millisecond)
+	runs := j.LastRuns()
+	assert.False(t, runs["ok"].Before(start))
+	// Задача с ошибкой не считается выполненной
+	assert.True(t, runs["failing"].IsZero())
+	assert.True(t, ran.Load())
+}
+
+func TestJanitor_RunsEveryInterval(t *testing.T) {
+	var calls atomic.Int64
+	task := Task{Name: "rows", Run: func(context.Context, int) (int, error) {
+		calls.Add(1)
+
+		return 0, nil
+	}}
+
+	now := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	j := New(discard(), time.Hour, 10, task)
+	j.SetClock(now)
+	go func() { _ = j.Run() }()
+	t.Cleanup(j.Stop)
+
+	now.BlockUntil(1)
+	require.EqualValues(t, 1, calls.Load())
+	assert.Equal(t, now.Now(), j.LastRuns()["rows"])
+
+	now.Advance(59 * time.Minute)
+	assert.EqualValues(t, 1, calls.Load(), "interval has not passed yet")
+
+	now.Advance(time.Minute)
+	require.Eventually(t, func() bool { return calls.Load() == 2 }, time.Second, time.Millisecond)
+	now.BlockUntil(1)
+	assert.Equal(t, now.Now(), j.LastRuns()["rows"])
+}
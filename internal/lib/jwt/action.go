@@ -0,0 +1,37 @@
+package jwt
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrNotActionToken - токен без клейма purpose (обычный токен доступа)
+var ErrNotActionToken = errors.New("not an action token")
+
+// NewActionToken - токен, разрешающий userID одно действие purpose. Подписывается ключом сервиса secret (HS256),
+// а не ключом приложения: такой токен проверяет только сам сервис. payloadHash связывает токен с данными действия,
+// issuedAt - время выпуска (от него считается срок ttl)
+func NewActionToken(userID int64, purpose, payloadHash string, issuedAt time.Time, ttl time.Duration, secret []byte) (string, Claims, error) {
+	now := issuedAt
+
+	claims := Claims{
+		UID:         userID,
+		Purpose:     purpose,
+		PayloadHash: payloadHash,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	return token, claims, nil
+}
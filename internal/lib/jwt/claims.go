@@ -0,0 +1,85 @@
+package jwt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// ErrReservedClaim - дополнительный клейм пытается переопределить стандартный
+var ErrReservedClaim = errors.New("claim is reserved")
+
+// reservedClaims - клеймы, которые выставляет сам сервис; их нельзя задать через Extra
+var reservedClaims = []string{
+	"uid", "email", "app_id", "scope", "sid", "org_id", "org_role", "guest", "offline", "purpose", "payload_hash",
+	"amr", "acr", "auth_time",
+	"iss", "sub", "aud", "exp", "nbf", "iat", "jti",
+}
+
+// checkExtra - проверяет, что дополнительные клеймы не пересекаются с зарезервированными
+func checkExtra(extra map[string]any) error {
+	for _, name := range slices.Sorted(maps.Keys(extra)) {
+		if slices.Contains(reservedClaims, name) {
+			return fmt.Errorf("%w: %q", ErrReservedClaim, name)
+		}
+	}
+
+	return nil
+}
+
+// claimsAlias - Claims без собственных методов JSON (чтобы не уйти в рекурсию)
+type claimsAlias Claims
+
+// MarshalJSON - кладёт Extra на верхний уровень рядом со стандартными клеймами
+func (c Claims) MarshalJSON() ([]byte, error) {
+	base, err := json.Marshal(claimsAlias(c))
+	if err != nil || len(c.Extra) == 0 {
+		return base, err
+	}
+
+	merged := make(map[string]json.RawMessage, len(c.Extra)+8)
+	if err := json.Unmarshal(base, &merged); err != nil {
+		return nil, err
+	}
+
+	for name, value := range c.Extra {
+		if _, ok := merged[name]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrReservedClaim, name)
+		}
+
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("claim %q: %w", name, err)
+		}
+		merged[name] = raw
+	}
+
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON - незнакомые клеймы попадают в Extra
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	var base claimsAlias
+	if err := json.Unmarshal(data, &base); err != nil {
+		return err
+	}
+
+	var all map[string]any
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+
+	for _, name := range reservedClaims {
+		delete(all, name)
+	}
+
+	if len(all) > 0 {
+		base.Extra = all
+	}
+
+	*c = Claims(base)
+
+	return nil
+}
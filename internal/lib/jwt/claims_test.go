@@ -0,0 +1,41 @@
+package jwt
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewToken_ExtraClaims(t *testing.T) {
+	extra := map[string]any{"department": "sales", "locale": "ru-RU", "tenant_id": float64(12)}
+
+	token, err := NewToken(testUser, testApp, time.Hour, WithExtraClaims(extra))
+	require.NoError(t, err)
+
+	claims, err := Parse(token, []byte(testApp.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, testUser.ID, claims.UID)
+	assert.Equal(t, extra, claims.Extra)
+}
+
+func TestNewToken_ReservedClaims(t *testing.T) {
+	for _, name := range []string{"exp", "uid", "app_id", "iss", "aud"} {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewToken(testUser, testApp, time.Hour, WithExtraClaims(map[string]any{name: 1}))
+			require.ErrorIs(t, err, ErrReservedClaim)
+		})
+	}
+}
+
+func TestNewToken_MaxSize(t *testing.T) {
+	extra := map[string]any{"blob": strings.Repeat("x", 1000)}
+
+	_, err := NewToken(testUser, testApp, time.Hour, WithExtraClaims(extra), WithMaxSize(512))
+	require.ErrorIs(t, err, ErrTokenTooLarge)
+
+	_, err = NewToken(testUser, testApp, time.Hour, WithExtraClaims(extra), WithMaxSize(4096))
+	require.NoError(t, err)
+}
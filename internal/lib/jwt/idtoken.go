@@ -0,0 +1,54 @@
+package jwt
+
+import (
+	"sso/internal/domain/models"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// IDTokenClaims - клеймы ID-токена OpenID Connect (Core, 2)
+type IDTokenClaims struct {
+	Nonce     string           `json:"nonce,omitempty"`
+	Email     string           `json:"email,omitempty"`
+	AMR       []string         `json:"amr,omitempty"`
+	ACR       string           `json:"acr,omitempty"`
+	AuthTime  *jwt.NumericDate `json:"auth_time,omitempty"`
+	SessionID string           `json:"sid,omitempty"`
+
+	jwt.RegisteredClaims
+}
+
+// NewIDToken - ID-токен для приложения app о входе, которым выдан токен доступа access: тот же пользователь
+// (sub), способ входа и срок действия. aud - id приложения (client_id), подпись - ключом приложения,
+// issuedAt - время выпуска (клейм iat)
+func NewIDToken(access Claims, app models.App, issuer, nonce string, issuedAt time.Time) (string, error) {
+	claims := IDTokenClaims{
+		Nonce:     nonce,
+		Email:     access.Email,
+		AMR:       access.AMR,
+		ACR:       access.ACR,
+		AuthTime:  access.AuthTime,
+		SessionID: access.SessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    issuer,
+			Subject:   strconv.FormatInt(access.UID, 10),
+			Audience:  jwt.ClaimStrings{strconv.Itoa(app.ID)},
+			ExpiresAt: access.ExpiresAt,
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+		},
+	}
+
+	key, err := keyFor(app)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.method != jwt.SigningMethodHS256 {
+		token.Header["kid"] = keyID(app)
+	}
+
+	return token.SignedString(key.sign)
+}
@@ -0,0 +1,42 @@
+package jwt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewIDToken(t *testing.T) {
+	app := newKeyApp(t, AlgES256)
+	authTime := time.Now().Add(-time.Minute)
+
+	access, claims, err := Issue(testUser, app, time.Hour,
+		WithSession("sid-1"), WithAuthentication([]string{"pwd"}, "aal1", authTime))
+	require.NoError(t, err)
+	require.NotEmpty(t, access)
+
+	idToken, err := NewIDToken(claims, app, "https://sso.example.com", "nonce-1", time.Now())
+	require.NoError(t, err)
+
+	key, err := keyFor(app)
+	require.NoError(t, err)
+
+	var got IDTokenClaims
+	parsed, err := jwt.ParseWithClaims(idToken, &got, func(*jwt.Token) (any, error) { return key.verify, nil },
+		jwt.WithValidMethods([]string{AlgES256}),
+		jwt.WithIssuer("https://sso.example.com"),
+		jwt.WithAudience("7"))
+	require.NoError(t, err)
+	assert.Equal(t, "7", parsed.Header["kid"])
+
+	assert.Equal(t, "42", got.Subject)
+	assert.Equal(t, "nonce-1", got.Nonce)
+	assert.Equal(t, testUser.Email, got.Email)
+	assert.Equal(t, []string{"pwd"}, got.AMR)
+	assert.Equal(t, "sid-1", got.SessionID)
+	assert.Equal(t, claims.ExpiresAt.Unix(), got.ExpiresAt.Unix())
+	assert.Equal(t, authTime.Unix(), got.AuthTime.Unix())
+}
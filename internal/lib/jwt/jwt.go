@@ -1,31 +1,386 @@
 package jwt
 
 import (
+	"errors"
+	"fmt"
 	"sso/internal/domain/models"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-func NewToken(user models.User, app models.App, duration time.Duration) (string, error) {
-	// Создаем новый JWT токен с методом подписи HMAC-SHA256
-	token := jwt.New(jwt.SigningMethodHS256)
+// DefaultLeeway - допустимое расхождение часов между хостами при проверке exp/nbf/iat
+const DefaultLeeway = 30 * time.Second
 
-	// Получаем map-клеймы токена (ключ-значение)
-	claims := token.Claims.(jwt.MapClaims)
+// Ошибки разбора токена
+var (
+	ErrTokenMalformed    = errors.New("token is malformed")         // Не JWT или битый base64/JSON
+	ErrSignatureInvalid  = errors.New("token signature is invalid") // Неверная подпись или неожиданный алгоритм
+	ErrTokenExpired      = errors.New("token is expired")           // Истёк срок действия (exp)
+	ErrTokenNotValidYet  = errors.New("token is not valid yet")     // Ещё не начал действовать (nbf)
+	ErrTokenInvalidClaim = errors.New("token has invalid claims")   // Не хватает обязательных клеймов и т. п.
+)
+
+// Claims - клеймы токенов, которые выдаёт сервис.
+// Стандартные exp, iat, nbf и jti лежат во встроенной RegisteredClaims
+type Claims struct {
+	UID       int64  `json:"uid"`             // ID пользователя
+	Email     string `json:"email"`           // Email пользователя
+	AppID     int    `json:"app_id"`          // ID приложения, для которого выдан токен
+	Scope     string `json:"scope,omitempty"` // Права через пробел
+	SessionID string `json:"sid,omitempty"`   // ID сессии
+
+	OrgID   int64  `json:"org_id,omitempty"`   // Организация, в которую выполнен вход (0 - без организации)
+	OrgRole string `json:"org_role,omitempty"` // Роль в организации на момент выдачи токена
+
+	Guest   bool `json:"guest,omitempty"`   // Гостевой пользователь без email и пароля
+	Offline bool `json:"offline,omitempty"` // Долгоживущий токен фонового агента (см. AsOffline)
+
+	// Как пользователь вошёл (OpenID Connect Core, 2): способы (RFC 8176), уровень и время аутентификации
+	AMR      []string         `json:"amr,omitempty"`
+	ACR      string           `json:"acr,omitempty"`
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"`
+
+	// Токен одного действия (см. NewActionToken): для входа в приложения не годится
+	Purpose     string `json:"purpose,omitempty"`      // Действие, которое разрешает токен
+	PayloadHash string `json:"payload_hash,omitempty"` // SHA-256 данных действия (base64url)
+
+	// Extra - дополнительные клеймы приложения (отдел, локаль и т. п.), лежат на верхнем уровне токена
+	Extra map[string]any `json:"-"`
+
+	jwt.RegisteredClaims
+}
+
+// ErrTokenTooLarge - подписанный токен превышает допустимый размер
+var ErrTokenTooLarge = errors.New("token is too large")
+
+// TokenOption - настройка выпуска токена
+type TokenOption func(o *tokenOptions)
+
+type tokenOptions struct {
+	extra   map[string]any
+	maxSize int
+	orgID   int64
+	orgRole string
+	guest   bool
+	offline bool
+	scope   []string
+	sid     string
+	amr     []string
+	acr     string
+	authAt  time.Time
+	issued  time.Time
+}
+
+// WithExtraClaims - добавляет в токен дополнительные клеймы. Зарезервированные клеймы
+// (uid, exp, app_id, iss, aud и т. п.) переопределить нельзя - NewToken вернёт ErrReservedClaim
+func WithExtraClaims(extra map[string]any) TokenOption {
+	return func(o *tokenOptions) {
+		o.extra = extra
+	}
+}
+
+// WithOrg - вход в организацию orgID с ролью role (клеймы org_id и org_role)
+func WithOrg(orgID int64, role string) TokenOption {
+	return func(o *tokenOptions) {
+		o.orgID = orgID
+		o.orgRole = role
+	}
+}
+
+// AsGuest - токен гостевого пользователя (клейм guest)
+func AsGuest() TokenOption {
+	return func(o *tokenOptions) {
+		o.guest = true
+	}
+}
+
+// AsOffline - offline-токен фонового агента (клейм offline): его принимают только приложения,
+// которые на это согласились
+func AsOffline() TokenOption {
+	return func(o *tokenOptions) {
+		o.offline = true
+	}
+}
+
+// WithScope - права токена (клейм scope через пробел)
+func WithScope(scopes []string) TokenOption {
+	return func(o *tokenOptions) {
+		o.scope = scopes
+	}
+}
+
+// WithSession - токен сессии входа sid (клейм sid): отзыв сессии отзывает и токен
+func WithSession(sid string) TokenOption {
+	return func(o *tokenOptions) {
+		o.sid = sid
+	}
+}
+
+// WithAuthentication - как пользователь вошёл: способы amr, уровень acr и время входа authTime
+// (клеймы amr, acr и auth_time)
+func WithAuthentication(amr []string, acr string, authTime time.Time) TokenOption {
+	return func(o *tokenOptions) {
+		o.amr = amr
+		o.acr = acr
+		o.authAt = authTime
+	}
+}
+
+// WithIssuedAt - время выпуска токена (клейм iat, от него считается exp); по умолчанию time.Now
+func WithIssuedAt(t time.Time) TokenOption {
+	return func(o *tokenOptions) {
+		o.issued = t
+	}
+}
+
+// WithMaxSize - максимальный размер подписанного токена в байтах (0 - без ограничения)
+func WithMaxSize(n int) TokenOption {
+	return func(o *tokenOptions) {
+		o.maxSize = n
+	}
+}
+
+// ParseOption - настройка проверки токена
+type ParseOption func(o *parseOptions)
+
+type parseOptions struct {
+	leeway time.Duration
+	now    func() time.Time
+}
+
+// WithLeeway - допустимое расхождение часов при проверке exp/nbf/iat (по умолчанию DefaultLeeway).
+// Применяется только при проверке: срок действия выдаваемых токенов не меняется
+func WithLeeway(leeway time.Duration) ParseOption {
+	return func(o *parseOptions) {
+		o.leeway = leeway
+	}
+}
+
+// WithClock - источник текущего времени (по умолчанию time.Now); нужен тестам, чтобы не ждать истечения токена
+func WithClock(now func() time.Time) ParseOption {
+	return func(o *parseOptions) {
+		o.now = now
+	}
+}
+
+// NewToken - выпускает подписанный токен пользователя для приложения
+func NewToken(user models.User, app models.App, duration time.Duration, opts ...TokenOption) (string, error) {
+	token, _, err := Issue(user, app, duration, opts...)
+
+	return token, err
+}
+
+// Issue - как NewToken, но возвращает и подписанные клеймы, чтобы метаданные ответа
+// (срок действия, права) брались из тех же значений, что лежат в токене
+func Issue(user models.User, app models.App, duration time.Duration, opts ...TokenOption) (string, Claims, error) {
+	var o tokenOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if err := checkExtra(o.extra); err != nil {
+		return "", Claims{}, err
+	}
+
+	now := o.issued
+	if now.IsZero() {
+		now = time.Now()
+	}
 
 	// Добавляем в токен информацию о пользователе и приложении
-	claims["uid"] = user.ID           // ID пользователя
-	claims["email"] = user.Email      // Email пользователя
-	claims["exp"] = time.Now().Add(duration).Unix() // Время истечения токена (в UNIX формате)
-	claims["app_id"] = app.ID         // ID приложения
+	claims := Claims{
+		UID:   user.ID,    // ID пользователя
+		Email: user.Email, // Email пользователя
+		AppID: app.ID,     // ID приложения
+		Extra: o.extra,
+
+		OrgID:   o.orgID,
+		OrgRole: o.orgRole,
+		Guest:   o.guest,
+		Offline: o.offline,
+		Scope:   strings.Join(o.scope, " "),
+
+		SessionID: o.sid,
+		AMR:       o.amr,
+		ACR:       o.acr,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(duration)), // Время истечения токена (в UNIX формате)
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        uuid.NewString(),
+		},
+	}
+
+	if !o.authAt.IsZero() {
+		claims.AuthTime = jwt.NewNumericDate(o.authAt)
+	}
+
+	// Алгоритм и ключ выбираются по настройкам приложения
+	key, err := keyFor(app)
+	if err != nil {
+		return "", Claims{}, err
+	}
+
+	token := jwt.NewWithClaims(key.method, claims)
+	if key.method != jwt.SigningMethodHS256 {
+		token.Header["kid"] = keyID(app) // по kid проверяющая сторона находит ключ в JWKS
+	}
 
-	// Подписываем токен с использованием секрета приложения
-	tokenString, err := token.SignedString([]byte(app.Secret))
+	// Подписываем токен ключом приложения
+	tokenString, err := token.SignedString(key.sign)
 	if err != nil {
-		return "", err // Возвращаем ошибку, если не удалось подписать токен
+		return "", Claims{}, err // Возвращаем ошибку, если не удалось подписать токен
+	}
+
+	// Большие токены не пролезают в заголовки и cookie у клиентов
+	if o.maxSize > 0 && len(tokenString) > o.maxSize {
+		return "", Claims{}, fmt.Errorf("%w: %d bytes, limit %d", ErrTokenTooLarge, len(tokenString), o.maxSize)
 	}
 
 	// Возвращаем готовый токен
-	return tokenString, nil
+	return tokenString, claims, nil
+}
+
+// Parse - проверяет подпись (HS256) и сроки действия токена и возвращает его клеймы.
+// Сроки действия проверяются с допуском DefaultLeeway (см. WithLeeway, WithClock).
+// Ошибки приводятся к ErrTokenMalformed, ErrSignatureInvalid, ErrTokenExpired,
+// ErrTokenNotValidYet или ErrTokenInvalidClaim
+func Parse(tokenString string, secret []byte, opts ...ParseOption) (Claims, error) {
+	const op = "jwt.Parse"
+
+	claims, err := parse(tokenString, jwt.SigningMethodHS256, secret, opts)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return claims, nil
+}
+
+// ParseForApp - как Parse, но алгоритм и ключ берутся из настроек приложения.
+// Токен, подписанный любым другим алгоритмом, отклоняется
+func ParseForApp(tokenString string, app models.App, opts ...ParseOption) (Claims, error) {
+	const op = "jwt.ParseForApp"
+
+	key, err := keyFor(app)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	claims, err := parse(tokenString, key.method, key.verify, opts)
+	if err != nil {
+		return Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return claims, nil
+}
+
+// InspectForApp - проверяет только подпись токена ключом приложения и возвращает клеймы даже
+// истёкшего токена (для разбора администратором). validity - результат проверки сроков:
+// nil, ErrTokenExpired, ErrTokenNotValidYet или ErrTokenInvalidClaim
+func InspectForApp(tokenString string, app models.App, opts ...ParseOption) (claims Claims, validity error, err error) {
+	const op = "jwt.InspectForApp"
+
+	key, err := keyFor(app)
+	if err != nil {
+		return Claims{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	o := newParseOptions(opts)
+	claims, err = verify(tokenString, key.method, key.verify)
+	if err != nil {
+		return Claims{}, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return claims, claims.validateTimes(o.now(), o.leeway), nil
+}
+
+// UnverifiedAppID - достаёт app_id из токена БЕЗ проверки подписи.
+// Нужен только чтобы выбрать ключ приложения для ParseForApp; доверять значению до проверки нельзя
+func UnverifiedAppID(tokenString string) (int, error) {
+	const op = "jwt.UnverifiedAppID"
+
+	var claims Claims
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, &claims); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, ErrTokenMalformed)
+	}
+
+	return claims.AppID, nil
+}
+
+// parse - проверяет токен строго указанным алгоритмом
+func parse(tokenString string, method jwt.SigningMethod, key any, opts []ParseOption) (Claims, error) {
+	o := newParseOptions(opts)
+
+	claims, err := verify(tokenString, method, key)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if err := claims.validateTimes(o.now(), o.leeway); err != nil {
+		return Claims{}, err
+	}
+
+	return claims, nil
+}
+
+// verify - проверяет подпись строго указанным алгоритмом, без проверки сроков действия
+func verify(tokenString string, method jwt.SigningMethod, key any) (Claims, error) {
+	var claims Claims
+
+	// Сроки действия проверяем сами (validateTimes), чтобы границы leeway были включительными
+	_, err := jwt.ParseWithClaims(tokenString, &claims,
+		func(*jwt.Token) (any, error) { return key, nil },
+		jwt.WithValidMethods([]string{method.Alg()}), // "none" и подмена алгоритма отклоняются здесь
+		jwt.WithoutClaimsValidation(),
+	)
+	if err != nil {
+		return Claims{}, mapError(err)
+	}
+
+	return claims, nil
+}
+
+func newParseOptions(opts []ParseOption) parseOptions {
+	o := parseOptions{leeway: DefaultLeeway, now: time.Now}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return o
+}
+
+// validateTimes - проверяет exp/nbf/iat с учётом leeway. Токен действителен, пока now <= exp + leeway
+func (c Claims) validateTimes(now time.Time, leeway time.Duration) error {
+	if c.ExpiresAt == nil {
+		return fmt.Errorf("%w: exp is required", ErrTokenInvalidClaim)
+	}
+
+	if now.After(c.ExpiresAt.Add(leeway)) {
+		return ErrTokenExpired
+	}
+
+	if c.NotBefore != nil && now.Before(c.NotBefore.Add(-leeway)) {
+		return ErrTokenNotValidYet
+	}
+
+	// Токен, выпущенный "в будущем", означает, что часы выдавшего хоста сильно убежали
+	if c.IssuedAt != nil && now.Before(c.IssuedAt.Add(-leeway)) {
+		return ErrTokenNotValidYet
+	}
+
+	return nil
+}
+
+// mapError - приводит ошибки golang-jwt к ошибкам пакета
+func mapError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrSignatureInvalid
+	default:
+		return fmt.Errorf("%w: %w", ErrTokenInvalidClaim, err)
+	}
 }
@@ -0,0 +1,266 @@
+package jwt
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"sso/internal/domain/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testUser = models.User{ID: 42, Email: "user@example.com"}
+	testApp  = models.App{ID: 1, Name: "test", Secret: "test-secret"}
+)
+
+func TestNewToken_Parse(t *testing.T) {
+	token, err := NewToken(testUser, testApp, time.Hour)
+	require.NoError(t, err)
+
+	claims, err := Parse(token, []byte(testApp.Secret))
+	require.NoError(t, err)
+
+	assert.Equal(t, testUser.ID, claims.UID)
+	assert.Equal(t, testUser.Email, claims.Email)
+	assert.Equal(t, testApp.ID, claims.AppID)
+	assert.NotEmpty(t, claims.ID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), claims.ExpiresAt.Time, time.Second)
+	assert.WithinDuration(t, time.Now(), claims.IssuedAt.Time, time.Second)
+}
+
+func TestNewToken_IssuedAt(t *testing.T) {
+	issued := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	token, err := NewToken(testUser, testApp, time.Hour, WithIssuedAt(issued))
+	require.NoError(t, err)
+
+	// Часы проверки идут от того же момента: токен жив до exp и истекает сразу после
+	at := func(d time.Duration) ParseOption {
+		return WithClock(func() time.Time { return issued.Add(d) })
+	}
+	claims, err := Parse(token, []byte(testApp.Secret), at(time.Hour), WithLeeway(0))
+	require.NoError(t, err)
+	assert.Equal(t, issued, claims.IssuedAt.Time.UTC())
+	assert.Equal(t, issued.Add(time.Hour), claims.ExpiresAt.Time.UTC())
+
+	_, err = Parse(token, []byte(testApp.Secret), at(time.Hour+time.Second), WithLeeway(0))
+	require.ErrorIs(t, err, ErrTokenExpired)
+}
+
+func TestNewToken_Org(t *testing.T) {
+	token, err := NewToken(testUser, testApp, time.Hour, WithOrg(7, models.OrgRoleAdmin))
+	require.NoError(t, err)
+
+	claims, err := Parse(token, []byte(testApp.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), claims.OrgID)
+	assert.Equal(t, models.OrgRoleAdmin, claims.OrgRole)
+	assert.Empty(t, claims.Extra)
+
+	// Дополнительные клеймы не могут подменить организацию
+	_, err = NewToken(testUser, testApp, time.Hour, WithExtraClaims(map[string]any{"org_id": 1}))
+	require.ErrorIs(t, err, ErrReservedClaim)
+}
+
+func TestParse_Errors(t *testing.T) {
+	secret := []byte(testApp.Secret)
+
+	sign := func(method jwt.SigningMethod, key any, claims jwt.Claims) string {
+		s, err := jwt.NewWithClaims(method, claims).SignedString(key)
+		require.NoError(t, err)
+		return s
+	}
+
+	valid := Claims{UID: 1, RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}
+	expired := Claims{UID: 1, RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(-time.Minute)),
+	}}
+	notYet := Claims{UID: 1, RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		NotBefore: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+	}}
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{name: "garbage", token: "not a token", want: ErrTokenMalformed},
+		{name: "empty", token: "", want: ErrTokenMalformed},
+		{name: "wrong secret", token: sign(jwt.SigningMethodHS256, []byte("other"), valid), want: ErrSignatureInvalid},
+		{name: "alg none", token: sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, valid), want: ErrSignatureInvalid},
+		{name: "alg switch", token: sign(jwt.SigningMethodHS512, secret, valid), want: ErrSignatureInvalid},
+		{name: "expired", token: sign(jwt.SigningMethodHS256, secret, expired), want: ErrTokenExpired},
+		{name: "not valid yet", token: sign(jwt.SigningMethodHS256, secret, notYet), want: ErrTokenNotValidYet},
+		{name: "no exp", token: sign(jwt.SigningMethodHS256, secret, Claims{UID: 1}), want: ErrTokenInvalidClaim},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.token, secret)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestParse_Leeway(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(func() time.Time { return now })
+
+	tokenExpiringAt := func(exp time.Time) string {
+		claims := Claims{UID: 1, RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(exp),
+			IssuedAt:  jwt.NewNumericDate(exp.Add(-time.Hour)),
+		}}
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testApp.Secret))
+		require.NoError(t, err)
+		return token
+	}
+
+	tests := []struct {
+		name    string
+		exp     time.Time
+		expired bool
+	}{
+		{name: "expires now", exp: now},
+		{name: "expired leeway ago", exp: now.Add(-DefaultLeeway)},
+		{name: "expired leeway and a second ago", exp: now.Add(-DefaultLeeway - time.Second), expired: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tokenExpiringAt(tt.exp), []byte(testApp.Secret), clock)
+			if tt.expired {
+				require.ErrorIs(t, err, ErrTokenExpired)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+
+	// Без допуска токен, истёкший секунду назад, недействителен
+	_, err := Parse(tokenExpiringAt(now.Add(-time.Second)), []byte(testApp.Secret), clock, WithLeeway(0))
+	require.ErrorIs(t, err, ErrTokenExpired)
+}
+
+func TestParse_IssuedInFuture(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	claims := Claims{UID: 1, RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(now.Add(2 * time.Hour)),
+		IssuedAt:  jwt.NewNumericDate(now.Add(time.Hour)),
+	}}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testApp.Secret))
+	require.NoError(t, err)
+
+	_, err = Parse(token, []byte(testApp.Secret), WithClock(func() time.Time { return now }))
+	require.ErrorIs(t, err, ErrTokenNotValidYet)
+}
+
+// signedHS256 - независимая от библиотеки проверка: в заголовке HS256, а подпись - HMAC-SHA256
+// заголовка и клеймов ключом secret
+func signedHS256(token string, secret []byte) bool {
+	i := strings.LastIndexByte(token, '.')
+	if i < 0 {
+		return false
+	}
+	signingInput, sig := token[:i], token[i+1:]
+
+	rawHeader, _, _ := strings.Cut(signingInput, ".")
+	headerJSON, err := base64.RawURLEncoding.DecodeString(rawHeader)
+	if err != nil {
+		return false
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if json.Unmarshal(headerJSON, &header) != nil || header.Alg != "HS256" {
+		return false
+	}
+
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(signingInput))
+
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// FuzzParseToken - произвольные строки и испорченные действительные токены не роняют разбор,
+// а принимается только токен с настоящей подписью HS256 ключом приложения: подделать подпись
+// (alg=none, другой алгоритм, изменённые клеймы) нельзя. Запуск: go test ./internal/lib/jwt -fuzz=FuzzParseToken
+func FuzzParseToken(f *testing.F) {
+	token, err := NewToken(testUser, testApp, time.Hour)
+	require.NoError(f, err)
+	orgToken, err := NewToken(testUser, testApp, time.Hour, WithOrg(7, models.OrgRoleAdmin))
+	require.NoError(f, err)
+
+	parts := strings.Split(token, ".")
+	header, payload, signature := parts[0], parts[1], parts[2]
+
+	f.Add(token, uint(0), byte(0))
+	f.Add(orgToken, uint(0), byte(0))
+	f.Add("", uint(len(header)+5), byte('A'))
+	f.Add("a.b.c", uint(len(token)-1), byte('x'))
+	f.Add(strings.Repeat(".", 100), uint(len(header)), byte('.'))
+	// alg=none с подписью и без
+	f.Add("eyJhbGciOiJub25lIn0.e30.", uint(0), byte(0))
+	f.Add("eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0."+payload+".", uint(0), byte(0))
+	f.Add("eyJhbGciOiJub25lIiwidHlwIjoiSldUIn0."+payload+"."+signature, uint(0), byte(0))
+	// другой алгоритм с той же подписью и регистр в имени алгоритма
+	f.Add("eyJhbGciOiJIUzUxMiIsInR5cCI6IkpXVCJ9."+payload+"."+signature, uint(0), byte(0))
+	f.Add("eyJhbGciOiJoczI1NiIsInR5cCI6IkpXVCJ9."+payload+"."+signature, uint(0), byte(0))
+	// обрезанный base64, дополнение "=", пробелы
+	f.Add(token[:len(token)-3], uint(0), byte(0))
+	f.Add(header+"."+payload[:len(payload)-1]+"."+signature, uint(0), byte(0))
+	f.Add(header+"=="+"."+payload+"."+signature, uint(0), byte(0))
+	f.Add(" "+token+" ", uint(0), byte(0))
+
+	f.Fuzz(func(t *testing.T, s string, pos uint, b byte) {
+		// строка как есть и действительный токен с заменённым байтом
+		mutated := []byte(token)
+		mutated[pos%uint(len(mutated))] = b
+
+		for _, candidate := range []string{s, string(mutated)} {
+			claims, err := Parse(candidate, []byte(testApp.Secret))
+			if err != nil {
+				assert.Zero(t, claims)
+				continue
+			}
+			assert.True(t, signedHS256(candidate, []byte(testApp.Secret)), "accepted a token we did not sign: %q", candidate)
+
+			_, _ = UnverifiedAppID(candidate)
+		}
+	})
+}
+
+func TestInspectForApp(t *testing.T) {
+	token, err := NewToken(testUser, testApp, time.Hour, WithSession("sid-1"))
+	require.NoError(t, err)
+
+	// Истёкший токен разбирается, а срок действия сообщается отдельно
+	later := WithClock(func() time.Time { return time.Now().Add(2 * time.Hour) })
+	claims, validity, err := InspectForApp(token, testApp, later)
+	require.NoError(t, err)
+	require.ErrorIs(t, validity, ErrTokenExpired)
+	assert.Equal(t, testUser.ID, claims.UID)
+	assert.Equal(t, "sid-1", claims.SessionID)
+
+	_, validity, err = InspectForApp(token, testApp)
+	require.NoError(t, err)
+	require.NoError(t, validity)
+
+	// Подпись проверяется всегда
+	_, _, err = InspectForApp(token, models.App{ID: 1, Secret: "other"})
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+}
@@ -0,0 +1,143 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"strconv"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Алгоритмы подписи, которые можно выбрать для приложения (колонка apps.algorithm)
+const (
+	AlgHS256 = "HS256" // HMAC на секрете приложения (по умолчанию)
+	AlgES256 = "ES256" // ECDSA P-256, ключ в apps.signing_key (PEM)
+	AlgEdDSA = "EdDSA" // Ed25519, ключ в apps.signing_key (PEM)
+)
+
+var (
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrInvalidKey           = errors.New("invalid signing key")
+	ErrNoPublicKey          = errors.New("symmetric algorithm has no public key")
+)
+
+// signingKey - алгоритм и ключи приложения
+type signingKey struct {
+	method jwt.SigningMethod
+	sign   any // ключ для подписи
+	verify any // ключ для проверки
+}
+
+// keyFor - выбирает алгоритм и ключи по настройкам приложения
+func keyFor(app models.App) (signingKey, error) {
+	switch app.Algorithm {
+	case "", AlgHS256:
+		return signingKey{method: jwt.SigningMethodHS256, sign: []byte(app.Secret), verify: []byte(app.Secret)}, nil
+	case AlgES256:
+		key, err := jwt.ParseECPrivateKeyFromPEM([]byte(app.SigningKey))
+		if err != nil {
+			return signingKey{}, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+		}
+		if key.Curve != elliptic.P256() {
+			return signingKey{}, fmt.Errorf("%w: ES256 requires a P-256 key", ErrInvalidKey)
+		}
+
+		return signingKey{method: jwt.SigningMethodES256, sign: key, verify: &key.PublicKey}, nil
+	case AlgEdDSA:
+		key, err := jwt.ParseEdPrivateKeyFromPEM([]byte(app.SigningKey))
+		if err != nil {
+			return signingKey{}, fmt.Errorf("%w: %w", ErrInvalidKey, err)
+		}
+		priv := key.(ed25519.PrivateKey)
+
+		return signingKey{method: jwt.SigningMethodEdDSA, sign: priv, verify: priv.Public()}, nil
+	default:
+		return signingKey{}, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, app.Algorithm)
+	}
+}
+
+// keyID - идентификатор ключа приложения (заголовок kid и поле kid в JWKS)
+func keyID(app models.App) string {
+	return strconv.Itoa(app.ID)
+}
+
+// JWK - открытый ключ в формате RFC 7517
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Kid string `json:"kid"`
+}
+
+// JWKS - набор открытых ключей
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWK - открытый ключ приложения для публикации в JWKS.
+// Для HS256 возвращает ErrNoPublicKey: секрет приложения публиковать нельзя
+func PublicJWK(app models.App) (JWK, error) {
+	const op = "jwt.PublicJWK"
+
+	key, err := keyFor(app)
+	if err != nil {
+		return JWK{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	enc := base64.RawURLEncoding
+	jwk := JWK{Alg: key.method.Alg(), Use: "sig", Kid: keyID(app)}
+
+	switch pub := key.verify.(type) {
+	case *ecdsa.PublicKey:
+		jwk.Kty, jwk.Crv = "EC", "P-256"
+		jwk.X = enc.EncodeToString(pub.X.FillBytes(make([]byte, 32)))
+		jwk.Y = enc.EncodeToString(pub.Y.FillBytes(make([]byte, 32)))
+	case ed25519.PublicKey:
+		jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+		jwk.X = enc.EncodeToString(pub)
+	default:
+		return JWK{}, fmt.Errorf("%s: %w", op, ErrNoPublicKey)
+	}
+
+	return jwk, nil
+}
+
+// GenerateKey - создаёт закрытый ключ для алгоритма ES256 или EdDSA в формате PEM (PKCS#8)
+func GenerateKey(alg string) ([]byte, error) {
+	const op = "jwt.GenerateKey"
+
+	var (
+		key any
+		err error
+	)
+
+	switch alg {
+	case AlgES256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case AlgEdDSA:
+		_, key, err = ed25519.GenerateKey(rand.Reader)
+	default:
+		return nil, fmt.Errorf("%s: %w: %q", op, ErrUnsupportedAlgorithm, alg)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
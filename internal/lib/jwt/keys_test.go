@@ -0,0 +1,104 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"sso/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKeyApp(t *testing.T, alg string) models.App {
+	t.Helper()
+
+	key, err := GenerateKey(alg)
+	require.NoError(t, err)
+
+	return models.App{ID: 7, Name: "partner", Secret: "unused", Algorithm: alg, SigningKey: string(key)}
+}
+
+func TestAsymmetric_RoundTrip(t *testing.T) {
+	for _, alg := range []string{AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			app := newKeyApp(t, alg)
+
+			token, err := NewToken(testUser, app, time.Hour)
+			require.NoError(t, err)
+
+			claims, err := ParseForApp(token, app)
+			require.NoError(t, err)
+			assert.Equal(t, testUser.ID, claims.UID)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, alg, parsed.Header["alg"])
+			assert.Equal(t, "7", parsed.Header["kid"])
+		})
+	}
+}
+
+func TestParseForApp_AlgorithmMustMatch(t *testing.T) {
+	es := newKeyApp(t, AlgES256)
+	ed := newKeyApp(t, AlgEdDSA)
+
+	// Токен EdDSA не принимается приложением с ES256 и наоборот
+	token, err := NewToken(testUser, ed, time.Hour)
+	require.NoError(t, err)
+	_, err = ParseForApp(token, es)
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+
+	// Классическая атака: HS256, подписанный открытым ключом как секретом
+	block, _ := pem.Decode([]byte(es.SigningKey))
+	priv, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	require.NoError(t, err)
+	pubDER, err := x509.MarshalPKIXPublicKey(priv.(crypto.Signer).Public())
+	require.NoError(t, err)
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UID: 1, RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}).SignedString(pubDER)
+	require.NoError(t, err)
+	_, err = ParseForApp(forged, es)
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+
+	// Токен HS256 не принимается асимметричным приложением, даже с верным секретом
+	hs := es
+	hs.Algorithm = AlgHS256
+	token, err = NewToken(testUser, hs, time.Hour)
+	require.NoError(t, err)
+	_, err = ParseForApp(token, es)
+	require.ErrorIs(t, err, ErrSignatureInvalid)
+}
+
+func TestPublicJWK(t *testing.T) {
+	jwk, err := PublicJWK(newKeyApp(t, AlgES256))
+	require.NoError(t, err)
+	assert.Equal(t, "EC", jwk.Kty)
+	assert.Equal(t, "P-256", jwk.Crv)
+	assert.Equal(t, "ES256", jwk.Alg)
+	assert.Len(t, jwk.X, 43)
+	assert.Len(t, jwk.Y, 43)
+
+	jwk, err = PublicJWK(newKeyApp(t, AlgEdDSA))
+	require.NoError(t, err)
+	assert.Equal(t, "OKP", jwk.Kty)
+	assert.Equal(t, "Ed25519", jwk.Crv)
+	assert.Equal(t, "EdDSA", jwk.Alg)
+	assert.Empty(t, jwk.Y)
+
+	_, err = PublicJWK(testApp)
+	require.ErrorIs(t, err, ErrNoPublicKey)
+}
+
+func TestKeyFor_Errors(t *testing.T) {
+	_, err := NewToken(testUser, models.App{Algorithm: "RS256"}, time.Hour)
+	require.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+
+	_, err = NewToken(testUser, models.App{Algorithm: AlgES256, SigningKey: "garbage"}, time.Hour)
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
@@ -0,0 +1,228 @@
+// Package listenfd - слушающие сокеты, унаследованные от родительского процесса.
+//
+// При активации сокетом systemd (LISTEN_FDS) или перезапуске с передачей сокета (--inherit-listener)
+// порт не освобождается на время перезапуска: новые соединения ждут в очереди ядра, пока новый процесс
+// не начнёт их принимать, а старый в это время завершает активные запросы. Без унаследованных сокетов
+// сервер открывает адреса сам.
+package listenfd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// firstFD - номер первого переданного дескриптора (SD_LISTEN_FDS_START)
+const firstFD = 3
+
+// Переменные окружения протокола активации сокетом systemd (sd_listen_fds(3))
+const (
+	envFDs     = "LISTEN_FDS"
+	envPID     = "LISTEN_PID"
+	envFDNames = "LISTEN_FDNAMES"
+)
+
+// Set - унаследованные сокеты, ещё не занятые сервером. Нулевой указатель - пустой набор
+type Set struct {
+	mu        sync.Mutex
+	listeners []named
+}
+
+type named struct {
+	name string // имя из LISTEN_FDNAMES (может быть пустым)
+	l    net.Listener
+}
+
+// New - набор из уже открытых сокетов
+func New(listeners ...net.Listener) *Set {
+	s := &Set{}
+	for _, l := range listeners {
+		s.listeners = append(s.listeners, named{l: l})
+	}
+
+	return s
+}
+
+// FromEnv - сокеты, переданные по протоколу systemd: LISTEN_FDS дескрипторов начиная с 3, если LISTEN_PID
+// не задан или совпадает с текущим процессом. Переменные удаляются, чтобы их не унаследовали дочерние
+// процессы. Без LISTEN_FDS - пустой набор
+func FromEnv() (*Set, error) {
+	const op = "listenfd.FromEnv"
+
+	n, names, err := parseEnv(os.Getenv, os.Getpid())
+	_ = os.Unsetenv(envFDs)
+	_ = os.Unsetenv(envPID)
+	_ = os.Unsetenv(envFDNames)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	fds := make([]int, n)
+	for i := range fds {
+		fds[i] = firstFD + i
+	}
+
+	s, err := fromFDs(fds, names)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// FromFDs - сокеты с явно указанными номерами дескрипторов (флаг --inherit-listener)
+func FromFDs(fds []int) (*Set, error) {
+	const op = "listenfd.FromFDs"
+
+	s, err := fromFDs(fds, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return s, nil
+}
+
+// parseEnv - число сокетов и их имена; сокеты для другого процесса (LISTEN_PID) не наши
+func parseEnv(getenv func(string) string, pid int) (int, []string, error) {
+	fds := getenv(envFDs)
+	if fds == "" {
+		return 0, nil, nil
+	}
+	if p := getenv(envPID); p != "" && p != strconv.Itoa(pid) {
+		return 0, nil, nil
+	}
+
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return 0, nil, fmt.Errorf("invalid %s %q", envFDs, fds)
+	}
+
+	var names []string
+	if v := getenv(envFDNames); v != "" {
+		names = strings.Split(v, ":")
+	}
+
+	return n, names, nil
+}
+
+func fromFDs(fds []int, names []string) (*Set, error) {
+	s := &Set{}
+	for i, fd := range fds {
+		var name string
+		if i < len(names) {
+			name = names[i]
+		}
+
+		l, err := fileListener(fd, name)
+		if err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+		s.listeners = append(s.listeners, named{name: name, l: l})
+	}
+
+	return s, nil
+}
+
+// fileListener - net.Listener поверх дескриптора fd; сам fd закрывается (FileListener работает с копией)
+func fileListener(fd int, name string) (net.Listener, error) {
+	if fd < firstFD {
+		return nil, fmt.Errorf("file descriptor %d: must be %d or greater", fd, firstFD)
+	}
+
+	f := os.NewFile(uintptr(fd), "listener-"+strconv.Itoa(fd))
+	if f == nil {
+		return nil, fmt.Errorf("file descriptor %d: invalid", fd)
+	}
+	defer f.Close()
+
+	l, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("file descriptor %d (%s): not a listening socket: %w", fd, name, err)
+	}
+
+	return l, nil
+}
+
+// Len - сколько сокетов ещё не занято
+func (s *Set) Len() int {
+	if s == nil {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.listeners)
+}
+
+// Take - забирает сокет для адреса addr из конфигурации: с таким именем в LISTEN_FDNAMES или слушающий
+// этот адрес (пустой хост, 0.0.0.0 и :: - любой адрес, имена хостов не разрешаются)
+func (s *Set) Take(addr string) (net.Listener, bool) {
+	if s == nil {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, n := range s.listeners {
+		if n.name == addr || sameAddr(n.l.Addr(), addr) {
+			s.listeners = append(s.listeners[:i], s.listeners[i+1:]...)
+			return n.l, true
+		}
+	}
+
+	return nil, false
+}
+
+// Close - закрывает незанятые сокеты
+func (s *Set) Close() error {
+	if s == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, n := range s.listeners {
+		if err := n.l.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	s.listeners = nil
+
+	return errors.Join(errs...)
+}
+
+func sameAddr(got net.Addr, want string) bool {
+	tcp, ok := got.(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+
+	host, port, err := net.SplitHostPort(want)
+	if err != nil || port != strconv.Itoa(tcp.Port) {
+		return false
+	}
+	if host == "" {
+		return true
+	}
+
+	wantIP, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+	gotIP, ok := netip.AddrFromSlice(tcp.IP)
+	if !ok {
+		return false
+	}
+
+	return wantIP.Unmap() == gotIP.Unmap() || (wantIP.IsUnspecified() && gotIP.IsUnspecified())
+}
@@ -0,0 +1,150 @@
+package listenfd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// inheritedFD - копия дескриптора сокета l, как её получил бы дочерний процесс
+func inheritedFD(t *testing.T, l net.Listener) int {
+	t.Helper()
+
+	f, err := l.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer f.Close()
+
+	fd, err := syscall.Dup(int(f.Fd()))
+	require.NoError(t, err)
+
+	return fd
+}
+
+func TestFromFDs_ServesInheritedSocket(t *testing.T) {
+	parent, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := parent.Addr().String()
+
+	s, err := FromFDs([]int{inheritedFD(t, parent)})
+	require.NoError(t, err)
+	// родитель закрывает свою копию - сокет продолжает принимать соединения
+	require.NoError(t, parent.Close())
+
+	l, ok := s.Take(addr)
+	require.True(t, ok)
+	defer l.Close()
+	assert.Zero(t, s.Len())
+
+	accepted := make(chan error, 1)
+	go func() {
+		c, err := l.Accept()
+		if err == nil {
+			_ = c.Close()
+		}
+		accepted <- err
+	}()
+
+	c, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	_ = c.Close()
+	require.NoError(t, <-accepted)
+}
+
+func TestFromFDs_NotASocket(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "file"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	fd, err := syscall.Dup(int(f.Fd()))
+	require.NoError(t, err)
+
+	_, err = FromFDs([]int{fd})
+	assert.ErrorContains(t, err, "not a listening socket")
+
+	_, err = FromFDs([]int{2})
+	assert.ErrorContains(t, err, "must be 3 or greater")
+}
+
+func TestParseEnv(t *testing.T) {
+	const pid = 42
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		n       int
+		names   []string
+		wantErr bool
+	}{
+		{name: "not set"},
+		{name: "fds", env: map[string]string{"LISTEN_FDS": "2"}, n: 2},
+		{name: "our pid", env: map[string]string{"LISTEN_FDS": "1", "LISTEN_PID": "42"}, n: 1},
+		{name: "other pid", env: map[string]string{"LISTEN_FDS": "1", "LISTEN_PID": "7"}},
+		{
+			name:  "names",
+			env:   map[string]string{"LISTEN_FDS": "2", "LISTEN_FDNAMES": "public:admin"},
+			n:     2,
+			names: []string{"public", "admin"},
+		},
+		{name: "invalid", env: map[string]string{"LISTEN_FDS": "x"}, wantErr: true},
+		{name: "negative", env: map[string]string{"LISTEN_FDS": "-1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, names, err := parseEnv(func(k string) string { return tt.env[k] }, pid)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.n, n)
+			assert.Equal(t, tt.names, names)
+		})
+	}
+}
+
+func TestTake_MatchesConfiguredAddr(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	port := strconv.Itoa(l.Addr().(*net.TCPAddr).Port)
+
+	s := New(l)
+	for _, addr := range []string{"127.0.0.1:1", "0.0.0.0:" + port, "[::1]:" + port, "localhost:" + port} {
+		_, ok := s.Take(addr)
+		assert.False(t, ok, addr)
+	}
+
+	got, ok := s.Take(":" + port)
+	require.True(t, ok)
+	assert.Equal(t, l, got)
+
+	_, ok = s.Take(":" + port)
+	assert.False(t, ok, "a socket is taken only once")
+}
+
+func TestTake_ByName(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	s := &Set{listeners: []named{{name: "localhost:44045", l: l}}}
+	got, ok := s.Take("localhost:44045")
+	require.True(t, ok)
+	assert.Equal(t, l, got)
+}
+
+func TestNilSet(t *testing.T) {
+	var s *Set
+
+	_, ok := s.Take(":44044")
+	assert.False(t, ok)
+	assert.Zero(t, s.Len())
+	assert.NoError(t, s.Close())
+}
@@ -0,0 +1,30 @@
+package logctx
+
+import (
+	"context"
+	"log/slog"
+)
+
+// ctxKey - приватный тип ключа, чтобы не пересекаться с другими пакетами
+type ctxKey struct{}
+
+// Into - кладёт логгер запроса (с request id, методом, адресом клиента и т. д.) в контекст
+func Into(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, log)
+}
+
+// From - достаёт логгер запроса из контекста; возвращает nil, если его там нет
+func From(ctx context.Context) *slog.Logger {
+	log, _ := ctx.Value(ctxKey{}).(*slog.Logger)
+
+	return log
+}
+
+// FromOr - достаёт логгер запроса из контекста или возвращает fallback
+func FromOr(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if log := From(ctx); log != nil {
+		return log
+	}
+
+	return fallback
+}
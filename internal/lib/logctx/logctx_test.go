@@ -0,0 +1,25 @@
+package logctx
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIntoFrom(t *testing.T) {
+	var buf bytes.Buffer
+	fallback := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+	reqLog := slog.New(slog.NewTextHandler(&buf, nil)).With(slog.String("request_id", "req-1"))
+
+	assert.Nil(t, From(context.Background()))
+	assert.Same(t, fallback, FromOr(context.Background(), fallback))
+
+	ctx := Into(context.Background(), reqLog)
+	assert.Same(t, reqLog, From(ctx))
+
+	FromOr(ctx, fallback).Info("hello")
+	assert.Contains(t, buf.String(), "request_id=req-1")
+}
@@ -0,0 +1,72 @@
+package logger
+
+import (
+	"io"
+	"log/slog"
+	"os"
+	"sso/internal/config"
+)
+
+// Форматы вывода логов
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Setup - создаёт логгер по настройкам log.level/log.format.
+// Если они не заданы, используются значения по умолчанию для окружения:
+// local - text/debug, dev - json/debug, prod - json/info.
+// Для неизвестного окружения используется безопасный вариант json/info, а не nil-логгер.
+// Уровень хранится в LevelVar, чтобы его можно было менять при перезагрузке конфигурации
+func Setup(w io.Writer, cfg config.LogConfig, env string) (*slog.Logger, *slog.LevelVar) {
+	l, err := config.ParseLevel(cfg.Level, env)
+	if err != nil {
+		l = slog.LevelInfo // Неизвестный уровень отсекает Validate, здесь просто не падаем
+	}
+
+	level := new(slog.LevelVar)
+	level.Set(l)
+
+	format := cfg.Format
+	if format == "" {
+		format = defaultFormat(env)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	var handler slog.Handler
+	switch format {
+	case FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	default:
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(handler), level
+}
+
+// Output - куда писать лог приложения по настройкам log.output: stdout или файл с ротацией.
+// Файл переоткрывается по ReopenAll вместе с журналом безопасности
+func Output(cfg config.LogConfig) (io.WriteCloser, error) {
+	if cfg.Output != config.LogOutputFile {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	return OpenRotatingFile(cfg.Path, int64(cfg.MaxSizeMB)<<20, cfg.MaxAge, cfg.MaxBackups)
+}
+
+// nopCloser - stdout не закрывается вместе с логгером
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// defaultFormat - формат логов по умолчанию для окружения
+func defaultFormat(env string) string {
+	if env == config.EnvLocal {
+		return FormatText
+	}
+
+	return FormatJSON
+}
@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sso/internal/config"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetup(t *testing.T) {
+	envs := []string{config.EnvLocal, config.EnvDev, config.EnvProd, "staging"}
+	levels := []string{"", "debug", "info", "warn", "error"}
+	formats := []string{"", FormatText, FormatJSON}
+
+	wantDefaultLevel := map[string]slog.Level{
+		config.EnvLocal: slog.LevelDebug,
+		config.EnvDev:   slog.LevelDebug,
+		config.EnvProd:  slog.LevelInfo,
+		"staging":       slog.LevelInfo,
+	}
+	explicitLevel := map[string]slog.Level{
+		"debug": slog.LevelDebug,
+		"info":  slog.LevelInfo,
+		"warn":  slog.LevelWarn,
+		"error": slog.LevelError,
+	}
+
+	for _, env := range envs {
+		for _, level := range levels {
+			for _, format := range formats {
+				name := env + "/" + level + "/" + format
+				t.Run(name, func(t *testing.T) {
+					var buf bytes.Buffer
+
+					log, lv := Setup(&buf, config.LogConfig{Level: level, Format: format}, env)
+					require.NotNil(t, log)
+
+					want := wantDefaultLevel[env]
+					if level != "" {
+						want = explicitLevel[level]
+					}
+					assert.Equal(t, want, lv.Level())
+					assert.True(t, log.Enabled(context.Background(), want))
+					assert.False(t, log.Enabled(context.Background(), want-1))
+
+					log.Log(context.Background(), want, "hello")
+
+					wantJSON := format == FormatJSON || (format == "" && env != config.EnvLocal)
+					assert.Equal(t, wantJSON, json.Valid(bytes.TrimSpace(buf.Bytes())), buf.String())
+				})
+			}
+		}
+	}
+}
+
+func TestSetup_LevelVarAdjustable(t *testing.T) {
+	var buf bytes.Buffer
+
+	log, lv := Setup(&buf, config.LogConfig{Level: "error"}, config.EnvProd)
+	log.Info("dropped")
+	require.Zero(t, buf.Len())
+
+	lv.Set(slog.LevelInfo)
+	log.Info("kept")
+	assert.Contains(t, buf.String(), "kept")
+}
@@ -0,0 +1,178 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// open - все открытые RotatingFile: ReopenAll переоткрывает их по SIGUSR2
+var (
+	openMu sync.Mutex
+	open   = map[*RotatingFile]struct{}{}
+)
+
+// RotatingFile - файл лога с ротацией по размеру и возрасту.
+// При превышении maxSize или maxAge текущий файл переименовывается в path.1, path.1 - в path.2 и т. д.;
+// хранится не больше maxBackups старых файлов. Безопасен для конкурентной записи
+type RotatingFile struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	file       *os.File
+	size       int64
+	started    time.Time // когда в текущий файл начали писать (для существующего файла - время его изменения)
+}
+
+// OpenRotatingFile - открывает (или создаёт) файл лога с ротацией. maxSize 0 - без ротации по размеру,
+// maxAge 0 - без ротации по возрасту
+func OpenRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{path: path, maxSize: maxSize, maxAge: maxAge, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	openMu.Lock()
+	open[f] = struct{}{}
+	openMu.Unlock()
+
+	return f, nil
+}
+
+// Write - реализует io.Writer; перед записью, которая переполнит файл, или в файл старше maxAge
+// выполняет ротацию
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	if f.size > 0 && (f.tooBig(len(p)) || f.tooOld()) {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, err
+}
+
+func (f *RotatingFile) tooBig(next int) bool {
+	return f.maxSize > 0 && f.size+int64(next) > f.maxSize
+}
+
+func (f *RotatingFile) tooOld() bool {
+	return f.maxAge > 0 && time.Since(f.started) >= f.maxAge
+}
+
+// Reopen - закрывает файл и открывает path заново. Нужен после внешней ротации (logrotate без
+// copytruncate): иначе запись продолжится в переименованный файл
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return os.ErrClosed
+	}
+
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close log file %s: %w", f.path, err)
+	}
+
+	return f.open()
+}
+
+// Close - закрывает файл
+func (f *RotatingFile) Close() error {
+	openMu.Lock()
+	delete(open, f)
+	openMu.Unlock()
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	err := f.file.Close()
+	f.file = nil
+
+	return err
+}
+
+// ReopenAll - переоткрывает все открытые файлы логов (SIGUSR2 после logrotate)
+func ReopenAll() error {
+	openMu.Lock()
+	files := make([]*RotatingFile, 0, len(open))
+	for f := range open {
+		files = append(files, f)
+	}
+	openMu.Unlock()
+
+	var errs []error
+	for _, f := range files {
+		if err := f.Reopen(); err != nil && !errors.Is(err, os.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// open - открывает файл на дозапись и запоминает его текущий размер
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return fmt.Errorf("open log file %s: %w", f.path, err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat log file %s: %w", f.path, err)
+	}
+
+	f.file = file
+	f.size = info.Size()
+	f.started = time.Now()
+	if f.size > 0 {
+		f.started = info.ModTime()
+	}
+
+	return nil
+}
+
+// rotate - сдвигает старые файлы и открывает новый
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close log file %s: %w", f.path, err)
+	}
+
+	if f.maxBackups <= 0 {
+		_ = os.Remove(f.path)
+	} else {
+		_ = os.Remove(backupName(f.path, f.maxBackups))
+		for i := f.maxBackups - 1; i >= 1; i-- {
+			_ = os.Rename(backupName(f.path, i), backupName(f.path, i+1))
+		}
+		if err := os.Rename(f.path, backupName(f.path, 1)); err != nil {
+			return fmt.Errorf("rotate log file %s: %w", f.path, err)
+		}
+	}
+
+	return f.open()
+}
+
+// backupName - имя i-го старого файла
+func backupName(path string, i int) string {
+	return fmt.Sprintf("%s.%d", path, i)
+}
@@ -0,0 +1,136 @@
+package logger
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile_RotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "security.log")
+
+	f, err := OpenRotatingFile(path, 10, 0, 2)
+	require.NoError(t, err)
+
+	for _, rec := range []string{"first---\n", "second--\n", "third---\n", "fourth--\n"} {
+		_, err := f.Write([]byte(rec))
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.Close())
+
+	read := func(name string) string {
+		data, err := os.ReadFile(name)
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	assert.Equal(t, "fourth--\n", read(path))
+	assert.Equal(t, "third---\n", read(path+".1"))
+	assert.Equal(t, "second--\n", read(path+".2"))
+	assert.NoFileExists(t, path+".3", "only maxBackups old files are kept")
+}
+
+// Конкурентная запись с ротацией: ни одна строка не теряется и не разрывается
+func TestRotatingFile_ConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sso.log")
+
+	const writers, lines = 8, 200
+	f, err := OpenRotatingFile(path, 4<<10, 0, 100)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range lines {
+				_, err := fmt.Fprintf(f, "writer=%d line=%03d\n", w, i)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+	require.NoError(t, f.Close())
+
+	files, err := filepath.Glob(path + "*")
+	require.NoError(t, err)
+	assert.Greater(t, len(files), 2, "writes past max size must rotate")
+
+	total := 0
+	for _, name := range files {
+		info, err := os.Stat(name)
+		require.NoError(t, err)
+		assert.LessOrEqual(t, info.Size(), int64(4<<10), name)
+
+		file, err := os.Open(name)
+		require.NoError(t, err)
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			assert.Regexp(t, `^writer=\d line=\d{3}$`, scanner.Text())
+			total++
+		}
+		require.NoError(t, file.Close())
+	}
+	assert.Equal(t, writers*lines, total)
+}
+
+func TestRotatingFile_RotatesByAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sso.log")
+
+	f, err := OpenRotatingFile(path, 0, time.Hour, 1)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	_, err = f.Write([]byte("old\n"))
+	require.NoError(t, err)
+
+	f.mu.Lock()
+	f.started = time.Now().Add(-2 * time.Hour)
+	f.mu.Unlock()
+
+	_, err = f.Write([]byte("new\n"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new\n", string(data))
+	data, err = os.ReadFile(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, "old\n", string(data))
+}
+
+// logrotate переименовывает файл и шлёт SIGUSR2: запись продолжается в новый файл по тому же пути
+func TestReopenAll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sso.log")
+
+	f, err := OpenRotatingFile(path, 0, 0, 0)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = f.Close() })
+
+	_, err = f.Write([]byte("before\n"))
+	require.NoError(t, err)
+	require.NoError(t, os.Rename(path, path+"-20261015"))
+
+	require.NoError(t, ReopenAll())
+	_, err = f.Write([]byte("after\n"))
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "after\n", string(data))
+	data, err = os.ReadFile(path + "-20261015")
+	require.NoError(t, err)
+	assert.Equal(t, "before\n", string(data))
+
+	// Закрытый файл больше не переоткрывается
+	require.NoError(t, f.Close())
+	require.NoError(t, ReopenAll())
+	assert.NotContains(t, open, f)
+}
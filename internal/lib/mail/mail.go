@@ -0,0 +1,94 @@
+// Package mail - отправка писем (подтверждение email, сброс пароля, коды входа).
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/config"
+	"strings"
+)
+
+// Mailer - отправляет одно письмо; textBody и htmlBody - две версии одного содержимого
+type Mailer interface {
+	Send(ctx context.Context, to, subject, textBody, htmlBody string) error
+}
+
+// ErrInvalidHeader - адрес или тема содержат перевод строки (попытка внедрить заголовки)
+var ErrInvalidHeader = errors.New("invalid mail header value")
+
+// New - создаёт отправителя по настройкам mail.* (без очереди повторов)
+func New(cfg config.MailConfig, log *slog.Logger) (Mailer, error) {
+	switch cfg.Driver {
+	case config.MailDriverSMTP:
+		return NewSMTP(cfg.SMTP, cfg.From), nil
+	case "", config.MailDriverLog:
+		return NewLog(log), nil
+	default:
+		return nil, fmt.Errorf("mail.New: unknown driver %q", cfg.Driver)
+	}
+}
+
+// Log - отправитель для локального окружения: пишет письма в лог вместо отправки
+type Log struct {
+	log *slog.Logger
+}
+
+// NewLog - создаёт отправитель, пишущий письма в лог
+func NewLog(log *slog.Logger) *Log {
+	return &Log{log: log}
+}
+
+// Send - пишет письмо в лог (текстовую версию целиком, чтобы из неё можно было взять ссылку или код)
+func (l *Log) Send(ctx context.Context, to, subject, textBody, _ string) error {
+	if err := checkHeader(to, subject); err != nil {
+		return fmt.Errorf("mail.Log.Send: %w", err)
+	}
+
+	l.log.InfoContext(ctx, "email (not sent, log driver)",
+		slog.String("to", to),
+		slog.String("subject", subject),
+		slog.String("body", textBody),
+	)
+
+	return nil
+}
+
+// checkHeader - значения заголовков не должны содержать переводов строки
+func checkHeader(values ...string) error {
+	for _, v := range values {
+		if strings.ContainsAny(v, "\r\n") {
+			return ErrInvalidHeader
+		}
+	}
+
+	return nil
+}
+
+// Sender - отправка писем по шаблонам
+type Sender struct {
+	mailer    Mailer
+	templates *Templates
+}
+
+// NewSender - отрисовывает письма шаблонами templates и отправляет через mailer
+func NewSender(mailer Mailer, templates *Templates) *Sender {
+	return &Sender{mailer: mailer, templates: templates}
+}
+
+// SendTemplate - отрисовывает письмо name и отправляет его на адрес to
+func (s *Sender) SendTemplate(ctx context.Context, to, name string, data Data) error {
+	const op = "mail.Sender.SendTemplate"
+
+	msg, err := s.templates.Render(name, data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.mailer.Send(ctx, to, msg.Subject, msg.Text, msg.HTML); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Метрики отправки писем (видны в /debug/vars)
+var (
+	mailSent    = expvar.NewInt("mail_sent")    // доставлено
+	mailFailed  = expvar.NewInt("mail_failed")  // не доставлено после всех попыток
+	mailRetries = expvar.NewInt("mail_retries") // повторных попыток
+	mailDropped = expvar.NewInt("mail_dropped") // отброшено из-за переполненной очереди
+)
+
+// ErrQueueFull - очередь писем переполнена, письмо не будет отправлено
+var ErrQueueFull = errors.New("mail queue is full")
+
+// ErrQueueClosed - очередь уже остановлена
+var ErrQueueClosed = errors.New("mail queue is closed")
+
+// job - письмо в очереди
+type job struct {
+	to, subject, text, html string
+}
+
+// Queue - асинхронная отправка с ограниченным числом повторов.
+// Send только ставит письмо в очередь и сразу возвращается, поэтому сбой почтового сервера
+// не задерживает и не ломает запрос пользователя; результат виден в логах и метриках
+type Queue struct {
+	next        Mailer
+	log         *slog.Logger
+	maxAttempts int
+	backoff     time.Duration
+
+	jobs chan job
+	quit chan struct{} // закрывается при остановке: ожидание перед повтором прерывается
+	wg   sync.WaitGroup
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewQueue - запускает фоновую отправку через next. Между попытками ждёт backoff, 2*backoff, 4*backoff...
+func NewQueue(next Mailer, log *slog.Logger, size, maxAttempts int, backoff time.Duration) *Queue {
+	q := &Queue{
+		next:        next,
+		log:         log.With(slog.String("component", "mail queue")),
+		maxAttempts: max(maxAttempts, 1),
+		backoff:     backoff,
+		jobs:        make(chan job, max(size, 1)),
+		quit:        make(chan struct{}),
+	}
+
+	q.wg.Add(1)
+	go q.loop()
+
+	return q
+}
+
+// Send - ставит письмо в очередь; ошибка только если очередь переполнена или остановлена
+func (q *Queue) Send(_ context.Context, to, subject, textBody, htmlBody string) error {
+	if err := checkHeader(to, subject); err != nil {
+		return err
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.jobs <- job{to: to, subject: subject, text: textBody, html: htmlBody}:
+		return nil
+	default:
+		mailDropped.Add(1)
+
+		return ErrQueueFull
+	}
+}
+
+// Close - перестаёт принимать письма, отправляет уже поставленные (без ожидания между повторами)
+// и дожидается фоновой горутины
+func (q *Queue) Close() error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.quit)
+		close(q.jobs)
+	}
+	q.mu.Unlock()
+
+	q.wg.Wait()
+
+	return nil
+}
+
+func (q *Queue) loop() {
+	defer q.wg.Done()
+
+	for j := range q.jobs {
+		q.deliver(j)
+	}
+}
+
+// deliver - отправляет письмо, повторяя до maxAttempts раз
+func (q *Queue) deliver(j job) {
+	delay := q.backoff
+
+	for attempt := 1; ; attempt++ {
+		err := q.next.Send(context.Background(), j.to, j.subject, j.text, j.html)
+		if err == nil {
+			mailSent.Add(1)
+
+			return
+		}
+
+		if attempt >= q.maxAttempts || errors.Is(err, ErrInvalidHeader) {
+			mailFailed.Add(1)
+			q.log.Error("failed to send email",
+				slog.String("to", j.to),
+				slog.String("subject", j.subject),
+				slog.Int("attempts", attempt),
+				slog.String("error", err.Error()),
+			)
+
+			return
+		}
+
+		mailRetries.Add(1)
+		q.log.Warn("failed to send email, will retry",
+			slog.String("to", j.to),
+			slog.Int("attempt", attempt),
+			slog.Duration("retry_in", delay),
+			slog.String("error", err.Error()),
+		)
+
+		select {
+		case <-time.After(delay):
+		case <-q.quit:
+		}
+		delay *= 2
+	}
+}
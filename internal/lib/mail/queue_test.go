@@ -0,0 +1,77 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyMailer - падает на первых failures отправках
+type flakyMailer struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	sent     []string
+}
+
+func (m *flakyMailer) Send(_ context.Context, to, _, _, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.attempts++
+	if m.failures > 0 {
+		m.failures--
+
+		return errors.New("smtp unavailable")
+	}
+	m.sent = append(m.sent, to)
+
+	return nil
+}
+
+func TestQueue_RetriesUntilDelivered(t *testing.T) {
+	m := &flakyMailer{failures: 2}
+	q := NewQueue(m, slog.New(slog.NewTextHandler(io.Discard, nil)), 10, 5, time.Millisecond)
+
+	sent, retries := mailSent.Value(), mailRetries.Value()
+
+	require.NoError(t, q.Send(context.Background(), "a@b.c", "subject", "text", "<p>html</p>"))
+	require.NoError(t, q.Close())
+
+	assert.Equal(t, []string{"a@b.c"}, m.sent)
+	assert.Equal(t, 3, m.attempts)
+	assert.Equal(t, sent+1, mailSent.Value())
+	assert.Equal(t, retries+2, mailRetries.Value())
+}
+
+func TestQueue_BoundedAttempts(t *testing.T) {
+	m := &flakyMailer{failures: 100}
+	q := NewQueue(m, slog.New(slog.NewTextHandler(io.Discard, nil)), 10, 3, time.Hour)
+
+	failed := mailFailed.Value()
+
+	require.NoError(t, q.Send(context.Background(), "a@b.c", "subject", "text", ""))
+	// Close прерывает ожидание между повторами, но не число попыток
+	require.NoError(t, q.Close())
+
+	assert.Equal(t, 3, m.attempts)
+	assert.Empty(t, m.sent)
+	assert.Equal(t, failed+1, mailFailed.Value())
+
+	require.ErrorIs(t, q.Send(context.Background(), "a@b.c", "subject", "text", ""), ErrQueueClosed)
+}
+
+func TestQueue_RejectsHeaderInjection(t *testing.T) {
+	q := NewQueue(&flakyMailer{}, slog.New(slog.NewTextHandler(io.Discard, nil)), 10, 1, 0)
+	t.Cleanup(func() { _ = q.Close() })
+
+	err := q.Send(context.Background(), "a@b.c\r\nBcc: victim@example.com", "subject", "text", "")
+	require.ErrorIs(t, err, ErrInvalidHeader)
+}
@@ -0,0 +1,179 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"sso/internal/config"
+	"strconv"
+	"time"
+)
+
+// SMTP - отправка писем через SMTP-сервер
+type SMTP struct {
+	addr     string
+	host     string
+	tlsMode  string
+	username string
+	password config.Secret
+	from     string
+	timeout  time.Duration
+}
+
+// NewSMTP - создаёт отправителя по настройкам mail.smtp.*
+func NewSMTP(cfg config.SMTPConfig, from string) *SMTP {
+	return &SMTP{
+		addr:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		host:     cfg.Host,
+		tlsMode:  cfg.TLS,
+		username: cfg.Username,
+		password: cfg.Password,
+		from:     from,
+		timeout:  cfg.Timeout,
+	}
+}
+
+// Send - отправляет письмо одной SMTP-сессией
+func (s *SMTP) Send(ctx context.Context, to, subject, textBody, htmlBody string) error {
+	const op = "mail.SMTP.Send"
+
+	if err := checkHeader(to, subject); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	msg, err := s.buildMessage(to, subject, textBody, htmlBody)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	c, err := s.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer c.Close()
+
+	if err := s.deliver(c, to, msg); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// dial - подключается к серверу; при implicit TLS соединение шифруется сразу, при starttls - после EHLO
+func (s *SMTP) dial(ctx context.Context) (*smtp.Client, error) {
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Таймаут на всю сессию, а не только на подключение
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if s.tlsMode == config.SMTPTLSImplicit {
+		conn = tls.Client(conn, &tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12})
+	}
+
+	c, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		_ = conn.Close()
+
+		return nil, err
+	}
+
+	if s.tlsMode == config.SMTPTLSStartTLS {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host, MinVersion: tls.VersionTLS12}); err != nil {
+			_ = c.Close()
+
+			return nil, fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	return c, nil
+}
+
+// deliver - AUTH (если задан логин), MAIL, RCPT, DATA, QUIT
+func (s *SMTP) deliver(c *smtp.Client, to string, msg []byte) error {
+	if s.username != "" {
+		// PlainAuth сам откажется отправлять пароль по незашифрованному соединению (кроме localhost)
+		if err := c.Auth(smtp.PlainAuth("", s.username, s.password.Reveal(), s.host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(s.from); err != nil {
+		return fmt.Errorf("mail from: %w", err)
+	}
+	if err := c.Rcpt(to); err != nil {
+		return fmt.Errorf("rcpt to: %w", err)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := w.Write(msg); err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+
+	return c.Quit()
+}
+
+// buildMessage - письмо multipart/alternative с текстовой и HTML-версиями
+func (s *SMTP) buildMessage(to, subject, textBody, htmlBody string) ([]byte, error) {
+	var body bytes.Buffer
+
+	mw := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{contentType: "text/plain; charset=utf-8", content: textBody},
+		{contentType: "text/html; charset=utf-8", content: htmlBody},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	var msg bytes.Buffer
+
+	fmt.Fprintf(&msg, "From: %s\r\n", (&mail.Address{Address: s.from}).String())
+	fmt.Fprintf(&msg, "To: %s\r\n", (&mail.Address{Address: to}).String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+
+	return msg.Bytes(), nil
+}
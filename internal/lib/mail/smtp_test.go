@@ -0,0 +1,167 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"sso/internal/config"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSession - то, что фейковый SMTP-сервер получил за сессию
+type smtpSession struct {
+	auth     string // расшифрованный AUTH PLAIN
+	from, to string
+	data     string
+}
+
+// fakeSMTP - минимальный SMTP-сервер без TLS на одну сессию
+func fakeSMTP(t *testing.T) (port int, session <-chan smtpSession) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	ch := make(chan smtpSession, 1)
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { _, _ = io.WriteString(conn, s+"\r\n") }
+
+		var s smtpSession
+		reply("220 localhost ESMTP")
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			cmd := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+			switch cmd {
+			case "EHLO", "HELO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				raw, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(line, "AUTH PLAIN "))
+				s.auth = string(raw)
+				reply("235 OK")
+			case "MAIL":
+				s.from = line
+				reply("250 OK")
+			case "RCPT":
+				s.to = line
+				reply("250 OK")
+			case "DATA":
+				reply("354 go ahead")
+
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				s.data = data.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				ch <- s
+
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().(*net.TCPAddr).Port, ch
+}
+
+func TestSMTP_Send(t *testing.T) {
+	port, sessions := fakeSMTP(t)
+
+	s := NewSMTP(config.SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		TLS:      config.SMTPTLSNone,
+		Username: "sso",
+		Password: "secret",
+		Timeout:  5 * time.Second,
+	}, "no-reply@example.com")
+
+	err := s.Send(context.Background(), "user@example.com", "Код для входа: 123456", "Ваш код: 123456", "<b>123456</b>")
+	require.NoError(t, err)
+
+	var got smtpSession
+	select {
+	case got = <-sessions:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no SMTP session")
+	}
+
+	assert.Equal(t, "\x00sso\x00secret", got.auth)
+	assert.Equal(t, "MAIL FROM:<no-reply@example.com>", strings.SplitN(got.from, " BODY", 2)[0])
+	assert.Equal(t, "RCPT TO:<user@example.com>", got.to)
+
+	msg, err := mail.ReadMessage(strings.NewReader(got.data))
+	require.NoError(t, err)
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "Код для входа: 123456", subject)
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	require.Equal(t, "multipart/alternative", mediaType)
+
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	var bodies []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+
+		b, err := io.ReadAll(part) // NextPart сам декодирует quoted-printable
+		require.NoError(t, err)
+		bodies = append(bodies, part.Header.Get("Content-Type")+": "+string(b))
+	}
+
+	assert.Equal(t, []string{
+		"text/plain; charset=utf-8: Ваш код: 123456",
+		"text/html; charset=utf-8: <b>123456</b>",
+	}, bodies)
+}
+
+func TestSMTP_ConnectionRefused(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := ln.Addr().(*net.TCPAddr).Port
+	require.NoError(t, ln.Close())
+
+	s := NewSMTP(config.SMTPConfig{Host: "127.0.0.1", Port: port, TLS: config.SMTPTLSNone, Timeout: time.Second}, "a@b.c")
+
+	require.Error(t, s.Send(context.Background(), "user@example.com", "s", "t", "h"))
+	require.ErrorIs(t, s.Send(context.Background(), "user@example.com\nBcc: x@y.z", "s", "t", "h"), ErrInvalidHeader)
+}
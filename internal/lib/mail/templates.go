@@ -0,0 +1,147 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// Имена шаблонов писем
+const (
+	TemplateVerification  = "verification"
+	TemplatePasswordReset = "password_reset"
+	TemplateLoginCode     = "login_code"
+	TemplateInvitation    = "invitation"
+	TemplateLoginAlert    = "login_alert"
+	TemplateDeletion      = "account_deletion"
+)
+
+//go:embed templates/*.tmpl
+var embedded embed.FS
+
+// Data - данные для шаблонов писем
+type Data struct {
+	Email     string    // Адрес получателя
+	Link      string    // Ссылка подтверждения, сброса пароля, приглашения или отмены удаления аккаунта
+	Code      string    // Одноразовый код для входа (или код приглашения, если ссылки нет)
+	ExpiresAt time.Time // До какого времени действительны ссылка или код (для удаления аккаунта - когда он будет удалён)
+
+	// Уведомление о входе
+	LoggedInAt time.Time // Время входа
+	Location   string    // Примерное место входа (код страны); пусто, если не определено
+	Device     string    // User-Agent устройства; пусто, если клиент его не прислал
+}
+
+// Message - отрисованное письмо
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Templates - шаблоны писем. Каждое письмо - пара файлов <name>.txt.tmpl (тема в блоке "subject"
+// и текстовая версия) и <name>.html.tmpl (HTML-версия). Файлы из каталога переопределений
+// заменяют встроенные по одному, остальные берутся из бинаря
+type Templates struct {
+	text map[string]*texttemplate.Template
+	html map[string]*htmltemplate.Template
+}
+
+// LoadTemplates - загружает встроенные шаблоны с переопределениями из dir (пусто - без переопределений).
+// Ошибка в любом шаблоне возвращается сразу, чтобы не узнать о ней при первой отправке
+func LoadTemplates(dir string) (*Templates, error) {
+	const op = "mail.LoadTemplates"
+
+	t := &Templates{
+		text: make(map[string]*texttemplate.Template),
+		html: make(map[string]*htmltemplate.Template),
+	}
+
+	for _, name := range []string{TemplateVerification, TemplatePasswordReset, TemplateLoginCode, TemplateInvitation, TemplateLoginAlert,
+		TemplateDeletion} {
+		src, err := readTemplate(dir, name+".txt.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		text, err := texttemplate.New(name).Option("missingkey=error").Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, name, err)
+		}
+		if text.Lookup("subject") == nil {
+			return nil, fmt.Errorf("%s: %s.txt.tmpl: missing {{define \"subject\"}} block", op, name)
+		}
+
+		src, err = readTemplate(dir, name+".html.tmpl")
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		html, err := htmltemplate.New(name).Option("missingkey=error").Parse(src)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", op, name, err)
+		}
+
+		t.text[name] = text
+		t.html[name] = html
+	}
+
+	return t, nil
+}
+
+// Render - отрисовывает письмо name
+func (t *Templates) Render(name string, data Data) (Message, error) {
+	const op = "mail.Templates.Render"
+
+	text, ok := t.text[name]
+	if !ok {
+		return Message{}, fmt.Errorf("%s: unknown template %q", op, name)
+	}
+
+	var subject, body, html bytes.Buffer
+
+	if err := text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := text.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := t.html[name].Execute(&html, data); err != nil {
+		return Message{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return Message{
+		// Перевод строки в теме сломал бы заголовки письма
+		Subject: strings.Join(strings.Fields(subject.String()), " "),
+		Text:    body.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// readTemplate - файл из dir, если он там есть, иначе встроенный
+func readTemplate(dir, file string) (string, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, file))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	data, err := embedded.ReadFile("templates/" + file)
+	if err != nil {
+		return "", err
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,95 @@
+package mail
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplates_Embedded(t *testing.T) {
+	tpl, err := LoadTemplates("")
+	require.NoError(t, err)
+
+	data := Data{
+		Email:     "a@b.c",
+		Link:      "https://sso.example.com/verify?token=x&y=<z>",
+		Code:      "123456",
+		ExpiresAt: time.Date(2025, 1, 2, 3, 4, 0, 0, time.UTC),
+	}
+
+	for _, name := range []string{TemplateVerification, TemplatePasswordReset, TemplateLoginCode, TemplateInvitation, TemplateDeletion} {
+		msg, err := tpl.Render(name, data)
+		require.NoError(t, err, name)
+
+		assert.NotEmpty(t, msg.Subject, name)
+		assert.NotContains(t, msg.Subject, "\n", name)
+		assert.Contains(t, msg.Text, "a@b.c", name)
+		assert.Contains(t, msg.HTML, "02.01.2025 03:04 UTC", name)
+	}
+
+	msg, err := tpl.Render(TemplateVerification, data)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, data.Link, "text version is not escaped")
+	assert.NotContains(t, msg.HTML, "<z>", "html version is escaped")
+
+	msg, err = tpl.Render(TemplateLoginCode, data)
+	require.NoError(t, err)
+	assert.Contains(t, msg.Subject, "123456")
+
+	msg, err = tpl.Render(TemplateInvitation, Data{Email: "a@b.c", Code: "invite-token", ExpiresAt: data.ExpiresAt})
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "invite-token", "without a link the token is sent as a code")
+
+	msg, err = tpl.Render(TemplateDeletion, Data{Email: "a@b.c", Code: "cancel-token", ExpiresAt: data.ExpiresAt})
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "cancel-token", "without a link the token is sent as a code")
+
+	msg, err = tpl.Render(TemplateLoginAlert, Data{
+		Email: "a@b.c", Link: "https://sso.example.com/sessions",
+		LoggedInAt: data.ExpiresAt, Location: "DE", Device: "Mozilla/5.0 <script>",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "02.01.2025 03:04 UTC")
+	assert.Contains(t, msg.Text, "DE")
+	assert.Contains(t, msg.HTML, "https://sso.example.com/sessions")
+	assert.NotContains(t, msg.HTML, "<script>", "user agent comes from the client and is escaped")
+
+	msg, err = tpl.Render(TemplateLoginAlert, Data{Email: "a@b.c", LoggedInAt: data.ExpiresAt})
+	require.NoError(t, err)
+	assert.Contains(t, msg.Text, "не определено")
+
+	_, err = tpl.Render("unknown", data)
+	require.Error(t, err)
+}
+
+func TestTemplates_Override(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "verification.txt.tmpl"),
+		[]byte(`{{define "subject"}}Welcome to ACME{{end}}Confirm {{.Email}}: {{.Link}}`), 0o600))
+
+	tpl, err := LoadTemplates(dir)
+	require.NoError(t, err)
+
+	msg, err := tpl.Render(TemplateVerification, Data{Email: "a@b.c", Link: "https://x"})
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome to ACME", msg.Subject)
+	assert.Equal(t, "Confirm a@b.c: https://x", msg.Text)
+	assert.Contains(t, msg.HTML, "Подтвердить email", "html version still comes from the binary")
+}
+
+func TestTemplates_OverrideErrors(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "login_code.txt.tmpl"), []byte(`no subject {{.Code}}`), 0o600))
+
+	_, err := LoadTemplates(dir)
+	require.ErrorContains(t, err, "subject")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "login_code.txt.tmpl"), []byte(`{{define "subject"}}{{.Code}`), 0o600))
+
+	_, err = LoadTemplates(dir)
+	require.Error(t, err)
+}
@@ -0,0 +1,109 @@
+// Package maintenance - режим обслуживания (только чтение).
+//
+// В режиме обслуживания сервис отклоняет всё, что меняет хранилище, но продолжает проверять токены,
+// чтобы пользователей не разлогинило на время миграции или разбора инцидента.
+// Флаг живёт в памяти процесса: перезагрузка конфигурации его не сбрасывает, рестарт - сбрасывает.
+package maintenance
+
+import (
+	"expvar"
+	"sso/internal/config"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// enabledGauge - 1, пока включён режим обслуживания (виден в /debug/vars)
+var enabledGauge = expvar.NewInt("maintenance_mode")
+
+// State - состояние режима обслуживания
+type State struct {
+	Enabled bool
+	Reason  string    // зачем включён (для администраторов)
+	Since   time.Time // когда включён
+}
+
+// Mode - флаг режима обслуживания; безопасен для конкурентного использования
+type Mode struct {
+	enabled    atomic.Bool
+	allowLogin atomic.Bool
+	retryAfter atomic.Int64
+
+	mu    sync.Mutex
+	state State
+}
+
+// New - выключенный режим обслуживания с настройками cfg
+func New(cfg config.MaintenanceConfig) *Mode {
+	m := &Mode{}
+	m.Configure(cfg)
+
+	return m
+}
+
+// Configure - применяет настройки (при перезагрузке конфигурации); сам флаг не меняется
+func (m *Mode) Configure(cfg config.MaintenanceConfig) {
+	m.allowLogin.Store(!cfg.BlockLogin)
+	m.retryAfter.Store(int64(cfg.RetryAfter))
+}
+
+// Set - включает или выключает режим; reason запоминается при включении.
+// Возвращает false, если режим уже был в этом состоянии
+func (m *Mode) Set(enabled bool, reason string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.set(enabled, reason)
+}
+
+// Toggle - переключает режим (SIGUSR1) и возвращает новое состояние
+func (m *Mode) Toggle(reason string) State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.set(!m.state.Enabled, reason)
+
+	return m.state
+}
+
+// set - меняет состояние; вызывается под m.mu
+func (m *Mode) set(enabled bool, reason string) bool {
+	if m.state.Enabled == enabled {
+		return false
+	}
+
+	m.state = State{Enabled: enabled}
+	if enabled {
+		m.state.Reason = reason
+		m.state.Since = time.Now()
+		enabledGauge.Set(1)
+	} else {
+		enabledGauge.Set(0)
+	}
+	m.enabled.Store(enabled)
+
+	return true
+}
+
+// State - текущее состояние
+func (m *Mode) State() State {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.state
+}
+
+// Enabled - включён ли режим обслуживания
+func (m *Mode) Enabled() bool {
+	return m.enabled.Load()
+}
+
+// AllowLogin - работает ли вход в режиме обслуживания
+func (m *Mode) AllowLogin() bool {
+	return m.allowLogin.Load()
+}
+
+// RetryAfter - через сколько клиенту стоит повторить отклонённый запрос
+func (m *Mode) RetryAfter() time.Duration {
+	return time.Duration(m.retryAfter.Load())
+}
@@ -0,0 +1,28 @@
+package maintenance
+
+import (
+	"sso/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMode(t *testing.T) {
+	m := New(config.MaintenanceConfig{RetryAfter: time.Minute})
+	assert.False(t, m.Enabled())
+	assert.True(t, m.AllowLogin())
+	assert.Equal(t, time.Minute, m.RetryAfter())
+
+	assert.True(t, m.Set(true, "migration"))
+	assert.False(t, m.Set(true, "again"), "already enabled")
+	assert.Equal(t, "migration", m.State().Reason)
+	assert.WithinDuration(t, time.Now(), m.State().Since, time.Second)
+	assert.Equal(t, int64(1), enabledGauge.Value())
+
+	state := m.Toggle("SIGUSR1")
+	assert.False(t, state.Enabled)
+	assert.Empty(t, state.Reason)
+	assert.False(t, m.Enabled())
+	assert.Equal(t, int64(0), enabledGauge.Value())
+}
@@ -0,0 +1,67 @@
+// Package mergepatch - JSON Merge Patch (RFC 7386).
+//
+// Объект патча сливается с документом рекурсивно, null удаляет ключ, любое другое значение
+// (включая массивы) заменяет прежнее целиком.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Apply - применяет patch к doc. Пустой doc считается отсутствующим документом.
+// Числа сохраняются в исходной записи (без преобразования во float64)
+func Apply(doc, patch []byte) ([]byte, error) {
+	p, err := decode(patch)
+	if err != nil {
+		return nil, fmt.Errorf("invalid patch: %w", err)
+	}
+
+	var target any
+	if len(bytes.TrimSpace(doc)) > 0 {
+		if target, err = decode(doc); err != nil {
+			return nil, fmt.Errorf("invalid document: %w", err)
+		}
+	}
+
+	return json.Marshal(merge(target, p))
+}
+
+// merge - алгоритм MergePatch(Target, Patch) из раздела 2 RFC 7386
+func merge(target, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+			continue
+		}
+		t[k] = merge(t[k], v)
+	}
+
+	return t
+}
+
+func decode(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after JSON value")
+	}
+
+	return v, nil
+}
@@ -0,0 +1,51 @@
+package mergepatch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Примеры из приложения A RFC 7386
+func TestApply_RFCExamples(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"a":1,"e":null}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{``, `{"a":1}`, `{"a":1}`},
+	}
+	for _, tt := range tests {
+		got, err := Apply([]byte(tt.doc), []byte(tt.patch))
+		require.NoError(t, err, "%s + %s", tt.doc, tt.patch)
+		assert.JSONEq(t, tt.want, string(got), "%s + %s", tt.doc, tt.patch)
+	}
+}
+
+func TestApply_KeepsNumbers(t *testing.T) {
+	got, err := Apply([]byte(`{"id":9007199254740993}`), []byte(`{"n":1.50}`))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id":9007199254740993,"n":1.50}`, string(got))
+}
+
+func TestApply_Invalid(t *testing.T) {
+	_, err := Apply([]byte(`{}`), []byte(`{"a":`))
+	require.Error(t, err)
+
+	_, err = Apply([]byte(`{} {}`), []byte(`{}`))
+	require.Error(t, err)
+}
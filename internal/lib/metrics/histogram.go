@@ -0,0 +1,115 @@
+// Package metrics - гистограммы для expvar (/debug/vars): в стандартном expvar есть только счётчики
+package metrics
+
+import (
+	"encoding/json"
+	"expvar"
+	"strconv"
+	"sync"
+)
+
+// DurationBuckets - верхние границы корзин для длительностей, в секундах
+var DurationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5}
+
+// Histogram - гистограмма с накопительными корзинами (как у Prometheus), публикуемая через expvar.
+// В /debug/vars выглядит как {"buckets":{"0.001":3,...,"+Inf":10},"count":10,"sum":0.42}
+type Histogram struct {
+	bounds []float64
+
+	mu     sync.Mutex
+	counts []uint64 // counts[i] - наблюдения <= bounds[i], последний элемент - +Inf
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram - создаёт гистограмму с верхними границами корзин bounds (по возрастанию)
+func NewHistogram(bounds []float64) *Histogram {
+	return &Histogram{
+		bounds: bounds,
+		counts: make([]uint64, len(bounds)+1),
+	}
+}
+
+// Observe - добавляет наблюдение
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i, b := range h.bounds {
+		if v <= b {
+			h.counts[i]++
+		}
+	}
+	h.counts[len(h.bounds)]++
+	h.count++
+	h.sum += v
+}
+
+// Count - сколько всего наблюдений
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return h.count
+}
+
+// String - JSON-представление для expvar
+func (h *Histogram) String() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	buckets := make(map[string]uint64, len(h.counts))
+	for i, b := range h.bounds {
+		buckets[strconv.FormatFloat(b, 'g', -1, 64)] = h.counts[i]
+	}
+	buckets["+Inf"] = h.counts[len(h.bounds)]
+
+	out, _ := json.Marshal(struct {
+		Buckets map[string]uint64 `json:"buckets"`
+		Count   uint64            `json:"count"`
+		Sum     float64           `json:"sum"`
+	}{buckets, h.count, h.sum})
+
+	return string(out)
+}
+
+// HistogramVec - набор гистограмм с одинаковыми корзинами, по одной на значение метки (например, метод).
+// В /debug/vars выглядит как {"<метка>": <гистограмма>, ...}
+type HistogramVec struct {
+	bounds []float64
+	m      expvar.Map
+	mu     sync.Mutex
+}
+
+// NewHistogramVec - создаёт набор гистограмм с корзинами bounds
+func NewHistogramVec(bounds []float64) *HistogramVec {
+	return &HistogramVec{bounds: bounds}
+}
+
+// With - гистограмма для метки label (создаётся при первом обращении)
+func (v *HistogramVec) With(label string) *Histogram {
+	if h, ok := v.m.Get(label).(*Histogram); ok {
+		return h
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if h, ok := v.m.Get(label).(*Histogram); ok {
+		return h
+	}
+	h := NewHistogram(v.bounds)
+	v.m.Set(label, h)
+
+	return h
+}
+
+// String - JSON-представление для expvar
+func (v *HistogramVec) String() string {
+	return v.m.String()
+}
+
+var (
+	_ expvar.Var = (*Histogram)(nil)
+	_ expvar.Var = (*HistogramVec)(nil)
+)
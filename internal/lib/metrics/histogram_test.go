@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogram(t *testing.T) {
+	h := NewHistogram([]float64{0.1, 1})
+	h.Observe(0.05)
+	h.Observe(0.5)
+	h.Observe(5)
+
+	var got struct {
+		Buckets map[string]uint64 `json:"buckets"`
+		Count   uint64            `json:"count"`
+		Sum     float64           `json:"sum"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(h.String()), &got))
+
+	assert.Equal(t, map[string]uint64{"0.1": 1, "1": 2, "+Inf": 3}, got.Buckets)
+	assert.Equal(t, uint64(3), got.Count)
+	assert.InDelta(t, 5.55, got.Sum, 1e-9)
+}
+
+func TestHistogramVec(t *testing.T) {
+	v := NewHistogramVec([]float64{1})
+	v.With("a").Observe(0.5)
+	v.With("a").Observe(2)
+	v.With("b").Observe(0.5)
+
+	assert.Same(t, v.With("a"), v.With("a"))
+	assert.Equal(t, uint64(2), v.With("a").Count())
+
+	var got map[string]struct {
+		Count uint64 `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(v.String()), &got))
+	assert.Equal(t, uint64(2), got["a"].Count)
+	assert.Equal(t, uint64(1), got["b"].Count)
+}
@@ -0,0 +1,87 @@
+// Package origin - разрешённые источники (Origin) браузерных клиентов приложения.
+//
+// Источник - схема, хост и необязательный порт без пути: https://app.example.com, http://localhost:3000.
+// Самая левая метка хоста может быть звёздочкой: https://*.example.com подходит для https://a.example.com
+// и https://a.b.example.com, но не для самого https://example.com. Схема и порт сравниваются точно,
+// хост - без учёта регистра
+package origin
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// wildcard - префикс хоста, подходящий для любого поддомена
+const wildcard = "*."
+
+// ErrInvalid - запись не является источником
+var ErrInvalid = errors.New("invalid origin")
+
+// Validate - проверяет запись списка источников приложения
+func Validate(pattern string) error {
+	scheme, host, _, err := split(pattern)
+	if err != nil {
+		return err
+	}
+	if scheme != "http" && scheme != "https" {
+		return fmt.Errorf("%w %q: scheme must be http or https", ErrInvalid, pattern)
+	}
+
+	name, wild := strings.CutPrefix(host, wildcard)
+	if name == "" || strings.Contains(name, "*") {
+		return fmt.Errorf("%w %q: * is allowed only as the leftmost label", ErrInvalid, pattern)
+	}
+	// *.com разрешил бы любой сайт в зоне
+	if wild && !strings.Contains(name, ".") {
+		return fmt.Errorf("%w %q: wildcard needs at least two labels after *", ErrInvalid, pattern)
+	}
+	if strings.HasPrefix(name, ".") || strings.HasSuffix(name, ".") || strings.Contains(name, "..") {
+		return fmt.Errorf("%w %q: empty host label", ErrInvalid, pattern)
+	}
+
+	return nil
+}
+
+// Match - подходит ли Origin запроса под запись pattern (проверенную Validate)
+func Match(pattern, origin string) bool {
+	pScheme, pHost, pPort, err := split(pattern)
+	if err != nil {
+		return false
+	}
+	scheme, host, port, err := split(origin)
+	if err != nil || strings.Contains(host, "*") {
+		return false
+	}
+	if pScheme != scheme || pPort != port {
+		return false
+	}
+
+	if suffix, ok := strings.CutPrefix(pHost, "*"); ok {
+		// suffix начинается с точки: поддомен должен быть непустым
+		return len(host) > len(suffix) && strings.HasSuffix(host, suffix)
+	}
+
+	return host == pHost
+}
+
+// split - схема, хост в нижнем регистре и порт (порт схемы по умолчанию - пустой: браузер его не передаёт).
+// Путь, запрос, фрагмент и учётные данные запрещены
+func split(s string) (scheme, host, port string, err error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", "", "", fmt.Errorf("%w %q: %w", ErrInvalid, s, err)
+	}
+	if u.Host == "" || u.User != nil || u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.Opaque != "" ||
+		strings.HasSuffix(s, "?") || strings.HasSuffix(s, "#") {
+		return "", "", "", fmt.Errorf("%w %q: must be scheme://host[:port] without a path", ErrInvalid, s)
+	}
+
+	scheme, port = strings.ToLower(u.Scheme), u.Port()
+	if scheme == "https" && port == "443" || scheme == "http" && port == "80" {
+		port = ""
+	}
+
+	return scheme, strings.ToLower(u.Hostname()), port, nil
+}
@@ -0,0 +1,73 @@
+package origin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	for _, p := range []string{
+		"https://app.example.com",
+		"http://localhost:3000",
+		"https://*.example.com",
+		"https://*.example.com:8443",
+		"HTTPS://App.Example.com",
+	} {
+		assert.NoError(t, Validate(p), p)
+	}
+
+	for _, p := range []string{
+		"",
+		"app.example.com",
+		"ftp://example.com",
+		"https://example.com/",
+		"https://example.com/cb",
+		"https://example.com?x=1",
+		"https://example.com#top",
+		"https://user@example.com",
+		"https://*",
+		"https://*.com",
+		"https://a.*.example.com",
+		"https://*example.com",
+		"https://**.example.com",
+		"https://example..com",
+		"*",
+	} {
+		assert.ErrorIs(t, Validate(p), ErrInvalid, p)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, origin string
+		want            bool
+	}{
+		{"https://app.example.com", "https://app.example.com", true},
+		{"https://app.example.com", "https://APP.example.com", true},
+		{"https://app.example.com", "http://app.example.com", false},
+		{"https://app.example.com", "https://app.example.com:8443", false},
+		{"https://app.example.com:443", "https://app.example.com", true},
+		{"http://localhost:3000", "http://localhost:3000", true},
+		{"http://localhost:3000", "http://localhost:3001", false},
+
+		// Звёздочка - любой непустой поддомен, но не сам домен
+		{"https://*.example.com", "https://a.example.com", true},
+		{"https://*.example.com", "https://a.b.example.com", true},
+		{"https://*.example.com", "https://example.com", false},
+		{"https://*.example.com", "https://.example.com", false},
+		{"https://*.example.com", "https://evilexample.com", false},
+		{"https://*.example.com", "https://a.example.com.evil.net", false},
+		{"https://*.example.com", "http://a.example.com", false},
+		{"https://*.example.com", "https://a.example.com:8443", false},
+		{"https://*.example.com:8443", "https://a.example.com:8443", true},
+
+		// Origin запроса не бывает шаблоном или адресом с путём
+		{"https://*.example.com", "https://*.example.com", false},
+		{"https://app.example.com", "https://app.example.com/path", false},
+		{"https://app.example.com", "null", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.pattern, tt.origin), "%s vs %s", tt.pattern, tt.origin)
+	}
+}
@@ -0,0 +1,60 @@
+// Package phone - номера телефонов в формате E.164 (+<код страны><номер>, до 15 цифр).
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+// Длина номера E.164 в цифрах (без "+")
+const (
+	minDigits = 7
+	maxDigits = 15
+)
+
+// ErrInvalid - строка не похожа на международный номер телефона
+var ErrInvalid = errors.New("invalid phone number")
+
+// Normalize - приводит номер к E.164: "+7 (912) 345-67-89" -> "+79123456789".
+// Пробелы, дефисы, точки и скобки отбрасываются, международный префикс "00" заменяется на "+".
+// Номер без кода страны отклоняется: по нему нельзя понять, куда отправлять SMS
+func Normalize(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasPrefix(s, "00"):
+		s = s[2:]
+	default:
+		return "", ErrInvalid
+	}
+
+	digits := make([]byte, 0, maxDigits)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+		default:
+			return "", ErrInvalid
+		}
+	}
+
+	if len(digits) < minDigits || len(digits) > maxDigits || digits[0] == '0' {
+		return "", ErrInvalid
+	}
+
+	return "+" + string(digits), nil
+}
+
+// Mask - номер для показа пользователю: видны первая цифра кода страны и две последние цифры
+// ("+79123456789" -> "+7********89")
+func Mask(e164 string) string {
+	digits := strings.TrimPrefix(e164, "+")
+	if len(digits) < minDigits {
+		return strings.Repeat("*", len(e164))
+	}
+
+	return "+" + digits[:1] + strings.Repeat("*", len(digits)-3) + digits[len(digits)-2:]
+}
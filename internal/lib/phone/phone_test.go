@@ -0,0 +1,40 @@
+package phone
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"+79123456789":         "+79123456789",
+		" +7 (912) 345-67-89 ": "+79123456789",
+		"0044 20.7946.0000":    "+442079460000",
+		"+683 4002":            "+6834002",
+	} {
+		got, err := Normalize(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+
+	for _, in := range []string{
+		"",
+		"89123456789",        // без кода страны
+		"+0123456789",        // код страны не начинается с 0
+		"+7912345678901234",  // больше 15 цифр
+		"+12345",             // слишком короткий
+		"+7912345678x",       // посторонний символ
+		"+7912345678;rm -rf", // посторонние символы
+	} {
+		_, err := Normalize(in)
+		assert.ErrorIs(t, err, ErrInvalid, in)
+	}
+}
+
+func TestMask(t *testing.T) {
+	assert.Equal(t, "+7********89", Mask("+79123456789"))
+	assert.Equal(t, "+6****02", Mask("+6834002"))
+	assert.Equal(t, "***", Mask("+12"))
+}
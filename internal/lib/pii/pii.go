@@ -0,0 +1,140 @@
+// Package pii - шифрование персональных данных (email, номера телефонов) в колонках БД.
+//
+// Значение шифруется AES-256-GCM со случайным nonce, поэтому одинаковые email дают разный шифртекст
+// и искать по нему нельзя. Для поиска и уникальности рядом хранится слепой индекс - HMAC-SHA256
+// значения в нижнем регистре: он детерминирован, но без ключа по нему не восстановить email.
+// Ключи шифрования и индекса выводятся из одного ключа конфигурации (pii_key).
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// KeySize - размер ключа в байтах (в конфигурации - base64 от 32 случайных байт: openssl rand -base64 32)
+const KeySize = 32
+
+// prefix - версия формата шифртекста; по нему же видно, что значение зашифровано
+const prefix = "v1:"
+
+// ErrNotEncrypted - значение в колонке не зашифровано (БД не прошла admin encrypt-pii)
+var ErrNotEncrypted = errors.New("value is not encrypted")
+
+// Cipher - шифрование значений и слепой индекс. Безопасен для конкурентного использования
+type Cipher struct {
+	aead  cipher.AEAD
+	index []byte
+	id    string
+}
+
+// ParseKey - ключ из base64 (стандартный алфавит, с дополнением или без)
+func ParseKey(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		key, err = base64.RawStdEncoding.DecodeString(s)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("pii key: invalid base64: %w", err)
+	}
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("pii key: must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	return key, nil
+}
+
+// New - шифр с ключом key (KeySize байт)
+func New(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("pii key: must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	block, err := aes.NewCipher(derive(key, "sso pii encryption"))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cipher{
+		aead:  aead,
+		index: derive(key, "sso pii blind index"),
+		id:    hex.EncodeToString(derive(key, "sso pii key id")[:8]),
+	}, nil
+}
+
+// derive - отдельный ключ для назначения purpose, чтобы один ключ не использовался в AES и HMAC
+func derive(key []byte, purpose string) []byte {
+	m := hmac.New(sha256.New, key)
+	m.Write([]byte(purpose))
+
+	return m.Sum(nil)
+}
+
+// KeyID - отпечаток ключа: по нему БД запоминает, каким ключом зашифрована (сам ключ из него не восстановить)
+func (c *Cipher) KeyID() string {
+	return c.id
+}
+
+// Encrypt - зашифрованное значение. Пустая строка не шифруется: пусто значит "нет значения" (нет телефона)
+func (c *Cipher) Encrypt(plain string) (string, error) {
+	if plain == "" {
+		return "", nil
+	}
+
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plain)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	return prefix + base64.RawStdEncoding.EncodeToString(c.aead.Seal(nonce, nonce, []byte(plain), nil)), nil
+}
+
+// Decrypt - исходное значение. Незашифрованное значение - ErrNotEncrypted, чужой ключ или
+// повреждённые данные - ошибка расшифровки
+func (c *Cipher) Decrypt(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	enc, ok := strings.CutPrefix(s, prefix)
+	if !ok {
+		return "", ErrNotEncrypted
+	}
+
+	b, err := base64.RawStdEncoding.DecodeString(enc)
+	if err != nil || len(b) < c.aead.NonceSize() {
+		return "", fmt.Errorf("pii: malformed ciphertext")
+	}
+
+	plain, err := c.aead.Open(nil, b[:c.aead.NonceSize()], b[c.aead.NonceSize():], nil)
+	if err != nil {
+		return "", fmt.Errorf("pii: decrypt: %w", err)
+	}
+
+	return string(plain), nil
+}
+
+// Index - слепой индекс значения без учёта регистра (hex HMAC-SHA256)
+func (c *Cipher) Index(s string) string {
+	m := hmac.New(sha256.New, c.index)
+	m.Write([]byte(strings.ToLower(s)))
+
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Encrypted - похоже ли значение на зашифрованное этим пакетом
+func Encrypted(s string) bool {
+	return strings.HasPrefix(s, prefix)
+}
@@ -0,0 +1,87 @@
+package pii
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCipher(t *testing.T, b byte) *Cipher {
+	t.Helper()
+
+	c, err := New(bytes.Repeat([]byte{b}, KeySize))
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	c := testCipher(t, 1)
+
+	a, err := c.Encrypt("a@b.c")
+	require.NoError(t, err)
+	b, err := c.Encrypt("a@b.c")
+	require.NoError(t, err)
+
+	assert.True(t, Encrypted(a))
+	assert.NotContains(t, a, "a@b.c")
+	assert.NotEqual(t, a, b, "random nonce: equal values give different ciphertexts")
+
+	plain, err := c.Decrypt(a)
+	require.NoError(t, err)
+	assert.Equal(t, "a@b.c", plain)
+
+	empty, err := c.Encrypt("")
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestDecrypt_Errors(t *testing.T) {
+	c := testCipher(t, 1)
+
+	_, err := c.Decrypt("a@b.c")
+	require.ErrorIs(t, err, ErrNotEncrypted)
+
+	enc, err := testCipher(t, 2).Encrypt("a@b.c")
+	require.NoError(t, err)
+	_, err = c.Decrypt(enc)
+	assert.ErrorContains(t, err, "decrypt", "a different key does not open the value")
+
+	_, err = c.Decrypt("v1:!!!")
+	assert.ErrorContains(t, err, "malformed")
+}
+
+func TestIndex(t *testing.T) {
+	c := testCipher(t, 1)
+
+	assert.Equal(t, c.Index("A@B.c"), c.Index("a@b.C"), "case-insensitive like email lookups")
+	assert.NotEqual(t, c.Index("a@b.c"), c.Index("a@b.d"))
+	assert.NotEqual(t, c.Index("a@b.c"), testCipher(t, 2).Index("a@b.c"))
+	assert.Len(t, c.Index("a@b.c"), 64)
+}
+
+func TestKeyID(t *testing.T) {
+	assert.Equal(t, testCipher(t, 1).KeyID(), testCipher(t, 1).KeyID())
+	assert.NotEqual(t, testCipher(t, 1).KeyID(), testCipher(t, 2).KeyID())
+}
+
+func TestParseKey(t *testing.T) {
+	key := bytes.Repeat([]byte{7}, KeySize)
+
+	got, err := ParseKey(base64.StdEncoding.EncodeToString(key) + "\n")
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	got, err = ParseKey(base64.RawStdEncoding.EncodeToString(key))
+	require.NoError(t, err)
+	assert.Equal(t, key, got)
+
+	_, err = ParseKey(base64.StdEncoding.EncodeToString(key[:16]))
+	assert.ErrorContains(t, err, "must be 32 bytes")
+
+	_, err = ParseKey("not base64!")
+	assert.ErrorContains(t, err, "invalid base64")
+}
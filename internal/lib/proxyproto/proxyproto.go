@@ -0,0 +1,209 @@
+// Package proxyproto - приём заголовка PROXY protocol (v1 и v2) от балансировщика L4.
+//
+// Балансировщик L4 (HAProxy, NLB) не меняет gRPC-метаданные, поэтому адрес клиента он передаёт
+// заголовком PROXY в начале TCP-соединения. Заголовку верим, только если соединение пришло из
+// доверенных сетей: от остальных заголовок читается (иначе его байты попали бы в gRPC), но адрес
+// из него игнорируется. Соединение без заголовка обслуживается как обычно.
+package proxyproto
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"sso/internal/lib/clientip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInvalidHeader - заголовок PROXY испорчен; соединение закрывается
+var ErrInvalidHeader = errors.New("invalid PROXY protocol header")
+
+// HeaderTimeout - сколько ждать заголовок от только что принятого соединения
+const HeaderTimeout = 5 * time.Second
+
+const (
+	v1Prefix = "PROXY "
+	v1MaxLen = 107 // самая длинная строка v1 вместе с \r\n
+)
+
+// v2Signature - первые 12 байт заголовка v2
+var v2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// Listener - net.Listener, соединения которого сообщают адрес клиента из заголовка PROXY
+type Listener struct {
+	net.Listener
+	trusted []netip.Prefix
+}
+
+// NewListener - принимает заголовок PROXY от балансировщиков из сетей trusted
+func NewListener(l net.Listener, trusted []netip.Prefix) *Listener {
+	return &Listener{Listener: l, trusted: trusted}
+}
+
+// Accept - принимает соединение; заголовок читается при первом чтении или RemoteAddr уже в горутине
+// соединения, поэтому медленный клиент не задерживает приём остальных
+func (l *Listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Conn{Conn: c, trusted: l.trusted}, nil
+}
+
+// Conn - соединение, RemoteAddr которого - адрес клиента из заголовка PROXY доверенного балансировщика
+type Conn struct {
+	net.Conn
+	trusted []netip.Prefix
+
+	once   sync.Once
+	r      *bufio.Reader
+	remote net.Addr
+	err    error
+}
+
+// Read - читает данные после заголовка PROXY
+func (c *Conn) Read(p []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.err != nil {
+		return 0, c.err
+	}
+
+	return c.r.Read(p)
+}
+
+// RemoteAddr - адрес клиента из заголовка или адрес соединения
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+
+	return c.remote
+}
+
+// readHeader - читает заголовок, если он есть
+func (c *Conn) readHeader() {
+	c.r = bufio.NewReader(c.Conn)
+	c.remote = c.Conn.RemoteAddr()
+
+	_ = c.Conn.SetReadDeadline(time.Now().Add(HeaderTimeout))
+	defer func() { _ = c.Conn.SetReadDeadline(time.Time{}) }()
+
+	var (
+		src net.Addr
+		err error
+	)
+	switch {
+	case hasPrefix(c.r, []byte(v1Prefix)):
+		src, err = readV1(c.r)
+	case hasPrefix(c.r, v2Signature):
+		src, err = readV2(c.r)
+	default:
+		return
+	}
+	if err != nil {
+		c.err = fmt.Errorf("%w: %w", ErrInvalidHeader, err)
+		return
+	}
+
+	if direct, ok := clientip.AddrOf(c.Conn.RemoteAddr()); ok && src != nil && clientip.Allowed(direct, c.trusted) {
+		c.remote = src
+	}
+}
+
+// hasPrefix - начинается ли поток с prefix. Читает не больше len(prefix) байт и только пока они совпадают:
+// gRPC-клиент без заголовка не ждёт ответа и не отправит больше, чем нужно для различия
+func hasPrefix(r *bufio.Reader, prefix []byte) bool {
+	for n := 1; n <= len(prefix); n++ {
+		b, err := r.Peek(n)
+		if err != nil || b[n-1] != prefix[n-1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// readV1 - "PROXY TCP4 <src> <dst> <sport> <dport>\r\n"; для UNKNOWN адреса нет (nil)
+func readV1(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for !bytes.HasSuffix(line, []byte("\r\n")) {
+		if len(line) >= v1MaxLen {
+			return nil, errors.New("v1 header too long")
+		}
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+	}
+
+	fields := strings.Split(strings.TrimSuffix(string(line), "\r\n"), " ")
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, fmt.Errorf("malformed v1 header %q", line)
+	}
+
+	ip, err := netip.ParseAddr(fields[2])
+	if err != nil || ip.Is4() != (fields[1] == "TCP4") {
+		return nil, fmt.Errorf("malformed v1 source address %q", fields[2])
+	}
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("malformed v1 source port %q", fields[4])
+	}
+
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip, uint16(port))), nil
+}
+
+// readV2 - двоичный заголовок; для LOCAL (проверки балансировщика) и не TCP адреса нет (nil)
+func readV2(r *bufio.Reader) (net.Addr, error) {
+	var hdr [16]byte
+	if _, err := io.ReadFull(r, hdr[:]); err != nil {
+		return nil, err
+	}
+	if hdr[12]>>4 != 2 {
+		return nil, fmt.Errorf("unsupported v2 version %d", hdr[12]>>4)
+	}
+
+	body := make([]byte, binary.BigEndian.Uint16(hdr[14:16]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+
+	switch command := hdr[12] & 0x0f; command {
+	case 0x0: // LOCAL
+		return nil, nil
+	case 0x1: // PROXY
+	default:
+		return nil, fmt.Errorf("unsupported v2 command %d", command)
+	}
+
+	var (
+		ip   netip.Addr
+		port []byte
+	)
+	switch hdr[13] {
+	case 0x11: // TCP over IPv4: src(4) dst(4) sport(2) dport(2)
+		if len(body) < 12 {
+			return nil, errors.New("v2 header too short for IPv4")
+		}
+		ip, port = netip.AddrFrom4([4]byte(body[0:4])), body[8:10]
+	case 0x21: // TCP over IPv6: src(16) dst(16) sport(2) dport(2)
+		if len(body) < 36 {
+			return nil, errors.New("v2 header too short for IPv6")
+		}
+		ip, port = netip.AddrFrom16([16]byte(body[0:16])), body[32:34]
+	default:
+		return nil, nil
+	}
+
+	return net.TCPAddrFromAddrPort(netip.AddrPortFrom(ip, binary.BigEndian.Uint16(port))), nil
+}
@@ -0,0 +1,173 @@
+package proxyproto
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/peer"
+)
+
+var loopback = []netip.Prefix{netip.MustParsePrefix("127.0.0.0/8")}
+
+// accept - отправляет data через настоящее TCP-соединение и возвращает принятое Listener соединение
+func accept(t *testing.T, trusted []netip.Prefix, data []byte) net.Conn {
+	t.Helper()
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	l := NewListener(inner, trusted)
+	t.Cleanup(func() { _ = l.Close() })
+
+	client, err := net.Dial("tcp", inner.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+	_, err = client.Write(data)
+	require.NoError(t, err)
+
+	conn, err := l.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+// readAll - читает n байт после заголовка
+func readAll(t *testing.T, conn net.Conn, n int) string {
+	t.Helper()
+
+	buf := make([]byte, n)
+	_, err := io.ReadFull(conn, buf)
+	require.NoError(t, err)
+
+	return string(buf)
+}
+
+func v2Header(command byte, family byte, addrs []byte) []byte {
+	h := append([]byte{}, v2Signature...)
+	h = append(h, 0x20|command, family, 0, 0)
+	binary.BigEndian.PutUint16(h[14:16], uint16(len(addrs)))
+
+	return append(h, addrs...)
+}
+
+const preface = "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n"
+
+func TestV1(t *testing.T) {
+	conn := accept(t, loopback, []byte("PROXY TCP4 203.0.113.7 10.0.0.5 51234 44044\r\n"+preface))
+	assert.Equal(t, "203.0.113.7:51234", conn.RemoteAddr().String())
+	assert.Equal(t, preface, readAll(t, conn, len(preface)))
+
+	conn = accept(t, loopback, []byte("PROXY TCP6 2001:db8::1 2001:db8::2 443 44044\r\n"+preface))
+	assert.Equal(t, "[2001:db8::1]:443", conn.RemoteAddr().String())
+
+	// UNKNOWN - адрес соединения
+	conn = accept(t, loopback, []byte("PROXY UNKNOWN\r\n"+preface))
+	assert.Equal(t, preface, readAll(t, conn, len(preface)))
+	assert.Contains(t, conn.RemoteAddr().String(), "127.0.0.1:")
+}
+
+func TestV2(t *testing.T) {
+	ipv4 := []byte{203, 0, 113, 7, 10, 0, 0, 5, 0xc8, 0x22, 0xac, 0x0c}
+	conn := accept(t, loopback, append(v2Header(0x1, 0x11, ipv4), preface...))
+	assert.Equal(t, "203.0.113.7:51234", conn.RemoteAddr().String())
+	assert.Equal(t, preface, readAll(t, conn, len(preface)))
+
+	ipv6 := make([]byte, 36)
+	copy(ipv6, netip.MustParseAddr("2001:db8::1").AsSlice())
+	binary.BigEndian.PutUint16(ipv6[32:], 443)
+	conn = accept(t, loopback, append(v2Header(0x1, 0x21, append(ipv6, 0x04, 0, 1, 'x')), preface...))
+	assert.Equal(t, "[2001:db8::1]:443", conn.RemoteAddr().String())
+	assert.Equal(t, preface, readAll(t, conn, len(preface)), "TLVs after the addresses are skipped")
+
+	// LOCAL - проверка здоровья от самого балансировщика
+	conn = accept(t, loopback, append(v2Header(0x0, 0x00, nil), preface...))
+	assert.Contains(t, conn.RemoteAddr().String(), "127.0.0.1:")
+	assert.Equal(t, preface, readAll(t, conn, len(preface)))
+}
+
+// Заголовок от недоверенного соединения читается, но адрес из него игнорируется
+func TestUntrustedPeer(t *testing.T) {
+	untrusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	conn := accept(t, untrusted, []byte("PROXY TCP4 203.0.113.7 10.0.0.5 51234 44044\r\n"+preface))
+	assert.Contains(t, conn.RemoteAddr().String(), "127.0.0.1:")
+	assert.Equal(t, preface, readAll(t, conn, len(preface)))
+
+	ipv4 := []byte{203, 0, 113, 7, 10, 0, 0, 5, 0xc8, 0x22, 0xac, 0x0c}
+	conn = accept(t, untrusted, append(v2Header(0x1, 0x11, ipv4), preface...))
+	assert.Contains(t, conn.RemoteAddr().String(), "127.0.0.1:")
+}
+
+func TestNoHeader(t *testing.T) {
+	conn := accept(t, loopback, []byte(preface))
+	assert.Contains(t, conn.RemoteAddr().String(), "127.0.0.1:")
+	assert.Equal(t, preface, readAll(t, conn, len(preface)))
+
+	// TLS ClientHello
+	conn = accept(t, loopback, []byte{0x16, 0x03, 0x01})
+	assert.Equal(t, "\x16\x03\x01", readAll(t, conn, 3))
+}
+
+func TestInvalidHeader(t *testing.T) {
+	for name, data := range map[string][]byte{
+		"v1 garbage":     []byte("PROXY TCP4 not-an-ip 10.0.0.5 1 2\r\n"),
+		"v1 family":      []byte("PROXY TCP4 2001:db8::1 10.0.0.5 1 2\r\n"),
+		"v1 too long":    []byte("PROXY TCP4 " + string(make([]byte, 200))),
+		"v2 version":     append(append([]byte{}, v2Signature...), 0x11, 0x11, 0, 0),
+		"v2 short ipv4":  v2Header(0x1, 0x11, []byte{1, 2, 3}),
+		"v2 bad command": v2Header(0x5, 0x11, make([]byte, 12)),
+	} {
+		t.Run(name, func(t *testing.T) {
+			conn := accept(t, loopback, data)
+			_, err := conn.Read(make([]byte, 1))
+			require.ErrorIs(t, err, ErrInvalidHeader)
+		})
+	}
+}
+
+// gRPC-сервер видит адрес из заголовка в peer - оттуда его берёт интерцептор ClientIP
+func TestGRPCPeer(t *testing.T) {
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	peers := make(chan string, 1)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			p, _ := peer.FromContext(ctx)
+			peers <- p.Addr.String()
+
+			return handler(ctx, req)
+		}))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go func() { _ = srv.Serve(NewListener(inner, loopback)) }()
+	t.Cleanup(srv.Stop)
+
+	// Балансировщик отправляет заголовок первым делом после установки соединения
+	dial := func(ctx context.Context, addr string) (net.Conn, error) {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", addr)
+		if err != nil {
+			return nil, err
+		}
+		_, err = conn.Write([]byte("PROXY TCP4 203.0.113.7 10.0.0.5 51234 44044\r\n"))
+
+		return conn, err
+	}
+	cc, err := grpc.NewClient(inner.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithContextDialer(dial))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+
+	_, err = healthpb.NewHealthClient(cc).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7:51234", <-peers)
+}
@@ -0,0 +1,155 @@
+// Package pwned - проверка паролей по базе утечек Pwned Passwords (haveibeenpwned.com).
+//
+// Используется k-anonymity: наружу уходят только первые 5 символов SHA-1 пароля,
+// сервис отвечает всеми известными суффиксами с этим префиксом, а сравнение выполняется локально.
+package pwned
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1" // SHA-1 требует API Pwned Passwords, для хранения паролей он не используется
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// prefixLen - длина префикса хеша, отправляемого в API
+const prefixLen = 5
+
+// Checker - проверяет, встречался ли пароль в публичных утечках
+type Checker struct {
+	endpoint  string
+	threshold int
+	failOpen  bool
+	cacheTTL  time.Duration
+
+	client *httpx.Client
+	log    *slog.Logger
+	now    func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cacheEntry // префикс -> суффиксы с количеством вхождений
+}
+
+// cacheEntry - ответ API для одного префикса
+type cacheEntry struct {
+	counts  map[string]int
+	expires time.Time
+}
+
+// New - создаёт проверку по настройкам password.breach_check.*; client - клиент исходящих
+// запросов с таймаутом cfg.Timeout
+func New(cfg config.BreachCheckConfig, client *httpx.Client, log *slog.Logger) *Checker {
+	return &Checker{
+		endpoint:  strings.TrimSuffix(cfg.Endpoint, "/") + "/",
+		threshold: cfg.Threshold,
+		failOpen:  cfg.FailOpen,
+		cacheTTL:  cfg.CacheTTL,
+		client:    client,
+		log:       log,
+		now:       time.Now,
+		cache:     make(map[string]cacheEntry),
+	}
+}
+
+// IsCompromised - true, если пароль встречался в утечках больше threshold раз.
+// Если API недоступен и включён fail-open, пароль считается безопасным, а в лог пишется предупреждение
+func (c *Checker) IsCompromised(ctx context.Context, password string) (bool, error) {
+	const op = "pwned.IsCompromised"
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:prefixLen], hash[prefixLen:]
+
+	counts, err := c.rangeCounts(ctx, prefix)
+	if err != nil {
+		if c.failOpen {
+			c.log.Warn("breached password check unavailable, allowing password",
+				slog.String("op", op),
+				slog.String("error", err.Error()),
+			)
+
+			return false, nil
+		}
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return counts[suffix] > c.threshold, nil
+}
+
+// rangeCounts - суффиксы для префикса из кеша или из API
+func (c *Checker) rangeCounts(ctx context.Context, prefix string) (map[string]int, error) {
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.cache[prefix]
+	c.mu.Unlock()
+
+	if ok && now.Before(entry.expires) {
+		return entry.counts, nil
+	}
+
+	counts, err := c.fetch(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cacheTTL > 0 {
+		c.mu.Lock()
+		// Заодно выбрасываем устаревшие записи, чтобы кеш не рос бесконечно
+		for p, e := range c.cache {
+			if !now.Before(e.expires) {
+				delete(c.cache, p)
+			}
+		}
+		c.cache[prefix] = cacheEntry{counts: counts, expires: now.Add(c.cacheTTL)}
+		c.mu.Unlock()
+	}
+
+	return counts, nil
+}
+
+// fetch - запрашивает GET {endpoint}{prefix}; ответ - строки вида "SUFFIX:COUNT"
+func (c *Checker) fetch(ctx context.Context, prefix string) (map[string]int, error) {
+	// Add-Padding дополняет ответ фиктивными суффиксами, чтобы по размеру ответа нельзя было судить о префиксе
+	resp, err := c.client.Get(ctx, c.endpoint+prefix, http.Header{"Add-Padding": {"true"}})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	counts := make(map[string]int)
+
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		suffix, count, ok := strings.Cut(strings.TrimSpace(sc.Text()), ":")
+		if !ok {
+			continue
+		}
+
+		n, err := strconv.Atoi(count)
+		if err != nil {
+			return nil, fmt.Errorf("malformed count for suffix %s: %w", suffix, err)
+		}
+
+		counts[strings.ToUpper(suffix)] = n
+	}
+
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	return counts, nil
+}
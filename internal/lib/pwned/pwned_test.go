@@ -0,0 +1,152 @@
+package pwned
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// SHA-1("password") = 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+const (
+	passwordPrefix = "5BAA6"
+	passwordSuffix = "1E4C9B93F3F0682250B6CF8331B7EE68FD8"
+)
+
+// fakeAPI - range API, знающий только префикс пароля "password"
+type fakeAPI struct {
+	count    int // сколько раз "password" встречался в утечках
+	status   int // код ответа (0 - 200)
+	delay    time.Duration
+	requests atomic.Int32
+	paths    chan string // запрошенные пути, если задан; канал создаётся вместе с fakeAPI, обработчик читает поле конкурентно
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests.Add(1)
+	select {
+	case f.paths <- r.URL.Path:
+	default:
+	}
+
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+
+		return
+	}
+
+	if strings.TrimPrefix(r.URL.Path, "/range/") != passwordPrefix {
+		return
+	}
+
+	// Padding-строки с нулевым счётчиком, как при Add-Padding: true
+	fmt.Fprintf(w, "0018A45C4D1DEF81644B54AB7F969B88D65:0\r\n")
+	fmt.Fprintf(w, "%s:%d\r\n", strings.ToLower(passwordSuffix), f.count)
+}
+
+func newChecker(t *testing.T, api *fakeAPI, mutate func(*config.BreachCheckConfig)) *Checker {
+	t.Helper()
+
+	srv := httptest.NewServer(api)
+	t.Cleanup(srv.Close)
+
+	cfg := config.BreachCheckConfig{
+		Enabled:  true,
+		Endpoint: srv.URL + "/range",
+		Timeout:  time.Second,
+		CacheTTL: time.Minute,
+	}
+	if mutate != nil {
+		mutate(&cfg)
+	}
+
+	// Повторы проверяются в httpx; здесь каждая проверка - ровно один запрос
+	outbound, err := httpx.NewFactory(config.OutboundConfig{NoRetries: true})
+	require.NoError(t, err)
+
+	return New(cfg, outbound.Client("pwned", cfg.Timeout), slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestIsCompromised(t *testing.T) {
+	api := &fakeAPI{count: 10, paths: make(chan string, 16)}
+	c := newChecker(t, api, func(cfg *config.BreachCheckConfig) { cfg.Threshold = 5 })
+	ctx := context.Background()
+
+	compromised, err := c.IsCompromised(ctx, "password")
+	require.NoError(t, err)
+	assert.True(t, compromised)
+
+	// Наружу уходит только префикс, а не весь хеш
+	assert.Equal(t, "/range/"+passwordPrefix, <-api.paths)
+
+	compromised, err = c.IsCompromised(ctx, "correct horse battery staple 42")
+	require.NoError(t, err)
+	assert.False(t, compromised)
+}
+
+func TestIsCompromised_Threshold(t *testing.T) {
+	api := &fakeAPI{count: 3}
+	c := newChecker(t, api, func(cfg *config.BreachCheckConfig) { cfg.Threshold = 3 })
+
+	compromised, err := c.IsCompromised(context.Background(), "password")
+	require.NoError(t, err)
+	assert.False(t, compromised, "count equal to threshold is allowed")
+}
+
+func TestIsCompromised_CachesPrefix(t *testing.T) {
+	api := &fakeAPI{count: 1}
+	c := newChecker(t, api, nil)
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	for range 3 {
+		_, err := c.IsCompromised(context.Background(), "password")
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), api.requests.Load())
+
+	now = now.Add(2 * time.Minute)
+	_, err := c.IsCompromised(context.Background(), "password")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), api.requests.Load(), "expired entry must be refetched")
+}
+
+func TestIsCompromised_APIUnavailable(t *testing.T) {
+	tests := []struct {
+		name string
+		api  *fakeAPI
+	}{
+		{name: "server error", api: &fakeAPI{status: http.StatusServiceUnavailable}},
+		{name: "timeout", api: &fakeAPI{count: 1, delay: 200 * time.Millisecond}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slow := func(cfg *config.BreachCheckConfig) { cfg.Timeout = 50 * time.Millisecond }
+
+			open := newChecker(t, tt.api, func(cfg *config.BreachCheckConfig) { slow(cfg); cfg.FailOpen = true })
+			compromised, err := open.IsCompromised(context.Background(), "password")
+			require.NoError(t, err)
+			assert.False(t, compromised)
+
+			closed := newChecker(t, tt.api, slow)
+			_, err = closed.IsCompromised(context.Background(), "password")
+			require.Error(t, err)
+		})
+	}
+}
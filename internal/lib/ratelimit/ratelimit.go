@@ -0,0 +1,189 @@
+// Package ratelimit - ограничение частоты запросов корзинами токенов (token bucket).
+//
+// Корзина выбирается по полному имени gRPC-метода и, если для метода так настроено, по app_id из запроса:
+// регистрацию можно ограничить строже проверки токенов, а шумное приложение не съест лимит остальных.
+package ratelimit
+
+import (
+	"expvar"
+	"sso/internal/config"
+	"sso/internal/lib/clock"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rejected - отклонённые запросы по ключу корзины ("/auth.Auth/Login" или "/auth.Auth/Login app=3")
+var rejected = expvar.NewMap("ratelimit_rejected")
+
+// maxBuckets - сколько корзин держать одновременно. app_id приходит от клиента, и без предела
+// перебор несуществующих app_id раздувал бы память
+const maxBuckets = 10000
+
+// overflowApp - ключ общей корзины для app_id, которым не хватило отдельной
+const overflowApp = "other"
+
+type ruleKey struct {
+	method string
+	appID  int
+}
+
+// Limiter - корзины токенов по методам и приложениям
+type Limiter struct {
+	clock clock.Clock
+
+	mu      sync.Mutex
+	enabled bool
+	def     config.RateLimit
+	rules   map[ruleKey]config.RateLimitRule
+	buckets map[string]*bucket
+}
+
+// Option - необязательная настройка Limiter
+type Option func(l *Limiter)
+
+// WithClock - источник времени для пополнения корзин (по умолчанию настоящие часы)
+func WithClock(c clock.Clock) Option {
+	return func(l *Limiter) {
+		l.clock = c
+	}
+}
+
+// New - создаёт Limiter с настройками cfg
+func New(cfg config.RateLimitConfig, opts ...Option) *Limiter {
+	l := &Limiter{clock: clock.Real()}
+	for _, opt := range opts {
+		opt(l)
+	}
+	l.Configure(cfg)
+
+	return l
+}
+
+// Configure - применяет новые лимиты (перезагрузка конфигурации). Корзины создаются заново,
+// поэтому сразу после перезагрузки каждому ключу снова доступен полный burst
+func (l *Limiter) Configure(cfg config.RateLimitConfig) {
+	rules := make(map[ruleKey]config.RateLimitRule, len(cfg.Rules))
+	for _, r := range cfg.Rules {
+		rules[ruleKey{method: r.Method, appID: r.AppID}] = r
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.enabled = cfg.Enabled
+	l.def = cfg.Default
+	l.rules = rules
+	l.buckets = make(map[string]*bucket)
+}
+
+// Allow - можно ли выполнить запрос к method от приложения appID (0 - app_id в запросе нет).
+// Если нельзя, возвращает, через сколько в корзине появится токен
+func (l *Limiter) Allow(method string, appID int) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.enabled {
+		return 0, true
+	}
+
+	key, limit := l.match(method, appID)
+	if limit.Rate <= 0 {
+		return 0, true
+	}
+
+	now := l.clock.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.prune(now)
+		}
+		if len(l.buckets) >= maxBuckets && appID != 0 {
+			key = method + " app=" + overflowApp
+			b, ok = l.buckets[key]
+		}
+		if !ok {
+			b = newBucket(limit, now)
+			l.buckets[key] = b
+		}
+	}
+
+	retryAfter, allowed := b.take(now)
+	if !allowed {
+		rejected.Add(key, 1)
+	}
+
+	return retryAfter, allowed
+}
+
+// match - ключ корзины и лимиты для запроса: правило для метода и приложения, затем правило
+// для метода, затем лимиты по умолчанию (своя корзина на каждый метод)
+func (l *Limiter) match(method string, appID int) (string, config.RateLimit) {
+	if appID != 0 {
+		if r, ok := l.rules[ruleKey{method: method, appID: appID}]; ok {
+			return appKey(method, appID), r.Limit()
+		}
+	}
+
+	if r, ok := l.rules[ruleKey{method: method}]; ok {
+		if r.PerApp && appID != 0 {
+			return appKey(method, appID), r.Limit()
+		}
+
+		return method, r.Limit()
+	}
+
+	return method, l.def
+}
+
+// prune - удаляет полные корзины: такая корзина ничем не отличается от только что созданной
+func (l *Limiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		if b.full(now) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func appKey(method string, appID int) string {
+	return method + " app=" + strconv.Itoa(appID)
+}
+
+// bucket - корзина токенов: пополняется со скоростью rate, вмещает не больше burst
+type bucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(limit config.RateLimit, now time.Time) *bucket {
+	return &bucket{
+		rate:   limit.Rate,
+		burst:  float64(limit.Burst),
+		tokens: float64(limit.Burst),
+		last:   now,
+	}
+}
+
+// take - забирает токен; если его нет, возвращает, через сколько он появится
+func (b *bucket) take(now time.Time) (time.Duration, bool) {
+	b.tokens = b.available(now)
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+
+		return 0, true
+	}
+
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second)), false
+}
+
+func (b *bucket) full(now time.Time) bool {
+	return b.available(now) >= b.burst
+}
+
+func (b *bucket) available(now time.Time) float64 {
+	return min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+}
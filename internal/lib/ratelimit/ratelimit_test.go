@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"expvar"
+	"sso/internal/config"
+	"sso/internal/lib/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	register = "/auth.Auth/Register"
+	login    = "/auth.Auth/Login"
+	validate = "/auth.Auth/ValidateToken"
+)
+
+// newLimiter - Limiter с часами, которые двигает тест
+func newLimiter(cfg config.RateLimitConfig) (*Limiter, *clock.Fake) {
+	now := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	return New(cfg, WithClock(now)), now
+}
+
+func allowN(l *Limiter, method string, appID, n int) int {
+	allowed := 0
+	for range n {
+		if _, ok := l.Allow(method, appID); ok {
+			allowed++
+		}
+	}
+
+	return allowed
+}
+
+func TestLimiter_MethodRules(t *testing.T) {
+	l, now := newLimiter(config.RateLimitConfig{
+		Enabled: true,
+		Default: config.RateLimit{Rate: 100, Burst: 100},
+		Rules:   []config.RateLimitRule{{Method: register, Rate: 0.5, Burst: 2}},
+	})
+
+	assert.Equal(t, 2, allowN(l, register, 0, 5))
+	assert.Equal(t, 100, allowN(l, validate, 0, 100), "default bucket is separate from register")
+
+	retryAfter, ok := l.Allow(register, 0)
+	require.False(t, ok)
+	assert.Equal(t, 2*time.Second, retryAfter)
+
+	now.Advance(2 * time.Second)
+	assert.Equal(t, 1, allowN(l, register, 0, 2))
+	counter, ok := rejected.Get(register).(*expvar.Int)
+	require.True(t, ok)
+	assert.Positive(t, counter.Value())
+}
+
+func TestLimiter_PerApp(t *testing.T) {
+	l, _ := newLimiter(config.RateLimitConfig{
+		Enabled: true,
+		Rules: []config.RateLimitRule{
+			{Method: login, PerApp: true, Rate: 1, Burst: 3},
+			{Method: login, AppID: 9, Rate: 1, Burst: 10},
+		},
+	})
+
+	// Шумное приложение не съедает лимит остальных
+	assert.Equal(t, 3, allowN(l, login, 1, 10))
+	assert.Equal(t, 3, allowN(l, login, 2, 10))
+
+	// Своё правило для приложения важнее общего
+	assert.Equal(t, 10, allowN(l, login, 9, 20))
+
+	// Без app_id - общая корзина метода
+	assert.Equal(t, 3, allowN(l, login, 0, 10))
+
+	// Метод без правила и без лимита по умолчанию не ограничен
+	assert.Equal(t, 50, allowN(l, validate, 1, 50))
+}
+
+func TestLimiter_Configure(t *testing.T) {
+	l, _ := newLimiter(config.RateLimitConfig{})
+	assert.Equal(t, 10, allowN(l, register, 0, 10), "disabled limiter allows everything")
+
+	l.Configure(config.RateLimitConfig{
+		Enabled: true,
+		Rules:   []config.RateLimitRule{{Method: register, Rate: 1, Burst: 1}},
+	})
+	assert.Equal(t, 1, allowN(l, register, 0, 10))
+
+	l.Configure(config.RateLimitConfig{
+		Enabled: true,
+		Rules:   []config.RateLimitRule{{Method: register, Rate: 1, Burst: 5}},
+	})
+	assert.Equal(t, 5, allowN(l, register, 0, 10), "new limits apply with fresh buckets")
+}
+
+func TestLimiter_BucketLimit(t *testing.T) {
+	l, now := newLimiter(config.RateLimitConfig{
+		Enabled: true,
+		Rules:   []config.RateLimitRule{{Method: login, PerApp: true, Rate: 1, Burst: 1}},
+	})
+
+	for app := 1; app <= maxBuckets; app++ {
+		_, ok := l.Allow(login, app)
+		require.True(t, ok)
+	}
+
+	// Корзин больше не заводится: новые app_id делят одну
+	assert.Equal(t, 1, allowN(l, login, maxBuckets+1, 1))
+	assert.Equal(t, 0, allowN(l, login, maxBuckets+2, 1))
+	assert.Len(t, l.buckets, maxBuckets+1)
+
+	// Пополнившиеся корзины освобождают место
+	now.Advance(time.Second)
+	assert.Equal(t, 1, allowN(l, login, maxBuckets+3, 1))
+	assert.Len(t, l.buckets, 1)
+}
@@ -0,0 +1,152 @@
+// Package redact - маскирование секретов в protobuf-сообщениях перед записью в лог.
+//
+// Секретные поля определяются по имени, а не по типу сообщения: поле любого сообщения, в имени которого
+// есть одна из частей реестра (password, secret, token, code), маскируется. Так новые методы не нужно
+// регистрировать отдельно. Вложенные сообщения, списки и словари обходятся рекурсивно.
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Mask - значение, которым заменяются секретные строки
+const Mask = "[redacted]"
+
+// Registry - части имён секретных полей. Безопасен для конкурентного использования
+type Registry struct {
+	mu    sync.RWMutex
+	parts []string
+}
+
+// Default - реестр по умолчанию: пароли, секреты, токены и коды подтверждения
+var Default = NewRegistry("password", "secret", "token", "code")
+
+// NewRegistry - реестр с частями имён parts (регистр не важен)
+func NewRegistry(parts ...string) *Registry {
+	r := &Registry{}
+	r.Register(parts...)
+
+	return r
+}
+
+// Register - добавляет части имён секретных полей
+func (r *Registry) Register(parts ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, p := range parts {
+		r.parts = append(r.parts, strings.ToLower(p))
+	}
+}
+
+// Secret - секретное ли поле с таким именем
+func (r *Registry) Secret(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	name = strings.ToLower(name)
+	for _, p := range r.parts {
+		if strings.Contains(name, p) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Message - копия msg с замаскированными секретными полями; msg не меняется.
+// Строки (и строки в списках и значениях словарей) заменяются на Mask, поля других типов очищаются
+func (r *Registry) Message(msg proto.Message) proto.Message {
+	if msg == nil {
+		return nil
+	}
+
+	c := proto.Clone(msg)
+	r.mask(c.ProtoReflect())
+
+	return c
+}
+
+// JSON - msg в компактном JSON с замаскированными секретными полями (для лога)
+func (r *Registry) JSON(msg proto.Message) string {
+	b, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(r.Message(msg))
+	if err != nil {
+		return "<" + err.Error() + ">"
+	}
+
+	// protojson намеренно добавляет случайные пробелы; в логе строки должны быть одинаковыми
+	var out bytes.Buffer
+	if err := json.Compact(&out, b); err != nil {
+		return string(b)
+	}
+
+	return out.String()
+}
+
+func (r *Registry) mask(m protoreflect.Message) {
+	// Поля собираются заранее: менять сообщение внутри Range нельзя
+	var fields []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		fields = append(fields, fd)
+		return true
+	})
+
+	for _, fd := range fields {
+		switch {
+		case r.Secret(string(fd.Name())):
+			maskField(m, fd)
+		case fd.IsMap():
+			if isMessage(fd.MapValue()) {
+				m.Mutable(fd).Map().Range(func(_ protoreflect.MapKey, v protoreflect.Value) bool {
+					r.mask(v.Message())
+					return true
+				})
+			}
+		case fd.IsList():
+			if isMessage(fd) {
+				list := m.Mutable(fd).List()
+				for i := range list.Len() {
+					r.mask(list.Get(i).Message())
+				}
+			}
+		case isMessage(fd):
+			r.mask(m.Mutable(fd).Message())
+		}
+	}
+}
+
+// maskField - заменяет строки на Mask; поля других типов (числа, байты, сообщения) очищает
+func maskField(m protoreflect.Message, fd protoreflect.FieldDescriptor) {
+	switch {
+	case fd.IsMap() && fd.MapValue().Kind() == protoreflect.StringKind:
+		mp := m.Mutable(fd).Map()
+		var keys []protoreflect.MapKey
+		mp.Range(func(k protoreflect.MapKey, _ protoreflect.Value) bool {
+			keys = append(keys, k)
+			return true
+		})
+		for _, k := range keys {
+			mp.Set(k, protoreflect.ValueOfString(Mask))
+		}
+	case fd.IsList() && fd.Kind() == protoreflect.StringKind:
+		list := m.Mutable(fd).List()
+		for i := range list.Len() {
+			list.Set(i, protoreflect.ValueOfString(Mask))
+		}
+	case !fd.IsMap() && !fd.IsList() && fd.Kind() == protoreflect.StringKind:
+		m.Set(fd, protoreflect.ValueOfString(Mask))
+	default:
+		m.Clear(fd)
+	}
+}
+
+func isMessage(fd protoreflect.FieldDescriptor) bool {
+	return fd.Kind() == protoreflect.MessageKind || fd.Kind() == protoreflect.GroupKind
+}
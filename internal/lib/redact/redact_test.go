@@ -0,0 +1,86 @@
+package redact
+
+import (
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessage_Default(t *testing.T) {
+	req := &ssov1.LoginRequest{Email: "a@b.c", Password: "hunter2", AppId: 1, CaptchaToken: "captcha"}
+
+	masked, ok := Default.Message(req).(*ssov1.LoginRequest)
+	require.True(t, ok)
+	assert.Equal(t, "a@b.c", masked.GetEmail())
+	assert.Equal(t, Mask, masked.GetPassword())
+	assert.Equal(t, Mask, masked.GetCaptchaToken())
+	assert.Equal(t, int32(1), masked.GetAppId())
+
+	// исходное сообщение не меняется
+	assert.Equal(t, "hunter2", req.GetPassword())
+}
+
+func TestMessage_UnsetSecretStaysUnset(t *testing.T) {
+	masked := Default.Message(&ssov1.LoginResponse{Token: "jwt"}).(*ssov1.LoginResponse)
+
+	assert.Equal(t, Mask, masked.GetToken())
+	assert.Empty(t, masked.GetMfaChallengeToken())
+}
+
+func TestMessage_RepeatedAndNested(t *testing.T) {
+	r := NewRegistry("email", "expires_at")
+
+	resp := &ssov1.ListInvitationsResponse{
+		Invitations: []*ssov1.Invitation{
+			{Id: 1, Email: "a@b.c", ExpiresAt: 100},
+			{Id: 2, Email: "d@e.f", ExpiresAt: 200},
+		},
+	}
+
+	masked := r.Message(resp).(*ssov1.ListInvitationsResponse)
+	require.Len(t, masked.GetInvitations(), 2)
+	for i, inv := range masked.GetInvitations() {
+		assert.Equal(t, int64(i+1), inv.GetId())
+		assert.Equal(t, Mask, inv.GetEmail())
+		assert.Zero(t, inv.GetExpiresAt(), "secret fields of other types are cleared")
+	}
+	assert.Equal(t, "a@b.c", resp.GetInvitations()[0].GetEmail())
+}
+
+func TestMessage_MapValues(t *testing.T) {
+	r := NewRegistry("email")
+
+	resp := &ssov1.GetUsersBatchResponse{Users: map[int64]*ssov1.UserInfo{
+		1: {Exists: true, Email: "a@b.c"},
+	}}
+
+	masked := r.Message(resp).(*ssov1.GetUsersBatchResponse)
+	assert.Equal(t, Mask, masked.GetUsers()[1].GetEmail())
+	assert.True(t, masked.GetUsers()[1].GetExists())
+}
+
+func TestMessage_RepeatedSecretStrings(t *testing.T) {
+	r := NewRegistry("scopes")
+
+	masked := r.Message(&ssov1.GrantConsentRequest{Scopes: []string{"email", "profile"}}).(*ssov1.GrantConsentRequest)
+	assert.Equal(t, []string{Mask, Mask}, masked.GetScopes())
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry("password")
+	assert.True(t, r.Secret("old_Password"))
+	assert.False(t, r.Secret("phone"))
+
+	r.Register("PHONE")
+	assert.True(t, r.Secret("phone"))
+}
+
+func TestJSON(t *testing.T) {
+	out := Default.JSON(&ssov1.RegisterRequest{Email: "a@b.c", Password: "hunter2"})
+
+	assert.Contains(t, out, `"email":"a@b.c"`)
+	assert.Contains(t, out, `"password":"[redacted]"`)
+	assert.NotContains(t, out, "hunter2")
+}
@@ -0,0 +1,58 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+)
+
+// Header - ключ метаданных gRPC с идентификатором запроса
+const Header = "x-request-id"
+
+// TraceparentHeader - ключ метаданных W3C Trace Context
+const TraceparentHeader = "traceparent"
+
+type ctxKey struct{}
+
+type traceCtxKey struct{}
+
+// Into - кладёт идентификатор запроса в контекст
+func Into(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, id)
+}
+
+// From - достаёт идентификатор запроса из контекста (пустая строка, если его нет)
+func From(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKey{}).(string)
+
+	return id
+}
+
+// TraceIntoContext - кладёт trace id вызывающей стороны в контекст
+func TraceIntoContext(ctx context.Context, traceID string) context.Context {
+	return context.WithValue(ctx, traceCtxKey{}, traceID)
+}
+
+// TraceFrom - достаёт trace id из контекста (пустая строка, если его нет)
+func TraceFrom(ctx context.Context) string {
+	id, _ := ctx.Value(traceCtxKey{}).(string)
+
+	return id
+}
+
+// traceparentRe - формат traceparent: версия-trace_id-parent_id-флаги
+var traceparentRe = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+
+// ParseTraceparent - разбирает заголовок W3C traceparent и возвращает trace id и parent span id.
+// Некорректные значения и нулевые идентификаторы отбрасываются, как требует спецификация
+func ParseTraceparent(v string) (traceID, parentID string, ok bool) {
+	m := traceparentRe.FindStringSubmatch(v)
+	if m == nil || m[1] == "ff" {
+		return "", "", false
+	}
+
+	if m[2] == "00000000000000000000000000000000" || m[3] == "0000000000000000" {
+		return "", "", false
+	}
+
+	return m[2], m[3], true
+}
@@ -0,0 +1,30 @@
+package requestid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tests := []struct {
+		in     string
+		wantOK bool
+	}{
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", wantOK: true},
+		{in: "00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		{in: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01"},
+		{in: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		{in: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01"},
+		{in: "garbage"},
+		{in: ""},
+	}
+
+	for _, tt := range tests {
+		traceID, _, ok := ParseTraceparent(tt.in)
+		assert.Equal(t, tt.wantOK, ok, tt.in)
+		if ok {
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", traceID)
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package revocations - рассылка отзывов токенов подписчикам (поток WatchRevocations).
+//
+// Отзывы пишутся в журнал хранилища с монотонным seq; Feed опрашивает журнал и раздаёт новые записи
+// всем подписчикам. Подписчик, не успевающий читать, отключается, а не тормозит остальных:
+// он переподключается и дочитывает пропущенное из журнала по последнему полученному seq.
+package revocations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/config"
+	"sso/internal/domain/models"
+	"sync"
+	"time"
+)
+
+// pollBatch - сколько записей журнала читается за один запрос
+const pollBatch = 500
+
+// Причины отключения подписчика (Subscription.Err)
+var (
+	ErrSlowConsumer = errors.New("subscriber buffer overflow") // подписчик не успевал читать
+	ErrStopped      = errors.New("revocation feed stopped")    // сервер останавливается
+)
+
+// Store - журнал отзывов
+type Store interface {
+	// RevocationsSince - до limit отзывов с seq больше afterSeq по возрастанию seq
+	RevocationsSince(ctx context.Context, afterSeq int64, limit int) ([]models.Revocation, error)
+	// LastRevocationSeq - seq последнего отзыва (0, если журнал пуст)
+	LastRevocationSeq(ctx context.Context) (int64, error)
+}
+
+// Feed - опрос журнала отзывов и рассылка новых записей подписчикам
+type Feed struct {
+	store     Store
+	log       *slog.Logger
+	interval  time.Duration
+	buffer    int
+	keepalive time.Duration
+
+	wake chan struct{}
+
+	mu      sync.Mutex
+	last    int64 // seq последней разосланной записи
+	synced  bool  // last прочитан из журнала
+	subs    map[*Subscription]struct{}
+	running bool // Run запущен
+	stopped bool // Stop уже вызван
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// New - создаёт Feed, рассылающий подписчикам отзывы, записанные после первой подписки или запуска
+// (историю подписчики дочитывают сами)
+func New(log *slog.Logger, store Store, cfg config.RevocationsConfig) *Feed {
+	return &Feed{
+		store:     store,
+		log:       log.With(slog.String("component", "revocation feed")),
+		interval:  cfg.PollInterval,
+		buffer:    cfg.BufferSize,
+		keepalive: cfg.KeepaliveInterval,
+		wake:      make(chan struct{}, 1),
+		subs:      make(map[*Subscription]struct{}),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Subscription - подписка на новые отзывы
+type Subscription struct {
+	feed   *Feed
+	c      chan models.Revocation
+	closed chan struct{}
+	reason error
+}
+
+// C - новые отзывы по возрастанию seq. Подписка может начаться раньше, чем разосланы уже записанные
+// отзывы, поэтому подписчик сам отбрасывает записи с seq не больше уже полученного
+func (s *Subscription) C() <-chan models.Revocation {
+	return s.c
+}
+
+// Closed - закрывается, когда Feed отключил подписчика (Err - почему)
+func (s *Subscription) Closed() <-chan struct{} {
+	return s.closed
+}
+
+// Err - причина отключения: ErrSlowConsumer или ErrStopped
+func (s *Subscription) Err() error {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	return s.reason
+}
+
+// Close - отписывается от Feed
+func (s *Subscription) Close() {
+	s.feed.mu.Lock()
+	defer s.feed.mu.Unlock()
+
+	delete(s.feed.subs, s)
+}
+
+// Subscribe - подписывает на отзывы, записанные после последней разосланной записи.
+// После Stop подписка сразу закрыта с ErrStopped
+func (f *Feed) Subscribe(ctx context.Context) (*Subscription, error) {
+	const op = "revocations.Feed.Subscribe"
+
+	s := &Subscription{feed: f, c: make(chan models.Revocation, f.buffer), closed: make(chan struct{})}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.stopped {
+		s.reason = ErrStopped
+		close(s.closed)
+
+		return s, nil
+	}
+	if err := f.sync(ctx); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	f.subs[s] = struct{}{}
+
+	return s, nil
+}
+
+// sync - при первом вызове ставит позицию рассылки на конец журнала; вызывается под f.mu.
+// Позиция не может перескочить записи, которые подписчик ещё не дочитал: её читает первая подписка
+// (до того, как начнёт дочитывать историю) или опрос, пока подписчиков нет
+func (f *Feed) sync(ctx context.Context) error {
+	if f.synced {
+		return nil
+	}
+
+	last, err := f.store.LastRevocationSeq(ctx)
+	if err != nil {
+		return err
+	}
+	f.last = last
+	f.synced = true
+
+	return nil
+}
+
+// RevocationsSince - до limit записей журнала с seq больше afterSeq (дочитывание после переподключения)
+func (f *Feed) RevocationsSince(ctx context.Context, afterSeq int64, limit int) ([]models.Revocation, error) {
+	return f.store.RevocationsSince(ctx, afterSeq, limit)
+}
+
+// KeepaliveInterval - как часто поток без новых отзывов шлёт пинг
+func (f *Feed) KeepaliveInterval() time.Duration {
+	return f.keepalive
+}
+
+// Notify - будит опрос журнала, не дожидаясь interval (вызывается после записи отзыва)
+func (f *Feed) Notify() {
+	select {
+	case f.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run - опрашивает журнал, пока не вызван Stop
+func (f *Feed) Run() error {
+	f.mu.Lock()
+	if f.stopped {
+		f.mu.Unlock()
+
+		return nil
+	}
+	f.running = true
+	f.mu.Unlock()
+
+	defer close(f.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		select {
+		case <-f.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		case <-f.wake:
+		}
+
+		// Полная пачка - скорее всего, есть ещё, поэтому не ждём следующего тика
+		for n := pollBatch; n == pollBatch && ctx.Err() == nil; {
+			n = f.broadcastBatch(ctx)
+		}
+	}
+}
+
+// Stop - останавливает опрос и отключает всех подписчиков с ErrStopped.
+// Вызывается до остановки gRPC-сервера: иначе он ждал бы завершения бесконечных потоков
+func (f *Feed) Stop() {
+	f.mu.Lock()
+	if !f.stopped {
+		f.stopped = true
+		close(f.stop)
+
+		for s := range f.subs {
+			f.drop(s, ErrStopped)
+		}
+	}
+	running := f.running
+	f.mu.Unlock()
+
+	if running {
+		<-f.done
+	}
+}
+
+// broadcastBatch - рассылает одну пачку новых записей и возвращает их количество
+func (f *Feed) broadcastBatch(ctx context.Context) int {
+	f.mu.Lock()
+	err := f.sync(ctx)
+	last := f.last
+	f.mu.Unlock()
+	if err != nil {
+		if ctx.Err() == nil {
+			f.log.Error("failed to read revocation log position", slog.String("error", err.Error()))
+		}
+
+		return 0
+	}
+
+	batch, err := f.store.RevocationsSince(ctx, last, pollBatch)
+	if err != nil {
+		if ctx.Err() == nil {
+			f.log.Error("failed to read revocation log", slog.String("error", err.Error()))
+		}
+
+		return 0
+	}
+	if len(batch) == 0 {
+		return 0
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, r := range batch {
+		for s := range f.subs {
+			select {
+			case s.c <- r:
+			default:
+				f.log.Warn("disconnecting slow revocation subscriber", slog.Int64("seq", r.Seq))
+				f.drop(s, ErrSlowConsumer)
+			}
+		}
+	}
+	f.last = batch[len(batch)-1].Seq
+
+	return len(batch)
+}
+
+// drop - отключает подписчика; вызывается под f.mu
+func (f *Feed) drop(s *Subscription, reason error) {
+	delete(f.subs, s)
+	s.reason = reason
+	close(s.closed)
+}
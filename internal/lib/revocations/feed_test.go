@@ -0,0 +1,138 @@
+package revocations
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sso/internal/config"
+	"sso/internal/domain/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memLog - журнал отзывов в памяти
+type memLog struct {
+	mu   sync.Mutex
+	rows []models.Revocation
+}
+
+func (m *memLog) add(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for range n {
+		m.rows = append(m.rows, models.Revocation{Seq: int64(len(m.rows) + 1), Kind: models.RevocationUser, UserID: 7})
+	}
+}
+
+func (m *memLog) RevocationsSince(_ context.Context, afterSeq int64, limit int) ([]models.Revocation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var res []models.Revocation
+	for _, r := range m.rows {
+		if r.Seq > afterSeq && len(res) < limit {
+			res = append(res, r)
+		}
+	}
+
+	return res, nil
+}
+
+func (m *memLog) LastRevocationSeq(context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return int64(len(m.rows)), nil
+}
+
+func newFeed(t *testing.T, store Store, buffer int) *Feed {
+	t.Helper()
+
+	f := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, config.RevocationsConfig{
+		PollInterval: time.Hour, KeepaliveInterval: time.Minute, BufferSize: buffer,
+	})
+	go func() { _ = f.Run() }()
+	t.Cleanup(f.Stop)
+
+	return f
+}
+
+func TestFeed_BroadcastsNewRevocations(t *testing.T) {
+	store := &memLog{}
+	store.add(3) // история не рассылается: её подписчик дочитывает сам
+	f := newFeed(t, store, 10)
+
+	sub, err := f.Subscribe(context.Background())
+	require.NoError(t, err)
+	defer sub.Close()
+
+	store.add(2)
+	f.Notify()
+
+	for _, want := range []int64{4, 5} {
+		select {
+		case r := <-sub.C():
+			assert.Equal(t, want, r.Seq)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("revocation %d was not delivered", want)
+		}
+	}
+}
+
+func TestFeed_DisconnectsSlowConsumer(t *testing.T) {
+	store := &memLog{}
+	f := newFeed(t, store, 2)
+
+	slow, err := f.Subscribe(context.Background())
+	require.NoError(t, err)
+	fast, err := f.Subscribe(context.Background())
+	require.NoError(t, err)
+	defer fast.Close()
+
+	// fast читает всё, slow - ничего
+	got := make(chan int64, 10)
+	go func() {
+		for r := range fast.C() {
+			got <- r.Seq
+		}
+	}()
+
+	for i := range 3 {
+		store.add(1)
+		f.Notify()
+		select {
+		case seq := <-got:
+			assert.Equal(t, int64(i+1), seq)
+		case <-time.After(5 * time.Second):
+			t.Fatal("fast subscriber was blocked by the slow one")
+		}
+	}
+
+	select {
+	case <-slow.Closed():
+		require.ErrorIs(t, slow.Err(), ErrSlowConsumer)
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow subscriber was not disconnected")
+	}
+}
+
+func TestFeed_StopClosesSubscriptions(t *testing.T) {
+	f := newFeed(t, &memLog{}, 1)
+
+	sub, err := f.Subscribe(context.Background())
+	require.NoError(t, err)
+
+	f.Stop()
+	<-sub.Closed()
+	require.ErrorIs(t, sub.Err(), ErrStopped)
+
+	late, err := f.Subscribe(context.Background())
+	require.NoError(t, err)
+	<-late.Closed()
+	require.ErrorIs(t, late.Err(), ErrStopped)
+}
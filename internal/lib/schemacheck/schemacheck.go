@@ -0,0 +1,152 @@
+// Package schemacheck - совместима ли схема БД с запущенной сборкой.
+//
+// Сборка ожидает версию схемы, равную последней встроенной миграции (migrations.Latest). На более
+// старой схеме первый же запрос к новой колонке упал бы, поэтому сервис сообщает, что не готов
+// (health NOT_SERVING, /readyz 503), пока миграции не применят. На более новой схеме (откат бинарника
+// без отката миграций) сервис по умолчанию работает с предупреждением.
+package schemacheck
+
+import (
+	"context"
+	"log/slog"
+	"sso/internal/config"
+	"sso/migrations"
+	"sync"
+	"time"
+)
+
+// checkTimeout - сколько ждать ответа БД при одной проверке
+const checkTimeout = 5 * time.Second
+
+// Source - версия схемы из таблицы миграций
+type Source interface {
+	SchemaVersion(ctx context.Context) (version int64, dirty bool, err error)
+}
+
+// Status - результат последней проверки
+type Status struct {
+	Expected int64 // версия, которую ожидает сборка
+	Current  int64 // версия в БД (0 - неизвестна)
+	Dirty    bool  // миграция была прервана
+	Err      error // версию не удалось прочитать (например, БД не мигрирована)
+	Ready    bool  // можно обслуживать запросы
+}
+
+// Checker - периодическая проверка версии схемы. Run/Stop - как у остальных фоновых компонентов App
+type Checker struct {
+	source      Source
+	expected    int64
+	refuseNewer bool
+	interval    time.Duration
+	log         *slog.Logger
+	onChange    func(ready bool)
+
+	mu     sync.Mutex
+	status Status
+	known  bool // проверка уже выполнялась
+
+	stopOnce sync.Once
+	stop     chan struct{}
+}
+
+// New - проверка версии схемы в source против expected (обычно migrations.Latest()).
+// onChange вызывается при каждом изменении готовности и после первой проверки (может быть nil)
+func New(source Source, expected int64, cfg config.SchemaConfig, log *slog.Logger, onChange func(ready bool)) *Checker {
+	return &Checker{
+		source:      source,
+		expected:    expected,
+		refuseNewer: cfg.RefuseNewer,
+		interval:    cfg.CheckInterval,
+		log:         log.With(slog.String("component", "schema check")),
+		onChange:    onChange,
+		stop:        make(chan struct{}),
+	}
+}
+
+// Check - читает версию схемы, обновляет статус и логирует его изменение
+func (c *Checker) Check(ctx context.Context) Status {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	st := Status{Expected: c.expected}
+	st.Current, st.Dirty, st.Err = c.source.SchemaVersion(ctx)
+	st.Ready = st.Err == nil && !st.Dirty && st.Current >= c.expected && (!c.refuseNewer || st.Current == c.expected)
+
+	c.mu.Lock()
+	prev, known := c.status, c.known
+	c.status, c.known = st, true
+	c.mu.Unlock()
+
+	if known && prev.Ready == st.Ready && prev.Current == st.Current && prev.Dirty == st.Dirty && (prev.Err == nil) == (st.Err == nil) {
+		return st
+	}
+
+	c.logStatus(st)
+	if c.onChange != nil && (!known || prev.Ready != st.Ready) {
+		c.onChange(st.Ready)
+	}
+
+	return st
+}
+
+// Status - результат последней проверки (до первой проверки сервис не готов)
+func (c *Checker) Status() Status {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.known {
+		return Status{Expected: c.expected}
+	}
+
+	return c.status
+}
+
+// Ready - можно ли обслуживать запросы по результату последней проверки
+func (c *Checker) Ready() bool {
+	return c.Status().Ready
+}
+
+// Run - перепроверяет версию каждые interval, пока не вызван Stop: после миграции сервис становится
+// готов без перезапуска
+func (c *Checker) Run() error {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return nil
+		case <-ticker.C:
+			c.Check(context.Background())
+		}
+	}
+}
+
+// Stop - останавливает Run
+func (c *Checker) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+func (c *Checker) logStatus(st Status) {
+	log := c.log.With(slog.Int64("schema_version", st.Current), slog.Int64("expected_schema_version", st.Expected))
+
+	switch {
+	case st.Err != nil:
+		log.Error("cannot read schema version, not serving", slog.String("error", st.Err.Error()))
+	case st.Dirty:
+		log.Error("schema migration was interrupted, not serving: fix the database and rerun the migrator")
+	case st.Current < st.Expected:
+		missing := make([]string, 0, st.Expected-st.Current)
+		for _, m := range migrations.Pending(st.Current) {
+			missing = append(missing, m.Name)
+		}
+		log.Error("schema is older than this build, not serving: apply the missing migrations",
+			slog.Any("missing_migrations", missing))
+	case st.Current > st.Expected && c.refuseNewer:
+		log.Error("schema is newer than this build, not serving (schema.refuse_newer)")
+	case st.Current > st.Expected:
+		log.Warn("schema is newer than this build: migrations after the expected version may be incompatible")
+	default:
+		log.Info("schema version is compatible")
+	}
+}
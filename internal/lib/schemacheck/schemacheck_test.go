@@ -0,0 +1,111 @@
+package schemacheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sso/internal/config"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSource struct {
+	mu      sync.Mutex
+	version int64
+	dirty   bool
+	err     error
+}
+
+func (f *fakeSource) SchemaVersion(context.Context) (int64, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.version, f.dirty, f.err
+}
+
+func (f *fakeSource) set(version int64) {
+	f.mu.Lock()
+	f.version = version
+	f.mu.Unlock()
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name        string
+		source      *fakeSource
+		refuseNewer bool
+		ready       bool
+		log         string
+	}{
+		{name: "same", source: &fakeSource{version: 5}, ready: true, log: "schema version is compatible"},
+		{name: "older", source: &fakeSource{version: 3}, log: "schema is older than this build"},
+		{name: "newer", source: &fakeSource{version: 6}, ready: true, log: "schema is newer than this build"},
+		{name: "newer refused", source: &fakeSource{version: 6}, refuseNewer: true, log: "schema.refuse_newer"},
+		{name: "dirty", source: &fakeSource{version: 5, dirty: true}, log: "migration was interrupted"},
+		{name: "not migrated", source: &fakeSource{err: errors.New("no such table: migrations")}, log: "cannot read schema version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			var changes []bool
+			c := New(tt.source, 5, config.SchemaConfig{RefuseNewer: tt.refuseNewer, CheckInterval: time.Minute},
+				slog.New(slog.NewTextHandler(&buf, nil)), func(ready bool) { changes = append(changes, ready) })
+
+			assert.False(t, c.Ready(), "not ready before the first check")
+
+			st := c.Check(context.Background())
+			assert.Equal(t, tt.ready, st.Ready)
+			assert.Equal(t, tt.ready, c.Ready())
+			assert.Equal(t, int64(5), st.Expected)
+			assert.Contains(t, buf.String(), tt.log)
+			assert.Equal(t, []bool{tt.ready}, changes)
+
+			// Повторная проверка с тем же результатом не логируется и не меняет статус health
+			buf.Reset()
+			c.Check(context.Background())
+			assert.Empty(t, buf.String())
+			assert.Len(t, changes, 1)
+		})
+	}
+}
+
+func TestCheck_ListsMissingMigrations(t *testing.T) {
+	var buf bytes.Buffer
+	c := New(&fakeSource{version: 1}, 3, config.SchemaConfig{CheckInterval: time.Minute}, slog.New(slog.NewTextHandler(&buf, nil)), nil)
+
+	c.Check(context.Background())
+	assert.Contains(t, buf.String(), "2_")
+}
+
+// После миграции сервис становится готов без перезапуска
+func TestRun_BecomesReady(t *testing.T) {
+	source := &fakeSource{version: 4}
+	changed := make(chan bool, 2)
+	c := New(source, 5, config.SchemaConfig{CheckInterval: 10 * time.Millisecond},
+		slog.New(slog.NewTextHandler(io.Discard, nil)), func(ready bool) { changed <- ready })
+
+	c.Check(context.Background())
+	require.False(t, <-changed)
+
+	done := make(chan error, 1)
+	go func() { done <- c.Run() }()
+
+	source.set(5)
+	select {
+	case ready := <-changed:
+		assert.True(t, ready)
+	case <-time.After(5 * time.Second):
+		t.Fatal("checker did not notice the migration")
+	}
+
+	c.Stop()
+	require.NoError(t, <-done)
+	c.Stop()
+}
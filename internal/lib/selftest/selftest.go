@@ -0,0 +1,19 @@
+// Package selftest - пометка запросов самопроверки (auth.RunSelfTest). Помеченный контекст ставит только
+// сервер: из метаданных запроса его получить нельзя
+package selftest
+
+import "context"
+
+type ctxKey struct{}
+
+// Into - помечает контекст как запрос самопроверки
+func Into(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKey{}, true)
+}
+
+// From - выполняется ли запрос в рамках самопроверки
+func From(ctx context.Context) bool {
+	on, _ := ctx.Value(ctxKey{}).(bool)
+
+	return on
+}
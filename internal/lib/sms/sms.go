@@ -0,0 +1,47 @@
+// Package sms - отправка SMS с кодами входа.
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+)
+
+// Provider - отправляет одно SMS на номер phone в формате E.164
+type Provider interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// New - создаёт отправителя по настройкам sms.*; client нужен только драйверу twilio
+func New(cfg config.SMSConfig, client *httpx.Client, log *slog.Logger) (Provider, error) {
+	switch cfg.Driver {
+	case config.SMSDriverTwilio:
+		return NewTwilio(cfg.Twilio, client), nil
+	case config.SMSDriverLog:
+		return NewLog(log), nil
+	default:
+		return nil, fmt.Errorf("sms.New: unknown driver %q", cfg.Driver)
+	}
+}
+
+// Log - отправитель для локального окружения: пишет SMS в лог вместо отправки
+type Log struct {
+	log *slog.Logger
+}
+
+// NewLog - создаёт отправитель, пишущий SMS в лог
+func NewLog(log *slog.Logger) *Log {
+	return &Log{log: log}
+}
+
+// Send - пишет SMS в лог целиком, чтобы из него можно было взять код
+func (l *Log) Send(ctx context.Context, phone, message string) error {
+	l.log.InfoContext(ctx, "sms (not sent, log driver)",
+		slog.String("to", phone),
+		slog.String("message", message),
+	)
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+	"strings"
+)
+
+// maxErrorBody - сколько байт ответа с ошибкой читать ради сообщения провайдера
+const maxErrorBody = 4 << 10
+
+// Twilio - отправка через Messages API Twilio (или совместимого провайдера).
+// POST не повторяется при ошибках (см. httpx): повтор мог бы отправить пользователю второе платное SMS
+type Twilio struct {
+	endpoint   string
+	accountSID string
+	authToken  string
+	from       string
+	client     *httpx.Client
+}
+
+// NewTwilio - создаёт отправителя по настройкам sms.twilio.*; client - клиент исходящих
+// запросов с таймаутом cfg.Timeout
+func NewTwilio(cfg config.TwilioConfig, client *httpx.Client) *Twilio {
+	return &Twilio{
+		endpoint:   strings.TrimSuffix(cfg.Endpoint, "/"),
+		accountSID: cfg.AccountSID,
+		authToken:  cfg.AuthToken.Reveal(),
+		from:       cfg.From,
+		client:     client,
+	}
+}
+
+// Send - POST /2010-04-01/Accounts/{AccountSid}/Messages.json
+func (t *Twilio) Send(ctx context.Context, phone, message string) error {
+	const op = "sms.Twilio.Send"
+
+	form := url.Values{"To": {phone}, "Body": {message}}
+	// Отправитель - номер или Messaging Service (пул номеров провайдера)
+	if strings.HasPrefix(t.from, "MG") {
+		form.Set("MessagingServiceSid", t.from)
+	} else {
+		form.Set("From", t.from)
+	}
+
+	endpoint := t.endpoint + "/2010-04-01/Accounts/" + url.PathEscape(t.accountSID) + "/Messages.json"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(t.accountSID, t.authToken)
+
+	resp, err := t.client.Do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 == 2 {
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		return nil
+	}
+
+	// Ошибка Twilio: {"code": 21211, "message": "The 'To' number is not a valid phone number.", ...}
+	var apiErr struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBody)).Decode(&apiErr); err == nil && apiErr.Message != "" {
+		return fmt.Errorf("%s: unexpected status %s: %d %s", op, resp.Status, apiErr.Code, apiErr.Message)
+	}
+
+	return fmt.Errorf("%s: unexpected status %s", op, resp.Status)
+}
@@ -0,0 +1,75 @@
+package sms
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sso/internal/config"
+	"sso/internal/lib/httpx"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTwilio(t *testing.T, from string, handler http.HandlerFunc) *Twilio {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	outbound, err := httpx.NewFactory(config.OutboundConfig{})
+	require.NoError(t, err)
+
+	return NewTwilio(config.TwilioConfig{
+		Endpoint: srv.URL + "/", AccountSID: "AC123", AuthToken: "token", From: from,
+	}, outbound.Client("sms", time.Second))
+}
+
+func TestTwilio_Send(t *testing.T) {
+	tw := newTwilio(t, "+15005550006", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/2010-04-01/Accounts/AC123/Messages.json", r.URL.Path)
+
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "AC123", user)
+		assert.Equal(t, "token", pass)
+
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "+79123456789", r.PostForm.Get("To"))
+		assert.Equal(t, "+15005550006", r.PostForm.Get("From"))
+		assert.Equal(t, "123456 is your code", r.PostForm.Get("Body"))
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"sid": "SM1", "status": "queued"}`))
+	})
+
+	require.NoError(t, tw.Send(context.Background(), "+79123456789", "123456 is your code"))
+}
+
+func TestTwilio_MessagingService(t *testing.T) {
+	tw := newTwilio(t, "MG42", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "MG42", r.PostForm.Get("MessagingServiceSid"))
+		assert.Empty(t, r.PostForm.Get("From"))
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	require.NoError(t, tw.Send(context.Background(), "+79123456789", "code"))
+}
+
+func TestTwilio_Error(t *testing.T) {
+	var requests int
+	tw := newTwilio(t, "+15005550006", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"code": 20503, "message": "Service unavailable", "status": 503}`))
+	})
+
+	err := tw.Send(context.Background(), "+79123456789", "code")
+	require.ErrorContains(t, err, "20503 Service unavailable")
+	// POST не повторяется: повтор отправил бы второе SMS
+	assert.Equal(t, 1, requests)
+}
@@ -0,0 +1,55 @@
+// Package useragent - User-Agent клиента в контексте запроса и отпечаток устройства по нему.
+//
+// Отпечаток грубый: разные устройства с одинаковым браузером неразличимы, а обновление браузера
+// даёт новое "устройство". Для уведомлений о входе этого достаточно, для защиты - нет
+package useragent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength - сколько символов User-Agent сохраняется в контексте: остальное клиент мог дописать,
+// чтобы раздуть логи и письма
+const MaxLength = 256
+
+type ctxKey struct{}
+
+// Into - кладёт User-Agent клиента в контекст
+func Into(ctx context.Context, userAgent string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, truncate(strings.TrimSpace(userAgent)))
+}
+
+// From - User-Agent клиента из контекста (пусто, если его нет)
+func From(ctx context.Context) string {
+	ua, _ := ctx.Value(ctxKey{}).(string)
+
+	return ua
+}
+
+// Fingerprint - отпечаток устройства по User-Agent (пусто для пустого User-Agent)
+func Fingerprint(userAgent string) string {
+	if userAgent == "" {
+		return ""
+	}
+
+	sum := sha256.Sum256([]byte(userAgent))
+
+	return hex.EncodeToString(sum[:8])
+}
+
+func truncate(s string) string {
+	if len(s) <= MaxLength {
+		return s
+	}
+
+	s = s[:MaxLength]
+	for !utf8.ValidString(s) {
+		s = s[:len(s)-1]
+	}
+
+	return s
+}
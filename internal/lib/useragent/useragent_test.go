@@ -0,0 +1,28 @@
+package useragent
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, From(context.Background()))
+
+	ctx := Into(context.Background(), "  Mozilla/5.0 grpc-go/1.70.0 ")
+	assert.Equal(t, "Mozilla/5.0 grpc-go/1.70.0", From(ctx))
+
+	long := From(Into(context.Background(), strings.Repeat("я", MaxLength)))
+	assert.LessOrEqual(t, len(long), MaxLength)
+	assert.True(t, utf8.ValidString(long), "truncation keeps whole characters")
+}
+
+func TestFingerprint(t *testing.T) {
+	assert.Empty(t, Fingerprint(""))
+	assert.Len(t, Fingerprint("curl/8.5.0"), 16)
+	assert.Equal(t, Fingerprint("curl/8.5.0"), Fingerprint("curl/8.5.0"))
+	assert.NotEqual(t, Fingerprint("curl/8.5.0"), Fingerprint("curl/8.6.0"))
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"time"
+)
+
+// MaxActionTokenTTL - наибольший срок действия токена одного действия; больший ttl сокращается до него
+const MaxActionTokenTTL = 5 * time.Minute
+
+// Ошибки токенов одного действия
+var (
+	ErrActionTokensDisabled = errors.New("action tokens are disabled")           // Ошибка, если не задан ключ подписи сервиса.
+	ErrInvalidAction        = errors.New("invalid action name")                  // Ошибка, если имя действия пустое или недопустимое.
+	ErrInvalidActionToken   = errors.New("invalid action token")                 // Ошибка, если токен не проверен или выдан для другого действия.
+	ErrActionTokenUsed      = errors.New("action token already used or expired") // Ошибка, если токен уже использован.
+)
+
+// actionRe - допустимое имя действия (попадает в клейм purpose)
+var actionRe = regexp.MustCompile(`^[a-z0-9][a-z0-9._:-]{0,63}$`)
+
+// ActionTokenStore - неиспользованные токены одного действия
+type ActionTokenStore interface {
+	// SaveActionToken - запоминает выданный токен.
+	SaveActionToken(ctx context.Context, t models.ActionToken) error
+	// ConsumeActionToken - забирает токен (storage.ErrActionTokenNotFound, если он использован или просрочен).
+	ConsumeActionToken(ctx context.Context, jti string) (models.ActionToken, error)
+}
+
+// WithActionTokens - включает токены одного действия, подписанные ключом сервиса secret.
+func WithActionTokens(store ActionTokenStore, secret []byte) Option {
+	return func(a *AuthService) {
+		a.actionTokens = store
+		a.actionSecret = secret
+	}
+}
+
+// IssueActionToken - выдаёт токен, разрешающий пользователю ровно одно действие action с данными payload
+// (например, подтверждение опасной операции или скачивание выгрузки). Срок действия не больше MaxActionTokenTTL.
+// Токен одноразовый и не принимается как токен доступа.
+func (a *AuthService) IssueActionToken(
+	ctx context.Context,
+	userID int64,
+	action string,
+	ttl time.Duration,
+	payload []byte,
+) (string, error) {
+	const op = "Auth.IssueActionToken"
+
+	if a.actionTokens == nil {
+		return "", fmt.Errorf("%s: %w", op, ErrActionTokensDisabled)
+	}
+	if !actionRe.MatchString(action) {
+		return "", fmt.Errorf("%s: %w: %q", op, ErrInvalidAction, action)
+	}
+	if ttl <= 0 || ttl > MaxActionTokenTTL {
+		ttl = MaxActionTokenTTL
+	}
+
+	token, claims, err := jwt.NewActionToken(userID, action, payloadHash(payload), a.clock.Now(), ttl, a.actionSecret)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.actionTokens.SaveActionToken(ctx, models.ActionToken{
+		JTI:       claims.ID,
+		UserID:    userID,
+		Action:    action,
+		Payload:   payload,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	logctx.FromOr(ctx, a.log).Info("action token issued",
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.String("action", action))
+
+	return token, nil
+}
+
+// ConsumeActionToken - проверяет токен действия action и использует его: повторно тот же токен не пройдёт.
+// Возвращает пользователя, которому выдан токен, и данные действия.
+func (a *AuthService) ConsumeActionToken(ctx context.Context, token, action string) (int64, []byte, error) {
+	const op = "Auth.ConsumeActionToken"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.String("action", action))
+
+	if a.actionTokens == nil {
+		return 0, nil, fmt.Errorf("%s: %w", op, ErrActionTokensDisabled)
+	}
+
+	// Допуск по часам не нужен: токен проверяет тот же сервис, что его выдал
+	claims, err := jwt.Parse(token, a.actionSecret, jwt.WithLeeway(0), jwt.WithClock(a.clock.Now))
+	if err != nil {
+		return 0, nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidActionToken, err)
+	}
+	if claims.Purpose == "" {
+		return 0, nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidActionToken, jwt.ErrNotActionToken)
+	}
+	if claims.Purpose != action {
+		log.Warn("action token used for another action", slog.String("purpose", claims.Purpose))
+
+		return 0, nil, fmt.Errorf("%s: %w: issued for %q", op, ErrInvalidActionToken, claims.Purpose)
+	}
+
+	t, err := a.actionTokens.ConsumeActionToken(ctx, claims.ID)
+	if err != nil {
+		if errors.Is(err, storage.ErrActionTokenNotFound) {
+			log.Warn("action token replayed", slog.Int64("user_id", claims.UID))
+
+			return 0, nil, fmt.Errorf("%s: %w", op, ErrActionTokenUsed)
+		}
+
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Подпись уже проверена, это защита от расхождения токена и записи в хранилище
+	if t.UserID != claims.UID || t.Action != claims.Purpose || payloadHash(t.Payload) != claims.PayloadHash {
+		return 0, nil, fmt.Errorf("%s: %w: token does not match stored action", op, ErrInvalidActionToken)
+	}
+
+	// Отключённый или стёртый после выдачи пользователь действие уже не подтвердит
+	user, err := a.usrProvider.UserByID(ctx, t.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return 0, nil, fmt.Errorf("%s: %w: user not found", op, ErrInvalidActionToken)
+		}
+
+		return 0, nil, fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.IsActive {
+		return 0, nil, fmt.Errorf("%s: %w: user is not active", op, ErrInvalidActionToken)
+	}
+
+	log.Info("action token consumed", slog.Int64("user_id", t.UserID))
+
+	return t.UserID, bytes.Clone(t.Payload), nil
+}
+
+// payloadHash - SHA-256 данных действия для клейма payload_hash
+func payloadHash(payload []byte) string {
+	sum := sha256.Sum256(payload)
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var actionSecret = []byte("service-signing-key")
+
+// memActionTokens - ActionTokenStore в памяти с настоящей одноразовостью
+type memActionTokens map[string]models.ActionToken
+
+func (m memActionTokens) SaveActionToken(_ context.Context, t models.ActionToken) error {
+	m[t.JTI] = t
+
+	return nil
+}
+
+func (m memActionTokens) ConsumeActionToken(_ context.Context, jti string) (models.ActionToken, error) {
+	t, ok := m[jti]
+	if !ok {
+		return models.ActionToken{}, fmt.Errorf("storage.mem.ConsumeActionToken: %w", storage.ErrActionTokenNotFound)
+	}
+	delete(m, jti)
+
+	return t, nil
+}
+
+func TestActionToken_SingleUse(t *testing.T) {
+	store := memActionTokens{}
+	a, _, provider, _ := newTestService(t, WithActionTokens(store, actionSecret))
+	ctx := context.Background()
+
+	token, err := a.IssueActionToken(ctx, 7, "export.download", time.Hour, []byte(`{"export_id":3}`))
+	require.NoError(t, err)
+
+	claims, err := jwt.Parse(token, actionSecret)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(MaxActionTokenTTL), claims.ExpiresAt.Time, 5*time.Second, "ttl is capped")
+
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, IsActive: true}, nil).Once()
+
+	userID, payload, err := a.ConsumeActionToken(ctx, token, "export.download")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), userID)
+	assert.JSONEq(t, `{"export_id":3}`, string(payload))
+
+	// Повтор того же токена
+	_, _, err = a.ConsumeActionToken(ctx, token, "export.download")
+	require.ErrorIs(t, err, ErrActionTokenUsed)
+}
+
+func TestActionToken_WrongPurpose(t *testing.T) {
+	store := memActionTokens{}
+	a, _, _, _ := newTestService(t, WithActionTokens(store, actionSecret))
+	ctx := context.Background()
+
+	token, err := a.IssueActionToken(ctx, 7, "account.delete", time.Minute, nil)
+	require.NoError(t, err)
+
+	_, _, err = a.ConsumeActionToken(ctx, token, "export.download")
+	require.ErrorIs(t, err, ErrInvalidActionToken)
+	assert.Len(t, store, 1, "token for another action is not consumed")
+}
+
+func TestActionToken_Expired(t *testing.T) {
+	a, _, _, _ := newTestService(t, WithActionTokens(memActionTokens{}, actionSecret))
+
+	token, _, err := jwt.NewActionToken(7, "export.download", payloadHash(nil), time.Now(), -time.Second, actionSecret)
+	require.NoError(t, err)
+
+	_, _, err = a.ConsumeActionToken(context.Background(), token, "export.download")
+	require.ErrorIs(t, err, ErrInvalidActionToken)
+	require.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestActionToken_NotInterchangeableWithAccessTokens(t *testing.T) {
+	// Секрет приложения совпадает с ключом сервиса, чтобы подпись не мешала проверить именно назначение токена
+	app := models.App{ID: 1, Name: "test", Secret: string(actionSecret)}
+	a, _, provider, apps := newTestService(t, WithActionTokens(mocks.NewActionTokenStore(t), actionSecret))
+	ctx := context.Background()
+
+	access, err := jwt.NewToken(models.User{ID: 7, Email: "a@b.c"}, app, time.Hour)
+	require.NoError(t, err)
+
+	_, _, err = a.ConsumeActionToken(ctx, access, "export.download")
+	require.ErrorIs(t, err, ErrInvalidActionToken)
+	require.ErrorIs(t, err, jwt.ErrNotActionToken)
+
+	action, _, err := jwt.NewActionToken(7, "export.download", payloadHash(nil), time.Now(), time.Minute, actionSecret)
+	require.NoError(t, err)
+
+	apps.EXPECT().App(mock.Anything, 0).Return(app, nil).Maybe()
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, IsActive: true}, nil).Maybe()
+
+	_, err = a.Authenticate(ctx, action)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/mail"
+	"sso/internal/lib/selftest"
+	"sync"
+	"time"
+)
+
+// LoginAlertsMetadataKey - флаг в метаданных пользователя: false - не присылать письма о входе
+const LoginAlertsMetadataKey = "login_alerts"
+
+// loginAlertTimeout - сколько фоновая отправка письма о входе ждёт хранилище и очередь писем
+const loginAlertTimeout = 10 * time.Second
+
+// maxTrackedAlerts - сколько пользователей помнит ограничитель писем; сверх этого забываются те,
+// чей интервал уже прошёл
+const maxTrackedAlerts = 10000
+
+// LoginAlertSettings - письма о входе с нового устройства или из новой страны
+type LoginAlertSettings struct {
+	SessionsURL string        // Страница управления сеансами (ссылка в письме; пусто - без ссылки)
+	Interval    time.Duration // Не чаще одного письма пользователю за Interval: IP, прыгающий между странами, не заваливает почту
+}
+
+// loginAlerts - настройки писем о входе и время последнего письма каждому пользователю
+type loginAlerts struct {
+	settings LoginAlertSettings
+
+	mu   sync.Mutex
+	sent map[int64]time.Time
+}
+
+// WithLoginAlerts - после входа с устройства или из страны, которых нет в недавней истории входов,
+// пользователю уходит письмо. Нужны WithLoginHistory и WithMailer
+func WithLoginAlerts(s LoginAlertSettings) Option {
+	return func(a *AuthService) {
+		a.loginAlerts = &loginAlerts{settings: s, sent: make(map[int64]time.Time)}
+	}
+}
+
+// allow - можно ли отправить письмо пользователю сейчас; если да, запоминает отправку
+func (l *loginAlerts) allow(userID int64, now time.Time) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if last, ok := l.sent[userID]; ok && now.Sub(last) < l.settings.Interval {
+		return false
+	}
+
+	if len(l.sent) >= maxTrackedAlerts {
+		for id, last := range l.sent {
+			if now.Sub(last) >= l.settings.Interval {
+				delete(l.sent, id)
+			}
+		}
+	}
+	l.sent[userID] = now
+
+	return true
+}
+
+// newDevice - устройства device нет среди входов recent с известным устройством.
+// Как и со страной, первый вход с известным устройством новым не считается
+func newDevice(device string, recent []models.LoginRecord) bool {
+	if device == "" {
+		return false
+	}
+
+	known := false
+	for _, r := range recent {
+		if r.Device == "" {
+			continue
+		}
+		if r.Device == device {
+			return false
+		}
+		known = true
+	}
+
+	return known
+}
+
+// alertLogin - отправляет письмо о входе в фоне: вход уже состоялся, и ни задержка, ни ошибка
+// отправки на него не влияют
+func (a *AuthService) alertLogin(ctx context.Context, user models.User, record models.LoginRecord, userAgent string) {
+	log := logctx.FromOr(ctx, a.log).With(slog.Int64("user_id", user.ID))
+
+	if a.mailer == nil || user.IsGuest || user.Email == "" || selftest.From(ctx) {
+		return
+	}
+	if !a.loginAlerts.allow(user.ID, record.LoggedInAt) {
+		log.Debug("login alert suppressed, one was sent recently")
+
+		return
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		ctx, cancel := context.WithTimeout(ctx, loginAlertTimeout)
+		defer cancel()
+
+		if a.loginAlertsOptedOut(ctx, log, user.ID) {
+			log.Debug("login alert skipped, user opted out")
+
+			return
+		}
+
+		data := mail.Data{
+			Email:      user.Email,
+			Link:       a.loginAlerts.settings.SessionsURL,
+			LoggedInAt: record.LoggedInAt,
+			Location:   record.Country,
+			Device:     userAgent,
+		}
+		if err := a.mailer.SendTemplate(ctx, user.Email, mail.TemplateLoginAlert, data); err != nil {
+			log.Error("failed to send login alert", slog.String("error", err.Error()))
+
+			return
+		}
+
+		log.Info("login alert sent")
+	}()
+}
+
+// loginAlertsOptedOut - отказался ли пользователь от писем о входе. Если метаданные прочитать
+// не удалось, письмо отправляется: лишнее письмо лучше пропущенного входа злоумышленника
+func (a *AuthService) loginAlertsOptedOut(ctx context.Context, log *slog.Logger, userID int64) bool {
+	raw, err := a.usrProvider.UserMetadata(ctx, userID)
+	if err != nil {
+		log.Warn("failed to read user metadata", slog.String("error", err.Error()))
+
+		return false
+	}
+
+	var metadata map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return false
+	}
+
+	var enabled bool
+	if flag, ok := metadata[LoginAlertsMetadataKey]; ok && json.Unmarshal(flag, &enabled) == nil {
+		return !enabled
+	}
+
+	return false
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/lib/mail"
+	"sso/internal/lib/useragent"
+	"sso/internal/services/auth/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// alertMailer - почта, складывающая письма в канал: письма о входе отправляются в фоне
+type alertMailer struct {
+	sent chan mail.Data
+	err  error
+}
+
+func (m *alertMailer) SendTemplate(_ context.Context, _, template string, data mail.Data) error {
+	if template == mail.TemplateLoginAlert {
+		m.sent <- data
+	}
+
+	return m.err
+}
+
+func loginWith(ip, userAgent string) context.Context {
+	return useragent.Into(loginFrom(ip), userAgent)
+}
+
+func TestLogin_AlertsOnNewDevice(t *testing.T) {
+	history := mocks.NewLoginHistoryStore(t)
+	mailer := &alertMailer{sent: make(chan mail.Data, 4), err: errors.New("queue is full")}
+	a, _, provider, apps := newTestService(t,
+		WithLoginHistory(history, LoginHistorySettings{Geo: testGeo, HistoryDepth: 20}),
+		WithMailer(mailer),
+		WithLoginAlerts(LoginAlertSettings{SessionsURL: "https://sso.example.com/sessions", Interval: time.Hour}),
+	)
+	user := userWithPassword(t, "secret")
+	laptop := useragent.Fingerprint("laptop")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	provider.EXPECT().UserMetadata(mock.Anything, user.ID).Return([]byte(`{"locale":"ru"}`), nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	history.EXPECT().RecentLogins(mock.Anything, user.ID, 20).
+		Return([]models.LoginRecord{{Country: "DE", Device: laptop}}, nil)
+	history.EXPECT().AddLogin(mock.Anything, mock.Anything).Return(nil)
+
+	// Знакомое устройство из знакомой страны - письма нет
+	_, err := a.Login(loginWith("198.51.100.1", "laptop"), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+
+	// Новое устройство: ошибка почты на вход не влияет
+	_, err = a.Login(loginWith("198.51.100.1", "phone"), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+
+	select {
+	case data := <-mailer.sent:
+		assert.Equal(t, "a@b.c", data.Email)
+		assert.Equal(t, "phone", data.Device)
+		assert.Equal(t, "DE", data.Location)
+		assert.Equal(t, "https://sso.example.com/sessions", data.Link)
+		assert.False(t, data.LoggedInAt.IsZero())
+	case <-time.After(5 * time.Second):
+		t.Fatal("login alert was not sent")
+	}
+
+	// Новая страна в пределах интервала - письмо не повторяется
+	_, err = a.Login(loginWith("203.0.113.1", "tablet"), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.Never(t, func() bool { return len(mailer.sent) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestLogin_AlertsOptOut(t *testing.T) {
+	history := mocks.NewLoginHistoryStore(t)
+	mailer := &alertMailer{sent: make(chan mail.Data, 1)}
+	a, _, provider, apps := newTestService(t,
+		WithLoginHistory(history, LoginHistorySettings{HistoryDepth: 20}),
+		WithMailer(mailer),
+		WithLoginAlerts(LoginAlertSettings{Interval: time.Hour}),
+	)
+	user := userWithPassword(t, "secret")
+
+	checked := make(chan struct{})
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	provider.EXPECT().UserMetadata(mock.Anything, user.ID).RunAndReturn(func(context.Context, int64) ([]byte, error) {
+		close(checked)
+
+		return []byte(`{"login_alerts":false}`), nil
+	}).Once()
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	history.EXPECT().RecentLogins(mock.Anything, user.ID, 20).
+		Return([]models.LoginRecord{{Device: useragent.Fingerprint("laptop")}}, nil)
+	history.EXPECT().AddLogin(mock.Anything, mock.Anything).Return(nil)
+
+	_, err := a.Login(loginWith("198.51.100.1", "phone"), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+
+	select {
+	case <-checked:
+	case <-time.After(5 * time.Second):
+		t.Fatal("opt-out flag was not checked")
+	}
+	assert.Never(t, func() bool { return len(mailer.sent) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestNewDevice(t *testing.T) {
+	assert.False(t, newDevice("", []models.LoginRecord{{Device: "a"}}), "unknown device is not reported")
+	assert.False(t, newDevice("a", nil), "first login has nothing to compare with")
+	assert.False(t, newDevice("a", []models.LoginRecord{{Device: ""}}))
+	assert.False(t, newDevice("a", []models.LoginRecord{{Device: "b"}, {Device: "a"}}))
+	assert.True(t, newDevice("c", []models.LoginRecord{{Device: "b"}, {Device: "a"}}))
+}
@@ -2,33 +2,288 @@ package auth
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/clock"
+	"sso/internal/lib/events"
+	"sso/internal/lib/features"
+	"sso/internal/lib/hashpool"
 	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/mail"
+	"sso/internal/lib/selftest"
 	"sso/internal/storage"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // Auth - структура сервисного слоя, отвечающая за аутентификацию пользователей.
 type AuthService struct {
-	log         *slog.Logger    // Логгер для записи информации о работе сервиса.
-	usrSaver    UserSaver       // Интерфейс для сохранения пользователей в базе.
-	usrProvider UserProvider    // Интерфейс для получения данных о пользователях.
-	appProvider AppProvider     // Интерфейс для работы с приложениями (если есть разные приложения, например, web и mobile).
-	tokenTTL    time.Duration   // Время жизни токена (JWT, session и т. д.).
+	log         *slog.Logger  // Логгер для записи информации о работе сервиса.
+	usrSaver    UserSaver     // Интерфейс для сохранения пользователей в базе.
+	usrProvider UserProvider  // Интерфейс для получения данных о пользователях.
+	appProvider AppProvider   // Интерфейс для работы с приложениями (если есть разные приложения, например, web и mobile).
+	tokenTTL    atomic.Int64  // Время жизни токена (JWT, session и т. д.), может меняться при перезагрузке конфигурации.
+	audit       *audit.Logger // Журнал событий безопасности.
+	clock       clock.Clock   // Источник текущего времени для сроков токенов, сессий и кодов.
+	features    *features.Set // Флаги постепенного включения поведения (nil - все выключены).
+
+	enricher     ClaimsEnricher // Источник дополнительных клеймов токена (может быть nil).
+	maxTokenSize int            // Максимальный размер токена в байтах (0 - без ограничения).
+	leeway       time.Duration  // Допустимое расхождение часов при проверке сроков токена.
+	historySize  int            // Сколько предыдущих паролей нельзя повторять (0 - проверка отключена).
+	maxPassAge   time.Duration  // Срок действия пароля (0 - без ограничения).
+	breaches     BreachChecker  // Проверка паролей по базе утечек (может быть nil).
+	hashing      *hashpool.Pool // Ограничение одновременных bcrypt-операций (nil - без ограничения).
+
+	validation ValidationCache // Кеш пользователей для Authenticate (nil - читать из usrProvider).
+
+	events events.Publisher // Публикация доменных событий (по умолчанию никуда не отправляются).
+	tx     Transactor       // Транзакции хранилища для outbox (может быть nil).
+	mailer Mailer           // Отправка писем (может быть nil).
+
+	orgs         OrgStore // Организации (nil - вход и регистрация в организацию недоступны).
+	perOrgEmails bool     // Email уникален внутри организации, а не во всём сервисе.
+
+	invitations   InvitationStore // Приглашения (nil - приглашать пользователей нельзя).
+	invitationTTL time.Duration   // Срок действия приглашения.
+	acceptURL     string          // Страница принятия приглашения (пусто - в письме только код).
+
+	consents ConsentStore // Согласия для сторонних приложений (nil - вход в них невозможен).
+
+	appMetadata AppMetadataStore // Метаданные пользователей по приложениям (nil - недоступны).
+
+	actionTokens ActionTokenStore // Токены одного действия (nil - выдавать их нельзя).
+	actionSecret []byte           // Ключ подписи токенов одного действия.
+
+	guests   GuestStore    // Гостевые пользователи (nil - создавать гостей нельзя).
+	guestTTL time.Duration // Время жизни токена гостя.
+
+	sessions SessionStore  // Сессии входа (nil - токены выдаются без sid).
+	denylist TokenDenylist // Отдельно отозванные токены (nil - отзывать можно только сессии).
+
+	revocations      RevocationStore // Журнал отзывов для WatchRevocations (nil - отзывы не записываются).
+	revocationNotify func()          // Вызывается после записи отзыва.
+
+	secondFactor SecondFactor // Проверка второго фактора для StepUp (nil - подтверждение недоступно).
+
+	loginHistory LoginHistoryStore    // История входов (nil - входы не записываются).
+	loginRisk    LoginHistorySettings // Поиск подозрительных входов.
+	loginAlerts  *loginAlerts         // Письма о входе с нового устройства или из новой страны (nil - не отправляются).
+
+	devices DeviceStore    // Входы на устройствах (nil - вход на устройстве недоступен).
+	device  DeviceSettings // Параметры входа на устройстве.
+
+	passkeys     PasskeyStore       // Passkey и церемонии WebAuthn (nil - вход по passkey недоступен).
+	relyingParty *webauthn.WebAuthn // Параметры сайта для церемоний WebAuthn.
+	passkey      PasskeySettings    // Параметры входа по passkey.
+
+	smsStore    SMSStore    // Вызовы с кодом из SMS и номера телефонов (nil - второй фактор по SMS недоступен).
+	smsProvider SMSProvider // Отправка SMS.
+	sms         SMSSettings // Сроки кодов и лимиты отправки.
+
+	oidcStore OIDCStore    // Адреса возврата и коды авторизации (nil - OpenID Connect выключен).
+	oidc      OIDCSettings // Параметры OpenID Connect.
+
+	captcha       CaptchaVerifier // Проверка CAPTCHA (nil - не требуется).
+	captchaPolicy CaptchaSettings // Когда требовать CAPTCHA при входе.
+	loginFailures *loginFailures  // Неудачные входы по email и адресу клиента.
+
+	tosStore TOSStore                  // Принятия условий использования (nil - условия не проверяются).
+	tos      atomic.Pointer[TOSPolicy] // Текущая версия условий, может меняться при перезагрузке конфигурации.
+
+	deletions         AccountDeletionStore // Запросы удаления аккаунта (nil - удалить аккаунт может только администратор).
+	deletionGrace     time.Duration        // Сколько аккаунт ждёт удаления после запроса.
+	deletionCancelURL string               // Страница отмены удаления (пусто - в письме только код).
+
+	offline         OfflineTokenStore    // Offline-токены фоновых агентов (nil - выдавать их нельзя, приложения их не принимают).
+	offlineSettings OfflineTokenSettings // Срок, права и требования к выдаче offline-токенов.
+
+	refresh         RefreshTokenStore // Цепочки refresh-токенов (nil - вход выдаёт только токен доступа).
+	refreshSettings RefreshSettings   // Окна refresh-токенов по умолчанию.
+
+	provisioning ProvisioningStore // Пользователи внешней системы (nil - провижининг выключен).
+
+	selfTest SelfTestStore // Тестовые приложение и пользователь самопроверки (nil - самопроверка выключена).
+
+	userApps   UserAppStore // Связи пользователей с приложениями (nil - не записываются).
+	requireApp bool         // Регистрация только через приложение (app_id обязателен).
+
+	domains atomic.Pointer[DomainPolicy] // Ограничения доменов email при регистрации, может меняться при перезагрузке конфигурации.
+}
+
+// Option - необязательная настройка AuthService.
+type Option func(a *AuthService)
+
+// WithAudit - задаёт журнал событий безопасности (по умолчанию события никуда не пишутся).
+func WithAudit(l *audit.Logger) Option {
+	return func(a *AuthService) {
+		a.audit = l
+	}
+}
+
+// WithClock - задаёт источник времени (по умолчанию настоящие часы). Тесты подставляют clock.Fake,
+// чтобы проверять истечение токенов, сессий и кодов без ожидания.
+func WithClock(c clock.Clock) Option {
+	return func(a *AuthService) {
+		a.clock = c
+	}
+}
+
+// WithFeatures - задаёт флаги постепенного включения поведения (по умолчанию все выключены).
+// Правила флагов меняются при перезагрузке конфигурации через сам Set.
+func WithFeatures(s *features.Set) Option {
+	return func(a *AuthService) {
+		a.features = s
+	}
+}
+
+// WithClaimsEnricher - задаёт источник дополнительных клеймов токена.
+func WithClaimsEnricher(e ClaimsEnricher) Option {
+	return func(a *AuthService) {
+		a.enricher = e
+	}
+}
+
+// WithMaxTokenSize - ограничивает размер выдаваемого токена в байтах.
+func WithMaxTokenSize(n int) Option {
+	return func(a *AuthService) {
+		a.maxTokenSize = n
+	}
+}
+
+// WithTokenLeeway - допустимое расхождение часов при проверке exp/nbf/iat (по умолчанию jwt.DefaultLeeway).
+func WithTokenLeeway(d time.Duration) Option {
+	return func(a *AuthService) {
+		a.leeway = d
+	}
+}
+
+// WithPasswordHistory - запрещает повторно использовать n последних паролей.
+func WithPasswordHistory(n int) Option {
+	return func(a *AuthService) {
+		a.historySize = n
+	}
+}
+
+// WithPasswordMaxAge - ограничивает срок действия пароля: после него Login возвращает ErrPasswordExpired.
+func WithPasswordMaxAge(d time.Duration) Option {
+	return func(a *AuthService) {
+		a.maxPassAge = d
+	}
+}
+
+// WithBreachChecker - отклоняет при регистрации и смене пароля пароли из публичных утечек.
+func WithBreachChecker(c BreachChecker) Option {
+	return func(a *AuthService) {
+		a.breaches = c
+	}
+}
+
+// WithHashPool - ограничивает число одновременных bcrypt-операций; сверх очереди пула запросы получают ErrOverloaded.
+func WithHashPool(p *hashpool.Pool) Option {
+	return func(a *AuthService) {
+		a.hashing = p
+	}
+}
+
+// WithValidationCache - Authenticate читает пользователя через кеш c.
+// Login, Register и остальные методы всегда обращаются к хранилищу напрямую.
+func WithValidationCache(c ValidationCache) Option {
+	return func(a *AuthService) {
+		a.validation = c
+	}
+}
+
+// WithDomainPolicy - ограничивает домены email, с которыми можно зарегистрироваться.
+func WithDomainPolicy(p *DomainPolicy) Option {
+	return func(a *AuthService) {
+		a.SetDomainPolicy(p)
+	}
+}
+
+// WithEventPublisher - задаёт, куда публиковать доменные события (user.registered, user.logged_in и т. д.).
+func WithEventPublisher(p events.Publisher) Option {
+	return func(a *AuthService) {
+		a.events = p
+	}
+}
+
+// WithOutbox - пишет доменные события в outbox в одной транзакции с изменением состояния.
+// writer - Publisher, сохраняющий событие в хранилище (events.OutboxWriter), tx - транзакции того же хранилища.
+func WithOutbox(writer events.Publisher, tx Transactor) Option {
+	return func(a *AuthService) {
+		a.events = writer
+		a.tx = tx
+	}
+}
+
+// WithMailer - задаёт отправку писем (приглашения, уведомления).
+func WithMailer(m Mailer) Option {
+	return func(a *AuthService) {
+		a.mailer = m
+	}
+}
+
+// WithOrganizations - включает вход и регистрацию в организации. perOrgEmails: пользователи,
+// зарегистрированные в организации, получают собственное пространство email (один email в разных организациях -
+// разные учётные записи); иначе email уникален во всём сервисе.
+func WithOrganizations(orgs OrgStore, perOrgEmails bool) Option {
+	return func(a *AuthService) {
+		a.orgs = orgs
+		a.perOrgEmails = perOrgEmails
+	}
+}
+
+// Mailer - отправляет письмо по шаблону. Отправка асинхронная: ошибка означает,
+// что письмо не удалось поставить в очередь, а не что оно не доставлено.
+type Mailer interface {
+	SendTemplate(ctx context.Context, to, template string, data mail.Data) error
+}
+
+// Transactor - выполняет fn в одной транзакции хранилища (вызовы с переданным ctx попадают в неё).
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// BreachChecker - проверяет, встречался ли пароль в публичных утечках.
+type BreachChecker interface {
+	IsCompromised(ctx context.Context, password string) (bool, error)
+}
+
+// ClaimsEnricher - возвращает дополнительные клеймы для токена пользователя в приложении
+// (отдел, локаль, tenant id). Зарезервированные клеймы (uid, exp, app_id, iss, aud) переопределить нельзя.
+type ClaimsEnricher interface {
+	Claims(ctx context.Context, user models.User, app models.App) (map[string]any, error)
 }
 
 // UserSaver - интерфейс для сохранения пользователей в хранилище (например, в базе данных).
 type UserSaver interface {
 	SaveUser(
 		ctx context.Context, // Контекст запроса (для отмены, таймаутов и т. д.).
-		email string,        // Email пользователя.
-		passHash []byte,     // Хеш пароля пользователя.
+		email string, // Email пользователя.
+		passHash []byte, // Хеш пароля пользователя.
 	) (uid int64, err error) // Возвращает ID созданного пользователя или ошибку.
+
+	// EraseUser - обезличивает пользователя (email -> tombstone, хеш пароля и метаданные стираются).
+	EraseUser(ctx context.Context, userID int64, tombstone string) error
+
+	// UpdatePassword - меняет хеш пароля, перенося текущий в историю (в истории остаётся keep записей).
+	UpdatePassword(ctx context.Context, userID int64, passHash []byte, keep int) error
+
+	// RehashPassword - заменяет хеш того же пароля (oldHash -> newHash) без записи в историю и сброса срока.
+	// Если хеш уже сменился, ничего не делает
+	RehashPassword(ctx context.Context, userID int64, oldHash, newHash []byte) error
 }
 
 // UserProvider - интерфейс для получения информации о пользователях.
@@ -36,6 +291,26 @@ type UserProvider interface {
 	User(ctx context.Context, email string) (models.User, error) // Получает пользователя по email.
 	IsAdmin(ctx context.Context, userID int64) (bool, error)     // Проверяет, является ли пользователь администратором.
 	IsUserExists(ctx context.Context, userID int64) (bool, error)
+	UsersByIDs(ctx context.Context, userIDs []int64) ([]models.User, error)         // Получает пользователей одним запросом (без хешей паролей).
+	UserByID(ctx context.Context, userID int64) (models.User, error)                // Получает пользователя по id (без хеша пароля).
+	UserMetadata(ctx context.Context, userID int64) ([]byte, error)                 // Получает метаданные пользователя (JSON).
+	PasswordHistory(ctx context.Context, userID int64, limit int) ([][]byte, error) // Хеши предыдущих паролей, от новых к старым.
+}
+
+// ValidationCache - UserProvider с кешем для проверки токенов; Invalidate сбрасывает запись после изменения пользователя.
+type ValidationCache interface {
+	UserProvider
+	Invalidate(userID int64)
+}
+
+// OrgStore - организации и членство пользователей в них.
+type OrgStore interface {
+	OrgBySlug(ctx context.Context, slug string) (models.Organization, error)
+	MemberRole(ctx context.Context, orgID, userID int64) (string, error)            // storage.ErrNotMember, если не участник.
+	ScopedUser(ctx context.Context, scope int64, email string) (models.User, error) // Пользователь из пространства email организации.
+
+	// SaveOrgUser - сохраняет пользователя в пространстве email scope (0 - общее) и добавляет его в организацию.
+	SaveOrgUser(ctx context.Context, email string, passHash []byte, orgID, scope int64, role string) (int64, error)
 }
 
 // AppProvider - интерфейс для работы с данными о приложении (если у нас многосервисная архитектура).
@@ -45,111 +320,769 @@ type AppProvider interface {
 
 // Предопределенные ошибки, которые могут возникнуть в процессе работы с сервисным слоем.
 var (
-	ErrInvalidCredentials = errors.New("invalid credentials") // Ошибка, если логин/пароль неверные.
-	ErrInvalidAppID       = errors.New("invalid app id")      // Ошибка, если передан несуществующий app_id.
-	ErrUserExists         = errors.New("user already exists") // Ошибка, если пользователь с таким email уже зарегистрирован.
-	ErrUserNotFound       = errors.New("user not found")      // Ошибка, если пользователь не найден.
+	ErrInvalidCredentials = errors.New("invalid credentials")         // Ошибка, если логин/пароль неверные.
+	ErrInvalidAppID       = errors.New("invalid app id")              // Ошибка, если передан несуществующий app_id.
+	ErrUserExists         = errors.New("user already exists")         // Ошибка, если пользователь с таким email уже зарегистрирован.
+	ErrUserNotFound       = errors.New("user not found")              // Ошибка, если пользователь не найден.
+	ErrInvalidToken       = errors.New("invalid token")               // Ошибка, если токен не прошёл проверку.
+	ErrTooManyIDs         = errors.New("too many ids")                // Ошибка, если в пакетном запросе больше MaxBatchSize id.
+	ErrPasswordReused     = errors.New("password was used recently")  // Ошибка, если новый пароль совпадает с одним из недавних.
+	ErrPasswordExpired    = errors.New("password expired")            // Ошибка, если пароль нужно сменить через ChangePassword перед входом.
+	ErrWeakPassword       = errors.New("password is too weak")        // Ошибка, если пароль не прошёл парольную политику.
+	ErrDomainNotAllowed   = errors.New("email domain is not allowed") // Ошибка, если домен email запрещён политикой регистрации.
+	ErrOverloaded         = hashpool.ErrOverloaded                    // Ошибка, если проверка пароля отклонена из-за перегрузки.
+	ErrOrgNotFound        = errors.New("organization not found")      // Ошибка, если организации с таким slug нет.
+	ErrSignupClosed       = errors.New("organization is invite-only") // Ошибка, если в организации нельзя зарегистрироваться самому.
 )
 
+// MaxBatchSize - максимальное количество id в одном пакетном запросе.
+const MaxBatchSize = 1000
+
 func New(
 	log *slog.Logger,
 	userSaver UserSaver,
 	userProvider UserProvider,
 	appProvider AppProvider,
-	tokenTTL time.Duration) *AuthService {
-	return &AuthService{
+	tokenTTL time.Duration,
+	opts ...Option) *AuthService {
+	a := &AuthService{
 		usrSaver:    userSaver,
 		usrProvider: userProvider,
 		log:         log,
 		appProvider: appProvider,
-		tokenTTL:    tokenTTL,
+		audit:       audit.Nop(),
+		clock:       clock.Real(),
+		events:      events.Nop(),
+		leeway:      jwt.DefaultLeeway,
+	}
+	a.SetTokenTTL(tokenTTL)
+
+	for _, opt := range opts {
+		opt(a)
 	}
+
+	return a
+}
+
+// SetTokenTTL - меняет время жизни выдаваемых токенов (безопасно вызывать во время работы сервиса)
+func (a *AuthService) SetTokenTTL(ttl time.Duration) {
+	a.tokenTTL.Store(int64(ttl))
+}
+
+// SetDomainPolicy - меняет ограничения доменов email при регистрации (безопасно вызывать во время работы сервиса)
+func (a *AuthService) SetDomainPolicy(p *DomainPolicy) {
+	a.domains.Store(p)
+}
+
+// Login - проверяет email и пароль и выдаёт токен для приложения appID.
+// Если у пользователя подтверждён номер телефона (WithSMS), токена нет: Token.MFAChallenge - вызов для кода из SMS.
+// Непустой orgSlug - вход в организацию: пользователь должен быть её участником, в токен попадают org_id и org_role.
+// acceptedTOS - версия условий использования, которую пользователь принял на странице входа (может быть пустой).
+// captchaToken - токен CAPTCHA; нужен после нескольких неудачных входов для email или адреса клиента (WithCaptcha).
+func (a *AuthService) Login(
+	ctx context.Context,
+	email string,
+	password string,
+	appID int,
+	orgSlug string,
+	acceptedTOS string,
+	captchaToken string,
+) (models.Token, error) {
+	return a.login(ctx, passwordLogin{
+		email:        email,
+		password:     password,
+		appID:        appID,
+		orgSlug:      orgSlug,
+		acceptedTOS:  acceptedTOS,
+		captchaToken: captchaToken,
+	})
 }
 
-func (a *AuthService) Login(ctx context.Context, email string, password string, appID int) (string, error) {
+// passwordLogin - параметры входа по паролю (см. Login)
+type passwordLogin struct {
+	email        string
+	password     string
+	appID        int
+	orgSlug      string
+	acceptedTOS  string
+	captchaToken string
+
+	// grantScopes - согласие на эти права, которое пользователь дал на странице входа
+	// (AuthorizeWithPassword): сохраняется после проверки пароля (nil - согласие не меняется)
+	grantScopes []string
+}
+
+// login - вход по паролю: общая часть Login и AuthorizeWithPassword
+func (a *AuthService) login(ctx context.Context, req passwordLogin) (models.Token, error) {
 	const op = "Auth.Login"
 
-	log := a.log.With(
+	email, password, appID, acceptedTOS := req.email, req.password, req.appID, req.acceptedTOS
+
+	log := logctx.FromOr(ctx, a.log).With(
 		slog.String("op", op),
 		slog.String("email", email))
 
 	log.Info("attempting to login user")
 
-	user, err := a.usrProvider.User(ctx, email)
+	// CAPTCHA проверяется до пароля: иначе перебор паролей продолжался бы без неё
+	if a.loginCaptchaRequired(ctx, email) {
+		if err := a.checkCaptcha(ctx, log, req.captchaToken); err != nil {
+			log.Info("captcha check failed", slog.String("error", err.Error()))
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+				Email: email, AppID: appID, Reason: captchaFailureReason(err),
+			})
+
+			return models.Token{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	var org models.Organization
+	if req.orgSlug != "" {
+		var err error
+		if org, err = a.orgBySlug(ctx, req.orgSlug); err != nil {
+			log.Warn("organization not found", slog.String("org", req.orgSlug), slog.String("error", err.Error()))
+
+			return models.Token{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	normalized := a.features.EnabledFor(features.NormalizedEmail, appID, normalizeEmail(email))
+	user, err := a.loginUser(ctx, email, org, normalized)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
-			a.log.Warn("user not found", slog.String("error", err.Error()))
+			log.Warn("user not found", slog.String("error", err.Error()))
+			// Ответ не должен приходить заметно быстрее, чем при неверном пароле: иначе по времени видно, есть ли email
+			if a.features.EnabledFor(features.ConstantTimeLogin, appID, normalizeEmail(email)) {
+				_, _, _ = a.verifyPassword(ctx, dummyHash(), password)
+			}
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+				Email: email, AppID: appID, Reason: "user not found",
+			})
+			a.recordLoginFailure(ctx, log, appID)
+			a.countLoginFailure(ctx, email)
 
-			return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+			return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
 		}
 
-		a.log.Error("failed to get user", slog.String("error", err.Error()))
+		log.Error("failed to get user", slog.String("error", err.Error()))
 
-		return "", fmt.Errorf("%s: %w", op, err)
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	if err := bcrypt.CompareHashAndPassword(user.PassHash, []byte(password)); err != nil {
-		a.log.Info("invalid credentials", slog.String("error", err.Error()))
+	ok, legacy, err := a.verifyPassword(ctx, user.PassHash, password)
+	if err != nil {
+		log.Warn("password check rejected", slog.String("error", err.Error()))
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		log.Info("invalid credentials")
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+			UserID: user.ID, Email: email, AppID: appID, Reason: "invalid password",
+		})
+		a.recordLoginFailure(ctx, log, appID)
+		a.countLoginFailure(ctx, email)
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+	if legacy || (a.features.Enabled(features.RehashOnLogin, appID, user.ID) && weakHash(user.PassHash)) {
+		a.rehashLegacy(ctx, log, user, password)
+	}
+	a.resetLoginFailures(email)
 
-		return "", fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	// Пароль верный, но токен не выдаём: клиент должен сменить пароль через ChangePassword
+	if reason, expired := a.passwordExpired(user); expired {
+		log.Info("password change required", slog.String("reason", reason))
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+			UserID: user.ID, Email: email, AppID: appID, Reason: reason,
+		})
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrPasswordExpired)
 	}
 
+	var tokenOpts []jwt.TokenOption
+	if org.ID != 0 {
+		role, err := a.orgs.MemberRole(ctx, org.ID, user.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotMember) {
+				log.Info("user is not a member of the organization", slog.Int64("org_id", org.ID))
+				a.audit.Emit(ctx, audit.Event{
+					Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+					UserID: user.ID, Email: email, AppID: appID, Reason: "not a member of the organization",
+				})
+
+				return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+			}
+
+			log.Error("failed to get organization role", slog.String("error", err.Error()))
+
+			return models.Token{}, fmt.Errorf("%s: %w", op, err)
+		}
+		tokenOpts = append(tokenOpts, jwt.WithOrg(org.ID, role))
+	}
+
+	// С подтверждённым номером пароля недостаточно: токен выдаст VerifySMSCode
+	if a.smsStore != nil && user.Phone != "" {
+		token, err := a.startLoginChallenge(ctx, log, user, appID, org.ID, acceptedTOS)
+		if err != nil {
+			return models.Token{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		return token, nil
+	}
+
+	if req.grantScopes != nil {
+		if _, err := a.GrantConsent(ctx, user.ID, appID, req.grantScopes); err != nil {
+			return models.Token{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	tokenOpts = append(tokenOpts, passwordAuthentication(a.clock.Now()))
+
+	token, err := a.completeLogin(ctx, log, user, email, appID, acceptedTOS, models.ACRSingleFactor, tokenOpts...)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// completeLogin - общая часть входа после проверки учётных данных: приложение, согласие, условия использования,
+// сессия, токен, история входов, аудит и событие user.logged_in. email - адрес из запроса (для аудита),
+// acr - уровень входа, tokenOpts - клеймы способа входа (amr, acr, auth_time) и организации
+func (a *AuthService) completeLogin(
+	ctx context.Context,
+	log *slog.Logger,
+	user models.User,
+	email string,
+	appID int,
+	acceptedTOS string,
+	acr string,
+	tokenOpts ...jwt.TokenOption,
+) (models.Token, error) {
 	app, err := a.appProvider.App(ctx, appID)
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", op, err)
+		if errors.Is(err, storage.ErrAppNotFound) {
+			log.Warn("app not found", slog.Int("app_id", appID))
+
+			return models.Token{}, ErrInvalidAppID
+		}
+
+		return models.Token{}, err
+	}
+
+	if err := a.checkLoginPolicies(ctx, log, audit.EventLoginFailed, app, user.ID, email); err != nil {
+		return models.Token{}, err
+	}
+
+	// Стороннее приложение получает данные пользователя только с его согласия
+	if app.ThirdParty {
+		if _, err := a.checkConsent(ctx, user.ID, app.ID, time.Time{}); err != nil {
+			if errors.Is(err, ErrConsentRequired) {
+				log.Info("consent required", slog.Int("app_id", appID))
+				a.audit.Emit(ctx, audit.Event{
+					Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+					UserID: user.ID, Email: email, AppID: appID, Reason: "consent required",
+				})
+			} else {
+				log.Error("failed to check consent", slog.String("error", err.Error()))
+			}
+
+			return models.Token{}, err
+		}
+	}
+
+	tosPending, err := a.tosPending(ctx, user.ID, acceptedTOS)
+	if err != nil {
+		log.Error("failed to check terms of service acceptance", slog.String("error", err.Error()))
+
+		return models.Token{}, err
 	}
+	if tosPending && a.tosPolicy().EnforceOnLogin {
+		log.Info("terms of service re-acceptance required")
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+			UserID: user.ID, Email: email, AppID: appID, Reason: "terms of service not accepted",
+		})
 
-	log.Info("user logged in successfully")
+		return models.Token{}, ErrTOSReacceptanceRequired
+	}
 
-	token, err := jwt.NewToken(user, app, a.tokenTTL)
+	// С refresh-токенами сессия живёт до их предела: токены доступа той же сессии выдаёт RefreshToken
+	refresh := a.refresh != nil && !user.IsGuest
+	lifetime := time.Duration(a.tokenTTL.Load())
+	if refresh {
+		_, lifetime = a.refreshWindows(app)
+	}
+
+	// id сессии попадает в токен, а сама сессия сохраняется с клеймами подписанного токена
+	if a.sessions != nil {
+		tokenOpts = append(tokenOpts, jwt.WithSession(uuid.NewString()))
+	}
+	token, claims, err := a.issueTokenClaims(ctx, user, app, tokenOpts...)
 	if err != nil {
-		a.log.Error("failed to create token", slog.String("error", err.Error()))
+		log.Error("failed to create token", slog.String("error", err.Error()))
+
+		return models.Token{}, err
+	}
+
+	var session models.Session
+	if a.sessions != nil {
+		session, err = a.startSession(ctx, user, app, claims, lifetime)
+		if err != nil {
+			if errors.Is(err, ErrTooManySessions) {
+				log.Info("session limit reached", slog.Int("max_sessions", app.MaxSessions))
+				a.audit.Emit(ctx, audit.Event{
+					Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+					UserID: user.ID, Email: email, AppID: appID, Reason: "session limit reached",
+				})
+			} else {
+				log.Error("failed to create session", slog.String("error", err.Error()))
+			}
+
+			return models.Token{}, err
+		}
+	}
+	if refresh {
+		absolute := session.ExpiresAt
+		if absolute.IsZero() {
+			absolute = claims.IssuedAt.Time.Add(lifetime)
+		}
+		if err := a.startRefreshFamily(ctx, &token, claims, app, absolute); err != nil {
+			log.Error("failed to save refresh token", slog.String("error", err.Error()))
 
-		return "", fmt.Errorf("%s: %w", op, err)
+			return models.Token{}, err
+		}
 	}
+	token.SessionID = session.ID
+	token.TOSReacceptanceRequired = tosPending
+	token.DeletionScheduledAt = a.pendingDeletion(ctx, log, user.ID)
+	// Вход всё равно успешен: приложение само решает, какие экраны требуют подтверждения (StepUp)
+	token.StepUpRequired = !acrSatisfies(acr, app.MinACR)
+
+	if a.loginHistory != nil && a.recordLogin(ctx, user, app.ID) && a.stepUpOnSuspicious(ctx, user.ID) {
+		token.StepUpRequired = true
+	}
+	a.recordUserApp(ctx, user.ID, app.ID)
+
+	log.Info("user logged in successfully")
+
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventLoginSucceeded, Outcome: audit.OutcomeSuccess,
+		UserID: user.ID, Email: email, AppID: appID,
+	})
+
+	loggedIn := events.New(ctx, events.TypeUserLoggedIn, user.ID)
+	loggedIn.AppID = appID
+	a.publish(ctx, loggedIn)
 
 	return token, nil
 }
 
-func (a *AuthService) RegisterNewUser(ctx context.Context, email string, pass string) (int64, error) {
+// issueToken - токен пользователя для приложения с дополнительными клеймами от enricher.
+// Токен гостя выдаётся на guestTTL, с клеймом guest и без email-заглушки
+func (a *AuthService) issueToken(
+	ctx context.Context,
+	user models.User,
+	app models.App,
+	opts ...jwt.TokenOption,
+) (models.Token, error) {
+	token, _, err := a.issueTokenClaims(ctx, user, app, opts...)
+
+	return token, err
+}
+
+// issueTokenClaims - как issueToken, но возвращает и подписанные клеймы (по ним заводится цепочка refresh-токенов)
+func (a *AuthService) issueTokenClaims(
+	ctx context.Context,
+	user models.User,
+	app models.App,
+	opts ...jwt.TokenOption,
+) (models.Token, jwt.Claims, error) {
+	ttl := time.Duration(a.tokenTTL.Load())
+	if user.IsGuest {
+		ttl = a.guestTTL
+	}
+
+	return a.issueTokenTTL(ctx, user, app, ttl, opts...)
+}
+
+// issueTokenTTL - как issueToken, но со сроком ttl; возвращает и подписанные клеймы (нужен jti)
+func (a *AuthService) issueTokenTTL(
+	ctx context.Context,
+	user models.User,
+	app models.App,
+	ttl time.Duration,
+	opts ...jwt.TokenOption,
+) (models.Token, jwt.Claims, error) {
+	opts = append([]jwt.TokenOption{jwt.WithMaxSize(a.maxTokenSize), jwt.WithIssuedAt(a.clock.Now())}, opts...)
+
+	if user.IsGuest {
+		user.Email = ""
+		opts = append(opts, jwt.AsGuest())
+	}
+
+	if a.enricher != nil {
+		extra, err := a.enricher.Claims(ctx, user, app)
+		if err != nil {
+			return models.Token{}, jwt.Claims{}, fmt.Errorf("extra claims: %w", err)
+		}
+		opts = append(opts, jwt.WithExtraClaims(extra))
+	}
+
+	token, claims, err := jwt.Issue(user, app, ttl, opts...)
+	if err != nil {
+		return models.Token{}, jwt.Claims{}, err
+	}
+
+	return models.Token{
+		AccessToken: token,
+		ExpiresAt:   claims.ExpiresAt.Time,
+		Scopes:      strings.Fields(claims.Scope),
+	}, claims, nil
+}
+
+// orgBySlug - организация для входа или регистрации; ErrOrgNotFound, если её нет или организации выключены
+func (a *AuthService) orgBySlug(ctx context.Context, slug string) (models.Organization, error) {
+	if a.orgs == nil {
+		return models.Organization{}, ErrOrgNotFound
+	}
+
+	org, err := a.orgs.OrgBySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, storage.ErrOrgNotFound) {
+			return models.Organization{}, ErrOrgNotFound
+		}
+
+		return models.Organization{}, err
+	}
+
+	return org, nil
+}
+
+// loginUser - пользователь для входа. При входе в организацию с собственным пространством email
+// сначала ищется учётная запись этой организации, затем общая (её могли добавить в организацию через AddMember)
+func (a *AuthService) loginUser(ctx context.Context, email string, org models.Organization, normalized bool) (models.User, error) {
+	// Сначала - email в нормальной форме; пользователи, зарегистрированные до флага, могли сохраниться как есть
+	if n := normalizeEmail(email); normalized && n != email {
+		user, err := a.loginUser(ctx, n, org, false)
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			return user, err
+		}
+	}
+
+	if org.ID != 0 && a.perOrgEmails {
+		user, err := a.orgs.ScopedUser(ctx, org.ID, email)
+		if !errors.Is(err, storage.ErrUserNotFound) {
+			return user, err
+		}
+	}
+
+	return a.usrProvider.User(ctx, email)
+}
+
+// emailScope - пространство email для нового участника организации
+func (a *AuthService) emailScope(org models.Organization) int64 {
+	if a.perOrgEmails {
+		return org.ID
+	}
+
+	return 0
+}
+
+// publish - публикует доменное событие. Ошибка публикации только логируется и учитывается
+// в счётчике: операция пользователя из-за неё не должна завершаться ошибкой
+func (a *AuthService) publish(ctx context.Context, e events.Event) {
+	// Данные самопроверки временные: потребителям событий о них знать незачем
+	if selftest.From(ctx) {
+		return
+	}
+
+	// Событие должно уйти, даже если клиент уже не ждёт ответа
+	if err := a.events.Publish(context.WithoutCancel(ctx), e); err != nil {
+		events.PublishFailures.Add(1)
+		logctx.FromOr(ctx, a.log).Warn("failed to publish event",
+			slog.String("event", e.Type),
+			slog.String("event_id", e.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// atomically - выполняет изменение состояния fn и публикует возвращённое им событие.
+// С outbox событие пишется в той же транзакции, что и изменение (ошибка записи откатывает изменение);
+// без outbox событие публикуется после fn, а ошибка публикации только логируется
+func (a *AuthService) atomically(ctx context.Context, fn func(ctx context.Context) (events.Event, error)) error {
+	if a.tx == nil {
+		e, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		a.publish(ctx, e)
+
+		return nil
+	}
+
+	return a.tx.WithinTx(ctx, func(ctx context.Context) error {
+		e, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		if selftest.From(ctx) {
+			return nil
+		}
+
+		return a.events.Publish(ctx, e)
+	})
+}
+
+// comparePassword - сверяет пароль с bcrypt-хешем в пуле хеширования.
+// Ошибка означает, что проверка не выполнялась (пул перегружен или контекст отменён), а не что пароль неверный
+func (a *AuthService) comparePassword(ctx context.Context, hash []byte, password string) (bool, error) {
+	var match bool
+	err := a.hashing.Do(ctx, func() {
+		match = bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+	})
+
+	return match, err
+}
+
+// hashPassword - считает bcrypt-хеш пароля в пуле хеширования
+func (a *AuthService) hashPassword(ctx context.Context, password string) ([]byte, error) {
+	var (
+		hash    []byte
+		hashErr error
+	)
+	if err := a.hashing.Do(ctx, func() {
+		hash, hashErr = bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	}); err != nil {
+		return nil, err
+	}
+
+	return hash, hashErr
+}
+
+// passwordExpired - нужно ли сменить пароль перед входом, и почему
+func (a *AuthService) passwordExpired(user models.User) (string, bool) {
+	if user.ForcePasswordChange {
+		return "password change forced", true
+	}
+
+	if a.maxPassAge > 0 && !user.PasswordChangedAt.IsZero() && a.clock.Now().Sub(user.PasswordChangedAt) > a.maxPassAge {
+		return "password expired", true
+	}
+
+	return "", false
+}
+
+// checkBreached - ErrWeakPassword, если пароль встречался в утечках (проверка выключена, если breaches не задан)
+func (a *AuthService) checkBreached(ctx context.Context, password string) error {
+	if a.breaches == nil {
+		return nil
+	}
+
+	compromised, err := a.breaches.IsCompromised(ctx, password)
+	if err != nil {
+		return err
+	}
+
+	if compromised {
+		return ErrWeakPassword
+	}
+
+	return nil
+}
+
+// RegisterNewUser - регистрирует пользователя. Непустой orgSlug - регистрация в организации
+// (если она разрешает регистрацию без приглашения) с ролью member. Если задана версия условий использования,
+// acceptedTOS должна с ней совпадать; принятие сохраняется вместе с пользователем.
+// captchaToken - токен CAPTCHA, обязателен, если она включена (WithCaptcha).
+// appID - приложение, через которое регистрируется пользователь (0 - без приложения); с WithUserApps
+// пользователь связывается с ним вместе с сохранением, без него appID не используется.
+func (a *AuthService) RegisterNewUser(
+	ctx context.Context,
+	email string,
+	pass string,
+	orgSlug string,
+	acceptedTOS string,
+	captchaToken string,
+	appID int,
+) (int64, error) {
 	const op = "auth.RegisterNewUser"
 
-	log := a.log.With(
+	if a.features.EnabledFor(features.NormalizedEmail, appID, normalizeEmail(email)) {
+		email = normalizeEmail(email)
+	}
+
+	log := logctx.FromOr(ctx, a.log).With(
 		slog.String("op", op),
 		slog.String("email", email),
 	)
 
 	log.Info("registering new user")
 
-	passHash, err := bcrypt.GenerateFromPassword([]byte(pass), bcrypt.DefaultCost)
+	// Самопроверка регистрирует пользователя с адресом в зарезервированном домене и пройти CAPTCHA не может
+	selfTest := selftest.From(ctx)
+
+	if a.captcha != nil && !selfTest {
+		if err := a.checkCaptcha(ctx, log, captchaToken); err != nil {
+			log.Info("captcha check failed", slog.String("error", err.Error()))
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventRegisterFailed, Outcome: audit.OutcomeFailure,
+				Email: email, Reason: captchaFailureReason(err),
+			})
+
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if appID == 0 && a.requireApp {
+		log.Info("registration without app rejected")
+
+		return 0, fmt.Errorf("%s: %w", op, ErrAppRequired)
+	}
+	if appID != 0 && a.userApps != nil {
+		if _, err := a.appProvider.App(ctx, appID); err != nil {
+			if errors.Is(err, storage.ErrAppNotFound) {
+				log.Warn("app not found", slog.Int("app_id", appID))
+
+				return 0, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+			}
+
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	var org models.Organization
+	if orgSlug != "" {
+		var err error
+		if org, err = a.orgBySlug(ctx, orgSlug); err != nil {
+			log.Warn("organization not found", slog.String("org", orgSlug), slog.String("error", err.Error()))
+
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if !org.AllowSelfSignup {
+			log.Info("organization is invite-only", slog.Int64("org_id", org.ID))
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventRegisterFailed, Outcome: audit.OutcomeFailure,
+				Email: email, Reason: "organization is invite-only",
+			})
+
+			return 0, fmt.Errorf("%s: %w", op, ErrSignupClosed)
+		}
+	}
+
+	tos := a.tosPolicy()
+	if tos.Version != "" && acceptedTOS != tos.Version {
+		log.Info("terms of service not accepted", slog.String("accepted", acceptedTOS))
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventRegisterFailed, Outcome: audit.OutcomeFailure,
+			Email: email, Reason: "terms of service not accepted",
+		})
+
+		return 0, fmt.Errorf("%s: %w", op, ErrTOSNotAccepted)
+	}
+
+	if !selfTest && !a.domains.Load().Allowed(email) {
+		log.Info("email domain rejected")
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventRegisterFailed, Outcome: audit.OutcomeFailure,
+			Email: email, Reason: "email domain not allowed",
+		})
+
+		return 0, fmt.Errorf("%s: %w", op, ErrDomainNotAllowed)
+	}
+
+	pass, err := prepareNewPassword(pass)
 	if err != nil {
-		log.Error("failed ot generate password hash", slog.String("error", err.Error()))
+		log.Info("password rejected", slog.String("error", err.Error()))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.checkBreached(ctx, pass); err != nil {
+		if errors.Is(err, ErrWeakPassword) {
+			log.Info("compromised password rejected")
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventRegisterFailed, Outcome: audit.OutcomeFailure,
+				Email: email, Reason: "compromised password",
+			})
+		} else {
+			log.Error("failed to check password", slog.String("error", err.Error()))
+		}
 
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	id, err := a.usrSaver.SaveUser(ctx, email, passHash)
+	passHash, err := a.hashPassword(ctx, pass)
+	if err != nil {
+		log.Error("failed to generate password hash", slog.String("error", err.Error()))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var id int64
+	err = a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		if org.ID != 0 {
+			id, err = a.orgs.SaveOrgUser(ctx, email, passHash, org.ID, a.emailScope(org), models.OrgRoleMember)
+		} else {
+			id, err = a.usrSaver.SaveUser(ctx, email, passHash)
+		}
+		if err != nil {
+			return events.Event{}, err
+		}
+
+		if tos.Version != "" {
+			if err := a.tosStore.AcceptTOS(ctx, id, tos.Version); err != nil {
+				return events.Event{}, err
+			}
+		}
+
+		if appID != 0 && a.userApps != nil {
+			if err := a.userApps.AddUserApp(ctx, id, appID, a.clock.Now()); err != nil {
+				return events.Event{}, err
+			}
+		}
+
+		return events.New(ctx, events.TypeUserRegistered, id), nil
+	})
 	if err != nil {
 		if errors.Is(err, storage.ErrUserExists) {
-			a.log.Warn("user not found", slog.String("error", err.Error()))
+			log.Warn("user already exists", slog.String("error", err.Error()))
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventRegisterFailed, Outcome: audit.OutcomeFailure,
+				Email: email, Reason: "user already exists",
+			})
 
 			return 0, fmt.Errorf("%s: %w", op, ErrUserExists)
 		}
 
-		log.Error("failed ot save user", slog.String("error", err.Error()))
+		log.Error("failed to save user", slog.String("error", err.Error()))
 
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
+	// Реплика может ещё не получить пользователя: до конца запроса читаем с основной БД
+	ctx = storage.PinPrimary(ctx)
 
 	log.Info("user registered")
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventUserRegistered, Outcome: audit.OutcomeSuccess,
+		UserID: id, Email: email,
+	})
 
 	return id, nil
 }
 
+// IsAdmin - является ли пользователь администратором; ErrUserNotFound, если пользователя нет
 func (a *AuthService) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 	const op = "Auth.IsAdmin"
 
-	log := a.log.With(
+	log := logctx.FromOr(ctx, a.log).With(
 		slog.String("op", op),
 		slog.Int64("user_id", userID))
 
@@ -160,9 +1093,11 @@ func (a *AuthService) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 		if errors.Is(err, storage.ErrUserNotFound) {
 			log.Warn("user not found", slog.String("error", err.Error()))
 
-			return false, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+			return false, fmt.Errorf("%s: %w", op, ErrUserNotFound)
 		}
 
+		log.Error("failed to check if user is admin", slog.String("error", err.Error()))
+
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
@@ -171,10 +1106,12 @@ func (a *AuthService) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 	return isAdmin, nil
 }
 
+// IsUserExists - есть ли пользователь. Отсутствие пользователя - ответ на вопрос, а не ошибка:
+// для него возвращается false без ошибки
 func (a *AuthService) IsUserExists(ctx context.Context, userID int64) (bool, error) {
 	const op = "Auth.IsUserExists"
 
-	log := a.log.With(
+	log := logctx.FromOr(ctx, a.log).With(
 		slog.String("op", op),
 		slog.Int64("user_id", userID))
 
@@ -183,15 +1120,412 @@ func (a *AuthService) IsUserExists(ctx context.Context, userID int64) (bool, err
 	isUserExists, err := a.usrProvider.IsUserExists(ctx, userID)
 	if err != nil {
 		if errors.Is(err, storage.ErrUserNotFound) {
-			log.Warn("user not found", slog.String("error", err.Error()))
+			log.Info("checked if user exists", slog.Bool("exists", false))
 
-			return false, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+			return false, nil
 		}
 
+		log.Error("failed to check if user exists", slog.String("error", err.Error()))
+
 		return false, fmt.Errorf("%s: %w", op, err)
 	}
 
 	log.Info("checked if user exists", slog.Bool("exists", isUserExists))
 
 	return isUserExists, nil
-}
\ No newline at end of file
+}
+
+// Authenticate - проверяет bearer-токен (подпись ключом приложения из app_id, сроки действия)
+// и возвращает вызывающего. Права администратора берутся из БД, а не из токена.
+func (a *AuthService) Authenticate(ctx context.Context, token string) (authctx.Principal, error) {
+	const op = "Auth.Authenticate"
+
+	appID, err := jwt.UnverifiedAppID(token)
+	if err != nil {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return authctx.Principal{}, fmt.Errorf("%s: %w: unknown app", op, ErrInvalidToken)
+		}
+
+		return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	claims, err := jwt.ParseForApp(token, app, jwt.WithLeeway(a.leeway), jwt.WithClock(a.clock.Now))
+	if err != nil {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+	if claims.Purpose != "" {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: action token", op, ErrInvalidToken)
+	}
+	denied, err := a.tokenDenied(ctx, claims.ID)
+	if err != nil {
+		return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if denied {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: token revoked", op, ErrInvalidToken)
+	}
+
+	// Стёртые и отключённые пользователи не проходят, даже если токен ещё не истёк
+	users := a.usrProvider
+	if a.validation != nil {
+		users = a.validation
+	}
+
+	user, err := users.UserByID(ctx, claims.UID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return authctx.Principal{}, fmt.Errorf("%s: %w: user not found", op, ErrInvalidToken)
+		}
+
+		return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.IsActive {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: user is not active", op, ErrInvalidToken)
+	}
+	// После UpgradeGuest гостевые токены не действуют: для входа есть обычный токен
+	if claims.Guest != user.IsGuest {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: guest status changed", op, ErrInvalidToken)
+	}
+	if err := a.checkSession(ctx, claims.SessionID, app); err != nil {
+		return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// Токен, выданный в разрешённой сети, не действует за её пределами
+	if app.RecheckNetworkPolicy && !networkPolicyAllows(ctx, app) {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: network is not allowed by app policy", op, ErrInvalidToken)
+	}
+	if claims.Offline {
+		if err := a.checkOfflineToken(ctx, claims.ID, claims.UID, app); err != nil {
+			return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	principal := authctx.Principal{
+		UserID:       claims.UID,
+		Email:        claims.Email,
+		AppID:        claims.AppID,
+		IsAdmin:      user.IsAdmin,
+		IsSuperadmin: user.IsSuperadmin,
+		IsGuest:      user.IsGuest,
+		SessionID:    claims.SessionID,
+		Offline:      claims.Offline,
+		AMR:          claims.AMR,
+		ACR:          claims.ACR,
+	}
+	if claims.AuthTime != nil {
+		principal.AuthTime = claims.AuthTime.Time
+	}
+	if claims.Scope != "" {
+		principal.Scopes = strings.Fields(claims.Scope)
+	}
+	if user.IsGuest {
+		a.touchGuest(ctx, user.ID)
+	}
+
+	// Роль в организации, как и права администратора, берётся из БД: исключённый участник теряет доступ сразу
+	if claims.OrgID != 0 {
+		if a.orgs == nil {
+			return authctx.Principal{}, fmt.Errorf("%s: %w: organizations are disabled", op, ErrInvalidToken)
+		}
+
+		role, err := a.orgs.MemberRole(ctx, claims.OrgID, user.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotMember) {
+				return authctx.Principal{}, fmt.Errorf("%s: %w: not a member of the organization", op, ErrInvalidToken)
+			}
+
+			return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+		}
+		principal.OrgID, principal.OrgRole = claims.OrgID, role
+	}
+
+	// Отзыв согласия отзывает и токены стороннего приложения
+	if app.ThirdParty {
+		var issuedAt time.Time
+		if claims.IssuedAt != nil {
+			issuedAt = claims.IssuedAt.Time
+		}
+
+		consent, err := a.checkConsent(ctx, user.ID, app.ID, issuedAt)
+		if err != nil {
+			if errors.Is(err, ErrConsentRequired) {
+				return authctx.Principal{}, fmt.Errorf("%s: %w: consent revoked", op, ErrInvalidToken)
+			}
+
+			return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+		}
+		// Права токена не шире согласия: после сужения согласия старые токены с лишними правами не действуют
+		if principal.Scopes != nil && a.features.Enabled(features.StrictScopes, app.ID, user.ID) {
+			if s := ungrantedScope(principal.Scopes, consent); s != "" {
+				return authctx.Principal{}, fmt.Errorf("%s: %w: scope %q is not granted", op, ErrInvalidToken, s)
+			}
+		}
+		principal.ThirdParty = true
+	}
+	if claims.ExpiresAt != nil {
+		principal.ExpiresAt = claims.ExpiresAt.Time
+	}
+
+	return principal, nil
+}
+
+// UsersBatch - получает пользователей по списку id одним запросом к хранилищу.
+// Отсутствующих пользователей в результате нет: вызывающий сам сверяет со списком id.
+func (a *AuthService) UsersBatch(ctx context.Context, userIDs []int64) (map[int64]models.User, error) {
+	const op = "Auth.UsersBatch"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("count", len(userIDs)))
+
+	if len(userIDs) > MaxBatchSize {
+		return nil, fmt.Errorf("%s: %w: %d > %d", op, ErrTooManyIDs, len(userIDs), MaxBatchSize)
+	}
+
+	users, err := a.usrProvider.UsersByIDs(ctx, userIDs)
+	if err != nil {
+		log.Error("failed to get users", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res := make(map[int64]models.User, len(users))
+	for _, u := range users {
+		res[u.ID] = u
+	}
+
+	log.Info("got users batch", slog.Int("found", len(res)))
+
+	return res, nil
+}
+
+// ExportUserData - собирает всё, что хранится о пользователе, в JSON (запрос субъекта данных).
+func (a *AuthService) ExportUserData(ctx context.Context, userID int64) ([]byte, error) {
+	const op = "Auth.ExportUserData"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	metadata, err := a.usrProvider.UserMetadata(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var appMetadata json.RawMessage
+	if a.appMetadata != nil {
+		if appMetadata, err = a.appMetadata.AllAppMetadata(ctx, userID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	var apps []int
+	if a.userApps != nil {
+		if apps, err = a.userApps.UserApps(ctx, userID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	data, err := json.Marshal(models.UserDataExport{
+		ExportedAt: a.clock.Now().UTC(),
+		Profile: models.UserProfile{
+			ID:       user.ID,
+			Email:    user.Email,
+			IsAdmin:  user.IsAdmin,
+			IsActive: user.IsActive,
+		},
+		Metadata:    json.RawMessage(metadata),
+		AppMetadata: appMetadata,
+		Apps:        apps,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("user data exported")
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventUserExported, Outcome: audit.OutcomeSuccess,
+		UserID: userID,
+	})
+
+	return data, nil
+}
+
+// EraseUser - обезличивает пользователя вместо удаления: uid и записи журнала безопасности сохраняются,
+// а войти или найти пользователя через IsUserExists больше нельзя. В отличие от RequestAccountDeletion
+// срока не ждёт; назначенное пользователем удаление при этом снимается.
+func (a *AuthService) EraseUser(ctx context.Context, userID int64) error {
+	const op = "Auth.EraseUser"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if err := a.eraseUser(ctx, userID, nil); err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		log.Error("failed to erase user", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("user erased")
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventUserErased, Outcome: audit.OutcomeSuccess,
+		UserID: userID,
+	})
+
+	return nil
+}
+
+// eraseUser - обезличивает пользователя и отзывает его токены. check (может быть nil) выполняется в той же
+// транзакции до изменения; его ошибка отменяет обезличивание
+func (a *AuthService) eraseUser(ctx context.Context, userID int64, check func(ctx context.Context) error) error {
+	tombstone := fmt.Sprintf("erased-%d@erased.invalid", userID)
+
+	err := a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		if check != nil {
+			if err := check(ctx); err != nil {
+				return events.Event{}, err
+			}
+		}
+		if err := a.usrSaver.EraseUser(ctx, userID, tombstone); err != nil {
+			return events.Event{}, err
+		}
+
+		return events.New(ctx, events.TypeUserDeleted, userID), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// Токены стёртого пользователя должны перестать проходить сразу, а не через TTL кеша
+	if a.validation != nil {
+		a.validation.Invalidate(userID)
+	}
+	a.recordRevocation(ctx, models.Revocation{Kind: models.RevocationUser, UserID: userID})
+
+	return nil
+}
+
+// ChangePassword - меняет пароль пользователя после проверки текущего.
+// Новый пароль не должен совпадать ни с текущим, ни с historySize предыдущими.
+func (a *AuthService) ChangePassword(ctx context.Context, email, oldPassword, newPassword string) error {
+	const op = "Auth.ChangePassword"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.String("email", email))
+
+	user, err := a.usrProvider.User(ctx, email)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		}
+
+		log.Error("failed to get user", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	ok, _, err := a.verifyPassword(ctx, user.PassHash, oldPassword)
+	if err != nil {
+		log.Warn("password check rejected", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !ok {
+		log.Info("invalid credentials")
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventPasswordChangeFailed, Outcome: audit.OutcomeFailure,
+			UserID: user.ID, Email: email, Reason: "invalid password",
+		})
+
+		return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	// История может хранить хеши ненормализованных паролей, поэтому повтор ищется по исходному вводу
+	rawPassword := newPassword
+	if newPassword, err = prepareNewPassword(newPassword); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.checkBreached(ctx, newPassword); err != nil {
+		if errors.Is(err, ErrWeakPassword) {
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventPasswordChangeFailed, Outcome: audit.OutcomeFailure,
+				UserID: user.ID, Email: email, Reason: "compromised password",
+			})
+		} else {
+			log.Error("failed to check password", slog.String("error", err.Error()))
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if a.historySize > 0 {
+		// Текущий пароль проверяем всегда, к нему - historySize предыдущих
+		recent := [][]byte{user.PassHash}
+
+		history, err := a.usrProvider.PasswordHistory(ctx, user.ID, a.historySize)
+		if err != nil {
+			log.Error("failed to get password history", slog.String("error", err.Error()))
+
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, h := range append(recent, history...) {
+			reused, _, err := a.verifyPassword(ctx, h, rawPassword)
+			if err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+			if reused {
+				a.audit.Emit(ctx, audit.Event{
+					Name: audit.EventPasswordChangeFailed, Outcome: audit.OutcomeFailure,
+					UserID: user.ID, Email: email, Reason: "password reused",
+				})
+
+				return fmt.Errorf("%s: %w", op, ErrPasswordReused)
+			}
+		}
+	}
+
+	passHash, err := a.hashPassword(ctx, newPassword)
+	if err != nil {
+		log.Error("failed to generate password hash", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.usrSaver.UpdatePassword(ctx, user.ID, passHash, a.historySize); err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		}
+
+		log.Error("failed to update password", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("password changed")
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventPasswordChanged, Outcome: audit.OutcomeSuccess,
+		UserID: user.ID, Email: email,
+	})
+
+	return nil
+}
@@ -0,0 +1,249 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testApp - приложение, для которого выдаются токены в тестах
+var testApp = models.App{ID: 1, Name: "test", Secret: "test-secret"}
+
+// newTestService - сервис на моках; моки сами проверяют в конце теста, что все ожидания выполнены
+func newTestService(t *testing.T, opts ...Option) (*AuthService, *mocks.UserSaver, *mocks.UserProvider, *mocks.AppProvider) {
+	t.Helper()
+
+	saver := mocks.NewUserSaver(t)
+	provider := mocks.NewUserProvider(t)
+	apps := mocks.NewAppProvider(t)
+
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), saver, provider, apps, time.Hour, opts...)
+
+	return a, saver, provider, apps
+}
+
+// userWithPassword - пользователь с bcrypt-хешем password (минимальная стоимость, чтобы тесты были быстрыми)
+func userWithPassword(t *testing.T, password string) models.User {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	return models.User{ID: 7, Email: "a@b.c", PassHash: hash, IsActive: true}
+}
+
+func TestRegisterNewUser_UserExists(t *testing.T) {
+	a, saver, _, _ := newTestService(t)
+
+	saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).
+		Return(0, fmt.Errorf("storage.sqlite.SaveUser: %w", storage.ErrUserExists))
+
+	_, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", "", "", 0)
+	require.ErrorIs(t, err, ErrUserExists)
+	assert.EqualError(t, err, "auth.RegisterNewUser: user already exists")
+}
+
+func TestRegisterNewUser_StorageErrorPropagated(t *testing.T) {
+	a, saver, _, _ := newTestService(t)
+
+	dbErr := errors.New("disk I/O error")
+	saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).
+		Return(0, fmt.Errorf("storage.sqlite.SaveUser: %w", dbErr))
+
+	_, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", "", "", 0)
+	require.ErrorIs(t, err, dbErr)
+	assert.EqualError(t, err, "auth.RegisterNewUser: storage.sqlite.SaveUser: disk I/O error")
+}
+
+func TestRegisterNewUser_HashesPassword(t *testing.T) {
+	a, saver, _, _ := newTestService(t)
+
+	saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).
+		RunAndReturn(func(_ context.Context, _ string, passHash []byte) (int64, error) {
+			assert.NoError(t, bcrypt.CompareHashAndPassword(passHash, []byte("secret")))
+
+			return 42, nil
+		})
+
+	id, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", "", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+}
+
+func TestLogin(t *testing.T) {
+	a, _, provider, apps := newTestService(t)
+	user := userWithPassword(t, "secret")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	token, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, 2*time.Second)
+
+	claims, err := jwt.Parse(token.AccessToken, []byte(testApp.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UID)
+	assert.Equal(t, testApp.ID, claims.AppID)
+}
+
+func TestLogin_WrongPassword(t *testing.T) {
+	// AppProvider не настроен: при неверном пароле до него дело дойти не должно
+	a, _, provider, _ := newTestService(t)
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(userWithPassword(t, "secret"), nil)
+
+	_, err := a.Login(context.Background(), "a@b.c", "wrong", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.EqualError(t, err, "Auth.Login: invalid credentials")
+}
+
+func TestLogin_UserNotFound(t *testing.T) {
+	a, _, provider, _ := newTestService(t)
+
+	provider.EXPECT().User(mock.Anything, "missing@b.c").
+		Return(models.User{}, fmt.Errorf("storage.sqlite.User: %w", storage.ErrUserNotFound))
+
+	_, err := a.Login(context.Background(), "missing@b.c", "secret", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials, "unknown email must look like a wrong password")
+	assert.NotErrorIs(t, err, storage.ErrUserNotFound, "storage error must not leak")
+}
+
+func TestLogin_AppProviderFailure(t *testing.T) {
+	tests := []struct {
+		name    string
+		appErr  error
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name:    "unknown app",
+			appErr:  fmt.Errorf("storage.sqlite.App: %w", storage.ErrAppNotFound),
+			wantErr: ErrInvalidAppID,
+			wantMsg: "Auth.Login: invalid app id",
+		},
+		{
+			name:    "storage failure",
+			appErr:  fmt.Errorf("storage.sqlite.App: %w", context.DeadlineExceeded),
+			wantErr: context.DeadlineExceeded,
+			wantMsg: "Auth.Login: storage.sqlite.App: context deadline exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _, provider, apps := newTestService(t)
+
+			provider.EXPECT().User(mock.Anything, "a@b.c").Return(userWithPassword(t, "secret"), nil)
+			apps.EXPECT().App(mock.Anything, 5).Return(models.App{}, tt.appErr)
+
+			_, err := a.Login(context.Background(), "a@b.c", "secret", 5, "", "", "")
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.EqualError(t, err, tt.wantMsg)
+		})
+	}
+}
+
+func TestIsAdmin_UserNotFound(t *testing.T) {
+	a, _, provider, _ := newTestService(t)
+
+	provider.EXPECT().IsAdmin(mock.Anything, int64(404)).
+		Return(false, fmt.Errorf("storage.sqlite.IsAdmin: %w", storage.ErrUserNotFound))
+
+	_, err := a.IsAdmin(context.Background(), 404)
+	require.ErrorIs(t, err, ErrUserNotFound)
+	assert.EqualError(t, err, "Auth.IsAdmin: user not found")
+}
+
+func TestIsAdmin_StorageErrors(t *testing.T) {
+	dbErr := errors.New("database is locked")
+
+	tests := []struct {
+		name       string
+		storageErr error
+		wantErr    error
+		wantMsg    string
+	}{
+		{
+			name:       "user not found",
+			storageErr: fmt.Errorf("storage.sqlite.IsAdmin: %w", storage.ErrUserNotFound),
+			wantErr:    ErrUserNotFound,
+			wantMsg:    "Auth.IsAdmin: user not found",
+		},
+		{
+			name:       "storage failure",
+			storageErr: fmt.Errorf("storage.sqlite.IsAdmin: %w", dbErr),
+			wantErr:    dbErr,
+			wantMsg:    "Auth.IsAdmin: storage.sqlite.IsAdmin: database is locked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _, provider, _ := newTestService(t)
+			provider.EXPECT().IsAdmin(mock.Anything, int64(7)).Return(false, tt.storageErr)
+
+			_, err := a.IsAdmin(context.Background(), 7)
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.NotErrorIs(t, err, ErrInvalidAppID)
+			assert.EqualError(t, err, tt.wantMsg)
+		})
+	}
+}
+
+func TestIsUserExists_StorageErrors(t *testing.T) {
+	dbErr := errors.New("database is locked")
+
+	t.Run("user not found", func(t *testing.T) {
+		a, _, provider, _ := newTestService(t)
+		provider.EXPECT().IsUserExists(mock.Anything, int64(404)).
+			Return(false, fmt.Errorf("storage.sqlite.IsUserExists: %w", storage.ErrUserNotFound))
+
+		exists, err := a.IsUserExists(context.Background(), 404)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("storage failure", func(t *testing.T) {
+		a, _, provider, _ := newTestService(t)
+		provider.EXPECT().IsUserExists(mock.Anything, int64(7)).
+			Return(false, fmt.Errorf("storage.sqlite.IsUserExists: %w", dbErr))
+
+		_, err := a.IsUserExists(context.Background(), 7)
+		require.ErrorIs(t, err, dbErr)
+		assert.NotErrorIs(t, err, ErrUserNotFound)
+		assert.NotErrorIs(t, err, ErrInvalidAppID)
+		assert.EqualError(t, err, "Auth.IsUserExists: storage.sqlite.IsUserExists: database is locked")
+	})
+}
+
+// invalidations - ValidationCache поверх мока, запоминающий сброшенные id
+type invalidations struct {
+	*mocks.UserProvider
+	ids []int64
+}
+
+func (c *invalidations) Invalidate(userID int64) { c.ids = append(c.ids, userID) }
+
+func TestEraseUser_InvalidatesValidationCache(t *testing.T) {
+	cache := &invalidations{UserProvider: mocks.NewUserProvider(t)}
+	a, saver, _, _ := newTestService(t, WithValidationCache(cache))
+
+	saver.EXPECT().EraseUser(mock.Anything, int64(7), "erased-7@erased.invalid").Return(nil)
+
+	require.NoError(t, a.EraseUser(context.Background(), 7))
+	assert.Equal(t, []int64{7}, cache.ids)
+}
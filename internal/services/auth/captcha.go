@@ -0,0 +1,219 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/lib/captcha"
+	"sso/internal/lib/clientip"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrCaptchaRequired    = errors.New("captcha required")          // Ошибка, если запрос без токена CAPTCHA, а он нужен.
+	ErrCaptchaInvalid     = captcha.ErrRejected                     // Ошибка, если провайдер не принял токен CAPTCHA.
+	ErrCaptchaUnavailable = errors.New("captcha check unavailable") // Ошибка, если провайдер CAPTCHA недоступен, а пропускать без проверки нельзя.
+)
+
+// maxFailureKeys - сколько email и адресов считать одновременно. Ключи приходят от клиента,
+// и без предела перебор адресов раздувал бы память
+const maxFailureKeys = 100000
+
+// overflowFailures - общий счётчик для ключей, которым не хватило отдельного
+const overflowFailures = "other"
+
+// CaptchaVerifier - проверка токена CAPTCHA у провайдера (captcha.Verifier)
+type CaptchaVerifier interface {
+	// Verify - ErrCaptchaInvalid, если токен не принят; другие ошибки - провайдер недоступен.
+	// remoteIP - адрес клиента (пусто - неизвестен)
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// CaptchaSettings - когда требовать CAPTCHA
+type CaptchaSettings struct {
+	LoginFailures int           // После скольких неудачных входов для email или адреса вход требует CAPTCHA (0 - всегда)
+	FailureWindow time.Duration // Окно, в котором считаются неудачные входы
+	FailOpen      bool          // Пропускать запрос, если провайдер недоступен
+}
+
+// WithCaptcha - регистрация всегда требует CAPTCHA, вход - после settings.LoginFailures неудачных попыток
+// для email или адреса клиента. Неудачные входы считаются в памяти процесса
+func WithCaptcha(v CaptchaVerifier, s CaptchaSettings) Option {
+	return func(a *AuthService) {
+		a.captcha = v
+		a.captchaPolicy = s
+		// Часы читаются при каждом вызове: WithClock может идти в опциях после WithCaptcha
+		a.loginFailures = newLoginFailures(s.FailureWindow, func() time.Time { return a.clock.Now() })
+	}
+}
+
+// loginCaptchaRequired - нужна ли CAPTCHA для входа с email: слишком много неудачных попыток
+// для этого email или с адреса клиента
+func (a *AuthService) loginCaptchaRequired(ctx context.Context, email string) bool {
+	if a.captcha == nil {
+		return false
+	}
+
+	return a.loginFailures.count(failureKeys(ctx, email)...) >= a.captchaPolicy.LoginFailures
+}
+
+// countLoginFailure - учитывает неудачный вход для email и адреса клиента
+func (a *AuthService) countLoginFailure(ctx context.Context, email string) {
+	if a.captcha == nil {
+		return
+	}
+
+	a.loginFailures.add(failureKeys(ctx, email)...)
+}
+
+// resetLoginFailures - сбрасывает счётчик email после верного пароля. Счётчик адреса не сбрасывается:
+// иначе перебор паролей к разным email перемежался бы входом в свою учётную запись
+func (a *AuthService) resetLoginFailures(email string) {
+	if a.captcha == nil {
+		return
+	}
+
+	a.loginFailures.reset(emailFailureKey(email))
+}
+
+// checkCaptcha - проверяет токен CAPTCHA. Если провайдер недоступен, запрос пропускается
+// только при FailOpen
+func (a *AuthService) checkCaptcha(ctx context.Context, log *slog.Logger, token string) error {
+	if token == "" {
+		return ErrCaptchaRequired
+	}
+
+	var remoteIP string
+	if addr, ok := clientip.From(ctx); ok {
+		remoteIP = addr.String()
+	}
+
+	err := a.captcha.Verify(ctx, token, remoteIP)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ErrCaptchaInvalid):
+		return err
+	case a.captchaPolicy.FailOpen:
+		log.Warn("captcha provider unavailable, request let through", slog.String("error", err.Error()))
+
+		return nil
+	default:
+		log.Error("captcha provider unavailable", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%w: %s", ErrCaptchaUnavailable, err)
+	}
+}
+
+// captchaFailureReason - причина отказа для журнала аудита
+func captchaFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrCaptchaRequired):
+		return "captcha required"
+	case errors.Is(err, ErrCaptchaInvalid):
+		return "captcha rejected"
+	default:
+		return "captcha unavailable"
+	}
+}
+
+// failureKeys - ключи счётчиков неудачных входов: email и, если известен, адрес клиента
+func failureKeys(ctx context.Context, email string) []string {
+	keys := []string{emailFailureKey(email)}
+	if addr, ok := clientip.From(ctx); ok {
+		keys = append(keys, "ip:"+addr.String())
+	}
+
+	return keys
+}
+
+func emailFailureKey(email string) string {
+	return "email:" + strings.ToLower(email)
+}
+
+// loginFailures - неудачные входы по ключам в фиксированном окне
+type loginFailures struct {
+	window time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	counts map[string]*failureCount
+}
+
+type failureCount struct {
+	n     int
+	since time.Time // начало окна
+}
+
+func newLoginFailures(window time.Duration, now func() time.Time) *loginFailures {
+	return &loginFailures{window: window, now: now, counts: make(map[string]*failureCount)}
+}
+
+// add - учитывает неудачу для каждого ключа
+func (f *loginFailures) add(keys ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	for _, key := range keys {
+		c, ok := f.counts[key]
+		if !ok {
+			if len(f.counts) >= maxFailureKeys {
+				f.prune(now)
+			}
+			if len(f.counts) >= maxFailureKeys {
+				key = overflowFailures
+				c, ok = f.counts[key]
+			}
+			if !ok {
+				c = &failureCount{since: now}
+				f.counts[key] = c
+			}
+		}
+
+		if now.Sub(c.since) >= f.window {
+			c.n, c.since = 0, now
+		}
+		c.n++
+	}
+}
+
+// count - наибольшее число неудач среди ключей. Для ключа без своего счётчика при переполнении
+// учитывается общий
+func (f *loginFailures) count(keys ...string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	n := 0
+	for _, key := range keys {
+		c, ok := f.counts[key]
+		if !ok {
+			c, ok = f.counts[overflowFailures]
+		}
+		if ok && now.Sub(c.since) < f.window {
+			n = max(n, c.n)
+		}
+	}
+
+	return n
+}
+
+func (f *loginFailures) reset(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.counts, key)
+}
+
+// prune - удаляет счётчики, окно которых закончилось
+func (f *loginFailures) prune(now time.Time) {
+	for key, c := range f.counts {
+		if now.Sub(c.since) >= f.window {
+			delete(f.counts, key)
+		}
+	}
+}
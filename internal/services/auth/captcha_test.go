@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"sso/internal/lib/captcha"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/clock"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCaptcha - провайдер, принимающий только токен "human"; err - провайдер недоступен
+type fakeCaptcha struct {
+	err      error
+	remoteIP string
+}
+
+func (f *fakeCaptcha) Verify(_ context.Context, token, remoteIP string) error {
+	f.remoteIP = remoteIP
+	if f.err != nil {
+		return f.err
+	}
+	if token != "human" {
+		return fmt.Errorf("captcha.Verify: %w", captcha.ErrRejected)
+	}
+
+	return nil
+}
+
+func TestLogin_CaptchaAfterFailures(t *testing.T) {
+	verifier := &fakeCaptcha{}
+	a, _, provider, apps := newTestService(t, WithCaptcha(verifier, CaptchaSettings{LoginFailures: 2, FailureWindow: time.Hour}))
+	user := userWithPassword(t, "secret")
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	ctx := clientip.Into(context.Background(), netip.MustParseAddr("203.0.113.7"))
+
+	for range 2 {
+		_, err := a.Login(ctx, "a@b.c", "wrong", testApp.ID, "", "", "")
+		require.ErrorIs(t, err, ErrInvalidCredentials)
+	}
+
+	// Даже верный пароль не проверяется без CAPTCHA
+	_, err := a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrCaptchaRequired)
+	_, err = a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "bot")
+	require.ErrorIs(t, err, ErrCaptchaInvalid)
+
+	_, err = a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "human")
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", verifier.remoteIP)
+
+	// Верный пароль сбрасывает счётчик email, но не адреса: с него CAPTCHA нужна для любого email
+	_, err = a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	_, err = a.Login(ctx, "other@b.c", "secret", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrCaptchaRequired)
+}
+
+func TestRegisterNewUser_Captcha(t *testing.T) {
+	verifier := &fakeCaptcha{}
+	a, saver, _, _ := newTestService(t, WithCaptcha(verifier, CaptchaSettings{LoginFailures: 3, FailureWindow: time.Hour}))
+	saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).Return(42, nil)
+	ctx := context.Background()
+
+	_, err := a.RegisterNewUser(ctx, "a@b.c", "secret", "", "", "", 0)
+	require.ErrorIs(t, err, ErrCaptchaRequired)
+
+	// Недоступный провайдер без fail_open - отказ
+	verifier.err = errors.New("context deadline exceeded")
+	_, err = a.RegisterNewUser(ctx, "a@b.c", "secret", "", "", "human", 0)
+	require.ErrorIs(t, err, ErrCaptchaUnavailable)
+
+	verifier.err = nil
+	id, err := a.RegisterNewUser(ctx, "a@b.c", "secret", "", "", "human", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+}
+
+func TestCaptcha_FailOpen(t *testing.T) {
+	verifier := &fakeCaptcha{err: errors.New("connection refused")}
+	a, saver, _, _ := newTestService(t, WithCaptcha(verifier, CaptchaSettings{FailureWindow: time.Hour, FailOpen: true}))
+	saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).Return(42, nil)
+
+	// Отсутствие токена - не сбой провайдера: fail_open его не пропускает
+	_, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", "", "", 0)
+	require.ErrorIs(t, err, ErrCaptchaRequired)
+
+	_, err = a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", "", "token", 0)
+	require.NoError(t, err)
+}
+
+func TestLoginFailures_Window(t *testing.T) {
+	now := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	f := newLoginFailures(time.Minute, now.Now)
+
+	f.add("email:a@b.c", "ip:203.0.113.7")
+	f.add("ip:203.0.113.7")
+	assert.Equal(t, 2, f.count("email:a@b.c", "ip:203.0.113.7"))
+	assert.Equal(t, 1, f.count("email:a@b.c"))
+
+	now.Advance(time.Minute)
+	assert.Zero(t, f.count("email:a@b.c", "ip:203.0.113.7"))
+
+	f.add("email:a@b.c")
+	assert.Equal(t, 1, f.count("email:a@b.c"))
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/clock"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var clockStart = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestClock_TokenExpiresWithoutWaiting(t *testing.T) {
+	now := clock.NewFake(clockStart)
+	guests := mocks.NewGuestStore(t)
+	a, _, provider, apps := newTestService(t, WithClock(now), WithGuests(guests, 10*time.Minute))
+	ctx := context.Background()
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	guests.EXPECT().SaveGuest(mock.Anything, mock.Anything).Return(42, nil)
+
+	_, token, err := a.CreateGuest(ctx, testApp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, clockStart.Add(10*time.Minute), token.ExpiresAt.UTC())
+
+	guest := models.User{ID: 42, IsActive: true, IsGuest: true}
+	provider.EXPECT().UserByID(mock.Anything, int64(42)).Return(guest, nil).Once()
+	guests.EXPECT().TouchGuest(mock.Anything, int64(42)).Return(nil).Once()
+
+	// На границе exp + leeway токен ещё действует
+	now.Advance(10*time.Minute + jwt.DefaultLeeway)
+	_, err = a.Authenticate(ctx, token.AccessToken)
+	require.NoError(t, err)
+
+	now.Advance(time.Second)
+	_, err = a.Authenticate(ctx, token.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestClock_DeviceCodeExpires(t *testing.T) {
+	now := clock.NewFake(clockStart)
+	devices := memDevices{}
+	a, _, _, apps := newTestService(t, WithClock(now), WithDeviceAuthorization(devices, deviceSettings))
+	ctx := context.Background()
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	dc, err := a.StartDeviceAuthorization(ctx, testApp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, clockStart.Add(DefaultDeviceCodeTTL), devices[1].ExpiresAt)
+
+	now.Advance(DefaultDeviceCodeTTL + time.Second)
+	require.ErrorIs(t, a.ApproveDevice(ctx, 7, dc.UserCode), ErrDeviceCodeExpired)
+}
+
+func TestClock_ActionTokenExpires(t *testing.T) {
+	now := clock.NewFake(clockStart)
+	store := memActionTokens{}
+	a, _, provider, _ := newTestService(t, WithClock(now), WithActionTokens(store, actionSecret))
+	ctx := context.Background()
+
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, IsActive: true}, nil).Once()
+
+	// Выпущенный на фальшивых часах токен действует на них же, а срок в хранилище считается от того же момента
+	token, err := a.IssueActionToken(ctx, 7, "export.download", time.Minute, nil)
+	require.NoError(t, err)
+	for _, saved := range store {
+		assert.Equal(t, clockStart.Add(time.Minute), saved.ExpiresAt.UTC())
+	}
+
+	userID, _, err := a.ConsumeActionToken(ctx, token, "export.download")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), userID)
+
+	expiring, err := a.IssueActionToken(ctx, 7, "export.download", time.Minute, nil)
+	require.NoError(t, err)
+
+	now.Advance(time.Minute + time.Second)
+	_, _, err = a.ConsumeActionToken(ctx, expiring, "export.download")
+	require.ErrorIs(t, err, ErrInvalidActionToken)
+	require.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
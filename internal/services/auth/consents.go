@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/events"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"time"
+)
+
+// Ошибки согласий
+var (
+	ErrConsentRequired  = errors.New("consent required")       // Ошибка, если стороннему приложению нужно согласие пользователя.
+	ErrConsentNotFound  = errors.New("consent not found")      // Ошибка, если действующего согласия нет.
+	ErrConsentsDisabled = errors.New("consents are disabled")  // Ошибка, если хранилище согласий не задано.
+	ErrInvalidScopes    = errors.New("invalid consent scopes") // Ошибка, если scopes пустые или некорректные.
+)
+
+// scopeRe - допустимое имя scope согласия
+var scopeRe = regexp.MustCompile(`^[a-z][a-z0-9_.:-]{0,63}$`)
+
+// ConsentStore - хранилище согласий пользователей для сторонних приложений.
+type ConsentStore interface {
+	// GrantConsent - сохраняет согласие (для действующего меняет только scopes).
+	GrantConsent(ctx context.Context, userID int64, appID int, scopes []string) (models.Consent, error)
+	// ActiveConsent - действующее согласие (storage.ErrConsentNotFound, если его нет).
+	ActiveConsent(ctx context.Context, userID int64, appID int) (models.Consent, error)
+	// ListConsents - все согласия пользователя, включая отозванные.
+	ListConsents(ctx context.Context, userID int64) ([]models.Consent, error)
+	// RevokeConsent - отзывает действующее согласие (storage.ErrConsentNotFound, если его нет).
+	RevokeConsent(ctx context.Context, userID int64, appID int) error
+}
+
+// WithConsents - включает согласия для сторонних приложений (models.App.ThirdParty).
+// Без хранилища вход в сторонние приложения невозможен: согласие негде проверить.
+func WithConsents(store ConsentStore) Option {
+	return func(a *AuthService) {
+		a.consents = store
+	}
+}
+
+// GrantConsent - записывает согласие пользователя передавать приложению данные из scopes.
+// После этого Login в стороннее приложение выдаёт токены.
+func (a *AuthService) GrantConsent(ctx context.Context, userID int64, appID int, scopes []string) (models.Consent, error) {
+	const op = "Auth.GrantConsent"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.Int("app_id", appID))
+
+	if a.consents == nil {
+		return models.Consent{}, fmt.Errorf("%s: %w", op, ErrConsentsDisabled)
+	}
+
+	if len(scopes) == 0 {
+		return models.Consent{}, fmt.Errorf("%s: %w: at least one scope is required", op, ErrInvalidScopes)
+	}
+	for _, scope := range scopes {
+		if !scopeRe.MatchString(scope) {
+			return models.Consent{}, fmt.Errorf("%s: %w: %q", op, ErrInvalidScopes, scope)
+		}
+	}
+
+	if _, err := a.appProvider.App(ctx, appID); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.Consent{}, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return models.Consent{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	consent, err := a.consents.GrantConsent(ctx, userID, appID, scopes)
+	if err != nil {
+		log.Error("failed to save consent", slog.String("error", err.Error()))
+
+		return models.Consent{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("consent granted", slog.Any("scopes", scopes))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventConsentGranted, Outcome: audit.OutcomeSuccess,
+		UserID: userID, AppID: appID,
+	})
+
+	return consent, nil
+}
+
+// ListConsents - согласия пользователя, включая отозванные.
+func (a *AuthService) ListConsents(ctx context.Context, userID int64) ([]models.Consent, error) {
+	const op = "Auth.ListConsents"
+
+	if a.consents == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrConsentsDisabled)
+	}
+
+	consents, err := a.consents.ListConsents(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return consents, nil
+}
+
+// RevokeConsent - отзывает согласие пользователя для приложения. Выданные приложению токены
+// пользователя сразу перестают проходить проверку (см. checkConsent), а событие token.revoked
+// сообщает приложению, что его сессии этого пользователя нужно завершить.
+func (a *AuthService) RevokeConsent(ctx context.Context, userID int64, appID int) error {
+	const op = "Auth.RevokeConsent"
+
+	if a.consents == nil {
+		return fmt.Errorf("%s: %w", op, ErrConsentsDisabled)
+	}
+
+	err := a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		if err := a.consents.RevokeConsent(ctx, userID, appID); err != nil {
+			return events.Event{}, err
+		}
+
+		revoked := events.New(ctx, events.TypeTokenRevoked, userID)
+		revoked.AppID = appID
+
+		return revoked, nil
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrConsentNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrConsentNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.recordRevocation(ctx, models.Revocation{Kind: models.RevocationUser, UserID: userID, AppID: appID})
+
+	logctx.FromOr(ctx, a.log).Info("consent revoked",
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.Int("app_id", appID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventConsentRevoked, Outcome: audit.OutcomeSuccess,
+		UserID: userID, AppID: appID,
+	})
+
+	return nil
+}
+
+// checkConsent - есть ли у пользователя действующее согласие для стороннего приложения, выданное
+// не позже issuedAt (нулевое значение - без проверки времени). Токен, выданный до нового согласия,
+// не оживает после повторного согласия: его отозвали вместе с прежним. Нет согласия - ErrConsentRequired.
+// Возвращает найденное согласие, чтобы вызывающий мог сверить с ним права токена
+func (a *AuthService) checkConsent(ctx context.Context, userID int64, appID int, issuedAt time.Time) (models.Consent, error) {
+	if a.consents == nil {
+		return models.Consent{}, ErrConsentRequired
+	}
+
+	consent, err := a.consents.ActiveConsent(ctx, userID, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrConsentNotFound) {
+			return models.Consent{}, ErrConsentRequired
+		}
+
+		return models.Consent{}, err
+	}
+
+	if !issuedAt.IsZero() && issuedAt.Before(consent.GrantedAt) {
+		return models.Consent{}, ErrConsentRequired
+	}
+
+	return consent, nil
+}
+
+// ungrantedScope - первое из прав scopes, которого нет в согласии (пусто - все права согласованы)
+func ungrantedScope(scopes []string, consent models.Consent) string {
+	for _, s := range scopes {
+		if !slices.Contains(consent.Scopes, s) {
+			return s
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/events"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// partnerApp - стороннее приложение, вход в которое требует согласия
+var partnerApp = models.App{ID: 2, Name: "partner", Secret: "partner-secret", ThirdParty: true}
+
+func TestLogin_ConsentRequired(t *testing.T) {
+	consents := mocks.NewConsentStore(t)
+	a, _, provider, apps := newTestService(t, WithConsents(consents))
+	user := userWithPassword(t, "secret")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, partnerApp.ID).Return(partnerApp, nil)
+	consents.EXPECT().ActiveConsent(mock.Anything, user.ID, partnerApp.ID).
+		Return(models.Consent{}, fmt.Errorf("storage.sqlite.ActiveConsent: %w", storage.ErrConsentNotFound)).Once()
+
+	_, err := a.Login(context.Background(), "a@b.c", "secret", partnerApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrConsentRequired)
+
+	consents.EXPECT().ActiveConsent(mock.Anything, user.ID, partnerApp.ID).
+		Return(models.Consent{UserID: user.ID, AppID: partnerApp.ID, GrantedAt: time.Now()}, nil).Once()
+
+	_, err = a.Login(context.Background(), "a@b.c", "secret", partnerApp.ID, "", "", "")
+	require.NoError(t, err)
+}
+
+func TestLogin_FirstPartyBypassesConsent(t *testing.T) {
+	// ConsentStore без ожиданий: внутреннее приложение согласий не проверяет
+	consents := mocks.NewConsentStore(t)
+	a, _, provider, apps := newTestService(t, WithConsents(consents))
+	user := userWithPassword(t, "secret")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	_, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+
+	// Без хранилища согласий стороннее приложение недоступно, а внутреннее - как обычно
+	a, _, provider, apps = newTestService(t)
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, partnerApp.ID).Return(partnerApp, nil)
+
+	_, err = a.Login(context.Background(), "a@b.c", "secret", partnerApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrConsentRequired)
+}
+
+func TestAuthenticate_ConsentRevoked(t *testing.T) {
+	consents := mocks.NewConsentStore(t)
+	a, _, provider, apps := newTestService(t, WithConsents(consents))
+	user := userWithPassword(t, "secret")
+
+	token, err := jwt.NewToken(user, partnerApp, time.Hour)
+	require.NoError(t, err)
+
+	apps.EXPECT().App(mock.Anything, partnerApp.ID).Return(partnerApp, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+
+	granted := models.Consent{UserID: user.ID, AppID: partnerApp.ID, GrantedAt: time.Now().Add(-time.Minute)}
+	consents.EXPECT().ActiveConsent(mock.Anything, user.ID, partnerApp.ID).Return(granted, nil).Once()
+
+	p, err := a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.True(t, p.ThirdParty)
+
+	// Согласие отозвано - токен больше не действует
+	consents.EXPECT().ActiveConsent(mock.Anything, user.ID, partnerApp.ID).
+		Return(models.Consent{}, fmt.Errorf("storage.sqlite.ActiveConsent: %w", storage.ErrConsentNotFound)).Once()
+
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Новое согласие не возвращает силу токенам, выданным до него
+	regranted := models.Consent{UserID: user.ID, AppID: partnerApp.ID, GrantedAt: time.Now().Add(time.Minute)}
+	consents.EXPECT().ActiveConsent(mock.Anything, user.ID, partnerApp.ID).Return(regranted, nil).Once()
+
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRevokeConsent_PublishesTokenRevoked(t *testing.T) {
+	consents := mocks.NewConsentStore(t)
+	pub := mocks.NewPublisher(t)
+	a, _, _, _ := newTestService(t, WithConsents(consents), WithEventPublisher(pub))
+
+	consents.EXPECT().RevokeConsent(mock.Anything, int64(7), partnerApp.ID).Return(nil)
+	pub.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e events.Event) bool {
+		return e.Type == events.TypeTokenRevoked && e.UserID == 7 && e.AppID == partnerApp.ID
+	})).Return(nil)
+
+	require.NoError(t, a.RevokeConsent(context.Background(), 7, partnerApp.ID))
+
+	consents.EXPECT().RevokeConsent(mock.Anything, int64(8), partnerApp.ID).
+		Return(fmt.Errorf("storage.sqlite.RevokeConsent: %w", storage.ErrConsentNotFound))
+
+	err := a.RevokeConsent(context.Background(), 8, partnerApp.ID)
+	require.ErrorIs(t, err, ErrConsentNotFound)
+}
+
+func TestGrantConsent_InvalidScopes(t *testing.T) {
+	// Ни приложение, ни хранилище не вызываются
+	a, _, _, _ := newTestService(t, WithConsents(mocks.NewConsentStore(t)))
+
+	for _, scopes := range [][]string{nil, {""}, {"email", "Profile"}, {"email profile"}} {
+		_, err := a.GrantConsent(context.Background(), 7, partnerApp.ID, scopes)
+		require.ErrorIs(t, err, ErrInvalidScopes, "scopes %q", scopes)
+	}
+}
@@ -0,0 +1,260 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/events"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/mail"
+	"sso/internal/storage"
+	"time"
+)
+
+// DefaultDeletionGracePeriod - сколько аккаунт ждёт удаления после запроса, если срок не задан в WithAccountDeletion
+const DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+// Ошибки удаления аккаунта по запросу пользователя
+var (
+	ErrAccountDeletionDisabled = errors.New("account deletion is disabled")     // Ошибка, если хранилище запросов удаления не задано.
+	ErrNoPendingDeletion       = errors.New("no pending account deletion")      // Ошибка, если удаление не запрошено или токен отмены неверный.
+	ErrGuestDeletion           = errors.New("guest accounts cannot be deleted") // Ошибка, если удаление запрашивает гость (у него нет email для отмены).
+)
+
+// AccountDeletionStore - хранилище запросов удаления аккаунта.
+type AccountDeletionStore interface {
+	// SaveAccountDeletion - сохраняет запрос (повторный запрос пользователя заменяет прежний).
+	SaveAccountDeletion(ctx context.Context, d models.AccountDeletion) error
+	// AccountDeletion - ожидающее удаление пользователя (storage.ErrAccountDeletionNotFound, если его нет).
+	AccountDeletion(ctx context.Context, userID int64) (models.AccountDeletion, error)
+	// AccountDeletionByTokenHash - ожидающее удаление по SHA-256 токена отмены (storage.ErrAccountDeletionNotFound).
+	AccountDeletionByTokenHash(ctx context.Context, tokenHash string) (models.AccountDeletion, error)
+	// DeleteAccountDeletion - отменяет ожидающее удаление (storage.ErrAccountDeletionNotFound, если его нет).
+	DeleteAccountDeletion(ctx context.Context, userID int64) error
+	// DueAccountDeletions - до limit пользователей, срок удаления которых наступил до before.
+	DueAccountDeletions(ctx context.Context, before time.Time, limit int) ([]int64, error)
+}
+
+// WithAccountDeletion - включает удаление аккаунта по запросу пользователя. grace - сколько аккаунт ждёт
+// удаления (0 - DefaultDeletionGracePeriod); cancelURL - страница отмены, к которой в письме добавляется
+// ?token=... (пусто - в письме только код). Удаление администратором (EraseUser) срока не ждёт.
+func WithAccountDeletion(store AccountDeletionStore, grace time.Duration, cancelURL string) Option {
+	return func(a *AuthService) {
+		if grace <= 0 {
+			grace = DefaultDeletionGracePeriod
+		}
+		a.deletions = store
+		a.deletionGrace = grace
+		a.deletionCancelURL = cancelURL
+	}
+}
+
+// RequestAccountDeletion - назначает удаление аккаунта через срок из WithAccountDeletion и отправляет
+// письмо со ссылкой отмены. До удаления пользователь входит как обычно, а ответ на вход сообщает, когда
+// аккаунт будет удалён. Повторный запрос назначает срок заново, прежняя ссылка отмены перестаёт действовать.
+func (a *AuthService) RequestAccountDeletion(ctx context.Context, userID int64) (models.AccountDeletion, error) {
+	const op = "Auth.RequestAccountDeletion"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if a.deletions == nil || a.mailer == nil {
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, ErrAccountDeletionDisabled)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if user.IsGuest {
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, ErrGuestDeletion)
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := a.clock.Now().UTC().Truncate(time.Second)
+	d := models.AccountDeletion{
+		UserID:      userID,
+		TokenHash:   hashInvitationToken(token),
+		RequestedAt: now,
+		ExecuteAt:   now.Add(a.deletionGrace),
+	}
+
+	err = a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		if err := a.deletions.SaveAccountDeletion(ctx, d); err != nil {
+			return events.Event{}, err
+		}
+
+		return events.New(ctx, events.TypeAccountDeletionRequested, userID), nil
+	})
+	if err != nil {
+		log.Error("failed to save account deletion", slog.String("error", err.Error()))
+
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	data := mail.Data{Email: user.Email, Link: linkWithToken(a.deletionCancelURL, token), ExpiresAt: d.ExecuteAt}
+	if data.Link == "" {
+		data.Code = token
+	}
+	if err := a.mailer.SendTemplate(ctx, user.Email, mail.TemplateDeletion, data); err != nil {
+		// Удаление назначено: повторный запрос заменит токен и отправит письмо снова
+		log.Error("failed to send account deletion notice", slog.String("error", err.Error()))
+
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("account deletion requested", slog.Time("execute_at", d.ExecuteAt))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventAccountDeletionRequested, Outcome: audit.OutcomeSuccess,
+		UserID: userID, Email: user.Email,
+	})
+
+	return d, nil
+}
+
+// CancelAccountDeletion - отменяет назначенное удаление по токену из письма или, если token пуст,
+// по userID (права на него проверяет вызывающий). Возвращает пользователя, чьё удаление отменено.
+func (a *AuthService) CancelAccountDeletion(ctx context.Context, token string, userID int64) (int64, error) {
+	const op = "Auth.CancelAccountDeletion"
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+
+	if a.deletions == nil {
+		return 0, fmt.Errorf("%s: %w", op, ErrAccountDeletionDisabled)
+	}
+
+	if token != "" {
+		d, err := a.deletions.AccountDeletionByTokenHash(ctx, hashInvitationToken(token))
+		if err != nil {
+			if errors.Is(err, storage.ErrAccountDeletionNotFound) {
+				return 0, fmt.Errorf("%s: %w", op, ErrNoPendingDeletion)
+			}
+
+			return 0, fmt.Errorf("%s: %w", op, err)
+		}
+		userID = d.UserID
+	}
+
+	log = log.With(slog.Int64("user_id", userID))
+
+	err := a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		if err := a.deletions.DeleteAccountDeletion(ctx, userID); err != nil {
+			return events.Event{}, err
+		}
+
+		return events.New(ctx, events.TypeAccountDeletionCancelled, userID), nil
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrAccountDeletionNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrNoPendingDeletion)
+		}
+
+		log.Error("failed to cancel account deletion", slog.String("error", err.Error()))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("account deletion cancelled")
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventAccountDeletionCancelled, Outcome: audit.OutcomeSuccess,
+		UserID: userID,
+	})
+
+	return userID, nil
+}
+
+// errDeletionNotDue - удаление отменено или перенесено, пока janitor до него добирался
+var errDeletionNotDue = errors.New("account deletion is no longer due")
+
+// ExecuteDueDeletions - обезличивает (как EraseUser) до limit пользователей, чей срок удаления наступил.
+// Вызывается janitor; возвращает, сколько аккаунтов удалено.
+func (a *AuthService) ExecuteDueDeletions(ctx context.Context, limit int) (int, error) {
+	const op = "Auth.ExecuteDueDeletions"
+
+	if a.deletions == nil {
+		return 0, nil
+	}
+
+	now := a.clock.Now()
+	due, err := a.deletions.DueAccountDeletions(ctx, now, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var n int
+	for _, userID := range due {
+		// Запрос перечитывается в той же транзакции: пользователь мог отменить удаление после выборки
+		err := a.eraseUser(ctx, userID, func(ctx context.Context) error {
+			d, err := a.deletions.AccountDeletion(ctx, userID)
+			if err != nil {
+				if errors.Is(err, storage.ErrAccountDeletionNotFound) {
+					return errDeletionNotDue
+				}
+
+				return err
+			}
+			if d.ExecuteAt.After(now) {
+				return errDeletionNotDue
+			}
+
+			return nil
+		})
+		switch {
+		case errors.Is(err, errDeletionNotDue):
+			continue
+		case errors.Is(err, storage.ErrUserNotFound):
+			// Пользователя уже нет - запрос больше не нужен, иначе janitor выбирал бы его снова
+			if err := a.deletions.DeleteAccountDeletion(ctx, userID); err != nil && !errors.Is(err, storage.ErrAccountDeletionNotFound) {
+				return n, fmt.Errorf("%s: %w", op, err)
+			}
+			continue
+		case err != nil:
+			return n, fmt.Errorf("%s: %w", op, err)
+		}
+		n++
+
+		executed := events.New(ctx, events.TypeAccountDeletionExecuted, userID)
+		a.publish(ctx, executed)
+
+		logctx.FromOr(ctx, a.log).Info("account deleted on request",
+			slog.String("op", op),
+			slog.Int64("user_id", userID))
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventAccountDeletionExecuted, Outcome: audit.OutcomeSuccess,
+			UserID: userID,
+		})
+	}
+
+	return n, nil
+}
+
+// pendingDeletion - когда будет удалён аккаунт пользователя (нулевое время - удаление не запрошено).
+// Ошибка хранилища не мешает входу: предупреждение об удалении не отменяет его
+func (a *AuthService) pendingDeletion(ctx context.Context, log *slog.Logger, userID int64) time.Time {
+	if a.deletions == nil {
+		return time.Time{}
+	}
+
+	d, err := a.deletions.AccountDeletion(ctx, userID)
+	if err != nil {
+		if !errors.Is(err, storage.ErrAccountDeletionNotFound) {
+			log.Warn("failed to check pending account deletion", slog.String("error", err.Error()))
+		}
+
+		return time.Time{}
+	}
+
+	return d.ExecuteAt
+}
@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sso/internal/domain/models"
+	"sso/internal/lib/mail"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestAccountDeletion(t *testing.T) {
+	store := mocks.NewAccountDeletionStore(t)
+	mailer := mocks.NewMailer(t)
+	a, _, provider, _ := newTestService(t,
+		WithAccountDeletion(store, 48*time.Hour, "https://app.example.com/cancel"), WithMailer(mailer))
+
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, Email: "a@b.c"}, nil)
+
+	var saved models.AccountDeletion
+	store.EXPECT().SaveAccountDeletion(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, d models.AccountDeletion) error {
+			saved = d
+			return nil
+		})
+
+	var sent mail.Data
+	mailer.EXPECT().SendTemplate(mock.Anything, "a@b.c", mail.TemplateDeletion, mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _ string, data mail.Data) error {
+			sent = data
+			return nil
+		})
+
+	d, err := a.RequestAccountDeletion(context.Background(), 7)
+	require.NoError(t, err)
+	assert.Equal(t, saved, d)
+	assert.WithinDuration(t, time.Now().Add(48*time.Hour), d.ExecuteAt, 2*time.Second)
+	assert.Equal(t, d.ExecuteAt, sent.ExpiresAt)
+
+	// В письме ссылка отмены с токеном, в хранилище - только его хеш
+	u, err := url.Parse(sent.Link)
+	require.NoError(t, err)
+	token := u.Query().Get("token")
+	require.NotEmpty(t, token)
+	assert.Equal(t, hashInvitationToken(token), saved.TokenHash)
+}
+
+func TestRequestAccountDeletion_Rejected(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+	_, err := a.RequestAccountDeletion(context.Background(), 7)
+	require.ErrorIs(t, err, ErrAccountDeletionDisabled)
+
+	store := mocks.NewAccountDeletionStore(t)
+	a, _, provider, _ := newTestService(t, WithAccountDeletion(store, 0, ""), WithMailer(mocks.NewMailer(t)))
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, IsGuest: true}, nil)
+	provider.EXPECT().UserByID(mock.Anything, int64(8)).
+		Return(models.User{}, fmt.Errorf("storage.sqlite.UserByID: %w", storage.ErrUserNotFound))
+
+	_, err = a.RequestAccountDeletion(context.Background(), 7)
+	require.ErrorIs(t, err, ErrGuestDeletion)
+	_, err = a.RequestAccountDeletion(context.Background(), 8)
+	require.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestCancelAccountDeletion(t *testing.T) {
+	store := mocks.NewAccountDeletionStore(t)
+	a, _, _, _ := newTestService(t, WithAccountDeletion(store, 0, ""))
+
+	store.EXPECT().AccountDeletionByTokenHash(mock.Anything, hashInvitationToken("token")).
+		Return(models.AccountDeletion{UserID: 7}, nil)
+	store.EXPECT().AccountDeletionByTokenHash(mock.Anything, hashInvitationToken("stale")).
+		Return(models.AccountDeletion{}, storage.ErrAccountDeletionNotFound)
+	store.EXPECT().DeleteAccountDeletion(mock.Anything, int64(7)).Return(nil).Once()
+	store.EXPECT().DeleteAccountDeletion(mock.Anything, int64(8)).
+		Return(fmt.Errorf("storage.sqlite.DeleteAccountDeletion: %w", storage.ErrAccountDeletionNotFound))
+
+	// по токену из письма пользователь определяется по записи, а не по userID из запроса
+	userID, err := a.CancelAccountDeletion(context.Background(), "token", 99)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), userID)
+
+	_, err = a.CancelAccountDeletion(context.Background(), "stale", 0)
+	require.ErrorIs(t, err, ErrNoPendingDeletion)
+
+	_, err = a.CancelAccountDeletion(context.Background(), "", 8)
+	require.ErrorIs(t, err, ErrNoPendingDeletion)
+}
+
+func TestExecuteDueDeletions(t *testing.T) {
+	store := mocks.NewAccountDeletionStore(t)
+	a, saver, _, _ := newTestService(t, WithAccountDeletion(store, 0, ""))
+
+	store.EXPECT().DueAccountDeletions(mock.Anything, mock.Anything, 10).Return([]int64{1, 2, 3}, nil)
+
+	// 1 - срок наступил
+	store.EXPECT().AccountDeletion(mock.Anything, int64(1)).
+		Return(models.AccountDeletion{UserID: 1, ExecuteAt: time.Now().Add(-time.Minute)}, nil)
+	saver.EXPECT().EraseUser(mock.Anything, int64(1), "erased-1@erased.invalid").Return(nil)
+	// 2 - пользователь отменил удаление после выборки
+	store.EXPECT().AccountDeletion(mock.Anything, int64(2)).
+		Return(models.AccountDeletion{}, storage.ErrAccountDeletionNotFound)
+	// 3 - пользователя уже нет: запрос удаляется, чтобы не выбирать его снова
+	store.EXPECT().AccountDeletion(mock.Anything, int64(3)).
+		Return(models.AccountDeletion{UserID: 3, ExecuteAt: time.Now().Add(-time.Minute)}, nil)
+	saver.EXPECT().EraseUser(mock.Anything, int64(3), "erased-3@erased.invalid").Return(storage.ErrUserNotFound)
+	store.EXPECT().DeleteAccountDeletion(mock.Anything, int64(3)).Return(nil)
+
+	n, err := a.ExecuteDueDeletions(context.Background(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestLogin_PendingDeletion(t *testing.T) {
+	user := userWithPassword(t, "secret")
+	executeAt := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	store := mocks.NewAccountDeletionStore(t)
+	a, _, provider, apps := newTestService(t, WithAccountDeletion(store, 0, ""))
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	store.EXPECT().AccountDeletion(mock.Anything, user.ID).
+		Return(models.AccountDeletion{UserID: user.ID, ExecuteAt: executeAt}, nil).Once()
+
+	token, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.AccessToken, "login still works until the account is deleted")
+	assert.True(t, executeAt.Equal(token.DeletionScheduledAt))
+
+	store.EXPECT().AccountDeletion(mock.Anything, user.ID).
+		Return(models.AccountDeletion{}, storage.ErrAccountDeletionNotFound).Once()
+
+	token, err = a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.True(t, token.DeletionScheduledAt.IsZero())
+}
@@ -0,0 +1,373 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/events"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+// Значения по умолчанию для входа на устройстве
+const (
+	DefaultDeviceCodeTTL      = 10 * time.Minute
+	DefaultDevicePollInterval = 5 * time.Second
+)
+
+// Параметры кодов устройства
+const (
+	userCodeAlphabet = "BCDFGHJKLMNPQRSTVWXZ" // без гласных (не складываются слова) и похожих символов
+	userCodeLength   = 8
+	slowDownStep     = 5 * time.Second // RFC 8628, 3.5: slow_down увеличивает интервал на 5 секунд
+	userCodeRetries  = 3
+)
+
+// Ошибки входа на устройстве
+var (
+	ErrDeviceFlowDisabled   = errors.New("device authorization is disabled")        // Ошибка, если вход на устройстве не включён.
+	ErrDeviceCodeNotFound   = errors.New("device code not found or already used")   // Ошибка, если кода нет или токен по нему уже выдан.
+	ErrUserCodeNotFound     = errors.New("user code not found")                     // Ошибка, если пользователь ввёл неизвестный код.
+	ErrDeviceCodeExpired    = errors.New("device code expired")                     // Ошибка, если срок кода истёк.
+	ErrDeviceAlreadyDecided = errors.New("device authorization is already decided") // Ошибка, если вход уже разрешён или отклонён.
+	ErrAuthorizationPending = errors.New("authorization pending")                   // Ошибка, если пользователь ещё не принял решение.
+	ErrSlowDown             = errors.New("polling too frequently")                  // Ошибка, если устройство опрашивает чаще интервала.
+	ErrDeviceDenied         = errors.New("user denied the device authorization")    // Ошибка, если пользователь отказал.
+)
+
+// DeviceSettings - параметры входа на устройстве.
+type DeviceSettings struct {
+	VerificationURI string        // Страница, на которой пользователь вводит код.
+	CodeTTL         time.Duration // Срок действия кодов (0 - DefaultDeviceCodeTTL).
+	PollInterval    time.Duration // Минимальный интервал опроса (0 - DefaultDevicePollInterval).
+}
+
+// DeviceStore - хранилище входов на устройствах.
+type DeviceStore interface {
+	// SaveDeviceAuthorization - сохраняет вход (storage.ErrUserCodeExists, если user_code занят).
+	SaveDeviceAuthorization(ctx context.Context, d models.DeviceAuthorization) (int64, error)
+	// DeviceAuthorizationByUserCode - вход по user_code (storage.ErrDeviceAuthorizationNotFound, если его нет).
+	DeviceAuthorizationByUserCode(ctx context.Context, userCode string) (models.DeviceAuthorization, error)
+	// DeviceAuthorizationByCodeHash - вход по SHA-256 device_code (storage.ErrDeviceAuthorizationNotFound, если его нет).
+	DeviceAuthorizationByCodeHash(ctx context.Context, codeHash string) (models.DeviceAuthorization, error)
+	// DecideDeviceAuthorization - решение пользователя (storage.ErrDeviceAuthorizationUsed, если уже принято).
+	DecideDeviceAuthorization(ctx context.Context, id, userID int64, approve bool) error
+	// RecordDevicePoll - время опроса и новый интервал.
+	RecordDevicePoll(ctx context.Context, id int64, polledAt time.Time, interval time.Duration) error
+	// ConsumeDeviceAuthorization - отмечает выдачу токена (storage.ErrDeviceAuthorizationUsed, если вход не разрешён).
+	ConsumeDeviceAuthorization(ctx context.Context, id int64) error
+}
+
+// WithDeviceAuthorization - включает вход на устройствах без браузера (OAuth 2.0 Device Authorization Grant).
+func WithDeviceAuthorization(store DeviceStore, s DeviceSettings) Option {
+	return func(a *AuthService) {
+		if s.CodeTTL <= 0 {
+			s.CodeTTL = DefaultDeviceCodeTTL
+		}
+		if s.PollInterval <= 0 {
+			s.PollInterval = DefaultDevicePollInterval
+		}
+		a.devices = store
+		a.device = s
+	}
+}
+
+// StartDeviceAuthorization - начинает вход на устройстве (телевизор, CLI): возвращает секретный device_code
+// для опроса PollDeviceToken и короткий user_code, который пользователь вводит на странице VerificationURI.
+func (a *AuthService) StartDeviceAuthorization(ctx context.Context, appID int) (models.DeviceCodes, error) {
+	const op = "Auth.StartDeviceAuthorization"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("app_id", appID))
+
+	if a.devices == nil {
+		return models.DeviceCodes{}, fmt.Errorf("%s: %w", op, ErrDeviceFlowDisabled)
+	}
+
+	if _, err := a.appProvider.App(ctx, appID); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.DeviceCodes{}, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return models.DeviceCodes{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	deviceCode, err := newDeviceCode()
+	if err != nil {
+		return models.DeviceCodes{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	d := models.DeviceAuthorization{
+		DeviceCodeHash: hashDeviceCode(deviceCode),
+		AppID:          appID,
+		Interval:       a.device.PollInterval,
+		ExpiresAt:      a.clock.Now().Add(a.device.CodeTTL),
+	}
+
+	// user_code короткий, поэтому совпадение с действующим кодом возможно: генерируем заново
+	for attempt := 1; ; attempt++ {
+		if d.UserCode, err = newUserCode(); err != nil {
+			return models.DeviceCodes{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		_, err = a.devices.SaveDeviceAuthorization(ctx, d)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, storage.ErrUserCodeExists) || attempt == userCodeRetries {
+			log.Error("failed to save device authorization", slog.String("error", err.Error()))
+
+			return models.DeviceCodes{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	log.Info("device authorization started")
+
+	userCode := formatUserCode(d.UserCode)
+
+	return models.DeviceCodes{
+		DeviceCode:              deviceCode,
+		UserCode:                userCode,
+		VerificationURI:         a.device.VerificationURI,
+		VerificationURIComplete: a.verificationLink(userCode),
+		ExpiresAt:               d.ExpiresAt,
+		Interval:                d.Interval,
+	}, nil
+}
+
+// ApproveDevice - пользователь userID разрешает вход на устройстве с кодом userCode.
+// Для стороннего приложения нужно его согласие (ErrConsentRequired, если его нет).
+func (a *AuthService) ApproveDevice(ctx context.Context, userID int64, userCode string) error {
+	return a.decideDevice(ctx, "Auth.ApproveDevice", userID, userCode, true)
+}
+
+// DenyDevice - пользователь userID отказывает во входе на устройстве с кодом userCode.
+func (a *AuthService) DenyDevice(ctx context.Context, userID int64, userCode string) error {
+	return a.decideDevice(ctx, "Auth.DenyDevice", userID, userCode, false)
+}
+
+func (a *AuthService) decideDevice(ctx context.Context, op string, userID int64, userCode string, approve bool) error {
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if a.devices == nil {
+		return fmt.Errorf("%s: %w", op, ErrDeviceFlowDisabled)
+	}
+
+	d, err := a.devices.DeviceAuthorizationByUserCode(ctx, normalizeUserCode(userCode))
+	if err != nil {
+		if errors.Is(err, storage.ErrDeviceAuthorizationNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserCodeNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if a.clock.Now().After(d.ExpiresAt) {
+		return fmt.Errorf("%s: %w", op, ErrDeviceCodeExpired)
+	}
+	if d.Status != models.DevicePending {
+		return fmt.Errorf("%s: %w", op, ErrDeviceAlreadyDecided)
+	}
+
+	if approve {
+		app, err := a.appProvider.App(ctx, d.AppID)
+		if err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		// Устройство получит токен стороннего приложения - нужно то же согласие, что и при входе
+		if app.ThirdParty {
+			if _, err := a.checkConsent(ctx, userID, app.ID, time.Time{}); err != nil {
+				return fmt.Errorf("%s: %w", op, err)
+			}
+		}
+	}
+
+	if err := a.devices.DecideDeviceAuthorization(ctx, d.ID, userID, approve); err != nil {
+		if errors.Is(err, storage.ErrDeviceAuthorizationUsed) {
+			return fmt.Errorf("%s: %w", op, ErrDeviceAlreadyDecided)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	event := audit.EventDeviceDenied
+	if approve {
+		event = audit.EventDeviceApproved
+	}
+	log.Info("device authorization decided", slog.Bool("approved", approve), slog.Int("app_id", d.AppID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: event, Outcome: audit.OutcomeSuccess,
+		UserID: userID, AppID: d.AppID,
+	})
+
+	return nil
+}
+
+// PollDeviceToken - опрос устройства: ErrAuthorizationPending, пока пользователь не принял решение,
+// ErrSlowDown, если устройство спрашивает чаще интервала (интервал растёт на 5 секунд), ErrDeviceDenied
+// при отказе и токен при разрешении. Токен выдаётся один раз - следующий опрос получит ErrDeviceCodeNotFound.
+func (a *AuthService) PollDeviceToken(ctx context.Context, deviceCode string) (models.Token, error) {
+	const op = "Auth.PollDeviceToken"
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+
+	if a.devices == nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrDeviceFlowDisabled)
+	}
+
+	d, err := a.devices.DeviceAuthorizationByCodeHash(ctx, hashDeviceCode(deviceCode))
+	if err != nil {
+		if errors.Is(err, storage.ErrDeviceAuthorizationNotFound) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, ErrDeviceCodeNotFound)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := a.clock.Now()
+	switch {
+	case d.Status == models.DeviceConsumed:
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrDeviceCodeNotFound)
+	case now.After(d.ExpiresAt):
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrDeviceCodeExpired)
+	}
+
+	interval := d.Interval
+	tooSoon := !d.LastPolledAt.IsZero() && now.Sub(d.LastPolledAt) < d.Interval
+	if tooSoon {
+		interval += slowDownStep
+	}
+	if err := a.devices.RecordDevicePoll(ctx, d.ID, now, interval); err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if tooSoon {
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrSlowDown)
+	}
+
+	switch d.Status {
+	case models.DevicePending:
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrAuthorizationPending)
+	case models.DeviceDenied:
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrDeviceDenied)
+	}
+
+	// Одновременные опросы не получат два токена: отметку выдачи выигрывает только один
+	if err := a.devices.ConsumeDeviceAuthorization(ctx, d.ID); err != nil {
+		if errors.Is(err, storage.ErrDeviceAuthorizationUsed) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, ErrDeviceCodeNotFound)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Пользователя могли удалить или отключить после решения - вход не состоится
+	user, err := a.usrProvider.UserByID(ctx, d.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, ErrDeviceDenied)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.IsActive {
+		return models.Token{}, fmt.Errorf("%s: %w: user is not active", op, ErrDeviceDenied)
+	}
+
+	app, err := a.appProvider.App(ctx, d.AppID)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := a.issueToken(ctx, user, app)
+	if err != nil {
+		log.Error("failed to create token", slog.String("error", err.Error()))
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("device logged in", slog.Int64("user_id", user.ID), slog.Int("app_id", app.ID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventLoginSucceeded, Outcome: audit.OutcomeSuccess,
+		UserID: user.ID, Email: user.Email, AppID: app.ID,
+	})
+
+	loggedIn := events.New(ctx, events.TypeUserLoggedIn, user.ID)
+	loggedIn.AppID = app.ID
+	a.publish(ctx, loggedIn)
+
+	return token, nil
+}
+
+// verificationLink - страница ввода кода с уже подставленным user_code
+func (a *AuthService) verificationLink(userCode string) string {
+	u, err := url.Parse(a.device.VerificationURI)
+	if err != nil || a.device.VerificationURI == "" {
+		return ""
+	}
+
+	q := u.Query()
+	q.Set("user_code", userCode)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+func newDeviceCode() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashDeviceCode - как и токен приглашения, device_code хранится только в виде SHA-256
+func hashDeviceCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// newUserCode - 8 символов из 20-буквенного алфавита (~34 бита): перебрать их за время жизни кода нельзя
+func newUserCode() (string, error) {
+	b := make([]byte, userCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	// 256 делится на 20 с остатком, но смещение в пользу первых 16 букв (13 против 12 из 256) несущественно
+	for i := range b {
+		b[i] = userCodeAlphabet[int(b[i])%len(userCodeAlphabet)]
+	}
+
+	return string(b), nil
+}
+
+// formatUserCode - код для показа пользователю: XXXX-XXXX
+func formatUserCode(code string) string {
+	return code[:userCodeLength/2] + "-" + code[userCodeLength/2:]
+}
+
+// normalizeUserCode - введённый пользователем код в виде, в котором он хранится: без дефисов и пробелов, в верхнем регистре
+func normalizeUserCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+
+		return r
+	}, strings.ToUpper(code))
+}
@@ -0,0 +1,165 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memDevices - DeviceStore в памяти с теми же переходами состояний, что и в sqlite
+type memDevices map[int64]*models.DeviceAuthorization
+
+func (m memDevices) SaveDeviceAuthorization(_ context.Context, d models.DeviceAuthorization) (int64, error) {
+	d.ID = int64(len(m) + 1)
+	d.Status = models.DevicePending
+	m[d.ID] = &d
+
+	return d.ID, nil
+}
+
+func (m memDevices) DeviceAuthorizationByUserCode(_ context.Context, code string) (models.DeviceAuthorization, error) {
+	for _, d := range m {
+		if d.UserCode == code {
+			return *d, nil
+		}
+	}
+
+	return models.DeviceAuthorization{}, fmt.Errorf("storage.mem: %w", storage.ErrDeviceAuthorizationNotFound)
+}
+
+func (m memDevices) DeviceAuthorizationByCodeHash(_ context.Context, hash string) (models.DeviceAuthorization, error) {
+	for _, d := range m {
+		if d.DeviceCodeHash == hash {
+			return *d, nil
+		}
+	}
+
+	return models.DeviceAuthorization{}, fmt.Errorf("storage.mem: %w", storage.ErrDeviceAuthorizationNotFound)
+}
+
+func (m memDevices) DecideDeviceAuthorization(_ context.Context, id, userID int64, approve bool) error {
+	return m.transition(id, models.DevicePending, map[bool]string{true: models.DeviceApproved, false: models.DeviceDenied}[approve],
+		func(d *models.DeviceAuthorization) { d.UserID = userID })
+}
+
+func (m memDevices) RecordDevicePoll(_ context.Context, id int64, polledAt time.Time, interval time.Duration) error {
+	m[id].LastPolledAt, m[id].Interval = polledAt, interval
+
+	return nil
+}
+
+func (m memDevices) ConsumeDeviceAuthorization(_ context.Context, id int64) error {
+	return m.transition(id, models.DeviceApproved, models.DeviceConsumed, func(*models.DeviceAuthorization) {})
+}
+
+func (m memDevices) transition(id int64, from, to string, fn func(d *models.DeviceAuthorization)) error {
+	d := m[id]
+	if d.Status != from {
+		return fmt.Errorf("storage.mem: %w", storage.ErrDeviceAuthorizationUsed)
+	}
+	d.Status = to
+	fn(d)
+
+	return nil
+}
+
+// waitInterval - делает вид, что устройство выждало интервал опроса
+func (m memDevices) waitInterval() {
+	for _, d := range m {
+		d.LastPolledAt = d.LastPolledAt.Add(-d.Interval)
+	}
+}
+
+var deviceSettings = DeviceSettings{VerificationURI: "https://sso.example/device"}
+
+func TestDeviceAuthorization_ApproveOnce(t *testing.T) {
+	devices := memDevices{}
+	a, _, provider, apps := newTestService(t, WithDeviceAuthorization(devices, deviceSettings))
+	ctx := context.Background()
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	dc, err := a.StartDeviceAuthorization(ctx, testApp.ID)
+	require.NoError(t, err)
+	assert.Regexp(t, `^[B-Z]{4}-[B-Z]{4}$`, dc.UserCode)
+	assert.Equal(t, DefaultDevicePollInterval, dc.Interval)
+	assert.Equal(t, "https://sso.example/device?user_code="+dc.UserCode, dc.VerificationURIComplete)
+
+	_, err = a.PollDeviceToken(ctx, dc.DeviceCode)
+	require.ErrorIs(t, err, ErrAuthorizationPending)
+
+	// Опрос раньше интервала - slow_down, и интервал растёт на 5 секунд
+	_, err = a.PollDeviceToken(ctx, dc.DeviceCode)
+	require.ErrorIs(t, err, ErrSlowDown)
+	assert.Equal(t, DefaultDevicePollInterval+slowDownStep, devices[1].Interval)
+
+	// Пользователь вводит код как попало: в нижнем регистре и без дефиса
+	require.NoError(t, a.ApproveDevice(ctx, 7, strings.ToLower(strings.ReplaceAll(dc.UserCode, "-", ""))))
+	require.ErrorIs(t, a.DenyDevice(ctx, 7, dc.UserCode), ErrDeviceAlreadyDecided)
+
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(userWithPassword(t, "secret"), nil).Once()
+
+	devices.waitInterval()
+	token, err := a.PollDeviceToken(ctx, dc.DeviceCode)
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.AccessToken)
+
+	// Токен выдаётся один раз
+	devices.waitInterval()
+	_, err = a.PollDeviceToken(ctx, dc.DeviceCode)
+	require.ErrorIs(t, err, ErrDeviceCodeNotFound)
+}
+
+func TestDeviceAuthorization_DeniedAndExpired(t *testing.T) {
+	devices := memDevices{}
+	a, _, _, apps := newTestService(t, WithDeviceAuthorization(devices, deviceSettings))
+	ctx := context.Background()
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	denied, err := a.StartDeviceAuthorization(ctx, testApp.ID)
+	require.NoError(t, err)
+	require.NoError(t, a.DenyDevice(ctx, 7, denied.UserCode))
+
+	_, err = a.PollDeviceToken(ctx, denied.DeviceCode)
+	require.ErrorIs(t, err, ErrDeviceDenied)
+
+	expired, err := a.StartDeviceAuthorization(ctx, testApp.ID)
+	require.NoError(t, err)
+	devices[2].ExpiresAt = time.Now().Add(-time.Second)
+
+	require.ErrorIs(t, a.ApproveDevice(ctx, 7, expired.UserCode), ErrDeviceCodeExpired)
+	_, err = a.PollDeviceToken(ctx, expired.DeviceCode)
+	require.ErrorIs(t, err, ErrDeviceCodeExpired)
+
+	require.ErrorIs(t, a.ApproveDevice(ctx, 7, "XXXX-XXXX"), ErrUserCodeNotFound)
+}
+
+func TestDeviceAuthorization_ThirdPartyNeedsConsent(t *testing.T) {
+	devices := memDevices{}
+	a, _, _, apps := newTestService(t, WithDeviceAuthorization(devices, deviceSettings))
+	ctx := context.Background()
+
+	apps.EXPECT().App(mock.Anything, partnerApp.ID).Return(partnerApp, nil)
+
+	dc, err := a.StartDeviceAuthorization(ctx, partnerApp.ID)
+	require.NoError(t, err)
+
+	require.ErrorIs(t, a.ApproveDevice(ctx, 7, dc.UserCode), ErrConsentRequired)
+	assert.Equal(t, models.DevicePending, devices[1].Status)
+}
+
+func TestDeviceAuthorization_Disabled(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+
+	_, err := a.StartDeviceAuthorization(context.Background(), testApp.ID)
+	require.ErrorIs(t, err, ErrDeviceFlowDisabled)
+}
@@ -0,0 +1,73 @@
+package auth
+
+import "strings"
+
+// DomainPolicy - списки разрешённых и запрещённых доменов email при регистрации.
+// Шаблон "example.com" совпадает только с самим доменом, "*.example.com" - только с его поддоменами.
+// Сравнение регистронезависимое. Запрещающий список проверяется первым;
+// если разрешающий список не пуст, домен должен совпасть хотя бы с одним его шаблоном
+type DomainPolicy struct {
+	allowed []string
+	blocked []string
+}
+
+// NewDomainPolicy - создаёт политику; пустые списки ничего не ограничивают
+func NewDomainPolicy(allowed, blocked []string) *DomainPolicy {
+	return &DomainPolicy{
+		allowed: normalizePatterns(allowed),
+		blocked: normalizePatterns(blocked),
+	}
+}
+
+// Allowed - можно ли зарегистрироваться с этим email
+func (p *DomainPolicy) Allowed(email string) bool {
+	if p == nil {
+		return true
+	}
+
+	_, domain, ok := strings.Cut(normalizeEmail(email), "@")
+	if !ok || domain == "" {
+		return false
+	}
+	domain = strings.TrimSuffix(domain, ".")
+
+	if matchDomain(p.blocked, domain) {
+		return false
+	}
+
+	return len(p.allowed) == 0 || matchDomain(p.allowed, domain)
+}
+
+// normalizeEmail - приводит email к виду, в котором сравниваются домены
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func normalizePatterns(patterns []string) []string {
+	res := make([]string, 0, len(patterns))
+	for _, p := range patterns {
+		if p = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(p)), "."); p != "" {
+			res = append(res, p)
+		}
+	}
+
+	return res
+}
+
+func matchDomain(patterns []string, domain string) bool {
+	for _, p := range patterns {
+		if parent, ok := strings.CutPrefix(p, "*."); ok {
+			if strings.HasSuffix(domain, "."+parent) {
+				return true
+			}
+
+			continue
+		}
+
+		if domain == p {
+			return true
+		}
+	}
+
+	return false
+}
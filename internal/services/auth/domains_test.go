@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDomainPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		blocked []string
+		email   string
+		want    bool
+	}{
+		{name: "no lists", email: "a@anything.org", want: true},
+		{name: "allowed exact", allowed: []string{"ourcompany.com"}, email: "a@ourcompany.com", want: true},
+		{name: "allowed case-insensitive", allowed: []string{"OurCompany.com"}, email: " A@OURCOMPANY.COM ", want: true},
+		{name: "not in allowlist", allowed: []string{"ourcompany.com"}, email: "a@gmail.com"},
+		{name: "lookalike suffix", allowed: []string{"ourcompany.com"}, email: "a@evil-ourcompany.com"},
+		{name: "wildcard subdomain", allowed: []string{"*.ourcompany.com"}, email: "a@eu.ourcompany.com", want: true},
+		{name: "wildcard excludes apex", allowed: []string{"*.ourcompany.com"}, email: "a@ourcompany.com"},
+		{name: "wildcard lookalike", allowed: []string{"*.ourcompany.com"}, email: "a@evilourcompany.com"},
+		{name: "blocked", blocked: []string{"mailinator.com"}, email: "a@mailinator.com"},
+		{name: "blocked wins over allowed", allowed: []string{"*.ourcompany.com"}, blocked: []string{"contractors.ourcompany.com"},
+			email: "a@contractors.ourcompany.com"},
+		{name: "trailing dot", blocked: []string{"mailinator.com"}, email: "a@mailinator.com."},
+		{name: "no domain", email: "not-an-email"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewDomainPolicy(tt.allowed, tt.blocked).Allowed(tt.email))
+		})
+	}
+
+	var nilPolicy *DomainPolicy
+	assert.True(t, nilPolicy.Allowed("a@b.c"))
+}
+
+// FuzzNormalizeEmail - нормализация идемпотентна, а проверка домена не падает на любых строках.
+// Запуск: go test ./internal/services/auth -fuzz=FuzzNormalizeEmail
+func FuzzNormalizeEmail(f *testing.F) {
+	for _, seed := range []string{
+		"a@b.c",
+		" A@OURCOMPANY.COM ",
+		"a@mailinator.com.",
+		"not-an-email",
+		"\u00a0a@b.c\u3000",     // неразрывный и идеографический пробелы
+		"a\u200b@b.c",           // пробел нулевой ширины
+		"a@b\u200d.c\ufeff",     // соединитель нулевой ширины, BOM
+		"\u202ea@moc.elgoog",    // смена направления письма справа налево
+		"user@\u0627\u0644.com", // арабский домен
+		"\u0130STANBUL@B.C",     // İ: в нижнем регистре два символа
+		"\u212a@b.c",            // знак кельвина
+		"a@b.c\xff\xfe",         // не UTF-8
+		"",
+	} {
+		f.Add(seed)
+	}
+
+	policy := NewDomainPolicy([]string{"*.ourcompany.com", "b.c"}, []string{"mailinator.com"})
+
+	f.Fuzz(func(t *testing.T, email string) {
+		once := normalizeEmail(email)
+		assert.Equal(t, once, normalizeEmail(once))
+
+		_ = policy.Allowed(email)
+	})
+}
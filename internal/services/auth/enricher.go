@@ -0,0 +1,150 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sso/internal/domain/models"
+)
+
+// UserMetadataProvider - источник метаданных пользователя (JSON-объект)
+type UserMetadataProvider interface {
+	UserMetadata(ctx context.Context, userID int64) ([]byte, error)
+}
+
+// MetadataEnricher - добавляет в токен поля из колонки users.metadata.
+// Каждое поле JSON-объекта становится отдельным клеймом
+type MetadataEnricher struct {
+	provider UserMetadataProvider
+}
+
+// NewMetadataEnricher - конструктор MetadataEnricher
+func NewMetadataEnricher(provider UserMetadataProvider) *MetadataEnricher {
+	return &MetadataEnricher{provider: provider}
+}
+
+// Claims - реализует ClaimsEnricher
+func (e *MetadataEnricher) Claims(ctx context.Context, user models.User, _ models.App) (map[string]any, error) {
+	const op = "auth.MetadataEnricher.Claims"
+
+	raw, err := e.provider.UserMetadata(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if len(raw) == 0 {
+		return nil, nil
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, fmt.Errorf("%s: metadata must be a JSON object: %w", op, err)
+	}
+
+	return claims, nil
+}
+
+// AppMetadataEnricher - добавляет в токен разрешённые ключи из метаданных, которые приложение
+// хранит о пользователе (UpdateAppMetadata). Приложение получает только свои метаданные;
+// ключи вне списка остаются на сервере и в токен не попадают
+type AppMetadataEnricher struct {
+	provider AppMetadataProvider
+	keys     []string
+}
+
+// NewAppMetadataEnricher - конструктор AppMetadataEnricher; keys - ключи, которые можно включать в токены
+func NewAppMetadataEnricher(provider AppMetadataProvider, keys []string) *AppMetadataEnricher {
+	return &AppMetadataEnricher{provider: provider, keys: keys}
+}
+
+// Claims - реализует ClaimsEnricher
+func (e *AppMetadataEnricher) Claims(ctx context.Context, user models.User, app models.App) (map[string]any, error) {
+	const op = "auth.AppMetadataEnricher.Claims"
+
+	if len(e.keys) == 0 {
+		return nil, nil
+	}
+
+	raw, _, err := e.provider.AppMetadata(ctx, user.ID, app.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var metadata map[string]any
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		return nil, fmt.Errorf("%s: app metadata must be a JSON object: %w", op, err)
+	}
+
+	claims := make(map[string]any, len(e.keys))
+	for _, k := range e.keys {
+		if v, ok := metadata[k]; ok {
+			claims[k] = v
+		}
+	}
+
+	return claims, nil
+}
+
+// UserGroupsProvider - источник групп пользователя
+type UserGroupsProvider interface {
+	// UserGroups - имена групп пользователя (пустой срез, если групп нет)
+	UserGroups(ctx context.Context, userID int64) ([]string, error)
+}
+
+// GroupsEnricher - добавляет в токен клейм groups с именами групп пользователя.
+// Клейм добавляется только приложениям с models.App.GroupsClaim: токены остальных не растут.
+// Группы читаются при каждой выдаче токена, поэтому изменения членства видны в следующем токене
+// (при повторном входе или перевыпуске), а уже выданные токены сохраняют прежний список до истечения
+type GroupsEnricher struct {
+	provider UserGroupsProvider
+}
+
+// NewGroupsEnricher - конструктор GroupsEnricher
+func NewGroupsEnricher(provider UserGroupsProvider) *GroupsEnricher {
+	return &GroupsEnricher{provider: provider}
+}
+
+// Claims - реализует ClaimsEnricher
+func (e *GroupsEnricher) Claims(ctx context.Context, user models.User, app models.App) (map[string]any, error) {
+	const op = "auth.GroupsEnricher.Claims"
+
+	if !app.GroupsClaim {
+		return nil, nil
+	}
+
+	groups, err := e.provider.UserGroups(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return map[string]any{"groups": groups}, nil
+}
+
+// ChainEnrichers - объединяет клеймы нескольких источников; при совпадении имён побеждает более поздний
+// (например, groups из GroupsEnricher важнее одноимённого поля метаданных)
+func ChainEnrichers(enrichers ...ClaimsEnricher) ClaimsEnricher {
+	return enricherChain(enrichers)
+}
+
+// enricherChain - ClaimsEnricher из нескольких источников
+type enricherChain []ClaimsEnricher
+
+// Claims - реализует ClaimsEnricher
+func (c enricherChain) Claims(ctx context.Context, user models.User, app models.App) (map[string]any, error) {
+	var claims map[string]any
+	for _, e := range c {
+		extra, err := e.Claims(ctx, user, app)
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range extra {
+			if claims == nil {
+				claims = make(map[string]any, len(extra))
+			}
+			claims[k] = v
+		}
+	}
+
+	return claims, nil
+}
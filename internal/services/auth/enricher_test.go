@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type metadataStub []byte
+
+func (m metadataStub) UserMetadata(context.Context, int64) ([]byte, error) {
+	return m, nil
+}
+
+func TestMetadataEnricher(t *testing.T) {
+	e := NewMetadataEnricher(metadataStub(`{"department":"sales","locale":"ru-RU"}`))
+
+	claims, err := e.Claims(context.Background(), models.User{ID: 1}, models.App{ID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"department": "sales", "locale": "ru-RU"}, claims)
+
+	claims, err = NewMetadataEnricher(metadataStub(`{}`)).Claims(context.Background(), models.User{ID: 1}, models.App{})
+	require.NoError(t, err)
+	assert.Empty(t, claims)
+
+	_, err = NewMetadataEnricher(metadataStub(`["not", "an", "object"]`)).Claims(context.Background(), models.User{ID: 1}, models.App{})
+	require.Error(t, err)
+}
+
+// groupsStub - группы пользователя, которые можно менять между выдачами токенов
+type groupsStub struct{ groups []string }
+
+func (g *groupsStub) UserGroups(context.Context, int64) ([]string, error) {
+	return g.groups, nil
+}
+
+func TestGroupsEnricher_PerApp(t *testing.T) {
+	e := NewGroupsEnricher(&groupsStub{groups: []string{"engineering"}})
+
+	claims, err := e.Claims(context.Background(), models.User{ID: 1}, models.App{ID: 1})
+	require.NoError(t, err)
+	assert.Empty(t, claims, "apps without groups_claim keep small tokens")
+
+	claims, err = e.Claims(context.Background(), models.User{ID: 1}, models.App{ID: 1, GroupsClaim: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"groups": []string{"engineering"}}, claims)
+}
+
+func TestChainEnrichers(t *testing.T) {
+	e := ChainEnrichers(
+		NewMetadataEnricher(metadataStub(`{"department":"sales","groups":"from-metadata"}`)),
+		NewGroupsEnricher(&groupsStub{groups: []string{"finance"}}),
+	)
+
+	claims, err := e.Claims(context.Background(), models.User{ID: 1}, models.App{ID: 1, GroupsClaim: true})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"department": "sales", "groups": []string{"finance"}}, claims)
+}
+
+// Изменение групп видно в следующем выданном токене; уже выданный остаётся прежним
+func TestLogin_GroupsReissued(t *testing.T) {
+	groups := &groupsStub{groups: []string{"engineering"}}
+	a, _, provider, apps := newTestService(t, WithClaimsEnricher(NewGroupsEnricher(groups)))
+	user := userWithPassword(t, "secret")
+	app := models.App{ID: testApp.ID, Name: testApp.Name, Secret: testApp.Secret, GroupsClaim: true}
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, app.ID).Return(app, nil)
+
+	first, err := a.Login(context.Background(), "a@b.c", "secret", app.ID, "", "", "")
+	require.NoError(t, err)
+
+	groups.groups = []string{"engineering", "finance"}
+
+	second, err := a.Login(context.Background(), "a@b.c", "secret", app.ID, "", "", "")
+	require.NoError(t, err)
+
+	claims, err := jwt.Parse(first.AccessToken, []byte(app.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, []any{"engineering"}, claims.Extra["groups"])
+
+	claims, err = jwt.Parse(second.AccessToken, []byte(app.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, []any{"engineering", "finance"}, claims.Extra["groups"])
+}
+
+// appMetadataStub - метаданные по приложениям
+type appMetadataStub map[int]string
+
+func (m appMetadataStub) AppMetadata(_ context.Context, _ int64, appID int) ([]byte, int64, error) {
+	if data, ok := m[appID]; ok {
+		return []byte(data), 1, nil
+	}
+
+	return []byte(`{}`), 1, nil
+}
+
+func TestAppMetadataEnricher_Whitelist(t *testing.T) {
+	metadata := appMetadataStub{
+		1: `{"locale":"ru-RU","display_name":"Anna","internal_notes":"vip"}`,
+		2: `{"locale":"en-US"}`,
+	}
+	e := NewAppMetadataEnricher(metadata, []string{"locale", "display_name"})
+
+	claims, err := e.Claims(context.Background(), models.User{ID: 1}, models.App{ID: 1})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"locale": "ru-RU", "display_name": "Anna"}, claims)
+
+	// Каждое приложение получает только свои метаданные
+	claims, err = e.Claims(context.Background(), models.User{ID: 1}, models.App{ID: 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"locale": "en-US"}, claims)
+
+	claims, err = NewAppMetadataEnricher(metadata, nil).Claims(context.Background(), models.User{ID: 1}, models.App{ID: 1})
+	require.NoError(t, err)
+	assert.Empty(t, claims)
+}
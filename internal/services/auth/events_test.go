@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/lib/events"
+	"sso/internal/services/auth/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterNewUser_PublishesEvent(t *testing.T) {
+	pub := mocks.NewPublisher(t)
+	a, saver, _, _ := newTestService(t, WithEventPublisher(pub))
+
+	saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).Return(42, nil)
+	pub.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e events.Event) bool {
+		return e.Type == events.TypeUserRegistered && e.UserID == 42
+	})).Return(nil).Once()
+
+	_, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", "", "", 0)
+	require.NoError(t, err)
+}
+
+func TestRegisterNewUser_PublishFailureIgnored(t *testing.T) {
+	pub := mocks.NewPublisher(t)
+	a, saver, _, _ := newTestService(t, WithEventPublisher(pub))
+
+	saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).Return(42, nil)
+	pub.EXPECT().Publish(mock.Anything, mock.Anything).Return(errors.New("broker down"))
+
+	before := events.PublishFailures.Value()
+
+	_, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", "", "", 0)
+	require.NoError(t, err, "publish failure must not fail registration")
+	assert.Equal(t, before+1, events.PublishFailures.Value())
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/features"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// flagOn - флаг f включён для всех
+func flagOn(f features.Flag) Option {
+	return WithFeatures(features.New(map[string]features.Rule{string(f): {Enabled: true}}))
+}
+
+func TestLogin_RehashOnLogin(t *testing.T) {
+	user := userWithPassword(t, "secret") // bcrypt.MinCost
+
+	// Без флага хеш с низкой стоимостью остаётся как есть (у мока нет ожидания RehashPassword)
+	a, _, provider, apps := newTestService(t)
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	_, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+
+	a, saver, provider, apps := newTestService(t, flagOn(features.RehashOnLogin))
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	saver.EXPECT().RehashPassword(mock.Anything, user.ID, user.PassHash, mock.MatchedBy(func(hash []byte) bool {
+		cost, err := bcrypt.Cost(hash)
+		return err == nil && cost == bcrypt.DefaultCost && bcrypt.CompareHashAndPassword(hash, []byte("secret")) == nil
+	})).Return(nil).Once()
+
+	_, err = a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+}
+
+func TestNormalizedEmail(t *testing.T) {
+	a, saver, provider, apps := newTestService(t, flagOn(features.NormalizedEmail))
+	ctx := context.Background()
+
+	saver.EXPECT().SaveUser(mock.Anything, "new@b.c", mock.Anything).Return(8, nil)
+	_, err := a.RegisterNewUser(ctx, " New@B.c", "secret", "", "", "", 0)
+	require.NoError(t, err)
+
+	// Сначала ищется нормальная форма, затем email как есть - так находятся пользователи,
+	// зарегистрированные до включения флага
+	user := userWithPassword(t, "secret")
+	user.Email = "Old@B.c"
+	provider.EXPECT().User(mock.Anything, "old@b.c").Return(models.User{}, storage.ErrUserNotFound)
+	provider.EXPECT().User(mock.Anything, "Old@B.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	_, err = a.Login(ctx, "Old@B.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+}
+
+func TestLogin_ConstantTimeUnknownUser(t *testing.T) {
+	a, _, provider, _ := newTestService(t, flagOn(features.ConstantTimeLogin))
+	provider.EXPECT().User(mock.Anything, "nobody@b.c").Return(models.User{}, storage.ErrUserNotFound)
+
+	// Сравнение с подставным хешем не должно ни находить совпадение, ни менять ответ
+	_, err := a.Login(context.Background(), "nobody@b.c", "constant-time login", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestAuthenticate_StrictScopes(t *testing.T) {
+	user := userWithPassword(t, "secret")
+	token, err := jwt.NewToken(user, partnerApp, time.Hour, jwt.WithScope([]string{"profile", "contacts"}))
+	require.NoError(t, err)
+	consent := models.Consent{UserID: user.ID, AppID: partnerApp.ID, Scopes: []string{"profile"}, GrantedAt: time.Now().Add(-time.Minute)}
+
+	setup := func(opts ...Option) *AuthService {
+		consents := mocks.NewConsentStore(t)
+		a, _, provider, apps := newTestService(t, append(opts, WithConsents(consents))...)
+		apps.EXPECT().App(mock.Anything, partnerApp.ID).Return(partnerApp, nil)
+		provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+		consents.EXPECT().ActiveConsent(mock.Anything, user.ID, partnerApp.ID).Return(consent, nil)
+
+		return a
+	}
+
+	p, err := setup().Authenticate(context.Background(), token)
+	require.NoError(t, err, "without the flag scopes are not compared with the consent")
+	assert.Equal(t, []string{"profile", "contacts"}, p.Scopes)
+
+	_, err = setup(flagOn(features.StrictScopes)).Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	assert.ErrorContains(t, err, `scope "contacts" is not granted`)
+}
@@ -0,0 +1,5 @@
+package auth
+
+// Моки интерфейсов сервиса для тестов (testify/mock). После изменения интерфейсов: go generate ./internal/services/auth
+//go:generate go run github.com/vektra/mockery/v2@v2.53.7 --name "^(UserSaver|UserProvider|AppProvider|OrgStore|InvitationStore|ConsentStore|TOSStore|AccountDeletionStore|AppMetadataStore|GuestStore|ActionTokenStore|DeviceStore|PasskeyStore|SMSStore|SMSProvider|OIDCStore|SessionStore|OfflineTokenStore|RefreshTokenStore|ProvisioningStore|SelfTestStore|UserAppStore|TokenDenylist|RevocationStore|LoginHistoryStore|SecondFactor|Mailer|BreachChecker)$" --output mocks --outpkg mocks --with-expecter --disable-version-string
+//go:generate go run github.com/vektra/mockery/v2@v2.53.7 --dir ../../lib/events --name "^Publisher$" --output mocks --outpkg mocks --with-expecter --disable-version-string
@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/events"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"time"
+)
+
+// guestEmailDomain - домен email-заглушек гостей (.invalid зарезервирован RFC 2606 и никогда не существует)
+const guestEmailDomain = "guest.invalid"
+
+// Ошибки гостевых пользователей
+var (
+	ErrGuestsDisabled     = errors.New("guest accounts are disabled")                           // Ошибка, если гости не включены.
+	ErrNotGuest           = errors.New("token does not belong to a guest")                      // Ошибка, если UpgradeGuest получил токен обычного пользователя.
+	ErrGuestAppNotAllowed = errors.New("guest accounts are not available for third-party apps") // Ошибка, если гость создаётся для стороннего приложения.
+)
+
+// GuestStore - хранилище гостевых пользователей
+type GuestStore interface {
+	// SaveGuest - создаёт гостя с email-заглушкой placeholder и без пароля.
+	SaveGuest(ctx context.Context, placeholder string) (int64, error)
+	// TouchGuest - отмечает активность гостя (для обычных пользователей ничего не делает).
+	TouchGuest(ctx context.Context, userID int64) error
+	// UpgradeGuest - задаёт гостю email и пароль и снимает флаг гостя
+	// (storage.ErrUserExists, если email занят; storage.ErrUserNotFound, если пользователь не гость).
+	UpgradeGuest(ctx context.Context, userID int64, email string, passHash []byte) error
+}
+
+// WithGuests - разрешает гостевых пользователей: CreateGuest выдаёт им токены со сроком действия ttl.
+func WithGuests(store GuestStore, ttl time.Duration) Option {
+	return func(a *AuthService) {
+		a.guests = store
+		a.guestTTL = ttl
+	}
+}
+
+// CreateGuest - создаёт гостя (без email и пароля) и выдаёт ему токен приложения appID с клеймом guest.
+// Гость может пользоваться приложением, а позже стать обычным пользователем через UpgradeGuest.
+func (a *AuthService) CreateGuest(ctx context.Context, appID int) (int64, models.Token, error) {
+	const op = "Auth.CreateGuest"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("app_id", appID))
+
+	if a.guests == nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrGuestsDisabled)
+	}
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Стороннему приложению нужно согласие пользователя, а гость дать его не может
+	if app.ThirdParty {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrGuestAppNotAllowed)
+	}
+
+	placeholder, err := newGuestPlaceholder()
+	if err != nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := a.guests.SaveGuest(ctx, placeholder)
+	if err != nil {
+		log.Error("failed to save guest", slog.String("error", err.Error()))
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := a.issueToken(ctx, models.User{ID: id, IsActive: true, IsGuest: true}, app)
+	if err != nil {
+		log.Error("failed to create token", slog.String("error", err.Error()))
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("guest created", slog.Int64("user_id", id))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventGuestCreated, Outcome: audit.OutcomeSuccess,
+		UserID: id, AppID: appID,
+	})
+
+	return id, token, nil
+}
+
+// UpgradeGuest - превращает гостя из guestToken в обычного пользователя с тем же id: задаёт email и пароль
+// с теми же проверками, что и при регистрации, и выдаёт обычный токен того же приложения.
+// Токены гостя после этого больше не действуют.
+func (a *AuthService) UpgradeGuest(
+	ctx context.Context,
+	guestToken string,
+	email string,
+	password string,
+	acceptedTOS string,
+) (int64, models.Token, error) {
+	const op = "Auth.UpgradeGuest"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.String("email", email))
+
+	if a.guests == nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrGuestsDisabled)
+	}
+
+	principal, err := a.Authenticate(ctx, guestToken)
+	if err != nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !principal.IsGuest {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrNotGuest)
+	}
+	id := principal.UserID
+
+	tos := a.tosPolicy()
+	if tos.Version != "" && acceptedTOS != tos.Version {
+		log.Info("terms of service not accepted", slog.String("accepted", acceptedTOS))
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrTOSNotAccepted)
+	}
+
+	if !a.domains.Load().Allowed(email) {
+		log.Info("email domain rejected")
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventRegisterFailed, Outcome: audit.OutcomeFailure,
+			UserID: id, Email: email, Reason: "email domain not allowed",
+		})
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrDomainNotAllowed)
+	}
+
+	password, err = prepareNewPassword(password)
+	if err != nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.checkBreached(ctx, password); err != nil {
+		if errors.Is(err, ErrWeakPassword) {
+			log.Info("compromised password rejected")
+		} else {
+			log.Error("failed to check password", slog.String("error", err.Error()))
+		}
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	passHash, err := a.hashPassword(ctx, password)
+	if err != nil {
+		log.Error("failed to generate password hash", slog.String("error", err.Error()))
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Для подписчиков событий аккаунт появляется только сейчас: гости в user.registered не попадают
+	err = a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		if err := a.guests.UpgradeGuest(ctx, id, email, passHash); err != nil {
+			return events.Event{}, err
+		}
+
+		if tos.Version != "" {
+			if err := a.tosStore.AcceptTOS(ctx, id, tos.Version); err != nil {
+				return events.Event{}, err
+			}
+		}
+
+		return events.New(ctx, events.TypeUserRegistered, id), nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrUserExists):
+			log.Info("email already taken")
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventRegisterFailed, Outcome: audit.OutcomeFailure,
+				UserID: id, Email: email, Reason: "user already exists",
+			})
+
+			return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrUserExists)
+		case errors.Is(err, storage.ErrUserNotFound):
+			// Гостя успели обновить параллельным запросом
+			return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrNotGuest)
+		}
+
+		log.Error("failed to upgrade guest", slog.String("error", err.Error()))
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	app, err := a.appProvider.App(ctx, principal.AppID)
+	if err != nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := a.issueToken(ctx, models.User{ID: id, Email: email, IsActive: true}, app)
+	if err != nil {
+		log.Error("failed to create token", slog.String("error", err.Error()))
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("guest upgraded", slog.Int64("user_id", id))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventGuestUpgraded, Outcome: audit.OutcomeSuccess,
+		UserID: id, Email: email, AppID: app.ID,
+	})
+
+	return id, token, nil
+}
+
+// touchGuest - отмечает активность гостя; ошибка только логируется, чтобы не ронять запрос
+func (a *AuthService) touchGuest(ctx context.Context, userID int64) {
+	if a.guests == nil {
+		return
+	}
+
+	if err := a.guests.TouchGuest(ctx, userID); err != nil {
+		logctx.FromOr(ctx, a.log).Warn("failed to record guest activity",
+			slog.Int64("user_id", userID),
+			slog.String("error", err.Error()))
+	}
+}
+
+// newGuestPlaceholder - уникальная email-заглушка гостя (колонка email обязательна и уникальна)
+func newGuestPlaceholder() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return "guest-" + hex.EncodeToString(b) + "@" + guestEmailDomain, nil
+}
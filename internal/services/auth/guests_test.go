@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateGuest_UpgradeKeepsID(t *testing.T) {
+	guests := mocks.NewGuestStore(t)
+	a, _, provider, apps := newTestService(t, WithGuests(guests, 10*time.Minute))
+	ctx := context.Background()
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	guests.EXPECT().SaveGuest(mock.Anything, mock.MatchedBy(func(email string) bool {
+		return strings.HasSuffix(email, "@"+guestEmailDomain)
+	})).Return(42, nil)
+
+	id, token, err := a.CreateGuest(ctx, testApp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), token.ExpiresAt, 5*time.Second)
+
+	claims, err := jwt.ParseForApp(token.AccessToken, testApp)
+	require.NoError(t, err)
+	assert.True(t, claims.Guest)
+	assert.Empty(t, claims.Email, "email placeholder stays out of the token")
+
+	// Каждый запрос гостя продлевает ему жизнь
+	guest := models.User{ID: 42, Email: "guest-x@guest.invalid", IsActive: true, IsGuest: true}
+	provider.EXPECT().UserByID(mock.Anything, int64(42)).Return(guest, nil).Times(2)
+	guests.EXPECT().TouchGuest(mock.Anything, int64(42)).Return(nil).Times(2)
+
+	p, err := a.Authenticate(ctx, token.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, p.IsGuest)
+
+	guests.EXPECT().UpgradeGuest(mock.Anything, int64(42), "new@b.c", mock.Anything).Return(nil)
+
+	id, upgraded, err := a.UpgradeGuest(ctx, token.AccessToken, "new@b.c", "secret", "")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+
+	claims, err = jwt.ParseForApp(upgraded.AccessToken, testApp)
+	require.NoError(t, err)
+	assert.False(t, claims.Guest)
+	assert.Equal(t, int64(42), claims.UID)
+	assert.Equal(t, "new@b.c", claims.Email)
+
+	// Гостевой токен уже обновлённого пользователя не действует
+	provider.EXPECT().UserByID(mock.Anything, int64(42)).Return(models.User{ID: 42, Email: "new@b.c", IsActive: true}, nil)
+
+	_, err = a.Authenticate(ctx, token.AccessToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestUpgradeGuest_RegularTokenRejected(t *testing.T) {
+	a, _, provider, apps := newTestService(t, WithGuests(mocks.NewGuestStore(t), time.Minute))
+	user := userWithPassword(t, "secret")
+
+	token, err := jwt.NewToken(user, testApp, time.Hour)
+	require.NoError(t, err)
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+
+	_, _, err = a.UpgradeGuest(context.Background(), token, "new@b.c", "secret", "")
+	require.ErrorIs(t, err, ErrNotGuest)
+}
+
+func TestCreateGuest_Restrictions(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+
+	_, _, err := a.CreateGuest(context.Background(), testApp.ID)
+	require.ErrorIs(t, err, ErrGuestsDisabled)
+
+	a, _, _, apps := newTestService(t, WithGuests(mocks.NewGuestStore(t), time.Minute))
+	apps.EXPECT().App(mock.Anything, partnerApp.ID).Return(partnerApp, nil)
+
+	_, _, err = a.CreateGuest(context.Background(), partnerApp.ID)
+	require.ErrorIs(t, err, ErrGuestAppNotAllowed)
+}
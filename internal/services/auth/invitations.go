@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/events"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/mail"
+	"sso/internal/storage"
+	"time"
+)
+
+// DefaultInvitationTTL - срок действия приглашения, если он не задан в WithInvitations
+const DefaultInvitationTTL = 72 * time.Hour
+
+// Ошибки приглашений
+var (
+	ErrInvitationsDisabled = errors.New("invitations are disabled")                  // Ошибка, если хранилище приглашений не задано.
+	ErrInvalidRole         = errors.New("invalid role")                              // Ошибка, если роль приглашения неизвестна.
+	ErrInvitationNotFound  = errors.New("invitation not found")                      // Ошибка, если приглашения с таким токеном или id нет.
+	ErrInvitationExpired   = errors.New("invitation expired")                        // Ошибка, если срок приглашения истёк.
+	ErrInvitationUsed      = errors.New("invitation is already accepted or revoked") // Ошибка, если приглашение уже использовано.
+)
+
+// InvitationStore - хранилище приглашений.
+type InvitationStore interface {
+	// SaveInvitation - сохраняет приглашение или продлевает ожидающее приглашение того же email (renewed = true).
+	SaveInvitation(ctx context.Context, inv models.Invitation) (saved models.Invitation, renewed bool, err error)
+	// InvitationByTokenHash - приглашение по SHA-256 токена (storage.ErrInvitationNotFound, если его нет).
+	InvitationByTokenHash(ctx context.Context, tokenHash string) (models.Invitation, error)
+	// AcceptInvitation - создаёт пользователя по приглашению и помечает его принятым (storage.ErrInvitationUsed).
+	AcceptInvitation(ctx context.Context, invitationID int64, passHash []byte) (int64, error)
+	// ListInvitations - приглашения постранично по возрастанию id.
+	ListInvitations(ctx context.Context, afterID int64, limit int, pendingOnly bool) ([]models.Invitation, error)
+	// RevokeInvitation - отзывает ожидающее приглашение и возвращает его email.
+	RevokeInvitation(ctx context.Context, invitationID int64) (string, error)
+}
+
+// WithInvitations - включает приглашения. ttl - срок действия (0 - DefaultInvitationTTL);
+// acceptURL - страница принятия, к которой в письме добавляется ?token=... (пусто - в письме только код).
+func WithInvitations(store InvitationStore, ttl time.Duration, acceptURL string) Option {
+	return func(a *AuthService) {
+		if ttl <= 0 {
+			ttl = DefaultInvitationTTL
+		}
+		a.invitations = store
+		a.invitationTTL = ttl
+		a.acceptURL = acceptURL
+	}
+}
+
+// InviteUser - приглашает email с ролью role (models.RoleUser или models.RoleAdmin) и отправляет письмо
+// с одноразовым токеном. Повторное приглашение того же email, пока первое ждёт ответа, не создаёт
+// второе, а продлевает первое и отправляет новое письмо (прежняя ссылка перестаёт действовать).
+func (a *AuthService) InviteUser(ctx context.Context, email string, appID int, role string) (models.Invitation, error) {
+	const op = "Auth.InviteUser"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.String("email", email),
+		slog.Int("app_id", appID),
+		slog.String("role", role))
+
+	if a.invitations == nil || a.mailer == nil {
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, ErrInvitationsDisabled)
+	}
+
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return models.Invitation{}, fmt.Errorf("%s: %w: %q", op, ErrInvalidRole, role)
+	}
+
+	if _, err := a.appProvider.App(ctx, appID); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.Invitation{}, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := a.usrProvider.User(ctx, email); err == nil {
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, ErrUserExists)
+	} else if !errors.Is(err, storage.ErrUserNotFound) {
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token, err := newInvitationToken()
+	if err != nil {
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	principal, _ := authctx.From(ctx)
+	inv, renewed, err := a.invitations.SaveInvitation(ctx, models.Invitation{
+		Email:     email,
+		TokenHash: hashInvitationToken(token),
+		AppID:     appID,
+		Role:      role,
+		InvitedBy: principal.UserID,
+		ExpiresAt: a.clock.Now().Add(a.invitationTTL).UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		log.Error("failed to save invitation", slog.String("error", err.Error()))
+
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	data := mail.Data{Email: email, Link: a.invitationLink(token), ExpiresAt: inv.ExpiresAt}
+	if data.Link == "" {
+		data.Code = token
+	}
+	if err := a.mailer.SendTemplate(ctx, email, mail.TemplateInvitation, data); err != nil {
+		// Приглашение сохранено: повторный InviteUser продлит его и отправит письмо снова
+		log.Error("failed to send invitation", slog.String("error", err.Error()))
+
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("user invited", slog.Int64("invitation_id", inv.ID), slog.Bool("renewed", renewed))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventUserInvited, Outcome: audit.OutcomeSuccess,
+		Email: email, AppID: appID,
+	})
+
+	return inv, nil
+}
+
+// AcceptInvitation - создаёт по приглашению пользователя с паролем password (email сразу считается
+// подтверждённым, роль берётся из приглашения) и выдаёт ему токен для приложения из приглашения.
+// Истёкшее приглашение - ErrInvitationExpired, уже принятое или отозванное - ErrInvitationUsed.
+func (a *AuthService) AcceptInvitation(ctx context.Context, token, password string) (int64, models.Token, error) {
+	const op = "Auth.AcceptInvitation"
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+
+	if a.invitations == nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrInvitationsDisabled)
+	}
+
+	inv, err := a.invitations.InvitationByTokenHash(ctx, hashInvitationToken(token))
+	if err != nil {
+		if errors.Is(err, storage.ErrInvitationNotFound) {
+			return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrInvitationNotFound)
+		}
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log = log.With(slog.Int64("invitation_id", inv.ID), slog.String("email", inv.Email))
+
+	if !inv.Pending() {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrInvitationUsed)
+	}
+	if a.clock.Now().After(inv.ExpiresAt) {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrInvitationExpired)
+	}
+
+	// Приложение проверяется до создания пользователя: без него токен не выдать
+	app, err := a.appProvider.App(ctx, inv.AppID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	password, err = prepareNewPassword(password)
+	if err != nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Домены не проверяются: приглашение - осознанное решение администратора
+	if err := a.checkBreached(ctx, password); err != nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	passHash, err := a.hashPassword(ctx, password)
+	if err != nil {
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var userID int64
+	err = a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		if userID, err = a.invitations.AcceptInvitation(ctx, inv.ID, passHash); err != nil {
+			return events.Event{}, err
+		}
+
+		return events.New(ctx, events.TypeUserRegistered, userID), nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrInvitationUsed):
+			return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrInvitationUsed)
+		case errors.Is(err, storage.ErrUserExists):
+			return 0, models.Token{}, fmt.Errorf("%s: %w", op, ErrUserExists)
+		}
+
+		log.Error("failed to accept invitation", slog.String("error", err.Error()))
+
+		return 0, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("invitation accepted", slog.Int64("user_id", userID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventInvitationAccepted, Outcome: audit.OutcomeSuccess,
+		UserID: userID, Email: inv.Email, AppID: inv.AppID, ActorID: inv.InvitedBy,
+	})
+
+	user := models.User{ID: userID, Email: inv.Email, IsAdmin: inv.Role == models.RoleAdmin, IsActive: true, EmailVerified: true}
+	t, err := a.issueToken(ctx, user, app)
+	if err != nil {
+		// Пользователь уже создан: он может войти через Login
+		log.Error("failed to create token", slog.String("error", err.Error()))
+
+		return userID, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, t, nil
+}
+
+// ListInvitations - до limit приглашений с id больше afterID; pendingOnly - только ожидающие ответа.
+func (a *AuthService) ListInvitations(
+	ctx context.Context,
+	afterID int64,
+	limit int,
+	pendingOnly bool,
+) ([]models.Invitation, error) {
+	const op = "Auth.ListInvitations"
+
+	if a.invitations == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrInvitationsDisabled)
+	}
+
+	invitations, err := a.invitations.ListInvitations(ctx, afterID, limit, pendingOnly)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation - отзывает ожидающее приглашение; его ссылка перестаёт действовать.
+func (a *AuthService) RevokeInvitation(ctx context.Context, invitationID int64) error {
+	const op = "Auth.RevokeInvitation"
+
+	if a.invitations == nil {
+		return fmt.Errorf("%s: %w", op, ErrInvitationsDisabled)
+	}
+
+	email, err := a.invitations.RevokeInvitation(ctx, invitationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrInvitationNotFound):
+			return fmt.Errorf("%s: %w", op, ErrInvitationNotFound)
+		case errors.Is(err, storage.ErrInvitationUsed):
+			return fmt.Errorf("%s: %w", op, ErrInvitationUsed)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	logctx.FromOr(ctx, a.log).Info("invitation revoked",
+		slog.String("op", op),
+		slog.Int64("invitation_id", invitationID),
+		slog.String("email", email))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventInvitationRevoked, Outcome: audit.OutcomeSuccess,
+		Email: email,
+	})
+
+	return nil
+}
+
+// invitationLink - ссылка принятия с токеном (пусто, если acceptURL не задан; его формат проверяет конфигурация)
+func (a *AuthService) invitationLink(token string) string {
+	return linkWithToken(a.acceptURL, token)
+}
+
+// linkWithToken - страница page с параметром token (пусто, если страница не задана)
+func linkWithToken(page, token string) string {
+	if page == "" {
+		return ""
+	}
+
+	u, err := url.Parse(page)
+	if err != nil {
+		return ""
+	}
+
+	q := u.Query()
+	q.Set("token", token)
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// newInvitationToken - случайный одноразовый токен приглашения (256 бит)
+func newInvitationToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashInvitationToken - в хранилище лежит только SHA-256 токена: утечка БД не даёт принять приглашения.
+// Медленный хеш не нужен - у токена 256 бит энтропии
+func hashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+
+	return hex.EncodeToString(sum[:])
+}
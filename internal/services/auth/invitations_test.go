@@ -0,0 +1,170 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/mail"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInviteUser(t *testing.T) {
+	store := mocks.NewInvitationStore(t)
+	mailer := mocks.NewMailer(t)
+	a, _, provider, apps := newTestService(t,
+		WithInvitations(store, time.Hour, "https://app.example.com/accept?lang=en"), WithMailer(mailer))
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().User(mock.Anything, "new@b.c").
+		Return(models.User{}, fmt.Errorf("storage.sqlite.User: %w", storage.ErrUserNotFound))
+
+	var saved models.Invitation
+	store.EXPECT().SaveInvitation(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, inv models.Invitation) (models.Invitation, bool, error) {
+			saved = inv
+			inv.ID = 5
+			return inv, false, nil
+		})
+
+	var sent mail.Data
+	mailer.EXPECT().SendTemplate(mock.Anything, "new@b.c", mail.TemplateInvitation, mock.Anything).
+		RunAndReturn(func(_ context.Context, _, _ string, data mail.Data) error {
+			sent = data
+			return nil
+		})
+
+	inv, err := a.InviteUser(context.Background(), "new@b.c", testApp.ID, models.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), inv.ID)
+	assert.Equal(t, models.RoleAdmin, saved.Role)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), saved.ExpiresAt, 2*time.Second)
+
+	// В письме ссылка с токеном, в хранилище - только его хеш
+	u, err := url.Parse(sent.Link)
+	require.NoError(t, err)
+	assert.Equal(t, "en", u.Query().Get("lang"))
+	token := u.Query().Get("token")
+	require.NotEmpty(t, token)
+	assert.Empty(t, sent.Code)
+	assert.Equal(t, hashInvitationToken(token), saved.TokenHash)
+	assert.NotEqual(t, token, saved.TokenHash)
+}
+
+func TestInviteUser_CodeWithoutAcceptURL(t *testing.T) {
+	store := mocks.NewInvitationStore(t)
+	mailer := mocks.NewMailer(t)
+	a, _, provider, apps := newTestService(t, WithInvitations(store, 0, ""), WithMailer(mailer))
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().User(mock.Anything, "new@b.c").Return(models.User{}, storage.ErrUserNotFound)
+	store.EXPECT().SaveInvitation(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, inv models.Invitation) (models.Invitation, bool, error) {
+			return inv, true, nil
+		})
+	mailer.EXPECT().SendTemplate(mock.Anything, "new@b.c", mail.TemplateInvitation,
+		mock.MatchedBy(func(d mail.Data) bool { return d.Link == "" && d.Code != "" })).Return(nil)
+
+	inv, err := a.InviteUser(context.Background(), "new@b.c", testApp.ID, models.RoleUser)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(DefaultInvitationTTL), inv.ExpiresAt, 2*time.Second)
+}
+
+func TestInviteUser_Rejected(t *testing.T) {
+	store := mocks.NewInvitationStore(t)
+	mailer := mocks.NewMailer(t)
+	a, _, provider, apps := newTestService(t, WithInvitations(store, 0, ""), WithMailer(mailer))
+
+	_, err := a.InviteUser(context.Background(), "new@b.c", testApp.ID, "owner")
+	require.ErrorIs(t, err, ErrInvalidRole)
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(userWithPassword(t, "secret"), nil)
+
+	_, err = a.InviteUser(context.Background(), "a@b.c", testApp.ID, models.RoleUser)
+	require.ErrorIs(t, err, ErrUserExists)
+
+	// Без хранилища приглашения выключены
+	a, _, _, _ = newTestService(t, WithMailer(mailer))
+	_, err = a.InviteUser(context.Background(), "new@b.c", testApp.ID, models.RoleUser)
+	require.ErrorIs(t, err, ErrInvitationsDisabled)
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	store := mocks.NewInvitationStore(t)
+	a, _, _, apps := newTestService(t, WithInvitations(store, 0, ""))
+
+	inv := models.Invitation{ID: 5, Email: "new@b.c", AppID: testApp.ID, Role: models.RoleAdmin, ExpiresAt: time.Now().Add(time.Hour)}
+	store.EXPECT().InvitationByTokenHash(mock.Anything, hashInvitationToken("tok")).Return(inv, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	store.EXPECT().AcceptInvitation(mock.Anything, inv.ID, mock.Anything).Return(42, nil)
+
+	userID, token, err := a.AcceptInvitation(context.Background(), "tok", "secret")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), userID)
+
+	claims, err := jwt.Parse(token.AccessToken, []byte(testApp.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), claims.UID)
+}
+
+func TestAcceptInvitation_NotUsable(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name string
+		inv  models.Invitation
+		want error
+	}{
+		{
+			name: "expired",
+			inv:  models.Invitation{ID: 5, ExpiresAt: now.Add(-time.Minute)},
+			want: ErrInvitationExpired,
+		},
+		{
+			name: "accepted",
+			inv:  models.Invitation{ID: 5, ExpiresAt: now.Add(time.Hour), AcceptedAt: now},
+			want: ErrInvitationUsed,
+		},
+		{
+			name: "revoked",
+			inv:  models.Invitation{ID: 5, ExpiresAt: now.Add(time.Hour), RevokedAt: now},
+			want: ErrInvitationUsed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// AppProvider не вызывается: пользователь не создаётся
+			store := mocks.NewInvitationStore(t)
+			a, _, _, _ := newTestService(t, WithInvitations(store, 0, ""))
+
+			store.EXPECT().InvitationByTokenHash(mock.Anything, mock.Anything).Return(tt.inv, nil)
+
+			_, _, err := a.AcceptInvitation(context.Background(), "tok", "secret")
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestAcceptInvitation_RaceLost(t *testing.T) {
+	store := mocks.NewInvitationStore(t)
+	a, _, _, apps := newTestService(t, WithInvitations(store, 0, ""))
+
+	inv := models.Invitation{ID: 5, Email: "new@b.c", AppID: testApp.ID, Role: models.RoleUser, ExpiresAt: time.Now().Add(time.Hour)}
+	store.EXPECT().InvitationByTokenHash(mock.Anything, mock.Anything).Return(inv, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	store.EXPECT().AcceptInvitation(mock.Anything, inv.ID, mock.Anything).
+		Return(0, fmt.Errorf("storage.sqlite.AcceptInvitation: %w", storage.ErrInvitationUsed))
+
+	_, _, err := a.AcceptInvitation(context.Background(), "tok", "secret")
+	require.ErrorIs(t, err, ErrInvitationUsed)
+}
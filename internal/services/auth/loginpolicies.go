@@ -0,0 +1,85 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/clientip"
+	"time"
+)
+
+// ErrLoginPolicyDenied - вход запрещён политикой приложения (models.LoginPolicy). Ошибка приходит
+// обёрнутой в *PolicyDeniedError: из неё видно, какая политика и какое условие нарушены
+var ErrLoginPolicyDenied = errors.New("login denied by app policy")
+
+// PolicyDeniedError - нарушенная политика входа: имя политики и условие (models.PolicyRule*)
+type PolicyDeniedError struct {
+	Policy string
+	Rule   string
+}
+
+func (e *PolicyDeniedError) Error() string {
+	return fmt.Sprintf("%s: policy %q: %s rule", ErrLoginPolicyDenied, e.Policy, e.Rule)
+}
+
+func (e *PolicyDeniedError) Unwrap() error {
+	return ErrLoginPolicyDenied
+}
+
+// loginPolicyViolation - первая политика приложения, которую нарушает вход с адреса клиента
+// из ctx в момент now (nil - нарушений нет). Без адреса клиента сетевые условия не выполняются
+func loginPolicyViolation(ctx context.Context, app models.App, now time.Time) *PolicyDeniedError {
+	if len(app.LoginPolicies) == 0 {
+		return nil
+	}
+
+	addr, _ := clientip.From(ctx)
+	for _, p := range app.LoginPolicies {
+		if rule := p.Violation(addr, now); rule != "" {
+			return &PolicyDeniedError{Policy: p.Name, Rule: rule}
+		}
+	}
+
+	return nil
+}
+
+// checkLoginPolicies - проверяет политики входа app для выдачи токена пользователю userID.
+// Отказ попадает в журнал безопасности как неудачное событие event
+func (a *AuthService) checkLoginPolicies(
+	ctx context.Context,
+	log *slog.Logger,
+	event string,
+	app models.App,
+	userID int64,
+	email string,
+) error {
+	denied := loginPolicyViolation(ctx, app, a.clock.Now())
+	if denied == nil {
+		return nil
+	}
+
+	log.Info("login denied by app policy", slog.String("policy", denied.Policy), slog.String("rule", denied.Rule))
+	a.audit.Emit(ctx, audit.Event{
+		Name: event, Outcome: audit.OutcomeFailure,
+		UserID: userID, Email: email, AppID: app.ID,
+		Reason: fmt.Sprintf("policy %q: %s rule", denied.Policy, denied.Rule),
+	})
+
+	return denied
+}
+
+// networkPolicyAllows - разрешают ли сетевые условия политик app обращение с адреса клиента из ctx.
+// Используется при проверке выданных токенов приложений с models.App.RecheckNetworkPolicy
+func networkPolicyAllows(ctx context.Context, app models.App) bool {
+	addr, _ := clientip.From(ctx)
+	for _, p := range app.LoginPolicies {
+		if !p.NetworkAllowed(addr) {
+			return false
+		}
+	}
+
+	return true
+}
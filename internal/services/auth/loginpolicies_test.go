@@ -0,0 +1,103 @@
+package auth
+
+import (
+	"context"
+	"net/netip"
+	"sso/internal/domain/models"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/jwt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+var office = netip.MustParsePrefix("10.1.0.0/16")
+
+func TestLoginPolicy_Violation(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	inOffice := netip.MustParseAddr("10.1.2.3")
+	// Среда, 10:00 по Москве
+	wednesday := time.Date(2026, 10, 14, 7, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		policy models.LoginPolicy
+		addr   netip.Addr
+		now    time.Time
+		want   string
+	}{
+		{"empty policy", models.LoginPolicy{}, netip.Addr{}, wednesday, ""},
+		{"office network", models.LoginPolicy{Networks: []netip.Prefix{office}}, inOffice, wednesday, ""},
+		{"mapped ipv4", models.LoginPolicy{Networks: []netip.Prefix{office}}, netip.MustParseAddr("::ffff:10.1.2.3"), wednesday, ""},
+		{"home network", models.LoginPolicy{Networks: []netip.Prefix{office}}, netip.MustParseAddr("192.0.2.1"), wednesday, models.PolicyRuleNetwork},
+		{"unknown address", models.LoginPolicy{Networks: []netip.Prefix{office}}, netip.Addr{}, wednesday, models.PolicyRuleNetwork},
+		{"weekday", models.LoginPolicy{Weekdays: []time.Weekday{time.Wednesday}, Location: moscow}, inOffice, wednesday, ""},
+		{"weekend", models.LoginPolicy{Weekdays: []time.Weekday{time.Saturday, time.Sunday}}, inOffice, wednesday, models.PolicyRuleWeekday},
+		{"business hours", models.LoginPolicy{StartMinute: 9 * 60, EndMinute: 18 * 60, Location: moscow}, inOffice, wednesday, ""},
+		// В UTC ещё 07:00
+		{"business hours in utc", models.LoginPolicy{StartMinute: 9 * 60, EndMinute: 18 * 60}, inOffice, wednesday, models.PolicyRuleTime},
+		{"end is exclusive", models.LoginPolicy{StartMinute: 9 * 60, EndMinute: 10 * 60, Location: moscow}, inOffice, wednesday, models.PolicyRuleTime},
+		{"night shift", models.LoginPolicy{StartMinute: 22 * 60, EndMinute: 6 * 60}, inOffice, wednesday.Add(-3 * time.Hour), ""},
+		{"night shift over", models.LoginPolicy{StartMinute: 22 * 60, EndMinute: 6 * 60}, inOffice, wednesday, models.PolicyRuleTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Violation(tt.addr, tt.now))
+		})
+	}
+}
+
+func TestLogin_PolicyDenied(t *testing.T) {
+	a, _, provider, apps := newTestService(t)
+	user := userWithPassword(t, "secret")
+
+	internal := testApp
+	internal.LoginPolicies = []models.LoginPolicy{{Name: "office", Networks: []netip.Prefix{office}}}
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(internal, nil)
+
+	_, err := a.Login(clientip.Into(context.Background(), netip.MustParseAddr("192.0.2.1")), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrLoginPolicyDenied)
+
+	var denied *PolicyDeniedError
+	require.ErrorAs(t, err, &denied)
+	assert.Equal(t, "office", denied.Policy)
+	assert.Equal(t, models.PolicyRuleNetwork, denied.Rule)
+
+	_, err = a.Login(clientip.Into(context.Background(), netip.MustParseAddr("10.1.0.5")), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+}
+
+func TestAuthenticate_RecheckNetworkPolicy(t *testing.T) {
+	a, _, provider, apps := newTestService(t)
+	user := userWithPassword(t, "secret")
+
+	internal := testApp
+	internal.LoginPolicies = []models.LoginPolicy{{Name: "office", Networks: []netip.Prefix{office}}}
+
+	token, err := jwt.NewToken(user, internal, time.Hour)
+	require.NoError(t, err)
+
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(internal, nil).Once()
+
+	// Без повторной проверки сеть важна только при входе
+	home := clientip.Into(context.Background(), netip.MustParseAddr("192.0.2.1"))
+	_, err = a.Authenticate(home, token)
+	require.NoError(t, err)
+
+	internal.RecheckNetworkPolicy = true
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(internal, nil)
+
+	_, err = a.Authenticate(home, token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	_, err = a.Authenticate(clientip.Into(context.Background(), netip.MustParseAddr("10.1.0.5")), token)
+	require.NoError(t, err)
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net/netip"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/events"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/useragent"
+	"time"
+)
+
+// LoginHistoryStore - история успешных входов
+type LoginHistoryStore interface {
+	// AddLogin - записывает успешный вход.
+	AddLogin(ctx context.Context, r models.LoginRecord) error
+	// RecentLogins - до limit последних входов пользователя, от новых к старым.
+	RecentLogins(ctx context.Context, userID int64, limit int) ([]models.LoginRecord, error)
+	// AddLoginFailure - записывает неудачный вход (для статистики, без данных пользователя).
+	AddLoginFailure(ctx context.Context, appID int, at time.Time) error
+}
+
+// GeoLocator - страна и сеть по адресу клиента (geoip.DB)
+type GeoLocator interface {
+	// Locate - false, если адрес не найден в базе
+	Locate(addr netip.Addr) (models.GeoLocation, bool)
+}
+
+// LoginHistorySettings - параметры истории входов и поиска подозрительных входов
+type LoginHistorySettings struct {
+	Geo           GeoLocator // Определение страны по адресу клиента (nil - страна не определяется, подозрительных входов нет)
+	HistoryDepth  int        // Со сколькими последними входами сравнивается страна нового
+	RequireStepUp bool       // Подозрительный вход требует StepUp, если у пользователя есть второй фактор
+}
+
+// WithLoginHistory - успешные входы записываются в историю; вход из страны, которой нет среди
+// последних входов пользователя, считается подозрительным (событие login.suspicious)
+func WithLoginHistory(store LoginHistoryStore, s LoginHistorySettings) Option {
+	return func(a *AuthService) {
+		a.loginHistory = store
+		a.loginRisk = s
+	}
+}
+
+// recordLoginFailure - записывает неудачный вход; ошибка хранилища только логируется,
+// ответ клиенту от неё не зависит
+func (a *AuthService) recordLoginFailure(ctx context.Context, log *slog.Logger, appID int) {
+	if a.loginHistory == nil {
+		return
+	}
+
+	if err := a.loginHistory.AddLoginFailure(ctx, appID, a.clock.Now()); err != nil {
+		log.Error("failed to record login failure", slog.String("error", err.Error()))
+	}
+}
+
+// recordLogin - записывает вход в историю и сообщает, подозрителен ли он.
+// Вход к этому моменту уже разрешён, поэтому ошибки хранилища только логируются
+func (a *AuthService) recordLogin(ctx context.Context, user models.User, appID int) (suspicious bool) {
+	log := logctx.FromOr(ctx, a.log).With(slog.Int64("user_id", user.ID), slog.Int("app_id", appID))
+
+	userAgent := useragent.From(ctx)
+	record := models.LoginRecord{
+		UserID: user.ID, AppID: appID, Device: useragent.Fingerprint(userAgent), LoggedInAt: a.clock.Now(),
+	}
+
+	if a.loginRisk.Geo != nil {
+		if ip, ok := clientip.From(ctx); ok {
+			if loc, ok := a.loginRisk.Geo.Locate(ip); ok {
+				record.Country = loc.Country
+				record.ASN = loc.ASN
+			}
+		}
+	}
+
+	// История нужна, чтобы сравнить страну, и для писем - чтобы сравнить устройство
+	var recent []models.LoginRecord
+	if record.Country != "" || a.loginAlerts != nil {
+		var err error
+		if recent, err = a.loginHistory.RecentLogins(ctx, user.ID, a.loginRisk.HistoryDepth); err != nil {
+			log.Error("failed to read login history", slog.String("error", err.Error()))
+		}
+	}
+	if record.Country != "" {
+		record.Suspicious = newCountry(record.Country, recent)
+	}
+
+	if err := a.loginHistory.AddLogin(ctx, record); err != nil {
+		log.Error("failed to record login", slog.String("error", err.Error()))
+	}
+
+	if a.loginAlerts != nil && (record.Suspicious || newDevice(record.Device, recent)) {
+		a.alertLogin(ctx, user, record, userAgent)
+	}
+
+	if !record.Suspicious {
+		return false
+	}
+
+	log.Warn("login from a new country", slog.String("country", record.Country), slog.Uint64("asn", uint64(record.ASN)))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventLoginSuspicious, Outcome: audit.OutcomeSuccess,
+		UserID: user.ID, Email: user.Email, AppID: appID, Reason: "new country " + record.Country,
+	})
+
+	e := events.New(ctx, events.TypeLoginSuspicious, user.ID)
+	e.AppID = appID
+	a.publish(ctx, e)
+
+	return true
+}
+
+// newCountry - страны country нет среди входов recent с известной страной.
+// Первый вход с известной страной подозрительным не считается: сравнивать не с чем
+func newCountry(country string, recent []models.LoginRecord) bool {
+	known := false
+	for _, r := range recent {
+		if r.Country == "" {
+			continue
+		}
+		if r.Country == country {
+			return false
+		}
+		known = true
+	}
+
+	return known
+}
+
+// stepUpOnSuspicious - нужно ли после подозрительного входа подтверждение вторым фактором:
+// только если это включено и второй фактор у пользователя есть (иначе подтвердить вход нечем)
+func (a *AuthService) stepUpOnSuspicious(ctx context.Context, userID int64) bool {
+	if !a.loginRisk.RequireStepUp || a.secondFactor == nil {
+		return false
+	}
+
+	enrolled, err := a.secondFactor.HasSecondFactor(ctx, userID)
+	if err != nil {
+		logctx.FromOr(ctx, a.log).Error("failed to check second factor enrollment",
+			slog.Int64("user_id", userID), slog.String("error", err.Error()))
+
+		return false
+	}
+
+	return enrolled
+}
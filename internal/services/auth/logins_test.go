@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"sso/internal/domain/models"
+	"sso/internal/lib/clientip"
+	"sso/internal/lib/events"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// countryGeo - страна по точному адресу
+type countryGeo map[string]string
+
+func (g countryGeo) Locate(addr netip.Addr) (models.GeoLocation, bool) {
+	country, ok := g[addr.String()]
+
+	return models.GeoLocation{Country: country, ASN: 64500}, ok
+}
+
+var testGeo = countryGeo{"198.51.100.1": "DE", "203.0.113.1": "BR"}
+
+func loginFrom(ip string) context.Context {
+	return clientip.Into(context.Background(), netip.MustParseAddr(ip))
+}
+
+func TestLogin_SuspiciousCountry(t *testing.T) {
+	history := mocks.NewLoginHistoryStore(t)
+	factor := mocks.NewSecondFactor(t)
+	publisher := mocks.NewPublisher(t)
+	a, _, provider, apps := newTestService(t,
+		WithLoginHistory(history, LoginHistorySettings{Geo: testGeo, HistoryDepth: 20, RequireStepUp: true}),
+		WithSecondFactor(factor),
+		WithEventPublisher(publisher),
+	)
+	user := userWithPassword(t, "secret")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	history.EXPECT().RecentLogins(mock.Anything, user.ID, 20).
+		Return([]models.LoginRecord{{Country: ""}, {Country: "DE"}}, nil)
+	publisher.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e events.Event) bool {
+		return e.Type == events.TypeUserLoggedIn
+	})).Return(nil)
+
+	// Страна уже встречалась - вход обычный
+	history.EXPECT().AddLogin(mock.Anything, mock.MatchedBy(func(r models.LoginRecord) bool {
+		return r.Country == "DE" && r.ASN == 64500 && !r.Suspicious
+	})).Return(nil).Once()
+
+	token, err := a.Login(loginFrom("198.51.100.1"), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.False(t, token.StepUpRequired)
+
+	// Новая страна - событие login.suspicious и StepUp, раз второй фактор настроен
+	history.EXPECT().AddLogin(mock.Anything, mock.MatchedBy(func(r models.LoginRecord) bool {
+		return r.Country == "BR" && r.Suspicious
+	})).Return(nil).Once()
+	publisher.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e events.Event) bool {
+		return e.Type == events.TypeLoginSuspicious && e.UserID == user.ID && e.AppID == testApp.ID
+	})).Return(nil).Once()
+	factor.EXPECT().HasSecondFactor(mock.Anything, user.ID).Return(true, nil).Once()
+
+	token, err = a.Login(loginFrom("203.0.113.1"), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.True(t, token.StepUpRequired)
+}
+
+func TestLogin_HistoryWithoutGeo(t *testing.T) {
+	history := mocks.NewLoginHistoryStore(t)
+	a, _, provider, apps := newTestService(t, WithLoginHistory(history, LoginHistorySettings{HistoryDepth: 20}))
+	user := userWithPassword(t, "secret")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	// Без базы страна неизвестна: историю не читаем, вход не подозрительный
+	history.EXPECT().AddLogin(mock.Anything, mock.MatchedBy(func(r models.LoginRecord) bool {
+		return r.UserID == user.ID && r.Country == "" && !r.Suspicious
+	})).Return(nil).Once()
+
+	token, err := a.Login(loginFrom("203.0.113.1"), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.False(t, token.StepUpRequired)
+}
+
+func TestLogin_RecordsFailures(t *testing.T) {
+	history := mocks.NewLoginHistoryStore(t)
+	a, _, provider, _ := newTestService(t, WithLoginHistory(history, LoginHistorySettings{HistoryDepth: 20}))
+	user := userWithPassword(t, "secret")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	provider.EXPECT().User(mock.Anything, "missing@b.c").Return(models.User{}, storage.ErrUserNotFound)
+	history.EXPECT().AddLoginFailure(mock.Anything, testApp.ID, mock.Anything).Return(nil).Once()
+
+	_, err := a.Login(context.Background(), "a@b.c", "wrong", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	// Ошибка записи не меняет ответ
+	history.EXPECT().AddLoginFailure(mock.Anything, testApp.ID, mock.Anything).Return(errors.New("database is locked")).Once()
+	_, err = a.Login(context.Background(), "missing@b.c", "secret", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestNewCountry(t *testing.T) {
+	assert.False(t, newCountry("DE", nil), "first login has nothing to compare with")
+	assert.False(t, newCountry("DE", []models.LoginRecord{{Country: ""}}))
+	assert.False(t, newCountry("DE", []models.LoginRecord{{Country: "FR"}, {Country: "DE"}}))
+	assert.True(t, newCountry("BR", []models.LoginRecord{{Country: "FR"}, {Country: "DE"}}))
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/mergepatch"
+	"sso/internal/storage"
+)
+
+// MaxAppMetadataSize - максимальный размер метаданных одного приложения (JSON в байтах)
+const MaxAppMetadataSize = 8 << 10
+
+// metadataRetries - сколько раз UpdateAppMetadata повторяет патч при конкурентном изменении пользователя
+const metadataRetries = 3
+
+// Ошибки метаданных приложений
+var (
+	ErrInvalidMetadata  = errors.New("invalid metadata patch")        // Ошибка, если патч не JSON или результат не объект.
+	ErrMetadataTooLarge = errors.New("metadata too large")            // Ошибка, если результат больше MaxAppMetadataSize.
+	ErrMetadataDisabled = errors.New("app metadata is not available") // Ошибка, если хранилище метаданных не задано.
+	ErrMetadataConflict = errors.New("metadata changed concurrently") // Ошибка, если все повторы патча проиграли гонку.
+)
+
+// AppMetadataProvider - источник метаданных пользователя в пространстве приложения
+type AppMetadataProvider interface {
+	// AppMetadata - JSON-объект приложения ("{}", если пуст) и версия пользователя.
+	AppMetadata(ctx context.Context, userID int64, appID int) ([]byte, int64, error)
+}
+
+// AppMetadataStore - хранилище метаданных пользователя, разделённых по приложениям
+type AppMetadataStore interface {
+	AppMetadataProvider
+	// SetAppMetadata - заменяет объект приложения, если версия пользователя равна expectedVersion
+	// (storage.ErrVersionConflict, если нет).
+	SetAppMetadata(ctx context.Context, userID int64, appID int, metadata []byte, expectedVersion int64) (int64, error)
+	// AllAppMetadata - объекты всех приложений пользователя ({"<app_id>": {...}}).
+	AllAppMetadata(ctx context.Context, userID int64) ([]byte, error)
+}
+
+// WithAppMetadata - позволяет приложениям хранить небольшие настройки пользователя (язык, имя, аватар).
+func WithAppMetadata(store AppMetadataStore) Option {
+	return func(a *AuthService) {
+		a.appMetadata = store
+	}
+}
+
+// AppMetadata - метаданные пользователя в пространстве приложения appID (JSON-объект).
+func (a *AuthService) AppMetadata(ctx context.Context, userID int64, appID int) ([]byte, error) {
+	const op = "Auth.AppMetadata"
+
+	if a.appMetadata == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrMetadataDisabled)
+	}
+
+	metadata, _, err := a.appMetadata.AppMetadata(ctx, userID, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return metadata, nil
+}
+
+// UpdateAppMetadata - применяет к метаданным приложения JSON Merge Patch (RFC 7386: null удаляет ключ)
+// и возвращает результат. Чтение и запись разделены проверкой версии пользователя, поэтому
+// конкурентные патчи не теряются: проигравший перечитывает метаданные и применяет патч заново.
+func (a *AuthService) UpdateAppMetadata(ctx context.Context, userID int64, appID int, patch []byte) ([]byte, error) {
+	const op = "Auth.UpdateAppMetadata"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.Int("app_id", appID))
+
+	if a.appMetadata == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrMetadataDisabled)
+	}
+
+	if _, err := a.appProvider.App(ctx, appID); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return nil, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		current, version, err := a.appMetadata.AppMetadata(ctx, userID, appID)
+		if err != nil {
+			if errors.Is(err, storage.ErrUserNotFound) {
+				return nil, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+			}
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		merged, err := mergepatch.Apply(current, patch)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %w", op, ErrInvalidMetadata, err)
+		}
+		if !bytes.HasPrefix(merged, []byte("{")) {
+			return nil, fmt.Errorf("%s: %w: result must be a JSON object", op, ErrInvalidMetadata)
+		}
+		if len(merged) > MaxAppMetadataSize {
+			return nil, fmt.Errorf("%s: %w: %d bytes, limit %d", op, ErrMetadataTooLarge, len(merged), MaxAppMetadataSize)
+		}
+
+		_, err = a.appMetadata.SetAppMetadata(ctx, userID, appID, merged, version)
+		switch {
+		case err == nil:
+			log.Info("app metadata updated", slog.Int("size", len(merged)))
+
+			return merged, nil
+		case errors.Is(err, storage.ErrVersionConflict) && attempt < metadataRetries:
+			log.Debug("user changed concurrently, retrying", slog.Int("attempt", attempt))
+		case errors.Is(err, storage.ErrUserNotFound):
+			return nil, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		case errors.Is(err, storage.ErrVersionConflict):
+			return nil, fmt.Errorf("%s: %w", op, ErrMetadataConflict)
+		default:
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateAppMetadata_MergePatch(t *testing.T) {
+	store := mocks.NewAppMetadataStore(t)
+	a, _, _, apps := newTestService(t, WithAppMetadata(store))
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	store.EXPECT().AppMetadata(mock.Anything, int64(7), testApp.ID).
+		Return([]byte(`{"locale":"ru-RU","theme":"dark"}`), 3, nil)
+	store.EXPECT().SetAppMetadata(mock.Anything, int64(7), testApp.ID, []byte(`{"locale":"en-US"}`), int64(3)).
+		Return(4, nil)
+
+	got, err := a.UpdateAppMetadata(context.Background(), 7, testApp.ID, []byte(`{"locale":"en-US","theme":null}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"locale":"en-US"}`, string(got))
+}
+
+// Конкурентное изменение пользователя: патч применяется заново к свежим метаданным, а не затирает их
+func TestUpdateAppMetadata_RetriesOnConflict(t *testing.T) {
+	store := mocks.NewAppMetadataStore(t)
+	a, _, _, apps := newTestService(t, WithAppMetadata(store))
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	store.EXPECT().AppMetadata(mock.Anything, int64(7), testApp.ID).Return([]byte(`{}`), 1, nil).Once()
+	store.EXPECT().SetAppMetadata(mock.Anything, int64(7), testApp.ID, []byte(`{"theme":"dark"}`), int64(1)).
+		Return(0, fmt.Errorf("storage.sqlite.SetAppMetadata: %w", storage.ErrVersionConflict)).Once()
+	store.EXPECT().AppMetadata(mock.Anything, int64(7), testApp.ID).Return([]byte(`{"locale":"ru-RU"}`), 2, nil).Once()
+	store.EXPECT().SetAppMetadata(mock.Anything, int64(7), testApp.ID, []byte(`{"locale":"ru-RU","theme":"dark"}`), int64(2)).
+		Return(3, nil).Once()
+
+	got, err := a.UpdateAppMetadata(context.Background(), 7, testApp.ID, []byte(`{"theme":"dark"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"locale":"ru-RU","theme":"dark"}`, string(got))
+}
+
+func TestUpdateAppMetadata_Rejected(t *testing.T) {
+	store := mocks.NewAppMetadataStore(t)
+	a, _, _, apps := newTestService(t, WithAppMetadata(store))
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	store.EXPECT().AppMetadata(mock.Anything, int64(7), testApp.ID).Return([]byte(`{}`), 1, nil)
+
+	tests := []struct {
+		patch string
+		want  error
+	}{
+		{patch: `{"a":`, want: ErrInvalidMetadata},
+		{patch: `["not","an","object"]`, want: ErrInvalidMetadata},
+		{patch: `{"avatar":"` + strings.Repeat("x", MaxAppMetadataSize) + `"}`, want: ErrMetadataTooLarge},
+	}
+	for _, tt := range tests {
+		_, err := a.UpdateAppMetadata(context.Background(), 7, testApp.ID, []byte(tt.patch))
+		require.ErrorIs(t, err, tt.want)
+	}
+}
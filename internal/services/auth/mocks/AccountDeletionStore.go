@@ -0,0 +1,307 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// AccountDeletionStore is an autogenerated mock type for the AccountDeletionStore type
+type AccountDeletionStore struct {
+	mock.Mock
+}
+
+type AccountDeletionStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *AccountDeletionStore) EXPECT() *AccountDeletionStore_Expecter {
+	return &AccountDeletionStore_Expecter{mock: &_m.Mock}
+}
+
+// AccountDeletion provides a mock function with given fields: ctx, userID
+func (_m *AccountDeletionStore) AccountDeletion(ctx context.Context, userID int64) (models.AccountDeletion, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AccountDeletion")
+	}
+
+	var r0 models.AccountDeletion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (models.AccountDeletion, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) models.AccountDeletion); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(models.AccountDeletion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccountDeletionStore_AccountDeletion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AccountDeletion'
+type AccountDeletionStore_AccountDeletion_Call struct {
+	*mock.Call
+}
+
+// AccountDeletion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *AccountDeletionStore_Expecter) AccountDeletion(ctx interface{}, userID interface{}) *AccountDeletionStore_AccountDeletion_Call {
+	return &AccountDeletionStore_AccountDeletion_Call{Call: _e.mock.On("AccountDeletion", ctx, userID)}
+}
+
+func (_c *AccountDeletionStore_AccountDeletion_Call) Run(run func(ctx context.Context, userID int64)) *AccountDeletionStore_AccountDeletion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *AccountDeletionStore_AccountDeletion_Call) Return(_a0 models.AccountDeletion, _a1 error) *AccountDeletionStore_AccountDeletion_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AccountDeletionStore_AccountDeletion_Call) RunAndReturn(run func(context.Context, int64) (models.AccountDeletion, error)) *AccountDeletionStore_AccountDeletion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AccountDeletionByTokenHash provides a mock function with given fields: ctx, tokenHash
+func (_m *AccountDeletionStore) AccountDeletionByTokenHash(ctx context.Context, tokenHash string) (models.AccountDeletion, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AccountDeletionByTokenHash")
+	}
+
+	var r0 models.AccountDeletion
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.AccountDeletion, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.AccountDeletion); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(models.AccountDeletion)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccountDeletionStore_AccountDeletionByTokenHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AccountDeletionByTokenHash'
+type AccountDeletionStore_AccountDeletionByTokenHash_Call struct {
+	*mock.Call
+}
+
+// AccountDeletionByTokenHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash string
+func (_e *AccountDeletionStore_Expecter) AccountDeletionByTokenHash(ctx interface{}, tokenHash interface{}) *AccountDeletionStore_AccountDeletionByTokenHash_Call {
+	return &AccountDeletionStore_AccountDeletionByTokenHash_Call{Call: _e.mock.On("AccountDeletionByTokenHash", ctx, tokenHash)}
+}
+
+func (_c *AccountDeletionStore_AccountDeletionByTokenHash_Call) Run(run func(ctx context.Context, tokenHash string)) *AccountDeletionStore_AccountDeletionByTokenHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *AccountDeletionStore_AccountDeletionByTokenHash_Call) Return(_a0 models.AccountDeletion, _a1 error) *AccountDeletionStore_AccountDeletionByTokenHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AccountDeletionStore_AccountDeletionByTokenHash_Call) RunAndReturn(run func(context.Context, string) (models.AccountDeletion, error)) *AccountDeletionStore_AccountDeletionByTokenHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAccountDeletion provides a mock function with given fields: ctx, userID
+func (_m *AccountDeletionStore) DeleteAccountDeletion(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAccountDeletion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AccountDeletionStore_DeleteAccountDeletion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAccountDeletion'
+type AccountDeletionStore_DeleteAccountDeletion_Call struct {
+	*mock.Call
+}
+
+// DeleteAccountDeletion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *AccountDeletionStore_Expecter) DeleteAccountDeletion(ctx interface{}, userID interface{}) *AccountDeletionStore_DeleteAccountDeletion_Call {
+	return &AccountDeletionStore_DeleteAccountDeletion_Call{Call: _e.mock.On("DeleteAccountDeletion", ctx, userID)}
+}
+
+func (_c *AccountDeletionStore_DeleteAccountDeletion_Call) Run(run func(ctx context.Context, userID int64)) *AccountDeletionStore_DeleteAccountDeletion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *AccountDeletionStore_DeleteAccountDeletion_Call) Return(_a0 error) *AccountDeletionStore_DeleteAccountDeletion_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *AccountDeletionStore_DeleteAccountDeletion_Call) RunAndReturn(run func(context.Context, int64) error) *AccountDeletionStore_DeleteAccountDeletion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DueAccountDeletions provides a mock function with given fields: ctx, before, limit
+func (_m *AccountDeletionStore) DueAccountDeletions(ctx context.Context, before time.Time, limit int) ([]int64, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DueAccountDeletions")
+	}
+
+	var r0 []int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]int64, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []int64); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int64)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AccountDeletionStore_DueAccountDeletions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DueAccountDeletions'
+type AccountDeletionStore_DueAccountDeletions_Call struct {
+	*mock.Call
+}
+
+// DueAccountDeletions is a helper method to define mock.On call
+//   - ctx context.Context
+//   - before time.Time
+//   - limit int
+func (_e *AccountDeletionStore_Expecter) DueAccountDeletions(ctx interface{}, before interface{}, limit interface{}) *AccountDeletionStore_DueAccountDeletions_Call {
+	return &AccountDeletionStore_DueAccountDeletions_Call{Call: _e.mock.On("DueAccountDeletions", ctx, before, limit)}
+}
+
+func (_c *AccountDeletionStore_DueAccountDeletions_Call) Run(run func(ctx context.Context, before time.Time, limit int)) *AccountDeletionStore_DueAccountDeletions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *AccountDeletionStore_DueAccountDeletions_Call) Return(_a0 []int64, _a1 error) *AccountDeletionStore_DueAccountDeletions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AccountDeletionStore_DueAccountDeletions_Call) RunAndReturn(run func(context.Context, time.Time, int) ([]int64, error)) *AccountDeletionStore_DueAccountDeletions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveAccountDeletion provides a mock function with given fields: ctx, d
+func (_m *AccountDeletionStore) SaveAccountDeletion(ctx context.Context, d models.AccountDeletion) error {
+	ret := _m.Called(ctx, d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAccountDeletion")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.AccountDeletion) error); ok {
+		r0 = rf(ctx, d)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// AccountDeletionStore_SaveAccountDeletion_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAccountDeletion'
+type AccountDeletionStore_SaveAccountDeletion_Call struct {
+	*mock.Call
+}
+
+// SaveAccountDeletion is a helper method to define mock.On call
+//   - ctx context.Context
+//   - d models.AccountDeletion
+func (_e *AccountDeletionStore_Expecter) SaveAccountDeletion(ctx interface{}, d interface{}) *AccountDeletionStore_SaveAccountDeletion_Call {
+	return &AccountDeletionStore_SaveAccountDeletion_Call{Call: _e.mock.On("SaveAccountDeletion", ctx, d)}
+}
+
+func (_c *AccountDeletionStore_SaveAccountDeletion_Call) Run(run func(ctx context.Context, d models.AccountDeletion)) *AccountDeletionStore_SaveAccountDeletion_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.AccountDeletion))
+	})
+	return _c
+}
+
+func (_c *AccountDeletionStore_SaveAccountDeletion_Call) Return(_a0 error) *AccountDeletionStore_SaveAccountDeletion_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *AccountDeletionStore_SaveAccountDeletion_Call) RunAndReturn(run func(context.Context, models.AccountDeletion) error) *AccountDeletionStore_SaveAccountDeletion_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAccountDeletionStore creates a new instance of AccountDeletionStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAccountDeletionStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AccountDeletionStore {
+	mock := &AccountDeletionStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,141 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ActionTokenStore is an autogenerated mock type for the ActionTokenStore type
+type ActionTokenStore struct {
+	mock.Mock
+}
+
+type ActionTokenStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ActionTokenStore) EXPECT() *ActionTokenStore_Expecter {
+	return &ActionTokenStore_Expecter{mock: &_m.Mock}
+}
+
+// ConsumeActionToken provides a mock function with given fields: ctx, jti
+func (_m *ActionTokenStore) ConsumeActionToken(ctx context.Context, jti string) (models.ActionToken, error) {
+	ret := _m.Called(ctx, jti)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeActionToken")
+	}
+
+	var r0 models.ActionToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.ActionToken, error)); ok {
+		return rf(ctx, jti)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.ActionToken); ok {
+		r0 = rf(ctx, jti)
+	} else {
+		r0 = ret.Get(0).(models.ActionToken)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, jti)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ActionTokenStore_ConsumeActionToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeActionToken'
+type ActionTokenStore_ConsumeActionToken_Call struct {
+	*mock.Call
+}
+
+// ConsumeActionToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jti string
+func (_e *ActionTokenStore_Expecter) ConsumeActionToken(ctx interface{}, jti interface{}) *ActionTokenStore_ConsumeActionToken_Call {
+	return &ActionTokenStore_ConsumeActionToken_Call{Call: _e.mock.On("ConsumeActionToken", ctx, jti)}
+}
+
+func (_c *ActionTokenStore_ConsumeActionToken_Call) Run(run func(ctx context.Context, jti string)) *ActionTokenStore_ConsumeActionToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ActionTokenStore_ConsumeActionToken_Call) Return(_a0 models.ActionToken, _a1 error) *ActionTokenStore_ConsumeActionToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ActionTokenStore_ConsumeActionToken_Call) RunAndReturn(run func(context.Context, string) (models.ActionToken, error)) *ActionTokenStore_ConsumeActionToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveActionToken provides a mock function with given fields: ctx, t
+func (_m *ActionTokenStore) SaveActionToken(ctx context.Context, t models.ActionToken) error {
+	ret := _m.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveActionToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.ActionToken) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ActionTokenStore_SaveActionToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveActionToken'
+type ActionTokenStore_SaveActionToken_Call struct {
+	*mock.Call
+}
+
+// SaveActionToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - t models.ActionToken
+func (_e *ActionTokenStore_Expecter) SaveActionToken(ctx interface{}, t interface{}) *ActionTokenStore_SaveActionToken_Call {
+	return &ActionTokenStore_SaveActionToken_Call{Call: _e.mock.On("SaveActionToken", ctx, t)}
+}
+
+func (_c *ActionTokenStore_SaveActionToken_Call) Run(run func(ctx context.Context, t models.ActionToken)) *ActionTokenStore_SaveActionToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.ActionToken))
+	})
+	return _c
+}
+
+func (_c *ActionTokenStore_SaveActionToken_Call) Return(_a0 error) *ActionTokenStore_SaveActionToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ActionTokenStore_SaveActionToken_Call) RunAndReturn(run func(context.Context, models.ActionToken) error) *ActionTokenStore_SaveActionToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewActionTokenStore creates a new instance of ActionTokenStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewActionTokenStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ActionTokenStore {
+	mock := &ActionTokenStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,222 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// AppMetadataStore is an autogenerated mock type for the AppMetadataStore type
+type AppMetadataStore struct {
+	mock.Mock
+}
+
+type AppMetadataStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *AppMetadataStore) EXPECT() *AppMetadataStore_Expecter {
+	return &AppMetadataStore_Expecter{mock: &_m.Mock}
+}
+
+// AllAppMetadata provides a mock function with given fields: ctx, userID
+func (_m *AppMetadataStore) AllAppMetadata(ctx context.Context, userID int64) ([]byte, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AllAppMetadata")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]byte, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []byte); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AppMetadataStore_AllAppMetadata_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AllAppMetadata'
+type AppMetadataStore_AllAppMetadata_Call struct {
+	*mock.Call
+}
+
+// AllAppMetadata is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *AppMetadataStore_Expecter) AllAppMetadata(ctx interface{}, userID interface{}) *AppMetadataStore_AllAppMetadata_Call {
+	return &AppMetadataStore_AllAppMetadata_Call{Call: _e.mock.On("AllAppMetadata", ctx, userID)}
+}
+
+func (_c *AppMetadataStore_AllAppMetadata_Call) Run(run func(ctx context.Context, userID int64)) *AppMetadataStore_AllAppMetadata_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *AppMetadataStore_AllAppMetadata_Call) Return(_a0 []byte, _a1 error) *AppMetadataStore_AllAppMetadata_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AppMetadataStore_AllAppMetadata_Call) RunAndReturn(run func(context.Context, int64) ([]byte, error)) *AppMetadataStore_AllAppMetadata_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AppMetadata provides a mock function with given fields: ctx, userID, appID
+func (_m *AppMetadataStore) AppMetadata(ctx context.Context, userID int64, appID int) ([]byte, int64, error) {
+	ret := _m.Called(ctx, userID, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppMetadata")
+	}
+
+	var r0 []byte
+	var r1 int64
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) ([]byte, int64, error)); ok {
+		return rf(ctx, userID, appID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) []byte); ok {
+		r0 = rf(ctx, userID, appID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) int64); ok {
+		r1 = rf(ctx, userID, appID)
+	} else {
+		r1 = ret.Get(1).(int64)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, int64, int) error); ok {
+		r2 = rf(ctx, userID, appID)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// AppMetadataStore_AppMetadata_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AppMetadata'
+type AppMetadataStore_AppMetadata_Call struct {
+	*mock.Call
+}
+
+// AppMetadata is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - appID int
+func (_e *AppMetadataStore_Expecter) AppMetadata(ctx interface{}, userID interface{}, appID interface{}) *AppMetadataStore_AppMetadata_Call {
+	return &AppMetadataStore_AppMetadata_Call{Call: _e.mock.On("AppMetadata", ctx, userID, appID)}
+}
+
+func (_c *AppMetadataStore_AppMetadata_Call) Run(run func(ctx context.Context, userID int64, appID int)) *AppMetadataStore_AppMetadata_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *AppMetadataStore_AppMetadata_Call) Return(_a0 []byte, _a1 int64, _a2 error) *AppMetadataStore_AppMetadata_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *AppMetadataStore_AppMetadata_Call) RunAndReturn(run func(context.Context, int64, int) ([]byte, int64, error)) *AppMetadataStore_AppMetadata_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAppMetadata provides a mock function with given fields: ctx, userID, appID, metadata, expectedVersion
+func (_m *AppMetadataStore) SetAppMetadata(ctx context.Context, userID int64, appID int, metadata []byte, expectedVersion int64) (int64, error) {
+	ret := _m.Called(ctx, userID, appID, metadata, expectedVersion)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetAppMetadata")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, []byte, int64) (int64, error)); ok {
+		return rf(ctx, userID, appID, metadata, expectedVersion)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, []byte, int64) int64); ok {
+		r0 = rf(ctx, userID, appID, metadata, expectedVersion)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int, []byte, int64) error); ok {
+		r1 = rf(ctx, userID, appID, metadata, expectedVersion)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// AppMetadataStore_SetAppMetadata_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAppMetadata'
+type AppMetadataStore_SetAppMetadata_Call struct {
+	*mock.Call
+}
+
+// SetAppMetadata is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - appID int
+//   - metadata []byte
+//   - expectedVersion int64
+func (_e *AppMetadataStore_Expecter) SetAppMetadata(ctx interface{}, userID interface{}, appID interface{}, metadata interface{}, expectedVersion interface{}) *AppMetadataStore_SetAppMetadata_Call {
+	return &AppMetadataStore_SetAppMetadata_Call{Call: _e.mock.On("SetAppMetadata", ctx, userID, appID, metadata, expectedVersion)}
+}
+
+func (_c *AppMetadataStore_SetAppMetadata_Call) Run(run func(ctx context.Context, userID int64, appID int, metadata []byte, expectedVersion int64)) *AppMetadataStore_SetAppMetadata_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int), args[3].([]byte), args[4].(int64))
+	})
+	return _c
+}
+
+func (_c *AppMetadataStore_SetAppMetadata_Call) Return(_a0 int64, _a1 error) *AppMetadataStore_SetAppMetadata_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *AppMetadataStore_SetAppMetadata_Call) RunAndReturn(run func(context.Context, int64, int, []byte, int64) (int64, error)) *AppMetadataStore_SetAppMetadata_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewAppMetadataStore creates a new instance of AppMetadataStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAppMetadataStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AppMetadataStore {
+	mock := &AppMetadataStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,93 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// BreachChecker is an autogenerated mock type for the BreachChecker type
+type BreachChecker struct {
+	mock.Mock
+}
+
+type BreachChecker_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *BreachChecker) EXPECT() *BreachChecker_Expecter {
+	return &BreachChecker_Expecter{mock: &_m.Mock}
+}
+
+// IsCompromised provides a mock function with given fields: ctx, password
+func (_m *BreachChecker) IsCompromised(ctx context.Context, password string) (bool, error) {
+	ret := _m.Called(ctx, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsCompromised")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, password)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, password)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BreachChecker_IsCompromised_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsCompromised'
+type BreachChecker_IsCompromised_Call struct {
+	*mock.Call
+}
+
+// IsCompromised is a helper method to define mock.On call
+//   - ctx context.Context
+//   - password string
+func (_e *BreachChecker_Expecter) IsCompromised(ctx interface{}, password interface{}) *BreachChecker_IsCompromised_Call {
+	return &BreachChecker_IsCompromised_Call{Call: _e.mock.On("IsCompromised", ctx, password)}
+}
+
+func (_c *BreachChecker_IsCompromised_Call) Run(run func(ctx context.Context, password string)) *BreachChecker_IsCompromised_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *BreachChecker_IsCompromised_Call) Return(_a0 bool, _a1 error) *BreachChecker_IsCompromised_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *BreachChecker_IsCompromised_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *BreachChecker_IsCompromised_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewBreachChecker creates a new instance of BreachChecker. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewBreachChecker(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *BreachChecker {
+	mock := &BreachChecker{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,261 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ConsentStore is an autogenerated mock type for the ConsentStore type
+type ConsentStore struct {
+	mock.Mock
+}
+
+type ConsentStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ConsentStore) EXPECT() *ConsentStore_Expecter {
+	return &ConsentStore_Expecter{mock: &_m.Mock}
+}
+
+// ActiveConsent provides a mock function with given fields: ctx, userID, appID
+func (_m *ConsentStore) ActiveConsent(ctx context.Context, userID int64, appID int) (models.Consent, error) {
+	ret := _m.Called(ctx, userID, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ActiveConsent")
+	}
+
+	var r0 models.Consent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) (models.Consent, error)); ok {
+		return rf(ctx, userID, appID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) models.Consent); ok {
+		r0 = rf(ctx, userID, appID)
+	} else {
+		r0 = ret.Get(0).(models.Consent)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, userID, appID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConsentStore_ActiveConsent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ActiveConsent'
+type ConsentStore_ActiveConsent_Call struct {
+	*mock.Call
+}
+
+// ActiveConsent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - appID int
+func (_e *ConsentStore_Expecter) ActiveConsent(ctx interface{}, userID interface{}, appID interface{}) *ConsentStore_ActiveConsent_Call {
+	return &ConsentStore_ActiveConsent_Call{Call: _e.mock.On("ActiveConsent", ctx, userID, appID)}
+}
+
+func (_c *ConsentStore_ActiveConsent_Call) Run(run func(ctx context.Context, userID int64, appID int)) *ConsentStore_ActiveConsent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *ConsentStore_ActiveConsent_Call) Return(_a0 models.Consent, _a1 error) *ConsentStore_ActiveConsent_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ConsentStore_ActiveConsent_Call) RunAndReturn(run func(context.Context, int64, int) (models.Consent, error)) *ConsentStore_ActiveConsent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GrantConsent provides a mock function with given fields: ctx, userID, appID, scopes
+func (_m *ConsentStore) GrantConsent(ctx context.Context, userID int64, appID int, scopes []string) (models.Consent, error) {
+	ret := _m.Called(ctx, userID, appID, scopes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GrantConsent")
+	}
+
+	var r0 models.Consent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, []string) (models.Consent, error)); ok {
+		return rf(ctx, userID, appID, scopes)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, []string) models.Consent); ok {
+		r0 = rf(ctx, userID, appID, scopes)
+	} else {
+		r0 = ret.Get(0).(models.Consent)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int, []string) error); ok {
+		r1 = rf(ctx, userID, appID, scopes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConsentStore_GrantConsent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GrantConsent'
+type ConsentStore_GrantConsent_Call struct {
+	*mock.Call
+}
+
+// GrantConsent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - appID int
+//   - scopes []string
+func (_e *ConsentStore_Expecter) GrantConsent(ctx interface{}, userID interface{}, appID interface{}, scopes interface{}) *ConsentStore_GrantConsent_Call {
+	return &ConsentStore_GrantConsent_Call{Call: _e.mock.On("GrantConsent", ctx, userID, appID, scopes)}
+}
+
+func (_c *ConsentStore_GrantConsent_Call) Run(run func(ctx context.Context, userID int64, appID int, scopes []string)) *ConsentStore_GrantConsent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int), args[3].([]string))
+	})
+	return _c
+}
+
+func (_c *ConsentStore_GrantConsent_Call) Return(_a0 models.Consent, _a1 error) *ConsentStore_GrantConsent_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ConsentStore_GrantConsent_Call) RunAndReturn(run func(context.Context, int64, int, []string) (models.Consent, error)) *ConsentStore_GrantConsent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListConsents provides a mock function with given fields: ctx, userID
+func (_m *ConsentStore) ListConsents(ctx context.Context, userID int64) ([]models.Consent, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListConsents")
+	}
+
+	var r0 []models.Consent
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]models.Consent, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []models.Consent); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Consent)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ConsentStore_ListConsents_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListConsents'
+type ConsentStore_ListConsents_Call struct {
+	*mock.Call
+}
+
+// ListConsents is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *ConsentStore_Expecter) ListConsents(ctx interface{}, userID interface{}) *ConsentStore_ListConsents_Call {
+	return &ConsentStore_ListConsents_Call{Call: _e.mock.On("ListConsents", ctx, userID)}
+}
+
+func (_c *ConsentStore_ListConsents_Call) Run(run func(ctx context.Context, userID int64)) *ConsentStore_ListConsents_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *ConsentStore_ListConsents_Call) Return(_a0 []models.Consent, _a1 error) *ConsentStore_ListConsents_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ConsentStore_ListConsents_Call) RunAndReturn(run func(context.Context, int64) ([]models.Consent, error)) *ConsentStore_ListConsents_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeConsent provides a mock function with given fields: ctx, userID, appID
+func (_m *ConsentStore) RevokeConsent(ctx context.Context, userID int64, appID int) error {
+	ret := _m.Called(ctx, userID, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeConsent")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) error); ok {
+		r0 = rf(ctx, userID, appID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ConsentStore_RevokeConsent_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeConsent'
+type ConsentStore_RevokeConsent_Call struct {
+	*mock.Call
+}
+
+// RevokeConsent is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - appID int
+func (_e *ConsentStore_Expecter) RevokeConsent(ctx interface{}, userID interface{}, appID interface{}) *ConsentStore_RevokeConsent_Call {
+	return &ConsentStore_RevokeConsent_Call{Call: _e.mock.On("RevokeConsent", ctx, userID, appID)}
+}
+
+func (_c *ConsentStore_RevokeConsent_Call) Run(run func(ctx context.Context, userID int64, appID int)) *ConsentStore_RevokeConsent_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *ConsentStore_RevokeConsent_Call) Return(_a0 error) *ConsentStore_RevokeConsent_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ConsentStore_RevokeConsent_Call) RunAndReturn(run func(context.Context, int64, int) error) *ConsentStore_RevokeConsent_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewConsentStore creates a new instance of ConsentStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewConsentStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ConsentStore {
+	mock := &ConsentStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,355 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// DeviceStore is an autogenerated mock type for the DeviceStore type
+type DeviceStore struct {
+	mock.Mock
+}
+
+type DeviceStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *DeviceStore) EXPECT() *DeviceStore_Expecter {
+	return &DeviceStore_Expecter{mock: &_m.Mock}
+}
+
+// ConsumeDeviceAuthorization provides a mock function with given fields: ctx, id
+func (_m *DeviceStore) ConsumeDeviceAuthorization(ctx context.Context, id int64) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeDeviceAuthorization")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceStore_ConsumeDeviceAuthorization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeDeviceAuthorization'
+type DeviceStore_ConsumeDeviceAuthorization_Call struct {
+	*mock.Call
+}
+
+// ConsumeDeviceAuthorization is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+func (_e *DeviceStore_Expecter) ConsumeDeviceAuthorization(ctx interface{}, id interface{}) *DeviceStore_ConsumeDeviceAuthorization_Call {
+	return &DeviceStore_ConsumeDeviceAuthorization_Call{Call: _e.mock.On("ConsumeDeviceAuthorization", ctx, id)}
+}
+
+func (_c *DeviceStore_ConsumeDeviceAuthorization_Call) Run(run func(ctx context.Context, id int64)) *DeviceStore_ConsumeDeviceAuthorization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *DeviceStore_ConsumeDeviceAuthorization_Call) Return(_a0 error) *DeviceStore_ConsumeDeviceAuthorization_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DeviceStore_ConsumeDeviceAuthorization_Call) RunAndReturn(run func(context.Context, int64) error) *DeviceStore_ConsumeDeviceAuthorization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DecideDeviceAuthorization provides a mock function with given fields: ctx, id, userID, approve
+func (_m *DeviceStore) DecideDeviceAuthorization(ctx context.Context, id int64, userID int64, approve bool) error {
+	ret := _m.Called(ctx, id, userID, approve)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DecideDeviceAuthorization")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64, bool) error); ok {
+		r0 = rf(ctx, id, userID, approve)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceStore_DecideDeviceAuthorization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DecideDeviceAuthorization'
+type DeviceStore_DecideDeviceAuthorization_Call struct {
+	*mock.Call
+}
+
+// DecideDeviceAuthorization is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - userID int64
+//   - approve bool
+func (_e *DeviceStore_Expecter) DecideDeviceAuthorization(ctx interface{}, id interface{}, userID interface{}, approve interface{}) *DeviceStore_DecideDeviceAuthorization_Call {
+	return &DeviceStore_DecideDeviceAuthorization_Call{Call: _e.mock.On("DecideDeviceAuthorization", ctx, id, userID, approve)}
+}
+
+func (_c *DeviceStore_DecideDeviceAuthorization_Call) Run(run func(ctx context.Context, id int64, userID int64, approve bool)) *DeviceStore_DecideDeviceAuthorization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *DeviceStore_DecideDeviceAuthorization_Call) Return(_a0 error) *DeviceStore_DecideDeviceAuthorization_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DeviceStore_DecideDeviceAuthorization_Call) RunAndReturn(run func(context.Context, int64, int64, bool) error) *DeviceStore_DecideDeviceAuthorization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeviceAuthorizationByCodeHash provides a mock function with given fields: ctx, codeHash
+func (_m *DeviceStore) DeviceAuthorizationByCodeHash(ctx context.Context, codeHash string) (models.DeviceAuthorization, error) {
+	ret := _m.Called(ctx, codeHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeviceAuthorizationByCodeHash")
+	}
+
+	var r0 models.DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.DeviceAuthorization, error)); ok {
+		return rf(ctx, codeHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.DeviceAuthorization); ok {
+		r0 = rf(ctx, codeHash)
+	} else {
+		r0 = ret.Get(0).(models.DeviceAuthorization)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, codeHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeviceStore_DeviceAuthorizationByCodeHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeviceAuthorizationByCodeHash'
+type DeviceStore_DeviceAuthorizationByCodeHash_Call struct {
+	*mock.Call
+}
+
+// DeviceAuthorizationByCodeHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - codeHash string
+func (_e *DeviceStore_Expecter) DeviceAuthorizationByCodeHash(ctx interface{}, codeHash interface{}) *DeviceStore_DeviceAuthorizationByCodeHash_Call {
+	return &DeviceStore_DeviceAuthorizationByCodeHash_Call{Call: _e.mock.On("DeviceAuthorizationByCodeHash", ctx, codeHash)}
+}
+
+func (_c *DeviceStore_DeviceAuthorizationByCodeHash_Call) Run(run func(ctx context.Context, codeHash string)) *DeviceStore_DeviceAuthorizationByCodeHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *DeviceStore_DeviceAuthorizationByCodeHash_Call) Return(_a0 models.DeviceAuthorization, _a1 error) *DeviceStore_DeviceAuthorizationByCodeHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DeviceStore_DeviceAuthorizationByCodeHash_Call) RunAndReturn(run func(context.Context, string) (models.DeviceAuthorization, error)) *DeviceStore_DeviceAuthorizationByCodeHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeviceAuthorizationByUserCode provides a mock function with given fields: ctx, userCode
+func (_m *DeviceStore) DeviceAuthorizationByUserCode(ctx context.Context, userCode string) (models.DeviceAuthorization, error) {
+	ret := _m.Called(ctx, userCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeviceAuthorizationByUserCode")
+	}
+
+	var r0 models.DeviceAuthorization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.DeviceAuthorization, error)); ok {
+		return rf(ctx, userCode)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.DeviceAuthorization); ok {
+		r0 = rf(ctx, userCode)
+	} else {
+		r0 = ret.Get(0).(models.DeviceAuthorization)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, userCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeviceStore_DeviceAuthorizationByUserCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeviceAuthorizationByUserCode'
+type DeviceStore_DeviceAuthorizationByUserCode_Call struct {
+	*mock.Call
+}
+
+// DeviceAuthorizationByUserCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userCode string
+func (_e *DeviceStore_Expecter) DeviceAuthorizationByUserCode(ctx interface{}, userCode interface{}) *DeviceStore_DeviceAuthorizationByUserCode_Call {
+	return &DeviceStore_DeviceAuthorizationByUserCode_Call{Call: _e.mock.On("DeviceAuthorizationByUserCode", ctx, userCode)}
+}
+
+func (_c *DeviceStore_DeviceAuthorizationByUserCode_Call) Run(run func(ctx context.Context, userCode string)) *DeviceStore_DeviceAuthorizationByUserCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *DeviceStore_DeviceAuthorizationByUserCode_Call) Return(_a0 models.DeviceAuthorization, _a1 error) *DeviceStore_DeviceAuthorizationByUserCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DeviceStore_DeviceAuthorizationByUserCode_Call) RunAndReturn(run func(context.Context, string) (models.DeviceAuthorization, error)) *DeviceStore_DeviceAuthorizationByUserCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecordDevicePoll provides a mock function with given fields: ctx, id, polledAt, interval
+func (_m *DeviceStore) RecordDevicePoll(ctx context.Context, id int64, polledAt time.Time, interval time.Duration) error {
+	ret := _m.Called(ctx, id, polledAt, interval)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordDevicePoll")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time, time.Duration) error); ok {
+		r0 = rf(ctx, id, polledAt, interval)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeviceStore_RecordDevicePoll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecordDevicePoll'
+type DeviceStore_RecordDevicePoll_Call struct {
+	*mock.Call
+}
+
+// RecordDevicePoll is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - polledAt time.Time
+//   - interval time.Duration
+func (_e *DeviceStore_Expecter) RecordDevicePoll(ctx interface{}, id interface{}, polledAt interface{}, interval interface{}) *DeviceStore_RecordDevicePoll_Call {
+	return &DeviceStore_RecordDevicePoll_Call{Call: _e.mock.On("RecordDevicePoll", ctx, id, polledAt, interval)}
+}
+
+func (_c *DeviceStore_RecordDevicePoll_Call) Run(run func(ctx context.Context, id int64, polledAt time.Time, interval time.Duration)) *DeviceStore_RecordDevicePoll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(time.Time), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *DeviceStore_RecordDevicePoll_Call) Return(_a0 error) *DeviceStore_RecordDevicePoll_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DeviceStore_RecordDevicePoll_Call) RunAndReturn(run func(context.Context, int64, time.Time, time.Duration) error) *DeviceStore_RecordDevicePoll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveDeviceAuthorization provides a mock function with given fields: ctx, d
+func (_m *DeviceStore) SaveDeviceAuthorization(ctx context.Context, d models.DeviceAuthorization) (int64, error) {
+	ret := _m.Called(ctx, d)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveDeviceAuthorization")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.DeviceAuthorization) (int64, error)); ok {
+		return rf(ctx, d)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.DeviceAuthorization) int64); ok {
+		r0 = rf(ctx, d)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.DeviceAuthorization) error); ok {
+		r1 = rf(ctx, d)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeviceStore_SaveDeviceAuthorization_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveDeviceAuthorization'
+type DeviceStore_SaveDeviceAuthorization_Call struct {
+	*mock.Call
+}
+
+// SaveDeviceAuthorization is a helper method to define mock.On call
+//   - ctx context.Context
+//   - d models.DeviceAuthorization
+func (_e *DeviceStore_Expecter) SaveDeviceAuthorization(ctx interface{}, d interface{}) *DeviceStore_SaveDeviceAuthorization_Call {
+	return &DeviceStore_SaveDeviceAuthorization_Call{Call: _e.mock.On("SaveDeviceAuthorization", ctx, d)}
+}
+
+func (_c *DeviceStore_SaveDeviceAuthorization_Call) Run(run func(ctx context.Context, d models.DeviceAuthorization)) *DeviceStore_SaveDeviceAuthorization_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.DeviceAuthorization))
+	})
+	return _c
+}
+
+func (_c *DeviceStore_SaveDeviceAuthorization_Call) Return(_a0 int64, _a1 error) *DeviceStore_SaveDeviceAuthorization_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DeviceStore_SaveDeviceAuthorization_Call) RunAndReturn(run func(context.Context, models.DeviceAuthorization) (int64, error)) *DeviceStore_SaveDeviceAuthorization_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewDeviceStore creates a new instance of DeviceStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewDeviceStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *DeviceStore {
+	mock := &DeviceStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,189 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// GuestStore is an autogenerated mock type for the GuestStore type
+type GuestStore struct {
+	mock.Mock
+}
+
+type GuestStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *GuestStore) EXPECT() *GuestStore_Expecter {
+	return &GuestStore_Expecter{mock: &_m.Mock}
+}
+
+// SaveGuest provides a mock function with given fields: ctx, placeholder
+func (_m *GuestStore) SaveGuest(ctx context.Context, placeholder string) (int64, error) {
+	ret := _m.Called(ctx, placeholder)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveGuest")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (int64, error)); ok {
+		return rf(ctx, placeholder)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) int64); ok {
+		r0 = rf(ctx, placeholder)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, placeholder)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GuestStore_SaveGuest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveGuest'
+type GuestStore_SaveGuest_Call struct {
+	*mock.Call
+}
+
+// SaveGuest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - placeholder string
+func (_e *GuestStore_Expecter) SaveGuest(ctx interface{}, placeholder interface{}) *GuestStore_SaveGuest_Call {
+	return &GuestStore_SaveGuest_Call{Call: _e.mock.On("SaveGuest", ctx, placeholder)}
+}
+
+func (_c *GuestStore_SaveGuest_Call) Run(run func(ctx context.Context, placeholder string)) *GuestStore_SaveGuest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *GuestStore_SaveGuest_Call) Return(_a0 int64, _a1 error) *GuestStore_SaveGuest_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *GuestStore_SaveGuest_Call) RunAndReturn(run func(context.Context, string) (int64, error)) *GuestStore_SaveGuest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchGuest provides a mock function with given fields: ctx, userID
+func (_m *GuestStore) TouchGuest(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchGuest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GuestStore_TouchGuest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchGuest'
+type GuestStore_TouchGuest_Call struct {
+	*mock.Call
+}
+
+// TouchGuest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *GuestStore_Expecter) TouchGuest(ctx interface{}, userID interface{}) *GuestStore_TouchGuest_Call {
+	return &GuestStore_TouchGuest_Call{Call: _e.mock.On("TouchGuest", ctx, userID)}
+}
+
+func (_c *GuestStore_TouchGuest_Call) Run(run func(ctx context.Context, userID int64)) *GuestStore_TouchGuest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *GuestStore_TouchGuest_Call) Return(_a0 error) *GuestStore_TouchGuest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *GuestStore_TouchGuest_Call) RunAndReturn(run func(context.Context, int64) error) *GuestStore_TouchGuest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpgradeGuest provides a mock function with given fields: ctx, userID, email, passHash
+func (_m *GuestStore) UpgradeGuest(ctx context.Context, userID int64, email string, passHash []byte) error {
+	ret := _m.Called(ctx, userID, email, passHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpgradeGuest")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, []byte) error); ok {
+		r0 = rf(ctx, userID, email, passHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GuestStore_UpgradeGuest_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpgradeGuest'
+type GuestStore_UpgradeGuest_Call struct {
+	*mock.Call
+}
+
+// UpgradeGuest is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - email string
+//   - passHash []byte
+func (_e *GuestStore_Expecter) UpgradeGuest(ctx interface{}, userID interface{}, email interface{}, passHash interface{}) *GuestStore_UpgradeGuest_Call {
+	return &GuestStore_UpgradeGuest_Call{Call: _e.mock.On("UpgradeGuest", ctx, userID, email, passHash)}
+}
+
+func (_c *GuestStore_UpgradeGuest_Call) Run(run func(ctx context.Context, userID int64, email string, passHash []byte)) *GuestStore_UpgradeGuest_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].([]byte))
+	})
+	return _c
+}
+
+func (_c *GuestStore_UpgradeGuest_Call) Return(_a0 error) *GuestStore_UpgradeGuest_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *GuestStore_UpgradeGuest_Call) RunAndReturn(run func(context.Context, int64, string, []byte) error) *GuestStore_UpgradeGuest_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewGuestStore creates a new instance of GuestStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewGuestStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *GuestStore {
+	mock := &GuestStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
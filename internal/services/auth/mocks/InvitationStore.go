@@ -0,0 +1,334 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// InvitationStore is an autogenerated mock type for the InvitationStore type
+type InvitationStore struct {
+	mock.Mock
+}
+
+type InvitationStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *InvitationStore) EXPECT() *InvitationStore_Expecter {
+	return &InvitationStore_Expecter{mock: &_m.Mock}
+}
+
+// AcceptInvitation provides a mock function with given fields: ctx, invitationID, passHash
+func (_m *InvitationStore) AcceptInvitation(ctx context.Context, invitationID int64, passHash []byte) (int64, error) {
+	ret := _m.Called(ctx, invitationID, passHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptInvitation")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []byte) (int64, error)); ok {
+		return rf(ctx, invitationID, passHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []byte) int64); ok {
+		r0 = rf(ctx, invitationID, passHash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, []byte) error); ok {
+		r1 = rf(ctx, invitationID, passHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvitationStore_AcceptInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcceptInvitation'
+type InvitationStore_AcceptInvitation_Call struct {
+	*mock.Call
+}
+
+// AcceptInvitation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - invitationID int64
+//   - passHash []byte
+func (_e *InvitationStore_Expecter) AcceptInvitation(ctx interface{}, invitationID interface{}, passHash interface{}) *InvitationStore_AcceptInvitation_Call {
+	return &InvitationStore_AcceptInvitation_Call{Call: _e.mock.On("AcceptInvitation", ctx, invitationID, passHash)}
+}
+
+func (_c *InvitationStore_AcceptInvitation_Call) Run(run func(ctx context.Context, invitationID int64, passHash []byte)) *InvitationStore_AcceptInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].([]byte))
+	})
+	return _c
+}
+
+func (_c *InvitationStore_AcceptInvitation_Call) Return(_a0 int64, _a1 error) *InvitationStore_AcceptInvitation_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *InvitationStore_AcceptInvitation_Call) RunAndReturn(run func(context.Context, int64, []byte) (int64, error)) *InvitationStore_AcceptInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// InvitationByTokenHash provides a mock function with given fields: ctx, tokenHash
+func (_m *InvitationStore) InvitationByTokenHash(ctx context.Context, tokenHash string) (models.Invitation, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for InvitationByTokenHash")
+	}
+
+	var r0 models.Invitation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Invitation, error)); ok {
+		return rf(ctx, tokenHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Invitation); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(models.Invitation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvitationStore_InvitationByTokenHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'InvitationByTokenHash'
+type InvitationStore_InvitationByTokenHash_Call struct {
+	*mock.Call
+}
+
+// InvitationByTokenHash is a helper method to define mock.On call
+//   - ctx context.Context
+//   - tokenHash string
+func (_e *InvitationStore_Expecter) InvitationByTokenHash(ctx interface{}, tokenHash interface{}) *InvitationStore_InvitationByTokenHash_Call {
+	return &InvitationStore_InvitationByTokenHash_Call{Call: _e.mock.On("InvitationByTokenHash", ctx, tokenHash)}
+}
+
+func (_c *InvitationStore_InvitationByTokenHash_Call) Run(run func(ctx context.Context, tokenHash string)) *InvitationStore_InvitationByTokenHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *InvitationStore_InvitationByTokenHash_Call) Return(_a0 models.Invitation, _a1 error) *InvitationStore_InvitationByTokenHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *InvitationStore_InvitationByTokenHash_Call) RunAndReturn(run func(context.Context, string) (models.Invitation, error)) *InvitationStore_InvitationByTokenHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListInvitations provides a mock function with given fields: ctx, afterID, limit, pendingOnly
+func (_m *InvitationStore) ListInvitations(ctx context.Context, afterID int64, limit int, pendingOnly bool) ([]models.Invitation, error) {
+	ret := _m.Called(ctx, afterID, limit, pendingOnly)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListInvitations")
+	}
+
+	var r0 []models.Invitation
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, bool) ([]models.Invitation, error)); ok {
+		return rf(ctx, afterID, limit, pendingOnly)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, bool) []models.Invitation); ok {
+		r0 = rf(ctx, afterID, limit, pendingOnly)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Invitation)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int, bool) error); ok {
+		r1 = rf(ctx, afterID, limit, pendingOnly)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvitationStore_ListInvitations_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListInvitations'
+type InvitationStore_ListInvitations_Call struct {
+	*mock.Call
+}
+
+// ListInvitations is a helper method to define mock.On call
+//   - ctx context.Context
+//   - afterID int64
+//   - limit int
+//   - pendingOnly bool
+func (_e *InvitationStore_Expecter) ListInvitations(ctx interface{}, afterID interface{}, limit interface{}, pendingOnly interface{}) *InvitationStore_ListInvitations_Call {
+	return &InvitationStore_ListInvitations_Call{Call: _e.mock.On("ListInvitations", ctx, afterID, limit, pendingOnly)}
+}
+
+func (_c *InvitationStore_ListInvitations_Call) Run(run func(ctx context.Context, afterID int64, limit int, pendingOnly bool)) *InvitationStore_ListInvitations_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *InvitationStore_ListInvitations_Call) Return(_a0 []models.Invitation, _a1 error) *InvitationStore_ListInvitations_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *InvitationStore_ListInvitations_Call) RunAndReturn(run func(context.Context, int64, int, bool) ([]models.Invitation, error)) *InvitationStore_ListInvitations_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeInvitation provides a mock function with given fields: ctx, invitationID
+func (_m *InvitationStore) RevokeInvitation(ctx context.Context, invitationID int64) (string, error) {
+	ret := _m.Called(ctx, invitationID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeInvitation")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (string, error)); ok {
+		return rf(ctx, invitationID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) string); ok {
+		r0 = rf(ctx, invitationID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, invitationID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvitationStore_RevokeInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeInvitation'
+type InvitationStore_RevokeInvitation_Call struct {
+	*mock.Call
+}
+
+// RevokeInvitation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - invitationID int64
+func (_e *InvitationStore_Expecter) RevokeInvitation(ctx interface{}, invitationID interface{}) *InvitationStore_RevokeInvitation_Call {
+	return &InvitationStore_RevokeInvitation_Call{Call: _e.mock.On("RevokeInvitation", ctx, invitationID)}
+}
+
+func (_c *InvitationStore_RevokeInvitation_Call) Run(run func(ctx context.Context, invitationID int64)) *InvitationStore_RevokeInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *InvitationStore_RevokeInvitation_Call) Return(_a0 string, _a1 error) *InvitationStore_RevokeInvitation_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *InvitationStore_RevokeInvitation_Call) RunAndReturn(run func(context.Context, int64) (string, error)) *InvitationStore_RevokeInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveInvitation provides a mock function with given fields: ctx, inv
+func (_m *InvitationStore) SaveInvitation(ctx context.Context, inv models.Invitation) (models.Invitation, bool, error) {
+	ret := _m.Called(ctx, inv)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveInvitation")
+	}
+
+	var r0 models.Invitation
+	var r1 bool
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Invitation) (models.Invitation, bool, error)); ok {
+		return rf(ctx, inv)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Invitation) models.Invitation); ok {
+		r0 = rf(ctx, inv)
+	} else {
+		r0 = ret.Get(0).(models.Invitation)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Invitation) bool); ok {
+		r1 = rf(ctx, inv)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, models.Invitation) error); ok {
+		r2 = rf(ctx, inv)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// InvitationStore_SaveInvitation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveInvitation'
+type InvitationStore_SaveInvitation_Call struct {
+	*mock.Call
+}
+
+// SaveInvitation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - inv models.Invitation
+func (_e *InvitationStore_Expecter) SaveInvitation(ctx interface{}, inv interface{}) *InvitationStore_SaveInvitation_Call {
+	return &InvitationStore_SaveInvitation_Call{Call: _e.mock.On("SaveInvitation", ctx, inv)}
+}
+
+func (_c *InvitationStore_SaveInvitation_Call) Run(run func(ctx context.Context, inv models.Invitation)) *InvitationStore_SaveInvitation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Invitation))
+	})
+	return _c
+}
+
+func (_c *InvitationStore_SaveInvitation_Call) Return(saved models.Invitation, renewed bool, err error) *InvitationStore_SaveInvitation_Call {
+	_c.Call.Return(saved, renewed, err)
+	return _c
+}
+
+func (_c *InvitationStore_SaveInvitation_Call) RunAndReturn(run func(context.Context, models.Invitation) (models.Invitation, bool, error)) *InvitationStore_SaveInvitation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewInvitationStore creates a new instance of InvitationStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewInvitationStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *InvitationStore {
+	mock := &InvitationStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,194 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// LoginHistoryStore is an autogenerated mock type for the LoginHistoryStore type
+type LoginHistoryStore struct {
+	mock.Mock
+}
+
+type LoginHistoryStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *LoginHistoryStore) EXPECT() *LoginHistoryStore_Expecter {
+	return &LoginHistoryStore_Expecter{mock: &_m.Mock}
+}
+
+// AddLogin provides a mock function with given fields: ctx, r
+func (_m *LoginHistoryStore) AddLogin(ctx context.Context, r models.LoginRecord) error {
+	ret := _m.Called(ctx, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddLogin")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.LoginRecord) error); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LoginHistoryStore_AddLogin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddLogin'
+type LoginHistoryStore_AddLogin_Call struct {
+	*mock.Call
+}
+
+// AddLogin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - r models.LoginRecord
+func (_e *LoginHistoryStore_Expecter) AddLogin(ctx interface{}, r interface{}) *LoginHistoryStore_AddLogin_Call {
+	return &LoginHistoryStore_AddLogin_Call{Call: _e.mock.On("AddLogin", ctx, r)}
+}
+
+func (_c *LoginHistoryStore_AddLogin_Call) Run(run func(ctx context.Context, r models.LoginRecord)) *LoginHistoryStore_AddLogin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.LoginRecord))
+	})
+	return _c
+}
+
+func (_c *LoginHistoryStore_AddLogin_Call) Return(_a0 error) *LoginHistoryStore_AddLogin_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *LoginHistoryStore_AddLogin_Call) RunAndReturn(run func(context.Context, models.LoginRecord) error) *LoginHistoryStore_AddLogin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddLoginFailure provides a mock function with given fields: ctx, appID, at
+func (_m *LoginHistoryStore) AddLoginFailure(ctx context.Context, appID int, at time.Time) error {
+	ret := _m.Called(ctx, appID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddLoginFailure")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Time) error); ok {
+		r0 = rf(ctx, appID, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// LoginHistoryStore_AddLoginFailure_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddLoginFailure'
+type LoginHistoryStore_AddLoginFailure_Call struct {
+	*mock.Call
+}
+
+// AddLoginFailure is a helper method to define mock.On call
+//   - ctx context.Context
+//   - appID int
+//   - at time.Time
+func (_e *LoginHistoryStore_Expecter) AddLoginFailure(ctx interface{}, appID interface{}, at interface{}) *LoginHistoryStore_AddLoginFailure_Call {
+	return &LoginHistoryStore_AddLoginFailure_Call{Call: _e.mock.On("AddLoginFailure", ctx, appID, at)}
+}
+
+func (_c *LoginHistoryStore_AddLoginFailure_Call) Run(run func(ctx context.Context, appID int, at time.Time)) *LoginHistoryStore_AddLoginFailure_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *LoginHistoryStore_AddLoginFailure_Call) Return(_a0 error) *LoginHistoryStore_AddLoginFailure_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *LoginHistoryStore_AddLoginFailure_Call) RunAndReturn(run func(context.Context, int, time.Time) error) *LoginHistoryStore_AddLoginFailure_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RecentLogins provides a mock function with given fields: ctx, userID, limit
+func (_m *LoginHistoryStore) RecentLogins(ctx context.Context, userID int64, limit int) ([]models.LoginRecord, error) {
+	ret := _m.Called(ctx, userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecentLogins")
+	}
+
+	var r0 []models.LoginRecord
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) ([]models.LoginRecord, error)); ok {
+		return rf(ctx, userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) []models.LoginRecord); ok {
+		r0 = rf(ctx, userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.LoginRecord)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// LoginHistoryStore_RecentLogins_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RecentLogins'
+type LoginHistoryStore_RecentLogins_Call struct {
+	*mock.Call
+}
+
+// RecentLogins is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - limit int
+func (_e *LoginHistoryStore_Expecter) RecentLogins(ctx interface{}, userID interface{}, limit interface{}) *LoginHistoryStore_RecentLogins_Call {
+	return &LoginHistoryStore_RecentLogins_Call{Call: _e.mock.On("RecentLogins", ctx, userID, limit)}
+}
+
+func (_c *LoginHistoryStore_RecentLogins_Call) Run(run func(ctx context.Context, userID int64, limit int)) *LoginHistoryStore_RecentLogins_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *LoginHistoryStore_RecentLogins_Call) Return(_a0 []models.LoginRecord, _a1 error) *LoginHistoryStore_RecentLogins_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *LoginHistoryStore_RecentLogins_Call) RunAndReturn(run func(context.Context, int64, int) ([]models.LoginRecord, error)) *LoginHistoryStore_RecentLogins_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewLoginHistoryStore creates a new instance of LoginHistoryStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewLoginHistoryStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *LoginHistoryStore {
+	mock := &LoginHistoryStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
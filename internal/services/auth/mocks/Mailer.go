@@ -0,0 +1,86 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	mail "sso/internal/lib/mail"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Mailer is an autogenerated mock type for the Mailer type
+type Mailer struct {
+	mock.Mock
+}
+
+type Mailer_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Mailer) EXPECT() *Mailer_Expecter {
+	return &Mailer_Expecter{mock: &_m.Mock}
+}
+
+// SendTemplate provides a mock function with given fields: ctx, to, template, data
+func (_m *Mailer) SendTemplate(ctx context.Context, to string, template string, data mail.Data) error {
+	ret := _m.Called(ctx, to, template, data)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SendTemplate")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, mail.Data) error); ok {
+		r0 = rf(ctx, to, template, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Mailer_SendTemplate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SendTemplate'
+type Mailer_SendTemplate_Call struct {
+	*mock.Call
+}
+
+// SendTemplate is a helper method to define mock.On call
+//   - ctx context.Context
+//   - to string
+//   - template string
+//   - data mail.Data
+func (_e *Mailer_Expecter) SendTemplate(ctx interface{}, to interface{}, template interface{}, data interface{}) *Mailer_SendTemplate_Call {
+	return &Mailer_SendTemplate_Call{Call: _e.mock.On("SendTemplate", ctx, to, template, data)}
+}
+
+func (_c *Mailer_SendTemplate_Call) Run(run func(ctx context.Context, to string, template string, data mail.Data)) *Mailer_SendTemplate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(mail.Data))
+	})
+	return _c
+}
+
+func (_c *Mailer_SendTemplate_Call) Return(_a0 error) *Mailer_SendTemplate_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *Mailer_SendTemplate_Call) RunAndReturn(run func(context.Context, string, string, mail.Data) error) *Mailer_SendTemplate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewMailer creates a new instance of Mailer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewMailer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Mailer {
+	mock := &Mailer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
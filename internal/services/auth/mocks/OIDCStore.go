@@ -0,0 +1,258 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OIDCStore is an autogenerated mock type for the OIDCStore type
+type OIDCStore struct {
+	mock.Mock
+}
+
+type OIDCStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *OIDCStore) EXPECT() *OIDCStore_Expecter {
+	return &OIDCStore_Expecter{mock: &_m.Mock}
+}
+
+// AppRedirectURIs provides a mock function with given fields: ctx, appID
+func (_m *OIDCStore) AppRedirectURIs(ctx context.Context, appID int) ([]string, error) {
+	ret := _m.Called(ctx, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppRedirectURIs")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]string, error)); ok {
+		return rf(ctx, appID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []string); ok {
+		r0 = rf(ctx, appID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, appID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OIDCStore_AppRedirectURIs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AppRedirectURIs'
+type OIDCStore_AppRedirectURIs_Call struct {
+	*mock.Call
+}
+
+// AppRedirectURIs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - appID int
+func (_e *OIDCStore_Expecter) AppRedirectURIs(ctx interface{}, appID interface{}) *OIDCStore_AppRedirectURIs_Call {
+	return &OIDCStore_AppRedirectURIs_Call{Call: _e.mock.On("AppRedirectURIs", ctx, appID)}
+}
+
+func (_c *OIDCStore_AppRedirectURIs_Call) Run(run func(ctx context.Context, appID int)) *OIDCStore_AppRedirectURIs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *OIDCStore_AppRedirectURIs_Call) Return(_a0 []string, _a1 error) *OIDCStore_AppRedirectURIs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OIDCStore_AppRedirectURIs_Call) RunAndReturn(run func(context.Context, int) ([]string, error)) *OIDCStore_AppRedirectURIs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AppsWithPublicKeys provides a mock function with given fields: ctx
+func (_m *OIDCStore) AppsWithPublicKeys(ctx context.Context) ([]models.App, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AppsWithPublicKeys")
+	}
+
+	var r0 []models.App
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]models.App, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []models.App); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.App)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OIDCStore_AppsWithPublicKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AppsWithPublicKeys'
+type OIDCStore_AppsWithPublicKeys_Call struct {
+	*mock.Call
+}
+
+// AppsWithPublicKeys is a helper method to define mock.On call
+//   - ctx context.Context
+func (_e *OIDCStore_Expecter) AppsWithPublicKeys(ctx interface{}) *OIDCStore_AppsWithPublicKeys_Call {
+	return &OIDCStore_AppsWithPublicKeys_Call{Call: _e.mock.On("AppsWithPublicKeys", ctx)}
+}
+
+func (_c *OIDCStore_AppsWithPublicKeys_Call) Run(run func(ctx context.Context)) *OIDCStore_AppsWithPublicKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context))
+	})
+	return _c
+}
+
+func (_c *OIDCStore_AppsWithPublicKeys_Call) Return(_a0 []models.App, _a1 error) *OIDCStore_AppsWithPublicKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OIDCStore_AppsWithPublicKeys_Call) RunAndReturn(run func(context.Context) ([]models.App, error)) *OIDCStore_AppsWithPublicKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveAuthCode provides a mock function with given fields: ctx, c
+func (_m *OIDCStore) SaveAuthCode(ctx context.Context, c models.AuthCode) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAuthCode")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.AuthCode) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// OIDCStore_SaveAuthCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAuthCode'
+type OIDCStore_SaveAuthCode_Call struct {
+	*mock.Call
+}
+
+// SaveAuthCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - c models.AuthCode
+func (_e *OIDCStore_Expecter) SaveAuthCode(ctx interface{}, c interface{}) *OIDCStore_SaveAuthCode_Call {
+	return &OIDCStore_SaveAuthCode_Call{Call: _e.mock.On("SaveAuthCode", ctx, c)}
+}
+
+func (_c *OIDCStore_SaveAuthCode_Call) Run(run func(ctx context.Context, c models.AuthCode)) *OIDCStore_SaveAuthCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.AuthCode))
+	})
+	return _c
+}
+
+func (_c *OIDCStore_SaveAuthCode_Call) Return(_a0 error) *OIDCStore_SaveAuthCode_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *OIDCStore_SaveAuthCode_Call) RunAndReturn(run func(context.Context, models.AuthCode) error) *OIDCStore_SaveAuthCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TakeAuthCode provides a mock function with given fields: ctx, id
+func (_m *OIDCStore) TakeAuthCode(ctx context.Context, id string) (models.AuthCode, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TakeAuthCode")
+	}
+
+	var r0 models.AuthCode
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.AuthCode, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.AuthCode); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.AuthCode)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OIDCStore_TakeAuthCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TakeAuthCode'
+type OIDCStore_TakeAuthCode_Call struct {
+	*mock.Call
+}
+
+// TakeAuthCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *OIDCStore_Expecter) TakeAuthCode(ctx interface{}, id interface{}) *OIDCStore_TakeAuthCode_Call {
+	return &OIDCStore_TakeAuthCode_Call{Call: _e.mock.On("TakeAuthCode", ctx, id)}
+}
+
+func (_c *OIDCStore_TakeAuthCode_Call) Run(run func(ctx context.Context, id string)) *OIDCStore_TakeAuthCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *OIDCStore_TakeAuthCode_Call) Return(_a0 models.AuthCode, _a1 error) *OIDCStore_TakeAuthCode_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OIDCStore_TakeAuthCode_Call) RunAndReturn(run func(context.Context, string) (models.AuthCode, error)) *OIDCStore_TakeAuthCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewOIDCStore creates a new instance of OIDCStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOIDCStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OIDCStore {
+	mock := &OIDCStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,252 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// OfflineTokenStore is an autogenerated mock type for the OfflineTokenStore type
+type OfflineTokenStore struct {
+	mock.Mock
+}
+
+type OfflineTokenStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *OfflineTokenStore) EXPECT() *OfflineTokenStore_Expecter {
+	return &OfflineTokenStore_Expecter{mock: &_m.Mock}
+}
+
+// OfflineToken provides a mock function with given fields: ctx, id
+func (_m *OfflineTokenStore) OfflineToken(ctx context.Context, id string) (models.OfflineToken, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OfflineToken")
+	}
+
+	var r0 models.OfflineToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.OfflineToken, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.OfflineToken); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.OfflineToken)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OfflineTokenStore_OfflineToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OfflineToken'
+type OfflineTokenStore_OfflineToken_Call struct {
+	*mock.Call
+}
+
+// OfflineToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *OfflineTokenStore_Expecter) OfflineToken(ctx interface{}, id interface{}) *OfflineTokenStore_OfflineToken_Call {
+	return &OfflineTokenStore_OfflineToken_Call{Call: _e.mock.On("OfflineToken", ctx, id)}
+}
+
+func (_c *OfflineTokenStore_OfflineToken_Call) Run(run func(ctx context.Context, id string)) *OfflineTokenStore_OfflineToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *OfflineTokenStore_OfflineToken_Call) Return(_a0 models.OfflineToken, _a1 error) *OfflineTokenStore_OfflineToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OfflineTokenStore_OfflineToken_Call) RunAndReturn(run func(context.Context, string) (models.OfflineToken, error)) *OfflineTokenStore_OfflineToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OfflineTokens provides a mock function with given fields: ctx, userID, now
+func (_m *OfflineTokenStore) OfflineTokens(ctx context.Context, userID int64, now time.Time) ([]models.OfflineToken, error) {
+	ret := _m.Called(ctx, userID, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OfflineTokens")
+	}
+
+	var r0 []models.OfflineToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) ([]models.OfflineToken, error)); ok {
+		return rf(ctx, userID, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) []models.OfflineToken); ok {
+		r0 = rf(ctx, userID, now)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.OfflineToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, time.Time) error); ok {
+		r1 = rf(ctx, userID, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OfflineTokenStore_OfflineTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OfflineTokens'
+type OfflineTokenStore_OfflineTokens_Call struct {
+	*mock.Call
+}
+
+// OfflineTokens is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - now time.Time
+func (_e *OfflineTokenStore_Expecter) OfflineTokens(ctx interface{}, userID interface{}, now interface{}) *OfflineTokenStore_OfflineTokens_Call {
+	return &OfflineTokenStore_OfflineTokens_Call{Call: _e.mock.On("OfflineTokens", ctx, userID, now)}
+}
+
+func (_c *OfflineTokenStore_OfflineTokens_Call) Run(run func(ctx context.Context, userID int64, now time.Time)) *OfflineTokenStore_OfflineTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *OfflineTokenStore_OfflineTokens_Call) Return(_a0 []models.OfflineToken, _a1 error) *OfflineTokenStore_OfflineTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OfflineTokenStore_OfflineTokens_Call) RunAndReturn(run func(context.Context, int64, time.Time) ([]models.OfflineToken, error)) *OfflineTokenStore_OfflineTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeOfflineToken provides a mock function with given fields: ctx, userID, id, at
+func (_m *OfflineTokenStore) RevokeOfflineToken(ctx context.Context, userID int64, id string, at time.Time) error {
+	ret := _m.Called(ctx, userID, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeOfflineToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time) error); ok {
+		r0 = rf(ctx, userID, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// OfflineTokenStore_RevokeOfflineToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeOfflineToken'
+type OfflineTokenStore_RevokeOfflineToken_Call struct {
+	*mock.Call
+}
+
+// RevokeOfflineToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - id string
+//   - at time.Time
+func (_e *OfflineTokenStore_Expecter) RevokeOfflineToken(ctx interface{}, userID interface{}, id interface{}, at interface{}) *OfflineTokenStore_RevokeOfflineToken_Call {
+	return &OfflineTokenStore_RevokeOfflineToken_Call{Call: _e.mock.On("RevokeOfflineToken", ctx, userID, id, at)}
+}
+
+func (_c *OfflineTokenStore_RevokeOfflineToken_Call) Run(run func(ctx context.Context, userID int64, id string, at time.Time)) *OfflineTokenStore_RevokeOfflineToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *OfflineTokenStore_RevokeOfflineToken_Call) Return(_a0 error) *OfflineTokenStore_RevokeOfflineToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *OfflineTokenStore_RevokeOfflineToken_Call) RunAndReturn(run func(context.Context, int64, string, time.Time) error) *OfflineTokenStore_RevokeOfflineToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveOfflineToken provides a mock function with given fields: ctx, t
+func (_m *OfflineTokenStore) SaveOfflineToken(ctx context.Context, t models.OfflineToken) error {
+	ret := _m.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOfflineToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.OfflineToken) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// OfflineTokenStore_SaveOfflineToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveOfflineToken'
+type OfflineTokenStore_SaveOfflineToken_Call struct {
+	*mock.Call
+}
+
+// SaveOfflineToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - t models.OfflineToken
+func (_e *OfflineTokenStore_Expecter) SaveOfflineToken(ctx interface{}, t interface{}) *OfflineTokenStore_SaveOfflineToken_Call {
+	return &OfflineTokenStore_SaveOfflineToken_Call{Call: _e.mock.On("SaveOfflineToken", ctx, t)}
+}
+
+func (_c *OfflineTokenStore_SaveOfflineToken_Call) Run(run func(ctx context.Context, t models.OfflineToken)) *OfflineTokenStore_SaveOfflineToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.OfflineToken))
+	})
+	return _c
+}
+
+func (_c *OfflineTokenStore_SaveOfflineToken_Call) Return(_a0 error) *OfflineTokenStore_SaveOfflineToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *OfflineTokenStore_SaveOfflineToken_Call) RunAndReturn(run func(context.Context, models.OfflineToken) error) *OfflineTokenStore_SaveOfflineToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewOfflineTokenStore creates a new instance of OfflineTokenStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOfflineTokenStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OfflineTokenStore {
+	mock := &OfflineTokenStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,271 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// OrgStore is an autogenerated mock type for the OrgStore type
+type OrgStore struct {
+	mock.Mock
+}
+
+type OrgStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *OrgStore) EXPECT() *OrgStore_Expecter {
+	return &OrgStore_Expecter{mock: &_m.Mock}
+}
+
+// MemberRole provides a mock function with given fields: ctx, orgID, userID
+func (_m *OrgStore) MemberRole(ctx context.Context, orgID int64, userID int64) (string, error) {
+	ret := _m.Called(ctx, orgID, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MemberRole")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) (string, error)); ok {
+		return rf(ctx, orgID, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) string); ok {
+		r0 = rf(ctx, orgID, userID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int64) error); ok {
+		r1 = rf(ctx, orgID, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrgStore_MemberRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MemberRole'
+type OrgStore_MemberRole_Call struct {
+	*mock.Call
+}
+
+// MemberRole is a helper method to define mock.On call
+//   - ctx context.Context
+//   - orgID int64
+//   - userID int64
+func (_e *OrgStore_Expecter) MemberRole(ctx interface{}, orgID interface{}, userID interface{}) *OrgStore_MemberRole_Call {
+	return &OrgStore_MemberRole_Call{Call: _e.mock.On("MemberRole", ctx, orgID, userID)}
+}
+
+func (_c *OrgStore_MemberRole_Call) Run(run func(ctx context.Context, orgID int64, userID int64)) *OrgStore_MemberRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *OrgStore_MemberRole_Call) Return(_a0 string, _a1 error) *OrgStore_MemberRole_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OrgStore_MemberRole_Call) RunAndReturn(run func(context.Context, int64, int64) (string, error)) *OrgStore_MemberRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrgBySlug provides a mock function with given fields: ctx, slug
+func (_m *OrgStore) OrgBySlug(ctx context.Context, slug string) (models.Organization, error) {
+	ret := _m.Called(ctx, slug)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrgBySlug")
+	}
+
+	var r0 models.Organization
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Organization, error)); ok {
+		return rf(ctx, slug)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Organization); ok {
+		r0 = rf(ctx, slug)
+	} else {
+		r0 = ret.Get(0).(models.Organization)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, slug)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrgStore_OrgBySlug_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrgBySlug'
+type OrgStore_OrgBySlug_Call struct {
+	*mock.Call
+}
+
+// OrgBySlug is a helper method to define mock.On call
+//   - ctx context.Context
+//   - slug string
+func (_e *OrgStore_Expecter) OrgBySlug(ctx interface{}, slug interface{}) *OrgStore_OrgBySlug_Call {
+	return &OrgStore_OrgBySlug_Call{Call: _e.mock.On("OrgBySlug", ctx, slug)}
+}
+
+func (_c *OrgStore_OrgBySlug_Call) Run(run func(ctx context.Context, slug string)) *OrgStore_OrgBySlug_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *OrgStore_OrgBySlug_Call) Return(_a0 models.Organization, _a1 error) *OrgStore_OrgBySlug_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OrgStore_OrgBySlug_Call) RunAndReturn(run func(context.Context, string) (models.Organization, error)) *OrgStore_OrgBySlug_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveOrgUser provides a mock function with given fields: ctx, email, passHash, orgID, scope, role
+func (_m *OrgStore) SaveOrgUser(ctx context.Context, email string, passHash []byte, orgID int64, scope int64, role string) (int64, error) {
+	ret := _m.Called(ctx, email, passHash, orgID, scope, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveOrgUser")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, int64, int64, string) (int64, error)); ok {
+		return rf(ctx, email, passHash, orgID, scope, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, int64, int64, string) int64); ok {
+		r0 = rf(ctx, email, passHash, orgID, scope, role)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte, int64, int64, string) error); ok {
+		r1 = rf(ctx, email, passHash, orgID, scope, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrgStore_SaveOrgUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveOrgUser'
+type OrgStore_SaveOrgUser_Call struct {
+	*mock.Call
+}
+
+// SaveOrgUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - passHash []byte
+//   - orgID int64
+//   - scope int64
+//   - role string
+func (_e *OrgStore_Expecter) SaveOrgUser(ctx interface{}, email interface{}, passHash interface{}, orgID interface{}, scope interface{}, role interface{}) *OrgStore_SaveOrgUser_Call {
+	return &OrgStore_SaveOrgUser_Call{Call: _e.mock.On("SaveOrgUser", ctx, email, passHash, orgID, scope, role)}
+}
+
+func (_c *OrgStore_SaveOrgUser_Call) Run(run func(ctx context.Context, email string, passHash []byte, orgID int64, scope int64, role string)) *OrgStore_SaveOrgUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]byte), args[3].(int64), args[4].(int64), args[5].(string))
+	})
+	return _c
+}
+
+func (_c *OrgStore_SaveOrgUser_Call) Return(_a0 int64, _a1 error) *OrgStore_SaveOrgUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OrgStore_SaveOrgUser_Call) RunAndReturn(run func(context.Context, string, []byte, int64, int64, string) (int64, error)) *OrgStore_SaveOrgUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ScopedUser provides a mock function with given fields: ctx, scope, email
+func (_m *OrgStore) ScopedUser(ctx context.Context, scope int64, email string) (models.User, error) {
+	ret := _m.Called(ctx, scope, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ScopedUser")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (models.User, error)); ok {
+		return rf(ctx, scope, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) models.User); ok {
+		r0 = rf(ctx, scope, email)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, scope, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OrgStore_ScopedUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ScopedUser'
+type OrgStore_ScopedUser_Call struct {
+	*mock.Call
+}
+
+// ScopedUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - scope int64
+//   - email string
+func (_e *OrgStore_Expecter) ScopedUser(ctx interface{}, scope interface{}, email interface{}) *OrgStore_ScopedUser_Call {
+	return &OrgStore_ScopedUser_Call{Call: _e.mock.On("ScopedUser", ctx, scope, email)}
+}
+
+func (_c *OrgStore_ScopedUser_Call) Run(run func(ctx context.Context, scope int64, email string)) *OrgStore_ScopedUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *OrgStore_ScopedUser_Call) Return(_a0 models.User, _a1 error) *OrgStore_ScopedUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *OrgStore_ScopedUser_Call) RunAndReturn(run func(context.Context, int64, string) (models.User, error)) *OrgStore_ScopedUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewOrgStore creates a new instance of OrgStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrgStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OrgStore {
+	mock := &OrgStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
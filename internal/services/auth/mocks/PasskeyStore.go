@@ -0,0 +1,414 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// PasskeyStore is an autogenerated mock type for the PasskeyStore type
+type PasskeyStore struct {
+	mock.Mock
+}
+
+type PasskeyStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *PasskeyStore) EXPECT() *PasskeyStore_Expecter {
+	return &PasskeyStore_Expecter{mock: &_m.Mock}
+}
+
+// DeletePasskey provides a mock function with given fields: ctx, userID, id
+func (_m *PasskeyStore) DeletePasskey(ctx context.Context, userID int64, id int64) error {
+	ret := _m.Called(ctx, userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeletePasskey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int64) error); ok {
+		r0 = rf(ctx, userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PasskeyStore_DeletePasskey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeletePasskey'
+type PasskeyStore_DeletePasskey_Call struct {
+	*mock.Call
+}
+
+// DeletePasskey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - id int64
+func (_e *PasskeyStore_Expecter) DeletePasskey(ctx interface{}, userID interface{}, id interface{}) *PasskeyStore_DeletePasskey_Call {
+	return &PasskeyStore_DeletePasskey_Call{Call: _e.mock.On("DeletePasskey", ctx, userID, id)}
+}
+
+func (_c *PasskeyStore_DeletePasskey_Call) Run(run func(ctx context.Context, userID int64, id int64)) *PasskeyStore_DeletePasskey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *PasskeyStore_DeletePasskey_Call) Return(_a0 error) *PasskeyStore_DeletePasskey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PasskeyStore_DeletePasskey_Call) RunAndReturn(run func(context.Context, int64, int64) error) *PasskeyStore_DeletePasskey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PasskeyByCredentialID provides a mock function with given fields: ctx, credentialID
+func (_m *PasskeyStore) PasskeyByCredentialID(ctx context.Context, credentialID []byte) (models.Passkey, error) {
+	ret := _m.Called(ctx, credentialID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PasskeyByCredentialID")
+	}
+
+	var r0 models.Passkey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) (models.Passkey, error)); ok {
+		return rf(ctx, credentialID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []byte) models.Passkey); ok {
+		r0 = rf(ctx, credentialID)
+	} else {
+		r0 = ret.Get(0).(models.Passkey)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []byte) error); ok {
+		r1 = rf(ctx, credentialID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PasskeyStore_PasskeyByCredentialID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PasskeyByCredentialID'
+type PasskeyStore_PasskeyByCredentialID_Call struct {
+	*mock.Call
+}
+
+// PasskeyByCredentialID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - credentialID []byte
+func (_e *PasskeyStore_Expecter) PasskeyByCredentialID(ctx interface{}, credentialID interface{}) *PasskeyStore_PasskeyByCredentialID_Call {
+	return &PasskeyStore_PasskeyByCredentialID_Call{Call: _e.mock.On("PasskeyByCredentialID", ctx, credentialID)}
+}
+
+func (_c *PasskeyStore_PasskeyByCredentialID_Call) Run(run func(ctx context.Context, credentialID []byte)) *PasskeyStore_PasskeyByCredentialID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]byte))
+	})
+	return _c
+}
+
+func (_c *PasskeyStore_PasskeyByCredentialID_Call) Return(_a0 models.Passkey, _a1 error) *PasskeyStore_PasskeyByCredentialID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PasskeyStore_PasskeyByCredentialID_Call) RunAndReturn(run func(context.Context, []byte) (models.Passkey, error)) *PasskeyStore_PasskeyByCredentialID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Passkeys provides a mock function with given fields: ctx, userID
+func (_m *PasskeyStore) Passkeys(ctx context.Context, userID int64) ([]models.Passkey, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Passkeys")
+	}
+
+	var r0 []models.Passkey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]models.Passkey, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []models.Passkey); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Passkey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PasskeyStore_Passkeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Passkeys'
+type PasskeyStore_Passkeys_Call struct {
+	*mock.Call
+}
+
+// Passkeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *PasskeyStore_Expecter) Passkeys(ctx interface{}, userID interface{}) *PasskeyStore_Passkeys_Call {
+	return &PasskeyStore_Passkeys_Call{Call: _e.mock.On("Passkeys", ctx, userID)}
+}
+
+func (_c *PasskeyStore_Passkeys_Call) Run(run func(ctx context.Context, userID int64)) *PasskeyStore_Passkeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *PasskeyStore_Passkeys_Call) Return(_a0 []models.Passkey, _a1 error) *PasskeyStore_Passkeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PasskeyStore_Passkeys_Call) RunAndReturn(run func(context.Context, int64) ([]models.Passkey, error)) *PasskeyStore_Passkeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SavePasskey provides a mock function with given fields: ctx, p
+func (_m *PasskeyStore) SavePasskey(ctx context.Context, p models.Passkey) (int64, error) {
+	ret := _m.Called(ctx, p)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SavePasskey")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Passkey) (int64, error)); ok {
+		return rf(ctx, p)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Passkey) int64); ok {
+		r0 = rf(ctx, p)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Passkey) error); ok {
+		r1 = rf(ctx, p)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PasskeyStore_SavePasskey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SavePasskey'
+type PasskeyStore_SavePasskey_Call struct {
+	*mock.Call
+}
+
+// SavePasskey is a helper method to define mock.On call
+//   - ctx context.Context
+//   - p models.Passkey
+func (_e *PasskeyStore_Expecter) SavePasskey(ctx interface{}, p interface{}) *PasskeyStore_SavePasskey_Call {
+	return &PasskeyStore_SavePasskey_Call{Call: _e.mock.On("SavePasskey", ctx, p)}
+}
+
+func (_c *PasskeyStore_SavePasskey_Call) Run(run func(ctx context.Context, p models.Passkey)) *PasskeyStore_SavePasskey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Passkey))
+	})
+	return _c
+}
+
+func (_c *PasskeyStore_SavePasskey_Call) Return(_a0 int64, _a1 error) *PasskeyStore_SavePasskey_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PasskeyStore_SavePasskey_Call) RunAndReturn(run func(context.Context, models.Passkey) (int64, error)) *PasskeyStore_SavePasskey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveWebAuthnSession provides a mock function with given fields: ctx, session
+func (_m *PasskeyStore) SaveWebAuthnSession(ctx context.Context, session models.WebAuthnSession) error {
+	ret := _m.Called(ctx, session)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveWebAuthnSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.WebAuthnSession) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PasskeyStore_SaveWebAuthnSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveWebAuthnSession'
+type PasskeyStore_SaveWebAuthnSession_Call struct {
+	*mock.Call
+}
+
+// SaveWebAuthnSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - session models.WebAuthnSession
+func (_e *PasskeyStore_Expecter) SaveWebAuthnSession(ctx interface{}, session interface{}) *PasskeyStore_SaveWebAuthnSession_Call {
+	return &PasskeyStore_SaveWebAuthnSession_Call{Call: _e.mock.On("SaveWebAuthnSession", ctx, session)}
+}
+
+func (_c *PasskeyStore_SaveWebAuthnSession_Call) Run(run func(ctx context.Context, session models.WebAuthnSession)) *PasskeyStore_SaveWebAuthnSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.WebAuthnSession))
+	})
+	return _c
+}
+
+func (_c *PasskeyStore_SaveWebAuthnSession_Call) Return(_a0 error) *PasskeyStore_SaveWebAuthnSession_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PasskeyStore_SaveWebAuthnSession_Call) RunAndReturn(run func(context.Context, models.WebAuthnSession) error) *PasskeyStore_SaveWebAuthnSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TakeWebAuthnSession provides a mock function with given fields: ctx, id
+func (_m *PasskeyStore) TakeWebAuthnSession(ctx context.Context, id string) (models.WebAuthnSession, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TakeWebAuthnSession")
+	}
+
+	var r0 models.WebAuthnSession
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.WebAuthnSession, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.WebAuthnSession); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.WebAuthnSession)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PasskeyStore_TakeWebAuthnSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TakeWebAuthnSession'
+type PasskeyStore_TakeWebAuthnSession_Call struct {
+	*mock.Call
+}
+
+// TakeWebAuthnSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *PasskeyStore_Expecter) TakeWebAuthnSession(ctx interface{}, id interface{}) *PasskeyStore_TakeWebAuthnSession_Call {
+	return &PasskeyStore_TakeWebAuthnSession_Call{Call: _e.mock.On("TakeWebAuthnSession", ctx, id)}
+}
+
+func (_c *PasskeyStore_TakeWebAuthnSession_Call) Run(run func(ctx context.Context, id string)) *PasskeyStore_TakeWebAuthnSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *PasskeyStore_TakeWebAuthnSession_Call) Return(_a0 models.WebAuthnSession, _a1 error) *PasskeyStore_TakeWebAuthnSession_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *PasskeyStore_TakeWebAuthnSession_Call) RunAndReturn(run func(context.Context, string) (models.WebAuthnSession, error)) *PasskeyStore_TakeWebAuthnSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePasskeyUsage provides a mock function with given fields: ctx, id, signCount, backupState, usedAt
+func (_m *PasskeyStore) UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) error {
+	ret := _m.Called(ctx, id, signCount, backupState, usedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePasskeyUsage")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, uint32, bool, time.Time) error); ok {
+		r0 = rf(ctx, id, signCount, backupState, usedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PasskeyStore_UpdatePasskeyUsage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePasskeyUsage'
+type PasskeyStore_UpdatePasskeyUsage_Call struct {
+	*mock.Call
+}
+
+// UpdatePasskeyUsage is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id int64
+//   - signCount uint32
+//   - backupState bool
+//   - usedAt time.Time
+func (_e *PasskeyStore_Expecter) UpdatePasskeyUsage(ctx interface{}, id interface{}, signCount interface{}, backupState interface{}, usedAt interface{}) *PasskeyStore_UpdatePasskeyUsage_Call {
+	return &PasskeyStore_UpdatePasskeyUsage_Call{Call: _e.mock.On("UpdatePasskeyUsage", ctx, id, signCount, backupState, usedAt)}
+}
+
+func (_c *PasskeyStore_UpdatePasskeyUsage_Call) Run(run func(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time)) *PasskeyStore_UpdatePasskeyUsage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(uint32), args[3].(bool), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *PasskeyStore_UpdatePasskeyUsage_Call) Return(_a0 error) *PasskeyStore_UpdatePasskeyUsage_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *PasskeyStore_UpdatePasskeyUsage_Call) RunAndReturn(run func(context.Context, int64, uint32, bool, time.Time) error) *PasskeyStore_UpdatePasskeyUsage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewPasskeyStore creates a new instance of PasskeyStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewPasskeyStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *PasskeyStore {
+	mock := &PasskeyStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,319 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// ProvisioningStore is an autogenerated mock type for the ProvisioningStore type
+type ProvisioningStore struct {
+	mock.Mock
+}
+
+type ProvisioningStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *ProvisioningStore) EXPECT() *ProvisioningStore_Expecter {
+	return &ProvisioningStore_Expecter{mock: &_m.Mock}
+}
+
+// DeprovisionUser provides a mock function with given fields: ctx, externalID, at
+func (_m *ProvisioningStore) DeprovisionUser(ctx context.Context, externalID string, at time.Time) (int64, error) {
+	ret := _m.Called(ctx, externalID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeprovisionUser")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (int64, error)); ok {
+		return rf(ctx, externalID, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) int64); ok {
+		r0 = rf(ctx, externalID, at)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, externalID, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProvisioningStore_DeprovisionUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeprovisionUser'
+type ProvisioningStore_DeprovisionUser_Call struct {
+	*mock.Call
+}
+
+// DeprovisionUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - externalID string
+//   - at time.Time
+func (_e *ProvisioningStore_Expecter) DeprovisionUser(ctx interface{}, externalID interface{}, at interface{}) *ProvisioningStore_DeprovisionUser_Call {
+	return &ProvisioningStore_DeprovisionUser_Call{Call: _e.mock.On("DeprovisionUser", ctx, externalID, at)}
+}
+
+func (_c *ProvisioningStore_DeprovisionUser_Call) Run(run func(ctx context.Context, externalID string, at time.Time)) *ProvisioningStore_DeprovisionUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *ProvisioningStore_DeprovisionUser_Call) Return(_a0 int64, _a1 error) *ProvisioningStore_DeprovisionUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProvisioningStore_DeprovisionUser_Call) RunAndReturn(run func(context.Context, string, time.Time) (int64, error)) *ProvisioningStore_DeprovisionUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ProvisionedUser provides a mock function with given fields: ctx, externalID
+func (_m *ProvisioningStore) ProvisionedUser(ctx context.Context, externalID string) (models.ProvisionedUser, error) {
+	ret := _m.Called(ctx, externalID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProvisionedUser")
+	}
+
+	var r0 models.ProvisionedUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.ProvisionedUser, error)); ok {
+		return rf(ctx, externalID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.ProvisionedUser); ok {
+		r0 = rf(ctx, externalID)
+	} else {
+		r0 = ret.Get(0).(models.ProvisionedUser)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, externalID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProvisioningStore_ProvisionedUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProvisionedUser'
+type ProvisioningStore_ProvisionedUser_Call struct {
+	*mock.Call
+}
+
+// ProvisionedUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - externalID string
+func (_e *ProvisioningStore_Expecter) ProvisionedUser(ctx interface{}, externalID interface{}) *ProvisioningStore_ProvisionedUser_Call {
+	return &ProvisioningStore_ProvisionedUser_Call{Call: _e.mock.On("ProvisionedUser", ctx, externalID)}
+}
+
+func (_c *ProvisioningStore_ProvisionedUser_Call) Run(run func(ctx context.Context, externalID string)) *ProvisioningStore_ProvisionedUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *ProvisioningStore_ProvisionedUser_Call) Return(_a0 models.ProvisionedUser, _a1 error) *ProvisioningStore_ProvisionedUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProvisioningStore_ProvisionedUser_Call) RunAndReturn(run func(context.Context, string) (models.ProvisionedUser, error)) *ProvisioningStore_ProvisionedUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ProvisionedUsers provides a mock function with given fields: ctx, since, afterID, limit
+func (_m *ProvisioningStore) ProvisionedUsers(ctx context.Context, since time.Time, afterID int64, limit int) ([]models.ProvisionedUser, error) {
+	ret := _m.Called(ctx, since, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ProvisionedUsers")
+	}
+
+	var r0 []models.ProvisionedUser
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64, int) ([]models.ProvisionedUser, error)); ok {
+		return rf(ctx, since, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int64, int) []models.ProvisionedUser); ok {
+		r0 = rf(ctx, since, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.ProvisionedUser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int64, int) error); ok {
+		r1 = rf(ctx, since, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProvisioningStore_ProvisionedUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ProvisionedUsers'
+type ProvisioningStore_ProvisionedUsers_Call struct {
+	*mock.Call
+}
+
+// ProvisionedUsers is a helper method to define mock.On call
+//   - ctx context.Context
+//   - since time.Time
+//   - afterID int64
+//   - limit int
+func (_e *ProvisioningStore_Expecter) ProvisionedUsers(ctx interface{}, since interface{}, afterID interface{}, limit interface{}) *ProvisioningStore_ProvisionedUsers_Call {
+	return &ProvisioningStore_ProvisionedUsers_Call{Call: _e.mock.On("ProvisionedUsers", ctx, since, afterID, limit)}
+}
+
+func (_c *ProvisioningStore_ProvisionedUsers_Call) Run(run func(ctx context.Context, since time.Time, afterID int64, limit int)) *ProvisioningStore_ProvisionedUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(time.Time), args[2].(int64), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *ProvisioningStore_ProvisionedUsers_Call) Return(_a0 []models.ProvisionedUser, _a1 error) *ProvisioningStore_ProvisionedUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProvisioningStore_ProvisionedUsers_Call) RunAndReturn(run func(context.Context, time.Time, int64, int) ([]models.ProvisionedUser, error)) *ProvisioningStore_ProvisionedUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveProvisionedUser provides a mock function with given fields: ctx, u
+func (_m *ProvisioningStore) SaveProvisionedUser(ctx context.Context, u models.ProvisionedUser) (int64, error) {
+	ret := _m.Called(ctx, u)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveProvisionedUser")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.ProvisionedUser) (int64, error)); ok {
+		return rf(ctx, u)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.ProvisionedUser) int64); ok {
+		r0 = rf(ctx, u)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.ProvisionedUser) error); ok {
+		r1 = rf(ctx, u)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ProvisioningStore_SaveProvisionedUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveProvisionedUser'
+type ProvisioningStore_SaveProvisionedUser_Call struct {
+	*mock.Call
+}
+
+// SaveProvisionedUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - u models.ProvisionedUser
+func (_e *ProvisioningStore_Expecter) SaveProvisionedUser(ctx interface{}, u interface{}) *ProvisioningStore_SaveProvisionedUser_Call {
+	return &ProvisioningStore_SaveProvisionedUser_Call{Call: _e.mock.On("SaveProvisionedUser", ctx, u)}
+}
+
+func (_c *ProvisioningStore_SaveProvisionedUser_Call) Run(run func(ctx context.Context, u models.ProvisionedUser)) *ProvisioningStore_SaveProvisionedUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.ProvisionedUser))
+	})
+	return _c
+}
+
+func (_c *ProvisioningStore_SaveProvisionedUser_Call) Return(_a0 int64, _a1 error) *ProvisioningStore_SaveProvisionedUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *ProvisioningStore_SaveProvisionedUser_Call) RunAndReturn(run func(context.Context, models.ProvisionedUser) (int64, error)) *ProvisioningStore_SaveProvisionedUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateProvisionedUser provides a mock function with given fields: ctx, u
+func (_m *ProvisioningStore) UpdateProvisionedUser(ctx context.Context, u models.ProvisionedUser) error {
+	ret := _m.Called(ctx, u)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateProvisionedUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.ProvisionedUser) error); ok {
+		r0 = rf(ctx, u)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ProvisioningStore_UpdateProvisionedUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateProvisionedUser'
+type ProvisioningStore_UpdateProvisionedUser_Call struct {
+	*mock.Call
+}
+
+// UpdateProvisionedUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - u models.ProvisionedUser
+func (_e *ProvisioningStore_Expecter) UpdateProvisionedUser(ctx interface{}, u interface{}) *ProvisioningStore_UpdateProvisionedUser_Call {
+	return &ProvisioningStore_UpdateProvisionedUser_Call{Call: _e.mock.On("UpdateProvisionedUser", ctx, u)}
+}
+
+func (_c *ProvisioningStore_UpdateProvisionedUser_Call) Run(run func(ctx context.Context, u models.ProvisionedUser)) *ProvisioningStore_UpdateProvisionedUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.ProvisionedUser))
+	})
+	return _c
+}
+
+func (_c *ProvisioningStore_UpdateProvisionedUser_Call) Return(_a0 error) *ProvisioningStore_UpdateProvisionedUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *ProvisioningStore_UpdateProvisionedUser_Call) RunAndReturn(run func(context.Context, models.ProvisionedUser) error) *ProvisioningStore_UpdateProvisionedUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewProvisioningStore creates a new instance of ProvisioningStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProvisioningStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ProvisioningStore {
+	mock := &ProvisioningStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,241 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// RefreshTokenStore is an autogenerated mock type for the RefreshTokenStore type
+type RefreshTokenStore struct {
+	mock.Mock
+}
+
+type RefreshTokenStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *RefreshTokenStore) EXPECT() *RefreshTokenStore_Expecter {
+	return &RefreshTokenStore_Expecter{mock: &_m.Mock}
+}
+
+// RefreshFamily provides a mock function with given fields: ctx, id
+func (_m *RefreshTokenStore) RefreshFamily(ctx context.Context, id string) (models.RefreshFamily, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshFamily")
+	}
+
+	var r0 models.RefreshFamily
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.RefreshFamily, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.RefreshFamily); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.RefreshFamily)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefreshTokenStore_RefreshFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RefreshFamily'
+type RefreshTokenStore_RefreshFamily_Call struct {
+	*mock.Call
+}
+
+// RefreshFamily is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *RefreshTokenStore_Expecter) RefreshFamily(ctx interface{}, id interface{}) *RefreshTokenStore_RefreshFamily_Call {
+	return &RefreshTokenStore_RefreshFamily_Call{Call: _e.mock.On("RefreshFamily", ctx, id)}
+}
+
+func (_c *RefreshTokenStore_RefreshFamily_Call) Run(run func(ctx context.Context, id string)) *RefreshTokenStore_RefreshFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *RefreshTokenStore_RefreshFamily_Call) Return(_a0 models.RefreshFamily, _a1 error) *RefreshTokenStore_RefreshFamily_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *RefreshTokenStore_RefreshFamily_Call) RunAndReturn(run func(context.Context, string) (models.RefreshFamily, error)) *RefreshTokenStore_RefreshFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeRefreshFamily provides a mock function with given fields: ctx, id, at
+func (_m *RefreshTokenStore) RevokeRefreshFamily(ctx context.Context, id string, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeRefreshFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RefreshTokenStore_RevokeRefreshFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeRefreshFamily'
+type RefreshTokenStore_RevokeRefreshFamily_Call struct {
+	*mock.Call
+}
+
+// RevokeRefreshFamily is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - at time.Time
+func (_e *RefreshTokenStore_Expecter) RevokeRefreshFamily(ctx interface{}, id interface{}, at interface{}) *RefreshTokenStore_RevokeRefreshFamily_Call {
+	return &RefreshTokenStore_RevokeRefreshFamily_Call{Call: _e.mock.On("RevokeRefreshFamily", ctx, id, at)}
+}
+
+func (_c *RefreshTokenStore_RevokeRefreshFamily_Call) Run(run func(ctx context.Context, id string, at time.Time)) *RefreshTokenStore_RevokeRefreshFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *RefreshTokenStore_RevokeRefreshFamily_Call) Return(_a0 error) *RefreshTokenStore_RevokeRefreshFamily_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *RefreshTokenStore_RevokeRefreshFamily_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *RefreshTokenStore_RevokeRefreshFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateRefreshToken provides a mock function with given fields: ctx, id, oldHash, newHash, expiresAt
+func (_m *RefreshTokenStore) RotateRefreshToken(ctx context.Context, id string, oldHash string, newHash string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, id, oldHash, newHash, expiresAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateRefreshToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, string, time.Time) error); ok {
+		r0 = rf(ctx, id, oldHash, newHash, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RefreshTokenStore_RotateRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateRefreshToken'
+type RefreshTokenStore_RotateRefreshToken_Call struct {
+	*mock.Call
+}
+
+// RotateRefreshToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - oldHash string
+//   - newHash string
+//   - expiresAt time.Time
+func (_e *RefreshTokenStore_Expecter) RotateRefreshToken(ctx interface{}, id interface{}, oldHash interface{}, newHash interface{}, expiresAt interface{}) *RefreshTokenStore_RotateRefreshToken_Call {
+	return &RefreshTokenStore_RotateRefreshToken_Call{Call: _e.mock.On("RotateRefreshToken", ctx, id, oldHash, newHash, expiresAt)}
+}
+
+func (_c *RefreshTokenStore_RotateRefreshToken_Call) Run(run func(ctx context.Context, id string, oldHash string, newHash string, expiresAt time.Time)) *RefreshTokenStore_RotateRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(string), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *RefreshTokenStore_RotateRefreshToken_Call) Return(_a0 error) *RefreshTokenStore_RotateRefreshToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *RefreshTokenStore_RotateRefreshToken_Call) RunAndReturn(run func(context.Context, string, string, string, time.Time) error) *RefreshTokenStore_RotateRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveRefreshFamily provides a mock function with given fields: ctx, f
+func (_m *RefreshTokenStore) SaveRefreshFamily(ctx context.Context, f models.RefreshFamily) error {
+	ret := _m.Called(ctx, f)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveRefreshFamily")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.RefreshFamily) error); ok {
+		r0 = rf(ctx, f)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RefreshTokenStore_SaveRefreshFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveRefreshFamily'
+type RefreshTokenStore_SaveRefreshFamily_Call struct {
+	*mock.Call
+}
+
+// SaveRefreshFamily is a helper method to define mock.On call
+//   - ctx context.Context
+//   - f models.RefreshFamily
+func (_e *RefreshTokenStore_Expecter) SaveRefreshFamily(ctx interface{}, f interface{}) *RefreshTokenStore_SaveRefreshFamily_Call {
+	return &RefreshTokenStore_SaveRefreshFamily_Call{Call: _e.mock.On("SaveRefreshFamily", ctx, f)}
+}
+
+func (_c *RefreshTokenStore_SaveRefreshFamily_Call) Run(run func(ctx context.Context, f models.RefreshFamily)) *RefreshTokenStore_SaveRefreshFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.RefreshFamily))
+	})
+	return _c
+}
+
+func (_c *RefreshTokenStore_SaveRefreshFamily_Call) Return(_a0 error) *RefreshTokenStore_SaveRefreshFamily_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *RefreshTokenStore_SaveRefreshFamily_Call) RunAndReturn(run func(context.Context, models.RefreshFamily) error) *RefreshTokenStore_SaveRefreshFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewRefreshTokenStore creates a new instance of RefreshTokenStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRefreshTokenStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RefreshTokenStore {
+	mock := &RefreshTokenStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
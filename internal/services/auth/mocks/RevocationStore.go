@@ -0,0 +1,94 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// RevocationStore is an autogenerated mock type for the RevocationStore type
+type RevocationStore struct {
+	mock.Mock
+}
+
+type RevocationStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *RevocationStore) EXPECT() *RevocationStore_Expecter {
+	return &RevocationStore_Expecter{mock: &_m.Mock}
+}
+
+// AddRevocation provides a mock function with given fields: ctx, r
+func (_m *RevocationStore) AddRevocation(ctx context.Context, r models.Revocation) (int64, error) {
+	ret := _m.Called(ctx, r)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddRevocation")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Revocation) (int64, error)); ok {
+		return rf(ctx, r)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Revocation) int64); ok {
+		r0 = rf(ctx, r)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Revocation) error); ok {
+		r1 = rf(ctx, r)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevocationStore_AddRevocation_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddRevocation'
+type RevocationStore_AddRevocation_Call struct {
+	*mock.Call
+}
+
+// AddRevocation is a helper method to define mock.On call
+//   - ctx context.Context
+//   - r models.Revocation
+func (_e *RevocationStore_Expecter) AddRevocation(ctx interface{}, r interface{}) *RevocationStore_AddRevocation_Call {
+	return &RevocationStore_AddRevocation_Call{Call: _e.mock.On("AddRevocation", ctx, r)}
+}
+
+func (_c *RevocationStore_AddRevocation_Call) Run(run func(ctx context.Context, r models.Revocation)) *RevocationStore_AddRevocation_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Revocation))
+	})
+	return _c
+}
+
+func (_c *RevocationStore_AddRevocation_Call) Return(_a0 int64, _a1 error) *RevocationStore_AddRevocation_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *RevocationStore_AddRevocation_Call) RunAndReturn(run func(context.Context, models.Revocation) (int64, error)) *RevocationStore_AddRevocation_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewRevocationStore creates a new instance of RevocationStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRevocationStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *RevocationStore {
+	mock := &RevocationStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,84 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SMSProvider is an autogenerated mock type for the SMSProvider type
+type SMSProvider struct {
+	mock.Mock
+}
+
+type SMSProvider_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *SMSProvider) EXPECT() *SMSProvider_Expecter {
+	return &SMSProvider_Expecter{mock: &_m.Mock}
+}
+
+// Send provides a mock function with given fields: ctx, phone, message
+func (_m *SMSProvider) Send(ctx context.Context, phone string, message string) error {
+	ret := _m.Called(ctx, phone, message)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Send")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, phone, message)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SMSProvider_Send_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Send'
+type SMSProvider_Send_Call struct {
+	*mock.Call
+}
+
+// Send is a helper method to define mock.On call
+//   - ctx context.Context
+//   - phone string
+//   - message string
+func (_e *SMSProvider_Expecter) Send(ctx interface{}, phone interface{}, message interface{}) *SMSProvider_Send_Call {
+	return &SMSProvider_Send_Call{Call: _e.mock.On("Send", ctx, phone, message)}
+}
+
+func (_c *SMSProvider_Send_Call) Run(run func(ctx context.Context, phone string, message string)) *SMSProvider_Send_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *SMSProvider_Send_Call) Return(_a0 error) *SMSProvider_Send_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SMSProvider_Send_Call) RunAndReturn(run func(context.Context, string, string) error) *SMSProvider_Send_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewSMSProvider creates a new instance of SMSProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSMSProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SMSProvider {
+	mock := &SMSProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
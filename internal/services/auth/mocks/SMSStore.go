@@ -0,0 +1,388 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// SMSStore is an autogenerated mock type for the SMSStore type
+type SMSStore struct {
+	mock.Mock
+}
+
+type SMSStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *SMSStore) EXPECT() *SMSStore_Expecter {
+	return &SMSStore_Expecter{mock: &_m.Mock}
+}
+
+// ConsumeSMSAttempt provides a mock function with given fields: ctx, id, maxAttempts
+func (_m *SMSStore) ConsumeSMSAttempt(ctx context.Context, id string, maxAttempts int) error {
+	ret := _m.Called(ctx, id, maxAttempts)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumeSMSAttempt")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int) error); ok {
+		r0 = rf(ctx, id, maxAttempts)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SMSStore_ConsumeSMSAttempt_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ConsumeSMSAttempt'
+type SMSStore_ConsumeSMSAttempt_Call struct {
+	*mock.Call
+}
+
+// ConsumeSMSAttempt is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - maxAttempts int
+func (_e *SMSStore_Expecter) ConsumeSMSAttempt(ctx interface{}, id interface{}, maxAttempts interface{}) *SMSStore_ConsumeSMSAttempt_Call {
+	return &SMSStore_ConsumeSMSAttempt_Call{Call: _e.mock.On("ConsumeSMSAttempt", ctx, id, maxAttempts)}
+}
+
+func (_c *SMSStore_ConsumeSMSAttempt_Call) Run(run func(ctx context.Context, id string, maxAttempts int)) *SMSStore_ConsumeSMSAttempt_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *SMSStore_ConsumeSMSAttempt_Call) Return(_a0 error) *SMSStore_ConsumeSMSAttempt_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SMSStore_ConsumeSMSAttempt_Call) RunAndReturn(run func(context.Context, string, int) error) *SMSStore_ConsumeSMSAttempt_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteSMSChallenge provides a mock function with given fields: ctx, id
+func (_m *SMSStore) DeleteSMSChallenge(ctx context.Context, id string) error {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSMSChallenge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SMSStore_DeleteSMSChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSMSChallenge'
+type SMSStore_DeleteSMSChallenge_Call struct {
+	*mock.Call
+}
+
+// DeleteSMSChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *SMSStore_Expecter) DeleteSMSChallenge(ctx interface{}, id interface{}) *SMSStore_DeleteSMSChallenge_Call {
+	return &SMSStore_DeleteSMSChallenge_Call{Call: _e.mock.On("DeleteSMSChallenge", ctx, id)}
+}
+
+func (_c *SMSStore_DeleteSMSChallenge_Call) Run(run func(ctx context.Context, id string)) *SMSStore_DeleteSMSChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *SMSStore_DeleteSMSChallenge_Call) Return(_a0 error) *SMSStore_DeleteSMSChallenge_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SMSStore_DeleteSMSChallenge_Call) RunAndReturn(run func(context.Context, string) error) *SMSStore_DeleteSMSChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ReserveSMSSend provides a mock function with given fields: ctx, userID, phone, sentAt, since, maxPerUser, maxPerPhone
+func (_m *SMSStore) ReserveSMSSend(ctx context.Context, userID int64, phone string, sentAt time.Time, since time.Time, maxPerUser int, maxPerPhone int) error {
+	ret := _m.Called(ctx, userID, phone, sentAt, since, maxPerUser, maxPerPhone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReserveSMSSend")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, time.Time, time.Time, int, int) error); ok {
+		r0 = rf(ctx, userID, phone, sentAt, since, maxPerUser, maxPerPhone)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SMSStore_ReserveSMSSend_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ReserveSMSSend'
+type SMSStore_ReserveSMSSend_Call struct {
+	*mock.Call
+}
+
+// ReserveSMSSend is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - phone string
+//   - sentAt time.Time
+//   - since time.Time
+//   - maxPerUser int
+//   - maxPerPhone int
+func (_e *SMSStore_Expecter) ReserveSMSSend(ctx interface{}, userID interface{}, phone interface{}, sentAt interface{}, since interface{}, maxPerUser interface{}, maxPerPhone interface{}) *SMSStore_ReserveSMSSend_Call {
+	return &SMSStore_ReserveSMSSend_Call{Call: _e.mock.On("ReserveSMSSend", ctx, userID, phone, sentAt, since, maxPerUser, maxPerPhone)}
+}
+
+func (_c *SMSStore_ReserveSMSSend_Call) Run(run func(ctx context.Context, userID int64, phone string, sentAt time.Time, since time.Time, maxPerUser int, maxPerPhone int)) *SMSStore_ReserveSMSSend_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string), args[3].(time.Time), args[4].(time.Time), args[5].(int), args[6].(int))
+	})
+	return _c
+}
+
+func (_c *SMSStore_ReserveSMSSend_Call) Return(_a0 error) *SMSStore_ReserveSMSSend_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SMSStore_ReserveSMSSend_Call) RunAndReturn(run func(context.Context, int64, string, time.Time, time.Time, int, int) error) *SMSStore_ReserveSMSSend_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SMSChallenge provides a mock function with given fields: ctx, id
+func (_m *SMSStore) SMSChallenge(ctx context.Context, id string) (models.SMSChallenge, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SMSChallenge")
+	}
+
+	var r0 models.SMSChallenge
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.SMSChallenge, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.SMSChallenge); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.SMSChallenge)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SMSStore_SMSChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SMSChallenge'
+type SMSStore_SMSChallenge_Call struct {
+	*mock.Call
+}
+
+// SMSChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *SMSStore_Expecter) SMSChallenge(ctx interface{}, id interface{}) *SMSStore_SMSChallenge_Call {
+	return &SMSStore_SMSChallenge_Call{Call: _e.mock.On("SMSChallenge", ctx, id)}
+}
+
+func (_c *SMSStore_SMSChallenge_Call) Run(run func(ctx context.Context, id string)) *SMSStore_SMSChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *SMSStore_SMSChallenge_Call) Return(_a0 models.SMSChallenge, _a1 error) *SMSStore_SMSChallenge_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *SMSStore_SMSChallenge_Call) RunAndReturn(run func(context.Context, string) (models.SMSChallenge, error)) *SMSStore_SMSChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveSMSChallenge provides a mock function with given fields: ctx, c
+func (_m *SMSStore) SaveSMSChallenge(ctx context.Context, c models.SMSChallenge) error {
+	ret := _m.Called(ctx, c)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSMSChallenge")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.SMSChallenge) error); ok {
+		r0 = rf(ctx, c)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SMSStore_SaveSMSChallenge_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSMSChallenge'
+type SMSStore_SaveSMSChallenge_Call struct {
+	*mock.Call
+}
+
+// SaveSMSChallenge is a helper method to define mock.On call
+//   - ctx context.Context
+//   - c models.SMSChallenge
+func (_e *SMSStore_Expecter) SaveSMSChallenge(ctx interface{}, c interface{}) *SMSStore_SaveSMSChallenge_Call {
+	return &SMSStore_SaveSMSChallenge_Call{Call: _e.mock.On("SaveSMSChallenge", ctx, c)}
+}
+
+func (_c *SMSStore_SaveSMSChallenge_Call) Run(run func(ctx context.Context, c models.SMSChallenge)) *SMSStore_SaveSMSChallenge_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.SMSChallenge))
+	})
+	return _c
+}
+
+func (_c *SMSStore_SaveSMSChallenge_Call) Return(_a0 error) *SMSStore_SaveSMSChallenge_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SMSStore_SaveSMSChallenge_Call) RunAndReturn(run func(context.Context, models.SMSChallenge) error) *SMSStore_SaveSMSChallenge_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetSMSCode provides a mock function with given fields: ctx, id, codeHash, codeExpiresAt, sentAt
+func (_m *SMSStore) SetSMSCode(ctx context.Context, id string, codeHash []byte, codeExpiresAt time.Time, sentAt time.Time) error {
+	ret := _m.Called(ctx, id, codeHash, codeExpiresAt, sentAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetSMSCode")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte, time.Time, time.Time) error); ok {
+		r0 = rf(ctx, id, codeHash, codeExpiresAt, sentAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SMSStore_SetSMSCode_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetSMSCode'
+type SMSStore_SetSMSCode_Call struct {
+	*mock.Call
+}
+
+// SetSMSCode is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - codeHash []byte
+//   - codeExpiresAt time.Time
+//   - sentAt time.Time
+func (_e *SMSStore_Expecter) SetSMSCode(ctx interface{}, id interface{}, codeHash interface{}, codeExpiresAt interface{}, sentAt interface{}) *SMSStore_SetSMSCode_Call {
+	return &SMSStore_SetSMSCode_Call{Call: _e.mock.On("SetSMSCode", ctx, id, codeHash, codeExpiresAt, sentAt)}
+}
+
+func (_c *SMSStore_SetSMSCode_Call) Run(run func(ctx context.Context, id string, codeHash []byte, codeExpiresAt time.Time, sentAt time.Time)) *SMSStore_SetSMSCode_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]byte), args[3].(time.Time), args[4].(time.Time))
+	})
+	return _c
+}
+
+func (_c *SMSStore_SetSMSCode_Call) Return(_a0 error) *SMSStore_SetSMSCode_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SMSStore_SetSMSCode_Call) RunAndReturn(run func(context.Context, string, []byte, time.Time, time.Time) error) *SMSStore_SetSMSCode_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetUserPhone provides a mock function with given fields: ctx, userID, phone
+func (_m *SMSStore) SetUserPhone(ctx context.Context, userID int64, phone string) error {
+	ret := _m.Called(ctx, userID, phone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserPhone")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, phone)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SMSStore_SetUserPhone_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserPhone'
+type SMSStore_SetUserPhone_Call struct {
+	*mock.Call
+}
+
+// SetUserPhone is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - phone string
+func (_e *SMSStore_Expecter) SetUserPhone(ctx interface{}, userID interface{}, phone interface{}) *SMSStore_SetUserPhone_Call {
+	return &SMSStore_SetUserPhone_Call{Call: _e.mock.On("SetUserPhone", ctx, userID, phone)}
+}
+
+func (_c *SMSStore_SetUserPhone_Call) Run(run func(ctx context.Context, userID int64, phone string)) *SMSStore_SetUserPhone_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *SMSStore_SetUserPhone_Call) Return(_a0 error) *SMSStore_SetUserPhone_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SMSStore_SetUserPhone_Call) RunAndReturn(run func(context.Context, int64, string) error) *SMSStore_SetUserPhone_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewSMSStore creates a new instance of SMSStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSMSStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SMSStore {
+	mock := &SMSStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
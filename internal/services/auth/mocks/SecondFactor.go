@@ -0,0 +1,151 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SecondFactor is an autogenerated mock type for the SecondFactor type
+type SecondFactor struct {
+	mock.Mock
+}
+
+type SecondFactor_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *SecondFactor) EXPECT() *SecondFactor_Expecter {
+	return &SecondFactor_Expecter{mock: &_m.Mock}
+}
+
+// HasSecondFactor provides a mock function with given fields: ctx, userID
+func (_m *SecondFactor) HasSecondFactor(ctx context.Context, userID int64) (bool, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HasSecondFactor")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SecondFactor_HasSecondFactor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HasSecondFactor'
+type SecondFactor_HasSecondFactor_Call struct {
+	*mock.Call
+}
+
+// HasSecondFactor is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *SecondFactor_Expecter) HasSecondFactor(ctx interface{}, userID interface{}) *SecondFactor_HasSecondFactor_Call {
+	return &SecondFactor_HasSecondFactor_Call{Call: _e.mock.On("HasSecondFactor", ctx, userID)}
+}
+
+func (_c *SecondFactor_HasSecondFactor_Call) Run(run func(ctx context.Context, userID int64)) *SecondFactor_HasSecondFactor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *SecondFactor_HasSecondFactor_Call) Return(_a0 bool, _a1 error) *SecondFactor_HasSecondFactor_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *SecondFactor_HasSecondFactor_Call) RunAndReturn(run func(context.Context, int64) (bool, error)) *SecondFactor_HasSecondFactor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// VerifySecondFactor provides a mock function with given fields: ctx, userID, code
+func (_m *SecondFactor) VerifySecondFactor(ctx context.Context, userID int64, code string) (string, error) {
+	ret := _m.Called(ctx, userID, code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifySecondFactor")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) (string, error)); ok {
+		return rf(ctx, userID, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) string); ok {
+		r0 = rf(ctx, userID, code)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string) error); ok {
+		r1 = rf(ctx, userID, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SecondFactor_VerifySecondFactor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'VerifySecondFactor'
+type SecondFactor_VerifySecondFactor_Call struct {
+	*mock.Call
+}
+
+// VerifySecondFactor is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - code string
+func (_e *SecondFactor_Expecter) VerifySecondFactor(ctx interface{}, userID interface{}, code interface{}) *SecondFactor_VerifySecondFactor_Call {
+	return &SecondFactor_VerifySecondFactor_Call{Call: _e.mock.On("VerifySecondFactor", ctx, userID, code)}
+}
+
+func (_c *SecondFactor_VerifySecondFactor_Call) Run(run func(ctx context.Context, userID int64, code string)) *SecondFactor_VerifySecondFactor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *SecondFactor_VerifySecondFactor_Call) Return(method string, err error) *SecondFactor_VerifySecondFactor_Call {
+	_c.Call.Return(method, err)
+	return _c
+}
+
+func (_c *SecondFactor_VerifySecondFactor_Call) RunAndReturn(run func(context.Context, int64, string) (string, error)) *SecondFactor_VerifySecondFactor_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewSecondFactor creates a new instance of SecondFactor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSecondFactor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SecondFactor {
+	mock := &SecondFactor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,239 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// SelfTestStore is an autogenerated mock type for the SelfTestStore type
+type SelfTestStore struct {
+	mock.Mock
+}
+
+type SelfTestStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *SelfTestStore) EXPECT() *SelfTestStore_Expecter {
+	return &SelfTestStore_Expecter{mock: &_m.Mock}
+}
+
+// DeleteSelfTestData provides a mock function with given fields: ctx, userID, appID
+func (_m *SelfTestStore) DeleteSelfTestData(ctx context.Context, userID int64, appID int) error {
+	ret := _m.Called(ctx, userID, appID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSelfTestData")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) error); ok {
+		r0 = rf(ctx, userID, appID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SelfTestStore_DeleteSelfTestData_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSelfTestData'
+type SelfTestStore_DeleteSelfTestData_Call struct {
+	*mock.Call
+}
+
+// DeleteSelfTestData is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - appID int
+func (_e *SelfTestStore_Expecter) DeleteSelfTestData(ctx interface{}, userID interface{}, appID interface{}) *SelfTestStore_DeleteSelfTestData_Call {
+	return &SelfTestStore_DeleteSelfTestData_Call{Call: _e.mock.On("DeleteSelfTestData", ctx, userID, appID)}
+}
+
+func (_c *SelfTestStore_DeleteSelfTestData_Call) Run(run func(ctx context.Context, userID int64, appID int)) *SelfTestStore_DeleteSelfTestData_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *SelfTestStore_DeleteSelfTestData_Call) Return(_a0 error) *SelfTestStore_DeleteSelfTestData_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SelfTestStore_DeleteSelfTestData_Call) RunAndReturn(run func(context.Context, int64, int) error) *SelfTestStore_DeleteSelfTestData_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MarkSelfTestUser provides a mock function with given fields: ctx, userID, at
+func (_m *SelfTestStore) MarkSelfTestUser(ctx context.Context, userID int64, at time.Time) error {
+	ret := _m.Called(ctx, userID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MarkSelfTestUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, time.Time) error); ok {
+		r0 = rf(ctx, userID, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SelfTestStore_MarkSelfTestUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MarkSelfTestUser'
+type SelfTestStore_MarkSelfTestUser_Call struct {
+	*mock.Call
+}
+
+// MarkSelfTestUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - at time.Time
+func (_e *SelfTestStore_Expecter) MarkSelfTestUser(ctx interface{}, userID interface{}, at interface{}) *SelfTestStore_MarkSelfTestUser_Call {
+	return &SelfTestStore_MarkSelfTestUser_Call{Call: _e.mock.On("MarkSelfTestUser", ctx, userID, at)}
+}
+
+func (_c *SelfTestStore_MarkSelfTestUser_Call) Run(run func(ctx context.Context, userID int64, at time.Time)) *SelfTestStore_MarkSelfTestUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *SelfTestStore_MarkSelfTestUser_Call) Return(_a0 error) *SelfTestStore_MarkSelfTestUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SelfTestStore_MarkSelfTestUser_Call) RunAndReturn(run func(context.Context, int64, time.Time) error) *SelfTestStore_MarkSelfTestUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveSelfTestApp provides a mock function with given fields: ctx, name, secret, at
+func (_m *SelfTestStore) SaveSelfTestApp(ctx context.Context, name string, secret string, at time.Time) (int, error) {
+	ret := _m.Called(ctx, name, secret, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveSelfTestApp")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) (int, error)); ok {
+		return rf(ctx, name, secret, at)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, time.Time) int); ok {
+		r0 = rf(ctx, name, secret, at)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, time.Time) error); ok {
+		r1 = rf(ctx, name, secret, at)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SelfTestStore_SaveSelfTestApp_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveSelfTestApp'
+type SelfTestStore_SaveSelfTestApp_Call struct {
+	*mock.Call
+}
+
+// SaveSelfTestApp is a helper method to define mock.On call
+//   - ctx context.Context
+//   - name string
+//   - secret string
+//   - at time.Time
+func (_e *SelfTestStore_Expecter) SaveSelfTestApp(ctx interface{}, name interface{}, secret interface{}, at interface{}) *SelfTestStore_SaveSelfTestApp_Call {
+	return &SelfTestStore_SaveSelfTestApp_Call{Call: _e.mock.On("SaveSelfTestApp", ctx, name, secret, at)}
+}
+
+func (_c *SelfTestStore_SaveSelfTestApp_Call) Run(run func(ctx context.Context, name string, secret string, at time.Time)) *SelfTestStore_SaveSelfTestApp_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *SelfTestStore_SaveSelfTestApp_Call) Return(_a0 int, _a1 error) *SelfTestStore_SaveSelfTestApp_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *SelfTestStore_SaveSelfTestApp_Call) RunAndReturn(run func(context.Context, string, string, time.Time) (int, error)) *SelfTestStore_SaveSelfTestApp_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetEmailVerified provides a mock function with given fields: ctx, userID
+func (_m *SelfTestStore) SetEmailVerified(ctx context.Context, userID int64) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetEmailVerified")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SelfTestStore_SetEmailVerified_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetEmailVerified'
+type SelfTestStore_SetEmailVerified_Call struct {
+	*mock.Call
+}
+
+// SetEmailVerified is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *SelfTestStore_Expecter) SetEmailVerified(ctx interface{}, userID interface{}) *SelfTestStore_SetEmailVerified_Call {
+	return &SelfTestStore_SetEmailVerified_Call{Call: _e.mock.On("SetEmailVerified", ctx, userID)}
+}
+
+func (_c *SelfTestStore_SetEmailVerified_Call) Run(run func(ctx context.Context, userID int64)) *SelfTestStore_SetEmailVerified_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *SelfTestStore_SetEmailVerified_Call) Return(_a0 error) *SelfTestStore_SetEmailVerified_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SelfTestStore_SetEmailVerified_Call) RunAndReturn(run func(context.Context, int64) error) *SelfTestStore_SetEmailVerified_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewSelfTestStore creates a new instance of SelfTestStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSelfTestStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SelfTestStore {
+	mock := &SelfTestStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
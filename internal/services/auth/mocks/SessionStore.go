@@ -0,0 +1,254 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// SessionStore is an autogenerated mock type for the SessionStore type
+type SessionStore struct {
+	mock.Mock
+}
+
+type SessionStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *SessionStore) EXPECT() *SessionStore_Expecter {
+	return &SessionStore_Expecter{mock: &_m.Mock}
+}
+
+// CreateSession provides a mock function with given fields: ctx, s, limit, evictOldest
+func (_m *SessionStore) CreateSession(ctx context.Context, s models.Session, limit int, evictOldest bool) ([]string, error) {
+	ret := _m.Called(ctx, s, limit, evictOldest)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateSession")
+	}
+
+	var r0 []string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.Session, int, bool) ([]string, error)); ok {
+		return rf(ctx, s, limit, evictOldest)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.Session, int, bool) []string); ok {
+		r0 = rf(ctx, s, limit, evictOldest)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.Session, int, bool) error); ok {
+		r1 = rf(ctx, s, limit, evictOldest)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SessionStore_CreateSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSession'
+type SessionStore_CreateSession_Call struct {
+	*mock.Call
+}
+
+// CreateSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - s models.Session
+//   - limit int
+//   - evictOldest bool
+func (_e *SessionStore_Expecter) CreateSession(ctx interface{}, s interface{}, limit interface{}, evictOldest interface{}) *SessionStore_CreateSession_Call {
+	return &SessionStore_CreateSession_Call{Call: _e.mock.On("CreateSession", ctx, s, limit, evictOldest)}
+}
+
+func (_c *SessionStore_CreateSession_Call) Run(run func(ctx context.Context, s models.Session, limit int, evictOldest bool)) *SessionStore_CreateSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.Session), args[2].(int), args[3].(bool))
+	})
+	return _c
+}
+
+func (_c *SessionStore_CreateSession_Call) Return(evicted []string, err error) *SessionStore_CreateSession_Call {
+	_c.Call.Return(evicted, err)
+	return _c
+}
+
+func (_c *SessionStore_CreateSession_Call) RunAndReturn(run func(context.Context, models.Session, int, bool) ([]string, error)) *SessionStore_CreateSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeSession provides a mock function with given fields: ctx, id, at
+func (_m *SessionStore) RevokeSession(ctx context.Context, id string, at time.Time) error {
+	ret := _m.Called(ctx, id, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, id, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionStore_RevokeSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeSession'
+type SessionStore_RevokeSession_Call struct {
+	*mock.Call
+}
+
+// RevokeSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - at time.Time
+func (_e *SessionStore_Expecter) RevokeSession(ctx interface{}, id interface{}, at interface{}) *SessionStore_RevokeSession_Call {
+	return &SessionStore_RevokeSession_Call{Call: _e.mock.On("RevokeSession", ctx, id, at)}
+}
+
+func (_c *SessionStore_RevokeSession_Call) Run(run func(ctx context.Context, id string, at time.Time)) *SessionStore_RevokeSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time))
+	})
+	return _c
+}
+
+func (_c *SessionStore_RevokeSession_Call) Return(_a0 error) *SessionStore_RevokeSession_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SessionStore_RevokeSession_Call) RunAndReturn(run func(context.Context, string, time.Time) error) *SessionStore_RevokeSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Session provides a mock function with given fields: ctx, id
+func (_m *SessionStore) Session(ctx context.Context, id string) (models.Session, error) {
+	ret := _m.Called(ctx, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Session")
+	}
+
+	var r0 models.Session
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Session, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Session); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(models.Session)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SessionStore_Session_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Session'
+type SessionStore_Session_Call struct {
+	*mock.Call
+}
+
+// Session is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+func (_e *SessionStore_Expecter) Session(ctx interface{}, id interface{}) *SessionStore_Session_Call {
+	return &SessionStore_Session_Call{Call: _e.mock.On("Session", ctx, id)}
+}
+
+func (_c *SessionStore_Session_Call) Run(run func(ctx context.Context, id string)) *SessionStore_Session_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *SessionStore_Session_Call) Return(_a0 models.Session, _a1 error) *SessionStore_Session_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *SessionStore_Session_Call) RunAndReturn(run func(context.Context, string) (models.Session, error)) *SessionStore_Session_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchSession provides a mock function with given fields: ctx, id, at, throttle
+func (_m *SessionStore) TouchSession(ctx context.Context, id string, at time.Time, throttle time.Duration) error {
+	ret := _m.Called(ctx, id, at, throttle)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchSession")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time, time.Duration) error); ok {
+		r0 = rf(ctx, id, at, throttle)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SessionStore_TouchSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchSession'
+type SessionStore_TouchSession_Call struct {
+	*mock.Call
+}
+
+// TouchSession is a helper method to define mock.On call
+//   - ctx context.Context
+//   - id string
+//   - at time.Time
+//   - throttle time.Duration
+func (_e *SessionStore_Expecter) TouchSession(ctx interface{}, id interface{}, at interface{}, throttle interface{}) *SessionStore_TouchSession_Call {
+	return &SessionStore_TouchSession_Call{Call: _e.mock.On("TouchSession", ctx, id, at, throttle)}
+}
+
+func (_c *SessionStore_TouchSession_Call) Run(run func(ctx context.Context, id string, at time.Time, throttle time.Duration)) *SessionStore_TouchSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(time.Time), args[3].(time.Duration))
+	})
+	return _c
+}
+
+func (_c *SessionStore_TouchSession_Call) Return(_a0 error) *SessionStore_TouchSession_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *SessionStore_TouchSession_Call) RunAndReturn(run func(context.Context, string, time.Time, time.Duration) error) *SessionStore_TouchSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewSessionStore creates a new instance of SessionStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewSessionStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *SessionStore {
+	mock := &SessionStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,203 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TOSStore is an autogenerated mock type for the TOSStore type
+type TOSStore struct {
+	mock.Mock
+}
+
+type TOSStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *TOSStore) EXPECT() *TOSStore_Expecter {
+	return &TOSStore_Expecter{mock: &_m.Mock}
+}
+
+// AcceptTOS provides a mock function with given fields: ctx, userID, version
+func (_m *TOSStore) AcceptTOS(ctx context.Context, userID int64, version string) error {
+	ret := _m.Called(ctx, userID, version)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AcceptTOS")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, version)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TOSStore_AcceptTOS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AcceptTOS'
+type TOSStore_AcceptTOS_Call struct {
+	*mock.Call
+}
+
+// AcceptTOS is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - version string
+func (_e *TOSStore_Expecter) AcceptTOS(ctx interface{}, userID interface{}, version interface{}) *TOSStore_AcceptTOS_Call {
+	return &TOSStore_AcceptTOS_Call{Call: _e.mock.On("AcceptTOS", ctx, userID, version)}
+}
+
+func (_c *TOSStore_AcceptTOS_Call) Run(run func(ctx context.Context, userID int64, version string)) *TOSStore_AcceptTOS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *TOSStore_AcceptTOS_Call) Return(_a0 error) *TOSStore_AcceptTOS_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *TOSStore_AcceptTOS_Call) RunAndReturn(run func(context.Context, int64, string) error) *TOSStore_AcceptTOS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LatestTOSAcceptance provides a mock function with given fields: ctx, userID
+func (_m *TOSStore) LatestTOSAcceptance(ctx context.Context, userID int64) (models.TOSAcceptance, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LatestTOSAcceptance")
+	}
+
+	var r0 models.TOSAcceptance
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (models.TOSAcceptance, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) models.TOSAcceptance); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(models.TOSAcceptance)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TOSStore_LatestTOSAcceptance_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LatestTOSAcceptance'
+type TOSStore_LatestTOSAcceptance_Call struct {
+	*mock.Call
+}
+
+// LatestTOSAcceptance is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *TOSStore_Expecter) LatestTOSAcceptance(ctx interface{}, userID interface{}) *TOSStore_LatestTOSAcceptance_Call {
+	return &TOSStore_LatestTOSAcceptance_Call{Call: _e.mock.On("LatestTOSAcceptance", ctx, userID)}
+}
+
+func (_c *TOSStore_LatestTOSAcceptance_Call) Run(run func(ctx context.Context, userID int64)) *TOSStore_LatestTOSAcceptance_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *TOSStore_LatestTOSAcceptance_Call) Return(_a0 models.TOSAcceptance, _a1 error) *TOSStore_LatestTOSAcceptance_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *TOSStore_LatestTOSAcceptance_Call) RunAndReturn(run func(context.Context, int64) (models.TOSAcceptance, error)) *TOSStore_LatestTOSAcceptance_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UsersPendingTOS provides a mock function with given fields: ctx, version, afterID, limit
+func (_m *TOSStore) UsersPendingTOS(ctx context.Context, version string, afterID int64, limit int) ([]models.PendingTOS, error) {
+	ret := _m.Called(ctx, version, afterID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UsersPendingTOS")
+	}
+
+	var r0 []models.PendingTOS
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) ([]models.PendingTOS, error)); ok {
+		return rf(ctx, version, afterID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, int64, int) []models.PendingTOS); ok {
+		r0 = rf(ctx, version, afterID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.PendingTOS)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, int64, int) error); ok {
+		r1 = rf(ctx, version, afterID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TOSStore_UsersPendingTOS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UsersPendingTOS'
+type TOSStore_UsersPendingTOS_Call struct {
+	*mock.Call
+}
+
+// UsersPendingTOS is a helper method to define mock.On call
+//   - ctx context.Context
+//   - version string
+//   - afterID int64
+//   - limit int
+func (_e *TOSStore_Expecter) UsersPendingTOS(ctx interface{}, version interface{}, afterID interface{}, limit interface{}) *TOSStore_UsersPendingTOS_Call {
+	return &TOSStore_UsersPendingTOS_Call{Call: _e.mock.On("UsersPendingTOS", ctx, version, afterID, limit)}
+}
+
+func (_c *TOSStore_UsersPendingTOS_Call) Run(run func(ctx context.Context, version string, afterID int64, limit int)) *TOSStore_UsersPendingTOS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].(int64), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *TOSStore_UsersPendingTOS_Call) Return(_a0 []models.PendingTOS, _a1 error) *TOSStore_UsersPendingTOS_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *TOSStore_UsersPendingTOS_Call) RunAndReturn(run func(context.Context, string, int64, int) ([]models.PendingTOS, error)) *TOSStore_UsersPendingTOS_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewTOSStore creates a new instance of TOSStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTOSStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TOSStore {
+	mock := &TOSStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
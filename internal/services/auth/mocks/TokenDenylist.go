@@ -0,0 +1,141 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// TokenDenylist is an autogenerated mock type for the TokenDenylist type
+type TokenDenylist struct {
+	mock.Mock
+}
+
+type TokenDenylist_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *TokenDenylist) EXPECT() *TokenDenylist_Expecter {
+	return &TokenDenylist_Expecter{mock: &_m.Mock}
+}
+
+// DenyToken provides a mock function with given fields: ctx, t
+func (_m *TokenDenylist) DenyToken(ctx context.Context, t models.DeniedToken) error {
+	ret := _m.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DenyToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.DeniedToken) error); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TokenDenylist_DenyToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DenyToken'
+type TokenDenylist_DenyToken_Call struct {
+	*mock.Call
+}
+
+// DenyToken is a helper method to define mock.On call
+//   - ctx context.Context
+//   - t models.DeniedToken
+func (_e *TokenDenylist_Expecter) DenyToken(ctx interface{}, t interface{}) *TokenDenylist_DenyToken_Call {
+	return &TokenDenylist_DenyToken_Call{Call: _e.mock.On("DenyToken", ctx, t)}
+}
+
+func (_c *TokenDenylist_DenyToken_Call) Run(run func(ctx context.Context, t models.DeniedToken)) *TokenDenylist_DenyToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(models.DeniedToken))
+	})
+	return _c
+}
+
+func (_c *TokenDenylist_DenyToken_Call) Return(_a0 error) *TokenDenylist_DenyToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *TokenDenylist_DenyToken_Call) RunAndReturn(run func(context.Context, models.DeniedToken) error) *TokenDenylist_DenyToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TokenDenied provides a mock function with given fields: ctx, jti
+func (_m *TokenDenylist) TokenDenied(ctx context.Context, jti string) (bool, error) {
+	ret := _m.Called(ctx, jti)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TokenDenied")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, jti)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, jti)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, jti)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TokenDenylist_TokenDenied_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TokenDenied'
+type TokenDenylist_TokenDenied_Call struct {
+	*mock.Call
+}
+
+// TokenDenied is a helper method to define mock.On call
+//   - ctx context.Context
+//   - jti string
+func (_e *TokenDenylist_Expecter) TokenDenied(ctx interface{}, jti interface{}) *TokenDenylist_TokenDenied_Call {
+	return &TokenDenylist_TokenDenied_Call{Call: _e.mock.On("TokenDenied", ctx, jti)}
+}
+
+func (_c *TokenDenylist_TokenDenied_Call) Run(run func(ctx context.Context, jti string)) *TokenDenylist_TokenDenied_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *TokenDenylist_TokenDenied_Call) Return(_a0 bool, _a1 error) *TokenDenylist_TokenDenied_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *TokenDenylist_TokenDenied_Call) RunAndReturn(run func(context.Context, string) (bool, error)) *TokenDenylist_TokenDenied_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewTokenDenylist creates a new instance of TokenDenylist. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTokenDenylist(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *TokenDenylist {
+	mock := &TokenDenylist{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,145 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserAppStore is an autogenerated mock type for the UserAppStore type
+type UserAppStore struct {
+	mock.Mock
+}
+
+type UserAppStore_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *UserAppStore) EXPECT() *UserAppStore_Expecter {
+	return &UserAppStore_Expecter{mock: &_m.Mock}
+}
+
+// AddUserApp provides a mock function with given fields: ctx, userID, appID, at
+func (_m *UserAppStore) AddUserApp(ctx context.Context, userID int64, appID int, at time.Time) error {
+	ret := _m.Called(ctx, userID, appID, at)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddUserApp")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int, time.Time) error); ok {
+		r0 = rf(ctx, userID, appID, at)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserAppStore_AddUserApp_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddUserApp'
+type UserAppStore_AddUserApp_Call struct {
+	*mock.Call
+}
+
+// AddUserApp is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - appID int
+//   - at time.Time
+func (_e *UserAppStore_Expecter) AddUserApp(ctx interface{}, userID interface{}, appID interface{}, at interface{}) *UserAppStore_AddUserApp_Call {
+	return &UserAppStore_AddUserApp_Call{Call: _e.mock.On("AddUserApp", ctx, userID, appID, at)}
+}
+
+func (_c *UserAppStore_AddUserApp_Call) Run(run func(ctx context.Context, userID int64, appID int, at time.Time)) *UserAppStore_AddUserApp_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int), args[3].(time.Time))
+	})
+	return _c
+}
+
+func (_c *UserAppStore_AddUserApp_Call) Return(_a0 error) *UserAppStore_AddUserApp_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserAppStore_AddUserApp_Call) RunAndReturn(run func(context.Context, int64, int, time.Time) error) *UserAppStore_AddUserApp_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UserApps provides a mock function with given fields: ctx, userID
+func (_m *UserAppStore) UserApps(ctx context.Context, userID int64) ([]int, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserApps")
+	}
+
+	var r0 []int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]int, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []int); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]int)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserAppStore_UserApps_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UserApps'
+type UserAppStore_UserApps_Call struct {
+	*mock.Call
+}
+
+// UserApps is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *UserAppStore_Expecter) UserApps(ctx interface{}, userID interface{}) *UserAppStore_UserApps_Call {
+	return &UserAppStore_UserApps_Call{Call: _e.mock.On("UserApps", ctx, userID)}
+}
+
+func (_c *UserAppStore_UserApps_Call) Run(run func(ctx context.Context, userID int64)) *UserAppStore_UserApps_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserAppStore_UserApps_Call) Return(_a0 []int, _a1 error) *UserAppStore_UserApps_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserAppStore_UserApps_Call) RunAndReturn(run func(context.Context, int64) ([]int, error)) *UserAppStore_UserApps_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewUserAppStore creates a new instance of UserAppStore. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserAppStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserAppStore {
+	mock := &UserAppStore{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
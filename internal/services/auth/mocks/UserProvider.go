@@ -0,0 +1,443 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "sso/internal/domain/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserProvider is an autogenerated mock type for the UserProvider type
+type UserProvider struct {
+	mock.Mock
+}
+
+type UserProvider_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *UserProvider) EXPECT() *UserProvider_Expecter {
+	return &UserProvider_Expecter{mock: &_m.Mock}
+}
+
+// IsAdmin provides a mock function with given fields: ctx, userID
+func (_m *UserProvider) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsAdmin")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserProvider_IsAdmin_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsAdmin'
+type UserProvider_IsAdmin_Call struct {
+	*mock.Call
+}
+
+// IsAdmin is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *UserProvider_Expecter) IsAdmin(ctx interface{}, userID interface{}) *UserProvider_IsAdmin_Call {
+	return &UserProvider_IsAdmin_Call{Call: _e.mock.On("IsAdmin", ctx, userID)}
+}
+
+func (_c *UserProvider_IsAdmin_Call) Run(run func(ctx context.Context, userID int64)) *UserProvider_IsAdmin_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserProvider_IsAdmin_Call) Return(_a0 bool, _a1 error) *UserProvider_IsAdmin_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserProvider_IsAdmin_Call) RunAndReturn(run func(context.Context, int64) (bool, error)) *UserProvider_IsAdmin_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsUserExists provides a mock function with given fields: ctx, userID
+func (_m *UserProvider) IsUserExists(ctx context.Context, userID int64) (bool, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsUserExists")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (bool, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) bool); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserProvider_IsUserExists_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsUserExists'
+type UserProvider_IsUserExists_Call struct {
+	*mock.Call
+}
+
+// IsUserExists is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *UserProvider_Expecter) IsUserExists(ctx interface{}, userID interface{}) *UserProvider_IsUserExists_Call {
+	return &UserProvider_IsUserExists_Call{Call: _e.mock.On("IsUserExists", ctx, userID)}
+}
+
+func (_c *UserProvider_IsUserExists_Call) Run(run func(ctx context.Context, userID int64)) *UserProvider_IsUserExists_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserProvider_IsUserExists_Call) Return(_a0 bool, _a1 error) *UserProvider_IsUserExists_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserProvider_IsUserExists_Call) RunAndReturn(run func(context.Context, int64) (bool, error)) *UserProvider_IsUserExists_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PasswordHistory provides a mock function with given fields: ctx, userID, limit
+func (_m *UserProvider) PasswordHistory(ctx context.Context, userID int64, limit int) ([][]byte, error) {
+	ret := _m.Called(ctx, userID, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PasswordHistory")
+	}
+
+	var r0 [][]byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) ([][]byte, error)); ok {
+		return rf(ctx, userID, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, int) [][]byte); ok {
+		r0 = rf(ctx, userID, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([][]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, int) error); ok {
+		r1 = rf(ctx, userID, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserProvider_PasswordHistory_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PasswordHistory'
+type UserProvider_PasswordHistory_Call struct {
+	*mock.Call
+}
+
+// PasswordHistory is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - limit int
+func (_e *UserProvider_Expecter) PasswordHistory(ctx interface{}, userID interface{}, limit interface{}) *UserProvider_PasswordHistory_Call {
+	return &UserProvider_PasswordHistory_Call{Call: _e.mock.On("PasswordHistory", ctx, userID, limit)}
+}
+
+func (_c *UserProvider_PasswordHistory_Call) Run(run func(ctx context.Context, userID int64, limit int)) *UserProvider_PasswordHistory_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *UserProvider_PasswordHistory_Call) Return(_a0 [][]byte, _a1 error) *UserProvider_PasswordHistory_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserProvider_PasswordHistory_Call) RunAndReturn(run func(context.Context, int64, int) ([][]byte, error)) *UserProvider_PasswordHistory_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// User provides a mock function with given fields: ctx, email
+func (_m *UserProvider) User(ctx context.Context, email string) (models.User, error) {
+	ret := _m.Called(ctx, email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for User")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.User, error)); ok {
+		return rf(ctx, email)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.User); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, email)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserProvider_User_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'User'
+type UserProvider_User_Call struct {
+	*mock.Call
+}
+
+// User is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+func (_e *UserProvider_Expecter) User(ctx interface{}, email interface{}) *UserProvider_User_Call {
+	return &UserProvider_User_Call{Call: _e.mock.On("User", ctx, email)}
+}
+
+func (_c *UserProvider_User_Call) Run(run func(ctx context.Context, email string)) *UserProvider_User_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *UserProvider_User_Call) Return(_a0 models.User, _a1 error) *UserProvider_User_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserProvider_User_Call) RunAndReturn(run func(context.Context, string) (models.User, error)) *UserProvider_User_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UserByID provides a mock function with given fields: ctx, userID
+func (_m *UserProvider) UserByID(ctx context.Context, userID int64) (models.User, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserByID")
+	}
+
+	var r0 models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (models.User, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) models.User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(models.User)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserProvider_UserByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UserByID'
+type UserProvider_UserByID_Call struct {
+	*mock.Call
+}
+
+// UserByID is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *UserProvider_Expecter) UserByID(ctx interface{}, userID interface{}) *UserProvider_UserByID_Call {
+	return &UserProvider_UserByID_Call{Call: _e.mock.On("UserByID", ctx, userID)}
+}
+
+func (_c *UserProvider_UserByID_Call) Run(run func(ctx context.Context, userID int64)) *UserProvider_UserByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserProvider_UserByID_Call) Return(_a0 models.User, _a1 error) *UserProvider_UserByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserProvider_UserByID_Call) RunAndReturn(run func(context.Context, int64) (models.User, error)) *UserProvider_UserByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UserMetadata provides a mock function with given fields: ctx, userID
+func (_m *UserProvider) UserMetadata(ctx context.Context, userID int64) ([]byte, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserMetadata")
+	}
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) ([]byte, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) []byte); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserProvider_UserMetadata_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UserMetadata'
+type UserProvider_UserMetadata_Call struct {
+	*mock.Call
+}
+
+// UserMetadata is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+func (_e *UserProvider_Expecter) UserMetadata(ctx interface{}, userID interface{}) *UserProvider_UserMetadata_Call {
+	return &UserProvider_UserMetadata_Call{Call: _e.mock.On("UserMetadata", ctx, userID)}
+}
+
+func (_c *UserProvider_UserMetadata_Call) Run(run func(ctx context.Context, userID int64)) *UserProvider_UserMetadata_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64))
+	})
+	return _c
+}
+
+func (_c *UserProvider_UserMetadata_Call) Return(_a0 []byte, _a1 error) *UserProvider_UserMetadata_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserProvider_UserMetadata_Call) RunAndReturn(run func(context.Context, int64) ([]byte, error)) *UserProvider_UserMetadata_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UsersByIDs provides a mock function with given fields: ctx, userIDs
+func (_m *UserProvider) UsersByIDs(ctx context.Context, userIDs []int64) ([]models.User, error) {
+	ret := _m.Called(ctx, userIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UsersByIDs")
+	}
+
+	var r0 []models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) ([]models.User, error)); ok {
+		return rf(ctx, userIDs)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int64) []models.User); ok {
+		r0 = rf(ctx, userIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int64) error); ok {
+		r1 = rf(ctx, userIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserProvider_UsersByIDs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UsersByIDs'
+type UserProvider_UsersByIDs_Call struct {
+	*mock.Call
+}
+
+// UsersByIDs is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userIDs []int64
+func (_e *UserProvider_Expecter) UsersByIDs(ctx interface{}, userIDs interface{}) *UserProvider_UsersByIDs_Call {
+	return &UserProvider_UsersByIDs_Call{Call: _e.mock.On("UsersByIDs", ctx, userIDs)}
+}
+
+func (_c *UserProvider_UsersByIDs_Call) Run(run func(ctx context.Context, userIDs []int64)) *UserProvider_UsersByIDs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].([]int64))
+	})
+	return _c
+}
+
+func (_c *UserProvider_UsersByIDs_Call) Return(_a0 []models.User, _a1 error) *UserProvider_UsersByIDs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *UserProvider_UsersByIDs_Call) RunAndReturn(run func(context.Context, []int64) ([]models.User, error)) *UserProvider_UsersByIDs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewUserProvider creates a new instance of UserProvider. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserProvider(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserProvider {
+	mock := &UserProvider{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,240 @@
+// Code generated by mockery. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UserSaver is an autogenerated mock type for the UserSaver type
+type UserSaver struct {
+	mock.Mock
+}
+
+type UserSaver_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *UserSaver) EXPECT() *UserSaver_Expecter {
+	return &UserSaver_Expecter{mock: &_m.Mock}
+}
+
+// EraseUser provides a mock function with given fields: ctx, userID, tombstone
+func (_m *UserSaver) EraseUser(ctx context.Context, userID int64, tombstone string) error {
+	ret := _m.Called(ctx, userID, tombstone)
+
+	if len(ret) == 0 {
+		panic("no return value specified for EraseUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string) error); ok {
+		r0 = rf(ctx, userID, tombstone)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSaver_EraseUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'EraseUser'
+type UserSaver_EraseUser_Call struct {
+	*mock.Call
+}
+
+// EraseUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - tombstone string
+func (_e *UserSaver_Expecter) EraseUser(ctx interface{}, userID interface{}, tombstone interface{}) *UserSaver_EraseUser_Call {
+	return &UserSaver_EraseUser_Call{Call: _e.mock.On("EraseUser", ctx, userID, tombstone)}
+}
+
+func (_c *UserSaver_EraseUser_Call) Run(run func(ctx context.Context, userID int64, tombstone string)) *UserSaver_EraseUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *UserSaver_EraseUser_Call) Return(_a0 error) *UserSaver_EraseUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserSaver_EraseUser_Call) RunAndReturn(run func(context.Context, int64, string) error) *UserSaver_EraseUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RehashPassword provides a mock function with given fields: ctx, userID, oldHash, newHash
+func (_m *UserSaver) RehashPassword(ctx context.Context, userID int64, oldHash []byte, newHash []byte) error {
+	ret := _m.Called(ctx, userID, oldHash, newHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RehashPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []byte, []byte) error); ok {
+		r0 = rf(ctx, userID, oldHash, newHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSaver_RehashPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RehashPassword'
+type UserSaver_RehashPassword_Call struct {
+	*mock.Call
+}
+
+// RehashPassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - oldHash []byte
+//   - newHash []byte
+func (_e *UserSaver_Expecter) RehashPassword(ctx interface{}, userID interface{}, oldHash interface{}, newHash interface{}) *UserSaver_RehashPassword_Call {
+	return &UserSaver_RehashPassword_Call{Call: _e.mock.On("RehashPassword", ctx, userID, oldHash, newHash)}
+}
+
+func (_c *UserSaver_RehashPassword_Call) Run(run func(ctx context.Context, userID int64, oldHash []byte, newHash []byte)) *UserSaver_RehashPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].([]byte), args[3].([]byte))
+	})
+	return _c
+}
+
+func (_c *UserSaver_RehashPassword_Call) Return(_a0 error) *UserSaver_RehashPassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserSaver_RehashPassword_Call) RunAndReturn(run func(context.Context, int64, []byte, []byte) error) *UserSaver_RehashPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveUser provides a mock function with given fields: ctx, email, passHash
+func (_m *UserSaver) SaveUser(ctx context.Context, email string, passHash []byte) (int64, error) {
+	ret := _m.Called(ctx, email, passHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveUser")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) (int64, error)); ok {
+		return rf(ctx, email, passHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, []byte) int64); ok {
+		r0 = rf(ctx, email, passHash)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, []byte) error); ok {
+		r1 = rf(ctx, email, passHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UserSaver_SaveUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveUser'
+type UserSaver_SaveUser_Call struct {
+	*mock.Call
+}
+
+// SaveUser is a helper method to define mock.On call
+//   - ctx context.Context
+//   - email string
+//   - passHash []byte
+func (_e *UserSaver_Expecter) SaveUser(ctx interface{}, email interface{}, passHash interface{}) *UserSaver_SaveUser_Call {
+	return &UserSaver_SaveUser_Call{Call: _e.mock.On("SaveUser", ctx, email, passHash)}
+}
+
+func (_c *UserSaver_SaveUser_Call) Run(run func(ctx context.Context, email string, passHash []byte)) *UserSaver_SaveUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string), args[2].([]byte))
+	})
+	return _c
+}
+
+func (_c *UserSaver_SaveUser_Call) Return(uid int64, err error) *UserSaver_SaveUser_Call {
+	_c.Call.Return(uid, err)
+	return _c
+}
+
+func (_c *UserSaver_SaveUser_Call) RunAndReturn(run func(context.Context, string, []byte) (int64, error)) *UserSaver_SaveUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdatePassword provides a mock function with given fields: ctx, userID, passHash, keep
+func (_m *UserSaver) UpdatePassword(ctx context.Context, userID int64, passHash []byte, keep int) error {
+	ret := _m.Called(ctx, userID, passHash, keep)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, []byte, int) error); ok {
+		r0 = rf(ctx, userID, passHash, keep)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UserSaver_UpdatePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdatePassword'
+type UserSaver_UpdatePassword_Call struct {
+	*mock.Call
+}
+
+// UpdatePassword is a helper method to define mock.On call
+//   - ctx context.Context
+//   - userID int64
+//   - passHash []byte
+//   - keep int
+func (_e *UserSaver_Expecter) UpdatePassword(ctx interface{}, userID interface{}, passHash interface{}, keep interface{}) *UserSaver_UpdatePassword_Call {
+	return &UserSaver_UpdatePassword_Call{Call: _e.mock.On("UpdatePassword", ctx, userID, passHash, keep)}
+}
+
+func (_c *UserSaver_UpdatePassword_Call) Run(run func(ctx context.Context, userID int64, passHash []byte, keep int)) *UserSaver_UpdatePassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(int64), args[2].([]byte), args[3].(int))
+	})
+	return _c
+}
+
+func (_c *UserSaver_UpdatePassword_Call) Return(_a0 error) *UserSaver_UpdatePassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *UserSaver_UpdatePassword_Call) RunAndReturn(run func(context.Context, int64, []byte, int) error) *UserSaver_UpdatePassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewUserSaver creates a new instance of UserSaver. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUserSaver(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UserSaver {
+	mock := &UserSaver{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
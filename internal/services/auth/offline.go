@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/events"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/useragent"
+	"sso/internal/storage"
+	"time"
+)
+
+// Значения по умолчанию для WithOfflineTokens
+const (
+	DefaultOfflineTokenTTL   = 90 * 24 * time.Hour // Срок offline-токена
+	DefaultOfflineMFAMaxAge  = 5 * time.Minute     // Насколько свежим должен быть вход со вторым фактором
+	DefaultOfflineTokenScope = "offline_sync"      // Право offline-токена, если права не заданы
+)
+
+// Ошибки offline-токенов
+var (
+	ErrOfflineTokensDisabled    = errors.New("offline tokens are disabled")                     // Ошибка, если хранилище offline-токенов не задано.
+	ErrOfflineTokensNotAccepted = errors.New("app does not accept offline tokens")              // Ошибка, если приложение не согласилось принимать offline-токены.
+	ErrFreshMFARequired         = errors.New("a recent login with a second factor is required") // Ошибка, если вход вызывающего без второго фактора или давний.
+	ErrOfflineTokenNotFound     = errors.New("offline token not found")                         // Ошибка, если offline-токена нет, он чужой или уже отозван.
+)
+
+// OfflineTokenStore - хранилище offline-токенов фоновых агентов
+type OfflineTokenStore interface {
+	// SaveOfflineToken - сохраняет выданный токен.
+	SaveOfflineToken(ctx context.Context, t models.OfflineToken) error
+	// OfflineToken - токен по jti, включая отозванные (storage.ErrOfflineTokenNotFound, если его нет).
+	OfflineToken(ctx context.Context, id string) (models.OfflineToken, error)
+	// OfflineTokens - действующие на момент now токены пользователя, от новых к старым.
+	OfflineTokens(ctx context.Context, userID int64, now time.Time) ([]models.OfflineToken, error)
+	// RevokeOfflineToken - отзывает действующий токен пользователя (storage.ErrOfflineTokenNotFound, если его нет).
+	RevokeOfflineToken(ctx context.Context, userID int64, id string, at time.Time) error
+}
+
+// OfflineTokenSettings - параметры offline-токенов; нулевые значения заменяются значениями по умолчанию
+type OfflineTokenSettings struct {
+	TTL       time.Duration // Срок действия токена
+	Scopes    []string      // Права токена (клейм scope); у токена входа прав не ограничено
+	MFAMaxAge time.Duration // Выдача требует входа со вторым фактором не раньше этого срока
+}
+
+// WithOfflineTokens - включает IssueOfflineToken: долгоживущие токены фоновых агентов с клеймом offline
+// и узкими правами. Приложение принимает их, только если согласилось (models.App.AcceptOfflineTokens)
+func WithOfflineTokens(store OfflineTokenStore, s OfflineTokenSettings) Option {
+	return func(a *AuthService) {
+		if s.TTL <= 0 {
+			s.TTL = DefaultOfflineTokenTTL
+		}
+		if len(s.Scopes) == 0 {
+			s.Scopes = []string{DefaultOfflineTokenScope}
+		}
+		if s.MFAMaxAge <= 0 {
+			s.MFAMaxAge = DefaultOfflineMFAMaxAge
+		}
+		a.offline = store
+		a.offlineSettings = s
+	}
+}
+
+// IssueOfflineToken - выдаёт пользователю userID offline-токен приложения appID с подписью label.
+// caller - вызывающий: его вход должен быть подтверждён вторым фактором не раньше MFAMaxAge назад
+// (ErrFreshMFARequired), а сам offline-токен выдать ещё один не может. Выдача попадает в историю входов
+// с пометкой offline. Права вызывающего на userID проверяет обработчик
+func (a *AuthService) IssueOfflineToken(
+	ctx context.Context,
+	caller authctx.Principal,
+	userID int64,
+	appID int,
+	label string,
+) (models.OfflineToken, models.Token, error) {
+	const op = "Auth.IssueOfflineToken"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.Int("app_id", appID))
+
+	if a.offline == nil {
+		return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, ErrOfflineTokensDisabled)
+	}
+
+	now := a.clock.Now()
+	if caller.Offline || caller.ACR != models.ACRMultiFactor || caller.AuthTime.IsZero() ||
+		now.Sub(caller.AuthTime) > a.offlineSettings.MFAMaxAge {
+		return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, ErrFreshMFARequired)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !app.AcceptOfflineTokens {
+		return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, ErrOfflineTokensNotAccepted)
+	}
+
+	scopes := slices.Clone(a.offlineSettings.Scopes)
+	token, claims, err := a.issueTokenTTL(ctx, user, app, a.offlineSettings.TTL, jwt.AsOffline(), jwt.WithScope(scopes))
+	if err != nil {
+		log.Error("failed to create offline token", slog.String("error", err.Error()))
+
+		return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	offline := models.OfflineToken{
+		ID:        claims.ID,
+		UserID:    user.ID,
+		AppID:     app.ID,
+		Label:     label,
+		Scopes:    scopes,
+		CreatedAt: claims.IssuedAt.Time,
+		ExpiresAt: token.ExpiresAt,
+	}
+	if err := a.offline.SaveOfflineToken(ctx, offline); err != nil {
+		log.Error("failed to save offline token", slog.String("error", err.Error()))
+
+		return models.OfflineToken{}, models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// В истории входов выдача видна отдельно от интерактивных входов; ошибка записи выдачу не отменяет
+	if a.loginHistory != nil {
+		record := models.LoginRecord{
+			UserID: user.ID, AppID: app.ID, Device: useragent.Fingerprint(useragent.From(ctx)),
+			Offline: true, LoggedInAt: offline.CreatedAt,
+		}
+		if err := a.loginHistory.AddLogin(ctx, record); err != nil {
+			log.Error("failed to record offline token in login history", slog.String("error", err.Error()))
+		}
+	}
+
+	log.Info("offline token issued", slog.String("token_id", offline.ID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventOfflineTokenIssued, Outcome: audit.OutcomeSuccess,
+		UserID: user.ID, Email: user.Email, AppID: app.ID,
+	})
+
+	return offline, token, nil
+}
+
+// ListOfflineTokens - действующие offline-токены пользователя, от новых к старым
+func (a *AuthService) ListOfflineTokens(ctx context.Context, userID int64) ([]models.OfflineToken, error) {
+	const op = "Auth.ListOfflineTokens"
+
+	if a.offline == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrOfflineTokensDisabled)
+	}
+
+	tokens, err := a.offline.OfflineTokens(ctx, userID, a.clock.Now())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tokens, nil
+}
+
+// RevokeOfflineToken - отзывает offline-токен id пользователя userID; токен перестаёт действовать сразу.
+// Нет такого, он чужой или уже отозван - ErrOfflineTokenNotFound
+func (a *AuthService) RevokeOfflineToken(ctx context.Context, userID int64, id string) error {
+	const op = "Auth.RevokeOfflineToken"
+
+	if a.offline == nil {
+		return fmt.Errorf("%s: %w", op, ErrOfflineTokensDisabled)
+	}
+
+	t, err := a.offline.OfflineToken(ctx, id)
+	switch {
+	case errors.Is(err, storage.ErrOfflineTokenNotFound) || (err == nil && t.UserID != userID):
+		return fmt.Errorf("%s: %w", op, ErrOfflineTokenNotFound)
+	case err != nil:
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.revokeOfflineToken(ctx, t); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// revokeOfflineToken - отзывает offline-токен t и сообщает об этом потребителям отзывов
+func (a *AuthService) revokeOfflineToken(ctx context.Context, t models.OfflineToken) error {
+	if err := a.offline.RevokeOfflineToken(ctx, t.UserID, t.ID, a.clock.Now()); err != nil {
+		if errors.Is(err, storage.ErrOfflineTokenNotFound) {
+			return ErrOfflineTokenNotFound
+		}
+
+		return err
+	}
+
+	logctx.FromOr(ctx, a.log).Info("offline token revoked",
+		slog.Int64("user_id", t.UserID),
+		slog.Int("app_id", t.AppID),
+		slog.String("token_id", t.ID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventOfflineTokenRevoked, Outcome: audit.OutcomeSuccess,
+		UserID: t.UserID, AppID: t.AppID,
+	})
+
+	revoked := events.New(ctx, events.TypeTokenRevoked, t.UserID)
+	revoked.AppID = t.AppID
+	a.publish(ctx, revoked)
+	a.recordRevocation(ctx, models.Revocation{
+		Kind: models.RevocationToken, UserID: t.UserID, AppID: t.AppID, TokenID: t.ID,
+	})
+
+	return nil
+}
+
+// checkOfflineToken - действует ли offline-токен jti пользователя userID в приложении app:
+// приложение должно принимать такие токены, а токен - быть выданным и не отозванным
+func (a *AuthService) checkOfflineToken(ctx context.Context, jti string, userID int64, app models.App) error {
+	if a.offline == nil {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, ErrOfflineTokensDisabled)
+	}
+	if !app.AcceptOfflineTokens {
+		return fmt.Errorf("%w: %w", ErrInvalidToken, ErrOfflineTokensNotAccepted)
+	}
+
+	t, err := a.offline.OfflineToken(ctx, jti)
+	if err != nil {
+		if errors.Is(err, storage.ErrOfflineTokenNotFound) {
+			return fmt.Errorf("%w: offline token not found", ErrInvalidToken)
+		}
+
+		return err
+	}
+	if t.UserID != userID || t.AppID != app.ID {
+		return fmt.Errorf("%w: offline token does not match", ErrInvalidToken)
+	}
+	if !t.Active(a.clock.Now()) {
+		return fmt.Errorf("%w: offline token revoked or expired", ErrInvalidToken)
+	}
+
+	return nil
+}
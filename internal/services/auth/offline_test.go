@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// freshMFA - вызывающий, только что подтвердивший вход вторым фактором
+func freshMFA(userID int64) authctx.Principal {
+	return authctx.Principal{UserID: userID, AppID: testApp.ID, ACR: models.ACRMultiFactor, AuthTime: time.Now().Add(-time.Minute)}
+}
+
+func TestIssueOfflineToken(t *testing.T) {
+	store := mocks.NewOfflineTokenStore(t)
+	history := mocks.NewLoginHistoryStore(t)
+	a, _, provider, apps := newTestService(t,
+		WithOfflineTokens(store, OfflineTokenSettings{}), WithLoginHistory(history, LoginHistorySettings{}))
+
+	agent := testApp
+	agent.AcceptOfflineTokens = true
+	user := models.User{ID: 7, Email: "a@b.c", IsActive: true}
+
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+	apps.EXPECT().App(mock.Anything, agent.ID).Return(agent, nil)
+
+	var saved models.OfflineToken
+	store.EXPECT().SaveOfflineToken(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, t models.OfflineToken) error {
+			saved = t
+			return nil
+		})
+	history.EXPECT().AddLogin(mock.Anything, mock.MatchedBy(func(r models.LoginRecord) bool {
+		return r.UserID == user.ID && r.AppID == agent.ID && r.Offline
+	})).Return(nil)
+
+	offline, token, err := a.IssueOfflineToken(context.Background(), freshMFA(user.ID), user.ID, agent.ID, "Laptop sync")
+	require.NoError(t, err)
+	assert.Equal(t, saved, offline)
+	assert.Equal(t, "Laptop sync", offline.Label)
+	assert.Equal(t, []string{DefaultOfflineTokenScope}, offline.Scopes)
+	assert.WithinDuration(t, time.Now().Add(DefaultOfflineTokenTTL), offline.ExpiresAt, 2*time.Second)
+
+	claims, err := jwt.ParseForApp(token.AccessToken, agent)
+	require.NoError(t, err)
+	assert.True(t, claims.Offline)
+	assert.Equal(t, DefaultOfflineTokenScope, claims.Scope)
+	assert.Equal(t, offline.ID, claims.ID)
+	assert.Empty(t, claims.SessionID, "offline tokens are not interactive sessions")
+}
+
+func TestIssueOfflineToken_Rejected(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+	_, _, err := a.IssueOfflineToken(context.Background(), freshMFA(7), 7, testApp.ID, "sync")
+	require.ErrorIs(t, err, ErrOfflineTokensDisabled)
+
+	store := mocks.NewOfflineTokenStore(t)
+	a, _, provider, apps := newTestService(t, WithOfflineTokens(store, OfflineTokenSettings{MFAMaxAge: 5 * time.Minute}))
+
+	stale := freshMFA(7)
+	stale.AuthTime = time.Now().Add(-6 * time.Minute)
+	singleFactor := freshMFA(7)
+	singleFactor.ACR = models.ACRSingleFactor
+	offline := freshMFA(7)
+	offline.Offline = true
+
+	for name, caller := range map[string]authctx.Principal{
+		"stale mfa":     stale,
+		"no mfa":        singleFactor,
+		"offline token": offline,
+		"no auth_time":  {UserID: 7, ACR: models.ACRMultiFactor},
+	} {
+		_, _, err := a.IssueOfflineToken(context.Background(), caller, 7, testApp.ID, "sync")
+		require.ErrorIs(t, err, ErrFreshMFARequired, name)
+	}
+
+	// Приложение не согласилось принимать offline-токены
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, IsActive: true}, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	_, _, err = a.IssueOfflineToken(context.Background(), freshMFA(7), 7, testApp.ID, "sync")
+	require.ErrorIs(t, err, ErrOfflineTokensNotAccepted)
+}
+
+func TestAuthenticate_OfflineToken(t *testing.T) {
+	store := mocks.NewOfflineTokenStore(t)
+	a, _, provider, apps := newTestService(t, WithOfflineTokens(store, OfflineTokenSettings{}))
+	user := models.User{ID: 7, Email: "a@b.c", IsActive: true}
+
+	agent := testApp
+	agent.AcceptOfflineTokens = true
+
+	token, claims, err := jwt.Issue(user, agent, time.Hour, jwt.AsOffline(), jwt.WithScope([]string{"offline_sync"}))
+	require.NoError(t, err)
+	active := models.OfflineToken{ID: claims.ID, UserID: user.ID, AppID: agent.ID, ExpiresAt: time.Now().Add(time.Hour)}
+
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+
+	apps.EXPECT().App(mock.Anything, agent.ID).Return(agent, nil).Once()
+	store.EXPECT().OfflineToken(mock.Anything, claims.ID).Return(active, nil).Once()
+	principal, err := a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.True(t, principal.Offline)
+	assert.Equal(t, []string{"offline_sync"}, principal.Scopes)
+
+	// Отозванный токен отклоняется сразу, хотя срок ещё не вышел
+	revoked := active
+	revoked.RevokedAt = time.Now()
+	apps.EXPECT().App(mock.Anything, agent.ID).Return(agent, nil).Once()
+	store.EXPECT().OfflineToken(mock.Anything, claims.ID).Return(revoked, nil).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Приложение отказалось от offline-токенов - уже выданные тоже не действуют
+	apps.EXPECT().App(mock.Anything, agent.ID).Return(testApp, nil).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	require.ErrorIs(t, err, ErrOfflineTokensNotAccepted)
+}
+
+func TestRevokeOfflineToken(t *testing.T) {
+	store := mocks.NewOfflineTokenStore(t)
+	revocations := mocks.NewRevocationStore(t)
+	a, _, _, _ := newTestService(t, WithOfflineTokens(store, OfflineTokenSettings{}), WithRevocationLog(revocations, nil))
+
+	store.EXPECT().OfflineToken(mock.Anything, "jti-1").Return(models.OfflineToken{ID: "jti-1", UserID: 7, AppID: 1}, nil)
+	store.EXPECT().OfflineToken(mock.Anything, "jti-2").
+		Return(models.OfflineToken{}, fmt.Errorf("storage.sqlite.OfflineToken: %w", storage.ErrOfflineTokenNotFound))
+	store.EXPECT().RevokeOfflineToken(mock.Anything, int64(7), "jti-1", mock.Anything).Return(nil).Once()
+	revocations.EXPECT().AddRevocation(mock.Anything, mock.MatchedBy(func(r models.Revocation) bool {
+		return r.Kind == models.RevocationToken && r.TokenID == "jti-1"
+	})).Return(int64(1), nil)
+
+	require.NoError(t, a.RevokeOfflineToken(context.Background(), 7, "jti-1"))
+
+	// Чужой токен для вызывающего не существует
+	require.ErrorIs(t, a.RevokeOfflineToken(context.Background(), 8, "jti-1"), ErrOfflineTokenNotFound)
+	require.ErrorIs(t, a.RevokeOfflineToken(context.Background(), 7, "jti-2"), ErrOfflineTokenNotFound)
+}
@@ -0,0 +1,369 @@
+package auth
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOIDCDisabled       = errors.New("openid connect is disabled")                          // Ошибка, если эндпоинты OpenID Connect выключены.
+	ErrInvalidRedirectURI = errors.New("redirect_uri is not registered for the app")          // Ошибка, если redirect_uri не совпадает ни с одним адресом приложения.
+	ErrInvalidAuthRequest = errors.New("invalid authorization request")                       // Ошибка, если в запросе авторизации нет обязательных параметров.
+	ErrInvalidClient      = errors.New("invalid client credentials")                          // Ошибка, если client_id или client_secret неверные.
+	ErrInvalidGrant       = errors.New("invalid, expired or already used authorization code") // Ошибка, если код авторизации не подходит.
+)
+
+// DefaultAuthCodeTTL - срок действия кода авторизации, если он не задан
+const DefaultAuthCodeTTL = time.Minute
+
+// maxAuthParamLen - максимальная длина state и nonce
+const maxAuthParamLen = 512
+
+// ScopeOpenID - право, без которого запрос авторизации не является запросом OpenID Connect
+const ScopeOpenID = "openid"
+
+// OIDCStore - адреса возврата приложений и коды авторизации
+type OIDCStore interface {
+	// AppRedirectURIs - адреса возврата приложения.
+	AppRedirectURIs(ctx context.Context, appID int) ([]string, error)
+	// AppsWithPublicKeys - приложения с асимметричной подписью токенов (для JWKS).
+	AppsWithPublicKeys(ctx context.Context) ([]models.App, error)
+	// SaveAuthCode - сохраняет код авторизации.
+	SaveAuthCode(ctx context.Context, c models.AuthCode) error
+	// TakeAuthCode - забирает код авторизации (storage.ErrAuthCodeNotFound, если его нет или он уже использован).
+	TakeAuthCode(ctx context.Context, id string) (models.AuthCode, error)
+}
+
+// OIDCSettings - параметры OpenID Connect
+type OIDCSettings struct {
+	Issuer  string        // Идентификатор сервиса (iss в ID-токенах)
+	CodeTTL time.Duration // Срок действия кода авторизации
+}
+
+// WithOIDC - включает вход в приложения по OpenID Connect (authorization code flow с PKCE)
+func WithOIDC(store OIDCStore, s OIDCSettings) Option {
+	return func(a *AuthService) {
+		if s.CodeTTL <= 0 {
+			s.CodeTTL = DefaultAuthCodeTTL
+		}
+		a.oidcStore = store
+		a.oidc = s
+	}
+}
+
+// CheckAuthorizationRequest - проверяет запрос авторизации и возвращает приложение (для страницы входа).
+// ErrInvalidAppID и ErrInvalidRedirectURI означают, что вернуть ошибку приложению нельзя:
+// адрес возврата не подтверждён, и показывать её нужно самому пользователю
+func (a *AuthService) CheckAuthorizationRequest(ctx context.Context, r models.AuthorizationRequest) (models.App, error) {
+	const op = "Auth.CheckAuthorizationRequest"
+
+	if a.oidcStore == nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, ErrOIDCDisabled)
+	}
+
+	app, err := a.appProvider.App(ctx, r.ClientID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.App{}, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Адрес сравнивается побайтно: любая нормализация открыла бы подмену адреса возврата
+	uris, err := a.oidcStore.AppRedirectURIs(ctx, app.ID)
+	if err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !slices.Contains(uris, r.RedirectURI) {
+		return models.App{}, fmt.Errorf("%s: %w", op, ErrInvalidRedirectURI)
+	}
+
+	switch {
+	case !slices.Contains(strings.Fields(r.Scope), ScopeOpenID):
+		return models.App{}, fmt.Errorf("%s: %w: scope must include %s", op, ErrInvalidAuthRequest, ScopeOpenID)
+	case !validCodeChallenge(r.CodeChallenge):
+		return models.App{}, fmt.Errorf("%s: %w: code_challenge (S256) is required", op, ErrInvalidAuthRequest)
+	case len(r.State) > maxAuthParamLen || len(r.Nonce) > maxAuthParamLen:
+		return models.App{}, fmt.Errorf("%s: %w: state or nonce is too long", op, ErrInvalidAuthRequest)
+	}
+
+	return app, nil
+}
+
+// AuthorizeWithPassword - вход на странице авторизации: проверяет запрос и пароль и возвращает код
+// авторизации для redirect_uri. grantConsent - пользователь согласился передать данные стороннему
+// приложению (права из scope); без согласия вход в такое приложение вернёт ErrConsentRequired.
+// Второй фактор на странице не поддерживается: пользователю с подтверждённым телефоном - ErrMFARequired
+func (a *AuthService) AuthorizeWithPassword(
+	ctx context.Context,
+	r models.AuthorizationRequest,
+	email, password, captchaToken string,
+	grantConsent bool,
+) (string, error) {
+	const op = "Auth.AuthorizeWithPassword"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("app_id", r.ClientID))
+
+	app, err := a.CheckAuthorizationRequest(ctx, r)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	req := passwordLogin{email: email, password: password, appID: app.ID, captchaToken: captchaToken}
+	if app.ThirdParty && grantConsent {
+		req.grantScopes = strings.Fields(r.Scope)
+	}
+
+	token, err := a.login(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if token.MFAChallenge != nil {
+		log.Info("second factor is required, not supported by the authorization page")
+
+		return "", fmt.Errorf("%s: %w", op, ErrMFARequired)
+	}
+
+	code, err := newDeviceCode()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	sealed, err := sealToken(code, token.AccessToken)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = a.oidcStore.SaveAuthCode(ctx, models.AuthCode{
+		ID:            hashDeviceCode(code),
+		AppID:         app.ID,
+		RedirectURI:   r.RedirectURI,
+		CodeChallenge: r.CodeChallenge,
+		Nonce:         r.Nonce,
+		SealedToken:   sealed,
+		ExpiresAt:     a.clock.Now().Add(a.oidc.CodeTTL),
+	})
+	if err != nil {
+		log.Error("failed to save authorization code", slog.String("error", err.Error()))
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventOIDCCodeIssued, Outcome: audit.OutcomeSuccess,
+		Email: email, AppID: app.ID,
+	})
+
+	return code, nil
+}
+
+// ExchangeAuthCode - обмен кода авторизации на токен доступа и ID-токен (token endpoint).
+// Приложение подтверждает себя секретом; redirectURI и codeVerifier должны соответствовать запросу
+// авторизации. Код одноразовый: при любой ошибке после его проверки он уже недействителен
+func (a *AuthService) ExchangeAuthCode(
+	ctx context.Context,
+	clientID int,
+	clientSecret, code, redirectURI, codeVerifier string,
+) (models.Token, string, error) {
+	const op = "Auth.ExchangeAuthCode"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("app_id", clientID))
+
+	if a.oidcStore == nil {
+		return models.Token{}, "", fmt.Errorf("%s: %w", op, ErrOIDCDisabled)
+	}
+
+	app, err := a.appProvider.App(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.Token{}, "", fmt.Errorf("%s: %w", op, ErrInvalidClient)
+		}
+
+		return models.Token{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+	if subtle.ConstantTimeCompare([]byte(clientSecret), []byte(app.Secret)) != 1 {
+		log.Warn("invalid client secret")
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventOIDCCodeExchanged, Outcome: audit.OutcomeFailure,
+			AppID: clientID, Reason: "invalid client secret",
+		})
+
+		return models.Token{}, "", fmt.Errorf("%s: %w", op, ErrInvalidClient)
+	}
+
+	c, err := a.oidcStore.TakeAuthCode(ctx, hashDeviceCode(code))
+	if err != nil {
+		if errors.Is(err, storage.ErrAuthCodeNotFound) {
+			return models.Token{}, "", fmt.Errorf("%s: %w", op, ErrInvalidGrant)
+		}
+
+		return models.Token{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var reason string
+	switch {
+	case c.AppID != app.ID:
+		reason = "code was issued to another app"
+	case c.RedirectURI != redirectURI:
+		reason = "redirect_uri mismatch"
+	case a.clock.Now().After(c.ExpiresAt):
+		reason = "code expired"
+	case !verifyCodeChallenge(c.CodeChallenge, codeVerifier):
+		reason = "code_verifier mismatch"
+	}
+	if reason != "" {
+		log.Info("authorization code rejected", slog.String("reason", reason))
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventOIDCCodeExchanged, Outcome: audit.OutcomeFailure,
+			AppID: clientID, Reason: reason,
+		})
+
+		return models.Token{}, "", fmt.Errorf("%s: %w", op, ErrInvalidGrant)
+	}
+
+	accessToken, err := openToken(code, c.SealedToken)
+	if err != nil {
+		return models.Token{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Токен мог истечь, пока код ждал обмена (например, при коротком token_ttl)
+	claims, err := jwt.ParseForApp(accessToken, app, jwt.WithLeeway(a.leeway), jwt.WithClock(a.clock.Now))
+	if err != nil {
+		return models.Token{}, "", fmt.Errorf("%s: %w: %w", op, ErrInvalidGrant, err)
+	}
+
+	idToken, err := jwt.NewIDToken(claims, app, a.oidc.Issuer, c.Nonce, a.clock.Now())
+	if err != nil {
+		log.Error("failed to sign id token", slog.String("error", err.Error()))
+
+		return models.Token{}, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventOIDCCodeExchanged, Outcome: audit.OutcomeSuccess,
+		UserID: claims.UID, Email: claims.Email, AppID: app.ID,
+	})
+
+	return models.Token{
+		AccessToken: accessToken,
+		ExpiresAt:   claims.ExpiresAt.Time,
+		Scopes:      strings.Fields(claims.Scope),
+		SessionID:   claims.SessionID,
+	}, idToken, nil
+}
+
+// PublicKeys - открытые ключи приложений с асимметричной подписью (jwks_uri). Приложения с HS256
+// проверяют ID-токены своим секретом, их ключей в наборе нет
+func (a *AuthService) PublicKeys(ctx context.Context) (jwt.JWKS, error) {
+	const op = "Auth.PublicKeys"
+
+	if a.oidcStore == nil {
+		return jwt.JWKS{}, fmt.Errorf("%s: %w", op, ErrOIDCDisabled)
+	}
+
+	apps, err := a.oidcStore.AppsWithPublicKeys(ctx)
+	if err != nil {
+		return jwt.JWKS{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	set := jwt.JWKS{Keys: make([]jwt.JWK, 0, len(apps))}
+	for _, app := range apps {
+		jwk, err := jwt.PublicJWK(app)
+		if err != nil {
+			// Битый ключ одного приложения не должен ломать проверку токенов остальных
+			logctx.FromOr(ctx, a.log).Error("failed to publish app key",
+				slog.String("op", op), slog.Int("app_id", app.ID), slog.String("error", err.Error()))
+
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set, nil
+}
+
+// validCodeChallenge - code_challenge метода S256: base64url от SHA-256 (43 символа)
+func validCodeChallenge(challenge string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(challenge)
+
+	return err == nil && len(b) == sha256.Size
+}
+
+// verifyCodeChallenge - PKCE S256 (RFC 7636, 4.6): base64url(SHA-256(code_verifier)) == code_challenge
+func verifyCodeChallenge(challenge, verifier string) bool {
+	// code_verifier - от 43 до 128 символов
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+
+	return subtle.ConstantTimeCompare([]byte(base64.RawURLEncoding.EncodeToString(sum[:])), []byte(challenge)) == 1
+}
+
+// sealToken - шифрует токен доступа ключом, выведенным из кода авторизации (AES-256-GCM).
+// Код хранится только в виде хеша, поэтому по базе токен не расшифровать
+func sealToken(code, token string) ([]byte, error) {
+	gcm, err := codeCipher(code)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, []byte(token), nil), nil
+}
+
+// openToken - расшифровывает токен, зашифрованный sealToken
+func openToken(code string, sealed []byte) (string, error) {
+	gcm, err := codeCipher(code)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return "", errors.New("sealed token is too short")
+	}
+
+	token, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(token), nil
+}
+
+// codeCipher - AES-256-GCM с ключом из кода. Ключ отличается от хеша кода (id записи) префиксом
+func codeCipher(code string) (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte("oidc-code-seal:" + code))
+
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"strings"
+	"testing"
+
+	gojwt "github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testVerifier = "dBjftJeZ4CVP-mJ92K9fhbXQ9dW7q7Qf1PrhCrpYTOQkk"
+
+func testChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func testAuthRequest() models.AuthorizationRequest {
+	return models.AuthorizationRequest{
+		ClientID:      testApp.ID,
+		RedirectURI:   "https://app.example/cb",
+		Scope:         "openid email",
+		State:         "xyz",
+		Nonce:         "n-0S6",
+		CodeChallenge: testChallenge(testVerifier),
+	}
+}
+
+// newOIDCService - сервис с OpenID Connect; сохранённые коды авторизации отдаются TakeAuthCode один раз
+func newOIDCService(t *testing.T) (*AuthService, *mocks.UserProvider) {
+	t.Helper()
+
+	store := mocks.NewOIDCStore(t)
+	a, _, provider, apps := newTestService(t, WithOIDC(store, OIDCSettings{Issuer: "https://sso.example"}))
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil).Maybe()
+	apps.EXPECT().App(mock.Anything, mock.Anything).Return(models.App{}, storage.ErrAppNotFound).Maybe()
+	store.EXPECT().AppRedirectURIs(mock.Anything, testApp.ID).Return([]string{"https://app.example/cb"}, nil).Maybe()
+
+	codes := map[string]models.AuthCode{}
+	store.EXPECT().SaveAuthCode(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, c models.AuthCode) error {
+			codes[c.ID] = c
+			return nil
+		}).Maybe()
+	store.EXPECT().TakeAuthCode(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, id string) (models.AuthCode, error) {
+			c, ok := codes[id]
+			if !ok {
+				return models.AuthCode{}, storage.ErrAuthCodeNotFound
+			}
+			delete(codes, id)
+
+			return c, nil
+		}).Maybe()
+
+	return a, provider
+}
+
+func TestCheckAuthorizationRequest(t *testing.T) {
+	a, _ := newOIDCService(t)
+	ctx := context.Background()
+
+	_, err := a.CheckAuthorizationRequest(ctx, testAuthRequest())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		modify func(r *models.AuthorizationRequest)
+		want   error
+	}{
+		{"unknown client", func(r *models.AuthorizationRequest) { r.ClientID = 99 }, ErrInvalidAppID},
+		{"redirect uri prefix", func(r *models.AuthorizationRequest) { r.RedirectURI += "/evil" }, ErrInvalidRedirectURI},
+		{"no openid scope", func(r *models.AuthorizationRequest) { r.Scope = "email" }, ErrInvalidAuthRequest},
+		{"no code challenge", func(r *models.AuthorizationRequest) { r.CodeChallenge = "" }, ErrInvalidAuthRequest},
+		{"plain code challenge", func(r *models.AuthorizationRequest) { r.CodeChallenge = testVerifier }, ErrInvalidAuthRequest},
+		{"long state", func(r *models.AuthorizationRequest) { r.State = strings.Repeat("s", 513) }, ErrInvalidAuthRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := testAuthRequest()
+			tt.modify(&r)
+
+			_, err := a.CheckAuthorizationRequest(ctx, r)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestAuthCodeFlow(t *testing.T) {
+	a, provider := newOIDCService(t)
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(userWithPassword(t, "secret"), nil)
+	ctx := context.Background()
+
+	_, err := a.AuthorizeWithPassword(ctx, testAuthRequest(), "a@b.c", "wrong", "", false)
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	code, err := a.AuthorizeWithPassword(ctx, testAuthRequest(), "a@b.c", "secret", "", false)
+	require.NoError(t, err)
+
+	token, idToken, err := a.ExchangeAuthCode(ctx, testApp.ID, testApp.Secret, code, "https://app.example/cb", testVerifier)
+	require.NoError(t, err)
+
+	claims, err := jwt.Parse(token.AccessToken, []byte(testApp.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), claims.UID)
+
+	var idClaims jwt.IDTokenClaims
+	_, err = gojwt.ParseWithClaims(idToken, &idClaims, func(*gojwt.Token) (any, error) { return []byte(testApp.Secret), nil },
+		gojwt.WithAudience("1"))
+	require.NoError(t, err)
+	assert.Equal(t, "https://sso.example", idClaims.Issuer)
+	assert.Equal(t, "n-0S6", idClaims.Nonce)
+	assert.Equal(t, "7", idClaims.Subject)
+
+	// Код одноразовый
+	_, _, err = a.ExchangeAuthCode(ctx, testApp.ID, testApp.Secret, code, "https://app.example/cb", testVerifier)
+	require.ErrorIs(t, err, ErrInvalidGrant)
+}
+
+func TestExchangeAuthCode_Rejected(t *testing.T) {
+	a, provider := newOIDCService(t)
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(userWithPassword(t, "secret"), nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		name                  string
+		secret, uri, verifier string
+		want                  error
+	}{
+		{"wrong secret", "nope", "https://app.example/cb", testVerifier, ErrInvalidClient},
+		{"wrong redirect uri", testApp.Secret, "https://app.example/other", testVerifier, ErrInvalidGrant},
+		{"wrong verifier", testApp.Secret, "https://app.example/cb", strings.Repeat("a", 43), ErrInvalidGrant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := a.AuthorizeWithPassword(ctx, testAuthRequest(), "a@b.c", "secret", "", false)
+			require.NoError(t, err)
+
+			_, _, err = a.ExchangeAuthCode(ctx, testApp.ID, tt.secret, code, tt.uri, tt.verifier)
+			require.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestAuthCodeSeal(t *testing.T) {
+	sealed, err := sealToken("code", "token")
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "token")
+
+	token, err := openToken("code", sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "token", token)
+
+	_, err = openToken("other", sealed)
+	require.Error(t, err)
+}
@@ -0,0 +1,135 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testOrg - организация, в которую входят в тестах
+var testOrg = models.Organization{ID: 3, Slug: "acme", Name: "Acme"}
+
+func TestLogin_Org(t *testing.T) {
+	orgs := mocks.NewOrgStore(t)
+	a, _, provider, apps := newTestService(t, WithOrganizations(orgs, false))
+	user := userWithPassword(t, "secret")
+
+	orgs.EXPECT().OrgBySlug(mock.Anything, "acme").Return(testOrg, nil)
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	orgs.EXPECT().MemberRole(mock.Anything, testOrg.ID, user.ID).Return(models.OrgRoleAdmin, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	token, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "acme", "", "")
+	require.NoError(t, err)
+
+	claims, err := jwt.Parse(token.AccessToken, []byte(testApp.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, testOrg.ID, claims.OrgID)
+	assert.Equal(t, models.OrgRoleAdmin, claims.OrgRole)
+}
+
+func TestLogin_OrgNotMember(t *testing.T) {
+	// Токен не выдаётся, поэтому AppProvider не нужен
+	orgs := mocks.NewOrgStore(t)
+	a, _, provider, _ := newTestService(t, WithOrganizations(orgs, false))
+	user := userWithPassword(t, "secret")
+
+	orgs.EXPECT().OrgBySlug(mock.Anything, "acme").Return(testOrg, nil)
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	orgs.EXPECT().MemberRole(mock.Anything, testOrg.ID, user.ID).
+		Return("", fmt.Errorf("storage.sqlite.MemberRole: %w", storage.ErrNotMember))
+
+	_, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "acme", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials, "non-member must look like a wrong password")
+}
+
+func TestLogin_OrgNotFound(t *testing.T) {
+	orgs := mocks.NewOrgStore(t)
+	a, _, _, _ := newTestService(t, WithOrganizations(orgs, false))
+
+	orgs.EXPECT().OrgBySlug(mock.Anything, "missing").
+		Return(models.Organization{}, fmt.Errorf("storage.sqlite.OrgBySlug: %w", storage.ErrOrgNotFound))
+
+	_, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "missing", "", "")
+	require.ErrorIs(t, err, ErrOrgNotFound)
+
+	// Без хранилища организаций вход в организацию невозможен
+	a, _, _, _ = newTestService(t)
+	_, err = a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "acme", "", "")
+	require.ErrorIs(t, err, ErrOrgNotFound)
+}
+
+func TestLogin_OrgScopedEmail(t *testing.T) {
+	orgs := mocks.NewOrgStore(t)
+	a, _, _, apps := newTestService(t, WithOrganizations(orgs, true))
+	user := userWithPassword(t, "secret")
+
+	// Учётная запись организации находится раньше общей: UserProvider не вызывается
+	orgs.EXPECT().OrgBySlug(mock.Anything, "acme").Return(testOrg, nil)
+	orgs.EXPECT().ScopedUser(mock.Anything, testOrg.ID, "a@b.c").Return(user, nil)
+	orgs.EXPECT().MemberRole(mock.Anything, testOrg.ID, user.ID).Return(models.OrgRoleMember, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	_, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "acme", "", "")
+	require.NoError(t, err)
+}
+
+func TestRegisterNewUser_Org(t *testing.T) {
+	orgs := mocks.NewOrgStore(t)
+	a, _, _, _ := newTestService(t, WithOrganizations(orgs, true))
+
+	open := testOrg
+	open.AllowSelfSignup = true
+	orgs.EXPECT().OrgBySlug(mock.Anything, "acme").Return(open, nil)
+	orgs.EXPECT().SaveOrgUser(mock.Anything, "a@b.c", mock.Anything, open.ID, open.ID, models.OrgRoleMember).
+		Return(42, nil)
+
+	id, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "acme", "", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), id)
+}
+
+func TestRegisterNewUser_OrgSignupClosed(t *testing.T) {
+	// UserSaver не настроен: закрытая организация отказывает до сохранения
+	orgs := mocks.NewOrgStore(t)
+	a, _, _, _ := newTestService(t, WithOrganizations(orgs, false))
+
+	orgs.EXPECT().OrgBySlug(mock.Anything, "acme").Return(testOrg, nil)
+
+	_, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "acme", "", "", 0)
+	require.ErrorIs(t, err, ErrSignupClosed)
+}
+
+func TestAuthenticate_OrgMembershipRevoked(t *testing.T) {
+	orgs := mocks.NewOrgStore(t)
+	a, _, provider, apps := newTestService(t, WithOrganizations(orgs, false))
+	user := userWithPassword(t, "secret")
+
+	token, err := jwt.NewToken(user, testApp, time.Hour, jwt.WithOrg(testOrg.ID, models.OrgRoleAdmin))
+	require.NoError(t, err)
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+
+	// Роль берётся из БД, а не из токена
+	orgs.EXPECT().MemberRole(mock.Anything, testOrg.ID, user.ID).Return(models.OrgRoleMember, nil).Once()
+	p, err := a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, testOrg.ID, p.OrgID)
+	assert.Equal(t, models.OrgRoleMember, p.OrgRole)
+
+	// После исключения из организации токен больше не действует
+	orgs.EXPECT().MemberRole(mock.Anything, testOrg.ID, user.ID).
+		Return("", fmt.Errorf("storage.sqlite.MemberRole: %w", storage.ErrNotMember)).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
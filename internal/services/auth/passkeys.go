@@ -0,0 +1,569 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+)
+
+// DefaultPasskeyChallengeTTL - срок действия вызова Begin* по умолчанию
+const DefaultPasskeyChallengeTTL = 5 * time.Minute
+
+// Ошибки passkey
+var (
+	ErrPasskeysDisabled         = errors.New("passkeys are disabled")                  // Ошибка, если вход по passkey не включён.
+	ErrPasskeyNotFound          = errors.New("passkey not found")                      // Ошибка, если у пользователя нет такой passkey.
+	ErrPasskeyExists            = errors.New("passkey is already registered")          // Ошибка, если эта passkey уже зарегистрирована.
+	ErrTooManyPasskeys          = errors.New("passkey limit reached")                  // Ошибка, если у пользователя уже максимум passkey.
+	ErrPasskeyChallengeNotFound = errors.New("passkey challenge not found or expired") // Ошибка, если session_id неизвестен, истёк или уже использован.
+	ErrInvalidPasskey           = errors.New("passkey verification failed")            // Ошибка, если ответ аутентификатора не прошёл проверку.
+)
+
+// PasskeySettings - параметры входа по passkey.
+type PasskeySettings struct {
+	ChallengeTTL time.Duration // Сколько действует вызов Begin* (0 - DefaultPasskeyChallengeTTL).
+	MaxPerUser   int           // Сколько passkey может зарегистрировать пользователь (0 - без ограничения).
+}
+
+// PasskeyStore - хранилище passkey и начатых церемоний WebAuthn.
+type PasskeyStore interface {
+	// SavePasskey - сохраняет passkey (storage.ErrPasskeyExists, если credential_id уже есть).
+	SavePasskey(ctx context.Context, p models.Passkey) (int64, error)
+	// Passkeys - passkey пользователя в порядке регистрации.
+	Passkeys(ctx context.Context, userID int64) ([]models.Passkey, error)
+	// PasskeyByCredentialID - passkey по id учётных данных (storage.ErrPasskeyNotFound, если её нет).
+	PasskeyByCredentialID(ctx context.Context, credentialID []byte) (models.Passkey, error)
+	// UpdatePasskeyUsage - счётчик подписей, состояние синхронизации и время входа.
+	UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) error
+	// DeletePasskey - удаляет passkey пользователя (storage.ErrPasskeyNotFound, если её нет или она чужая).
+	DeletePasskey(ctx context.Context, userID, id int64) error
+	// SaveWebAuthnSession - сохраняет состояние начатой церемонии.
+	SaveWebAuthnSession(ctx context.Context, session models.WebAuthnSession) error
+	// TakeWebAuthnSession - возвращает и удаляет состояние церемонии (storage.ErrWebAuthnSessionNotFound, если его нет).
+	TakeWebAuthnSession(ctx context.Context, id string) (models.WebAuthnSession, error)
+}
+
+// WithPasskeys - включает регистрацию passkey и вход по ним (WebAuthn). rp - параметры сайта (RP ID, origin).
+func WithPasskeys(store PasskeyStore, rp *webauthn.WebAuthn, s PasskeySettings) Option {
+	return func(a *AuthService) {
+		if s.ChallengeTTL <= 0 {
+			s.ChallengeTTL = DefaultPasskeyChallengeTTL
+		}
+		a.passkeys = store
+		a.relyingParty = rp
+		a.passkey = s
+	}
+}
+
+// BeginPasskeyRegistration - начинает регистрацию passkey пользователя userID. Уже зарегистрированные
+// passkey попадают в список исключений, чтобы аутентификатор не создал вторую для того же пользователя.
+func (a *AuthService) BeginPasskeyRegistration(ctx context.Context, userID int64) (models.PasskeyOptions, error) {
+	const op = "Auth.BeginPasskeyRegistration"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if a.passkeys == nil {
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, ErrPasskeysDisabled)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	passkeys, err := a.passkeys.Passkeys(ctx, userID)
+	if err != nil {
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if a.passkey.MaxPerUser > 0 && len(passkeys) >= a.passkey.MaxPerUser {
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, ErrTooManyPasskeys)
+	}
+
+	owner := passkeyUser{user: user, passkeys: passkeys}
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(passkeys))
+	for _, c := range owner.WebAuthnCredentials() {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+
+	creation, session, err := a.relyingParty.BeginRegistration(owner,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementRequired),
+	)
+	if err != nil {
+		log.Error("failed to begin passkey registration", slog.String("error", err.Error()))
+
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	opts, err := a.saveCeremony(ctx, models.CeremonyRegistration, userID, creation, session)
+	if err != nil {
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return opts, nil
+}
+
+// FinishPasskeyRegistration - проверяет ответ аутентификатора на вызов sessionID и сохраняет passkey
+// пользователя userID под именем name. Возвращает id passkey.
+func (a *AuthService) FinishPasskeyRegistration(
+	ctx context.Context,
+	userID int64,
+	sessionID string,
+	name string,
+	credentialJSON []byte,
+) (int64, error) {
+	const op = "Auth.FinishPasskeyRegistration"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if a.passkeys == nil {
+		return 0, fmt.Errorf("%s: %w", op, ErrPasskeysDisabled)
+	}
+
+	// Вызов, выданный другому пользователю, не подходит: его ответ подписан не для этого user handle
+	session, err := a.takeCeremony(ctx, sessionID, models.CeremonyRegistration)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if session.UserID != userID {
+		return 0, fmt.Errorf("%s: %w", op, ErrPasskeyChallengeNotFound)
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBytes(credentialJSON)
+	if err != nil {
+		log.Info("invalid passkey registration response", slog.String("error", err.Error()))
+
+		return 0, fmt.Errorf("%s: %w: %s", op, ErrInvalidPasskey, err)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	credential, err := a.relyingParty.CreateCredential(passkeyUser{user: user}, session.data, parsed)
+	if err != nil {
+		log.Info("passkey registration rejected", slog.String("error", err.Error()))
+
+		return 0, fmt.Errorf("%s: %w: %s", op, ErrInvalidPasskey, err)
+	}
+
+	p := passkeyFromCredential(userID, credential)
+	p.Name = name
+
+	id, err := a.passkeys.SavePasskey(ctx, p)
+	if err != nil {
+		if errors.Is(err, storage.ErrPasskeyExists) {
+			return 0, fmt.Errorf("%s: %w", op, ErrPasskeyExists)
+		}
+
+		log.Error("failed to save passkey", slog.String("error", err.Error()))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("passkey registered", slog.Int64("passkey_id", id))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventPasskeyRegistered, Outcome: audit.OutcomeSuccess,
+		UserID: userID, Email: user.Email,
+	})
+
+	return id, nil
+}
+
+// BeginPasskeyLogin - начинает вход по passkey. С email браузер предложит passkey этого пользователя;
+// без email (или если у пользователя нет passkey) - любую passkey сайта. Ответ одинаковый,
+// есть ли такой пользователь, чтобы по нему нельзя было проверять адреса.
+func (a *AuthService) BeginPasskeyLogin(ctx context.Context, email string) (models.PasskeyOptions, error) {
+	const op = "Auth.BeginPasskeyLogin"
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+
+	if a.passkeys == nil {
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, ErrPasskeysDisabled)
+	}
+
+	var owner *passkeyUser
+	if email != "" {
+		user, err := a.usrProvider.User(ctx, email)
+		switch {
+		case err == nil:
+			passkeys, err := a.passkeys.Passkeys(ctx, user.ID)
+			if err != nil {
+				return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, err)
+			}
+			if len(passkeys) > 0 {
+				owner = &passkeyUser{user: user, passkeys: passkeys}
+			}
+		case !errors.Is(err, storage.ErrUserNotFound):
+			return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	var (
+		assertion *protocol.CredentialAssertion
+		session   *webauthn.SessionData
+		userID    int64
+		err       error
+	)
+	if owner != nil {
+		userID = owner.user.ID
+		assertion, session, err = a.relyingParty.BeginLogin(owner)
+	} else {
+		assertion, session, err = a.relyingParty.BeginDiscoverableLogin()
+	}
+	if err != nil {
+		log.Error("failed to begin passkey login", slog.String("error", err.Error()))
+
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	opts, err := a.saveCeremony(ctx, models.CeremonyLogin, userID, assertion, session)
+	if err != nil {
+		return models.PasskeyOptions{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return opts, nil
+}
+
+// FinishPasskeyLogin - проверяет подпись аутентификатора на вызов sessionID и выдаёт токен для приложения appID,
+// как Login. Вход с проверкой пользователя на аутентификаторе (PIN, биометрия) получает уровень models.ACRMultiFactor.
+// Счётчик подписей меньше сохранённого означает возможную копию ключа: вход не запрещается,
+// но в журнал безопасности пишется passkey.clone_warning.
+func (a *AuthService) FinishPasskeyLogin(
+	ctx context.Context,
+	sessionID string,
+	credentialJSON []byte,
+	appID int,
+	acceptedTOS string,
+) (models.Token, error) {
+	const op = "Auth.FinishPasskeyLogin"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("app_id", appID))
+
+	if a.passkeys == nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrPasskeysDisabled)
+	}
+
+	session, err := a.takeCeremony(ctx, sessionID, models.CeremonyLogin)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBytes(credentialJSON)
+	if err != nil {
+		log.Info("invalid passkey login response", slog.String("error", err.Error()))
+
+		return models.Token{}, fmt.Errorf("%s: %w: %s", op, ErrInvalidPasskey, err)
+	}
+
+	passkey, err := a.passkeys.PasskeyByCredentialID(ctx, parsed.RawID)
+	if err != nil {
+		if errors.Is(err, storage.ErrPasskeyNotFound) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, a.passkeyLoginFailed(ctx, log, 0, appID, "unknown passkey"))
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// Вход по email: подходит только passkey того пользователя, для которого выдан вызов
+	if session.UserID != 0 && session.UserID != passkey.UserID {
+		return models.Token{}, fmt.Errorf("%s: %w", op, a.passkeyLoginFailed(ctx, log, passkey.UserID, appID, "passkey of another user"))
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, passkey.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, a.passkeyLoginFailed(ctx, log, passkey.UserID, appID, "user not found"))
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.IsActive {
+		return models.Token{}, fmt.Errorf("%s: %w", op, a.passkeyLoginFailed(ctx, log, user.ID, appID, "user is not active"))
+	}
+
+	passkeys, err := a.passkeys.Passkeys(ctx, user.ID)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	owner := passkeyUser{user: user, passkeys: passkeys}
+
+	var credential *webauthn.Credential
+	if session.UserID != 0 {
+		credential, err = a.relyingParty.ValidateLogin(owner, session.data, parsed)
+	} else {
+		_, credential, err = a.relyingParty.ValidatePasskeyLogin(func(_, userHandle []byte) (webauthn.User, error) {
+			if !bytes.Equal(userHandle, owner.WebAuthnID()) {
+				return nil, errors.New("user handle does not match the passkey owner")
+			}
+
+			return owner, nil
+		}, session.data, parsed)
+	}
+	if err != nil {
+		log.Info("passkey login rejected", slog.String("error", err.Error()))
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, a.passkeyLoginFailed(ctx, log, user.ID, appID, "invalid signature"))
+	}
+
+	now := a.clock.Now()
+	if credential.Authenticator.CloneWarning {
+		log.Warn("passkey sign count regressed, possible cloned authenticator",
+			slog.Int64("passkey_id", passkey.ID),
+			slog.Uint64("stored_sign_count", uint64(passkey.SignCount)),
+			slog.Uint64("sign_count", uint64(parsed.Response.AuthenticatorData.Counter)))
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventPasskeyCloneWarning, Outcome: audit.OutcomeFailure,
+			UserID: user.ID, Email: user.Email, AppID: appID, Reason: "sign count regressed",
+		})
+	}
+	if err := a.passkeys.UpdatePasskeyUsage(ctx, passkey.ID, credential.Authenticator.SignCount,
+		credential.Flags.BackupState, now); err != nil {
+		log.Error("failed to update passkey usage", slog.String("error", err.Error()))
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	amr, acr := passkeyAuthentication(passkey, credential.Flags.UserVerified)
+
+	token, err := a.completeLogin(ctx, log, user, user.Email, appID, acceptedTOS, acr,
+		jwt.WithAuthentication(amr, acr, now))
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// ListPasskeys - passkey пользователя userID
+func (a *AuthService) ListPasskeys(ctx context.Context, userID int64) ([]models.Passkey, error) {
+	const op = "Auth.ListPasskeys"
+
+	if a.passkeys == nil {
+		return nil, fmt.Errorf("%s: %w", op, ErrPasskeysDisabled)
+	}
+
+	passkeys, err := a.passkeys.Passkeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return passkeys, nil
+}
+
+// DeletePasskey - удаляет passkey id пользователя userID (ErrPasskeyNotFound, если её нет или она чужая)
+func (a *AuthService) DeletePasskey(ctx context.Context, userID, id int64) error {
+	const op = "Auth.DeletePasskey"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if a.passkeys == nil {
+		return fmt.Errorf("%s: %w", op, ErrPasskeysDisabled)
+	}
+
+	if err := a.passkeys.DeletePasskey(ctx, userID, id); err != nil {
+		if errors.Is(err, storage.ErrPasskeyNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrPasskeyNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("passkey deleted", slog.Int64("passkey_id", id))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventPasskeyDeleted, Outcome: audit.OutcomeSuccess,
+		UserID: userID,
+	})
+
+	return nil
+}
+
+// passkeyLoginFailed - аудит и учёт неудачного входа по passkey; возвращает ErrInvalidPasskey
+func (a *AuthService) passkeyLoginFailed(ctx context.Context, log *slog.Logger, userID int64, appID int, reason string) error {
+	log.Info("passkey login failed", slog.String("reason", reason))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+		UserID: userID, AppID: appID, Reason: reason,
+	})
+	a.recordLoginFailure(ctx, log, appID)
+
+	return ErrInvalidPasskey
+}
+
+// webauthnSession - сохранённая церемония с разобранным состоянием библиотеки
+type webauthnSession struct {
+	models.WebAuthnSession
+	data webauthn.SessionData
+}
+
+// saveCeremony - сохраняет состояние церемонии под новым nonce и возвращает параметры для браузера
+func (a *AuthService) saveCeremony(
+	ctx context.Context,
+	ceremony string,
+	userID int64,
+	options any,
+	session *webauthn.SessionData,
+) (models.PasskeyOptions, error) {
+	optionsJSON, err := json.Marshal(options)
+	if err != nil {
+		return models.PasskeyOptions{}, err
+	}
+
+	data, err := json.Marshal(session)
+	if err != nil {
+		return models.PasskeyOptions{}, err
+	}
+
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return models.PasskeyOptions{}, err
+	}
+	id := base64.RawURLEncoding.EncodeToString(nonce)
+
+	if err := a.passkeys.SaveWebAuthnSession(ctx, models.WebAuthnSession{
+		ID:        id,
+		UserID:    userID,
+		Ceremony:  ceremony,
+		Data:      data,
+		ExpiresAt: a.clock.Now().Add(a.passkey.ChallengeTTL),
+	}); err != nil {
+		return models.PasskeyOptions{}, err
+	}
+
+	return models.PasskeyOptions{SessionID: id, OptionsJSON: optionsJSON}, nil
+}
+
+// takeCeremony - забирает состояние церемонии ceremony: после этого повторить Finish* с тем же nonce нельзя.
+// Неизвестный, истёкший nonce или nonce другой церемонии - ErrPasskeyChallengeNotFound
+func (a *AuthService) takeCeremony(ctx context.Context, id, ceremony string) (webauthnSession, error) {
+	session, err := a.passkeys.TakeWebAuthnSession(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrWebAuthnSessionNotFound) {
+			return webauthnSession{}, ErrPasskeyChallengeNotFound
+		}
+
+		return webauthnSession{}, err
+	}
+	if session.Ceremony != ceremony || a.clock.Now().After(session.ExpiresAt) {
+		return webauthnSession{}, ErrPasskeyChallengeNotFound
+	}
+
+	s := webauthnSession{WebAuthnSession: session}
+	if err := json.Unmarshal(session.Data, &s.data); err != nil {
+		return webauthnSession{}, fmt.Errorf("decode webauthn session: %w", err)
+	}
+
+	return s, nil
+}
+
+// passkeyAuthentication - клеймы amr и acr входа по passkey. Passkey - владение ключом; если аутентификатор
+// ещё и проверил пользователя (PIN, биометрия), это второй фактор
+func passkeyAuthentication(p models.Passkey, userVerified bool) ([]string, string) {
+	method := models.AMRHardwareKey
+	if p.BackupEligible {
+		method = models.AMRSoftwareKey
+	}
+
+	if userVerified {
+		return []string{method, models.AMRMultiFactor}, models.ACRMultiFactor
+	}
+
+	return []string{method}, models.ACRSingleFactor
+}
+
+// passkeyUser - пользователь в виде, который нужен библиотеке WebAuthn
+type passkeyUser struct {
+	user     models.User
+	passkeys []models.Passkey
+}
+
+// WebAuthnID - user handle: id пользователя (8 байт big-endian), без email и других персональных данных
+func (u passkeyUser) WebAuthnID() []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(u.user.ID))
+}
+
+func (u passkeyUser) WebAuthnName() string {
+	return u.user.Email
+}
+
+func (u passkeyUser) WebAuthnDisplayName() string {
+	return u.user.Email
+}
+
+func (u passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, len(u.passkeys))
+	for i, p := range u.passkeys {
+		transports := make([]protocol.AuthenticatorTransport, len(p.Transports))
+		for j, t := range p.Transports {
+			transports[j] = protocol.AuthenticatorTransport(t)
+		}
+
+		credentials[i] = webauthn.Credential{
+			ID:              p.CredentialID,
+			PublicKey:       p.PublicKey,
+			AttestationType: p.AttestationType,
+			Transport:       transports,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: p.BackupEligible,
+				BackupState:    p.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    p.AAGUID,
+				SignCount: p.SignCount,
+			},
+		}
+	}
+
+	return credentials
+}
+
+// passkeyFromCredential - passkey для хранения из зарегистрированных учётных данных
+func passkeyFromCredential(userID int64, c *webauthn.Credential) models.Passkey {
+	transports := make([]string, len(c.Transport))
+	for i, t := range c.Transport {
+		transports[i] = string(t)
+	}
+
+	return models.Passkey{
+		UserID:          userID,
+		CredentialID:    c.ID,
+		PublicKey:       c.PublicKey,
+		AttestationType: c.AttestationType,
+		AAGUID:          c.Authenticator.AAGUID,
+		SignCount:       c.Authenticator.SignCount,
+		Transports:      transports,
+		BackupEligible:  c.Flags.BackupEligible,
+		BackupState:     c.Flags.BackupState,
+	}
+}
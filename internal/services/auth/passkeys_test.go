@@ -0,0 +1,394 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "sso.example"
+	testOrigin = "https://sso.example"
+)
+
+// memPasskeys - PasskeyStore в памяти
+type memPasskeys struct {
+	passkeys map[int64]*models.Passkey
+	sessions map[string]models.WebAuthnSession
+}
+
+func newMemPasskeys() *memPasskeys {
+	return &memPasskeys{passkeys: map[int64]*models.Passkey{}, sessions: map[string]models.WebAuthnSession{}}
+}
+
+func (m *memPasskeys) SavePasskey(_ context.Context, p models.Passkey) (int64, error) {
+	for _, existing := range m.passkeys {
+		if bytes.Equal(existing.CredentialID, p.CredentialID) {
+			return 0, fmt.Errorf("storage.mem: %w", storage.ErrPasskeyExists)
+		}
+	}
+	p.ID = int64(len(m.passkeys) + 1)
+	p.CreatedAt = time.Now()
+	m.passkeys[p.ID] = &p
+
+	return p.ID, nil
+}
+
+func (m *memPasskeys) Passkeys(_ context.Context, userID int64) ([]models.Passkey, error) {
+	var out []models.Passkey
+	for id := int64(1); id <= int64(len(m.passkeys)); id++ {
+		if p, ok := m.passkeys[id]; ok && p.UserID == userID {
+			out = append(out, *p)
+		}
+	}
+
+	return out, nil
+}
+
+func (m *memPasskeys) PasskeyByCredentialID(_ context.Context, credentialID []byte) (models.Passkey, error) {
+	for _, p := range m.passkeys {
+		if bytes.Equal(p.CredentialID, credentialID) {
+			return *p, nil
+		}
+	}
+
+	return models.Passkey{}, fmt.Errorf("storage.mem: %w", storage.ErrPasskeyNotFound)
+}
+
+func (m *memPasskeys) UpdatePasskeyUsage(_ context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) error {
+	p := m.passkeys[id]
+	p.SignCount, p.BackupState, p.LastUsedAt = signCount, backupState, usedAt
+
+	return nil
+}
+
+func (m *memPasskeys) DeletePasskey(_ context.Context, userID, id int64) error {
+	if p, ok := m.passkeys[id]; !ok || p.UserID != userID {
+		return fmt.Errorf("storage.mem: %w", storage.ErrPasskeyNotFound)
+	}
+	delete(m.passkeys, id)
+
+	return nil
+}
+
+func (m *memPasskeys) SaveWebAuthnSession(_ context.Context, s models.WebAuthnSession) error {
+	m.sessions[s.ID] = s
+
+	return nil
+}
+
+func (m *memPasskeys) TakeWebAuthnSession(_ context.Context, id string) (models.WebAuthnSession, error) {
+	s, ok := m.sessions[id]
+	if !ok {
+		return models.WebAuthnSession{}, fmt.Errorf("storage.mem: %w", storage.ErrWebAuthnSessionNotFound)
+	}
+	delete(m.sessions, id)
+
+	return s, nil
+}
+
+// testAuthenticator - программный аутентификатор: ключ ES256, аттестация "none"
+type testAuthenticator struct {
+	t          *testing.T
+	key        *ecdsa.PrivateKey
+	id         []byte
+	userHandle []byte
+}
+
+func newTestAuthenticator(t *testing.T) *testAuthenticator {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+
+	return &testAuthenticator{t: t, key: key, id: id}
+}
+
+// register - ответ navigator.credentials.create() на параметры opts
+func (ta *testAuthenticator) register(opts models.PasskeyOptions) []byte {
+	ta.t.Helper()
+
+	var creation struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+			User      struct {
+				ID string `json:"id"`
+			} `json:"user"`
+		} `json:"publicKey"`
+	}
+	require.NoError(ta.t, json.Unmarshal(opts.OptionsJSON, &creation))
+
+	var err error
+	ta.userHandle, err = base64.RawURLEncoding.DecodeString(creation.PublicKey.User.ID)
+	require.NoError(ta.t, err)
+
+	coseKey, err := webauthncbor.Marshal(webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  int64(webauthncose.P256),
+		XCoord: ta.key.X.FillBytes(make([]byte, 32)),
+		YCoord: ta.key.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(ta.t, err)
+
+	authData := ta.authData(0x01|0x04|0x40, 0) // UP, UV, AT
+	authData = append(authData, make([]byte, 16)...)
+	authData = binary.BigEndian.AppendUint16(authData, uint16(len(ta.id)))
+	authData = append(authData, ta.id...)
+	authData = append(authData, coseKey...)
+
+	attestation, err := webauthncbor.Marshal(map[string]any{"fmt": "none", "attStmt": map[string]any{}, "authData": authData})
+	require.NoError(ta.t, err)
+
+	return ta.response(map[string]any{
+		"clientDataJSON":    ta.clientData("webauthn.create", creation.PublicKey.Challenge),
+		"attestationObject": b64(attestation),
+		"transports":        []string{"internal"},
+	})
+}
+
+// login - ответ navigator.credentials.get() со счётчиком подписей counter
+func (ta *testAuthenticator) login(opts models.PasskeyOptions, counter uint32, userVerified bool) []byte {
+	ta.t.Helper()
+
+	var assertion struct {
+		PublicKey struct {
+			Challenge string `json:"challenge"`
+		} `json:"publicKey"`
+	}
+	require.NoError(ta.t, json.Unmarshal(opts.OptionsJSON, &assertion))
+
+	flags := byte(0x01)
+	if userVerified {
+		flags |= 0x04
+	}
+	authData := ta.authData(flags, counter)
+	clientData := ta.clientData("webauthn.get", assertion.PublicKey.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(slices.Clone(authData), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, ta.key, digest[:])
+	require.NoError(ta.t, err)
+
+	return ta.response(map[string]any{
+		"clientDataJSON":    b64(clientData),
+		"authenticatorData": b64(authData),
+		"signature":         b64(signature),
+		"userHandle":        b64(ta.userHandle),
+	})
+}
+
+func (ta *testAuthenticator) authData(flags byte, counter uint32) []byte {
+	rpIDHash := sha256.Sum256([]byte(testRPID))
+
+	data := append(rpIDHash[:], flags)
+
+	return binary.BigEndian.AppendUint32(data, counter)
+}
+
+func (ta *testAuthenticator) clientData(typ, challenge string) []byte {
+	data, err := json.Marshal(map[string]string{"type": typ, "challenge": challenge, "origin": testOrigin})
+	require.NoError(ta.t, err)
+
+	return data
+}
+
+func (ta *testAuthenticator) response(resp map[string]any) []byte {
+	data, err := json.Marshal(map[string]any{
+		"id": b64(ta.id), "rawId": b64(ta.id), "type": "public-key", "response": resp,
+	})
+	require.NoError(ta.t, err)
+
+	return data
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func newTestRelyingParty(t *testing.T) *webauthn.WebAuthn {
+	t.Helper()
+
+	rp, err := webauthn.New(&webauthn.Config{RPID: testRPID, RPDisplayName: "SSO", RPOrigins: []string{testOrigin}})
+	require.NoError(t, err)
+
+	return rp
+}
+
+func TestPasskeys_RegisterAndLogin(t *testing.T) {
+	store := newMemPasskeys()
+	var auditLog bytes.Buffer
+	a, _, provider, apps := newTestService(t,
+		WithPasskeys(store, newTestRelyingParty(t), PasskeySettings{}),
+		WithAudit(audit.New(&auditLog)))
+	ctx := context.Background()
+
+	user := models.User{ID: 7, Email: "a@b.c", IsActive: true}
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	authenticator := newTestAuthenticator(t)
+
+	opts, err := a.BeginPasskeyRegistration(ctx, 7)
+	require.NoError(t, err)
+	credential := authenticator.register(opts)
+
+	// Вызов выдан пользователю 7 - другой пользователь его завершить не может
+	_, err = a.FinishPasskeyRegistration(ctx, 8, opts.SessionID, "Laptop", credential)
+	require.ErrorIs(t, err, ErrPasskeyChallengeNotFound)
+
+	opts, err = a.BeginPasskeyRegistration(ctx, 7)
+	require.NoError(t, err)
+	credential = authenticator.register(opts)
+
+	id, err := a.FinishPasskeyRegistration(ctx, 7, opts.SessionID, "Laptop", credential)
+	require.NoError(t, err)
+	assert.Equal(t, "Laptop", store.passkeys[id].Name)
+	assert.Equal(t, []string{"internal"}, store.passkeys[id].Transports)
+
+	// Вызов одноразовый
+	_, err = a.FinishPasskeyRegistration(ctx, 7, opts.SessionID, "Laptop", credential)
+	require.ErrorIs(t, err, ErrPasskeyChallengeNotFound)
+
+	// Вход без email (discoverable credential)
+	opts, err = a.BeginPasskeyLogin(ctx, "")
+	require.NoError(t, err)
+
+	token, err := a.FinishPasskeyLogin(ctx, opts.SessionID, authenticator.login(opts, 5, true), testApp.ID, "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.AccessToken)
+	assert.Equal(t, uint32(5), store.passkeys[id].SignCount)
+	assert.False(t, store.passkeys[id].LastUsedAt.IsZero())
+	assert.NotContains(t, auditLog.String(), audit.EventPasskeyCloneWarning)
+
+	// Счётчик не вырос - возможна копия ключа: вход проходит, но попадает в журнал безопасности
+	opts, err = a.BeginPasskeyLogin(ctx, "")
+	require.NoError(t, err)
+
+	_, err = a.FinishPasskeyLogin(ctx, opts.SessionID, authenticator.login(opts, 3, true), testApp.ID, "")
+	require.NoError(t, err)
+	assert.Contains(t, auditLog.String(), audit.EventPasskeyCloneWarning)
+	assert.Equal(t, uint32(5), store.passkeys[id].SignCount)
+
+	// Вход по email: браузеру предлагаются только passkey этого пользователя
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	opts, err = a.BeginPasskeyLogin(ctx, "a@b.c")
+	require.NoError(t, err)
+	assert.Contains(t, string(opts.OptionsJSON), b64(authenticator.id))
+
+	_, err = a.FinishPasskeyLogin(ctx, opts.SessionID, authenticator.login(opts, 6, false), testApp.ID, "")
+	require.NoError(t, err)
+	assert.Equal(t, uint32(6), store.passkeys[id].SignCount)
+
+	passkeys, err := a.ListPasskeys(ctx, 7)
+	require.NoError(t, err)
+	require.Len(t, passkeys, 1)
+
+	require.ErrorIs(t, a.DeletePasskey(ctx, 8, id), ErrPasskeyNotFound)
+	require.NoError(t, a.DeletePasskey(ctx, 7, id))
+	require.ErrorIs(t, a.DeletePasskey(ctx, 7, id), ErrPasskeyNotFound)
+}
+
+func TestPasskeys_LoginRejected(t *testing.T) {
+	store := newMemPasskeys()
+	a, _, provider, _ := newTestService(t, WithPasskeys(store, newTestRelyingParty(t), PasskeySettings{}))
+	ctx := context.Background()
+
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, Email: "a@b.c", IsActive: true}, nil)
+
+	registered := newTestAuthenticator(t)
+	opts, err := a.BeginPasskeyRegistration(ctx, 7)
+	require.NoError(t, err)
+	_, err = a.FinishPasskeyRegistration(ctx, 7, opts.SessionID, "", registered.register(opts))
+	require.NoError(t, err)
+
+	// Неизвестная passkey
+	stranger := newTestAuthenticator(t)
+	stranger.userHandle = registered.userHandle
+	opts, err = a.BeginPasskeyLogin(ctx, "")
+	require.NoError(t, err)
+	_, err = a.FinishPasskeyLogin(ctx, opts.SessionID, stranger.login(opts, 1, true), testApp.ID, "")
+	require.ErrorIs(t, err, ErrInvalidPasskey)
+
+	// Подпись на другой вызов
+	first, err := a.BeginPasskeyLogin(ctx, "")
+	require.NoError(t, err)
+	second, err := a.BeginPasskeyLogin(ctx, "")
+	require.NoError(t, err)
+	_, err = a.FinishPasskeyLogin(ctx, second.SessionID, registered.login(first, 1, true), testApp.ID, "")
+	require.ErrorIs(t, err, ErrInvalidPasskey)
+
+	// Истёкший вызов
+	expired, err := a.BeginPasskeyLogin(ctx, "")
+	require.NoError(t, err)
+	s := store.sessions[expired.SessionID]
+	s.ExpiresAt = time.Now().Add(-time.Second)
+	store.sessions[expired.SessionID] = s
+	_, err = a.FinishPasskeyLogin(ctx, expired.SessionID, registered.login(expired, 1, true), testApp.ID, "")
+	require.ErrorIs(t, err, ErrPasskeyChallengeNotFound)
+
+	// Вызов регистрации не годится для входа
+	registration, err := a.BeginPasskeyRegistration(ctx, 7)
+	require.NoError(t, err)
+	_, err = a.FinishPasskeyLogin(ctx, registration.SessionID, registered.login(registration, 1, true), testApp.ID, "")
+	require.ErrorIs(t, err, ErrPasskeyChallengeNotFound)
+}
+
+func TestPasskeys_Limit(t *testing.T) {
+	store := newMemPasskeys()
+	store.passkeys[1] = &models.Passkey{ID: 1, UserID: 7, CredentialID: []byte{1}}
+	a, _, provider, _ := newTestService(t, WithPasskeys(store, newTestRelyingParty(t), PasskeySettings{MaxPerUser: 1}))
+
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, Email: "a@b.c", IsActive: true}, nil)
+
+	_, err := a.BeginPasskeyRegistration(context.Background(), 7)
+	require.ErrorIs(t, err, ErrTooManyPasskeys)
+}
+
+func TestPasskeys_Disabled(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+	ctx := context.Background()
+
+	_, err := a.BeginPasskeyRegistration(ctx, 7)
+	require.ErrorIs(t, err, ErrPasskeysDisabled)
+	_, err = a.BeginPasskeyLogin(ctx, "a@b.c")
+	require.ErrorIs(t, err, ErrPasskeysDisabled)
+	_, err = a.FinishPasskeyLogin(ctx, "nonce", []byte("{}"), testApp.ID, "")
+	require.ErrorIs(t, err, ErrPasskeysDisabled)
+	require.ErrorIs(t, a.DeletePasskey(ctx, 7, 1), ErrPasskeysDisabled)
+}
+
+func TestPasskeyAuthentication(t *testing.T) {
+	amr, acr := passkeyAuthentication(models.Passkey{}, true)
+	assert.Equal(t, []string{models.AMRHardwareKey, models.AMRMultiFactor}, amr)
+	assert.Equal(t, models.ACRMultiFactor, acr)
+
+	amr, acr = passkeyAuthentication(models.Passkey{BackupEligible: true}, false)
+	assert.Equal(t, []string{models.AMRSoftwareKey}, amr)
+	assert.Equal(t, models.ACRSingleFactor, acr)
+}
@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Пароли перед хешированием и сравнением приводятся к NFKC: иначе один и тот же пароль, набранный
+// на разных системах (например, "é" одним символом или буквой с комбинируемым знаком), давал бы разные
+// байты и не подходил к своему хешу.
+//
+// bcrypt учитывает только первые 72 байта. Пароли длиннее (после нормализации) отклоняются, а не
+// хешируются с SHA-256 заранее: предварительное хеширование потребовало бы второго формата хеша,
+// а 72 байт хватает на парольную фразу из латиницы или 36 символов кириллицы.
+// Ограничение проверяется только для новых паролей: при входе сравнивается любой пароль
+const maxPasswordBytes = 72
+
+// ErrPasswordTooLong - новый пароль после нормализации длиннее maxPasswordBytes
+var ErrPasswordTooLong = errors.New("password is longer than 72 bytes")
+
+// normalizePassword - пароль в форме NFKC
+func normalizePassword(password string) string {
+	return norm.NFKC.String(password)
+}
+
+// prepareNewPassword - нормализует новый пароль и проверяет его длину
+func prepareNewPassword(password string) (string, error) {
+	password = normalizePassword(password)
+	if len(password) > maxPasswordBytes {
+		return "", ErrPasswordTooLong
+	}
+
+	return password, nil
+}
+
+// verifyPassword - сверяет пароль с хешем: сначала в форме NFKC, затем как есть - хеши, посчитанные
+// до нормализации, сделаны из исходных байтов. legacy - подошёл только исходный вариант, хеш стоит пересчитать
+func (a *AuthService) verifyPassword(ctx context.Context, hash []byte, password string) (match, legacy bool, err error) {
+	normalized := normalizePassword(password)
+
+	if match, err = a.comparePassword(ctx, hash, normalized); err != nil || match || normalized == password {
+		return match, false, err
+	}
+
+	match, err = a.comparePassword(ctx, hash, password)
+
+	return match, match, err
+}
+
+// dummyHash - хеш, с которым сравнивается пароль входа с неизвестным email (флаг constant_time_login).
+// Считается один раз и с той же стоимостью, что и настоящие хеши
+var dummyHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("constant-time login"), bcrypt.DefaultCost)
+	if err != nil {
+		panic(err)
+	}
+
+	return hash
+})
+
+// weakHash - хеш посчитан со стоимостью ниже текущей и его стоит пересчитать при входе (флаг rehash_on_login)
+func weakHash(hash []byte) bool {
+	cost, err := bcrypt.Cost(hash)
+
+	return err == nil && cost < bcrypt.DefaultCost
+}
+
+// rehashLegacy - заменяет устаревший хеш (ненормализованного пароля или с низкой стоимостью) на хеш
+// NFKC-формы пароля с текущей стоимостью после успешного входа.
+// Ошибки только логируются: вход уже состоялся, а старый хеш продолжает работать
+func (a *AuthService) rehashLegacy(ctx context.Context, log *slog.Logger, user models.User, password string) {
+	normalized, err := prepareNewPassword(password)
+	if err != nil {
+		log.Info("legacy password hash kept", slog.String("reason", err.Error()))
+
+		return
+	}
+
+	hash, err := a.hashPassword(ctx, normalized)
+	if err != nil {
+		log.Warn("failed to rehash legacy password", slog.String("error", err.Error()))
+
+		return
+	}
+
+	if err := a.usrSaver.RehashPassword(ctx, user.ID, user.PassHash, hash); err != nil {
+		log.Warn("failed to rehash legacy password", slog.String("error", err.Error()))
+
+		return
+	}
+
+	log.Info("legacy password hash upgraded")
+}
@@ -0,0 +1,232 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/text/unicode/norm"
+)
+
+// passwordStore - хранилище одного пользователя с историей паролей. Это фейк, а не мок:
+// проверкам смены пароля нужна история, которая меняется от вызова к вызову
+type passwordStore struct {
+	UserSaver
+	UserProvider
+
+	user    models.User
+	history [][]byte
+}
+
+func (p *passwordStore) User(_ context.Context, email string) (models.User, error) {
+	if email != p.user.Email {
+		return models.User{}, storage.ErrUserNotFound
+	}
+
+	return p.user, nil
+}
+
+func (p *passwordStore) PasswordHistory(_ context.Context, _ int64, limit int) ([][]byte, error) {
+	return p.history[:min(limit, len(p.history))], nil
+}
+
+func (p *passwordStore) UpdatePassword(_ context.Context, _ int64, passHash []byte, keep int) error {
+	p.history = append([][]byte{p.user.PassHash}, p.history...)
+	p.history = p.history[:min(keep, len(p.history))]
+	p.user.PassHash = passHash
+	p.user.PasswordChangedAt = time.Now()
+	p.user.ForcePasswordChange = false
+
+	return nil
+}
+
+func (p *passwordStore) RehashPassword(_ context.Context, _ int64, oldHash, newHash []byte) error {
+	if bytes.Equal(p.user.PassHash, oldHash) {
+		p.user.PassHash = newHash
+	}
+
+	return nil
+}
+
+func TestChangePassword_RejectsRecentPasswords(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("first"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	store := &passwordStore{user: models.User{ID: 1, Email: "a@b.c", PassHash: hash}}
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, store, nil, 0, WithPasswordHistory(2))
+	ctx := context.Background()
+
+	require.ErrorIs(t, a.ChangePassword(ctx, "a@b.c", "wrong", "second"), ErrInvalidCredentials)
+	require.ErrorIs(t, a.ChangePassword(ctx, "a@b.c", "first", "first"), ErrPasswordReused)
+
+	require.NoError(t, a.ChangePassword(ctx, "a@b.c", "first", "second"))
+	require.NoError(t, a.ChangePassword(ctx, "a@b.c", "second", "third"))
+	require.ErrorIs(t, a.ChangePassword(ctx, "a@b.c", "third", "first"), ErrPasswordReused)
+
+	// "first" вытеснен из истории после ещё одной смены
+	require.NoError(t, a.ChangePassword(ctx, "a@b.c", "third", "fourth"))
+	assert.NoError(t, a.ChangePassword(ctx, "a@b.c", "fourth", "first"))
+}
+
+func TestLogin_PasswordExpired(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	store := &passwordStore{user: models.User{
+		ID: 1, Email: "a@b.c", PassHash: hash,
+		PasswordChangedAt: time.Now().Add(-91 * 24 * time.Hour),
+	}}
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, store, nil, time.Hour,
+		WithPasswordMaxAge(90*24*time.Hour))
+	ctx := context.Background()
+
+	// Неверный пароль по-прежнему неверный, а не истёкший
+	_, err = a.Login(ctx, "a@b.c", "wrong", 1, "", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = a.Login(ctx, "a@b.c", "secret", 1, "", "", "")
+	require.ErrorIs(t, err, ErrPasswordExpired)
+
+	// Смена пароля со старыми учётными данными разрешена
+	require.NoError(t, a.ChangePassword(ctx, "a@b.c", "secret", "new-secret"))
+
+	// Требование администратора действует независимо от срока
+	store.user.PasswordChangedAt = time.Now()
+	store.user.ForcePasswordChange = true
+	_, err = a.Login(ctx, "a@b.c", "new-secret", 1, "", "", "")
+	require.ErrorIs(t, err, ErrPasswordExpired)
+}
+
+func TestBreachedPasswordsRejected(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	breaches := mocks.NewBreachChecker(t)
+	breaches.EXPECT().IsCompromised(mock.Anything, mock.Anything).RunAndReturn(func(_ context.Context, password string) (bool, error) {
+		return password == "password123", nil
+	})
+
+	store := &passwordStore{user: models.User{ID: 1, Email: "a@b.c", PassHash: hash}}
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, store, nil, time.Hour,
+		WithBreachChecker(breaches))
+	ctx := context.Background()
+
+	_, err = a.RegisterNewUser(ctx, "new@b.c", "password123", "", "", "", 0)
+	require.ErrorIs(t, err, ErrWeakPassword)
+
+	require.ErrorIs(t, a.ChangePassword(ctx, "a@b.c", "secret", "password123"), ErrWeakPassword)
+	require.NoError(t, a.ChangePassword(ctx, "a@b.c", "secret", "unbreached"))
+}
+
+func TestLogin_NormalizesPassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("caf\u00e9"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	store := &passwordStore{user: models.User{ID: 1, Email: "a@b.c", PassHash: hash, PasswordChangedAt: time.Now()}}
+	apps := mocks.NewAppProvider(t)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, store, apps, time.Hour)
+
+	// "é" как буква с комбинируемым знаком (NFD)
+	_, err = a.Login(context.Background(), "a@b.c", "cafe\u0301", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, hash, store.user.PassHash, "hash of normalized input is not touched")
+}
+
+func TestLogin_UpgradesLegacyHash(t *testing.T) {
+	// Хеш посчитан до нормализации из байтов NFD
+	legacy, err := bcrypt.GenerateFromPassword([]byte("cafe\u0301"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	store := &passwordStore{user: models.User{ID: 1, Email: "a@b.c", PassHash: legacy, PasswordChangedAt: time.Now()}}
+	apps := mocks.NewAppProvider(t)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, store, apps, time.Hour)
+	ctx := context.Background()
+
+	_, err = a.Login(ctx, "a@b.c", "caf\u00e9", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrInvalidCredentials, "NFC input does not match raw NFD bytes")
+
+	_, err = a.Login(ctx, "a@b.c", "cafe\u0301", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	require.NotEqual(t, legacy, store.user.PassHash)
+	assert.NoError(t, bcrypt.CompareHashAndPassword(store.user.PassHash, []byte("caf\u00e9")))
+	assert.Empty(t, store.history, "rehash is not a password change")
+
+	// После пересчёта подходит любая форма
+	_, err = a.Login(ctx, "a@b.c", "caf\u00e9", testApp.ID, "", "", "")
+	assert.NoError(t, err)
+}
+
+func TestNewPassword_LengthLimit(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	store := &passwordStore{user: models.User{ID: 1, Email: "a@b.c", PassHash: hash}}
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), store, store, nil, time.Hour)
+	ctx := context.Background()
+
+	_, err = a.RegisterNewUser(ctx, "new@b.c", strings.Repeat("a", 73), "", "", "", 0)
+	require.ErrorIs(t, err, ErrPasswordTooLong)
+
+	// 37 букв кириллицы - 74 байта
+	require.ErrorIs(t, a.ChangePassword(ctx, "a@b.c", "secret", strings.Repeat("я", 37)), ErrPasswordTooLong)
+
+	// Длина считается после нормализации: полноширинные буквы (3 байта) становятся ASCII
+	fullwidth := strings.Repeat("\uff41", 40)
+	require.NoError(t, a.ChangePassword(ctx, "a@b.c", "secret", fullwidth))
+	assert.NoError(t, bcrypt.CompareHashAndPassword(store.user.PassHash, []byte(strings.Repeat("a", 40))))
+}
+
+// FuzzNewPasswordRoundTrip - любой пароль, принятый политикой, подходит к своему хешу - и в исходном
+// виде, и в другой форме Unicode (NFD), - без признака устаревшего хеша.
+// Запуск: go test ./internal/services/auth -fuzz=FuzzNewPasswordRoundTrip
+func FuzzNewPasswordRoundTrip(f *testing.F) {
+	for _, seed := range []string{
+		"secret",
+		"correct horse battery staple",
+		"caf\u00e9",          // é одним символом
+		"cafe\u0301",         // e с комбинируемым знаком
+		"\ufb01re",           // лигатура fi
+		"\uff21\uff22\uff23", // полноширинные буквы
+		"пароль",
+		strings.Repeat("a", maxPasswordBytes),
+		strings.Repeat("я", maxPasswordBytes/2+1), // длиннее лимита в байтах
+		"pass\x00word",
+		"\xff\xfe",
+	} {
+		f.Add(seed)
+	}
+
+	a := New(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, nil, nil, time.Hour)
+
+	f.Fuzz(func(t *testing.T, password string) {
+		prepared, err := prepareNewPassword(password)
+		if err != nil {
+			require.ErrorIs(t, err, ErrPasswordTooLong)
+			return
+		}
+
+		// минимальная стоимость: свойство не зависит от неё, а фаззинг с DefaultCost был бы слишком медленным
+		hash, err := bcrypt.GenerateFromPassword([]byte(prepared), bcrypt.MinCost)
+		require.NoError(t, err)
+
+		for _, form := range []string{password, norm.NFD.String(password)} {
+			match, legacy, err := a.verifyPassword(context.Background(), hash, form)
+			require.NoError(t, err)
+			assert.True(t, match, "password %q does not match its own hash", form)
+			assert.False(t, legacy)
+		}
+	})
+}
@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/events"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"time"
+)
+
+// Ошибки провижининга
+var (
+	ErrProvisioningDisabled    = errors.New("user provisioning is disabled")                // Ошибка, если хранилище провижининга не задано.
+	ErrNotProvisioner          = errors.New("app does not have the provisioner role")       // Ошибка, если у приложения нет роли provisioner.
+	ErrProvisioningConflict    = errors.New("email is already used by another account")     // Ошибка, если email занят аккаунтом с другим external id или без него.
+	ErrProvisionedUserNotFound = errors.New("no user is provisioned with this external id") // Ошибка, если пользователя с таким external id нет.
+)
+
+// ProvisioningStore - пользователи, которых создаёт внешняя система (HR)
+type ProvisioningStore interface {
+	// ProvisionedUser - пользователь по external id (storage.ErrUserNotFound, если его нет).
+	ProvisionedUser(ctx context.Context, externalID string) (models.ProvisionedUser, error)
+	// SaveProvisionedUser - создаёт пользователя без пароля (storage.ErrUserExists, если email занят).
+	SaveProvisionedUser(ctx context.Context, u models.ProvisionedUser) (int64, error)
+	// UpdateProvisionedUser - меняет email и атрибуты и включает пользователя (storage.ErrUserExists, если email занят).
+	UpdateProvisionedUser(ctx context.Context, u models.ProvisionedUser) error
+	// DeprovisionUser - отключает пользователя и отзывает его сессии и offline-токены; возвращает его id.
+	DeprovisionUser(ctx context.Context, externalID string, at time.Time) (int64, error)
+	// ProvisionedUsers - пользователи, которых провижининг менял не раньше since, по возрастанию id после afterID.
+	ProvisionedUsers(ctx context.Context, since time.Time, afterID int64, limit int) ([]models.ProvisionedUser, error)
+}
+
+// WithProvisioning - включает провижининг пользователей внешней системой: ProvisionUser, DeprovisionUser
+// и ListProvisionedUsers. Вызывающий подтверждает себя id и секретом приложения с ролью provisioner
+// (models.App.Provisioner)
+func WithProvisioning(store ProvisioningStore) Option {
+	return func(a *AuthService) {
+		a.provisioning = store
+	}
+}
+
+// ProvisionUser - создаёт или обновляет пользователя с id externalID во внешней системе и включает его.
+// Email, занятый аккаунтом, который провижининг не создавал (или создавал с другим external id), не
+// объединяется с ним: возвращается ErrProvisioningConflict. created - пользователь создан, а не обновлён
+func (a *AuthService) ProvisionUser(
+	ctx context.Context,
+	clientID int,
+	clientSecret, externalID, email string,
+	attributes map[string]string,
+) (models.ProvisionedUser, bool, error) {
+	const op = "Auth.ProvisionUser"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("app_id", clientID),
+		slog.String("external_id", externalID))
+
+	if err := a.authenticateProvisioner(ctx, log, audit.EventUserProvisioned, clientID, clientSecret); err != nil {
+		return models.ProvisionedUser{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	u := models.ProvisionedUser{
+		ExternalID:    externalID,
+		Email:         normalizeEmail(email),
+		Attributes:    attributes,
+		IsActive:      true,
+		ProvisionedAt: a.clock.Now(),
+	}
+
+	var created bool
+	err := a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		existing, err := a.provisioning.ProvisionedUser(ctx, externalID)
+		switch {
+		case errors.Is(err, storage.ErrUserNotFound):
+			created = true
+		case err != nil:
+			return events.Event{}, err
+		}
+
+		// Email может принадлежать только этому же пользователю
+		owner, err := a.usrProvider.User(ctx, u.Email)
+		switch {
+		case err == nil && (created || owner.ID != existing.UserID):
+			return events.Event{}, ErrProvisioningConflict
+		case err != nil && !errors.Is(err, storage.ErrUserNotFound):
+			return events.Event{}, err
+		}
+
+		if created {
+			if u.UserID, err = a.provisioning.SaveProvisionedUser(ctx, u); err != nil {
+				return events.Event{}, err
+			}
+		} else {
+			u.UserID = existing.UserID
+			if err := a.provisioning.UpdateProvisionedUser(ctx, u); err != nil {
+				return events.Event{}, err
+			}
+		}
+
+		e := events.New(ctx, events.TypeUserProvisioned, u.UserID)
+		e.AppID = clientID
+
+		return e, nil
+	})
+	if err != nil {
+		// Уникальный индекс ловит то же, что проверка выше, если аккаунт появился между ними
+		if errors.Is(err, ErrProvisioningConflict) || errors.Is(err, storage.ErrUserExists) ||
+			errors.Is(err, storage.ErrExternalIDExists) {
+			log.Warn("provisioning conflict")
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventUserProvisioned, Outcome: audit.OutcomeFailure,
+				AppID: clientID, Email: u.Email, Reason: "email is used by another account",
+			})
+
+			return models.ProvisionedUser{}, false, fmt.Errorf("%s: %w", op, ErrProvisioningConflict)
+		}
+
+		log.Error("failed to provision user", slog.String("error", err.Error()))
+
+		return models.ProvisionedUser{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Пользователь мог быть отключён: кеш проверки токенов не должен держать старое состояние
+	if a.validation != nil {
+		a.validation.Invalidate(u.UserID)
+	}
+
+	log.Info("user provisioned", slog.Int64("user_id", u.UserID), slog.Bool("created", created))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventUserProvisioned, Outcome: audit.OutcomeSuccess,
+		UserID: u.UserID, AppID: clientID, Email: u.Email,
+	})
+
+	return u, created, nil
+}
+
+// DeprovisionUser - отключает пользователя с id externalID во внешней системе и отзывает его сессии,
+// offline-токены и все выданные ему токены. Возвращает id пользователя
+func (a *AuthService) DeprovisionUser(ctx context.Context, clientID int, clientSecret, externalID string) (int64, error) {
+	const op = "Auth.DeprovisionUser"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("app_id", clientID),
+		slog.String("external_id", externalID))
+
+	if err := a.authenticateProvisioner(ctx, log, audit.EventUserDeprovisioned, clientID, clientSecret); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var userID int64
+	err := a.atomically(ctx, func(ctx context.Context) (events.Event, error) {
+		var err error
+		if userID, err = a.provisioning.DeprovisionUser(ctx, externalID, a.clock.Now()); err != nil {
+			return events.Event{}, err
+		}
+
+		e := events.New(ctx, events.TypeUserDeprovisioned, userID)
+		e.AppID = clientID
+
+		return e, nil
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return 0, fmt.Errorf("%s: %w", op, ErrProvisionedUserNotFound)
+		}
+
+		log.Error("failed to deprovision user", slog.String("error", err.Error()))
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Токены отключённого пользователя должны перестать проходить сразу, а не через TTL кеша
+	if a.validation != nil {
+		a.validation.Invalidate(userID)
+	}
+	a.recordRevocation(ctx, models.Revocation{Kind: models.RevocationUser, UserID: userID})
+
+	log.Info("user deprovisioned", slog.Int64("user_id", userID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventUserDeprovisioned, Outcome: audit.OutcomeSuccess,
+		UserID: userID, AppID: clientID,
+	})
+
+	return userID, nil
+}
+
+// ListProvisionedUsers - пользователи из внешней системы (включая отключённых), которых провижининг менял
+// не раньше since, для сверки с ней: по возрастанию id после afterID, не больше limit
+func (a *AuthService) ListProvisionedUsers(
+	ctx context.Context,
+	clientID int,
+	clientSecret string,
+	since time.Time,
+	afterID int64,
+	limit int,
+) ([]models.ProvisionedUser, error) {
+	const op = "Auth.ListProvisionedUsers"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int("app_id", clientID))
+
+	if err := a.authenticateProvisioner(ctx, log, audit.EventProvisionedUsersListed, clientID, clientSecret); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	users, err := a.provisioning.ProvisionedUsers(ctx, since, afterID, limit)
+	if err != nil {
+		log.Error("failed to list provisioned users", slog.String("error", err.Error()))
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventProvisionedUsersListed, Outcome: audit.OutcomeSuccess,
+		AppID: clientID,
+	})
+
+	return users, nil
+}
+
+// authenticateProvisioner - проверяет, что вызывающий - приложение clientID с секретом clientSecret
+// и ролью provisioner. Отказ попадает в журнал безопасности как неудачное событие event
+func (a *AuthService) authenticateProvisioner(
+	ctx context.Context,
+	log *slog.Logger,
+	event string,
+	clientID int,
+	clientSecret string,
+) error {
+	if a.provisioning == nil {
+		return ErrProvisioningDisabled
+	}
+
+	app, err := a.appProvider.App(ctx, clientID)
+	if err != nil && !errors.Is(err, storage.ErrAppNotFound) {
+		return err
+	}
+
+	var (
+		reason string
+		denied error
+	)
+	switch {
+	case err != nil, subtle.ConstantTimeCompare([]byte(clientSecret), []byte(app.Secret)) != 1:
+		reason, denied = "invalid client credentials", ErrInvalidClient
+	case !app.Provisioner:
+		reason, denied = "app is not a provisioner", ErrNotProvisioner
+	default:
+		return nil
+	}
+
+	log.Warn("provisioning request rejected", slog.String("reason", reason))
+	a.audit.Emit(ctx, audit.Event{
+		Name: event, Outcome: audit.OutcomeFailure,
+		AppID: clientID, Reason: reason,
+	})
+
+	return denied
+}
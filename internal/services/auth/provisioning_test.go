@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// provisionerApp - приложение HR-системы с ролью provisioner
+var provisionerApp = models.App{ID: 5, Name: "hr", Secret: "hr-secret", Provisioner: true}
+
+func TestProvisionUser_Creates(t *testing.T) {
+	store := mocks.NewProvisioningStore(t)
+	a, _, provider, apps := newTestService(t, WithProvisioning(store))
+
+	apps.EXPECT().App(mock.Anything, provisionerApp.ID).Return(provisionerApp, nil)
+	store.EXPECT().ProvisionedUser(mock.Anything, "emp-1").
+		Return(models.ProvisionedUser{}, fmt.Errorf("wrapped: %w", storage.ErrUserNotFound))
+	provider.EXPECT().User(mock.Anything, "new@corp.io").Return(models.User{}, storage.ErrUserNotFound)
+	store.EXPECT().SaveProvisionedUser(mock.Anything, mock.MatchedBy(func(u models.ProvisionedUser) bool {
+		return u.ExternalID == "emp-1" && u.Email == "new@corp.io" && u.Attributes["dept"] == "sales"
+	})).Return(42, nil)
+
+	user, created, err := a.ProvisionUser(context.Background(), provisionerApp.ID, "hr-secret",
+		"emp-1", " New@Corp.io ", map[string]string{"dept": "sales"})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, int64(42), user.UserID)
+	assert.True(t, user.IsActive)
+}
+
+func TestProvisionUser_UpdatesByExternalID(t *testing.T) {
+	store := mocks.NewProvisioningStore(t)
+	a, _, provider, apps := newTestService(t, WithProvisioning(store))
+
+	apps.EXPECT().App(mock.Anything, provisionerApp.ID).Return(provisionerApp, nil)
+	store.EXPECT().ProvisionedUser(mock.Anything, "emp-1").
+		Return(models.ProvisionedUser{UserID: 42, ExternalID: "emp-1", Email: "old@corp.io"}, nil)
+	// Email уже принадлежит этому же пользователю - это не конфликт
+	provider.EXPECT().User(mock.Anything, "old@corp.io").Return(models.User{ID: 42}, nil)
+	store.EXPECT().UpdateProvisionedUser(mock.Anything, mock.MatchedBy(func(u models.ProvisionedUser) bool {
+		return u.UserID == 42 && u.Email == "old@corp.io"
+	})).Return(nil)
+
+	user, created, err := a.ProvisionUser(context.Background(), provisionerApp.ID, "hr-secret", "emp-1", "old@corp.io", nil)
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, int64(42), user.UserID)
+}
+
+func TestProvisionUser_EmailConflict(t *testing.T) {
+	store := mocks.NewProvisioningStore(t)
+	a, _, provider, apps := newTestService(t, WithProvisioning(store))
+
+	apps.EXPECT().App(mock.Anything, provisionerApp.ID).Return(provisionerApp, nil)
+
+	// Email занят аккаунтом, зарегистрированным самостоятельно: объединять нельзя
+	store.EXPECT().ProvisionedUser(mock.Anything, "emp-1").Return(models.ProvisionedUser{}, storage.ErrUserNotFound).Once()
+	provider.EXPECT().User(mock.Anything, "taken@corp.io").Return(models.User{ID: 9}, nil).Once()
+	_, _, err := a.ProvisionUser(context.Background(), provisionerApp.ID, "hr-secret", "emp-1", "taken@corp.io", nil)
+	require.ErrorIs(t, err, ErrProvisioningConflict)
+
+	// Смена email на адрес другого пользователя
+	store.EXPECT().ProvisionedUser(mock.Anything, "emp-2").Return(models.ProvisionedUser{UserID: 42}, nil).Once()
+	provider.EXPECT().User(mock.Anything, "taken@corp.io").Return(models.User{ID: 9}, nil).Once()
+	_, _, err = a.ProvisionUser(context.Background(), provisionerApp.ID, "hr-secret", "emp-2", "taken@corp.io", nil)
+	require.ErrorIs(t, err, ErrProvisioningConflict)
+
+	// Аккаунт появился между проверкой и записью: ошибку уникальности отдаёт хранилище
+	store.EXPECT().ProvisionedUser(mock.Anything, "emp-3").Return(models.ProvisionedUser{}, storage.ErrUserNotFound).Once()
+	provider.EXPECT().User(mock.Anything, "race@corp.io").Return(models.User{}, storage.ErrUserNotFound).Once()
+	store.EXPECT().SaveProvisionedUser(mock.Anything, mock.Anything).Return(0, storage.ErrUserExists).Once()
+	_, _, err = a.ProvisionUser(context.Background(), provisionerApp.ID, "hr-secret", "emp-3", "race@corp.io", nil)
+	require.ErrorIs(t, err, ErrProvisioningConflict)
+}
+
+func TestProvisioning_Authentication(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+	_, err := a.DeprovisionUser(context.Background(), provisionerApp.ID, "hr-secret", "emp-1")
+	require.ErrorIs(t, err, ErrProvisioningDisabled)
+
+	store := mocks.NewProvisioningStore(t)
+	a, _, _, apps := newTestService(t, WithProvisioning(store))
+
+	apps.EXPECT().App(mock.Anything, provisionerApp.ID).Return(provisionerApp, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	apps.EXPECT().App(mock.Anything, 99).Return(models.App{}, storage.ErrAppNotFound)
+
+	_, err = a.DeprovisionUser(context.Background(), provisionerApp.ID, "wrong", "emp-1")
+	require.ErrorIs(t, err, ErrInvalidClient)
+	_, err = a.DeprovisionUser(context.Background(), 99, "hr-secret", "emp-1")
+	require.ErrorIs(t, err, ErrInvalidClient)
+
+	// Верный секрет приложения без роли provisioner
+	_, err = a.ListProvisionedUsers(context.Background(), testApp.ID, testApp.Secret, time.Time{}, 0, 10)
+	require.ErrorIs(t, err, ErrNotProvisioner)
+}
+
+func TestDeprovisionUser(t *testing.T) {
+	store := mocks.NewProvisioningStore(t)
+	revocations := mocks.NewRevocationStore(t)
+	a, _, _, apps := newTestService(t, WithProvisioning(store), WithRevocationLog(revocations, nil))
+
+	apps.EXPECT().App(mock.Anything, provisionerApp.ID).Return(provisionerApp, nil)
+	store.EXPECT().DeprovisionUser(mock.Anything, "emp-1", mock.Anything).Return(42, nil).Once()
+	revocations.EXPECT().AddRevocation(mock.Anything, mock.MatchedBy(func(r models.Revocation) bool {
+		return r.Kind == models.RevocationUser && r.UserID == 42
+	})).Return(int64(1), nil)
+
+	userID, err := a.DeprovisionUser(context.Background(), provisionerApp.ID, "hr-secret", "emp-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), userID)
+
+	store.EXPECT().DeprovisionUser(mock.Anything, "ghost", mock.Anything).Return(0, storage.ErrUserNotFound).Once()
+	_, err = a.DeprovisionUser(context.Background(), provisionerApp.ID, "hr-secret", "ghost")
+	require.ErrorIs(t, err, ErrProvisionedUserNotFound)
+}
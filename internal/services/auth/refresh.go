@@ -0,0 +1,330 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Значения по умолчанию для WithRefreshTokens
+const (
+	DefaultRefreshSlidingWindow = 7 * 24 * time.Hour  // Без обновления за это время refresh-токен истекает
+	DefaultRefreshMaxLifetime   = 30 * 24 * time.Hour // Дальше этого срока от входа обновление невозможно
+)
+
+// Ошибки refresh-токенов
+var (
+	ErrRefreshDisabled     = errors.New("refresh tokens are disabled")                               // Ошибка, если хранилище refresh-токенов не задано.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")                                     // Ошибка, если токена нет, он отозван или уже заменён.
+	ErrRefreshTokenExpired = errors.New("refresh token expired, a full login is required")           // Ошибка, если токен не обновляли дольше скользящего окна.
+	ErrReloginRequired     = errors.New("refresh token lifetime exceeded, a full login is required") // Ошибка, если истёк максимальный срок от входа или сессия.
+)
+
+// RefreshTokenStore - хранилище цепочек refresh-токенов
+type RefreshTokenStore interface {
+	// SaveRefreshFamily - сохраняет цепочку нового входа.
+	SaveRefreshFamily(ctx context.Context, f models.RefreshFamily) error
+	// RefreshFamily - цепочка по id, включая отозванные (storage.ErrRefreshFamilyNotFound, если её нет).
+	RefreshFamily(ctx context.Context, id string) (models.RefreshFamily, error)
+	// RotateRefreshToken - заменяет секрет oldHash на newHash и сдвигает срок цепочки на expiresAt
+	// (storage.ErrRefreshFamilyNotFound, если секрет уже заменён или цепочка отозвана).
+	RotateRefreshToken(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error
+	// RevokeRefreshFamily - отзывает цепочку в момент at.
+	RevokeRefreshFamily(ctx context.Context, id string, at time.Time) error
+}
+
+// RefreshSettings - окна refresh-токенов по умолчанию; приложение может задать свои
+// (models.App.RefreshSlidingWindow, models.App.RefreshMaxLifetime). Нулевые значения заменяются значениями по умолчанию
+type RefreshSettings struct {
+	SlidingWindow time.Duration // Каждое обновление продлевает токен на это время
+	MaxLifetime   time.Duration // Предел от входа, дальше которого окно не сдвигается
+}
+
+// WithRefreshTokens - вход выдаёт вместе с токеном доступа refresh-токен, а RefreshToken меняет его на новую пару.
+// Сессия входа живёт до предела refresh-токена, а не до конца токена доступа
+func WithRefreshTokens(store RefreshTokenStore, s RefreshSettings) Option {
+	return func(a *AuthService) {
+		if s.SlidingWindow <= 0 {
+			s.SlidingWindow = DefaultRefreshSlidingWindow
+		}
+		if s.MaxLifetime <= 0 {
+			s.MaxLifetime = DefaultRefreshMaxLifetime
+		}
+		a.refresh = store
+		a.refreshSettings = s
+	}
+}
+
+// refreshWindows - скользящее окно и максимальный срок refresh-токенов приложения
+func (a *AuthService) refreshWindows(app models.App) (sliding, maxLifetime time.Duration) {
+	sliding, maxLifetime = a.refreshSettings.SlidingWindow, a.refreshSettings.MaxLifetime
+	if app.RefreshSlidingWindow > 0 {
+		sliding = app.RefreshSlidingWindow
+	}
+	if app.RefreshMaxLifetime > 0 {
+		maxLifetime = app.RefreshMaxLifetime
+	}
+
+	return sliding, maxLifetime
+}
+
+// slideRefresh - новый конец скользящего окна: now+sliding, но не позже absolute
+func slideRefresh(now time.Time, sliding time.Duration, absolute time.Time) time.Time {
+	if next := now.Add(sliding); next.Before(absolute) {
+		return next
+	}
+
+	return absolute
+}
+
+// startRefreshFamily - заводит цепочку refresh-токенов входа, которому выданы claims, и дописывает
+// её токен в token. Предел цепочки absolute считается от начала входа
+func (a *AuthService) startRefreshFamily(
+	ctx context.Context,
+	token *models.Token,
+	claims jwt.Claims,
+	app models.App,
+	absolute time.Time,
+) error {
+	secret, err := newRefreshSecret()
+	if err != nil {
+		return err
+	}
+
+	now := a.clock.Now()
+	sliding, _ := a.refreshWindows(app)
+	family := models.RefreshFamily{
+		ID:                uuid.NewString(),
+		UserID:            claims.UID,
+		AppID:             app.ID,
+		SessionID:         claims.SessionID,
+		SecretHash:        hashRefreshSecret(secret),
+		Scopes:            strings.Fields(claims.Scope),
+		OrgID:             claims.OrgID,
+		AMR:               claims.AMR,
+		ACR:               claims.ACR,
+		CreatedAt:         now,
+		ExpiresAt:         slideRefresh(now, sliding, absolute),
+		AbsoluteExpiresAt: absolute,
+	}
+	if claims.AuthTime != nil {
+		family.AuthTime = claims.AuthTime.Time
+	}
+	if err := a.refresh.SaveRefreshFamily(ctx, family); err != nil {
+		return err
+	}
+
+	token.RefreshToken = family.ID + "." + secret
+	token.RefreshExpiresAt = family.ExpiresAt
+
+	return nil
+}
+
+// RefreshToken - меняет refreshToken на новый токен доступа и новый refresh-токен той же цепочки; прежний
+// refresh-токен перестаёт действовать. Срок цепочки сдвигается на скользящее окно, но не дальше предела от входа:
+// после предела - ErrReloginRequired, без обновления дольше окна - ErrRefreshTokenExpired. Повторное предъявление
+// уже заменённого токена - признак кражи: цепочка отзывается целиком (ErrInvalidRefreshToken)
+func (a *AuthService) RefreshToken(ctx context.Context, refreshToken string) (models.Token, error) {
+	const op = "Auth.RefreshToken"
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+
+	if a.refresh == nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrRefreshDisabled)
+	}
+
+	id, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || id == "" || secret == "" {
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+	}
+
+	family, err := a.refresh.RefreshFamily(ctx, id)
+	if err != nil {
+		if errors.Is(err, storage.ErrRefreshFamilyNotFound) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	log = log.With(slog.Int64("user_id", family.UserID), slog.Int("app_id", family.AppID))
+
+	now := a.clock.Now()
+	switch {
+	case !family.RevokedAt.IsZero():
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+	case !now.Before(family.AbsoluteExpiresAt):
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrReloginRequired)
+	case !now.Before(family.ExpiresAt):
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrRefreshTokenExpired)
+	}
+
+	hash := hashRefreshSecret(secret)
+	if subtle.ConstantTimeCompare([]byte(hash), []byte(family.SecretHash)) != 1 {
+		a.revokeReusedFamily(ctx, log, family)
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, family.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.Token{}, fmt.Errorf("%s: %w: user not found", op, ErrInvalidRefreshToken)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.IsActive {
+		return models.Token{}, fmt.Errorf("%s: %w: user is not active", op, ErrInvalidRefreshToken)
+	}
+
+	app, err := a.appProvider.App(ctx, family.AppID)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// Обновление - тоже выдача: окно времени или сеть могли перестать подходить после входа
+	if err := a.checkLoginPolicies(ctx, log, audit.EventTokenRefreshFailed, app, user.ID, user.Email); err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Завершённая сессия завершает и цепочку; истёкшая по тайм-аутам приложения требует нового входа
+	if err := a.checkSession(ctx, family.SessionID, app); err != nil {
+		if errors.Is(err, ErrSessionExpired) {
+			return models.Token{}, fmt.Errorf("%s: %w: session expired", op, ErrReloginRequired)
+		}
+		if errors.Is(err, ErrInvalidToken) {
+			return models.Token{}, fmt.Errorf("%s: %w: session ended", op, ErrInvalidRefreshToken)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	opts := []jwt.TokenOption{jwt.WithAuthentication(family.AMR, family.ACR, family.AuthTime)}
+	if family.SessionID != "" {
+		opts = append(opts, jwt.WithSession(family.SessionID))
+	}
+	if len(family.Scopes) > 0 {
+		opts = append(opts, jwt.WithScope(family.Scopes))
+	}
+	// Роль берётся из БД, как и при проверке токена: исключённый участник обновить токен не может
+	if family.OrgID != 0 {
+		if a.orgs == nil {
+			return models.Token{}, fmt.Errorf("%s: %w: organizations are disabled", op, ErrInvalidRefreshToken)
+		}
+
+		role, err := a.orgs.MemberRole(ctx, family.OrgID, user.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotMember) {
+				return models.Token{}, fmt.Errorf("%s: %w: not a member of the organization", op, ErrInvalidRefreshToken)
+			}
+
+			return models.Token{}, fmt.Errorf("%s: %w", op, err)
+		}
+		opts = append(opts, jwt.WithOrg(family.OrgID, role))
+	}
+	if app.ThirdParty {
+		if _, err := a.checkConsent(ctx, user.ID, app.ID, time.Time{}); err != nil {
+			if errors.Is(err, ErrConsentRequired) {
+				return models.Token{}, fmt.Errorf("%s: %w: consent revoked", op, ErrInvalidRefreshToken)
+			}
+
+			return models.Token{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	token, err := a.issueToken(ctx, user, app, opts...)
+	if err != nil {
+		log.Error("failed to create token", slog.String("error", err.Error()))
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	next, err := newRefreshSecret()
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	sliding, _ := a.refreshWindows(app)
+	expiresAt := slideRefresh(now, sliding, family.AbsoluteExpiresAt)
+
+	// Секрет меняется, только если его не заменил параллельный запрос с тем же токеном: иначе токен предъявлен дважды
+	if err := a.refresh.RotateRefreshToken(ctx, family.ID, family.SecretHash, hashRefreshSecret(next), expiresAt); err != nil {
+		if errors.Is(err, storage.ErrRefreshFamilyNotFound) {
+			a.revokeReusedFamily(ctx, log, family)
+
+			return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidRefreshToken)
+		}
+		log.Error("failed to rotate refresh token", slog.String("error", err.Error()))
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	token.SessionID = family.SessionID
+	token.RefreshToken = family.ID + "." + next
+	token.RefreshExpiresAt = expiresAt
+	token.StepUpRequired = !acrSatisfies(family.ACR, app.MinACR)
+
+	log.Info("token refreshed")
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventTokenRefreshed, Outcome: audit.OutcomeSuccess,
+		UserID: user.ID, Email: user.Email, AppID: app.ID,
+	})
+
+	return token, nil
+}
+
+// revokeReusedFamily - отзывает цепочку, чей уже заменённый токен предъявлен снова, вместе с её сессией:
+// токен мог быть украден, и какая из сторон законная, неизвестно
+func (a *AuthService) revokeReusedFamily(ctx context.Context, log *slog.Logger, family models.RefreshFamily) {
+	now := a.clock.Now()
+	if err := a.refresh.RevokeRefreshFamily(ctx, family.ID, now); err != nil {
+		log.Error("failed to revoke refresh token family", slog.String("error", err.Error()))
+	}
+	if family.SessionID != "" && a.sessions != nil {
+		err := a.sessions.RevokeSession(ctx, family.SessionID, now)
+		if err != nil && !errors.Is(err, storage.ErrSessionNotFound) {
+			log.Error("failed to revoke session of reused refresh token", slog.String("error", err.Error()))
+		}
+		if err == nil {
+			a.recordRevocation(ctx, models.Revocation{
+				Kind: models.RevocationSession, UserID: family.UserID, AppID: family.AppID, SessionID: family.SessionID,
+			})
+		}
+	}
+
+	log.Warn("refresh token reuse detected", slog.String("family_id", family.ID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventTokenRefreshFailed, Outcome: audit.OutcomeFailure,
+		UserID: family.UserID, AppID: family.AppID, Reason: "refresh token reuse",
+	})
+}
+
+// newRefreshSecret - случайный секрет refresh-токена (256 бит)
+func newRefreshSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashRefreshSecret - как и токен приглашения, секрет хранится только в виде SHA-256
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
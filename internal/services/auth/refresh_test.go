@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/clock"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type memRefresh map[string]*models.RefreshFamily
+
+func (m memRefresh) SaveRefreshFamily(_ context.Context, f models.RefreshFamily) error {
+	m[f.ID] = &f
+
+	return nil
+}
+
+func (m memRefresh) RefreshFamily(_ context.Context, id string) (models.RefreshFamily, error) {
+	f, ok := m[id]
+	if !ok {
+		return models.RefreshFamily{}, fmt.Errorf("storage.mem: %w", storage.ErrRefreshFamilyNotFound)
+	}
+
+	return *f, nil
+}
+
+func (m memRefresh) RotateRefreshToken(_ context.Context, id, oldHash, newHash string, expiresAt time.Time) error {
+	f, ok := m[id]
+	if !ok || f.SecretHash != oldHash || !f.RevokedAt.IsZero() {
+		return fmt.Errorf("storage.mem: %w", storage.ErrRefreshFamilyNotFound)
+	}
+	f.SecretHash, f.ExpiresAt = newHash, expiresAt
+
+	return nil
+}
+
+func (m memRefresh) RevokeRefreshFamily(_ context.Context, id string, at time.Time) error {
+	if f, ok := m[id]; ok && f.RevokedAt.IsZero() {
+		f.RevokedAt = at
+	}
+
+	return nil
+}
+
+// refreshApp - приложение с короткими окнами: обновлять раз в час, не дольше трёх часов от входа
+var refreshApp = func() models.App {
+	app := testApp
+	app.RefreshSlidingWindow = time.Hour
+	app.RefreshMaxLifetime = 3 * time.Hour
+
+	return app
+}()
+
+// loginWithRefresh - вход в refreshApp на часах now; возвращает сервис, хранилище цепочек и токен входа
+func loginWithRefresh(t *testing.T, now *clock.Fake, opts ...Option) (*AuthService, memRefresh, models.Token) {
+	t.Helper()
+
+	families := memRefresh{}
+	opts = append([]Option{WithClock(now), WithRefreshTokens(families, RefreshSettings{})}, opts...)
+	a, _, provider, apps := newTestService(t, opts...)
+	user := userWithPassword(t, "secret")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil).Maybe()
+	apps.EXPECT().App(mock.Anything, refreshApp.ID).Return(refreshApp, nil)
+
+	token, err := a.Login(context.Background(), "a@b.c", "secret", refreshApp.ID, "", "", "")
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+
+	// Срок в ответе - тот же, что записан в цепочке
+	for id, f := range families {
+		assert.True(t, strings.HasPrefix(token.RefreshToken, id+"."))
+		assert.Equal(t, f.ExpiresAt, token.RefreshExpiresAt)
+	}
+
+	return a, families, token
+}
+
+func TestRefreshToken_SlidingWindowCappedAtMaxLifetime(t *testing.T) {
+	now := clock.NewFake(clockStart)
+	a, _, login := loginWithRefresh(t, now)
+	ctx := context.Background()
+
+	assert.Equal(t, clockStart.Add(time.Hour), login.RefreshExpiresAt.UTC())
+
+	// Каждое обновление сдвигает окно на час от момента обновления
+	now.Advance(50 * time.Minute)
+	first, err := a.RefreshToken(ctx, login.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, clockStart.Add(110*time.Minute), first.RefreshExpiresAt.UTC())
+	assert.NotEqual(t, login.RefreshToken, first.RefreshToken)
+
+	claims, err := jwt.ParseForApp(first.AccessToken, refreshApp, jwt.WithClock(now.Now))
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.AMRPassword}, claims.AMR, "the new access token keeps how the user logged in")
+	assert.Equal(t, clockStart, claims.AuthTime.UTC())
+
+	now.Advance(50 * time.Minute)
+	second, err := a.RefreshToken(ctx, first.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, clockStart.Add(160*time.Minute), second.RefreshExpiresAt.UTC())
+
+	// Сдвиг на час вышел бы за три часа от входа: окно упирается в предел
+	now.Advance(50 * time.Minute)
+	capped, err := a.RefreshToken(ctx, second.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, clockStart.Add(3*time.Hour), capped.RefreshExpiresAt.UTC())
+
+	// На пределе обновление невозможно, даже если окно ещё открыто: нужен новый вход
+	now.Set(clockStart.Add(3 * time.Hour))
+	_, err = a.RefreshToken(ctx, capped.RefreshToken)
+	require.ErrorIs(t, err, ErrReloginRequired)
+}
+
+func TestRefreshToken_SlidingWindowExpires(t *testing.T) {
+	now := clock.NewFake(clockStart)
+	a, _, login := loginWithRefresh(t, now)
+
+	now.Advance(time.Hour)
+	_, err := a.RefreshToken(context.Background(), login.RefreshToken)
+	require.ErrorIs(t, err, ErrRefreshTokenExpired)
+}
+
+func TestRefreshToken_ReuseRevokesFamily(t *testing.T) {
+	now := clock.NewFake(clockStart)
+	a, families, login := loginWithRefresh(t, now)
+	ctx := context.Background()
+
+	rotated, err := a.RefreshToken(ctx, login.RefreshToken)
+	require.NoError(t, err)
+
+	// Заменённый токен предъявлен снова: отзывается вся цепочка, включая законный новый токен
+	_, err = a.RefreshToken(ctx, login.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+
+	for _, f := range families {
+		assert.False(t, f.RevokedAt.IsZero())
+	}
+	_, err = a.RefreshToken(ctx, rotated.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRefreshToken_EndedSession(t *testing.T) {
+	now := clock.NewFake(clockStart)
+	sessions := mocks.NewSessionStore(t)
+
+	var session models.Session
+	sessions.EXPECT().CreateSession(mock.Anything, mock.Anything, 0, false).
+		RunAndReturn(func(_ context.Context, s models.Session, _ int, _ bool) ([]string, error) {
+			session = s
+
+			return nil, nil
+		})
+	a, _, login := loginWithRefresh(t, now, WithSessions(sessions))
+
+	// Сессия живёт до предела refresh-токенов, а не до конца токена доступа
+	assert.Equal(t, clockStart.Add(3*time.Hour), session.ExpiresAt)
+	assert.Equal(t, session.ID, login.SessionID)
+
+	session.RevokedAt = clockStart
+	sessions.EXPECT().Session(mock.Anything, session.ID).Return(session, nil)
+
+	_, err := a.RefreshToken(context.Background(), login.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidRefreshToken)
+}
+
+func TestRefreshToken_Rejected(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+	_, err := a.RefreshToken(context.Background(), "family.secret")
+	require.ErrorIs(t, err, ErrRefreshDisabled)
+
+	a, _, _, _ = newTestService(t, WithRefreshTokens(memRefresh{}, RefreshSettings{}))
+	for _, token := range []string{"", "no-separator", "unknown.secret"} {
+		_, err := a.RefreshToken(context.Background(), token)
+		require.ErrorIs(t, err, ErrInvalidRefreshToken, token)
+	}
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/logctx"
+)
+
+// RevocationStore - журнал отзывов токенов, из которого читает поток WatchRevocations
+type RevocationStore interface {
+	// AddRevocation - записывает отзыв и возвращает его seq.
+	AddRevocation(ctx context.Context, r models.Revocation) (int64, error)
+}
+
+// WithRevocationLog - отзывы сессий и токенов пользователя записываются в журнал, чтобы сервисы,
+// кеширующие результат ValidateToken, узнавали о них сразу. notify вызывается после каждой записи.
+func WithRevocationLog(store RevocationStore, notify func()) Option {
+	return func(a *AuthService) {
+		a.revocations = store
+		a.revocationNotify = notify
+	}
+}
+
+// recordRevocation - записывает отзыв в журнал. Сам отзыв к этому моменту уже действует,
+// поэтому ошибка записи только логируется: потребители узнают о нём при следующей проверке токена
+func (a *AuthService) recordRevocation(ctx context.Context, r models.Revocation) {
+	if a.revocations == nil {
+		return
+	}
+
+	r.RevokedAt = a.clock.Now()
+	if _, err := a.revocations.AddRevocation(ctx, r); err != nil {
+		logctx.FromOr(ctx, a.log).Error("failed to record revocation",
+			slog.String("kind", r.Kind),
+			slog.Int64("user_id", r.UserID),
+			slog.String("error", err.Error()))
+
+		return
+	}
+
+	if a.revocationNotify != nil {
+		a.revocationNotify()
+	}
+}
@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/selftest"
+	"sso/internal/storage"
+	"time"
+)
+
+// selfTestEmailDomain - домен адресов пользователей самопроверки: зарезервирован (RFC 2606), письма на него не уходят
+const selfTestEmailDomain = "sso.invalid"
+
+// Ошибки самопроверки
+var (
+	ErrSelfTestDisabled    = errors.New("self-test is disabled")  // Ошибка, если хранилище самопроверки не задано.
+	ErrSelfTestCheckFailed = errors.New("self-test check failed") // Ошибка, если шаг выполнился, но результат не тот, что ожидался.
+	errPreviousStepFailed  = errors.New("not run: a previous step failed")
+)
+
+// SelfTestStore - приложение и пользователь самопроверки
+type SelfTestStore interface {
+	// SaveSelfTestApp - регистрирует приложение самопроверки и возвращает его id.
+	SaveSelfTestApp(ctx context.Context, name, secret string, at time.Time) (int, error)
+	// MarkSelfTestUser - помечает пользователя как созданного самопроверкой (storage.ErrUserNotFound).
+	MarkSelfTestUser(ctx context.Context, userID int64, at time.Time) error
+	// SetEmailVerified - подтверждает email пользователя самопроверки без письма (storage.ErrUserNotFound).
+	SetEmailVerified(ctx context.Context, userID int64) error
+	// DeleteSelfTestData - удаляет пользователя и приложение самопроверки со всеми их записями.
+	DeleteSelfTestData(ctx context.Context, userID int64, appID int) error
+}
+
+// WithSelfTest - включает самопроверку RunSelfTest: сценарий входа на работающем сервисе с временными
+// приложением и пользователем
+func WithSelfTest(store SelfTestStore) Option {
+	return func(a *AuthService) {
+		a.selfTest = store
+	}
+}
+
+// RunSelfTest - проходит сценарий пользователя на работающем сервисе: создаёт временное приложение,
+// регистрирует пользователя selftest+<nonce>@sso.invalid, подтверждает email, входит, проверяет токен,
+// обменивает refresh-токен, меняет пароль, выходит и удаляет аккаунт. Каждый шаг выполняется через те же методы, что и обычные запросы;
+// после первого упавшего шага остальные пропускаются. Тестовые данные удаляются в конце в любом случае,
+// в статистику и экспорт журнала безопасности они не попадают, события о них не публикуются.
+// Ошибка возвращается, только если самопроверка выключена; результат шагов - в отчёте
+func (a *AuthService) RunSelfTest(ctx context.Context) (models.SelfTestReport, error) {
+	const op = "Auth.RunSelfTest"
+
+	if a.selfTest == nil {
+		return models.SelfTestReport{}, fmt.Errorf("%s: %w", op, ErrSelfTestDisabled)
+	}
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+	// Каждый шаг читает то, что записал предыдущий: закрепление за основной БД действует на весь прогон
+	ctx = storage.WithPinScope(selftest.Into(ctx))
+
+	run := &selfTestRun{report: models.SelfTestReport{StartedAt: a.clock.Now()}}
+	var (
+		appID           int
+		userID          int64
+		email           string
+		password, fresh string
+		token           models.Token
+	)
+
+	run.step(ctx, models.SelfTestCreateApp, func(ctx context.Context) error {
+		nonce := make([]byte, 8)
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		secret, err := selfTestSecret(32)
+		if err != nil {
+			return err
+		}
+		email = "selftest+" + hex.EncodeToString(nonce) + "@" + selfTestEmailDomain
+
+		appID, err = a.selfTest.SaveSelfTestApp(ctx, "selftest-"+hex.EncodeToString(nonce), secret, a.clock.Now())
+
+		return err
+	})
+	run.step(ctx, models.SelfTestRegister, func(ctx context.Context) error {
+		var err error
+		if password, err = selfTestSecret(24); err != nil {
+			return err
+		}
+		if userID, err = a.RegisterNewUser(ctx, email, password, "", a.TOSVersion(), "", appID); err != nil {
+			return err
+		}
+
+		return a.selfTest.MarkSelfTestUser(ctx, userID, a.clock.Now())
+	})
+	run.step(ctx, models.SelfTestVerifyEmail, func(ctx context.Context) error {
+		return a.selfTest.SetEmailVerified(ctx, userID)
+	})
+	run.step(ctx, models.SelfTestLogin, func(ctx context.Context) error {
+		var err error
+		token, err = a.selfTestLogin(ctx, email, password, appID)
+
+		return err
+	})
+	run.step(ctx, models.SelfTestValidate, func(ctx context.Context) error {
+		p, err := a.Authenticate(ctx, token.AccessToken)
+		if err != nil {
+			return err
+		}
+		if p.UserID != userID || p.AppID != appID {
+			return fmt.Errorf("%w: token issued for user %d in app %d", ErrSelfTestCheckFailed, p.UserID, p.AppID)
+		}
+
+		return nil
+	})
+	run.step(ctx, models.SelfTestRefresh, func(ctx context.Context) error {
+		if a.refresh == nil {
+			// Refresh-токены выключены: клиенты входят заново, проверять нечего
+			return skipStep{ErrRefreshDisabled}
+		}
+		if token.RefreshToken == "" {
+			return fmt.Errorf("%w: login did not issue a refresh token", ErrSelfTestCheckFailed)
+		}
+
+		refreshed, err := a.RefreshToken(ctx, token.RefreshToken)
+		if err != nil {
+			return err
+		}
+		p, err := a.Authenticate(ctx, refreshed.AccessToken)
+		if err != nil {
+			return err
+		}
+		if p.UserID != userID || p.AppID != appID {
+			return fmt.Errorf("%w: refreshed token issued for user %d in app %d", ErrSelfTestCheckFailed, p.UserID, p.AppID)
+		}
+
+		// Обменянный токен второй раз не принимается
+		if _, err := a.RefreshToken(ctx, token.RefreshToken); !errors.Is(err, ErrInvalidRefreshToken) {
+			return fmt.Errorf("%w: refresh token is accepted after rotation", ErrSelfTestCheckFailed)
+		}
+
+		return nil
+	})
+	run.step(ctx, models.SelfTestChangePassword, func(ctx context.Context) error {
+		var err error
+		if fresh, err = selfTestSecret(24); err != nil {
+			return err
+		}
+		if err := a.ChangePassword(ctx, email, password, fresh); err != nil {
+			return err
+		}
+
+		// Вход с новым паролем подтверждает, что он сохранён
+		token, err = a.selfTestLogin(ctx, email, fresh, appID)
+
+		return err
+	})
+	run.step(ctx, models.SelfTestLogout, func(ctx context.Context) error {
+		if _, err := a.RevokeToken(ctx, token.AccessToken); err != nil {
+			if errors.Is(err, ErrTokenRevocationDisabled) {
+				// Без сессий и списка отозванных токенов выйти нельзя: токен действует до истечения
+				return skipStep{err}
+			}
+
+			return err
+		}
+		if _, err := a.Authenticate(ctx, token.AccessToken); !errors.Is(err, ErrInvalidToken) {
+			return fmt.Errorf("%w: token is still accepted after logout", ErrSelfTestCheckFailed)
+		}
+
+		return nil
+	})
+	run.step(ctx, models.SelfTestDelete, func(ctx context.Context) error {
+		return a.EraseUser(ctx, userID)
+	})
+
+	// Уборка выполняется и после упавшего шага, и после отмены запроса: иначе данные останутся до janitor
+	run.always(context.WithoutCancel(ctx), models.SelfTestCleanup, func(ctx context.Context) error {
+		return a.selfTest.DeleteSelfTestData(ctx, userID, appID)
+	})
+
+	if run.failed {
+		log.Warn("self-test failed", slog.String("step", run.failedStep))
+	} else {
+		log.Info("self-test passed")
+	}
+
+	return run.report, nil
+}
+
+// selfTestLogin - вход самопроверки; незавершённый вход (нужен код из SMS или подтверждение) - ошибка шага
+func (a *AuthService) selfTestLogin(ctx context.Context, email, password string, appID int) (models.Token, error) {
+	token, err := a.Login(ctx, email, password, appID, "", a.TOSVersion(), "")
+	if err != nil {
+		return models.Token{}, err
+	}
+	if token.AccessToken == "" {
+		return models.Token{}, fmt.Errorf("%w: login did not issue a token", ErrSelfTestCheckFailed)
+	}
+
+	return token, nil
+}
+
+// skipStep - шаг не может выполниться в этой конфигурации сервиса: он отмечается пропущенным, а не упавшим
+type skipStep struct {
+	reason error
+}
+
+func (s skipStep) Error() string { return s.reason.Error() }
+
+// selfTestRun - ход самопроверки: отчёт и был ли уже упавший шаг
+type selfTestRun struct {
+	report     models.SelfTestReport
+	failed     bool
+	failedStep string
+}
+
+// step - выполняет шаг, если ни один шаг до него не упал, иначе отмечает его пропущенным
+func (r *selfTestRun) step(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	if r.failed {
+		r.skip(name, errPreviousStepFailed)
+
+		return
+	}
+
+	r.always(ctx, name, fn)
+}
+
+// always - выполняет шаг независимо от предыдущих
+func (r *selfTestRun) always(ctx context.Context, name string, fn func(ctx context.Context) error) {
+	start := time.Now()
+	err := fn(ctx)
+
+	s := models.SelfTestStep{Name: name, Status: models.SelfTestPassed, Duration: time.Since(start), Err: err}
+	var skip skipStep
+	switch {
+	case errors.As(err, &skip):
+		s.Status, s.Err = models.SelfTestSkipped, skip.reason
+	case err != nil:
+		s.Status = models.SelfTestFailed
+		if !r.failed {
+			r.failed, r.failedStep = true, name
+		}
+	}
+	r.report.Steps = append(r.report.Steps, s)
+}
+
+// skip - отмечает шаг пропущенным по причине reason
+func (r *selfTestRun) skip(name string, reason error) {
+	r.report.Steps = append(r.report.Steps, models.SelfTestStep{Name: name, Status: models.SelfTestSkipped, Err: reason})
+}
+
+// selfTestSecret - случайная строка из n байт для nonce, секрета приложения и паролей
+func selfTestSecret(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sso/internal/domain/models"
+	"sso/internal/services/auth/mocks"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSelfTest_Disabled(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+
+	_, err := a.RunSelfTest(context.Background())
+	require.ErrorIs(t, err, ErrSelfTestDisabled)
+}
+
+func TestRunSelfTest_FailedStepSkipsRest(t *testing.T) {
+	store := mocks.NewSelfTestStore(t)
+	// Публикатор без ожиданий: событие о пользователе самопроверки провалило бы тест
+	pub := mocks.NewPublisher(t)
+	a, saver, _, _ := newTestService(t, WithSelfTest(store), WithEventPublisher(pub),
+		// Домен самопроверки не должен проходить через ограничения регистрации
+		WithDomainPolicy(NewDomainPolicy([]string{"corp.io"}, nil)))
+
+	store.EXPECT().SaveSelfTestApp(mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(12, nil)
+	saver.EXPECT().SaveUser(mock.Anything, mock.MatchedBy(func(email string) bool {
+		return strings.HasPrefix(email, "selftest+") && strings.HasSuffix(email, "@sso.invalid")
+	}), mock.Anything).Return(0, errors.New("disk I/O error"))
+	// Уборка выполняется после упавшего шага; пользователь не создан
+	store.EXPECT().DeleteSelfTestData(mock.Anything, int64(0), 12).Return(nil)
+
+	report, err := a.RunSelfTest(context.Background())
+	require.NoError(t, err)
+	assert.False(t, report.Passed())
+
+	statuses := make(map[string]string, len(report.Steps))
+	for _, s := range report.Steps {
+		statuses[s.Name] = s.Status
+	}
+	assert.Equal(t, map[string]string{
+		models.SelfTestCreateApp:      models.SelfTestPassed,
+		models.SelfTestRegister:       models.SelfTestFailed,
+		models.SelfTestVerifyEmail:    models.SelfTestSkipped,
+		models.SelfTestLogin:          models.SelfTestSkipped,
+		models.SelfTestValidate:       models.SelfTestSkipped,
+		models.SelfTestRefresh:        models.SelfTestSkipped,
+		models.SelfTestChangePassword: models.SelfTestSkipped,
+		models.SelfTestLogout:         models.SelfTestSkipped,
+		models.SelfTestDelete:         models.SelfTestSkipped,
+		models.SelfTestCleanup:        models.SelfTestPassed,
+	}, statuses)
+	assert.ErrorContains(t, report.Steps[1].Err, "disk I/O error")
+}
@@ -0,0 +1,295 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/authctx"
+	"sso/internal/lib/events"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+// sessionTouchInterval - активность сессии записывается не чаще этого интервала
+const sessionTouchInterval = time.Minute
+
+// Ошибки сессий
+var (
+	ErrTooManySessions = errors.New("too many concurrent sessions")              // Ошибка, если у пользователя уже models.App.MaxSessions сессий (политика reject).
+	ErrSessionExpired  = errors.New("session expired, a full login is required") // Ошибка, если сессия превысила тайм-аут бездействия или время жизни.
+	ErrSessionNotFound = errors.New("session not found")                         // Ошибка, если сессии нет или она уже завершена.
+)
+
+// SessionStore - хранилище сессий входа
+type SessionStore interface {
+	// CreateSession - сохраняет сессию, не допуская больше limit активных сессий пользователя в приложении
+	// (0 - без лимита): storage.ErrSessionLimitReached или, если evictOldest, отзыв самых старых (их id возвращаются).
+	CreateSession(ctx context.Context, s models.Session, limit int, evictOldest bool) (evicted []string, err error)
+	// Session - сессия по id (storage.ErrSessionNotFound, если её нет).
+	Session(ctx context.Context, id string) (models.Session, error)
+	// TouchSession - отмечает активность сессии в момент at, если с прошлой отметки прошло не меньше throttle.
+	TouchSession(ctx context.Context, id string, at time.Time, throttle time.Duration) error
+	// RevokeSession - отзывает активную сессию (storage.ErrSessionNotFound, если её нет или она уже отозвана).
+	RevokeSession(ctx context.Context, id string, at time.Time) error
+}
+
+// WithSessions - Login создаёт сессию, id которой попадает в клейм sid токена; Authenticate отклоняет токены
+// отозванных сессий. Лимит одновременных сессий задаётся для каждого приложения (models.App.MaxSessions).
+func WithSessions(store SessionStore) Option {
+	return func(a *AuthService) {
+		a.sessions = store
+	}
+}
+
+// startSession - создаёт сессию claims.SessionID для входа user в app, которая истекает через lifetime,
+// и возвращает её. Клеймы входа сохраняются в сессии. Сессии, вытесненные лимитом приложения, отзываются
+// вместе с их токенами
+func (a *AuthService) startSession(
+	ctx context.Context,
+	user models.User,
+	app models.App,
+	claims jwt.Claims,
+	lifetime time.Duration,
+) (models.Session, error) {
+	now := a.clock.Now()
+	session := models.Session{
+		ID:        claims.SessionID,
+		UserID:    user.ID,
+		AppID:     app.ID,
+		Scopes:    strings.Fields(claims.Scope),
+		OrgID:     claims.OrgID,
+		AMR:       claims.AMR,
+		ACR:       claims.ACR,
+		CreatedAt: now,
+		ExpiresAt: now.Add(lifetime),
+	}
+	if claims.AuthTime != nil {
+		session.AuthTime = claims.AuthTime.Time
+	}
+
+	evictOldest := app.SessionLimitPolicy == models.SessionLimitEvictOldest
+	evicted, err := a.sessions.CreateSession(ctx, session, app.MaxSessions, evictOldest)
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionLimitReached) {
+			return models.Session{}, ErrTooManySessions
+		}
+
+		return models.Session{}, err
+	}
+
+	if len(evicted) > 0 {
+		logctx.FromOr(ctx, a.log).Info("oldest sessions evicted",
+			slog.Int64("user_id", user.ID),
+			slog.Int("app_id", app.ID),
+			slog.Int("count", len(evicted)))
+
+		a.audit.Emit(ctx, audit.Event{
+			Name: audit.EventSessionEvicted, Outcome: audit.OutcomeSuccess,
+			UserID: user.ID, AppID: app.ID, Reason: fmt.Sprintf("%d session(s) over the app limit", len(evicted)),
+		})
+
+		revoked := events.New(ctx, events.TypeTokenRevoked, user.ID)
+		revoked.AppID = app.ID
+		a.publish(ctx, revoked)
+
+		for _, sid := range evicted {
+			a.recordRevocation(ctx, models.Revocation{
+				Kind: models.RevocationSession, UserID: user.ID, AppID: app.ID, SessionID: sid,
+			})
+		}
+	}
+
+	return session, nil
+}
+
+// EndSession - завершает сессию sid (выход): токены с этим sid перестают действовать.
+// Уже завершённая или неизвестная сессия - ErrSessionNotFound
+func (a *AuthService) EndSession(ctx context.Context, sid string) error {
+	const op = "Auth.EndSession"
+
+	if a.sessions == nil || sid == "" {
+		return fmt.Errorf("%s: %w", op, ErrSessionNotFound)
+	}
+
+	session, err := a.sessions.Session(ctx, sid)
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrSessionNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.sessions.RevokeSession(ctx, sid, a.clock.Now()); err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrSessionNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	logctx.FromOr(ctx, a.log).Info("session ended",
+		slog.String("op", op),
+		slog.Int64("user_id", session.UserID),
+		slog.Int("app_id", session.AppID))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventSessionEnded, Outcome: audit.OutcomeSuccess,
+		UserID: session.UserID, AppID: session.AppID,
+	})
+
+	revoked := events.New(ctx, events.TypeTokenRevoked, session.UserID)
+	revoked.AppID = session.AppID
+	a.publish(ctx, revoked)
+	a.recordRevocation(ctx, models.Revocation{
+		Kind: models.RevocationSession, UserID: session.UserID, AppID: session.AppID, SessionID: sid,
+	})
+
+	return nil
+}
+
+// checkSession - действует ли сессия токена приложения app (токены без sid сессией не ограничены).
+// Сессия, простоявшая без активности дольше app.SessionIdleTimeout или прожившая дольше app.SessionMaxLifetime,
+// истекает (ErrSessionExpired): токен ещё может быть не просрочен, но клиенту нужно войти заново
+func (a *AuthService) checkSession(ctx context.Context, sid string, app models.App) error {
+	if sid == "" || a.sessions == nil {
+		return nil
+	}
+
+	session, err := a.sessions.Session(ctx, sid)
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return fmt.Errorf("%w: session not found", ErrInvalidToken)
+		}
+
+		return err
+	}
+
+	return a.validateSession(ctx, session, app)
+}
+
+// validateSession - проверки checkSession для уже загруженной сессии; отмечает её активность
+func (a *AuthService) validateSession(ctx context.Context, session models.Session, app models.App) error {
+	now := a.clock.Now()
+	if !session.Active(now) {
+		return fmt.Errorf("%w: session revoked", ErrInvalidToken)
+	}
+
+	if app.SessionIdleTimeout > 0 && now.Sub(session.LastSeenAt) > app.SessionIdleTimeout {
+		return fmt.Errorf("%w: %w: idle for %s", ErrInvalidToken, ErrSessionExpired, now.Sub(session.LastSeenAt).Round(time.Second))
+	}
+	if app.SessionMaxLifetime > 0 && now.Sub(session.CreatedAt) > app.SessionMaxLifetime {
+		return fmt.Errorf("%w: %w: maximum lifetime exceeded", ErrInvalidToken, ErrSessionExpired)
+	}
+
+	// Неудачная отметка активности не мешает запросу: в худшем случае сессия истечёт на минуту раньше
+	if now.Sub(session.LastSeenAt) >= sessionTouchInterval {
+		if err := a.sessions.TouchSession(ctx, session.ID, now, sessionTouchInterval); err != nil {
+			logctx.FromOr(ctx, a.log).Warn("failed to record session activity", slog.String("error", err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// AuthenticateSession - вызывающий по id сессии, без токена: так HTTP-шлюз в режиме cookie находит пользователя
+// по cookie на любом экземпляре и после перезапуска. Проверки те же, что у Authenticate для токена с этим sid;
+// права, организация и способ аутентификации берутся из сохранённых при входе клеймов сессии
+func (a *AuthService) AuthenticateSession(ctx context.Context, sid string) (authctx.Principal, error) {
+	const op = "Auth.AuthenticateSession"
+
+	if sid == "" || a.sessions == nil {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: session not found", op, ErrInvalidToken)
+	}
+
+	session, err := a.sessions.Session(ctx, sid)
+	if err != nil {
+		if errors.Is(err, storage.ErrSessionNotFound) {
+			return authctx.Principal{}, fmt.Errorf("%s: %w: session not found", op, ErrInvalidToken)
+		}
+
+		return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	app, err := a.appProvider.App(ctx, session.AppID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return authctx.Principal{}, fmt.Errorf("%s: %w: unknown app", op, ErrInvalidToken)
+		}
+
+		return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := a.validateSession(ctx, session, app); err != nil {
+		return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if app.RecheckNetworkPolicy && !networkPolicyAllows(ctx, app) {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: network is not allowed by app policy", op, ErrInvalidToken)
+	}
+
+	users := a.usrProvider
+	if a.validation != nil {
+		users = a.validation
+	}
+
+	user, err := users.UserByID(ctx, session.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return authctx.Principal{}, fmt.Errorf("%s: %w: user not found", op, ErrInvalidToken)
+		}
+
+		return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.IsActive {
+		return authctx.Principal{}, fmt.Errorf("%s: %w: user is not active", op, ErrInvalidToken)
+	}
+	// Отзыв согласия завершает и сессии стороннего приложения
+	if app.ThirdParty {
+		if _, err := a.checkConsent(ctx, user.ID, app.ID, session.CreatedAt); err != nil {
+			if errors.Is(err, ErrConsentRequired) {
+				return authctx.Principal{}, fmt.Errorf("%s: %w: consent revoked", op, ErrInvalidToken)
+			}
+
+			return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	principal := authctx.Principal{
+		UserID:       user.ID,
+		Email:        user.Email,
+		AppID:        app.ID,
+		IsAdmin:      user.IsAdmin,
+		IsSuperadmin: user.IsSuperadmin,
+		IsGuest:      user.IsGuest,
+		SessionID:    session.ID,
+		ThirdParty:   app.ThirdParty,
+		Scopes:       session.Scopes,
+		AMR:          session.AMR,
+		ACR:          session.ACR,
+		AuthTime:     session.AuthTime,
+		ExpiresAt:    session.ExpiresAt,
+	}
+
+	// Роль в организации, как и у токена, берётся из БД: исключённый участник теряет доступ сразу
+	if session.OrgID != 0 {
+		if a.orgs == nil {
+			return authctx.Principal{}, fmt.Errorf("%s: %w: organizations are disabled", op, ErrInvalidToken)
+		}
+
+		role, err := a.orgs.MemberRole(ctx, session.OrgID, user.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotMember) {
+				return authctx.Principal{}, fmt.Errorf("%s: %w: not a member of the organization", op, ErrInvalidToken)
+			}
+
+			return authctx.Principal{}, fmt.Errorf("%s: %w", op, err)
+		}
+		principal.OrgID, principal.OrgRole = session.OrgID, role
+	}
+
+	return principal, nil
+}
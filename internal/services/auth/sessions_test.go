@@ -0,0 +1,265 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/events"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogin_SessionLimit(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	a, _, provider, apps := newTestService(t, WithSessions(sessions))
+	user := userWithPassword(t, "secret")
+
+	limited := testApp
+	limited.MaxSessions = 2
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(limited, nil)
+	sessions.EXPECT().CreateSession(mock.Anything, mock.Anything, 2, false).
+		Return(nil, fmt.Errorf("storage.sqlite.CreateSession: %w", storage.ErrSessionLimitReached)).Once()
+
+	_, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.ErrorIs(t, err, ErrTooManySessions)
+
+	var session models.Session
+	sessions.EXPECT().CreateSession(mock.Anything, mock.Anything, 2, false).
+		RunAndReturn(func(_ context.Context, s models.Session, _ int, _ bool) ([]string, error) {
+			session = s
+
+			return nil, nil
+		}).Once()
+
+	token, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+
+	claims, err := jwt.ParseForApp(token.AccessToken, testApp)
+	require.NoError(t, err)
+	assert.Equal(t, session.ID, claims.SessionID)
+	assert.Equal(t, session.ID, token.SessionID)
+
+	// Сессия хранит те же клеймы входа, что подписаны в токене
+	assert.Equal(t, claims.AMR, session.AMR)
+	assert.Equal(t, claims.ACR, session.ACR)
+	assert.Equal(t, claims.AuthTime.Time, session.AuthTime)
+	assert.Equal(t, []string{models.AMRPassword}, session.AMR)
+}
+
+func TestEndSession(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	pub := mocks.NewPublisher(t)
+	a, _, _, _ := newTestService(t, WithSessions(sessions), WithEventPublisher(pub))
+
+	active := models.Session{ID: "sid-1", UserID: 7, AppID: testApp.ID, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(active, nil)
+	sessions.EXPECT().RevokeSession(mock.Anything, "sid-1", mock.Anything).Return(nil).Once()
+	pub.EXPECT().Publish(mock.Anything, mock.MatchedBy(func(e events.Event) bool {
+		return e.Type == events.TypeTokenRevoked && e.UserID == 7 && e.AppID == testApp.ID
+	})).Return(nil)
+
+	require.NoError(t, a.EndSession(context.Background(), "sid-1"))
+
+	// Повторный выход: сессия уже отозвана
+	sessions.EXPECT().RevokeSession(mock.Anything, "sid-1", mock.Anything).
+		Return(fmt.Errorf("storage.sqlite.RevokeSession: %w", storage.ErrSessionNotFound)).Once()
+
+	require.ErrorIs(t, a.EndSession(context.Background(), "sid-1"), ErrSessionNotFound)
+}
+
+func TestAuthenticate_RevokedSession(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	a, _, provider, apps := newTestService(t, WithSessions(sessions))
+	user := userWithPassword(t, "secret")
+
+	token, err := jwt.NewToken(user, testApp, time.Hour, jwt.WithSession("sid-1"))
+	require.NoError(t, err)
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+
+	active := models.Session{ID: "sid-1", CreatedAt: time.Now(), LastSeenAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(active, nil).Once()
+
+	p, err := a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+	assert.Equal(t, "sid-1", p.SessionID)
+
+	// Сессию вытеснил новый вход - её токен больше не действует
+	evicted := active
+	evicted.RevokedAt = time.Now()
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(evicted, nil).Once()
+
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthenticate_SessionTimeouts(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	a, _, provider, apps := newTestService(t, WithSessions(sessions))
+	user := userWithPassword(t, "secret")
+
+	limited := testApp
+	limited.SessionIdleTimeout = 30 * time.Minute
+	limited.SessionMaxLifetime = 12 * time.Hour
+
+	token, err := jwt.NewToken(user, limited, time.Hour, jwt.WithSession("sid-1"))
+	require.NoError(t, err)
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(limited, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+
+	now := time.Now()
+	session := func(created, lastSeen time.Duration) models.Session {
+		return models.Session{ID: "sid-1", CreatedAt: now.Add(-created), LastSeenAt: now.Add(-lastSeen), ExpiresAt: now.Add(time.Hour)}
+	}
+
+	// Активность отмечена недавно - в БД не пишем
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(session(time.Hour, 10*time.Second), nil).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+
+	// Прошло больше минуты - активность обновляется
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(session(time.Hour, 5*time.Minute), nil).Once()
+	sessions.EXPECT().TouchSession(mock.Anything, "sid-1", mock.Anything, sessionTouchInterval).Return(nil).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(session(time.Hour, 31*time.Minute), nil).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrSessionExpired)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	// Абсолютный лимит не зависит от активности
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(session(13*time.Hour, 0), nil).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrSessionExpired)
+}
+
+// Тайм-аут бездействия сдвигается с каждой активностью, но не дальше абсолютного лимита от входа
+func TestAuthenticate_SessionIdleBoundedByMaxLifetime(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	a, _, provider, apps := newTestService(t, WithSessions(sessions))
+	user := userWithPassword(t, "secret")
+
+	limited := testApp
+	limited.SessionIdleTimeout = 30 * time.Minute
+	limited.SessionMaxLifetime = 12 * time.Hour
+
+	token, err := jwt.NewToken(user, limited, time.Hour, jwt.WithSession("sid-1"))
+	require.NoError(t, err)
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(limited, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+
+	now := time.Now()
+	session := func(created, lastSeen time.Duration) models.Session {
+		return models.Session{ID: "sid-1", CreatedAt: now.Add(-created), LastSeenAt: now.Add(-lastSeen), ExpiresAt: now.Add(time.Hour)}
+	}
+
+	// За минуту до лимита активность ещё продлевает сессию
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(session(limited.SessionMaxLifetime-time.Minute, 2*time.Minute), nil).Once()
+	sessions.EXPECT().TouchSession(mock.Anything, "sid-1", mock.Anything, sessionTouchInterval).Return(nil).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.NoError(t, err)
+
+	// Окно бездействия ещё открыто, но лимит от входа пройден - нужен новый вход, активность не отмечается
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(session(limited.SessionMaxLifetime+time.Second, 2*time.Minute), nil).Once()
+	_, err = a.Authenticate(context.Background(), token)
+	require.ErrorIs(t, err, ErrSessionExpired)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestEndSession_RecordsRevocation(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	revocations := mocks.NewRevocationStore(t)
+	notified := 0
+	a, _, _, _ := newTestService(t, WithSessions(sessions), WithRevocationLog(revocations, func() { notified++ }))
+
+	active := models.Session{ID: "sid-1", UserID: 7, AppID: testApp.ID, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(active, nil)
+	sessions.EXPECT().RevokeSession(mock.Anything, "sid-1", mock.Anything).Return(nil)
+	revocations.EXPECT().AddRevocation(mock.Anything, mock.MatchedBy(func(r models.Revocation) bool {
+		return r.Kind == models.RevocationSession && r.SessionID == "sid-1" && r.UserID == 7 && !r.RevokedAt.IsZero()
+	})).Return(1, nil)
+
+	require.NoError(t, a.EndSession(context.Background(), "sid-1"))
+	assert.Equal(t, 1, notified)
+}
+
+func TestAuthenticateSession(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	a, _, provider, apps := newTestService(t, WithSessions(sessions))
+	user := userWithPassword(t, "secret")
+
+	active := models.Session{
+		ID: "sid-1", UserID: user.ID, AppID: testApp.ID,
+		CreatedAt: time.Now(), LastSeenAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour),
+	}
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil).Once()
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(active, nil).Once()
+
+	p, err := a.AuthenticateSession(context.Background(), "sid-1")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, p.UserID)
+	assert.Equal(t, testApp.ID, p.AppID)
+	assert.Equal(t, "sid-1", p.SessionID)
+	assert.Equal(t, active.ExpiresAt, p.ExpiresAt)
+
+	// Завершённая сессия и неизвестный id - как отозванный токен
+	ended := active
+	ended.RevokedAt = time.Now()
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(ended, nil).Once()
+	_, err = a.AuthenticateSession(context.Background(), "sid-1")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	sessions.EXPECT().Session(mock.Anything, "sid-2").
+		Return(models.Session{}, fmt.Errorf("storage.sqlite.Session: %w", storage.ErrSessionNotFound)).Once()
+	_, err = a.AuthenticateSession(context.Background(), "sid-2")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthenticateSession_LoginClaims(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	orgs := mocks.NewOrgStore(t)
+	a, _, provider, apps := newTestService(t, WithSessions(sessions), WithOrganizations(orgs, false))
+	user := userWithPassword(t, "secret")
+
+	authTime := time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	session := models.Session{
+		ID: "sid-1", UserID: user.ID, AppID: testApp.ID,
+		Scopes: []string{"files.read"}, OrgID: 3,
+		AMR: []string{models.AMRPassword, "otp"}, ACR: models.ACRMultiFactor, AuthTime: authTime,
+		CreatedAt: time.Now(), LastSeenAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour),
+	}
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(session, nil)
+	orgs.EXPECT().MemberRole(mock.Anything, int64(3), user.ID).Return(models.OrgRoleAdmin, nil).Once()
+
+	// Вызывающий по cookie получает те же права и уровень входа, что и по токену этой сессии
+	p, err := a.AuthenticateSession(context.Background(), "sid-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"files.read"}, p.Scopes)
+	assert.Equal(t, int64(3), p.OrgID)
+	assert.Equal(t, models.OrgRoleAdmin, p.OrgRole)
+	assert.Equal(t, session.AMR, p.AMR)
+	assert.Equal(t, models.ACRMultiFactor, p.ACR)
+	assert.Equal(t, authTime, p.AuthTime)
+
+	// Исключённый из организации участник теряет доступ и по cookie
+	orgs.EXPECT().MemberRole(mock.Anything, int64(3), user.ID).
+		Return("", fmt.Errorf("storage.sqlite.MemberRole: %w", storage.ErrNotMember)).Once()
+	_, err = a.AuthenticateSession(context.Background(), "sid-1")
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
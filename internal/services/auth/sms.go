@@ -0,0 +1,476 @@
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/lib/phone"
+	"sso/internal/storage"
+	"time"
+)
+
+// smsCodeDigits - длина кода из SMS
+const smsCodeDigits = 6
+
+// Ошибки второго фактора по SMS
+var (
+	ErrSMSDisabled          = errors.New("sms second factor is disabled")                          // Ошибка, если SMS не включены.
+	ErrInvalidPhone         = errors.New("invalid phone number")                                   // Ошибка, если номер не в международном формате.
+	ErrMFAChallengeNotFound = errors.New("mfa challenge not found or expired")                     // Ошибка, если токен вызова неизвестен, истёк или уже использован.
+	ErrSMSCodeExpired       = errors.New("sms code expired or not sent")                           // Ошибка, если код не отправлялся или истёк: нужно запросить новый.
+	ErrTooManySMSAttempts   = errors.New("too many invalid sms codes")                             // Ошибка, если попытки ввода кода исчерпаны: вызов отменён.
+	ErrSMSRateLimited       = errors.New("too many sms sent, try again later")                     // Ошибка, если отправка SMS сейчас запрещена лимитами.
+	ErrPhoneNotSet          = errors.New("phone number is not set")                                // Ошибка, если у пользователя нет подтверждённого номера.
+	ErrMFARequired          = errors.New("action requires a login confirmed with a second factor") // Ошибка, если токен получен без второго фактора.
+)
+
+// SMSSettings - параметры второго фактора по SMS.
+type SMSSettings struct {
+	ChallengeTTL     time.Duration // Сколько действует вызов.
+	CodeTTL          time.Duration // Сколько действует отправленный код.
+	MaxAttempts      int           // Сколько раз можно ввести код одного вызова.
+	ResendInterval   time.Duration // Минимальная пауза между отправками кода одного вызова.
+	SendWindow       time.Duration // Окно, в котором считаются отправки.
+	MaxSendsPerUser  int           // Сколько SMS можно отправить пользователю за окно.
+	MaxSendsPerPhone int           // Сколько SMS можно отправить на номер за окно.
+}
+
+// SMSProvider - отправляет SMS на номер phone в формате E.164.
+type SMSProvider interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// SMSStore - хранилище вызовов с кодом из SMS и подтверждённых номеров.
+type SMSStore interface {
+	// SaveSMSChallenge - сохраняет вызов.
+	SaveSMSChallenge(ctx context.Context, c models.SMSChallenge) error
+	// SMSChallenge - вызов по SHA-256 токена (storage.ErrSMSChallengeNotFound, если его нет).
+	SMSChallenge(ctx context.Context, id string) (models.SMSChallenge, error)
+	// ReserveSMSSend - учитывает отправку, если лимиты за окно с since не исчерпаны (иначе storage.ErrSMSSendLimitReached).
+	ReserveSMSSend(ctx context.Context, userID int64, phone string, sentAt, since time.Time, maxPerUser, maxPerPhone int) error
+	// SetSMSCode - хеш нового кода, срок его действия и время отправки.
+	SetSMSCode(ctx context.Context, id string, codeHash []byte, codeExpiresAt, sentAt time.Time) error
+	// ConsumeSMSAttempt - учитывает проверку кода (storage.ErrSMSAttemptsExhausted, если попытки кончились).
+	ConsumeSMSAttempt(ctx context.Context, id string, maxAttempts int) error
+	// DeleteSMSChallenge - удаляет вызов (storage.ErrSMSChallengeNotFound, если его уже нет).
+	DeleteSMSChallenge(ctx context.Context, id string) error
+	// SetUserPhone - подтверждённый номер пользователя (пусто - удалить).
+	SetUserPhone(ctx context.Context, userID int64, phone string) error
+}
+
+// WithSMS - включает второй фактор по SMS: вход по паролю пользователя с подтверждённым номером
+// завершается кодом из SMS (SendSMSCode, VerifySMSCode).
+func WithSMS(store SMSStore, provider SMSProvider, s SMSSettings) Option {
+	return func(a *AuthService) {
+		a.smsStore = store
+		a.smsProvider = provider
+		a.sms = s
+	}
+}
+
+// startLoginChallenge - вызов для входа пользователя с подтверждённым номером: токен выдаст VerifySMSCode.
+// Приложение проверяется сразу, чтобы не тратить SMS на вход в несуществующее приложение
+func (a *AuthService) startLoginChallenge(
+	ctx context.Context,
+	log *slog.Logger,
+	user models.User,
+	appID int,
+	orgID int64,
+	acceptedTOS string,
+) (models.Token, error) {
+	if _, err := a.appProvider.App(ctx, appID); err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			log.Warn("app not found", slog.Int("app_id", appID))
+
+			return models.Token{}, ErrInvalidAppID
+		}
+
+		return models.Token{}, err
+	}
+
+	challenge, err := a.saveSMSChallenge(ctx, models.SMSChallenge{
+		Purpose: models.SMSPurposeLogin, UserID: user.ID, Phone: user.Phone,
+		AppID: appID, OrgID: orgID, AcceptedTOS: acceptedTOS,
+	})
+	if err != nil {
+		log.Error("failed to save mfa challenge", slog.String("error", err.Error()))
+
+		return models.Token{}, err
+	}
+
+	log.Info("second factor required")
+
+	return models.Token{MFAChallenge: &challenge}, nil
+}
+
+// SendSMSCode - отправляет новый код вызова challengeToken (вход или подтверждение телефона)
+// и возвращает срок его действия. Прежний код вызова перестаёт действовать.
+// Отправки ограничены паузой между ними и лимитами по пользователю и номеру (ErrSMSRateLimited).
+func (a *AuthService) SendSMSCode(ctx context.Context, challengeToken string) (time.Time, error) {
+	const op = "Auth.SendSMSCode"
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+
+	if a.smsStore == nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, ErrSMSDisabled)
+	}
+
+	now := a.clock.Now()
+
+	c, err := a.smsChallenge(ctx, challengeToken, now)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+	log = log.With(slog.Int64("user_id", c.UserID), slog.String("purpose", c.Purpose))
+
+	if !c.SentAt.IsZero() && now.Before(c.SentAt.Add(a.sms.ResendInterval)) {
+		log.Info("sms code resent too early")
+
+		return time.Time{}, fmt.Errorf("%s: %w", op, ErrSMSRateLimited)
+	}
+
+	if err := a.smsStore.ReserveSMSSend(ctx, c.UserID, c.Phone, now, now.Add(-a.sms.SendWindow),
+		a.sms.MaxSendsPerUser, a.sms.MaxSendsPerPhone); err != nil {
+		if errors.Is(err, storage.ErrSMSSendLimitReached) {
+			log.Warn("sms send limit reached", slog.String("phone", phone.Mask(c.Phone)))
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventSMSSendLimited, Outcome: audit.OutcomeFailure,
+				UserID: c.UserID, AppID: c.AppID, Reason: "send limit reached",
+			})
+
+			return time.Time{}, fmt.Errorf("%s: %w", op, ErrSMSRateLimited)
+		}
+
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	code, err := newSMSCode()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	codeExpiresAt := now.Add(a.sms.CodeTTL)
+	if err := a.smsStore.SetSMSCode(ctx, c.ID, hashSMSCode(c.ID, code), codeExpiresAt, now); err != nil {
+		return time.Time{}, fmt.Errorf("%s: %w", op, mfaChallengeError(err))
+	}
+
+	message := fmt.Sprintf("%s is your verification code. It expires in %d minutes.", code, int(a.sms.CodeTTL.Minutes()))
+	if err := a.smsProvider.Send(ctx, c.Phone, message); err != nil {
+		log.Error("failed to send sms", slog.String("error", err.Error()))
+
+		return time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("sms code sent", slog.String("phone", phone.Mask(c.Phone)))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventSMSCodeSent, Outcome: audit.OutcomeSuccess,
+		UserID: c.UserID, AppID: c.AppID,
+	})
+
+	return codeExpiresAt, nil
+}
+
+// VerifySMSCode - завершает вход кодом из SMS и выдаёт токен с acr models.ACRMultiFactor.
+// Неверный код - ErrInvalidSecondFactor; после MaxAttempts проверок вызов отменяется (ErrTooManySMSAttempts).
+func (a *AuthService) VerifySMSCode(ctx context.Context, challengeToken, code string) (models.Token, error) {
+	const op = "Auth.VerifySMSCode"
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+
+	if a.smsStore == nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrSMSDisabled)
+	}
+
+	now := a.clock.Now()
+
+	c, err := a.smsChallenge(ctx, challengeToken, now)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if c.Purpose != models.SMSPurposeLogin {
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrMFAChallengeNotFound)
+	}
+	log = log.With(slog.Int64("user_id", c.UserID), slog.Int("app_id", c.AppID))
+
+	if err := a.checkSMSCode(ctx, c, code, now); err != nil {
+		if errors.Is(err, ErrInvalidSecondFactor) || errors.Is(err, ErrTooManySMSAttempts) {
+			log.Info("invalid sms code")
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventLoginFailed, Outcome: audit.OutcomeFailure,
+				UserID: c.UserID, AppID: c.AppID, Reason: "invalid sms code",
+			})
+			a.recordLoginFailure(ctx, log, c.AppID)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, c.UserID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if !user.IsActive {
+		log.Info("user is not active")
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+	}
+
+	var tokenOpts []jwt.TokenOption
+	if c.OrgID != 0 {
+		// Роль читается заново: за время ввода кода её могли изменить
+		role, err := a.orgs.MemberRole(ctx, c.OrgID, user.ID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotMember) {
+				return models.Token{}, fmt.Errorf("%s: %w", op, ErrInvalidCredentials)
+			}
+
+			return models.Token{}, fmt.Errorf("%s: %w", op, err)
+		}
+		tokenOpts = append(tokenOpts, jwt.WithOrg(c.OrgID, role))
+	}
+
+	tokenOpts = append(tokenOpts, jwt.WithAuthentication(
+		[]string{models.AMRPassword, models.AMRSMS, models.AMRMultiFactor}, models.ACRMultiFactor, now))
+
+	token, err := a.completeLogin(ctx, log, user, user.Email, c.AppID, c.AcceptedTOS, models.ACRMultiFactor, tokenOpts...)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return token, nil
+}
+
+// StartPhoneVerification - начинает подтверждение номера пользователя userID: приводит номер к E.164
+// и отправляет на него код. Номер сохраняется только после ConfirmPhone.
+func (a *AuthService) StartPhoneVerification(ctx context.Context, userID int64, number string) (models.MFAChallenge, error) {
+	const op = "Auth.StartPhoneVerification"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if a.smsStore == nil {
+		return models.MFAChallenge{}, fmt.Errorf("%s: %w", op, ErrSMSDisabled)
+	}
+
+	e164, err := phone.Normalize(number)
+	if err != nil {
+		return models.MFAChallenge{}, fmt.Errorf("%s: %w", op, ErrInvalidPhone)
+	}
+
+	challenge, err := a.saveSMSChallenge(ctx, models.SMSChallenge{Purpose: models.SMSPurposePhone, UserID: userID, Phone: e164})
+	if err != nil {
+		log.Error("failed to save phone challenge", slog.String("error", err.Error()))
+
+		return models.MFAChallenge{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if _, err := a.SendSMSCode(ctx, challenge.Token); err != nil {
+		return models.MFAChallenge{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return challenge, nil
+}
+
+// ConfirmPhone - подтверждает номер кодом из SMS и сохраняет его пользователю userID.
+// С этого момента вход по паролю требует кода из SMS. Возвращает номер в E.164.
+func (a *AuthService) ConfirmPhone(ctx context.Context, userID int64, challengeToken, code string) (string, error) {
+	const op = "Auth.ConfirmPhone"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if a.smsStore == nil {
+		return "", fmt.Errorf("%s: %w", op, ErrSMSDisabled)
+	}
+
+	now := a.clock.Now()
+
+	c, err := a.smsChallenge(ctx, challengeToken, now)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if c.Purpose != models.SMSPurposePhone || c.UserID != userID {
+		return "", fmt.Errorf("%s: %w", op, ErrMFAChallengeNotFound)
+	}
+
+	if err := a.checkSMSCode(ctx, c, code, now); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := a.smsStore.SetUserPhone(ctx, userID, c.Phone); err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return "", fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+		log.Error("failed to save phone", slog.String("error", err.Error()))
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("phone verified", slog.String("phone", phone.Mask(c.Phone)))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventPhoneVerified, Outcome: audit.OutcomeSuccess, UserID: userID,
+	})
+
+	return c.Phone, nil
+}
+
+// RemovePhone - удаляет подтверждённый номер пользователя userID; вход снова требует только пароля.
+// acr - уровень входа токена вызывающего: отключить второй фактор можно только после входа с ним,
+// иначе украденного пароля и токена хватило бы, чтобы снять защиту.
+func (a *AuthService) RemovePhone(ctx context.Context, userID int64, acr string) error {
+	const op = "Auth.RemovePhone"
+
+	log := logctx.FromOr(ctx, a.log).With(
+		slog.String("op", op),
+		slog.Int64("user_id", userID))
+
+	if a.smsStore == nil {
+		return fmt.Errorf("%s: %w", op, ErrSMSDisabled)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrUserNotFound) {
+			return fmt.Errorf("%s: %w", op, ErrUserNotFound)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if user.Phone == "" {
+		return fmt.Errorf("%s: %w", op, ErrPhoneNotSet)
+	}
+	if acr != models.ACRMultiFactor {
+		return fmt.Errorf("%s: %w", op, ErrMFARequired)
+	}
+
+	if err := a.smsStore.SetUserPhone(ctx, userID, ""); err != nil {
+		log.Error("failed to remove phone", slog.String("error", err.Error()))
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("phone removed")
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventPhoneRemoved, Outcome: audit.OutcomeSuccess, UserID: userID,
+	})
+
+	return nil
+}
+
+// saveSMSChallenge - сохраняет вызов c под новым токеном и возвращает токен клиенту
+func (a *AuthService) saveSMSChallenge(ctx context.Context, c models.SMSChallenge) (models.MFAChallenge, error) {
+	token, err := newDeviceCode()
+	if err != nil {
+		return models.MFAChallenge{}, err
+	}
+
+	c.ID = hashDeviceCode(token)
+	c.ExpiresAt = a.clock.Now().Add(a.sms.ChallengeTTL)
+	if err := a.smsStore.SaveSMSChallenge(ctx, c); err != nil {
+		return models.MFAChallenge{}, err
+	}
+
+	return models.MFAChallenge{Token: token, PhoneHint: phone.Mask(c.Phone), ExpiresAt: c.ExpiresAt}, nil
+}
+
+// smsChallenge - действующий вызов по токену
+func (a *AuthService) smsChallenge(ctx context.Context, token string, now time.Time) (models.SMSChallenge, error) {
+	if token == "" {
+		return models.SMSChallenge{}, ErrMFAChallengeNotFound
+	}
+
+	c, err := a.smsStore.SMSChallenge(ctx, hashDeviceCode(token))
+	if err != nil {
+		return models.SMSChallenge{}, mfaChallengeError(err)
+	}
+	if !now.Before(c.ExpiresAt) {
+		return models.SMSChallenge{}, ErrMFAChallengeNotFound
+	}
+
+	return c, nil
+}
+
+// checkSMSCode - проверяет код вызова c и при успехе удаляет вызов (код одноразовый).
+// Попытка учитывается до сравнения, поэтому параллельные запросы не получат больше MaxAttempts проверок
+func (a *AuthService) checkSMSCode(ctx context.Context, c models.SMSChallenge, code string, now time.Time) error {
+	if c.CodeHash == nil || !now.Before(c.CodeExpiresAt) {
+		return ErrSMSCodeExpired
+	}
+
+	if err := a.smsStore.ConsumeSMSAttempt(ctx, c.ID, a.sms.MaxAttempts); err != nil {
+		if errors.Is(err, storage.ErrSMSAttemptsExhausted) {
+			a.cancelSMSChallenge(ctx, c.ID)
+
+			return ErrTooManySMSAttempts
+		}
+
+		return mfaChallengeError(err)
+	}
+
+	if !hmac.Equal(hashSMSCode(c.ID, code), c.CodeHash) {
+		if c.Attempts+1 >= a.sms.MaxAttempts {
+			a.cancelSMSChallenge(ctx, c.ID)
+
+			return ErrTooManySMSAttempts
+		}
+
+		return ErrInvalidSecondFactor
+	}
+
+	// Из параллельных верных запросов вызов использует только первый
+	if err := a.smsStore.DeleteSMSChallenge(ctx, c.ID); err != nil {
+		return mfaChallengeError(err)
+	}
+
+	return nil
+}
+
+// cancelSMSChallenge - удаляет вызов, попытки которого исчерпаны (ошибка только логируется: вызов всё равно истечёт)
+func (a *AuthService) cancelSMSChallenge(ctx context.Context, id string) {
+	if err := a.smsStore.DeleteSMSChallenge(ctx, id); err != nil && !errors.Is(err, storage.ErrSMSChallengeNotFound) {
+		logctx.FromOr(ctx, a.log).Error("failed to cancel sms challenge", slog.String("error", err.Error()))
+	}
+}
+
+// mfaChallengeError - storage.ErrSMSChallengeNotFound превращается в ErrMFAChallengeNotFound
+func mfaChallengeError(err error) error {
+	if errors.Is(err, storage.ErrSMSChallengeNotFound) {
+		return ErrMFAChallengeNotFound
+	}
+
+	return err
+}
+
+// newSMSCode - случайный код из smsCodeDigits цифр (с ведущими нулями)
+func newSMSCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000)) // 10^smsCodeDigits
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", smsCodeDigits, n.Int64()), nil
+}
+
+// hashSMSCode - код хранится только в виде HMAC с ключом - id вызова: одинаковые коды разных вызовов
+// дают разные хеши. Перебор 10^6 кодов по утёкшей БД это не останавливает, но коды живут минуты
+func hashSMSCode(challengeID, code string) []byte {
+	mac := hmac.New(sha256.New, []byte(challengeID))
+	mac.Write([]byte(code))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,300 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// memSMS - SMSStore в памяти с теми же лимитами, что и в sqlite
+type memSMS struct {
+	challenges map[string]*models.SMSChallenge
+	sends      []sentSMS
+	phones     map[int64]string
+}
+
+type sentSMS struct {
+	userID int64
+	phone  string
+	at     time.Time
+}
+
+func newMemSMS() *memSMS {
+	return &memSMS{challenges: map[string]*models.SMSChallenge{}, phones: map[int64]string{}}
+}
+
+func (m *memSMS) SaveSMSChallenge(_ context.Context, c models.SMSChallenge) error {
+	m.challenges[c.ID] = &c
+
+	return nil
+}
+
+func (m *memSMS) SMSChallenge(_ context.Context, id string) (models.SMSChallenge, error) {
+	c, ok := m.challenges[id]
+	if !ok {
+		return models.SMSChallenge{}, fmt.Errorf("storage.mem: %w", storage.ErrSMSChallengeNotFound)
+	}
+
+	return *c, nil
+}
+
+func (m *memSMS) ReserveSMSSend(_ context.Context, userID int64, phone string, sentAt, since time.Time, maxPerUser, maxPerPhone int) error {
+	var byUser, byPhone int
+	for _, s := range m.sends {
+		if s.at.After(since) && s.userID == userID {
+			byUser++
+		}
+		if s.at.After(since) && s.phone == phone {
+			byPhone++
+		}
+	}
+	if byUser >= maxPerUser || byPhone >= maxPerPhone {
+		return fmt.Errorf("storage.mem: %w", storage.ErrSMSSendLimitReached)
+	}
+	m.sends = append(m.sends, sentSMS{userID: userID, phone: phone, at: sentAt})
+
+	return nil
+}
+
+func (m *memSMS) SetSMSCode(_ context.Context, id string, codeHash []byte, codeExpiresAt, sentAt time.Time) error {
+	c := m.challenges[id]
+	c.CodeHash, c.CodeExpiresAt, c.SentAt = codeHash, codeExpiresAt, sentAt
+
+	return nil
+}
+
+func (m *memSMS) ConsumeSMSAttempt(_ context.Context, id string, maxAttempts int) error {
+	c, ok := m.challenges[id]
+	if !ok {
+		return fmt.Errorf("storage.mem: %w", storage.ErrSMSChallengeNotFound)
+	}
+	if c.Attempts >= maxAttempts {
+		return fmt.Errorf("storage.mem: %w", storage.ErrSMSAttemptsExhausted)
+	}
+	c.Attempts++
+
+	return nil
+}
+
+func (m *memSMS) DeleteSMSChallenge(_ context.Context, id string) error {
+	if _, ok := m.challenges[id]; !ok {
+		return fmt.Errorf("storage.mem: %w", storage.ErrSMSChallengeNotFound)
+	}
+	delete(m.challenges, id)
+
+	return nil
+}
+
+func (m *memSMS) SetUserPhone(_ context.Context, userID int64, phone string) error {
+	m.phones[userID] = phone
+
+	return nil
+}
+
+// outbox - SMSProvider, запоминающий отправленные сообщения
+type outbox []string
+
+func (o *outbox) Send(_ context.Context, phone, message string) error {
+	*o = append(*o, phone+": "+message)
+
+	return nil
+}
+
+// lastCode - код из последнего SMS
+func (o *outbox) lastCode(t *testing.T) string {
+	t.Helper()
+
+	require.NotEmpty(t, *o)
+
+	return regexp.MustCompile(`: (\d{6}) `).FindStringSubmatch((*o)[len(*o)-1])[1]
+}
+
+var smsSettings = SMSSettings{
+	ChallengeTTL: 10 * time.Minute, CodeTTL: 5 * time.Minute, MaxAttempts: 3,
+	ResendInterval: time.Minute, SendWindow: time.Hour, MaxSendsPerUser: 5, MaxSendsPerPhone: 5,
+}
+
+// userWithPhone - пользователь с паролем и подтверждённым номером
+func userWithPhone(t *testing.T) models.User {
+	user := userWithPassword(t, "secret")
+	user.Phone = "+79123456789"
+
+	return user
+}
+
+func TestSMSLogin(t *testing.T) {
+	store, sent := newMemSMS(), &outbox{}
+	a, _, provider, apps := newTestService(t, WithSMS(store, sent, smsSettings))
+	ctx := context.Background()
+	user := userWithPhone(t)
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	// Пароля недостаточно: вместо токена - вызов
+	pending, err := a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.Empty(t, pending.AccessToken)
+	require.NotNil(t, pending.MFAChallenge)
+	assert.Equal(t, "+7********89", pending.MFAChallenge.PhoneHint)
+	assert.Empty(t, *sent, "code is sent only on SendSMSCode")
+
+	_, err = a.VerifySMSCode(ctx, pending.MFAChallenge.Token, "000000")
+	require.ErrorIs(t, err, ErrSMSCodeExpired)
+
+	codeExpiresAt, err := a.SendSMSCode(ctx, pending.MFAChallenge.Token)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(smsSettings.CodeTTL), codeExpiresAt, time.Second)
+	require.Len(t, *sent, 1)
+	assert.Contains(t, (*sent)[0], "+79123456789: ")
+
+	code := sent.lastCode(t)
+	wrong := fmt.Sprintf("%06d", (atoi(t, code)+1)%1_000_000)
+	_, err = a.VerifySMSCode(ctx, pending.MFAChallenge.Token, wrong)
+	require.ErrorIs(t, err, ErrInvalidSecondFactor)
+
+	token, err := a.VerifySMSCode(ctx, pending.MFAChallenge.Token, code)
+	require.NoError(t, err)
+	assert.False(t, token.StepUpRequired)
+
+	claims, err := jwt.Parse(token.AccessToken, []byte(testApp.Secret))
+	require.NoError(t, err)
+	assert.Equal(t, models.ACRMultiFactor, claims.ACR)
+	assert.Equal(t, []string{models.AMRPassword, models.AMRSMS, models.AMRMultiFactor}, claims.AMR)
+
+	// Вызов одноразовый
+	_, err = a.VerifySMSCode(ctx, pending.MFAChallenge.Token, code)
+	require.ErrorIs(t, err, ErrMFAChallengeNotFound)
+}
+
+func TestSMSLogin_AttemptsExhausted(t *testing.T) {
+	store, sent := newMemSMS(), &outbox{}
+	a, _, provider, apps := newTestService(t, WithSMS(store, sent, smsSettings))
+	ctx := context.Background()
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(userWithPhone(t), nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	pending, err := a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	_, err = a.SendSMSCode(ctx, pending.MFAChallenge.Token)
+	require.NoError(t, err)
+	wrong := fmt.Sprintf("%06d", (atoi(t, sent.lastCode(t))+1)%1_000_000)
+
+	for range smsSettings.MaxAttempts - 1 {
+		_, err = a.VerifySMSCode(ctx, pending.MFAChallenge.Token, wrong)
+		require.ErrorIs(t, err, ErrInvalidSecondFactor)
+	}
+	_, err = a.VerifySMSCode(ctx, pending.MFAChallenge.Token, wrong)
+	require.ErrorIs(t, err, ErrTooManySMSAttempts)
+
+	// Вызов отменён: верный код уже не поможет
+	_, err = a.VerifySMSCode(ctx, pending.MFAChallenge.Token, sent.lastCode(t))
+	require.ErrorIs(t, err, ErrMFAChallengeNotFound)
+}
+
+func TestSendSMSCode_Limits(t *testing.T) {
+	store, sent := newMemSMS(), &outbox{}
+	a, _, provider, apps := newTestService(t, WithSMS(store, sent, smsSettings))
+	ctx := context.Background()
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(userWithPhone(t), nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	pending, err := a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+
+	_, err = a.SendSMSCode(ctx, pending.MFAChallenge.Token)
+	require.NoError(t, err)
+
+	// Повтор раньше resend_interval
+	_, err = a.SendSMSCode(ctx, pending.MFAChallenge.Token)
+	require.ErrorIs(t, err, ErrSMSRateLimited)
+
+	// Лимит пользователя за окно действует и на новые вызовы
+	for range smsSettings.MaxSendsPerUser - 1 {
+		pending, err := a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "")
+		require.NoError(t, err)
+		_, err = a.SendSMSCode(ctx, pending.MFAChallenge.Token)
+		require.NoError(t, err)
+	}
+	pending, err = a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	_, err = a.SendSMSCode(ctx, pending.MFAChallenge.Token)
+	require.ErrorIs(t, err, ErrSMSRateLimited)
+	assert.Len(t, *sent, smsSettings.MaxSendsPerUser)
+
+	_, err = a.SendSMSCode(ctx, "unknown")
+	require.ErrorIs(t, err, ErrMFAChallengeNotFound)
+}
+
+func TestPhoneVerification(t *testing.T) {
+	store, sent := newMemSMS(), &outbox{}
+	a, _, provider, _ := newTestService(t, WithSMS(store, sent, smsSettings))
+	ctx := context.Background()
+
+	_, err := a.StartPhoneVerification(ctx, 7, "8 912 345-67-89")
+	require.ErrorIs(t, err, ErrInvalidPhone)
+
+	challenge, err := a.StartPhoneVerification(ctx, 7, "+7 (912) 345-67-89")
+	require.NoError(t, err)
+	assert.Equal(t, "+7********89", challenge.PhoneHint)
+	require.Len(t, *sent, 1, "code is sent right away")
+
+	// Чужой вызов не подходит
+	_, err = a.ConfirmPhone(ctx, 8, challenge.Token, sent.lastCode(t))
+	require.ErrorIs(t, err, ErrMFAChallengeNotFound)
+	// Вызов подтверждения телефона не завершает вход
+	_, err = a.VerifySMSCode(ctx, challenge.Token, sent.lastCode(t))
+	require.ErrorIs(t, err, ErrMFAChallengeNotFound)
+
+	phone, err := a.ConfirmPhone(ctx, 7, challenge.Token, sent.lastCode(t))
+	require.NoError(t, err)
+	assert.Equal(t, "+79123456789", phone)
+	assert.Equal(t, "+79123456789", store.phones[7])
+
+	// Отключить второй фактор можно только из входа с ним
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(userWithPhone(t), nil)
+	require.ErrorIs(t, a.RemovePhone(ctx, 7, models.ACRSingleFactor), ErrMFARequired)
+	require.NoError(t, a.RemovePhone(ctx, 7, models.ACRMultiFactor))
+	assert.Empty(t, store.phones[7])
+}
+
+func TestSMS_Disabled(t *testing.T) {
+	a, _, provider, apps := newTestService(t)
+	ctx := context.Background()
+
+	// Без WithSMS номер пользователя не влияет на вход
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(userWithPhone(t), nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+
+	token, err := a.Login(ctx, "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.NotEmpty(t, token.AccessToken)
+	assert.Nil(t, token.MFAChallenge)
+
+	_, err = a.SendSMSCode(ctx, "token")
+	require.ErrorIs(t, err, ErrSMSDisabled)
+	_, err = a.StartPhoneVerification(ctx, 7, "+79123456789")
+	require.ErrorIs(t, err, ErrSMSDisabled)
+}
+
+func atoi(t *testing.T, s string) int {
+	t.Helper()
+
+	var n int
+	_, err := fmt.Sscan(s, &n)
+	require.NoError(t, err)
+
+	return n
+}
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"time"
+)
+
+// Ошибки подтверждения входа вторым фактором
+var (
+	ErrStepUpUnavailable   = errors.New("no second factor is configured") // Ошибка, если проверка второго фактора не задана.
+	ErrInvalidSecondFactor = errors.New("invalid second factor code")     // Ошибка, если код второго фактора неверный (её возвращает SecondFactor).
+)
+
+// SecondFactor - проверка второго фактора пользователя (одноразовый код из приложения, SMS и т. п.)
+type SecondFactor interface {
+	// VerifySecondFactor - проверяет код пользователя и возвращает способ для клейма amr (RFC 8176: "otp", "sms").
+	// Неверный код - ErrInvalidSecondFactor.
+	VerifySecondFactor(ctx context.Context, userID int64, code string) (method string, err error)
+	// HasSecondFactor - настроен ли у пользователя второй фактор
+	HasSecondFactor(ctx context.Context, userID int64) (bool, error)
+}
+
+// WithSecondFactor - включает StepUp: подтверждение входа вторым фактором v.
+func WithSecondFactor(v SecondFactor) Option {
+	return func(a *AuthService) {
+		a.secondFactor = v
+	}
+}
+
+// passwordAuthentication - клеймы входа по паролю
+func passwordAuthentication(now time.Time) jwt.TokenOption {
+	return jwt.WithAuthentication([]string{models.AMRPassword}, models.ACRSingleFactor, now)
+}
+
+// acrSatisfies - достаточно ли уровня acr для приложения с минимальным уровнем minACR
+func acrSatisfies(acr, minACR string) bool {
+	return minACR == "" || minACR == models.ACRSingleFactor || acr == models.ACRMultiFactor
+}
+
+// StepUp - подтверждает вход из currentToken вторым фактором и выдаёт замену токена той же сессии
+// с уровнем models.ACRMultiFactor, дополненным amr и новым auth_time. Прежний токен продолжает действовать до exp.
+func (a *AuthService) StepUp(ctx context.Context, currentToken string, code string) (models.Token, error) {
+	const op = "Auth.StepUp"
+
+	log := logctx.FromOr(ctx, a.log).With(slog.String("op", op))
+
+	if a.secondFactor == nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, ErrStepUpUnavailable)
+	}
+
+	principal, err := a.Authenticate(ctx, currentToken)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	log = log.With(slog.Int64("user_id", principal.UserID), slog.Int("app_id", principal.AppID))
+
+	method, err := a.secondFactor.VerifySecondFactor(ctx, principal.UserID, code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSecondFactor) {
+			log.Info("invalid second factor")
+			a.audit.Emit(ctx, audit.Event{
+				Name: audit.EventStepUpFailed, Outcome: audit.OutcomeFailure,
+				UserID: principal.UserID, AppID: principal.AppID, Reason: "invalid second factor",
+			})
+		} else {
+			log.Error("failed to verify second factor", slog.String("error", err.Error()))
+		}
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	user, err := a.usrProvider.UserByID(ctx, principal.UserID)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	app, err := a.appProvider.App(ctx, principal.AppID)
+	if err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+	// Замена токена - тоже выдача: окно времени могло закрыться после входа
+	if err := a.checkLoginPolicies(ctx, log, audit.EventStepUpFailed, app, user.ID, user.Email); err != nil {
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	amr := slices.Clone(principal.AMR)
+	for _, m := range []string{method, models.AMRMultiFactor} {
+		if !slices.Contains(amr, m) {
+			amr = append(amr, m)
+		}
+	}
+
+	opts := []jwt.TokenOption{jwt.WithAuthentication(amr, models.ACRMultiFactor, a.clock.Now())}
+	if principal.SessionID != "" {
+		opts = append(opts, jwt.WithSession(principal.SessionID))
+	}
+	if principal.OrgID != 0 {
+		opts = append(opts, jwt.WithOrg(principal.OrgID, principal.OrgRole))
+	}
+
+	token, err := a.issueToken(ctx, user, app, opts...)
+	if err != nil {
+		log.Error("failed to create token", slog.String("error", err.Error()))
+
+		return models.Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	log.Info("authentication stepped up", slog.String("method", method))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventStepUpSucceeded, Outcome: audit.OutcomeSuccess,
+		UserID: user.ID, Email: user.Email, AppID: app.ID,
+	})
+
+	return token, nil
+}
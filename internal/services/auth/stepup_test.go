@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogin_AuthenticationClaims(t *testing.T) {
+	a, _, provider, apps := newTestService(t)
+	user := userWithPassword(t, "secret")
+
+	strict := testApp
+	strict.MinACR = models.ACRMultiFactor
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(strict, nil)
+
+	token, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+	assert.True(t, token.StepUpRequired)
+
+	claims, err := jwt.ParseForApp(token.AccessToken, testApp)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.AMRPassword}, claims.AMR)
+	assert.Equal(t, models.ACRSingleFactor, claims.ACR)
+	require.NotNil(t, claims.AuthTime)
+	assert.WithinDuration(t, time.Now(), claims.AuthTime.Time, 5*time.Second)
+}
+
+func TestStepUp(t *testing.T) {
+	factor := mocks.NewSecondFactor(t)
+	a, _, provider, apps := newTestService(t, WithSecondFactor(factor))
+	user := userWithPassword(t, "secret")
+	ctx := context.Background()
+
+	authTime := time.Now().Add(-time.Hour)
+	current, err := jwt.NewToken(user, testApp, time.Hour,
+		jwt.WithAuthentication([]string{models.AMRPassword}, models.ACRSingleFactor, authTime))
+	require.NoError(t, err)
+
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().UserByID(mock.Anything, user.ID).Return(user, nil)
+
+	factor.EXPECT().VerifySecondFactor(mock.Anything, user.ID, "000000").
+		Return("", fmt.Errorf("otp: %w", ErrInvalidSecondFactor)).Once()
+
+	_, err = a.StepUp(ctx, current, "000000")
+	require.ErrorIs(t, err, ErrInvalidSecondFactor)
+
+	factor.EXPECT().VerifySecondFactor(mock.Anything, user.ID, "123456").Return("otp", nil).Once()
+
+	token, err := a.StepUp(ctx, current, "123456")
+	require.NoError(t, err)
+
+	p, err := a.Authenticate(ctx, token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, []string{models.AMRPassword, "otp", models.AMRMultiFactor}, p.AMR)
+	assert.Equal(t, models.ACRMultiFactor, p.ACR)
+	assert.True(t, p.AuthTime.After(authTime))
+}
+
+func TestStepUp_Unavailable(t *testing.T) {
+	a, _, _, _ := newTestService(t)
+
+	_, err := a.StepUp(context.Background(), "token", "123456")
+	require.ErrorIs(t, err, ErrStepUpUnavailable)
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/events"
+	"sso/internal/lib/jwt"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+	"strings"
+)
+
+// ErrTokenRevocationDisabled - токен без сессии нельзя отозвать: список отозванных токенов не подключён
+var ErrTokenRevocationDisabled = errors.New("token revocation is disabled")
+
+// TokenDenylist - отдельно отозванные токены (по jti)
+type TokenDenylist interface {
+	// DenyToken - отзывает токен. Повторный отзыв - не ошибка.
+	DenyToken(ctx context.Context, t models.DeniedToken) error
+	// TokenDenied - отозван ли токен с этим jti.
+	TokenDenied(ctx context.Context, jti string) (bool, error)
+}
+
+// WithTokenDenylist - RevokeToken отзывает токены без сессии, Authenticate отклоняет отозванные токены.
+// Запись о токене нужна только до его истечения: дальше токен отклоняется и без неё
+func WithTokenDenylist(store TokenDenylist) Option {
+	return func(a *AuthService) {
+		a.denylist = store
+	}
+}
+
+// InspectToken - разбирает токен для администратора: подпись проверяется ключом приложения из app_id,
+// сроки действия - нет, их результат попадает в Validity. Токен неизвестного приложения - ErrInvalidAppID
+func (a *AuthService) InspectToken(ctx context.Context, token string) (models.TokenInfo, error) {
+	const op = "Auth.InspectToken"
+
+	appID, err := jwt.UnverifiedAppID(token)
+	if err != nil {
+		return models.TokenInfo{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+
+	app, err := a.appProvider.App(ctx, appID)
+	if err != nil {
+		if errors.Is(err, storage.ErrAppNotFound) {
+			return models.TokenInfo{}, fmt.Errorf("%s: %w", op, ErrInvalidAppID)
+		}
+
+		return models.TokenInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	claims, validity, err := jwt.InspectForApp(token, app, jwt.WithLeeway(a.leeway), jwt.WithClock(a.clock.Now))
+	if err != nil {
+		return models.TokenInfo{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+	}
+
+	info := models.TokenInfo{
+		TokenID:   claims.ID,
+		UserID:    claims.UID,
+		Email:     claims.Email,
+		AppID:     claims.AppID,
+		SessionID: claims.SessionID,
+		Scopes:    strings.Fields(claims.Scope),
+		OrgID:     claims.OrgID,
+		Guest:     claims.Guest,
+		Offline:   claims.Offline,
+		AMR:       claims.AMR,
+		ACR:       claims.ACR,
+		Purpose:   claims.Purpose,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+	if claims.IssuedAt != nil {
+		info.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.AuthTime != nil {
+		info.AuthTime = claims.AuthTime.Time
+	}
+
+	switch {
+	case validity == nil:
+		info.Validity = models.TokenValid
+	case errors.Is(validity, jwt.ErrTokenExpired):
+		info.Validity = models.TokenExpired
+	case errors.Is(validity, jwt.ErrTokenNotValidYet):
+		info.Validity = models.TokenNotValidYet
+	default:
+		return models.TokenInfo{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, validity)
+	}
+
+	denied, err := a.tokenDenied(ctx, claims.ID)
+	if err != nil {
+		return models.TokenInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if denied {
+		info.Revoked, info.RevokedReason = true, models.RevokedByToken
+	}
+
+	if claims.Offline && a.offline != nil && !info.Revoked {
+		t, err := a.offline.OfflineToken(ctx, claims.ID)
+		switch {
+		case errors.Is(err, storage.ErrOfflineTokenNotFound):
+		case err != nil:
+			return models.TokenInfo{}, fmt.Errorf("%s: %w", op, err)
+		case !t.RevokedAt.IsZero():
+			info.Revoked, info.RevokedReason = true, models.RevokedByToken
+		}
+	}
+
+	if claims.SessionID != "" && a.sessions != nil {
+		session, err := a.sessions.Session(ctx, claims.SessionID)
+		switch {
+		case errors.Is(err, storage.ErrSessionNotFound):
+		case err != nil:
+			return models.TokenInfo{}, fmt.Errorf("%s: %w", op, err)
+		default:
+			info.Session = &session
+			if !session.RevokedAt.IsZero() && !info.Revoked {
+				info.Revoked, info.RevokedReason = true, models.RevokedBySession
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// RevokeToken - отзывает один токен: завершает его сессию, а если сессии нет - вносит jti в список
+// отозванных (истёкший токен без сессии уже не действует, отзывать его не нужно). Работает и для истёкших токенов
+func (a *AuthService) RevokeToken(ctx context.Context, token string) (models.TokenInfo, error) {
+	const op = "Auth.RevokeToken"
+
+	info, err := a.InspectToken(ctx, token)
+	if err != nil {
+		return models.TokenInfo{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := a.clock.Now()
+	var revoked []models.Revocation
+
+	if info.SessionID != "" && a.sessions != nil {
+		err := a.sessions.RevokeSession(ctx, info.SessionID, now)
+		switch {
+		case errors.Is(err, storage.ErrSessionNotFound):
+			// Сессия уже завершена или удалена: токен сессии и так не действует
+		case err != nil:
+			return models.TokenInfo{}, fmt.Errorf("%s: %w", op, err)
+		default:
+			revoked = append(revoked, models.Revocation{
+				Kind: models.RevocationSession, UserID: info.UserID, AppID: info.AppID, SessionID: info.SessionID,
+			})
+			if info.Session != nil {
+				info.Session.RevokedAt = now
+			}
+			info.Revoked, info.RevokedReason = true, models.RevokedBySession
+		}
+	}
+
+	// Offline-токен отзывается в своём хранилище: там его и проверяет Authenticate
+	if info.Offline && !info.Revoked && a.offline != nil {
+		err := a.revokeOfflineToken(ctx, models.OfflineToken{ID: info.TokenID, UserID: info.UserID, AppID: info.AppID})
+		switch {
+		case errors.Is(err, ErrOfflineTokenNotFound):
+			// Токен не выдавался через IssueOfflineToken - отзывается по jti, как обычный
+		case err != nil:
+			return models.TokenInfo{}, fmt.Errorf("%s: %w", op, err)
+		default:
+			info.Revoked, info.RevokedReason = true, models.RevokedByToken
+		}
+	}
+
+	// Токены сессии отклоняются вместе с ней; в список попадают только токены без сессии
+	if !info.Revoked && info.Validity != models.TokenExpired {
+		if a.denylist == nil || info.TokenID == "" {
+			return models.TokenInfo{}, fmt.Errorf("%s: %w", op, ErrTokenRevocationDisabled)
+		}
+
+		err := a.denylist.DenyToken(ctx, models.DeniedToken{
+			TokenID: info.TokenID, UserID: info.UserID, AppID: info.AppID, ExpiresAt: info.ExpiresAt, RevokedAt: now,
+		})
+		if err != nil {
+			return models.TokenInfo{}, fmt.Errorf("%s: %w", op, err)
+		}
+
+		revoked = append(revoked, models.Revocation{
+			Kind: models.RevocationToken, UserID: info.UserID, AppID: info.AppID, TokenID: info.TokenID,
+		})
+		info.Revoked, info.RevokedReason = true, models.RevokedByToken
+	}
+
+	logctx.FromOr(ctx, a.log).Info("token revoked",
+		slog.String("op", op),
+		slog.Int64("user_id", info.UserID),
+		slog.Int("app_id", info.AppID),
+		slog.String("token_id", info.TokenID),
+		slog.String("validity", info.Validity))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventTokenRevoked, Outcome: audit.OutcomeSuccess,
+		UserID: info.UserID, AppID: info.AppID, Email: info.Email,
+	})
+
+	if len(revoked) > 0 {
+		e := events.New(ctx, events.TypeTokenRevoked, info.UserID)
+		e.AppID = info.AppID
+		a.publish(ctx, e)
+	}
+	for _, r := range revoked {
+		a.recordRevocation(ctx, r)
+	}
+
+	return info, nil
+}
+
+// tokenDenied - отозван ли токен с этим jti (без списка отозванных - нет)
+func (a *AuthService) tokenDenied(ctx context.Context, jti string) (bool, error) {
+	if a.denylist == nil || jti == "" {
+		return false, nil
+	}
+
+	return a.denylist.TokenDenied(ctx, jti)
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func testToken(t *testing.T, ttl time.Duration, opts ...jwt.TokenOption) string {
+	t.Helper()
+
+	token, err := jwt.NewToken(models.User{ID: 7, Email: "a@b.c"}, testApp, ttl, opts...)
+	require.NoError(t, err)
+
+	return token
+}
+
+func TestInspectToken(t *testing.T) {
+	denylist := mocks.NewTokenDenylist(t)
+	a, _, _, apps := newTestService(t, WithTokenDenylist(denylist))
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil).Maybe()
+	apps.EXPECT().App(mock.Anything, 99).Return(models.App{}, storage.ErrAppNotFound).Maybe()
+	denylist.EXPECT().TokenDenied(mock.Anything, mock.Anything).Return(false, nil).Maybe()
+	ctx := context.Background()
+
+	// Истёкший токен разбирается, а не отклоняется
+	info, err := a.InspectToken(ctx, testToken(t, -time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, models.TokenExpired, info.Validity)
+	assert.Equal(t, int64(7), info.UserID)
+	assert.NotEmpty(t, info.TokenID)
+	assert.False(t, info.Revoked)
+
+	info, err = a.InspectToken(ctx, testToken(t, time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, models.TokenValid, info.Validity)
+
+	other := testApp
+	other.ID = 99
+	token, err := jwt.NewToken(models.User{ID: 7}, other, time.Hour)
+	require.NoError(t, err)
+	_, err = a.InspectToken(ctx, token)
+	require.ErrorIs(t, err, ErrInvalidAppID)
+
+	_, err = a.InspectToken(ctx, "not a token")
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	forged := testApp
+	forged.Secret = "other-secret"
+	token, err = jwt.NewToken(models.User{ID: 7}, forged, time.Hour)
+	require.NoError(t, err)
+	_, err = a.InspectToken(ctx, token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestRevokeToken_WithoutSession(t *testing.T) {
+	denylist := mocks.NewTokenDenylist(t)
+	var auditLog bytes.Buffer
+	a, _, provider, apps := newTestService(t, WithTokenDenylist(denylist), WithAudit(audit.New(&auditLog)))
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, IsActive: true}, nil).Maybe()
+	ctx := context.Background()
+
+	denied := map[string]models.DeniedToken{}
+	denylist.EXPECT().DenyToken(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, d models.DeniedToken) error {
+			denied[d.TokenID] = d
+			return nil
+		})
+	denylist.EXPECT().TokenDenied(mock.Anything, mock.Anything).
+		RunAndReturn(func(_ context.Context, jti string) (bool, error) {
+			_, ok := denied[jti]
+			return ok, nil
+		})
+
+	token := testToken(t, time.Hour)
+	_, err := a.Authenticate(ctx, token)
+	require.NoError(t, err)
+
+	info, err := a.RevokeToken(ctx, token)
+	require.NoError(t, err)
+	assert.True(t, info.Revoked)
+	assert.Equal(t, "token", info.RevokedReason)
+	assert.WithinDuration(t, info.ExpiresAt, denied[info.TokenID].ExpiresAt, time.Second)
+	assert.Contains(t, auditLog.String(), `"event":"token.revoked"`)
+
+	_, err = a.Authenticate(ctx, token)
+	require.ErrorIs(t, err, ErrInvalidToken)
+
+	info, err = a.InspectToken(ctx, token)
+	require.NoError(t, err)
+	assert.True(t, info.Revoked)
+
+	// Истёкший токен без сессии уже не действует: в список он не попадает
+	expired := testToken(t, -time.Hour)
+	_, err = a.RevokeToken(ctx, expired)
+	require.NoError(t, err)
+	assert.Len(t, denied, 1)
+}
+
+func TestRevokeToken_Session(t *testing.T) {
+	sessions := mocks.NewSessionStore(t)
+	a, _, _, apps := newTestService(t, WithSessions(sessions))
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	ctx := context.Background()
+
+	session := models.Session{ID: "sid-1", UserID: 7, AppID: testApp.ID, CreatedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)}
+	sessions.EXPECT().Session(mock.Anything, "sid-1").Return(session, nil)
+	sessions.EXPECT().RevokeSession(mock.Anything, "sid-1", mock.Anything).Return(nil).Once()
+
+	// Токен сессии отзывается вместе с ней, даже если истёк; список отозванных не нужен
+	info, err := a.RevokeToken(ctx, testToken(t, -time.Hour, jwt.WithSession("sid-1")))
+	require.NoError(t, err)
+	assert.Equal(t, "session", info.RevokedReason)
+	require.NotNil(t, info.Session)
+	assert.False(t, info.Session.RevokedAt.IsZero())
+
+	// Без списка отозванных токен без сессии отозвать нельзя
+	_, err = a.RevokeToken(ctx, testToken(t, time.Hour))
+	require.ErrorIs(t, err, ErrTokenRevocationDisabled)
+}
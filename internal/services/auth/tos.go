@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sso/internal/domain/models"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/logctx"
+	"sso/internal/storage"
+)
+
+// Ошибки условий использования
+var (
+	ErrTOSNotAccepted          = errors.New("terms of service not accepted")              // Ошибка, если при регистрации не принята текущая версия условий.
+	ErrTOSReacceptanceRequired = errors.New("terms of service re-acceptance required")    // Ошибка, если вход требует принять новую версию условий.
+	ErrInvalidTOSVersion       = errors.New("terms of service version is not current")    // Ошибка, если принимается не текущая версия условий.
+	ErrTOSDisabled             = errors.New("terms of service acceptance is not tracked") // Ошибка, если версия условий не задана.
+)
+
+// TOSPolicy - текущие условия использования.
+type TOSPolicy struct {
+	Version        string // Текущая версия (пусто - принятие условий не проверяется).
+	EnforceOnLogin bool   // Не выдавать токен, пока пользователь не примет текущую версию.
+}
+
+// TOSStore - хранилище принятий условий использования.
+type TOSStore interface {
+	// AcceptTOS - записывает принятие версии условий.
+	AcceptTOS(ctx context.Context, userID int64, version string) error
+	// LatestTOSAcceptance - последнее принятие (storage.ErrTOSNotAccepted, если не принимал).
+	LatestTOSAcceptance(ctx context.Context, userID int64) (models.TOSAcceptance, error)
+	// UsersPendingTOS - до limit пользователей с id больше afterID, не принявших version.
+	UsersPendingTOS(ctx context.Context, version string, afterID int64, limit int) ([]models.PendingTOS, error)
+}
+
+// WithTOS - включает учёт принятия условий использования: регистрация требует принять текущую версию,
+// а вход отмечает (или, если p.EnforceOnLogin, блокирует) пользователей с устаревшим принятием.
+func WithTOS(store TOSStore, p TOSPolicy) Option {
+	return func(a *AuthService) {
+		a.tosStore = store
+		a.SetTOSPolicy(p)
+	}
+}
+
+// SetTOSPolicy - меняет текущую версию условий (безопасно вызывать во время работы сервиса)
+func (a *AuthService) SetTOSPolicy(p TOSPolicy) {
+	a.tos.Store(&p)
+}
+
+// tosPolicy - действующие условия; без хранилища или версии условия не проверяются
+func (a *AuthService) tosPolicy() TOSPolicy {
+	p := a.tos.Load()
+	if a.tosStore == nil || p == nil {
+		return TOSPolicy{}
+	}
+
+	return *p
+}
+
+// TOSVersion - текущая версия условий использования (пусто - не проверяются)
+func (a *AuthService) TOSVersion() string {
+	return a.tosPolicy().Version
+}
+
+// AcceptTOS - записывает, что пользователь принял условия. Принять можно только текущую версию:
+// клиент передаёт версию, которую показал пользователю, и устаревшая страница не засчитывается.
+func (a *AuthService) AcceptTOS(ctx context.Context, userID int64, version string) error {
+	const op = "Auth.AcceptTOS"
+
+	current := a.tosPolicy().Version
+	if current == "" {
+		return fmt.Errorf("%s: %w", op, ErrTOSDisabled)
+	}
+	if version != current {
+		return fmt.Errorf("%s: %w", op, ErrInvalidTOSVersion)
+	}
+
+	if err := a.tosStore.AcceptTOS(ctx, userID, version); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	logctx.FromOr(ctx, a.log).Info("terms of service accepted",
+		slog.String("op", op),
+		slog.Int64("user_id", userID),
+		slog.String("version", version))
+	a.audit.Emit(ctx, audit.Event{
+		Name: audit.EventTOSAccepted, Outcome: audit.OutcomeSuccess,
+		UserID: userID,
+	})
+
+	return nil
+}
+
+// UsersPendingTOS - до limit пользователей с id больше afterID, которым нужно принять текущую версию условий.
+func (a *AuthService) UsersPendingTOS(ctx context.Context, afterID int64, limit int) ([]models.PendingTOS, error) {
+	const op = "Auth.UsersPendingTOS"
+
+	current := a.tosPolicy().Version
+	if current == "" {
+		return nil, fmt.Errorf("%s: %w", op, ErrTOSDisabled)
+	}
+
+	pending, err := a.tosStore.UsersPendingTOS(ctx, current, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pending, nil
+}
+
+// tosPending - нужно ли пользователю принять текущую версию условий при входе. Если accepted совпадает
+// с текущей версией, принятие записывается здесь же: иначе при EnforceOnLogin пользователь не смог бы
+// ни войти, ни вызвать AcceptTOS без токена
+func (a *AuthService) tosPending(ctx context.Context, userID int64, accepted string) (bool, error) {
+	current := a.tosPolicy().Version
+	if current == "" {
+		return false, nil
+	}
+
+	if accepted == current {
+		if err := a.AcceptTOS(ctx, userID, accepted); err != nil {
+			return false, err
+		}
+
+		return false, nil
+	}
+
+	latest, err := a.tosStore.LatestTOSAcceptance(ctx, userID)
+	if err != nil {
+		if errors.Is(err, storage.ErrTOSNotAccepted) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	return latest.Version != current, nil
+}
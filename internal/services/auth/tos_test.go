@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterNewUser_RequiresCurrentTOS(t *testing.T) {
+	tos := mocks.NewTOSStore(t)
+	a, saver, _, _ := newTestService(t, WithTOS(tos, TOSPolicy{Version: "2025-01"}))
+
+	// Без принятия или с устаревшей версией пользователь не создаётся
+	for _, accepted := range []string{"", "2024-01"} {
+		_, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", accepted, "", 0)
+		require.ErrorIs(t, err, ErrTOSNotAccepted, "accepted %q", accepted)
+	}
+
+	saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).Return(7, nil)
+	tos.EXPECT().AcceptTOS(mock.Anything, int64(7), "2025-01").Return(nil)
+
+	id, err := a.RegisterNewUser(context.Background(), "a@b.c", "secret", "", "2025-01", "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), id)
+}
+
+func TestLogin_TOSReacceptance(t *testing.T) {
+	user := userWithPassword(t, "secret")
+	stale := models.TOSAcceptance{UserID: user.ID, Version: "2024-01"}
+
+	t.Run("flagged", func(t *testing.T) {
+		tos := mocks.NewTOSStore(t)
+		a, _, provider, apps := newTestService(t, WithTOS(tos, TOSPolicy{Version: "2025-01"}))
+
+		provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+		apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+		tos.EXPECT().LatestTOSAcceptance(mock.Anything, user.ID).Return(stale, nil)
+
+		token, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+		require.NoError(t, err)
+		assert.NotEmpty(t, token.AccessToken)
+		assert.True(t, token.TOSReacceptanceRequired)
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		tos := mocks.NewTOSStore(t)
+		a, _, provider, apps := newTestService(t, WithTOS(tos, TOSPolicy{Version: "2025-01", EnforceOnLogin: true}))
+
+		provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+		apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+		tos.EXPECT().LatestTOSAcceptance(mock.Anything, user.ID).
+			Return(models.TOSAcceptance{}, fmt.Errorf("storage.sqlite.LatestTOSAcceptance: %w", storage.ErrTOSNotAccepted)).Once()
+
+		_, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+		require.ErrorIs(t, err, ErrTOSReacceptanceRequired)
+
+		// Принятие текущей версии на странице входа записывается, и токен выдаётся
+		tos.EXPECT().AcceptTOS(mock.Anything, user.ID, "2025-01").Return(nil).Once()
+
+		token, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "2025-01", "")
+		require.NoError(t, err)
+		assert.False(t, token.TOSReacceptanceRequired)
+	})
+}
+
+func TestAcceptTOS_OnlyCurrentVersion(t *testing.T) {
+	tos := mocks.NewTOSStore(t)
+	a, _, _, _ := newTestService(t, WithTOS(tos, TOSPolicy{Version: "2025-01"}))
+
+	require.ErrorIs(t, a.AcceptTOS(context.Background(), 7, "2024-01"), ErrInvalidTOSVersion)
+
+	tos.EXPECT().AcceptTOS(mock.Anything, int64(7), "2025-01").Return(nil)
+	require.NoError(t, a.AcceptTOS(context.Background(), 7, "2025-01"))
+
+	// Новая версия после перезагрузки конфигурации: прежняя больше не принимается
+	a.SetTOSPolicy(TOSPolicy{Version: "2025-06"})
+	require.ErrorIs(t, a.AcceptTOS(context.Background(), 7, "2025-01"), ErrInvalidTOSVersion)
+
+	// Без версии условия не учитываются
+	a, _, _, _ = newTestService(t)
+	require.ErrorIs(t, a.AcceptTOS(context.Background(), 7, "2025-01"), ErrTOSDisabled)
+}
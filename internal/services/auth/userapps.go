@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sso/internal/lib/logctx"
+	"time"
+)
+
+// ErrAppRequired - ошибка, если регистрация требует app_id (WithUserApps с requireApp), а он не передан.
+var ErrAppRequired = errors.New("app id is required")
+
+// UserAppStore - связи пользователей с приложениями, в которые они входили или через которые регистрировались.
+type UserAppStore interface {
+	// AddUserApp - связывает пользователя с приложением, если связи ещё нет.
+	AddUserApp(ctx context.Context, userID int64, appID int, at time.Time) error
+	// UserApps - приложения пользователя.
+	UserApps(ctx context.Context, userID int64) ([]int, error)
+}
+
+// WithUserApps - пользователь связывается с приложением при регистрации через него и при первом входе.
+// С requireApp регистрация без app_id отклоняется (ErrAppRequired): каждый аккаунт сразу принадлежит приложению.
+func WithUserApps(store UserAppStore, requireApp bool) Option {
+	return func(a *AuthService) {
+		a.userApps = store
+		a.requireApp = requireApp
+	}
+}
+
+// recordUserApp - связывает пользователя с приложением, в которое он вошёл. Вход к этому моменту
+// уже разрешён, поэтому ошибка хранилища только логируется
+func (a *AuthService) recordUserApp(ctx context.Context, userID int64, appID int) {
+	if a.userApps == nil {
+		return
+	}
+
+	if err := a.userApps.AddUserApp(ctx, userID, appID, a.clock.Now()); err != nil {
+		logctx.FromOr(ctx, a.log).Error("failed to record user app",
+			slog.Int64("user_id", userID), slog.Int("app_id", appID), slog.String("error", err.Error()))
+	}
+}
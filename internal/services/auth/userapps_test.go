@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/services/auth/mocks"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterNewUser_UserApps(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("app required", func(t *testing.T) {
+		a, _, _, _ := newTestService(t, WithUserApps(mocks.NewUserAppStore(t), true))
+
+		_, err := a.RegisterNewUser(ctx, "a@b.c", "secret", "", "", "", 0)
+		require.ErrorIs(t, err, ErrAppRequired)
+	})
+
+	t.Run("unknown app", func(t *testing.T) {
+		a, _, _, apps := newTestService(t, WithUserApps(mocks.NewUserAppStore(t), false))
+		apps.EXPECT().App(mock.Anything, 5).Return(models.App{}, fmt.Errorf("storage.sqlite.App: %w", storage.ErrAppNotFound))
+
+		_, err := a.RegisterNewUser(ctx, "a@b.c", "secret", "", "", "", 5)
+		require.ErrorIs(t, err, ErrInvalidAppID)
+	})
+
+	t.Run("born scoped", func(t *testing.T) {
+		userApps := mocks.NewUserAppStore(t)
+		a, saver, _, apps := newTestService(t, WithUserApps(userApps, true))
+		apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+		saver.EXPECT().SaveUser(mock.Anything, "a@b.c", mock.Anything).Return(42, nil)
+		userApps.EXPECT().AddUserApp(mock.Anything, int64(42), testApp.ID, mock.Anything).Return(nil)
+
+		id, err := a.RegisterNewUser(ctx, "a@b.c", "secret", "", "", "", testApp.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), id)
+	})
+}
+
+func TestLogin_RecordsUserApp(t *testing.T) {
+	userApps := mocks.NewUserAppStore(t)
+	a, _, provider, apps := newTestService(t, WithUserApps(userApps, false))
+	user := userWithPassword(t, "secret")
+
+	provider.EXPECT().User(mock.Anything, "a@b.c").Return(user, nil)
+	apps.EXPECT().App(mock.Anything, testApp.ID).Return(testApp, nil)
+	userApps.EXPECT().AddUserApp(mock.Anything, user.ID, testApp.ID, mock.Anything).Return(nil)
+
+	_, err := a.Login(context.Background(), "a@b.c", "secret", testApp.ID, "", "", "")
+	require.NoError(t, err)
+}
+
+func TestExportUserData_Apps(t *testing.T) {
+	userApps := mocks.NewUserAppStore(t)
+	a, _, provider, _ := newTestService(t, WithUserApps(userApps, false))
+
+	provider.EXPECT().UserByID(mock.Anything, int64(7)).Return(models.User{ID: 7, Email: "a@b.c"}, nil)
+	provider.EXPECT().UserMetadata(mock.Anything, int64(7)).Return([]byte("{}"), nil)
+	userApps.EXPECT().UserApps(mock.Anything, int64(7)).Return([]int{1, 3}, nil)
+
+	data, err := a.ExportUserData(context.Background(), 7)
+	require.NoError(t, err)
+
+	var export models.UserDataExport
+	require.NoError(t, json.Unmarshal(data, &export))
+	assert.Equal(t, []int{1, 3}, export.Apps)
+}
@@ -0,0 +1,1210 @@
+// Package instrumented - метрики хранилища: длительность каждого метода и ошибки по видам.
+//
+// Storage оборачивает любой бэкенд, реализующий Backend, поэтому метрики есть у всех
+// хранилищ без изменений в их коде. Метрики видны в /debug/vars:
+//
+//	storage_query_duration_seconds - гистограмма длительности по методам
+//	storage_errors                 - {"<метод>": {"not_found": n, "conflict": n, "busy": n, "other": n}}
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"sso/internal/domain/models"
+	admingrpc "sso/internal/grpc/admin"
+	authgrpc "sso/internal/grpc/auth"
+	"sso/internal/lib/audit"
+	"sso/internal/lib/auditchain"
+	"sso/internal/lib/backlog"
+	"sso/internal/lib/events"
+	"sso/internal/lib/metrics"
+	"sso/internal/lib/revocations"
+	"sso/internal/services/auth"
+	"sso/internal/storage"
+	"sync"
+	"time"
+)
+
+// Виды ошибок в storage_errors
+const (
+	KindNotFound = "not_found" // запись не найдена
+	KindConflict = "conflict"  // нарушение уникальности или устаревшая версия
+	KindBusy     = "busy"      // хранилище занято (блокировка, таймаут ожидания)
+	KindOther    = "other"     // всё остальное
+)
+
+// Метрики хранилища (видны в /debug/vars)
+var (
+	queryDuration = metrics.NewHistogramVec(metrics.DurationBuckets)
+	queryErrors   = expvar.NewMap("storage_errors")
+
+	errorsMu sync.Mutex // создание вложенной карты метода в queryErrors
+)
+
+func init() {
+	expvar.Publish("storage_query_duration_seconds", queryDuration)
+}
+
+// Backend - всё, что приложение использует из хранилища
+type Backend interface {
+	auth.UserSaver
+	auth.UserProvider
+	auth.AppProvider
+	auth.Transactor
+	auth.OrgStore
+	auth.InvitationStore
+	auth.UserGroupsProvider
+	auth.ConsentStore
+	auth.TOSStore
+	auth.AccountDeletionStore
+	auth.AppMetadataStore
+	auth.GuestStore
+	auth.ActionTokenStore
+	auth.DeviceStore
+	auth.PasskeyStore
+	auth.SMSStore
+	auth.OIDCStore
+	auth.SessionStore
+	auth.OfflineTokenStore
+	auth.RefreshTokenStore
+	auth.ProvisioningStore
+	auth.SelfTestStore
+	auth.RevocationStore
+	auth.TokenDenylist
+	auth.LoginHistoryStore
+	revocations.Store
+	admingrpc.UserAdmin
+	admingrpc.AppAdmin
+	admingrpc.OrgAdmin
+	admingrpc.GroupAdmin
+	admingrpc.Backuper
+	admingrpc.StatsProvider
+	admingrpc.AuditExporter
+	admingrpc.AppScope
+	auth.UserAppStore
+	audit.Store
+	auditchain.Store
+	authgrpc.SchemaProvider
+	events.OutboxStore
+	backlog.Store
+
+	// DeleteInactiveGuests - удаляет до limit гостей, неактивных с before (janitor)
+	DeleteInactiveGuests(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredActionTokens - удаляет до limit токенов одного действия, истёкших до before (janitor)
+	DeleteExpiredActionTokens(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredDeviceAuthorizations - удаляет до limit входов на устройствах, истёкших до before (janitor)
+	DeleteExpiredDeviceAuthorizations(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredWebAuthnSessions - удаляет до limit церемоний WebAuthn, истёкших до before (janitor)
+	DeleteExpiredWebAuthnSessions(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredSMSChallenges - удаляет до limit вызовов с кодом из SMS, истёкших до before (janitor)
+	DeleteExpiredSMSChallenges(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteSMSSendsBefore - удаляет до limit записей об отправленных SMS старше before (janitor)
+	DeleteSMSSendsBefore(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredAuthCodes - удаляет до limit кодов авторизации, истёкших до before (janitor)
+	DeleteExpiredAuthCodes(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredSessions - удаляет до limit сессий, истёкших до before (janitor)
+	DeleteExpiredSessions(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredOfflineTokens - удаляет до limit offline-токенов, истёкших до before (janitor)
+	DeleteExpiredOfflineTokens(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredRefreshFamilies - удаляет до limit цепочек refresh-токенов, истёкших до before (janitor)
+	DeleteExpiredRefreshFamilies(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteRevocationsBefore - удаляет до limit отзывов, записанных до before (janitor)
+	DeleteRevocationsBefore(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteExpiredDeniedTokens - удаляет до limit отозванных токенов, истёкших до before (janitor)
+	DeleteExpiredDeniedTokens(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteLoginsBefore - удаляет до limit записей истории входов старше before (janitor)
+	DeleteLoginsBefore(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteLoginFailuresBefore - удаляет до limit неудачных входов старше before (janitor)
+	DeleteLoginFailuresBefore(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteAuditEventsBefore - удаляет до limit событий журнала безопасности старше before (janitor)
+	DeleteAuditEventsBefore(ctx context.Context, before time.Time, limit int) (int, error)
+	// DeleteStaleSelfTestData - удаляет до limit пользователей и приложений самопроверки старше before (janitor)
+	DeleteStaleSelfTestData(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+// Storage - Backend, записывающий метрики каждого вызова
+type Storage struct {
+	next   Backend
+	isBusy func(error) bool
+}
+
+var _ Backend = (*Storage)(nil)
+
+// New - оборачивает next. isBusy распознаёт ошибки занятости конкретного бэкенда (может быть nil)
+func New(next Backend, isBusy func(error) bool) *Storage {
+	return &Storage{next: next, isBusy: isBusy}
+}
+
+// observe - записывает длительность вызова method и вид ошибки, если она есть
+func (s *Storage) observe(method string, start time.Time, err error) {
+	queryDuration.With(method).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		countError(method, s.kind(err))
+	}
+}
+
+// kind - вид ошибки для storage_errors
+func (s *Storage) kind(err error) string {
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound), errors.Is(err, storage.ErrAppNotFound),
+		errors.Is(err, storage.ErrGroupNotFound), errors.Is(err, storage.ErrNotInGroup),
+		errors.Is(err, storage.ErrConsentNotFound), errors.Is(err, storage.ErrTOSNotAccepted),
+		errors.Is(err, storage.ErrAccountDeletionNotFound):
+		return KindNotFound
+	case errors.Is(err, storage.ErrUserExists), errors.Is(err, storage.ErrAppExists),
+		errors.Is(err, storage.ErrOrgExists), errors.Is(err, storage.ErrVersionConflict),
+		errors.Is(err, storage.ErrInvitationUsed), errors.Is(err, storage.ErrGroupExists):
+		return KindConflict
+	case errors.Is(err, context.DeadlineExceeded), s.isBusy != nil && s.isBusy(err):
+		return KindBusy
+	default:
+		return KindOther
+	}
+}
+
+func countError(method, kind string) {
+	m, ok := queryErrors.Get(method).(*expvar.Map)
+	if !ok {
+		errorsMu.Lock()
+		if m, ok = queryErrors.Get(method).(*expvar.Map); !ok {
+			m = new(expvar.Map)
+			queryErrors.Set(method, m)
+		}
+		errorsMu.Unlock()
+	}
+
+	m.Add(kind, 1)
+}
+
+func (s *Storage) SaveUser(ctx context.Context, email string, passHash []byte) (id int64, err error) {
+	defer func(start time.Time) { s.observe("SaveUser", start, err) }(time.Now())
+
+	return s.next.SaveUser(ctx, email, passHash)
+}
+
+func (s *Storage) EraseUser(ctx context.Context, userID int64, tombstone string) (err error) {
+	defer func(start time.Time) { s.observe("EraseUser", start, err) }(time.Now())
+
+	return s.next.EraseUser(ctx, userID, tombstone)
+}
+
+func (s *Storage) UpdatePassword(ctx context.Context, userID int64, passHash []byte, keep int) (err error) {
+	defer func(start time.Time) { s.observe("UpdatePassword", start, err) }(time.Now())
+
+	return s.next.UpdatePassword(ctx, userID, passHash, keep)
+}
+
+func (s *Storage) RehashPassword(ctx context.Context, userID int64, oldHash, newHash []byte) (err error) {
+	defer func(start time.Time) { s.observe("RehashPassword", start, err) }(time.Now())
+
+	return s.next.RehashPassword(ctx, userID, oldHash, newHash)
+}
+
+func (s *Storage) User(ctx context.Context, email string) (user models.User, err error) {
+	defer func(start time.Time) { s.observe("User", start, err) }(time.Now())
+
+	return s.next.User(ctx, email)
+}
+
+func (s *Storage) IsAdmin(ctx context.Context, userID int64) (isAdmin bool, err error) {
+	defer func(start time.Time) { s.observe("IsAdmin", start, err) }(time.Now())
+
+	return s.next.IsAdmin(ctx, userID)
+}
+
+func (s *Storage) IsUserExists(ctx context.Context, userID int64) (exists bool, err error) {
+	defer func(start time.Time) { s.observe("IsUserExists", start, err) }(time.Now())
+
+	return s.next.IsUserExists(ctx, userID)
+}
+
+func (s *Storage) UsersByIDs(ctx context.Context, userIDs []int64) (users []models.User, err error) {
+	defer func(start time.Time) { s.observe("UsersByIDs", start, err) }(time.Now())
+
+	return s.next.UsersByIDs(ctx, userIDs)
+}
+
+func (s *Storage) UserByID(ctx context.Context, userID int64) (user models.User, err error) {
+	defer func(start time.Time) { s.observe("UserByID", start, err) }(time.Now())
+
+	return s.next.UserByID(ctx, userID)
+}
+
+func (s *Storage) UserMetadata(ctx context.Context, userID int64) (metadata []byte, err error) {
+	defer func(start time.Time) { s.observe("UserMetadata", start, err) }(time.Now())
+
+	return s.next.UserMetadata(ctx, userID)
+}
+
+func (s *Storage) PasswordHistory(ctx context.Context, userID int64, limit int) (hashes [][]byte, err error) {
+	defer func(start time.Time) { s.observe("PasswordHistory", start, err) }(time.Now())
+
+	return s.next.PasswordHistory(ctx, userID, limit)
+}
+
+func (s *Storage) App(ctx context.Context, appID int) (app models.App, err error) {
+	defer func(start time.Time) { s.observe("App", start, err) }(time.Now())
+
+	return s.next.App(ctx, appID)
+}
+
+// WithinTx - длительность всей транзакции вместе с fn
+func (s *Storage) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer func(start time.Time) { s.observe("WithinTx", start, err) }(time.Now())
+
+	return s.next.WithinTx(ctx, fn)
+}
+
+func (s *Storage) ListUsers(
+	ctx context.Context,
+	afterID int64,
+	limit int,
+	emailPrefix string,
+	includeGuests bool,
+	appID int,
+) (users []models.User, err error) {
+	defer func(start time.Time) { s.observe("ListUsers", start, err) }(time.Now())
+
+	return s.next.ListUsers(ctx, afterID, limit, emailPrefix, includeGuests, appID)
+}
+
+func (s *Storage) SetAdmin(ctx context.Context, userID int64, isAdmin bool, expectedVersion int64) (version int64, err error) {
+	defer func(start time.Time) { s.observe("SetAdmin", start, err) }(time.Now())
+
+	return s.next.SetAdmin(ctx, userID, isAdmin, expectedVersion)
+}
+
+func (s *Storage) SetForcePasswordChange(
+	ctx context.Context,
+	userID int64,
+	force bool,
+	expectedVersion int64,
+) (version int64, err error) {
+	defer func(start time.Time) { s.observe("SetForcePasswordChange", start, err) }(time.Now())
+
+	return s.next.SetForcePasswordChange(ctx, userID, force, expectedVersion)
+}
+
+func (s *Storage) SaveUsers(ctx context.Context, users []models.User) (results []error, err error) {
+	defer func(start time.Time) { s.observe("SaveUsers", start, err) }(time.Now())
+
+	return s.next.SaveUsers(ctx, users)
+}
+
+func (s *Storage) SaveApp(ctx context.Context, name string, secret string) (id int, err error) {
+	defer func(start time.Time) { s.observe("SaveApp", start, err) }(time.Now())
+
+	return s.next.SaveApp(ctx, name, secret)
+}
+
+func (s *Storage) SetAppGroupsClaim(ctx context.Context, appID int, enabled bool) (err error) {
+	defer func(start time.Time) { s.observe("SetAppGroupsClaim", start, err) }(time.Now())
+
+	return s.next.SetAppGroupsClaim(ctx, appID, enabled)
+}
+
+func (s *Storage) SetAppThirdParty(ctx context.Context, appID int, thirdParty bool) (err error) {
+	defer func(start time.Time) { s.observe("SetAppThirdParty", start, err) }(time.Now())
+
+	return s.next.SetAppThirdParty(ctx, appID, thirdParty)
+}
+
+func (s *Storage) SetAppSessionTimeouts(ctx context.Context, appID int, idle, maxLifetime time.Duration) (err error) {
+	defer func(start time.Time) { s.observe("SetAppSessionTimeouts", start, err) }(time.Now())
+
+	return s.next.SetAppSessionTimeouts(ctx, appID, idle, maxLifetime)
+}
+
+func (s *Storage) SetAppRefreshWindows(ctx context.Context, appID int, sliding, maxLifetime time.Duration) (err error) {
+	defer func(start time.Time) { s.observe("SetAppRefreshWindows", start, err) }(time.Now())
+
+	return s.next.SetAppRefreshWindows(ctx, appID, sliding, maxLifetime)
+}
+
+func (s *Storage) SetAppAcceptOfflineTokens(ctx context.Context, appID int, accept bool) (err error) {
+	defer func(start time.Time) { s.observe("SetAppAcceptOfflineTokens", start, err) }(time.Now())
+
+	return s.next.SetAppAcceptOfflineTokens(ctx, appID, accept)
+}
+
+func (s *Storage) SetAppProvisioner(ctx context.Context, appID int, provisioner bool) (err error) {
+	defer func(start time.Time) { s.observe("SetAppProvisioner", start, err) }(time.Now())
+
+	return s.next.SetAppProvisioner(ctx, appID, provisioner)
+}
+
+func (s *Storage) SetAppLoginPolicy(ctx context.Context, appID int, p models.LoginPolicy) (err error) {
+	defer func(start time.Time) { s.observe("SetAppLoginPolicy", start, err) }(time.Now())
+
+	return s.next.SetAppLoginPolicy(ctx, appID, p)
+}
+
+func (s *Storage) DeleteAppLoginPolicy(ctx context.Context, appID int, name string) (err error) {
+	defer func(start time.Time) { s.observe("DeleteAppLoginPolicy", start, err) }(time.Now())
+
+	return s.next.DeleteAppLoginPolicy(ctx, appID, name)
+}
+
+func (s *Storage) SetAppRecheckNetworkPolicy(ctx context.Context, appID int, recheck bool) (err error) {
+	defer func(start time.Time) { s.observe("SetAppRecheckNetworkPolicy", start, err) }(time.Now())
+
+	return s.next.SetAppRecheckNetworkPolicy(ctx, appID, recheck)
+}
+
+func (s *Storage) SetAppMinACR(ctx context.Context, appID int, minACR string) (err error) {
+	defer func(start time.Time) { s.observe("SetAppMinACR", start, err) }(time.Now())
+
+	return s.next.SetAppMinACR(ctx, appID, minACR)
+}
+
+func (s *Storage) SetAppSessionLimit(ctx context.Context, appID int, maxSessions int, policy string) (err error) {
+	defer func(start time.Time) { s.observe("SetAppSessionLimit", start, err) }(time.Now())
+
+	return s.next.SetAppSessionLimit(ctx, appID, maxSessions, policy)
+}
+
+func (s *Storage) CreateOrganization(ctx context.Context, slug, name string, allowSelfSignup bool) (id int64, err error) {
+	defer func(start time.Time) { s.observe("CreateOrganization", start, err) }(time.Now())
+
+	return s.next.CreateOrganization(ctx, slug, name, allowSelfSignup)
+}
+
+func (s *Storage) OrgBySlug(ctx context.Context, slug string) (org models.Organization, err error) {
+	defer func(start time.Time) { s.observe("OrgBySlug", start, err) }(time.Now())
+
+	return s.next.OrgBySlug(ctx, slug)
+}
+
+func (s *Storage) ScopedUser(ctx context.Context, scope int64, email string) (user models.User, err error) {
+	defer func(start time.Time) { s.observe("ScopedUser", start, err) }(time.Now())
+
+	return s.next.ScopedUser(ctx, scope, email)
+}
+
+func (s *Storage) SaveOrgUser(
+	ctx context.Context,
+	email string,
+	passHash []byte,
+	orgID int64,
+	scope int64,
+	role string,
+) (id int64, err error) {
+	defer func(start time.Time) { s.observe("SaveOrgUser", start, err) }(time.Now())
+
+	return s.next.SaveOrgUser(ctx, email, passHash, orgID, scope, role)
+}
+
+func (s *Storage) MemberRole(ctx context.Context, orgID, userID int64) (role string, err error) {
+	defer func(start time.Time) { s.observe("MemberRole", start, err) }(time.Now())
+
+	return s.next.MemberRole(ctx, orgID, userID)
+}
+
+func (s *Storage) AddMember(ctx context.Context, orgID, userID int64, role string) (err error) {
+	defer func(start time.Time) { s.observe("AddMember", start, err) }(time.Now())
+
+	return s.next.AddMember(ctx, orgID, userID, role)
+}
+
+func (s *Storage) RemoveMember(ctx context.Context, orgID, userID int64) (err error) {
+	defer func(start time.Time) { s.observe("RemoveMember", start, err) }(time.Now())
+
+	return s.next.RemoveMember(ctx, orgID, userID)
+}
+
+func (s *Storage) ListMembers(ctx context.Context, orgID int64) (members []models.OrgMember, err error) {
+	defer func(start time.Time) { s.observe("ListMembers", start, err) }(time.Now())
+
+	return s.next.ListMembers(ctx, orgID)
+}
+
+func (s *Storage) CreateGroup(ctx context.Context, name string) (id int64, err error) {
+	defer func(start time.Time) { s.observe("CreateGroup", start, err) }(time.Now())
+
+	return s.next.CreateGroup(ctx, name)
+}
+
+func (s *Storage) DeleteGroup(ctx context.Context, groupID int64) (err error) {
+	defer func(start time.Time) { s.observe("DeleteGroup", start, err) }(time.Now())
+
+	return s.next.DeleteGroup(ctx, groupID)
+}
+
+func (s *Storage) AddToGroup(ctx context.Context, groupID, userID int64) (err error) {
+	defer func(start time.Time) { s.observe("AddToGroup", start, err) }(time.Now())
+
+	return s.next.AddToGroup(ctx, groupID, userID)
+}
+
+func (s *Storage) RemoveFromGroup(ctx context.Context, groupID, userID int64) (err error) {
+	defer func(start time.Time) { s.observe("RemoveFromGroup", start, err) }(time.Now())
+
+	return s.next.RemoveFromGroup(ctx, groupID, userID)
+}
+
+func (s *Storage) ListGroupMembers(ctx context.Context, groupID int64) (members []models.GroupMember, err error) {
+	defer func(start time.Time) { s.observe("ListGroupMembers", start, err) }(time.Now())
+
+	return s.next.ListGroupMembers(ctx, groupID)
+}
+
+func (s *Storage) UserGroups(ctx context.Context, userID int64) (groups []string, err error) {
+	defer func(start time.Time) { s.observe("UserGroups", start, err) }(time.Now())
+
+	return s.next.UserGroups(ctx, userID)
+}
+
+func (s *Storage) GrantConsent(
+	ctx context.Context,
+	userID int64,
+	appID int,
+	scopes []string,
+) (consent models.Consent, err error) {
+	defer func(start time.Time) { s.observe("GrantConsent", start, err) }(time.Now())
+
+	return s.next.GrantConsent(ctx, userID, appID, scopes)
+}
+
+func (s *Storage) ActiveConsent(ctx context.Context, userID int64, appID int) (consent models.Consent, err error) {
+	defer func(start time.Time) { s.observe("ActiveConsent", start, err) }(time.Now())
+
+	return s.next.ActiveConsent(ctx, userID, appID)
+}
+
+func (s *Storage) ListConsents(ctx context.Context, userID int64) (consents []models.Consent, err error) {
+	defer func(start time.Time) { s.observe("ListConsents", start, err) }(time.Now())
+
+	return s.next.ListConsents(ctx, userID)
+}
+
+func (s *Storage) RevokeConsent(ctx context.Context, userID int64, appID int) (err error) {
+	defer func(start time.Time) { s.observe("RevokeConsent", start, err) }(time.Now())
+
+	return s.next.RevokeConsent(ctx, userID, appID)
+}
+
+func (s *Storage) AppMetadata(ctx context.Context, userID int64, appID int) (metadata []byte, version int64, err error) {
+	defer func(start time.Time) { s.observe("AppMetadata", start, err) }(time.Now())
+
+	return s.next.AppMetadata(ctx, userID, appID)
+}
+
+func (s *Storage) SetAppMetadata(
+	ctx context.Context,
+	userID int64,
+	appID int,
+	metadata []byte,
+	expectedVersion int64,
+) (version int64, err error) {
+	defer func(start time.Time) { s.observe("SetAppMetadata", start, err) }(time.Now())
+
+	return s.next.SetAppMetadata(ctx, userID, appID, metadata, expectedVersion)
+}
+
+func (s *Storage) AllAppMetadata(ctx context.Context, userID int64) (metadata []byte, err error) {
+	defer func(start time.Time) { s.observe("AllAppMetadata", start, err) }(time.Now())
+
+	return s.next.AllAppMetadata(ctx, userID)
+}
+
+func (s *Storage) SaveGuest(ctx context.Context, placeholder string) (id int64, err error) {
+	defer func(start time.Time) { s.observe("SaveGuest", start, err) }(time.Now())
+
+	return s.next.SaveGuest(ctx, placeholder)
+}
+
+func (s *Storage) TouchGuest(ctx context.Context, userID int64) (err error) {
+	defer func(start time.Time) { s.observe("TouchGuest", start, err) }(time.Now())
+
+	return s.next.TouchGuest(ctx, userID)
+}
+
+func (s *Storage) UpgradeGuest(ctx context.Context, userID int64, email string, passHash []byte) (err error) {
+	defer func(start time.Time) { s.observe("UpgradeGuest", start, err) }(time.Now())
+
+	return s.next.UpgradeGuest(ctx, userID, email, passHash)
+}
+
+func (s *Storage) DeleteInactiveGuests(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteInactiveGuests", start, err) }(time.Now())
+
+	return s.next.DeleteInactiveGuests(ctx, before, limit)
+}
+
+func (s *Storage) SaveActionToken(ctx context.Context, t models.ActionToken) (err error) {
+	defer func(start time.Time) { s.observe("SaveActionToken", start, err) }(time.Now())
+
+	return s.next.SaveActionToken(ctx, t)
+}
+
+func (s *Storage) ConsumeActionToken(ctx context.Context, jti string) (t models.ActionToken, err error) {
+	defer func(start time.Time) { s.observe("ConsumeActionToken", start, err) }(time.Now())
+
+	return s.next.ConsumeActionToken(ctx, jti)
+}
+
+func (s *Storage) DeleteExpiredActionTokens(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredActionTokens", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredActionTokens(ctx, before, limit)
+}
+
+func (s *Storage) SaveDeviceAuthorization(ctx context.Context, d models.DeviceAuthorization) (id int64, err error) {
+	defer func(start time.Time) { s.observe("SaveDeviceAuthorization", start, err) }(time.Now())
+
+	return s.next.SaveDeviceAuthorization(ctx, d)
+}
+
+func (s *Storage) DeviceAuthorizationByUserCode(
+	ctx context.Context,
+	userCode string,
+) (d models.DeviceAuthorization, err error) {
+	defer func(start time.Time) { s.observe("DeviceAuthorizationByUserCode", start, err) }(time.Now())
+
+	return s.next.DeviceAuthorizationByUserCode(ctx, userCode)
+}
+
+func (s *Storage) DeviceAuthorizationByCodeHash(
+	ctx context.Context,
+	codeHash string,
+) (d models.DeviceAuthorization, err error) {
+	defer func(start time.Time) { s.observe("DeviceAuthorizationByCodeHash", start, err) }(time.Now())
+
+	return s.next.DeviceAuthorizationByCodeHash(ctx, codeHash)
+}
+
+func (s *Storage) DecideDeviceAuthorization(ctx context.Context, id, userID int64, approve bool) (err error) {
+	defer func(start time.Time) { s.observe("DecideDeviceAuthorization", start, err) }(time.Now())
+
+	return s.next.DecideDeviceAuthorization(ctx, id, userID, approve)
+}
+
+func (s *Storage) RecordDevicePoll(ctx context.Context, id int64, polledAt time.Time, interval time.Duration) (err error) {
+	defer func(start time.Time) { s.observe("RecordDevicePoll", start, err) }(time.Now())
+
+	return s.next.RecordDevicePoll(ctx, id, polledAt, interval)
+}
+
+func (s *Storage) ConsumeDeviceAuthorization(ctx context.Context, id int64) (err error) {
+	defer func(start time.Time) { s.observe("ConsumeDeviceAuthorization", start, err) }(time.Now())
+
+	return s.next.ConsumeDeviceAuthorization(ctx, id)
+}
+
+func (s *Storage) DeleteExpiredDeviceAuthorizations(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredDeviceAuthorizations", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredDeviceAuthorizations(ctx, before, limit)
+}
+
+func (s *Storage) SavePasskey(ctx context.Context, p models.Passkey) (id int64, err error) {
+	defer func(start time.Time) { s.observe("SavePasskey", start, err) }(time.Now())
+
+	return s.next.SavePasskey(ctx, p)
+}
+
+func (s *Storage) Passkeys(ctx context.Context, userID int64) (p []models.Passkey, err error) {
+	defer func(start time.Time) { s.observe("Passkeys", start, err) }(time.Now())
+
+	return s.next.Passkeys(ctx, userID)
+}
+
+func (s *Storage) PasskeyByCredentialID(ctx context.Context, credentialID []byte) (p models.Passkey, err error) {
+	defer func(start time.Time) { s.observe("PasskeyByCredentialID", start, err) }(time.Now())
+
+	return s.next.PasskeyByCredentialID(ctx, credentialID)
+}
+
+func (s *Storage) UpdatePasskeyUsage(
+	ctx context.Context,
+	id int64,
+	signCount uint32,
+	backupState bool,
+	usedAt time.Time,
+) (err error) {
+	defer func(start time.Time) { s.observe("UpdatePasskeyUsage", start, err) }(time.Now())
+
+	return s.next.UpdatePasskeyUsage(ctx, id, signCount, backupState, usedAt)
+}
+
+func (s *Storage) DeletePasskey(ctx context.Context, userID, id int64) (err error) {
+	defer func(start time.Time) { s.observe("DeletePasskey", start, err) }(time.Now())
+
+	return s.next.DeletePasskey(ctx, userID, id)
+}
+
+func (s *Storage) SaveWebAuthnSession(ctx context.Context, session models.WebAuthnSession) (err error) {
+	defer func(start time.Time) { s.observe("SaveWebAuthnSession", start, err) }(time.Now())
+
+	return s.next.SaveWebAuthnSession(ctx, session)
+}
+
+func (s *Storage) TakeWebAuthnSession(ctx context.Context, id string) (session models.WebAuthnSession, err error) {
+	defer func(start time.Time) { s.observe("TakeWebAuthnSession", start, err) }(time.Now())
+
+	return s.next.TakeWebAuthnSession(ctx, id)
+}
+
+func (s *Storage) DeleteExpiredWebAuthnSessions(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredWebAuthnSessions", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredWebAuthnSessions(ctx, before, limit)
+}
+
+func (s *Storage) SaveSMSChallenge(ctx context.Context, c models.SMSChallenge) (err error) {
+	defer func(start time.Time) { s.observe("SaveSMSChallenge", start, err) }(time.Now())
+
+	return s.next.SaveSMSChallenge(ctx, c)
+}
+
+func (s *Storage) SMSChallenge(ctx context.Context, id string) (c models.SMSChallenge, err error) {
+	defer func(start time.Time) { s.observe("SMSChallenge", start, err) }(time.Now())
+
+	return s.next.SMSChallenge(ctx, id)
+}
+
+func (s *Storage) ReserveSMSSend(
+	ctx context.Context,
+	userID int64,
+	phone string,
+	sentAt, since time.Time,
+	maxPerUser, maxPerPhone int,
+) (err error) {
+	defer func(start time.Time) { s.observe("ReserveSMSSend", start, err) }(time.Now())
+
+	return s.next.ReserveSMSSend(ctx, userID, phone, sentAt, since, maxPerUser, maxPerPhone)
+}
+
+func (s *Storage) SetSMSCode(ctx context.Context, id string, codeHash []byte, codeExpiresAt, sentAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("SetSMSCode", start, err) }(time.Now())
+
+	return s.next.SetSMSCode(ctx, id, codeHash, codeExpiresAt, sentAt)
+}
+
+func (s *Storage) ConsumeSMSAttempt(ctx context.Context, id string, maxAttempts int) (err error) {
+	defer func(start time.Time) { s.observe("ConsumeSMSAttempt", start, err) }(time.Now())
+
+	return s.next.ConsumeSMSAttempt(ctx, id, maxAttempts)
+}
+
+func (s *Storage) DeleteSMSChallenge(ctx context.Context, id string) (err error) {
+	defer func(start time.Time) { s.observe("DeleteSMSChallenge", start, err) }(time.Now())
+
+	return s.next.DeleteSMSChallenge(ctx, id)
+}
+
+func (s *Storage) SetUserPhone(ctx context.Context, userID int64, phone string) (err error) {
+	defer func(start time.Time) { s.observe("SetUserPhone", start, err) }(time.Now())
+
+	return s.next.SetUserPhone(ctx, userID, phone)
+}
+
+func (s *Storage) DeleteExpiredSMSChallenges(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredSMSChallenges", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredSMSChallenges(ctx, before, limit)
+}
+
+func (s *Storage) DeleteSMSSendsBefore(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteSMSSendsBefore", start, err) }(time.Now())
+
+	return s.next.DeleteSMSSendsBefore(ctx, before, limit)
+}
+
+func (s *Storage) AppRedirectURIs(ctx context.Context, appID int) (uris []string, err error) {
+	defer func(start time.Time) { s.observe("AppRedirectURIs", start, err) }(time.Now())
+
+	return s.next.AppRedirectURIs(ctx, appID)
+}
+
+func (s *Storage) SetAppRedirectURIs(ctx context.Context, appID int, uris []string) (err error) {
+	defer func(start time.Time) { s.observe("SetAppRedirectURIs", start, err) }(time.Now())
+
+	return s.next.SetAppRedirectURIs(ctx, appID, uris)
+}
+
+func (s *Storage) AppOrigins(ctx context.Context, appID int) (origins []string, err error) {
+	defer func(start time.Time) { s.observe("AppOrigins", start, err) }(time.Now())
+
+	return s.next.AppOrigins(ctx, appID)
+}
+
+func (s *Storage) SetAppOrigins(ctx context.Context, appID int, origins []string) (err error) {
+	defer func(start time.Time) { s.observe("SetAppOrigins", start, err) }(time.Now())
+
+	return s.next.SetAppOrigins(ctx, appID, origins)
+}
+
+func (s *Storage) AddUserApp(ctx context.Context, userID int64, appID int, at time.Time) (err error) {
+	defer func(start time.Time) { s.observe("AddUserApp", start, err) }(time.Now())
+
+	return s.next.AddUserApp(ctx, userID, appID, at)
+}
+
+func (s *Storage) UserApps(ctx context.Context, userID int64) (apps []int, err error) {
+	defer func(start time.Time) { s.observe("UserApps", start, err) }(time.Now())
+
+	return s.next.UserApps(ctx, userID)
+}
+
+func (s *Storage) IsUserInApp(ctx context.Context, userID int64, appID int) (ok bool, err error) {
+	defer func(start time.Time) { s.observe("IsUserInApp", start, err) }(time.Now())
+
+	return s.next.IsUserInApp(ctx, userID, appID)
+}
+
+func (s *Storage) IsAppAdmin(ctx context.Context, userID int64, appID int) (ok bool, err error) {
+	defer func(start time.Time) { s.observe("IsAppAdmin", start, err) }(time.Now())
+
+	return s.next.IsAppAdmin(ctx, userID, appID)
+}
+
+func (s *Storage) SetAppAdmin(ctx context.Context, userID int64, appID int, admin bool) (err error) {
+	defer func(start time.Time) { s.observe("SetAppAdmin", start, err) }(time.Now())
+
+	return s.next.SetAppAdmin(ctx, userID, appID, admin)
+}
+
+func (s *Storage) AppsWithPublicKeys(ctx context.Context) (apps []models.App, err error) {
+	defer func(start time.Time) { s.observe("AppsWithPublicKeys", start, err) }(time.Now())
+
+	return s.next.AppsWithPublicKeys(ctx)
+}
+
+func (s *Storage) SaveAuthCode(ctx context.Context, c models.AuthCode) (err error) {
+	defer func(start time.Time) { s.observe("SaveAuthCode", start, err) }(time.Now())
+
+	return s.next.SaveAuthCode(ctx, c)
+}
+
+func (s *Storage) TakeAuthCode(ctx context.Context, id string) (c models.AuthCode, err error) {
+	defer func(start time.Time) { s.observe("TakeAuthCode", start, err) }(time.Now())
+
+	return s.next.TakeAuthCode(ctx, id)
+}
+
+func (s *Storage) DeleteExpiredAuthCodes(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredAuthCodes", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredAuthCodes(ctx, before, limit)
+}
+
+func (s *Storage) AcceptTOS(ctx context.Context, userID int64, version string) (err error) {
+	defer func(start time.Time) { s.observe("AcceptTOS", start, err) }(time.Now())
+
+	return s.next.AcceptTOS(ctx, userID, version)
+}
+
+func (s *Storage) LatestTOSAcceptance(ctx context.Context, userID int64) (a models.TOSAcceptance, err error) {
+	defer func(start time.Time) { s.observe("LatestTOSAcceptance", start, err) }(time.Now())
+
+	return s.next.LatestTOSAcceptance(ctx, userID)
+}
+
+func (s *Storage) UsersPendingTOS(
+	ctx context.Context,
+	version string,
+	afterID int64,
+	limit int,
+) (pending []models.PendingTOS, err error) {
+	defer func(start time.Time) { s.observe("UsersPendingTOS", start, err) }(time.Now())
+
+	return s.next.UsersPendingTOS(ctx, version, afterID, limit)
+}
+
+func (s *Storage) SaveAccountDeletion(ctx context.Context, d models.AccountDeletion) (err error) {
+	defer func(start time.Time) { s.observe("SaveAccountDeletion", start, err) }(time.Now())
+
+	return s.next.SaveAccountDeletion(ctx, d)
+}
+
+func (s *Storage) AccountDeletion(ctx context.Context, userID int64) (d models.AccountDeletion, err error) {
+	defer func(start time.Time) { s.observe("AccountDeletion", start, err) }(time.Now())
+
+	return s.next.AccountDeletion(ctx, userID)
+}
+
+func (s *Storage) AccountDeletionByTokenHash(ctx context.Context, tokenHash string) (d models.AccountDeletion, err error) {
+	defer func(start time.Time) { s.observe("AccountDeletionByTokenHash", start, err) }(time.Now())
+
+	return s.next.AccountDeletionByTokenHash(ctx, tokenHash)
+}
+
+func (s *Storage) DeleteAccountDeletion(ctx context.Context, userID int64) (err error) {
+	defer func(start time.Time) { s.observe("DeleteAccountDeletion", start, err) }(time.Now())
+
+	return s.next.DeleteAccountDeletion(ctx, userID)
+}
+
+func (s *Storage) DueAccountDeletions(ctx context.Context, before time.Time, limit int) (ids []int64, err error) {
+	defer func(start time.Time) { s.observe("DueAccountDeletions", start, err) }(time.Now())
+
+	return s.next.DueAccountDeletions(ctx, before, limit)
+}
+
+func (s *Storage) SaveInvitation(
+	ctx context.Context,
+	inv models.Invitation,
+) (saved models.Invitation, renewed bool, err error) {
+	defer func(start time.Time) { s.observe("SaveInvitation", start, err) }(time.Now())
+
+	return s.next.SaveInvitation(ctx, inv)
+}
+
+func (s *Storage) InvitationByTokenHash(ctx context.Context, tokenHash string) (inv models.Invitation, err error) {
+	defer func(start time.Time) { s.observe("InvitationByTokenHash", start, err) }(time.Now())
+
+	return s.next.InvitationByTokenHash(ctx, tokenHash)
+}
+
+func (s *Storage) AcceptInvitation(ctx context.Context, invitationID int64, passHash []byte) (userID int64, err error) {
+	defer func(start time.Time) { s.observe("AcceptInvitation", start, err) }(time.Now())
+
+	return s.next.AcceptInvitation(ctx, invitationID, passHash)
+}
+
+func (s *Storage) ListInvitations(
+	ctx context.Context,
+	afterID int64,
+	limit int,
+	pendingOnly bool,
+) (invitations []models.Invitation, err error) {
+	defer func(start time.Time) { s.observe("ListInvitations", start, err) }(time.Now())
+
+	return s.next.ListInvitations(ctx, afterID, limit, pendingOnly)
+}
+
+func (s *Storage) RevokeInvitation(ctx context.Context, invitationID int64) (email string, err error) {
+	defer func(start time.Time) { s.observe("RevokeInvitation", start, err) }(time.Now())
+
+	return s.next.RevokeInvitation(ctx, invitationID)
+}
+
+func (s *Storage) Backup(ctx context.Context, path string, progress func(copied, total int)) (err error) {
+	defer func(start time.Time) { s.observe("Backup", start, err) }(time.Now())
+
+	return s.next.Backup(ctx, path, progress)
+}
+
+func (s *Storage) SchemaVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	defer func(start time.Time) { s.observe("SchemaVersion", start, err) }(time.Now())
+
+	return s.next.SchemaVersion(ctx)
+}
+
+func (s *Storage) AddOutboxEvent(ctx context.Context, eventID, eventType string, payload []byte) (err error) {
+	defer func(start time.Time) { s.observe("AddOutboxEvent", start, err) }(time.Now())
+
+	return s.next.AddOutboxEvent(ctx, eventID, eventType, payload)
+}
+
+func (s *Storage) PendingOutboxEvents(ctx context.Context, limit int) (evs []models.OutboxEvent, err error) {
+	defer func(start time.Time) { s.observe("PendingOutboxEvents", start, err) }(time.Now())
+
+	return s.next.PendingOutboxEvents(ctx, limit)
+}
+
+func (s *Storage) MarkOutboxSent(ctx context.Context, seqs []int64) (err error) {
+	defer func(start time.Time) { s.observe("MarkOutboxSent", start, err) }(time.Now())
+
+	return s.next.MarkOutboxSent(ctx, seqs)
+}
+
+func (s *Storage) OutboxLag(ctx context.Context) (pending int64, oldest time.Time, err error) {
+	defer func(start time.Time) { s.observe("OutboxLag", start, err) }(time.Now())
+
+	return s.next.OutboxLag(ctx)
+}
+
+func (s *Storage) CreateSession(
+	ctx context.Context,
+	session models.Session,
+	limit int,
+	evictOldest bool,
+) (evicted []string, err error) {
+	defer func(start time.Time) { s.observe("CreateSession", start, err) }(time.Now())
+
+	return s.next.CreateSession(ctx, session, limit, evictOldest)
+}
+
+func (s *Storage) Session(ctx context.Context, id string) (session models.Session, err error) {
+	defer func(start time.Time) { s.observe("Session", start, err) }(time.Now())
+
+	return s.next.Session(ctx, id)
+}
+
+func (s *Storage) TouchSession(ctx context.Context, id string, at time.Time, throttle time.Duration) (err error) {
+	defer func(start time.Time) { s.observe("TouchSession", start, err) }(time.Now())
+
+	return s.next.TouchSession(ctx, id, at, throttle)
+}
+
+func (s *Storage) RevokeSession(ctx context.Context, id string, at time.Time) (err error) {
+	defer func(start time.Time) { s.observe("RevokeSession", start, err) }(time.Now())
+
+	return s.next.RevokeSession(ctx, id, at)
+}
+
+func (s *Storage) DeleteExpiredSessions(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredSessions", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredSessions(ctx, before, limit)
+}
+
+func (s *Storage) SaveOfflineToken(ctx context.Context, t models.OfflineToken) (err error) {
+	defer func(start time.Time) { s.observe("SaveOfflineToken", start, err) }(time.Now())
+
+	return s.next.SaveOfflineToken(ctx, t)
+}
+
+func (s *Storage) OfflineToken(ctx context.Context, id string) (t models.OfflineToken, err error) {
+	defer func(start time.Time) { s.observe("OfflineToken", start, err) }(time.Now())
+
+	return s.next.OfflineToken(ctx, id)
+}
+
+func (s *Storage) OfflineTokens(ctx context.Context, userID int64, now time.Time) (res []models.OfflineToken, err error) {
+	defer func(start time.Time) { s.observe("OfflineTokens", start, err) }(time.Now())
+
+	return s.next.OfflineTokens(ctx, userID, now)
+}
+
+func (s *Storage) RevokeOfflineToken(ctx context.Context, userID int64, id string, at time.Time) (err error) {
+	defer func(start time.Time) { s.observe("RevokeOfflineToken", start, err) }(time.Now())
+
+	return s.next.RevokeOfflineToken(ctx, userID, id, at)
+}
+
+func (s *Storage) DeleteExpiredOfflineTokens(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredOfflineTokens", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredOfflineTokens(ctx, before, limit)
+}
+
+func (s *Storage) SaveRefreshFamily(ctx context.Context, f models.RefreshFamily) (err error) {
+	defer func(start time.Time) { s.observe("SaveRefreshFamily", start, err) }(time.Now())
+
+	return s.next.SaveRefreshFamily(ctx, f)
+}
+
+func (s *Storage) RefreshFamily(ctx context.Context, id string) (f models.RefreshFamily, err error) {
+	defer func(start time.Time) { s.observe("RefreshFamily", start, err) }(time.Now())
+
+	return s.next.RefreshFamily(ctx, id)
+}
+
+func (s *Storage) RotateRefreshToken(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) (err error) {
+	defer func(start time.Time) { s.observe("RotateRefreshToken", start, err) }(time.Now())
+
+	return s.next.RotateRefreshToken(ctx, id, oldHash, newHash, expiresAt)
+}
+
+func (s *Storage) RevokeRefreshFamily(ctx context.Context, id string, at time.Time) (err error) {
+	defer func(start time.Time) { s.observe("RevokeRefreshFamily", start, err) }(time.Now())
+
+	return s.next.RevokeRefreshFamily(ctx, id, at)
+}
+
+func (s *Storage) DeleteExpiredRefreshFamilies(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredRefreshFamilies", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredRefreshFamilies(ctx, before, limit)
+}
+
+func (s *Storage) ProvisionedUser(ctx context.Context, externalID string) (u models.ProvisionedUser, err error) {
+	defer func(start time.Time) { s.observe("ProvisionedUser", start, err) }(time.Now())
+
+	return s.next.ProvisionedUser(ctx, externalID)
+}
+
+func (s *Storage) SaveProvisionedUser(ctx context.Context, u models.ProvisionedUser) (id int64, err error) {
+	defer func(start time.Time) { s.observe("SaveProvisionedUser", start, err) }(time.Now())
+
+	return s.next.SaveProvisionedUser(ctx, u)
+}
+
+func (s *Storage) UpdateProvisionedUser(ctx context.Context, u models.ProvisionedUser) (err error) {
+	defer func(start time.Time) { s.observe("UpdateProvisionedUser", start, err) }(time.Now())
+
+	return s.next.UpdateProvisionedUser(ctx, u)
+}
+
+func (s *Storage) DeprovisionUser(ctx context.Context, externalID string, at time.Time) (id int64, err error) {
+	defer func(start time.Time) { s.observe("DeprovisionUser", start, err) }(time.Now())
+
+	return s.next.DeprovisionUser(ctx, externalID, at)
+}
+
+func (s *Storage) ProvisionedUsers(
+	ctx context.Context,
+	since time.Time,
+	afterID int64,
+	limit int,
+) (res []models.ProvisionedUser, err error) {
+	defer func(start time.Time) { s.observe("ProvisionedUsers", start, err) }(time.Now())
+
+	return s.next.ProvisionedUsers(ctx, since, afterID, limit)
+}
+
+func (s *Storage) SaveSelfTestApp(ctx context.Context, name, secret string, at time.Time) (id int, err error) {
+	defer func(start time.Time) { s.observe("SaveSelfTestApp", start, err) }(time.Now())
+
+	return s.next.SaveSelfTestApp(ctx, name, secret, at)
+}
+
+func (s *Storage) MarkSelfTestUser(ctx context.Context, userID int64, at time.Time) (err error) {
+	defer func(start time.Time) { s.observe("MarkSelfTestUser", start, err) }(time.Now())
+
+	return s.next.MarkSelfTestUser(ctx, userID, at)
+}
+
+func (s *Storage) SetEmailVerified(ctx context.Context, userID int64) (err error) {
+	defer func(start time.Time) { s.observe("SetEmailVerified", start, err) }(time.Now())
+
+	return s.next.SetEmailVerified(ctx, userID)
+}
+
+func (s *Storage) DeleteSelfTestData(ctx context.Context, userID int64, appID int) (err error) {
+	defer func(start time.Time) { s.observe("DeleteSelfTestData", start, err) }(time.Now())
+
+	return s.next.DeleteSelfTestData(ctx, userID, appID)
+}
+
+func (s *Storage) DeleteStaleSelfTestData(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteStaleSelfTestData", start, err) }(time.Now())
+
+	return s.next.DeleteStaleSelfTestData(ctx, before, limit)
+}
+
+func (s *Storage) AddRevocation(ctx context.Context, r models.Revocation) (seq int64, err error) {
+	defer func(start time.Time) { s.observe("AddRevocation", start, err) }(time.Now())
+
+	return s.next.AddRevocation(ctx, r)
+}
+
+func (s *Storage) RevocationsSince(ctx context.Context, afterSeq int64, limit int) (res []models.Revocation, err error) {
+	defer func(start time.Time) { s.observe("RevocationsSince", start, err) }(time.Now())
+
+	return s.next.RevocationsSince(ctx, afterSeq, limit)
+}
+
+func (s *Storage) LastRevocationSeq(ctx context.Context) (seq int64, err error) {
+	defer func(start time.Time) { s.observe("LastRevocationSeq", start, err) }(time.Now())
+
+	return s.next.LastRevocationSeq(ctx)
+}
+
+func (s *Storage) DeleteRevocationsBefore(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteRevocationsBefore", start, err) }(time.Now())
+
+	return s.next.DeleteRevocationsBefore(ctx, before, limit)
+}
+
+func (s *Storage) DenyToken(ctx context.Context, t models.DeniedToken) (err error) {
+	defer func(start time.Time) { s.observe("DenyToken", start, err) }(time.Now())
+
+	return s.next.DenyToken(ctx, t)
+}
+
+func (s *Storage) TokenDenied(ctx context.Context, jti string) (denied bool, err error) {
+	defer func(start time.Time) { s.observe("TokenDenied", start, err) }(time.Now())
+
+	return s.next.TokenDenied(ctx, jti)
+}
+
+func (s *Storage) DeleteExpiredDeniedTokens(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteExpiredDeniedTokens", start, err) }(time.Now())
+
+	return s.next.DeleteExpiredDeniedTokens(ctx, before, limit)
+}
+
+func (s *Storage) AddLogin(ctx context.Context, r models.LoginRecord) (err error) {
+	defer func(start time.Time) { s.observe("AddLogin", start, err) }(time.Now())
+
+	return s.next.AddLogin(ctx, r)
+}
+
+func (s *Storage) RecentLogins(ctx context.Context, userID int64, limit int) (res []models.LoginRecord, err error) {
+	defer func(start time.Time) { s.observe("RecentLogins", start, err) }(time.Now())
+
+	return s.next.RecentLogins(ctx, userID, limit)
+}
+
+func (s *Storage) DeleteLoginsBefore(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteLoginsBefore", start, err) }(time.Now())
+
+	return s.next.DeleteLoginsBefore(ctx, before, limit)
+}
+
+func (s *Storage) AddLoginFailure(ctx context.Context, appID int, at time.Time) (err error) {
+	defer func(start time.Time) { s.observe("AddLoginFailure", start, err) }(time.Now())
+
+	return s.next.AddLoginFailure(ctx, appID, at)
+}
+
+func (s *Storage) DeleteLoginFailuresBefore(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteLoginFailuresBefore", start, err) }(time.Now())
+
+	return s.next.DeleteLoginFailuresBefore(ctx, before, limit)
+}
+
+func (s *Storage) Stats(ctx context.Context, appID int, appUsers bool, now time.Time) (st models.Stats, err error) {
+	defer func(start time.Time) { s.observe("Stats", start, err) }(time.Now())
+
+	return s.next.Stats(ctx, appID, appUsers, now)
+}
+
+func (s *Storage) Backlog(ctx context.Context, now time.Time) (b models.Backlog, err error) {
+	defer func(start time.Time) { s.observe("Backlog", start, err) }(time.Now())
+
+	return s.next.Backlog(ctx, now)
+}
+
+func (s *Storage) AddAuditEvent(ctx context.Context, r models.AuditRecord) (err error) {
+	defer func(start time.Time) { s.observe("AddAuditEvent", start, err) }(time.Now())
+
+	return s.next.AddAuditEvent(ctx, r)
+}
+
+func (s *Storage) AuditEvents(
+	ctx context.Context,
+	afterTime time.Time,
+	afterID int64,
+	to time.Time,
+	limit int,
+) (res []models.AuditRecord, err error) {
+	defer func(start time.Time) { s.observe("AuditEvents", start, err) }(time.Now())
+
+	return s.next.AuditEvents(ctx, afterTime, afterID, to, limit)
+}
+
+func (s *Storage) DeleteAuditEventsBefore(ctx context.Context, before time.Time, limit int) (n int, err error) {
+	defer func(start time.Time) { s.observe("DeleteAuditEventsBefore", start, err) }(time.Now())
+
+	return s.next.DeleteAuditEventsBefore(ctx, before, limit)
+}
+
+func (s *Storage) AuditChainRange(ctx context.Context, from, to time.Time) (first, last int64, err error) {
+	defer func(start time.Time) { s.observe("AuditChainRange", start, err) }(time.Now())
+
+	return s.next.AuditChainRange(ctx, from, to)
+}
+
+func (s *Storage) AuditChainRows(
+	ctx context.Context,
+	afterID int64,
+	lastID int64,
+	limit int,
+) (res []models.AuditRecord, err error) {
+	defer func(start time.Time) { s.observe("AuditChainRows", start, err) }(time.Now())
+
+	return s.next.AuditChainRows(ctx, afterID, lastID, limit)
+}
+
+func (s *Storage) AuditChainHead(ctx context.Context) (head models.AuditChainHead, err error) {
+	defer func(start time.Time) { s.observe("AuditChainHead", start, err) }(time.Now())
+
+	return s.next.AuditChainHead(ctx)
+}
@@ -0,0 +1,132 @@
+package instrumented
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"fmt"
+	"path/filepath"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"sso/internal/storage/sqlite"
+	"sso/internal/storage/storagetest"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// errBusy - ошибка занятости фейкового бэкенда
+var errBusy = errors.New("database is locked")
+
+// fakeBackend - UserByID отвечает по id: 1 - пользователь, 2 - не найден, 3 - занято, остальные - прочая ошибка
+type fakeBackend struct {
+	Backend
+}
+
+func (fakeBackend) UserByID(_ context.Context, userID int64) (models.User, error) {
+	switch userID {
+	case 1:
+		return models.User{ID: 1}, nil
+	case 2:
+		return models.User{}, fmt.Errorf("storage.fake.UserByID: %w", storage.ErrUserNotFound)
+	case 3:
+		return models.User{}, fmt.Errorf("storage.fake.UserByID: %w", errBusy)
+	default:
+		return models.User{}, errors.New("disk I/O error")
+	}
+}
+
+func (fakeBackend) SaveApp(context.Context, string, string) (int, error) {
+	return 0, fmt.Errorf("storage.fake.SaveApp: %w", storage.ErrAppExists)
+}
+
+// errorCount - текущее значение storage_errors[method][kind]
+func errorCount(method, kind string) int64 {
+	m, ok := queryErrors.Get(method).(*expvar.Map)
+	if !ok {
+		return 0
+	}
+	v, ok := m.Get(kind).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+
+	return v.Value()
+}
+
+func TestStorage_Metrics(t *testing.T) {
+	s := New(fakeBackend{}, func(err error) bool { return errors.Is(err, errBusy) })
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		call    func() error
+		method  string
+		wantErr string // "" - вызов успешный
+	}{
+		{name: "success", call: func() error { _, err := s.UserByID(ctx, 1); return err }, method: "UserByID"},
+		{name: "not found", call: func() error { _, err := s.UserByID(ctx, 2); return err }, method: "UserByID", wantErr: KindNotFound},
+		{name: "busy", call: func() error { _, err := s.UserByID(ctx, 3); return err }, method: "UserByID", wantErr: KindBusy},
+		{name: "other", call: func() error { _, err := s.UserByID(ctx, 4); return err }, method: "UserByID", wantErr: KindOther},
+		{name: "conflict", call: func() error { _, err := s.SaveApp(ctx, "app", "secret"); return err }, method: "SaveApp", wantErr: KindConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := queryDuration.With(tt.method).Count()
+			errs := map[string]int64{}
+			for _, kind := range []string{KindNotFound, KindConflict, KindBusy, KindOther} {
+				errs[kind] = errorCount(tt.method, kind)
+			}
+
+			err := tt.call()
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+			}
+
+			assert.Equal(t, calls+1, queryDuration.With(tt.method).Count(), "duration must be recorded")
+			for kind, before := range errs {
+				want := before
+				if kind == tt.wantErr {
+					want++
+				}
+				assert.Equal(t, want, errorCount(tt.method, kind), "storage_errors[%s][%s]", tt.method, kind)
+			}
+		})
+	}
+}
+
+// Декоратор прозрачен: поверх SQLite выполняется тот же контракт хранилища
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return New(newSQLite(t), sqlite.IsBusy)
+	})
+}
+
+// newSQLite - временная SQLite с применёнными миграциями
+func newSQLite(tb testing.TB) *sqlite.Storage {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "sso.db")
+
+	m, err := migrate.New("file://../../../migrations", "sqlite3://"+path+"?x-migrations-table=migrations")
+	require.NoError(tb, err)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(tb, err)
+	}
+	srcErr, dbErr := m.Close()
+	require.NoError(tb, srcErr)
+	require.NoError(tb, dbErr)
+
+	s, err := sqlite.New(path)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
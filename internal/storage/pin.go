@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type pinKey struct{}
+
+// pin - признак «читать с основной БД», общий для всех контекстов одной области
+type pin struct {
+	on atomic.Bool
+}
+
+// WithPinScope - начинает область (например, сценарий из нескольких вызовов сервиса): PinPrimary, вызванный
+// в любом вложенном вызове, действует до конца области, а не только в контексте, который он вернул.
+// Вложенная область переиспользует внешнюю
+func WithPinScope(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(pinKey{}).(*pin); ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, pinKey{}, &pin{})
+}
+
+// PinPrimary - дальнейшие чтения с этим контекстом (и во всей области WithPinScope, если она есть) идут
+// на основную БД, а не на реплику: после записи реплика могла её ещё не получить
+func PinPrimary(ctx context.Context) context.Context {
+	if p, ok := ctx.Value(pinKey{}).(*pin); ok {
+		p.on.Store(true)
+
+		return ctx
+	}
+
+	p := &pin{}
+	p.on.Store(true)
+
+	return context.WithValue(ctx, pinKey{}, p)
+}
+
+// PinnedToPrimary - должны ли чтения с контекстом идти на основную БД
+func PinnedToPrimary(ctx context.Context) bool {
+	p, ok := ctx.Value(pinKey{}).(*pin)
+
+	return ok && p.on.Load()
+}
@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPinPrimary(t *testing.T) {
+	ctx := context.Background()
+	assert.False(t, PinnedToPrimary(ctx))
+	assert.True(t, PinnedToPrimary(PinPrimary(ctx)))
+	assert.False(t, PinnedToPrimary(ctx), "parent context is not pinned without a scope")
+
+	// В области закрепление из вложенного вызова видно и снаружи
+	scope := WithPinScope(ctx)
+	assert.False(t, PinnedToPrimary(scope))
+	func(ctx context.Context) {
+		_ = PinPrimary(ctx)
+	}(scope)
+	assert.True(t, PinnedToPrimary(scope))
+	assert.True(t, PinnedToPrimary(WithPinScope(scope)), "nested scope reuses the outer one")
+}
@@ -0,0 +1,61 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"time"
+)
+
+// SaveActionToken - запоминает выданный токен одного действия до его использования.
+func (s *Storage) SaveActionToken(ctx context.Context, t models.ActionToken) error {
+	const op = "storage.sqlite.SaveActionToken"
+
+	if _, err := s.db.ExecContext(ctx, `INSERT INTO action_tokens(jti, user_id, action, payload, expires_at)
+		VALUES(?, ?, ?, ?, ?)`, t.JTI, t.UserID, t.Action, t.Payload, t.ExpiresAt.UTC().Format(time.DateTime)); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeActionToken - забирает токен: строка удаляется тем же запросом, поэтому из двух
+// одновременных попыток успешна только одна. Нет токена (использован или просрочен) - storage.ErrActionTokenNotFound
+func (s *Storage) ConsumeActionToken(ctx context.Context, jti string) (models.ActionToken, error) {
+	const op = "storage.sqlite.ConsumeActionToken"
+
+	t := models.ActionToken{JTI: jti}
+	err := s.db.QueryRowContext(ctx, `DELETE FROM action_tokens WHERE jti = ? AND expires_at > ?
+		RETURNING user_id, action, payload, expires_at`, jti, time.Now().UTC().Format(time.DateTime)).
+		Scan(&t.UserID, &t.Action, &t.Payload, &t.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ActionToken{}, fmt.Errorf("%s: %w", op, storage.ErrActionTokenNotFound)
+		}
+
+		return models.ActionToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return t, nil
+}
+
+// DeleteExpiredActionTokens - удаляет до limit токенов, истёкших до before. Возвращает количество удалённых
+func (s *Storage) DeleteExpiredActionTokens(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredActionTokens"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM action_tokens WHERE jti IN
+		(SELECT jti FROM action_tokens WHERE expires_at < ? LIMIT ?)`, before.UTC().Format(time.DateTime), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
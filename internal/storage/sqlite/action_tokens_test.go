@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActionTokens_SingleUse(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	require.NoError(t, s.SaveActionToken(ctx, models.ActionToken{
+		JTI: "a", UserID: ids[0], Action: "export.download", Payload: []byte("42"), ExpiresAt: time.Now().Add(time.Minute),
+	}))
+
+	got, err := s.ConsumeActionToken(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, ids[0], got.UserID)
+	assert.Equal(t, "export.download", got.Action)
+	assert.Equal(t, []byte("42"), got.Payload)
+
+	_, err = s.ConsumeActionToken(ctx, "a")
+	require.ErrorIs(t, err, storage.ErrActionTokenNotFound)
+}
+
+func TestActionTokens_Expired(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	for _, jti := range []string{"old", "new"} {
+		expires := time.Now().Add(time.Minute)
+		if jti == "old" {
+			expires = time.Now().Add(-time.Minute)
+		}
+		require.NoError(t, s.SaveActionToken(ctx, models.ActionToken{
+			JTI: jti, UserID: ids[0], Action: "x", Payload: []byte{}, ExpiresAt: expires,
+		}))
+	}
+
+	_, err := s.ConsumeActionToken(ctx, "old")
+	require.ErrorIs(t, err, storage.ErrActionTokenNotFound)
+
+	n, err := s.DeleteExpiredActionTokens(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.ConsumeActionToken(ctx, "new")
+	require.NoError(t, err)
+}
@@ -0,0 +1,187 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/auditchain"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEvents_Cursor(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	// Два события в одну и ту же секунду: курсор различает их по id
+	for i, at := range []time.Time{base, base, base.Add(time.Second), base.Add(2 * time.Second), base.Add(time.Hour)} {
+		require.NoError(t, s.AddAuditEvent(ctx, models.AuditRecord{
+			OccurredAt: at, Event: "login.failed", Outcome: "failure", UserID: int64(i), IP: "198.51.100.1",
+		}))
+	}
+
+	to := base.Add(time.Minute)
+	var got []models.AuditRecord
+	afterTime, afterID := base, int64(0)
+	for {
+		page, err := s.AuditEvents(ctx, afterTime, afterID, to, 2)
+		require.NoError(t, err)
+		got = append(got, page...)
+		if len(page) < 2 {
+			break
+		}
+		afterTime, afterID = page[len(page)-1].OccurredAt, page[len(page)-1].ID
+	}
+
+	require.Len(t, got, 4)
+	for i, r := range got {
+		assert.EqualValues(t, i, r.UserID)
+	}
+	assert.True(t, got[2].OccurredAt.Equal(base.Add(time.Second)))
+	assert.Equal(t, "198.51.100.1", got[0].IP)
+
+	n, err := s.DeleteAuditEventsBefore(ctx, base.Add(time.Second), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
+
+// addAuditEvents - записывает n событий с интервалом в минуту начиная с base
+func addAuditEvents(tb testing.TB, s *Storage, base time.Time, n int) {
+	tb.Helper()
+
+	for i := range n {
+		require.NoError(tb, s.AddAuditEvent(context.Background(), models.AuditRecord{
+			OccurredAt: base.Add(time.Duration(i) * time.Minute), Event: "login.failed", Outcome: "failure",
+			UserID: int64(i + 1), Email: "user@example.com", IP: "198.51.100.1",
+		}))
+	}
+}
+
+func TestAddAuditEvent_Chain(t *testing.T) {
+	key := []byte(strings.Repeat("k", 32))
+	ctx := context.Background()
+	base := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	from, to := base.Add(-time.Hour), base.Add(time.Hour)
+
+	t.Run("intact", func(t *testing.T) {
+		s := newTestStorage(t, WithAuditChain(key))
+		addAuditEvents(t, s, base, 5)
+
+		rows, err := s.AuditChainRows(ctx, 0, 10, 10)
+		require.NoError(t, err)
+		require.Len(t, rows, 5)
+		assert.Empty(t, rows[0].PrevHash)
+		for i := 1; i < len(rows); i++ {
+			assert.Equal(t, rows[i-1].Hash, rows[i].PrevHash)
+		}
+
+		head, err := s.AuditChainHead(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, models.AuditChainHead{EventID: 5, Hash: rows[4].Hash, MAC: auditchain.MAC(key, 5, rows[4].Hash)}, head)
+
+		report, err := auditchain.New(s, key).VerifyAuditChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, models.AuditChainReport{RowsChecked: 5, HeadVerified: true}, report)
+
+		// Диапазон внутри журнала: последнее звено не проверяется
+		report, err = auditchain.New(s, key).VerifyAuditChain(ctx, base, base.Add(2*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, models.AuditChainReport{RowsChecked: 2}, report)
+	})
+
+	tests := []struct {
+		name   string
+		tamper string
+		key    []byte
+		want   models.AuditChainReport
+	}{
+		{
+			name:   "modified",
+			tamper: `UPDATE audit_events SET outcome = 'success' WHERE id = 3`,
+			want:   models.AuditChainReport{RowsChecked: 3, FirstBrokenID: 3, Reason: auditchain.ReasonHash},
+		},
+		{
+			name:   "deleted from the middle",
+			tamper: `DELETE FROM audit_events WHERE id = 3`,
+			want:   models.AuditChainReport{RowsChecked: 3, FirstBrokenID: 4, Reason: auditchain.ReasonPrevHash},
+		},
+		{
+			name:   "truncated",
+			tamper: `DELETE FROM audit_events WHERE id >= 4`,
+			want:   models.AuditChainReport{RowsChecked: 3, FirstBrokenID: 5, Reason: auditchain.ReasonTruncated},
+		},
+		{
+			name: "head moved back",
+			tamper: `DELETE FROM audit_events WHERE id = 5;
+				UPDATE audit_chain_head SET event_id = 4, hash = (SELECT hash FROM audit_events WHERE id = 4)`,
+			key:  key,
+			want: models.AuditChainReport{RowsChecked: 4, FirstBrokenID: 4, Reason: auditchain.ReasonHeadMAC},
+		},
+		{
+			name:   "head moved back without key",
+			tamper: `DELETE FROM audit_events WHERE id = 5; UPDATE audit_chain_head SET event_id = 4, hash = 'x'`,
+			want:   models.AuditChainReport{RowsChecked: 4, FirstBrokenID: 4, Reason: auditchain.ReasonHead},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStorage(t, WithAuditChain(key))
+			addAuditEvents(t, s, base, 5)
+
+			_, err := s.db.ExecContext(ctx, tt.tamper)
+			require.NoError(t, err)
+
+			report, err := auditchain.New(s, tt.key).VerifyAuditChain(ctx, from, to)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, report)
+			assert.False(t, report.OK())
+		})
+	}
+
+	t.Run("retention keeps the rest verifiable", func(t *testing.T) {
+		s := newTestStorage(t, WithAuditChain(key))
+		addAuditEvents(t, s, base, 5)
+
+		n, err := s.DeleteAuditEventsBefore(ctx, base.Add(2*time.Minute), 10)
+		require.NoError(t, err)
+		require.Equal(t, 2, n)
+
+		report, err := auditchain.New(s, key).VerifyAuditChain(ctx, from, to)
+		require.NoError(t, err)
+		assert.Equal(t, models.AuditChainReport{RowsChecked: 3, HeadVerified: true}, report)
+	})
+}
+
+// BenchmarkAddAuditEvent - запись события журнала безопасности без цепочки и с цепочкой хешей (с подписью
+// последнего звена). Время уходит в основном на фиксацию транзакции; на 1 vCPU Xeon и обычном диске:
+// off ~470 мкс/событие, on ~600 мкс/событие
+func BenchmarkAddAuditEvent(b *testing.B) {
+	r := models.AuditRecord{
+		OccurredAt: time.Now(), Event: "login.failed", Outcome: "failure", UserID: 42, AppID: 1,
+		Email: "user@example.com", Reason: "invalid_credentials", RequestID: "req-1", IP: "198.51.100.1",
+	}
+
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{name: "chain=off"},
+		{name: "chain=on", opts: []Option{WithAuditChain([]byte(strings.Repeat("k", 32)))}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			s := newTestStorage(b, bc.opts...)
+			ctx := context.Background()
+
+			b.ResetTimer()
+			for range b.N {
+				if err := s.AddAuditEvent(ctx, r); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
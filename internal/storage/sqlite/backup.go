@@ -0,0 +1,176 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"sso/internal/storage"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Параметры онлайн-копирования
+const (
+	backupStepPages  = 1024                  // страниц за один шаг; между шагами БД доступна для записи
+	backupRetryDelay = 10 * time.Millisecond // пауза, если источник на шаге заблокирован
+)
+
+// Backup - согласованная копия БД в файл path без остановки сервера (sqlite3 backup API).
+// Копия пишется во временный файл рядом с path, проверяется PRAGMA integrity_check
+// и только после этого переименовывается в path. Если path уже существует - os.ErrExist.
+// progress (может быть nil) вызывается после каждого шага с числом скопированных и всего страниц
+func (s *Storage) Backup(ctx context.Context, path string, progress func(copied, total int)) error {
+	const op = "storage.sqlite.Backup"
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s: %w", op, os.ErrExist)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	partial := path + ".partial"
+	if err := s.copyTo(ctx, partial, progress); err != nil {
+		_ = os.Remove(partial)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := CheckIntegrity(ctx, partial); err != nil {
+		_ = os.Remove(partial)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := os.Rename(partial, path); err != nil {
+		_ = os.Remove(partial)
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// copyTo - постраничное копирование основной БД в файл dst
+func (s *Storage) copyTo(ctx context.Context, dst string, progress func(copied, total int)) error {
+	// O_EXCL: не перезаписываем чужой файл (например, копию, которую сейчас делает другой запрос)
+	f, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	dstDB, err := sql.Open("sqlite3", dst)
+	if err != nil {
+		return err
+	}
+	defer dstDB.Close()
+
+	dstConn, err := dstDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer dstConn.Close()
+
+	srcConn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dstRaw any) error {
+		return srcConn.Raw(func(srcRaw any) error {
+			b, err := dstRaw.(*sqlite3.SQLiteConn).Backup("main", srcRaw.(*sqlite3.SQLiteConn), "main")
+			if err != nil {
+				return err
+			}
+			defer b.Close()
+
+			for {
+				done, err := b.Step(backupStepPages)
+				if err != nil && !IsBusy(err) {
+					return err
+				}
+
+				if progress != nil {
+					total := b.PageCount()
+					progress(total-b.Remaining(), total)
+				}
+
+				if done {
+					return b.Finish()
+				}
+
+				// Между шагами источник не заблокирован: сервер продолжает обслуживать запросы.
+				// Если его изменят, SQLite сама продолжит копирование с учётом изменений
+				if err != nil {
+					select {
+					case <-ctx.Done():
+						return ctx.Err()
+					case <-time.After(backupRetryDelay):
+					}
+				} else if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+		})
+	})
+}
+
+// CheckIntegrity - открывает файл БД только для чтения и проверяет его PRAGMA integrity_check.
+// Нарушения возвращаются как storage.ErrCorrupt вместе с отчётом SQLite
+func CheckIntegrity(ctx context.Context, path string) error {
+	const op = "storage.sqlite.CheckIntegrity"
+
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+path+"?mode=ro")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, corruptErr(err))
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: %w", op, corruptErr(err))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s: %w: %s", op, storage.ErrCorrupt, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// corruptErr - ошибки SQLite о повреждённом файле или файле, который вообще не похож на БД
+// (их SQLite возвращает ещё до проверки), превращает в storage.ErrCorrupt
+func corruptErr(err error) error {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrNotADB || sqliteErr.Code == sqlite3.ErrCorrupt) {
+		return fmt.Errorf("%w: %v", storage.ErrCorrupt, err)
+	}
+
+	return err
+}
@@ -0,0 +1,100 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sso/internal/storage"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Копия снимается, пока в БД продолжают писать, и содержит согласованные данные
+func TestBackup_WhileWriting(t *testing.T) {
+	s := newTestStorage(t)
+	seedUsers(t, s, 2000)
+	ctx := context.Background()
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_, err := s.SaveUser(ctx, fmt.Sprintf("writer%d@example.com", i), []byte("hash"))
+			if err != nil && !IsBusy(err) {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+
+	var calls, lastCopied, lastTotal int
+	err := s.Backup(ctx, path, func(copied, total int) {
+		calls++
+		lastCopied, lastTotal = copied, total
+	})
+	close(stop)
+	wg.Wait()
+	require.NoError(t, err)
+
+	assert.Positive(t, calls)
+	assert.Equal(t, lastTotal, lastCopied, "last progress report must be complete")
+	assert.NoFileExists(t, path+".partial")
+
+	require.NoError(t, CheckIntegrity(ctx, path))
+
+	backup, err := New(path)
+	require.NoError(t, err)
+	defer backup.Close()
+
+	_, err = backup.User(ctx, "user1999@example.com")
+	require.NoError(t, err)
+}
+
+func TestBackup_RefusesExistingFile(t *testing.T) {
+	s := newTestStorage(t)
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	require.NoError(t, os.WriteFile(path, []byte("keep me"), 0o600))
+
+	require.ErrorIs(t, s.Backup(context.Background(), path, nil), os.ErrExist)
+
+	got, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "keep me", string(got))
+}
+
+func TestCheckIntegrity_Corrupt(t *testing.T) {
+	s := newTestStorage(t)
+	seedUsers(t, s, 500)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	require.NoError(t, s.Backup(ctx, path, nil))
+
+	// Портим страницы в середине файла, оставляя заголовок целым
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	for i := len(data) / 2; i < len(data)/2+8192 && i < len(data); i++ {
+		data[i] = 0xAA
+	}
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	require.ErrorIs(t, CheckIntegrity(ctx, path), storage.ErrCorrupt)
+
+	notDB := filepath.Join(t.TempDir(), "not.db")
+	require.NoError(t, os.WriteFile(notDB, []byte("definitely not a database file, just some text"), 0o600))
+	require.ErrorIs(t, CheckIntegrity(ctx, notDB), storage.ErrCorrupt)
+}
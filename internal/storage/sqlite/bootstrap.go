@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sso/migrations"
+	"strings"
+	"syscall"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// ErrSchemaMissing - файла БД нет или в нём не применена ни одна миграция
+var ErrSchemaMissing = errors.New("database schema is missing")
+
+// ErrStorageDirMissing - нет каталога, в котором должен лежать файл БД
+var ErrStorageDirMissing = errors.New("storage directory does not exist")
+
+// ErrStorageReadOnly - в каталог или файл БД нельзя писать (например, том смонтирован только для чтения)
+var ErrStorageReadOnly = errors.New("storage is read-only")
+
+// Bootstrap - проверяет при старте, что по storagePath лежит БД со схемой, в которую можно писать:
+// без этого первый же запрос упал бы с непонятной "no such table: users" или ошибкой записи.
+// Если файла (или каталога) нет или миграции ни разу не применялись, с autoApply каталог и схема
+// создаются встроенными миграциями (migrated = true), без него - ErrStorageDirMissing или ErrSchemaMissing
+// с командой мигратора. Каталог или файл только для чтения - ErrStorageReadOnly.
+// БД в памяти не проверяется
+func Bootstrap(ctx context.Context, storagePath string, autoApply bool) (migrated bool, err error) {
+	const op = "storage.sqlite.Bootstrap"
+
+	file, ok := dbFile(storagePath)
+	if !ok {
+		return false, nil
+	}
+
+	dir := filepath.Dir(file)
+	if _, err := os.Stat(dir); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+		if !autoApply {
+			return false, fmt.Errorf("%s: %w: %s (create it or set migrations.auto_apply: true)", op, ErrStorageDirMissing, dir)
+		}
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return false, fmt.Errorf("%s: %w", op, readOnly(err, dir))
+		}
+	}
+
+	// SQLite пишет рядом с файлом журнал (-wal, -journal), поэтому писать нужно и в каталог
+	if err := checkWritable(dir, file); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var version int64
+	if info, err := os.Stat(file); err == nil && info.Size() > 0 {
+		if version, err = appliedVersion(ctx, file); err != nil {
+			return false, fmt.Errorf("%s: %w", op, err)
+		}
+	} else if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+	if version > 0 {
+		return false, nil
+	}
+
+	if !autoApply {
+		return false, fmt.Errorf(
+			"%s: %w in %s: run `migrator --storage-path=%s --migrations-path=./migrations` or set migrations.auto_apply: true",
+			op, ErrSchemaMissing, file, file)
+	}
+	if err := applyMigrations(file); err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return true, nil
+}
+
+// dbFile - путь к файлу БД из storagePath (путь или DSN вида file:path?params); false - БД в памяти
+func dbFile(storagePath string) (string, bool) {
+	path, query, _ := strings.Cut(strings.TrimPrefix(storagePath, "file:"), "?")
+	if path == "" || path == ":memory:" {
+		return "", false
+	}
+	if params, err := url.ParseQuery(query); err == nil && params.Get("mode") == "memory" {
+		return "", false
+	}
+
+	return path, true
+}
+
+// checkWritable - можно ли создать файл в dir и открыть file (если он есть) на запись
+func checkWritable(dir, file string) error {
+	probe, err := os.CreateTemp(dir, ".sso-write-check-*")
+	if err != nil {
+		return readOnly(err, dir)
+	}
+	_ = probe.Close()
+	_ = os.Remove(probe.Name())
+
+	f, err := os.OpenFile(file, os.O_RDWR, 0)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+
+		return readOnly(err, file)
+	}
+
+	return f.Close()
+}
+
+// readOnly - ErrStorageReadOnly, если err - отказ в записи (права или файловая система только для чтения)
+func readOnly(err error, path string) error {
+	if errors.Is(err, fs.ErrPermission) || errors.Is(err, syscall.EROFS) {
+		return fmt.Errorf("%w: %s: %w (check that the volume is mounted read-write)", ErrStorageReadOnly, path, err)
+	}
+
+	return err
+}
+
+// appliedVersion - версия последней применённой миграции (0 - таблицы миграций нет или она пуста)
+func appliedVersion(ctx context.Context, file string) (int64, error) {
+	db, err := sql.Open("sqlite3", file)
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	var tables int
+	err = db.QueryRowContext(ctx,
+		"SELECT count(*) FROM sqlite_master WHERE type = 'table' AND name = ?", migrationsTable).Scan(&tables)
+	if err != nil || tables == 0 {
+		return 0, err
+	}
+
+	var version int64
+	err = db.QueryRowContext(ctx, "SELECT version FROM "+migrationsTable+" LIMIT 1").Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+
+	return version, err
+}
+
+// applyMigrations - применяет встроенные миграции к file, как это делает cmd/migrator
+func applyMigrations(file string) error {
+	src, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src,
+		fmt.Sprintf("sqlite3://%s?x-migrations-table=%s", file, migrationsTable))
+	if err != nil {
+		return err
+	}
+
+	upErr := m.Up()
+	srcErr, dbErr := m.Close()
+	if upErr != nil && !errors.Is(upErr, migrate.ErrNoChange) {
+		return upErr
+	}
+
+	return errors.Join(srcErr, dbErr)
+}
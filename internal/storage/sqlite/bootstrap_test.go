@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sso/migrations"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requireSchema - по path лежит БД со всеми встроенными миграциями
+func requireSchema(t *testing.T, path string) {
+	t.Helper()
+
+	s, err := New(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+
+	version, dirty, err := s.SchemaVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, migrations.Latest(), version)
+	assert.False(t, dirty)
+}
+
+func TestBootstrap_MissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sso.db")
+
+	_, err := Bootstrap(context.Background(), path, false)
+	require.ErrorIs(t, err, ErrSchemaMissing)
+	assert.ErrorContains(t, err, "migrator --storage-path="+path)
+	assert.NoFileExists(t, path, "check must not create an empty database")
+
+	migrated, err := Bootstrap(context.Background(), path, true)
+	require.NoError(t, err)
+	assert.True(t, migrated)
+	requireSchema(t, path)
+
+	// Повторный запуск ничего не меняет
+	migrated, err = Bootstrap(context.Background(), path, true)
+	require.NoError(t, err)
+	assert.False(t, migrated)
+}
+
+func TestBootstrap_EmptyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sso.db")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+	_, err := Bootstrap(context.Background(), path, false)
+	require.ErrorIs(t, err, ErrSchemaMissing)
+
+	migrated, err := Bootstrap(context.Background(), path, true)
+	require.NoError(t, err)
+	assert.True(t, migrated)
+	requireSchema(t, path)
+}
+
+func TestBootstrap_MissingDirectory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "sso.db")
+
+	_, err := Bootstrap(context.Background(), path, false)
+	require.ErrorIs(t, err, ErrStorageDirMissing)
+	assert.NoDirExists(t, filepath.Dir(path))
+
+	migrated, err := Bootstrap(context.Background(), "file:"+path+"?_txlock=immediate", true)
+	require.NoError(t, err)
+	assert.True(t, migrated)
+	requireSchema(t, path)
+}
+
+func TestBootstrap_ReadOnlyDirectory(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root ignores directory permissions")
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0o555))
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o755) })
+
+	_, err := Bootstrap(context.Background(), filepath.Join(dir, "sso.db"), true)
+	require.ErrorIs(t, err, ErrStorageReadOnly)
+}
+
+func TestBootstrap_MigratedAndInMemory(t *testing.T) {
+	migrated, err := Bootstrap(context.Background(), newTestDB(t), false)
+	require.NoError(t, err)
+	assert.False(t, migrated)
+
+	migrated, err = Bootstrap(context.Background(), ":memory:", false)
+	require.NoError(t, err)
+	assert.False(t, migrated)
+}
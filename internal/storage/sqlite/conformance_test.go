@@ -0,0 +1,12 @@
+package sqlite
+
+import (
+	"sso/internal/storage/storagetest"
+	"testing"
+)
+
+func TestConformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newTestStorage(t)
+	})
+}
@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strings"
+)
+
+// consentColumns - столбцы согласия в порядке scanConsent
+const consentColumns = "id, user_id, app_id, scopes, granted_at, revoked_at"
+
+// GrantConsent - сохраняет согласие пользователя для приложения. Если согласие уже действует,
+// меняются только scopes: время выдачи остаётся прежним, и выданные по нему токены не теряют силу.
+func (s *Storage) GrantConsent(ctx context.Context, userID int64, appID int, scopes []string) (models.Consent, error) {
+	const op = "storage.sqlite.GrantConsent"
+
+	consent, err := scanConsent(s.db.QueryRowContext(ctx, `INSERT INTO consents(user_id, app_id, scopes) VALUES(?, ?, ?)
+		ON CONFLICT (user_id, app_id) WHERE revoked_at IS NULL DO UPDATE SET scopes = excluded.scopes
+		RETURNING `+consentColumns, userID, appID, strings.Join(scopes, " ")))
+	if err != nil {
+		return models.Consent{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return consent, nil
+}
+
+// ActiveConsent - действующее согласие пользователя для приложения; нет его - storage.ErrConsentNotFound.
+func (s *Storage) ActiveConsent(ctx context.Context, userID int64, appID int) (models.Consent, error) {
+	const op = "storage.sqlite.ActiveConsent"
+
+	consent, err := scanConsent(s.db.QueryRowContext(ctx, "SELECT "+consentColumns+` FROM consents
+		WHERE user_id = ? AND app_id = ? AND revoked_at IS NULL`, userID, appID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Consent{}, fmt.Errorf("%s: %w", op, storage.ErrConsentNotFound)
+		}
+
+		return models.Consent{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return consent, nil
+}
+
+// ListConsents - согласия пользователя, включая отозванные, по возрастанию id.
+func (s *Storage) ListConsents(ctx context.Context, userID int64) ([]models.Consent, error) {
+	const op = "storage.sqlite.ListConsents"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+consentColumns+" FROM consents WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var consents []models.Consent
+	for rows.Next() {
+		c, err := scanConsent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		consents = append(consents, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return consents, nil
+}
+
+// RevokeConsent - отзывает действующее согласие; нет его - storage.ErrConsentNotFound.
+// Внутри WithinTx выполняется в общей транзакции.
+func (s *Storage) RevokeConsent(ctx context.Context, userID int64, appID int) error {
+	const op = "storage.sqlite.RevokeConsent"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE consents SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = ? AND app_id = ? AND revoked_at IS NULL`, userID, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrConsentNotFound)
+	}
+
+	return nil
+}
+
+func scanConsent(row interface{ Scan(dest ...any) error }) (models.Consent, error) {
+	var (
+		c       models.Consent
+		scopes  string
+		revoked sql.NullTime
+	)
+	if err := row.Scan(&c.ID, &c.UserID, &c.AppID, &scopes, &c.GrantedAt, &revoked); err != nil {
+		return models.Consent{}, err
+	}
+
+	c.Scopes, c.RevokedAt = strings.Fields(scopes), revoked.Time
+
+	return c, nil
+}
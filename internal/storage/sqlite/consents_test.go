@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsents(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "partner", "secret")
+	require.NoError(t, err)
+	require.NoError(t, s.SetAppThirdParty(ctx, appID, true))
+
+	app, err := s.App(ctx, appID)
+	require.NoError(t, err)
+	assert.True(t, app.ThirdParty)
+
+	_, err = s.ActiveConsent(ctx, ids[0], appID)
+	require.ErrorIs(t, err, storage.ErrConsentNotFound)
+
+	first, err := s.GrantConsent(ctx, ids[0], appID, []string{"email"})
+	require.NoError(t, err)
+	assert.True(t, first.Active())
+
+	// Повторное согласие меняет scopes действующего, а не создаёт второе
+	again, err := s.GrantConsent(ctx, ids[0], appID, []string{"email", "profile"})
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, again.ID)
+	assert.Equal(t, first.GrantedAt, again.GrantedAt)
+	assert.Equal(t, []string{"email", "profile"}, again.Scopes)
+
+	active, err := s.ActiveConsent(ctx, ids[0], appID)
+	require.NoError(t, err)
+	assert.Equal(t, again.Scopes, active.Scopes)
+
+	require.NoError(t, s.RevokeConsent(ctx, ids[0], appID))
+	require.ErrorIs(t, s.RevokeConsent(ctx, ids[0], appID), storage.ErrConsentNotFound)
+	_, err = s.ActiveConsent(ctx, ids[0], appID)
+	require.ErrorIs(t, err, storage.ErrConsentNotFound)
+
+	// После отзыва согласие выдаётся заново, отозванное остаётся в истории
+	renewed, err := s.GrantConsent(ctx, ids[0], appID, []string{"email"})
+	require.NoError(t, err)
+	assert.NotEqual(t, first.ID, renewed.ID)
+
+	consents, err := s.ListConsents(ctx, ids[0])
+	require.NoError(t, err)
+	require.Len(t, consents, 2)
+	assert.False(t, consents[0].Active())
+	assert.True(t, consents[1].Active())
+}
@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"time"
+)
+
+// SaveAccountDeletion - запоминает запрос удаления аккаунта. Повторный запрос того же пользователя заменяет
+// прежний: старый токен отмены перестаёт действовать, срок отсчитывается заново
+func (s *Storage) SaveAccountDeletion(ctx context.Context, d models.AccountDeletion) error {
+	const op = "storage.sqlite.SaveAccountDeletion"
+
+	_, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO account_deletions(user_id, token_hash, requested_at, execute_at)
+		VALUES(?, ?, ?, ?)
+		ON CONFLICT(user_id) DO UPDATE SET token_hash = excluded.token_hash, requested_at = excluded.requested_at,
+			execute_at = excluded.execute_at`,
+		d.UserID, d.TokenHash, d.RequestedAt.UTC(), d.ExecuteAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AccountDeletion - ожидающее удаление аккаунта пользователя; нет такого - storage.ErrAccountDeletionNotFound.
+func (s *Storage) AccountDeletion(ctx context.Context, userID int64) (models.AccountDeletion, error) {
+	const op = "storage.sqlite.AccountDeletion"
+
+	d, err := scanAccountDeletion(s.conn(ctx).QueryRowContext(ctx,
+		"SELECT user_id, token_hash, requested_at, execute_at FROM account_deletions WHERE user_id = ?", userID))
+	if err != nil {
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
+
+// AccountDeletionByTokenHash - ожидающее удаление по SHA-256 токена отмены; нет такого - storage.ErrAccountDeletionNotFound.
+func (s *Storage) AccountDeletionByTokenHash(ctx context.Context, tokenHash string) (models.AccountDeletion, error) {
+	const op = "storage.sqlite.AccountDeletionByTokenHash"
+
+	d, err := scanAccountDeletion(s.conn(ctx).QueryRowContext(ctx,
+		"SELECT user_id, token_hash, requested_at, execute_at FROM account_deletions WHERE token_hash = ?", tokenHash))
+	if err != nil {
+		return models.AccountDeletion{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
+
+// DeleteAccountDeletion - отменяет ожидающее удаление; нет такого - storage.ErrAccountDeletionNotFound.
+func (s *Storage) DeleteAccountDeletion(ctx context.Context, userID int64) error {
+	const op = "storage.sqlite.DeleteAccountDeletion"
+
+	res, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM account_deletions WHERE user_id = ?", userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAccountDeletionNotFound)
+	}
+
+	return nil
+}
+
+// DueAccountDeletions - до limit пользователей, срок удаления которых наступил до before, от самых ранних.
+func (s *Storage) DueAccountDeletions(ctx context.Context, before time.Time, limit int) ([]int64, error) {
+	const op = "storage.sqlite.DueAccountDeletions"
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT user_id FROM account_deletions WHERE execute_at <= ? ORDER BY execute_at, user_id LIMIT ?",
+		before.UTC(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ids, nil
+}
+
+func scanAccountDeletion(row *sql.Row) (models.AccountDeletion, error) {
+	var d models.AccountDeletion
+	if err := row.Scan(&d.UserID, &d.TokenHash, &d.RequestedAt, &d.ExecuteAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AccountDeletion{}, storage.ErrAccountDeletionNotFound
+		}
+
+		return models.AccountDeletion{}, err
+	}
+
+	return d, nil
+}
@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountDeletion_Lifecycle(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	_, err := s.AccountDeletion(ctx, ids[0])
+	require.ErrorIs(t, err, storage.ErrAccountDeletionNotFound)
+
+	now := time.Now()
+	require.NoError(t, s.SaveAccountDeletion(ctx, models.AccountDeletion{
+		UserID: ids[0], TokenHash: "first", RequestedAt: now, ExecuteAt: now.Add(time.Hour),
+	}))
+
+	// Повторный запрос заменяет токен и срок
+	require.NoError(t, s.SaveAccountDeletion(ctx, models.AccountDeletion{
+		UserID: ids[0], TokenHash: "second", RequestedAt: now, ExecuteAt: now.Add(2 * time.Hour),
+	}))
+	_, err = s.AccountDeletionByTokenHash(ctx, "first")
+	require.ErrorIs(t, err, storage.ErrAccountDeletionNotFound)
+
+	d, err := s.AccountDeletionByTokenHash(ctx, "second")
+	require.NoError(t, err)
+	assert.Equal(t, ids[0], d.UserID)
+	assert.WithinDuration(t, now.Add(2*time.Hour), d.ExecuteAt, time.Second)
+
+	d, err = s.AccountDeletion(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, "second", d.TokenHash)
+
+	require.NoError(t, s.DeleteAccountDeletion(ctx, ids[0]))
+	require.ErrorIs(t, s.DeleteAccountDeletion(ctx, ids[0]), storage.ErrAccountDeletionNotFound)
+}
+
+func TestDueAccountDeletions(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 3)
+	ctx := context.Background()
+
+	now := time.Now()
+	for i, at := range []time.Time{now.Add(-time.Minute), now.Add(-time.Hour), now.Add(time.Hour)} {
+		require.NoError(t, s.SaveAccountDeletion(ctx, models.AccountDeletion{
+			UserID: ids[i], TokenHash: string(rune('a' + i)), RequestedAt: now, ExecuteAt: at,
+		}))
+	}
+
+	due, err := s.DueAccountDeletions(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[1], ids[0]}, due)
+
+	due, err = s.DueAccountDeletions(ctx, now, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{ids[1]}, due)
+}
+
+func TestEraseUser_ClearsAccountDeletion(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	require.NoError(t, s.SaveAccountDeletion(ctx, models.AccountDeletion{
+		UserID: ids[0], TokenHash: "hash", RequestedAt: time.Now(), ExecuteAt: time.Now(),
+	}))
+	require.NoError(t, s.EraseUser(ctx, ids[0], "deleted@example.invalid"))
+
+	_, err := s.AccountDeletion(ctx, ids[0])
+	require.ErrorIs(t, err, storage.ErrAccountDeletionNotFound)
+}
@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"time"
+)
+
+// deviceColumns - столбцы входа на устройстве в порядке scanDevice
+const deviceColumns = `id, device_code_hash, user_code, app_id, status, COALESCE(user_id, 0), interval_seconds,
+	last_polled_at, created_at, expires_at`
+
+// SaveDeviceAuthorization - сохраняет начатый вход на устройстве и возвращает его id.
+// user_code уже занят - storage.ErrUserCodeExists (нужно сгенерировать другой).
+func (s *Storage) SaveDeviceAuthorization(ctx context.Context, d models.DeviceAuthorization) (int64, error) {
+	const op = "storage.sqlite.SaveDeviceAuthorization"
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO device_authorizations
+		(device_code_hash, user_code, app_id, interval_seconds, expires_at) VALUES(?, ?, ?, ?, ?)`,
+		d.DeviceCodeHash, d.UserCode, d.AppID, int64(d.Interval/time.Second), d.ExpiresAt.UTC())
+	if err != nil {
+		if isUnique(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrUserCodeExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// DeviceAuthorizationByUserCode - вход на устройстве по user_code; нет такого - storage.ErrDeviceAuthorizationNotFound.
+func (s *Storage) DeviceAuthorizationByUserCode(ctx context.Context, userCode string) (models.DeviceAuthorization, error) {
+	const op = "storage.sqlite.DeviceAuthorizationByUserCode"
+
+	d, err := scanDevice(s.db.QueryRowContext(ctx,
+		"SELECT "+deviceColumns+" FROM device_authorizations WHERE user_code = ?", userCode))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DeviceAuthorization{}, fmt.Errorf("%s: %w", op, storage.ErrDeviceAuthorizationNotFound)
+		}
+
+		return models.DeviceAuthorization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
+
+// DeviceAuthorizationByCodeHash - вход на устройстве по SHA-256 device_code; нет такого - storage.ErrDeviceAuthorizationNotFound.
+func (s *Storage) DeviceAuthorizationByCodeHash(ctx context.Context, codeHash string) (models.DeviceAuthorization, error) {
+	const op = "storage.sqlite.DeviceAuthorizationByCodeHash"
+
+	d, err := scanDevice(s.db.QueryRowContext(ctx,
+		"SELECT "+deviceColumns+" FROM device_authorizations WHERE device_code_hash = ?", codeHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DeviceAuthorization{}, fmt.Errorf("%s: %w", op, storage.ErrDeviceAuthorizationNotFound)
+		}
+
+		return models.DeviceAuthorization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return d, nil
+}
+
+// DecideDeviceAuthorization - записывает решение пользователя userID (разрешить или отказать).
+// Решение уже принято - storage.ErrDeviceAuthorizationUsed.
+func (s *Storage) DecideDeviceAuthorization(ctx context.Context, id, userID int64, approve bool) error {
+	const op = "storage.sqlite.DecideDeviceAuthorization"
+
+	status := models.DeviceDenied
+	if approve {
+		status = models.DeviceApproved
+	}
+
+	if err := s.updateDevice(ctx, `UPDATE device_authorizations SET status = ?, user_id = ?
+		WHERE id = ? AND status = ?`, status, userID, id, models.DevicePending); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RecordDevicePoll - запоминает время опроса и (возможно, увеличенный) интервал опроса.
+func (s *Storage) RecordDevicePoll(ctx context.Context, id int64, polledAt time.Time, interval time.Duration) error {
+	const op = "storage.sqlite.RecordDevicePoll"
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE device_authorizations SET last_polled_at = ?, interval_seconds = ?
+		WHERE id = ?`, polledAt.UTC(), int64(interval/time.Second), id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeDeviceAuthorization - отмечает, что устройство забрало токен. Токен выдаётся ровно один раз:
+// вход не разрешён или уже использован - storage.ErrDeviceAuthorizationUsed.
+func (s *Storage) ConsumeDeviceAuthorization(ctx context.Context, id int64) error {
+	const op = "storage.sqlite.ConsumeDeviceAuthorization"
+
+	if err := s.updateDevice(ctx, `UPDATE device_authorizations SET status = ? WHERE id = ? AND status = ?`,
+		models.DeviceConsumed, id, models.DeviceApproved); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredDeviceAuthorizations - удаляет до limit входов, истёкших до before. Возвращает количество удалённых
+func (s *Storage) DeleteExpiredDeviceAuthorizations(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredDeviceAuthorizations"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM device_authorizations WHERE id IN
+		(SELECT id FROM device_authorizations WHERE expires_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+// updateDevice - меняет состояние входа; ни одна строка не изменилась - storage.ErrDeviceAuthorizationUsed
+func (s *Storage) updateDevice(ctx context.Context, query string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return storage.ErrDeviceAuthorizationUsed
+	}
+
+	return nil
+}
+
+func scanDevice(row interface{ Scan(dest ...any) error }) (models.DeviceAuthorization, error) {
+	var (
+		d        models.DeviceAuthorization
+		interval int64
+		polled   sql.NullTime
+	)
+	err := row.Scan(&d.ID, &d.DeviceCodeHash, &d.UserCode, &d.AppID, &d.Status, &d.UserID, &interval,
+		&polled, &d.CreatedAt, &d.ExpiresAt)
+	if err != nil {
+		return models.DeviceAuthorization{}, err
+	}
+
+	d.Interval, d.LastPolledAt = time.Duration(interval)*time.Second, polled.Time
+
+	return d, nil
+}
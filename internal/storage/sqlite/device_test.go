@@ -0,0 +1,78 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceAuthorization_Lifecycle(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	id, err := s.SaveDeviceAuthorization(ctx, models.DeviceAuthorization{
+		DeviceCodeHash: "hash", UserCode: "BCDFGHJK", AppID: 1, Interval: 5 * time.Second,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+
+	_, err = s.SaveDeviceAuthorization(ctx, models.DeviceAuthorization{
+		DeviceCodeHash: "other", UserCode: "BCDFGHJK", AppID: 1, ExpiresAt: time.Now().Add(time.Minute),
+	})
+	require.ErrorIs(t, err, storage.ErrUserCodeExists)
+
+	d, err := s.DeviceAuthorizationByUserCode(ctx, "BCDFGHJK")
+	require.NoError(t, err)
+	assert.Equal(t, id, d.ID)
+	assert.Equal(t, models.DevicePending, d.Status)
+	assert.Equal(t, 5*time.Second, d.Interval)
+	assert.True(t, d.LastPolledAt.IsZero())
+
+	// Устройство не может забрать токен, пока пользователь не разрешил вход
+	require.ErrorIs(t, s.ConsumeDeviceAuthorization(ctx, id), storage.ErrDeviceAuthorizationUsed)
+
+	polled := time.Now()
+	require.NoError(t, s.RecordDevicePoll(ctx, id, polled, 10*time.Second))
+	require.NoError(t, s.DecideDeviceAuthorization(ctx, id, ids[0], true))
+	require.ErrorIs(t, s.DecideDeviceAuthorization(ctx, id, ids[0], false), storage.ErrDeviceAuthorizationUsed)
+
+	d, err = s.DeviceAuthorizationByCodeHash(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, models.DeviceApproved, d.Status)
+	assert.Equal(t, ids[0], d.UserID)
+	assert.Equal(t, 10*time.Second, d.Interval)
+	assert.WithinDuration(t, polled, d.LastPolledAt, time.Second)
+
+	require.NoError(t, s.ConsumeDeviceAuthorization(ctx, id))
+	require.ErrorIs(t, s.ConsumeDeviceAuthorization(ctx, id), storage.ErrDeviceAuthorizationUsed)
+
+	_, err = s.DeviceAuthorizationByCodeHash(ctx, "missing")
+	require.ErrorIs(t, err, storage.ErrDeviceAuthorizationNotFound)
+}
+
+func TestDeleteExpiredDeviceAuthorizations(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	for i, expires := range []time.Time{time.Now().Add(-time.Minute), time.Now().Add(time.Minute)} {
+		_, err := s.SaveDeviceAuthorization(ctx, models.DeviceAuthorization{
+			DeviceCodeHash: string(rune('a' + i)), UserCode: string(rune('A' + i)), AppID: 1, ExpiresAt: expires,
+		})
+		require.NoError(t, err)
+	}
+
+	n, err := s.DeleteExpiredDeviceAuthorizations(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.DeviceAuthorizationByUserCode(ctx, "A")
+	require.ErrorIs(t, err, storage.ErrDeviceAuthorizationNotFound)
+	_, err = s.DeviceAuthorizationByUserCode(ctx, "B")
+	require.NoError(t, err)
+}
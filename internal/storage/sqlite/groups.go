@@ -0,0 +1,190 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+)
+
+// CreateGroup - создаёт группу; имя уже занято - storage.ErrGroupExists.
+func (s *Storage) CreateGroup(ctx context.Context, name string) (int64, error) {
+	const op = "storage.sqlite.CreateGroup"
+
+	res, err := s.db.ExecContext(ctx, "INSERT INTO groups(name) VALUES(?)", name)
+	if err != nil {
+		if isUnique(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrGroupExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// DeleteGroup - удаляет группу вместе с членством в ней; группы нет - storage.ErrGroupNotFound.
+func (s *Storage) DeleteGroup(ctx context.Context, groupID int64) error {
+	const op = "storage.sqlite.DeleteGroup"
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		// Внешние ключи выключены, поэтому ON DELETE CASCADE не сработает - удаляем членство сами
+		if _, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM user_groups WHERE group_id = ?", groupID); err != nil {
+			return err
+		}
+
+		res, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM groups WHERE id = ?", groupID)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return storage.ErrGroupNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AddToGroup - добавляет пользователя в группу; повторное добавление ничего не меняет.
+func (s *Storage) AddToGroup(ctx context.Context, groupID, userID int64) error {
+	const op = "storage.sqlite.AddToGroup"
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.checkGroup(ctx, groupID); err != nil {
+			return err
+		}
+
+		var exists bool
+		if err := s.conn(ctx).QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM users WHERE id = ? AND erased_at IS NULL)", userID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrUserNotFound
+		}
+
+		_, err := s.conn(ctx).ExecContext(ctx,
+			"INSERT INTO user_groups(group_id, user_id) VALUES(?, ?) ON CONFLICT DO NOTHING", groupID, userID)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RemoveFromGroup - исключает пользователя из группы; не участник - storage.ErrNotInGroup.
+func (s *Storage) RemoveFromGroup(ctx context.Context, groupID, userID int64) error {
+	const op = "storage.sqlite.RemoveFromGroup"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM user_groups WHERE group_id = ? AND user_id = ?", groupID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotInGroup)
+	}
+
+	return nil
+}
+
+// ListGroupMembers - участники группы по возрастанию id пользователя (стёртые не попадают).
+func (s *Storage) ListGroupMembers(ctx context.Context, groupID int64) ([]models.GroupMember, error) {
+	const op = "storage.sqlite.ListGroupMembers"
+
+	if err := s.checkGroup(ctx, groupID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT u.id, u.email, g.created_at
+		FROM user_groups g JOIN users u ON u.id = g.user_id
+		WHERE g.group_id = ? AND u.erased_at IS NULL
+		ORDER BY u.id`, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var members []models.GroupMember
+	for rows.Next() {
+		var m models.GroupMember
+		if err := rows.Scan(&m.UserID, &m.Email, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if m.Email, err = s.open(m.Email); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// UserGroups - имена групп пользователя по алфавиту (для клейма groups).
+func (s *Storage) UserGroups(ctx context.Context, userID int64) ([]string, error) {
+	const op = "storage.sqlite.UserGroups"
+
+	rows, err := s.read(ctx).QueryContext(ctx, `SELECT g.name
+		FROM user_groups m JOIN groups g ON g.id = m.group_id
+		WHERE m.user_id = ?
+		ORDER BY g.name`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	groups := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		groups = append(groups, name)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return groups, nil
+}
+
+// checkGroup - storage.ErrGroupNotFound, если группы groupID нет
+func (s *Storage) checkGroup(ctx context.Context, groupID int64) error {
+	var exists bool
+	if err := s.conn(ctx).QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM groups WHERE id = ?)", groupID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return storage.ErrGroupNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroups(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 2)
+	ctx := context.Background()
+
+	eng, err := s.CreateGroup(ctx, "engineering")
+	require.NoError(t, err)
+	finance, err := s.CreateGroup(ctx, "finance")
+	require.NoError(t, err)
+
+	_, err = s.CreateGroup(ctx, "engineering")
+	require.ErrorIs(t, err, storage.ErrGroupExists)
+
+	require.NoError(t, s.AddToGroup(ctx, finance, ids[0]))
+	require.NoError(t, s.AddToGroup(ctx, eng, ids[0]))
+	require.NoError(t, s.AddToGroup(ctx, eng, ids[1]))
+	// Повторное добавление ничего не меняет
+	require.NoError(t, s.AddToGroup(ctx, eng, ids[1]))
+
+	groups, err := s.UserGroups(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"engineering", "finance"}, groups)
+
+	members, err := s.ListGroupMembers(ctx, eng)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "user0@example.com", members[0].Email)
+
+	require.ErrorIs(t, s.AddToGroup(ctx, eng, 999), storage.ErrUserNotFound)
+	require.ErrorIs(t, s.AddToGroup(ctx, 999, ids[0]), storage.ErrGroupNotFound)
+	_, err = s.ListGroupMembers(ctx, 999)
+	require.ErrorIs(t, err, storage.ErrGroupNotFound)
+
+	require.NoError(t, s.RemoveFromGroup(ctx, finance, ids[0]))
+	require.ErrorIs(t, s.RemoveFromGroup(ctx, finance, ids[0]), storage.ErrNotInGroup)
+
+	groups, err = s.UserGroups(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, []string{"engineering"}, groups)
+
+	// Удаление группы удаляет и членство в ней
+	require.NoError(t, s.DeleteGroup(ctx, eng))
+	require.ErrorIs(t, s.DeleteGroup(ctx, eng), storage.ErrGroupNotFound)
+
+	groups, err = s.UserGroups(ctx, ids[1])
+	require.NoError(t, err)
+	assert.Empty(t, groups)
+	assert.NotNil(t, groups, "an empty claim is [] rather than null")
+
+	var left int
+	require.NoError(t, s.db.QueryRow("SELECT COUNT(*) FROM user_groups WHERE group_id = ?", eng).Scan(&left))
+	assert.Zero(t, left)
+}
+
+func TestSetAppGroupsClaim(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "wiki", "secret")
+	require.NoError(t, err)
+
+	app, err := s.App(ctx, appID)
+	require.NoError(t, err)
+	assert.False(t, app.GroupsClaim, "off by default")
+
+	require.NoError(t, s.SetAppGroupsClaim(ctx, appID, true))
+	app, err = s.App(ctx, appID)
+	require.NoError(t, err)
+	assert.True(t, app.GroupsClaim)
+
+	require.ErrorIs(t, s.SetAppGroupsClaim(ctx, 999, true), storage.ErrAppNotFound)
+}
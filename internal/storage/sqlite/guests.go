@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+// SaveGuest - создаёт гостя: placeholder вместо email, без пароля (войти по паролю нельзя).
+func (s *Storage) SaveGuest(ctx context.Context, placeholder string) (int64, error) {
+	const op = "storage.sqlite.SaveGuest"
+
+	placeholder, index, err := s.sealEmail(placeholder)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO users(email, email_index, pass_hash, is_guest, last_active_at, created_at)
+		VALUES(?, ?, X'', TRUE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`, placeholder, index)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// TouchGuest - отмечает активность гостя. Для обычных пользователей ничего не делает.
+// Время обновляется не чаще раза в минуту, чтобы частые запросы гостя не превращались в частые записи
+func (s *Storage) TouchGuest(ctx context.Context, userID int64) error {
+	const op = "storage.sqlite.TouchGuest"
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE users SET last_active_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND is_guest AND last_active_at < datetime('now', '-1 minute')`, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UpgradeGuest - превращает гостя в обычного пользователя с тем же id: задаёт email и пароль
+// и снимает флаг гостя. Email занят - storage.ErrUserExists; пользователь не гость (или уже
+// обновлён) - storage.ErrUserNotFound
+func (s *Storage) UpgradeGuest(ctx context.Context, userID int64, email string, passHash []byte) error {
+	const op = "storage.sqlite.UpgradeGuest"
+
+	email, index, err := s.sealEmail(email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users
+		SET email = ?, email_index = ?, pass_hash = ?, is_guest = FALSE, last_active_at = NULL, password_changed_at = CURRENT_TIMESTAMP,
+			version = version + 1
+		WHERE id = ? AND is_guest AND erased_at IS NULL`, email, index, passHash, userID)
+	if err != nil {
+		if isUnique(err) {
+			return fmt.Errorf("%s: %w", op, storage.ErrUserExists)
+		}
+
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// DeleteInactiveGuests - удаляет до limit гостей, неактивных с before, вместе с их записями
+// в других таблицах. Возвращает количество удалённых
+func (s *Storage) DeleteInactiveGuests(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteInactiveGuests"
+
+	var deleted int
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		// last_active_at пишется через CURRENT_TIMESTAMP (UTC, "YYYY-MM-DD HH:MM:SS"), сравниваем в том же формате
+		rows, err := s.conn(ctx).QueryContext(ctx, `SELECT id FROM users
+			WHERE is_guest AND last_active_at < ? ORDER BY last_active_at LIMIT ?`, before.UTC().Format(time.DateTime), limit)
+		if err != nil {
+			return err
+		}
+
+		var ids []any
+		for rows.Next() {
+			var id int64
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+
+				return err
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+
+		// Внешние ключи выключены - записи гостя в других таблицах удаляем сами
+		in := "(?" + strings.Repeat(", ?", len(ids)-1) + ")"
+		tables := []string{"user_groups", "org_members", "consents", "tos_acceptances", "password_history", "action_tokens", "login_history"}
+		for _, table := range tables {
+			if _, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM "+table+" WHERE user_id IN "+in, ids...); err != nil {
+				return err
+			}
+		}
+
+		res, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM users WHERE is_guest AND id IN "+in, ids...)
+		if err != nil {
+			return err
+		}
+
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		deleted = int(n)
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return deleted, nil
+}
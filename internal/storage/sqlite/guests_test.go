@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGuests_UpgradeKeepsID(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	guestID, err := s.SaveGuest(ctx, "guest-1@guest.invalid")
+	require.NoError(t, err)
+
+	u, err := s.UserByID(ctx, guestID)
+	require.NoError(t, err)
+	assert.True(t, u.IsGuest)
+
+	// Гости не попадают в список пользователей, пока их не попросили явно
+	users, err := s.ListUsers(ctx, 0, 10, "", false, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, ids[0], users[0].ID)
+
+	users, err = s.ListUsers(ctx, 0, 10, "", true, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.True(t, users[1].IsGuest)
+
+	err = s.UpgradeGuest(ctx, guestID, "user0@example.com", []byte("hash"))
+	require.ErrorIs(t, err, storage.ErrUserExists)
+
+	require.NoError(t, s.UpgradeGuest(ctx, guestID, "new@example.com", []byte("hash")))
+
+	u, err = s.UserByID(ctx, guestID)
+	require.NoError(t, err)
+	assert.False(t, u.IsGuest)
+	assert.Equal(t, "new@example.com", u.Email)
+
+	// Обычного пользователя "обновить" нельзя
+	err = s.UpgradeGuest(ctx, guestID, "other@example.com", []byte("hash"))
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func TestDeleteInactiveGuests(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	stale, err := s.SaveGuest(ctx, "guest-1@guest.invalid")
+	require.NoError(t, err)
+	fresh, err := s.SaveGuest(ctx, "guest-2@guest.invalid")
+	require.NoError(t, err)
+	require.NoError(t, s.AcceptTOS(ctx, stale, "2025-01"))
+
+	_, err = s.db.ExecContext(ctx, `UPDATE users SET last_active_at = datetime('now', '-2 days') WHERE id = ?`, stale)
+	require.NoError(t, err)
+
+	n, err := s.DeleteInactiveGuests(ctx, time.Now().Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.UserByID(ctx, stale)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.LatestTOSAcceptance(ctx, stale)
+	require.ErrorIs(t, err, storage.ErrTOSNotAccepted)
+
+	_, err = s.UserByID(ctx, fresh)
+	require.NoError(t, err)
+
+	// Обычные пользователи не удаляются, сколько бы ни прошло времени
+	n, err = s.DeleteInactiveGuests(ctx, time.Now().Add(time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.UserByID(ctx, ids[0])
+	require.NoError(t, err)
+}
@@ -0,0 +1,202 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+)
+
+// invitationColumns - столбцы приглашения в порядке scanInvitation
+const invitationColumns = `id, email, token_hash, app_id, role, invited_by, created_at, expires_at,
+	accepted_at, revoked_at, COALESCE(user_id, 0)`
+
+// SaveInvitation - сохраняет приглашение и возвращает его в том виде, в каком оно записано.
+// Если у email уже есть ожидающее приглашение, оно продлевается: получает новый токен, срок, приложение
+// и роль (старая ссылка перестаёт действовать). renewed - так и случилось.
+func (s *Storage) SaveInvitation(ctx context.Context, inv models.Invitation) (saved models.Invitation, renewed bool, err error) {
+	const op = "storage.sqlite.SaveInvitation"
+
+	email, index, err := s.sealEmail(inv.Email)
+	if err != nil {
+		return models.Invitation{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.WithinTx(ctx, func(ctx context.Context) error {
+		where, arg := s.emailEquals("email", inv.Email)
+		saved, err = s.scanInvitation(s.conn(ctx).QueryRowContext(ctx, `UPDATE invitations
+			SET token_hash = ?, app_id = ?, role = ?, invited_by = ?, expires_at = ?
+			WHERE `+where+` AND accepted_at IS NULL AND revoked_at IS NULL
+			RETURNING `+invitationColumns,
+			inv.TokenHash, inv.AppID, inv.Role, inv.InvitedBy, inv.ExpiresAt, arg))
+		if err == nil {
+			renewed = true
+
+			return nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+
+		saved, err = s.scanInvitation(s.conn(ctx).QueryRowContext(ctx, `INSERT INTO invitations
+			(email, email_index, token_hash, app_id, role, invited_by, expires_at) VALUES(?, ?, ?, ?, ?, ?, ?)
+			RETURNING `+invitationColumns,
+			email, index, inv.TokenHash, inv.AppID, inv.Role, inv.InvitedBy, inv.ExpiresAt))
+
+		return err
+	})
+	if err != nil {
+		return models.Invitation{}, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return saved, renewed, nil
+}
+
+// InvitationByTokenHash - приглашение по SHA-256 токена; нет такого - storage.ErrInvitationNotFound.
+func (s *Storage) InvitationByTokenHash(ctx context.Context, tokenHash string) (models.Invitation, error) {
+	const op = "storage.sqlite.InvitationByTokenHash"
+
+	inv, err := s.scanInvitation(s.db.QueryRowContext(ctx,
+		"SELECT "+invitationColumns+" FROM invitations WHERE token_hash = ?", tokenHash))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Invitation{}, fmt.Errorf("%s: %w", op, storage.ErrInvitationNotFound)
+		}
+
+		return models.Invitation{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return inv, nil
+}
+
+// AcceptInvitation - создаёт пользователя по приглашению (email подтверждён, роль из приглашения)
+// и помечает приглашение принятым - всё в одной транзакции. Приглашение уже принято или отозвано -
+// storage.ErrInvitationUsed; email занят - storage.ErrUserExists.
+func (s *Storage) AcceptInvitation(ctx context.Context, invitationID int64, passHash []byte) (int64, error) {
+	const op = "storage.sqlite.AcceptInvitation"
+
+	var userID int64
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		// email переносится в пользователя как есть: с ключом pii он уже зашифрован и имеет слепой индекс
+		var (
+			email, role string
+			index       sql.NullString
+		)
+		err := s.conn(ctx).QueryRowContext(ctx, `UPDATE invitations SET accepted_at = CURRENT_TIMESTAMP
+			WHERE id = ? AND accepted_at IS NULL AND revoked_at IS NULL
+			RETURNING email, email_index, role`, invitationID).Scan(&email, &index, &role)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storage.ErrInvitationUsed
+			}
+
+			return err
+		}
+
+		res, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO users(email, email_index, pass_hash, password_changed_at, is_admin, email_verified, created_at)
+			VALUES(?, ?, ?, CURRENT_TIMESTAMP, ?, TRUE, CURRENT_TIMESTAMP)`, email, index, passHash, role == models.RoleAdmin)
+		if err != nil {
+			if isUnique(err) {
+				return storage.ErrUserExists
+			}
+
+			return err
+		}
+
+		if userID, err = res.LastInsertId(); err != nil {
+			return err
+		}
+
+		_, err = s.conn(ctx).ExecContext(ctx, "UPDATE invitations SET user_id = ? WHERE id = ?", userID, invitationID)
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, nil
+}
+
+// ListInvitations - до limit приглашений с id больше afterID по возрастанию id;
+// pendingOnly оставляет только не принятые и не отозванные (в том числе истёкшие).
+func (s *Storage) ListInvitations(ctx context.Context, afterID int64, limit int, pendingOnly bool) ([]models.Invitation, error) {
+	const op = "storage.sqlite.ListInvitations"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT "+invitationColumns+` FROM invitations
+		WHERE id > ? AND (NOT ? OR (accepted_at IS NULL AND revoked_at IS NULL))
+		ORDER BY id LIMIT ?`, afterID, pendingOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var invitations []models.Invitation
+	for rows.Next() {
+		inv, err := s.scanInvitation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		invitations = append(invitations, inv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return invitations, nil
+}
+
+// RevokeInvitation - отзывает ожидающее приглашение и возвращает его email. Нет такого - storage.ErrInvitationNotFound,
+// уже принято или отозвано - storage.ErrInvitationUsed.
+func (s *Storage) RevokeInvitation(ctx context.Context, invitationID int64) (string, error) {
+	const op = "storage.sqlite.RevokeInvitation"
+
+	var email string
+	err := s.db.QueryRowContext(ctx, `UPDATE invitations SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = ? AND accepted_at IS NULL AND revoked_at IS NULL
+		RETURNING email`, invitationID).Scan(&email)
+	if err == nil {
+		if email, err = s.open(email); err != nil {
+			return "", fmt.Errorf("%s: %w", op, err)
+		}
+
+		return email, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	var exists bool
+	if err := s.db.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM invitations WHERE id = ?)", invitationID).Scan(&exists); err != nil {
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return "", fmt.Errorf("%s: %w", op, storage.ErrInvitationNotFound)
+	}
+
+	return "", fmt.Errorf("%s: %w", op, storage.ErrInvitationUsed)
+}
+
+// scanInvitation - приглашение из строки с invitationColumns
+func (s *Storage) scanInvitation(row interface{ Scan(dest ...any) error }) (models.Invitation, error) {
+	var (
+		inv               models.Invitation
+		accepted, revoked sql.NullTime
+	)
+	err := row.Scan(&inv.ID, &inv.Email, &inv.TokenHash, &inv.AppID, &inv.Role, &inv.InvitedBy,
+		&inv.CreatedAt, &inv.ExpiresAt, &accepted, &revoked, &inv.UserID)
+	if err != nil {
+		return models.Invitation{}, err
+	}
+
+	inv.AcceptedAt, inv.RevokedAt = accepted.Time, revoked.Time
+	if inv.Email, err = s.open(inv.Email); err != nil {
+		return models.Invitation{}, err
+	}
+
+	return inv, nil
+}
@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvitations(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	inv, renewed, err := s.SaveInvitation(ctx, models.Invitation{
+		Email: "new@example.com", TokenHash: "hash-1", AppID: 1, Role: models.RoleAdmin, InvitedBy: 5, ExpiresAt: expires,
+	})
+	require.NoError(t, err)
+	assert.False(t, renewed)
+	assert.NotZero(t, inv.ID)
+	assert.False(t, inv.CreatedAt.IsZero())
+	assert.True(t, inv.ExpiresAt.Equal(expires))
+
+	// Повторное приглашение того же email (в другом регистре) продлевает первое, а не создаёт второе
+	later := expires.Add(time.Hour)
+	again, renewed, err := s.SaveInvitation(ctx, models.Invitation{
+		Email: "New@Example.com", TokenHash: "hash-2", AppID: 1, Role: models.RoleUser, ExpiresAt: later,
+	})
+	require.NoError(t, err)
+	assert.True(t, renewed)
+	assert.Equal(t, inv.ID, again.ID)
+	assert.Equal(t, models.RoleUser, again.Role)
+	assert.True(t, again.ExpiresAt.Equal(later))
+
+	// Старый токен больше не действует
+	_, err = s.InvitationByTokenHash(ctx, "hash-1")
+	require.ErrorIs(t, err, storage.ErrInvitationNotFound)
+
+	got, err := s.InvitationByTokenHash(ctx, "hash-2")
+	require.NoError(t, err)
+	assert.True(t, got.Pending())
+
+	userID, err := s.AcceptInvitation(ctx, got.ID, []byte("hash"))
+	require.NoError(t, err)
+
+	user, err := s.UserByID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", user.Email, "email is stored as it was first invited")
+	assert.True(t, user.EmailVerified)
+	assert.False(t, user.IsAdmin, "role of the renewed invitation applies")
+
+	// Одноразовость
+	_, err = s.AcceptInvitation(ctx, got.ID, []byte("hash"))
+	require.ErrorIs(t, err, storage.ErrInvitationUsed)
+
+	accepted, err := s.InvitationByTokenHash(ctx, "hash-2")
+	require.NoError(t, err)
+	assert.Equal(t, models.InvitationAccepted, accepted.Status(time.Now()))
+	assert.Equal(t, userID, accepted.UserID)
+}
+
+func TestInvitations_AdminRole(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	inv, _, err := s.SaveInvitation(ctx, models.Invitation{
+		Email: "boss@example.com", TokenHash: "h", AppID: 1, Role: models.RoleAdmin, ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	userID, err := s.AcceptInvitation(ctx, inv.ID, []byte("hash"))
+	require.NoError(t, err)
+
+	isAdmin, err := s.IsAdmin(ctx, userID)
+	require.NoError(t, err)
+	assert.True(t, isAdmin)
+}
+
+func TestInvitations_Revoke(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for _, email := range []string{"a@example.com", "b@example.com", "c@example.com"} {
+		inv, _, err := s.SaveInvitation(ctx, models.Invitation{
+			Email: email, TokenHash: "hash-" + email, AppID: 1, Role: models.RoleUser, ExpiresAt: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		ids = append(ids, inv.ID)
+	}
+
+	email, err := s.RevokeInvitation(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, "a@example.com", email)
+
+	_, err = s.RevokeInvitation(ctx, ids[0])
+	require.ErrorIs(t, err, storage.ErrInvitationUsed)
+	_, err = s.RevokeInvitation(ctx, 999)
+	require.ErrorIs(t, err, storage.ErrInvitationNotFound)
+	_, err = s.AcceptInvitation(ctx, ids[0], []byte("hash"))
+	require.ErrorIs(t, err, storage.ErrInvitationUsed)
+
+	// После отзыва тот же email можно пригласить снова: это новое приглашение
+	inv, renewed, err := s.SaveInvitation(ctx, models.Invitation{
+		Email: "a@example.com", TokenHash: "hash-a2", AppID: 1, Role: models.RoleUser, ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.False(t, renewed)
+	assert.NotEqual(t, ids[0], inv.ID)
+
+	all, err := s.ListInvitations(ctx, 0, 10, false)
+	require.NoError(t, err)
+	assert.Len(t, all, 4)
+
+	pending, err := s.ListInvitations(ctx, 0, 10, true)
+	require.NoError(t, err)
+	require.Len(t, pending, 3)
+	assert.Equal(t, ids[1], pending[0].ID)
+
+	page, err := s.ListInvitations(ctx, ids[1], 1, true)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, ids[2], page[0].ID)
+}
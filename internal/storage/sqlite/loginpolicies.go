@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetAppLoginPolicy - создаёт политику входа приложения или заменяет его политику с тем же именем.
+// Нет приложения - storage.ErrAppNotFound
+func (s *Storage) SetAppLoginPolicy(ctx context.Context, appID int, p models.LoginPolicy) error {
+	const op = "storage.sqlite.SetAppLoginPolicy"
+
+	networks := make([]string, 0, len(p.Networks))
+	for _, n := range p.Networks {
+		networks = append(networks, n.String())
+	}
+	weekdays := make([]string, 0, len(p.Weekdays))
+	for _, d := range p.Weekdays {
+		weekdays = append(weekdays, strconv.Itoa(int(d)))
+	}
+	timezone := time.UTC.String()
+	if p.Location != nil {
+		timezone = p.Location.String()
+	}
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		var exists bool
+		if err := s.conn(ctx).QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM apps WHERE id = ?)", appID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrAppNotFound
+		}
+
+		_, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO app_login_policies
+			(app_id, name, networks, weekdays, start_minute, end_minute, timezone) VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (app_id, name) DO UPDATE SET networks = excluded.networks, weekdays = excluded.weekdays,
+				start_minute = excluded.start_minute, end_minute = excluded.end_minute, timezone = excluded.timezone`,
+			appID, p.Name, strings.Join(networks, " "), strings.Join(weekdays, " "), p.StartMinute, p.EndMinute, timezone)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteAppLoginPolicy - удаляет политику входа приложения; нет такой - storage.ErrLoginPolicyNotFound.
+func (s *Storage) DeleteAppLoginPolicy(ctx context.Context, appID int, name string) error {
+	const op = "storage.sqlite.DeleteAppLoginPolicy"
+
+	res, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM app_login_policies WHERE app_id = ? AND name = ?", appID, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrLoginPolicyNotFound)
+	}
+
+	return nil
+}
+
+// AppLoginPolicies - политики входа приложения по имени.
+func (s *Storage) AppLoginPolicies(ctx context.Context, appID int) ([]models.LoginPolicy, error) {
+	const op = "storage.sqlite.AppLoginPolicies"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, `SELECT name, networks, weekdays, start_minute, end_minute, timezone
+		FROM app_login_policies WHERE app_id = ? ORDER BY name`, appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var res []models.LoginPolicy
+	for rows.Next() {
+		var (
+			p                            models.LoginPolicy
+			networks, weekdays, timezone string
+		)
+		if err := rows.Scan(&p.Name, &networks, &weekdays, &p.StartMinute, &p.EndMinute, &timezone); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		for _, n := range strings.Fields(networks) {
+			prefix, err := netip.ParsePrefix(n)
+			if err != nil {
+				return nil, fmt.Errorf("%s: policy %q: %w", op, p.Name, err)
+			}
+			p.Networks = append(p.Networks, prefix)
+		}
+		for _, d := range strings.Fields(weekdays) {
+			day, err := strconv.Atoi(d)
+			if err != nil {
+				return nil, fmt.Errorf("%s: policy %q: %w", op, p.Name, err)
+			}
+			p.Weekdays = append(p.Weekdays, time.Weekday(day))
+		}
+		if p.Location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("%s: policy %q: %w", op, p.Name, err)
+		}
+
+		res = append(res, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"context"
+	"net/netip"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppLoginPolicies(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	id, err := s.SaveApp(ctx, "intranet", "secret")
+	require.NoError(t, err)
+
+	office := models.LoginPolicy{
+		Name:        "office",
+		Networks:    []netip.Prefix{netip.MustParsePrefix("10.1.0.0/16"), netip.MustParsePrefix("2001:db8::/32")},
+		Weekdays:    []time.Weekday{time.Monday, time.Friday},
+		StartMinute: 9 * 60,
+		EndMinute:   18 * 60,
+		Location:    moscow,
+	}
+	require.NoError(t, s.SetAppLoginPolicy(ctx, id, office))
+	require.NoError(t, s.SetAppLoginPolicy(ctx, id, models.LoginPolicy{Name: "anytime"}))
+
+	app, err := s.App(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, app.LoginPolicies, 2)
+	assert.Equal(t, "anytime", app.LoginPolicies[0].Name)
+	assert.Equal(t, time.UTC, app.LoginPolicies[0].Location)
+	assert.Equal(t, office.Networks, app.LoginPolicies[1].Networks)
+	assert.Equal(t, office.Weekdays, app.LoginPolicies[1].Weekdays)
+	assert.Equal(t, 9*60, app.LoginPolicies[1].StartMinute)
+	assert.Equal(t, "Europe/Moscow", app.LoginPolicies[1].Location.String())
+	assert.False(t, app.RecheckNetworkPolicy)
+
+	// Политика с тем же именем заменяется
+	office.Networks = nil
+	require.NoError(t, s.SetAppLoginPolicy(ctx, id, office))
+	app, err = s.App(ctx, id)
+	require.NoError(t, err)
+	require.Len(t, app.LoginPolicies, 2)
+	assert.Empty(t, app.LoginPolicies[1].Networks)
+
+	require.NoError(t, s.DeleteAppLoginPolicy(ctx, id, "anytime"))
+	require.ErrorIs(t, s.DeleteAppLoginPolicy(ctx, id, "anytime"), storage.ErrLoginPolicyNotFound)
+
+	require.NoError(t, s.SetAppRecheckNetworkPolicy(ctx, id, true))
+	app, err = s.App(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, app.RecheckNetworkPolicy)
+	require.Len(t, app.LoginPolicies, 1)
+
+	require.ErrorIs(t, s.SetAppLoginPolicy(ctx, id+1, office), storage.ErrAppNotFound)
+	require.ErrorIs(t, s.SetAppRecheckNetworkPolicy(ctx, id+1, true), storage.ErrAppNotFound)
+}
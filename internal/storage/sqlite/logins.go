@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"time"
+)
+
+// AddLogin - записывает успешный вход в историю входов.
+func (s *Storage) AddLogin(ctx context.Context, r models.LoginRecord) error {
+	const op = "storage.sqlite.AddLogin"
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO login_history (user_id, app_id, country, asn, device, suspicious, offline, logged_in_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)`, r.UserID, r.AppID, r.Country, r.ASN, r.Device, r.Suspicious, r.Offline, r.LoggedInAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RecentLogins - до limit последних входов пользователя, от новых к старым.
+func (s *Storage) RecentLogins(ctx context.Context, userID int64, limit int) ([]models.LoginRecord, error) {
+	const op = "storage.sqlite.RecentLogins"
+
+	rows, err := s.db.QueryContext(ctx, `SELECT id, user_id, app_id, country, asn, device, suspicious, offline, logged_in_at
+		FROM login_history WHERE user_id = ? ORDER BY logged_in_at DESC, id DESC LIMIT ?`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var res []models.LoginRecord
+	for rows.Next() {
+		var r models.LoginRecord
+		if err := rows.Scan(&r.ID, &r.UserID, &r.AppID, &r.Country, &r.ASN, &r.Device, &r.Suspicious, &r.Offline, &r.LoggedInAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+// DeleteLoginsBefore - удаляет до limit входов, совершённых до before. Возвращает количество удалённых
+func (s *Storage) DeleteLoginsBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteLoginsBefore"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM login_history WHERE id IN
+		(SELECT id FROM login_history WHERE logged_in_at < ? ORDER BY id LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+// AddLoginFailure - записывает неудачный вход в приложение appID.
+func (s *Storage) AddLoginFailure(ctx context.Context, appID int, at time.Time) error {
+	const op = "storage.sqlite.AddLoginFailure"
+
+	_, err := s.db.ExecContext(ctx, "INSERT INTO login_failures (app_id, failed_at) VALUES(?, ?)", appID, at.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteLoginFailuresBefore - удаляет до limit неудачных входов до before. Возвращает количество удалённых
+func (s *Storage) DeleteLoginFailuresBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteLoginFailuresBefore"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM login_failures WHERE id IN
+		(SELECT id FROM login_failures WHERE failed_at < ? ORDER BY id LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
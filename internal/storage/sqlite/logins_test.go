@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginHistory(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, r := range []models.LoginRecord{
+		{UserID: 7, AppID: 1, Country: "DE", ASN: 3320, LoggedInAt: now.Add(-48 * time.Hour)},
+		{UserID: 7, AppID: 1, Country: "BR", ASN: 28573, Suspicious: true, LoggedInAt: now.Add(-time.Hour)},
+		{UserID: 7, AppID: 2, Device: "3f2a9c0d1b4e5f60", Offline: true, LoggedInAt: now},
+		{UserID: 8, AppID: 1, Country: "FR", LoggedInAt: now},
+	} {
+		require.NoError(t, s.AddLogin(ctx, r))
+	}
+
+	got, err := s.RecentLogins(ctx, 7, 2)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, 2, got[0].AppID)
+	assert.Empty(t, got[0].Country)
+	assert.Equal(t, "3f2a9c0d1b4e5f60", got[0].Device)
+	assert.True(t, got[0].Offline)
+	assert.False(t, got[1].Offline)
+	assert.Empty(t, got[1].Device)
+	assert.Equal(t, "BR", got[1].Country)
+	assert.Equal(t, uint(28573), got[1].ASN)
+	assert.True(t, got[1].Suspicious)
+
+	n, err := s.DeleteLoginsBefore(ctx, now.Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	got, err = s.RecentLogins(ctx, 7, 10)
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+}
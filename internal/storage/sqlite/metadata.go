@@ -0,0 +1,79 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/storage"
+)
+
+// appMetadataPath - JSON-путь к объекту приложения в users.app_metadata
+func appMetadataPath(appID int) string {
+	return fmt.Sprintf(`$."%d"`, appID)
+}
+
+// AppMetadata - метаданные пользователя в пространстве приложения appID ("{}", если их нет)
+// и текущая версия пользователя для SetAppMetadata.
+func (s *Storage) AppMetadata(ctx context.Context, userID int64, appID int) ([]byte, int64, error) {
+	const op = "storage.sqlite.AppMetadata"
+
+	var (
+		metadata []byte
+		version  int64
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT coalesce(json_extract(app_metadata, ?), '{}'), version FROM users
+		WHERE id = ? AND erased_at IS NULL`, appMetadataPath(appID), userID).Scan(&metadata, &version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, 0, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+
+		return nil, 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return metadata, version, nil
+}
+
+// SetAppMetadata - заменяет метаданные пользователя в пространстве приложения appID (metadata - JSON-объект;
+// пустой объект удаляет пространство). Объекты других приложений не меняются. Возвращает новую версию
+// пользователя; expectedVersion - как в SetAdmin
+func (s *Storage) SetAppMetadata(
+	ctx context.Context,
+	userID int64,
+	appID int,
+	metadata []byte,
+	expectedVersion int64,
+) (int64, error) {
+	const op = "storage.sqlite.SetAppMetadata"
+
+	// Путь собирается из числа, поэтому подставлять его в запрос безопасно
+	set, value := "app_metadata = json_set(app_metadata, '"+appMetadataPath(appID)+"', json(?))", any(string(metadata))
+	if string(metadata) == "{}" {
+		set, value = "app_metadata = json_remove(app_metadata, ?)", appMetadataPath(appID)
+	}
+
+	version, err := s.updateUser(ctx, set, value, userID, expectedVersion)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, nil
+}
+
+// AllAppMetadata - метаданные пользователя всех приложений ({"<app_id>": {...}}) для выгрузки данных.
+func (s *Storage) AllAppMetadata(ctx context.Context, userID int64) ([]byte, error) {
+	const op = "storage.sqlite.AllAppMetadata"
+
+	var metadata []byte
+	err := s.db.QueryRowContext(ctx, "SELECT app_metadata FROM users WHERE id = ?", userID).Scan(&metadata)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return metadata, nil
+}
@@ -0,0 +1,48 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppMetadata(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	data, version, err := s.AppMetadata(ctx, ids[0], 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(data))
+
+	version, err = s.SetAppMetadata(ctx, ids[0], 1, []byte(`{"locale":"ru-RU"}`), version)
+	require.NoError(t, err)
+	_, err = s.SetAppMetadata(ctx, ids[0], 2, []byte(`{"locale":"en-US","theme":"dark"}`), 0)
+	require.NoError(t, err)
+
+	// Пространства приложений не пересекаются
+	data, current, err := s.AppMetadata(ctx, ids[0], 1)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"locale":"ru-RU"}`, string(data))
+	assert.Greater(t, current, version)
+
+	// Устаревшая версия - конфликт, запись не меняется
+	_, err = s.SetAppMetadata(ctx, ids[0], 1, []byte(`{"locale":"de-DE"}`), version)
+	require.ErrorIs(t, err, storage.ErrVersionConflict)
+
+	// Пустой объект удаляет пространство приложения
+	_, err = s.SetAppMetadata(ctx, ids[0], 1, []byte(`{}`), current)
+	require.NoError(t, err)
+
+	all, err := s.AllAppMetadata(ctx, ids[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"2":{"locale":"en-US","theme":"dark"}}`, string(all))
+
+	_, _, err = s.AppMetadata(ctx, 999, 1)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.SetAppMetadata(ctx, 999, 1, []byte(`{}`), 0)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
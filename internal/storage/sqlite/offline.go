@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+const offlineTokenColumns = "id, user_id, app_id, label, scope, created_at, expires_at, revoked_at"
+
+// SaveOfflineToken - сохраняет выданный offline-токен.
+func (s *Storage) SaveOfflineToken(ctx context.Context, t models.OfflineToken) error {
+	const op = "storage.sqlite.SaveOfflineToken"
+
+	_, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO offline_tokens (id, user_id, app_id, label, scope, created_at, expires_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`, t.ID, t.UserID, t.AppID, t.Label, strings.Join(t.Scopes, " "),
+		t.CreatedAt.UTC(), t.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// OfflineToken - offline-токен по id (jti), включая отозванные; нет такого - storage.ErrOfflineTokenNotFound.
+func (s *Storage) OfflineToken(ctx context.Context, id string) (models.OfflineToken, error) {
+	const op = "storage.sqlite.OfflineToken"
+
+	t, err := scanOfflineToken(s.conn(ctx).QueryRowContext(ctx,
+		"SELECT "+offlineTokenColumns+" FROM offline_tokens WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.OfflineToken{}, fmt.Errorf("%s: %w", op, storage.ErrOfflineTokenNotFound)
+		}
+
+		return models.OfflineToken{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return t, nil
+}
+
+// OfflineTokens - действующие на момент now offline-токены пользователя, от новых к старым.
+func (s *Storage) OfflineTokens(ctx context.Context, userID int64, now time.Time) ([]models.OfflineToken, error) {
+	const op = "storage.sqlite.OfflineTokens"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, "SELECT "+offlineTokenColumns+` FROM offline_tokens
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ? ORDER BY created_at DESC, rowid DESC`, userID, now.UTC())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var res []models.OfflineToken
+	for rows.Next() {
+		t, err := scanOfflineToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+// RevokeOfflineToken - отзывает в момент at действующий offline-токен id пользователя userID.
+// Нет такого, он чужой или уже отозван - storage.ErrOfflineTokenNotFound
+func (s *Storage) RevokeOfflineToken(ctx context.Context, userID int64, id string, at time.Time) error {
+	const op = "storage.sqlite.RevokeOfflineToken"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE offline_tokens SET revoked_at = ?
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL`, at.UTC(), id, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrOfflineTokenNotFound)
+	}
+
+	return nil
+}
+
+// DeleteExpiredOfflineTokens - удаляет до limit offline-токенов, истёкших до before (и отозванных, и нет).
+// Возвращает количество удалённых
+func (s *Storage) DeleteExpiredOfflineTokens(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredOfflineTokens"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM offline_tokens WHERE id IN
+		(SELECT id FROM offline_tokens WHERE expires_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+func scanOfflineToken(row interface{ Scan(dest ...any) error }) (models.OfflineToken, error) {
+	var (
+		t       models.OfflineToken
+		scope   string
+		revoked sql.NullTime
+	)
+	if err := row.Scan(&t.ID, &t.UserID, &t.AppID, &t.Label, &scope, &t.CreatedAt, &t.ExpiresAt, &revoked); err != nil {
+		return models.OfflineToken{}, err
+	}
+	t.Scopes = strings.Fields(scope)
+	t.RevokedAt = revoked.Time
+
+	return t, nil
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOfflineTokens(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 2)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	tokens := []models.OfflineToken{
+		{ID: "old", UserID: ids[0], AppID: 1, Label: "Desktop", Scopes: []string{"offline_sync"},
+			CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "new", UserID: ids[0], AppID: 1, Label: "Laptop", Scopes: []string{"offline_sync", "files.read"},
+			CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour)},
+		{ID: "expired", UserID: ids[0], AppID: 1, Label: "Old PC", Scopes: []string{"offline_sync"},
+			CreatedAt: now.Add(-3 * time.Hour), ExpiresAt: now.Add(-time.Minute)},
+		{ID: "other", UserID: ids[1], AppID: 1, Label: "Desktop", Scopes: []string{"offline_sync"},
+			CreatedAt: now, ExpiresAt: now.Add(time.Hour)},
+	}
+	for _, tok := range tokens {
+		require.NoError(t, s.SaveOfflineToken(ctx, tok))
+	}
+
+	got, err := s.OfflineToken(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, tokens[1].Scopes, got.Scopes)
+	assert.Equal(t, "Laptop", got.Label)
+	assert.True(t, got.Active(now))
+
+	list, err := s.OfflineTokens(ctx, ids[0], now)
+	require.NoError(t, err)
+	require.Len(t, list, 2, "expired and other users' tokens are not listed")
+	assert.Equal(t, "new", list[0].ID)
+	assert.Equal(t, "old", list[1].ID)
+
+	// Отозвать можно только свой действующий токен и только один раз
+	require.ErrorIs(t, s.RevokeOfflineToken(ctx, ids[1], "old", now), storage.ErrOfflineTokenNotFound)
+	require.NoError(t, s.RevokeOfflineToken(ctx, ids[0], "old", now))
+	require.ErrorIs(t, s.RevokeOfflineToken(ctx, ids[0], "old", now), storage.ErrOfflineTokenNotFound)
+
+	revoked, err := s.OfflineToken(ctx, "old")
+	require.NoError(t, err)
+	assert.False(t, revoked.Active(now))
+
+	list, err = s.OfflineTokens(ctx, ids[0], now)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+
+	n, err := s.DeleteExpiredOfflineTokens(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	_, err = s.OfflineToken(ctx, "expired")
+	require.ErrorIs(t, err, storage.ErrOfflineTokenNotFound)
+}
+
+func TestSetAppAcceptOfflineTokens(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "desktop", "secret")
+	require.NoError(t, err)
+
+	app, err := s.App(ctx, appID)
+	require.NoError(t, err)
+	assert.False(t, app.AcceptOfflineTokens)
+
+	require.NoError(t, s.SetAppAcceptOfflineTokens(ctx, appID, true))
+	app, err = s.App(ctx, appID)
+	require.NoError(t, err)
+	assert.True(t, app.AcceptOfflineTokens)
+
+	require.ErrorIs(t, s.SetAppAcceptOfflineTokens(ctx, 999, true), storage.ErrAppNotFound)
+}
@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"time"
+)
+
+// AppRedirectURIs - адреса возврата приложения (пустой список, если их нет)
+func (s *Storage) AppRedirectURIs(ctx context.Context, appID int) ([]string, error) {
+	const op = "storage.sqlite.AppRedirectURIs"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT uri FROM app_redirect_uris WHERE app_id = ? ORDER BY uri", appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	uris := []string{}
+	for rows.Next() {
+		var uri string
+		if err := rows.Scan(&uri); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		uris = append(uris, uri)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return uris, nil
+}
+
+// SetAppRedirectURIs - заменяет адреса возврата приложения (storage.ErrAppNotFound, если его нет)
+func (s *Storage) SetAppRedirectURIs(ctx context.Context, appID int, uris []string) error {
+	const op = "storage.sqlite.SetAppRedirectURIs"
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		var exists bool
+		if err := s.conn(ctx).QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM apps WHERE id = ?)", appID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrAppNotFound
+		}
+
+		if _, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM app_redirect_uris WHERE app_id = ?", appID); err != nil {
+			return err
+		}
+		for _, uri := range uris {
+			_, err := s.conn(ctx).ExecContext(ctx,
+				"INSERT OR IGNORE INTO app_redirect_uris (app_id, uri) VALUES (?, ?)", appID, uri)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AppsWithPublicKeys - приложения с асимметричной подписью токенов (ES256, EdDSA) для публикации JWKS
+func (s *Storage) AppsWithPublicKeys(ctx context.Context) ([]models.App, error) {
+	const op = "storage.sqlite.AppsWithPublicKeys"
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, name, algorithm, signing_key FROM apps WHERE signing_key != '' ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var apps []models.App
+	for rows.Next() {
+		var app models.App
+		if err := rows.Scan(&app.ID, &app.Name, &app.Algorithm, &app.SigningKey); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		apps = append(apps, app)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return apps, nil
+}
+
+// SaveAuthCode - сохраняет код авторизации
+func (s *Storage) SaveAuthCode(ctx context.Context, c models.AuthCode) error {
+	const op = "storage.sqlite.SaveAuthCode"
+
+	_, err := s.db.ExecContext(ctx, `INSERT INTO oidc_codes
+		(id, app_id, redirect_uri, code_challenge, nonce, sealed_token, expires_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.AppID, c.RedirectURI, c.CodeChallenge, c.Nonce, c.SealedToken, c.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// TakeAuthCode - забирает код авторизации: запись удаляется, второй обмен того же кода получит
+// storage.ErrAuthCodeNotFound. Срок действия не проверяется: это делает вызывающий
+func (s *Storage) TakeAuthCode(ctx context.Context, id string) (models.AuthCode, error) {
+	const op = "storage.sqlite.TakeAuthCode"
+
+	var c models.AuthCode
+	err := s.db.QueryRowContext(ctx, `DELETE FROM oidc_codes WHERE id = ?
+		RETURNING id, app_id, redirect_uri, code_challenge, nonce, sealed_token, expires_at`, id).
+		Scan(&c.ID, &c.AppID, &c.RedirectURI, &c.CodeChallenge, &c.Nonce, &c.SealedToken, &c.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.AuthCode{}, fmt.Errorf("%s: %w", op, storage.ErrAuthCodeNotFound)
+		}
+
+		return models.AuthCode{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return c, nil
+}
+
+// DeleteExpiredAuthCodes - удаляет до limit кодов, истёкших до before. Возвращает количество удалённых
+func (s *Storage) DeleteExpiredAuthCodes(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredAuthCodes"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM oidc_codes WHERE id IN
+		(SELECT id FROM oidc_codes WHERE expires_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppRedirectURIs(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "grafana", "secret")
+	require.NoError(t, err)
+
+	uris, err := s.AppRedirectURIs(ctx, appID)
+	require.NoError(t, err)
+	assert.Empty(t, uris)
+
+	require.NoError(t, s.SetAppRedirectURIs(ctx, appID, []string{"https://b.example/cb", "https://a.example/cb"}))
+	require.NoError(t, s.SetAppRedirectURIs(ctx, appID, []string{"https://c.example/cb", "https://a.example/cb"}))
+
+	uris, err = s.AppRedirectURIs(ctx, appID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://a.example/cb", "https://c.example/cb"}, uris)
+
+	require.ErrorIs(t, s.SetAppRedirectURIs(ctx, appID+100, []string{"https://a.example/cb"}), storage.ErrAppNotFound)
+}
+
+func TestAuthCodes_TakeOnce(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "grafana", "secret")
+	require.NoError(t, err)
+
+	code := models.AuthCode{
+		ID: "hash", AppID: appID, RedirectURI: "https://a.example/cb", CodeChallenge: "challenge",
+		Nonce: "n", SealedToken: []byte{1, 2, 3}, ExpiresAt: time.Now().Add(time.Minute),
+	}
+	require.NoError(t, s.SaveAuthCode(ctx, code))
+	require.NoError(t, s.SaveAuthCode(ctx, models.AuthCode{
+		ID: "old", AppID: appID, SealedToken: []byte{1}, ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+
+	got, err := s.TakeAuthCode(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, code.RedirectURI, got.RedirectURI)
+	assert.Equal(t, code.CodeChallenge, got.CodeChallenge)
+	assert.Equal(t, code.Nonce, got.Nonce)
+	assert.Equal(t, code.SealedToken, got.SealedToken)
+	assert.WithinDuration(t, code.ExpiresAt, got.ExpiresAt, time.Second)
+
+	_, err = s.TakeAuthCode(ctx, "hash")
+	require.ErrorIs(t, err, storage.ErrAuthCodeNotFound)
+
+	n, err := s.DeleteExpiredAuthCodes(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
@@ -0,0 +1,249 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+)
+
+// CreateOrganization - создаёт организацию; slug уже занят - storage.ErrOrgExists.
+func (s *Storage) CreateOrganization(ctx context.Context, slug, name string, allowSelfSignup bool) (int64, error) {
+	const op = "storage.sqlite.CreateOrganization"
+
+	res, err := s.db.ExecContext(ctx,
+		"INSERT INTO organizations(slug, name, allow_self_signup) VALUES(?, ?, ?)", slug, name, allowSelfSignup)
+	if err != nil {
+		if isUnique(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrOrgExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// OrgBySlug - получает организацию по slug.
+func (s *Storage) OrgBySlug(ctx context.Context, slug string) (models.Organization, error) {
+	const op = "storage.sqlite.OrgBySlug"
+
+	var org models.Organization
+	err := s.db.QueryRowContext(ctx,
+		"SELECT id, slug, name, allow_self_signup, created_at FROM organizations WHERE slug = ?", slug).
+		Scan(&org.ID, &org.Slug, &org.Name, &org.AllowSelfSignup, &org.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Organization{}, fmt.Errorf("%s: %w", op, storage.ErrOrgNotFound)
+		}
+
+		return models.Organization{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return org, nil
+}
+
+// ScopedUser - получает пользователя по email из пространства организации scope
+// (при email_uniqueness: organization email уникален только внутри организации).
+func (s *Storage) ScopedUser(ctx context.Context, scope int64, email string) (models.User, error) {
+	const op = "storage.sqlite.ScopedUser"
+
+	user, err := s.userByEmail(ctx, scope, email)
+	if err != nil {
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return user, nil
+}
+
+// SaveOrgUser - сохраняет пользователя в пространстве email scope и сразу добавляет его в организацию с ролью role.
+// Внутри WithinTx выполняется в общей транзакции, иначе - в собственной.
+func (s *Storage) SaveOrgUser(
+	ctx context.Context,
+	email string,
+	passHash []byte,
+	orgID, scope int64,
+	role string,
+) (int64, error) {
+	const op = "storage.sqlite.SaveOrgUser"
+
+	email, index, err := s.sealEmail(email)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	var id int64
+	err = s.WithinTx(ctx, func(ctx context.Context) error {
+		res, err := s.conn(ctx).ExecContext(ctx,
+			"INSERT INTO users(email, email_index, pass_hash, password_changed_at, email_scope, created_at) VALUES(?, ?, ?, CURRENT_TIMESTAMP, ?, CURRENT_TIMESTAMP)",
+			email, index, passHash, scope)
+		if err != nil {
+			if isUnique(err) {
+				return storage.ErrUserExists
+			}
+
+			return err
+		}
+
+		if id, err = res.LastInsertId(); err != nil {
+			return err
+		}
+
+		_, err = s.conn(ctx).ExecContext(ctx,
+			"INSERT INTO org_members(org_id, user_id, role) VALUES(?, ?, ?)", orgID, id, role)
+
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// MemberRole - роль пользователя в организации; не участник - storage.ErrNotMember.
+func (s *Storage) MemberRole(ctx context.Context, orgID, userID int64) (string, error) {
+	const op = "storage.sqlite.MemberRole"
+
+	var role string
+	err := s.db.QueryRowContext(ctx,
+		"SELECT role FROM org_members WHERE org_id = ? AND user_id = ?", orgID, userID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("%s: %w", op, storage.ErrNotMember)
+		}
+
+		return "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return role, nil
+}
+
+// AddMember - добавляет пользователя в организацию или меняет его роль, если он уже участник.
+func (s *Storage) AddMember(ctx context.Context, orgID, userID int64, role string) error {
+	const op = "storage.sqlite.AddMember"
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		// Внешние ключи в SQLite по умолчанию не проверяются, поэтому существование проверяем сами
+		if err := s.checkOrg(ctx, orgID); err != nil {
+			return err
+		}
+
+		var exists bool
+		if err := s.conn(ctx).QueryRowContext(ctx,
+			"SELECT EXISTS (SELECT 1 FROM users WHERE id = ? AND erased_at IS NULL)", userID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrUserNotFound
+		}
+
+		_, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO org_members(org_id, user_id, role) VALUES(?, ?, ?)
+			ON CONFLICT (org_id, user_id) DO UPDATE SET role = excluded.role`, orgID, userID, role)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RemoveMember - исключает пользователя из организации; не участник - storage.ErrNotMember.
+func (s *Storage) RemoveMember(ctx context.Context, orgID, userID int64) error {
+	const op = "storage.sqlite.RemoveMember"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM org_members WHERE org_id = ? AND user_id = ?", orgID, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrNotMember)
+	}
+
+	return nil
+}
+
+// ListMembers - участники организации по возрастанию id пользователя (стёртые не попадают).
+func (s *Storage) ListMembers(ctx context.Context, orgID int64) ([]models.OrgMember, error) {
+	const op = "storage.sqlite.ListMembers"
+
+	if err := s.checkOrg(ctx, orgID); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT u.id, u.email, m.role, m.created_at
+		FROM org_members m JOIN users u ON u.id = m.user_id
+		WHERE m.org_id = ? AND u.erased_at IS NULL
+		ORDER BY u.id`, orgID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var members []models.OrgMember
+	for rows.Next() {
+		var m models.OrgMember
+		if err := rows.Scan(&m.UserID, &m.Email, &m.Role, &m.JoinedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if m.Email, err = s.open(m.Email); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		members = append(members, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return members, nil
+}
+
+// SetSuperadmin - выдаёт или отзывает права администратора всех организаций.
+func (s *Storage) SetSuperadmin(ctx context.Context, userID int64, superadmin bool) error {
+	const op = "storage.sqlite.SetSuperadmin"
+
+	res, err := s.db.ExecContext(ctx, `UPDATE users SET is_superadmin = ?, version = version + 1
+		WHERE id = ? AND erased_at IS NULL`, superadmin, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// checkOrg - storage.ErrOrgNotFound, если организации orgID нет
+func (s *Storage) checkOrg(ctx context.Context, orgID int64) error {
+	var exists bool
+	if err := s.conn(ctx).QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM organizations WHERE id = ?)", orgID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return storage.ErrOrgNotFound
+	}
+
+	return nil
+}
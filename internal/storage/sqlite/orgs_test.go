@@ -0,0 +1,115 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrganizations(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 2)
+	ctx := context.Background()
+
+	orgID, err := s.CreateOrganization(ctx, "acme", "Acme Inc.", true)
+	require.NoError(t, err)
+
+	_, err = s.CreateOrganization(ctx, "acme", "Another Acme", false)
+	require.ErrorIs(t, err, storage.ErrOrgExists)
+
+	org, err := s.OrgBySlug(ctx, "acme")
+	require.NoError(t, err)
+	assert.Equal(t, orgID, org.ID)
+	assert.Equal(t, "Acme Inc.", org.Name)
+	assert.True(t, org.AllowSelfSignup)
+
+	_, err = s.OrgBySlug(ctx, "missing")
+	require.ErrorIs(t, err, storage.ErrOrgNotFound)
+
+	require.NoError(t, s.AddMember(ctx, orgID, ids[0], models.OrgRoleAdmin))
+	require.NoError(t, s.AddMember(ctx, orgID, ids[1], models.OrgRoleMember))
+	// Повторное добавление меняет роль
+	require.NoError(t, s.AddMember(ctx, orgID, ids[1], models.OrgRoleOwner))
+
+	role, err := s.MemberRole(ctx, orgID, ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleOwner, role)
+
+	members, err := s.ListMembers(ctx, orgID)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, "user0@example.com", members[0].Email)
+	assert.Equal(t, models.OrgRoleAdmin, members[0].Role)
+
+	require.ErrorIs(t, s.AddMember(ctx, orgID, 999, models.OrgRoleMember), storage.ErrUserNotFound)
+	require.ErrorIs(t, s.AddMember(ctx, 999, ids[0], models.OrgRoleMember), storage.ErrOrgNotFound)
+	_, err = s.ListMembers(ctx, 999)
+	require.ErrorIs(t, err, storage.ErrOrgNotFound)
+
+	require.NoError(t, s.RemoveMember(ctx, orgID, ids[0]))
+	require.ErrorIs(t, s.RemoveMember(ctx, orgID, ids[0]), storage.ErrNotMember)
+	_, err = s.MemberRole(ctx, orgID, ids[0])
+	require.ErrorIs(t, err, storage.ErrNotMember)
+}
+
+// В разных пространствах email может повторяться, внутри одного - нет
+func TestSaveOrgUser_EmailScopes(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	acme, err := s.CreateOrganization(ctx, "acme", "Acme", false)
+	require.NoError(t, err)
+	globex, err := s.CreateOrganization(ctx, "globex", "Globex", false)
+	require.NoError(t, err)
+
+	globalID, err := s.SaveUser(ctx, "a@b.c", []byte("global"))
+	require.NoError(t, err)
+
+	acmeID, err := s.SaveOrgUser(ctx, "a@b.c", []byte("acme"), acme, acme, models.OrgRoleMember)
+	require.NoError(t, err)
+	_, err = s.SaveOrgUser(ctx, "A@b.c", []byte("acme-2"), acme, acme, models.OrgRoleMember)
+	require.ErrorIs(t, err, storage.ErrUserExists)
+
+	// Общее пространство (email_uniqueness: global): повтор email запрещён в любой организации
+	_, err = s.SaveOrgUser(ctx, "a@b.c", []byte("globex"), globex, 0, models.OrgRoleMember)
+	require.ErrorIs(t, err, storage.ErrUserExists)
+
+	user, err := s.User(ctx, "a@b.c")
+	require.NoError(t, err)
+	assert.Equal(t, globalID, user.ID)
+
+	user, err = s.ScopedUser(ctx, acme, "a@b.c")
+	require.NoError(t, err)
+	assert.Equal(t, acmeID, user.ID)
+	assert.Equal(t, []byte("acme"), user.PassHash)
+
+	_, err = s.ScopedUser(ctx, globex, "a@b.c")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	role, err := s.MemberRole(ctx, acme, acmeID)
+	require.NoError(t, err)
+	assert.Equal(t, models.OrgRoleMember, role)
+
+	// Неудачная регистрация не оставляет участника без пользователя
+	members, err := s.ListMembers(ctx, globex)
+	require.NoError(t, err)
+	assert.Empty(t, members)
+}
+
+func TestSetSuperadmin(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	require.NoError(t, s.SetSuperadmin(ctx, ids[0], true))
+
+	user, err := s.UserByID(ctx, ids[0])
+	require.NoError(t, err)
+	assert.True(t, user.IsSuperadmin)
+
+	require.ErrorIs(t, s.SetSuperadmin(ctx, 999, true), storage.ErrUserNotFound)
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/storage"
+)
+
+// AppOrigins - источники браузерных клиентов приложения (пустой список, если их нет)
+func (s *Storage) AppOrigins(ctx context.Context, appID int) ([]string, error) {
+	const op = "storage.sqlite.AppOrigins"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT origin FROM app_origins WHERE app_id = ? ORDER BY origin", appID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	origins := []string{}
+	for rows.Next() {
+		var origin string
+		if err := rows.Scan(&origin); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		origins = append(origins, origin)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return origins, nil
+}
+
+// SetAppOrigins - заменяет источники браузерных клиентов приложения (storage.ErrAppNotFound, если его нет)
+func (s *Storage) SetAppOrigins(ctx context.Context, appID int, origins []string) error {
+	const op = "storage.sqlite.SetAppOrigins"
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		var exists bool
+		if err := s.conn(ctx).QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM apps WHERE id = ?)", appID).Scan(&exists); err != nil {
+			return err
+		}
+		if !exists {
+			return storage.ErrAppNotFound
+		}
+
+		if _, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM app_origins WHERE app_id = ?", appID); err != nil {
+			return err
+		}
+		for _, origin := range origins {
+			_, err := s.conn(ctx).ExecContext(ctx,
+				"INSERT OR IGNORE INTO app_origins (app_id, origin) VALUES (?, ?)", appID, origin)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppOrigins(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "dashboard", "secret")
+	require.NoError(t, err)
+	otherID, err := s.SaveApp(ctx, "billing", "secret-2")
+	require.NoError(t, err)
+
+	origins, err := s.AppOrigins(ctx, appID)
+	require.NoError(t, err)
+	assert.Empty(t, origins)
+
+	require.NoError(t, s.SetAppOrigins(ctx, appID, []string{"https://b.example.com", "https://*.example.com"}))
+	require.NoError(t, s.SetAppOrigins(ctx, appID, []string{"https://*.example.com", "http://localhost:3000", "http://localhost:3000"}))
+	require.NoError(t, s.SetAppOrigins(ctx, otherID, []string{"https://billing.example.net"}))
+
+	origins, err = s.AppOrigins(ctx, appID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"http://localhost:3000", "https://*.example.com"}, origins)
+
+	origins, err = s.AppOrigins(ctx, otherID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://billing.example.net"}, origins)
+
+	require.ErrorIs(t, s.SetAppOrigins(ctx, appID+100, []string{"https://a.example.com"}), storage.ErrAppNotFound)
+}
@@ -0,0 +1,197 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+// passkeyColumns - столбцы passkey в порядке scanPasskey
+const passkeyColumns = `id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports,
+	backup_eligible, backup_state, name, created_at, last_used_at`
+
+// SavePasskey - сохраняет passkey пользователя и возвращает её id.
+// Учётные данные с таким credential_id уже есть - storage.ErrPasskeyExists
+func (s *Storage) SavePasskey(ctx context.Context, p models.Passkey) (int64, error) {
+	const op = "storage.sqlite.SavePasskey"
+
+	res, err := s.db.ExecContext(ctx, `INSERT INTO passkeys
+		(user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state, name)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		p.UserID, p.CredentialID, p.PublicKey, p.AttestationType, p.AAGUID, p.SignCount,
+		strings.Join(p.Transports, ","), p.BackupEligible, p.BackupState, p.Name)
+	if err != nil {
+		if isUnique(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrPasskeyExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// Passkeys - passkey пользователя в порядке регистрации
+func (s *Storage) Passkeys(ctx context.Context, userID int64) ([]models.Passkey, error) {
+	const op = "storage.sqlite.Passkeys"
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+passkeyColumns+" FROM passkeys WHERE user_id = ? ORDER BY id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var passkeys []models.Passkey
+	for rows.Next() {
+		p, err := scanPasskey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		passkeys = append(passkeys, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return passkeys, nil
+}
+
+// PasskeyByCredentialID - passkey по id учётных данных; нет такой - storage.ErrPasskeyNotFound
+func (s *Storage) PasskeyByCredentialID(ctx context.Context, credentialID []byte) (models.Passkey, error) {
+	const op = "storage.sqlite.PasskeyByCredentialID"
+
+	p, err := scanPasskey(s.db.QueryRowContext(ctx,
+		"SELECT "+passkeyColumns+" FROM passkeys WHERE credential_id = ?", credentialID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Passkey{}, fmt.Errorf("%s: %w", op, storage.ErrPasskeyNotFound)
+		}
+
+		return models.Passkey{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return p, nil
+}
+
+// UpdatePasskeyUsage - записывает счётчик подписей и состояние синхронизации после входа
+func (s *Storage) UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, usedAt time.Time) error {
+	const op = "storage.sqlite.UpdatePasskeyUsage"
+
+	res, err := s.db.ExecContext(ctx,
+		"UPDATE passkeys SET sign_count = ?, backup_state = ?, last_used_at = ? WHERE id = ?",
+		signCount, backupState, usedAt.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrPasskeyNotFound)
+	}
+
+	return nil
+}
+
+// DeletePasskey - удаляет passkey id пользователя userID; чужая или несуществующая - storage.ErrPasskeyNotFound
+func (s *Storage) DeletePasskey(ctx context.Context, userID, id int64) error {
+	const op = "storage.sqlite.DeletePasskey"
+
+	res, err := s.db.ExecContext(ctx, "DELETE FROM passkeys WHERE id = ? AND user_id = ?", id, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrPasskeyNotFound)
+	}
+
+	return nil
+}
+
+// SaveWebAuthnSession - сохраняет состояние начатой церемонии
+func (s *Storage) SaveWebAuthnSession(ctx context.Context, session models.WebAuthnSession) error {
+	const op = "storage.sqlite.SaveWebAuthnSession"
+
+	if _, err := s.db.ExecContext(ctx,
+		"INSERT INTO webauthn_sessions (id, user_id, ceremony, data, expires_at) VALUES(?, ?, ?, ?, ?)",
+		session.ID, session.UserID, session.Ceremony, session.Data, session.ExpiresAt.UTC()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// TakeWebAuthnSession - возвращает и удаляет состояние церемонии: повторить Finish* с тем же nonce нельзя.
+// Нет такой (или её уже забрали) - storage.ErrWebAuthnSessionNotFound. Срок действия проверяет вызывающий
+func (s *Storage) TakeWebAuthnSession(ctx context.Context, id string) (models.WebAuthnSession, error) {
+	const op = "storage.sqlite.TakeWebAuthnSession"
+
+	var session models.WebAuthnSession
+	err := s.db.QueryRowContext(ctx,
+		"DELETE FROM webauthn_sessions WHERE id = ? RETURNING id, user_id, ceremony, data, expires_at", id).
+		Scan(&session.ID, &session.UserID, &session.Ceremony, &session.Data, &session.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.WebAuthnSession{}, fmt.Errorf("%s: %w", op, storage.ErrWebAuthnSessionNotFound)
+		}
+
+		return models.WebAuthnSession{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return session, nil
+}
+
+// DeleteExpiredWebAuthnSessions - удаляет до limit церемоний, истёкших до before. Возвращает количество удалённых
+func (s *Storage) DeleteExpiredWebAuthnSessions(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredWebAuthnSessions"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM webauthn_sessions WHERE id IN
+		(SELECT id FROM webauthn_sessions WHERE expires_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+func scanPasskey(row interface{ Scan(dest ...any) error }) (models.Passkey, error) {
+	var (
+		p          models.Passkey
+		transports string
+		lastUsedAt sql.NullTime
+	)
+	if err := row.Scan(&p.ID, &p.UserID, &p.CredentialID, &p.PublicKey, &p.AttestationType, &p.AAGUID, &p.SignCount,
+		&transports, &p.BackupEligible, &p.BackupState, &p.Name, &p.CreatedAt, &lastUsedAt); err != nil {
+		return models.Passkey{}, err
+	}
+
+	if transports != "" {
+		p.Transports = strings.Split(transports, ",")
+	}
+	p.LastUsedAt = lastUsedAt.Time
+
+	return p, nil
+}
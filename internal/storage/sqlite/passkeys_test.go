@@ -0,0 +1,93 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasskeys_Lifecycle(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 2)
+	ctx := context.Background()
+
+	id, err := s.SavePasskey(ctx, models.Passkey{
+		UserID: ids[0], CredentialID: []byte{1, 2, 3}, PublicKey: []byte{4}, AttestationType: "none",
+		SignCount: 1, Transports: []string{"usb", "nfc"}, BackupEligible: true, Name: "YubiKey",
+	})
+	require.NoError(t, err)
+
+	_, err = s.SavePasskey(ctx, models.Passkey{UserID: ids[1], CredentialID: []byte{1, 2, 3}, PublicKey: []byte{5}})
+	require.ErrorIs(t, err, storage.ErrPasskeyExists)
+
+	p, err := s.PasskeyByCredentialID(ctx, []byte{1, 2, 3})
+	require.NoError(t, err)
+	assert.Equal(t, id, p.ID)
+	assert.Equal(t, ids[0], p.UserID)
+	assert.Equal(t, []string{"usb", "nfc"}, p.Transports)
+	assert.Equal(t, "YubiKey", p.Name)
+	assert.True(t, p.BackupEligible)
+	assert.True(t, p.LastUsedAt.IsZero())
+
+	usedAt := time.Now()
+	require.NoError(t, s.UpdatePasskeyUsage(ctx, id, 9, true, usedAt))
+
+	passkeys, err := s.Passkeys(ctx, ids[0])
+	require.NoError(t, err)
+	require.Len(t, passkeys, 1)
+	assert.Equal(t, uint32(9), passkeys[0].SignCount)
+	assert.True(t, passkeys[0].BackupState)
+	assert.WithinDuration(t, usedAt, passkeys[0].LastUsedAt, time.Second)
+
+	// Чужую passkey удалить нельзя
+	require.ErrorIs(t, s.DeletePasskey(ctx, ids[1], id), storage.ErrPasskeyNotFound)
+	require.NoError(t, s.DeletePasskey(ctx, ids[0], id))
+
+	_, err = s.PasskeyByCredentialID(ctx, []byte{1, 2, 3})
+	require.ErrorIs(t, err, storage.ErrPasskeyNotFound)
+}
+
+func TestWebAuthnSessions_TakeOnce(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.SaveWebAuthnSession(ctx, models.WebAuthnSession{
+		ID: "nonce", UserID: 7, Ceremony: models.CeremonyLogin, Data: []byte(`{}`), ExpiresAt: time.Now().Add(time.Minute),
+	}))
+	require.NoError(t, s.SaveWebAuthnSession(ctx, models.WebAuthnSession{
+		ID: "old", Ceremony: models.CeremonyLogin, Data: []byte(`{}`), ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+
+	session, err := s.TakeWebAuthnSession(ctx, "nonce")
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), session.UserID)
+	assert.Equal(t, models.CeremonyLogin, session.Ceremony)
+	assert.Equal(t, []byte(`{}`), session.Data)
+
+	_, err = s.TakeWebAuthnSession(ctx, "nonce")
+	require.ErrorIs(t, err, storage.ErrWebAuthnSessionNotFound)
+
+	n, err := s.DeleteExpiredWebAuthnSessions(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestEraseUser_DeletesPasskeys(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	_, err := s.SavePasskey(ctx, models.Passkey{UserID: ids[0], CredentialID: []byte{1}, PublicKey: []byte{2}})
+	require.NoError(t, err)
+
+	require.NoError(t, s.EraseUser(ctx, ids[0], "erased@example.invalid"))
+
+	passkeys, err := s.Passkeys(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Empty(t, passkeys)
+}
@@ -0,0 +1,304 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/lib/pii"
+	"strings"
+	"time"
+)
+
+// ErrPIINotEncrypted - ключ pii_key задан, а БД не зашифрована им (или шифрование не закончено)
+var ErrPIINotEncrypted = errors.New("database is not encrypted with pii_key: run `admin encrypt-pii` first")
+
+// ErrPIIKeyRequired - БД зашифрована, а ключ pii_key не задан
+var ErrPIIKeyRequired = errors.New("database is encrypted: pii_key is required")
+
+// Option - необязательная настройка Storage
+type Option func(s *Storage)
+
+// WithPII - хранить email и телефоны зашифрованными ключом c (email ищется по слепому индексу).
+// Перед запуском с ключом существующую БД нужно зашифровать (EncryptPII, admin encrypt-pii)
+func WithPII(c *pii.Cipher) Option {
+	return func(s *Storage) {
+		s.pii = c
+	}
+}
+
+// sealEmail - email для записи в колонку email и его слепой индекс (без ключа - как есть и NULL)
+func (s *Storage) sealEmail(email string) (string, any, error) {
+	if s.pii == nil {
+		return email, nil, nil
+	}
+
+	enc, err := s.pii.Encrypt(email)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return enc, s.pii.Index(email), nil
+}
+
+// seal - значение для записи в шифруемую колонку (без ключа - как есть)
+func (s *Storage) seal(v string) (string, error) {
+	if s.pii == nil {
+		return v, nil
+	}
+
+	return s.pii.Encrypt(v)
+}
+
+// open - значение из шифруемой колонки (без ключа - как есть)
+func (s *Storage) open(v string) (string, error) {
+	if s.pii == nil {
+		return v, nil
+	}
+
+	return s.pii.Decrypt(v)
+}
+
+// openUser - расшифровывает email и телефон прочитанного пользователя
+func (s *Storage) openUser(u *models.User) error {
+	var err error
+	if u.Email, err = s.open(u.Email); err != nil {
+		return fmt.Errorf("user %d email: %w", u.ID, err)
+	}
+	if u.Phone, err = s.open(u.Phone); err != nil {
+		return fmt.Errorf("user %d phone: %w", u.ID, err)
+	}
+
+	return nil
+}
+
+// emailEquals - условие поиска по email без учёта регистра и его аргумент: без ключа - по lower(email),
+// с ключом - по слепому индексу. column - колонка email с псевдонимом таблицы, если он нужен
+func (s *Storage) emailEquals(column, email string) (string, any) {
+	if s.pii == nil {
+		return "lower(" + column + ") = lower(?)", email
+	}
+
+	return strings.TrimSuffix(column, "email") + "email_index = ?", s.pii.Index(email)
+}
+
+// phoneKey - номер для журнала отправок SMS: там он нужен только для подсчёта, поэтому с ключом
+// хранится слепой индекс, а не шифртекст
+func (s *Storage) phoneKey(phone string) string {
+	if s.pii == nil {
+		return phone
+	}
+
+	return s.pii.Index(phone)
+}
+
+// CheckPII - соответствует ли БД настройке шифрования: с ключом БД должна быть полностью зашифрована
+// этим ключом (иначе ErrPIINotEncrypted), без ключа - не зашифрована вовсе (иначе ErrPIIKeyRequired).
+// Вызывается при запуске, чтобы не читать шифртекст как email и не смешивать зашифрованные строки с открытыми
+func (s *Storage) CheckPII(ctx context.Context) error {
+	const op = "storage.sqlite.CheckPII"
+
+	var (
+		keyID     string
+		completed sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, "SELECT key_id, completed_at FROM pii_encryption WHERE id = 1").Scan(&keyID, &completed)
+	switch {
+	case errors.Is(err, sql.ErrNoRows) || isNoSuchTable(err):
+		if s.pii != nil {
+			return fmt.Errorf("%s: %w", op, ErrPIINotEncrypted)
+		}
+
+		return nil
+	case err != nil:
+		return fmt.Errorf("%s: %w", op, err)
+	case s.pii == nil:
+		return fmt.Errorf("%s: %w", op, ErrPIIKeyRequired)
+	case keyID != s.pii.KeyID():
+		return fmt.Errorf("%s: database is encrypted with another key (key id %s, pii_key has %s)", op, keyID, s.pii.KeyID())
+	case !completed.Valid:
+		return fmt.Errorf("%s: %w (encryption is not finished)", op, ErrPIINotEncrypted)
+	}
+
+	return nil
+}
+
+func isNoSuchTable(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "no such table")
+}
+
+// PIIProgress - ход шифрования одной таблицы
+type PIIProgress struct {
+	Table string
+	Done  int // зашифровано строк в этом запуске
+	Total int // строк, которые нужно было зашифровать в начале запуска
+}
+
+// EncryptPII - шифрует ключом хранилища (WithPII) email и телефоны существующих строк пачками по batch,
+// каждая пачка - отдельная транзакция; после каждой вызывается progress. Прерванное шифрование
+// продолжается повторным запуском с тем же ключом (зашифрованные строки уже имеют email_index).
+// Журнал отправок SMS и незавершённые вызовы с кодом из SMS живут минуты и удаляются, а не шифруются.
+// По окончании БД помечается зашифрованной, и сервис с этим ключом проходит CheckPII
+func (s *Storage) EncryptPII(ctx context.Context, batch int, progress func(PIIProgress)) error {
+	const op = "storage.sqlite.EncryptPII"
+
+	if s.pii == nil {
+		return fmt.Errorf("%s: pii key is not set", op)
+	}
+	if batch <= 0 {
+		return fmt.Errorf("%s: batch must be positive", op)
+	}
+
+	var (
+		keyID     string
+		completed sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, "SELECT key_id, completed_at FROM pii_encryption WHERE id = 1").Scan(&keyID, &completed)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if _, err := s.db.ExecContext(ctx, "INSERT INTO pii_encryption(id, key_id, started_at) VALUES(1, ?, ?)",
+			s.pii.KeyID(), time.Now().UTC()); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	case err != nil:
+		return fmt.Errorf("%s: %w", op, err)
+	case keyID != s.pii.KeyID():
+		return fmt.Errorf("%s: encryption was started with another key (key id %s)", op, keyID)
+	}
+
+	for _, query := range []string{"DELETE FROM sms_challenges", "DELETE FROM sms_sends"} {
+		if _, err := s.db.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	tables := []struct {
+		name   string
+		encode func(ctx context.Context, limit int) (int, error)
+	}{
+		{name: "users", encode: s.encryptUsers},
+		{name: "invitations", encode: s.encryptInvitations},
+	}
+	for _, t := range tables {
+		var total int
+		if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM "+t.name+" WHERE email_index IS NULL").
+			Scan(&total); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+
+		p := PIIProgress{Table: t.name, Total: total}
+		for {
+			n, err := t.encode(ctx, batch)
+			if err != nil {
+				return fmt.Errorf("%s: %s: %w", op, t.name, err)
+			}
+			if n == 0 {
+				break
+			}
+			p.Done += n
+			if progress != nil {
+				progress(p)
+			}
+		}
+	}
+
+	if _, err := s.db.ExecContext(ctx, "UPDATE pii_encryption SET completed_at = ? WHERE id = 1 AND completed_at IS NULL",
+		time.Now().UTC()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// encryptUsers - шифрует до limit ещё не зашифрованных пользователей
+func (s *Storage) encryptUsers(ctx context.Context, limit int) (int, error) {
+	var n int
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		rows, err := s.conn(ctx).QueryContext(ctx,
+			"SELECT id, email, phone FROM users WHERE email_index IS NULL ORDER BY id LIMIT ?", limit)
+		if err != nil {
+			return err
+		}
+
+		var users []models.User
+		for rows.Next() {
+			var u models.User
+			if err := rows.Scan(&u.ID, &u.Email, &u.Phone); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			users = append(users, u)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, u := range users {
+			email, index, err := s.sealEmail(u.Email)
+			if err != nil {
+				return err
+			}
+			phone, err := s.seal(u.Phone)
+			if err != nil {
+				return err
+			}
+			if _, err := s.conn(ctx).ExecContext(ctx,
+				"UPDATE users SET email = ?, email_index = ?, phone = ? WHERE id = ?", email, index, phone, u.ID); err != nil {
+				return err
+			}
+		}
+		n = len(users)
+
+		return nil
+	})
+
+	return n, err
+}
+
+// encryptInvitations - шифрует до limit ещё не зашифрованных приглашений
+func (s *Storage) encryptInvitations(ctx context.Context, limit int) (int, error) {
+	var n int
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		rows, err := s.conn(ctx).QueryContext(ctx,
+			"SELECT id, email FROM invitations WHERE email_index IS NULL ORDER BY id LIMIT ?", limit)
+		if err != nil {
+			return err
+		}
+
+		var invs []models.Invitation
+		for rows.Next() {
+			var inv models.Invitation
+			if err := rows.Scan(&inv.ID, &inv.Email); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			invs = append(invs, inv)
+		}
+		if err := rows.Close(); err != nil {
+			return err
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, inv := range invs {
+			email, index, err := s.sealEmail(inv.Email)
+			if err != nil {
+				return err
+			}
+			if _, err := s.conn(ctx).ExecContext(ctx,
+				"UPDATE invitations SET email = ?, email_index = ? WHERE id = ?", email, index, inv.ID); err != nil {
+				return err
+			}
+		}
+		n = len(invs)
+
+		return nil
+	})
+
+	return n, err
+}
@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/lib/pii"
+	"sso/internal/storage"
+	"sso/internal/storage/storagetest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testPIICipher(t *testing.T, b byte) *pii.Cipher {
+	t.Helper()
+
+	c, err := pii.New(bytes.Repeat([]byte{b}, pii.KeySize))
+	require.NoError(t, err)
+
+	return c
+}
+
+func TestConformance_EncryptedPII(t *testing.T) {
+	c := testPIICipher(t, 1)
+
+	storagetest.Run(t, func(t *testing.T) storagetest.Storage {
+		return newTestStorage(t, WithPII(c))
+	})
+}
+
+func TestEncryptPII(t *testing.T) {
+	ctx := context.Background()
+	plain := newTestStorage(t)
+
+	ids := seedUsers(t, plain, 5)
+	require.NoError(t, plain.SetUserPhone(ctx, ids[0], "+15550001"))
+	_, _, err := plain.SaveInvitation(ctx, models.Invitation{
+		Email: "Invited@Example.com", TokenHash: "hash", AppID: 1, Role: models.RoleUser,
+		InvitedBy: ids[0], ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.NoError(t, plain.CheckPII(ctx))
+
+	enc := &Storage{db: plain.db, pii: testPIICipher(t, 1)}
+	require.ErrorIs(t, enc.CheckPII(ctx), ErrPIINotEncrypted)
+
+	// Прерванный запуск: шифруется только первая пачка
+	interrupted, cancel := context.WithCancel(ctx)
+	err = enc.EncryptPII(interrupted, 2, func(PIIProgress) { cancel() })
+	require.Error(t, err)
+	require.ErrorIs(t, enc.CheckPII(ctx), ErrPIINotEncrypted)
+
+	var progress []PIIProgress
+	require.NoError(t, enc.EncryptPII(ctx, 2, func(p PIIProgress) { progress = append(progress, p) }))
+	assert.Equal(t, []PIIProgress{
+		{Table: "users", Done: 2, Total: 3},
+		{Table: "users", Done: 3, Total: 3},
+		{Table: "invitations", Done: 1, Total: 1},
+	}, progress)
+	require.NoError(t, enc.CheckPII(ctx))
+	require.ErrorIs(t, plain.CheckPII(ctx), ErrPIIKeyRequired)
+	require.ErrorContains(t, (&Storage{db: plain.db, pii: testPIICipher(t, 2)}).CheckPII(ctx), "another key")
+
+	// В колонках шифртекст, через хранилище - исходные значения
+	var rawEmail, rawPhone string
+	require.NoError(t, plain.db.QueryRowContext(ctx, "SELECT email, phone FROM users WHERE id = ?", ids[0]).
+		Scan(&rawEmail, &rawPhone))
+	assert.True(t, pii.Encrypted(rawEmail))
+	assert.True(t, pii.Encrypted(rawPhone))
+
+	u, err := enc.User(ctx, "USER0@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, ids[0], u.ID)
+	assert.Equal(t, "user0@example.com", u.Email)
+	assert.Equal(t, "+15550001", u.Phone)
+
+	_, err = enc.SaveUser(ctx, "User1@Example.com", []byte("hash"))
+	require.ErrorIs(t, err, storage.ErrUserExists, "uniqueness is case-insensitive through the blind index")
+
+	_, renewed, err := enc.SaveInvitation(ctx, models.Invitation{
+		Email: "invited@example.com", TokenHash: "hash2", AppID: 1, Role: models.RoleUser,
+		InvitedBy: ids[0], ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.True(t, renewed)
+
+	// Повторный запуск ничего не шифрует заново
+	progress = nil
+	require.NoError(t, enc.EncryptPII(ctx, 2, func(p PIIProgress) { progress = append(progress, p) }))
+	assert.Empty(t, progress)
+}
+
+func TestCheckPII_FreshDatabase(t *testing.T) {
+	ctx := context.Background()
+	s := newTestStorage(t, WithPII(testPIICipher(t, 1)))
+
+	require.ErrorIs(t, s.CheckPII(ctx), ErrPIINotEncrypted)
+	require.NoError(t, s.EncryptPII(ctx, 100, nil))
+	require.NoError(t, s.CheckPII(ctx))
+
+	id, err := s.SaveUser(ctx, "a@b.c", []byte("hash"))
+	require.NoError(t, err)
+	u, err := s.UserByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "a@b.c", u.Email)
+}
@@ -0,0 +1,203 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+const provisionedUserColumns = "id, external_id, email, provisioned_attributes, is_active, provisioned_at"
+
+// ProvisionedUser - пользователь по id во внешней системе; нет такого (или он стёрт) - storage.ErrUserNotFound.
+func (s *Storage) ProvisionedUser(ctx context.Context, externalID string) (models.ProvisionedUser, error) {
+	const op = "storage.sqlite.ProvisionedUser"
+
+	u, err := s.scanProvisionedUser(s.conn(ctx).QueryRowContext(ctx, "SELECT "+provisionedUserColumns+
+		" FROM users WHERE external_id = ? AND erased_at IS NULL", externalID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.ProvisionedUser{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+
+		return models.ProvisionedUser{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return u, nil
+}
+
+// SaveProvisionedUser - создаёт активного пользователя без пароля с подтверждённым email.
+// Email уже занят - storage.ErrUserExists, external id уже занят - storage.ErrExternalIDExists
+func (s *Storage) SaveProvisionedUser(ctx context.Context, u models.ProvisionedUser) (int64, error) {
+	const op = "storage.sqlite.SaveProvisionedUser"
+
+	email, index, err := s.sealEmail(u.Email)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	attrs, err := json.Marshal(u.Attributes)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO users(email, email_index, pass_hash, email_verified,
+		external_id, provisioned_attributes, provisioned_at, created_at)
+		VALUES(?, ?, X'', TRUE, ?, ?, ?, CURRENT_TIMESTAMP)`, email, index, u.ExternalID, attrs, u.ProvisionedAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, provisioningUniqueErr(err))
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return id, nil
+}
+
+// UpdateProvisionedUser - меняет email и атрибуты пользователя u.UserID и снова включает его.
+// Email занят другим пользователем - storage.ErrUserExists
+func (s *Storage) UpdateProvisionedUser(ctx context.Context, u models.ProvisionedUser) error {
+	const op = "storage.sqlite.UpdateProvisionedUser"
+
+	email, index, err := s.sealEmail(u.Email)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	attrs, err := json.Marshal(u.Attributes)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users
+		SET email = ?, email_index = ?, provisioned_attributes = ?, provisioned_at = ?, is_active = TRUE, version = version + 1
+		WHERE id = ? AND erased_at IS NULL`, email, index, attrs, u.ProvisionedAt.UTC(), u.UserID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, provisioningUniqueErr(err))
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// DeprovisionUser - отключает пользователя с id externalID во внешней системе и в момент at отзывает
+// его сессии и offline-токены. Возвращает id пользователя; нет такого - storage.ErrUserNotFound.
+// Повторный вызов для отключённого пользователя только обновляет provisioned_at
+func (s *Storage) DeprovisionUser(ctx context.Context, externalID string, at time.Time) (int64, error) {
+	const op = "storage.sqlite.DeprovisionUser"
+
+	var userID int64
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.conn(ctx).QueryRowContext(ctx,
+			"SELECT id FROM users WHERE external_id = ? AND erased_at IS NULL", externalID).Scan(&userID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return storage.ErrUserNotFound
+			}
+
+			return err
+		}
+
+		for _, q := range []struct {
+			query string
+			args  []any
+		}{
+			{"UPDATE users SET is_active = FALSE, provisioned_at = ?, version = version + 1 WHERE id = ?", []any{at.UTC(), userID}},
+			{"UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", []any{at.UTC(), userID}},
+			{"UPDATE offline_tokens SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", []any{at.UTC(), userID}},
+			{"UPDATE refresh_families SET revoked_at = ? WHERE user_id = ? AND revoked_at IS NULL", []any{at.UTC(), userID}},
+		} {
+			if _, err := s.conn(ctx).ExecContext(ctx, q.query, q.args...); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return userID, nil
+}
+
+// ProvisionedUsers - пользователи из внешней системы, которых провижининг менял не раньше since
+// (включая отключённых), по возрастанию id, начиная после afterID; не больше limit.
+func (s *Storage) ProvisionedUsers(
+	ctx context.Context,
+	since time.Time,
+	afterID int64,
+	limit int,
+) ([]models.ProvisionedUser, error) {
+	const op = "storage.sqlite.ProvisionedUsers"
+
+	rows, err := s.conn(ctx).QueryContext(ctx, "SELECT "+provisionedUserColumns+` FROM users
+		WHERE external_id IS NOT NULL AND erased_at IS NULL AND provisioned_at >= ? AND id > ?
+		ORDER BY id LIMIT ?`, since.UTC(), afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var res []models.ProvisionedUser
+	for rows.Next() {
+		u, err := s.scanProvisionedUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+func (s *Storage) scanProvisionedUser(row interface{ Scan(dest ...any) error }) (models.ProvisionedUser, error) {
+	var (
+		u             models.ProvisionedUser
+		attrs         string
+		provisionedAt sql.NullTime
+	)
+	if err := row.Scan(&u.UserID, &u.ExternalID, &u.Email, &attrs, &u.IsActive, &provisionedAt); err != nil {
+		return models.ProvisionedUser{}, err
+	}
+	u.ProvisionedAt = provisionedAt.Time
+
+	var err error
+	if u.Email, err = s.open(u.Email); err != nil {
+		return models.ProvisionedUser{}, fmt.Errorf("user %d email: %w", u.UserID, err)
+	}
+	if err := json.Unmarshal([]byte(attrs), &u.Attributes); err != nil {
+		return models.ProvisionedUser{}, fmt.Errorf("user %d attributes: %w", u.UserID, err)
+	}
+
+	return u, nil
+}
+
+// provisioningUniqueErr - нарушение UNIQUE при записи пользователя из внешней системы: занят external id
+// (storage.ErrExternalIDExists) или email (storage.ErrUserExists)
+func provisioningUniqueErr(err error) error {
+	if !isUnique(err) {
+		return err
+	}
+	if strings.Contains(err.Error(), "external_id") {
+		return storage.ErrExternalIDExists
+	}
+
+	return storage.ErrUserExists
+}
@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisionedUsers(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	_, err := s.ProvisionedUser(ctx, "emp-1")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	id, err := s.SaveProvisionedUser(ctx, models.ProvisionedUser{
+		ExternalID: "emp-1", Email: "emp@corp.io", Attributes: map[string]string{"dept": "sales"}, ProvisionedAt: now,
+	})
+	require.NoError(t, err)
+
+	u, err := s.ProvisionedUser(ctx, "emp-1")
+	require.NoError(t, err)
+	assert.Equal(t, id, u.UserID)
+	assert.Equal(t, "emp@corp.io", u.Email)
+	assert.Equal(t, map[string]string{"dept": "sales"}, u.Attributes)
+	assert.True(t, u.IsActive)
+	assert.True(t, now.Equal(u.ProvisionedAt))
+
+	// Пользователь без пароля с подтверждённым email
+	user, err := s.UserByID(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, user.EmailVerified)
+
+	// Занятый email и занятый external id различаются
+	_, err = s.SaveUser(ctx, "self@corp.io", []byte("hash"))
+	require.NoError(t, err)
+	_, err = s.SaveProvisionedUser(ctx, models.ProvisionedUser{ExternalID: "emp-2", Email: "SELF@corp.io", ProvisionedAt: now})
+	require.ErrorIs(t, err, storage.ErrUserExists)
+	_, err = s.SaveProvisionedUser(ctx, models.ProvisionedUser{ExternalID: "emp-1", Email: "other@corp.io", ProvisionedAt: now})
+	require.ErrorIs(t, err, storage.ErrExternalIDExists)
+
+	u.Email = "self@corp.io"
+	require.ErrorIs(t, s.UpdateProvisionedUser(ctx, u), storage.ErrUserExists)
+
+	// Отключение отзывает сессии
+	app, err := s.SaveApp(ctx, "web", "secret")
+	require.NoError(t, err)
+	_, err = s.CreateSession(ctx, models.Session{ID: "sid", UserID: id, AppID: app, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}, 0, false)
+	require.NoError(t, err)
+
+	deprovisionedAt := now.Add(time.Minute)
+	got, err := s.DeprovisionUser(ctx, "emp-1", deprovisionedAt)
+	require.NoError(t, err)
+	assert.Equal(t, id, got)
+
+	u, err = s.ProvisionedUser(ctx, "emp-1")
+	require.NoError(t, err)
+	assert.False(t, u.IsActive)
+	session, err := s.Session(ctx, "sid")
+	require.NoError(t, err)
+	assert.False(t, session.RevokedAt.IsZero())
+
+	_, err = s.DeprovisionUser(ctx, "ghost", deprovisionedAt)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	// Повторный провижининг снова включает пользователя
+	u.Email = "emp@corp.io"
+	u.Attributes = map[string]string{"dept": "support"}
+	u.ProvisionedAt = now.Add(2 * time.Minute)
+	require.NoError(t, s.UpdateProvisionedUser(ctx, u))
+
+	// Сверка видит только пользователей провижининга, изменённых не раньше since
+	list, err := s.ProvisionedUsers(ctx, now.Add(time.Minute), 0, 10)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, id, list[0].UserID)
+	assert.True(t, list[0].IsActive)
+	assert.Equal(t, "support", list[0].Attributes["dept"])
+
+	list, err = s.ProvisionedUsers(ctx, now.Add(3*time.Minute), 0, 10)
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	// Обезличивание освобождает external id
+	require.NoError(t, s.EraseUser(ctx, id, "erased@erased.invalid"))
+	_, err = s.ProvisionedUser(ctx, "emp-1")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.SaveProvisionedUser(ctx, models.ProvisionedUser{ExternalID: "emp-1", Email: "emp@corp.io", ProvisionedAt: now})
+	require.NoError(t, err)
+}
+
+func TestSetAppProvisioner(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.SaveApp(ctx, "hr", "secret")
+	require.NoError(t, err)
+
+	require.NoError(t, s.SetAppProvisioner(ctx, id, true))
+	app, err := s.App(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, app.Provisioner)
+
+	require.ErrorIs(t, s.SetAppProvisioner(ctx, id+1, true), storage.ErrAppNotFound)
+}
@@ -0,0 +1,126 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+const refreshFamilyColumns = `id, user_id, app_id, session_id, secret_hash, scope, org_id, amr, acr, auth_time,
+	created_at, expires_at, absolute_expires_at, revoked_at`
+
+// SaveRefreshFamily - сохраняет цепочку refresh-токенов нового входа.
+func (s *Storage) SaveRefreshFamily(ctx context.Context, f models.RefreshFamily) error {
+	const op = "storage.sqlite.SaveRefreshFamily"
+
+	var authTime sql.NullTime
+	if !f.AuthTime.IsZero() {
+		authTime = sql.NullTime{Time: f.AuthTime.UTC(), Valid: true}
+	}
+
+	_, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO refresh_families (id, user_id, app_id, session_id, secret_hash,
+		scope, org_id, amr, acr, auth_time, created_at, expires_at, absolute_expires_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, f.ID, f.UserID, f.AppID, f.SessionID, f.SecretHash,
+		strings.Join(f.Scopes, " "), f.OrgID, strings.Join(f.AMR, " "), f.ACR, authTime,
+		f.CreatedAt.UTC(), f.ExpiresAt.UTC(), f.AbsoluteExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RefreshFamily - цепочка по id, включая отозванные и истёкшие; нет такой - storage.ErrRefreshFamilyNotFound.
+func (s *Storage) RefreshFamily(ctx context.Context, id string) (models.RefreshFamily, error) {
+	const op = "storage.sqlite.RefreshFamily"
+
+	f, err := scanRefreshFamily(s.conn(ctx).QueryRowContext(ctx,
+		"SELECT "+refreshFamilyColumns+" FROM refresh_families WHERE id = ?", id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.RefreshFamily{}, fmt.Errorf("%s: %w", op, storage.ErrRefreshFamilyNotFound)
+		}
+
+		return models.RefreshFamily{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return f, nil
+}
+
+// RotateRefreshToken - заменяет секрет действующей цепочки id с oldHash на newHash и сдвигает её срок на expiresAt.
+// Секрет уже заменён другим обновлением или цепочка отозвана - storage.ErrRefreshFamilyNotFound
+func (s *Storage) RotateRefreshToken(ctx context.Context, id, oldHash, newHash string, expiresAt time.Time) error {
+	const op = "storage.sqlite.RotateRefreshToken"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE refresh_families SET secret_hash = ?, expires_at = ?
+		WHERE id = ? AND secret_hash = ? AND revoked_at IS NULL`, newHash, expiresAt.UTC(), id, oldHash)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrRefreshFamilyNotFound)
+	}
+
+	return nil
+}
+
+// RevokeRefreshFamily - отзывает в момент at цепочку id; уже отозванная остаётся как есть.
+func (s *Storage) RevokeRefreshFamily(ctx context.Context, id string, at time.Time) error {
+	const op = "storage.sqlite.RevokeRefreshFamily"
+
+	_, err := s.conn(ctx).ExecContext(ctx, `UPDATE refresh_families SET revoked_at = ?
+		WHERE id = ? AND revoked_at IS NULL`, at.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredRefreshFamilies - удаляет до limit цепочек, истёкших до before (и отозванных, и нет).
+// Возвращает количество удалённых
+func (s *Storage) DeleteExpiredRefreshFamilies(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredRefreshFamilies"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM refresh_families WHERE id IN
+		(SELECT id FROM refresh_families WHERE expires_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+func scanRefreshFamily(row interface{ Scan(dest ...any) error }) (models.RefreshFamily, error) {
+	var (
+		f                 models.RefreshFamily
+		scope, amr        string
+		authTime, revoked sql.NullTime
+	)
+	err := row.Scan(&f.ID, &f.UserID, &f.AppID, &f.SessionID, &f.SecretHash, &scope, &f.OrgID, &amr,
+		&f.ACR, &authTime, &f.CreatedAt, &f.ExpiresAt, &f.AbsoluteExpiresAt, &revoked)
+	if err != nil {
+		return models.RefreshFamily{}, err
+	}
+	f.Scopes = strings.Fields(scope)
+	f.AMR = strings.Fields(amr)
+	f.AuthTime = authTime.Time
+	f.RevokedAt = revoked.Time
+
+	return f, nil
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshFamilies(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+
+	family := models.RefreshFamily{
+		ID: "fam", UserID: ids[0], AppID: 1, SessionID: "sid", SecretHash: "h1",
+		Scopes: []string{"files.read"}, OrgID: 7,
+		AMR: []string{"pwd", "mfa"}, ACR: models.ACRMultiFactor, AuthTime: now.Add(-time.Minute),
+		CreatedAt: now, ExpiresAt: now.Add(time.Hour), AbsoluteExpiresAt: now.Add(24 * time.Hour),
+	}
+	require.NoError(t, s.SaveRefreshFamily(ctx, family))
+
+	got, err := s.RefreshFamily(ctx, "fam")
+	require.NoError(t, err)
+	assert.Equal(t, family.Scopes, got.Scopes)
+	assert.Equal(t, family.AMR, got.AMR)
+	assert.Equal(t, int64(7), got.OrgID)
+	assert.True(t, family.AuthTime.Equal(got.AuthTime))
+	assert.True(t, family.AbsoluteExpiresAt.Equal(got.AbsoluteExpiresAt))
+	assert.True(t, got.Active(now))
+
+	_, err = s.RefreshFamily(ctx, "missing")
+	require.ErrorIs(t, err, storage.ErrRefreshFamilyNotFound)
+
+	// Секрет заменяется только один раз: повтор со старым хешем - уже заменённый токен
+	require.NoError(t, s.RotateRefreshToken(ctx, "fam", "h1", "h2", now.Add(2*time.Hour)))
+	require.ErrorIs(t, s.RotateRefreshToken(ctx, "fam", "h1", "h3", now.Add(3*time.Hour)), storage.ErrRefreshFamilyNotFound)
+
+	got, err = s.RefreshFamily(ctx, "fam")
+	require.NoError(t, err)
+	assert.Equal(t, "h2", got.SecretHash)
+	assert.True(t, now.Add(2*time.Hour).Equal(got.ExpiresAt))
+
+	require.NoError(t, s.RevokeRefreshFamily(ctx, "fam", now))
+	require.NoError(t, s.RevokeRefreshFamily(ctx, "fam", now.Add(time.Minute)), "repeated revoke is a no-op")
+	require.ErrorIs(t, s.RotateRefreshToken(ctx, "fam", "h2", "h3", now.Add(3*time.Hour)), storage.ErrRefreshFamilyNotFound)
+
+	got, err = s.RefreshFamily(ctx, "fam")
+	require.NoError(t, err)
+	assert.True(t, now.Equal(got.RevokedAt))
+	assert.False(t, got.Active(now))
+
+	n, err := s.DeleteExpiredRefreshFamilies(ctx, now.Add(90*time.Minute), 10)
+	require.NoError(t, err)
+	assert.Zero(t, n, "the rotated family expires later")
+
+	n, err = s.DeleteExpiredRefreshFamilies(ctx, now.Add(3*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestSetAppRefreshWindows(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "mobile", "secret")
+	require.NoError(t, err)
+
+	app, err := s.App(ctx, appID)
+	require.NoError(t, err)
+	assert.Zero(t, app.RefreshSlidingWindow)
+	assert.Zero(t, app.RefreshMaxLifetime)
+
+	require.NoError(t, s.SetAppRefreshWindows(ctx, appID, 24*time.Hour, 14*24*time.Hour))
+	app, err = s.App(ctx, appID)
+	require.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, app.RefreshSlidingWindow)
+	assert.Equal(t, 14*24*time.Hour, app.RefreshMaxLifetime)
+
+	require.ErrorIs(t, s.SetAppRefreshWindows(ctx, 999, time.Hour, time.Hour), storage.ErrAppNotFound)
+}
@@ -0,0 +1,216 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"fmt"
+	"sso/internal/storage"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MetricPools - переменная /debug/vars с показателями пулов соединений (основная БД и реплики)
+const MetricPools = "storage_pools"
+
+// Пулы соединений в PoolStats
+const (
+	PoolPrimary = "primary"
+	poolReplica = "replica"
+)
+
+// pool - пул соединений с признаком здоровья по последней проверке
+type pool struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+// replicaSet - реплики только для чтения и фоновая проверка их здоровья
+type replicaSet struct {
+	dsns     []string
+	interval time.Duration
+	timeout  time.Duration
+
+	primary  *pool
+	replicas []*pool
+	next     atomic.Uint64 // счётчик для выбора реплики по кругу
+
+	stop context.CancelFunc
+	done chan struct{}
+}
+
+// WithReplicas - реплики только для чтения (например, копии LiteFS). Горячие чтения (пользователь, приложение,
+// права, группы) идут на здоровую реплику по кругу, записи и транзакции - на основную БД. Если все реплики
+// недоступны, чтения идут на основную БД. Здоровье пулов проверяется раз в interval запросом с таймаутом timeout
+func WithReplicas(dsns []string, interval, timeout time.Duration) Option {
+	return func(s *Storage) {
+		s.rs = &replicaSet{dsns: dsns, interval: interval, timeout: timeout}
+	}
+}
+
+// openReplicas - открывает пулы реплик, проверяет их и запускает фоновую проверку.
+// Недоступная при старте реплика - не ошибка: чтения пойдут на основную БД, пока она не поднимется
+func (s *Storage) openReplicas() error {
+	rs := s.rs
+	rs.primary = &pool{name: PoolPrimary, db: s.db}
+	rs.primary.healthy.Store(true)
+
+	for i, dsn := range rs.dsns {
+		db, err := sql.Open("sqlite3", withQueryOnly(dsn))
+		if err != nil {
+			rs.close()
+			return err
+		}
+		rs.replicas = append(rs.replicas, &pool{name: fmt.Sprintf("%s%d", poolReplica, i+1), db: db})
+	}
+
+	rs.check(context.Background())
+
+	ctx, stop := context.WithCancel(context.Background())
+	rs.stop, rs.done = stop, make(chan struct{})
+	go rs.run(ctx)
+
+	return nil
+}
+
+// withQueryOnly - реплика открывается с PRAGMA query_only: запись, по ошибке отправленная на неё, падает
+func withQueryOnly(dsn string) string {
+	if strings.Contains(dsn, "_query_only=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&_query_only=true"
+	}
+
+	return dsn + "?_query_only=true"
+}
+
+// run - проверяет здоровье пулов раз в interval до отмены ctx
+func (rs *replicaSet) run(ctx context.Context) {
+	defer close(rs.done)
+
+	ticker := time.NewTicker(rs.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rs.check(ctx)
+		}
+	}
+}
+
+// check - проверяет все пулы параллельно: зависшая реплика не задерживает проверку остальных
+func (rs *replicaSet) check(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, p := range append([]*pool{rs.primary}, rs.replicas...) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, rs.timeout)
+			defer cancel()
+
+			// Ping не открывает файл SQLite до первого запроса, поэтому проверяется чтением схемы
+			var n int
+			p.healthy.Store(p.db.QueryRowContext(ctx, "SELECT count(*) FROM sqlite_master").Scan(&n) == nil)
+		}()
+	}
+	wg.Wait()
+}
+
+// close - останавливает проверку и закрывает пулы реплик (основной закрывает Storage.Close)
+func (rs *replicaSet) close() {
+	if rs.stop != nil {
+		rs.stop()
+		<-rs.done
+	}
+	for _, p := range rs.replicas {
+		_ = p.db.Close()
+	}
+}
+
+// read - соединение для чтения, которое может отставать от записи: открытая транзакция, иначе здоровая
+// реплика, иначе основная БД. Контекст, закреплённый за основной БД (storage.PinPrimary), читает с неё
+func (s *Storage) read(ctx context.Context) querier {
+	if tx, ok := ctx.Value(txCtxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	if s.rs == nil || len(s.rs.replicas) == 0 || storage.PinnedToPrimary(ctx) {
+		return s.db
+	}
+
+	n := uint64(len(s.rs.replicas))
+	start := s.rs.next.Add(1)
+	for i := range n {
+		if p := s.rs.replicas[(start+i)%n]; p.healthy.Load() {
+			return p.db
+		}
+	}
+
+	return s.db
+}
+
+// PoolStats - показатели пула соединений
+type PoolStats struct {
+	Healthy     bool    `json:"healthy"` // последняя проверка прошла (без реплик основная БД не проверяется)
+	Open        int     `json:"open"`
+	InUse       int     `json:"in_use"`
+	Idle        int     `json:"idle"`
+	WaitCount   int64   `json:"wait_count"`
+	WaitSeconds float64 `json:"wait_seconds"`
+}
+
+// PoolStats - показатели пулов: primary и replica1, replica2... в порядке конфигурации
+func (s *Storage) PoolStats() map[string]PoolStats {
+	stat := func(db *sql.DB, healthy bool) PoolStats {
+		st := db.Stats()
+
+		return PoolStats{
+			Healthy:     healthy,
+			Open:        st.OpenConnections,
+			InUse:       st.InUse,
+			Idle:        st.Idle,
+			WaitCount:   st.WaitCount,
+			WaitSeconds: st.WaitDuration.Seconds(),
+		}
+	}
+
+	if s.rs == nil {
+		return map[string]PoolStats{PoolPrimary: stat(s.db, true)}
+	}
+
+	res := make(map[string]PoolStats, len(s.rs.replicas)+1)
+	res[PoolPrimary] = stat(s.db, s.rs.primary.healthy.Load())
+	for _, p := range s.rs.replicas {
+		res[p.name] = stat(p.db, p.healthy.Load())
+	}
+
+	return res
+}
+
+// published - Storage, показатели которого отдаёт /debug/vars. expvar не позволяет заменить переменную,
+// поэтому она регистрируется один раз, а PublishPoolStats меняет, на что она указывает
+var published struct {
+	once    sync.Once
+	storage atomic.Pointer[Storage]
+}
+
+// PublishPoolStats - публикует показатели пулов s в /debug/vars (storage_pools) вместо опубликованных раньше
+func PublishPoolStats(s *Storage) {
+	published.storage.Store(s)
+	published.once.Do(func() {
+		expvar.Publish(MetricPools, expvar.Func(func() any {
+			if s := published.storage.Load(); s != nil {
+				return s.PoolStats()
+			}
+
+			return nil
+		}))
+	})
+}
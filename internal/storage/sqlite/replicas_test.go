@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicas_ReadRouting(t *testing.T) {
+	ctx := context.Background()
+
+	// Реплика - отдельная БД: пользователь, которого нет на основной, виден только при чтении с реплики
+	path := newTestDB(t)
+	replica, err := New(path)
+	require.NoError(t, err)
+	onReplica := seedUsers(t, replica, 1)[0]
+	require.NoError(t, replica.Close())
+
+	s := newTestStorage(t, WithReplicas([]string{path}, time.Hour, time.Second))
+
+	_, err = s.UserByID(ctx, onReplica)
+	require.NoError(t, err)
+
+	_, err = s.UserByID(storage.PinPrimary(ctx), onReplica)
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	err = s.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := s.UserByID(ctx, onReplica)
+		return err
+	})
+	assert.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	// Реплика открыта только для чтения
+	_, err = s.rs.replicas[0].db.ExecContext(ctx, "DELETE FROM users")
+	require.Error(t, err)
+
+	stats := s.PoolStats()
+	assert.True(t, stats[PoolPrimary].Healthy)
+	assert.True(t, stats["replica1"].Healthy)
+}
+
+func TestReplicas_FallbackToPrimary(t *testing.T) {
+	ctx := context.Background()
+
+	missing := filepath.Join(t.TempDir(), "missing", "sso.db")
+	s := newTestStorage(t, WithReplicas([]string{missing}, time.Hour, time.Second))
+	id := seedUsers(t, s, 1)[0]
+
+	assert.False(t, s.PoolStats()["replica1"].Healthy)
+
+	_, err := s.UserByID(ctx, id)
+	require.NoError(t, err)
+}
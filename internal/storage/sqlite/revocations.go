@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"time"
+)
+
+// AddRevocation - записывает отзыв в журнал (внутри транзакции, если она есть в ctx) и возвращает его seq.
+func (s *Storage) AddRevocation(ctx context.Context, r models.Revocation) (int64, error) {
+	const op = "storage.sqlite.AddRevocation"
+
+	res, err := s.conn(ctx).ExecContext(ctx, `INSERT INTO revocations (kind, user_id, app_id, session_id, token_id, revoked_at)
+		VALUES(?, ?, ?, ?, ?, ?)`, r.Kind, r.UserID, r.AppID, r.SessionID, r.TokenID, r.RevokedAt.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	seq, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return seq, nil
+}
+
+// RevocationsSince - до limit отзывов с seq больше afterSeq по возрастанию seq.
+func (s *Storage) RevocationsSince(ctx context.Context, afterSeq int64, limit int) ([]models.Revocation, error) {
+	const op = "storage.sqlite.RevocationsSince"
+
+	rows, err := s.db.QueryContext(ctx, `SELECT seq, kind, user_id, app_id, session_id, token_id, revoked_at
+		FROM revocations WHERE seq > ? ORDER BY seq LIMIT ?`, afterSeq, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var res []models.Revocation
+	for rows.Next() {
+		var r models.Revocation
+		if err := rows.Scan(&r.Seq, &r.Kind, &r.UserID, &r.AppID, &r.SessionID, &r.TokenID, &r.RevokedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+// LastRevocationSeq - seq последнего отзыва (0, если журнал пуст).
+func (s *Storage) LastRevocationSeq(ctx context.Context) (int64, error) {
+	const op = "storage.sqlite.LastRevocationSeq"
+
+	var seq int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(seq), 0) FROM revocations").Scan(&seq); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return seq, nil
+}
+
+// DeleteRevocationsBefore - удаляет до limit отзывов, записанных до before. Возвращает количество удалённых
+func (s *Storage) DeleteRevocationsBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteRevocationsBefore"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM revocations WHERE seq IN
+		(SELECT seq FROM revocations WHERE revoked_at < ? ORDER BY seq LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+// DenyToken - отзывает токен по jti. Повторный отзыв того же токена - не ошибка.
+func (s *Storage) DenyToken(ctx context.Context, t models.DeniedToken) error {
+	const op = "storage.sqlite.DenyToken"
+
+	_, err := s.conn(ctx).ExecContext(ctx, `INSERT OR IGNORE INTO revoked_tokens (jti, user_id, app_id, expires_at, revoked_at)
+		VALUES(?, ?, ?, ?, ?)`, t.TokenID, t.UserID, t.AppID, t.ExpiresAt.UTC(), t.RevokedAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// TokenDenied - отозван ли токен с этим jti.
+func (s *Storage) TokenDenied(ctx context.Context, jti string) (bool, error) {
+	const op = "storage.sqlite.TokenDenied"
+
+	var n int
+	err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM revoked_tokens WHERE jti = ?", jti).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return n > 0, nil
+}
+
+// DeleteExpiredDeniedTokens - удаляет до limit отозванных токенов, истёкших до before. Возвращает количество удалённых
+func (s *Storage) DeleteExpiredDeniedTokens(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredDeniedTokens"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE jti IN
+		(SELECT jti FROM revoked_tokens WHERE expires_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
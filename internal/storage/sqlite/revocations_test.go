@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevocations(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	last, err := s.LastRevocationSeq(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, last)
+
+	old := time.Now().Add(-48 * time.Hour)
+	for i, r := range []models.Revocation{
+		{Kind: models.RevocationSession, UserID: 7, AppID: 1, SessionID: "sid-1", RevokedAt: old},
+		{Kind: models.RevocationUser, UserID: 7, RevokedAt: time.Now()},
+		{Kind: models.RevocationUser, UserID: 8, AppID: 2, RevokedAt: time.Now()},
+	} {
+		seq, err := s.AddRevocation(ctx, r)
+		require.NoError(t, err)
+		assert.Equal(t, int64(i+1), seq)
+	}
+
+	got, err := s.RevocationsSince(ctx, 0, 2)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "sid-1", got[0].SessionID)
+	assert.WithinDuration(t, old, got[0].RevokedAt, time.Second)
+	assert.Equal(t, int64(2), got[1].Seq)
+
+	got, err = s.RevocationsSince(ctx, 2, 10)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, 2, got[0].AppID)
+
+	n, err := s.DeleteRevocationsBefore(ctx, time.Now().Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	// Удаление старых записей не сбрасывает нумерацию
+	last, err = s.LastRevocationSeq(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), last)
+	seq, err := s.AddRevocation(ctx, models.Revocation{Kind: models.RevocationUser, UserID: 9, RevokedAt: time.Now()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), seq)
+}
+
+func TestDeniedTokens(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	denied, err := s.TokenDenied(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.False(t, denied)
+
+	for _, d := range []models.DeniedToken{
+		{TokenID: "jti-1", UserID: 7, AppID: 1, ExpiresAt: time.Now().Add(-time.Hour), RevokedAt: time.Now()},
+		{TokenID: "jti-2", UserID: 7, AppID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: time.Now()},
+		{TokenID: "jti-2", UserID: 7, AppID: 1, ExpiresAt: time.Now().Add(time.Hour), RevokedAt: time.Now()},
+	} {
+		require.NoError(t, s.DenyToken(ctx, d))
+	}
+
+	denied, err = s.TokenDenied(ctx, "jti-2")
+	require.NoError(t, err)
+	assert.True(t, denied)
+
+	n, err := s.DeleteExpiredDeniedTokens(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	denied, err = s.TokenDenied(ctx, "jti-1")
+	require.NoError(t, err)
+	assert.False(t, denied)
+
+	seq, err := s.AddRevocation(ctx, models.Revocation{Kind: models.RevocationToken, UserID: 7, AppID: 1, TokenID: "jti-2", RevokedAt: time.Now()})
+	require.NoError(t, err)
+	got, err := s.RevocationsSince(ctx, seq-1, 1)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, "jti-2", got[0].TokenID)
+}
@@ -0,0 +1,207 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/storage"
+	"time"
+)
+
+// selfTestUserTables - таблицы с данными пользователя, которые удаляются вместе с пользователем самопроверки
+var selfTestUserTables = []string{
+	"sessions", "login_history", "password_history", "tos_acceptances", "consents", "org_members", "user_groups",
+	"action_tokens", "device_authorizations", "invitations", "passkeys", "webauthn_sessions", "sms_challenges",
+	"sms_sends", "account_deletions", "offline_tokens", "revocations", "revoked_tokens", "user_apps", "app_admins",
+	"refresh_families",
+}
+
+// selfTestAppTables - таблицы с данными приложения, которые удаляются вместе с приложением самопроверки
+var selfTestAppTables = []string{
+	"sessions", "login_history", "login_failures", "consents", "invitations", "device_authorizations",
+	"offline_tokens", "revocations", "revoked_tokens", "oidc_codes", "app_redirect_uris", "app_origins",
+	"app_login_policies", "user_apps", "app_admins", "refresh_families",
+}
+
+// SaveSelfTestApp - регистрирует приложение самопроверки (помеченное временем at) и возвращает его id.
+func (s *Storage) SaveSelfTestApp(ctx context.Context, name, secret string, at time.Time) (int, error) {
+	const op = "storage.sqlite.SaveSelfTestApp"
+
+	res, err := s.db.ExecContext(ctx, "INSERT INTO apps(name, secret, selftest_at) VALUES(?, ?, ?)", name, secret, at.UTC())
+	if err != nil {
+		if isUnique(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrAppExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(id), nil
+}
+
+// MarkSelfTestUser - помечает пользователя как созданного самопроверкой: он пропадает из статистики
+// и списка пользователей и удаляется DeleteSelfTestData (storage.ErrUserNotFound).
+func (s *Storage) MarkSelfTestUser(ctx context.Context, userID int64, at time.Time) error {
+	const op = "storage.sqlite.MarkSelfTestUser"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE users SET selftest_at = ? WHERE id = ?", at.UTC(), userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// SetEmailVerified - отмечает email пользователя самопроверки подтверждённым (storage.ErrUserNotFound).
+// Обычных пользователей не меняет: подтверждение без письма допустимо только для тестовых данных
+func (s *Storage) SetEmailVerified(ctx context.Context, userID int64) error {
+	const op = "storage.sqlite.SetEmailVerified"
+
+	res, err := s.db.ExecContext(ctx, `UPDATE users SET email_verified = TRUE, version = version + 1
+		WHERE id = ? AND selftest_at IS NOT NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return nil
+}
+
+// DeleteSelfTestData - удаляет пользователя и приложение самопроверки со всеми их записями.
+// Не помеченные самопроверкой пользователь и приложение не удаляются; 0 - удалять нечего
+func (s *Storage) DeleteSelfTestData(ctx context.Context, userID int64, appID int) error {
+	const op = "storage.sqlite.DeleteSelfTestData"
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		if userID != 0 {
+			if err := s.deleteSelfTestUser(ctx, userID); err != nil {
+				return err
+			}
+		}
+		if appID != 0 {
+			if err := s.deleteSelfTestApp(ctx, appID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteStaleSelfTestData - удаляет до limit пользователей и до limit приложений самопроверки, созданных
+// до before: их оставила самопроверка, прерванная до уборки. Возвращает, сколько удалено всего
+func (s *Storage) DeleteStaleSelfTestData(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteStaleSelfTestData"
+
+	userIDs, err := s.staleSelfTestIDs(ctx, "users", before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	appIDs, err := s.staleSelfTestIDs(ctx, "apps", before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	err = s.WithinTx(ctx, func(ctx context.Context) error {
+		for _, id := range userIDs {
+			if err := s.deleteSelfTestUser(ctx, id); err != nil {
+				return err
+			}
+		}
+		for _, id := range appIDs {
+			if err := s.deleteSelfTestApp(ctx, int(id)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return len(userIDs) + len(appIDs), nil
+}
+
+// staleSelfTestIDs - id до limit записей table, помеченных самопроверкой до before
+func (s *Storage) staleSelfTestIDs(ctx context.Context, table string, before time.Time, limit int) ([]int64, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id FROM "+table+" WHERE selftest_at IS NOT NULL AND selftest_at < ? ORDER BY id LIMIT ?", before.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// deleteSelfTestUser - удаляет пользователя самопроверки и его записи; обычного пользователя не трогает
+func (s *Storage) deleteSelfTestUser(ctx context.Context, userID int64) error {
+	res, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM users WHERE id = ? AND selftest_at IS NOT NULL", userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+
+	for _, table := range selfTestUserTables {
+		if _, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM "+table+" WHERE user_id = ?", userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteSelfTestApp - удаляет приложение самопроверки и его записи; обычное приложение не трогает
+func (s *Storage) deleteSelfTestApp(ctx context.Context, appID int) error {
+	res, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM apps WHERE id = ? AND selftest_at IS NOT NULL", appID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+
+	for _, table := range selfTestAppTables {
+		if _, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM "+table+" WHERE app_id = ?", appID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
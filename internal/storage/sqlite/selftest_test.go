@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelfTestData(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	ids := seedUsers(t, s, 2)
+	regular, tester := ids[0], ids[1]
+	regularApp, err := s.SaveApp(ctx, "web", "secret")
+	require.NoError(t, err)
+
+	appID, err := s.SaveSelfTestApp(ctx, "selftest-1", "selftest-secret", now)
+	require.NoError(t, err)
+	require.NoError(t, s.MarkSelfTestUser(ctx, tester, now))
+	require.ErrorIs(t, s.MarkSelfTestUser(ctx, 999, now), storage.ErrUserNotFound)
+
+	// Подтвердить email без письма можно только пользователю самопроверки
+	require.ErrorIs(t, s.SetEmailVerified(ctx, regular), storage.ErrUserNotFound)
+	require.NoError(t, s.SetEmailVerified(ctx, tester))
+	u, err := s.UserByID(ctx, tester)
+	require.NoError(t, err)
+	assert.True(t, u.EmailVerified)
+
+	_, err = s.CreateSession(ctx, newSession("test", tester, appID), 0, false)
+	require.NoError(t, err)
+	_, err = s.CreateSession(ctx, newSession("real", regular, regularApp), 0, false)
+	require.NoError(t, err)
+	require.NoError(t, s.AddLogin(ctx, models.LoginRecord{UserID: tester, AppID: appID, LoggedInAt: now}))
+
+	// В статистике и списке пользователей данных самопроверки нет
+	st, err := s.Stats(ctx, 0, false, now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), st.TotalUsers)
+	assert.Equal(t, int64(1), st.ActiveSessions)
+	assert.Zero(t, st.LoginsLastHour)
+
+	users, err := s.ListUsers(ctx, 0, 10, "", false, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, regular, users[0].ID)
+
+	// Обычные пользователь и приложение не удаляются, даже если их передать
+	require.NoError(t, s.DeleteSelfTestData(ctx, regular, regularApp))
+	_, err = s.UserByID(ctx, regular)
+	require.NoError(t, err)
+	_, err = s.App(ctx, regularApp)
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteSelfTestData(ctx, tester, appID))
+	_, err = s.UserByID(ctx, tester)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.App(ctx, appID)
+	require.ErrorIs(t, err, storage.ErrAppNotFound)
+	_, err = s.Session(ctx, "test")
+	require.ErrorIs(t, err, storage.ErrSessionNotFound)
+	_, err = s.Session(ctx, "real")
+	require.NoError(t, err)
+
+	// Повторная уборка - не ошибка
+	require.NoError(t, s.DeleteSelfTestData(ctx, tester, appID))
+}
+
+func TestDeleteStaleSelfTestData(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	ids := seedUsers(t, s, 3)
+	require.NoError(t, s.MarkSelfTestUser(ctx, ids[0], now.Add(-2*time.Hour)))
+	require.NoError(t, s.MarkSelfTestUser(ctx, ids[1], now))
+	staleApp, err := s.SaveSelfTestApp(ctx, "selftest-old", "old-secret", now.Add(-2*time.Hour))
+	require.NoError(t, err)
+	freshApp, err := s.SaveSelfTestApp(ctx, "selftest-new", "new-secret", now)
+	require.NoError(t, err)
+
+	n, err := s.DeleteStaleSelfTestData(ctx, now.Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	_, err = s.UserByID(ctx, ids[0])
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+	_, err = s.App(ctx, staleApp)
+	require.ErrorIs(t, err, storage.ErrAppNotFound)
+
+	// Идущая самопроверка и обычный пользователь остаются
+	for _, id := range ids[1:] {
+		_, err = s.UserByID(ctx, id)
+		require.NoError(t, err)
+	}
+	_, err = s.App(ctx, freshApp)
+	require.NoError(t, err)
+}
@@ -0,0 +1,178 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strings"
+	"time"
+)
+
+// CreateSession - сохраняет сессию с учётом лимита limit активных сессий пользователя в приложении (0 - без лимита).
+// При превышении лимита сессия не создаётся (storage.ErrSessionLimitReached), а если evictOldest -
+// отзываются самые старые сессии; их id возвращаются.
+//
+// Новая сессия вставляется до подсчёта: первая запись захватывает блокировку записи SQLite,
+// поэтому одновременные входы одного пользователя считают сессии по очереди и не превышают лимит вдвоём
+func (s *Storage) CreateSession(
+	ctx context.Context,
+	session models.Session,
+	limit int,
+	evictOldest bool,
+) (evicted []string, err error) {
+	const op = "storage.sqlite.CreateSession"
+
+	err = s.WithinTx(ctx, func(ctx context.Context) error {
+		db := s.conn(ctx)
+
+		var authTime sql.NullTime
+		if !session.AuthTime.IsZero() {
+			authTime = sql.NullTime{Time: session.AuthTime.UTC(), Valid: true}
+		}
+
+		if _, err := db.ExecContext(ctx, `INSERT INTO sessions (id, user_id, app_id, scope, org_id, amr, acr, auth_time,
+			created_at, last_seen_at, expires_at)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, session.ID, session.UserID, session.AppID,
+			strings.Join(session.Scopes, " "), session.OrgID, strings.Join(session.AMR, " "), session.ACR, authTime,
+			session.CreatedAt.UTC(), session.CreatedAt.UTC(), session.ExpiresAt.UTC()); err != nil {
+			return err
+		}
+
+		if limit <= 0 {
+			return nil
+		}
+
+		rows, err := db.QueryContext(ctx, `SELECT id FROM sessions
+			WHERE user_id = ? AND app_id = ? AND revoked_at IS NULL AND expires_at > ? AND id != ?
+			ORDER BY created_at, rowid`, session.UserID, session.AppID, session.CreatedAt.UTC(), session.ID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var active []string
+		for rows.Next() {
+			var id string
+			if err := rows.Scan(&id); err != nil {
+				return err
+			}
+			active = append(active, id)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// Вместе с новой сессией активных должно остаться не больше limit
+		excess := len(active) + 1 - limit
+		if excess <= 0 {
+			return nil
+		}
+		if !evictOldest {
+			return storage.ErrSessionLimitReached
+		}
+
+		for _, id := range active[:excess] {
+			if _, err := db.ExecContext(ctx, "UPDATE sessions SET revoked_at = ? WHERE id = ?",
+				session.CreatedAt.UTC(), id); err != nil {
+				return err
+			}
+		}
+		evicted = active[:excess]
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return evicted, nil
+}
+
+// Session - сессия по id; нет такой - storage.ErrSessionNotFound.
+func (s *Storage) Session(ctx context.Context, id string) (models.Session, error) {
+	const op = "storage.sqlite.Session"
+
+	var (
+		session                     models.Session
+		scope, amr                  string
+		authTime, lastSeen, revoked sql.NullTime
+	)
+	err := s.conn(ctx).QueryRowContext(ctx, `SELECT id, user_id, app_id, scope, org_id, amr, acr, auth_time,
+		created_at, last_seen_at, expires_at, revoked_at
+		FROM sessions WHERE id = ?`, id).
+		Scan(&session.ID, &session.UserID, &session.AppID, &scope, &session.OrgID, &amr, &session.ACR, &authTime,
+			&session.CreatedAt, &lastSeen, &session.ExpiresAt, &revoked)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.Session{}, fmt.Errorf("%s: %w", op, storage.ErrSessionNotFound)
+		}
+
+		return models.Session{}, fmt.Errorf("%s: %w", op, err)
+	}
+	session.Scopes = strings.Fields(scope)
+	session.AMR = strings.Fields(amr)
+	session.AuthTime = authTime.Time
+	session.RevokedAt = revoked.Time
+
+	// Сессии, созданные до появления last_seen_at, считаются активными с момента входа
+	session.LastSeenAt = session.CreatedAt
+	if lastSeen.Valid {
+		session.LastSeenAt = lastSeen.Time
+	}
+
+	return session, nil
+}
+
+// TouchSession - отмечает активность сессии в момент at. Обновление пропускается, если активность
+// уже отмечена позже at - throttle (с шагом throttle), чтобы частые проверки токена не писали в БД каждый раз
+func (s *Storage) TouchSession(ctx context.Context, id string, at time.Time, throttle time.Duration) error {
+	const op = "storage.sqlite.TouchSession"
+
+	if _, err := s.db.ExecContext(ctx, `UPDATE sessions SET last_seen_at = ?
+		WHERE id = ? AND (last_seen_at IS NULL OR last_seen_at <= ?)`, at.UTC(), id, at.Add(-throttle).UTC()); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RevokeSession - отзывает активную сессию в момент at; нет такой или уже отозвана - storage.ErrSessionNotFound.
+func (s *Storage) RevokeSession(ctx context.Context, id string, at time.Time) error {
+	const op = "storage.sqlite.RevokeSession"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE sessions SET revoked_at = ? WHERE id = ? AND revoked_at IS NULL", at.UTC(), id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrSessionNotFound)
+	}
+
+	return nil
+}
+
+// DeleteExpiredSessions - удаляет до limit сессий, истёкших до before. Возвращает количество удалённых
+func (s *Storage) DeleteExpiredSessions(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredSessions"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sessions WHERE id IN
+		(SELECT id FROM sessions WHERE expires_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
@@ -0,0 +1,176 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newSession(id string, userID int64, appID int) models.Session {
+	now := time.Now()
+
+	return models.Session{ID: id, UserID: userID, AppID: appID, CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+}
+
+func TestCreateSession_Limit(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	for _, id := range []string{"a", "b"} {
+		_, err := s.CreateSession(ctx, newSession(id, ids[0], 1), 2, false)
+		require.NoError(t, err)
+	}
+
+	_, err := s.CreateSession(ctx, newSession("c", ids[0], 1), 2, false)
+	require.ErrorIs(t, err, storage.ErrSessionLimitReached)
+	_, err = s.Session(ctx, "c")
+	require.ErrorIs(t, err, storage.ErrSessionNotFound, "rejected session is not saved")
+
+	// Лимит считается по приложению: в другом приложении сессий пока нет
+	_, err = s.CreateSession(ctx, newSession("other-app", ids[0], 2), 2, false)
+	require.NoError(t, err)
+
+	evicted, err := s.CreateSession(ctx, newSession("d", ids[0], 1), 2, true)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, evicted)
+
+	a, err := s.Session(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, a.Active(time.Now()))
+
+	d, err := s.Session(ctx, "d")
+	require.NoError(t, err)
+	assert.True(t, d.Active(time.Now()))
+}
+
+func TestSession_LoginClaims(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	session := newSession("sid", ids[0], 1)
+	session.Scopes = []string{"files.read", "files.write"}
+	session.OrgID = 3
+	session.AMR = []string{"pwd", "otp"}
+	session.ACR = models.ACRMultiFactor
+	session.AuthTime = time.Now().Add(-time.Minute).UTC().Truncate(time.Second)
+	_, err := s.CreateSession(ctx, session, 0, false)
+	require.NoError(t, err)
+
+	got, err := s.Session(ctx, "sid")
+	require.NoError(t, err)
+	assert.Equal(t, session.Scopes, got.Scopes)
+	assert.Equal(t, int64(3), got.OrgID)
+	assert.Equal(t, session.AMR, got.AMR)
+	assert.Equal(t, models.ACRMultiFactor, got.ACR)
+	assert.True(t, session.AuthTime.Equal(got.AuthTime))
+
+	// Сессия без клеймов входа (как созданные до их появления) читается с пустыми значениями
+	_, err = s.CreateSession(ctx, newSession("bare", ids[0], 1), 0, false)
+	require.NoError(t, err)
+	bare, err := s.Session(ctx, "bare")
+	require.NoError(t, err)
+	assert.Empty(t, bare.Scopes)
+	assert.Empty(t, bare.AMR)
+	assert.True(t, bare.AuthTime.IsZero())
+}
+
+func TestCreateSession_ConcurrentLogins(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	const logins = 8
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, logins)
+	)
+	for i := range logins {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.CreateSession(ctx, newSession(fmt.Sprintf("s%d", i), ids[0], 1), 2, false)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorIs(t, err, storage.ErrSessionLimitReached)
+	}
+	assert.Equal(t, 2, created)
+}
+
+func TestTouchSession_Throttled(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	session := newSession("a", ids[0], 1)
+	_, err := s.CreateSession(ctx, session, 0, false)
+	require.NoError(t, err)
+
+	// С входа прошло меньше throttle - отметка не меняется
+	require.NoError(t, s.TouchSession(ctx, "a", session.CreatedAt.Add(30*time.Second), time.Minute))
+	got, err := s.Session(ctx, "a")
+	require.NoError(t, err)
+	assert.WithinDuration(t, session.CreatedAt, got.LastSeenAt, time.Second)
+
+	later := session.CreatedAt.Add(2 * time.Minute)
+	require.NoError(t, s.TouchSession(ctx, "a", later, time.Minute))
+	got, err = s.Session(ctx, "a")
+	require.NoError(t, err)
+	assert.WithinDuration(t, later, got.LastSeenAt, time.Second)
+}
+
+func TestRevokeSession(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	_, err := s.CreateSession(ctx, newSession("a", ids[0], 1), 0, false)
+	require.NoError(t, err)
+
+	require.NoError(t, s.RevokeSession(ctx, "a", time.Now()))
+	got, err := s.Session(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, got.Active(time.Now()))
+
+	require.ErrorIs(t, s.RevokeSession(ctx, "a", time.Now()), storage.ErrSessionNotFound, "already revoked")
+	require.ErrorIs(t, s.RevokeSession(ctx, "missing", time.Now()), storage.ErrSessionNotFound)
+}
+
+func TestDeleteExpiredSessions(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	old := newSession("old", ids[0], 1)
+	old.ExpiresAt = time.Now().Add(-time.Minute)
+	for _, session := range []models.Session{old, newSession("new", ids[0], 1)} {
+		_, err := s.CreateSession(ctx, session, 0, false)
+		require.NoError(t, err)
+	}
+
+	n, err := s.DeleteExpiredSessions(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.Session(ctx, "old")
+	require.ErrorIs(t, err, storage.ErrSessionNotFound)
+}
@@ -0,0 +1,224 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"time"
+)
+
+// SaveSMSChallenge - сохраняет вызов с кодом из SMS (код ещё не отправлен)
+func (s *Storage) SaveSMSChallenge(ctx context.Context, c models.SMSChallenge) error {
+	const op = "storage.sqlite.SaveSMSChallenge"
+
+	phone, err := s.seal(c.Phone)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	_, err = s.db.ExecContext(ctx, `INSERT INTO sms_challenges
+		(id, purpose, user_id, phone, app_id, org_id, accepted_tos, expires_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.ID, c.Purpose, c.UserID, phone, c.AppID, c.OrgID, c.AcceptedTOS, c.ExpiresAt.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SMSChallenge - вызов по SHA-256 токена (storage.ErrSMSChallengeNotFound, если его нет).
+// Срок действия не проверяется: это делает вызывающий
+func (s *Storage) SMSChallenge(ctx context.Context, id string) (models.SMSChallenge, error) {
+	const op = "storage.sqlite.SMSChallenge"
+
+	var (
+		c                     models.SMSChallenge
+		codeExpiresAt, sentAt sql.NullTime
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT id, purpose, user_id, phone, app_id, org_id, accepted_tos,
+			code_hash, code_expires_at, sent_at, attempts, expires_at
+		FROM sms_challenges WHERE id = ?`, id).
+		Scan(&c.ID, &c.Purpose, &c.UserID, &c.Phone, &c.AppID, &c.OrgID, &c.AcceptedTOS,
+			&c.CodeHash, &codeExpiresAt, &sentAt, &c.Attempts, &c.ExpiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.SMSChallenge{}, fmt.Errorf("%s: %w", op, storage.ErrSMSChallengeNotFound)
+		}
+
+		return models.SMSChallenge{}, fmt.Errorf("%s: %w", op, err)
+	}
+	c.CodeExpiresAt = codeExpiresAt.Time
+	c.SentAt = sentAt.Time
+	if c.Phone, err = s.open(c.Phone); err != nil {
+		return models.SMSChallenge{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return c, nil
+}
+
+// ReserveSMSSend - учитывает отправку SMS пользователю userID на номер phone, если за окно с since
+// их было меньше maxPerUser и maxPerPhone соответственно; иначе storage.ErrSMSSendLimitReached.
+// Проверка и запись выполняются в одной транзакции, чтобы параллельные запросы не превысили лимит
+func (s *Storage) ReserveSMSSend(
+	ctx context.Context,
+	userID int64,
+	phone string,
+	sentAt, since time.Time,
+	maxPerUser, maxPerPhone int,
+) error {
+	const op = "storage.sqlite.ReserveSMSSend"
+
+	phone = s.phoneKey(phone)
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		var byUser, byPhone int
+		err := s.conn(ctx).QueryRowContext(ctx, `SELECT
+				(SELECT COUNT(*) FROM sms_sends WHERE user_id = ? AND sent_at > ?),
+				(SELECT COUNT(*) FROM sms_sends WHERE phone = ? AND sent_at > ?)`,
+			userID, since.UTC(), phone, since.UTC()).Scan(&byUser, &byPhone)
+		if err != nil {
+			return err
+		}
+		if byUser >= maxPerUser || byPhone >= maxPerPhone {
+			return storage.ErrSMSSendLimitReached
+		}
+
+		_, err = s.conn(ctx).ExecContext(ctx,
+			"INSERT INTO sms_sends(user_id, phone, sent_at) VALUES(?, ?, ?)", userID, phone, sentAt.UTC())
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetSMSCode - хеш нового кода вызова, срок его действия и время отправки
+func (s *Storage) SetSMSCode(ctx context.Context, id string, codeHash []byte, codeExpiresAt, sentAt time.Time) error {
+	const op = "storage.sqlite.SetSMSCode"
+
+	if err := s.execAffecting(ctx, storage.ErrSMSChallengeNotFound,
+		"UPDATE sms_challenges SET code_hash = ?, code_expires_at = ?, sent_at = ? WHERE id = ?",
+		codeHash, codeExpiresAt.UTC(), sentAt.UTC(), id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// ConsumeSMSAttempt - учитывает одну проверку кода. Если проверок уже maxAttempts -
+// storage.ErrSMSAttemptsExhausted (счётчик не растёт)
+func (s *Storage) ConsumeSMSAttempt(ctx context.Context, id string, maxAttempts int) error {
+	const op = "storage.sqlite.ConsumeSMSAttempt"
+
+	var attempts int
+	err := s.db.QueryRowContext(ctx, `UPDATE sms_challenges SET attempts = attempts + 1
+		WHERE id = ? AND attempts < ? RETURNING attempts`, id, maxAttempts).Scan(&attempts)
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Строки нет или попытки кончились
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM sms_challenges WHERE id = ?)", id).
+		Scan(&exists); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !exists {
+		return fmt.Errorf("%s: %w", op, storage.ErrSMSChallengeNotFound)
+	}
+
+	return fmt.Errorf("%s: %w", op, storage.ErrSMSAttemptsExhausted)
+}
+
+// DeleteSMSChallenge - удаляет вызов (storage.ErrSMSChallengeNotFound, если его уже нет).
+// По результату удаления видно, кто из параллельных запросов использовал вызов первым
+func (s *Storage) DeleteSMSChallenge(ctx context.Context, id string) error {
+	const op = "storage.sqlite.DeleteSMSChallenge"
+
+	if err := s.execAffecting(ctx, storage.ErrSMSChallengeNotFound,
+		"DELETE FROM sms_challenges WHERE id = ?", id); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// SetUserPhone - задаёт подтверждённый номер пользователя (пусто - удаляет номер)
+func (s *Storage) SetUserPhone(ctx context.Context, userID int64, phone string) error {
+	const op = "storage.sqlite.SetUserPhone"
+
+	phone, err := s.seal(phone)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := s.execAffecting(ctx, storage.ErrUserNotFound,
+		"UPDATE users SET phone = ?, version = version + 1 WHERE id = ? AND erased_at IS NULL", phone, userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// DeleteExpiredSMSChallenges - удаляет до limit вызовов, истёкших до before. Возвращает количество удалённых
+func (s *Storage) DeleteExpiredSMSChallenges(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteExpiredSMSChallenges"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sms_challenges WHERE id IN
+		(SELECT id FROM sms_challenges WHERE expires_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+// DeleteSMSSendsBefore - удаляет до limit записей об отправках старше before (вне окна ограничения)
+func (s *Storage) DeleteSMSSendsBefore(ctx context.Context, before time.Time, limit int) (int, error) {
+	const op = "storage.sqlite.DeleteSMSSendsBefore"
+
+	res, err := s.db.ExecContext(ctx, `DELETE FROM sms_sends WHERE id IN
+		(SELECT id FROM sms_sends WHERE sent_at < ? LIMIT ?)`, before.UTC(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(n), nil
+}
+
+// execAffecting - выполняет запрос; ни одна строка не изменилась - notFound
+func (s *Storage) execAffecting(ctx context.Context, notFound error, query string, args ...any) error {
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return notFound
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMSChallenges_Lifecycle(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	require.NoError(t, s.SaveSMSChallenge(ctx, models.SMSChallenge{
+		ID: "hash", Purpose: models.SMSPurposeLogin, UserID: ids[0], Phone: "+79123456789",
+		AppID: 1, OrgID: 2, AcceptedTOS: "v1", ExpiresAt: time.Now().Add(10 * time.Minute),
+	}))
+
+	c, err := s.SMSChallenge(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, models.SMSPurposeLogin, c.Purpose)
+	assert.Equal(t, "+79123456789", c.Phone)
+	assert.Equal(t, int64(2), c.OrgID)
+	assert.Equal(t, "v1", c.AcceptedTOS)
+	assert.Nil(t, c.CodeHash)
+	assert.True(t, c.SentAt.IsZero())
+
+	sentAt := time.Now()
+	require.NoError(t, s.SetSMSCode(ctx, "hash", []byte{1, 2}, sentAt.Add(5*time.Minute), sentAt))
+	c, err = s.SMSChallenge(ctx, "hash")
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2}, c.CodeHash)
+	assert.WithinDuration(t, sentAt, c.SentAt, time.Second)
+
+	require.NoError(t, s.ConsumeSMSAttempt(ctx, "hash", 2))
+	require.NoError(t, s.ConsumeSMSAttempt(ctx, "hash", 2))
+	require.ErrorIs(t, s.ConsumeSMSAttempt(ctx, "hash", 2), storage.ErrSMSAttemptsExhausted)
+
+	require.NoError(t, s.DeleteSMSChallenge(ctx, "hash"))
+	require.ErrorIs(t, s.DeleteSMSChallenge(ctx, "hash"), storage.ErrSMSChallengeNotFound)
+	require.ErrorIs(t, s.ConsumeSMSAttempt(ctx, "hash", 2), storage.ErrSMSChallengeNotFound)
+	_, err = s.SMSChallenge(ctx, "hash")
+	require.ErrorIs(t, err, storage.ErrSMSChallengeNotFound)
+}
+
+func TestReserveSMSSend_Limits(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+	since := now.Add(-time.Hour)
+
+	require.NoError(t, s.ReserveSMSSend(ctx, 1, "+79123456789", now, since, 2, 3))
+	require.NoError(t, s.ReserveSMSSend(ctx, 1, "+79123456789", now, since, 2, 3))
+	// Третья SMS тому же пользователю - сверх лимита
+	require.ErrorIs(t, s.ReserveSMSSend(ctx, 1, "+79123456789", now, since, 2, 3), storage.ErrSMSSendLimitReached)
+
+	// На тот же номер от другого пользователя - до лимита номера
+	require.NoError(t, s.ReserveSMSSend(ctx, 2, "+79123456789", now, since, 2, 3))
+	require.ErrorIs(t, s.ReserveSMSSend(ctx, 3, "+79123456789", now, since, 2, 3), storage.ErrSMSSendLimitReached)
+
+	// Отправки вне окна не считаются и удаляются janitor
+	later := now.Add(2 * time.Hour)
+	require.NoError(t, s.ReserveSMSSend(ctx, 1, "+79123456789", later, later.Add(-time.Hour), 2, 3))
+
+	n, err := s.DeleteSMSSendsBefore(ctx, later.Add(-time.Hour), 100)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestSetUserPhone(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	require.NoError(t, s.SetUserPhone(ctx, ids[0], "+79123456789"))
+	u, err := s.UserByID(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, "+79123456789", u.Phone)
+
+	u, err = s.User(ctx, u.Email)
+	require.NoError(t, err)
+	assert.Equal(t, "+79123456789", u.Phone)
+
+	require.ErrorIs(t, s.SetUserPhone(ctx, 999, "+79123456789"), storage.ErrUserNotFound)
+
+	require.NoError(t, s.SaveSMSChallenge(ctx, models.SMSChallenge{
+		ID: "hash", Purpose: models.SMSPurposePhone, UserID: ids[0], Phone: "+79123456789", ExpiresAt: time.Now().Add(-time.Minute),
+	}))
+	require.NoError(t, s.EraseUser(ctx, ids[0], "erased@example.invalid"))
+
+	_, err = s.SMSChallenge(ctx, "hash")
+	require.ErrorIs(t, err, storage.ErrSMSChallengeNotFound)
+	require.ErrorIs(t, s.SetUserPhone(ctx, ids[0], "+79123456789"), storage.ErrUserNotFound)
+}
+
+func TestDeleteExpiredSMSChallenges(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	for id, expiresAt := range map[string]time.Time{"old": time.Now().Add(-time.Minute), "new": time.Now().Add(time.Minute)} {
+		require.NoError(t, s.SaveSMSChallenge(ctx, models.SMSChallenge{
+			ID: id, Purpose: models.SMSPurposeLogin, UserID: ids[0], Phone: "+79123456789", ExpiresAt: expiresAt,
+		}))
+	}
+
+	n, err := s.DeleteExpiredSMSChallenges(ctx, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	_, err = s.SMSChallenge(ctx, "new")
+	require.NoError(t, err)
+}
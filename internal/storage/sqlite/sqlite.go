@@ -6,42 +6,99 @@ import (
 	"errors"
 	"fmt"
 	"sso/internal/domain/models"
+	"sso/internal/lib/pii"
 	"sso/internal/storage"
+	"strings"
+	"time"
 
 	"github.com/mattn/go-sqlite3"
 )
 
 // Storage - структура для работы с SQLite БД.
 type Storage struct {
-	db *sql.DB // соединение с базой данных
+	db  *sql.DB     // соединение с базой данных
+	pii *pii.Cipher // шифрование email и телефонов (nil - хранятся открыто)
+
+	chain    bool   // связывать события журнала безопасности цепочкой хешей
+	chainKey []byte // ключ подписи последнего звена (nil - без подписи)
+
+	rs *replicaSet // реплики только для чтения (nil - все запросы к основной БД)
 }
 
 // New - инициализирует новое подключение к SQLite.
-func New(storagePath string) (*Storage, error) {
+// Транзакции начинаются с BEGIN IMMEDIATE: пишущие транзакции сразу берут блокировку записи и ждут
+// друг друга (busy timeout драйвера), а не падают с SQLITE_BUSY при повышении блокировки чтения.
+// Поэтому из конкурентных вставок одного email проходит одна, остальные получают storage.ErrUserExists
+func New(storagePath string, opts ...Option) (*Storage, error) {
 	const op = "storage.sqlite.New"
 
-	db, err := sql.Open("sqlite3", storagePath) // открываем SQLite по указанному пути
+	db, err := sql.Open("sqlite3", withTxLock(storagePath)) // открываем SQLite по указанному пути
 	if err != nil {
 		return nil, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return &Storage{db: db}, nil
+	s := &Storage{db: db}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if s.rs != nil {
+		if err := s.openReplicas(); err != nil {
+			_ = db.Close()
+
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return s, nil
+}
+
+// withTxLock - добавляет к пути параметр _txlock=immediate, если он не задан явно
+func withTxLock(storagePath string) string {
+	if strings.Contains(storagePath, "_txlock=") {
+		return storagePath
+	}
+	if strings.Contains(storagePath, "?") {
+		return storagePath + "&_txlock=immediate"
+	}
+
+	return storagePath + "?_txlock=immediate"
+}
+
+// isUnique - нарушено ограничение UNIQUE
+func isUnique(err error) bool {
+	var sqliteErr sqlite3.Error
+
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+// Close - закрывает соединение с БД (SQLite при этом сбрасывает WAL и удаляет -wal/-shm файлы).
+func (s *Storage) Close() error {
+	const op = "storage.sqlite.Close"
+
+	if s.rs != nil {
+		s.rs.close()
+	}
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
 }
 
 // SaveUser - сохраняет нового пользователя в БД.
 func (s *Storage) SaveUser(ctx context.Context, email string, passHash []byte) (int64, error) {
 	const op = "storage.sqlite.SaveUser"
 
-	stmt, err := s.db.Prepare("INSERT INTO users(email, pass_hash) VALUES(?, ?)")
+	email, index, err := s.sealEmail(email)
 	if err != nil {
 		return 0, fmt.Errorf("%s: %w", op, err)
 	}
 
-	res, err := stmt.ExecContext(ctx, email, passHash) // выполняем запрос
+	res, err := s.conn(ctx).ExecContext(ctx,
+		"INSERT INTO users(email, email_index, pass_hash, password_changed_at, created_at) VALUES(?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)", email, index, passHash) // выполняем запрос
 	if err != nil {
-		var sqliteErr sqlite3.Error
-		// проверяем, если ошибка связана с уникальностью email
-		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		if isUnique(err) {
 			return 0, fmt.Errorf("%s: %w", op, storage.ErrUserExists)
 		}
 
@@ -56,25 +113,39 @@ func (s *Storage) SaveUser(ctx context.Context, email string, passHash []byte) (
 	return id, nil
 }
 
-// User - получает пользователя по email.
+// User - получает пользователя по email из общего пространства (email_scope = 0).
 func (s *Storage) User(ctx context.Context, email string) (models.User, error) {
 	const op = "storage.sqlite.User"
 
-	stmt, err := s.db.Prepare("SELECT id, email, pass_hash FROM users WHERE email = ?")
+	user, err := s.userByEmail(ctx, 0, email)
 	if err != nil {
 		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	row := stmt.QueryRowContext(ctx, email)
+	return user, nil
+}
 
-	var user models.User
-	err = row.Scan(&user.ID, &user.Email, &user.PassHash) // записываем результат в структуру
+// userByEmail - пользователь с email в пространстве scope (0 - общее, иначе id организации)
+func (s *Storage) userByEmail(ctx context.Context, scope int64, email string) (models.User, error) {
+	match, arg := s.emailEquals("email", email)
+	row := s.read(ctx).QueryRowContext(ctx, `SELECT id, email, pass_hash, password_changed_at, force_password_change, phone
+		FROM users WHERE email_scope = ? AND `+match, scope, arg)
+
+	var (
+		user      models.User
+		changedAt sql.NullTime
+	)
+	err := row.Scan(&user.ID, &user.Email, &user.PassHash, &changedAt, &user.ForcePasswordChange, &user.Phone) // записываем результат в структуру
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+			return models.User{}, storage.ErrUserNotFound
 		}
 
-		return models.User{}, fmt.Errorf("%s: %w", op, err)
+		return models.User{}, err
+	}
+	user.PasswordChangedAt = changedAt.Time
+	if err := s.openUser(&user); err != nil {
+		return models.User{}, err
 	}
 
 	return user, nil
@@ -84,15 +155,10 @@ func (s *Storage) User(ctx context.Context, email string) (models.User, error) {
 func (s *Storage) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 	const op = "storage.sqlite.IsAdmin"
 
-	stmt, err := s.db.Prepare("SELECT is_admin FROM users WHERE id = ?")
-	if err != nil {
-		return false, fmt.Errorf("%s: %w", op, err)
-	}
-
-	row := stmt.QueryRowContext(ctx, userID)
+	row := s.read(ctx).QueryRowContext(ctx, "SELECT is_admin FROM users WHERE id = ?", userID)
 
 	var isAdmin bool
-	err = row.Scan(&isAdmin) // считываем булево значение
+	err := row.Scan(&isAdmin) // считываем булево значение
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
@@ -107,15 +173,10 @@ func (s *Storage) IsAdmin(ctx context.Context, userID int64) (bool, error) {
 func (s *Storage) IsUserExists(ctx context.Context, userID int64) (bool, error) {
 	const op = "storage.sqlite.IsUserExists"
 
-	stmt, err := s.db.Prepare("SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)")
-	if err != nil {
-		return false, fmt.Errorf("%s: %w", op, err)
-	}
-
-	row := stmt.QueryRowContext(ctx, userID)
+	row := s.read(ctx).QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = ? AND erased_at IS NULL)", userID)
 
 	var isUserExists bool
-	err = row.Scan(&isUserExists)
+	err := row.Scan(&isUserExists)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return false, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
@@ -131,22 +192,784 @@ func (s *Storage) IsUserExists(ctx context.Context, userID int64) (bool, error)
 func (s *Storage) App(ctx context.Context, id int) (models.App, error) {
 	const op = "storage.sqlite.App"
 
-	stmt, err := s.db.Prepare("SELECT id, name, secret FROM apps WHERE id = ?")
+	row := s.read(ctx).QueryRowContext(ctx, `SELECT id, name, secret, algorithm, signing_key, groups_claim, third_party,
+		max_sessions, session_limit_policy, session_idle_timeout, session_max_lifetime, min_acr, accept_offline_tokens, provisioner,
+		recheck_network_policy, refresh_sliding_window, refresh_max_lifetime FROM apps WHERE id = ?`, id)
+
+	var (
+		app                               models.App
+		idleTimeout, maxAge               int64
+		refreshWindow, refreshMaxLifetime int64
+	)
+	err := row.Scan(&app.ID, &app.Name, &app.Secret, &app.Algorithm, &app.SigningKey, &app.GroupsClaim, &app.ThirdParty,
+		&app.MaxSessions, &app.SessionLimitPolicy, &idleTimeout, &maxAge, &app.MinACR, &app.AcceptOfflineTokens, &app.Provisioner,
+		&app.RecheckNetworkPolicy, &refreshWindow, &refreshMaxLifetime) // заполняем структуру App
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.App{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+		}
+
 		return models.App{}, fmt.Errorf("%s: %w", op, err)
 	}
+	app.SessionIdleTimeout = time.Duration(idleTimeout) * time.Second
+	app.SessionMaxLifetime = time.Duration(maxAge) * time.Second
+	app.RefreshSlidingWindow = time.Duration(refreshWindow) * time.Second
+	app.RefreshMaxLifetime = time.Duration(refreshMaxLifetime) * time.Second
+
+	// Политики читаются вместе с приложением: кто кеширует приложение, кеширует и их
+	if app.LoginPolicies, err = s.AppLoginPolicies(ctx, app.ID); err != nil {
+		return models.App{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return app, nil
+}
+
+// migrationsTable - таблица, в которую cmd/migrator записывает версию схемы
+const migrationsTable = "migrations"
+
+// SchemaVersion - возвращает номер последней применённой миграции и признак прерванной миграции.
+func (s *Storage) SchemaVersion(ctx context.Context) (int64, bool, error) {
+	const op = "storage.sqlite.SchemaVersion"
+
+	row := s.db.QueryRowContext(ctx, "SELECT version, dirty FROM "+migrationsTable+" LIMIT 1")
+
+	var (
+		version int64
+		dirty   bool
+	)
+	if err := row.Scan(&version, &dirty); err != nil {
+		return 0, false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, dirty, nil
+}
+
+// UserMetadata - возвращает метаданные пользователя (JSON-объект из колонки metadata).
+func (s *Storage) UserMetadata(ctx context.Context, userID int64) ([]byte, error) {
+	const op = "storage.sqlite.UserMetadata"
+
+	row := s.db.QueryRowContext(ctx, "SELECT metadata FROM users WHERE id = ?", userID)
+
+	var metadata []byte
+	if err := row.Scan(&metadata); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+		}
+
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return metadata, nil
+}
+
+// UsersByIDs - получает пользователей по списку id одним запросом (WHERE id IN (...)).
+// Хеши паролей не выбираются. Отсутствующие и стёртые пользователи не попадают в результат
+func (s *Storage) UsersByIDs(ctx context.Context, userIDs []int64) ([]models.User, error) {
+	const op = "storage.sqlite.UsersByIDs"
+
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	args := make([]any, len(userIDs))
+	for i, id := range userIDs {
+		args[i] = id
+	}
+
+	query := "SELECT id, email, is_admin, is_active, version FROM users WHERE erased_at IS NULL AND id IN (?" +
+		strings.Repeat(", ?", len(userIDs)-1) + ")"
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	users := make([]models.User, 0, len(userIDs))
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Email, &u.IsAdmin, &u.IsActive, &u.Version); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := s.openUser(&u); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
 
-	row := stmt.QueryRowContext(ctx, id)
+	return users, nil
+}
 
-	var app models.App
-	err = row.Scan(&app.ID, &app.Name, &app.Secret) // заполняем структуру App
+// ListUsers - возвращает до limit пользователей с id больше afterID (по возрастанию id).
+// Если emailPrefix не пуст - только тех, чей email начинается с него (без учёта регистра).
+// Префикс ищется диапазоном по idx_users_email_lower, а не LIKE '%...%', который читает всю таблицу.
+// С шифрованием (WithPII) по префиксу искать нельзя: emailPrefix сравнивается с email целиком.
+// Гости попадают в список, только если includeGuests; пользователи самопроверки - никогда.
+// Если appID не 0 - только пользователи, связанные с этим приложением (user_apps)
+func (s *Storage) ListUsers(
+	ctx context.Context,
+	afterID int64,
+	limit int,
+	emailPrefix string,
+	includeGuests bool,
+	appID int,
+) ([]models.User, error) {
+	const op = "storage.sqlite.ListUsers"
+
+	query := `SELECT id, email, is_admin, is_active, version, email_verified, is_guest FROM users
+		WHERE id > ? AND selftest_at IS NULL`
+	args := []any{afterID}
+	if !includeGuests {
+		query += " AND NOT is_guest"
+	}
+	if appID != 0 {
+		query += " AND id IN (SELECT user_id FROM user_apps WHERE app_id = ?)"
+		args = append(args, appID)
+	}
+	switch {
+	case emailPrefix != "" && s.pii != nil:
+		match, arg := s.emailEquals("email", emailPrefix)
+		query += " AND " + match
+		args = append(args, arg)
+	case emailPrefix != "":
+		// Любой email с этим префиксом меньше prefix+0xFF: байт 0xFF не встречается в UTF-8
+		prefix := asciiLower(emailPrefix)
+		query += " AND lower(email) >= ? AND lower(email) < ?"
+		args = append(args, prefix, prefix+"\xff")
+	}
+	query += " ORDER BY id LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var u models.User
+		if err := rows.Scan(&u.ID, &u.Email, &u.IsAdmin, &u.IsActive, &u.Version, &u.EmailVerified, &u.IsGuest); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if err := s.openUser(&u); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return users, nil
+}
+
+// EmailCaseConflicts - группы email, различающихся только регистром (каждая группа по возрастанию id).
+// Из-за них не применяется миграция уникального индекса по lower(email). В зашифрованной БД (WithPII)
+// конфликтов нет: уникальность проверяет слепой индекс, который не зависит от регистра
+func (s *Storage) EmailCaseConflicts(ctx context.Context) ([][]string, error) {
+	const op = "storage.sqlite.EmailCaseConflicts"
+
+	if s.pii != nil {
+		return nil, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT lower(email), email FROM users
+		WHERE lower(email) IN (SELECT lower(email) FROM users GROUP BY lower(email) HAVING count(*) > 1)
+		ORDER BY lower(email), id`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var (
+		conflicts [][]string
+		last      string
+	)
+	for rows.Next() {
+		var key, email string
+		if err := rows.Scan(&key, &email); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+
+		if len(conflicts) == 0 || key != last {
+			conflicts = append(conflicts, nil)
+			last = key
+		}
+		conflicts[len(conflicts)-1] = append(conflicts[len(conflicts)-1], email)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return conflicts, nil
+}
+
+// IsBusy - ошибка SQLite из-за блокировки БД другим соединением (SQLITE_BUSY, SQLITE_LOCKED)
+func IsBusy(err error) bool {
+	var sqliteErr sqlite3.Error
+
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
+
+// asciiLower - strings.ToLower в понимании SQLite: lower() меняет регистр только у ASCII
+func asciiLower(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + ('a' - 'A')
+		}
+
+		return r
+	}, s)
+}
+
+// SetAdmin - выдаёт или отзывает права администратора и возвращает новую версию пользователя.
+// Если expectedVersion не 0 и не совпадает с текущей версией - storage.ErrVersionConflict
+func (s *Storage) SetAdmin(ctx context.Context, userID int64, isAdmin bool, expectedVersion int64) (int64, error) {
+	const op = "storage.sqlite.SetAdmin"
+
+	version, err := s.updateUser(ctx, "is_admin = ?", isAdmin, userID, expectedVersion)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, nil
+}
+
+// SetForcePasswordChange - требует (или перестаёт требовать) смены пароля при следующем входе.
+// Возвращает новую версию пользователя; expectedVersion - как в SetAdmin
+func (s *Storage) SetForcePasswordChange(ctx context.Context, userID int64, force bool, expectedVersion int64) (int64, error) {
+	const op = "storage.sqlite.SetForcePasswordChange"
+
+	version, err := s.updateUser(ctx, "force_password_change = ?", force, userID, expectedVersion)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return version, nil
+}
+
+// updateUser - изменяет одно поле пользователя (set - "поле = ?") с проверкой версии.
+// Проверка и изменение - один UPDATE, поэтому из двух конкурентных изменений одной версии проходит одно
+func (s *Storage) updateUser(ctx context.Context, set string, value any, userID, expectedVersion int64) (int64, error) {
+	var version int64
+	err := s.db.QueryRowContext(ctx, "UPDATE users SET "+set+`, version = version + 1
+		WHERE id = ? AND erased_at IS NULL AND (? = 0 OR version = ?) RETURNING version`,
+		value, userID, expectedVersion, expectedVersion).Scan(&version)
+	if err == nil {
+		return version, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+
+	// Ни одна строка не изменена: либо пользователя нет, либо версия устарела
+	exists, err := s.IsUserExists(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, storage.ErrUserNotFound
+	}
+
+	return 0, storage.ErrVersionConflict
+}
+
+// SaveApp - регистрирует новое приложение.
+func (s *Storage) SaveApp(ctx context.Context, name string, secret string) (int, error) {
+	const op = "storage.sqlite.SaveApp"
+
+	res, err := s.db.ExecContext(ctx, "INSERT INTO apps(name, secret) VALUES(?, ?)", name, secret)
+	if err != nil {
+		if isUnique(err) {
+			return 0, fmt.Errorf("%s: %w", op, storage.ErrAppExists)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return int(id), nil
+}
+
+// SetAppGroupsClaim - включает или выключает клейм groups в токенах приложения.
+func (s *Storage) SetAppGroupsClaim(ctx context.Context, appID int, enabled bool) error {
+	const op = "storage.sqlite.SetAppGroupsClaim"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET groups_claim = ? WHERE id = ?", enabled, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppSessionLimit - задаёт лимит одновременных сессий пользователя в приложении и политику его превышения.
+func (s *Storage) SetAppSessionLimit(ctx context.Context, appID int, maxSessions int, policy string) error {
+	const op = "storage.sqlite.SetAppSessionLimit"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET max_sessions = ?, session_limit_policy = ? WHERE id = ?",
+		maxSessions, policy, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppSessionTimeouts - задаёт тайм-аут бездействия и максимальное время жизни сессий приложения (0 - без ограничения).
+func (s *Storage) SetAppSessionTimeouts(ctx context.Context, appID int, idle, maxLifetime time.Duration) error {
+	const op = "storage.sqlite.SetAppSessionTimeouts"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET session_idle_timeout = ?, session_max_lifetime = ? WHERE id = ?",
+		int64(idle/time.Second), int64(maxLifetime/time.Second), appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppRefreshWindows - задаёт скользящее окно и максимальный срок refresh-токенов приложения
+// (0 - значения по умолчанию сервиса).
+func (s *Storage) SetAppRefreshWindows(ctx context.Context, appID int, sliding, maxLifetime time.Duration) error {
+	const op = "storage.sqlite.SetAppRefreshWindows"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET refresh_sliding_window = ?, refresh_max_lifetime = ? WHERE id = ?",
+		int64(sliding/time.Second), int64(maxLifetime/time.Second), appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppMinACR - задаёт минимальный уровень аутентификации приложения (пусто - любой).
+func (s *Storage) SetAppMinACR(ctx context.Context, appID int, minACR string) error {
+	const op = "storage.sqlite.SetAppMinACR"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET min_acr = ? WHERE id = ?", minACR, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppAcceptOfflineTokens - разрешает или запрещает приложению принимать offline-токены фоновых агентов.
+func (s *Storage) SetAppAcceptOfflineTokens(ctx context.Context, appID int, accept bool) error {
+	const op = "storage.sqlite.SetAppAcceptOfflineTokens"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET accept_offline_tokens = ? WHERE id = ?", accept, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppProvisioner - выдаёт приложению роль provisioner (провижининг пользователей) или забирает её.
+func (s *Storage) SetAppProvisioner(ctx context.Context, appID int, provisioner bool) error {
+	const op = "storage.sqlite.SetAppProvisioner"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET provisioner = ? WHERE id = ?", provisioner, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppRecheckNetworkPolicy - проверять ли сеть политик входа приложения и у уже выданных токенов.
+func (s *Storage) SetAppRecheckNetworkPolicy(ctx context.Context, appID int, recheck bool) error {
+	const op = "storage.sqlite.SetAppRecheckNetworkPolicy"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET recheck_network_policy = ? WHERE id = ?", recheck, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SetAppThirdParty - помечает приложение как стороннее (вход требует согласия) или внутреннее.
+func (s *Storage) SetAppThirdParty(ctx context.Context, appID int, thirdParty bool) error {
+	const op = "storage.sqlite.SetAppThirdParty"
+
+	res, err := s.db.ExecContext(ctx, "UPDATE apps SET third_party = ? WHERE id = ?", thirdParty, appID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+	}
+
+	return nil
+}
+
+// SaveUsers - сохраняет пачку пользователей в одной транзакции.
+// Возвращает результат по каждой строке: nil - создан, storage.ErrUserExists - такой email уже есть,
+// иная ошибка - строка не записана. Ошибка строки не откатывает остальные
+func (s *Storage) SaveUsers(ctx context.Context, users []models.User) ([]error, error) {
+	const op = "storage.sqlite.SaveUsers"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO users(email, email_index, pass_hash, is_admin, password_changed_at, created_at) VALUES(?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer stmt.Close()
+
+	results := make([]error, len(users))
+	for i, u := range users {
+		email, index, err := s.sealEmail(u.Email)
+		if err != nil {
+			results[i] = err
+			continue
+		}
+
+		// При нарушении ограничения SQLite откатывает только этот INSERT, транзакция продолжается
+		if _, err := stmt.ExecContext(ctx, email, index, u.PassHash, u.IsAdmin); err != nil {
+			if isUnique(err) {
+				results[i] = storage.ErrUserExists
+				continue
+			}
+
+			results[i] = err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return results, nil
+}
+
+// UserByID - получает пользователя по id (без хеша пароля).
+func (s *Storage) UserByID(ctx context.Context, userID int64) (models.User, error) {
+	const op = "storage.sqlite.UserByID"
+
+	row := s.read(ctx).QueryRowContext(ctx,
+		`SELECT id, email, is_admin, is_active, version, is_superadmin, email_verified, is_guest, phone
+		FROM users WHERE id = ? AND erased_at IS NULL`, userID)
+
+	var u models.User
+	if err := row.Scan(&u.ID, &u.Email, &u.IsAdmin, &u.IsActive, &u.Version, &u.IsSuperadmin, &u.EmailVerified,
+		&u.IsGuest, &u.Phone); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return models.App{}, fmt.Errorf("%s: %w", op, storage.ErrAppNotFound)
+			return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
 		}
 
-		return models.App{}, fmt.Errorf("%s: %w", op, err)
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.openUser(&u); err != nil {
+		return models.User{}, fmt.Errorf("%s: %w", op, err)
 	}
 
-	return app, nil
-}
\ No newline at end of file
+	return u, nil
+}
+
+// EraseUser - обезличивает пользователя: email заменяется на tombstone, хеш пароля, метаданные, телефон
+// и данные провижининга стираются, история входов (страны и сети) удаляется.
+// Строка и uid остаются, чтобы журнал безопасности продолжал ссылаться на существующий id
+func (s *Storage) EraseUser(ctx context.Context, userID int64, tombstone string) error {
+	const op = "storage.sqlite.EraseUser"
+
+	// tombstone - не персональные данные, но шифруется как любой email, чтобы в зашифрованной БД
+	// не было открытых строк
+	tombstone, index, err := s.sealEmail(tombstone)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	res, err := s.conn(ctx).ExecContext(ctx, `UPDATE users
+		SET email = ?, email_index = ?, pass_hash = X'', metadata = '{}', app_metadata = '{}', phone = '', is_admin = FALSE, is_active = FALSE, erased_at = CURRENT_TIMESTAMP,
+			external_id = NULL, provisioned_attributes = '{}', version = version + 1
+		WHERE id = ? AND erased_at IS NULL`, tombstone, index, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	if _, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM login_history WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Passkey обезличенного пользователя не должны открывать вход
+	if _, err := s.conn(ctx).ExecContext(ctx, "DELETE FROM passkeys WHERE user_id = ?", userID); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	// Номер телефона - персональные данные, в том числе в незавершённых вызовах и журнале отправок.
+	// Ожидающее удаление аккаунта после обезличивания не нужно (удаление администратором не ждёт срока)
+	for _, query := range []string{
+		"DELETE FROM sms_challenges WHERE user_id = ?",
+		"DELETE FROM sms_sends WHERE user_id = ?",
+		"DELETE FROM account_deletions WHERE user_id = ?",
+	} {
+		if _, err := s.conn(ctx).ExecContext(ctx, query, userID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// PasswordHistory - хеши предыдущих паролей пользователя, от новых к старым (не больше limit).
+func (s *Storage) PasswordHistory(ctx context.Context, userID int64, limit int) ([][]byte, error) {
+	const op = "storage.sqlite.PasswordHistory"
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT pass_hash FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?", userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var hashes [][]byte
+	for rows.Next() {
+		var h []byte
+		if err := rows.Scan(&h); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		hashes = append(hashes, h)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return hashes, nil
+}
+
+// UpdatePassword - меняет хеш пароля в одной транзакции: текущий хеш уходит в историю,
+// в истории остаются только keep последних записей.
+func (s *Storage) UpdatePassword(ctx context.Context, userID int64, passHash []byte, keep int) error {
+	const op = "storage.sqlite.UpdatePassword"
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if keep > 0 {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO password_history(user_id, pass_hash) SELECT id, pass_hash FROM users WHERE id = ?", userID); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE users SET pass_hash = ?, password_changed_at = CURRENT_TIMESTAMP, force_password_change = FALSE,
+		version = version + 1 WHERE id = ? AND erased_at IS NULL`, passHash, userID)
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	} else if n == 0 {
+		return fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	// Удаляем всё, что старше keep последних записей
+	if _, err := tx.ExecContext(ctx, `DELETE FROM password_history WHERE user_id = ? AND id NOT IN (
+		SELECT id FROM password_history WHERE user_id = ? ORDER BY id DESC LIMIT ?)`, userID, userID, keep); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// RehashPassword - заменяет хеш пароля, если он всё ещё равен oldHash (иначе пароль успели сменить).
+// История, срок пароля и версия пользователя не меняются: пароль тот же, изменился только хеш
+func (s *Storage) RehashPassword(ctx context.Context, userID int64, oldHash, newHash []byte) error {
+	const op = "storage.sqlite.RehashPassword"
+
+	if _, err := s.db.ExecContext(ctx,
+		"UPDATE users SET pass_hash = ? WHERE id = ? AND pass_hash = ? AND erased_at IS NULL",
+		newHash, userID, oldHash); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// AddOutboxEvent - записывает событие в outbox (внутри WithinTx - в той же транзакции, что и изменение состояния).
+func (s *Storage) AddOutboxEvent(ctx context.Context, eventID, eventType string, payload []byte) error {
+	const op = "storage.sqlite.AddOutboxEvent"
+
+	if _, err := s.conn(ctx).ExecContext(ctx,
+		"INSERT INTO outbox(event_id, type, payload) VALUES(?, ?, ?)", eventID, eventType, payload); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// PendingOutboxEvents - до limit неотправленных событий по возрастанию seq.
+func (s *Storage) PendingOutboxEvents(ctx context.Context, limit int) ([]models.OutboxEvent, error) {
+	const op = "storage.sqlite.PendingOutboxEvents"
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT seq, payload, created_at FROM outbox WHERE sent_at IS NULL ORDER BY seq LIMIT ?", limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var res []models.OutboxEvent
+	for rows.Next() {
+		var e models.OutboxEvent
+		if err := rows.Scan(&e.Seq, &e.Payload, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		res = append(res, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return res, nil
+}
+
+// MarkOutboxSent - отмечает события отправленными.
+func (s *Storage) MarkOutboxSent(ctx context.Context, seqs []int64) error {
+	const op = "storage.sqlite.MarkOutboxSent"
+
+	if len(seqs) == 0 {
+		return nil
+	}
+
+	args := make([]any, len(seqs))
+	for i, seq := range seqs {
+		args[i] = seq
+	}
+
+	query := "UPDATE outbox SET sent_at = CURRENT_TIMESTAMP WHERE seq IN (?" + strings.Repeat(", ?", len(seqs)-1) + ")"
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// OutboxLag - количество неотправленных событий и время записи самого старого из них
+// (нулевое, если очередь пуста).
+func (s *Storage) OutboxLag(ctx context.Context) (int64, time.Time, error) {
+	const op = "storage.sqlite.OutboxLag"
+
+	var pending int64
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM outbox WHERE sent_at IS NULL").Scan(&pending); err != nil {
+		return 0, time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	if pending == 0 {
+		return 0, time.Time{}, nil
+	}
+
+	var oldest time.Time
+	err := s.db.QueryRowContext(ctx,
+		"SELECT created_at FROM outbox WHERE sent_at IS NULL ORDER BY seq LIMIT 1").Scan(&oldest)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, time.Time{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pending, oldest, nil
+}
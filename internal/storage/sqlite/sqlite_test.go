@@ -0,0 +1,641 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStorage - создаёт временную БД с применёнными миграциями
+func newTestStorage(tb testing.TB, opts ...Option) *Storage {
+	tb.Helper()
+
+	s, err := New(newTestDB(tb), opts...)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
+
+// newTestDB - создаёт файл временной БД с применёнными миграциями и возвращает путь к нему
+func newTestDB(tb testing.TB) string {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "sso.db")
+
+	m, err := migrate.New("file://../../../migrations",
+		fmt.Sprintf("sqlite3://%s?x-migrations-table=%s", path, migrationsTable))
+	require.NoError(tb, err)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(tb, err)
+	}
+	srcErr, dbErr := m.Close()
+	require.NoError(tb, srcErr)
+	require.NoError(tb, dbErr)
+
+	return path
+}
+
+// seedUsers - создаёт n пользователей и возвращает их id
+func seedUsers(tb testing.TB, s *Storage, n int) []int64 {
+	tb.Helper()
+
+	ids := make([]int64, 0, n)
+	for i := range n {
+		id, err := s.SaveUser(context.Background(), fmt.Sprintf("user%d@example.com", i), []byte("hash"))
+		require.NoError(tb, err)
+		ids = append(ids, id)
+	}
+
+	return ids
+}
+
+func TestSchemaVersion(t *testing.T) {
+	s := newTestStorage(t)
+
+	version, dirty, err := s.SchemaVersion(context.Background())
+	require.NoError(t, err)
+	assert.Positive(t, version)
+	assert.False(t, dirty)
+}
+
+func TestUsersByIDs(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 3)
+
+	users, err := s.UsersByIDs(context.Background(), []int64{ids[0], ids[2], 999})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+
+	emails := []string{users[0].Email, users[1].Email}
+	assert.ElementsMatch(t, []string{"user0@example.com", "user2@example.com"}, emails)
+	assert.True(t, users[0].IsActive)
+	assert.Nil(t, users[0].PassHash)
+
+	users, err = s.UsersByIDs(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestSetAdmin(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	_, err := s.SetAdmin(ctx, ids[0], true, 0)
+	require.NoError(t, err)
+
+	isAdmin, err := s.IsAdmin(ctx, ids[0])
+	require.NoError(t, err)
+	assert.True(t, isAdmin)
+
+	_, err = s.SetAdmin(ctx, 999, true, 0)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func TestUserVersion(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	user, err := s.UserByID(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), user.Version)
+
+	// Каждое изменение увеличивает версию
+	v, err := s.SetAdmin(ctx, ids[0], true, user.Version)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), v)
+
+	v, err = s.SetForcePasswordChange(ctx, ids[0], true, v)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), v)
+
+	require.NoError(t, s.UpdatePassword(ctx, ids[0], []byte("new-hash"), 0))
+	user, err = s.UserByID(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), user.Version)
+
+	// Изменение по устаревшей версии отклоняется и ничего не меняет
+	_, err = s.SetAdmin(ctx, ids[0], false, 2)
+	require.ErrorIs(t, err, storage.ErrVersionConflict)
+	_, err = s.SetForcePasswordChange(ctx, ids[0], true, 3)
+	require.ErrorIs(t, err, storage.ErrVersionConflict)
+
+	isAdmin, err := s.IsAdmin(ctx, ids[0])
+	require.NoError(t, err)
+	assert.True(t, isAdmin)
+
+	users, err := s.ListUsers(ctx, 0, 10, "", false, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, int64(4), users[0].Version)
+
+	// Для отсутствующего пользователя - не найден, а не конфликт
+	_, err = s.SetAdmin(ctx, 999, true, 1)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+// Из конкурентных изменений одной версии проходит ровно одно
+func TestUserVersion_ConcurrentUpdates(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	const writers = 8
+
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		ok        int
+		conflicts int
+	)
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.SetAdmin(ctx, ids[0], i%2 == 0, 1)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, storage.ErrVersionConflict):
+				conflicts++
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, ok)
+	assert.Equal(t, writers-1, conflicts)
+}
+
+// Из конкурентных регистраций одного email проходит ровно одна, остальные получают ErrUserExists,
+// даже если транзакция сначала читает (повышение блокировки не даёт SQLITE_BUSY)
+func TestSaveUser_ConcurrentDuplicates(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	const writers = 10
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		ok     int
+		exists int
+	)
+	for range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := s.WithinTx(ctx, func(ctx context.Context) error {
+				var n int
+				if err := s.conn(ctx).QueryRowContext(ctx,
+					"SELECT COUNT(*) FROM users WHERE email = ?", "race@b.c").Scan(&n); err != nil {
+					return err
+				}
+				if n > 0 {
+					return storage.ErrUserExists
+				}
+				_, err := s.SaveUser(ctx, "race@b.c", []byte("hash"))
+
+				return err
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				ok++
+			case errors.Is(err, storage.ErrUserExists):
+				exists++
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, ok)
+	assert.Equal(t, writers-1, exists)
+}
+
+func TestWithTxLock(t *testing.T) {
+	assert.Equal(t, "sso.db?_txlock=immediate", withTxLock("sso.db"))
+	assert.Equal(t, "file:sso.db?cache=shared&_txlock=immediate", withTxLock("file:sso.db?cache=shared"))
+	assert.Equal(t, "sso.db?_txlock=exclusive", withTxLock("sso.db?_txlock=exclusive"))
+}
+
+func TestListUsers(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 3)
+
+	users, err := s.ListUsers(context.Background(), ids[0], 10, "", false, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, ids[1], users[0].ID)
+	assert.Equal(t, ids[2], users[1].ID)
+}
+
+func TestListUsers_EmailPrefix(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	var ids []int64
+	for _, email := range []string{"Alice@example.com", "alex@example.com", "bob@example.com", "al@example.org"} {
+		id, err := s.SaveUser(ctx, email, []byte("hash"))
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+
+	users, err := s.ListUsers(ctx, 0, 10, "AL", false, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 3)
+	assert.Equal(t, "Alice@example.com", users[0].Email)
+	assert.Equal(t, "alex@example.com", users[1].Email)
+	assert.Equal(t, "al@example.org", users[2].Email)
+
+	// Пагинация по id работает и с фильтром
+	users, err = s.ListUsers(ctx, ids[1], 10, "al", false, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, ids[3], users[0].ID)
+
+	users, err = s.ListUsers(ctx, 0, 10, "ale", false, 0)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, "alex@example.com", users[0].Email)
+}
+
+// Поиск по email идёт по индексу, а не полным перебором таблицы
+func TestEmailQueriesUseIndex(t *testing.T) {
+	s := newTestStorage(t)
+
+	tests := []struct {
+		name  string
+		query string
+		args  []any
+		index string
+	}{
+		{
+			name:  "User",
+			query: "SELECT id FROM users WHERE email_scope = ? AND lower(email) = lower(?)",
+			args:  []any{0, "a@b.c"},
+			index: "idx_users_scope_email",
+		},
+		{
+			name:  "ListUsers by prefix",
+			query: "SELECT id FROM users WHERE id > ? AND lower(email) >= ? AND lower(email) < ? ORDER BY id LIMIT ?",
+			args:  []any{0, "al", "al\xff", 100},
+			index: "idx_users_email_lower",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := s.db.Query("EXPLAIN QUERY PLAN "+tt.query, tt.args...)
+			require.NoError(t, err)
+			defer rows.Close()
+
+			var plan []string
+			for rows.Next() {
+				var id, parent, notUsed int
+				var detail string
+				require.NoError(t, rows.Scan(&id, &parent, &notUsed, &detail))
+				plan = append(plan, detail)
+			}
+			require.NoError(t, rows.Err())
+
+			assert.Contains(t, strings.Join(plan, "\n"), tt.index)
+		})
+	}
+}
+
+func TestUser_CaseInsensitive(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.SaveUser(ctx, "Mixed@Example.com", []byte("hash"))
+	require.NoError(t, err)
+
+	user, err := s.User(ctx, "mixed@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, id, user.ID)
+	assert.Equal(t, "Mixed@Example.com", user.Email, "email is stored as registered")
+
+	_, err = s.SaveUser(ctx, "MIXED@example.com", []byte("hash"))
+	require.ErrorIs(t, err, storage.ErrUserExists)
+}
+
+// Миграция индекса по lower(email) не применяется, если есть адреса, различающиеся только регистром
+func TestMigration_EmailCaseConflicts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sso.db")
+
+	m, err := migrate.New("file://../../../migrations",
+		fmt.Sprintf("sqlite3://%s?x-migrations-table=%s", path, migrationsTable))
+	require.NoError(t, err)
+	defer m.Close()
+
+	require.NoError(t, m.Migrate(9))
+
+	s, err := New(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	// SaveUser пишет колонки из более поздних миграций, поэтому пользователи вставляются напрямую
+	for _, email := range []string{"dup@example.com", "Dup@Example.com"} {
+		_, err := s.db.Exec("INSERT INTO users(email, pass_hash) VALUES(?, ?)", email, []byte("hash"))
+		require.NoError(t, err)
+	}
+
+	err = m.Up()
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "found emails that differ only by case")
+
+	conflicts, err := s.EmailCaseConflicts(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"dup@example.com", "Dup@Example.com"}}, conflicts)
+}
+
+// BenchmarkEmailSearch - поиск по email среди 100k пользователей: подстрока (LIKE '%...%', полный перебор)
+// против префикса по индексу idx_users_email_lower
+func BenchmarkEmailSearch(b *testing.B) {
+	s := newTestStorage(b)
+	ctx := context.Background()
+
+	_, err := s.db.ExecContext(ctx, `WITH RECURSIVE seq(n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM seq WHERE n < 100000)
+		INSERT INTO users (email, pass_hash) SELECT 'user' || n || '@example.com', X'00' FROM seq`)
+	require.NoError(b, err)
+
+	b.Run("substring", func(b *testing.B) {
+		for range b.N {
+			rows, err := s.db.QueryContext(ctx,
+				"SELECT id, email, is_admin, is_active FROM users WHERE lower(email) LIKE ? ORDER BY id LIMIT 100", "%user9999%")
+			if err != nil {
+				b.Fatal(err)
+			}
+			for rows.Next() {
+			}
+			if err := rows.Close(); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("prefix", func(b *testing.B) {
+		for range b.N {
+			if _, err := s.ListUsers(ctx, 0, 100, "user9999", false, 0); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("User", func(b *testing.B) {
+		for range b.N {
+			if _, err := s.User(ctx, "USER50000@example.com"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestSaveApp(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	id, err := s.SaveApp(ctx, "partner", "secret-1")
+	require.NoError(t, err)
+
+	app, err := s.App(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "partner", app.Name)
+	assert.Equal(t, "HS256", app.Algorithm)
+
+	_, err = s.SaveApp(ctx, "partner", "secret-2")
+	require.ErrorIs(t, err, storage.ErrAppExists)
+}
+
+func TestSaveUsers(t *testing.T) {
+	s := newTestStorage(t)
+	seedUsers(t, s, 1) // user0@example.com
+
+	results, err := s.SaveUsers(context.Background(), []models.User{
+		{Email: "new@example.com", PassHash: []byte("hash"), IsAdmin: true},
+		{Email: "user0@example.com", PassHash: []byte("hash")},
+		{Email: "new@example.com", PassHash: []byte("hash")},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.NoError(t, results[0])
+	assert.ErrorIs(t, results[1], storage.ErrUserExists)
+	assert.ErrorIs(t, results[2], storage.ErrUserExists)
+
+	user, err := s.User(context.Background(), "new@example.com")
+	require.NoError(t, err)
+
+	isAdmin, err := s.IsAdmin(context.Background(), user.ID)
+	require.NoError(t, err)
+	assert.True(t, isAdmin)
+}
+
+func TestEraseUser(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 2)
+	ctx := context.Background()
+
+	require.NoError(t, s.EraseUser(ctx, ids[0], "erased-1@erased.invalid"))
+
+	_, err := s.User(ctx, "user0@example.com")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	exists, err := s.IsUserExists(ctx, ids[0])
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = s.UserByID(ctx, ids[0])
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	users, err := s.UsersByIDs(ctx, ids)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, ids[1], users[0].ID)
+
+	// Повторное стирание - пользователя уже нет
+	require.ErrorIs(t, s.EraseUser(ctx, ids[0], "erased-1@erased.invalid"), storage.ErrUserNotFound)
+}
+
+// BenchmarkUserLookup - 1000 отдельных IsUserExists против одного UsersByIDs
+func BenchmarkUserLookup(b *testing.B) {
+	s := newTestStorage(b)
+	ids := seedUsers(b, s, 1000)
+	ctx := context.Background()
+
+	b.Run("IsUserExists x1000", func(b *testing.B) {
+		for range b.N {
+			for _, id := range ids {
+				if _, err := s.IsUserExists(ctx, id); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+
+	b.Run("UsersByIDs x1", func(b *testing.B) {
+		for range b.N {
+			if _, err := s.UsersByIDs(ctx, ids); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func TestUpdatePassword_PrunesHistory(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	for _, h := range []string{"hash-1", "hash-2", "hash-3"} {
+		require.NoError(t, s.UpdatePassword(ctx, ids[0], []byte(h), 2))
+	}
+
+	user, err := s.User(ctx, "user0@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hash-3"), user.PassHash)
+
+	// Исходный хеш вытеснен, остались два последних предыдущих
+	history, err := s.PasswordHistory(ctx, ids[0], 10)
+	require.NoError(t, err)
+	assert.Equal(t, [][]byte{[]byte("hash-2"), []byte("hash-1")}, history)
+
+	require.ErrorIs(t, s.UpdatePassword(ctx, 999, []byte("x"), 2), storage.ErrUserNotFound)
+}
+
+func TestRehashPassword(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	require.NoError(t, s.UpdatePassword(ctx, ids[0], []byte("legacy"), 0))
+	before, err := s.User(ctx, "user0@example.com")
+	require.NoError(t, err)
+
+	require.NoError(t, s.RehashPassword(ctx, ids[0], []byte("legacy"), []byte("normalized")))
+	user, err := s.User(ctx, "user0@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("normalized"), user.PassHash)
+	assert.Equal(t, before.PasswordChangedAt, user.PasswordChangedAt)
+
+	history, err := s.PasswordHistory(ctx, ids[0], 10)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+
+	// Пароль успели сменить - хеш не перетирается
+	require.NoError(t, s.RehashPassword(ctx, ids[0], []byte("legacy"), []byte("stale")))
+	user, err = s.User(ctx, "user0@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("normalized"), user.PassHash)
+}
+
+func TestForcePasswordChange_ClearedOnUpdate(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 1)
+	ctx := context.Background()
+
+	user, err := s.User(ctx, "user0@example.com")
+	require.NoError(t, err)
+	assert.False(t, user.ForcePasswordChange)
+	assert.False(t, user.PasswordChangedAt.IsZero())
+
+	_, err = s.SetForcePasswordChange(ctx, ids[0], true, 0)
+	require.NoError(t, err)
+	user, err = s.User(ctx, "user0@example.com")
+	require.NoError(t, err)
+	assert.True(t, user.ForcePasswordChange)
+
+	require.NoError(t, s.UpdatePassword(ctx, ids[0], []byte("new-hash"), 0))
+	user, err = s.User(ctx, "user0@example.com")
+	require.NoError(t, err)
+	assert.False(t, user.ForcePasswordChange)
+
+	_, err = s.SetForcePasswordChange(ctx, 999, true, 0)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func TestWithinTx_RollsBackOnError(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	errBoom := errors.New("boom")
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.SaveUser(ctx, "tx@example.com", []byte("hash")); err != nil {
+			return err
+		}
+		if err := s.AddOutboxEvent(ctx, "event-1", "user.registered", []byte(`{}`)); err != nil {
+			return err
+		}
+
+		return errBoom
+	})
+	require.ErrorIs(t, err, errBoom)
+
+	_, err = s.User(ctx, "tx@example.com")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	pending, err := s.PendingOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+}
+
+func TestOutbox(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range 3 {
+			if err := s.AddOutboxEvent(ctx, fmt.Sprintf("event-%d", i), "user.registered", []byte(`{}`)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}))
+
+	pending, err := s.PendingOutboxEvents(ctx, 2)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Less(t, pending[0].Seq, pending[1].Seq)
+
+	count, oldest, err := s.OutboxLag(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.False(t, oldest.IsZero())
+
+	require.NoError(t, s.MarkOutboxSent(ctx, []int64{pending[0].Seq, pending[1].Seq}))
+
+	rest, err := s.PendingOutboxEvents(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, rest, 1)
+	assert.Greater(t, rest[0].Seq, pending[1].Seq)
+
+	require.NoError(t, s.MarkOutboxSent(ctx, []int64{rest[0].Seq}))
+	count, oldest, err = s.OutboxLag(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	assert.True(t, oldest.IsZero())
+}
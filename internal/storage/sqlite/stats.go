@@ -0,0 +1,81 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"time"
+)
+
+// Stats - сводка для панели администратора на момент now. Если appID не 0, сессии и входы считаются
+// только по этому приложению, а пользователи - только связанные с ним (user_apps), если appUsers;
+// иначе счётчики пользователей общие.
+// Каждый подсчёт идёт по своему индексу: пользователи - по idx_users_created_at и idx_users_inactive,
+// сессии, входы и неудачные входы - по диапазону времени. Данные самопроверки (selftest_at) не учитываются
+func (s *Storage) Stats(ctx context.Context, appID int, appUsers bool, now time.Time) (models.Stats, error) {
+	const op = "storage.sqlite.Stats"
+
+	now = now.UTC()
+	hourAgo := now.Add(-time.Hour)
+
+	// usersApp - приложение, которым ограничены счётчики пользователей (0 - все пользователи)
+	var usersApp int
+	if appUsers {
+		usersApp = appID
+	}
+	const inApp = " AND (? = 0 OR id IN (SELECT user_id FROM user_apps WHERE app_id = ?))"
+
+	st := models.Stats{GeneratedAt: now}
+	err := s.db.QueryRowContext(ctx, `SELECT
+		(SELECT COUNT(*) FROM users INDEXED BY idx_users_created_at
+			WHERE erased_at IS NULL AND is_guest = FALSE AND selftest_at IS NULL`+inApp+`),
+		(SELECT COUNT(*) FROM users WHERE erased_at IS NULL AND is_guest = FALSE AND selftest_at IS NULL AND created_at >= ?`+inApp+`),
+		(SELECT COUNT(*) FROM users WHERE erased_at IS NULL AND is_guest = FALSE AND selftest_at IS NULL AND created_at >= ?`+inApp+`),
+		(SELECT COUNT(*) FROM users WHERE erased_at IS NULL AND is_guest = FALSE AND selftest_at IS NULL AND created_at >= ?`+inApp+`),
+		(SELECT COUNT(*) FROM users WHERE erased_at IS NULL AND is_active = FALSE AND selftest_at IS NULL`+inApp+`),
+		(SELECT COUNT(*) FROM sessions WHERE expires_at > ? AND revoked_at IS NULL AND (? = 0 OR app_id = ?)
+			AND app_id NOT IN (SELECT id FROM apps WHERE selftest_at IS NOT NULL)),
+		(SELECT COUNT(*) FROM login_history WHERE logged_in_at >= ? AND (? = 0 OR app_id = ?)
+			AND app_id NOT IN (SELECT id FROM apps WHERE selftest_at IS NOT NULL)),
+		(SELECT COUNT(*) FROM login_failures WHERE failed_at >= ? AND (? = 0 OR app_id = ?)
+			AND app_id NOT IN (SELECT id FROM apps WHERE selftest_at IS NOT NULL))`,
+		usersApp, usersApp,
+		now.Add(-24*time.Hour), usersApp, usersApp,
+		now.Add(-7*24*time.Hour), usersApp, usersApp,
+		now.Add(-30*24*time.Hour), usersApp, usersApp,
+		usersApp, usersApp,
+		now, appID, appID,
+		hourAgo, appID, appID,
+		hourAgo, appID, appID,
+	).Scan(
+		&st.TotalUsers, &st.RegisteredLast24h, &st.RegisteredLast7d, &st.RegisteredLast30d, &st.LockedUsers,
+		&st.ActiveSessions, &st.LoginsLastHour, &st.FailedLoginsLastHour,
+	)
+	if err != nil {
+		return models.Stats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return st, nil
+}
+
+// Backlog - сколько сейчас ожидающих приглашений, отключённых пользователей и активных сессий
+// (без данных самопроверки).
+func (s *Storage) Backlog(ctx context.Context, now time.Time) (models.Backlog, error) {
+	const op = "storage.sqlite.Backlog"
+
+	now = now.UTC()
+
+	var b models.Backlog
+	err := s.db.QueryRowContext(ctx, `SELECT
+		(SELECT COUNT(*) FROM invitations WHERE accepted_at IS NULL AND revoked_at IS NULL AND expires_at > ?),
+		(SELECT COUNT(*) FROM users WHERE erased_at IS NULL AND is_active = FALSE AND selftest_at IS NULL),
+		(SELECT COUNT(*) FROM sessions WHERE expires_at > ? AND revoked_at IS NULL
+			AND app_id NOT IN (SELECT id FROM apps WHERE selftest_at IS NOT NULL))`,
+		now, now,
+	).Scan(&b.PendingInvitations, &b.LockedUsers, &b.ActiveSessions)
+	if err != nil {
+		return models.Backlog{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return b, nil
+}
@@ -0,0 +1,140 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/domain/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	ids := seedUsers(t, s, 5)
+	// Пользователи зарегистрированы 2 дня, 10 дней и год назад; ещё один - до появления created_at
+	for i, age := range []time.Duration{48 * time.Hour, 240 * time.Hour, 365 * 24 * time.Hour} {
+		_, err := s.db.Exec("UPDATE users SET created_at = ? WHERE id = ?", now.Add(-age).UTC(), ids[i+1])
+		require.NoError(t, err)
+	}
+	_, err := s.db.Exec("UPDATE users SET created_at = NULL WHERE id = ?", ids[4])
+	require.NoError(t, err)
+	_, err = s.db.Exec("UPDATE users SET is_active = FALSE WHERE id = ?", ids[3])
+	require.NoError(t, err)
+	_, err = s.SaveGuest(ctx, "guest-1")
+	require.NoError(t, err)
+
+	for _, sess := range []models.Session{
+		newSession("a1", ids[0], 1),
+		newSession("a2", ids[1], 1),
+		newSession("b1", ids[0], 2),
+		{ID: "expired", UserID: ids[0], AppID: 1, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	} {
+		_, err := s.CreateSession(ctx, sess, 0, false)
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.RevokeSession(ctx, "a2", now))
+
+	for _, r := range []models.LoginRecord{
+		{UserID: ids[0], AppID: 1, LoggedInAt: now.Add(-time.Minute)},
+		{UserID: ids[0], AppID: 2, LoggedInAt: now.Add(-time.Minute)},
+		{UserID: ids[1], AppID: 1, LoggedInAt: now.Add(-2 * time.Hour)},
+	} {
+		require.NoError(t, s.AddLogin(ctx, r))
+	}
+	require.NoError(t, s.AddLoginFailure(ctx, 1, now.Add(-time.Minute)))
+	require.NoError(t, s.AddLoginFailure(ctx, 1, now.Add(-2*time.Hour)))
+	require.NoError(t, s.AddLoginFailure(ctx, 2, now.Add(-time.Minute)))
+
+	st, err := s.Stats(ctx, 0, false, now)
+	require.NoError(t, err)
+	assert.Equal(t, models.Stats{
+		TotalUsers:           5,
+		RegisteredLast24h:    1,
+		RegisteredLast7d:     2,
+		RegisteredLast30d:    3,
+		LockedUsers:          1,
+		ActiveSessions:       2,
+		LoginsLastHour:       2,
+		FailedLoginsLastHour: 2,
+		GeneratedAt:          now.UTC(),
+	}, st)
+
+	// По приложению считаются только сессии и входы
+	st, err = s.Stats(ctx, 1, false, now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 5, st.TotalUsers)
+	assert.EqualValues(t, 1, st.ActiveSessions)
+	assert.EqualValues(t, 1, st.LoginsLastHour)
+	assert.EqualValues(t, 1, st.FailedLoginsLastHour)
+
+	n, err := s.DeleteLoginFailuresBefore(ctx, now.Add(-time.Hour), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+// Подсчёты идут по индексам, а не перебором таблиц
+func TestBacklog(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	ids := seedUsers(t, s, 3)
+	_, err := s.db.Exec("UPDATE users SET is_active = FALSE WHERE id = ?", ids[2])
+	require.NoError(t, err)
+
+	for _, sess := range []models.Session{
+		newSession("a1", ids[0], 1),
+		newSession("a2", ids[1], 2),
+		{ID: "expired", UserID: ids[0], AppID: 1, CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)},
+	} {
+		_, err := s.CreateSession(ctx, sess, 0, false)
+		require.NoError(t, err)
+	}
+
+	for i, expires := range []time.Time{now.Add(time.Hour), now.Add(time.Hour), now.Add(-time.Hour)} {
+		_, _, err := s.SaveInvitation(ctx, models.Invitation{
+			Email: fmt.Sprintf("invitee%d@example.com", i), TokenHash: fmt.Sprintf("hash-%d", i),
+			AppID: 1, Role: models.RoleUser, ExpiresAt: expires,
+		})
+		require.NoError(t, err)
+	}
+	_, err = s.db.Exec("UPDATE invitations SET revoked_at = ? WHERE token_hash = 'hash-1'", now.UTC())
+	require.NoError(t, err)
+
+	b, err := s.Backlog(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, models.Backlog{PendingInvitations: 1, LockedUsers: 1, ActiveSessions: 2}, b)
+}
+
+func TestStats_UsesIndexes(t *testing.T) {
+	s := newTestStorage(t)
+
+	for _, q := range []string{
+		"SELECT COUNT(*) FROM users WHERE erased_at IS NULL AND is_guest = FALSE AND created_at >= ?",
+		"SELECT COUNT(*) FROM users WHERE erased_at IS NULL AND is_active = FALSE",
+		"SELECT COUNT(*) FROM sessions WHERE expires_at > ? AND revoked_at IS NULL AND (? = 0 OR app_id = ?)",
+		"SELECT COUNT(*) FROM login_history WHERE logged_in_at >= ? AND (? = 0 OR app_id = ?)",
+		"SELECT COUNT(*) FROM login_failures WHERE failed_at >= ? AND (? = 0 OR app_id = ?)",
+	} {
+		rows, err := s.db.Query("EXPLAIN QUERY PLAN "+q, time.Now(), 1, 1)
+		require.NoError(t, err)
+
+		var plan string
+		for rows.Next() {
+			var id, parent, notUsed int
+			var detail string
+			require.NoError(t, rows.Scan(&id, &parent, &notUsed, &detail))
+			plan += detail + "\n"
+		}
+		require.NoError(t, rows.Err())
+		rows.Close()
+
+		assert.Contains(t, plan, "INDEX", q)
+	}
+}
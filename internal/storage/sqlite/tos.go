@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/storage"
+)
+
+// AcceptTOS - записывает принятие пользователем версии условий использования.
+// Внутри WithinTx выполняется в общей транзакции.
+func (s *Storage) AcceptTOS(ctx context.Context, userID int64, version string) error {
+	const op = "storage.sqlite.AcceptTOS"
+
+	if _, err := s.conn(ctx).ExecContext(ctx,
+		"INSERT INTO tos_acceptances(user_id, version) VALUES(?, ?)", userID, version); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// LatestTOSAcceptance - последнее принятие условий пользователем; не принимал - storage.ErrTOSNotAccepted.
+func (s *Storage) LatestTOSAcceptance(ctx context.Context, userID int64) (models.TOSAcceptance, error) {
+	const op = "storage.sqlite.LatestTOSAcceptance"
+
+	a := models.TOSAcceptance{UserID: userID}
+	err := s.db.QueryRowContext(ctx, `SELECT version, accepted_at FROM tos_acceptances
+		WHERE user_id = ? ORDER BY id DESC LIMIT 1`, userID).Scan(&a.Version, &a.AcceptedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.TOSAcceptance{}, fmt.Errorf("%s: %w", op, storage.ErrTOSNotAccepted)
+		}
+
+		return models.TOSAcceptance{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return a, nil
+}
+
+// UsersPendingTOS - пользователи, чьё последнее принятие условий не совпадает с version
+// (включая не принимавших никакую), по возрастанию id, начиная после afterID.
+// Удалённые по GDPR пользователи и гости не попадают в отчёт.
+func (s *Storage) UsersPendingTOS(ctx context.Context, version string, afterID int64, limit int) ([]models.PendingTOS, error) {
+	const op = "storage.sqlite.UsersPendingTOS"
+
+	rows, err := s.db.QueryContext(ctx, `SELECT u.id, u.email, t.version, t.accepted_at FROM users u
+		LEFT JOIN tos_acceptances t ON t.id = (SELECT max(id) FROM tos_acceptances WHERE user_id = u.id)
+		WHERE u.id > ? AND u.erased_at IS NULL AND NOT u.is_guest AND (t.version IS NULL OR t.version <> ?)
+		ORDER BY u.id LIMIT ?`, afterID, version, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	var pending []models.PendingTOS
+	for rows.Next() {
+		var (
+			p          models.PendingTOS
+			accepted   sql.NullString
+			acceptedAt sql.NullTime
+		)
+		if err := rows.Scan(&p.UserID, &p.Email, &accepted, &acceptedAt); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		if p.Email, err = s.open(p.Email); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		p.Version, p.AcceptedAt = accepted.String, acceptedAt.Time
+		pending = append(pending, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return pending, nil
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTOSAcceptances(t *testing.T) {
+	s := newTestStorage(t)
+	ids := seedUsers(t, s, 3)
+	ctx := context.Background()
+
+	_, err := s.LatestTOSAcceptance(ctx, ids[0])
+	require.ErrorIs(t, err, storage.ErrTOSNotAccepted)
+
+	require.NoError(t, s.AcceptTOS(ctx, ids[0], "2024-01"))
+	require.NoError(t, s.AcceptTOS(ctx, ids[0], "2025-01"))
+	require.NoError(t, s.AcceptTOS(ctx, ids[1], "2024-01"))
+
+	latest, err := s.LatestTOSAcceptance(ctx, ids[0])
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01", latest.Version)
+	assert.False(t, latest.AcceptedAt.IsZero())
+
+	// Принявший текущую версию не попадает в отчёт; устаревшая и отсутствующая - попадают
+	pending, err := s.UsersPendingTOS(ctx, "2025-01", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, ids[1], pending[0].UserID)
+	assert.Equal(t, "2024-01", pending[0].Version)
+	assert.Equal(t, ids[2], pending[1].UserID)
+	assert.Empty(t, pending[1].Version)
+	assert.True(t, pending[1].AcceptedAt.IsZero())
+
+	page, err := s.UsersPendingTOS(ctx, "2025-01", ids[1], 10)
+	require.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, ids[2], page[0].UserID)
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"sso/internal/storage"
+	"time"
+)
+
+// AddUserApp - связывает пользователя с приложением, если связи ещё нет (время первой связи не меняется)
+func (s *Storage) AddUserApp(ctx context.Context, userID int64, appID int, at time.Time) error {
+	const op = "storage.sqlite.AddUserApp"
+
+	_, err := s.conn(ctx).ExecContext(ctx,
+		"INSERT OR IGNORE INTO user_apps (app_id, user_id, first_seen_at) VALUES (?, ?, ?)", appID, userID, at.UTC())
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
+
+// UserApps - приложения пользователя по возрастанию id (пустой список, если их нет)
+func (s *Storage) UserApps(ctx context.Context, userID int64) ([]int, error) {
+	const op = "storage.sqlite.UserApps"
+
+	rows, err := s.db.QueryContext(ctx, "SELECT app_id FROM user_apps WHERE user_id = ? ORDER BY app_id", userID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+	defer rows.Close()
+
+	apps := []int{}
+	for rows.Next() {
+		var appID int
+		if err := rows.Scan(&appID); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+		apps = append(apps, appID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return apps, nil
+}
+
+// IsUserInApp - связан ли пользователь с приложением
+func (s *Storage) IsUserInApp(ctx context.Context, userID int64, appID int) (bool, error) {
+	const op = "storage.sqlite.IsUserInApp"
+
+	var ok bool
+	err := s.read(ctx).QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM user_apps WHERE app_id = ? AND user_id = ?)", appID, userID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ok, nil
+}
+
+// IsAppAdmin - является ли пользователь администратором приложения
+func (s *Storage) IsAppAdmin(ctx context.Context, userID int64, appID int) (bool, error) {
+	const op = "storage.sqlite.IsAppAdmin"
+
+	var ok bool
+	err := s.read(ctx).QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM app_admins WHERE app_id = ? AND user_id = ?)", appID, userID).Scan(&ok)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return ok, nil
+}
+
+// SetAppAdmin - назначает пользователя администратором приложения или снимает с него эту роль
+// (storage.ErrAppNotFound, storage.ErrUserNotFound)
+func (s *Storage) SetAppAdmin(ctx context.Context, userID int64, appID int, admin bool) error {
+	const op = "storage.sqlite.SetAppAdmin"
+
+	err := s.WithinTx(ctx, func(ctx context.Context) error {
+		var appExists, userExists bool
+		err := s.conn(ctx).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM apps WHERE id = ?),
+			EXISTS (SELECT 1 FROM users WHERE id = ? AND erased_at IS NULL)`, appID, userID).Scan(&appExists, &userExists)
+		switch {
+		case err != nil:
+			return err
+		case !appExists:
+			return storage.ErrAppNotFound
+		case !userExists:
+			return storage.ErrUserNotFound
+		}
+
+		if admin {
+			_, err = s.conn(ctx).ExecContext(ctx,
+				"INSERT OR IGNORE INTO app_admins (app_id, user_id) VALUES (?, ?)", appID, userID)
+		} else {
+			_, err = s.conn(ctx).ExecContext(ctx, "DELETE FROM app_admins WHERE app_id = ? AND user_id = ?", appID, userID)
+		}
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"sso/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserApps(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+	now := time.Now()
+
+	appA, err := s.SaveApp(ctx, "product-a", "secret-a")
+	require.NoError(t, err)
+	appB, err := s.SaveApp(ctx, "product-b", "secret-b")
+	require.NoError(t, err)
+	ids := seedUsers(t, s, 3)
+
+	// ids[1] - пользователь обоих приложений, ids[2] - ни одного
+	require.NoError(t, s.AddUserApp(ctx, ids[0], appA, now))
+	require.NoError(t, s.AddUserApp(ctx, ids[1], appA, now))
+	require.NoError(t, s.AddUserApp(ctx, ids[1], appA, now.Add(time.Hour)))
+	require.NoError(t, s.AddUserApp(ctx, ids[1], appB, now))
+
+	apps, err := s.UserApps(ctx, ids[1])
+	require.NoError(t, err)
+	assert.Equal(t, []int{appA, appB}, apps)
+
+	apps, err = s.UserApps(ctx, ids[2])
+	require.NoError(t, err)
+	assert.Empty(t, apps)
+
+	ok, err := s.IsUserInApp(ctx, ids[0], appB)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	users, err := s.ListUsers(ctx, 0, 10, "", false, appA)
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, ids[0], users[0].ID)
+	assert.Equal(t, ids[1], users[1].ID)
+
+	users, err = s.ListUsers(ctx, 0, 10, "", false, appB)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	assert.Equal(t, ids[1], users[0].ID)
+
+	st, err := s.Stats(ctx, appB, true, now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, st.TotalUsers)
+
+	st, err = s.Stats(ctx, appB, false, now)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, st.TotalUsers)
+}
+
+func TestSetAppAdmin(t *testing.T) {
+	s := newTestStorage(t)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "product-a", "secret")
+	require.NoError(t, err)
+	userID := seedUsers(t, s, 1)[0]
+
+	require.NoError(t, s.SetAppAdmin(ctx, userID, appID, true))
+	require.NoError(t, s.SetAppAdmin(ctx, userID, appID, true))
+
+	ok, err := s.IsAppAdmin(ctx, userID, appID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, s.SetAppAdmin(ctx, userID, appID, false))
+	ok, err = s.IsAppAdmin(ctx, userID, appID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.ErrorIs(t, s.SetAppAdmin(ctx, userID, appID+100, true), storage.ErrAppNotFound)
+	require.ErrorIs(t, s.SetAppAdmin(ctx, userID+100, appID, true), storage.ErrUserNotFound)
+}
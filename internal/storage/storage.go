@@ -3,7 +3,55 @@ package storage
 import "errors"
 
 var (
-	ErrUserExists   = errors.New("user already exists")
-	ErrUserNotFound = errors.New("user not found")
-	ErrAppNotFound  = errors.New("app not found")
+	ErrUserExists    = errors.New("user already exists")
+	ErrUserNotFound  = errors.New("user not found")
+	ErrAppNotFound   = errors.New("app not found")
+	ErrAppExists     = errors.New("app already exists")
+	ErrOrgNotFound   = errors.New("organization not found")
+	ErrOrgExists     = errors.New("organization already exists")
+	ErrNotMember     = errors.New("user is not a member of the organization")
+	ErrGroupNotFound = errors.New("group not found")
+	ErrGroupExists   = errors.New("group already exists")
+	ErrNotInGroup    = errors.New("user is not a member of the group")
+
+	ErrConsentNotFound = errors.New("consent not found")
+	ErrTOSNotAccepted  = errors.New("terms of service not accepted")
+
+	ErrInvitationNotFound = errors.New("invitation not found")
+	ErrInvitationUsed     = errors.New("invitation is already accepted or revoked")
+
+	ErrActionTokenNotFound = errors.New("action token not found or already used")
+
+	ErrDeviceAuthorizationNotFound = errors.New("device authorization not found")
+	ErrDeviceAuthorizationUsed     = errors.New("device authorization is already decided or used")
+	ErrUserCodeExists              = errors.New("user code already exists")
+
+	ErrPasskeyNotFound         = errors.New("passkey not found")
+	ErrPasskeyExists           = errors.New("passkey already registered")
+	ErrWebAuthnSessionNotFound = errors.New("webauthn session not found or already used")
+
+	ErrSMSChallengeNotFound = errors.New("sms challenge not found")
+	ErrSMSAttemptsExhausted = errors.New("sms code attempts exhausted")
+	ErrSMSSendLimitReached  = errors.New("sms send limit reached")
+
+	ErrAuthCodeNotFound = errors.New("authorization code not found or already used")
+
+	ErrAccountDeletionNotFound = errors.New("account deletion not found")
+
+	ErrSessionNotFound     = errors.New("session not found")
+	ErrSessionLimitReached = errors.New("session limit reached")
+
+	ErrOfflineTokenNotFound = errors.New("offline token not found")
+
+	ErrRefreshFamilyNotFound = errors.New("refresh token not found or already rotated")
+
+	ErrExternalIDExists = errors.New("external id already exists")
+
+	ErrLoginPolicyNotFound = errors.New("login policy not found")
+
+	// ErrVersionConflict - пользователь изменён после того, как его прочитали (версия не совпала)
+	ErrVersionConflict = errors.New("version conflict")
+
+	// ErrCorrupt - файл БД (например, резервная копия) не прошёл проверку целостности
+	ErrCorrupt = errors.New("database integrity check failed")
 )
@@ -0,0 +1,227 @@
+// Package storagetest - общий набор проверок для реализаций хранилища.
+//
+// Каждый бэкенд подключает его в своих тестах:
+//
+//	func TestConformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storagetest.Storage { return newTestStorage(t) })
+//	}
+//
+// Набор проверяет только контракт, на который опирается AuthService (сопоставление ошибок,
+// уникальность email, поведение при отмене контекста и конкурентной записи).
+// Особенности конкретного бэкенда проверяются в его собственных тестах
+package storagetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sso/internal/services/auth"
+	"sso/internal/storage"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Storage - то, что проверяет набор: интерфейсы хранилища сервиса авторизации
+// и регистрация приложений (без неё нечего искать через App)
+type Storage interface {
+	auth.UserSaver
+	auth.UserProvider
+	auth.AppProvider
+
+	SaveApp(ctx context.Context, name string, secret string) (int, error)
+}
+
+// Factory - создаёт пустое хранилище для одного подтеста; освобождение ресурсов - через t.Cleanup
+type Factory func(t *testing.T) Storage
+
+// Run - запускает весь набор проверок; каждый подтест получает новое хранилище
+func Run(t *testing.T, newStorage Factory) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s Storage)
+	}{
+		{name: "SaveUser", fn: testSaveUser},
+		{name: "SaveUser duplicate email", fn: testSaveUserDuplicate},
+		{name: "email is case-insensitive", fn: testEmailCaseInsensitive},
+		{name: "User not found", fn: testUserNotFound},
+		{name: "IsAdmin", fn: testIsAdmin},
+		{name: "App", fn: testApp},
+		{name: "canceled context", fn: testCanceledContext},
+		{name: "concurrent writes", fn: testConcurrentWrites},
+		{name: "concurrent duplicate writes", fn: testConcurrentDuplicates},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStorage(t))
+		})
+	}
+}
+
+func testSaveUser(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id, err := s.SaveUser(ctx, "user@example.com", []byte("hash"))
+	require.NoError(t, err)
+	require.NotZero(t, id)
+
+	user, err := s.User(ctx, "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, id, user.ID)
+	assert.Equal(t, "user@example.com", user.Email)
+	assert.Equal(t, []byte("hash"), user.PassHash)
+
+	byID, err := s.UserByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", byID.Email)
+	assert.True(t, byID.IsActive, "new users must be active")
+
+	exists, err := s.IsUserExists(ctx, id)
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func testSaveUserDuplicate(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	first, err := s.SaveUser(ctx, "dup@example.com", []byte("hash"))
+	require.NoError(t, err)
+
+	_, err = s.SaveUser(ctx, "dup@example.com", []byte("other"))
+	require.ErrorIs(t, err, storage.ErrUserExists)
+
+	// Неудачная вставка не должна менять существующего пользователя
+	user, err := s.User(ctx, "dup@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, first, user.ID)
+	assert.Equal(t, []byte("hash"), user.PassHash)
+}
+
+func testEmailCaseInsensitive(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id, err := s.SaveUser(ctx, "Case@Example.com", []byte("hash"))
+	require.NoError(t, err)
+
+	user, err := s.User(ctx, "case@example.COM")
+	require.NoError(t, err)
+	assert.Equal(t, id, user.ID)
+
+	_, err = s.SaveUser(ctx, "case@example.com", []byte("other"))
+	require.ErrorIs(t, err, storage.ErrUserExists)
+}
+
+func testUserNotFound(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	_, err := s.User(ctx, "missing@example.com")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+
+	_, err = s.UserByID(ctx, 404)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func testIsAdmin(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id, err := s.SaveUser(ctx, "admin@example.com", []byte("hash"))
+	require.NoError(t, err)
+
+	isAdmin, err := s.IsAdmin(ctx, id)
+	require.NoError(t, err)
+	assert.False(t, isAdmin, "new users must not be admins")
+
+	_, err = s.IsAdmin(ctx, id+1000)
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func testApp(t *testing.T, s Storage) {
+	ctx := context.Background()
+
+	id, err := s.SaveApp(ctx, "conformance", "app-secret")
+	require.NoError(t, err)
+
+	app, err := s.App(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, id, app.ID)
+	assert.Equal(t, "conformance", app.Name)
+	assert.Equal(t, "app-secret", app.Secret)
+
+	_, err = s.App(ctx, id+1000)
+	require.ErrorIs(t, err, storage.ErrAppNotFound)
+}
+
+func testCanceledContext(t *testing.T, s Storage) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.SaveUser(ctx, "canceled@example.com", []byte("hash"))
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = s.User(ctx, "canceled@example.com")
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = s.App(ctx, 1)
+	require.ErrorIs(t, err, context.Canceled)
+
+	// Отменённая запись не должна оставить пользователя
+	_, err = s.User(context.Background(), "canceled@example.com")
+	require.ErrorIs(t, err, storage.ErrUserNotFound)
+}
+
+func testConcurrentWrites(t *testing.T, s Storage) {
+	const n = 20
+
+	ids := make([]int64, n)
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids[i], errs[i] = s.SaveUser(context.Background(), fmt.Sprintf("user%d@example.com", i), []byte("hash"))
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[int64]bool, n)
+	for i := range n {
+		require.NoError(t, errs[i], "user %d", i)
+		assert.False(t, seen[ids[i]], "id %d returned twice", ids[i])
+		seen[ids[i]] = true
+	}
+}
+
+func testConcurrentDuplicates(t *testing.T, s Storage) {
+	const n = 10
+
+	errs := make([]error, n)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = s.SaveUser(context.Background(), "race@example.com", []byte("hash"))
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case errors.Is(err, storage.ErrUserExists):
+		default:
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	assert.Equal(t, 1, created, "exactly one concurrent registration must win")
+}
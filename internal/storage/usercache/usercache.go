@@ -0,0 +1,169 @@
+// Package usercache - кеш пользователей для проверки токенов.
+//
+// ValidateToken и интерцептор авторизации на каждый запрос читают пользователя (is_active, is_admin),
+// и при большом потоке запросов от resource-серверов хранилище становится узким местом.
+// Cache - декоратор над auth.UserProvider, который запоминает результат UserByID на короткий TTL,
+// в том числе отсутствие пользователя. Остальные методы идут в хранилище напрямую.
+//
+// Изменения, сделанные этим же процессом, сбрасывают запись через Invalidate сразу;
+// изменения с других реплик становятся видны не позже чем через TTL
+package usercache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sso/internal/domain/models"
+	"sso/internal/services/auth"
+	"sso/internal/storage"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// sweepThreshold - с какого размера кеша при записи удаляются истёкшие записи
+const sweepThreshold = 1024
+
+// Cache - auth.UserProvider с кешированием UserByID
+type Cache struct {
+	auth.UserProvider
+
+	ttl time.Duration
+	now func() time.Time
+
+	mu        sync.Mutex
+	entries   map[int64]entry
+	nextSweep int
+	gen       uint64 // растёт при каждом Invalidate: результат чтения, начатого до сброса, не сохраняется
+
+	group singleflight.Group // одновременные промахи по одному id дают один запрос к хранилищу
+}
+
+// entry - минимальная запись для проверки токена: кешируется не вся строка пользователя
+type entry struct {
+	id       int64
+	email    string
+	isAdmin  bool
+	isActive bool
+	isSuper  bool
+	isGuest  bool
+	notFound bool
+	expires  time.Time
+}
+
+// New - кеширует UserByID из next на ttl
+func New(next auth.UserProvider, ttl time.Duration) *Cache {
+	return &Cache{
+		UserProvider: next,
+		ttl:          ttl,
+		now:          time.Now,
+		entries:      make(map[int64]entry),
+		nextSweep:    sweepThreshold,
+	}
+}
+
+// UserByID - пользователь из кеша или из хранилища. Хеш пароля и метаданные не заполняются
+func (c *Cache) UserByID(ctx context.Context, userID int64) (models.User, error) {
+	const op = "usercache.UserByID"
+
+	if e, ok := c.get(userID); ok {
+		return e.user(op)
+	}
+
+	v, err, _ := c.group.Do(strconv.FormatInt(userID, 10), func() (any, error) {
+		gen := c.generation()
+
+		user, err := c.UserProvider.UserByID(ctx, userID)
+		switch {
+		case err == nil:
+			e := entry{
+				id:       user.ID,
+				email:    user.Email,
+				isAdmin:  user.IsAdmin,
+				isActive: user.IsActive,
+				isSuper:  user.IsSuperadmin,
+				isGuest:  user.IsGuest,
+			}
+			c.set(userID, e, gen)
+
+			return e, nil
+		case errors.Is(err, storage.ErrUserNotFound):
+			e := entry{id: userID, notFound: true}
+			c.set(userID, e, gen)
+
+			return e, nil
+		default:
+			// Ошибки хранилища не кешируются
+			return nil, err
+		}
+	})
+	if err != nil {
+		return models.User{}, err
+	}
+
+	return v.(entry).user(op)
+}
+
+// Invalidate - сбрасывает запись пользователя; вызывается после изменений, влияющих на проверку токена
+func (c *Cache) Invalidate(userID int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.entries, userID)
+	c.gen++
+}
+
+func (c *Cache) generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.gen
+}
+
+func (c *Cache) get(userID int64) (entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[userID]
+	if !ok || !c.now().Before(e.expires) {
+		return entry{}, false
+	}
+
+	return e, true
+}
+
+func (c *Cache) set(userID int64, e entry, gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if gen != c.gen {
+		return
+	}
+
+	now := c.now()
+	e.expires = now.Add(c.ttl)
+	c.entries[userID] = e
+
+	if len(c.entries) < c.nextSweep {
+		return
+	}
+	for id, old := range c.entries {
+		if !now.Before(old.expires) {
+			delete(c.entries, id)
+		}
+	}
+	c.nextSweep = max(2*len(c.entries), sweepThreshold)
+}
+
+// user - запись в виде результата UserByID
+func (e entry) user(op string) (models.User, error) {
+	if e.notFound {
+		return models.User{}, fmt.Errorf("%s: %w", op, storage.ErrUserNotFound)
+	}
+
+	return models.User{
+		ID: e.id, Email: e.email, IsAdmin: e.isAdmin, IsActive: e.isActive, IsSuperadmin: e.isSuper, IsGuest: e.isGuest,
+	}, nil
+}
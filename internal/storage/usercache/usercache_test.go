@@ -0,0 +1,252 @@
+package usercache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"sso/internal/domain/models"
+	"sso/internal/lib/jwt"
+	"sso/internal/services/auth"
+	"sso/internal/storage"
+	"sso/internal/storage/sqlite"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUsers - хранилище пользователей в памяти со счётчиком обращений к UserByID
+type fakeUsers struct {
+	auth.UserProvider
+
+	mu    sync.Mutex
+	users map[int64]models.User
+	err   error
+	calls int
+}
+
+func (f *fakeUsers) UserByID(_ context.Context, userID int64) (models.User, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if f.err != nil {
+		return models.User{}, f.err
+	}
+
+	u, ok := f.users[userID]
+	if !ok {
+		return models.User{}, fmt.Errorf("storage.fake.UserByID: %w", storage.ErrUserNotFound)
+	}
+
+	return u, nil
+}
+
+func (f *fakeUsers) update(u models.User) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.users[u.ID] = u
+}
+
+// fakeClock - управляемое время для проверки TTL
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestCache(ttl time.Duration) (*Cache, *fakeUsers, *fakeClock) {
+	users := &fakeUsers{users: map[int64]models.User{
+		1: {ID: 1, Email: "a@b.c", IsActive: true, PassHash: []byte("hash")},
+	}}
+	clock := &fakeClock{t: time.Unix(1_700_000_000, 0)}
+
+	c := New(users, ttl)
+	c.now = clock.now
+
+	return c, users, clock
+}
+
+func TestCache_Hit(t *testing.T) {
+	c, users, _ := newTestCache(time.Second)
+
+	for range 3 {
+		u, err := c.UserByID(context.Background(), 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.User{ID: 1, Email: "a@b.c", IsActive: true}, u, "only the validation record is cached")
+	}
+
+	assert.Equal(t, 1, users.calls)
+}
+
+func TestCache_NegativeCaching(t *testing.T) {
+	c, users, clock := newTestCache(time.Second)
+
+	for range 3 {
+		_, err := c.UserByID(context.Background(), 404)
+		require.ErrorIs(t, err, storage.ErrUserNotFound)
+	}
+	assert.Equal(t, 1, users.calls)
+
+	// Пользователь появился - виден после истечения TTL
+	users.update(models.User{ID: 404, Email: "new@b.c", IsActive: true})
+	clock.advance(time.Second)
+
+	u, err := c.UserByID(context.Background(), 404)
+	require.NoError(t, err)
+	assert.Equal(t, "new@b.c", u.Email)
+}
+
+// Отключение пользователя другим процессом вступает в силу не позже чем через TTL
+func TestCache_RevocationWithinTTL(t *testing.T) {
+	const ttl = 2 * time.Second
+
+	c, users, clock := newTestCache(ttl)
+
+	u, err := c.UserByID(context.Background(), 1)
+	require.NoError(t, err)
+	require.True(t, u.IsActive)
+
+	users.update(models.User{ID: 1, Email: "a@b.c", IsActive: false})
+
+	clock.advance(ttl - time.Millisecond)
+	u, err = c.UserByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.True(t, u.IsActive, "stale record is allowed within TTL")
+
+	clock.advance(time.Millisecond)
+	u, err = c.UserByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.False(t, u.IsActive, "revocation must be visible once TTL has passed")
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	c, users, _ := newTestCache(time.Minute)
+
+	_, err := c.UserByID(context.Background(), 1)
+	require.NoError(t, err)
+
+	users.update(models.User{ID: 1, Email: "a@b.c", IsActive: true, IsAdmin: true})
+	c.Invalidate(1)
+
+	u, err := c.UserByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.True(t, u.IsAdmin)
+	assert.Equal(t, 2, users.calls)
+}
+
+func TestCache_StorageErrorNotCached(t *testing.T) {
+	c, users, _ := newTestCache(time.Minute)
+
+	dbErr := errors.New("database is locked")
+	users.err = dbErr
+
+	_, err := c.UserByID(context.Background(), 1)
+	require.ErrorIs(t, err, dbErr)
+
+	users.err = nil
+	_, err = c.UserByID(context.Background(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, users.calls)
+}
+
+// Гостевой токен проверяется через кеш: флаг гостя из записи совпадает с claims и при попадании в кеш
+func TestCache_GuestToken(t *testing.T) {
+	s := newSQLite(t)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "guests", "guest-secret")
+	require.NoError(t, err)
+
+	c := New(s, time.Minute)
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	a := auth.New(log, s, s, s, time.Hour, auth.WithGuests(s, time.Hour), auth.WithValidationCache(c))
+
+	guestID, token, err := a.CreateGuest(ctx, int(appID))
+	require.NoError(t, err)
+
+	for range 2 {
+		p, err := a.Authenticate(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, guestID, p.UserID)
+	}
+
+	u, err := c.UserByID(ctx, guestID)
+	require.NoError(t, err)
+	assert.True(t, u.IsGuest)
+
+	// После превращения в обычного пользователя запись сбрасывается, иначе кеш ещё TTL считал бы его гостем
+	require.NoError(t, s.UpgradeGuest(ctx, guestID, "guest@b.c", []byte("hash")))
+	c.Invalidate(guestID)
+
+	u, err = c.UserByID(ctx, guestID)
+	require.NoError(t, err)
+	assert.False(t, u.IsGuest)
+}
+
+// BenchmarkAuthenticate - проверка токена на SQLite без кеша и с кешем
+func BenchmarkAuthenticate(b *testing.B) {
+	s := newSQLite(b)
+	ctx := context.Background()
+
+	appID, err := s.SaveApp(ctx, "bench", "bench-secret")
+	require.NoError(b, err)
+	app := models.App{ID: appID, Name: "bench", Secret: "bench-secret"}
+
+	uid, err := s.SaveUser(ctx, "bench@example.com", []byte("hash"))
+	require.NoError(b, err)
+
+	token, _, err := jwt.Issue(models.User{ID: uid, Email: "bench@example.com"}, app, time.Hour)
+	require.NoError(b, err)
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	run := func(b *testing.B, a *auth.AuthService) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				if _, err := a.Authenticate(ctx, token); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+
+	b.Run("storage", func(b *testing.B) {
+		run(b, auth.New(log, s, s, s, time.Hour))
+	})
+
+	b.Run("cached", func(b *testing.B) {
+		run(b, auth.New(log, s, s, s, time.Hour, auth.WithValidationCache(New(s, time.Second))))
+	})
+}
+
+// newSQLite - временная SQLite с применёнными миграциями
+func newSQLite(tb testing.TB) *sqlite.Storage {
+	tb.Helper()
+
+	path := filepath.Join(tb.TempDir(), "sso.db")
+
+	m, err := migrate.New("file://../../../migrations", "sqlite3://"+path+"?x-migrations-table=migrations")
+	require.NoError(tb, err)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		require.NoError(tb, err)
+	}
+	srcErr, dbErr := m.Close()
+	require.NoError(tb, srcErr)
+	require.NoError(tb, dbErr)
+
+	s, err := sqlite.New(path)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { _ = s.Close() })
+
+	return s
+}
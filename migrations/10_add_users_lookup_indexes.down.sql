@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_erased_at;
+DROP INDEX IF EXISTS idx_users_email_lower;
+CREATE INDEX IF NOT EXISTS idx_email ON users (email);
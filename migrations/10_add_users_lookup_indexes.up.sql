@@ -0,0 +1,18 @@
+-- Email уникален без учёта регистра. Если в таблице уже есть адреса, различающиеся только регистром,
+-- миграция прерывается: конфликты нужно разрешить вручную (migrator выводит их список)
+CREATE TEMP TABLE migration_check (id INTEGER);
+CREATE TEMP TRIGGER migration_check_email_case
+    BEFORE INSERT ON migration_check
+    WHEN EXISTS (SELECT 1 FROM users GROUP BY lower(email) HAVING count(*) > 1)
+BEGIN
+    SELECT RAISE(ABORT, 'users.email: found emails that differ only by case, resolve them before creating a case-insensitive unique index');
+END;
+INSERT INTO migration_check VALUES (1);
+DROP TRIGGER migration_check_email_case;
+DROP TABLE migration_check;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
+-- Поиск по точному email теперь идёт через idx_users_email_lower, UNIQUE(email) остаётся автоиндексом
+DROP INDEX IF EXISTS idx_email;
+
+CREATE INDEX IF NOT EXISTS idx_users_erased_at ON users (erased_at);
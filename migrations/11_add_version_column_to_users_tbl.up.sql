@@ -0,0 +1,3 @@
+-- Версия строки для оптимистичной блокировки: растёт при каждом изменении пользователя
+ALTER TABLE users
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
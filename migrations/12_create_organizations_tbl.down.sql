@@ -0,0 +1,27 @@
+-- Не применится, если в разных организациях есть пользователи с одинаковым email
+CREATE TABLE users_old
+(
+    id                    INTEGER PRIMARY KEY,
+    email                 TEXT    NOT NULL UNIQUE,
+    pass_hash             BLOB    NOT NULL,
+    is_admin              BOOLEAN NOT NULL DEFAULT FALSE,
+    metadata              TEXT    NOT NULL DEFAULT '{}',
+    is_active             BOOLEAN NOT NULL DEFAULT TRUE,
+    erased_at             TIMESTAMP,
+    password_changed_at   TIMESTAMP,
+    force_password_change BOOLEAN NOT NULL DEFAULT FALSE,
+    version               INTEGER NOT NULL DEFAULT 1
+);
+INSERT INTO users_old (id, email, pass_hash, is_admin, metadata, is_active, erased_at,
+                       password_changed_at, force_password_change, version)
+SELECT id, email, pass_hash, is_admin, metadata, is_active, erased_at,
+       password_changed_at, force_password_change, version
+FROM users;
+DROP TABLE users;
+ALTER TABLE users_old RENAME TO users;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
+CREATE INDEX IF NOT EXISTS idx_users_erased_at ON users (erased_at);
+
+DROP TABLE IF EXISTS org_members;
+DROP TABLE IF EXISTS organizations;
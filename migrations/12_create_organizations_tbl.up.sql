@@ -0,0 +1,49 @@
+CREATE TABLE IF NOT EXISTS organizations
+(
+    id                INTEGER PRIMARY KEY,
+    slug              TEXT      NOT NULL UNIQUE,
+    name              TEXT      NOT NULL,
+    allow_self_signup BOOLEAN   NOT NULL DEFAULT FALSE, -- можно ли зарегистрироваться в организации без приглашения
+    created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS org_members
+(
+    org_id     INTEGER   NOT NULL REFERENCES organizations (id),
+    user_id    INTEGER   NOT NULL REFERENCES users (id),
+    role       TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (org_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_org_members_user_id ON org_members (user_id);
+
+-- email_scope - пространство, в котором email уникален: 0 - общее, id организации - только внутри неё
+-- (organizations.email_uniqueness: organization). UNIQUE(email) из 1_init нельзя снять через ALTER TABLE,
+-- поэтому таблица пересоздаётся
+CREATE TABLE users_new
+(
+    id                    INTEGER PRIMARY KEY,
+    email                 TEXT    NOT NULL,
+    pass_hash             BLOB    NOT NULL,
+    is_admin              BOOLEAN NOT NULL DEFAULT FALSE,
+    metadata              TEXT    NOT NULL DEFAULT '{}',
+    is_active             BOOLEAN NOT NULL DEFAULT TRUE,
+    erased_at             TIMESTAMP,
+    password_changed_at   TIMESTAMP,
+    force_password_change BOOLEAN NOT NULL DEFAULT FALSE,
+    version               INTEGER NOT NULL DEFAULT 1,
+    email_scope           INTEGER NOT NULL DEFAULT 0,
+    is_superadmin         BOOLEAN NOT NULL DEFAULT FALSE -- администратор всех организаций
+);
+INSERT INTO users_new (id, email, pass_hash, is_admin, metadata, is_active, erased_at,
+                       password_changed_at, force_password_change, version)
+SELECT id, email, pass_hash, is_admin, metadata, is_active, erased_at,
+       password_changed_at, force_password_change, version
+FROM users;
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_scope_email ON users (email_scope, lower(email));
+-- Поиск по префиксу email во всех пространствах (ListUsers)
+CREATE INDEX IF NOT EXISTS idx_users_email_lower ON users (lower(email));
+CREATE INDEX IF NOT EXISTS idx_users_erased_at ON users (erased_at);
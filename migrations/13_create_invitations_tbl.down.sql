@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN email_verified;
+DROP TABLE IF EXISTS invitations;
@@ -0,0 +1,22 @@
+-- Приглашения: по ссылке из письма приглашённый сам задаёт пароль.
+-- Хранится только SHA-256 токена; у одного email может быть не больше одного ожидающего приглашения
+CREATE TABLE IF NOT EXISTS invitations
+(
+    id          INTEGER PRIMARY KEY,
+    email       TEXT      NOT NULL,
+    token_hash  TEXT      NOT NULL UNIQUE,
+    app_id      INTEGER   NOT NULL REFERENCES apps (id),
+    role        TEXT      NOT NULL,
+    invited_by  INTEGER   NOT NULL DEFAULT 0,
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at  TIMESTAMP NOT NULL,
+    accepted_at TIMESTAMP,
+    revoked_at  TIMESTAMP,
+    user_id     INTEGER REFERENCES users (id)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_pending_email ON invitations (lower(email))
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+-- Адрес подтверждён: пользователь пришёл по ссылке из письма
+ALTER TABLE users
+    ADD COLUMN email_verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+ALTER TABLE apps DROP COLUMN groups_claim;
+
+DROP TABLE IF EXISTS user_groups;
+DROP TABLE IF EXISTS groups;
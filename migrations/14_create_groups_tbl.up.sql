@@ -0,0 +1,22 @@
+-- Группы пользователей ("engineering", "finance") для авторизации в приложениях.
+-- Внешние ключи в соединении не включены, поэтому DeleteGroup удаляет членство сам; ON DELETE CASCADE
+-- описывает то же правило для инструментов, которые работают с БД с foreign_keys = ON
+CREATE TABLE IF NOT EXISTS groups
+(
+    id         INTEGER PRIMARY KEY,
+    name       TEXT      NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS user_groups
+(
+    group_id   INTEGER   NOT NULL REFERENCES groups (id) ON DELETE CASCADE,
+    user_id    INTEGER   NOT NULL REFERENCES users (id),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_groups_user_id ON user_groups (user_id);
+
+-- Добавлять ли в токены приложения клейм groups (выключено: токены некоторых приложений должны оставаться маленькими)
+ALTER TABLE apps
+    ADD COLUMN groups_claim BOOLEAN NOT NULL DEFAULT FALSE;
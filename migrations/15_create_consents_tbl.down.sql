@@ -0,0 +1,3 @@
+ALTER TABLE apps DROP COLUMN third_party;
+
+DROP TABLE IF EXISTS consents;
@@ -0,0 +1,16 @@
+-- Согласия пользователей на передачу данных сторонним приложениям (apps.third_party).
+-- У пары пользователь-приложение не больше одного действующего согласия; отозванные остаются для истории
+CREATE TABLE IF NOT EXISTS consents
+(
+    id         INTEGER PRIMARY KEY,
+    user_id    INTEGER   NOT NULL REFERENCES users (id),
+    app_id     INTEGER   NOT NULL REFERENCES apps (id),
+    scopes     TEXT      NOT NULL, -- через пробел
+    granted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_consents_active ON consents (user_id, app_id) WHERE revoked_at IS NULL;
+
+-- Стороннее приложение: вход в него требует согласия пользователя
+ALTER TABLE apps
+    ADD COLUMN third_party BOOLEAN NOT NULL DEFAULT FALSE;
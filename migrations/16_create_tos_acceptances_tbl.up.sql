@@ -0,0 +1,10 @@
+-- Принятие условий использования: какую версию и когда принял пользователь (доказательство для юристов).
+-- Записи только добавляются; действует последняя по id
+CREATE TABLE IF NOT EXISTS tos_acceptances
+(
+    id          INTEGER PRIMARY KEY,
+    user_id     INTEGER   NOT NULL REFERENCES users (id),
+    version     TEXT      NOT NULL,
+    accepted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_tos_acceptances_user_id ON tos_acceptances (user_id, id);
@@ -0,0 +1,4 @@
+-- Метаданные, которые приложения хранят о пользователе: {"<app_id>": {...}}.
+-- Каждое приложение видит и меняет только свой объект
+ALTER TABLE users
+    ADD COLUMN app_metadata TEXT NOT NULL DEFAULT '{}';
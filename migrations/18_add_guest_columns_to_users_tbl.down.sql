@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_guest_last_active_at;
+ALTER TABLE users DROP COLUMN last_active_at;
+ALTER TABLE users DROP COLUMN is_guest;
@@ -0,0 +1,7 @@
+-- Гостевые пользователи: без email и пароля, пока не станут обычными через UpgradeGuest.
+-- last_active_at - последняя активность гостя; по ней janitor удаляет заброшенных гостей
+ALTER TABLE users
+    ADD COLUMN is_guest BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users
+    ADD COLUMN last_active_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_users_guest_last_active_at ON users (last_active_at) WHERE is_guest;
@@ -0,0 +1,12 @@
+-- Токены одного действия (подтверждение опасной операции, скачивание выгрузки).
+-- Строка существует, пока токен не использован: использование удаляет её, поэтому повтор не пройдёт.
+-- Просроченные строки удаляет janitor
+CREATE TABLE IF NOT EXISTS action_tokens
+(
+    jti        TEXT PRIMARY KEY,
+    user_id    INTEGER   NOT NULL REFERENCES users (id),
+    action     TEXT      NOT NULL,
+    payload    BLOB      NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_action_tokens_expires_at ON action_tokens (expires_at);
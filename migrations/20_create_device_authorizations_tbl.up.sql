@@ -0,0 +1,17 @@
+-- Вход на устройствах без браузера (OAuth 2.0 device authorization grant, RFC 8628).
+-- Хранится только SHA-256 device_code; user_code короткий, его вводит пользователь на другом устройстве.
+-- Просроченные записи удаляет janitor
+CREATE TABLE IF NOT EXISTS device_authorizations
+(
+    id               INTEGER PRIMARY KEY,
+    device_code_hash TEXT      NOT NULL UNIQUE,
+    user_code        TEXT      NOT NULL UNIQUE,
+    app_id           INTEGER   NOT NULL REFERENCES apps (id),
+    status           TEXT      NOT NULL DEFAULT 'pending',
+    user_id          INTEGER REFERENCES users (id),
+    interval_seconds INTEGER   NOT NULL,
+    last_polled_at   TIMESTAMP,
+    created_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at       TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_device_authorizations_expires_at ON device_authorizations (expires_at);
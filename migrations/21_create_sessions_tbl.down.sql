@@ -0,0 +1,3 @@
+ALTER TABLE apps DROP COLUMN session_limit_policy;
+ALTER TABLE apps DROP COLUMN max_sessions;
+DROP TABLE IF EXISTS sessions;
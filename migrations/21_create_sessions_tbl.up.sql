@@ -0,0 +1,19 @@
+-- Сессии входа: id попадает в клейм sid токена, и отозванная сессия делает недействительными её токены.
+-- max_sessions - сколько одновременных сессий пользователя допускает приложение (0 - без ограничения);
+-- session_limit_policy - что делать при превышении: reject (отказать во входе) или evict_oldest
+CREATE TABLE IF NOT EXISTS sessions
+(
+    id         TEXT PRIMARY KEY,
+    user_id    INTEGER   NOT NULL REFERENCES users (id),
+    app_id     INTEGER   NOT NULL REFERENCES apps (id),
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_sessions_user_app ON sessions (user_id, app_id) WHERE revoked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_sessions_expires_at ON sessions (expires_at);
+
+ALTER TABLE apps
+    ADD COLUMN max_sessions INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE apps
+    ADD COLUMN session_limit_policy TEXT NOT NULL DEFAULT 'reject';
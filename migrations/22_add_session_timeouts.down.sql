@@ -0,0 +1,3 @@
+ALTER TABLE apps DROP COLUMN session_max_lifetime;
+ALTER TABLE apps DROP COLUMN session_idle_timeout;
+ALTER TABLE sessions DROP COLUMN last_seen_at;
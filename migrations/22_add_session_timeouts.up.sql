@@ -0,0 +1,8 @@
+-- Тайм-ауты сессий. last_seen_at - последняя проверка токена сессии (обновляется не чаще раза в минуту);
+-- session_idle_timeout и session_max_lifetime - лимиты приложения в секундах (0 - выключен)
+ALTER TABLE sessions
+    ADD COLUMN last_seen_at TIMESTAMP;
+ALTER TABLE apps
+    ADD COLUMN session_idle_timeout INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE apps
+    ADD COLUMN session_max_lifetime INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- Минимальный уровень аутентификации приложения (клейм acr): вход ниже него успешен,
+-- но клиенту сообщается, что нужно подтвердить вход вторым фактором (Auth.StepUp). Пусто - не требуется
+ALTER TABLE apps
+    ADD COLUMN min_acr TEXT NOT NULL DEFAULT '';
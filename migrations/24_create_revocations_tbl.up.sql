@@ -0,0 +1,12 @@
+-- Журнал отзывов токенов для потока WatchRevocations. seq монотонно растёт: потребители
+-- переподключаются с последним полученным seq. Записи старше revocations.retention удаляет janitor
+CREATE TABLE IF NOT EXISTS revocations
+(
+    seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind       TEXT      NOT NULL,
+    user_id    INTEGER   NOT NULL,
+    app_id     INTEGER   NOT NULL DEFAULT 0,
+    session_id TEXT      NOT NULL DEFAULT '',
+    revoked_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_revocations_revoked_at ON revocations (revoked_at);
@@ -0,0 +1,14 @@
+-- История успешных входов со страной и сетью (ASN) клиента, если настроена GeoIP-база.
+-- Сам адрес клиента не хранится. Записи старше geo.retention удаляет janitor
+CREATE TABLE IF NOT EXISTS login_history
+(
+    id           INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id      INTEGER   NOT NULL,
+    app_id       INTEGER   NOT NULL,
+    country      TEXT      NOT NULL DEFAULT '',
+    asn          INTEGER   NOT NULL DEFAULT 0,
+    suspicious   BOOLEAN   NOT NULL DEFAULT FALSE,
+    logged_in_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_login_history_user ON login_history (user_id, logged_in_at);
+CREATE INDEX IF NOT EXISTS idx_login_history_logged_in_at ON login_history (logged_in_at);
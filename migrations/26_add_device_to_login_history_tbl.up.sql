@@ -0,0 +1,3 @@
+-- Отпечаток устройства (хеш User-Agent) для уведомлений о входе с нового устройства.
+-- Сама строка User-Agent не хранится
+ALTER TABLE login_history ADD COLUMN device TEXT NOT NULL DEFAULT '';
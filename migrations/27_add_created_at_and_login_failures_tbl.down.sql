@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS login_failures;
+DROP INDEX IF EXISTS idx_users_inactive;
+DROP INDEX IF EXISTS idx_users_created_at;
+ALTER TABLE users DROP COLUMN created_at;
@@ -0,0 +1,15 @@
+-- Время регистрации для статистики (Admin.GetStats). Пользователи, созданные до этой миграции,
+-- остаются с NULL: в общее число они попадают, в регистрации за период - нет.
+-- Индексы частичные, по тем же условиям, что и счётчики: подсчёт идёт по индексу, а не по таблице
+ALTER TABLE users ADD COLUMN created_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users (created_at) WHERE erased_at IS NULL AND is_guest = FALSE;
+CREATE INDEX IF NOT EXISTS idx_users_inactive ON users (id) WHERE erased_at IS NULL AND is_active = FALSE;
+
+-- Неудачные входы: только приложение и время, без адреса и email. Записи старше geo.retention удаляет janitor
+CREATE TABLE IF NOT EXISTS login_failures
+(
+    id        INTEGER PRIMARY KEY AUTOINCREMENT,
+    app_id    INTEGER   NOT NULL,
+    failed_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_login_failures_failed_at ON login_failures (failed_at);
@@ -0,0 +1,17 @@
+-- Журнал событий безопасности в БД (audit.store) для выгрузки через Admin.ExportAuditEvents.
+-- Выгрузка идёт курсором по (occurred_at, id): индекс по occurred_at в SQLite уже упорядочен и по rowid
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    id          INTEGER PRIMARY KEY AUTOINCREMENT,
+    occurred_at TIMESTAMP NOT NULL,
+    event       TEXT      NOT NULL,
+    outcome     TEXT      NOT NULL,
+    user_id     INTEGER   NOT NULL DEFAULT 0,
+    app_id      INTEGER   NOT NULL DEFAULT 0,
+    email       TEXT      NOT NULL DEFAULT '',
+    reason      TEXT      NOT NULL DEFAULT '',
+    actor_id    INTEGER   NOT NULL DEFAULT 0,
+    request_id  TEXT      NOT NULL DEFAULT '',
+    ip          TEXT      NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_occurred_at ON audit_events (occurred_at);
@@ -0,0 +1,31 @@
+-- Passkey (WebAuthn) пользователей: у пользователя может быть несколько, у каждой своё имя.
+-- Хранится только открытый ключ; sign_count - последний счётчик подписей для обнаружения клонов
+CREATE TABLE IF NOT EXISTS passkeys
+(
+    id               INTEGER PRIMARY KEY,
+    user_id          INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    credential_id    BLOB      NOT NULL UNIQUE,
+    public_key       BLOB      NOT NULL,
+    attestation_type TEXT      NOT NULL DEFAULT '',
+    aaguid           BLOB,
+    sign_count       INTEGER   NOT NULL DEFAULT 0,
+    transports       TEXT      NOT NULL DEFAULT '',
+    backup_eligible  BOOLEAN   NOT NULL DEFAULT FALSE,
+    backup_state     BOOLEAN   NOT NULL DEFAULT FALSE,
+    name             TEXT      NOT NULL DEFAULT '',
+    created_at       TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_used_at     TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys (user_id);
+
+-- Состояние начатых церемоний WebAuthn (вызов и параметры) между Begin* и Finish*.
+-- id - случайный nonce, который клиент возвращает в Finish*; запись одноразовая, просроченные удаляет janitor
+CREATE TABLE IF NOT EXISTS webauthn_sessions
+(
+    id         TEXT PRIMARY KEY,
+    user_id    INTEGER   NOT NULL DEFAULT 0,
+    ceremony   TEXT      NOT NULL,
+    data       BLOB      NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_webauthn_sessions_expires_at ON webauthn_sessions (expires_at);
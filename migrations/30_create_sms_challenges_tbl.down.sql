@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS sms_sends;
+DROP TABLE IF EXISTS sms_challenges;
+ALTER TABLE users DROP COLUMN phone;
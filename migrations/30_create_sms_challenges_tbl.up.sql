@@ -0,0 +1,35 @@
+-- Подтверждённый номер телефона пользователя (E.164). Пока он задан, вход по паролю требует кода из SMS
+ALTER TABLE users
+    ADD COLUMN phone TEXT NOT NULL DEFAULT '';
+
+-- Вызовы с кодом из SMS: незавершённый вход по паролю (purpose = 'login') или подтверждение нового номера
+-- (purpose = 'phone'). id - SHA-256 токена вызова; code_hash пуст, пока код не отправлен.
+-- attempts считает все проверки кода вызова, включая повторные отправки. Просроченные записи удаляет janitor
+CREATE TABLE IF NOT EXISTS sms_challenges
+(
+    id              TEXT PRIMARY KEY,
+    purpose         TEXT      NOT NULL,
+    user_id         INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    phone           TEXT      NOT NULL,
+    app_id          INTEGER   NOT NULL DEFAULT 0,
+    org_id          INTEGER   NOT NULL DEFAULT 0,
+    accepted_tos    TEXT      NOT NULL DEFAULT '',
+    code_hash       BLOB,
+    code_expires_at TIMESTAMP,
+    sent_at         TIMESTAMP,
+    attempts        INTEGER   NOT NULL DEFAULT 0,
+    expires_at      TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sms_challenges_expires_at ON sms_challenges (expires_at);
+
+-- Журнал отправленных SMS для ограничения частоты по пользователю и по номеру
+CREATE TABLE IF NOT EXISTS sms_sends
+(
+    id      INTEGER PRIMARY KEY,
+    user_id INTEGER   NOT NULL,
+    phone   TEXT      NOT NULL,
+    sent_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_sms_sends_user_id_sent_at ON sms_sends (user_id, sent_at);
+CREATE INDEX IF NOT EXISTS idx_sms_sends_phone_sent_at ON sms_sends (phone, sent_at);
+CREATE INDEX IF NOT EXISTS idx_sms_sends_sent_at ON sms_sends (sent_at);
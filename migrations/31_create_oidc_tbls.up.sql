@@ -0,0 +1,22 @@
+-- Адреса возврата приложения для OpenID Connect: redirect_uri запроса сравнивается с ними побайтно
+CREATE TABLE IF NOT EXISTS app_redirect_uris
+(
+    app_id INTEGER NOT NULL REFERENCES apps (id) ON DELETE CASCADE,
+    uri    TEXT    NOT NULL,
+    PRIMARY KEY (app_id, uri)
+);
+
+-- Коды авторизации (authorization code flow). id - SHA-256 кода; токен доступа зашифрован ключом,
+-- выведенным из самого кода, поэтому по содержимому таблицы им не воспользоваться.
+-- Код одноразовый: обмен удаляет запись. Просроченные записи удаляет janitor
+CREATE TABLE IF NOT EXISTS oidc_codes
+(
+    id             TEXT PRIMARY KEY,
+    app_id         INTEGER   NOT NULL REFERENCES apps (id) ON DELETE CASCADE,
+    redirect_uri   TEXT      NOT NULL,
+    code_challenge TEXT      NOT NULL,
+    nonce          TEXT      NOT NULL DEFAULT '',
+    sealed_token   BLOB      NOT NULL,
+    expires_at     TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_oidc_codes_expires_at ON oidc_codes (expires_at);
@@ -0,0 +1,2 @@
+ALTER TABLE revocations DROP COLUMN token_id;
+DROP TABLE IF EXISTS revoked_tokens;
@@ -0,0 +1,15 @@
+-- Отдельные отозванные токены (по jti), например токен, попавший в тикет поддержки.
+-- Запись нужна, пока токен не истёк: после expires_at её удаляет janitor
+CREATE TABLE IF NOT EXISTS revoked_tokens
+(
+    jti        TEXT PRIMARY KEY,
+    user_id    INTEGER   NOT NULL,
+    app_id     INTEGER   NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens (expires_at);
+
+-- jti отозванного токена для записей журнала вида token
+ALTER TABLE revocations
+    ADD COLUMN token_id TEXT NOT NULL DEFAULT '';
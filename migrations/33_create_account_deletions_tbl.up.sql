@@ -0,0 +1,10 @@
+-- Удаление аккаунта по запросу пользователя: до execute_at вход работает и удаление можно отменить
+-- (по ссылке из письма с токеном или из аккаунта). После execute_at аккаунт обезличивает janitor
+CREATE TABLE IF NOT EXISTS account_deletions
+(
+    user_id      INTEGER PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    token_hash   TEXT      NOT NULL UNIQUE,
+    requested_at TIMESTAMP NOT NULL,
+    execute_at   TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_account_deletions_execute_at ON account_deletions (execute_at);
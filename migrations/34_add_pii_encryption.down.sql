@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS pii_encryption;
+DROP INDEX IF EXISTS idx_invitations_pending_email_index;
+ALTER TABLE invitations DROP COLUMN email_index;
+DROP INDEX IF EXISTS idx_users_scope_email_index;
+ALTER TABLE users DROP COLUMN email_index;
@@ -0,0 +1,20 @@
+-- Шифрование персональных данных (pii_key): email и телефоны хранятся зашифрованными AES-GCM,
+-- а поиск и уникальность email идут по слепому индексу email_index (HMAC-SHA256 email в нижнем регистре).
+-- Без ключа email_index остаётся NULL и ничего не меняется; шифрует существующие строки admin encrypt-pii
+ALTER TABLE users
+    ADD COLUMN email_index TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_scope_email_index ON users (email_scope, email_index);
+
+ALTER TABLE invitations
+    ADD COLUMN email_index TEXT;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invitations_pending_email_index ON invitations (email_index)
+    WHERE accepted_at IS NULL AND revoked_at IS NULL;
+
+-- Каким ключом зашифрована БД (отпечаток ключа) и закончено ли шифрование. Строки нет - БД не зашифрована
+CREATE TABLE IF NOT EXISTS pii_encryption
+(
+    id           INTEGER PRIMARY KEY CHECK (id = 1),
+    key_id       TEXT      NOT NULL,
+    started_at   TIMESTAMP NOT NULL,
+    completed_at TIMESTAMP
+);
@@ -0,0 +1,3 @@
+ALTER TABLE login_history DROP COLUMN offline;
+ALTER TABLE apps DROP COLUMN accept_offline_tokens;
+DROP TABLE IF EXISTS offline_tokens;
@@ -0,0 +1,22 @@
+-- Offline-токены фоновых агентов (синхронизация, пока пользователя нет): живут долго, хранятся отдельно
+-- от сессий входа и отзываются по одному. id - jti токена, label - подпись для пользователя ("Ноутбук, синхронизация").
+-- accept_offline_tokens - приложение принимает такие токены (по умолчанию нет);
+-- login_history.offline - запись о выдаче offline-токена, а не об интерактивном входе
+CREATE TABLE IF NOT EXISTS offline_tokens
+(
+    id         TEXT PRIMARY KEY,
+    user_id    INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    app_id     INTEGER   NOT NULL REFERENCES apps (id),
+    label      TEXT      NOT NULL,
+    scope      TEXT      NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_offline_tokens_user ON offline_tokens (user_id);
+CREATE INDEX IF NOT EXISTS idx_offline_tokens_expires_at ON offline_tokens (expires_at);
+
+ALTER TABLE apps
+    ADD COLUMN accept_offline_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE login_history
+    ADD COLUMN offline INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+ALTER TABLE apps DROP COLUMN provisioner;
+DROP INDEX IF EXISTS idx_users_provisioned_at;
+DROP INDEX IF EXISTS idx_users_external_id;
+ALTER TABLE users DROP COLUMN provisioned_at;
+ALTER TABLE users DROP COLUMN provisioned_attributes;
+ALTER TABLE users DROP COLUMN external_id;
@@ -0,0 +1,15 @@
+-- Провижининг пользователей из внешней системы (HR): external_id - id сотрудника в ней,
+-- provisioned_attributes - атрибуты из последнего ProvisionUser (JSON-объект строк),
+-- provisioned_at - когда провижининг последний раз менял пользователя (по нему идёт сверка).
+-- apps.provisioner - приложение с ролью provisioner: может вызывать методы провижининга своим секретом
+ALTER TABLE users
+    ADD COLUMN external_id TEXT;
+ALTER TABLE users
+    ADD COLUMN provisioned_attributes TEXT NOT NULL DEFAULT '{}';
+ALTER TABLE users
+    ADD COLUMN provisioned_at TIMESTAMP;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_external_id ON users (external_id) WHERE external_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_users_provisioned_at ON users (provisioned_at) WHERE provisioned_at IS NOT NULL;
+
+ALTER TABLE apps
+    ADD COLUMN provisioner INTEGER NOT NULL DEFAULT 0;
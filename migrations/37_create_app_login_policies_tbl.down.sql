@@ -0,0 +1,2 @@
+ALTER TABLE apps DROP COLUMN recheck_network_policy;
+DROP TABLE IF EXISTS app_login_policies;
@@ -0,0 +1,19 @@
+-- Политики входа в приложение: вход разрешён, только если выполнены все политики приложения.
+-- networks - сети CIDR через пробел, weekdays - дни недели через пробел (0 - воскресенье),
+-- start_minute и end_minute - окно [start, end) в минутах от полуночи в часовом поясе timezone
+-- (равны - весь день, start больше end - окно через полночь). Пустой список не ограничивает.
+-- apps.recheck_network_policy - сеть проверяется и у уже выданных токенов, а не только при входе
+CREATE TABLE IF NOT EXISTS app_login_policies
+(
+    app_id       INTEGER NOT NULL REFERENCES apps (id) ON DELETE CASCADE,
+    name         TEXT    NOT NULL,
+    networks     TEXT    NOT NULL DEFAULT '',
+    weekdays     TEXT    NOT NULL DEFAULT '',
+    start_minute INTEGER NOT NULL DEFAULT 0,
+    end_minute   INTEGER NOT NULL DEFAULT 0,
+    timezone     TEXT    NOT NULL DEFAULT 'UTC',
+    PRIMARY KEY (app_id, name)
+);
+
+ALTER TABLE apps
+    ADD COLUMN recheck_network_policy INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,3 @@
+ALTER TABLE apps DROP COLUMN selftest_at;
+DROP INDEX IF EXISTS idx_users_selftest_at;
+ALTER TABLE users DROP COLUMN selftest_at;
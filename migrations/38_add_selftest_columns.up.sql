@@ -0,0 +1,9 @@
+-- Данные самопроверки (Admin.RunSelfTest): пользователь и приложение, которые она создаёт, помечены временем
+-- создания. Они не попадают в статистику и списки пользователей и удаляются в конце проверки, а оставшиеся
+-- после сбоя - janitor'ом
+ALTER TABLE users
+    ADD COLUMN selftest_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_users_selftest_at ON users (selftest_at) WHERE selftest_at IS NOT NULL;
+
+ALTER TABLE apps
+    ADD COLUMN selftest_at TIMESTAMP;
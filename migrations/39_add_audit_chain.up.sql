@@ -0,0 +1,17 @@
+-- Цепочка хешей журнала безопасности (audit.chain): каждое событие хранит хеш предыдущего и свой -
+-- SHA-256 от канонической записи события и prev_hash. События, записанные без цепочки, остаются с пустыми
+-- хешами. audit_chain_head - последнее звено цепочки и, если задан audit.chain_key, его HMAC: по нему
+-- видно удаление событий с конца журнала
+ALTER TABLE audit_events
+    ADD COLUMN prev_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE audit_events
+    ADD COLUMN hash TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS audit_chain_head
+(
+    id         INTEGER PRIMARY KEY CHECK (id = 1),
+    event_id   INTEGER   NOT NULL,
+    hash       TEXT      NOT NULL,
+    mac        TEXT      NOT NULL DEFAULT '',
+    updated_at TIMESTAMP NOT NULL
+);
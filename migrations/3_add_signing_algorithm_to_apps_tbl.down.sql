@@ -0,0 +1,2 @@
+ALTER TABLE apps DROP COLUMN signing_key;
+ALTER TABLE apps DROP COLUMN algorithm;
@@ -0,0 +1,4 @@
+ALTER TABLE apps
+    ADD COLUMN algorithm TEXT NOT NULL DEFAULT 'HS256';
+ALTER TABLE apps
+    ADD COLUMN signing_key TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,8 @@
+-- Источники (Origin) браузерных клиентов приложения: на их запросы шлюз отвечает заголовками CORS.
+-- Запись - scheme://host[:port], самая левая метка хоста может быть * (https://*.example.com)
+CREATE TABLE IF NOT EXISTS app_origins
+(
+    app_id INTEGER NOT NULL REFERENCES apps (id) ON DELETE CASCADE,
+    origin TEXT    NOT NULL,
+    PRIMARY KEY (app_id, origin)
+);
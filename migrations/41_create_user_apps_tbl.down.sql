@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS app_admins;
+DROP TABLE IF EXISTS user_apps;
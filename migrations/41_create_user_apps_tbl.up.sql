@@ -0,0 +1,20 @@
+-- Приложения пользователя (user_apps.enabled): запись появляется при регистрации через приложение или при
+-- первом входе в него. По ним администратор приложения видит только пользователей своих приложений
+CREATE TABLE IF NOT EXISTS user_apps
+(
+    app_id        INTEGER   NOT NULL REFERENCES apps (id) ON DELETE CASCADE,
+    user_id       INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    first_seen_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (app_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_user_apps_user_id ON user_apps (user_id);
+
+-- Администраторы приложений: без прав суперадминистратора Admin показывает им пользователей
+-- и статистику только этих приложений
+CREATE TABLE IF NOT EXISTS app_admins
+(
+    app_id  INTEGER NOT NULL REFERENCES apps (id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    PRIMARY KEY (app_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_app_admins_user_id ON app_admins (user_id);
@@ -0,0 +1,3 @@
+ALTER TABLE apps DROP COLUMN refresh_max_lifetime;
+ALTER TABLE apps DROP COLUMN refresh_sliding_window;
+DROP TABLE IF EXISTS refresh_families;
@@ -0,0 +1,29 @@
+-- Цепочки refresh-токенов: одна на вход. Хранится только SHA-256 секрета текущего токена (secret_hash);
+-- обновление заменяет его и сдвигает expires_at (скользящее окно), но не дальше absolute_expires_at.
+-- Предъявление уже заменённого токена отзывает всю цепочку. Клеймы входа (scope, org_id, amr, acr, auth_time)
+-- переносятся в токены доступа, выданные по цепочке.
+-- refresh_sliding_window, refresh_max_lifetime - окна приложения в секундах (0 - значения по умолчанию сервиса)
+CREATE TABLE IF NOT EXISTS refresh_families
+(
+    id                  TEXT PRIMARY KEY,
+    user_id             INTEGER   NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    app_id              INTEGER   NOT NULL REFERENCES apps (id),
+    session_id          TEXT      NOT NULL DEFAULT '',
+    secret_hash         TEXT      NOT NULL,
+    scope               TEXT      NOT NULL DEFAULT '',
+    org_id              INTEGER   NOT NULL DEFAULT 0,
+    amr                 TEXT      NOT NULL DEFAULT '',
+    acr                 TEXT      NOT NULL DEFAULT '',
+    auth_time           TIMESTAMP,
+    created_at          TIMESTAMP NOT NULL,
+    expires_at          TIMESTAMP NOT NULL,
+    absolute_expires_at TIMESTAMP NOT NULL,
+    revoked_at          TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_families_user ON refresh_families (user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_families_expires_at ON refresh_families (expires_at);
+
+ALTER TABLE apps
+    ADD COLUMN refresh_sliding_window INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE apps
+    ADD COLUMN refresh_max_lifetime INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+ALTER TABLE sessions DROP COLUMN auth_time;
+ALTER TABLE sessions DROP COLUMN acr;
+ALTER TABLE sessions DROP COLUMN amr;
+ALTER TABLE sessions DROP COLUMN org_id;
+ALTER TABLE sessions DROP COLUMN scope;
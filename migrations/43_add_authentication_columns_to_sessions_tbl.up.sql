@@ -0,0 +1,13 @@
+-- Как пользователь вошёл в сессию: те же клеймы, что в токенах входа (scope, org_id, amr, acr, auth_time).
+-- По ним вызывающий, найденный по id сессии (cookie шлюза), получает те же права, что и по токену.
+-- У сессий, созданных до миграции, значения пустые
+ALTER TABLE sessions
+    ADD COLUMN scope TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions
+    ADD COLUMN org_id INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE sessions
+    ADD COLUMN amr TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions
+    ADD COLUMN acr TEXT NOT NULL DEFAULT '';
+ALTER TABLE sessions
+    ADD COLUMN auth_time TIMESTAMP;
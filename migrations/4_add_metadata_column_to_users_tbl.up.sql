@@ -0,0 +1,2 @@
+ALTER TABLE users
+    ADD COLUMN metadata TEXT NOT NULL DEFAULT '{}';
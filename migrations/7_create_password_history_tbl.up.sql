@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS password_history
+(
+    id         INTEGER PRIMARY KEY,
+    user_id    INTEGER   NOT NULL REFERENCES users (id),
+    pass_hash  BLOB      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_password_history_user_id ON password_history (user_id, id);
@@ -0,0 +1,2 @@
+ALTER TABLE users DROP COLUMN force_password_change;
+ALTER TABLE users DROP COLUMN password_changed_at;
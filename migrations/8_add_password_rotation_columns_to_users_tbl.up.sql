@@ -0,0 +1,7 @@
+ALTER TABLE users
+    ADD COLUMN password_changed_at TIMESTAMP;
+-- Для существующих пользователей отсчёт срока действия пароля начинается с момента миграции
+UPDATE users
+SET password_changed_at = CURRENT_TIMESTAMP;
+ALTER TABLE users
+    ADD COLUMN force_password_change BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS outbox
+(
+    seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+    event_id   TEXT      NOT NULL UNIQUE,
+    type       TEXT      NOT NULL,
+    payload    BLOB      NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at    TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_unsent ON outbox (seq) WHERE sent_at IS NULL;
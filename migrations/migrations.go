@@ -0,0 +1,64 @@
+// Package migrations - миграции схемы БД, встроенные в бинарник. Сервис по ним знает, какую версию
+// схемы ожидает, и не обслуживает запросы на старой схеме (schemacheck).
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// FS - файлы миграций: <версия>_<имя>.up.sql и <версия>_<имя>.down.sql
+//
+//go:embed *.sql
+var FS embed.FS
+
+// Migration - одна миграция вверх
+type Migration struct {
+	Version int64
+	Name    string // имя файла
+}
+
+// All - миграции вверх по возрастанию версии
+func All() []Migration {
+	entries, _ := fs.ReadDir(FS, ".")
+
+	var all []Migration
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, ".up.sql") {
+			continue
+		}
+
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			continue
+		}
+		all = append(all, Migration{Version: version, Name: name})
+	}
+
+	slices.SortFunc(all, func(a, b Migration) int { return int(a.Version - b.Version) })
+
+	return all
+}
+
+// Latest - версия схемы, которую ожидает эта сборка: номер последней встроенной миграции
+func Latest() int64 {
+	all := All()
+	if len(all) == 0 {
+		return 0
+	}
+
+	return all[len(all)-1].Version
+}
+
+// Pending - миграции новее версии applied, которые ещё не применены
+func Pending(applied int64) []Migration {
+	all := All()
+	i, _ := slices.BinarySearchFunc(all, applied+1, func(m Migration, v int64) int { return int(m.Version - v) })
+
+	return all[i:]
+}
@@ -0,0 +1,36 @@
+package migrations
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAll(t *testing.T) {
+	all := All()
+	require.NotEmpty(t, all)
+
+	// Версии идут подряд с 1, у каждой миграции вверх есть миграция вниз
+	for i, m := range all {
+		assert.Equal(t, int64(i+1), m.Version, m.Name)
+
+		_, err := fs.Stat(FS, strings.TrimSuffix(m.Name, ".up.sql")+".down.sql")
+		assert.NoError(t, err, m.Name)
+	}
+	assert.Equal(t, all[len(all)-1].Version, Latest())
+}
+
+func TestPending(t *testing.T) {
+	latest := Latest()
+
+	assert.Empty(t, Pending(latest))
+	assert.Empty(t, Pending(latest+5))
+
+	pending := Pending(latest - 2)
+	require.Len(t, pending, 2)
+	assert.Equal(t, latest-1, pending[0].Version)
+	assert.Len(t, Pending(0), len(All()))
+}
@@ -0,0 +1,81 @@
+package ssoclient
+
+import (
+	"crypto/sha256"
+	"sync"
+	"time"
+)
+
+// maxCacheEntries - предел размера кэша; при заполнении новые результаты не кэшируются,
+// пока не истекут старые
+const maxCacheEntries = 10000
+
+// validationCache - успешные результаты ValidateToken. Ключ - хэш токена, чтобы сами токены
+// не хранились в памяти процесса дольше запроса
+type validationCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[[sha256.Size]byte]cacheEntry
+}
+
+type cacheEntry struct {
+	claims  Claims
+	expires time.Time
+}
+
+func newValidationCache(ttl time.Duration) *validationCache {
+	return &validationCache{
+		ttl:     ttl,
+		entries: make(map[[sha256.Size]byte]cacheEntry),
+	}
+}
+
+// get - результат проверки token, если он есть и ещё не устарел
+func (c *validationCache) get(token string) (Claims, bool) {
+	key := sha256.Sum256([]byte(token))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return Claims{}, false
+	}
+	if !time.Now().Before(e.expires) {
+		delete(c.entries, key)
+
+		return Claims{}, false
+	}
+
+	return e.claims, true
+}
+
+// put - запоминает результат на ttl, но не дольше срока действия самого токена
+func (c *validationCache) put(token string, claims Claims) {
+	now := time.Now()
+
+	expires := now.Add(c.ttl)
+	if !claims.ExpiresAt.IsZero() && claims.ExpiresAt.Before(expires) {
+		expires = claims.ExpiresAt
+	}
+	if !now.Before(expires) {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= maxCacheEntries {
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			return
+		}
+	}
+
+	c.entries[sha256.Sum256([]byte(token))] = cacheEntry{claims: claims, expires: expires}
+}
@@ -0,0 +1,355 @@
+// Package ssoclient - клиент SSO для сервисов-потребителей: вход, регистрация, проверка токенов
+// (с необязательным кэшем), повторы при недоступности сервера и oauth2.TokenSource для HTTP-клиентов.
+package ssoclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// Ошибки клиента. Исходная ошибка gRPC сохраняется в цепочке, поэтому status.Code(err) тоже работает
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrPasswordExpired    = errors.New("password expired")
+	ErrConsentRequired    = errors.New("consent required")
+	ErrTOSRequired        = errors.New("terms of service acceptance required")
+	ErrUserExists         = errors.New("user already exists")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrInvalidToken       = errors.New("invalid token")
+	ErrRefreshDisabled    = errors.New("refresh tokens are disabled")
+	ErrReloginRequired    = errors.New("sign-in expired, sign in again") // refresh-токен больше не обменять
+)
+
+// ErrorInfo.Reason, которыми сервер помечает отказ во входе при верном пароле
+const (
+	reasonPasswordExpired = "PASSWORD_EXPIRED"
+	reasonConsentRequired = "CONSENT_REQUIRED" // стороннему приложению нужно согласие пользователя
+
+	reasonTOSAcceptanceRequired   = "TOS_ACCEPTANCE_REQUIRED"   // регистрация без принятия текущих условий
+	reasonTOSReacceptanceRequired = "TOS_REACCEPTANCE_REQUIRED" // условия изменились, вход заблокирован до принятия
+
+	reasonRefreshDisabled     = "AUTH_REFRESH_DISABLED"
+	reasonInvalidRefreshToken = "AUTH_INVALID_REFRESH_TOKEN" // токен не найден, заменён или цепочка отозвана
+	reasonReloginRequired     = "AUTH_RELOGIN_REQUIRED"      // окно или предел цепочки истекли, сессия завершена
+)
+
+// Token - выданный токен доступа
+type Token struct {
+	AccessToken string
+	TokenType   string // всегда "Bearer"
+	ExpiresAt   time.Time
+	Scopes      []string
+
+	// RefreshToken - токен для Refresh; пусто, если сервер не выдаёт refresh-токены.
+	// Каждый обмен возвращает новый refresh-токен, старый больше не действует
+	RefreshToken     string
+	RefreshExpiresAt time.Time // обменять до этого момента, иначе нужен новый вход
+
+	TOSReacceptanceRequired bool // пользователю нужно принять новую версию условий использования
+}
+
+// Claims - владелец действительного токена
+type Claims struct {
+	UserID    int64
+	Email     string
+	AppID     int
+	IsAdmin   bool      // текущее значение из БД сервера, а не из токена
+	OrgID     int64     // 0, если токен выдан без организации
+	OrgRole   string    // роль в организации OrgID
+	ExpiresAt time.Time // нулевое значение, если у токена нет exp
+}
+
+// options - настройки клиента, задаются через Option
+type options struct {
+	timeout     time.Duration
+	maxAttempts int
+	backoff     time.Duration
+	cacheTTL    time.Duration
+	creds       credentials.TransportCredentials
+	dialOptions []grpc.DialOption
+}
+
+// Option - настройка клиента
+type Option func(*options)
+
+// WithTimeout - таймаут одной попытки вызова (по умолчанию 5s)
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// WithRetry - сколько раз всего пробовать вызов, если сервер недоступен (Unavailable),
+// и пауза перед первым повтором; дальше пауза удваивается. По умолчанию 3 попытки, 100ms
+func WithRetry(maxAttempts int, backoff time.Duration) Option {
+	return func(o *options) {
+		o.maxAttempts = maxAttempts
+		o.backoff = backoff
+	}
+}
+
+// WithValidationCache - кэшировать успешные результаты ValidateToken на ttl (но не дольше срока токена).
+// Отключение пользователя или смена прав станут видны клиенту с задержкой до ttl
+func WithValidationCache(ttl time.Duration) Option {
+	return func(o *options) {
+		o.cacheTTL = ttl
+	}
+}
+
+// WithTLS - TLS с заданными настройками (по умолчанию TLS с системными корневыми сертификатами)
+func WithTLS(cfg *tls.Config) Option {
+	return func(o *options) {
+		o.creds = credentials.NewTLS(cfg)
+	}
+}
+
+// WithInsecure - соединение без TLS (локальная разработка, тесты)
+func WithInsecure() Option {
+	return func(o *options) {
+		o.creds = insecure.NewCredentials()
+	}
+}
+
+// WithDialOptions - дополнительные параметры gRPC-соединения (интерцепторы, балансировка и т. п.)
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) {
+		o.dialOptions = append(o.dialOptions, opts...)
+	}
+}
+
+// Client - клиент SSO. Безопасен для конкурентного использования; создаётся один на процесс
+type Client struct {
+	conn  *grpc.ClientConn
+	auth  ssov1.AuthClient
+	cache *validationCache // nil - кэш выключен
+}
+
+// New - создаёт клиент для сервера addr (host:port). Соединение устанавливается при первом вызове
+// и восстанавливается автоматически
+func New(addr string, opts ...Option) (*Client, error) {
+	const op = "ssoclient.New"
+
+	o := options{
+		timeout:     5 * time.Second,
+		maxAttempts: 3,
+		backoff:     100 * time.Millisecond,
+		creds:       credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12}),
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dialOptions := append([]grpc.DialOption{
+		grpc.WithTransportCredentials(o.creds),
+		grpc.WithChainUnaryInterceptor(retryUnary(o.maxAttempts, o.backoff, o.timeout)),
+	}, o.dialOptions...)
+
+	conn, err := grpc.NewClient(addr, dialOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	c := &Client{
+		conn: conn,
+		auth: ssov1.NewAuthClient(conn),
+	}
+	if o.cacheTTL > 0 {
+		c.cache = newValidationCache(o.cacheTTL)
+	}
+
+	return c, nil
+}
+
+// Close - закрывает соединение
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Login - вход по email и паролю в приложение appID
+func (c *Client) Login(ctx context.Context, email, password string, appID int) (Token, error) {
+	const op = "ssoclient.Login"
+
+	resp, err := c.auth.Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: int32(appID)})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			return Token{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidCredentials, err)
+		case codes.FailedPrecondition:
+			if hasReason(err, reasonPasswordExpired) {
+				return Token{}, fmt.Errorf("%s: %w: %w", op, ErrPasswordExpired, err)
+			}
+			if hasReason(err, reasonConsentRequired) {
+				return Token{}, fmt.Errorf("%s: %w: %w", op, ErrConsentRequired, err)
+			}
+			if hasReason(err, reasonTOSReacceptanceRequired) {
+				return Token{}, fmt.Errorf("%s: %w: %w", op, ErrTOSRequired, err)
+			}
+		}
+
+		return Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+
+	return Token{
+		AccessToken:      resp.GetToken(),
+		TokenType:        resp.GetTokenType(),
+		ExpiresAt:        now.Add(time.Duration(resp.GetExpiresIn()) * time.Second),
+		Scopes:           resp.GetScopes(),
+		RefreshToken:     resp.GetRefreshToken(),
+		RefreshExpiresAt: refreshExpiresAt(now, resp.GetRefreshToken(), resp.GetRefreshExpiresIn()),
+
+		TOSReacceptanceRequired: resp.GetTosReacceptanceRequired(),
+	}, nil
+}
+
+// Refresh - обменивает refresh-токен на новый токен доступа и новый refresh-токен.
+// ErrReloginRequired - цепочка истекла или сессия завершена, ErrInvalidToken - токен неизвестен или уже обменян
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (Token, error) {
+	const op = "ssoclient.Refresh"
+
+	resp, err := c.auth.Refresh(ctx, &ssov1.RefreshRequest{RefreshToken: refreshToken})
+	if err != nil {
+		switch {
+		case hasReason(err, reasonReloginRequired):
+			return Token{}, fmt.Errorf("%s: %w: %w", op, ErrReloginRequired, err)
+		case hasReason(err, reasonInvalidRefreshToken):
+			return Token{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+		case hasReason(err, reasonRefreshDisabled):
+			return Token{}, fmt.Errorf("%s: %w: %w", op, ErrRefreshDisabled, err)
+		}
+
+		return Token{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	now := time.Now()
+
+	return Token{
+		AccessToken:      resp.GetToken(),
+		TokenType:        resp.GetTokenType(),
+		ExpiresAt:        now.Add(time.Duration(resp.GetExpiresIn()) * time.Second),
+		Scopes:           resp.GetScopes(),
+		RefreshToken:     resp.GetRefreshToken(),
+		RefreshExpiresAt: refreshExpiresAt(now, resp.GetRefreshToken(), resp.GetRefreshExpiresIn()),
+	}, nil
+}
+
+// refreshExpiresAt - срок refresh-токена по ответу сервера; без токена - нулевое время
+func refreshExpiresAt(now time.Time, token string, expiresIn int64) time.Time {
+	if token == "" {
+		return time.Time{}
+	}
+
+	return now.Add(time.Duration(expiresIn) * time.Second)
+}
+
+// Register - регистрирует пользователя и возвращает его id
+func (c *Client) Register(ctx context.Context, email, password string) (int64, error) {
+	const op = "ssoclient.Register"
+
+	resp, err := c.auth.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: password})
+	if err != nil {
+		if status.Code(err) == codes.AlreadyExists {
+			return 0, fmt.Errorf("%s: %w: %w", op, ErrUserExists, err)
+		}
+		if status.Code(err) == codes.FailedPrecondition && hasReason(err, reasonTOSAcceptanceRequired) {
+			return 0, fmt.Errorf("%s: %w: %w", op, ErrTOSRequired, err)
+		}
+
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.GetUserId(), nil
+}
+
+// ValidateToken - проверяет токен на сервере (подпись, срок, активность пользователя).
+// При включённом кэше повторная проверка того же токена не обращается к серверу
+func (c *Client) ValidateToken(ctx context.Context, token string) (Claims, error) {
+	const op = "ssoclient.ValidateToken"
+
+	if c.cache != nil {
+		if claims, ok := c.cache.get(token); ok {
+			return claims, nil
+		}
+	}
+
+	resp, err := c.auth.ValidateToken(ctx, &ssov1.ValidateTokenRequest{Token: token})
+	if err != nil {
+		if status.Code(err) == codes.Unauthenticated {
+			return Claims{}, fmt.Errorf("%s: %w: %w", op, ErrInvalidToken, err)
+		}
+
+		return Claims{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	claims := Claims{
+		UserID:  resp.GetUserId(),
+		Email:   resp.GetEmail(),
+		AppID:   int(resp.GetAppId()),
+		IsAdmin: resp.GetIsAdmin(),
+		OrgID:   resp.GetOrgId(),
+		OrgRole: resp.GetOrgRole(),
+	}
+	if resp.GetExpiresAt() != 0 {
+		claims.ExpiresAt = time.Unix(resp.GetExpiresAt(), 0)
+	}
+
+	if c.cache != nil {
+		c.cache.put(token, claims)
+	}
+
+	return claims, nil
+}
+
+// IsAdmin - является ли пользователь администратором
+func (c *Client) IsAdmin(ctx context.Context, userID int64) (bool, error) {
+	const op = "ssoclient.IsAdmin"
+
+	resp, err := c.auth.IsAdmin(ctx, &ssov1.IsAdminRequest{UserId: userID})
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return false, fmt.Errorf("%s: %w: %w", op, ErrUserNotFound, err)
+		}
+
+		return false, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return resp.GetIsAdmin(), nil
+}
+
+// errorDomain - ErrorInfo.Domain ошибок сервера SSO
+const errorDomain = "sso"
+
+// ReasonOf - машиночитаемая причина ошибки сервера (ErrorInfo.Reason): AUTH_INVALID_CREDENTIALS,
+// AUTH_USER_EXISTS, PASSWORD_EXPIRED и т. д. Причины стабильны, в отличие от текста сообщений.
+// Ошибки без своей причины получают общую по коду gRPC (INVALID_ARGUMENT, INTERNAL...).
+// Пустая строка - ошибка не от сервера SSO (например, сеть недоступна до ответа)
+func ReasonOf(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.GetDomain() == errorDomain {
+			return info.GetReason()
+		}
+	}
+
+	return ""
+}
+
+// hasReason - есть ли в ошибке деталь ErrorInfo с указанной причиной
+func hasReason(err error, reason string) bool {
+	return ReasonOf(err) == reason
+}
@@ -0,0 +1,258 @@
+package ssoclient
+
+import (
+	"context"
+	"path"
+	"sso/internal/config"
+	"sso/tests/suite"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testAppID = 1 // приложение из tests/migrations
+
+// startServer - поднимает настоящий SSO в процессе (см. tests/suite) и возвращает его адрес
+func startServer(t *testing.T, opts ...suite.Option) string {
+	t.Helper()
+
+	_, st := suite.New(t, opts...)
+
+	return st.Addr
+}
+
+func newClient(t *testing.T, addr string, opts ...Option) *Client {
+	t.Helper()
+
+	c, err := New(addr, append([]Option{WithInsecure()}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+
+	return c
+}
+
+func TestClient_RegisterLoginValidate(t *testing.T) {
+	c := newClient(t, startServer(t))
+	ctx := context.Background()
+
+	uid, err := c.Register(ctx, "user@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.NotZero(t, uid)
+
+	_, err = c.Register(ctx, "user@example.com", "correct horse")
+	require.ErrorIs(t, err, ErrUserExists)
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+	assert.Equal(t, "AUTH_USER_EXISTS", ReasonOf(err))
+
+	_, err = c.Login(ctx, "user@example.com", "wrong", testAppID)
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, "AUTH_INVALID_CREDENTIALS", ReasonOf(err))
+
+	// Ошибки проверки запроса получают общую причину по коду
+	_, err = c.Login(ctx, "", "wrong", testAppID)
+	assert.Equal(t, "INVALID_ARGUMENT", ReasonOf(err))
+
+	token, err := c.Login(ctx, "user@example.com", "correct horse", testAppID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", token.TokenType)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), token.ExpiresAt, 2*time.Second)
+
+	claims, err := c.ValidateToken(ctx, token.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, uid, claims.UserID)
+	assert.Equal(t, "user@example.com", claims.Email)
+	assert.Equal(t, testAppID, claims.AppID)
+	assert.False(t, claims.IsAdmin)
+	assert.WithinDuration(t, token.ExpiresAt, claims.ExpiresAt, 2*time.Second)
+
+	_, err = c.ValidateToken(ctx, "not-a-token")
+	require.ErrorIs(t, err, ErrInvalidToken)
+	assert.Equal(t, "AUTH_INVALID_TOKEN", ReasonOf(err))
+
+	isAdmin, err := c.IsAdmin(ctx, uid)
+	require.NoError(t, err)
+	assert.False(t, isAdmin)
+
+	_, err = c.IsAdmin(ctx, uid+100)
+	require.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestClient_ValidationCache(t *testing.T) {
+	var calls atomic.Int32
+	count := grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		calls.Add(1)
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+
+	c := newClient(t, startServer(t), WithValidationCache(time.Minute), WithDialOptions(count))
+	ctx := context.Background()
+
+	_, err := c.Register(ctx, "cached@example.com", "correct horse")
+	require.NoError(t, err)
+	token, err := c.Login(ctx, "cached@example.com", "correct horse", testAppID)
+	require.NoError(t, err)
+
+	calls.Store(0)
+	for range 3 {
+		claims, err := c.ValidateToken(ctx, token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, "cached@example.com", claims.Email)
+	}
+	assert.Equal(t, int32(1), calls.Load())
+
+	// Ошибки не кэшируются
+	for range 2 {
+		_, err := c.ValidateToken(ctx, "not-a-token")
+		require.ErrorIs(t, err, ErrInvalidToken)
+	}
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_TokenSource(t *testing.T) {
+	c := newClient(t, startServer(t))
+	ctx := context.Background()
+
+	_, err := c.Register(ctx, "ts@example.com", "correct horse")
+	require.NoError(t, err)
+
+	ts := c.TokenSource(ctx, "ts@example.com", "correct horse", testAppID)
+
+	first, err := ts.Token()
+	require.NoError(t, err)
+	assert.True(t, first.Valid())
+	assert.Equal(t, "Bearer", first.Type())
+
+	second, err := ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, first.AccessToken, second.AccessToken, "valid token must be reused")
+
+	_, err = c.TokenSource(ctx, "ts@example.com", "wrong", testAppID).Token()
+	require.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+// withRefresh - сервер выдаёт refresh-токены при входе
+func withRefresh(cfg *config.Config) { cfg.Refresh.Enabled = true }
+
+func TestClient_Refresh(t *testing.T) {
+	c := newClient(t, startServer(t, withRefresh))
+	ctx := context.Background()
+
+	_, err := c.Register(ctx, "refresh@example.com", "correct horse")
+	require.NoError(t, err)
+
+	login, err := c.Login(ctx, "refresh@example.com", "correct horse", testAppID)
+	require.NoError(t, err)
+	require.NotEmpty(t, login.RefreshToken)
+	assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), login.RefreshExpiresAt, 2*time.Second)
+
+	refreshed, err := c.Refresh(ctx, login.RefreshToken)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", refreshed.TokenType)
+	assert.NotEqual(t, login.RefreshToken, refreshed.RefreshToken)
+
+	claims, err := c.ValidateToken(ctx, refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "refresh@example.com", claims.Email)
+
+	// Повтор обменянного токена отзывает цепочку: не действует и новый
+	_, err = c.Refresh(ctx, login.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	_, err = c.Refresh(ctx, refreshed.RefreshToken)
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestClient_RefreshDisabled(t *testing.T) {
+	c := newClient(t, startServer(t))
+	ctx := context.Background()
+
+	_, err := c.Register(ctx, "plain@example.com", "correct horse")
+	require.NoError(t, err)
+
+	// Без refresh-токенов на сервере вход их не выдаёт, а обмен недоступен
+	token, err := c.Login(ctx, "plain@example.com", "correct horse", testAppID)
+	require.NoError(t, err)
+	assert.Empty(t, token.RefreshToken)
+	assert.True(t, token.RefreshExpiresAt.IsZero())
+
+	_, err = c.Refresh(ctx, "family.secret")
+	require.ErrorIs(t, err, ErrRefreshDisabled)
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+func TestClient_TokenSourceRefreshes(t *testing.T) {
+	var methods []string
+	record := grpc.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		methods = append(methods, path.Base(method))
+
+		return invoker(ctx, method, req, reply, cc, opts...)
+	})
+
+	c := newClient(t, startServer(t, withRefresh), WithDialOptions(record))
+	ctx := context.Background()
+
+	_, err := c.Register(ctx, "ts-refresh@example.com", "correct horse")
+	require.NoError(t, err)
+
+	// Источник без oauth2.ReuseTokenSource: каждый вызов - новый токен
+	src := &loginSource{ctx: ctx, client: c, email: "ts-refresh@example.com", password: "correct horse", appID: testAppID}
+
+	methods = nil
+	first, err := src.Token()
+	require.NoError(t, err)
+	second, err := src.Token()
+	require.NoError(t, err)
+	assert.NotEqual(t, first.AccessToken, second.AccessToken)
+	assert.Equal(t, []string{"Login", "Refresh"}, methods, "the password is sent only once")
+
+	// Refresh-токен больше не обменять - источник входит заново по паролю
+	_, err = c.Refresh(ctx, src.refresh.RefreshToken)
+	require.NoError(t, err)
+
+	methods = nil
+	third, err := src.Token()
+	require.NoError(t, err)
+	assert.True(t, third.Valid())
+	assert.Equal(t, []string{"Refresh", "Login"}, methods)
+}
+
+func TestRetryUnary(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error // ответы сервера по попыткам; дальше - успех
+		wantCalls int
+		wantCode  codes.Code
+	}{
+		{name: "recovers", errs: []error{status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down")}, wantCalls: 3, wantCode: codes.OK},
+		{name: "gives up", errs: []error{status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down"), status.Error(codes.Unavailable, "down")}, wantCalls: 3, wantCode: codes.Unavailable},
+		{name: "other codes are not retried", errs: []error{status.Error(codes.NotFound, "no")}, wantCalls: 1, wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				_, hasDeadline := ctx.Deadline()
+				assert.True(t, hasDeadline, "each attempt must have a timeout")
+
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+
+				return nil
+			}
+
+			err := retryUnary(3, time.Millisecond, time.Second)(context.Background(), "/auth.Auth/Login", nil, nil, nil, invoker)
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			assert.Equal(t, tt.wantCalls, calls)
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package ssoclient
+
+import (
+	"context"
+	"math/rand/v2"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// retryUnary - интерцептор: ограничивает каждую попытку таймаутом и повторяет вызов,
+// пока сервер недоступен (Unavailable). Остальные ошибки возвращаются сразу.
+// Паузы между попытками растут вдвое, со случайной добавкой до половины паузы,
+// чтобы клиенты не приходили к поднявшемуся серверу одновременно
+func retryUnary(maxAttempts int, backoff, timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		delay := backoff
+
+		for attempt := 1; ; attempt++ {
+			err := invokeWithTimeout(ctx, timeout, method, req, reply, cc, invoker, opts...)
+			if status.Code(err) != codes.Unavailable || attempt >= maxAttempts {
+				return err
+			}
+
+			select {
+			case <-time.After(delay + jitter(delay)):
+			case <-ctx.Done():
+				return err
+			}
+			delay *= 2
+		}
+	}
+}
+
+// invokeWithTimeout - одна попытка вызова; timeout <= 0 - без собственного таймаута
+func invokeWithTimeout(ctx context.Context, timeout time.Duration, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+// jitter - случайная добавка к паузе, от 0 до d/2
+func jitter(d time.Duration) time.Duration {
+	if d <= 1 {
+		return 0
+	}
+
+	return rand.N(d / 2)
+}
@@ -0,0 +1,69 @@
+package ssoclient
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenSource - источник токенов для golang.org/x/oauth2 (например, oauth2.NewClient).
+// Токен переиспользуется, пока не истечёт. Затем, если сервер выдал refresh-токен, источник обменивает его
+// через Refresh; без refresh-токена или когда обмен невозможен (ErrReloginRequired и т.п.) клиент входит заново
+// с теми же учётными данными. ctx используется для всех вызовов Login и Refresh этого источника
+func (c *Client) TokenSource(ctx context.Context, email, password string, appID int) oauth2.TokenSource {
+	return oauth2.ReuseTokenSource(nil, &loginSource{
+		ctx:      ctx,
+		client:   c,
+		email:    email,
+		password: password,
+		appID:    appID,
+	})
+}
+
+// loginSource - выдаёт новый токен при каждом вызове; кэширование делает oauth2.ReuseTokenSource.
+// Он же сериализует вызовы Token, поэтому refresh не защищён мьютексом
+type loginSource struct {
+	ctx      context.Context
+	client   *Client
+	email    string
+	password string
+	appID    int
+
+	refresh Token // последний выданный токен с refresh-токеном; пусто - следующий вызов входит заново
+}
+
+// Token - реализует oauth2.TokenSource
+func (s *loginSource) Token() (*oauth2.Token, error) {
+	t, err := s.next()
+	if err != nil {
+		return nil, err
+	}
+
+	s.refresh = Token{}
+	if t.RefreshToken != "" {
+		s.refresh = t
+	}
+
+	return &oauth2.Token{
+		AccessToken: t.AccessToken,
+		TokenType:   t.TokenType,
+		Expiry:      t.ExpiresAt,
+	}, nil
+}
+
+// next - обмен refresh-токена, пока он действует, иначе вход по паролю
+func (s *loginSource) next() (Token, error) {
+	if s.refresh.RefreshToken != "" && time.Now().Before(s.refresh.RefreshExpiresAt) {
+		t, err := s.client.Refresh(s.ctx, s.refresh.RefreshToken)
+		if err == nil {
+			return t, nil
+		}
+		if !errors.Is(err, ErrReloginRequired) && !errors.Is(err, ErrInvalidToken) && !errors.Is(err, ErrRefreshDisabled) {
+			return Token{}, err
+		}
+	}
+
+	return s.client.Login(s.ctx, s.email, s.password, s.appID)
+}
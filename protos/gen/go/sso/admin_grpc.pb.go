@@ -0,0 +1,1879 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v4.25.6
+// source: sso/admin.proto
+
+// Сервис администрирования живёт в том же пакете, что и Auth,
+// но регистрируется отдельно: его методы требуют прав администратора,
+// кроме методов организаций - их проверяет сам обработчик (владелец или администратор организации)
+
+package ssov1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Admin_ListUsers_FullMethodName                  = "/auth.Admin/ListUsers"
+	Admin_GetUser_FullMethodName                    = "/auth.Admin/GetUser"
+	Admin_SetAdmin_FullMethodName                   = "/auth.Admin/SetAdmin"
+	Admin_SetForcePasswordChange_FullMethodName     = "/auth.Admin/SetForcePasswordChange"
+	Admin_CreateApp_FullMethodName                  = "/auth.Admin/CreateApp"
+	Admin_ImportUsers_FullMethodName                = "/auth.Admin/ImportUsers"
+	Admin_Backup_FullMethodName                     = "/auth.Admin/Backup"
+	Admin_CreateOrganization_FullMethodName         = "/auth.Admin/CreateOrganization"
+	Admin_AddMember_FullMethodName                  = "/auth.Admin/AddMember"
+	Admin_RemoveMember_FullMethodName               = "/auth.Admin/RemoveMember"
+	Admin_ListMembers_FullMethodName                = "/auth.Admin/ListMembers"
+	Admin_InviteUser_FullMethodName                 = "/auth.Admin/InviteUser"
+	Admin_ListInvitations_FullMethodName            = "/auth.Admin/ListInvitations"
+	Admin_RevokeInvitation_FullMethodName           = "/auth.Admin/RevokeInvitation"
+	Admin_CreateGroup_FullMethodName                = "/auth.Admin/CreateGroup"
+	Admin_DeleteGroup_FullMethodName                = "/auth.Admin/DeleteGroup"
+	Admin_AddToGroup_FullMethodName                 = "/auth.Admin/AddToGroup"
+	Admin_RemoveFromGroup_FullMethodName            = "/auth.Admin/RemoveFromGroup"
+	Admin_ListGroupMembers_FullMethodName           = "/auth.Admin/ListGroupMembers"
+	Admin_SetAppGroupsClaim_FullMethodName          = "/auth.Admin/SetAppGroupsClaim"
+	Admin_SetAppThirdParty_FullMethodName           = "/auth.Admin/SetAppThirdParty"
+	Admin_SetAppSessionLimit_FullMethodName         = "/auth.Admin/SetAppSessionLimit"
+	Admin_SetAppSessionTimeouts_FullMethodName      = "/auth.Admin/SetAppSessionTimeouts"
+	Admin_SetAppRefreshWindows_FullMethodName       = "/auth.Admin/SetAppRefreshWindows"
+	Admin_SetAppMinACR_FullMethodName               = "/auth.Admin/SetAppMinACR"
+	Admin_SetAppOfflineTokens_FullMethodName        = "/auth.Admin/SetAppOfflineTokens"
+	Admin_SetAppProvisioner_FullMethodName          = "/auth.Admin/SetAppProvisioner"
+	Admin_SetAppLoginPolicy_FullMethodName          = "/auth.Admin/SetAppLoginPolicy"
+	Admin_DeleteAppLoginPolicy_FullMethodName       = "/auth.Admin/DeleteAppLoginPolicy"
+	Admin_ListAppLoginPolicies_FullMethodName       = "/auth.Admin/ListAppLoginPolicies"
+	Admin_SetAppNetworkPolicyRecheck_FullMethodName = "/auth.Admin/SetAppNetworkPolicyRecheck"
+	Admin_SetAppRedirectURIs_FullMethodName         = "/auth.Admin/SetAppRedirectURIs"
+	Admin_SetAppOrigins_FullMethodName              = "/auth.Admin/SetAppOrigins"
+	Admin_GetAppOrigins_FullMethodName              = "/auth.Admin/GetAppOrigins"
+	Admin_SetAppAdmin_FullMethodName                = "/auth.Admin/SetAppAdmin"
+	Admin_ListPendingTOS_FullMethodName             = "/auth.Admin/ListPendingTOS"
+	Admin_SetMaintenance_FullMethodName             = "/auth.Admin/SetMaintenance"
+	Admin_GetStats_FullMethodName                   = "/auth.Admin/GetStats"
+	Admin_ExportAuditEvents_FullMethodName          = "/auth.Admin/ExportAuditEvents"
+	Admin_InspectToken_FullMethodName               = "/auth.Admin/InspectToken"
+	Admin_RevokeToken_FullMethodName                = "/auth.Admin/RevokeToken"
+	Admin_RunSelfTest_FullMethodName                = "/auth.Admin/RunSelfTest"
+	Admin_VerifyAuditChain_FullMethodName           = "/auth.Admin/VerifyAuditChain"
+)
+
+// AdminClient is the client API for Admin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AdminClient interface {
+	// Список пользователей постранично (по возрастанию id)
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	// Пользователь по id или email вместе с текущей версией
+	GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error)
+	// Выдать или отозвать права администратора.
+	// Изменения пользователя принимают expected_version: если с момента чтения пользователя изменили,
+	// возвращается ABORTED - нужно перечитать его и повторить
+	SetAdmin(ctx context.Context, in *SetAdminRequest, opts ...grpc.CallOption) (*SetAdminResponse, error)
+	// Потребовать (или отменить требование) смены пароля при следующем входе
+	SetForcePasswordChange(ctx context.Context, in *SetForcePasswordChangeRequest, opts ...grpc.CallOption) (*SetForcePasswordChangeResponse, error)
+	// Зарегистрировать новое приложение; секрет возвращается только один раз
+	CreateApp(ctx context.Context, in *CreateAppRequest, opts ...grpc.CallOption) (*CreateAppResponse, error)
+	// Массовый импорт пользователей с готовыми bcrypt-хешами паролей.
+	// Дубликаты и ошибочные строки пропускаются и перечисляются в ответе, импорт не прерывается
+	ImportUsers(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportUsersRequest, ImportUsersResponse], error)
+	// Согласованная копия БД в каталог резервных копий (backup_dir) без остановки сервера.
+	// Поток сообщает прогресс и завершается итогом; копия перед этим проверяется integrity_check
+	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupResponse], error)
+	// Создать организацию (только суперадминистратор)
+	CreateOrganization(ctx context.Context, in *CreateOrganizationRequest, opts ...grpc.CallOption) (*CreateOrganizationResponse, error)
+	// Добавить пользователя в организацию или изменить его роль.
+	// Доступно суперадминистратору и администраторам организации; назначить owner может только owner
+	AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*AddMemberResponse, error)
+	// Исключить пользователя из организации
+	RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*RemoveMemberResponse, error)
+	// Участники организации
+	ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error)
+	// Пригласить пользователя по email: письмо с одноразовой ссылкой, по которой он сам задаёт пароль
+	// (Auth.AcceptInvitation). Повторное приглашение того же email продлевает ожидающее и отправляет письмо снова
+	InviteUser(ctx context.Context, in *InviteUserRequest, opts ...grpc.CallOption) (*InviteUserResponse, error)
+	// Приглашения постранично (по возрастанию id)
+	ListInvitations(ctx context.Context, in *ListInvitationsRequest, opts ...grpc.CallOption) (*ListInvitationsResponse, error)
+	// Отозвать ожидающее приглашение
+	RevokeInvitation(ctx context.Context, in *RevokeInvitationRequest, opts ...grpc.CallOption) (*RevokeInvitationResponse, error)
+	// Создать группу пользователей. Имена групп попадают в клейм groups токенов приложений,
+	// для которых он включён (SetAppGroupsClaim)
+	CreateGroup(ctx context.Context, in *CreateGroupRequest, opts ...grpc.CallOption) (*CreateGroupResponse, error)
+	// Удалить группу вместе с членством в ней
+	DeleteGroup(ctx context.Context, in *DeleteGroupRequest, opts ...grpc.CallOption) (*DeleteGroupResponse, error)
+	// Добавить пользователя в группу (повторно - без изменений).
+	// Изменения групп попадают в следующий выданный токен, уже выданные токены не меняются
+	AddToGroup(ctx context.Context, in *AddToGroupRequest, opts ...grpc.CallOption) (*AddToGroupResponse, error)
+	// Исключить пользователя из группы
+	RemoveFromGroup(ctx context.Context, in *RemoveFromGroupRequest, opts ...grpc.CallOption) (*RemoveFromGroupResponse, error)
+	// Участники группы
+	ListGroupMembers(ctx context.Context, in *ListGroupMembersRequest, opts ...grpc.CallOption) (*ListGroupMembersResponse, error)
+	// Включить или выключить клейм groups в токенах приложения (по умолчанию выключен)
+	SetAppGroupsClaim(ctx context.Context, in *SetAppGroupsClaimRequest, opts ...grpc.CallOption) (*SetAppGroupsClaimResponse, error)
+	// Пометить приложение как стороннее: вход в него требует согласия пользователя (Auth.GrantConsent).
+	// Внутренние приложения (по умолчанию) согласий не требуют
+	SetAppThirdParty(ctx context.Context, in *SetAppThirdPartyRequest, opts ...grpc.CallOption) (*SetAppThirdPartyResponse, error)
+	// Ограничить число одновременных сессий пользователя в приложении (0 - без ограничения).
+	// policy "reject" - новый вход сверх лимита получает FAILED_PRECONDITION (SESSION_LIMIT_REACHED);
+	// "evict_oldest" - самая старая сессия отзывается вместе с её токенами
+	SetAppSessionLimit(ctx context.Context, in *SetAppSessionLimitRequest, opts ...grpc.CallOption) (*SetAppSessionLimitResponse, error)
+	// Тайм-ауты сессий приложения (0 - выключен, по умолчанию оба выключены): сессия без активности дольше
+	// idle_timeout_seconds или старше max_lifetime_seconds истекает, и Auth.ValidateToken возвращает
+	// UNAUTHENTICATED с причиной SESSION_EXPIRED - клиенту нужно войти заново
+	SetAppSessionTimeouts(ctx context.Context, in *SetAppSessionTimeoutsRequest, opts ...grpc.CallOption) (*SetAppSessionTimeoutsResponse, error)
+	// Окна refresh-токенов приложения (0 - значения сервиса из конфигурации refresh): каждый Auth.Refresh
+	// продлевает refresh-токен на sliding_window_seconds, но не дальше max_lifetime_seconds от входа
+	SetAppRefreshWindows(ctx context.Context, in *SetAppRefreshWindowsRequest, opts ...grpc.CallOption) (*SetAppRefreshWindowsResponse, error)
+	// Минимальный уровень аутентификации приложения: "aal2" - вход только по паролю успешен,
+	// но Auth.Login возвращает step_up_required, и приложению нужно вызвать Auth.StepUp. Пусто - любой уровень
+	SetAppMinACR(ctx context.Context, in *SetAppMinACRRequest, opts ...grpc.CallOption) (*SetAppMinACRResponse, error)
+	// Принимает ли приложение offline-токены фоновых агентов (Auth.IssueOfflineToken). По умолчанию нет:
+	// такие токены приложения отклоняются, и выдать их для него нельзя
+	SetAppOfflineTokens(ctx context.Context, in *SetAppOfflineTokensRequest, opts ...grpc.CallOption) (*SetAppOfflineTokensResponse, error)
+	// Роль provisioner: приложение может своим секретом создавать и отключать пользователей
+	// (Auth.ProvisionUser, Auth.DeprovisionUser, Auth.ListProvisionedUsers)
+	SetAppProvisioner(ctx context.Context, in *SetAppProvisionerRequest, opts ...grpc.CallOption) (*SetAppProvisionerResponse, error)
+	// Создаёт или заменяет политику входа приложения с тем же именем. Вход (Auth.Login, вход по passkey и SMS)
+	// и Auth.StepUp разрешены, только если выполнены все политики приложения; иначе PERMISSION_DENIED
+	// с причиной AUTH_POLICY_DENIED, в metadata ErrorInfo - policy и нарушенное условие rule (network, weekday, time)
+	SetAppLoginPolicy(ctx context.Context, in *SetAppLoginPolicyRequest, opts ...grpc.CallOption) (*SetAppLoginPolicyResponse, error)
+	// Удаляет политику входа приложения; нет такой - NOT_FOUND
+	DeleteAppLoginPolicy(ctx context.Context, in *DeleteAppLoginPolicyRequest, opts ...grpc.CallOption) (*DeleteAppLoginPolicyResponse, error)
+	// Политики входа приложения по имени
+	ListAppLoginPolicies(ctx context.Context, in *ListAppLoginPoliciesRequest, opts ...grpc.CallOption) (*ListAppLoginPoliciesResponse, error)
+	// Проверять ли сети политик входа и у уже выданных токенов: токен, выданный в разрешённой сети,
+	// перестаёт проходить Auth.ValidateToken за её пределами. По умолчанию сеть проверяется только при входе
+	SetAppNetworkPolicyRecheck(ctx context.Context, in *SetAppNetworkPolicyRecheckRequest, opts ...grpc.CallOption) (*SetAppNetworkPolicyRecheckResponse, error)
+	// Адреса возврата приложения для входа по OpenID Connect (заменяет прежний список).
+	// redirect_uri запроса авторизации должен совпадать с одним из них побайтно
+	SetAppRedirectURIs(ctx context.Context, in *SetAppRedirectURIsRequest, opts ...grpc.CallOption) (*SetAppRedirectURIsResponse, error)
+	// Источники (Origin) браузерных клиентов приложения (заменяет прежний список). На их запросы к HTTP-шлюзу
+	// отвечается заголовками CORS (http.gateway.cors). Запись - scheme://host[:port], самая левая метка хоста
+	// может быть * (https://*.example.com - любой поддомен, но не сам example.com)
+	SetAppOrigins(ctx context.Context, in *SetAppOriginsRequest, opts ...grpc.CallOption) (*SetAppOriginsResponse, error)
+	GetAppOrigins(ctx context.Context, in *GetAppOriginsRequest, opts ...grpc.CallOption) (*GetAppOriginsResponse, error)
+	// Назначить пользователя администратором приложения или снять эту роль. Только для суперадминистратора.
+	// С user_apps.enabled администратор без прав суперадминистратора видит в ListUsers, GetUser и GetStats
+	// только пользователей приложений, которыми управляет
+	SetAppAdmin(ctx context.Context, in *SetAppAdminRequest, opts ...grpc.CallOption) (*SetAppAdminResponse, error)
+	// Пользователи, не принявшие текущую версию условий использования (включая не принимавших никакую).
+	// Если сервис не учитывает условия - FAILED_PRECONDITION
+	ListPendingTOS(ctx context.Context, in *ListPendingTOSRequest, opts ...grpc.CallOption) (*ListPendingTOSResponse, error)
+	// Режим обслуживания: изменяющие методы отвечают UNAVAILABLE (ErrorInfo MAINTENANCE и RetryInfo),
+	// проверка токенов и (если не запрещён) вход продолжают работать. Режим не переживает рестарт
+	SetMaintenance(ctx context.Context, in *SetMaintenanceRequest, opts ...grpc.CallOption) (*SetMaintenanceResponse, error)
+	// Сводка для панели администратора. Ответ кешируется примерно на 30 секунд (generated_at - когда
+	// он посчитан), поэтому частый опрос не нагружает хранилище
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error)
+	// Выгрузка журнала безопасности за [from, to) в CSV или JSON Lines, кусками по мере чтения из БД.
+	// Последнее сообщение - trailer с числом строк и SHA-256 всех кусков: по нему клиент проверяет,
+	// что получил выгрузку целиком. В БД события попадают, только если включено audit.store
+	ExportAuditEvents(ctx context.Context, in *ExportAuditEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportAuditEventsResponse], error)
+	// Разбор токена (например, из обращения в поддержку): подпись проверяется ключом приложения из app_id,
+	// секрет приложения передавать не нужно. Истёкший токен тоже разбирается (validity = expired).
+	// Токен неизвестного приложения - NOT_FOUND, не JWT или неверная подпись - INVALID_ARGUMENT
+	InspectToken(ctx context.Context, in *InspectTokenRequest, opts ...grpc.CallOption) (*InspectTokenResponse, error)
+	// Отзыв одного токена: его сессия завершается, а токен без сессии попадает в список отозванных до истечения.
+	// Повторный отзыв и отзыв истёкшего токена - не ошибка. Ошибки разбора - как у InspectToken
+	RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error)
+	// Самопроверка развёртывания: регистрация, подтверждение email, вход, проверка токена, смена пароля, выход
+	// и удаление на временных приложении и пользователе (selftest+<nonce>@sso.invalid), которые удаляются в конце.
+	// Упавший шаг не делает ответ ошибкой: результат каждого шага - в steps. FAILED_PRECONDITION - выключена (selftest.enabled)
+	RunSelfTest(ctx context.Context, in *RunSelfTestRequest, opts ...grpc.CallOption) (*RunSelfTestResponse, error)
+	// Проверка цепочки хешей журнала безопасности (audit.chain) по событиям между первым и последним событием
+	// за [from, to). Разрыв цепочки - не ошибка: ok = false и первое событие, на котором она рвётся.
+	// Если диапазон доходит до конца журнала, проверяется и последнее звено (с audit.chain_key - его подпись)
+	VerifyAuditChain(ctx context.Context, in *VerifyAuditChainRequest, opts ...grpc.CallOption) (*VerifyAuditChainResponse, error)
+}
+
+type adminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAdminClient(cc grpc.ClientConnInterface) AdminClient {
+	return &adminClient{cc}
+}
+
+func (c *adminClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListUsersResponse)
+	err := c.cc.Invoke(ctx, Admin_ListUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetUser(ctx context.Context, in *GetUserRequest, opts ...grpc.CallOption) (*GetUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetUserResponse)
+	err := c.cc.Invoke(ctx, Admin_GetUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAdmin(ctx context.Context, in *SetAdminRequest, opts ...grpc.CallOption) (*SetAdminResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAdminResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAdmin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetForcePasswordChange(ctx context.Context, in *SetForcePasswordChangeRequest, opts ...grpc.CallOption) (*SetForcePasswordChangeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetForcePasswordChangeResponse)
+	err := c.cc.Invoke(ctx, Admin_SetForcePasswordChange_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CreateApp(ctx context.Context, in *CreateAppRequest, opts ...grpc.CallOption) (*CreateAppResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateAppResponse)
+	err := c.cc.Invoke(ctx, Admin_CreateApp_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ImportUsers(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportUsersRequest, ImportUsersResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[0], Admin_ImportUsers_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportUsersRequest, ImportUsersResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Admin_ImportUsersClient = grpc.ClientStreamingClient[ImportUsersRequest, ImportUsersResponse]
+
+func (c *adminClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[1], Admin_Backup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[BackupRequest, BackupResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Admin_BackupClient = grpc.ServerStreamingClient[BackupResponse]
+
+func (c *adminClient) CreateOrganization(ctx context.Context, in *CreateOrganizationRequest, opts ...grpc.CallOption) (*CreateOrganizationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateOrganizationResponse)
+	err := c.cc.Invoke(ctx, Admin_CreateOrganization_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) AddMember(ctx context.Context, in *AddMemberRequest, opts ...grpc.CallOption) (*AddMemberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddMemberResponse)
+	err := c.cc.Invoke(ctx, Admin_AddMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RemoveMember(ctx context.Context, in *RemoveMemberRequest, opts ...grpc.CallOption) (*RemoveMemberResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveMemberResponse)
+	err := c.cc.Invoke(ctx, Admin_RemoveMember_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListMembers(ctx context.Context, in *ListMembersRequest, opts ...grpc.CallOption) (*ListMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListMembersResponse)
+	err := c.cc.Invoke(ctx, Admin_ListMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) InviteUser(ctx context.Context, in *InviteUserRequest, opts ...grpc.CallOption) (*InviteUserResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InviteUserResponse)
+	err := c.cc.Invoke(ctx, Admin_InviteUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListInvitations(ctx context.Context, in *ListInvitationsRequest, opts ...grpc.CallOption) (*ListInvitationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInvitationsResponse)
+	err := c.cc.Invoke(ctx, Admin_ListInvitations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RevokeInvitation(ctx context.Context, in *RevokeInvitationRequest, opts ...grpc.CallOption) (*RevokeInvitationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeInvitationResponse)
+	err := c.cc.Invoke(ctx, Admin_RevokeInvitation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) CreateGroup(ctx context.Context, in *CreateGroupRequest, opts ...grpc.CallOption) (*CreateGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateGroupResponse)
+	err := c.cc.Invoke(ctx, Admin_CreateGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DeleteGroup(ctx context.Context, in *DeleteGroupRequest, opts ...grpc.CallOption) (*DeleteGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteGroupResponse)
+	err := c.cc.Invoke(ctx, Admin_DeleteGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) AddToGroup(ctx context.Context, in *AddToGroupRequest, opts ...grpc.CallOption) (*AddToGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddToGroupResponse)
+	err := c.cc.Invoke(ctx, Admin_AddToGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RemoveFromGroup(ctx context.Context, in *RemoveFromGroupRequest, opts ...grpc.CallOption) (*RemoveFromGroupResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveFromGroupResponse)
+	err := c.cc.Invoke(ctx, Admin_RemoveFromGroup_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListGroupMembers(ctx context.Context, in *ListGroupMembersRequest, opts ...grpc.CallOption) (*ListGroupMembersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListGroupMembersResponse)
+	err := c.cc.Invoke(ctx, Admin_ListGroupMembers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppGroupsClaim(ctx context.Context, in *SetAppGroupsClaimRequest, opts ...grpc.CallOption) (*SetAppGroupsClaimResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppGroupsClaimResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppGroupsClaim_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppThirdParty(ctx context.Context, in *SetAppThirdPartyRequest, opts ...grpc.CallOption) (*SetAppThirdPartyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppThirdPartyResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppThirdParty_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppSessionLimit(ctx context.Context, in *SetAppSessionLimitRequest, opts ...grpc.CallOption) (*SetAppSessionLimitResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppSessionLimitResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppSessionLimit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppSessionTimeouts(ctx context.Context, in *SetAppSessionTimeoutsRequest, opts ...grpc.CallOption) (*SetAppSessionTimeoutsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppSessionTimeoutsResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppSessionTimeouts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppRefreshWindows(ctx context.Context, in *SetAppRefreshWindowsRequest, opts ...grpc.CallOption) (*SetAppRefreshWindowsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppRefreshWindowsResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppRefreshWindows_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppMinACR(ctx context.Context, in *SetAppMinACRRequest, opts ...grpc.CallOption) (*SetAppMinACRResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppMinACRResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppMinACR_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppOfflineTokens(ctx context.Context, in *SetAppOfflineTokensRequest, opts ...grpc.CallOption) (*SetAppOfflineTokensResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppOfflineTokensResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppOfflineTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppProvisioner(ctx context.Context, in *SetAppProvisionerRequest, opts ...grpc.CallOption) (*SetAppProvisionerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppProvisionerResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppProvisioner_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppLoginPolicy(ctx context.Context, in *SetAppLoginPolicyRequest, opts ...grpc.CallOption) (*SetAppLoginPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppLoginPolicyResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppLoginPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) DeleteAppLoginPolicy(ctx context.Context, in *DeleteAppLoginPolicyRequest, opts ...grpc.CallOption) (*DeleteAppLoginPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAppLoginPolicyResponse)
+	err := c.cc.Invoke(ctx, Admin_DeleteAppLoginPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListAppLoginPolicies(ctx context.Context, in *ListAppLoginPoliciesRequest, opts ...grpc.CallOption) (*ListAppLoginPoliciesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAppLoginPoliciesResponse)
+	err := c.cc.Invoke(ctx, Admin_ListAppLoginPolicies_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppNetworkPolicyRecheck(ctx context.Context, in *SetAppNetworkPolicyRecheckRequest, opts ...grpc.CallOption) (*SetAppNetworkPolicyRecheckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppNetworkPolicyRecheckResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppNetworkPolicyRecheck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppRedirectURIs(ctx context.Context, in *SetAppRedirectURIsRequest, opts ...grpc.CallOption) (*SetAppRedirectURIsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppRedirectURIsResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppRedirectURIs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppOrigins(ctx context.Context, in *SetAppOriginsRequest, opts ...grpc.CallOption) (*SetAppOriginsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppOriginsResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppOrigins_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetAppOrigins(ctx context.Context, in *GetAppOriginsRequest, opts ...grpc.CallOption) (*GetAppOriginsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetAppOriginsResponse)
+	err := c.cc.Invoke(ctx, Admin_GetAppOrigins_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetAppAdmin(ctx context.Context, in *SetAppAdminRequest, opts ...grpc.CallOption) (*SetAppAdminResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetAppAdminResponse)
+	err := c.cc.Invoke(ctx, Admin_SetAppAdmin_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ListPendingTOS(ctx context.Context, in *ListPendingTOSRequest, opts ...grpc.CallOption) (*ListPendingTOSResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPendingTOSResponse)
+	err := c.cc.Invoke(ctx, Admin_ListPendingTOS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) SetMaintenance(ctx context.Context, in *SetMaintenanceRequest, opts ...grpc.CallOption) (*SetMaintenanceResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetMaintenanceResponse)
+	err := c.cc.Invoke(ctx, Admin_SetMaintenance_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*GetStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetStatsResponse)
+	err := c.cc.Invoke(ctx, Admin_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) ExportAuditEvents(ctx context.Context, in *ExportAuditEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportAuditEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Admin_ServiceDesc.Streams[2], Admin_ExportAuditEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportAuditEventsRequest, ExportAuditEventsResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Admin_ExportAuditEventsClient = grpc.ServerStreamingClient[ExportAuditEventsResponse]
+
+func (c *adminClient) InspectToken(ctx context.Context, in *InspectTokenRequest, opts ...grpc.CallOption) (*InspectTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InspectTokenResponse)
+	err := c.cc.Invoke(ctx, Admin_InspectToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeTokenResponse)
+	err := c.cc.Invoke(ctx, Admin_RevokeToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) RunSelfTest(ctx context.Context, in *RunSelfTestRequest, opts ...grpc.CallOption) (*RunSelfTestResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RunSelfTestResponse)
+	err := c.cc.Invoke(ctx, Admin_RunSelfTest_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *adminClient) VerifyAuditChain(ctx context.Context, in *VerifyAuditChainRequest, opts ...grpc.CallOption) (*VerifyAuditChainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VerifyAuditChainResponse)
+	err := c.cc.Invoke(ctx, Admin_VerifyAuditChain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// AdminServer is the server API for Admin service.
+// All implementations must embed UnimplementedAdminServer
+// for forward compatibility.
+type AdminServer interface {
+	// Список пользователей постранично (по возрастанию id)
+	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	// Пользователь по id или email вместе с текущей версией
+	GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error)
+	// Выдать или отозвать права администратора.
+	// Изменения пользователя принимают expected_version: если с момента чтения пользователя изменили,
+	// возвращается ABORTED - нужно перечитать его и повторить
+	SetAdmin(context.Context, *SetAdminRequest) (*SetAdminResponse, error)
+	// Потребовать (или отменить требование) смены пароля при следующем входе
+	SetForcePasswordChange(context.Context, *SetForcePasswordChangeRequest) (*SetForcePasswordChangeResponse, error)
+	// Зарегистрировать новое приложение; секрет возвращается только один раз
+	CreateApp(context.Context, *CreateAppRequest) (*CreateAppResponse, error)
+	// Массовый импорт пользователей с готовыми bcrypt-хешами паролей.
+	// Дубликаты и ошибочные строки пропускаются и перечисляются в ответе, импорт не прерывается
+	ImportUsers(grpc.ClientStreamingServer[ImportUsersRequest, ImportUsersResponse]) error
+	// Согласованная копия БД в каталог резервных копий (backup_dir) без остановки сервера.
+	// Поток сообщает прогресс и завершается итогом; копия перед этим проверяется integrity_check
+	Backup(*BackupRequest, grpc.ServerStreamingServer[BackupResponse]) error
+	// Создать организацию (только суперадминистратор)
+	CreateOrganization(context.Context, *CreateOrganizationRequest) (*CreateOrganizationResponse, error)
+	// Добавить пользователя в организацию или изменить его роль.
+	// Доступно суперадминистратору и администраторам организации; назначить owner может только owner
+	AddMember(context.Context, *AddMemberRequest) (*AddMemberResponse, error)
+	// Исключить пользователя из организации
+	RemoveMember(context.Context, *RemoveMemberRequest) (*RemoveMemberResponse, error)
+	// Участники организации
+	ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error)
+	// Пригласить пользователя по email: письмо с одноразовой ссылкой, по которой он сам задаёт пароль
+	// (Auth.AcceptInvitation). Повторное приглашение того же email продлевает ожидающее и отправляет письмо снова
+	InviteUser(context.Context, *InviteUserRequest) (*InviteUserResponse, error)
+	// Приглашения постранично (по возрастанию id)
+	ListInvitations(context.Context, *ListInvitationsRequest) (*ListInvitationsResponse, error)
+	// Отозвать ожидающее приглашение
+	RevokeInvitation(context.Context, *RevokeInvitationRequest) (*RevokeInvitationResponse, error)
+	// Создать группу пользователей. Имена групп попадают в клейм groups токенов приложений,
+	// для которых он включён (SetAppGroupsClaim)
+	CreateGroup(context.Context, *CreateGroupRequest) (*CreateGroupResponse, error)
+	// Удалить группу вместе с членством в ней
+	DeleteGroup(context.Context, *DeleteGroupRequest) (*DeleteGroupResponse, error)
+	// Добавить пользователя в группу (повторно - без изменений).
+	// Изменения групп попадают в следующий выданный токен, уже выданные токены не меняются
+	AddToGroup(context.Context, *AddToGroupRequest) (*AddToGroupResponse, error)
+	// Исключить пользователя из группы
+	RemoveFromGroup(context.Context, *RemoveFromGroupRequest) (*RemoveFromGroupResponse, error)
+	// Участники группы
+	ListGroupMembers(context.Context, *ListGroupMembersRequest) (*ListGroupMembersResponse, error)
+	// Включить или выключить клейм groups в токенах приложения (по умолчанию выключен)
+	SetAppGroupsClaim(context.Context, *SetAppGroupsClaimRequest) (*SetAppGroupsClaimResponse, error)
+	// Пометить приложение как стороннее: вход в него требует согласия пользователя (Auth.GrantConsent).
+	// Внутренние приложения (по умолчанию) согласий не требуют
+	SetAppThirdParty(context.Context, *SetAppThirdPartyRequest) (*SetAppThirdPartyResponse, error)
+	// Ограничить число одновременных сессий пользователя в приложении (0 - без ограничения).
+	// policy "reject" - новый вход сверх лимита получает FAILED_PRECONDITION (SESSION_LIMIT_REACHED);
+	// "evict_oldest" - самая старая сессия отзывается вместе с её токенами
+	SetAppSessionLimit(context.Context, *SetAppSessionLimitRequest) (*SetAppSessionLimitResponse, error)
+	// Тайм-ауты сессий приложения (0 - выключен, по умолчанию оба выключены): сессия без активности дольше
+	// idle_timeout_seconds или старше max_lifetime_seconds истекает, и Auth.ValidateToken возвращает
+	// UNAUTHENTICATED с причиной SESSION_EXPIRED - клиенту нужно войти заново
+	SetAppSessionTimeouts(context.Context, *SetAppSessionTimeoutsRequest) (*SetAppSessionTimeoutsResponse, error)
+	// Окна refresh-токенов приложения (0 - значения сервиса из конфигурации refresh): каждый Auth.Refresh
+	// продлевает refresh-токен на sliding_window_seconds, но не дальше max_lifetime_seconds от входа
+	SetAppRefreshWindows(context.Context, *SetAppRefreshWindowsRequest) (*SetAppRefreshWindowsResponse, error)
+	// Минимальный уровень аутентификации приложения: "aal2" - вход только по паролю успешен,
+	// но Auth.Login возвращает step_up_required, и приложению нужно вызвать Auth.StepUp. Пусто - любой уровень
+	SetAppMinACR(context.Context, *SetAppMinACRRequest) (*SetAppMinACRResponse, error)
+	// Принимает ли приложение offline-токены фоновых агентов (Auth.IssueOfflineToken). По умолчанию нет:
+	// такие токены приложения отклоняются, и выдать их для него нельзя
+	SetAppOfflineTokens(context.Context, *SetAppOfflineTokensRequest) (*SetAppOfflineTokensResponse, error)
+	// Роль provisioner: приложение может своим секретом создавать и отключать пользователей
+	// (Auth.ProvisionUser, Auth.DeprovisionUser, Auth.ListProvisionedUsers)
+	SetAppProvisioner(context.Context, *SetAppProvisionerRequest) (*SetAppProvisionerResponse, error)
+	// Создаёт или заменяет политику входа приложения с тем же именем. Вход (Auth.Login, вход по passkey и SMS)
+	// и Auth.StepUp разрешены, только если выполнены все политики приложения; иначе PERMISSION_DENIED
+	// с причиной AUTH_POLICY_DENIED, в metadata ErrorInfo - policy и нарушенное условие rule (network, weekday, time)
+	SetAppLoginPolicy(context.Context, *SetAppLoginPolicyRequest) (*SetAppLoginPolicyResponse, error)
+	// Удаляет политику входа приложения; нет такой - NOT_FOUND
+	DeleteAppLoginPolicy(context.Context, *DeleteAppLoginPolicyRequest) (*DeleteAppLoginPolicyResponse, error)
+	// Политики входа приложения по имени
+	ListAppLoginPolicies(context.Context, *ListAppLoginPoliciesRequest) (*ListAppLoginPoliciesResponse, error)
+	// Проверять ли сети политик входа и у уже выданных токенов: токен, выданный в разрешённой сети,
+	// перестаёт проходить Auth.ValidateToken за её пределами. По умолчанию сеть проверяется только при входе
+	SetAppNetworkPolicyRecheck(context.Context, *SetAppNetworkPolicyRecheckRequest) (*SetAppNetworkPolicyRecheckResponse, error)
+	// Адреса возврата приложения для входа по OpenID Connect (заменяет прежний список).
+	// redirect_uri запроса авторизации должен совпадать с одним из них побайтно
+	SetAppRedirectURIs(context.Context, *SetAppRedirectURIsRequest) (*SetAppRedirectURIsResponse, error)
+	// Источники (Origin) браузерных клиентов приложения (заменяет прежний список). На их запросы к HTTP-шлюзу
+	// отвечается заголовками CORS (http.gateway.cors). Запись - scheme://host[:port], самая левая метка хоста
+	// может быть * (https://*.example.com - любой поддомен, но не сам example.com)
+	SetAppOrigins(context.Context, *SetAppOriginsRequest) (*SetAppOriginsResponse, error)
+	GetAppOrigins(context.Context, *GetAppOriginsRequest) (*GetAppOriginsResponse, error)
+	// Назначить пользователя администратором приложения или снять эту роль. Только для суперадминистратора.
+	// С user_apps.enabled администратор без прав суперадминистратора видит в ListUsers, GetUser и GetStats
+	// только пользователей приложений, которыми управляет
+	SetAppAdmin(context.Context, *SetAppAdminRequest) (*SetAppAdminResponse, error)
+	// Пользователи, не принявшие текущую версию условий использования (включая не принимавших никакую).
+	// Если сервис не учитывает условия - FAILED_PRECONDITION
+	ListPendingTOS(context.Context, *ListPendingTOSRequest) (*ListPendingTOSResponse, error)
+	// Режим обслуживания: изменяющие методы отвечают UNAVAILABLE (ErrorInfo MAINTENANCE и RetryInfo),
+	// проверка токенов и (если не запрещён) вход продолжают работать. Режим не переживает рестарт
+	SetMaintenance(context.Context, *SetMaintenanceRequest) (*SetMaintenanceResponse, error)
+	// Сводка для панели администратора. Ответ кешируется примерно на 30 секунд (generated_at - когда
+	// он посчитан), поэтому частый опрос не нагружает хранилище
+	GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error)
+	// Выгрузка журнала безопасности за [from, to) в CSV или JSON Lines, кусками по мере чтения из БД.
+	// Последнее сообщение - trailer с числом строк и SHA-256 всех кусков: по нему клиент проверяет,
+	// что получил выгрузку целиком. В БД события попадают, только если включено audit.store
+	ExportAuditEvents(*ExportAuditEventsRequest, grpc.ServerStreamingServer[ExportAuditEventsResponse]) error
+	// Разбор токена (например, из обращения в поддержку): подпись проверяется ключом приложения из app_id,
+	// секрет приложения передавать не нужно. Истёкший токен тоже разбирается (validity = expired).
+	// Токен неизвестного приложения - NOT_FOUND, не JWT или неверная подпись - INVALID_ARGUMENT
+	InspectToken(context.Context, *InspectTokenRequest) (*InspectTokenResponse, error)
+	// Отзыв одного токена: его сессия завершается, а токен без сессии попадает в список отозванных до истечения.
+	// Повторный отзыв и отзыв истёкшего токена - не ошибка. Ошибки разбора - как у InspectToken
+	RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error)
+	// Самопроверка развёртывания: регистрация, подтверждение email, вход, проверка токена, смена пароля, выход
+	// и удаление на временных приложении и пользователе (selftest+<nonce>@sso.invalid), которые удаляются в конце.
+	// Упавший шаг не делает ответ ошибкой: результат каждого шага - в steps. FAILED_PRECONDITION - выключена (selftest.enabled)
+	RunSelfTest(context.Context, *RunSelfTestRequest) (*RunSelfTestResponse, error)
+	// Проверка цепочки хешей журнала безопасности (audit.chain) по событиям между первым и последним событием
+	// за [from, to). Разрыв цепочки - не ошибка: ok = false и первое событие, на котором она рвётся.
+	// Если диапазон доходит до конца журнала, проверяется и последнее звено (с audit.chain_key - его подпись)
+	VerifyAuditChain(context.Context, *VerifyAuditChainRequest) (*VerifyAuditChainResponse, error)
+	mustEmbedUnimplementedAdminServer()
+}
+
+// UnimplementedAdminServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedAdminServer struct{}
+
+func (UnimplementedAdminServer) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedAdminServer) GetUser(context.Context, *GetUserRequest) (*GetUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUser not implemented")
+}
+func (UnimplementedAdminServer) SetAdmin(context.Context, *SetAdminRequest) (*SetAdminResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAdmin not implemented")
+}
+func (UnimplementedAdminServer) SetForcePasswordChange(context.Context, *SetForcePasswordChangeRequest) (*SetForcePasswordChangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetForcePasswordChange not implemented")
+}
+func (UnimplementedAdminServer) CreateApp(context.Context, *CreateAppRequest) (*CreateAppResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateApp not implemented")
+}
+func (UnimplementedAdminServer) ImportUsers(grpc.ClientStreamingServer[ImportUsersRequest, ImportUsersResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ImportUsers not implemented")
+}
+func (UnimplementedAdminServer) Backup(*BackupRequest, grpc.ServerStreamingServer[BackupResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Backup not implemented")
+}
+func (UnimplementedAdminServer) CreateOrganization(context.Context, *CreateOrganizationRequest) (*CreateOrganizationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateOrganization not implemented")
+}
+func (UnimplementedAdminServer) AddMember(context.Context, *AddMemberRequest) (*AddMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddMember not implemented")
+}
+func (UnimplementedAdminServer) RemoveMember(context.Context, *RemoveMemberRequest) (*RemoveMemberResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveMember not implemented")
+}
+func (UnimplementedAdminServer) ListMembers(context.Context, *ListMembersRequest) (*ListMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMembers not implemented")
+}
+func (UnimplementedAdminServer) InviteUser(context.Context, *InviteUserRequest) (*InviteUserResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InviteUser not implemented")
+}
+func (UnimplementedAdminServer) ListInvitations(context.Context, *ListInvitationsRequest) (*ListInvitationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInvitations not implemented")
+}
+func (UnimplementedAdminServer) RevokeInvitation(context.Context, *RevokeInvitationRequest) (*RevokeInvitationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeInvitation not implemented")
+}
+func (UnimplementedAdminServer) CreateGroup(context.Context, *CreateGroupRequest) (*CreateGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateGroup not implemented")
+}
+func (UnimplementedAdminServer) DeleteGroup(context.Context, *DeleteGroupRequest) (*DeleteGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteGroup not implemented")
+}
+func (UnimplementedAdminServer) AddToGroup(context.Context, *AddToGroupRequest) (*AddToGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddToGroup not implemented")
+}
+func (UnimplementedAdminServer) RemoveFromGroup(context.Context, *RemoveFromGroupRequest) (*RemoveFromGroupResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveFromGroup not implemented")
+}
+func (UnimplementedAdminServer) ListGroupMembers(context.Context, *ListGroupMembersRequest) (*ListGroupMembersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListGroupMembers not implemented")
+}
+func (UnimplementedAdminServer) SetAppGroupsClaim(context.Context, *SetAppGroupsClaimRequest) (*SetAppGroupsClaimResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppGroupsClaim not implemented")
+}
+func (UnimplementedAdminServer) SetAppThirdParty(context.Context, *SetAppThirdPartyRequest) (*SetAppThirdPartyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppThirdParty not implemented")
+}
+func (UnimplementedAdminServer) SetAppSessionLimit(context.Context, *SetAppSessionLimitRequest) (*SetAppSessionLimitResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppSessionLimit not implemented")
+}
+func (UnimplementedAdminServer) SetAppSessionTimeouts(context.Context, *SetAppSessionTimeoutsRequest) (*SetAppSessionTimeoutsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppSessionTimeouts not implemented")
+}
+func (UnimplementedAdminServer) SetAppRefreshWindows(context.Context, *SetAppRefreshWindowsRequest) (*SetAppRefreshWindowsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppRefreshWindows not implemented")
+}
+func (UnimplementedAdminServer) SetAppMinACR(context.Context, *SetAppMinACRRequest) (*SetAppMinACRResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppMinACR not implemented")
+}
+func (UnimplementedAdminServer) SetAppOfflineTokens(context.Context, *SetAppOfflineTokensRequest) (*SetAppOfflineTokensResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppOfflineTokens not implemented")
+}
+func (UnimplementedAdminServer) SetAppProvisioner(context.Context, *SetAppProvisionerRequest) (*SetAppProvisionerResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppProvisioner not implemented")
+}
+func (UnimplementedAdminServer) SetAppLoginPolicy(context.Context, *SetAppLoginPolicyRequest) (*SetAppLoginPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppLoginPolicy not implemented")
+}
+func (UnimplementedAdminServer) DeleteAppLoginPolicy(context.Context, *DeleteAppLoginPolicyRequest) (*DeleteAppLoginPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAppLoginPolicy not implemented")
+}
+func (UnimplementedAdminServer) ListAppLoginPolicies(context.Context, *ListAppLoginPoliciesRequest) (*ListAppLoginPoliciesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAppLoginPolicies not implemented")
+}
+func (UnimplementedAdminServer) SetAppNetworkPolicyRecheck(context.Context, *SetAppNetworkPolicyRecheckRequest) (*SetAppNetworkPolicyRecheckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppNetworkPolicyRecheck not implemented")
+}
+func (UnimplementedAdminServer) SetAppRedirectURIs(context.Context, *SetAppRedirectURIsRequest) (*SetAppRedirectURIsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppRedirectURIs not implemented")
+}
+func (UnimplementedAdminServer) SetAppOrigins(context.Context, *SetAppOriginsRequest) (*SetAppOriginsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppOrigins not implemented")
+}
+func (UnimplementedAdminServer) GetAppOrigins(context.Context, *GetAppOriginsRequest) (*GetAppOriginsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAppOrigins not implemented")
+}
+func (UnimplementedAdminServer) SetAppAdmin(context.Context, *SetAppAdminRequest) (*SetAppAdminResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetAppAdmin not implemented")
+}
+func (UnimplementedAdminServer) ListPendingTOS(context.Context, *ListPendingTOSRequest) (*ListPendingTOSResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPendingTOS not implemented")
+}
+func (UnimplementedAdminServer) SetMaintenance(context.Context, *SetMaintenanceRequest) (*SetMaintenanceResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetMaintenance not implemented")
+}
+func (UnimplementedAdminServer) GetStats(context.Context, *GetStatsRequest) (*GetStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedAdminServer) ExportAuditEvents(*ExportAuditEventsRequest, grpc.ServerStreamingServer[ExportAuditEventsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportAuditEvents not implemented")
+}
+func (UnimplementedAdminServer) InspectToken(context.Context, *InspectTokenRequest) (*InspectTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InspectToken not implemented")
+}
+func (UnimplementedAdminServer) RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeToken not implemented")
+}
+func (UnimplementedAdminServer) RunSelfTest(context.Context, *RunSelfTestRequest) (*RunSelfTestResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RunSelfTest not implemented")
+}
+func (UnimplementedAdminServer) VerifyAuditChain(context.Context, *VerifyAuditChainRequest) (*VerifyAuditChainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyAuditChain not implemented")
+}
+func (UnimplementedAdminServer) mustEmbedUnimplementedAdminServer() {}
+func (UnimplementedAdminServer) testEmbeddedByValue()               {}
+
+// UnsafeAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AdminServer will
+// result in compilation errors.
+type UnsafeAdminServer interface {
+	mustEmbedUnimplementedAdminServer()
+}
+
+func RegisterAdminServer(s grpc.ServiceRegistrar, srv AdminServer) {
+	// If the following call pancis, it indicates UnimplementedAdminServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Admin_ServiceDesc, srv)
+}
+
+func _Admin_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_GetUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetUser(ctx, req.(*GetUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAdmin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAdminRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAdmin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAdmin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAdmin(ctx, req.(*SetAdminRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetForcePasswordChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetForcePasswordChangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetForcePasswordChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetForcePasswordChange_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetForcePasswordChange(ctx, req.(*SetForcePasswordChangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CreateApp_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAppRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CreateApp(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_CreateApp_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CreateApp(ctx, req.(*CreateAppRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ImportUsers_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AdminServer).ImportUsers(&grpc.GenericServerStream[ImportUsersRequest, ImportUsersResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Admin_ImportUsersServer = grpc.ClientStreamingServer[ImportUsersRequest, ImportUsersResponse]
+
+func _Admin_Backup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(BackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).Backup(m, &grpc.GenericServerStream[BackupRequest, BackupResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Admin_BackupServer = grpc.ServerStreamingServer[BackupResponse]
+
+func _Admin_CreateOrganization_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateOrganizationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CreateOrganization(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_CreateOrganization_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CreateOrganization(ctx, req.(*CreateOrganizationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_AddMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).AddMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_AddMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).AddMember(ctx, req.(*AddMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RemoveMember_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveMemberRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RemoveMember(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_RemoveMember_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RemoveMember(ctx, req.(*RemoveMemberRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListMembers(ctx, req.(*ListMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_InviteUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InviteUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).InviteUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_InviteUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).InviteUser(ctx, req.(*InviteUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListInvitations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInvitationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListInvitations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListInvitations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListInvitations(ctx, req.(*ListInvitationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RevokeInvitation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeInvitationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RevokeInvitation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_RevokeInvitation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RevokeInvitation(ctx, req.(*RevokeInvitationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_CreateGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).CreateGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_CreateGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).CreateGroup(ctx, req.(*CreateGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DeleteGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DeleteGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_DeleteGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DeleteGroup(ctx, req.(*DeleteGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_AddToGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddToGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).AddToGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_AddToGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).AddToGroup(ctx, req.(*AddToGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RemoveFromGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveFromGroupRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RemoveFromGroup(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_RemoveFromGroup_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RemoveFromGroup(ctx, req.(*RemoveFromGroupRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListGroupMembers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListGroupMembersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListGroupMembers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListGroupMembers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListGroupMembers(ctx, req.(*ListGroupMembersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppGroupsClaim_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppGroupsClaimRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppGroupsClaim(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppGroupsClaim_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppGroupsClaim(ctx, req.(*SetAppGroupsClaimRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppThirdParty_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppThirdPartyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppThirdParty(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppThirdParty_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppThirdParty(ctx, req.(*SetAppThirdPartyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppSessionLimit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppSessionLimitRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppSessionLimit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppSessionLimit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppSessionLimit(ctx, req.(*SetAppSessionLimitRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppSessionTimeouts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppSessionTimeoutsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppSessionTimeouts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppSessionTimeouts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppSessionTimeouts(ctx, req.(*SetAppSessionTimeoutsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppRefreshWindows_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppRefreshWindowsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppRefreshWindows(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppRefreshWindows_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppRefreshWindows(ctx, req.(*SetAppRefreshWindowsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppMinACR_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppMinACRRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppMinACR(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppMinACR_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppMinACR(ctx, req.(*SetAppMinACRRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppOfflineTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppOfflineTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppOfflineTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppOfflineTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppOfflineTokens(ctx, req.(*SetAppOfflineTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppProvisioner_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppProvisionerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppProvisioner(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppProvisioner_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppProvisioner(ctx, req.(*SetAppProvisionerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppLoginPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppLoginPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppLoginPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppLoginPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppLoginPolicy(ctx, req.(*SetAppLoginPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_DeleteAppLoginPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAppLoginPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).DeleteAppLoginPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_DeleteAppLoginPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).DeleteAppLoginPolicy(ctx, req.(*DeleteAppLoginPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListAppLoginPolicies_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAppLoginPoliciesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListAppLoginPolicies(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListAppLoginPolicies_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListAppLoginPolicies(ctx, req.(*ListAppLoginPoliciesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppNetworkPolicyRecheck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppNetworkPolicyRecheckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppNetworkPolicyRecheck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppNetworkPolicyRecheck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppNetworkPolicyRecheck(ctx, req.(*SetAppNetworkPolicyRecheckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppRedirectURIs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppRedirectURIsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppRedirectURIs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppRedirectURIs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppRedirectURIs(ctx, req.(*SetAppRedirectURIsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppOrigins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppOriginsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppOrigins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppOrigins_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppOrigins(ctx, req.(*SetAppOriginsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetAppOrigins_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAppOriginsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetAppOrigins(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_GetAppOrigins_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetAppOrigins(ctx, req.(*GetAppOriginsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetAppAdmin_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetAppAdminRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetAppAdmin(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetAppAdmin_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetAppAdmin(ctx, req.(*SetAppAdminRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ListPendingTOS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPendingTOSRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).ListPendingTOS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_ListPendingTOS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).ListPendingTOS(ctx, req.(*ListPendingTOSRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_SetMaintenance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetMaintenanceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).SetMaintenance(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_SetMaintenance_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).SetMaintenance(ctx, req.(*SetMaintenanceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_ExportAuditEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportAuditEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AdminServer).ExportAuditEvents(m, &grpc.GenericServerStream[ExportAuditEventsRequest, ExportAuditEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Admin_ExportAuditEventsServer = grpc.ServerStreamingServer[ExportAuditEventsResponse]
+
+func _Admin_InspectToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InspectTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).InspectToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_InspectToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).InspectToken(ctx, req.(*InspectTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RevokeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RevokeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_RevokeToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RevokeToken(ctx, req.(*RevokeTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_RunSelfTest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RunSelfTestRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).RunSelfTest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_RunSelfTest_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).RunSelfTest(ctx, req.(*RunSelfTestRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Admin_VerifyAuditChain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyAuditChainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AdminServer).VerifyAuditChain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Admin_VerifyAuditChain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AdminServer).VerifyAuditChain(ctx, req.(*VerifyAuditChainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Admin_ServiceDesc is the grpc.ServiceDesc for Admin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Admin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "auth.Admin",
+	HandlerType: (*AdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListUsers",
+			Handler:    _Admin_ListUsers_Handler,
+		},
+		{
+			MethodName: "GetUser",
+			Handler:    _Admin_GetUser_Handler,
+		},
+		{
+			MethodName: "SetAdmin",
+			Handler:    _Admin_SetAdmin_Handler,
+		},
+		{
+			MethodName: "SetForcePasswordChange",
+			Handler:    _Admin_SetForcePasswordChange_Handler,
+		},
+		{
+			MethodName: "CreateApp",
+			Handler:    _Admin_CreateApp_Handler,
+		},
+		{
+			MethodName: "CreateOrganization",
+			Handler:    _Admin_CreateOrganization_Handler,
+		},
+		{
+			MethodName: "AddMember",
+			Handler:    _Admin_AddMember_Handler,
+		},
+		{
+			MethodName: "RemoveMember",
+			Handler:    _Admin_RemoveMember_Handler,
+		},
+		{
+			MethodName: "ListMembers",
+			Handler:    _Admin_ListMembers_Handler,
+		},
+		{
+			MethodName: "InviteUser",
+			Handler:    _Admin_InviteUser_Handler,
+		},
+		{
+			MethodName: "ListInvitations",
+			Handler:    _Admin_ListInvitations_Handler,
+		},
+		{
+			MethodName: "RevokeInvitation",
+			Handler:    _Admin_RevokeInvitation_Handler,
+		},
+		{
+			MethodName: "CreateGroup",
+			Handler:    _Admin_CreateGroup_Handler,
+		},
+		{
+			MethodName: "DeleteGroup",
+			Handler:    _Admin_DeleteGroup_Handler,
+		},
+		{
+			MethodName: "AddToGroup",
+			Handler:    _Admin_AddToGroup_Handler,
+		},
+		{
+			MethodName: "RemoveFromGroup",
+			Handler:    _Admin_RemoveFromGroup_Handler,
+		},
+		{
+			MethodName: "ListGroupMembers",
+			Handler:    _Admin_ListGroupMembers_Handler,
+		},
+		{
+			MethodName: "SetAppGroupsClaim",
+			Handler:    _Admin_SetAppGroupsClaim_Handler,
+		},
+		{
+			MethodName: "SetAppThirdParty",
+			Handler:    _Admin_SetAppThirdParty_Handler,
+		},
+		{
+			MethodName: "SetAppSessionLimit",
+			Handler:    _Admin_SetAppSessionLimit_Handler,
+		},
+		{
+			MethodName: "SetAppSessionTimeouts",
+			Handler:    _Admin_SetAppSessionTimeouts_Handler,
+		},
+		{
+			MethodName: "SetAppRefreshWindows",
+			Handler:    _Admin_SetAppRefreshWindows_Handler,
+		},
+		{
+			MethodName: "SetAppMinACR",
+			Handler:    _Admin_SetAppMinACR_Handler,
+		},
+		{
+			MethodName: "SetAppOfflineTokens",
+			Handler:    _Admin_SetAppOfflineTokens_Handler,
+		},
+		{
+			MethodName: "SetAppProvisioner",
+			Handler:    _Admin_SetAppProvisioner_Handler,
+		},
+		{
+			MethodName: "SetAppLoginPolicy",
+			Handler:    _Admin_SetAppLoginPolicy_Handler,
+		},
+		{
+			MethodName: "DeleteAppLoginPolicy",
+			Handler:    _Admin_DeleteAppLoginPolicy_Handler,
+		},
+		{
+			MethodName: "ListAppLoginPolicies",
+			Handler:    _Admin_ListAppLoginPolicies_Handler,
+		},
+		{
+			MethodName: "SetAppNetworkPolicyRecheck",
+			Handler:    _Admin_SetAppNetworkPolicyRecheck_Handler,
+		},
+		{
+			MethodName: "SetAppRedirectURIs",
+			Handler:    _Admin_SetAppRedirectURIs_Handler,
+		},
+		{
+			MethodName: "SetAppOrigins",
+			Handler:    _Admin_SetAppOrigins_Handler,
+		},
+		{
+			MethodName: "GetAppOrigins",
+			Handler:    _Admin_GetAppOrigins_Handler,
+		},
+		{
+			MethodName: "SetAppAdmin",
+			Handler:    _Admin_SetAppAdmin_Handler,
+		},
+		{
+			MethodName: "ListPendingTOS",
+			Handler:    _Admin_ListPendingTOS_Handler,
+		},
+		{
+			MethodName: "SetMaintenance",
+			Handler:    _Admin_SetMaintenance_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _Admin_GetStats_Handler,
+		},
+		{
+			MethodName: "InspectToken",
+			Handler:    _Admin_InspectToken_Handler,
+		},
+		{
+			MethodName: "RevokeToken",
+			Handler:    _Admin_RevokeToken_Handler,
+		},
+		{
+			MethodName: "RunSelfTest",
+			Handler:    _Admin_RunSelfTest_Handler,
+		},
+		{
+			MethodName: "VerifyAuditChain",
+			Handler:    _Admin_VerifyAuditChain_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ImportUsers",
+			Handler:       _Admin_ImportUsers_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Backup",
+			Handler:       _Admin_Backup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ExportAuditEvents",
+			Handler:       _Admin_ExportAuditEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "sso/admin.proto",
+}
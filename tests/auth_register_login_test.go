@@ -3,10 +3,13 @@ package tests
 import (
 	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
 	"github.com/brianvoe/gofakeit/v6"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"sso/internal/lib/jwt"
 	"sso/tests/suite"
+	"sync"
 	"testing"
 	"time"
 )
@@ -44,22 +47,21 @@ func TestRegisterLogin_Login_HappyPath(t *testing.T) {
 
 	loginTime := time.Now()
 
-	tokenParsed, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
-		return []byte(appSecret), nil
-	})
+	claims, err := jwt.Parse(token, []byte(appSecret))
 	require.NoError(t, err)
 
-	claims, ok := tokenParsed.Claims.(jwt.MapClaims)
-	require.True(t, ok)
-
-	assert.Equal(t, respReg.GetUserId(), int64(claims["uid"].(float64)))
-	assert.Equal(t, email, claims["email"].(string))
-	assert.Equal(t, appID, int(claims["app_id"].(float64)))
+	assert.Equal(t, respReg.GetUserId(), claims.UID)
+	assert.Equal(t, email, claims.Email)
+	assert.Equal(t, appID, claims.AppID)
 
 	const deltaSeconds = 1
 
 	// check if exp of token is in correct range, ttl get from st.Cfg.TokenTTL
-	assert.InDelta(t, loginTime.Add(st.Cfg.TokenTTL).Unix(), claims["exp"].(float64), deltaSeconds)
+	assert.InDelta(t, loginTime.Add(st.Cfg.TokenTTL).Unix(), claims.ExpiresAt.Unix(), deltaSeconds)
+
+	// expires_in in the response matches exp inside the token
+	assert.Equal(t, "Bearer", respLogin.GetTokenType())
+	assert.InDelta(t, claims.ExpiresAt.Unix(), loginTime.Unix()+respLogin.GetExpiresIn(), deltaSeconds)
 }
 
 func TestRegisterLogin_DuplicatedRegistration(t *testing.T) {
@@ -195,3 +197,45 @@ func TestLogin_FailCases(t *testing.T) {
 func randomFakePassword() string {
 	return gofakeit.Password(true, true, true, true, false, passDefaultLen)
 }
+
+// Из конкурентных регистраций одного email проходит ровно одна, остальные получают AlreadyExists
+func TestRegisterLogin_ConcurrentDuplicatedRegistration(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	const clients = 10
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		ok     int
+		exists int
+	)
+	for range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{
+				Email:    email,
+				Password: pass,
+			})
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch status.Code(err) {
+			case codes.OK:
+				ok++
+			case codes.AlreadyExists:
+				exists++
+			default:
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, ok)
+	assert.Equal(t, clients-1, exists)
+}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"sso/internal/config"
+	"sso/tests/suite"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRegisterLoginValidate(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	respReg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+
+	respValidate, err := st.AuthClient.ValidateToken(ctx, &ssov1.ValidateTokenRequest{Token: respLogin.GetToken()})
+	require.NoError(t, err)
+
+	assert.Equal(t, respReg.GetUserId(), respValidate.GetUserId())
+	assert.Equal(t, email, respValidate.GetEmail())
+	assert.Equal(t, int32(appID), respValidate.GetAppId())
+	assert.False(t, respValidate.GetIsAdmin())
+	assert.InDelta(t, time.Now().Add(st.Cfg.TokenTTL).Unix(), respValidate.GetExpiresAt(), 1)
+}
+
+func TestLogin_WrongPassword(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: randomFakePassword()})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: randomFakePassword(), AppId: appID})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "invalid email or password")
+}
+
+func TestLogin_UnknownApp(t *testing.T) {
+	ctx, st := suite.New(t)
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: pass})
+	require.NoError(t, err)
+
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: pass, AppId: 999})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.ErrorContains(t, err, "invalid app_id")
+}
+
+func TestValidateToken_Expired(t *testing.T) {
+	ctx, st := suite.New(t, func(cfg *config.Config) {
+		cfg.TokenTTL = time.Second
+		cfg.JWT.Leeway = 0
+	})
+
+	email := gofakeit.Email()
+	pass := randomFakePassword()
+
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: pass})
+	require.NoError(t, err)
+
+	respLogin, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: pass, AppId: appID})
+	require.NoError(t, err)
+	assert.LessOrEqual(t, respLogin.GetExpiresIn(), int64(1))
+
+	_, err = st.AuthClient.ValidateToken(ctx, &ssov1.ValidateTokenRequest{Token: respLogin.GetToken()})
+	require.NoError(t, err)
+
+	// exp хранится с точностью до секунды: ждём с запасом
+	time.Sleep(2 * time.Second)
+
+	_, err = st.AuthClient.ValidateToken(ctx, &ssov1.ValidateTokenRequest{Token: respLogin.GetToken()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"sso/internal/config"
+	"sso/tests/suite"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthgrpc "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// Каждый слушатель обслуживает только свои сервисы; health отвечает за сервисы своего слушателя
+func TestGRPCListeners(t *testing.T) {
+	publicAddr := suite.FreeAddr(t)
+	legacyAddr := suite.FreeAddr(t)
+	adminAddr := suite.FreeAddr(t)
+
+	ctx, st := suite.New(t, func(cfg *config.Config) {
+		cfg.GRPC.Port = 0
+		cfg.GRPC.Listeners = []config.GRPCListener{
+			{Addr: publicAddr, Services: []string{config.GRPCServiceAuth, config.GRPCServiceHealth}},
+			{Addr: legacyAddr, Services: []string{config.GRPCServiceHealth, config.GRPCServiceAuth}},
+			{Addr: adminAddr, Services: []string{config.GRPCServiceAdmin, config.GRPCServiceHealth}},
+		}
+	})
+
+	dial := func(addr string) *grpc.ClientConn {
+		cc, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = cc.Close() })
+
+		return cc
+	}
+
+	// Старый и новый порт обслуживают Auth одинаково
+	email, password := gofakeit.Email(), randomFakePassword()
+	_, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: password})
+	require.NoError(t, err)
+
+	_, err = ssov1.NewAuthClient(dial(legacyAddr)).Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appID})
+	require.NoError(t, err)
+
+	health := func(addr, service string) (healthgrpc.HealthCheckResponse_ServingStatus, error) {
+		resp, err := healthgrpc.NewHealthClient(dial(addr)).Check(ctx, &healthgrpc.HealthCheckRequest{Service: service})
+
+		return resp.GetStatus(), err
+	}
+
+	for addr, services := range map[string][2]string{
+		publicAddr: {ssov1.Auth_ServiceDesc.ServiceName, ssov1.Admin_ServiceDesc.ServiceName},
+		adminAddr:  {ssov1.Admin_ServiceDesc.ServiceName, ssov1.Auth_ServiceDesc.ServiceName},
+	} {
+		serving, missing := services[0], services[1]
+
+		got, err := health(addr, serving)
+		require.NoError(t, err, addr)
+		assert.Equal(t, healthgrpc.HealthCheckResponse_SERVING, got, addr)
+
+		_, err = health(addr, missing)
+		assert.Equal(t, codes.NotFound, status.Code(err), addr)
+	}
+
+	// Admin не зарегистрирован на публичном порту, Auth - на порту администрирования
+	_, err = ssov1.NewAdminClient(dial(publicAddr)).GetStats(ctx, &ssov1.GetStatsRequest{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = ssov1.NewAuthClient(dial(adminAddr)).Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appID})
+	assert.Equal(t, codes.Unimplemented, status.Code(err))
+}
@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"database/sql"
+	"sso/internal/config"
+	"sso/tests/suite"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/brianvoe/gofakeit/v6"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	ctx, st := suite.New(t, func(cfg *config.Config) {
+		cfg.SelfTest = config.SelfTestConfig{Enabled: true, StaleAfter: time.Hour}
+		cfg.Refresh.Enabled = true
+	})
+	admin := adminClient(ctx, t, st)
+
+	resp, err := admin.RunSelfTest(ctx, &ssov1.RunSelfTestRequest{})
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(resp.GetSteps()))
+	for _, step := range resp.GetSteps() {
+		names = append(names, step.GetName())
+		assert.Equal(t, "passed", step.GetStatus(), "%s: %s %s", step.GetName(), step.GetReason(), step.GetMessage())
+	}
+	assert.True(t, resp.GetPassed())
+	assert.Equal(t, []string{
+		"create_app", "register", "verify_email", "login", "validate", "refresh",
+		"change_password", "logout", "delete", "cleanup",
+	}, names)
+
+	// Тестовые пользователь и приложение удалены и не попали в статистику
+	stats, err := admin.GetStats(ctx, &ssov1.GetStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stats.GetTotalUsers())
+
+	users, err := admin.ListUsers(ctx, &ssov1.ListUsersRequest{})
+	require.NoError(t, err)
+	assert.Len(t, users.GetUsers(), 1)
+}
+
+func TestRunSelfTest_RefreshDisabled(t *testing.T) {
+	ctx, st := suite.New(t, func(cfg *config.Config) {
+		cfg.SelfTest = config.SelfTestConfig{Enabled: true, StaleAfter: time.Hour}
+	})
+	admin := adminClient(ctx, t, st)
+
+	resp, err := admin.RunSelfTest(ctx, &ssov1.RunSelfTestRequest{})
+	require.NoError(t, err)
+
+	// Шаг refresh не выполнялся, остальные пройдены - но непроверенная часть не даёт общего успеха
+	for _, step := range resp.GetSteps() {
+		want := "passed"
+		if step.GetName() == "refresh" {
+			want = "skipped"
+		}
+		assert.Equal(t, want, step.GetStatus(), "%s: %s %s", step.GetName(), step.GetReason(), step.GetMessage())
+	}
+	assert.False(t, resp.GetPassed())
+}
+
+func TestRunSelfTest_Disabled(t *testing.T) {
+	ctx, st := suite.New(t)
+	admin := adminClient(ctx, t, st)
+
+	_, err := admin.RunSelfTest(ctx, &ssov1.RunSelfTestRequest{})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+}
+
+// adminClient - клиент Admin с токеном нового пользователя, которому права администратора выданы прямо в БД
+func adminClient(ctx context.Context, t *testing.T, st *suite.Suite) ssov1.AdminClient {
+	t.Helper()
+
+	email, password := gofakeit.Email(), randomFakePassword()
+	reg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: password})
+	require.NoError(t, err)
+
+	db, err := sql.Open("sqlite3", st.Cfg.StoragePath)
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "UPDATE users SET is_admin = TRUE WHERE id = ?", reg.GetUserId())
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	login, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appID})
+	require.NoError(t, err)
+
+	return ssov1.NewAdminClient(bearerConn(t, st, login.GetToken()))
+}
+
+// bearerConn - соединение с сервисом, которое передаёт token в каждом вызове
+func bearerConn(t *testing.T, st *suite.Suite, token string) *grpc.ClientConn {
+	t.Helper()
+
+	cc, err := grpc.NewClient(st.Addr,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(func(
+			ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker,
+			opts ...grpc.CallOption,
+		) error {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithStreamInterceptor(func(
+			ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer,
+			opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = cc.Close() })
+
+	return cc
+}
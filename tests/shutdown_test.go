@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sso/internal/config"
+	"sso/tests/suite"
+	"testing"
+	"time"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Остановка приложения дожидается запроса, который уже выполняется, и не принимает новые
+func TestShutdown_WaitsForInFlightRequest(t *testing.T) {
+	// Проверка пароля по базе утечек держит Register, пока тест не отпустит её:
+	// так запрос гарантированно выполняется в момент остановки
+	arrived := make(chan struct{})
+	release := make(chan struct{})
+	pwned := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		close(arrived)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer pwned.Close()
+
+	ctx, st := suite.New(t, func(cfg *config.Config) {
+		cfg.Password.BreachCheck = config.BreachCheckConfig{
+			Enabled:  true,
+			Endpoint: pwned.URL,
+			Timeout:  10 * time.Second,
+		}
+	})
+
+	type result struct {
+		resp *ssov1.RegisterResponse
+		err  error
+	}
+	registered := make(chan result, 1)
+	go func() {
+		resp, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: gofakeit.Email(), Password: randomFakePassword()})
+		registered <- result{resp: resp, err: err}
+	}()
+
+	select {
+	case <-arrived:
+	case <-ctx.Done():
+		t.Fatal("register did not reach the breach check")
+	}
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- st.Shutdown() }()
+
+	// Новые запросы отклоняются, пока старый ещё выполняется
+	require.Eventually(t, func() bool {
+		_, err := st.AuthClient.GetServerInfo(ctx, &ssov1.GetServerInfoRequest{})
+		return status.Code(err) == codes.Unavailable
+	}, 5*time.Second, 10*time.Millisecond)
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("application stopped before the in-flight request finished: %v", err)
+	default:
+	}
+
+	close(release)
+
+	res := <-registered
+	require.NoError(t, res.err)
+	assert.NotZero(t, res.resp.GetUserId())
+
+	require.NoError(t, <-stopped)
+}
@@ -1,14 +1,29 @@
+// Package suite - запуск полного приложения SSO внутри процесса теста: временная SQLite
+// с миграциями, свободный порт, подключённый gRPC-клиент и остановка по завершении теста.
+// Внешние сервисы не нужны, тесты запускаются обычным `go test ./...`
 package suite
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net"
-	"os"
+	"path/filepath"
+	"runtime"
+	"sso/internal/app"
 	"sso/internal/config"
+	"sso/internal/lib/jwt"
 	"strconv"
+	"sync"
 	"testing"
+	"time"
 
 	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite3"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
@@ -16,50 +31,191 @@ import (
 type Suite struct {
 	*testing.T                  // Потребуется для вызова методов *testing.T внутри Suite
 	Cfg        *config.Config   // Конфигурация приложения
+	Addr       string           // Адрес gRPC-сервера (127.0.0.1:port)
 	AuthClient ssov1.AuthClient // Клиент для взаимодействия с gRPC-сервером
+
+	stop     context.CancelFunc // отменяет контекст App.Run - штатная остановка, как по SIGTERM
+	done     chan error         // результат App.Run
+	stopOnce sync.Once
+	stopErr  error
 }
 
 const (
-	grpcHost = "localhost"
+	grpcHost = "127.0.0.1"
+
+	requestTimeout = 10 * time.Second // таймаут контекста, который получает тест
 )
 
-func New(t *testing.T) (context.Context, *Suite) {
+// Option - изменение конфигурации перед запуском приложения
+type Option func(cfg *config.Config)
+
+// New - запускает приложение с конфигурацией по умолчанию (с учётом opts) и возвращает
+// подключённого клиента. Всё останавливается и удаляется по завершении теста
+func New(t *testing.T, opts ...Option) (context.Context, *Suite) {
 	t.Helper()
 	t.Parallel()
 
-	cfg := config.MustLoadPath(configPath())
+	dir := t.TempDir()
+	cfg := defaultConfig(t, dir)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	migrateUp(t, filepath.Join(repoRoot(), "migrations"), cfg.StoragePath, "migrations")
+	migrateUp(t, filepath.Join(repoRoot(), "tests", "migrations"), cfg.StoragePath, "migrations_test")
+
+	// Логи приложения в выводе теста только мешают; журнал безопасности пишется в файл во временном каталоге
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
 
-	ctx, cancelCtx := context.WithTimeout(context.Background(), cfg.GRPC.Timeout)
+	application, err := app.New(log, new(slog.LevelVar), cfg)
+	if err != nil {
+		t.Fatalf("failed to init application: %v", err)
+	}
+
+	runCtx, stop := context.WithCancel(context.Background())
+	st := &Suite{
+		T:    t,
+		Cfg:  cfg,
+		Addr: grpcAddress(cfg),
+		stop: stop,
+		done: make(chan error, 1),
+	}
 
+	go func() { st.done <- application.Run(runCtx) }()
 	t.Cleanup(func() {
-		t.Helper()
-		cancelCtx()
+		if err := st.Shutdown(); err != nil {
+			t.Errorf("application stopped with error: %v", err)
+		}
 	})
 
-	cc, err := grpc.DialContext(context.Background(),
-		grpcAddress(cfg),
-		grpc.WithTransportCredentials(insecure.NewCredentials())) // Используем insecure-коннект для тестов
+	st.waitReady()
+
+	cc, err := grpc.NewClient(st.Addr, grpc.WithTransportCredentials(insecure.NewCredentials())) // Используем insecure-коннект для тестов
 	if err != nil {
 		t.Fatalf("grpc server connection failed: %v", err)
 	}
+	t.Cleanup(func() { _ = cc.Close() })
+
+	st.AuthClient = ssov1.NewAuthClient(cc)
+
+	ctx, cancelCtx := context.WithTimeout(context.Background(), requestTimeout)
+	t.Cleanup(cancelCtx)
+
+	return ctx, st
+}
+
+// Shutdown - штатная остановка приложения (как по SIGTERM): дожидается активных запросов
+// и возвращает результат App.Run. Повторные вызовы возвращают тот же результат
+func (s *Suite) Shutdown() error {
+	s.stopOnce.Do(func() {
+		s.stop()
+		s.stopErr = <-s.done
+	})
+
+	return s.stopErr
+}
+
+// waitReady - ждёт, пока сервер начнёт принимать соединения
+func (s *Suite) waitReady() {
+	s.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-s.done:
+			s.Fatalf("application stopped before it was ready: %v", err)
+		default:
+		}
+
+		conn, err := net.Dial("tcp", s.Addr)
+		if err == nil {
+			_ = conn.Close()
+
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	s.Fatalf("gRPC server did not start on %s", s.Addr)
+}
+
+// defaultConfig - минимальная конфигурация: SQLite и журнал безопасности в dir, свободный порт
+func defaultConfig(t *testing.T, dir string) *config.Config {
+	t.Helper()
+
+	return &config.Config{
+		Env:         config.EnvLocal,
+		StoragePath: filepath.Join(dir, "sso.db"),
+		TokenTTL:    time.Hour,
+		JWT:         config.JWTConfig{Leeway: jwt.DefaultLeeway},
+		GRPC: config.GRPCConfig{
+			Port:    freePort(t),
+			Timeout: requestTimeout,
+			Locale:  "en",
+		},
+		Audit: config.AuditConfig{
+			Output: config.AuditOutputFile,
+			Path:   filepath.Join(dir, "audit.log"),
+		},
+		Janitor: config.JanitorConfig{Interval: time.Hour, BatchSize: 500},
+		Revocations: config.RevocationsConfig{
+			PollInterval: time.Second, KeepaliveInterval: 30 * time.Second, BufferSize: 256, Retention: time.Hour,
+		},
+		Maintenance: config.MaintenanceConfig{RetryAfter: 30 * time.Second},
+		Geo:         config.GeoConfig{CheckInterval: time.Minute, HistoryDepth: 20, Retention: time.Hour},
+		Schema:      config.SchemaConfig{CheckInterval: 30 * time.Second},
+	}
+}
+
+// FreeAddr - адрес на grpcHost со свободным портом, например для grpc.listeners
+func FreeAddr(t *testing.T) string {
+	t.Helper()
+
+	return net.JoinHostPort(grpcHost, strconv.Itoa(freePort(t)))
+}
 
-	return ctx, &Suite{
-		T:          t,
-		Cfg:        cfg,
-		AuthClient: ssov1.NewAuthClient(cc),
+// freePort - порт, свободный в момент вызова
+func freePort(t *testing.T) int {
+	t.Helper()
+
+	l, err := net.Listen("tcp", net.JoinHostPort(grpcHost, "0"))
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
 	}
+	defer l.Close()
+
+	return l.Addr().(*net.TCPAddr).Port
 }
 
-func configPath() string {
-	const key = "CONFIG_PATH"
+// migrateUp - применяет миграции из source к SQLite в dbPath
+func migrateUp(t *testing.T, source, dbPath, table string) {
+	t.Helper()
+
+	m, err := migrate.New("file://"+source, fmt.Sprintf("sqlite3://%s?x-migrations-table=%s", dbPath, table))
+	if err != nil {
+		t.Fatalf("migrate %s: %v", source, err)
+	}
+	defer m.Close()
 
-	if v := os.Getenv(key); v != "" {
-		return v
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		t.Fatalf("migrate %s: %v", source, err)
 	}
+}
 
-	return "../config/local.yaml"
+// repoRoot - корень репозитория (пути к миграциям не зависят от каталога, из которого запущен тест)
+func repoRoot() string {
+	_, file, _, _ := runtime.Caller(0)
+
+	return filepath.Join(filepath.Dir(file), "..", "..")
 }
 
+// grpcAddress - адрес первого слушателя gRPC; к слушателю на всех интерфейсах подключаемся через grpcHost
 func grpcAddress(cfg *config.Config) string {
-	return net.JoinHostPort(grpcHost, strconv.Itoa(cfg.GRPC.Port))
+	host, port, _ := net.SplitHostPort(cfg.GRPC.AllListeners()[0].Addr)
+	if host == "" {
+		host = grpcHost
+	}
+
+	return net.JoinHostPort(host, port)
 }
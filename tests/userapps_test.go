@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"database/sql"
+	"sso/internal/config"
+	"sso/tests/suite"
+	"testing"
+
+	ssov1 "github.com/AmanNookat/protos/gen/go/sso"
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Администратор приложения без глобальных прав видит пользователей только своего приложения,
+// а остальные административные методы ему недоступны
+func TestUserApps_AppAdmin(t *testing.T) {
+	ctx, st := suite.New(t, func(cfg *config.Config) {
+		cfg.UserApps.Enabled = true
+	})
+	const otherApp = appID + 1
+
+	db, err := sql.Open("sqlite3", st.Cfg.StoragePath)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = db.ExecContext(ctx, "INSERT INTO apps (id, name, secret) VALUES (?, 'other', 'other-secret')", otherApp)
+	require.NoError(t, err)
+
+	email, password := gofakeit.Email(), randomFakePassword()
+	reg, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: email, Password: password})
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "INSERT INTO app_admins (app_id, user_id) VALUES (?, ?)", appID, reg.GetUserId())
+	require.NoError(t, err)
+	login, err := st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: email, Password: password, AppId: appID})
+	require.NoError(t, err)
+
+	// Пользователь другого приложения
+	otherEmail, otherPassword := gofakeit.Email(), randomFakePassword()
+	other, err := st.AuthClient.Register(ctx, &ssov1.RegisterRequest{Email: otherEmail, Password: otherPassword})
+	require.NoError(t, err)
+	_, err = st.AuthClient.Login(ctx, &ssov1.LoginRequest{Email: otherEmail, Password: otherPassword, AppId: otherApp})
+	require.NoError(t, err)
+
+	cc := bearerConn(t, st, login.GetToken())
+	admin, auth := ssov1.NewAdminClient(cc), ssov1.NewAuthClient(cc)
+
+	users, err := admin.ListUsers(ctx, &ssov1.ListUsersRequest{AppId: appID})
+	require.NoError(t, err)
+	require.Len(t, users.GetUsers(), 1)
+	assert.Equal(t, reg.GetUserId(), users.GetUsers()[0].GetId())
+	_, err = admin.GetUser(ctx, &ssov1.GetUserRequest{UserId: reg.GetUserId(), AppId: appID})
+	require.NoError(t, err)
+	_, err = admin.GetStats(ctx, &ssov1.GetStatsRequest{AppId: appID})
+	require.NoError(t, err)
+
+	denied := map[string]func() error{
+		"ListUsers other app": func() error {
+			_, err := admin.ListUsers(ctx, &ssov1.ListUsersRequest{AppId: otherApp})
+			return err
+		},
+		"ListUsers all apps": func() error {
+			_, err := admin.ListUsers(ctx, &ssov1.ListUsersRequest{})
+			return err
+		},
+		"GetUser other app": func() error {
+			_, err := admin.GetUser(ctx, &ssov1.GetUserRequest{UserId: other.GetUserId(), AppId: otherApp})
+			return err
+		},
+		"GetStats other app": func() error {
+			_, err := admin.GetStats(ctx, &ssov1.GetStatsRequest{AppId: otherApp})
+			return err
+		},
+		"SetAdmin": func() error {
+			_, err := admin.SetAdmin(ctx, &ssov1.SetAdminRequest{UserId: other.GetUserId(), IsAdmin: true})
+			return err
+		},
+		"SetMaintenance": func() error {
+			_, err := admin.SetMaintenance(ctx, &ssov1.SetMaintenanceRequest{Enabled: true})
+			return err
+		},
+		"ExportAuditEvents": func() error {
+			stream, err := admin.ExportAuditEvents(ctx, &ssov1.ExportAuditEventsRequest{Format: "jsonl"})
+			if err != nil {
+				return err
+			}
+			_, err = stream.Recv()
+			return err
+		},
+		"InspectToken": func() error {
+			_, err := admin.InspectToken(ctx, &ssov1.InspectTokenRequest{Token: login.GetToken()})
+			return err
+		},
+		"GetUsersBatch": func() error {
+			_, err := auth.GetUsersBatch(ctx, &ssov1.GetUsersBatchRequest{UserIds: []int64{other.GetUserId()}})
+			return err
+		},
+		"EraseUser": func() error {
+			_, err := auth.EraseUser(ctx, &ssov1.EraseUserRequest{UserId: other.GetUserId()})
+			return err
+		},
+	}
+	for name, call := range denied {
+		assert.Equal(t, codes.PermissionDenied, status.Code(call()), name)
+	}
+
+	// Пользователь другого приложения не изменён
+	var isAdmin bool
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT is_admin FROM users WHERE id = ?", other.GetUserId()).Scan(&isAdmin))
+	assert.False(t, isAdmin)
+}